@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/flanksource/arch-unit/internal/offline"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky/ai"
 	"github.com/flanksource/commons/logger"
@@ -36,6 +37,10 @@ func DefaultAIAnalyzerConfig() AIAnalyzerConfig {
 
 // NewAIAnalyzer creates a new AI analyzer with clicky integration
 func NewAIAnalyzer(config AIAnalyzerConfig) (*AIAnalyzer, error) {
+	if err := offline.Check("AI analysis"); err != nil {
+		return nil, err
+	}
+
 	// Create AI agent configuration
 	agentConfig := ai.AgentConfig{
 		Type:          ai.AgentTypeClaude,
@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/flanksource/arch-unit/internal/llm"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/parser"
+	"github.com/flanksource/commons/logger"
+)
+
+// askAttempts is the number of times an invalid proposal is asked for again
+const askAttempts = 2
+
+// ProposeRule asks an LLM for a rule implementing a request in natural language. Proposals that
+// do not parse are asked for again with the error.
+func ProposeRule(ctx context.Context, provider llm.Provider, request string, packages, directories, libraries []string) (*models.RuleProposal, error) {
+	prompt := BuildAskRulePrompt(request, packages, directories, libraries)
+	var lastErr error
+	for attempt := 0; attempt < askAttempts; attempt++ {
+		completion, err := provider.Complete(ctx, prompt)
+		if err != nil {
+			return nil, err
+		}
+		proposal, err := ParseProposal(completion)
+		if err == nil {
+			return proposal, nil
+		}
+		logger.Debugf("Invalid proposal from %s: %v\n%s", provider.Name(), err, completion)
+		lastErr = err
+		prompt = fmt.Sprintf("%s\n\nYour previous answer was invalid: %v\n%s\n\nAnswer again with a valid JSON object.", prompt, err, completion)
+	}
+	return nil, fmt.Errorf("%s did not propose a valid rule: %w", provider.Name(), lastErr)
+}
+
+// ParseProposal parses and validates the proposal of a completion: AQL rules must parse and
+// import rules need a valid glob
+func ParseProposal(completion string) (*models.RuleProposal, error) {
+	proposal, err := models.ParseRuleProposal(completion)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.IsAQL() {
+		ruleSet, err := parser.ParseAQL(proposal.AQL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AQL: %w", err)
+		}
+		if len(ruleSet.Rules) == 0 {
+			return nil, fmt.Errorf("the AQL has no RULE")
+		}
+	} else if !doublestar.ValidatePattern(proposal.Path) {
+		return nil, fmt.Errorf("invalid path glob '%s'", proposal.Path)
+	}
+	return proposal, nil
+}
@@ -0,0 +1,57 @@
+package analysis_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis"
+)
+
+// scriptedProvider answers prompts with its completions in turn
+type scriptedProvider struct {
+	completions []string
+	prompts     []string
+}
+
+func (p *scriptedProvider) Name() string {
+	return "scripted/model"
+}
+
+func (p *scriptedProvider) Complete(_ context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	completion := p.completions[0]
+	p.completions = p.completions[1:]
+	return completion, nil
+}
+
+var _ = Describe("ProposeRule", func() {
+	It("should list the codebase and parse AQL proposals in markdown fences", func() {
+		provider := &scriptedProvider{completions: []string{"```json\n" +
+			`{"aql": "RULE \"Controllers do not query\" {\n  FORBID(controllers:* -> sql/*)\n}", "explanation": "SQL belongs in repositories"}` +
+			"\n```"}}
+
+		proposal, err := analysis.ProposeRule(context.Background(), provider, "controllers must not query the database",
+			[]string{"controllers", "repository"}, []string{"pkg/controllers"}, []string{"gorm.io/gorm"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(proposal.IsAQL()).To(BeTrue())
+		Expect(proposal.Explanation).To(Equal("SQL belongs in repositories"))
+		Expect(provider.prompts[0]).To(ContainSubstring("controllers must not query the database"))
+		Expect(provider.prompts[0]).To(ContainSubstring("gorm.io/gorm"))
+	})
+
+	It("should ask again when a proposal is invalid", func() {
+		provider := &scriptedProvider{completions: []string{
+			`{"aql": "FORBID controllers"}`,
+			`{"path": "**/controllers/**", "imports": ["!gorm.io/gorm"]}`,
+		}}
+
+		proposal, err := analysis.ProposeRule(context.Background(), provider, "controllers must not touch gorm", nil, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(proposal.IsAQL()).To(BeFalse())
+		Expect(proposal.Imports).To(Equal([]string{"!gorm.io/gorm"}))
+		Expect(provider.prompts).To(HaveLen(2))
+		Expect(provider.prompts[1]).To(ContainSubstring("previous answer was invalid"))
+	})
+})
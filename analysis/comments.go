@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/flanksource/arch-unit/internal/offline"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky/ai"
 	"github.com/flanksource/commons/logger"
@@ -82,7 +83,7 @@ func (ca *CommentAnalyzer) AnalyzeComment(ctx context.Context, comment models.Co
 	}
 
 	// Use AI for complex comments
-	if ca.aiAgent == nil {
+	if ca.aiAgent == nil || offline.Enabled() {
 		return &CommentQualityResult{
 			Comment:        comment,
 			IsSimple:       false,
@@ -0,0 +1,54 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+)
+
+// cobertura is the subset of the Cobertura XML format written by coverage.py
+type cobertura struct {
+	Sources  []string `xml:"sources>source"`
+	Packages []struct {
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number int `xml:"number,attr"`
+				Hits   int `xml:"hits,attr"`
+			} `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+// parseCobertura parses a Cobertura XML report, such as coverage.py xml, whose file names are
+// relative to one of its sources
+func parseCobertura(profile *Profile, workDir string, content []byte) error {
+	var report cobertura
+	if err := xml.Unmarshal(content, &report); err != nil {
+		return err
+	}
+
+	for _, pkg := range report.Packages {
+		for _, class := range pkg.Classes {
+			file := resolveSource(workDir, report.Sources, class.Filename)
+			for _, line := range class.Lines {
+				profile.Add(file, Block{StartLine: line.Number, EndLine: line.Number, Statements: 1, Count: line.Hits})
+			}
+		}
+	}
+	return nil
+}
+
+// resolveSource resolves a file name against the first source it exists in, otherwise workDir
+func resolveSource(workDir string, sources []string, file string) string {
+	if filepath.IsAbs(file) {
+		return filepath.Clean(file)
+	}
+	for _, source := range sources {
+		path := filepath.Join(resolve(workDir, source), file)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return resolve(workDir, file)
+}
@@ -0,0 +1,194 @@
+// Package coverage reads test coverage reports and attaches the percentage of covered
+// statements to the method and type nodes of the AST cache, for the coverage metric of AQL.
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"gorm.io/gorm"
+)
+
+// Block is a range of lines instrumented by a coverage report, a single line for line based
+// reports such as coverage.py XML and lcov
+type Block struct {
+	StartLine  int
+	EndLine    int
+	Statements int
+	Count      int
+}
+
+// Profile is the instrumented blocks of every file of one or more coverage reports, keyed by
+// absolute file path
+type Profile struct {
+	Files map[string][]Block
+}
+
+// NewProfile returns an empty profile
+func NewProfile() *Profile {
+	return &Profile{Files: make(map[string][]Block)}
+}
+
+// Add records a block of a file, merging it with the same block of another report
+func (p *Profile) Add(file string, block Block) {
+	blocks := p.Files[file]
+	for i := range blocks {
+		if blocks[i].StartLine == block.StartLine && blocks[i].EndLine == block.EndLine {
+			blocks[i].Count += block.Count
+			return
+		}
+	}
+	p.Files[file] = append(blocks, block)
+}
+
+// Load reads the coverage reports matching the glob patterns, relative to workDir. The format of
+// each report is detected from its content: Go cover profiles, Cobertura XML as written by
+// coverage.py, or lcov tracefiles.
+func Load(workDir string, patterns ...string) (*Profile, error) {
+	profile := NewProfile()
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(workDir, pattern)
+		}
+		paths, err := doublestar.FilepathGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage pattern %s: %w", pattern, err)
+		}
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read coverage report %s: %w", path, err)
+			}
+			if err := Parse(profile, workDir, content); err != nil {
+				return nil, fmt.Errorf("failed to parse coverage report %s: %w", path, err)
+			}
+		}
+	}
+	return profile, nil
+}
+
+// Parse adds the blocks of a coverage report to a profile, resolving relative file paths
+// against workDir
+func Parse(profile *Profile, workDir string, content []byte) error {
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return parseGoProfile(profile, workDir, trimmed)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseCobertura(profile, workDir, trimmed)
+	case bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:")):
+		return parseLCOV(profile, workDir, trimmed)
+	default:
+		return fmt.Errorf("unknown coverage format, expected a Go cover profile, Cobertura XML or lcov")
+	}
+}
+
+// Annotate sets the coverage of the method and type nodes of the files of a profile, the
+// statements within the lines of a method and those within a type or its methods for types.
+// Nodes without instrumented statements are left without coverage. It returns the number of
+// nodes annotated.
+func Annotate(profile *Profile, nodes []*models.ASTNode) int {
+	// Types are covered by their own lines, e.g. Java and Python classes, and their methods,
+	// which in Go may live in other files of the package
+	methods := make(map[string][]*models.ASTNode)
+	for _, node := range nodes {
+		if node.NodeType == models.NodeTypeMethod && node.TypeName != "" {
+			key := node.PackageName + "." + node.TypeName
+			methods[key] = append(methods[key], node)
+		}
+	}
+
+	annotated := 0
+	for _, node := range nodes {
+		var ranges []*models.ASTNode
+		switch node.NodeType {
+		case models.NodeTypeMethod:
+			ranges = []*models.ASTNode{node}
+		case models.NodeTypeType:
+			ranges = append([]*models.ASTNode{node}, methods[node.PackageName+"."+node.TypeName]...)
+		default:
+			continue
+		}
+
+		node.Coverage = nil
+		if percent, ok := profile.covered(ranges); ok {
+			node.Coverage = &percent
+			annotated++
+		}
+	}
+	return annotated
+}
+
+// Store annotates the method and type nodes of the AST cache with the coverage of a profile,
+// clearing the coverage of nodes no longer covered by it. It returns the number of nodes annotated.
+func Store(astCache *cache.ASTCache, profile *Profile) (int, error) {
+	var nodes []*models.ASTNode
+	if err := astCache.GetReadQuery().Where("node_type IN ?", []string{models.NodeTypeMethod, models.NodeTypeType}).Find(&nodes).Error; err != nil {
+		return 0, fmt.Errorf("failed to query AST nodes: %w", err)
+	}
+
+	previous := make(map[int64]*float64, len(nodes))
+	for _, node := range nodes {
+		previous[node.ID] = node.Coverage
+	}
+	annotated := Annotate(profile, nodes)
+
+	err := astCache.GetWriteQuery().Transaction(func(tx *gorm.DB) error {
+		for _, node := range nodes {
+			if node.Coverage == nil && previous[node.ID] == nil {
+				continue
+			}
+			if err := tx.Model(&models.ASTNode{}).Where("id = ?", node.ID).Update("coverage", node.Coverage).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to store coverage: %w", err)
+	}
+	return annotated, nil
+}
+
+// covered returns the percentage of the statements of the blocks starting within the lines of
+// the nodes that were executed, false when no block starts within them
+func (p *Profile) covered(nodes []*models.ASTNode) (float64, bool) {
+	type blockKey struct {
+		file  string
+		index int
+	}
+	seen := make(map[blockKey]bool)
+	total, covered := 0, 0
+	for _, node := range nodes {
+		for i, block := range p.Files[node.FilePath] {
+			key := blockKey{node.FilePath, i}
+			if seen[key] || block.StartLine < node.StartLine || block.StartLine > node.EndLine {
+				continue
+			}
+			seen[key] = true
+			total += block.Statements
+			if block.Count > 0 {
+				covered += block.Statements
+			}
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return math.Round(float64(covered)*1000/float64(total)) / 10, true
+}
+
+// resolve returns the absolute path of a file named by a coverage report
+func resolve(workDir, file string) string {
+	if filepath.IsAbs(file) {
+		return filepath.Clean(file)
+	}
+	return filepath.Join(workDir, strings.TrimPrefix(file, "./"))
+}
@@ -0,0 +1,13 @@
+package coverage_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCoverage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Coverage Suite")
+}
@@ -0,0 +1,101 @@
+package coverage_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/coverage"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Coverage", func() {
+	const workDir = "/repo"
+
+	parse := func(report string) *coverage.Profile {
+		profile := coverage.NewProfile()
+		Expect(coverage.Parse(profile, workDir, []byte(report))).To(Succeed())
+		return profile
+	}
+
+	It("should parse Go cover profiles", func() {
+		profile := parse(`mode: set
+/repo/service/user.go:10.30,12.2 2 1
+/repo/service/user.go:14.30,18.2 3 0
+`)
+		Expect(profile.Files).To(HaveKeyWithValue("/repo/service/user.go", []coverage.Block{
+			{StartLine: 10, EndLine: 12, Statements: 2, Count: 1},
+			{StartLine: 14, EndLine: 18, Statements: 3, Count: 0},
+		}))
+	})
+
+	It("should resolve the import paths of Go cover profiles against the module", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/example/app\n\ngo 1.22\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "cover.out"), []byte("mode: count\ngithub.com/example/app/service/user.go:10.30,12.2 2 5\n"), 0644)).To(Succeed())
+
+		profile, err := coverage.Load(dir, "*.out")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profile.Files).To(HaveKey(filepath.Join(dir, "service", "user.go")))
+	})
+
+	It("should parse coverage.py XML reports", func() {
+		profile := parse(`<?xml version="1.0" ?>
+<coverage version="7.4.0">
+	<sources><source>/repo/src</source></sources>
+	<packages>
+		<package name="app">
+			<classes>
+				<class name="orders.py" filename="app/orders.py">
+					<lines>
+						<line number="3" hits="1"/>
+						<line number="4" hits="0"/>
+					</lines>
+				</class>
+			</classes>
+		</package>
+	</packages>
+</coverage>`)
+		Expect(profile.Files).To(HaveKeyWithValue("/repo/app/orders.py", []coverage.Block{
+			{StartLine: 3, EndLine: 3, Statements: 1, Count: 1},
+			{StartLine: 4, EndLine: 4, Statements: 1, Count: 0},
+		}))
+	})
+
+	It("should parse lcov tracefiles", func() {
+		profile := parse(`TN:
+SF:src/orders.ts
+DA:1,1
+DA:2,0
+end_of_record
+`)
+		Expect(profile.Files).To(HaveKeyWithValue("/repo/src/orders.ts", []coverage.Block{
+			{StartLine: 1, EndLine: 1, Statements: 1, Count: 1},
+			{StartLine: 2, EndLine: 2, Statements: 1, Count: 0},
+		}))
+	})
+
+	It("should reject unknown formats", func() {
+		Expect(coverage.Parse(coverage.NewProfile(), workDir, []byte("coverage: 80%"))).To(MatchError(ContainSubstring("unknown coverage format")))
+	})
+
+	It("should annotate methods with their statements and types with those of their methods", func() {
+		profile := parse(`mode: set
+/repo/service/user.go:10.30,12.2 2 1
+/repo/service/user.go:14.30,18.2 3 0
+/repo/service/user.go:22.30,24.2 1 1
+`)
+		userType := &models.ASTNode{FilePath: "/repo/service/types.go", PackageName: "service", TypeName: "User", NodeType: models.NodeTypeType, StartLine: 1, EndLine: 5}
+		create := &models.ASTNode{FilePath: "/repo/service/user.go", PackageName: "service", TypeName: "User", MethodName: "Create", NodeType: models.NodeTypeMethod, StartLine: 10, EndLine: 18}
+		validate := &models.ASTNode{FilePath: "/repo/service/user.go", PackageName: "service", TypeName: "User", MethodName: "Validate", NodeType: models.NodeTypeMethod, StartLine: 22, EndLine: 24}
+		untested := &models.ASTNode{FilePath: "/repo/other/other.go", PackageName: "other", MethodName: "Run", NodeType: models.NodeTypeMethod, StartLine: 1, EndLine: 10}
+
+		Expect(coverage.Annotate(profile, []*models.ASTNode{userType, create, validate, untested})).To(Equal(3))
+		Expect(*create.Coverage).To(Equal(40.0))
+		Expect(*validate.Coverage).To(Equal(100.0))
+		Expect(*userType.Coverage).To(Equal(50.0))
+		Expect(untested.Coverage).To(BeNil())
+	})
+})
@@ -0,0 +1,59 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// parseGoProfile parses a cover profile written by go test -coverprofile, whose lines are
+// file:startLine.startCol,endLine.endCol statements count, with files named by import path
+func parseGoProfile(profile *Profile, workDir string, content []byte) error {
+	module := modulePath(workDir)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		colon := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[colon+1:])
+		if colon < 0 || len(fields) != 3 {
+			return fmt.Errorf("line %d: expected file:range statements count", lineNo)
+		}
+		start, end, ok := strings.Cut(fields[0], ",")
+		startLine, err1 := strconv.Atoi(strings.Split(start, ".")[0])
+		endLine, err2 := strconv.Atoi(strings.Split(end, ".")[0])
+		statements, err3 := strconv.Atoi(fields[1])
+		count, err4 := strconv.Atoi(fields[2])
+		if !ok || err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			return fmt.Errorf("line %d: invalid block %s", lineNo, line[colon+1:])
+		}
+
+		file := line[:colon]
+		if module != "" && strings.HasPrefix(file, module+"/") {
+			file = strings.TrimPrefix(file, module+"/")
+		}
+		profile.Add(resolve(workDir, file), Block{StartLine: startLine, EndLine: endLine, Statements: statements, Count: count})
+	}
+	return scanner.Err()
+}
+
+// modulePath returns the module path declared by the go.mod of workDir, if any
+func modulePath(workDir string) string {
+	content, err := os.ReadFile(filepath.Join(workDir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseLCOV parses an lcov tracefile, recording the DA:line,hits records of each SF:file
+func parseLCOV(profile *Profile, workDir string, content []byte) error {
+	file := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = resolve(workDir, strings.TrimPrefix(line, "SF:"))
+		case line == "end_of_record":
+			file = ""
+		case strings.HasPrefix(line, "DA:"):
+			if file == "" {
+				return fmt.Errorf("line %d: DA record outside of a SF record", lineNo)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return fmt.Errorf("line %d: expected DA:line,hits", lineNo)
+			}
+			number, err1 := strconv.Atoi(fields[0])
+			hits, err2 := strconv.Atoi(fields[1])
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("line %d: invalid DA record %s", lineNo, line)
+			}
+			profile.Add(file, Block{StartLine: number, EndLine: number, Statements: 1, Count: hits})
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,287 @@
+package analysis
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// Credentials authorizes the requests to private package registries and Git hosts, in order of
+// precedence from the registries of arch-unit.yaml, from tokens in the environment, e.g.
+// GITHUB_TOKEN, and from the machines of ~/.netrc
+type Credentials struct {
+	registries []models.RegistryConfig
+	netrc      []netrcMachine
+}
+
+var (
+	credentialsInstance *Credentials
+	credentialsMutex    sync.RWMutex
+)
+
+// NewCredentials creates credentials for the configured registries, reading the netrc file at
+// $NETRC or ~/.netrc
+func NewCredentials(registries []models.RegistryConfig) *Credentials {
+	return &Credentials{registries: registries, netrc: loadNetrc()}
+}
+
+// GetCredentials returns the global credentials, those of the environment and ~/.netrc unless
+// configured by SetCredentials
+func GetCredentials() *Credentials {
+	credentialsMutex.RLock()
+	c := credentialsInstance
+	credentialsMutex.RUnlock()
+	if c != nil {
+		return c
+	}
+
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+	if credentialsInstance == nil {
+		credentialsInstance = NewCredentials(nil)
+	}
+	return credentialsInstance
+}
+
+// SetCredentials configures the global credentials, nil resets them to those of the environment
+func SetCredentials(c *Credentials) {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+	credentialsInstance = c
+}
+
+// Authorize adds the credentials of the registry or host of a request to it, leaving requests
+// that are already authorized or have no credentials unchanged
+func (c *Credentials) Authorize(req *http.Request) {
+	if req.Header.Get("Authorization") != "" {
+		return
+	}
+	if registry := c.registry(req.URL); registry != nil {
+		token, username, password := registry.Credentials()
+		switch {
+		case token != "":
+			req.Header.Set("Authorization", "Bearer "+token)
+			return
+		case username != "" || password != "":
+			req.SetBasicAuth(username, password)
+			return
+		}
+	}
+	host := strings.ToLower(req.URL.Hostname())
+	if token := envToken(host); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return
+	}
+	for _, machine := range c.netrc {
+		if machine.host == host {
+			req.SetBasicAuth(machine.login, machine.password)
+			return
+		}
+	}
+}
+
+// NpmRegistry returns the registry serving an npm package, the one configured for its scope or
+// NpmRegistry
+func (c *Credentials) NpmRegistry(packageName string) string {
+	if scope, _, ok := strings.Cut(packageName, "/"); ok && strings.HasPrefix(scope, "@") {
+		for _, registry := range c.registries {
+			for _, s := range registry.Scopes {
+				if s == scope {
+					return strings.TrimSuffix(registry.URL, "/")
+				}
+			}
+		}
+	}
+	return NpmRegistry
+}
+
+// GitHost returns the type of a Git host, github or gitlab, for github.com, gitlab.com, the
+// hosts of the registries configured with either type and the GitHub Enterprise host of GH_HOST,
+// or an empty string for other hosts
+func (c *Credentials) GitHost(host string) string {
+	host = strings.ToLower(host)
+	switch host {
+	case "github.com":
+		return models.RegistryTypeGitHub
+	case "gitlab.com":
+		return models.RegistryTypeGitLab
+	}
+	for _, registry := range c.registries {
+		if registry.Type != models.RegistryTypeGitHub && registry.Type != models.RegistryTypeGitLab {
+			continue
+		}
+		if u, err := url.Parse(registry.URL); err == nil && strings.ToLower(u.Host) == host {
+			return registry.Type
+		}
+	}
+	if enterprise := os.Getenv("GH_HOST"); enterprise != "" && host == strings.ToLower(enterprise) {
+		return models.RegistryTypeGitHub
+	}
+	return ""
+}
+
+// repositoryAPI returns an authorized request to the API describing the repository of a Git URL
+// on a GitHub or GitLab host with credentials, and the field of its response holding the web URL
+// of the repository. Private repositories are only found through the API, their pages are not
+// found without a session.
+func (c *Credentials) repositoryAPI(gitURL string) (*http.Request, string) {
+	u, err := url.Parse(gitURL)
+	if err != nil || u.Host == "" {
+		return nil, ""
+	}
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return nil, ""
+	}
+
+	// Only hosts with credentials need the API, the credentials of the host are those of its API
+	probe := &http.Request{URL: u, Header: http.Header{}}
+	c.Authorize(probe)
+	authorization := probe.Header.Get("Authorization")
+	if authorization == "" {
+		return nil, ""
+	}
+
+	base := u.Scheme + "://" + u.Host
+	var apiURL, field string
+	switch c.GitHost(u.Host) {
+	case models.RegistryTypeGitHub:
+		apiURL, field = base+"/api/v3/repos/"+parts[0]+"/"+parts[1], "html_url"
+		if strings.EqualFold(u.Host, "github.com") {
+			apiURL = "https://api.github.com/repos/" + parts[0] + "/" + parts[1]
+		}
+	case models.RegistryTypeGitLab:
+		// Projects may be nested in subgroups, group/subgroup/project
+		apiURL, field = base+"/api/v4/projects/"+url.PathEscape(path), "web_url"
+	default:
+		return nil, ""
+	}
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, ""
+	}
+	req.Header.Set("Authorization", authorization)
+	return req, field
+}
+
+// registry returns the registry whose URL is the longest prefix of u
+func (c *Credentials) registry(u *url.URL) *models.RegistryConfig {
+	var match *models.RegistryConfig
+	length := -1
+	for i, registry := range c.registries {
+		r, err := url.Parse(registry.URL)
+		if err != nil || r.Scheme != u.Scheme || !strings.EqualFold(r.Host, u.Host) {
+			continue
+		}
+		prefix := strings.TrimSuffix(r.Path, "/")
+		if u.Path != prefix && !strings.HasPrefix(u.Path, prefix+"/") {
+			continue
+		}
+		if len(prefix) > length {
+			match, length = &c.registries[i], len(prefix)
+		}
+	}
+	return match
+}
+
+// envToken returns the token of a host from the environment variables read by the gh, glab and
+// npm CLIs
+func envToken(host string) string {
+	var names []string
+	switch host {
+	case "github.com", "api.github.com", "raw.githubusercontent.com":
+		names = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	case "gitlab.com":
+		names = []string{"GITLAB_TOKEN"}
+	case "registry.npmjs.org":
+		names = []string{"NPM_TOKEN"}
+	default:
+		if enterprise := os.Getenv("GH_HOST"); enterprise != "" && host == strings.ToLower(enterprise) {
+			names = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+		}
+	}
+	for _, name := range names {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// netrcMachine is the login of a machine in a netrc file
+type netrcMachine struct {
+	host     string
+	login    string
+	password string
+}
+
+// loadNetrc reads the machines of the netrc file at $NETRC or ~/.netrc
+func loadNetrc() []netrcMachine {
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Failed to read %s: %v", path, err)
+		}
+		return nil
+	}
+	return parseNetrc(string(data))
+}
+
+// parseNetrc parses the machines of a netrc file with both a login and a password. The default
+// entry is ignored, as credentials are only sent to the hosts they are configured for.
+func parseNetrc(data string) []netrcMachine {
+	var machines []netrcMachine
+	var machine netrcMachine
+	macro := false
+	for _, line := range strings.Split(data, "\n") {
+		// Macros run by ftp end at the first empty line
+		if macro {
+			macro = strings.TrimSpace(line) != ""
+			continue
+		}
+		fields := strings.Fields(line)
+	tokens:
+		for i := 0; i < len(fields); i++ {
+			switch fields[i] {
+			case "default":
+				// The default entry comes last, after all machines
+				return machines
+			case "macdef":
+				macro = true
+				break tokens
+			}
+			if i+1 == len(fields) {
+				break
+			}
+			switch fields[i] {
+			case "machine":
+				machine = netrcMachine{host: strings.ToLower(fields[i+1])}
+			case "login":
+				machine.login = fields[i+1]
+			case "password":
+				machine.password = fields[i+1]
+			}
+			i++
+			if machine.host != "" && machine.login != "" && machine.password != "" {
+				machines = append(machines, machine)
+				machine = netrcMachine{}
+			}
+		}
+	}
+	return machines
+}
@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Credentials", func() {
+	setenv := func(name, value string) {
+		previous, ok := os.LookupEnv(name)
+		Expect(os.Setenv(name, value)).To(Succeed())
+		DeferCleanup(func() {
+			if ok {
+				_ = os.Setenv(name, previous)
+			} else {
+				_ = os.Unsetenv(name)
+			}
+		})
+	}
+
+	authorization := func(c *Credentials, target string) string {
+		req, err := http.NewRequest(http.MethodGet, target, nil)
+		Expect(err).NotTo(HaveOccurred())
+		c.Authorize(req)
+		return req.Header.Get("Authorization")
+	}
+
+	It("should parse the machines of a netrc file", func() {
+		Expect(parseNetrc(`machine github.example.com login ci password s3cret
+machine charts.example.com
+  login deploy
+  password hunter2
+macdef init
+machine ignored.example.com login a password b
+
+machine GitLab.example.com login bot password token
+default login anonymous password guest
+machine after-default.example.com login a password b
+`)).To(Equal([]netrcMachine{
+			{host: "github.example.com", login: "ci", password: "s3cret"},
+			{host: "charts.example.com", login: "deploy", password: "hunter2"},
+			{host: "gitlab.example.com", login: "bot", password: "token"},
+		}))
+	})
+
+	It("should prefer configured registries over environment tokens and netrc", func() {
+		setenv("GH_TOKEN", "")
+		setenv("GITHUB_TOKEN", "env-token")
+		setenv("REGISTRY_PASSWORD", "expanded")
+		c := &Credentials{
+			registries: []models.RegistryConfig{
+				{URL: "https://npm.example.com", Type: models.RegistryTypeNpm, Token: "npm-token"},
+				{URL: "https://npm.example.com/private/", Type: models.RegistryTypeNpm, Username: "ci", Password: "$REGISTRY_PASSWORD"},
+			},
+			netrc: []netrcMachine{
+				{host: "github.com", login: "netrc", password: "netrc"},
+				{host: "charts.example.com", login: "deploy", password: "hunter2"},
+			},
+		}
+
+		Expect(authorization(c, "https://npm.example.com/left-pad")).To(Equal("Bearer npm-token"))
+		Expect(authorization(c, "https://npm.example.com/private/@acme%2Fui")).To(Equal("Basic Y2k6ZXhwYW5kZWQ="))
+		Expect(authorization(c, "https://npm.example.com/private-other")).To(Equal("Bearer npm-token"))
+		Expect(authorization(c, "http://npm.example.com/left-pad")).To(BeEmpty())
+		Expect(authorization(c, "https://api.github.com/repos/acme/app")).To(Equal("Bearer env-token"))
+		Expect(authorization(c, "https://charts.example.com/index.yaml")).To(Equal("Basic ZGVwbG95Omh1bnRlcjI="))
+		Expect(authorization(c, "https://example.com")).To(BeEmpty())
+	})
+
+	It("should route npm scopes to their registry", func() {
+		c := &Credentials{registries: []models.RegistryConfig{
+			{URL: "https://npm.pkg.github.com/", Type: models.RegistryTypeNpm, Scopes: []string{"@acme"}},
+		}}
+		Expect(c.NpmRegistry("@acme/ui")).To(Equal("https://npm.pkg.github.com"))
+		Expect(c.NpmRegistry("@babel/core")).To(Equal(NpmRegistry))
+		Expect(c.NpmRegistry("acme")).To(Equal(NpmRegistry))
+	})
+
+	Describe("resolving private packages", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			setenv("GHE_TOKEN", "ghe-token")
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, password, _ := r.BasicAuth()
+				switch {
+				case r.URL.Path == "/npm/@acme/ui" && user == "ci" && password == "s3cret":
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{
+						"repository": map[string]string{"url": "git+ssh://git@github.com/acme/ui.git"},
+					})
+				case r.URL.Path == "/api/v3/repos/acme/old-name" && r.Header.Get("Authorization") == "Bearer ghe-token":
+					_ = json.NewEncoder(w).Encode(map[string]string{"html_url": server.URL + "/acme/new-name"})
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			DeferCleanup(server.Close)
+
+			SetCredentials(&Credentials{registries: []models.RegistryConfig{
+				{URL: server.URL + "/npm", Type: models.RegistryTypeNpm, Scopes: []string{"@acme"}, Username: "ci", Password: "s3cret"},
+				{URL: server.URL, Type: models.RegistryTypeGitHub, Token: "$GHE_TOKEN"},
+			}})
+			DeferCleanup(SetCredentials, nil)
+		})
+
+		It("should query the registry of a private npm scope", func() {
+			gitURL, err := NewResolutionService().extractNpmGitURL(nil, "@acme/ui")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitURL).To(Equal("https://github.com/acme/ui"))
+		})
+
+		It("should resolve and validate repositories of GitHub Enterprise hosts through their API", func() {
+			host := strings.TrimPrefix(server.URL, "http://")
+			gitURL, err := NewResolutionService().extractGoGitURL(nil, host+"/acme/old-name/v2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitURL).To(Equal("https://" + host + "/acme/old-name"))
+
+			valid, finalURL, err := NewResolutionService().validateGitURL(server.URL + "/acme/old-name.git")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(valid).To(BeTrue())
+			Expect(finalURL).To(Equal(server.URL + "/acme/new-name.git"))
+
+			valid, _, err = NewResolutionService().validateGitURL(server.URL + "/acme/missing")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(valid).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,393 @@
+package dart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/types"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/languages"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky"
+)
+
+// DartASTExtractor extracts classes, mixins, extensions, Flutter widgets and imports from Dart files.
+//
+// Nodes are placed in package paths derived from pubspec.yaml (e.g. shop/presentation for
+// lib/presentation/), matching how package: imports are resolved, so that layering rules
+// such as presentation -> domain -> data can be expressed in .ARCHUNIT files.
+type DartASTExtractor struct{}
+
+// NewDartASTExtractor creates a new Dart AST extractor
+func NewDartASTExtractor() *DartASTExtractor {
+	return &DartASTExtractor{}
+}
+
+// Widget kinds recorded in the "widget" metadata of Flutter widget classes
+const (
+	WidgetStateless = "stateless"
+	WidgetStateful  = "stateful"
+	WidgetState     = "state"
+	WidgetInherited = "inherited"
+)
+
+// widgetBaseClasses maps well-known Flutter (and hooks/riverpod) base classes to widget kinds
+var widgetBaseClasses = map[string]string{
+	"StatelessWidget":        WidgetStateless,
+	"HookWidget":             WidgetStateless,
+	"ConsumerWidget":         WidgetStateless,
+	"HookConsumerWidget":     WidgetStateless,
+	"StatefulWidget":         WidgetStateful,
+	"StatefulHookWidget":     WidgetStateful,
+	"ConsumerStatefulWidget": WidgetStateful,
+	"State":                  WidgetState,
+	"ConsumerState":          WidgetState,
+	"InheritedWidget":        WidgetInherited,
+	"InheritedNotifier":      WidgetInherited,
+	"InheritedModel":         WidgetInherited,
+}
+
+// A string literal in sanitized source, whose contents have been blanked out
+const stringLiteral = `r?['"]( *)['"]`
+
+var (
+	classRe     = regexp.MustCompile(`^\s*(?:(?:abstract|base|interface|final|sealed|mixin)\s+)*class\s+([A-Za-z_$][\w$]*)`)
+	mixinRe     = regexp.MustCompile(`^\s*(?:base\s+)?mixin\s+([A-Za-z_$][\w$]*)`)
+	extensionRe = regexp.MustCompile(`^\s*extension\s+(?:([A-Za-z_$][\w$]*)\s*)?(?:<[^>]*>\s*)?on\s+([\w$.]+)`)
+	enumRe      = regexp.MustCompile(`^\s*enum\s+([A-Za-z_$][\w$]*)`)
+	methodRe    = regexp.MustCompile(`^\s*(?:(?:static|external|factory|const|@override)\s+)*(?:[\w$<>?,\[\] .]+?\s+)?(?:get\s+|set\s+)?([A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)?)\s*(?:<[^()]*>)?\s*(?:\([^;]*?)?\s*(?:async\*?|sync\*)?\s*(\{|=>)`)
+	methodOpen  = regexp.MustCompile(`^\s*(?:(?:static|external|factory|const|@override)\s+)*(?:[\w$<>?,\[\] .]+?\s+)?([A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)?)\s*(?:<[^()]*>)?\s*\([^)]*$`)
+	genericsRe  = regexp.MustCompile(`<[^<>]*>`)
+	extendsRe   = regexp.MustCompile(`\bextends\s+([\w$.]+)(?:\s*<\s*([\w$.]+))?`)
+	withRe      = regexp.MustCompile(`\bwith\s+([\w$., ]+?)\s*(?:\bimplements\b|\{|$)`)
+	implRe      = regexp.MustCompile(`\bimplements\s+([\w$., ]+?)\s*(?:\{|$)`)
+	onRe        = regexp.MustCompile(`\bon\s+([\w$., ]+?)\s*(?:\bimplements\b|\{|$)`)
+	decisionRe  = regexp.MustCompile(`\b(?:if|for|while|case|catch)\b|&&|\|\||\?\?`)
+)
+
+// keywords that look like method declarations when followed by parentheses
+var keywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"else": true, "do": true, "try": true, "assert": true, "super": true, "this": true,
+}
+
+// frame is an open brace block; node is set for blocks that declare a type or method
+type frame struct {
+	node *models.ASTNode
+}
+
+// ExtractFile extracts structure information from a Dart file
+func (e *DartASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath string, content []byte) (*types.ASTResult, error) {
+	result := types.NewASTResult(filePath, "dart")
+	now := time.Now()
+
+	original := strings.Split(string(content), "\n")
+	sanitized := strings.Split(sanitize(string(content)), "\n")
+
+	packageName := PackageOf(filePath)
+	result.PackageName = packageName
+
+	result.AddNode(&models.ASTNode{
+		FilePath:     filePath,
+		PackageName:  packageName,
+		NodeType:     models.NodeTypePackage,
+		StartLine:    1,
+		EndLine:      len(original),
+		LineCount:    len(original),
+		LastModified: now,
+	})
+
+	for _, imp := range ParseImports(filePath, content) {
+		result.AddLibrary(&models.LibraryRelationship{
+			LineNo:           imp.Line,
+			RelationshipType: string(models.RelationshipImport),
+			Text: fmt.Sprintf("%s (pkg=%s;class=%s;method=;framework=%s)",
+				imp.Text, imp.Package, imp.File, imp.Framework),
+		})
+	}
+
+	var (
+		stack      []frame
+		pending    *models.ASTNode
+		parens     int
+		complexity = map[*models.ASTNode]int{}
+	)
+
+	// innermost returns the innermost open type or method node
+	innermost := func(nodeType models.NodeType) *models.ASTNode {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if node := stack[i].node; node != nil && node.NodeType == nodeType {
+				return node
+			}
+		}
+		return nil
+	}
+	// Methods are only declared at the top level or directly inside a type
+	inDeclarationScope := func() bool {
+		if len(stack) == 0 {
+			return true
+		}
+		top := stack[len(stack)-1].node
+		return top != nil && top.NodeType == models.NodeTypeType
+	}
+	finish := func(node *models.ASTNode, endLine int) {
+		node.EndLine = endLine
+		node.LineCount = endLine - node.StartLine + 1
+		if node.NodeType == models.NodeTypeMethod {
+			node.CyclomaticComplexity = 1 + complexity[node]
+		}
+		result.AddNode(node)
+	}
+
+	for i, line := range sanitized {
+		lineNo := i + 1
+		newNode := func(nodeType models.NodeType, typ, method string) *models.ASTNode {
+			name := typ
+			if nodeType == models.NodeTypeMethod {
+				name = method
+			}
+			return &models.ASTNode{
+				FilePath:     filePath,
+				PackageName:  packageName,
+				TypeName:     typ,
+				MethodName:   method,
+				NodeType:     nodeType,
+				StartLine:    lineNo,
+				EndLine:      lineNo,
+				IsPrivate:    strings.HasPrefix(name, "_"),
+				LastModified: now,
+			}
+		}
+
+		if inDeclarationScope() && pending == nil {
+			switch {
+			case classRe.MatchString(line):
+				node := newNode(models.NodeTypeType, classRe.FindStringSubmatch(line)[1], "")
+				node.Metatdata = typeMetadata("class", header(sanitized, i))
+				pending = node
+			case mixinRe.MatchString(line):
+				node := newNode(models.NodeTypeType, mixinRe.FindStringSubmatch(line)[1], "")
+				node.Metatdata = typeMetadata("mixin", header(sanitized, i))
+				pending = node
+			case extensionRe.MatchString(line):
+				m := extensionRe.FindStringSubmatch(line)
+				name := m[1]
+				if name == "" {
+					name = "on " + m[2]
+				}
+				node := newNode(models.NodeTypeType, name, "")
+				node.Metatdata = map[string]string{"kind": "extension", "on": m[2]}
+				pending = node
+			case enumRe.MatchString(line):
+				node := newNode(models.NodeTypeType, enumRe.FindStringSubmatch(line)[1], "")
+				node.Metatdata = map[string]string{"kind": "enum"}
+				pending = node
+			case methodRe.MatchString(line):
+				m := methodRe.FindStringSubmatch(line)
+				if keywords[m[1]] {
+					break
+				}
+				typeName := ""
+				if typ := innermost(models.NodeTypeType); typ != nil {
+					typeName = typ.TypeName
+				}
+				node := newNode(models.NodeTypeMethod, typeName, m[1])
+				if m[2] == "=>" {
+					// Expression bodies end with the statement
+					finish(node, statementEnd(sanitized, i))
+				} else {
+					pending = node
+				}
+			case methodOpen.MatchString(line):
+				// Parameters continue on the following lines
+				m := methodOpen.FindStringSubmatch(line)
+				if keywords[m[1]] {
+					break
+				}
+				typeName := ""
+				if typ := innermost(models.NodeTypeType); typ != nil {
+					typeName = typ.TypeName
+				}
+				pending = newNode(models.NodeTypeMethod, typeName, m[1])
+			}
+		}
+
+		// Attribute decision points to the innermost method
+		if decisions := len(decisionRe.FindAllString(line, -1)); decisions > 0 {
+			if method := innermost(models.NodeTypeMethod); method != nil {
+				complexity[method] += decisions
+			}
+		}
+
+		for _, ch := range line {
+			switch ch {
+			case '(':
+				parens++
+			case ')':
+				parens--
+			case '{':
+				// Braces inside parameter lists delimit named parameters, not the body
+				if parens > 0 {
+					stack = append(stack, frame{})
+					continue
+				}
+				stack = append(stack, frame{node: pending})
+				pending = nil
+			case '}':
+				if len(stack) == 0 {
+					continue
+				}
+				closed := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if closed.node != nil {
+					finish(closed.node, lineNo)
+				}
+			case ';':
+				// Abstract methods, redirecting constructors and mixin applications have no body
+				if pending != nil && parens == 0 {
+					finish(pending, lineNo)
+					pending = nil
+				}
+			}
+		}
+	}
+
+	// Unterminated blocks extend to the end of the file
+	for i := len(stack) - 1; i >= 0; i-- {
+		if node := stack[i].node; node != nil {
+			finish(node, len(original))
+		}
+	}
+
+	return result, nil
+}
+
+// header returns the declaration starting at line i up to its opening brace
+func header(lines []string, i int) string {
+	var sb strings.Builder
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if idx := strings.Index(line, "{"); idx >= 0 {
+			sb.WriteString(line[:idx])
+			break
+		}
+		sb.WriteString(line)
+		sb.WriteString(" ")
+	}
+	return sb.String()
+}
+
+// typeMetadata records the supertypes of a class or mixin declaration and its widget kind
+func typeMetadata(kind, header string) map[string]string {
+	metadata := map[string]string{"kind": kind}
+
+	if m := extendsRe.FindStringSubmatch(header); m != nil {
+		metadata["extends"] = m[1]
+		if widget, ok := widgetBaseClasses[m[1]]; ok {
+			metadata["widget"] = widget
+			if widget == WidgetState && m[2] != "" {
+				metadata["state_of"] = m[2]
+			}
+		}
+	}
+
+	// Strip type arguments so that lists of supertypes can be split on commas
+	for genericsRe.MatchString(header) {
+		header = genericsRe.ReplaceAllString(header, "")
+	}
+	for key, re := range map[string]*regexp.Regexp{"with": withRe, "implements": implRe, "on": onRe} {
+		if m := re.FindStringSubmatch(header); m != nil {
+			metadata[key] = strings.Join(strings.Fields(strings.ReplaceAll(m[1], ",", " ")), ",")
+		}
+	}
+	return metadata
+}
+
+// statementEnd returns the line on which the statement starting at line i ends
+func statementEnd(lines []string, i int) int {
+	for j := i; j < len(lines); j++ {
+		if strings.Contains(lines[j], ";") {
+			return j + 1
+		}
+	}
+	return len(lines)
+}
+
+// sanitize blanks out comments and the contents of string literals while preserving
+// line and column positions, so that braces and keywords inside them are ignored
+func sanitize(content string) string {
+	out := []byte(content)
+	blank := func(i int) {
+		if out[i] != '\n' {
+			out[i] = ' '
+		}
+	}
+
+	for i := 0; i < len(out); i++ {
+		switch {
+		case strings.HasPrefix(content[i:], "//"):
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				blank(i)
+			}
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				blank(i)
+			}
+			i--
+		case strings.HasPrefix(content[i:], `'''`) || strings.HasPrefix(content[i:], `"""`):
+			quote := content[i : i+3]
+			end := strings.Index(content[i+3:], quote)
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 3 + end
+			}
+			for j := i + 3; j < stop; j++ {
+				blank(j)
+			}
+			i = stop + 2
+		case content[i] == '\'' || content[i] == '"':
+			quote := content[i]
+			raw := i > 0 && content[i-1] == 'r'
+			j := i + 1
+			for ; j < len(out) && content[j] != quote && content[j] != '\n'; j++ {
+				if !raw && content[j] == '\\' && j+1 < len(out) && content[j+1] != '\n' {
+					blank(j)
+					j++
+				}
+				blank(j)
+			}
+			i = j
+		}
+	}
+	return string(out)
+}
+
+// dartAnalyzerAdapter adapts the DartASTExtractor to the languages.ASTAnalyzer interface
+type dartAnalyzerAdapter struct {
+	extractor *DartASTExtractor
+}
+
+func (a *dartAnalyzerAdapter) AnalyzeFile(task interface{}, filepath string, content []byte) (interface{}, error) {
+	clickyTask, ok := task.(*clicky.Task)
+	if !ok {
+		return nil, nil
+	}
+
+	// Delegate to the generic analyzer, which stores nodes and library relationships
+	genericAnalyzer := languages.GetGenericAnalyzerAdapter()
+	return genericAnalyzer.AnalyzeFile(clickyTask, filepath, content)
+}
+
+func init() {
+	dartExtractor := NewDartASTExtractor()
+	analysis.DefaultExtractorRegistry.Register("dart", dartExtractor)
+	languages.SetAnalyzer("dart", &dartAnalyzerAdapter{extractor: dartExtractor})
+}
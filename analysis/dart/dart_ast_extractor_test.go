@@ -0,0 +1,159 @@
+package dart
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/types"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Dart AST Extractor", func() {
+	var (
+		extractor *DartASTExtractor
+		astCache  *cache.ASTCache
+	)
+
+	BeforeEach(func() {
+		extractor = NewDartASTExtractor()
+		astCache = cache.MustGetASTCache()
+	})
+
+	extract := func(path string) *types.ASTResult {
+		testFile := filepath.Join("testdata", "shop", "lib", path)
+		content, err := os.ReadFile(testFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := extractor.ExtractFile(astCache, testFile, content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		return result
+	}
+
+	find := func(result *types.ASTResult, nodeType models.NodeType, typeName, methodName string) *models.ASTNode {
+		for _, node := range result.Nodes {
+			if node.NodeType == nodeType && node.TypeName == typeName && node.MethodName == methodName {
+				return node
+			}
+		}
+		return nil
+	}
+
+	Context("when extracting the domain layer", func() {
+		var result *types.ASTResult
+
+		BeforeEach(func() {
+			result = extract("domain/cart.dart")
+		})
+
+		It("should place nodes in the pubspec package path", func() {
+			Expect(result.PackageName).To(Equal("shop/domain"))
+			for _, node := range result.Nodes {
+				Expect(node.PackageName).To(Equal("shop/domain"))
+			}
+		})
+
+		It("should extract classes with their methods", func() {
+			cart := find(result, models.NodeTypeType, "Cart", "")
+			Expect(cart).NotTo(BeNil())
+			Expect(cart.StartLine).To(Equal(5))
+			Expect(cart.EndLine).To(Equal(19))
+			Expect(cart.Metatdata).To(HaveKeyWithValue("kind", "class"))
+
+			total := find(result, models.NodeTypeMethod, "Cart", "total")
+			Expect(total).NotTo(BeNil())
+			Expect(total.StartLine).To(Equal(12))
+			Expect(total.EndLine).To(Equal(18))
+			Expect(total.CyclomaticComplexity).To(Equal(2))
+
+			Expect(find(result, models.NodeTypeMethod, "Cart", "count")).NotTo(BeNil())
+			Expect(find(result, models.NodeTypeType, "CartRepository", "")).NotTo(BeNil())
+		})
+
+		It("should extract mixins and extensions", func() {
+			mixin := find(result, models.NodeTypeType, "Discountable", "")
+			Expect(mixin).NotTo(BeNil())
+			Expect(mixin.Metatdata).To(HaveKeyWithValue("kind", "mixin"))
+			Expect(mixin.Metatdata).To(HaveKeyWithValue("on", "Cart"))
+
+			extension := find(result, models.NodeTypeType, "CartFormatting", "")
+			Expect(extension).NotTo(BeNil())
+			Expect(extension.Metatdata).To(HaveKeyWithValue("kind", "extension"))
+			Expect(find(result, models.NodeTypeMethod, "CartFormatting", "describe")).NotTo(BeNil())
+		})
+	})
+
+	Context("when extracting Flutter widgets", func() {
+		var result *types.ASTResult
+
+		BeforeEach(func() {
+			result = extract("presentation/cart_page.dart")
+		})
+
+		It("should classify widgets by their base class", func() {
+			page := find(result, models.NodeTypeType, "CartPage", "")
+			Expect(page).NotTo(BeNil())
+			Expect(page.Metatdata).To(HaveKeyWithValue("widget", WidgetStateful))
+
+			state := find(result, models.NodeTypeType, "_CartPageState", "")
+			Expect(state).NotTo(BeNil())
+			Expect(state.IsPrivate).To(BeTrue())
+			Expect(state.Metatdata).To(HaveKeyWithValue("widget", WidgetState))
+			Expect(state.Metatdata).To(HaveKeyWithValue("state_of", "CartPage"))
+			Expect(state.Metatdata).To(HaveKeyWithValue("with", "SingleTickerProviderStateMixin"))
+
+			tile := find(result, models.NodeTypeType, "CartItemTile", "")
+			Expect(tile).NotTo(BeNil())
+			Expect(tile.Metatdata).To(HaveKeyWithValue("widget", WidgetStateless))
+		})
+
+		It("should extract build methods and top-level functions", func() {
+			build := find(result, models.NodeTypeMethod, "_CartPageState", "build")
+			Expect(build).NotTo(BeNil())
+			Expect(build.StartLine).To(Equal(29))
+			Expect(build.EndLine).To(Equal(38))
+
+			initState := find(result, models.NodeTypeMethod, "_CartPageState", "initState")
+			Expect(initState).NotTo(BeNil())
+			Expect(initState.IsPrivate).To(BeFalse())
+
+			Expect(find(result, models.NodeTypeMethod, "CartItemTile", "build")).NotTo(BeNil())
+			Expect(find(result, models.NodeTypeMethod, "", "openCart")).NotTo(BeNil())
+		})
+
+		It("should record imports as library relationships", func() {
+			var texts []string
+			for _, lib := range result.Libraries {
+				Expect(lib.RelationshipType).To(Equal(string(models.RelationshipImport)))
+				texts = append(texts, lib.Text)
+			}
+			Expect(texts).To(ConsistOf(
+				ContainSubstring("(pkg=flutter;class=material;method=;framework=flutter)"),
+				ContainSubstring("(pkg=shop/domain;class=cart;method=;framework=local)"),
+				ContainSubstring("(pkg=shop/data;class=cart_repository;method=;framework=local)"),
+			))
+		})
+	})
+
+	Context("when parsing imports", func() {
+		It("should resolve relative, package and SDK imports", func() {
+			path := filepath.Join("testdata", "shop", "lib", "data", "cart_repository.dart")
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			imports := ParseImports(path, content)
+			Expect(imports).To(HaveLen(3))
+			Expect(imports[0].Package).To(Equal("dart:convert"))
+			Expect(imports[0].Framework).To(Equal("dart"))
+			Expect(imports[1].Package).To(Equal("shared_preferences"))
+			Expect(imports[1].Framework).To(Equal("pub"))
+			Expect(imports[2].Package).To(Equal("shop/domain"))
+			Expect(imports[2].Framework).To(Equal("local"))
+			Expect(imports[2].Line).To(Equal(5))
+		})
+	})
+})
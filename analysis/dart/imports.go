@@ -0,0 +1,164 @@
+package dart
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Import is an import, export or part directive in a Dart file
+type Import struct {
+	URI       string // URI as written, e.g. package:shop/data/cart_repository.dart
+	Package   string // Package path used for rule matching, e.g. shop/data
+	File      string // File name without the .dart extension
+	Framework string // dart, flutter, pub or local
+	Line      int
+	Text      string
+}
+
+var importRe = regexp.MustCompile(`^\s*(?:import|export|part)\s+` + stringLiteral)
+
+// ParseImports returns the imports of a Dart file, resolving package and relative URIs
+// to the same package paths used for the nodes of the imported files
+func ParseImports(filePath string, content []byte) []Import {
+	original := strings.Split(string(content), "\n")
+	sanitized := strings.Split(sanitize(string(content)), "\n")
+	pubspec := findPubspec(filepath.Dir(filePath))
+
+	var imports []Import
+	for i, line := range sanitized {
+		m := importRe.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		uri := original[i][m[2]:m[3]]
+		imp := Import{
+			URI:  uri,
+			File: strings.TrimSuffix(filepath.Base(uri), ".dart"),
+			Line: i + 1,
+			Text: strings.TrimSpace(original[i]),
+		}
+
+		switch {
+		case strings.HasPrefix(uri, "dart:"):
+			imp.Package, imp.File, imp.Framework = uri, "", "dart"
+		case strings.HasPrefix(uri, "package:"):
+			imp.Package = packageDir(strings.TrimPrefix(uri, "package:"))
+			root := strings.SplitN(imp.Package, "/", 2)[0]
+			switch {
+			case pubspec != nil && root == pubspec.name:
+				imp.Framework = "local"
+			case root == "flutter" || strings.HasPrefix(root, "flutter_"):
+				imp.Framework = "flutter"
+			default:
+				imp.Framework = "pub"
+			}
+		default:
+			resolved := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(uri))
+			imp.Package = packageName(filepath.Dir(resolved), pubspec)
+			imp.Framework = "local"
+		}
+		imports = append(imports, imp)
+	}
+	return imports
+}
+
+// PackageOf returns the package path of the nodes extracted from a Dart file
+func PackageOf(filePath string) string {
+	dir := filepath.Dir(filePath)
+	return packageName(dir, findPubspec(dir))
+}
+
+// packageDir strips the file name from a package URI path: shop/data/repo.dart -> shop/data
+func packageDir(uriPath string) string {
+	if i := strings.LastIndex(uriPath, "/"); i >= 0 {
+		return uriPath[:i]
+	}
+	return uriPath
+}
+
+// pubspec is the root of a Dart package
+type pubspec struct {
+	dir  string
+	name string
+}
+
+var (
+	pubspecMu    sync.Mutex
+	pubspecCache = map[string]*pubspec{}
+)
+
+// findPubspec returns the nearest pubspec.yaml at or above dir, or nil
+func findPubspec(dir string) *pubspec {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	pubspecMu.Lock()
+	defer pubspecMu.Unlock()
+
+	var visited []string
+	var found *pubspec
+	for current := abs; ; current = filepath.Dir(current) {
+		if cached, ok := pubspecCache[current]; ok {
+			found = cached
+			break
+		}
+		visited = append(visited, current)
+		if name := readPubspecName(filepath.Join(current, "pubspec.yaml")); name != "" {
+			found = &pubspec{dir: current, name: name}
+			break
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	for _, dir := range visited {
+		pubspecCache[dir] = found
+	}
+	return found
+}
+
+// readPubspecName reads the top level name field of a pubspec.yaml
+func readPubspecName(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "name:") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "name:")), `'"`)
+		}
+	}
+	return ""
+}
+
+// packageName returns the package path of a directory: the pub package name followed by the
+// directory relative to lib/ (or to the package root for bin/, test/, ...), e.g. shop/data
+func packageName(dir string, pub *pubspec) string {
+	if pub == nil {
+		return filepath.Base(dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Base(dir)
+	}
+	rel, err := filepath.Rel(pub.dir, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.Base(dir)
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "lib" || rel == "." {
+		return pub.name
+	}
+	rel = strings.TrimPrefix(rel, "lib/")
+	return pub.name + "/" + rel
+}
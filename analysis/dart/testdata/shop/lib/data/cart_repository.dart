@@ -0,0 +1,25 @@
+import 'dart:convert';
+
+import 'package:shared_preferences/shared_preferences.dart';
+
+import '../domain/cart.dart';
+
+class PreferencesCartRepository implements CartRepository {
+  static const _key = 'cart';
+
+  @override
+  Future<Cart> load() async {
+    final prefs = await SharedPreferences.getInstance();
+    final raw = prefs.getString(_key) ?? '[]';
+    final items = (jsonDecode(raw) as List)
+        .map((e) => CartItem(name: e['name'], price: e['price']))
+        .toList();
+    return Cart(items);
+  }
+
+  @override
+  Future<void> save(Cart cart) async {
+    final prefs = await SharedPreferences.getInstance();
+    await prefs.setString(_key, jsonEncode(cart.items.map((i) => {'name': i.name}).toList()));
+  }
+}
@@ -0,0 +1,40 @@
+import 'package:meta/meta.dart';
+
+/// A shopping cart, independent of how it is stored or shown
+@immutable
+class Cart {
+  final List<CartItem> items;
+
+  const Cart(this.items);
+
+  int get count => items.length;
+
+  double total({double discount = 0}) {
+    var sum = 0.0;
+    for (final item in items) {
+      sum += item.price * item.quantity;
+    }
+    return discount > 0 ? sum * (1 - discount) : sum;
+  }
+}
+
+class CartItem {
+  final String name;
+  final double price;
+  final int quantity;
+
+  const CartItem({required this.name, required this.price, this.quantity = 1});
+}
+
+abstract interface class CartRepository {
+  Future<Cart> load();
+  Future<void> save(Cart cart);
+}
+
+mixin Discountable on Cart {
+  double get discount => items.length > 10 ? 0.1 : 0;
+}
+
+extension CartFormatting on Cart {
+  String describe() => '$count items: ${total()}';
+}
@@ -0,0 +1,57 @@
+import 'package:flutter/material.dart';
+import 'package:shop/domain/cart.dart';
+// Layering violation: presentation must go through the domain layer
+import 'package:shop/data/cart_repository.dart';
+
+class CartPage extends StatefulWidget {
+  const CartPage({super.key, required this.repository});
+
+  final CartRepository repository;
+
+  @override
+  State<CartPage> createState() => _CartPageState();
+}
+
+class _CartPageState extends State<CartPage> with SingleTickerProviderStateMixin {
+  Cart _cart = const Cart([]);
+
+  @override
+  void initState() {
+    super.initState();
+    widget.repository.load().then((cart) {
+      if (mounted) {
+        setState(() => _cart = cart);
+      }
+    });
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(title: Text('Cart (${_cart.count})')),
+      body: ListView(
+        children: [
+          for (final item in _cart.items) CartItemTile(item: item),
+        ],
+      ),
+    );
+  }
+}
+
+class CartItemTile extends StatelessWidget {
+  const CartItemTile({super.key, required this.item});
+
+  final CartItem item;
+
+  @override
+  Widget build(BuildContext context) => ListTile(
+        title: Text(item.name),
+        trailing: Text('${item.quantity} x ${item.price}'),
+      );
+}
+
+void openCart(BuildContext context, CartRepository repository) {
+  Navigator.of(context).push(
+    MaterialPageRoute(builder: (_) => CartPage(repository: repository)),
+  );
+}
@@ -0,0 +1,576 @@
+package dependencies
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var bazelFiles = []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel", "WORKSPACE.bzlmod"}
+
+func init() {
+	analysis.RegisterDependencyScanner(NewBazelDependencyScanner())
+}
+
+// BazelDependencyScanner scans the dependencies of Bazel modules and workspaces without running
+// Bazel: the bazel_dep of MODULE.bazel with their overrides, and the http_archive, git_repository
+// and go_repository rules of WORKSPACE files and of the .bzl files they load from the repository
+type BazelDependencyScanner struct {
+	*analysis.BaseDependencyScanner
+	resolver *analysis.ResolutionService
+}
+
+// NewBazelDependencyScanner creates a new Bazel dependency scanner
+func NewBazelDependencyScanner() *BazelDependencyScanner {
+	return &BazelDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("bazel", bazelFiles),
+	}
+}
+
+// NewBazelDependencyScannerWithResolver creates a new Bazel dependency scanner resolving the Git
+// URLs of modules from the Bazel Central Registry and of Go repositories
+func NewBazelDependencyScannerWithResolver(resolver *analysis.ResolutionService) *BazelDependencyScanner {
+	return &BazelDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("bazel", bazelFiles),
+		resolver:              resolver,
+	}
+}
+
+// ScanFile scans MODULE.bazel or a WORKSPACE file and extracts dependencies
+func (s *BazelDependencyScanner) ScanFile(ctx *models.ScanContext, filePath string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning Bazel dependencies from %s", filePath)
+
+	scan := &bazelScan{scanner: s, ctx: ctx, root: filepath.Dir(filePath), visited: make(map[string]bool)}
+	if err := scan.file(filePath, content); err != nil {
+		return nil, err
+	}
+
+	ctx.Debugf("Found %d Bazel dependencies in %s", len(scan.dependencies), filePath)
+	return scan.dependencies, nil
+}
+
+// bazelScan tracks the .bzl files visited while scanning a module or workspace file
+type bazelScan struct {
+	scanner      *BazelDependencyScanner
+	ctx          *models.ScanContext
+	root         string // Directory of the workspace, which // labels are relative to
+	visited      map[string]bool
+	dependencies []*models.Dependency
+}
+
+func (b *bazelScan) file(filePath string, content []byte) error {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		if b.visited[abs] {
+			return nil
+		}
+		b.visited[abs] = true
+	}
+
+	calls, err := parseStarlarkCalls(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	scanRoot := ""
+	if b.ctx != nil {
+		scanRoot = b.ctx.ScanRoot
+	}
+	relPath := makeRelativePath(filePath, scanRoot)
+
+	// Overrides of MODULE.bazel replace the version the registry resolves a module to
+	overrides := make(map[string]starlarkCall)
+	for _, call := range calls {
+		switch call.function {
+		case "git_override", "single_version_override", "archive_override", "local_path_override":
+			overrides[call.strings["module_name"]] = call
+		}
+	}
+
+	for _, call := range calls {
+		var dep *models.Dependency
+		switch call.function {
+		case "load":
+			if len(call.positional) > 0 {
+				if err := b.load(filePath, call.positional[0]); err != nil {
+					return err
+				}
+			}
+			continue
+		case "bazel_dep":
+			override, ok := overrides[call.strings["name"]]
+			if ok && override.function == "local_path_override" {
+				continue // Part of the repository
+			}
+			dep = b.scanner.moduleDependency(b.ctx, call, override)
+		case "http_archive":
+			dep = archiveDependency(call)
+		case "git_repository", "new_git_repository":
+			dep = &models.Dependency{
+				Name:    call.strings["name"],
+				Version: firstNonEmpty(call.strings["tag"], call.strings["commit"], call.strings["branch"]),
+				Type:    models.DependencyTypeBazel,
+				Git:     gitRemoteURL(call.strings["remote"]),
+			}
+		case "go_repository":
+			dep = b.scanner.goDependency(b.ctx, call.strings["importpath"],
+				firstNonEmpty(call.strings["version"], call.strings["tag"], call.strings["commit"]), call.strings["remote"])
+		default:
+			// Modules required by the go_deps extension of Gazelle, go_deps.module(path = ..., version = ...)
+			if strings.HasSuffix(call.function, ".module") && call.strings["path"] != "" {
+				dep = b.scanner.goDependency(b.ctx, call.strings["path"], call.strings["version"], "")
+			}
+		}
+		if dep == nil || dep.Name == "" {
+			continue
+		}
+		dep.Source = fmt.Sprintf("%s:%d", relPath, call.line)
+		if b.ctx.Matches(dep) {
+			b.dependencies = append(b.dependencies, dep)
+		}
+	}
+	return nil
+}
+
+// load scans a .bzl file of the repository loaded by a file, e.g. //bazel:deps.bzl, as workspaces
+// often declare their repositories in macros. Files of other repositories, @repo//..., are skipped.
+func (b *bazelScan) load(from, label string) error {
+	var path string
+	switch {
+	case strings.HasPrefix(label, "//"), strings.HasPrefix(label, "@//"):
+		pkg, name, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(label, "@"), "//"), ":")
+		path = filepath.Join(b.root, filepath.FromSlash(pkg), filepath.FromSlash(name))
+	case strings.HasPrefix(label, ":"):
+		path = filepath.Join(filepath.Dir(from), filepath.FromSlash(label[1:]))
+	default:
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		b.ctx.Debugf("Skipping %s loaded by %s: %v", label, from, err)
+		return nil
+	}
+	return b.file(path, content)
+}
+
+// moduleDependency returns the dependency of a bazel_dep, with the version and repository of its
+// override
+func (s *BazelDependencyScanner) moduleDependency(ctx *models.ScanContext, call, override starlarkCall) *models.Dependency {
+	dep := &models.Dependency{
+		Name:    call.strings["name"],
+		Version: call.strings["version"],
+		Type:    models.DependencyTypeBazel,
+	}
+
+	switch override.function {
+	case "git_override":
+		dep.Git = gitRemoteURL(override.strings["remote"])
+		dep.Version = firstNonEmpty(override.strings["tag"], override.strings["commit"], dep.Version)
+	case "single_version_override":
+		dep.Version = firstNonEmpty(override.strings["version"], dep.Version)
+	case "archive_override":
+		archive := archiveDependency(override)
+		dep.Git = archive.Git
+		dep.Version = firstNonEmpty(archive.Version, dep.Version)
+	}
+
+	if dep.Git == "" && s.resolver != nil {
+		if gitURL, err := s.resolver.ResolveGitURL(ctx, dep.Name, "bazel"); err == nil && gitURL != "" {
+			dep.Git = gitURL
+		}
+	}
+	return dep
+}
+
+// goDependency returns the dependency of a Go module fetched by Gazelle
+func (s *BazelDependencyScanner) goDependency(ctx *models.ScanContext, importPath, version, remote string) *models.Dependency {
+	dep := &models.Dependency{
+		Name:    importPath,
+		Version: version,
+		Type:    models.DependencyTypeGo,
+		Git:     gitRemoteURL(remote),
+	}
+	if dep.Git == "" && s.resolver != nil && importPath != "" {
+		if gitURL, err := s.resolver.ResolveGitURL(ctx, importPath, "go"); err == nil && gitURL != "" {
+			dep.Git = gitURL
+		}
+	}
+	return dep
+}
+
+// githubArchive matches the archives of GitHub repositories, of a ref or attached to a release,
+// including those of mirrors such as https://mirror.bazel.build/github.com/...
+var githubArchive = regexp.MustCompile(`github\.com/([\w.-]+)/([\w.-]+)/(?:archive/(?:refs/(?:tags|heads)/)?(.+?)\.(?:tar\.gz|tgz|tar\.bz2|tar\.xz|tar|zip)$|releases/download/([^/]+)/)`)
+
+// archiveVersion matches the version at the end of an archive name or prefix, e.g. protobuf-21.7
+var archiveVersion = regexp.MustCompile(`[-_](v?\d+(?:\.\d+)+[\w.+-]*)$`)
+
+// archiveDependency returns the dependency of an http_archive or archive_override, with the
+// repository and version of GitHub archives, or the version found in strip_prefix or in the name
+// of the archive
+func archiveDependency(call starlarkCall) *models.Dependency {
+	dep := &models.Dependency{Name: call.strings["name"], Type: models.DependencyTypeBazel}
+	if dep.Name == "" {
+		dep.Name = call.strings["module_name"]
+	}
+
+	urls := call.lists["urls"]
+	if url := call.strings["url"]; url != "" {
+		urls = append([]string{url}, urls...)
+	}
+	for _, url := range urls {
+		if m := githubArchive.FindStringSubmatch(url); m != nil {
+			dep.Git = fmt.Sprintf("https://github.com/%s/%s", m[1], strings.TrimSuffix(m[2], ".git"))
+			dep.Version = firstNonEmpty(m[3], m[4])
+			return dep
+		}
+	}
+
+	names := []string{call.strings["strip_prefix"]}
+	for _, url := range urls {
+		name := url[strings.LastIndex(url, "/")+1:]
+		for _, ext := range []string{".tar.gz", ".tgz", ".tar.bz2", ".tar.xz", ".tar", ".zip", ".jar"} {
+			name = strings.TrimSuffix(name, ext)
+		}
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if m := archiveVersion.FindStringSubmatch(strings.TrimSuffix(name, "/")); m != nil {
+			dep.Version = m[1]
+			break
+		}
+	}
+	return dep
+}
+
+// gitRemoteURL returns the https URL of a Git remote such as git@github.com:user/repo.git
+func gitRemoteURL(remote string) string {
+	if remote == "" {
+		return ""
+	}
+	if gitURL, _ := analysis.NpmGitURL(remote); gitURL != "" {
+		return gitURL
+	}
+	return strings.TrimSuffix(remote, ".git")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// starlarkCall is a function call of a Starlark file, with the arguments whose value is a string,
+// a list of strings or an identifier, other arguments are not kept
+type starlarkCall struct {
+	function   string // e.g. bazel_dep, or go_deps.module for methods
+	line       int
+	positional []string            // Strings and identifiers, e.g. the rule of maybe(http_archive, ...)
+	strings    map[string]string   // Keyword arguments with a string value, or an identifier such as True
+	lists      map[string][]string // Keyword arguments with a list of strings
+}
+
+// parseStarlarkCalls returns the function calls of a Starlark file in order, including those nested
+// in arguments or in the bodies of functions. Variables assigned a string are substituted in the
+// arguments following them, e.g. version = RULES_GO_VERSION.
+func parseStarlarkCalls(content []byte) ([]starlarkCall, error) {
+	tokens, err := tokenizeStarlark(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &starlarkParser{tokens: tokens, vars: make(map[string]string)}
+	var calls []starlarkCall
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch token.kind {
+		case '(', '[', '{':
+			depth++
+			continue
+		case ')', ']', '}':
+			depth--
+			continue
+		case 'i':
+		default:
+			continue
+		}
+		if i > 0 && (tokens[i-1].kind == '.' || tokens[i-1].text == "def") {
+			continue // Methods of other expressions and function definitions
+		}
+
+		// Variables assigned a string, NAME = "value"
+		if depth == 0 && p.peek(i+1).kind == '=' && p.peek(i+2).kind != '=' {
+			end := i + 2
+			for end < len(tokens) && tokens[end].kind != 'n' {
+				end++
+			}
+			if value, ok := p.str(tokens[i+2 : end]); ok {
+				p.vars[token.text] = value
+			}
+			continue
+		}
+
+		// Calls of functions or methods, name(...) or name.method(...)
+		name, j := token.text, i+1
+		for p.peek(j).kind == '.' && p.peek(j+1).kind == 'i' {
+			name += "." + tokens[j+1].text
+			j += 2
+		}
+		if p.peek(j).kind != '(' {
+			continue
+		}
+		call, err := p.call(name, token.line, j)
+		if err != nil {
+			return nil, err
+		}
+		// maybe(http_archive, name = ...) only declares repositories not declared yet
+		if call.function == "maybe" && len(call.positional) > 0 {
+			call.function, call.positional = call.positional[0], call.positional[1:]
+		}
+		calls = append(calls, call)
+		i = j - 1 // Continue at the opening parenthesis, to find the calls nested in arguments
+	}
+	return calls, nil
+}
+
+// starlarkToken is a token of a Starlark file, strings are unquoted
+type starlarkToken struct {
+	kind byte // 'i' identifier or number, 's' string, 'n' newline or a punctuation character
+	text string
+	line int
+}
+
+type starlarkParser struct {
+	tokens []starlarkToken
+	vars   map[string]string
+}
+
+func (p *starlarkParser) peek(pos int) starlarkToken {
+	if pos < len(p.tokens) {
+		return p.tokens[pos]
+	}
+	return starlarkToken{kind: 0}
+}
+
+// call parses the arguments of a call from its opening parenthesis at open
+func (p *starlarkParser) call(name string, line, open int) (starlarkCall, error) {
+	call := starlarkCall{function: name, line: line, strings: make(map[string]string), lists: make(map[string][]string)}
+	var arg []starlarkToken
+	depth := 0
+	for i := open + 1; i < len(p.tokens); i++ {
+		token := p.tokens[i]
+		switch token.kind {
+		case 'n':
+			continue
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+				break
+			}
+			if token.kind != ')' {
+				return call, fmt.Errorf("line %d: unexpected %q", token.line, token.text)
+			}
+			p.argument(&call, arg)
+			return call, nil
+		case ',':
+			if depth == 0 {
+				p.argument(&call, arg)
+				arg = nil
+				continue
+			}
+		}
+		arg = append(arg, token)
+	}
+	return call, fmt.Errorf("line %d: %s( is not closed", line, name)
+}
+
+// argument adds an argument of a call, name = value or a positional value
+func (p *starlarkParser) argument(call *starlarkCall, tokens []starlarkToken) {
+	if len(tokens) == 0 {
+		return
+	}
+	if len(tokens) > 2 && tokens[0].kind == 'i' && tokens[1].kind == '=' {
+		name, value := tokens[0].text, tokens[2:]
+		if s, ok := p.str(value); ok {
+			call.strings[name] = s
+		} else if list, ok := p.list(value); ok {
+			call.lists[name] = list
+		} else if len(value) == 1 && value[0].kind == 'i' {
+			call.strings[name] = value[0].text
+		}
+		return
+	}
+	if s, ok := p.str(tokens); ok {
+		call.positional = append(call.positional, s)
+	} else if len(tokens) == 1 && tokens[0].kind == 'i' {
+		call.positional = append(call.positional, tokens[0].text)
+	}
+}
+
+// str evaluates an expression of strings and variables concatenated with +, or formatted with
+// %s, e.g. "rules_go-%s" % VERSION or "%s/%s" % (NAME, VERSION)
+func (p *starlarkParser) str(tokens []starlarkToken) (string, bool) {
+	if format, ok := splitStarlark(tokens, '%'); ok && len(format) == 2 {
+		pattern, ok := p.str(format[0])
+		if !ok {
+			return "", false
+		}
+		values := [][]starlarkToken{format[1]}
+		if args := format[1]; len(args) > 1 && args[0].kind == '(' && args[len(args)-1].kind == ')' {
+			values, _ = splitStarlark(args[1:len(args)-1], ',')
+			if len(values) > 1 && len(values[len(values)-1]) == 0 {
+				values = values[:len(values)-1] // Trailing comma of a tuple
+			}
+		}
+		if strings.Count(pattern, "%s") != len(values) {
+			return "", false
+		}
+		for _, value := range values {
+			v, ok := p.str(value)
+			if !ok {
+				return "", false
+			}
+			pattern = strings.Replace(pattern, "%s", v, 1)
+		}
+		return pattern, true
+	}
+
+	var value strings.Builder
+	for i, token := range tokens {
+		if i%2 == 1 {
+			if token.kind != '+' {
+				return "", false
+			}
+			continue
+		}
+		switch token.kind {
+		case 's':
+			value.WriteString(token.text)
+		case 'i':
+			v, ok := p.vars[token.text]
+			if !ok {
+				return "", false
+			}
+			value.WriteString(v)
+		default:
+			return "", false
+		}
+	}
+	return value.String(), len(tokens)%2 == 1
+}
+
+// list evaluates a list of string expressions
+func (p *starlarkParser) list(tokens []starlarkToken) ([]string, bool) {
+	if len(tokens) < 2 || tokens[0].kind != '[' || tokens[len(tokens)-1].kind != ']' {
+		return nil, false
+	}
+	elements, _ := splitStarlark(tokens[1:len(tokens)-1], ',')
+	var list []string
+	for _, element := range elements {
+		if len(element) == 0 {
+			continue // Trailing comma
+		}
+		s, ok := p.str(element)
+		if !ok {
+			return nil, false
+		}
+		list = append(list, s)
+	}
+	return list, true
+}
+
+// splitStarlark splits tokens at the separators outside brackets, reporting whether there was any
+func splitStarlark(tokens []starlarkToken, separator byte) ([][]starlarkToken, bool) {
+	var parts [][]starlarkToken
+	start, depth := 0, 0
+	for i, token := range tokens {
+		switch token.kind {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case separator:
+			if depth == 0 {
+				parts = append(parts, tokens[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, tokens[start:]), len(parts) > 0
+}
+
+// tokenizeStarlark splits Starlark into identifiers, strings, newlines and punctuation, dropping
+// comments. Newlines inside brackets are kept, calls skip them.
+func tokenizeStarlark(content string) ([]starlarkToken, error) {
+	var tokens []starlarkToken
+	line := 1
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			tokens = append(tokens, starlarkToken{kind: 'n', text: "\n", line: line})
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\\':
+			i++
+		case c == '#':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'' || (c == 'r' || c == 'b') && i+1 < len(content) && (content[i+1] == '"' || content[i+1] == '\''):
+			raw := c == 'r'
+			if c == 'r' || c == 'b' {
+				i++
+			}
+			quote := content[i : i+1]
+			if strings.HasPrefix(content[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			start := line
+			var value strings.Builder
+			j := i + len(quote)
+			for ; j < len(content) && !strings.HasPrefix(content[j:], quote); j++ {
+				switch {
+				case content[j] == '\n' && len(quote) == 1:
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				case content[j] == '\n':
+					line++
+				case content[j] == '\\' && !raw && j+1 < len(content):
+					j++
+				}
+				value.WriteByte(content[j])
+			}
+			if j >= len(content) {
+				return nil, fmt.Errorf("line %d: unterminated string", start)
+			}
+			tokens = append(tokens, starlarkToken{kind: 's', text: value.String(), line: start})
+			i = j + len(quote)
+		case isStarlarkIdentifier(c):
+			j := i
+			for j < len(content) && isStarlarkIdentifier(content[j]) {
+				j++
+			}
+			tokens = append(tokens, starlarkToken{kind: 'i', text: content[i:j], line: line})
+			i = j
+		default:
+			tokens = append(tokens, starlarkToken{kind: c, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isStarlarkIdentifier(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
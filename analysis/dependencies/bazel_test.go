@@ -0,0 +1,54 @@
+package dependencies_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("BazelDependencyScanner", func() {
+	dir := filepath.Join("testdata", "bazel")
+
+	scan := func(name string) []string {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := dependencies.NewBazelDependencyScanner().ScanFile(models.NewScanContext(nil, dir), path, content)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, dep := range deps {
+			lines = append(lines, fmt.Sprintf("%s %s@%s %s %s", dep.Type, dep.Name, dep.Version, dep.Git, dep.Source))
+		}
+		return lines
+	}
+
+	It("should scan the modules of MODULE.bazel with their overrides", func() {
+		Expect(scan("MODULE.bazel")).To(Equal([]string{
+			"bazel rules_go@0.46.0  MODULE.bazel:3",
+			"bazel gazelle@0.35.0  MODULE.bazel:4",
+			"bazel protobuf@23.1  MODULE.bazel:5",
+			"bazel rules_oci@9c1d3a8f https://github.com/bazel-contrib/rules_oci MODULE.bazel:6",
+			"bazel buildifier_prebuilt@6.4.0  MODULE.bazel:8",
+			"go github.com/google/uuid@v1.3.1  MODULE.bazel:26",
+		}))
+	})
+
+	It("should scan the repositories of a WORKSPACE and the macros it loads", func() {
+		Expect(scan("WORKSPACE")).To(Equal([]string{
+			"bazel rules_python@0.31.0 https://github.com/bazelbuild/rules_python WORKSPACE:8",
+			"bazel com_google_absl@20230802.1 https://github.com/abseil/abseil-cpp WORKSPACE:15",
+			"bazel zlib@1.3  WORKSPACE:21",
+			"bazel io_bazel_rules_docker@v0.25.0 https://github.com/bazelbuild/rules_docker WORKSPACE:28",
+			"go github.com/spf13/cobra@v1.8.0  bazel/deps.bzl:5",
+			"go golang.org/x/mod@6e4e7b5e1d2c https://go.googlesource.com/mod bazel/deps.bzl:11",
+		}))
+	})
+})
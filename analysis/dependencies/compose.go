@@ -0,0 +1,217 @@
+package dependencies
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/models"
+	"gopkg.in/yaml.v3"
+)
+
+// composeFiles also match the overrides of a compose file, e.g. docker-compose.prod.yml
+var composeFiles = []string{"docker-compose*.yml", "docker-compose*.yaml", "compose*.yml", "compose*.yaml"}
+
+func init() {
+	analysis.RegisterDependencyScanner(NewComposeDependencyScanner())
+}
+
+// ComposeDependencyScanner scans the images of Docker Compose services, and the base images of
+// the services built from a local Dockerfile
+type ComposeDependencyScanner struct {
+	*analysis.BaseDependencyScanner
+	docker *DockerDependencyScanner
+}
+
+// NewComposeDependencyScanner creates a new Docker Compose dependency scanner
+func NewComposeDependencyScanner() *ComposeDependencyScanner {
+	return &ComposeDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("compose", composeFiles),
+		docker:                &DockerDependencyScanner{},
+	}
+}
+
+// NewComposeDependencyScannerWithResolver creates a new Docker Compose dependency scanner with a
+// resolution service
+func NewComposeDependencyScannerWithResolver(resolver *analysis.ResolutionService) *ComposeDependencyScanner {
+	return &ComposeDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("compose", composeFiles),
+		docker:                &DockerDependencyScanner{resolver: resolver},
+	}
+}
+
+// composeService is the part of a compose service referring to images
+type composeService struct {
+	Image yaml.Node     `yaml:"image"`
+	Build *composeBuild `yaml:"build"`
+}
+
+// composeBuild is the build section of a service, either its context or a mapping
+type composeBuild struct {
+	Context    string
+	Dockerfile string
+	Args       map[string]string
+}
+
+func (b *composeBuild) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		b.Context = node.Value
+		return nil
+	}
+
+	var build struct {
+		Context    string    `yaml:"context"`
+		Dockerfile string    `yaml:"dockerfile"`
+		Args       yaml.Node `yaml:"args"`
+	}
+	if err := node.Decode(&build); err != nil {
+		return err
+	}
+	b.Context = build.Context
+	b.Dockerfile = build.Dockerfile
+
+	// Args are either a mapping or a list of NAME=value
+	b.Args = make(map[string]string)
+	switch build.Args.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(build.Args.Content); i += 2 {
+			b.Args[build.Args.Content[i].Value] = build.Args.Content[i+1].Value
+		}
+	case yaml.SequenceNode:
+		for _, arg := range build.Args.Content {
+			if name, value, ok := strings.Cut(arg.Value, "="); ok {
+				b.Args[name] = value
+			}
+		}
+	}
+	return nil
+}
+
+// dockerfile returns the path of the Dockerfile a service is built from, or an empty string when
+// the build context is remote
+func (b *composeBuild) dockerfile(composeFile string) string {
+	context := b.Context
+	if context == "" {
+		context = "."
+	}
+	if strings.Contains(context, "://") || strings.HasPrefix(context, "git@") {
+		return ""
+	}
+	dockerfile := b.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if filepath.IsAbs(dockerfile) {
+		return dockerfile
+	}
+	if !filepath.IsAbs(context) {
+		context = filepath.Join(filepath.Dir(composeFile), context)
+	}
+	return filepath.Join(context, dockerfile)
+}
+
+// ScanFile scans a compose file for service images. The image of a service with a build section
+// names the image being built, so the base images of its Dockerfile are reported instead.
+func (s *ComposeDependencyScanner) ScanFile(ctx *models.ScanContext, filePath string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning Docker Compose file from %s", filePath)
+
+	// Make path relative for source tracking
+	scanRoot := ""
+	if ctx != nil {
+		scanRoot = ctx.ScanRoot
+	}
+	relPath := makeRelativePath(filePath, scanRoot)
+
+	var compose struct {
+		Services map[string]composeService `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(content, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse docker-compose file: %w", err)
+	}
+
+	// Compose interpolates variables from the .env file next to the compose file
+	variables := readDotEnv(filepath.Join(filepath.Dir(filePath), ".env"))
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var dependencies []*models.Dependency
+	seen := make(map[string]bool)
+	for _, name := range names {
+		service := compose.Services[name]
+
+		if service.Build != nil {
+			dockerfile := service.Build.dockerfile(filePath)
+			if dockerfile == "" || seen[dockerfile] {
+				continue
+			}
+			seen[dockerfile] = true
+
+			dockerfileContent, err := os.ReadFile(dockerfile)
+			if err != nil {
+				ctx.Warnf("Cannot read the Dockerfile of service %s: %v", name, err)
+				continue
+			}
+			args := make(map[string]string, len(service.Build.Args))
+			for arg, value := range service.Build.Args {
+				args[arg] = substituteVariables(value, variables)
+			}
+			built, err := s.docker.scanDockerfile(ctx, dockerfile, dockerfileContent, args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan the Dockerfile of service %s: %w", name, err)
+			}
+			dependencies = append(dependencies, built...)
+			continue
+		}
+
+		image := substituteVariables(service.Image.Value, variables)
+		if image == "" || seen[image] {
+			continue
+		}
+		seen[image] = true
+
+		dep := newImageDependency(ctx, s.docker.resolver, image)
+		dep.Source = fmt.Sprintf("%s:%d", relPath, service.Image.Line)
+		if !ctx.Matches(dep) {
+			continue
+		}
+
+		ctx.Debugf("Found service %s using image: %s at %s", name, image, dep.Source)
+		dependencies = append(dependencies, dep)
+	}
+
+	ctx.Debugf("Found %d Docker dependencies in compose file", len(dependencies))
+	return dependencies, nil
+}
+
+// readDotEnv reads the NAME=value lines of a .env file, it returns no variables when the file
+// does not exist
+func readDotEnv(path string) map[string]string {
+	variables := make(map[string]string)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return variables
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		variables[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return variables
+}
@@ -0,0 +1,40 @@
+package dependencies_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("ComposeDependencyScanner", func() {
+	It("should scan service images and the base images of services built locally", func() {
+		dir := filepath.Join("testdata", "compose")
+		path := filepath.Join(dir, "docker-compose.yml")
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := dependencies.NewComposeDependencyScanner().ScanFile(models.NewScanContext(nil, dir), path, content)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, dep := range deps {
+			Expect(dep.Type).To(Equal(models.DependencyTypeDocker))
+			lines = append(lines, fmt.Sprintf("%s@%s %s", dep.Name, dep.Version, dep.Source))
+		}
+		// Build args come from .env, the image of a built service is not a dependency and images
+		// used by several services are reported once
+		Expect(lines).To(Equal([]string{
+			"redis@@sha256:8c1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a docker-compose.yml:5",
+			"postgres@15 docker-compose.yml:3",
+			"ghcr.io/traefik/traefik@v2.10 docker-compose.yml:7",
+			"node@20-alpine web/Dockerfile:2",
+			"nginx@1.25 web/Dockerfile:5",
+		}))
+	})
+})
@@ -11,7 +11,6 @@ import (
 
 	"github.com/flanksource/arch-unit/analysis"
 	"github.com/flanksource/arch-unit/models"
-	"gopkg.in/yaml.v3"
 )
 
 // DockerDependencyScanner scans Docker and container-related dependencies
@@ -24,7 +23,7 @@ type DockerDependencyScanner struct {
 func NewDockerDependencyScanner() *DockerDependencyScanner {
 	scanner := &DockerDependencyScanner{
 		BaseDependencyScanner: analysis.NewBaseDependencyScanner("docker",
-			[]string{"Dockerfile", "Dockerfile.*", "*.dockerfile", "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}),
+			[]string{"Dockerfile", "Dockerfile.*", "*.dockerfile"}),
 	}
 
 	// Register with the global registry
@@ -37,7 +36,7 @@ func NewDockerDependencyScanner() *DockerDependencyScanner {
 func NewDockerDependencyScannerWithResolver(resolver *analysis.ResolutionService) *DockerDependencyScanner {
 	scanner := &DockerDependencyScanner{
 		BaseDependencyScanner: analysis.NewBaseDependencyScanner("docker",
-			[]string{"Dockerfile", "Dockerfile.*", "*.dockerfile", "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}),
+			[]string{"Dockerfile", "Dockerfile.*", "*.dockerfile"}),
 		resolver: resolver,
 	}
 
@@ -52,9 +51,7 @@ func (s *DockerDependencyScanner) ScanFile(ctx *models.ScanContext, filePath str
 	filename := strings.ToLower(filePath)
 
 	if strings.Contains(filename, "dockerfile") || strings.HasSuffix(filename, ".dockerfile") {
-		return s.scanDockerfile(ctx, filePath, content)
-	} else if strings.Contains(filename, "compose") && (strings.HasSuffix(filename, ".yml") || strings.HasSuffix(filename, ".yaml")) {
-		return s.scanDockerCompose(ctx, filePath, content)
+		return s.scanDockerfile(ctx, filePath, content, nil)
 	}
 
 	return nil, fmt.Errorf("unsupported Docker file: %s", filePath)
@@ -79,8 +76,9 @@ func makeRelativePath(path string, scanRoot string) string {
 	return relPath
 }
 
-// scanDockerfile scans Dockerfile for base images and other dependencies
-func (s *DockerDependencyScanner) scanDockerfile(ctx *models.ScanContext, filePath string, content []byte) ([]*models.Dependency, error) {
+// scanDockerfile scans Dockerfile for base images and other dependencies, buildArgs override the
+// defaults of its ARG instructions as docker build --build-arg does
+func (s *DockerDependencyScanner) scanDockerfile(ctx *models.ScanContext, filePath string, content []byte, buildArgs map[string]string) ([]*models.Dependency, error) {
 	ctx.Debugf("Scanning Docker dependencies from %s", filePath)
 
 	// Make path relative for source tracking
@@ -124,6 +122,9 @@ func (s *DockerDependencyScanner) scanDockerfile(ctx *models.ScanContext, filePa
 			if len(matches) > 2 {
 				argValue = matches[2]
 			}
+			if value, ok := buildArgs[argName]; ok {
+				argValue = value
+			}
 			argValues[argName] = argValue
 			ctx.Debugf("Found ARG %s=%s", argName, argValue)
 			continue
@@ -134,7 +135,7 @@ func (s *DockerDependencyScanner) scanDockerfile(ctx *models.ScanContext, filePa
 			image := matches[1]
 
 			// Substitute ARG variables if present
-			image = s.substituteVariables(image, argValues)
+			image = substituteVariables(image, argValues)
 
 			// Skip scratch image
 			if image == "scratch" {
@@ -157,7 +158,7 @@ func (s *DockerDependencyScanner) scanDockerfile(ctx *models.ScanContext, filePa
 			}
 
 			// Substitute ARG variables if present
-			source = s.substituteVariables(source, argValues)
+			source = substituteVariables(source, argValues)
 
 			dep := s.parseDockerImage(ctx, source)
 			dep.Source = fmt.Sprintf("%s:%d", relPath, lineNum)
@@ -171,98 +172,14 @@ func (s *DockerDependencyScanner) scanDockerfile(ctx *models.ScanContext, filePa
 	return dependencies, nil
 }
 
-// scanDockerCompose scans docker-compose files for service images
-func (s *DockerDependencyScanner) scanDockerCompose(ctx *models.ScanContext, filePath string, content []byte) ([]*models.Dependency, error) {
-	ctx.Debugf("Scanning Docker Compose file from %s", filePath)
-
-	// Make path relative for source tracking
-	scanRoot := ""
-	if ctx != nil {
-		scanRoot = ctx.ScanRoot
-	}
-	relPath := makeRelativePath(filePath, scanRoot)
-
-	var compose struct {
-		Version  string `yaml:"version"`
-		Services map[string]struct {
-			Image       string      `yaml:"image"`
-			Build       interface{} `yaml:"build"`
-			Environment interface{} `yaml:"environment"` // Can be []string or map[string]interface{}
-			EnvFile     interface{} `yaml:"env_file"`    // Can be string or []string
-		} `yaml:"services"`
-	}
-
-	if err := yaml.Unmarshal(content, &compose); err != nil {
-		return nil, fmt.Errorf("failed to parse docker-compose file: %w", err)
-	}
-
-	// Also scan line by line to get line numbers for source tracking
-	lineMap := s.buildImageLineMap(content)
-
-	var dependencies []*models.Dependency
-	seen := make(map[string]bool)
-
-	for serviceName, service := range compose.Services {
-		if service.Image == "" {
-			// Service is built locally, not an external dependency
-			continue
-		}
-
-		// Skip if we've already seen this image
-		if seen[service.Image] {
-			continue
-		}
-		seen[service.Image] = true
-
-		dep := s.parseDockerImage(ctx, service.Image)
-
-		// Add source location
-		if lineNum, ok := lineMap[service.Image]; ok {
-			dep.Source = fmt.Sprintf("%s:%d", relPath, lineNum)
-		} else {
-			// Fallback to just filename if we couldn't find the line
-			dep.Source = relPath
-		}
-		if ctx.Matches(dep) {
-			continue
-		}
-
-		ctx.Debugf("Found service %s using image: %s at %s", serviceName, service.Image, dep.Source)
-		dependencies = append(dependencies, dep)
-	}
-
-	ctx.Debugf("Found %d Docker dependencies in compose file", len(dependencies))
-	return dependencies, nil
-}
-
-// buildImageLineMap scans content to find line numbers for image definitions
-func (s *DockerDependencyScanner) buildImageLineMap(content []byte) map[string]int {
-	lineMap := make(map[string]int)
-	scanner := bufio.NewScanner(bytes.NewReader(content))
-
-	// Pattern to match image: value lines
-	// Matches lines like "    image: nginx:latest" or "image: postgres:15"
-	imagePattern := regexp.MustCompile(`^\s*image:\s*["']?([^"'\s]+)["']?`)
-
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-
-		// Check if this line defines an image
-		if matches := imagePattern.FindStringSubmatch(line); len(matches) > 1 {
-			image := matches[1]
-			// Store the line number for this image
-			// If the same image appears multiple times, the last occurrence wins
-			lineMap[image] = lineNum
-		}
-	}
-
-	return lineMap
-}
-
 // parseDockerImage parses a Docker image reference into a dependency
 func (s *DockerDependencyScanner) parseDockerImage(ctx *models.ScanContext, image string) *models.Dependency {
+	return newImageDependency(ctx, s.resolver, image)
+}
+
+// newImageDependency parses an image reference of a Dockerfile, compose file or Helm values into
+// a dependency, resolving its Git URL with resolver when there is one
+func newImageDependency(ctx *models.ScanContext, resolver *analysis.ResolutionService, image string) *models.Dependency {
 	dep := &models.Dependency{
 		Type: models.DependencyTypeDocker,
 	}
@@ -332,16 +249,16 @@ func (s *DockerDependencyScanner) parseDockerImage(ctx *models.ScanContext, imag
 	dep.Name = image
 
 	// Use resolver if available, otherwise fall back to heuristics
-	if s.resolver != nil {
-		if gitURL, err := s.resolver.ResolveGitURL(ctx, image, "docker"); err == nil && gitURL != "" {
+	if resolver != nil {
+		if gitURL, err := resolver.ResolveGitURL(ctx, image, "docker"); err == nil && gitURL != "" {
 			dep.Git = gitURL
 		} else {
 			// Resolver didn't find anything, use existing heuristics
-			dep.Git = s.fallbackGitURL(image)
+			dep.Git = fallbackImageGitURL(image)
 		}
 	} else {
 		// No resolver available, use heuristics
-		dep.Git = s.fallbackGitURL(image)
+		dep.Git = fallbackImageGitURL(image)
 	}
 
 	// Add package information
@@ -352,8 +269,8 @@ func (s *DockerDependencyScanner) parseDockerImage(ctx *models.ScanContext, imag
 	return dep
 }
 
-// fallbackGitURL provides the original heuristic-based Git URL detection
-func (s *DockerDependencyScanner) fallbackGitURL(image string) string {
+// fallbackImageGitURL provides the original heuristic-based Git URL detection
+func fallbackImageGitURL(image string) string {
 	// Determine registry and construct Git URL if applicable
 	if strings.HasPrefix(image, "ghcr.io/") {
 		// GitHub Container Registry
@@ -399,7 +316,7 @@ func (s *DockerDependencyScanner) fallbackGitURL(image string) string {
 }
 
 // substituteVariables replaces ${VAR} or $VAR with their values
-func (s *DockerDependencyScanner) substituteVariables(text string, variables map[string]string) string {
+func substituteVariables(text string, variables map[string]string) string {
 	// Replace ${VAR} format
 	for name, value := range variables {
 		text = strings.ReplaceAll(text, "${"+name+"}", value)
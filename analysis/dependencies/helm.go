@@ -5,6 +5,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/flanksource/arch-unit/analysis"
 	"github.com/flanksource/arch-unit/models"
@@ -15,10 +16,15 @@ func init() {
 	analysis.RegisterDependencyScanner(NewHelmDependencyScanner())
 }
 
-// HelmDependencyScanner scans Helm chart dependencies
+// HelmDependencyScanner scans Helm chart dependencies, and the transitive dependencies of the
+// subcharts vendored in charts/ or, when enabled, downloaded from their repository
 type HelmDependencyScanner struct {
 	*analysis.BaseDependencyScanner
 	resolver *analysis.ResolutionService
+	download bool
+
+	mu      sync.Mutex
+	indexes map[string]*helmRepositoryIndex // Chart repository indexes by URL
 }
 
 // NewHelmDependencyScanner creates a new Helm dependency scanner
@@ -63,25 +69,12 @@ func (s *HelmDependencyScanner) ScanFile(ctx *models.ScanContext, filepath strin
 	}
 }
 
-// scanChartYaml scans Chart.yaml for dependencies (Helm v3)
+// scanChartYaml scans Chart.yaml for dependencies (Helm v3), followed by the transitive
+// dependencies of its subcharts
 func (s *HelmDependencyScanner) scanChartYaml(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
 	ctx.Debugf("Scanning Helm chart from %s", filepath)
 
-	var chart struct {
-		APIVersion   string `yaml:"apiVersion"`
-		Name         string `yaml:"name"`
-		Version      string `yaml:"version"`
-		Dependencies []struct {
-			Name       string   `yaml:"name"`
-			Version    string   `yaml:"version"`
-			Repository string   `yaml:"repository"`
-			Condition  string   `yaml:"condition"`
-			Tags       []string `yaml:"tags"`
-			Enabled    *bool    `yaml:"enabled"`
-			Alias      string   `yaml:"alias"`
-		} `yaml:"dependencies"`
-	}
-
+	var chart helmChartMetadata
 	if err := yaml.Unmarshal(content, &chart); err != nil {
 		return nil, fmt.Errorf("failed to parse Chart.yaml: %w", err)
 	}
@@ -95,32 +88,7 @@ func (s *HelmDependencyScanner) scanChartYaml(ctx *models.ScanContext, filepath
 		}
 
 		sourceInfo := fmt.Sprintf("%s:%d", path.Base(filepath), s.findDependencyLine(content, dep.Name, i))
-		dependency := &models.Dependency{
-			Name:    dep.Name,
-			Version: dep.Version,
-			Type:    models.DependencyTypeHelm,
-			Source:  sourceInfo,
-			Depth:   0, // Direct dependencies
-		}
-
-		// Use alias if provided
-		if dep.Alias != "" {
-			dependency.Name = dep.Alias
-			dependency.Package = []string{dep.Name} // Store original name in packages
-		}
-
-		// Resolve Git URL before filtering so filter can match against it
-		if s.resolver != nil {
-			if gitURL, err := s.resolver.ResolveGitURL(ctx, dep.Name, "helm"); err == nil && gitURL != "" {
-				dependency.Git = gitURL
-			} else if dep.Repository != "" {
-				// If resolver didn't find anything, try the heuristics as fallback
-				dependency.Git = s.parseHelmRepository(ctx, dep.Repository, dep.Name)
-			}
-		} else if dep.Repository != "" {
-			// No resolver available, use heuristics
-			dependency.Git = s.parseHelmRepository(ctx, dep.Repository, dep.Name)
-		}
+		dependency := s.chartDependency(ctx, dep, sourceInfo)
 
 		if !ctx.Matches(dependency) {
 			continue
@@ -130,24 +98,62 @@ func (s *HelmDependencyScanner) scanChartYaml(ctx *models.ScanContext, filepath
 		ctx.Debugf("Found Helm dependency: %s@%s from %s at %s", dep.Name, dep.Version, dep.Repository, dependency.Source)
 	}
 
+	if len(chart.Dependencies) > 0 {
+		scanRoot := ""
+		if ctx != nil {
+			scanRoot = ctx.ScanRoot
+		}
+		dir := path.Dir(filepath)
+		parent, err := loadChartDir(dir, makeRelativePath(dir, scanRoot))
+		if err != nil {
+			ctx.Warnf("Failed to resolve the subcharts of %s: %v", filepath, err)
+		} else if transitive, err := s.subchartDependencies(ctx, parent, 1); err != nil {
+			ctx.Warnf("Failed to resolve the subcharts of %s: %v", filepath, err)
+		} else {
+			dependencies = append(dependencies, ctx.Filter(transitive)...)
+		}
+	}
+
 	ctx.Debugf("Found %d Helm dependencies", len(dependencies))
 	return dependencies, nil
 }
 
+// chartDependency creates the dependency of a chart on another
+func (s *HelmDependencyScanner) chartDependency(ctx *models.ScanContext, dep helmChartDependency, source string) *models.Dependency {
+	dependency := &models.Dependency{
+		Name:    dep.Name,
+		Version: dep.Version,
+		Type:    models.DependencyTypeHelm,
+		Source:  source,
+		Depth:   0, // Direct dependencies
+	}
+
+	// Use alias if provided
+	if dep.Alias != "" {
+		dependency.Name = dep.Alias
+		dependency.Package = []string{dep.Name} // Store original name in packages
+	}
+
+	// Resolve Git URL before filtering so filter can match against it
+	if s.resolver != nil {
+		if gitURL, err := s.resolver.ResolveGitURL(ctx, dep.Name, "helm"); err == nil && gitURL != "" {
+			dependency.Git = gitURL
+		} else if dep.Repository != "" {
+			// If resolver didn't find anything, try the heuristics as fallback
+			dependency.Git = s.parseHelmRepository(ctx, dep.Repository, dep.Name)
+		}
+	} else if dep.Repository != "" {
+		// No resolver available, use heuristics
+		dependency.Git = s.parseHelmRepository(ctx, dep.Repository, dep.Name)
+	}
+	return dependency
+}
+
 // scanChartLock scans Chart.lock for resolved dependencies (Helm v3)
 func (s *HelmDependencyScanner) scanChartLock(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
 	ctx.Debugf("Scanning Helm lock file from %s", filepath)
 
-	var lock struct {
-		Dependencies []struct {
-			Name       string `yaml:"name"`
-			Version    string `yaml:"version"`
-			Repository string `yaml:"repository"`
-			Digest     string `yaml:"digest"`
-		} `yaml:"dependencies"`
-		Digest    string `yaml:"digest"`
-		Generated string `yaml:"generated"`
-	}
+	var lock helmLock
 
 	if err := yaml.Unmarshal(content, &lock); err != nil {
 		return nil, fmt.Errorf("failed to parse Chart.lock: %w", err)
@@ -243,16 +249,7 @@ func (s *HelmDependencyScanner) scanRequirementsYaml(ctx *models.ScanContext, fi
 func (s *HelmDependencyScanner) scanRequirementsLock(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
 	ctx.Debugf("Scanning Helm v2 lock file from %s", filepath)
 
-	var lock struct {
-		Dependencies []struct {
-			Name       string `yaml:"name"`
-			Version    string `yaml:"version"`
-			Repository string `yaml:"repository"`
-			Digest     string `yaml:"digest"`
-		} `yaml:"dependencies"`
-		Digest    string `yaml:"digest"`
-		Generated string `yaml:"generated"`
-	}
+	var lock helmLock
 
 	if err := yaml.Unmarshal(content, &lock); err != nil {
 		return nil, fmt.Errorf("failed to parse requirements.lock: %w", err)
@@ -298,6 +295,29 @@ func (s *HelmDependencyScanner) scanValuesYaml(ctx *models.ScanContext, filepath
 	if err := yaml.Unmarshal(content, &valuesData); err != nil {
 		return nil, fmt.Errorf("failed to parse values YAML: %w", err)
 	}
+	scanRoot := ""
+	if ctx != nil {
+		scanRoot = ctx.ScanRoot
+	}
+
+	dependencies, err := s.valuesImages(ctx, content, valuesData, makeRelativePath(filepath, scanRoot))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Debugf("Found %d Docker images in values file", len(dependencies))
+	return dependencies, nil
+}
+
+// valuesImages returns the images of valuesData, sourced from the lines of content, the values
+// file at relPath
+func (s *HelmDependencyScanner) valuesImages(ctx *models.ScanContext, content []byte, valuesData interface{}, relPath string) ([]*models.Dependency, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse values YAML: %w", err)
+	}
+	values := &valuesFile{relPath: relPath, lines: make(map[string]int)}
+	s.collectValueLines(&root, "", values.lines)
 
 	var dependencies []*models.Dependency
 
@@ -305,12 +325,39 @@ func (s *HelmDependencyScanner) scanValuesYaml(ctx *models.ScanContext, filepath
 	global := s.extractGlobalConfig(valuesData)
 
 	// Recursively scan for image references
-	s.scanForImages(ctx, valuesData, "", filepath, global, &dependencies)
-
-	ctx.Debugf("Found %d Docker images in values file", len(dependencies))
+	s.scanForImages(ctx, valuesData, "", values, global, &dependencies)
 	return dependencies, nil
 }
 
+// valuesFile is a values file being scanned, with the line of every value keyed by its YAML path
+type valuesFile struct {
+	relPath string
+	lines   map[string]int
+}
+
+// collectValueLines records the line of every value of a YAML node by its path, as built by
+// buildPath
+func (s *HelmDependencyScanner) collectValueLines(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			s.collectValueLines(child, path, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			currentPath := s.buildPath(path, node.Content[i].Value)
+			lines[currentPath] = node.Content[i+1].Line
+			s.collectValueLines(node.Content[i+1], currentPath, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			currentPath := s.buildPath(path, fmt.Sprintf("[%d]", i))
+			lines[currentPath] = item.Line
+			s.collectValueLines(item, currentPath, lines)
+		}
+	}
+}
+
 // GlobalConfig holds global image registry configuration
 type GlobalConfig struct {
 	Registry string
@@ -338,7 +385,7 @@ func (s *HelmDependencyScanner) extractGlobalConfig(data interface{}) GlobalConf
 }
 
 // scanForImages recursively scans YAML data for image references
-func (s *HelmDependencyScanner) scanForImages(ctx *models.ScanContext, data interface{}, path, filepath string, global GlobalConfig, dependencies *[]*models.Dependency) {
+func (s *HelmDependencyScanner) scanForImages(ctx *models.ScanContext, data interface{}, path string, values *valuesFile, global GlobalConfig, dependencies *[]*models.Dependency) {
 	switch v := data.(type) {
 	case map[string]interface{}:
 		for key, value := range v {
@@ -346,25 +393,25 @@ func (s *HelmDependencyScanner) scanForImages(ctx *models.ScanContext, data inte
 
 			// Check for image patterns at this level
 			if s.isImageKey(key) {
-				s.processImageValue(ctx, value, currentPath, filepath, global, dependencies)
+				s.processImageValue(ctx, value, currentPath, values, global, dependencies)
 			} else if key == "image" {
 				// Handle both direct image strings and nested image objects
 				if imageStr, ok := value.(string); ok && imageStr != "" {
 					// Direct image string
-					s.processImageValue(ctx, value, currentPath, filepath, global, dependencies)
+					s.processImageValue(ctx, value, currentPath, values, global, dependencies)
 				} else {
 					// Nested image object (image.repository, image.tag)
-					s.processImageObject(ctx, value, currentPath, filepath, global, dependencies)
+					s.processImageObject(ctx, value, currentPath, values, global, dependencies)
 				}
 			} else {
 				// Recurse into nested structures
-				s.scanForImages(ctx, value, currentPath, filepath, global, dependencies)
+				s.scanForImages(ctx, value, currentPath, values, global, dependencies)
 			}
 		}
 	case []interface{}:
 		for i, item := range v {
 			currentPath := s.buildPath(path, fmt.Sprintf("[%d]", i))
-			s.scanForImages(ctx, item, currentPath, filepath, global, dependencies)
+			s.scanForImages(ctx, item, currentPath, values, global, dependencies)
 		}
 	}
 }
@@ -382,7 +429,7 @@ func (s *HelmDependencyScanner) isImageKey(key string) bool {
 }
 
 // processImageValue processes a direct image value (e.g., "nginx:1.21")
-func (s *HelmDependencyScanner) processImageValue(ctx *models.ScanContext, value interface{}, path, filepath string, global GlobalConfig, dependencies *[]*models.Dependency) {
+func (s *HelmDependencyScanner) processImageValue(ctx *models.ScanContext, value interface{}, path string, values *valuesFile, global GlobalConfig, dependencies *[]*models.Dependency) {
 	if imageStr, ok := value.(string); ok && imageStr != "" {
 		// Skip template variables and empty values
 		if strings.Contains(imageStr, "{{") || imageStr == "" {
@@ -390,13 +437,13 @@ func (s *HelmDependencyScanner) processImageValue(ctx *models.ScanContext, value
 		}
 
 		image := s.resolveImageWithGlobal(imageStr, global)
-		dep := s.createDockerDependency(ctx, image, filepath, path)
+		dep := s.createDockerDependency(ctx, image, values, path)
 		*dependencies = append(*dependencies, dep)
 	}
 }
 
 // processImageObject processes an image object with repository/tag structure
-func (s *HelmDependencyScanner) processImageObject(ctx *models.ScanContext, value interface{}, path, filepath string, global GlobalConfig, dependencies *[]*models.Dependency) {
+func (s *HelmDependencyScanner) processImageObject(ctx *models.ScanContext, value interface{}, path string, values *valuesFile, global GlobalConfig, dependencies *[]*models.Dependency) {
 	if imageMap, ok := value.(map[string]interface{}); ok {
 		repository := ""
 		tag := ""
@@ -428,7 +475,7 @@ func (s *HelmDependencyScanner) processImageObject(ctx *models.ScanContext, valu
 
 			// Use repository path for source tracking
 			sourcePath := s.buildPath(path, "repository")
-			dep := s.createDockerDependency(ctx, image, filepath, sourcePath)
+			dep := s.createDockerDependency(ctx, image, values, sourcePath)
 			*dependencies = append(*dependencies, dep)
 		}
 	}
@@ -453,31 +500,16 @@ func (s *HelmDependencyScanner) resolveImageWithGlobal(image string, global Glob
 	return image
 }
 
-// createDockerDependency creates a Docker dependency from an image reference
-func (s *HelmDependencyScanner) createDockerDependency(ctx *models.ScanContext, image, filepath, sourcePath string) *models.Dependency {
-	dep := &models.Dependency{
-		Name:   image,
-		Type:   models.DependencyTypeDocker,
-		Source: fmt.Sprintf("%s:%s", path.Base(filepath), sourcePath),
-		Depth:  0, // Direct dependencies
-	}
-
-	// Parse image for version information
-	if strings.Contains(image, ":") && !strings.Contains(image, "://") {
-		parts := strings.SplitN(image, ":", 2)
-		dep.Name = parts[0]
-		dep.Version = parts[1]
+// createDockerDependency creates a Docker dependency from an image reference, sourced from the
+// line of the value at sourcePath
+func (s *HelmDependencyScanner) createDockerDependency(ctx *models.ScanContext, image string, values *valuesFile, sourcePath string) *models.Dependency {
+	dep := newImageDependency(ctx, s.resolver, image)
+	if line, ok := values.lines[sourcePath]; ok {
+		dep.Source = fmt.Sprintf("%s:%d", values.relPath, line)
 	} else {
-		dep.Version = "latest"
+		dep.Source = fmt.Sprintf("%s:%s", values.relPath, sourcePath)
 	}
-
-	// Use resolver if available for Git URL detection
-	if s.resolver != nil {
-		if gitURL, err := s.resolver.ResolveGitURL(ctx, dep.Name, "docker"); err == nil && gitURL != "" {
-			dep.Git = gitURL
-		}
-	}
-
+	dep.Depth = 0 // Direct dependencies
 	return dep
 }
 
@@ -0,0 +1,530 @@
+package dependencies
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/models"
+	"gopkg.in/yaml.v3"
+)
+
+// helmChartFiles are the files of a chart read to resolve its subcharts
+var helmChartFiles = map[string]bool{
+	"Chart.yaml":        true,
+	"Chart.lock":        true,
+	"requirements.yaml": true,
+	"requirements.lock": true,
+	"values.yaml":       true,
+}
+
+// maxChartArchiveSize bounds the size of the chart archives read or downloaded
+const maxChartArchiveSize = 64 << 20
+
+var helmHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+// helmChartMetadata is the part of Chart.yaml, or of requirements.yaml for Helm v2, listing the
+// dependencies of a chart
+type helmChartMetadata struct {
+	APIVersion   string                `yaml:"apiVersion"`
+	Name         string                `yaml:"name"`
+	Version      string                `yaml:"version"`
+	Dependencies []helmChartDependency `yaml:"dependencies"`
+}
+
+// helmChartDependency is a dependency of Chart.yaml or requirements.yaml
+type helmChartDependency struct {
+	Name       string   `yaml:"name"`
+	Version    string   `yaml:"version"`
+	Repository string   `yaml:"repository"`
+	Condition  string   `yaml:"condition"`
+	Tags       []string `yaml:"tags"`
+	Enabled    *bool    `yaml:"enabled"`
+	Alias      string   `yaml:"alias"`
+}
+
+// helmLock is a Chart.lock or requirements.lock
+type helmLock struct {
+	Dependencies []struct {
+		Name       string `yaml:"name"`
+		Version    string `yaml:"version"`
+		Repository string `yaml:"repository"`
+		Digest     string `yaml:"digest"`
+	} `yaml:"dependencies"`
+	Digest    string `yaml:"digest"`
+	Generated string `yaml:"generated"`
+}
+
+// helmChart is a chart read from a directory or an archive, with the files needed to resolve its
+// subcharts keyed by their slash separated path in the chart
+type helmChart struct {
+	source string // Path of the chart in dependency sources, e.g. charts/redis-17.3.0.tgz/redis
+	dir    string // Directory of a chart read from disk, resolving file:// repositories
+	files  map[string][]byte
+	values map[string]interface{}
+}
+
+// helmRepositoryIndex is the index.yaml of a chart repository
+type helmRepositoryIndex struct {
+	Entries map[string][]struct {
+		Version string   `yaml:"version"`
+		URLs    []string `yaml:"urls"`
+	} `yaml:"entries"`
+}
+
+// WithDownload enables downloading the subcharts that are not vendored in the charts/ directory
+// of a chart from their repository, to resolve their dependencies and images
+func (s *HelmDependencyScanner) WithDownload(download bool) *HelmDependencyScanner {
+	s.download = download
+	return s
+}
+
+// loadChartDir reads a chart from a directory
+func loadChartDir(dir, source string) (*helmChart, error) {
+	chart := &helmChart{source: source, dir: dir, files: make(map[string][]byte)}
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if entry.Name() == "templates" || entry.Name() == "crds" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isChartFile(rel) {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		chart.files[rel] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart %s: %w", dir, err)
+	}
+	return chart, nil
+}
+
+// loadChartArchive reads a chart packaged by helm package, whose files are under a directory named
+// after the chart
+func loadChartArchive(content []byte, source string) (*helmChart, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart archive %s: %w", source, err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	chart := &helmChart{files: make(map[string][]byte)}
+	root := ""
+	archive := tar.NewReader(io.LimitReader(gz, maxChartArchiveSize))
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chart archive %s: %w", source, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		dir, rel, ok := strings.Cut(path.Clean(header.Name), "/")
+		if !ok || !isChartFile(rel) {
+			continue
+		}
+		root = dir
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s of chart archive %s: %w", header.Name, source, err)
+		}
+		chart.files[rel] = data
+	}
+	if _, ok := chart.files["Chart.yaml"]; !ok {
+		return nil, fmt.Errorf("chart archive %s has no Chart.yaml", source)
+	}
+	chart.source = sourcePath(source, root)
+	return chart, nil
+}
+
+// sourcePath joins the elements of the path of a chart in dependency sources, which is the URL
+// of the archive for downloaded charts
+func sourcePath(source string, elem ...string) string {
+	if scheme, rest, ok := strings.Cut(source, "://"); ok {
+		return scheme + "://" + path.Join(append([]string{rest}, elem...)...)
+	}
+	return path.Join(append([]string{source}, elem...)...)
+}
+
+// isChartFile reports whether a file of a chart is needed to resolve its subcharts: its own
+// metadata and values, and those of the subcharts vendored in charts/
+func isChartFile(rel string) bool {
+	if helmChartFiles[rel] {
+		return true
+	}
+	sub, ok := strings.CutPrefix(rel, "charts/")
+	if !ok {
+		return false
+	}
+	if !strings.Contains(sub, "/") {
+		return strings.HasSuffix(sub, ".tgz")
+	}
+	_, rest, _ := strings.Cut(sub, "/")
+	return isChartFile(rest)
+}
+
+// metadata returns the dependencies of a chart and the file listing them, requirements.yaml for
+// Helm v2 charts
+func (c *helmChart) metadata() (helmChartMetadata, string, error) {
+	var metadata helmChartMetadata
+	if err := yaml.Unmarshal(c.files["Chart.yaml"], &metadata); err != nil {
+		return metadata, "", fmt.Errorf("failed to parse %s/Chart.yaml: %w", c.source, err)
+	}
+	if len(metadata.Dependencies) == 0 && c.files["requirements.yaml"] != nil {
+		if err := yaml.Unmarshal(c.files["requirements.yaml"], &metadata); err != nil {
+			return metadata, "", fmt.Errorf("failed to parse %s/requirements.yaml: %w", c.source, err)
+		}
+		return metadata, "requirements.yaml", nil
+	}
+	return metadata, "Chart.yaml", nil
+}
+
+// lockedVersions returns the versions of the dependencies of a chart resolved by its lock file
+func (c *helmChart) lockedVersions() map[string]string {
+	versions := make(map[string]string)
+	for _, file := range []string{"Chart.lock", "requirements.lock"} {
+		var lock helmLock
+		if err := yaml.Unmarshal(c.files[file], &lock); err != nil {
+			continue
+		}
+		for _, dep := range lock.Dependencies {
+			versions[dep.Name] = dep.Version
+		}
+	}
+	return versions
+}
+
+// getValues returns the values of a chart, including those set by its parents
+func (c *helmChart) getValues() map[string]interface{} {
+	if c.values == nil {
+		c.values = make(map[string]interface{})
+		if err := yaml.Unmarshal(c.files["values.yaml"], &c.values); err != nil || c.values == nil {
+			c.values = make(map[string]interface{})
+		}
+	}
+	return c.values
+}
+
+// vendored returns the subchart vendored in charts/ as a directory or an archive, nil when it is
+// not vendored
+func (c *helmChart) vendored(name string) (*helmChart, error) {
+	var dirs, archives []string
+	for file := range c.files {
+		sub, ok := strings.CutPrefix(file, "charts/")
+		if !ok {
+			continue
+		}
+		if dir, ok := strings.CutSuffix(sub, "/Chart.yaml"); ok && !strings.Contains(dir, "/") {
+			dirs = append(dirs, dir)
+		} else if strings.HasSuffix(sub, ".tgz") && !strings.Contains(sub, "/") {
+			archives = append(archives, sub)
+		}
+	}
+	sort.Strings(dirs)
+	sort.Strings(archives)
+
+	for _, dir := range dirs {
+		sub := &helmChart{source: sourcePath(c.source, "charts", dir), files: make(map[string][]byte)}
+		if c.dir != "" {
+			sub.dir = filepath.Join(c.dir, "charts", dir)
+		}
+		prefix := "charts/" + dir + "/"
+		for file, content := range c.files {
+			if rel, ok := strings.CutPrefix(file, prefix); ok {
+				sub.files[rel] = content
+			}
+		}
+		if metadata, _, err := sub.metadata(); err == nil && metadata.Name == name {
+			return sub, nil
+		}
+	}
+	for _, archive := range archives {
+		// Archives are named <name>-<version>.tgz
+		if !strings.HasPrefix(archive, name+"-") {
+			continue
+		}
+		sub, err := loadChartArchive(c.files["charts/"+archive], sourcePath(c.source, "charts", archive))
+		if err != nil {
+			return nil, err
+		}
+		if metadata, _, err := sub.metadata(); err == nil && metadata.Name == name {
+			return sub, nil
+		}
+	}
+	return nil, nil
+}
+
+// subchart returns the chart of a dependency, vendored in charts/, in a local directory of a
+// file:// repository, or downloaded from its repository when downloads are enabled, nil when
+// it is not available
+func (s *HelmDependencyScanner) subchart(ctx *models.ScanContext, parent *helmChart, dep helmChartDependency, version string) (*helmChart, error) {
+	sub, err := parent.vendored(dep.Name)
+	if err != nil || sub != nil {
+		return sub, err
+	}
+
+	if dir, ok := strings.CutPrefix(dep.Repository, "file://"); ok {
+		if parent.dir == "" {
+			return nil, nil
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(parent.dir, dir)
+		}
+		return loadChartDir(dir, sourcePath(parent.source, strings.TrimPrefix(dep.Repository, "file://")))
+	}
+
+	if !s.download {
+		ctx.Debugf("Subchart %s of %s is not vendored, run helm dependency build to resolve its dependencies", dep.Name, parent.source)
+		return nil, nil
+	}
+	return s.downloadChart(ctx, dep.Repository, dep.Name, version)
+}
+
+// downloadChart downloads the version of a chart from a chart repository, the latest version
+// satisfying it when version is a constraint such as ~17.3.0
+func (s *HelmDependencyScanner) downloadChart(ctx *models.ScanContext, repository, name, version string) (*helmChart, error) {
+	switch {
+	case repository == "":
+		return nil, fmt.Errorf("subchart %s has no repository", name)
+	case strings.HasPrefix(repository, "oci://"):
+		return nil, fmt.Errorf("downloading %s from OCI registry %s is not supported, vendor it with helm dependency build", name, repository)
+	case strings.HasPrefix(repository, "@") || strings.HasPrefix(repository, "alias:"):
+		return nil, fmt.Errorf("repository alias %s of %s is not supported, use the repository URL", repository, name)
+	}
+	if err := offline.Check(fmt.Sprintf("downloading Helm chart %s from %s", name, repository)); err != nil {
+		return nil, err
+	}
+
+	index, err := s.repositoryIndex(repository)
+	if err != nil {
+		return nil, err
+	}
+	chartURL, resolved, err := index.find(name, version)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", repository, err)
+	}
+	if !strings.Contains(chartURL, "://") {
+		base, err := url.Parse(strings.TrimSuffix(repository, "/") + "/")
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository %s: %w", repository, err)
+		}
+		relative, err := url.Parse(chartURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %s of chart %s: %w", chartURL, name, err)
+		}
+		chartURL = base.ResolveReference(relative).String()
+	}
+
+	ctx.Debugf("Downloading Helm chart %s@%s from %s", name, resolved, chartURL)
+	content, err := httpGet(chartURL)
+	if err != nil {
+		return nil, err
+	}
+	return loadChartArchive(content, chartURL)
+}
+
+// repositoryIndex returns the index of a chart repository, fetched once per scanner
+func (s *HelmDependencyScanner) repositoryIndex(repository string) (*helmRepositoryIndex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index, ok := s.indexes[repository]; ok {
+		return index, nil
+	}
+
+	content, err := httpGet(strings.TrimSuffix(repository, "/") + "/index.yaml")
+	if err != nil {
+		return nil, err
+	}
+	var index helmRepositoryIndex
+	if err := yaml.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index of chart repository %s: %w", repository, err)
+	}
+	if s.indexes == nil {
+		s.indexes = make(map[string]*helmRepositoryIndex)
+	}
+	s.indexes[repository] = &index
+	return &index, nil
+}
+
+// find returns the URL and version of a chart version, or of the latest version satisfying a
+// constraint
+func (index *helmRepositoryIndex) find(name, version string) (string, string, error) {
+	entries := index.Entries[name]
+	for _, entry := range entries {
+		if entry.Version == version && len(entry.URLs) > 0 {
+			return entry.URLs[0], entry.Version, nil
+		}
+	}
+
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return "", "", fmt.Errorf("chart %s has no version %s", name, version)
+	}
+	var latest *semver.Version
+	chartURL := ""
+	for _, entry := range entries {
+		v, err := semver.NewVersion(entry.Version)
+		if err != nil || len(entry.URLs) == 0 || !constraint.Check(v) {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest, chartURL = v, entry.URLs[0]
+		}
+	}
+	if latest == nil {
+		return "", "", fmt.Errorf("chart %s has no version satisfying %s", name, version)
+	}
+	return chartURL, latest.Original(), nil
+}
+
+// httpGet returns the body of a URL, bounded by maxChartArchiveSize, authorized with the
+// credentials of private chart repositories
+func httpGet(target string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", target, err)
+	}
+	analysis.GetCredentials().Authorize(req)
+	resp, err := helmHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", target, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error %d when fetching %s", resp.StatusCode, target)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxChartArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", target, err)
+	}
+	if len(content) > maxChartArchiveSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", target, maxChartArchiveSize)
+	}
+	return content, nil
+}
+
+// subchartDependencies returns the transitive dependencies of a chart: the images and the
+// dependencies of its subcharts at depth, and those of their own subcharts below
+func (s *HelmDependencyScanner) subchartDependencies(ctx *models.ScanContext, chart *helmChart, depth int) ([]*models.Dependency, error) {
+	metadata, _, err := chart.metadata()
+	if err != nil {
+		return nil, err
+	}
+	locked := chart.lockedVersions()
+
+	var dependencies []*models.Dependency
+	for _, dep := range metadata.Dependencies {
+		if dep.Enabled != nil && !*dep.Enabled {
+			continue
+		}
+		version := dep.Version
+		if v, ok := locked[dep.Name]; ok {
+			version = v
+		}
+		sub, err := s.subchart(ctx, chart, dep, version)
+		if err != nil {
+			ctx.Warnf("Failed to resolve subchart %s of %s: %v", dep.Name, chart.source, err)
+			continue
+		}
+		if sub == nil {
+			continue
+		}
+
+		// Values of the parent under the name, or alias, of the subchart override its own
+		key := dep.Name
+		if dep.Alias != "" {
+			key = dep.Alias
+		}
+		values := sub.getValues()
+		if overrides, ok := chart.getValues()[key].(map[string]interface{}); ok {
+			mergeValues(values, overrides)
+		}
+		if global, ok := chart.getValues()["global"].(map[string]interface{}); ok {
+			merged, _ := values["global"].(map[string]interface{})
+			if merged == nil {
+				merged = make(map[string]interface{})
+			}
+			mergeValues(merged, global)
+			values["global"] = merged
+		}
+
+		images, err := s.valuesImages(ctx, sub.files["values.yaml"], values, sourcePath(sub.source, "values.yaml"))
+		if err != nil {
+			ctx.Warnf("Failed to scan images of subchart %s: %v", sub.source, err)
+		}
+		for _, image := range images {
+			image.Depth = depth
+			image.Indirect = true
+			dependencies = append(dependencies, image)
+		}
+
+		subMetadata, metadataFile, err := sub.metadata()
+		if err != nil {
+			ctx.Warnf("%v", err)
+			continue
+		}
+		for i, subDep := range subMetadata.Dependencies {
+			if subDep.Enabled != nil && !*subDep.Enabled {
+				continue
+			}
+			line := s.findDependencyLine(sub.files[metadataFile], subDep.Name, i)
+			dependency := s.chartDependency(ctx, subDep, fmt.Sprintf("%s:%d", sourcePath(sub.source, metadataFile), line))
+			dependency.Depth = depth
+			dependency.Indirect = true
+			dependencies = append(dependencies, dependency)
+		}
+
+		transitive, err := s.subchartDependencies(ctx, sub, depth+1)
+		if err != nil {
+			ctx.Warnf("%v", err)
+		}
+		dependencies = append(dependencies, transitive...)
+	}
+	return dependencies, nil
+}
+
+// mergeValues deep merges overrides into values, as Helm merges the values of a parent chart into
+// those of its subcharts
+func mergeValues(values, overrides map[string]interface{}) {
+	for key, override := range overrides {
+		if overrideMap, ok := override.(map[string]interface{}); ok {
+			if valueMap, ok := values[key].(map[string]interface{}); ok {
+				mergeValues(valueMap, overrideMap)
+				continue
+			}
+		}
+		values[key] = override
+	}
+}
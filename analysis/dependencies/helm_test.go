@@ -0,0 +1,140 @@
+package dependencies_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("HelmDependencyScanner", func() {
+	scan := func(scanner *dependencies.HelmDependencyScanner, dir string) []string {
+		path := filepath.Join(dir, "Chart.yaml")
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := scanner.ScanFile(models.NewScanContext(nil, dir), path, content)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, dep := range deps {
+			lines = append(lines, fmt.Sprintf("%d %s %s@%s %s", dep.Depth, dep.Type, dep.Name, dep.Version, dep.Source))
+		}
+		// Images are found in values in no particular order
+		sort.Strings(lines)
+		return lines
+	}
+
+	It("should scan the dependencies and images of vendored and local subcharts", func() {
+		Expect(scan(dependencies.NewHelmDependencyScanner(), filepath.Join("testdata", "helm", "umbrella"))).To(Equal([]string{
+			"0 helm api@0.1.0 Chart.yaml:8",
+			"0 helm jobs@2.0.0 Chart.yaml:11",
+			"0 helm redis@~17.3.0 Chart.yaml:5",
+			"1 docker ghcr.io/example/worker@2.0.1 charts/worker/values.yaml:2",
+			"1 docker nginx@1.25 ../api/values.yaml:2",
+			"1 helm postgresql@12.5.8 ../api/Chart.yaml:5",
+		}))
+	})
+
+	Describe("downloading subcharts", func() {
+		var dir string
+		var server *httptest.Server
+		var mu sync.Mutex
+		var requests []string
+		recorded := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]string{}, requests...)
+		}
+
+		BeforeEach(func() {
+			common := chartArchive(map[string]string{
+				"common/Chart.yaml": "apiVersion: v2\nname: common\nversion: 2.9.0\n",
+			})
+			redis := chartArchive(map[string]string{
+				"redis/Chart.yaml":              "apiVersion: v2\nname: redis\nversion: 17.3.7\ndependencies:\n  - name: common\n    version: 2.x.x\n    repository: oci://registry-1.docker.io/bitnamicharts\n",
+				"redis/values.yaml":             "image:\n  repository: bitnami/redis\n  tag: 7.2.3\n",
+				"redis/templates/master.yaml":   "kind: StatefulSet\n",
+				"redis/charts/common-2.9.0.tgz": string(common),
+			})
+
+			mu.Lock()
+			requests = nil
+			mu.Unlock()
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests = append(requests, r.URL.Path)
+				mu.Unlock()
+				switch r.URL.Path {
+				case "/index.yaml":
+					_, _ = fmt.Fprint(w, "apiVersion: v1\nentries:\n  redis:\n"+
+						"    - version: 17.4.0\n      urls: [charts/redis-17.4.0.tgz]\n"+
+						"    - version: 17.3.7\n      urls: [charts/redis-17.3.7.tgz]\n"+
+						"    - version: 17.3.0\n      urls: [charts/redis-17.3.0.tgz]\n")
+				case "/charts/redis-17.3.7.tgz":
+					_, _ = w.Write(redis)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			DeferCleanup(server.Close)
+
+			dir = GinkgoT().TempDir()
+			chart := fmt.Sprintf("apiVersion: v2\nname: app\nversion: 1.0.0\ndependencies:\n  - name: redis\n    version: ~17.3.0\n    repository: %s\n", server.URL)
+			Expect(os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(chart), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("redis:\n  image:\n    tag: 7.2.4\n"), 0644)).To(Succeed())
+		})
+
+		It("should download the latest version satisfying the constraint", func() {
+			archive := server.URL + "/charts/redis-17.3.7.tgz/redis"
+			Expect(scan(dependencies.NewHelmDependencyScanner().WithDownload(true), dir)).To(Equal([]string{
+				"0 helm redis@~17.3.0 Chart.yaml:5",
+				"1 docker bitnami/redis@7.2.4 " + archive + "/values.yaml:2",
+				"1 helm common@2.x.x " + archive + "/Chart.yaml:5",
+			}))
+			Expect(recorded()).To(Equal([]string{"/index.yaml", "/charts/redis-17.3.7.tgz"}))
+		})
+
+		It("should not download subcharts unless enabled or in offline mode", func() {
+			Expect(scan(dependencies.NewHelmDependencyScanner(), dir)).To(Equal([]string{"0 helm redis@~17.3.0 Chart.yaml:5"}))
+
+			offline.SetEnabled(true)
+			DeferCleanup(offline.SetEnabled, false)
+			Expect(scan(dependencies.NewHelmDependencyScanner().WithDownload(true), dir)).To(Equal([]string{"0 helm redis@~17.3.0 Chart.yaml:5"}))
+			Expect(recorded()).To(BeEmpty())
+		})
+	})
+})
+
+// chartArchive packages files as helm package does, in a gzipped tarball
+func chartArchive(files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		Expect(archive.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name])), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := archive.Write([]byte(files[name]))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(archive.Close()).To(Succeed())
+	Expect(gz.Close()).To(Succeed())
+	return buf.Bytes()
+}
@@ -0,0 +1,283 @@
+package dependencies
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/models"
+	"gopkg.in/yaml.v3"
+)
+
+var kustomizeFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+func init() {
+	analysis.RegisterDependencyScanner(NewKustomizeDependencyScanner())
+}
+
+// KustomizeDependencyScanner scans the remote resources, image overrides and Helm charts of
+// kustomizations, following local bases and components
+type KustomizeDependencyScanner struct {
+	*analysis.BaseDependencyScanner
+	resolver *analysis.ResolutionService
+}
+
+// NewKustomizeDependencyScanner creates a new Kustomize dependency scanner
+func NewKustomizeDependencyScanner() *KustomizeDependencyScanner {
+	return &KustomizeDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("kustomize", kustomizeFiles),
+	}
+}
+
+// NewKustomizeDependencyScannerWithResolver creates a new Kustomize dependency scanner with a
+// resolution service
+func NewKustomizeDependencyScannerWithResolver(resolver *analysis.ResolutionService) *KustomizeDependencyScanner {
+	return &KustomizeDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("kustomize", kustomizeFiles),
+		resolver:              resolver,
+	}
+}
+
+// kustomization is the part of a kustomization referring to other kustomizations, images and charts
+type kustomization struct {
+	Resources  []yaml.Node `yaml:"resources"`
+	Bases      []yaml.Node `yaml:"bases"`
+	Components []yaml.Node `yaml:"components"`
+	Images     []yaml.Node `yaml:"images"`
+	HelmCharts []yaml.Node `yaml:"helmCharts"`
+}
+
+// kustomizeImage is an entry of the images of a kustomization
+type kustomizeImage struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName"`
+	NewTag  string `yaml:"newTag"`
+	Digest  string `yaml:"digest"`
+}
+
+// kustomizeHelmChart is an entry of the helmCharts of a kustomization
+type kustomizeHelmChart struct {
+	Name    string `yaml:"name"`
+	Repo    string `yaml:"repo"`
+	Version string `yaml:"version"`
+}
+
+// ScanFile scans a kustomization and the local kustomizations it builds on
+func (s *KustomizeDependencyScanner) ScanFile(ctx *models.ScanContext, filePath string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning kustomization from %s", filePath)
+
+	scan := &kustomizeScan{scanner: s, ctx: ctx, visited: make(map[string]bool), remotes: make(map[string]*models.Dependency)}
+	if err := scan.kustomization(filePath, content); err != nil {
+		return nil, err
+	}
+
+	ctx.Debugf("Found %d Kustomize dependencies", len(scan.dependencies))
+	return scan.dependencies, nil
+}
+
+// kustomizeScan tracks the kustomizations visited while scanning a kustomization
+type kustomizeScan struct {
+	scanner      *KustomizeDependencyScanner
+	ctx          *models.ScanContext
+	visited      map[string]bool
+	remotes      map[string]*models.Dependency
+	dependencies []*models.Dependency
+}
+
+func (k *kustomizeScan) kustomization(filePath string, content []byte) error {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		if k.visited[abs] {
+			return nil
+		}
+		k.visited[abs] = true
+	}
+
+	var kust kustomization
+	if err := yaml.Unmarshal(content, &kust); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	scanRoot := ""
+	if k.ctx != nil {
+		scanRoot = k.ctx.ScanRoot
+	}
+	relPath := makeRelativePath(filePath, scanRoot)
+	dir := filepath.Dir(filePath)
+
+	targets := append(append(append([]yaml.Node{}, kust.Resources...), kust.Bases...), kust.Components...)
+	for _, target := range targets {
+		source := fmt.Sprintf("%s:%d", relPath, target.Line)
+
+		// Local files and directories take precedence, as for kustomize build
+		local := filepath.Join(dir, target.Value)
+		if info, err := os.Stat(local); err == nil {
+			if info.IsDir() {
+				if err := k.localKustomization(local); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		gitURL, subPath, ref := kustomizeRemote(target.Value)
+		if gitURL == "" {
+			k.add(&models.Dependency{Name: target.Value, Type: models.DependencyTypeKustomize, Source: source})
+			continue
+		}
+
+		name := strings.TrimPrefix(gitURL, "https://")
+		// Resources of the same repository and ref are a single dependency
+		if dep, ok := k.remotes[name+"@"+ref]; ok {
+			if subPath != "" {
+				dep.Package = append(dep.Package, subPath)
+			}
+			continue
+		}
+		dep := &models.Dependency{
+			Name:    name,
+			Version: ref,
+			Type:    models.DependencyTypeKustomize,
+			Git:     gitURL,
+			Source:  source,
+		}
+		if subPath != "" {
+			dep.Package = []string{subPath}
+		}
+		if k.add(dep) {
+			k.remotes[name+"@"+ref] = dep
+		}
+	}
+
+	for _, node := range kust.Images {
+		var image kustomizeImage
+		if err := node.Decode(&image); err != nil {
+			return fmt.Errorf("invalid image in %s: %w", filePath, err)
+		}
+		name := image.NewName
+		if name == "" {
+			name = image.Name
+		}
+		reference := name
+		if image.Digest != "" {
+			reference += "@" + image.Digest
+		} else if image.NewTag != "" {
+			reference += ":" + image.NewTag
+		}
+
+		dep := newImageDependency(k.ctx, k.scanner.resolver, reference)
+		if image.Digest == "" && image.NewTag == "" {
+			// The tag of the manifests is kept
+			dep.Version = ""
+		}
+		dep.Source = fmt.Sprintf("%s:%d", relPath, node.Line)
+		k.add(dep)
+	}
+
+	for _, node := range kust.HelmCharts {
+		var chart kustomizeHelmChart
+		if err := node.Decode(&chart); err != nil {
+			return fmt.Errorf("invalid helm chart in %s: %w", filePath, err)
+		}
+		helm := &HelmDependencyScanner{resolver: k.scanner.resolver}
+		dep := &models.Dependency{
+			Name:    chart.Name,
+			Version: chart.Version,
+			Type:    models.DependencyTypeHelm,
+			Source:  fmt.Sprintf("%s:%d", relPath, node.Line),
+		}
+		if chart.Repo != "" {
+			dep.Git = helm.parseHelmRepository(k.ctx, chart.Repo, chart.Name)
+		}
+		k.add(dep)
+	}
+	return nil
+}
+
+// localKustomization scans the kustomization of a local base or component directory
+func (k *kustomizeScan) localKustomization(dir string) error {
+	for _, name := range kustomizeFiles {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		return k.kustomization(path, content)
+	}
+	k.ctx.Debugf("No kustomization in %s", dir)
+	return nil
+}
+
+// add appends a dependency matching the scan filters, reporting whether it was added
+func (k *kustomizeScan) add(dep *models.Dependency) bool {
+	if !k.ctx.Matches(dep) {
+		return false
+	}
+	k.dependencies = append(k.dependencies, dep)
+	return true
+}
+
+// kustomizeRemote returns the https URL of the Git repository of a remote kustomize target, the
+// path within the repository and the ref, e.g. for github.com/org/repo/deploy?ref=v1,
+// https://github.com/org/repo//deploy?ref=v1 or git@github.com:org/repo.git/deploy?ref=v1. It
+// returns an empty URL when target is not a Git repository.
+func kustomizeRemote(target string) (string, string, string) {
+	target, query, _ := strings.Cut(target, "?")
+	var ref string
+	if values, err := url.ParseQuery(query); err == nil {
+		ref = values.Get("ref")
+		if ref == "" {
+			ref = values.Get("version")
+		}
+	}
+
+	target, explicitGit := strings.CutPrefix(target, "git::")
+	var host, path string
+	if scheme, rest, ok := strings.Cut(target, "://"); ok {
+		// ssh://git@host/path
+		if at := strings.Index(rest, "@"); at >= 0 && at < strings.Index(rest, "/") {
+			rest = rest[at+1:]
+		}
+		host, path, _ = strings.Cut(rest, "/")
+		if scheme == "file" {
+			return "", "", ""
+		}
+	} else if user, rest, ok := strings.Cut(target, "@"); ok && !strings.Contains(user, "/") {
+		// git@host:path
+		host, path, _ = strings.Cut(rest, ":")
+	} else {
+		host, path, _ = strings.Cut(target, "/")
+	}
+	host, _, _ = strings.Cut(host, ":")
+	if !strings.Contains(host, ".") || path == "" {
+		return "", "", ""
+	}
+
+	// The repository ends at //, at .git or, on known forges, after the owner and name
+	var repo, subPath string
+	switch {
+	case strings.Contains(path, "//"):
+		repo, subPath, _ = strings.Cut(path, "//")
+	case strings.Contains(path, ".git/") || strings.HasSuffix(path, ".git"):
+		i := strings.Index(path, ".git")
+		repo, subPath = path[:i], strings.TrimPrefix(path[i+len(".git"):], "/")
+	case host == "github.com" || host == "gitlab.com" || host == "bitbucket.org":
+		parts := strings.SplitN(path, "/", 3)
+		if len(parts) < 2 {
+			return "", "", ""
+		}
+		repo = parts[0] + "/" + parts[1]
+		if len(parts) == 3 {
+			subPath = parts[2]
+		}
+	case explicitGit:
+		repo = path
+	default:
+		// Other URLs are files served over HTTP
+		return "", "", ""
+	}
+
+	return "https://" + host + "/" + strings.TrimSuffix(repo, ".git"), strings.Trim(subPath, "/"), ref
+}
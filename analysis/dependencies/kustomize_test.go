@@ -0,0 +1,40 @@
+package dependencies_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("KustomizeDependencyScanner", func() {
+	It("should scan remote resources, images and charts of an overlay and its local bases", func() {
+		dir := filepath.Join("testdata", "kustomize")
+		path := filepath.Join(dir, "overlays", "prod", "kustomization.yaml")
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := dependencies.NewKustomizeDependencyScanner().ScanFile(models.NewScanContext(nil, dir), path, content)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, dep := range deps {
+			lines = append(lines, fmt.Sprintf("%s %s@%s %s %v %s", dep.Type, dep.Name, dep.Version, dep.Git, dep.Package, dep.Source))
+		}
+		Expect(lines).To(Equal([]string{
+			"kustomize github.com/fluxcd/flux2@v2.2.0 https://github.com/fluxcd/flux2 [manifests/rbac manifests/install] base/kustomization.yaml:5",
+			"docker redis@ https://hub.docker.com/_/redis [] base/kustomization.yaml:7",
+			"kustomize github.com/flanksource/canary-checker@v1.0.0 https://github.com/flanksource/canary-checker [chart/crds] overlays/prod/kustomization.yaml:5",
+			"kustomize https://raw.githubusercontent.com/example/app/main/namespace.yaml@  [] overlays/prod/kustomization.yaml:7",
+			"kustomize github.com/prometheus-operator/kube-prometheus@v0.13.0 https://github.com/prometheus-operator/kube-prometheus [manifests/setup] components/monitoring/kustomization.yaml:5",
+			"docker nginx@1.25.3 https://hub.docker.com/_/nginx [] overlays/prod/kustomization.yaml:12",
+			"docker ghcr.io/example/app@@sha256:0123abcd https://github.com/example/app [ghcr.io/example/app] overlays/prod/kustomization.yaml:14",
+			"helm podinfo@6.5.4 https://github.com/stefanprodan/podinfo [] overlays/prod/kustomization.yaml:18",
+		}))
+	})
+})
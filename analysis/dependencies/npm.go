@@ -0,0 +1,780 @@
+package dependencies
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/models"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	analysis.RegisterDependencyScanner(NewNpmDependencyScanner())
+}
+
+var npmFiles = []string{"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml"}
+
+// NpmDependencyScanner scans npm dependencies from package.json and the lockfiles of npm, yarn
+// and pnpm. Lockfiles report every installed package with its resolved version and its depth,
+// package.json is only scanned when there is no lockfile next to it.
+type NpmDependencyScanner struct {
+	*analysis.BaseDependencyScanner
+	resolver *analysis.ResolutionService
+}
+
+// NewNpmDependencyScanner creates a new npm dependency scanner
+func NewNpmDependencyScanner() *NpmDependencyScanner {
+	return &NpmDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("npm", npmFiles),
+	}
+}
+
+// NewNpmDependencyScannerWithResolver creates a new npm dependency scanner resolving the Git
+// URLs of direct dependencies from the npm registry
+func NewNpmDependencyScannerWithResolver(resolver *analysis.ResolutionService) *NpmDependencyScanner {
+	return &NpmDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("npm", npmFiles),
+		resolver:              resolver,
+	}
+}
+
+// ScanFile scans a package.json or lockfile and extracts dependencies
+func (s *NpmDependencyScanner) ScanFile(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
+	switch path.Base(strings.ToLower(filepath)) {
+	case "package.json":
+		return s.scanPackageJSON(ctx, filepath, content)
+	case "package-lock.json":
+		return s.scanPackageLock(ctx, filepath, content)
+	case "yarn.lock":
+		return s.scanYarnLock(ctx, filepath, content)
+	case "pnpm-lock.yaml":
+		return s.scanPnpmLock(ctx, filepath, content)
+	default:
+		return nil, fmt.Errorf("unsupported npm file: %s", filepath)
+	}
+}
+
+// packageJSON is the part of package.json listing dependencies
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// all returns every dependency with its version range, later sections overriding earlier ones
+// as npm does when a package is listed twice
+func (p packageJSON) all() map[string]string {
+	all := make(map[string]string)
+	for _, section := range []map[string]string{p.PeerDependencies, p.DevDependencies, p.OptionalDependencies, p.Dependencies} {
+		for name, version := range section {
+			all[name] = version
+		}
+	}
+	return all
+}
+
+// scanPackageJSON scans the declared dependencies of package.json, which are superseded by the
+// resolved versions of a lockfile next to it
+func (s *NpmDependencyScanner) scanPackageJSON(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	for _, lockfile := range npmFiles[1:] {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(file), lockfile)); err == nil {
+			ctx.Debugf("Skipping %s, dependencies are read from %s", file, lockfile)
+			return nil, nil
+		}
+	}
+	ctx.Debugf("Scanning npm package from %s", file)
+
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+
+	var dependencies []*models.Dependency
+	for name, version := range pkg.all() {
+		dependency := &models.Dependency{
+			Name:    name,
+			Version: version,
+			Type:    models.DependencyTypeNpm,
+			Source:  npmSource(file, lineOf(content, strconv.Quote(name)+":")),
+		}
+		if gitURL, ref := analysis.NpmGitURL(version); gitURL != "" {
+			dependency.Git = gitURL
+			dependency.Version = ref
+		} else {
+			s.resolveGitURL(ctx, dependency)
+		}
+
+		if !ctx.Matches(dependency) {
+			continue
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	sortNpmDependencies(dependencies)
+	ctx.Debugf("Found %d npm dependencies", len(dependencies))
+	return dependencies, nil
+}
+
+// lockPackage is a package installed by a lockfile
+type lockPackage struct {
+	name    string
+	version string
+	// resolved is the URL the package is fetched from, a Git URL for Git dependencies
+	resolved string
+	line     int
+	// dependencies are the keys of the packages it depends on
+	dependencies []string
+}
+
+// lockGraph is the dependency graph of a lockfile, packages are keyed the way the lockfile
+// refers to them, several keys may refer to the same package
+type lockGraph struct {
+	packages map[string]*lockPackage
+	// roots are the keys of the direct dependencies
+	roots []string
+}
+
+func newLockGraph() *lockGraph {
+	return &lockGraph{packages: make(map[string]*lockPackage)}
+}
+
+// dependencies walks the graph breadth first from its roots, reporting every package at the
+// depth it is first reached. When the roots are not known the packages no other package depends
+// on are used instead, packages that cannot be reached are reported as transitive.
+func (s *NpmDependencyScanner) dependencies(ctx *models.ScanContext, file string, graph *lockGraph) []*models.Dependency {
+	roots := graph.roots
+	if len(roots) == 0 {
+		roots = graph.unreferenced()
+	}
+
+	depths := make(map[*lockPackage]int)
+	var queue []*lockPackage
+	for _, key := range roots {
+		if pkg, ok := graph.packages[key]; ok {
+			if _, seen := depths[pkg]; !seen {
+				depths[pkg] = 0
+				queue = append(queue, pkg)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, key := range pkg.dependencies {
+			if child, ok := graph.packages[key]; ok {
+				if _, seen := depths[child]; !seen {
+					depths[child] = depths[pkg] + 1
+					queue = append(queue, child)
+				}
+			}
+		}
+	}
+	for _, pkg := range graph.packages {
+		if _, seen := depths[pkg]; !seen {
+			depths[pkg] = 1
+		}
+	}
+
+	var dependencies []*models.Dependency
+	for pkg, depth := range depths {
+		dependency := &models.Dependency{
+			Name:     pkg.name,
+			Version:  pkg.version,
+			Type:     models.DependencyTypeNpm,
+			Source:   npmSource(file, pkg.line),
+			Depth:    depth,
+			Indirect: depth > 0,
+		}
+		if gitURL, ref := npmGitSource(pkg.resolved); gitURL != "" {
+			dependency.Git = gitURL
+			if ref != "" {
+				dependency.Version = ref
+			}
+		} else if depth == 0 {
+			// Transitive dependencies are not resolved, as that would query the registry for
+			// every installed package
+			s.resolveGitURL(ctx, dependency)
+		}
+
+		if !ctx.Matches(dependency) {
+			continue
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	sortNpmDependencies(dependencies)
+	ctx.Debugf("Found %d npm dependencies in %s", len(dependencies), file)
+	return dependencies
+}
+
+// unreferenced returns the keys of the packages no other package depends on
+func (g *lockGraph) unreferenced() []string {
+	referenced := make(map[*lockPackage]bool)
+	for _, pkg := range g.packages {
+		for _, key := range pkg.dependencies {
+			if child, ok := g.packages[key]; ok && child != pkg {
+				referenced[child] = true
+			}
+		}
+	}
+	var roots []string
+	for key, pkg := range g.packages {
+		if !referenced[pkg] {
+			roots = append(roots, key)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// packageLock is package-lock.json, packages is used from lockfile version 2 and dependencies
+// by version 1
+type packageLock struct {
+	LockfileVersion int                         `json:"lockfileVersion"`
+	Packages        map[string]packageLockEntry `json:"packages"`
+	Dependencies    map[string]packageLockEntry `json:"dependencies"`
+}
+
+type packageLockEntry struct {
+	Name                 string                      `json:"name"`
+	Version              string                      `json:"version"`
+	Resolved             string                      `json:"resolved"`
+	Link                 bool                        `json:"link"`
+	Dependencies         json.RawMessage             `json:"dependencies"`
+	DevDependencies      map[string]string           `json:"devDependencies"`
+	PeerDependencies     map[string]string           `json:"peerDependencies"`
+	OptionalDependencies map[string]string           `json:"optionalDependencies"`
+	Requires             map[string]string           `json:"requires"`
+	Nested               map[string]packageLockEntry `json:"-"`
+}
+
+// requires returns the names of the packages an entry depends on
+func (e packageLockEntry) requires() []string {
+	var names []string
+	// dependencies are version ranges in lockfile version 2, and nested entries in version 1
+	var ranges map[string]string
+	if json.Unmarshal(e.Dependencies, &ranges) == nil {
+		for name := range ranges {
+			names = append(names, name)
+		}
+	}
+	for _, section := range []map[string]string{e.DevDependencies, e.PeerDependencies, e.OptionalDependencies, e.Requires} {
+		for name := range section {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scanPackageLock scans package-lock.json, resolving dependencies the way node does: from the
+// node_modules of the requiring package, then of each of its parents
+func (s *NpmDependencyScanner) scanPackageLock(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning npm lockfile from %s", file)
+
+	var lock packageLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse package-lock.json: %w", err)
+	}
+
+	entries := lock.Packages
+	if entries == nil {
+		// Lockfile version 1 nests the dependencies of each package
+		entries = make(map[string]packageLockEntry)
+		if err := flattenPackageLockV1(lock.Dependencies, "", entries); err != nil {
+			return nil, fmt.Errorf("failed to parse package-lock.json: %w", err)
+		}
+	}
+
+	graph := newLockGraph()
+	// Workspaces and the root package are keyed by their directory, they are not dependencies
+	// but their dependencies are direct ones
+	var importers []string
+	for key, entry := range entries {
+		if entry.Link {
+			continue
+		}
+		if !strings.Contains(key, "node_modules/") {
+			importers = append(importers, key)
+			continue
+		}
+		name := entry.Name
+		if name == "" {
+			name = key[strings.LastIndex(key, "node_modules/")+len("node_modules/"):]
+		}
+		line := lineOf(content, strconv.Quote(key)+":")
+		if line == 0 {
+			// Version 1 entries are keyed by their name
+			line = lineOf(content, strconv.Quote(name)+": {")
+		}
+		graph.packages[key] = &lockPackage{
+			name:     name,
+			version:  entry.Version,
+			resolved: entry.Resolved,
+			line:     line,
+		}
+	}
+
+	resolve := func(from, name string) string {
+		for dir := from; ; dir = parentPackage(dir) {
+			key := path.Join(dir, "node_modules", name)
+			if entry, ok := entries[key]; ok {
+				// Workspace packages are linked from node_modules
+				if entry.Link {
+					return ""
+				}
+				return key
+			}
+			if dir == "" {
+				return ""
+			}
+		}
+	}
+
+	for key, pkg := range graph.packages {
+		for _, name := range entries[key].requires() {
+			if dep := resolve(key, name); dep != "" {
+				pkg.dependencies = append(pkg.dependencies, dep)
+			}
+		}
+	}
+	sort.Strings(importers)
+	for _, importer := range importers {
+		for _, name := range entries[importer].requires() {
+			if dep := resolve(importer, name); dep != "" {
+				graph.roots = append(graph.roots, dep)
+			}
+		}
+	}
+
+	// Version 1 lockfiles do not record the root package, its dependencies are in package.json
+	if len(graph.roots) == 0 {
+		if pkg, ok := readPackageJSON(file); ok {
+			for name := range pkg.all() {
+				if dep := resolve("", name); dep != "" {
+					graph.roots = append(graph.roots, dep)
+				}
+			}
+		}
+	}
+
+	return s.dependencies(ctx, file, graph), nil
+}
+
+// flattenPackageLockV1 keys the nested entries of a version 1 lockfile by their node_modules path
+// as version 2 does
+func flattenPackageLockV1(dependencies map[string]packageLockEntry, parent string, entries map[string]packageLockEntry) error {
+	for name, entry := range dependencies {
+		key := path.Join(parent, "node_modules", name)
+		var nested map[string]packageLockEntry
+		if len(entry.Dependencies) > 0 {
+			if err := json.Unmarshal(entry.Dependencies, &nested); err != nil {
+				return err
+			}
+		}
+		entry.Dependencies = nil
+		entries[key] = entry
+		if err := flattenPackageLockV1(nested, key, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parentPackage returns the directory of the package that has key in its node_modules
+func parentPackage(key string) string {
+	if i := strings.LastIndex(key, "/node_modules/"); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+// yarnBerryEntry is a package of a yarn 2+ lockfile
+type yarnBerryEntry struct {
+	Version              string            `yaml:"version"`
+	Resolution           string            `yaml:"resolution"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+	PeerDependencies     map[string]string `yaml:"peerDependencies"`
+}
+
+// scanYarnLock scans yarn.lock, which keys packages by the descriptors resolving to them, e.g.
+// "lodash@^4.17.0, lodash@^4.17.21"
+func (s *NpmDependencyScanner) scanYarnLock(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning yarn lockfile from %s", file)
+
+	var graph *lockGraph
+	var err error
+	if bytes.Contains(content, []byte("\n__metadata:")) || bytes.HasPrefix(content, []byte("__metadata:")) {
+		graph, err = parseYarnBerryLock(content)
+	} else {
+		graph, err = parseYarnClassicLock(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse yarn.lock: %w", err)
+	}
+
+	// Classic lockfiles do not record the root package, its dependencies are in package.json
+	if len(graph.roots) == 0 {
+		if pkg, ok := readPackageJSON(file); ok {
+			for name, version := range pkg.all() {
+				graph.roots = append(graph.roots, name+"@"+version)
+			}
+		}
+	}
+
+	return s.dependencies(ctx, file, graph), nil
+}
+
+// parseYarnBerryLock parses the YAML lockfile of yarn 2+, where workspaces are packages
+// resolved from workspace: descriptors whose dependencies are the direct ones
+func parseYarnBerryLock(content []byte) (*lockGraph, error) {
+	var entries map[string]yarnBerryEntry
+	if err := yaml.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+
+	graph := newLockGraph()
+	var workspaces []*lockPackage
+	for key, entry := range entries {
+		if key == "__metadata" {
+			continue
+		}
+		name, reference := splitDescriptor(entry.Resolution)
+		pkg := &lockPackage{
+			name:     name,
+			version:  entry.Version,
+			resolved: strings.TrimPrefix(reference, "git+"),
+			line:     lineOfKey(content, key),
+		}
+		for _, section := range []map[string]string{entry.Dependencies, entry.OptionalDependencies} {
+			for dep, version := range section {
+				pkg.dependencies = append(pkg.dependencies, dep+"@"+version)
+				if !strings.Contains(version, ":") {
+					// Registry dependencies are keyed with the npm: protocol
+					pkg.dependencies = append(pkg.dependencies, dep+"@npm:"+version)
+				}
+			}
+		}
+		sort.Strings(pkg.dependencies)
+
+		if strings.HasPrefix(reference, "workspace:") {
+			workspaces = append(workspaces, pkg)
+			continue
+		}
+		for _, descriptor := range strings.Split(key, ",") {
+			graph.packages[strings.TrimSpace(descriptor)] = pkg
+		}
+	}
+
+	for _, workspace := range workspaces {
+		graph.roots = append(graph.roots, workspace.dependencies...)
+	}
+	sort.Strings(graph.roots)
+	return graph, nil
+}
+
+// splitDescriptor splits a descriptor such as @babel/core@npm:7.1.2 into the package name and
+// the reference, the name may start with the @ of a scope
+func splitDescriptor(descriptor string) (string, string) {
+	at := strings.Index(descriptor[min(1, len(descriptor)):], "@")
+	if at < 0 {
+		return descriptor, ""
+	}
+	at++
+	return descriptor[:at], descriptor[at+1:]
+}
+
+// parseYarnClassicLock parses the lockfile of yarn 1, an indented format of package blocks
+// headed by their descriptors with version, resolved and dependencies fields
+func parseYarnClassicLock(content []byte) (*lockGraph, error) {
+	graph := newLockGraph()
+	var pkg *lockPackage
+	inDependencies := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		switch {
+		case indent == 0:
+			pkg = &lockPackage{line: lineNo}
+			for _, descriptor := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				descriptor = strings.Trim(strings.TrimSpace(descriptor), `"`)
+				if pkg.name == "" {
+					pkg.name, _ = splitDescriptor(descriptor)
+				}
+				graph.packages[descriptor] = pkg
+			}
+			inDependencies = false
+		case pkg == nil:
+			return nil, fmt.Errorf("line %d: field outside of a package", lineNo)
+		case indent == 2:
+			key, value := yarnField(trimmed)
+			switch key {
+			case "version":
+				pkg.version = value
+			case "resolved":
+				pkg.resolved = value
+			}
+			inDependencies = key == "dependencies:" || key == "optionalDependencies:"
+		case inDependencies:
+			name, version := yarnField(trimmed)
+			pkg.dependencies = append(pkg.dependencies, name+"@"+version)
+		}
+	}
+	return graph, scanner.Err()
+}
+
+// yarnField splits a line such as `version "1.2.3"` or `"@babel/core" "^7.0.0"` into its
+// unquoted key and value
+func yarnField(line string) (string, string) {
+	var key string
+	if strings.HasPrefix(line, `"`) {
+		if end := strings.Index(line[1:], `"`); end >= 0 {
+			key, line = line[1:end+1], line[end+2:]
+		}
+	} else {
+		key, line, _ = strings.Cut(line, " ")
+	}
+	return key, strings.Trim(strings.TrimSpace(line), `"`)
+}
+
+// pnpmLock is pnpm-lock.yaml. Importers are the workspaces of version 6 and later lockfiles,
+// version 5 lockfiles of a single project list its dependencies at the top level instead.
+// Version 9 lockfiles moved the dependencies of packages to snapshots.
+type pnpmLock struct {
+	LockfileVersion      interface{}             `yaml:"lockfileVersion"`
+	Importers            map[string]pnpmImporter `yaml:"importers"`
+	Dependencies         map[string]pnpmVersion  `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmVersion  `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmVersion  `yaml:"optionalDependencies"`
+	Packages             map[string]pnpmPackage  `yaml:"packages"`
+	Snapshots            map[string]pnpmPackage  `yaml:"snapshots"`
+}
+
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmVersion `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmVersion `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmVersion `yaml:"optionalDependencies"`
+}
+
+// pnpmVersion is the version an importer resolved a dependency to, either the version itself or
+// an object with the specifier and the version
+type pnpmVersion string
+
+func (v *pnpmVersion) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = pnpmVersion(node.Value)
+		return nil
+	}
+	var versioned struct {
+		Version string `yaml:"version"`
+	}
+	if err := node.Decode(&versioned); err != nil {
+		return err
+	}
+	*v = pnpmVersion(versioned.Version)
+	return nil
+}
+
+type pnpmPackage struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Resolution struct {
+		Tarball string `yaml:"tarball"`
+		Repo    string `yaml:"repo"`
+		Commit  string `yaml:"commit"`
+	} `yaml:"resolution"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// scanPnpmLock scans pnpm-lock.yaml
+func (s *NpmDependencyScanner) scanPnpmLock(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning pnpm lockfile from %s", file)
+
+	var lock pnpmLock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse pnpm-lock.yaml: %w", err)
+	}
+
+	graph := newLockGraph()
+	keys := make(map[string]bool)
+	for key := range lock.Packages {
+		keys[key] = true
+	}
+	for key := range lock.Snapshots {
+		keys[key] = true
+	}
+	for key := range keys {
+		// Version 9 packages are keyed without the peer dependencies of their snapshots
+		info, ok := lock.Packages[key]
+		if !ok {
+			info = lock.Packages[pnpmPeerSuffix.ReplaceAllString(key, "")]
+		}
+		name, version := pnpmPackageKey(key)
+		if info.Name != "" {
+			name = info.Name
+		}
+		if info.Version != "" {
+			version = info.Version
+		}
+		pkg := &lockPackage{name: name, version: version, line: lineOfKey(content, key)}
+		if info.Resolution.Repo != "" {
+			// pnpm records the repository and commit of Git dependencies
+			pkg.resolved = info.Resolution.Repo + "#" + info.Resolution.Commit
+			if strings.HasPrefix(pkg.resolved, "https://") {
+				pkg.resolved = "git+" + pkg.resolved
+			}
+		} else {
+			pkg.resolved = info.Resolution.Tarball
+		}
+
+		dependencies := lock.Packages[key]
+		if snapshot, ok := lock.Snapshots[key]; ok {
+			dependencies = snapshot
+		}
+		for _, section := range []map[string]string{dependencies.Dependencies, dependencies.OptionalDependencies} {
+			for dep, depVersion := range section {
+				pkg.dependencies = append(pkg.dependencies, pnpmKeys(dep, depVersion)...)
+			}
+		}
+		graph.packages[key] = pkg
+	}
+
+	importers := lock.Importers
+	if importers == nil {
+		importers = map[string]pnpmImporter{".": {
+			Dependencies:         lock.Dependencies,
+			DevDependencies:      lock.DevDependencies,
+			OptionalDependencies: lock.OptionalDependencies,
+		}}
+	}
+	for _, importer := range importers {
+		for _, section := range []map[string]pnpmVersion{importer.Dependencies, importer.DevDependencies, importer.OptionalDependencies} {
+			for dep, version := range section {
+				graph.roots = append(graph.roots, pnpmKeys(dep, string(version))...)
+			}
+		}
+	}
+	sort.Strings(graph.roots)
+
+	return s.dependencies(ctx, file, graph), nil
+}
+
+var pnpmPeerSuffix = regexp.MustCompile(`\(.*\)$`)
+
+// pnpmKeys returns the keys a dependency on version of name may have in the packages of the
+// lockfiles versions, the version may also be the key itself as for Git dependencies
+func pnpmKeys(name, version string) []string {
+	if strings.HasPrefix(version, "link:") {
+		return nil
+	}
+	return []string{version, "/" + version, name + "@" + version, "/" + name + "@" + version, "/" + name + "/" + version}
+}
+
+// pnpmPackageKey returns the name and version of a package key: name@version(peers) from
+// version 9, /name@version(peers) from version 6 or /name/version_peers from version 5
+func pnpmPackageKey(key string) (string, string) {
+	if rest, ok := strings.CutPrefix(key, "/"); ok {
+		// Version 5 keys end with the version, which may be followed by peers naming other versions
+		if slash := strings.LastIndex(rest, "/"); slash > 0 && slash+1 < len(rest) && rest[slash+1] >= '0' && rest[slash+1] <= '9' {
+			version, _, _ := strings.Cut(rest[slash+1:], "_")
+			return rest[:slash], version
+		}
+		key = rest
+	}
+	return splitDescriptor(pnpmPeerSuffix.ReplaceAllString(key, ""))
+}
+
+// npmGitSource returns the Git URL and ref packages resolved from Git are fetched from, including
+// GitHub tarballs as yarn records them
+func npmGitSource(resolved string) (string, string) {
+	if gitURL, ref := analysis.NpmGitURL(resolved); gitURL != "" {
+		// yarn 2+ references commits as #commit=<sha>
+		return gitURL, strings.TrimPrefix(ref, "commit=")
+	}
+	if rest, ok := strings.CutPrefix(resolved, "https://codeload.github.com/"); ok {
+		if parts := strings.Split(rest, "/"); len(parts) == 4 && parts[2] == "tar.gz" {
+			return "https://github.com/" + parts[0] + "/" + parts[1], parts[3]
+		}
+	}
+	return "", ""
+}
+
+// resolveGitURL resolves the Git URL of a registry dependency
+func (s *NpmDependencyScanner) resolveGitURL(ctx *models.ScanContext, dependency *models.Dependency) {
+	if s.resolver == nil {
+		return
+	}
+	if gitURL, err := s.resolver.ResolveGitURL(ctx, dependency.Name, "npm"); err == nil && gitURL != "" {
+		dependency.Git = gitURL
+	}
+}
+
+// readPackageJSON reads the package.json next to a lockfile
+func readPackageJSON(lockfile string) (packageJSON, bool) {
+	var pkg packageJSON
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(lockfile), "package.json"))
+	if err != nil {
+		return pkg, false
+	}
+	return pkg, json.Unmarshal(content, &pkg) == nil
+}
+
+// lineOfKey returns the line of a YAML mapping key, which may be quoted
+func lineOfKey(content []byte, key string) int {
+	for _, text := range []string{strconv.Quote(key) + ":", "'" + key + "':", key + ":"} {
+		if line := lineOf(content, text); line > 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+// lineOf returns the first line containing text, 0 when there is none
+func lineOf(content []byte, text string) int {
+	i := bytes.Index(content, []byte(text))
+	if i < 0 {
+		return 0
+	}
+	return bytes.Count(content[:i], []byte("\n")) + 1
+}
+
+func npmSource(file string, line int) string {
+	if line == 0 {
+		return path.Base(file)
+	}
+	return fmt.Sprintf("%s:%d", path.Base(file), line)
+}
+
+func sortNpmDependencies(dependencies []*models.Dependency) {
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].Depth != dependencies[j].Depth {
+			return dependencies[i].Depth < dependencies[j].Depth
+		}
+		if dependencies[i].Name != dependencies[j].Name {
+			return dependencies[i].Name < dependencies[j].Name
+		}
+		return dependencies[i].Version < dependencies[j].Version
+	})
+}
@@ -0,0 +1,99 @@
+package dependencies_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("NpmDependencyScanner", func() {
+	scan := func(file string) []string {
+		path := filepath.Join("testdata", "npm", file)
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := dependencies.NewNpmDependencyScanner().ScanFile(models.NewScanContext(nil, "."), path, content)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, dep := range deps {
+			Expect(dep.Type).To(Equal(models.DependencyTypeNpm))
+			Expect(dep.Indirect).To(Equal(dep.Depth > 0))
+			lines = append(lines, fmt.Sprintf("%d %s@%s %s %s", dep.Depth, dep.Name, dep.Version, dep.Git, dep.Source))
+		}
+		return lines
+	}
+
+	It("should scan package.json when there is no lockfile", func() {
+		Expect(scan("package-json/package.json")).To(Equal([]string{
+			"0 arch@v1.0.0 https://github.com/flanksource/arch-unit package.json:5",
+			"0 express@^4.18.2  package.json:4",
+			"0 jest@^29.0.0  package.json:8",
+		}))
+	})
+
+	It("should skip package.json next to a lockfile", func() {
+		Expect(scan("package-lock-v1/package.json")).To(BeEmpty())
+	})
+
+	It("should scan package-lock.json with workspaces and nested node_modules", func() {
+		Expect(scan("package-lock/package-lock.json")).To(Equal([]string{
+			"0 @types/node@20.11.5  package-lock.json:30",
+			"0 express@4.18.2  package-lock.json:35",
+			"0 left-pad@5a32a9e1d2b3c4f5a6b7c8d9e0f1a2b3c4d5e6f7 https://github.com/stevemao/left-pad package-lock.json:53",
+			"0 react@18.2.0  package-lock.json:57",
+			"1 debug@2.6.9  package-lock.json:42",
+			"1 loose-envify@1.4.0  package-lock.json:64",
+			"2 ms@2.0.0  package-lock.json:49",
+		}))
+	})
+
+	It("should scan version 1 package-lock.json using the roots of package.json", func() {
+		Expect(scan("package-lock-v1/package-lock.json")).To(Equal([]string{
+			"0 express@4.16.4  package-lock.json:7",
+			"1 debug@2.6.9  package-lock.json:14",
+			"2 ms@2.0.0  package-lock.json:23",
+		}))
+	})
+
+	It("should scan yarn 1 lockfiles", func() {
+		Expect(scan("yarn-classic/yarn.lock")).To(Equal([]string{
+			"0 @babel/core@7.23.0  yarn.lock:5",
+			"0 lodash@f299b52f39486275a9e6483b60a410e06520c538 https://github.com/lodash/lodash yarn.lock:23",
+			"1 @babel/types@7.23.0  yarn.lock:13",
+			"1 debug@4.3.4  yarn.lock:17",
+			"2 ms@2.1.2  yarn.lock:27",
+		}))
+	})
+
+	It("should scan yarn 2+ lockfiles, treating workspace dependencies as direct", func() {
+		Expect(scan("yarn-berry/yarn.lock")).To(Equal([]string{
+			"0 lodash@4.17.21  yarn.lock:25",
+			"0 ms@2.1.3  yarn.lock:31",
+			"0 pad@abc123 https://github.com/stevemao/left-pad yarn.lock:37",
+		}))
+	})
+
+	It("should scan pnpm version 6 lockfiles, ignoring linked workspaces", func() {
+		Expect(scan("pnpm-v6/pnpm-lock.yaml")).To(Equal([]string{
+			"0 react-dom@18.2.0  pnpm-lock.yaml:20",
+			"0 typescript@5.3.3  pnpm-lock.yaml:31",
+			"1 react@18.2.0  pnpm-lock.yaml:27",
+			"1 scheduler@0.23.0  pnpm-lock.yaml:29",
+		}))
+	})
+
+	It("should scan pnpm version 9 lockfiles using snapshots", func() {
+		Expect(scan("pnpm-v9/pnpm-lock.yaml")).To(Equal([]string{
+			"0 @scope/pkg@1.0.0  pnpm-lock.yaml:14",
+			"0 left-pad@5a32a9e https://github.com/stevemao/left-pad pnpm-lock.yaml:18",
+			"1 ms@2.1.3  pnpm-lock.yaml:16",
+		}))
+	})
+})
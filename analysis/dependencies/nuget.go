@@ -0,0 +1,307 @@
+package dependencies
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/models"
+)
+
+func init() {
+	analysis.RegisterDependencyScanner(NewNugetDependencyScanner())
+}
+
+var nugetFiles = []string{"*.csproj", "*.fsproj", "*.vbproj", "packages.config", "packages.lock.json"}
+
+// NugetDependencyScanner scans NuGet dependencies of .NET projects from the PackageReference items
+// of project files, packages.config and packages.lock.json. The lock file reports every restored
+// package with its depth, project files are only scanned when there is no lock file next to them.
+type NugetDependencyScanner struct {
+	*analysis.BaseDependencyScanner
+}
+
+// NewNugetDependencyScanner creates a new NuGet dependency scanner
+func NewNugetDependencyScanner() *NugetDependencyScanner {
+	return &NugetDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("nuget", nugetFiles),
+	}
+}
+
+// ScanFile scans a project file, packages.config or packages.lock.json and extracts dependencies
+func (s *NugetDependencyScanner) ScanFile(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	switch strings.ToLower(filepath.Base(file)) {
+	case "packages.config":
+		return s.scanPackagesConfig(ctx, file, content)
+	case "packages.lock.json":
+		return s.scanPackagesLock(ctx, file, content)
+	default:
+		return s.scanProject(ctx, file, content)
+	}
+}
+
+// packageReference is a PackageReference item of a project file, or a PackageVersion item of
+// Directory.Packages.props when versions are managed centrally
+type packageReference struct {
+	Include         string `xml:"Include,attr"`
+	Version         string `xml:"Version,attr"`
+	VersionOverride string `xml:"VersionOverride,attr"`
+	VersionElement  string `xml:"Version"`
+	line            int
+}
+
+func (r packageReference) version() string {
+	for _, version := range []string{r.VersionOverride, r.Version, r.VersionElement} {
+		if version = strings.TrimSpace(version); version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+// scanProject scans the PackageReference items of a .csproj, .fsproj or .vbproj file
+func (s *NugetDependencyScanner) scanProject(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	if _, err := os.Stat(filepath.Join(filepath.Dir(file), "packages.lock.json")); err == nil {
+		ctx.Debugf("Skipping %s, its packages are scanned from packages.lock.json", file)
+		return nil, nil
+	}
+	ctx.Debugf("Scanning NuGet dependencies from %s", file)
+
+	references, err := readPackageReferences(content, "PackageReference")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	central := centralPackageVersions(ctx, filepath.Dir(file))
+
+	var dependencies []*models.Dependency
+	for _, reference := range references {
+		if reference.Include == "" {
+			continue // Update items change references declared elsewhere
+		}
+		version := reference.version()
+		if version == "" {
+			version = central[strings.ToLower(reference.Include)]
+		}
+		dep := &models.Dependency{
+			Name:    reference.Include,
+			Version: version,
+			Type:    models.DependencyTypeNuget,
+			Source:  npmSource(file, reference.line),
+		}
+		if ctx.Matches(dep) {
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	sortNpmDependencies(dependencies)
+	ctx.Debugf("Found %d NuGet dependencies in %s", len(dependencies), file)
+	return dependencies, nil
+}
+
+// readPackageReferences returns the items of an MSBuild file with their line
+func readPackageReferences(content []byte, item string) ([]packageReference, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var references []packageReference
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return references, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != item {
+			continue
+		}
+		line, _ := decoder.InputPos()
+		var reference packageReference
+		if err := decoder.DecodeElement(&reference, &start); err != nil {
+			return nil, err
+		}
+		reference.line = line
+		references = append(references, reference)
+	}
+}
+
+// centralPackageVersions returns the versions of Directory.Packages.props, found in the project
+// directory or its parents up to the scan root, by lower case package ID
+func centralPackageVersions(ctx *models.ScanContext, dir string) map[string]string {
+	versions := make(map[string]string)
+	root := ""
+	if ctx != nil && ctx.ScanRoot != "" {
+		root, _ = filepath.Abs(ctx.ScanRoot)
+	}
+	dir, _ = filepath.Abs(dir)
+	for {
+		content, err := os.ReadFile(filepath.Join(dir, "Directory.Packages.props"))
+		if err == nil {
+			references, err := readPackageReferences(content, "PackageVersion")
+			if err != nil {
+				ctx.Warnf("Failed to parse %s: %v", filepath.Join(dir, "Directory.Packages.props"), err)
+			}
+			for _, reference := range references {
+				versions[strings.ToLower(reference.Include)] = reference.version()
+			}
+			return versions
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir {
+			return versions
+		}
+		dir = parent
+	}
+}
+
+// packagesConfigEntry is a package of packages.config
+type packagesConfigEntry struct {
+	ID      string `xml:"id,attr"`
+	Version string `xml:"version,attr"`
+}
+
+// scanPackagesConfig scans packages.config, which lists every installed package without telling
+// direct dependencies apart
+func (s *NugetDependencyScanner) scanPackagesConfig(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning NuGet dependencies from %s", file)
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var dependencies []*models.Dependency
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "package" {
+			continue
+		}
+		line, _ := decoder.InputPos()
+		var entry packagesConfigEntry
+		if err := decoder.DecodeElement(&entry, &start); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		dep := &models.Dependency{
+			Name:    entry.ID,
+			Version: entry.Version,
+			Type:    models.DependencyTypeNuget,
+			Source:  npmSource(file, line),
+		}
+		if entry.ID != "" && ctx.Matches(dep) {
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	sortNpmDependencies(dependencies)
+	ctx.Debugf("Found %d NuGet dependencies in %s", len(dependencies), file)
+	return dependencies, nil
+}
+
+// nugetLock is packages.lock.json, listing the packages restored for every target framework
+type nugetLock struct {
+	Dependencies map[string]map[string]nugetLockEntry `json:"dependencies"`
+}
+
+type nugetLockEntry struct {
+	Type         string            `json:"type"` // Direct, Transitive, CentralTransitive or Project
+	Resolved     string            `json:"resolved"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// scanPackagesLock scans packages.lock.json, computing the depth of packages from the direct
+// dependencies of every target framework
+func (s *NugetDependencyScanner) scanPackagesLock(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning NuGet lock file from %s", file)
+
+	var lock nugetLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	frameworks := make([]string, 0, len(lock.Dependencies))
+	for framework := range lock.Dependencies {
+		frameworks = append(frameworks, framework)
+	}
+	sort.Strings(frameworks)
+
+	seen := make(map[string]*models.Dependency)
+	var dependencies []*models.Dependency
+	for _, framework := range frameworks {
+		packages := lock.Dependencies[framework]
+		// Package IDs are case insensitive
+		names := make(map[string]string, len(packages))
+		for name := range packages {
+			names[strings.ToLower(name)] = name
+		}
+
+		depths := make(map[string]int)
+		var queue []string
+		for name, entry := range packages {
+			if entry.Type == "Direct" {
+				depths[name] = 0
+				queue = append(queue, name)
+			}
+		}
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			for dependency := range packages[name].Dependencies {
+				child, ok := names[strings.ToLower(dependency)]
+				if _, visited := depths[child]; !ok || visited {
+					continue
+				}
+				depths[child] = depths[name] + 1
+				queue = append(queue, child)
+			}
+		}
+
+		for name, entry := range packages {
+			if entry.Type == "Project" {
+				continue // Project references are part of the repository
+			}
+			depth, ok := depths[name]
+			if !ok {
+				depth = 1
+			}
+			key := strings.ToLower(name) + "@" + entry.Resolved
+			if dep, ok := seen[key]; ok {
+				if depth < dep.Depth {
+					dep.Depth = depth
+					dep.Indirect = depth > 0
+				}
+				continue
+			}
+			dep := &models.Dependency{
+				Name:     name,
+				Version:  entry.Resolved,
+				Type:     models.DependencyTypeNuget,
+				Depth:    depth,
+				Indirect: depth > 0,
+				Source:   npmSource(file, lineOf(content, strconv.Quote(name)+": {")),
+			}
+			seen[key] = dep
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	var matched []*models.Dependency
+	for _, dep := range dependencies {
+		if ctx.Matches(dep) {
+			matched = append(matched, dep)
+		}
+	}
+	sortNpmDependencies(matched)
+	ctx.Debugf("Found %d NuGet dependencies in %s", len(matched), file)
+	return matched, nil
+}
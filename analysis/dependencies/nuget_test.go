@@ -0,0 +1,56 @@
+package dependencies_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("NugetDependencyScanner", func() {
+	dir := filepath.Join("testdata", "nuget")
+
+	scan := func(name string) []string {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := dependencies.NewNugetDependencyScanner().ScanFile(models.NewScanContext(nil, dir), path, content)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, dep := range deps {
+			lines = append(lines, fmt.Sprintf("%d %s %s@%s %s", dep.Depth, dep.Type, dep.Name, dep.Version, dep.Source))
+		}
+		return lines
+	}
+
+	It("should scan package references with centrally managed versions", func() {
+		Expect(scan(filepath.Join("src", "Api", "Api.csproj"))).To(Equal([]string{
+			"0 nuget Serilog@3.1.1 Api.csproj:8",
+			"0 nuget Swashbuckle.AspNetCore@6.5.0 Api.csproj:10",
+			"0 nuget xunit@2.5.0 Api.csproj:9",
+		}))
+	})
+
+	It("should scan the lock file instead of the project file next to it", func() {
+		Expect(scan(filepath.Join("src", "Worker", "Worker.csproj"))).To(BeEmpty())
+		Expect(scan(filepath.Join("src", "Worker", "packages.lock.json"))).To(Equal([]string{
+			"0 nuget Newtonsoft.Json@13.0.3 packages.lock.json:5",
+			"0 nuget Polly@8.2.0 packages.lock.json:11",
+			"1 nuget Polly.Core@8.2.0 packages.lock.json:20",
+		}))
+	})
+
+	It("should scan packages.config", func() {
+		Expect(scan(filepath.Join("legacy", "packages.config"))).To(Equal([]string{
+			"0 nuget EntityFramework@6.4.4 packages.config:3",
+			"0 nuget log4net@2.0.15 packages.config:4",
+		}))
+	})
+})
@@ -325,6 +325,8 @@ func (s *Scanner) parseGitURLWithSubdir(pathOrURL string) (gitURL, version, subd
 func (s *Scanner) discoverScanFiles(dir string) ([]git.ScanJob, error) {
 	var scanJobs []git.ScanJob
 	processedGoMod := make(map[string]bool)
+	// Several patterns of a scanner may match the same file, e.g. requirements.txt and requirements*.txt
+	discovered := make(map[string]bool)
 
 	// Get all registered scanners
 	languages := s.Registry.List()
@@ -379,6 +381,10 @@ func (s *Scanner) discoverScanFiles(dir string) ([]git.ScanJob, error) {
 				}
 
 				// For non-Go files or non-module files, create normal scan job
+				if discovered[lang+":"+relPath] {
+					continue
+				}
+				discovered[lang+":"+relPath] = true
 				if lang != "go" || (!strings.HasSuffix(relPath, "go.mod") && !strings.HasSuffix(relPath, "go.sum")) {
 					scanJob := git.ScanJob{
 						Path:        dir,
@@ -0,0 +1,556 @@
+package dependencies
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var terraformFiles = []string{"*.tf", ".terraform.lock.hcl"}
+
+func init() {
+	analysis.RegisterDependencyScanner(NewTerraformDependencyScanner())
+}
+
+// TerraformDependencyScanner scans the providers and modules of Terraform configurations
+type TerraformDependencyScanner struct {
+	*analysis.BaseDependencyScanner
+	resolver *analysis.ResolutionService
+}
+
+// NewTerraformDependencyScanner creates a new Terraform dependency scanner
+func NewTerraformDependencyScanner() *TerraformDependencyScanner {
+	return &TerraformDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("terraform", terraformFiles),
+	}
+}
+
+// NewTerraformDependencyScannerWithResolver creates a new Terraform dependency scanner resolving
+// the Git URLs of registry providers and modules
+func NewTerraformDependencyScannerWithResolver(resolver *analysis.ResolutionService) *TerraformDependencyScanner {
+	return &TerraformDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("terraform", terraformFiles),
+		resolver:              resolver,
+	}
+}
+
+// ScanFile scans a Terraform configuration file or the dependency lock file
+func (s *TerraformDependencyScanner) ScanFile(ctx *models.ScanContext, filePath string, content []byte) ([]*models.Dependency, error) {
+	if filepath.Base(filePath) == ".terraform.lock.hcl" {
+		return s.scanLockFile(ctx, filePath, content)
+	}
+	ctx.Debugf("Scanning Terraform configuration from %s", filePath)
+
+	// Provider versions are read from the lock file when there is one
+	_, err := os.Stat(filepath.Join(filepath.Dir(filePath), ".terraform.lock.hcl"))
+	scan := &terraformScan{scanner: s, ctx: ctx, providers: err != nil, visited: make(map[string]bool)}
+	if err := scan.file(filePath, content); err != nil {
+		return nil, err
+	}
+
+	ctx.Debugf("Found %d Terraform dependencies in %s", len(scan.dependencies), filePath)
+	return scan.dependencies, nil
+}
+
+// terraformScan tracks the local modules visited while scanning a configuration file
+type terraformScan struct {
+	scanner      *TerraformDependencyScanner
+	ctx          *models.ScanContext
+	providers    bool
+	visited      map[string]bool
+	dependencies []*models.Dependency
+}
+
+func (t *terraformScan) file(filePath string, content []byte) error {
+	body, err := parseHCL(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	scanRoot := ""
+	if t.ctx != nil {
+		scanRoot = t.ctx.ScanRoot
+	}
+	relPath := makeRelativePath(filePath, scanRoot)
+
+	if t.providers {
+		for _, block := range body.blocks("terraform") {
+			for _, required := range block.blocks("required_providers") {
+				for _, provider := range requiredProviders(required) {
+					dep := t.scanner.providerDependency(t.ctx, provider.source, provider.version)
+					dep.Source = fmt.Sprintf("%s:%d", relPath, provider.line)
+					t.add(dep)
+				}
+			}
+		}
+	}
+
+	for _, module := range body.blocks("module") {
+		source := module.attributes["source"]
+		if source.value == "" {
+			continue
+		}
+
+		if strings.HasPrefix(source.value, "./") || strings.HasPrefix(source.value, "../") {
+			// Local modules are part of the configuration, their own dependencies are reported
+			if err := t.localModule(filepath.Join(filepath.Dir(filePath), source.value)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		dep := t.scanner.moduleDependency(t.ctx, source.value, module.attributes["version"].value)
+		dep.Source = fmt.Sprintf("%s:%d", relPath, source.line)
+		t.add(dep)
+	}
+	return nil
+}
+
+// localModule scans the configuration files of a local module, whose providers are locked by the
+// lock file of the root module
+func (t *terraformScan) localModule(dir string) error {
+	if abs, err := filepath.Abs(dir); err == nil {
+		if t.visited[abs] {
+			return nil
+		}
+		t.visited[abs] = true
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	sort.Strings(files)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := t.file(file, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add appends a dependency matching the scan filters
+func (t *terraformScan) add(dep *models.Dependency) {
+	if t.ctx.Matches(dep) {
+		t.dependencies = append(t.dependencies, dep)
+	}
+}
+
+// requiredProvider is a provider of a required_providers block
+type requiredProvider struct {
+	name    string
+	source  string
+	version string
+	line    int
+}
+
+// requiredProviders returns the providers of a required_providers block, either objects with a
+// source and version or, before Terraform 0.13, a version constraint of a HashiCorp provider
+func requiredProviders(block *hclBlock) []requiredProvider {
+	var providers []requiredProvider
+	for name, attribute := range block.attributes {
+		providers = append(providers, requiredProvider{name: name, source: "hashicorp/" + name, version: attribute.value, line: attribute.line})
+	}
+	for _, object := range block.children {
+		provider := requiredProvider{name: object.typ, source: object.attributes["source"].value, version: object.attributes["version"].value, line: object.line}
+		if provider.source == "" {
+			provider.source = "hashicorp/" + object.typ
+		}
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].line < providers[j].line })
+	return providers
+}
+
+// providerDependency returns the dependency of a provider, addressed as [hostname/]namespace/type
+func (s *TerraformDependencyScanner) providerDependency(ctx *models.ScanContext, source, version string) *models.Dependency {
+	dep := &models.Dependency{
+		Name:    terraformRegistryAddress(source),
+		Version: version,
+		Type:    models.DependencyTypeTerraform,
+	}
+	s.resolveGitURL(ctx, dep)
+	return dep
+}
+
+// moduleDependency returns the dependency of a module source, either a registry address such as
+// terraform-aws-modules/vpc/aws, a Git repository or an archive
+func (s *TerraformDependencyScanner) moduleDependency(ctx *models.ScanContext, source, version string) *models.Dependency {
+	dep := &models.Dependency{
+		Name:    source,
+		Version: version,
+		Type:    models.DependencyTypeTerraform,
+	}
+
+	address, subPath, _ := strings.Cut(strings.TrimPrefix(source, "git::"), "//")
+	if !strings.Contains(source, "::") && !strings.Contains(address, "://") && isRegistryModule(address) {
+		dep.Name = terraformRegistryAddress(address)
+		if subPath != "" {
+			dep.Package = []string{subPath}
+		}
+		s.resolveGitURL(ctx, dep)
+		return dep
+	}
+
+	if gitURL, subPath, ref := kustomizeRemote(source); gitURL != "" {
+		dep.Name = strings.TrimPrefix(gitURL, "https://")
+		dep.Git = gitURL
+		dep.Version = ref
+		if subPath != "" {
+			dep.Package = []string{subPath}
+		}
+	}
+	return dep
+}
+
+// isRegistryModule reports whether a module source is a registry address,
+// [hostname/]namespace/name/provider
+func isRegistryModule(address string) bool {
+	parts := strings.Split(address, "/")
+	if len(parts) == 4 && strings.Contains(parts[0], ".") {
+		parts = parts[1:]
+	}
+	if len(parts) != 3 {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" || strings.ContainsAny(part, ".:@?") {
+			return false
+		}
+	}
+	return true
+}
+
+// terraformRegistryAddress returns a registry address without the public registry hostname
+func terraformRegistryAddress(address string) string {
+	for _, host := range []string{"registry.terraform.io/", "registry.opentofu.org/"} {
+		address = strings.TrimPrefix(address, host)
+	}
+	return address
+}
+
+// resolveGitURL resolves the Git URL of a registry provider or module
+func (s *TerraformDependencyScanner) resolveGitURL(ctx *models.ScanContext, dep *models.Dependency) {
+	if s.resolver == nil {
+		return
+	}
+	if gitURL, err := s.resolver.ResolveGitURL(ctx, dep.Name, "terraform"); err == nil && gitURL != "" {
+		dep.Git = gitURL
+	}
+}
+
+// scanLockFile scans the provider versions of .terraform.lock.hcl. The lock file also locks the
+// providers of modules, so providers the configuration next to it does not require are indirect.
+func (s *TerraformDependencyScanner) scanLockFile(ctx *models.ScanContext, filePath string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning Terraform lock file from %s", filePath)
+
+	body, err := parseHCL(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	direct := make(map[string]bool)
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(filePath), "*.tf"))
+	for _, file := range files {
+		config, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		configBody, err := parseHCL(config)
+		if err != nil {
+			continue
+		}
+		for _, block := range configBody.blocks("terraform") {
+			for _, required := range block.blocks("required_providers") {
+				for _, provider := range requiredProviders(required) {
+					direct[terraformRegistryAddress(provider.source)] = true
+				}
+			}
+		}
+	}
+
+	var dependencies []*models.Dependency
+	for _, block := range body.blocks("provider") {
+		if len(block.labels) == 0 {
+			continue
+		}
+		name := terraformRegistryAddress(block.labels[0])
+		dep := &models.Dependency{
+			Name:    name,
+			Version: block.attributes["version"].value,
+			Type:    models.DependencyTypeTerraform,
+			Source:  fmt.Sprintf("%s:%d", filepath.Base(filePath), block.line),
+		}
+		if len(files) > 0 && !direct[name] {
+			dep.Depth = 1
+			dep.Indirect = true
+		} else {
+			s.resolveGitURL(ctx, dep)
+		}
+
+		if !ctx.Matches(dep) {
+			continue
+		}
+		dependencies = append(dependencies, dep)
+	}
+
+	ctx.Debugf("Found %d Terraform providers in %s", len(dependencies), filePath)
+	return dependencies, nil
+}
+
+// hclAttribute is an attribute whose value is a string literal, other values are not kept
+type hclAttribute struct {
+	value string
+	line  int
+}
+
+// hclBlock is a block of an HCL body, attributes whose value is an object are kept as blocks
+type hclBlock struct {
+	typ        string
+	labels     []string
+	line       int
+	attributes map[string]hclAttribute
+	children   []*hclBlock
+}
+
+// blocks returns the nested blocks of a type
+func (b *hclBlock) blocks(typ string) []*hclBlock {
+	var blocks []*hclBlock
+	for _, child := range b.children {
+		if child.typ == typ {
+			blocks = append(blocks, child)
+		}
+	}
+	return blocks
+}
+
+// hclToken is a token of an HCL file, strings are unquoted
+type hclToken struct {
+	kind byte // 'i' identifier, 's' string, 'n' newline or a punctuation character
+	text string
+	line int
+}
+
+// parseHCL parses the blocks and string attributes of an HCL file, which is all the dependency
+// scanning needs, skipping the expressions of other attributes
+func parseHCL(content []byte) (*hclBlock, error) {
+	tokens, err := tokenizeHCL(string(content))
+	if err != nil {
+		return nil, err
+	}
+	parser := &hclParser{tokens: tokens}
+	body := &hclBlock{attributes: make(map[string]hclAttribute)}
+	if err := parser.body(body); err != nil {
+		return nil, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return nil, fmt.Errorf("line %d: unexpected %q", parser.tokens[parser.pos].line, parser.tokens[parser.pos].text)
+	}
+	return body, nil
+}
+
+type hclParser struct {
+	tokens []hclToken
+	pos    int
+}
+
+func (p *hclParser) peek(offset int) hclToken {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return hclToken{kind: 0}
+}
+
+// body parses attributes and blocks until the closing brace of the block or the end of the file
+func (p *hclParser) body(block *hclBlock) error {
+	for p.pos < len(p.tokens) {
+		token := p.peek(0)
+		switch {
+		case token.kind == '}':
+			return nil
+		case token.kind == 'n' || token.kind == ',':
+			p.pos++
+		case token.kind == 'i' || token.kind == 's':
+			if err := p.statement(block); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("line %d: unexpected %q", token.line, token.text)
+		}
+	}
+	return nil
+}
+
+// statement parses an attribute, "name = value", or a block, "type labels... {"
+func (p *hclParser) statement(parent *hclBlock) error {
+	name := p.peek(0)
+	p.pos++
+
+	if next := p.peek(0); next.kind == '=' || next.kind == ':' {
+		p.pos++
+		value := p.peek(0)
+		if value.kind == '{' {
+			// Objects are kept as blocks, other expressions such as for expressions are skipped
+			start := p.pos
+			p.pos++
+			if err := p.block(parent, &hclBlock{typ: name.text, line: name.line}); err == nil {
+				return nil
+			}
+			p.pos = start
+		}
+		if end := p.peek(1).kind; value.kind == 's' && (end == 'n' || end == ',' || end == '}' || end == 0) {
+			parent.attributes[name.text] = hclAttribute{value: value.text, line: value.line}
+			p.pos++
+			return nil
+		}
+		return p.skipExpression()
+	}
+
+	child := &hclBlock{typ: name.text, line: name.line}
+	for {
+		token := p.peek(0)
+		switch token.kind {
+		case 'i', 's':
+			child.labels = append(child.labels, token.text)
+			p.pos++
+		case '{':
+			p.pos++
+			return p.block(parent, child)
+		default:
+			return fmt.Errorf("line %d: expected a block after %s", token.line, name.text)
+		}
+	}
+}
+
+// block parses the body of a block after its opening brace
+func (p *hclParser) block(parent, child *hclBlock) error {
+	child.attributes = make(map[string]hclAttribute)
+	if err := p.body(child); err != nil {
+		return err
+	}
+	if p.peek(0).kind != '}' {
+		return fmt.Errorf("line %d: %s is not closed", child.line, child.typ)
+	}
+	p.pos++
+	parent.children = append(parent.children, child)
+	return nil
+}
+
+// skipExpression skips the value of an attribute up to the end of its line, or of its brackets
+// when it spans several lines
+func (p *hclParser) skipExpression() error {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		token := p.peek(0)
+		switch token.kind {
+		case '(', '[', '{':
+			depth++
+		case ')', ']':
+			depth--
+		case '}':
+			if depth == 0 {
+				return nil
+			}
+			depth--
+		case 'n', ',':
+			if depth == 0 {
+				return nil
+			}
+		}
+		p.pos++
+	}
+	return nil
+}
+
+// tokenizeHCL splits HCL into identifiers, strings, newlines and punctuation, dropping comments
+// and heredocs
+func tokenizeHCL(content string) ([]hclToken, error) {
+	var tokens []hclToken
+	line := 1
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			tokens = append(tokens, hclToken{kind: 'n', text: "\n", line: line})
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || strings.HasPrefix(content[i:], "//"):
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(content[i:i+2+end], "\n")
+			i += end + 4
+		case strings.HasPrefix(content[i:], "<<"):
+			// Heredoc, skipped up to the line holding only its delimiter
+			start := line
+			header := content[i:]
+			if nl := strings.IndexByte(header, '\n'); nl >= 0 {
+				header = header[:nl]
+			}
+			delimiter := strings.TrimSpace(strings.TrimLeft(header[2:], "-~"))
+			i += len(header)
+			for {
+				nl := strings.IndexByte(content[i:], '\n')
+				if nl < 0 {
+					return nil, fmt.Errorf("line %d: unterminated heredoc", start)
+				}
+				i += nl + 1
+				line++
+				end := strings.IndexByte(content[i:], '\n')
+				if end < 0 {
+					end = len(content) - i
+				}
+				if strings.TrimSpace(content[i:i+end]) == delimiter {
+					i += end
+					break
+				}
+			}
+			tokens = append(tokens, hclToken{kind: 's', line: start})
+		case c == '"':
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(content) && content[j] != '"'; j++ {
+				if content[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				if content[j] == '\\' && j+1 < len(content) {
+					j++
+				}
+				value.WriteByte(content[j])
+			}
+			if j >= len(content) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, hclToken{kind: 's', text: value.String(), line: line})
+			i = j + 1
+		case isHCLIdentifier(c):
+			j := i
+			for j < len(content) && isHCLIdentifier(content[j]) {
+				j++
+			}
+			tokens = append(tokens, hclToken{kind: 'i', text: content[i:j], line: line})
+			i = j
+		default:
+			tokens = append(tokens, hclToken{kind: c, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isHCLIdentifier(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c == '*' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,55 @@
+package dependencies_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("TerraformDependencyScanner", func() {
+	dir := filepath.Join("testdata", "terraform")
+
+	scan := func(name string) []string {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := dependencies.NewTerraformDependencyScanner().ScanFile(models.NewScanContext(nil, dir), path, content)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, dep := range deps {
+			lines = append(lines, fmt.Sprintf("%d %s %s@%s %s %v %s", dep.Depth, dep.Type, dep.Name, dep.Version, dep.Git, dep.Package, dep.Source))
+		}
+		return lines
+	}
+
+	It("should scan the modules of a configuration and its local modules", func() {
+		Expect(scan("main.tf")).To(Equal([]string{
+			"0 terraform terraform-aws-modules/vpc/aws@5.1.2  [] main.tf:20",
+			"0 terraform example.com/network/consul@v1.2.0 https://example.com/network/consul [modules/cluster] main.tf:28",
+			"0 terraform github.com/cloudposse/terraform-null-label@0.25.0 https://github.com/cloudposse/terraform-null-label [] modules/network/main.tf:8",
+		}))
+	})
+
+	It("should scan locked providers, those only required by modules as indirect", func() {
+		Expect(scan(".terraform.lock.hcl")).To(Equal([]string{
+			"0 terraform hashicorp/aws@5.31.0  [] .terraform.lock.hcl:4",
+			"1 terraform hashicorp/null@3.2.2  [] .terraform.lock.hcl:12",
+			"0 terraform hashicorp/random@3.6.0  [] .terraform.lock.hcl:20",
+		}))
+	})
+
+	It("should scan required providers without a lock file", func() {
+		Expect(scan(filepath.Join("modules", "network", "main.tf"))).To(Equal([]string{
+			"0 terraform hashicorp/null@>= 3.0  [] modules/network/main.tf:3",
+			"0 terraform github.com/cloudposse/terraform-null-label@0.25.0 https://github.com/cloudposse/terraform-null-label [] modules/network/main.tf:8",
+		}))
+	})
+})
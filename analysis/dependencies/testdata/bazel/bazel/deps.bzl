@@ -0,0 +1,18 @@
+load("@bazel_gazelle//:deps.bzl", "go_repository")
+load("@bazel_tools//tools/build_defs/repo:utils.bzl", "maybe")
+
+def go_dependencies():
+    go_repository(
+        name = "com_github_spf13_cobra",
+        importpath = "github.com/spf13/cobra",
+        sum = "h1:hyqWnYt1ZQShIddO5kBpj3vu05/++x6tJ6dg8EC572I=",
+        version = "v1.8.0",
+    )
+    maybe(
+        go_repository,
+        name = "org_golang_x_mod",
+        importpath = "golang.org/x/mod",
+        commit = "6e4e7b5e1d2c",
+        remote = "https://go.googlesource.com/mod",
+        vcs = "git",
+    )
@@ -0,0 +1,42 @@
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = {
+      source = "hashicorp/random"
+    }
+  }
+}
+
+locals {
+  tags = { for k, v in var.tags : k => upper(v) }
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+
+  cidr = "10.0.0.0/16"
+  azs  = ["eu-west-1a", "eu-west-1b"]
+}
+
+module "consul" {
+  source = "git::https://example.com/network/consul.git//modules/cluster?ref=v1.2.0"
+}
+
+# Local modules are followed
+module "network" {
+  source = "./modules/network"
+  name   = "${var.name}-network"
+}
+
+resource "aws_iam_policy" "policy" {
+  name = "policy"
+  policy = <<-EOT
+    { "Version": "2012-10-17" }
+  EOT
+}
@@ -0,0 +1,9 @@
+terraform {
+  required_providers {
+    null = { source = "hashicorp/null", version = ">= 3.0" }
+  }
+}
+
+module "label" {
+  source = "github.com/cloudposse/terraform-null-label?ref=0.25.0"
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/flanksource/arch-unit/git"
+	"github.com/flanksource/arch-unit/internal/capabilities"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky"
 	"github.com/flanksource/clicky/task"
@@ -87,6 +88,11 @@ func (w *DependencyWalker) queueScanJob(path, gitURL string, depth int, isLocal
 		return
 	}
 
+	// Without git the walk stops at the local dependencies, the missing tool is reported once
+	if !isLocal && capabilities.Require("git") != nil {
+		return
+	}
+
 	taskName := w.getJobName(path, gitURL, isLocal)
 
 	w.taskGroup.Add(taskName, func(taskCtx commonsCtx.Context, t *clicky.Task) ([]*models.Dependency, error) {
@@ -0,0 +1,129 @@
+package analysis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/llm"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// explainContextLines is the number of lines around a violation sent when no node encloses it
+const explainContextLines = 10
+
+// Explainer suggests remediations of violations with an LLM, reusing the suggestions cached for
+// the same rule, source and model
+type Explainer struct {
+	cache    *cache.ASTCache
+	provider llm.Provider
+}
+
+// ExplainResult counts the violations an Explainer visited
+type ExplainResult struct {
+	Generated int `json:"generated"`
+	Cached    int `json:"cached"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+}
+
+// NewExplainer creates an explainer caching suggestions in astCache
+func NewExplainer(astCache *cache.ASTCache, provider llm.Provider) *Explainer {
+	return &Explainer{cache: astCache, provider: provider}
+}
+
+// Explain sets the Suggestion of violations. At most limit suggestions are generated, 0 for no
+// limit, while cached suggestions are always used. Violations with the same prompt, e.g. several
+// calls to a forbidden package from one method, share a suggestion.
+func (e *Explainer) Explain(ctx context.Context, violations []models.Violation, limit int) (*ExplainResult, error) {
+	result := &ExplainResult{}
+	suggestions := make(map[string]string)
+	budgetSpent := false
+	for i := range violations {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		v := &violations[i]
+		prompt := BuildExplainViolationPrompt(*v, e.source(v), models.SuggestionWordLimit)
+		hash := e.promptHash(prompt)
+
+		if suggestion, ok := suggestions[hash]; ok {
+			v.Suggestion = &suggestion
+			result.Cached++
+			continue
+		}
+		suggestion, ok, err := e.cache.CachedSuggestion(hash)
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			suggestions[hash] = suggestion
+			v.Suggestion = &suggestion
+			result.Cached++
+			continue
+		}
+		if budgetSpent || (limit > 0 && result.Generated+result.Failed >= limit) {
+			result.Skipped++
+			continue
+		}
+
+		suggestion, err = e.explain(ctx, prompt)
+		if errors.Is(err, llm.ErrTokenBudget) {
+			logger.Warnf("Stopped explaining violations: %v", err)
+			budgetSpent = true
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			logger.Warnf("Failed to explain the violation at %s:%d: %v", v.File, v.Line, err)
+			result.Failed++
+			continue
+		}
+		if err := e.cache.StoreSuggestion(hash, suggestion, e.provider.Name()); err != nil {
+			return result, err
+		}
+		suggestions[hash] = suggestion
+		v.Suggestion = &suggestion
+		result.Generated++
+	}
+	return result, nil
+}
+
+// source returns the source of the innermost node around a violation, or the lines around it
+func (e *Explainer) source(v *models.Violation) []string {
+	if v.File == "" {
+		return nil
+	}
+	if node := e.cache.FindByLine(v.File, v.Line); node != nil {
+		if source, err := node.GetFullSourceCode(); err == nil && len(source) > 1 {
+			return source
+		}
+	}
+	file := &models.ASTNode{FilePath: v.File}
+	source, err := file.GetSourceCodeLines(v.Line-explainContextLines, v.Line+explainContextLines)
+	if err != nil {
+		logger.Debugf("Explaining the violation at %s:%d without its source: %v", v.File, v.Line, err)
+	}
+	return source
+}
+
+func (e *Explainer) promptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(e.provider.Name() + "\n" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+func (e *Explainer) explain(ctx context.Context, prompt string) (string, error) {
+	completion, err := e.provider.Complete(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	suggestion := models.LimitWords(completion, models.SuggestionWordLimit)
+	if suggestion == "" {
+		return "", fmt.Errorf("%s returned an empty suggestion", e.provider.Name())
+	}
+	return suggestion, nil
+}
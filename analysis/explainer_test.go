@@ -0,0 +1,76 @@
+package analysis_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Explainer", func() {
+	var astCache *cache.ASTCache
+	var provider *fakeProvider
+	var file string
+
+	violation := func(line int) models.Violation {
+		return models.Violation{
+			File:    file,
+			Line:    line,
+			Rule:    &models.Rule{Type: models.RuleTypeDeny, Package: "gorm.io/gorm", OriginalLine: "!gorm.io/gorm"},
+			Message: models.StringPtr("Login uses gorm.io/gorm"),
+			Source:  "arch-unit",
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		astCache, err = cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(astCache.Close)
+
+		file = filepath.Join(GinkgoT().TempDir(), "controller.go")
+		Expect(os.WriteFile(file, []byte("package controllers\n\nfunc Login(db *gorm.DB) {\n\tdb.First(&user)\n\tdb.Save(&user)\n}\n"), 0644)).To(Succeed())
+		_, err = astCache.StoreASTNode(&models.ASTNode{FilePath: file, PackageName: "controllers", MethodName: "Login", NodeType: models.NodeTypeMethod, StartLine: 3, EndLine: 6})
+		Expect(err).ToNot(HaveOccurred())
+		provider = &fakeProvider{completion: "Inject a UserRepository into Login instead of querying gorm directly from the controller."}
+	})
+
+	It("should explain violations from the source of the enclosing node", func() {
+		violations := []models.Violation{violation(4), violation(5)}
+		result, err := analysis.NewExplainer(astCache, provider).Explain(context.Background(), violations, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Generated).To(Equal(1))
+		Expect(result.Cached).To(Equal(1))
+
+		Expect(provider.prompts).To(HaveLen(1))
+		Expect(provider.prompts[0]).To(ContainSubstring("!gorm.io/gorm"))
+		Expect(provider.prompts[0]).To(ContainSubstring("db.Save(&user)"))
+		Expect(*violations[0].Suggestion).To(HavePrefix("Inject a UserRepository"))
+		Expect(violations[1].Suggestion).To(Equal(violations[0].Suggestion))
+	})
+
+	It("should reuse cached suggestions and bound the ones generated", func() {
+		explainer := analysis.NewExplainer(astCache, provider)
+		_, err := explainer.Explain(context.Background(), []models.Violation{violation(4)}, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		save, create := violation(5), violation(5)
+		save.Message = models.StringPtr("Login uses gorm.io/gorm.Save")
+		create.Message = models.StringPtr("Login uses gorm.io/gorm.Create")
+		violations := []models.Violation{violation(5), save, create}
+		result, err := explainer.Explain(context.Background(), violations, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Cached).To(Equal(1))
+		Expect(result.Generated).To(Equal(1))
+		Expect(result.Skipped).To(Equal(1))
+		Expect(provider.prompts).To(HaveLen(2))
+		Expect(violations[0].Suggestion).ToNot(BeNil())
+		Expect(violations[2].Suggestion).To(BeNil())
+	})
+})
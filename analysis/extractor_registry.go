@@ -12,12 +12,14 @@ var extractorRegistry = NewExtractorRegistry()
 type ExtractorRegistry struct {
 	mu         sync.RWMutex
 	extractors map[string]Extractor
+	extensions map[string]string // File extensions of languages registered by plugins
 }
 
 // NewExtractorRegistry creates a new AST extractor registry
 func NewExtractorRegistry() *ExtractorRegistry {
 	return &ExtractorRegistry{
 		extractors: make(map[string]Extractor),
+		extensions: make(map[string]string),
 	}
 }
 
@@ -33,6 +35,14 @@ func (r *ExtractorRegistry) Register(language string, extractor Extractor) {
 	r.extractors[strings.ToLower(language)] = extractor
 }
 
+// RegisterExtension maps a file extension such as .kt to the language of its extractor, for
+// languages the registry does not know
+func (r *ExtractorRegistry) RegisterExtension(ext, language string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.extensions[strings.ToLower(ext)] = strings.ToLower(language)
+}
+
 // Get retrieves an AST extractor by language
 func (r *ExtractorRegistry) Get(language string) (Extractor, bool) {
 	r.mu.RLock()
@@ -69,17 +79,31 @@ func (r *ExtractorRegistry) GetExtractorForFile(filePath string) (Extractor, str
 
 	// Map file extensions to languages
 	extToLanguage := map[string]string{
-		".go":   "go",
-		".java": "java",
-		".py":   "python",
-		".js":   "javascript",
-		".ts":   "javascript", // TypeScript uses JavaScript extractor
-		".jsx":  "javascript",
-		".tsx":  "javascript",
-		".md":   "markdown",
+		".go":          "go",
+		".java":        "java",
+		".py":          "python",
+		".ipynb":       "python", // Jupyter notebooks are extracted from their code cells
+		".js":          "javascript",
+		".ts":          "javascript", // TypeScript uses JavaScript extractor
+		".jsx":         "javascript",
+		".tsx":         "javascript",
+		".md":          "markdown",
+		".dart":        "dart",
+		".groovy":      "groovy",
+		".gvy":         "groovy",
+		".jenkinsfile": "groovy",
 	}
 
-	if language, ok := extToLanguage[ext]; ok {
+	// Jenkinsfile, Jenkinsfile.release, ...
+	if strings.HasPrefix(filepath.Base(filePath), "Jenkinsfile") {
+		ext = ".jenkinsfile"
+	}
+
+	language, ok := r.extensions[ext]
+	if !ok {
+		language, ok = extToLanguage[ext]
+	}
+	if ok {
 		if extractor, exists := r.extractors[language]; exists {
 			return extractor, language, true
 		}
@@ -128,4 +152,4 @@ func GetExtractorByLanguage(language string) (Extractor, bool) {
 // GetExtractorByFile is a convenience function to get an extractor by file path
 func GetExtractorByFile(filePath string) (Extractor, string, bool) {
 	return DefaultExtractorRegistry.GetExtractorForFile(filePath)
-}
\ No newline at end of file
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/capabilities"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky"
 	flanksourceContext "github.com/flanksource/commons/context"
@@ -73,6 +75,11 @@ func (a *GenericAnalyzer) AnalyzeFile(task *clicky.Task, filepath string, conten
 	// Extract AST using the appropriate extractor (pure operation with read-only cache)
 	task.Debugf("Calling extractor for %s (type: %T)", filepath, extractor)
 	result, err := extractor.ExtractFile(a.cache, filepath, content)
+	if capabilities.IsMissing(err) {
+		// The missing tool is reported once in the summary rather than for every file
+		task.Debugf("Skipping %s: %v", filepath, err)
+		return nil, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract AST from %s: %w", filepath, err)
 	}
@@ -110,6 +117,11 @@ func (a *GenericAnalyzer) AnalyzeFile(task *clicky.Task, filepath string, conten
 				rel.FromASTID = fromID
 			}
 		}
+		if rel.ToAST != nil {
+			if toID, exists := nodeMap[rel.ToAST.Key()]; exists {
+				rel.ToASTID = &toID
+			}
+		}
 
 		if err := a.cache.StoreASTRelationship(rel.FromASTID, rel.ToASTID, rel.LineNo, string(rel.RelationshipType), rel.Text); err != nil {
 			return nil, fmt.Errorf("failed to store AST relationship: %w", err)
@@ -135,7 +147,7 @@ func (a *GenericAnalyzer) AnalyzeFile(task *clicky.Task, filepath string, conten
 			libInfo["method"],
 			"",
 			models.NodeTypeMethod,
-			"go", // TODO: make this dynamic based on file type
+			a.libraryLanguage(filepath),
 			libInfo["framework"],
 		)
 		if err != nil {
@@ -161,8 +173,11 @@ func (a *GenericAnalyzer) AnalyzeFile(task *clicky.Task, filepath string, conten
 
 // getExtractor returns the appropriate extractor for the given file path
 func (a *GenericAnalyzer) getExtractor(filepath string) Extractor {
-	// Check cache first
-	ext := strings.ToLower(filepath[strings.LastIndex(filepath, "."):])
+	// Check cache first, keyed by extension or by file name for extensionless files such as Jenkinsfile
+	ext := strings.ToLower(path.Ext(filepath))
+	if ext == "" {
+		ext = path.Base(filepath)
+	}
 	if extractor, exists := a.extractors[ext]; exists {
 		return extractor
 	}
@@ -178,6 +193,9 @@ func (a *GenericAnalyzer) getExtractor(filepath string) Extractor {
 
 // findNodeForRelationship finds the source node for a relationship
 func (a *GenericAnalyzer) findNodeForRelationship(rel *models.ASTRelationship, nodes []*models.ASTNode) *models.ASTNode {
+	if rel.FromAST != nil {
+		return rel.FromAST
+	}
 	// This is a simplified approach - in practice, you'd need to track which node
 	// generated which relationship during extraction
 	// For now, we'll use the first method node as a fallback
@@ -191,13 +209,32 @@ func (a *GenericAnalyzer) findNodeForRelationship(rel *models.ASTRelationship, n
 
 // findNodeForLibraryRelationship finds the source node for a library relationship
 func (a *GenericAnalyzer) findNodeForLibraryRelationship(libRel *models.LibraryRelationship, nodes []*models.ASTNode) *models.ASTNode {
-	// Similar to above, simplified approach
+	// Prefer the innermost method enclosing the usage, falling back to the first method node
+	var enclosing, first *models.ASTNode
 	for _, node := range nodes {
-		if node.NodeType == models.NodeTypeMethod {
-			return node
+		if node == nil || node.NodeType != models.NodeTypeMethod {
+			continue
+		}
+		if first == nil {
+			first = node
+		}
+		if libRel.LineNo >= node.StartLine && libRel.LineNo <= node.EndLine &&
+			(enclosing == nil || node.EndLine-node.StartLine < enclosing.EndLine-enclosing.StartLine) {
+			enclosing = node
 		}
 	}
-	return nil
+	if enclosing != nil {
+		return enclosing
+	}
+	return first
+}
+
+// libraryLanguage returns the language library nodes referenced from filepath are stored under
+func (a *GenericAnalyzer) libraryLanguage(filepath string) string {
+	if language := a.detectLanguageFromPath(filepath); language != "" {
+		return language
+	}
+	return "go"
 }
 
 // parseLibraryInfo parses library information from the text field
@@ -255,6 +292,9 @@ func (a *GenericAnalyzer) AnalyzeFileWithRules(task *clicky.Task, filepath strin
 
 	// Extract AST directly
 	result, err := extractor.ExtractFile(a.cache, filepath, content)
+	if capabilities.IsMissing(err) {
+		return nil, nil
+	}
 	if err != nil || result == nil {
 		return result, err
 	}
@@ -281,8 +321,11 @@ func (a *GenericAnalyzer) AnalyzeFileWithRules(task *clicky.Task, filepath strin
 // AnalyzeGoFiles analyzes multiple Go files and returns aggregated results
 // This is a compatibility function for tests and existing code
 func AnalyzeGoFiles(rootDir string, files []string, ruleSets []models.RuleSet) (*models.AnalysisResult, error) {
-	cache := cache.MustGetASTCache()
-	analyzer := NewGenericAnalyzer(cache)
+	astCache, err := cache.GetProjectASTCache(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the AST cache of %s: %w", rootDir, err)
+	}
+	analyzer := NewGenericAnalyzer(astCache)
 
 	result := &models.AnalysisResult{
 		FileCount: len(files),
@@ -406,7 +449,7 @@ func (a *GenericAnalyzer) detectLanguageFromPath(filepath string) string {
 	switch {
 	case strings.HasSuffix(filepath, ".go"):
 		return "go"
-	case strings.HasSuffix(filepath, ".py"):
+	case strings.HasSuffix(filepath, ".py") || strings.HasSuffix(filepath, ".ipynb"):
 		return "python"
 	case strings.HasSuffix(filepath, ".js") || strings.HasSuffix(filepath, ".jsx") ||
 		 strings.HasSuffix(filepath, ".mjs") || strings.HasSuffix(filepath, ".cjs"):
@@ -419,6 +462,11 @@ func (a *GenericAnalyzer) detectLanguageFromPath(filepath string) string {
 		return "rust"
 	case strings.HasSuffix(filepath, ".sql"):
 		return "sql"
+	case strings.HasSuffix(filepath, ".dart"):
+		return "dart"
+	case strings.HasSuffix(filepath, ".groovy") || strings.HasSuffix(filepath, ".gvy") ||
+		strings.HasPrefix(path.Base(filepath), "Jenkinsfile"):
+		return "groovy"
 	default:
 		return ""
 	}
@@ -3,14 +3,21 @@ package _go
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/limits"
+	"github.com/flanksource/arch-unit/internal/offline"
 	"github.com/flanksource/arch-unit/languages"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky"
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
 )
 
 // GoDependencyScanner scans Go module dependencies from go.mod files
@@ -43,10 +50,33 @@ func (s *GoDependencyScanner) ScanFile(ctx *models.ScanContext, filepath string,
 		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
 	}
 
+	// Depths come from the module graph, without it requires marked "// indirect" are assumed
+	// to be needed by a direct dependency
+	var graph *goModGraph
+	if output, err := runGoModGraph(filepath); err != nil {
+		if ctx != nil {
+			ctx.Debugf("Transitive dependencies of %s are unavailable: %v", filepath, err)
+		}
+	} else {
+		graph = parseGoModGraph(output)
+	}
+	var depths map[string]int
+	if graph != nil {
+		var direct []string
+		for _, require := range modFile.Require {
+			if !require.Indirect {
+				direct = append(direct, require.Mod.Path)
+			}
+		}
+		depths = graph.depths(direct)
+	}
+
 	var dependencies []*models.Dependency
+	required := make(map[string]bool)
 
 	// Extract module dependencies
 	for lineNo, require := range modFile.Require {
+		required[require.Mod.Path] = true
 		// Determine dependency type
 		depType := models.DependencyTypeGo
 		if strings.HasPrefix(require.Mod.Path, "golang.org/x/") {
@@ -59,6 +89,16 @@ func (s *GoDependencyScanner) ScanFile(ctx *models.ScanContext, filepath string,
 			Type:    depType,
 			Source:  fmt.Sprintf("go.mod:%d", lineNo+1), // Line numbers are 1-based
 		}
+		if require.Syntax != nil {
+			dep.Source = fmt.Sprintf("go.mod:%d", require.Syntax.Start.Line)
+		}
+		if depth, ok := depths[require.Mod.Path]; ok {
+			dep.Depth = depth
+		} else if require.Indirect || graph != nil {
+			// Requires the direct dependencies do not reach are kept for the build of the module
+			dep.Depth = 1
+		}
+		dep.Indirect = dep.Depth > 0
 
 		// Note: Git URL resolution should be handled by a resolver service, not here
 		// This follows the pattern where dependency scanners extract dependency info
@@ -77,6 +117,31 @@ func (s *GoDependencyScanner) ScanFile(ctx *models.ScanContext, filepath string,
 		}
 	}
 
+	// Modules older than Go 1.17 do not list every module of the build in go.mod
+	if graph != nil {
+		for _, path := range graph.modules() {
+			depth, ok := depths[path]
+			if required[path] || !ok {
+				continue
+			}
+			dep := &models.Dependency{
+				Name:     path,
+				Version:  graph.selected[path],
+				Type:     models.DependencyTypeGo,
+				Source:   "go mod graph",
+				Depth:    depth,
+				Indirect: true,
+			}
+			if strings.HasPrefix(path, "golang.org/x/") {
+				dep.Type = models.DependencyTypeStdlib
+			}
+			if ctx != nil && !ctx.Matches(dep) {
+				continue
+			}
+			dependencies = append(dependencies, dep)
+		}
+	}
+
 	// Extract replace directives as they affect actual dependencies
 	for _, replace := range modFile.Replace {
 		// Find the dependency being replaced
@@ -109,6 +174,99 @@ func (s *GoDependencyScanner) ScanFile(ctx *models.ScanContext, filepath string,
 	return dependencies, nil
 }
 
+// runGoModGraph returns the output of go mod graph for the module of a go.mod file. In offline
+// mode modules missing from the module cache are not downloaded, which fails the command.
+func runGoModGraph(gomod string) ([]byte, error) {
+	if _, err := os.Stat(gomod); err != nil {
+		return nil, err
+	}
+	cmd, err := limits.CommandFor(context.Background(), "go", "mod", "graph")
+	if err != nil {
+		return nil, err
+	}
+	cmd.Dir = filepath.Dir(gomod)
+	// The graph of a workspace would include the other modules of the workspace
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+	if offline.Enabled() {
+		cmd.Env = append(cmd.Env, "GOPROXY=off")
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go mod graph failed: %w", err)
+	}
+	return output, nil
+}
+
+// goModGraph is the requirement graph printed by go mod graph
+type goModGraph struct {
+	// requires lists the requirements of each module version, keyed by path@version
+	requires map[string][]string
+	// selected is the version of each module used in the build, the highest one in the graph
+	selected map[string]string
+}
+
+// parseGoModGraph parses the "module@version requirement@version" lines of go mod graph
+func parseGoModGraph(output []byte) *goModGraph {
+	graph := &goModGraph{requires: make(map[string][]string), selected: make(map[string]string)}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		graph.requires[fields[0]] = append(graph.requires[fields[0]], fields[1])
+		for _, node := range fields {
+			path, version, ok := strings.Cut(node, "@")
+			// The main module has no version, go and toolchain are not modules
+			if !ok || path == "go" || path == "toolchain" {
+				continue
+			}
+			if current, found := graph.selected[path]; !found || semver.Compare(version, current) > 0 {
+				graph.selected[path] = version
+			}
+		}
+	}
+	return graph
+}
+
+// depths walks the graph breadth first from the direct dependencies, following the selected
+// version of each module, and returns the depth each module is first reached at
+func (g *goModGraph) depths(direct []string) map[string]int {
+	depths := make(map[string]int)
+	var queue []string
+	for _, path := range direct {
+		if _, seen := depths[path]; !seen {
+			depths[path] = 0
+			queue = append(queue, path)
+		}
+	}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		for _, node := range g.requires[path+"@"+g.selected[path]] {
+			child, _, _ := strings.Cut(node, "@")
+			if _, ok := g.selected[child]; !ok {
+				continue
+			}
+			if _, seen := depths[child]; !seen {
+				depths[child] = depths[path] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+	return depths
+}
+
+// modules returns the paths of every module in the graph, sorted
+func (g *goModGraph) modules() []string {
+	paths := make([]string, 0, len(g.selected))
+	for path := range g.selected {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // scanGoSum extracts dependency information from go.sum file
 func (s *GoDependencyScanner) scanGoSum(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
 	if !strings.HasSuffix(filepath, "go.sum") {
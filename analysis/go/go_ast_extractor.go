@@ -3,8 +3,10 @@ package _go
 import (
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/parser"
 	"go/token"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -14,12 +16,24 @@ import (
 	"github.com/flanksource/arch-unit/models"
 )
 
+// UnsafeFramework is the framework recorded for usages of unsafe, syscall, golang.org/x/sys and
+// cgo, which bypass the type system or the Go runtime
+const UnsafeFramework = "unsafe"
+
 // GoASTExtractor extracts AST information from Go source files
 type GoASTExtractor struct {
 	fileSet     *token.FileSet
 	packageName string
 	filePath    string
-	imports     map[string]string // alias -> package path
+	imports     map[string]string   // alias -> package path
+	content     []byte              // source of the file, for statement text
+	constants   map[string]ast.Expr // package level constants of the file, for raw SQL queries
+	queries     []rawQuery          // raw SQL queries of the function being extracted
+	httpCalls   []endpointCall      // HTTP calls to known URLs of the function being extracted
+	envVars     []envVarUse         // environment variables used by the function being extracted
+	typeParams  map[string]bool     // type parameters in scope, which are never package qualified
+	// build constraint of the file, recorded as build_tags metadata of its nodes
+	buildConstraint string
 }
 
 // NewGoASTExtractor creates a new Go AST extractor
@@ -42,9 +56,12 @@ func (e *GoASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath string,
 	}
 
 	e.filePath = filePath
+	e.content = content
 	e.packageName = src.Name.Name
 	result.PackageName = e.packageName
 	e.imports = make(map[string]string)
+	e.constants = fileConstants(src)
+	e.buildConstraint = e.extractBuildConstraint(src)
 
 	// Extract imports
 	for _, imp := range src.Imports {
@@ -58,6 +75,26 @@ func (e *GoASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath string,
 		}
 	}
 
+	// Embedded types may be declared after the types embedding them
+	e.linkEmbeddings(result)
+
+	// Every declaration of a file is only built when its build constraint is satisfied, and
+	// depends on a C toolchain when the file uses cgo
+	_, cgo := e.imports["C"]
+	if e.buildConstraint != "" || cgo {
+		for _, node := range result.Nodes {
+			if node.Metatdata == nil {
+				node.Metatdata = make(map[string]string)
+			}
+			if e.buildConstraint != "" {
+				node.Metatdata["build_tags"] = e.buildConstraint
+			}
+			if cgo {
+				node.Metatdata["cgo"] = "true"
+			}
+		}
+	}
+
 	return result, nil
 }
 
@@ -69,14 +106,34 @@ func (e *GoASTExtractor) extractImport(imp *ast.ImportSpec) {
 	if imp.Name != nil {
 		alias = imp.Name.Name
 	} else {
-		// Use last part of package path as default alias
+		// Use last part of package path as default alias, skipping a major version suffix
+		// as in github.com/go-resty/resty/v2 or gopkg.in/yaml.v3
 		parts := strings.Split(pkgPath, "/")
 		alias = parts[len(parts)-1]
+		if len(parts) > 1 && isMajorVersion(alias) {
+			alias = parts[len(parts)-2]
+		}
+		if dot := strings.LastIndex(alias, ".v"); dot > 0 && isMajorVersion(alias[dot+1:]) {
+			alias = alias[:dot]
+		}
 	}
 
 	e.imports[alias] = pkgPath
 }
 
+// isMajorVersion returns true for the version suffix of a module path, e.g. v2
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // extractDeclaration processes top-level declarations
 func (e *GoASTExtractor) extractDeclaration(cache cache.ReadOnlyCache, decl ast.Decl, result *types.ASTResult) error {
 	switch d := decl.(type) {
@@ -93,11 +150,20 @@ func (e *GoASTExtractor) extractGenDecl(cache cache.ReadOnlyCache, decl *ast.Gen
 	for _, spec := range decl.Specs {
 		switch s := spec.(type) {
 		case *ast.TypeSpec:
-			if err := e.extractTypeSpec(cache, s, result); err != nil {
+			doc := s.Doc
+			if doc == nil && !decl.Lparen.IsValid() {
+				doc = decl.Doc
+			}
+			if err := e.extractTypeSpec(cache, s, doc, result); err != nil {
 				return err
 			}
 		case *ast.ValueSpec:
-			if err := e.extractValueSpec(cache, s, decl.Tok == token.CONST, result); err != nil {
+			// Directives of ungrouped declarations are part of the declaration's doc comment
+			doc := s.Doc
+			if doc == nil && !decl.Lparen.IsValid() {
+				doc = decl.Doc
+			}
+			if err := e.extractValueSpec(cache, s, doc, decl.Tok == token.CONST, result); err != nil {
 				return err
 			}
 		}
@@ -106,23 +172,36 @@ func (e *GoASTExtractor) extractGenDecl(cache cache.ReadOnlyCache, decl *ast.Gen
 }
 
 // extractTypeSpec processes type declarations
-func (e *GoASTExtractor) extractTypeSpec(cache cache.ReadOnlyCache, spec *ast.TypeSpec, result *types.ASTResult) error {
+func (e *GoASTExtractor) extractTypeSpec(cache cache.ReadOnlyCache, spec *ast.TypeSpec, doc *ast.CommentGroup, result *types.ASTResult) error {
 	typeName := spec.Name.Name
 	startPos := e.fileSet.Position(spec.Pos())
 	endPos := e.fileSet.Position(spec.End())
 
+	// Type parameters are in scope for the fields and methods of a generic type
+	e.typeParams = nil
+	typeParameters := e.extractTypeParameters(spec.TypeParams)
+	defer func() { e.typeParams = nil }()
+
 	// Create type node
 	typeNode := &models.ASTNode{
-		FilePath:     e.filePath,
-		PackageName:  e.packageName,
-		TypeName:     typeName,
-		NodeType:     models.NodeTypeType,
-		StartLine:    startPos.Line,
-		EndLine:      endPos.Line,
-		LineCount:    endPos.Line - startPos.Line + 1,
-		IsPrivate:    e.isPrivate(typeName),
-		LastModified: time.Now(),
+		FilePath:       e.filePath,
+		PackageName:    e.packageName,
+		TypeName:       typeName,
+		NodeType:       models.NodeTypeType,
+		StartLine:      startPos.Line,
+		EndLine:        endPos.Line,
+		LineCount:      endPos.Line - startPos.Line + 1,
+		TypeParameters: typeParameters,
+		IsPrivate:      e.isPrivate(typeName),
+		LastModified:   time.Now(),
 	}
+	switch spec.Type.(type) {
+	case *ast.StructType:
+		typeNode.Metatdata = map[string]string{"kind": "struct"}
+	case *ast.InterfaceType:
+		typeNode.Metatdata = map[string]string{"kind": "interface"}
+	}
+	markDeprecated(typeNode, doc)
 
 	result.AddNode(typeNode)
 
@@ -146,13 +225,20 @@ func (e *GoASTExtractor) extractTypeSpec(cache cache.ReadOnlyCache, spec *ast.Ty
 // extractStructFields processes struct fields
 func (e *GoASTExtractor) extractStructFields(cache cache.ReadOnlyCache, parentNode *models.ASTNode, typeName string, structType *ast.StructType, result *types.ASTResult) error {
 	for _, field := range structType.Fields.List {
+		// Embedded fields have no names, the struct inherits their fields and methods
+		if len(field.Names) == 0 {
+			e.addEmbedding(parentNode, field.Type, result)
+			continue
+		}
+
 		// Get field type with full qualified name
 		fieldType := e.getFullQualifiedTypeString(field.Type)
 
 		// Extract default value from struct tag if present
 		var defaultValue *string
+		var tagValue string
 		if field.Tag != nil {
-			tagValue := strings.Trim(field.Tag.Value, "`")
+			tagValue = strings.Trim(field.Tag.Value, "`")
 			if defaultVal := e.extractDefaultFromTag(tagValue); defaultVal != "" {
 				defaultValue = &defaultVal
 			}
@@ -172,6 +258,14 @@ func (e *GoASTExtractor) extractStructFields(cache cache.ReadOnlyCache, parentNo
 				IsPrivate:    e.isPrivate(name.Name),
 				LastModified: time.Now(),
 			}
+			markDeprecated(fieldNode, field.Doc)
+			if tagValue != "" {
+				// Recorded for annotation rules, e.g. requiring json tags
+				if fieldNode.Metatdata == nil {
+					fieldNode.Metatdata = make(map[string]string)
+				}
+				fieldNode.Metatdata[models.MetadataTags] = tagValue
+			}
 
 			result.AddNode(fieldNode)
 		}
@@ -182,36 +276,88 @@ func (e *GoASTExtractor) extractStructFields(cache cache.ReadOnlyCache, parentNo
 // extractInterfaceMethods processes interface methods
 func (e *GoASTExtractor) extractInterfaceMethods(cache cache.ReadOnlyCache, parentNode *models.ASTNode, typeName string, interfaceType *ast.InterfaceType, result *types.ASTResult) error {
 	for _, method := range interfaceType.Methods.List {
-		if len(method.Names) > 0 {
-			methodName := method.Names[0].Name
-
-			methodNode := &models.ASTNode{
-				FilePath:     e.filePath,
-				PackageName:  e.packageName,
-				TypeName:     typeName,
-				MethodName:   methodName,
-				NodeType:     models.NodeTypeMethod,
-				StartLine:    e.fileSet.Position(method.Pos()).Line,
-				EndLine:      e.fileSet.Position(method.End()).Line,
-				IsPrivate:    e.isPrivate(methodName),
-				LastModified: time.Now(),
+		if len(method.Names) == 0 {
+			// Embedded interface, unless it is a type set element of a constraint such as ~int | ~string
+			if !e.isTypeSetElement(method.Type) {
+				e.addEmbedding(parentNode, method.Type, result)
 			}
+			continue
+		}
 
-			if funcType, ok := method.Type.(*ast.FuncType); ok {
-				methodNode.Parameters = e.extractParameters(funcType)
-				methodNode.ReturnValues = e.extractReturnValues(funcType)
-				methodNode.ParameterCount = len(methodNode.Parameters)
-				methodNode.ReturnCount = len(methodNode.ReturnValues)
-			}
+		methodName := method.Names[0].Name
+
+		methodNode := &models.ASTNode{
+			FilePath:     e.filePath,
+			PackageName:  e.packageName,
+			TypeName:     typeName,
+			MethodName:   methodName,
+			NodeType:     models.NodeTypeMethod,
+			StartLine:    e.fileSet.Position(method.Pos()).Line,
+			EndLine:      e.fileSet.Position(method.End()).Line,
+			IsPrivate:    e.isPrivate(methodName),
+			LastModified: time.Now(),
+		}
 
-			result.AddNode(methodNode)
+		if funcType, ok := method.Type.(*ast.FuncType); ok {
+			methodNode.Parameters = e.extractParameters(funcType)
+			methodNode.ReturnValues = e.extractReturnValues(funcType)
+			methodNode.ParameterCount = len(methodNode.Parameters)
+			methodNode.ReturnCount = len(methodNode.ReturnValues)
 		}
+		markDeprecated(methodNode, method.Doc)
+
+		result.AddNode(methodNode)
 	}
 	return nil
 }
 
+// addEmbedding records an embedded struct field or interface as an inheritance relationship from
+// the embedding type, with the fully qualified embedded type as text, e.g. *github.com/acme/store.Base
+func (e *GoASTExtractor) addEmbedding(parentNode *models.ASTNode, expr ast.Expr, result *types.ASTResult) {
+	result.AddRelationship(&models.ASTRelationship{
+		FromAST:          parentNode,
+		LineNo:           e.fileSet.Position(expr.Pos()).Line,
+		RelationshipType: models.RelationshipTypeInheritance,
+		Text:             e.getFullQualifiedTypeString(expr),
+	})
+}
+
+// isTypeSetElement returns true for interface elements that restrict a type set rather than
+// embed an interface, e.g. int, ~string or int | float64
+func (e *GoASTExtractor) isTypeSetElement(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr:
+		return true
+	case *ast.Ident:
+		return t.Name != "error" && e.isPrimitiveType(t.Name)
+	}
+	return false
+}
+
+// linkEmbeddings points inheritance relationships at the embedded type when it is declared in
+// the same file. Embedded types from other files are only known by their qualified name.
+func (e *GoASTExtractor) linkEmbeddings(result *types.ASTResult) {
+	typeNodes := make(map[string]*models.ASTNode)
+	for _, node := range result.Nodes {
+		if node.NodeType == models.NodeTypeType {
+			typeNodes[e.packageName+"."+node.TypeName] = node
+		}
+	}
+
+	for _, rel := range result.Relationships {
+		if rel.RelationshipType != models.RelationshipTypeInheritance || rel.FromAST == nil {
+			continue
+		}
+		// Strip the pointer and the type arguments of generic types, e.g. *pkg.List[int]
+		name, _, _ := strings.Cut(strings.TrimPrefix(rel.Text, "*"), "[")
+		if node, ok := typeNodes[name]; ok {
+			rel.ToAST = node
+		}
+	}
+}
+
 // extractValueSpec processes variable and constant declarations
-func (e *GoASTExtractor) extractValueSpec(cache cache.ReadOnlyCache, spec *ast.ValueSpec, isConstant bool, result *types.ASTResult) error {
+func (e *GoASTExtractor) extractValueSpec(cache cache.ReadOnlyCache, spec *ast.ValueSpec, doc *ast.CommentGroup, isConstant bool, result *types.ASTResult) error {
 	for _, name := range spec.Names {
 		if name.Name == "_" {
 			continue // Skip blank identifiers
@@ -227,12 +373,122 @@ func (e *GoASTExtractor) extractValueSpec(cache cache.ReadOnlyCache, spec *ast.V
 			IsPrivate:    e.isPrivate(name.Name),
 			LastModified: time.Now(),
 		}
+		markDeprecated(varNode, doc)
 
 		result.AddNode(varNode)
+		e.extractEmbedDirectives(varNode, doc, result)
 	}
 	return nil
 }
 
+// extractEmbedDirectives records the patterns of //go:embed directives as file_op relationships
+// from the variable holding the embedded files
+func (e *GoASTExtractor) extractEmbedDirectives(varNode *models.ASTNode, doc *ast.CommentGroup, result *types.ASTResult) {
+	if doc == nil {
+		return
+	}
+	for _, comment := range doc.List {
+		args, ok := strings.CutPrefix(comment.Text, "//go:embed")
+		if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+			continue
+		}
+		for _, pattern := range embedPatterns(args) {
+			result.AddRelationship(&models.ASTRelationship{
+				FromAST:          varNode,
+				LineNo:           e.fileSet.Position(comment.Pos()).Line,
+				RelationshipType: models.RelationshipTypeFileOp,
+				Text:             pattern,
+			})
+		}
+	}
+}
+
+// markDeprecated records the notice of a "Deprecated:" paragraph of a doc comment as the
+// deprecated metadata of a node, following the Go convention for deprecated identifiers
+func markDeprecated(node *models.ASTNode, doc *ast.CommentGroup) {
+	if doc == nil {
+		return
+	}
+	for _, paragraph := range strings.Split(doc.Text(), "\n\n") {
+		notice, ok := strings.CutPrefix(strings.TrimSpace(paragraph), "Deprecated:")
+		if !ok {
+			continue
+		}
+		notice = strings.Join(strings.Fields(notice), " ")
+		if notice == "" {
+			notice = "true"
+		}
+		if node.Metatdata == nil {
+			node.Metatdata = make(map[string]string)
+		}
+		node.Metatdata[models.MetadataDeprecated] = notice
+		return
+	}
+}
+
+// embedPatterns splits the arguments of a //go:embed directive, which are space separated
+// and may be quoted to include spaces, e.g. images/*.png "my file.txt"
+func embedPatterns(args string) []string {
+	var patterns []string
+	args = strings.TrimSpace(args)
+	for args != "" {
+		var pattern string
+		switch args[0] {
+		case '"', '`':
+			end := strings.IndexByte(args[1:], args[0])
+			if end < 0 {
+				return patterns // unterminated quote, rejected by the go command as well
+			}
+			if unquoted, err := strconv.Unquote(args[:end+2]); err == nil {
+				pattern = unquoted
+			}
+			args = args[end+2:]
+		default:
+			pattern, args, _ = strings.Cut(args, " ")
+		}
+		if pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+		args = strings.TrimSpace(args)
+	}
+	return patterns
+}
+
+// extractBuildConstraint returns the //go:build constraint of a file, e.g. linux && !cgo,
+// falling back to legacy // +build lines
+func (e *GoASTExtractor) extractBuildConstraint(src *ast.File) string {
+	var plusBuild []constraint.Expr
+	for _, group := range src.Comments {
+		// Constraints must appear before the package clause
+		if group.Pos() >= src.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if !constraint.IsGoBuild(comment.Text) && !constraint.IsPlusBuild(comment.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(comment.Text)
+			if err != nil {
+				continue
+			}
+			if constraint.IsGoBuild(comment.Text) {
+				return expr.String()
+			}
+			plusBuild = append(plusBuild, expr)
+		}
+	}
+
+	if len(plusBuild) == 0 {
+		return ""
+	}
+	// Multiple // +build lines must all be satisfied
+	expr := plusBuild[0]
+	for _, next := range plusBuild[1:] {
+		expr = &constraint.AndExpr{X: expr, Y: next}
+	}
+	return expr.String()
+}
+
 // extractFuncDecl processes function declarations
 func (e *GoASTExtractor) extractFuncDecl(cache cache.ReadOnlyCache, decl *ast.FuncDecl, receiverType string, result *types.ASTResult) error {
 	funcName := decl.Name.Name
@@ -241,10 +497,17 @@ func (e *GoASTExtractor) extractFuncDecl(cache cache.ReadOnlyCache, decl *ast.Fu
 
 	// Determine if this is a method (has receiver) or function
 	typeName := receiverType
+	e.typeParams = nil
+	defer func() { e.typeParams = nil }()
 	if decl.Recv != nil && len(decl.Recv.List) > 0 {
 		typeName = e.getReceiverTypeName(decl.Recv.List[0].Type)
+		// Methods of generic types re-declare the type parameters in the receiver, e.g. (s *Stack[T])
+		e.addReceiverTypeParams(decl.Recv.List[0].Type)
 	}
 
+	// Type parameters of generic functions, e.g. func Map[T, U any](...)
+	typeParameters := e.extractTypeParameters(decl.Type.TypeParams)
+
 	// Calculate cyclomatic complexity
 	complexity := e.calculateCyclomaticComplexity(decl.Body)
 
@@ -267,11 +530,20 @@ func (e *GoASTExtractor) extractFuncDecl(cache cache.ReadOnlyCache, decl *ast.Fu
 		CyclomaticComplexity: complexity,
 		Parameters:           parameters,
 		ReturnValues:         returnValues,
+		TypeParameters:       typeParameters,
 		ParameterCount:       len(parameters),
 		ReturnCount:          len(returnValues),
 		IsPrivate:            e.isPrivate(funcName),
 		LastModified:         time.Now(),
 	}
+	markDeprecated(funcNode, decl.Doc)
+
+	if decl.Body != nil {
+		funcNode.Statements = e.extractStatements(decl.Body.List)
+		e.addQueryRelationships(funcNode, result)
+		e.addHTTPRelationships(funcNode, result)
+		e.addEnvRelationships(funcNode, result)
+	}
 
 	result.AddNode(funcNode)
 
@@ -292,11 +564,67 @@ func (e *GoASTExtractor) getReceiverTypeName(expr ast.Expr) string {
 		return t.Name
 	case *ast.StarExpr:
 		return e.getReceiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return e.getReceiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return e.getReceiverTypeName(t.X)
 	default:
 		return ""
 	}
 }
 
+// addReceiverTypeParams brings the type parameters named in a generic receiver into scope
+func (e *GoASTExtractor) addReceiverTypeParams(expr ast.Expr) {
+	var indices []ast.Expr
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		e.addReceiverTypeParams(t.X)
+		return
+	case *ast.IndexExpr:
+		indices = []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		indices = t.Indices
+	}
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok && ident.Name != "_" {
+			if e.typeParams == nil {
+				e.typeParams = make(map[string]bool)
+			}
+			e.typeParams[ident.Name] = true
+		}
+	}
+}
+
+// extractTypeParameters brings type parameters into scope and returns them with their constraints
+func (e *GoASTExtractor) extractTypeParameters(fields *ast.FieldList) []models.Parameter {
+	if fields == nil || len(fields.List) == 0 {
+		return nil
+	}
+
+	// Register all names first, constraints may refer to other type parameters
+	if e.typeParams == nil {
+		e.typeParams = make(map[string]bool)
+	}
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			e.typeParams[name.Name] = true
+		}
+	}
+
+	var typeParameters []models.Parameter
+	for _, field := range fields.List {
+		constraint := e.getFullQualifiedTypeString(field.Type)
+		for _, name := range field.Names {
+			typeParameters = append(typeParameters, models.Parameter{
+				Name:       name.Name,
+				Type:       constraint,
+				NameLength: len(name.Name),
+			})
+		}
+	}
+	return typeParameters
+}
+
 // countParameters counts function parameters
 // countReturns counts function return values
 func (e *GoASTExtractor) extractParameters(funcType *ast.FuncType) []models.Parameter {
@@ -384,9 +712,9 @@ func (e *GoASTExtractor) getTypeString(expr ast.Expr) string {
 			return "chan " + e.getTypeString(t.Value)
 		}
 	case *ast.FuncType:
-		return "func(...)"
+		return "func" + e.signatureString(t, e.getTypeString)
 	case *ast.InterfaceType:
-		return "interface{}"
+		return e.interfaceTypeString(t, e.getTypeString)
 	case *ast.StructType:
 		return "struct{...}"
 	case *ast.SelectorExpr:
@@ -397,6 +725,8 @@ func (e *GoASTExtractor) getTypeString(expr ast.Expr) string {
 		return e.getTypeString(t.X) + "." + t.Sel.Name
 	case *ast.Ellipsis:
 		return "..." + e.getTypeString(t.Elt)
+	case *ast.IndexExpr, *ast.IndexListExpr, *ast.BinaryExpr, *ast.UnaryExpr, *ast.ParenExpr:
+		return e.genericTypeString(t, e.getTypeString)
 	default:
 		// Fallback for complex types
 		return "interface{}"
@@ -407,8 +737,8 @@ func (e *GoASTExtractor) getTypeString(expr ast.Expr) string {
 func (e *GoASTExtractor) getFullQualifiedTypeString(expr ast.Expr) string {
 	switch t := expr.(type) {
 	case *ast.Ident:
-		// Check if this is a primitive type
-		if e.isPrimitiveType(t.Name) {
+		// Check if this is a primitive type or a type parameter
+		if e.isPrimitiveType(t.Name) || e.typeParams[t.Name] {
 			return t.Name
 		}
 		// For non-primitive types in the same package, prefix with package name
@@ -437,9 +767,9 @@ func (e *GoASTExtractor) getFullQualifiedTypeString(expr ast.Expr) string {
 			return "chan " + e.getFullQualifiedTypeString(t.Value)
 		}
 	case *ast.FuncType:
-		return "func(...)"
+		return "func" + e.signatureString(t, e.getFullQualifiedTypeString)
 	case *ast.InterfaceType:
-		return "interface{}"
+		return e.interfaceTypeString(t, e.getFullQualifiedTypeString)
 	case *ast.StructType:
 		return "struct{...}"
 	case *ast.SelectorExpr:
@@ -454,19 +784,94 @@ func (e *GoASTExtractor) getFullQualifiedTypeString(expr ast.Expr) string {
 		return e.getFullQualifiedTypeString(t.X) + "." + t.Sel.Name
 	case *ast.Ellipsis:
 		return "..." + e.getFullQualifiedTypeString(t.Elt)
+	case *ast.IndexExpr, *ast.IndexListExpr, *ast.BinaryExpr, *ast.UnaryExpr, *ast.ParenExpr:
+		return e.genericTypeString(t, e.getFullQualifiedTypeString)
 	default:
 		// Fallback for complex types
 		return "interface{}"
 	}
 }
 
+// genericTypeString renders instantiated generic types (List[T], Map[K, V]) and constraint
+// expressions (~int | ~string), rendering nested types with typeString
+func (e *GoASTExtractor) genericTypeString(expr ast.Expr, typeString func(ast.Expr) string) string {
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		return typeString(t.X) + "[" + typeString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		indices := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			indices[i] = typeString(index)
+		}
+		return typeString(t.X) + "[" + strings.Join(indices, ", ") + "]"
+	case *ast.BinaryExpr:
+		if t.Op == token.OR {
+			return typeString(t.X) + " | " + typeString(t.Y)
+		}
+	case *ast.UnaryExpr:
+		if t.Op == token.TILDE {
+			return "~" + typeString(t.X)
+		}
+	case *ast.ParenExpr:
+		return "(" + typeString(t.X) + ")"
+	}
+	return "interface{}"
+}
+
+// signatureString renders the parameters and results of a function type, e.g. (T, int) (U, error)
+func (e *GoASTExtractor) signatureString(funcType *ast.FuncType, typeString func(ast.Expr) string) string {
+	fieldTypes := func(fields *ast.FieldList) []string {
+		if fields == nil {
+			return nil
+		}
+		var list []string
+		for _, field := range fields.List {
+			fieldType := typeString(field.Type)
+			for range max(len(field.Names), 1) {
+				list = append(list, fieldType)
+			}
+		}
+		return list
+	}
+
+	signature := "(" + strings.Join(fieldTypes(funcType.Params), ", ") + ")"
+	switch results := fieldTypes(funcType.Results); len(results) {
+	case 0:
+	case 1:
+		signature += " " + results[0]
+	default:
+		signature += " (" + strings.Join(results, ", ") + ")"
+	}
+	return signature
+}
+
+// interfaceTypeString renders an interface, including the type sets of constraint interfaces
+func (e *GoASTExtractor) interfaceTypeString(iface *ast.InterfaceType, typeString func(ast.Expr) string) string {
+	if iface.Methods == nil || len(iface.Methods.List) == 0 {
+		return "interface{}"
+	}
+
+	var elements []string
+	for _, field := range iface.Methods.List {
+		if funcType, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+			elements = append(elements, field.Names[0].Name+e.signatureString(funcType, typeString))
+			continue
+		}
+		// Embedded interface or type set, e.g. ~int | ~string
+		elements = append(elements, typeString(field.Type))
+	}
+	return "interface{" + strings.Join(elements, "; ") + "}"
+}
+
 // isPrimitiveType checks if a type name represents a Go primitive type
 func (e *GoASTExtractor) isPrimitiveType(typeName string) bool {
 	primitives := map[string]bool{
+		"any":        true,
 		"bool":       true,
 		"byte":       true,
 		"complex64":  true,
 		"complex128": true,
+		"comparable": true,
 		"error":      true,
 		"float32":    true,
 		"float64":    true,
@@ -556,21 +961,54 @@ func (e *GoASTExtractor) calculateCyclomaticComplexity(body *ast.BlockStmt) int
 	return complexity
 }
 
-// extractFunctionCalls extracts function calls and method invocations from function body
+// extractFunctionCalls extracts function calls and method invocations from function body.
+// Other uses of unsafe packages, such as unsafe.Pointer types or syscall constants, are recorded
+// as library references.
 func (e *GoASTExtractor) extractFunctionCalls(cache cache.ReadOnlyCache, funcNode *models.ASTNode, body *ast.BlockStmt, result *types.ASTResult) error {
+	called := make(map[*ast.SelectorExpr]bool)
 	ast.Inspect(body, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.CallExpr:
+			if sel, ok := node.Fun.(*ast.SelectorExpr); ok {
+				called[sel] = true
+			}
 			if err := e.extractCallExpr(cache, funcNode, node, result); err != nil {
 				// Log error but continue processing
 				fmt.Printf("Warning: failed to extract call expression: %v\n", err)
 			}
+		case *ast.SelectorExpr:
+			if !called[node] {
+				e.extractUnsafeReference(node, result)
+			}
 		}
 		return true
 	})
 	return nil
 }
 
+// extractUnsafeReference records a reference to a member of an unsafe package that is not called,
+// e.g. unsafe.Pointer in a declaration or syscall.SIGTERM
+func (e *GoASTExtractor) extractUnsafeReference(sel *ast.SelectorExpr, result *types.ASTResult) {
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return
+	}
+	pkgPath, isImport := e.imports[ident.Name]
+	if !isImport || !isUnsafePackage(pkgPath) {
+		return
+	}
+	result.Libraries = append(result.Libraries, &models.LibraryRelationship{
+		LineNo:           e.fileSet.Position(sel.Pos()).Line,
+		RelationshipType: models.RelationshipReference,
+		Text:             fmt.Sprintf("%s.%s (pkg=%s;class=;method=%s;framework=%s)", ident.Name, sel.Sel.Name, pkgPath, sel.Sel.Name, UnsafeFramework),
+	})
+}
+
+// isUnsafePackage returns true for packages that bypass the type system or the Go runtime
+func isUnsafePackage(pkgPath string) bool {
+	return pkgPath == "unsafe" || pkgPath == "syscall" || pkgPath == "C" || strings.HasPrefix(pkgPath, "golang.org/x/sys/")
+}
+
 // extractCallExpr processes a function call expression
 func (e *GoASTExtractor) extractCallExpr(cache cache.ReadOnlyCache, funcNode *models.ASTNode, call *ast.CallExpr, result *types.ASTResult) error {
 	callLine := e.fileSet.Position(call.Pos()).Line
@@ -679,6 +1117,10 @@ func (e *GoASTExtractor) handleLibraryCall(funcNode *models.ASTNode, line int, t
 
 // classifyLibrary determines the framework/library category
 func (e *GoASTExtractor) classifyLibrary(pkgPath string) string {
+	if isUnsafePackage(pkgPath) {
+		return UnsafeFramework
+	}
+
 	// Standard library
 	if !strings.Contains(pkgPath, "/") {
 		return "stdlib"
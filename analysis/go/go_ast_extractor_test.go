@@ -3,11 +3,14 @@ package _go
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
 )
 
 var _ = Describe("Go AST Extractor", func() {
@@ -293,4 +296,452 @@ var privateVar = "not exported"
 			Expect(methodsOnPrivate["privateMethod"]).To(BeTrue(), "privateMethod on private type should be private")
 		})
 	})
+
+	Context("when extracting generic types and functions", func() {
+		var nodes map[string]*models.ASTNode
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "generics.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			nodes = make(map[string]*models.ASTNode)
+			for _, node := range result.Nodes {
+				nodes[node.TypeName+"."+node.MethodName+node.FieldName] = node
+			}
+		})
+
+		It("should record type parameters and their constraints", func() {
+			Expect(nodes).To(HaveKey("Pair."))
+			Expect(nodes["Pair."].TypeParameters).To(Equal([]models.Parameter{
+				{Name: "K", Type: "comparable", NameLength: 1},
+				{Name: "V", Type: "any", NameLength: 1},
+			}))
+
+			Expect(nodes).To(HaveKey(".Sum"))
+			Expect(nodes[".Sum"].TypeParameters).To(ConsistOf(models.Parameter{Name: "N", Type: "generics.Number", NameLength: 1}))
+
+			Expect(nodes).To(HaveKey(".Clamp"))
+			Expect(nodes[".Clamp"].TypeParameters[0].Type).To(Equal("interface{~int | ~float64}"))
+		})
+
+		It("should not package qualify type parameters in fields", func() {
+			Expect(nodes).To(HaveKey("Pair.Key"))
+			Expect(*nodes["Pair.Key"].FieldType).To(Equal("K"))
+
+			Expect(nodes).To(HaveKey("Stack.items"))
+			Expect(*nodes["Stack.items"].FieldType).To(Equal("[]T"))
+
+			Expect(nodes).To(HaveKey("Stack.index"))
+			Expect(*nodes["Stack.index"].FieldType).To(Equal("map[string]generics.Pair[string, T]"))
+		})
+
+		It("should attach methods of generic types to the base type name", func() {
+			Expect(nodes).To(HaveKey("Stack.Push"))
+			Expect(nodes["Stack.Push"].Parameters).To(ConsistOf(models.Parameter{Name: "item", Type: "T", NameLength: 4}))
+
+			Expect(nodes).To(HaveKey("Stack.Pop"))
+			Expect(nodes["Stack.Pop"].ReturnValues).To(Equal([]models.ReturnValue{{Type: "T"}, {Type: "bool"}}))
+		})
+
+		It("should render function types and instantiated generic types", func() {
+			Expect(nodes).To(HaveKey(".Map"))
+			Expect(nodes[".Map"].Parameters).To(HaveLen(2))
+			Expect(nodes[".Map"].Parameters[0].Type).To(Equal("[]T"))
+			Expect(nodes[".Map"].Parameters[1].Type).To(Equal("func(T) U"))
+			Expect(nodes[".Map"].ReturnValues[0].Type).To(Equal("[]U"))
+
+			Expect(nodes).To(HaveKey(".Load"))
+			Expect(nodes[".Load"].Parameters[1].Type).To(Equal("func(context.Context, string) (*generics.Stack[int], error)"))
+			Expect(nodes[".Load"].ReturnValues[0].Type).To(Equal("*generics.Stack[generics.Pair[string, int]]"))
+		})
+	})
+
+	Context("when extracting embedded types", func() {
+		var embeddings []*models.ASTRelationship
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "embedding.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			embeddings = nil
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeInheritance {
+					embeddings = append(embeddings, rel)
+				}
+			}
+		})
+
+		It("should record embedded struct fields as inheritance from the struct", func() {
+			Expect(embeddings).To(HaveLen(5))
+			for _, rel := range embeddings[:3] {
+				Expect(rel.FromAST.TypeName).To(Equal("Service"))
+			}
+
+			Expect(embeddings[0].Text).To(Equal("*embedding.Base"))
+			Expect(embeddings[0].LineNo).To(Equal(10))
+			Expect(embeddings[1].Text).To(Equal("sync.Mutex"))
+			Expect(embeddings[2].Text).To(Equal("embedding.Auditable"))
+		})
+
+		It("should link embedded types declared in the same file", func() {
+			Expect(embeddings[0].ToAST).NotTo(BeNil())
+			Expect(embeddings[0].ToAST.TypeName).To(Equal("Base"))
+			Expect(embeddings[1].ToAST).To(BeNil())
+			Expect(embeddings[2].ToAST).NotTo(BeNil())
+			Expect(embeddings[2].ToAST.TypeName).To(Equal("Auditable"))
+		})
+
+		It("should record embedded interfaces but not type set elements", func() {
+			Expect(embeddings[3].FromAST.TypeName).To(Equal("ReadCloser"))
+			Expect(embeddings[3].Text).To(Equal("io.Reader"))
+			Expect(embeddings[4].FromAST.TypeName).To(Equal("ReadCloser"))
+			Expect(embeddings[4].Text).To(Equal("error"))
+		})
+	})
+
+	Context("when extracting go:embed directives and build constraints", func() {
+		var result *types.ASTResult
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "embed.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err = extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should record embedded patterns as file_op relationships from the variable", func() {
+			embedded := make(map[string][]string)
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeFileOp {
+					embedded[rel.FromAST.FieldName] = append(embedded[rel.FromAST.FieldName], rel.Text)
+				}
+			}
+			Expect(embedded).To(Equal(map[string][]string{
+				"Content": {"templates/*.html", "static"},
+				"Version": {"VERSION", "release notes.txt"},
+			}))
+		})
+
+		It("should record the build constraint on every node of the file", func() {
+			Expect(result.Nodes).NotTo(BeEmpty())
+			for _, node := range result.Nodes {
+				Expect(node.Metatdata).To(HaveKeyWithValue("build_tags", "linux && !cgo"))
+			}
+		})
+
+		It("should not record build tags for unconstrained files", func() {
+			content, err := os.ReadFile(filepath.Join("testdata", "generics.go"))
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, filepath.Join("testdata", "generics.go"), content)
+			Expect(err).NotTo(HaveOccurred())
+			for _, node := range result.Nodes {
+				Expect(node.Metatdata).NotTo(HaveKey("build_tags"))
+			}
+		})
+
+		It("should record the kind of struct and interface types", func() {
+			content, err := os.ReadFile(filepath.Join("testdata", "generics.go"))
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, filepath.Join("testdata", "generics.go"), content)
+			Expect(err).NotTo(HaveOccurred())
+			kinds := make(map[string]string)
+			for _, node := range result.Nodes {
+				if node.NodeType == models.NodeTypeType {
+					kinds[node.TypeName] = node.Metatdata["kind"]
+				}
+			}
+			Expect(kinds).To(HaveKeyWithValue("Number", "interface"))
+			Expect(kinds).To(HaveKeyWithValue("Pair", "struct"))
+		})
+	})
+
+	Context("when extracting cgo and unsafe usages", func() {
+		var result *types.ASTResult
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "lowlevel.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err = extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should flag every node of a cgo file", func() {
+			Expect(result.Nodes).NotTo(BeEmpty())
+			for _, node := range result.Nodes {
+				Expect(node.Metatdata).To(HaveKeyWithValue("cgo", "true"))
+			}
+		})
+
+		It("should record calls and references to unsafe packages with the unsafe framework", func() {
+			usages := make(map[string]string)
+			for _, lib := range result.Libraries {
+				Expect(lib.Text).To(HaveSuffix(";framework=" + UnsafeFramework + ")"))
+				usages[strings.Split(lib.Text, " ")[0]] = lib.RelationshipType
+			}
+			Expect(usages).To(Equal(map[string]string{
+				"C.malloc()":      models.RelationshipCall,
+				"C.size_t()":      models.RelationshipCall,
+				"unsafe.Pointer":  models.RelationshipReference,
+				"unsafe.Slice()":  models.RelationshipCall,
+				"syscall.Kill()":  models.RelationshipCall,
+				"syscall.SIGTERM": models.RelationshipReference,
+				"unix.Kill()":     models.RelationshipCall,
+				"unix.SIGKILL":    models.RelationshipReference,
+			}))
+		})
+
+		It("should not flag files without cgo", func() {
+			content, err := os.ReadFile(filepath.Join("testdata", "generics.go"))
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, filepath.Join("testdata", "generics.go"), content)
+			Expect(err).NotTo(HaveOccurred())
+			for _, node := range result.Nodes {
+				Expect(node.Metatdata).NotTo(HaveKey("cgo"))
+			}
+		})
+	})
+
+	Context("when extracting statements of function bodies", func() {
+		var statements []models.ASTStatement
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "statements.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, node := range result.Nodes {
+				if node.MethodName == "Import" {
+					statements = node.Statements
+				}
+			}
+		})
+
+		It("should extract the top level statements in order", func() {
+			var kinds []models.ASTStatementType
+			for _, statement := range statements {
+				kinds = append(kinds, statement.Type)
+			}
+			Expect(kinds).To(Equal([]models.ASTStatementType{
+				models.ASTStatementTypeAssignment,
+				models.ASTStatementTypeIf,
+				models.ASTStatementTypeLoop,
+				models.ASTStatementTypeIf,
+				models.ASTStatementTypeOther,
+			}))
+			Expect(statements[1].Text).To(Equal("if err != nil"))
+			Expect(statements[1].StartLine).To(Equal(17))
+			Expect(statements[2].Text).To(Equal("for _, url := range urls"))
+		})
+
+		It("should nest branches and loop bodies as children", func() {
+			ifStmt := statements[1]
+			Expect(ifStmt.Children).To(HaveLen(2))
+			Expect(ifStmt.Children[1].Text).To(Equal("else if len(data) == 0"))
+
+			switchStmt := statements[3]
+			Expect(switchStmt.Text).To(Equal("switch string(data)"))
+			Expect(switchStmt.Children).To(HaveLen(2))
+			Expect(switchStmt.Children[0].Text).To(Equal(`case "reset"`))
+			Expect(switchStmt.Children[1].Text).To(Equal("default"))
+		})
+
+		It("should classify file, HTTP and SQL calls", func() {
+			Expect(statements[0].Children).To(HaveLen(1))
+			Expect(statements[0].Children[0].Type).To(Equal(models.ASTStatementTypeFileOp))
+			Expect(statements[0].Children[0].Text).To(Equal("os.ReadFile(path)"))
+
+			loop := statements[2]
+			Expect(loop.Children[0].Children[0].Type).To(Equal(models.ASTStatementTypeHttpCall))
+			Expect(loop.Children[2].Type).To(Equal(models.ASTStatementTypeFunctionCall))
+			Expect(loop.Children[2].Text).To(Equal("defer resp.Body.Close()"))
+
+			Expect(statements[3].Children[0].Children[0].Type).To(Equal(models.ASTStatementTypeSQLQuery))
+		})
+
+		It("should skip builtins and conversions", func() {
+			printCall := statements[3].Children[1].Children[0]
+			Expect(printCall.Text).To(Equal("fmt.Println(len(data))"))
+			Expect(printCall.Children).To(BeEmpty())
+		})
+	})
+
+	Context("when extracting raw SQL queries", func() {
+		var result *types.ASTResult
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "queries.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err = extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should record string constants passed to database methods as SQL statements", func() {
+			queries := make(map[string][]string)
+			for _, node := range result.Nodes {
+				for _, statement := range node.Statements {
+					for _, child := range statement.Children {
+						if child.Type == models.ASTStatementTypeSQLQuery {
+							queries[node.MethodName] = append(queries[node.MethodName], child.Text)
+						}
+					}
+				}
+			}
+			Expect(queries).To(Equal(map[string][]string{
+				"Find":   {"SELECT id, name FROM users WHERE id = $1"},
+				"Orders": {"SELECT o.* FROM orders o JOIN users u ON u.id = o.user_id WHERE u.id = ?"},
+				// Queries built at runtime are classified by the receiver only
+				"Run": {"s.db.Exec(query)"},
+			}))
+		})
+
+		It("should link functions to the tables their queries use", func() {
+			tables := make(map[string][]string)
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeSQLQuery {
+					tables[rel.FromAST.MethodName] = append(tables[rel.FromAST.MethodName], rel.Text)
+				}
+			}
+			Expect(tables).To(Equal(map[string][]string{
+				"Find":   {"users"},
+				"Orders": {"orders", "users"},
+			}))
+		})
+	})
+
+	Context("when extracting HTTP calls", func() {
+		var result *types.ASTResult
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "http_calls.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err = extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should record the method and URL of HTTP calls when they are statically known", func() {
+			calls := make(map[string][]string)
+			for _, node := range result.Nodes {
+				for _, statement := range node.Statements {
+					for _, child := range statement.Children {
+						if child.Type == models.ASTStatementTypeHttpCall {
+							calls[node.MethodName] = append(calls[node.MethodName], child.Text)
+						}
+					}
+				}
+			}
+			Expect(calls).To(Equal(map[string][]string{
+				"Invoice": {"GET /invoices/{}"},
+				"Pay":     {"POST http://billing.svc:8080/invoices/{}/payments", "http.DefaultClient.Do(req)"},
+				"Health":  {"HEAD http://billing.svc/healthz", "GET http://billing.svc:8080/status"},
+				// URLs only known at runtime are classified by the called function only
+				"Fetch": {"http.Get(url)"},
+			}))
+		})
+
+		It("should link functions to the URLs they call", func() {
+			urls := make(map[string][]string)
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeHTTPCall {
+					urls[rel.FromAST.MethodName] = append(urls[rel.FromAST.MethodName], rel.Text)
+				}
+			}
+			Expect(urls).To(Equal(map[string][]string{
+				"Invoice": {"GET /invoices/{}"},
+				"Pay":     {"POST http://billing.svc:8080/invoices/{}/payments"},
+				"Health":  {"HEAD http://billing.svc/healthz", "GET http://billing.svc:8080/status"},
+			}))
+		})
+	})
+
+	Context("when extracting environment variables", func() {
+		It("should link functions to the environment variables they read", func() {
+			testFile := filepath.Join("testdata", "env_vars.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			envVars := make(map[string][]string)
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeEnvVar {
+					use := rel.Metadata["source"] + ":" + rel.Text
+					envVars[rel.FromAST.MethodName] = append(envVars[rel.FromAST.MethodName], use)
+				}
+			}
+			// Names only known at runtime, as in Lookup, are not recorded
+			Expect(envVars).To(Equal(map[string][]string{
+				"Load":  {"viper:LOG_LEVEL", "viper:APP_LOG_LEVEL", "os:DATABASE_URL", "os:APP_PORT", "viper:log.level"},
+				"Debug": {"os:DEBUG", "viper:debug"},
+			}))
+		})
+	})
+
+	Context("when extracting deprecated symbols", func() {
+		It("should record the notice of Deprecated: paragraphs of doc comments", func() {
+			testFile := filepath.Join("testdata", "deprecated.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			notices := make(map[string]string)
+			for _, node := range result.Nodes {
+				if notice, ok := node.Metatdata[models.MetadataDeprecated]; ok {
+					notices[node.String()] = notice
+				}
+			}
+			Expect(notices).To(Equal(map[string]string{
+				"legacy.Client":            "Use NewClient and the v2 API instead.",
+				"legacy.Client.Endpoint":   "the endpoint is discovered.",
+				"legacy.Connect":           "true",
+				"legacy.Client.Disconnect": "Use Client.Close, this is a single paragraph notice spanning two lines.",
+				"legacy.DefaultTimeout":    "Use Client.Timeout.",
+			}))
+		})
+	})
+
+	Context("when extracting struct tags", func() {
+		It("should record the struct tags of fields for annotation rules", func() {
+			testFile := filepath.Join("testdata", "visibility.go")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			tags := make(map[string][]string)
+			for _, node := range result.Nodes {
+				if _, ok := node.Metatdata[models.MetadataTags]; ok {
+					tags[node.String()] = models.Annotations(node)
+				}
+			}
+			Expect(tags).To(Equal(map[string][]string{
+				"visibility.PublicStruct.XMLTag": {"xml"},
+			}))
+		})
+	})
 })
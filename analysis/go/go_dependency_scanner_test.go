@@ -82,3 +82,37 @@ var _ = Describe("GoDependencyScanner ScanGoMod", func() {
 		})
 	})
 })
+
+var _ = Describe("GoDependencyScanner module graph", func() {
+	It("should report the depth of the modules reached through selected versions", func() {
+		content, err := os.ReadFile(filepath.Join("testdata", "simple.modgraph"))
+		Expect(err).NotTo(HaveOccurred())
+
+		graph := parseGoModGraph(content)
+		Expect(graph.modules()).To(Equal([]string{
+			"github.com/a/a", "github.com/b/b", "github.com/c/c", "github.com/d/d", "github.com/e/e", "github.com/f/f",
+		}))
+		Expect(graph.selected).To(HaveKeyWithValue("github.com/c/c", "v1.3.0"))
+
+		// d and f are only required by versions the build does not use
+		Expect(graph.depths([]string{"github.com/a/a", "github.com/b/b"})).To(Equal(map[string]int{
+			"github.com/a/a": 0,
+			"github.com/b/b": 0,
+			"github.com/c/c": 1,
+			"github.com/e/e": 2,
+		}))
+	})
+
+	It("should fall back to the indirect comments of go.mod without a module graph", func() {
+		content, err := os.ReadFile(filepath.Join("testdata", "simple.go.mod"))
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := NewGoDependencyScanner().ScanFile(nil, "/test/go.mod", content)
+		Expect(err).NotTo(HaveOccurred())
+		for _, dep := range deps {
+			indirect := dep.Name == "github.com/davecgh/go-spew" || dep.Name == "github.com/pmezard/go-difflib" || dep.Name == "gopkg.in/yaml.v3"
+			Expect(dep.Indirect).To(Equal(indirect), dep.Name)
+			Expect(dep.Depth > 0).To(Equal(indirect), dep.Name)
+		}
+	})
+})
@@ -0,0 +1,180 @@
+package _go
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/models"
+	"golang.org/x/tools/go/packages"
+)
+
+// TypeRef identifies a named type declared in a loaded Go package
+type TypeRef struct {
+	Package string // Import path
+	Name    string
+	File    string
+	Line    int
+}
+
+func (t TypeRef) String() string {
+	return t.Package + "." + t.Name
+}
+
+// Implementation is an interface satisfied by a concrete type
+type Implementation struct {
+	Interface TypeRef
+	Pointer   bool // Only the pointer type satisfies the interface
+}
+
+// TypeImplementations lists the interfaces satisfied by a concrete type, which may be none
+type TypeImplementations struct {
+	Type       TypeRef
+	Interfaces []Implementation
+}
+
+// FindImplementations type-checks the Go packages below dir and returns, for every concrete
+// named type, the interfaces declared in those packages that it satisfies
+func FindImplementations(dir string) ([]TypeImplementations, error) {
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:  dir,
+		Fset: fset,
+	}
+	if offline.Enabled() {
+		// go list must resolve modules from the local cache only
+		cfg.Env = append(os.Environ(), "GOPROXY=off")
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Go packages in %s: %w", dir, err)
+	}
+
+	var typed []*types.Package
+	for _, pkg := range pkgs {
+		if pkg.Types != nil {
+			typed = append(typed, pkg.Types)
+		}
+	}
+	return implementations(fset, typed), nil
+}
+
+// implementations matches the concrete types of pkgs against their non-empty interfaces
+func implementations(fset *token.FileSet, pkgs []*types.Package) []TypeImplementations {
+	type named struct {
+		ref TypeRef
+		typ *types.Named
+	}
+
+	var concretes, interfaces []named
+	for _, pkg := range pkgs {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+			typ, ok := obj.Type().(*types.Named)
+			// Generic types only satisfy interfaces once instantiated
+			if !ok || typ.TypeParams().Len() > 0 {
+				continue
+			}
+
+			pos := fset.Position(obj.Pos())
+			n := named{ref: TypeRef{Package: pkg.Path(), Name: name, File: pos.Filename, Line: pos.Line}, typ: typ}
+			if iface, ok := typ.Underlying().(*types.Interface); ok {
+				// Empty interfaces and constraints are satisfied trivially or not at all
+				if iface.NumMethods() > 0 && iface.IsMethodSet() {
+					interfaces = append(interfaces, n)
+				}
+				continue
+			}
+			concretes = append(concretes, n)
+		}
+	}
+
+	result := make([]TypeImplementations, 0, len(concretes))
+	for _, concrete := range concretes {
+		impls := TypeImplementations{Type: concrete.ref}
+		for _, iface := range interfaces {
+			it := iface.typ.Underlying().(*types.Interface)
+			switch {
+			case types.Implements(concrete.typ, it):
+				impls.Interfaces = append(impls.Interfaces, Implementation{Interface: iface.ref})
+			case types.Implements(types.NewPointer(concrete.typ), it):
+				impls.Interfaces = append(impls.Interfaces, Implementation{Interface: iface.ref, Pointer: true})
+			}
+		}
+		result = append(result, impls)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type.String() < result[j].Type.String()
+	})
+	return result
+}
+
+// StoreImplementations replaces the implements relationships of each concrete type with the
+// interfaces it currently satisfies, returning the number of relationships stored. Types whose
+// AST nodes are not in the cache are skipped.
+func StoreImplementations(astCache *cache.ASTCache, impls []TypeImplementations) (int, error) {
+	nodesByFile := make(map[string][]*models.ASTNode)
+	lookup := func(ref TypeRef) (*models.ASTNode, error) {
+		nodes, ok := nodesByFile[ref.File]
+		if !ok {
+			var err error
+			if nodes, err = astCache.GetASTNodesByFile(ref.File); err != nil {
+				return nil, err
+			}
+			nodesByFile[ref.File] = nodes
+		}
+		for _, node := range nodes {
+			if node.NodeType == models.NodeTypeType && node.TypeName == ref.Name {
+				return node, nil
+			}
+		}
+		return nil, nil
+	}
+
+	stored := 0
+	for _, impl := range impls {
+		from, err := lookup(impl.Type)
+		if err != nil {
+			return stored, err
+		}
+		if from == nil {
+			continue
+		}
+
+		if err := astCache.DeleteASTRelationships(from.ID, string(models.RelationshipTypeImplements)); err != nil {
+			return stored, err
+		}
+
+		for _, iface := range impl.Interfaces {
+			to, err := lookup(iface.Interface)
+			if err != nil {
+				return stored, err
+			}
+			if to == nil {
+				continue
+			}
+
+			text := fmt.Sprintf("%s implements %s", impl.Type, iface.Interface)
+			if iface.Pointer {
+				text = "*" + text
+			}
+			toID := to.ID
+			if err := astCache.StoreASTRelationship(from.ID, &toID, impl.Type.Line, string(models.RelationshipTypeImplements), text); err != nil {
+				return stored, err
+			}
+			stored++
+		}
+	}
+	return stored, nil
+}
@@ -0,0 +1,53 @@
+package _go
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Interface implementations", func() {
+	var impls map[string]TypeImplementations
+
+	BeforeEach(func() {
+		found, err := FindImplementations(filepath.Join("testdata", "implements"))
+		Expect(err).NotTo(HaveOccurred())
+
+		impls = make(map[string]TypeImplementations)
+		for _, impl := range found {
+			impls[impl.Type.String()] = impl
+		}
+	})
+
+	interfaceNames := func(impl TypeImplementations) []string {
+		var names []string
+		for _, iface := range impl.Interfaces {
+			names = append(names, iface.Interface.String())
+		}
+		return names
+	}
+
+	It("should link types implementing interfaces through pointer receivers", func() {
+		Expect(impls).To(HaveKey("example.com/shop/store.PostgresRepo"))
+		impl := impls["example.com/shop/store.PostgresRepo"]
+		Expect(impl.Type.File).To(HaveSuffix(filepath.Join("store", "store.go")))
+		Expect(impl.Type.Line).To(Equal(10))
+		Expect(interfaceNames(impl)).To(Equal([]string{"example.com/shop/repo.Closer", "example.com/shop/repo.Repository"}))
+		Expect(impl.Interfaces[1].Pointer).To(BeTrue())
+	})
+
+	It("should link types implementing interfaces through value receivers", func() {
+		Expect(impls).To(HaveKey("example.com/shop/store.memoryRepo"))
+		impl := impls["example.com/shop/store.memoryRepo"]
+		Expect(interfaceNames(impl)).To(Equal([]string{"example.com/shop/repo.Repository"}))
+		Expect(impl.Interfaces[0].Pointer).To(BeFalse())
+	})
+
+	It("should not link partial implementations, empty interfaces or constraints", func() {
+		Expect(impls).To(HaveKey("example.com/shop/store.Cache"))
+		Expect(impls["example.com/shop/store.Cache"].Interfaces).To(BeEmpty())
+		Expect(impls["example.com/shop/repo.Item"].Interfaces).To(BeEmpty())
+		Expect(impls).NotTo(HaveKey("example.com/shop/repo.Repository"))
+	})
+})
@@ -0,0 +1,576 @@
+package _go
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis/openapi/httpcall"
+	"github.com/flanksource/arch-unit/analysis/sql/rawsql"
+	"github.com/flanksource/arch-unit/analysis/types"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// maxStatementText is the length statement text is truncated to
+const maxStatementText = 120
+
+// builtins are calls that are not worth a statement of their own
+var builtins = map[string]bool{
+	"append": true, "cap": true, "clear": true, "complex": true, "copy": true, "delete": true,
+	"imag": true, "len": true, "make": true, "max": true, "min": true, "new": true, "real": true,
+}
+
+// Package functions classified by the import path of their package
+var (
+	httpFuncs = map[string]map[string]bool{
+		"net/http": {"Get": true, "Head": true, "Post": true, "PostForm": true, "NewRequest": true, "NewRequestWithContext": true},
+	}
+	fileFuncs = map[string]map[string]bool{
+		"os": {
+			"Open": true, "OpenFile": true, "Create": true, "ReadFile": true, "WriteFile": true, "ReadDir": true,
+			"Remove": true, "RemoveAll": true, "Rename": true, "Mkdir": true, "MkdirAll": true, "MkdirTemp": true, "CreateTemp": true,
+		},
+		"io/ioutil":     {"ReadFile": true, "WriteFile": true, "ReadDir": true, "TempFile": true, "TempDir": true},
+		"path/filepath": {"Walk": true, "WalkDir": true, "Glob": true},
+	}
+	sqlFuncs = map[string]map[string]bool{
+		"database/sql": {"Open": true, "OpenDB": true},
+	}
+	// envFuncs read or set the environment variable named by their first argument; viper keys are
+	// read from environment variables once viper.AutomaticEnv or viper.BindEnv is called
+	envFuncs = map[string]map[string]bool{
+		"os":      {"Getenv": true, "LookupEnv": true, "Setenv": true, "Unsetenv": true},
+		"syscall": {"Getenv": true, "Setenv": true, "Unsetenv": true},
+		"github.com/spf13/viper": {
+			"Get": true, "GetString": true, "GetBool": true, "GetInt": true, "GetInt32": true, "GetInt64": true,
+			"GetUint": true, "GetUint32": true, "GetUint64": true, "GetFloat64": true, "GetDuration": true,
+			"GetTime": true, "GetIntSlice": true, "GetStringSlice": true, "GetStringMap": true,
+			"GetStringMapString": true, "GetStringMapStringSlice": true, "GetSizeInBytes": true, "IsSet": true,
+			"BindEnv": true,
+		},
+	}
+	// httpClientPackages are client libraries whose request builders send HTTP requests,
+	// e.g. resty.New().R().Get(url)
+	httpClientPackages = map[string]bool{
+		"github.com/go-resty/resty": true, "github.com/go-resty/resty/v2": true, "gopkg.in/resty.v1": true,
+	}
+)
+
+// sqlMethods are the database/sql, sqlx, pgx and GORM methods that take a SQL statement
+var sqlMethods = map[string]bool{
+	"Exec": true, "ExecContext": true, "Query": true, "QueryContext": true, "QueryRow": true, "QueryRowContext": true,
+	"Prepare": true, "PrepareContext": true, "Get": true, "GetContext": true, "Select": true, "SelectContext": true,
+	"Queryx": true, "QueryxContext": true, "QueryRowx": true, "QueryRowxContext": true, "MustExec": true,
+	"MustExecContext": true, "NamedExec": true, "NamedExecContext": true, "NamedQuery": true, "NamedQueryContext": true,
+	"Raw": true,
+}
+
+// rawQuery is a raw SQL query run by the function being extracted
+type rawQuery struct {
+	line  int
+	query *rawsql.Query
+}
+
+// endpointCall is an HTTP call to a statically known URL made by the function being extracted
+type endpointCall struct {
+	line int
+	call httpcall.Call
+}
+
+// envVarUse is an environment variable read or set by the function being extracted
+type envVarUse struct {
+	line   int
+	name   string
+	source string // package of the function used, e.g. os or viper
+}
+
+// extractStatements builds the statement tree of a function body: calls, assignments,
+// branches and loops, with SQL, HTTP and file calls classified by their target
+func (e *GoASTExtractor) extractStatements(stmts []ast.Stmt) []models.ASTStatement {
+	var statements []models.ASTStatement
+	for _, stmt := range stmts {
+		statements = append(statements, e.extractStatement(stmt)...)
+	}
+	return statements
+}
+
+func (e *GoASTExtractor) extractStatement(stmt ast.Stmt) []models.ASTStatement {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		return e.extractStatements(s.List)
+	case *ast.LabeledStmt:
+		return e.extractStatement(s.Stmt)
+	case *ast.EmptyStmt:
+		return nil
+	case *ast.ExprStmt:
+		if call, ok := ast.Unparen(s.X).(*ast.CallExpr); ok {
+			if statement, ok := e.callStatement(call); ok {
+				return []models.ASTStatement{statement}
+			}
+			return nil
+		}
+		return []models.ASTStatement{e.newStatement(s, models.ASTStatementTypeExpression, e.callStatements(s.X))}
+	case *ast.GoStmt:
+		return []models.ASTStatement{e.deferredCall(s, s.Call)}
+	case *ast.DeferStmt:
+		return []models.ASTStatement{e.deferredCall(s, s.Call)}
+	case *ast.AssignStmt, *ast.IncDecStmt, *ast.DeclStmt:
+		return []models.ASTStatement{e.newStatement(s, models.ASTStatementTypeAssignment, e.callStatements(s))}
+	case *ast.IfStmt:
+		return []models.ASTStatement{e.ifStatement(s)}
+	case *ast.ForStmt:
+		var children []models.ASTStatement
+		if s.Init != nil {
+			children = append(children, e.extractStatement(s.Init)...)
+		}
+		children = append(children, e.extractStatements(s.Body.List)...)
+		return []models.ASTStatement{e.newStatement(s, models.ASTStatementTypeLoop, children)}
+	case *ast.RangeStmt:
+		children := append(e.callStatements(s.X), e.extractStatements(s.Body.List)...)
+		return []models.ASTStatement{e.newStatement(s, models.ASTStatementTypeLoop, children)}
+	case *ast.SwitchStmt:
+		return []models.ASTStatement{e.branchStatement(s, s.Init, s.Body)}
+	case *ast.TypeSwitchStmt:
+		return []models.ASTStatement{e.branchStatement(s, s.Init, s.Body)}
+	case *ast.SelectStmt:
+		return []models.ASTStatement{e.branchStatement(s, nil, s.Body)}
+	default:
+		// return, send, break, continue and goto
+		return []models.ASTStatement{e.newStatement(s, models.ASTStatementTypeOther, e.callStatements(s))}
+	}
+}
+
+// ifStatement renders an if statement with its init, body and else branches as children
+func (e *GoASTExtractor) ifStatement(s *ast.IfStmt) models.ASTStatement {
+	var children []models.ASTStatement
+	if s.Init != nil {
+		children = append(children, e.extractStatement(s.Init)...)
+	}
+	children = append(children, e.callStatements(s.Cond)...)
+	children = append(children, e.extractStatements(s.Body.List)...)
+
+	switch elseStmt := s.Else.(type) {
+	case *ast.IfStmt:
+		elseIf := e.ifStatement(elseStmt)
+		elseIf.Text = "else " + elseIf.Text
+		children = append(children, elseIf)
+	case *ast.BlockStmt:
+		elseBlock := e.newStatement(elseStmt, models.ASTStatementTypeIf, e.extractStatements(elseStmt.List))
+		elseBlock.Text = "else"
+		children = append(children, elseBlock)
+	}
+
+	// The statement ends with the if block, else branches are children
+	statement := e.newStatement(s, models.ASTStatementTypeIf, children)
+	statement.EndLine = e.fileSet.Position(s.Body.End()).Line
+	return statement
+}
+
+// branchStatement renders a switch or select statement with a child per case clause
+func (e *GoASTExtractor) branchStatement(s ast.Stmt, init ast.Stmt, body *ast.BlockStmt) models.ASTStatement {
+	var children []models.ASTStatement
+	if init != nil {
+		children = append(children, e.extractStatement(init)...)
+	}
+	for _, clause := range body.List {
+		switch c := clause.(type) {
+		case *ast.CaseClause:
+			children = append(children, e.newStatement(c, models.ASTStatementTypeIf, e.extractStatements(c.Body)))
+		case *ast.CommClause:
+			caseChildren := e.callStatements(c.Comm)
+			caseChildren = append(caseChildren, e.extractStatements(c.Body)...)
+			children = append(children, e.newStatement(c, models.ASTStatementTypeIf, caseChildren))
+		}
+	}
+	return e.newStatement(s, models.ASTStatementTypeIf, children)
+}
+
+// deferredCall renders a go or defer statement as the call it schedules
+func (e *GoASTExtractor) deferredCall(s ast.Stmt, call *ast.CallExpr) models.ASTStatement {
+	statement, ok := e.callStatement(call)
+	if !ok {
+		return e.newStatement(s, models.ASTStatementTypeFunctionCall, nil)
+	}
+	statement.Text = e.statementText(s)
+	return statement
+}
+
+// callStatements returns the calls made by an expression or simple statement, outside of
+// function literals; calls in the arguments of a call are its children
+func (e *GoASTExtractor) callStatements(node ast.Node) []models.ASTStatement {
+	if node == nil {
+		return nil
+	}
+	var statements []models.ASTStatement
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch c := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if statement, ok := e.callStatement(c); ok {
+				statements = append(statements, statement)
+				return false
+			}
+		}
+		return true
+	})
+	return statements
+}
+
+// callStatement returns the statement of a call, false for conversions and builtins
+func (e *GoASTExtractor) callStatement(call *ast.CallExpr) (models.ASTStatement, bool) {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		if builtins[fun.Name] || e.isPrimitiveType(fun.Name) {
+			return models.ASTStatement{}, false
+		}
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StarExpr:
+		return models.ASTStatement{}, false
+	}
+
+	var children []models.ASTStatement
+	for _, arg := range call.Args {
+		children = append(children, e.callStatements(arg)...)
+	}
+	statement := e.newStatement(call, ClassifyCall(call, e.imports), children)
+	if query, text, ok := e.rawQuery(call); ok {
+		statement.Type = models.ASTStatementTypeSQLQuery
+		statement.Text = truncate(text)
+		e.queries = append(e.queries, rawQuery{line: statement.StartLine, query: query})
+	} else if statement.Type == models.ASTStatementTypeHttpCall {
+		if httpCall, ok := e.httpCall(call); ok {
+			statement.Text = truncate(httpCall.String())
+			e.httpCalls = append(e.httpCalls, endpointCall{line: statement.StartLine, call: httpCall})
+		}
+	}
+	for _, use := range e.envVarUses(call) {
+		use.line = statement.StartLine
+		e.envVars = append(e.envVars, use)
+	}
+	return statement, true
+}
+
+// envVarUses returns the environment variables named by string constants in a call such as
+// os.Getenv("DATABASE_URL") or viper.GetString("log.level"). viper.BindEnv("key", "ENV", ...)
+// binds a key to the variables named after it.
+func (e *GoASTExtractor) envVarUses(call *ast.CallExpr) []envVarUse {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || !envFuncs[e.imports[pkg.Name]][sel.Sel.Name] {
+		return nil
+	}
+
+	args := call.Args[:1]
+	if sel.Sel.Name == "BindEnv" && len(call.Args) > 1 {
+		args = call.Args[1:]
+	}
+	var uses []envVarUse
+	for _, arg := range args {
+		if name, ok := e.constantString(arg); ok && name != "" {
+			uses = append(uses, envVarUse{name: name, source: path.Base(e.imports[pkg.Name])})
+		}
+	}
+	return uses
+}
+
+// httpCall returns the method and URL of an HTTP call when the URL is built from constants, e.g.
+// http.Get(baseURL + "/users") or client.R().Post(fmt.Sprintf("/users/%d/orders", id))
+func (e *GoASTExtractor) httpCall(call *ast.CallExpr) (httpcall.Call, bool) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return httpcall.Call{}, false
+	}
+
+	method, _ := httpcall.Verb(sel.Sel.Name)
+	methodArg, urlArg := -1, 0
+	switch sel.Sel.Name {
+	case "NewRequest", "Execute":
+		methodArg, urlArg = 0, 1
+	case "NewRequestWithContext":
+		methodArg, urlArg = 1, 2
+	}
+	if methodArg >= 0 && methodArg < len(call.Args) {
+		method = e.httpMethod(call.Args[methodArg])
+	}
+	if urlArg >= len(call.Args) {
+		return httpcall.Call{}, false
+	}
+
+	url, ok := e.urlString(call.Args[urlArg])
+	if !ok {
+		return httpcall.Call{}, false
+	}
+	return httpcall.Call{Method: method, URL: url}, true
+}
+
+// httpMethod returns the HTTP method of a string constant or of a constant such as
+// http.MethodGet, empty when it is not statically known
+func (e *GoASTExtractor) httpMethod(expr ast.Expr) string {
+	name, ok := e.constantString(expr)
+	if !ok {
+		sel, isSelector := ast.Unparen(expr).(*ast.SelectorExpr)
+		if !isSelector {
+			return ""
+		}
+		name = sel.Sel.Name
+	}
+	if method, ok := httpcall.Method(name); ok {
+		return method
+	}
+	return ""
+}
+
+// urlString returns the URL an expression evaluates to, with the parts only known at runtime
+// replaced by httpcall.Unknown, false when no part of it is constant
+func (e *GoASTExtractor) urlString(expr ast.Expr) (string, bool) {
+	switch x := ast.Unparen(expr).(type) {
+	case *ast.BinaryExpr:
+		if x.Op == token.ADD {
+			left, leftOK := e.urlString(x.X)
+			right, rightOK := e.urlString(x.Y)
+			return left + right, leftOK || rightOK
+		}
+	case *ast.CallExpr:
+		sel, ok := ast.Unparen(x.Fun).(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Sprintf" || len(x.Args) == 0 {
+			break
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && e.imports[pkg.Name] == "fmt" {
+			if format, ok := e.constantString(x.Args[0]); ok {
+				return httpcall.Template(format), true
+			}
+		}
+	}
+
+	if value, ok := e.constantString(expr); ok {
+		return value, true
+	}
+	return httpcall.Unknown, false
+}
+
+// rawQuery returns the SQL statement passed as a string constant to a database method, such as
+// db.QueryContext(ctx, "SELECT ...") or GORM's db.Raw("SELECT ...")
+func (e *GoASTExtractor) rawQuery(call *ast.CallExpr) (*rawsql.Query, string, bool) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || !sqlMethods[sel.Sel.Name] {
+		return nil, "", false
+	}
+	for _, arg := range call.Args {
+		text, ok := e.constantString(arg)
+		if !ok {
+			continue
+		}
+		if query, ok := rawsql.Parse(text); ok {
+			return query, rawsql.Normalize(text), true
+		}
+	}
+	return nil, "", false
+}
+
+// constantString returns the value of a string literal, a concatenation of literals or a string
+// constant declared at the package level of the file
+func (e *GoASTExtractor) constantString(expr ast.Expr) (string, bool) {
+	switch x := ast.Unparen(expr).(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(x.Value)
+		return value, err == nil
+	case *ast.BinaryExpr:
+		if x.Op != token.ADD {
+			return "", false
+		}
+		left, ok := e.constantString(x.X)
+		if !ok {
+			return "", false
+		}
+		right, ok := e.constantString(x.Y)
+		return left + right, ok
+	case *ast.Ident:
+		if value, ok := e.constants[x.Name]; ok {
+			return e.constantString(value)
+		}
+	}
+	return "", false
+}
+
+// addQueryRelationships links a function to the tables read or written by its raw SQL queries
+func (e *GoASTExtractor) addQueryRelationships(funcNode *models.ASTNode, result *types.ASTResult) {
+	for _, q := range e.queries {
+		for _, table := range q.query.Tables {
+			result.AddRelationship(&models.ASTRelationship{
+				FromAST:          funcNode,
+				LineNo:           q.line,
+				RelationshipType: models.RelationshipTypeSQLQuery,
+				Text:             table,
+			})
+		}
+	}
+	e.queries = nil
+}
+
+// addHTTPRelationships links a function to the URLs of its HTTP calls, which the AQL engine
+// resolves to the matching OpenAPI endpoints
+func (e *GoASTExtractor) addHTTPRelationships(funcNode *models.ASTNode, result *types.ASTResult) {
+	for _, c := range e.httpCalls {
+		result.AddRelationship(&models.ASTRelationship{
+			FromAST:          funcNode,
+			LineNo:           c.line,
+			RelationshipType: models.RelationshipTypeHTTPCall,
+			Text:             c.call.String(),
+		})
+	}
+	e.httpCalls = nil
+}
+
+// addEnvRelationships links a function to the environment variables it reads or sets, which
+// the AQL engine resolves to nodes of the env package, e.g. env:DATABASE_URL
+func (e *GoASTExtractor) addEnvRelationships(funcNode *models.ASTNode, result *types.ASTResult) {
+	for _, use := range e.envVars {
+		result.AddRelationship(&models.ASTRelationship{
+			FromAST:          funcNode,
+			LineNo:           use.line,
+			RelationshipType: models.RelationshipTypeEnvVar,
+			Text:             use.name,
+			Metadata:         map[string]string{"source": use.source},
+		})
+	}
+	e.envVars = nil
+}
+
+// fileConstants returns the values of the package level constants declared in a file
+func fileConstants(file *ast.File) map[string]ast.Expr {
+	constants := make(map[string]ast.Expr)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, name := range valueSpec.Names {
+				if i < len(valueSpec.Values) {
+					constants[name.Name] = valueSpec.Values[i]
+				}
+			}
+		}
+	}
+	return constants
+}
+
+// ClassifyCall classifies a call as a SQL, HTTP or file operation from the called package
+// function, or from the name of the receiver for database and HTTP client methods. imports maps
+// the local names of the file's imports to their package paths.
+func ClassifyCall(call *ast.CallExpr, imports map[string]string) models.ASTStatementType {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return models.ASTStatementTypeFunctionCall
+	}
+
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		if pkgPath, isImport := imports[ident.Name]; isImport {
+			switch {
+			case sqlFuncs[pkgPath][sel.Sel.Name]:
+				return models.ASTStatementTypeSQLQuery
+			case httpFuncs[pkgPath][sel.Sel.Name]:
+				return models.ASTStatementTypeHttpCall
+			case fileFuncs[pkgPath][sel.Sel.Name]:
+				return models.ASTStatementTypeFileOp
+			}
+			return models.ASTStatementTypeFunctionCall
+		}
+	}
+
+	receiver := strings.ToLower(receiverName(sel.X))
+	switch {
+	case receiver == "tx" || strings.HasSuffix(receiver, "tx") || strings.HasSuffix(receiver, "db") || strings.Contains(receiver, "conn"):
+		return models.ASTStatementTypeSQLQuery
+	case (strings.Contains(receiver, "client") || httpClientPackages[imports[receiver]]) && isClientMethod(sel.Sel.Name):
+		return models.ASTStatementTypeHttpCall
+	}
+	return models.ASTStatementTypeFunctionCall
+}
+
+// isClientMethod returns true for the methods of HTTP clients and request builders that send a
+// request
+func isClientMethod(name string) bool {
+	_, isVerb := httpcall.Verb(name)
+	return isVerb || name == "Do" || name == "Execute" || httpFuncs["net/http"][name]
+}
+
+// Imports returns the local names of the imports of a file mapped to their package paths, as
+// expected by ClassifyCall
+func Imports(file *ast.File) map[string]string {
+	e := &GoASTExtractor{imports: make(map[string]string)}
+	for _, imp := range file.Imports {
+		e.extractImport(imp)
+	}
+	return e.imports
+}
+
+// receiverName returns the name at the root of a method call chain, e.g. db for
+// s.db.Where(...).First(...)
+func receiverName(expr ast.Expr) string {
+	switch x := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	case *ast.CallExpr:
+		if sel, ok := ast.Unparen(x.Fun).(*ast.SelectorExpr); ok {
+			return receiverName(sel.X)
+		}
+	case *ast.IndexExpr:
+		return receiverName(x.X)
+	case *ast.StarExpr:
+		return receiverName(x.X)
+	}
+	return ""
+}
+
+func (e *GoASTExtractor) newStatement(node ast.Node, statementType models.ASTStatementType, children []models.ASTStatement) models.ASTStatement {
+	return models.ASTStatement{
+		StartLine: e.fileSet.Position(node.Pos()).Line,
+		EndLine:   e.fileSet.Position(node.End()).Line,
+		Text:      e.statementText(node),
+		Type:      statementType,
+		Children:  children,
+	}
+}
+
+// statementText returns the first source line of a statement without the opening brace
+// or colon of a block, e.g. "if err != nil" or "case http.MethodGet"
+func (e *GoASTExtractor) statementText(node ast.Node) string {
+	start := e.fileSet.Position(node.Pos()).Offset
+	end := e.fileSet.Position(node.End()).Offset
+	if start < 0 || end > len(e.content) || start >= end {
+		return ""
+	}
+
+	text, _, _ := strings.Cut(string(e.content[start:end]), "\n")
+	text = strings.TrimSpace(text)
+	text = strings.TrimSpace(strings.TrimSuffix(text, "{"))
+	if _, isClause := node.(*ast.CaseClause); isClause {
+		text = strings.TrimSuffix(text, ":")
+	} else if _, isClause := node.(*ast.CommClause); isClause {
+		text = strings.TrimSuffix(text, ":")
+	}
+	return truncate(text)
+}
+
+// truncate shortens statement text to maxStatementText
+func truncate(text string) string {
+	if len(text) > maxStatementText {
+		return text[:maxStatementText] + "..."
+	}
+	return text
+}
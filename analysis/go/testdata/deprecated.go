@@ -0,0 +1,33 @@
+package legacy
+
+// Client talks to the legacy API.
+//
+// Deprecated: Use NewClient and the v2 API instead.
+type Client struct {
+	// Endpoint of the API.
+	//
+	// Deprecated: the endpoint is discovered.
+	Endpoint string
+	Timeout  int
+}
+
+// Connect opens a connection.
+//
+// Deprecated:
+func Connect() *Client {
+	return &Client{}
+}
+
+// Deprecated: Use Client.Close, this is a single paragraph notice
+// spanning two lines.
+func (c *Client) Disconnect() {}
+
+// Close closes the connection, it is not Deprecated: as the word is not at the start.
+func (c *Client) Close() {}
+
+const (
+	// DefaultTimeout of requests.
+	//
+	// Deprecated: Use Client.Timeout.
+	DefaultTimeout = 30
+)
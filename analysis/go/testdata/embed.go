@@ -0,0 +1,25 @@
+//go:build linux && !cgo
+
+package assets
+
+import (
+	"embed"
+	_ "embed"
+)
+
+//go:embed templates/*.html static
+var Content embed.FS
+
+var (
+	// Version is embedded from a single file
+	//go:embed "VERSION" `release notes.txt`
+	Version string
+
+	// NotEmbedded is a plain variable
+	NotEmbedded = "text"
+)
+
+// Render uses the embedded templates
+func Render(name string) ([]byte, error) {
+	return Content.ReadFile(name)
+}
@@ -0,0 +1,37 @@
+package embedding
+
+import (
+	"io"
+	"sync"
+)
+
+// Service embeds a local struct by pointer, a library struct and a type declared below
+type Service struct {
+	*Base
+	sync.Mutex
+	Auditable
+	name string
+}
+
+// Base is embedded by Service
+type Base struct {
+	ID string
+}
+
+// Auditable is declared after the struct that embeds it
+type Auditable struct {
+	CreatedBy string
+}
+
+// ReadCloser embeds a library interface and error
+type ReadCloser interface {
+	io.Reader
+	error
+	Close() error
+}
+
+// Number is a constraint whose type set elements are not embeddings
+type Number interface {
+	~int | ~float64
+	comparable
+}
@@ -0,0 +1,36 @@
+package testdata
+
+import (
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+const portVar = "APP_PORT"
+
+type Config struct {
+	DatabaseURL string
+	Port        string
+	LogLevel    string
+}
+
+func Load() Config {
+	viper.AutomaticEnv()
+	_ = viper.BindEnv("log.level", "LOG_LEVEL", "APP_LOG_LEVEL")
+	return Config{
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+		Port:        os.Getenv(portVar),
+		LogLevel:    viper.GetString("log.level"),
+	}
+}
+
+func Debug() bool {
+	if _, ok := os.LookupEnv("DEBUG"); ok {
+		return true
+	}
+	return viper.GetBool("debug")
+}
+
+func Lookup(name string) string {
+	return os.Getenv(name)
+}
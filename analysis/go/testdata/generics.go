@@ -0,0 +1,64 @@
+package generics
+
+import "context"
+
+// Number is a constraint satisfied by integer and floating point types
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Pair holds a key and a value
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Stack is a generic LIFO stack
+type Stack[T any] struct {
+	items []T
+	index map[string]Pair[string, T]
+}
+
+// Push adds an item to the stack
+func (s *Stack[T]) Push(item T) {
+	s.items = append(s.items, item)
+}
+
+// Pop removes the top item from the stack
+func (s *Stack[T]) Pop() (T, bool) {
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	item := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return item, true
+}
+
+// Map applies f to every element of in
+func Map[T, U any](in []T, f func(T) U) []U {
+	out := make([]U, 0, len(in))
+	for _, v := range in {
+		out = append(out, f(v))
+	}
+	return out
+}
+
+// Sum adds up numbers
+func Sum[N Number](values ...N) N {
+	var total N
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// Load fetches a stack of pairs
+func Load(ctx context.Context, fetch func(context.Context, string) (*Stack[int], error)) (*Stack[Pair[string, int]], error) {
+	return nil, nil
+}
+
+// Clamp limits v to the range [lo, hi]
+func Clamp[T interface{ ~int | ~float64 }](v, lo, hi T) T {
+	return min(max(v, lo), hi)
+}
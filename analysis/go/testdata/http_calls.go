@@ -0,0 +1,42 @@
+package testdata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const billingURL = "http://billing.svc:8080"
+
+type BillingClient struct {
+	client  *resty.Client
+	baseURL string
+}
+
+func (c *BillingClient) Invoice(id int) (*resty.Response, error) {
+	return c.client.R().Get(fmt.Sprintf("/invoices/%d", id))
+}
+
+func (c *BillingClient) Pay(ctx context.Context, id string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, billingURL+"/invoices/"+id+"/payments", nil)
+	if err != nil {
+		return err
+	}
+	_, err = http.DefaultClient.Do(req)
+	return err
+}
+
+func Health() error {
+	_, err := resty.New().R().Head("http://billing.svc/healthz")
+	if err != nil {
+		return err
+	}
+	_, err = http.Get(billingURL + "/status")
+	return err
+}
+
+func Fetch(url string) (*http.Response, error) {
+	return http.Get(url)
+}
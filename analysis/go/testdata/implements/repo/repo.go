@@ -0,0 +1,28 @@
+package repo
+
+import "context"
+
+// Item is a stored entity
+type Item struct {
+	ID   string
+	Name string
+}
+
+// Repository persists items
+type Repository interface {
+	Get(ctx context.Context, id string) (*Item, error)
+	Save(ctx context.Context, item *Item) error
+}
+
+// Closer releases resources
+type Closer interface {
+	Close() error
+}
+
+// Any is an empty interface and is never linked
+type Any interface{}
+
+// ID is a type set constraint and is never linked
+type ID interface {
+	~string | ~int
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+
+	"example.com/shop/repo"
+)
+
+// PostgresRepo implements repo.Repository with pointer receivers
+type PostgresRepo struct {
+	dsn string
+}
+
+func (r *PostgresRepo) Get(ctx context.Context, id string) (*repo.Item, error) {
+	return nil, nil
+}
+
+func (r *PostgresRepo) Save(ctx context.Context, item *repo.Item) error {
+	return nil
+}
+
+func (r *PostgresRepo) Close() error {
+	return nil
+}
+
+// memoryRepo implements repo.Repository with value receivers
+type memoryRepo map[string]*repo.Item
+
+func (m memoryRepo) Get(ctx context.Context, id string) (*repo.Item, error) {
+	return m[id], nil
+}
+
+func (m memoryRepo) Save(ctx context.Context, item *repo.Item) error {
+	m[item.ID] = item
+	return nil
+}
+
+// Cache only has a subset of the repository methods
+type Cache struct{}
+
+func (Cache) Get(ctx context.Context, id string) (*repo.Item, error) {
+	return nil, nil
+}
+
+var _ repo.Repository = (*PostgresRepo)(nil)
@@ -0,0 +1,29 @@
+package lowlevel
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func Alloc(size int) unsafe.Pointer {
+	return C.malloc(C.size_t(size))
+}
+
+func Bytes(p unsafe.Pointer, n int) []byte {
+	var ptr unsafe.Pointer = p
+	return unsafe.Slice((*byte)(ptr), n)
+}
+
+func Stop(pid int) error {
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return err
+	}
+	return unix.Kill(pid, unix.SIGKILL)
+}
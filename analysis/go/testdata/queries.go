@@ -0,0 +1,30 @@
+package testdata
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+const findUser = "SELECT id, name FROM users WHERE id = $1"
+
+type UserStore struct {
+	db   *sql.DB
+	gorm *gorm.DB
+}
+
+func (s *UserStore) Find(ctx context.Context, id int) error {
+	row := s.db.QueryRowContext(ctx, findUser, id)
+	return row.Err()
+}
+
+func (s *UserStore) Orders(userID int) error {
+	return s.gorm.Raw("SELECT o.* FROM orders o "+
+		"JOIN users u ON u.id = o.user_id WHERE u.id = ?", userID).Error
+}
+
+func (s *UserStore) Run(query string) error {
+	_, err := s.db.Exec(query)
+	return err
+}
@@ -0,0 +1,38 @@
+package testdata
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+type Importer struct {
+	db     *sql.DB
+	client *http.Client
+}
+
+func (i *Importer) Import(path string, urls []string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	} else if len(data) == 0 {
+		return nil
+	}
+
+	for _, url := range urls {
+		resp, err := i.client.Get(url)
+		if err != nil {
+			continue
+		}
+		defer resp.Body.Close()
+	}
+
+	switch string(data) {
+	case "reset":
+		i.db.Exec("DELETE FROM items")
+	default:
+		fmt.Println(len(data))
+	}
+	return nil
+}
@@ -0,0 +1,399 @@
+package groovy
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/types"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/languages"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky"
+)
+
+// SharedLibraryFramework is the framework recorded for Jenkins shared library usages
+const SharedLibraryFramework = "jenkins-shared-library"
+
+// DefaultSharedLibrary is the package used for shared library calls when the library
+// cannot be determined, e.g. with several or implicitly loaded libraries
+const DefaultSharedLibrary = "shared-library"
+
+// GroovyASTExtractor extracts pipeline structure from Jenkinsfiles and Groovy scripts.
+//
+// Stages become method nodes (nested stages are named "Parent/Child"), pipeline and node
+// blocks and classes become type nodes, and shared library imports and steps that are not
+// built into Jenkins become library relationships.
+type GroovyASTExtractor struct{}
+
+// NewGroovyASTExtractor creates a new Groovy AST extractor
+func NewGroovyASTExtractor() *GroovyASTExtractor {
+	return &GroovyASTExtractor{}
+}
+
+// A string literal in sanitized source, whose contents have been blanked out
+const stringLiteral = `['"]( *)['"]`
+
+var (
+	packageRe  = regexp.MustCompile(`^\s*package\s+([\w.]+)`)
+	classRe    = regexp.MustCompile(`\b(?:class|interface|trait|enum)\s+([A-Za-z_]\w*)`)
+	defRe      = regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|final)\s+)*(?:def|void|[A-Z][\w.<>,\[\] ]*?)\s+([A-Za-z_]\w*)\s*\([^)]*\)?\s*(?:throws\s+[\w., ]+)?\{?\s*$`)
+	localRe    = regexp.MustCompile(`\b(?:def|[A-Z][\w<>,\[\]]*)\s+([a-z_]\w*)\s*=|\bfor\s*\(\s*(?:def\s+|[A-Z]\w*\s+)?([a-z_]\w*)\s+in\b|([a-z_]\w*)\s*->`)
+	paramRe    = regexp.MustCompile(`([a-z_]\w*)\s*(?:=[^,]*)?$`)
+	pipelineRe = regexp.MustCompile(`^\s*pipeline\s*\{`)
+	nodeRe     = regexp.MustCompile(`^\s*node\b\s*(?:\(\s*(?:` + stringLiteral + `)?[^)]*\))?\s*\{`)
+	stageRe    = regexp.MustCompile(`\bstage\s*\(\s*` + stringLiteral)
+	libraryRe  = regexp.MustCompile(`@Library\s*\(`)
+	libStepRe  = regexp.MustCompile(`^\s*library\b\s*\(?\s*(?:identifier\s*:\s*)?` + stringLiteral)
+	literalRe  = regexp.MustCompile(stringLiteral)
+	callRe     = regexp.MustCompile(`^\s*([a-z_]\w*)(?:\.([A-Za-z_]\w*))?(?:\s*\(|\s+['"\w\[])`)
+	decisionRe = regexp.MustCompile(`\b(?:if|when|catch|case|for|while)\b|&&|\|\|`)
+)
+
+// builtinSteps are Groovy keywords, declarative pipeline directives and common Jenkins steps
+// that are not attributed to shared libraries
+var builtinSteps = toSet(
+	// Groovy
+	"def", "return", "if", "else", "for", "while", "do", "try", "catch", "finally", "switch", "case",
+	"default", "throw", "new", "import", "package", "class", "interface", "trait", "enum", "assert",
+	"break", "continue", "in", "true", "false", "null", "this", "super", "static", "final", "private",
+	"public", "protected", "void", "println", "print", "sleep", "evaluate", "binding",
+	// Declarative directives
+	"pipeline", "agent", "any", "none", "label", "docker", "dockerfile", "kubernetes", "stages", "stage",
+	"steps", "post", "always", "success", "failure", "unstable", "changed", "fixed", "regression",
+	"aborted", "unsuccessful", "cleanup", "environment", "options", "parameters", "triggers", "tools",
+	"input", "when", "parallel", "matrix", "axes", "axis", "name", "values", "excludes", "exclude",
+	"branch", "expression", "allOf", "anyOf", "not", "tag", "changeset", "changeRequest", "buildingTag",
+	"beforeAgent", "beforeInput", "beforeOptions", "failFast", "script", "string", "booleanParam",
+	"choice", "text", "password", "cron", "pollSCM", "upstream", "node", "customWorkspace", "reuseNode",
+	"image", "args", "filename", "dir", "yaml", "yamlFile", "defaultContainer", "inheritFrom",
+	// Steps
+	"sh", "bat", "powershell", "pwsh", "echo", "error", "deleteDir", "writeFile", "readFile",
+	"fileExists", "stash", "unstash", "archiveArtifacts", "junit", "git", "checkout", "scm",
+	"withCredentials", "withEnv", "withDockerRegistry", "withDockerContainer", "withKubeConfig",
+	"withMaven", "withSonarQubeEnv", "waitForQualityGate", "timeout", "retry", "waitUntil",
+	"milestone", "lock", "build", "catchError", "warnError", "mail", "emailext", "publishHTML", "step",
+	"tool", "usernamePassword", "usernameColonPassword", "file", "sshUserPrivateKey", "certificate",
+	"properties", "pipelineTriggers", "buildDiscarder", "logRotator", "disableConcurrentBuilds",
+	"skipDefaultCheckout", "timestamps", "ansiColor", "wrap", "isUnix", "readJSON", "writeJSON",
+	"readYaml", "writeYaml", "readProperties", "readCSV", "findFiles", "zip", "unzip", "cleanWs",
+	"recordIssues", "slackSend", "container", "podTemplate", "containerTemplate", "load", "library",
+	"sshagent", "env", "params", "currentBuild", "getContext", "pwd", "copyArtifacts",
+	"fingerprint", "setBuildStatus", "githubNotify", "input", "httpRequest", "jiraComment",
+)
+
+// frame is an open brace block; node is set for blocks that declare a stage, method or type
+type frame struct {
+	node  *models.ASTNode
+	stage string // full stage name for stage blocks
+}
+
+// ExtractFile extracts pipeline structure from a Jenkinsfile or Groovy file
+func (e *GroovyASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath string, content []byte) (*types.ASTResult, error) {
+	result := types.NewASTResult(filePath, "groovy")
+	now := time.Now()
+
+	original := strings.Split(string(content), "\n")
+	sanitized := strings.Split(sanitize(string(content)), "\n")
+
+	packageName := filepath.Base(filepath.Dir(filePath))
+	for _, line := range sanitized {
+		if m := packageRe.FindStringSubmatch(line); m != nil {
+			packageName = m[1]
+			break
+		}
+	}
+	result.PackageName = packageName
+
+	result.AddNode(&models.ASTNode{
+		FilePath:     filePath,
+		PackageName:  packageName,
+		NodeType:     models.NodeTypePackage,
+		StartLine:    1,
+		EndLine:      len(original),
+		LineCount:    len(original),
+		LastModified: now,
+	})
+
+	// Locally declared methods, parameters and variables are never shared library calls
+	local := map[string]bool{}
+	for _, line := range sanitized {
+		for _, m := range localRe.FindAllStringSubmatch(line, -1) {
+			local[m[1]+m[2]+m[3]] = true
+		}
+		if m := defRe.FindStringSubmatchIndex(line); m != nil {
+			local[line[m[2]:m[3]]] = true
+			params := line[m[3]:]
+			params = params[strings.Index(params, "(")+1:]
+			if end := strings.Index(params, ")"); end >= 0 {
+				params = params[:end]
+			}
+			for _, param := range strings.Split(params, ",") {
+				if p := paramRe.FindStringSubmatch(strings.TrimSpace(param)); p != nil {
+					local[p[1]] = true
+				}
+			}
+		}
+	}
+
+	libraries := e.extractLibraries(sanitized, original, result)
+	callPackage := DefaultSharedLibrary
+	if len(libraries) == 1 {
+		callPackage = libraries[0]
+	}
+
+	var (
+		stack      []frame
+		pending    *frame
+		complexity = map[*models.ASTNode]int{}
+	)
+
+	innermost := func(match func(frame) bool) *frame {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if match(stack[i]) {
+				return &stack[i]
+			}
+		}
+		return nil
+	}
+	typeName := func() string {
+		if f := innermost(func(f frame) bool { return f.node != nil && f.node.NodeType == models.NodeTypeType }); f != nil {
+			return f.node.TypeName
+		}
+		return ""
+	}
+
+	for i, line := range sanitized {
+		lineNo := i + 1
+		declCol := -1
+
+		newNode := func(nodeType models.NodeType, typ, method string) *models.ASTNode {
+			return &models.ASTNode{
+				FilePath:     filePath,
+				PackageName:  packageName,
+				TypeName:     typ,
+				MethodName:   method,
+				NodeType:     nodeType,
+				StartLine:    lineNo,
+				EndLine:      lineNo,
+				LastModified: now,
+			}
+		}
+
+		switch {
+		case stageRe.MatchString(line):
+			loc := stageRe.FindStringSubmatchIndex(line)
+			name := strings.TrimSpace(original[i][loc[2]:loc[3]])
+			if parent := innermost(func(f frame) bool { return f.stage != "" }); parent != nil {
+				name = parent.stage + "/" + name
+			}
+			pending = &frame{node: newNode(models.NodeTypeMethod, typeName(), name), stage: name}
+			declCol = loc[0]
+		case pipelineRe.MatchString(line):
+			pending = &frame{node: newNode(models.NodeTypeType, "pipeline", "")}
+			declCol = 0
+		case nodeRe.MatchString(line):
+			pending = &frame{node: newNode(models.NodeTypeType, "node", "")}
+			declCol = 0
+		case classRe.MatchString(line):
+			m := classRe.FindStringSubmatchIndex(line)
+			pending = &frame{node: newNode(models.NodeTypeType, line[m[2]:m[3]], "")}
+			declCol = m[0]
+		case defRe.MatchString(line):
+			m := defRe.FindStringSubmatchIndex(line)
+			pending = &frame{node: newNode(models.NodeTypeMethod, typeName(), line[m[2]:m[3]])}
+			declCol = m[2]
+		}
+
+		// Attribute decision points to the innermost method
+		if decisions := len(decisionRe.FindAllString(line, -1)); decisions > 0 {
+			if f := innermost(func(f frame) bool { return f.node != nil && f.node.NodeType == models.NodeTypeMethod }); f != nil {
+				complexity[f.node] += decisions
+			}
+		}
+
+		if m := callRe.FindStringSubmatch(line); m != nil && !builtinSteps[m[1]] && !local[m[1]] {
+			class, method := "", m[1]
+			if m[2] != "" {
+				class, method = m[1], m[2]
+			}
+			result.AddLibrary(&models.LibraryRelationship{
+				LineNo:           lineNo,
+				RelationshipType: string(models.RelationshipCall),
+				Text: fmt.Sprintf("%s (pkg=%s;class=%s;method=%s;framework=%s)",
+					strings.TrimSpace(original[i]), callPackage, class, method, SharedLibraryFramework),
+			})
+		}
+
+		for col, ch := range line {
+			switch ch {
+			case '{':
+				if pending != nil && col >= declCol {
+					stack = append(stack, *pending)
+					pending = nil
+				} else {
+					stack = append(stack, frame{})
+				}
+			case '}':
+				if len(stack) == 0 {
+					continue
+				}
+				closed := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if closed.node != nil {
+					closed.node.EndLine = lineNo
+					closed.node.LineCount = lineNo - closed.node.StartLine + 1
+					if closed.node.NodeType == models.NodeTypeMethod {
+						closed.node.CyclomaticComplexity = 1 + complexity[closed.node]
+					}
+					result.AddNode(closed.node)
+				}
+			}
+		}
+	}
+
+	// Unterminated blocks extend to the end of the file
+	for i := len(stack) - 1; i >= 0; i-- {
+		if node := stack[i].node; node != nil {
+			node.EndLine = len(original)
+			node.LineCount = node.EndLine - node.StartLine + 1
+			if node.NodeType == models.NodeTypeMethod {
+				node.CyclomaticComplexity = 1 + complexity[node]
+			}
+			result.AddNode(node)
+		}
+	}
+
+	return result, nil
+}
+
+// extractLibraries records @Library annotations and library steps as import relationships,
+// returning the names of the imported libraries
+func (e *GroovyASTExtractor) extractLibraries(sanitized, original []string, result *types.ASTResult) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	add := func(lineNo int, text, ref string) {
+		name := strings.TrimSpace(ref)
+		if i := strings.Index(name, "@"); i >= 0 {
+			name = name[:i]
+		}
+		if name == "" {
+			return
+		}
+		result.AddLibrary(&models.LibraryRelationship{
+			LineNo:           lineNo,
+			RelationshipType: string(models.RelationshipImport),
+			Text: fmt.Sprintf("%s (pkg=%s;class=;method=;framework=%s)",
+				strings.TrimSpace(text), name, SharedLibraryFramework),
+		})
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for i, line := range sanitized {
+		if loc := libraryRe.FindStringIndex(line); loc != nil {
+			// @Library('name@version') or @Library(['a', 'b@v1'])
+			end := strings.Index(line[loc[1]:], ")")
+			if end < 0 {
+				end = len(line) - loc[1]
+			}
+			for _, m := range literalRe.FindAllStringSubmatchIndex(line[loc[1]:loc[1]+end], -1) {
+				add(i+1, original[i], original[i][loc[1]+m[2]:loc[1]+m[3]])
+			}
+			continue
+		}
+		if m := libStepRe.FindStringSubmatchIndex(line); m != nil {
+			add(i+1, original[i], original[i][m[2]:m[3]])
+		}
+	}
+	return names
+}
+
+// sanitize blanks out comments and the contents of string literals while preserving
+// line and column positions, so that braces and keywords inside them are ignored
+func sanitize(content string) string {
+	out := []byte(content)
+	blank := func(i int) {
+		if out[i] != '\n' {
+			out[i] = ' '
+		}
+	}
+
+	for i := 0; i < len(out); i++ {
+		switch {
+		case strings.HasPrefix(content[i:], "//"):
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				blank(i)
+			}
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				blank(i)
+			}
+			i--
+		case strings.HasPrefix(content[i:], `'''`) || strings.HasPrefix(content[i:], `"""`):
+			quote := content[i : i+3]
+			end := strings.Index(content[i+3:], quote)
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 3 + end
+			}
+			for j := i + 3; j < stop; j++ {
+				blank(j)
+			}
+			i = stop + 2
+		case content[i] == '\'' || content[i] == '"':
+			quote := content[i]
+			j := i + 1
+			for ; j < len(out) && content[j] != quote && content[j] != '\n'; j++ {
+				if content[j] == '\\' && j+1 < len(out) && content[j+1] != '\n' {
+					blank(j)
+					j++
+				}
+				blank(j)
+			}
+			i = j
+		}
+	}
+	return string(out)
+}
+
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// groovyAnalyzerAdapter adapts the GroovyASTExtractor to the languages.ASTAnalyzer interface
+type groovyAnalyzerAdapter struct {
+	extractor *GroovyASTExtractor
+}
+
+func (a *groovyAnalyzerAdapter) AnalyzeFile(task interface{}, filepath string, content []byte) (interface{}, error) {
+	clickyTask, ok := task.(*clicky.Task)
+	if !ok {
+		return nil, nil
+	}
+
+	// Delegate to the generic analyzer, which stores nodes and library relationships
+	genericAnalyzer := languages.GetGenericAnalyzerAdapter()
+	return genericAnalyzer.AnalyzeFile(clickyTask, filepath, content)
+}
+
+func init() {
+	groovyExtractor := NewGroovyASTExtractor()
+	analysis.DefaultExtractorRegistry.Register("groovy", groovyExtractor)
+	languages.SetAnalyzer("groovy", &groovyAnalyzerAdapter{extractor: groovyExtractor})
+}
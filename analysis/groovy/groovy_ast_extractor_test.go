@@ -0,0 +1,143 @@
+package groovy
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/types"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Groovy AST Extractor", func() {
+	var (
+		extractor *GroovyASTExtractor
+		astCache  *cache.ASTCache
+	)
+
+	BeforeEach(func() {
+		extractor = NewGroovyASTExtractor()
+		astCache = cache.MustGetASTCache()
+	})
+
+	methods := func(result *types.ASTResult) map[string]*models.ASTNode {
+		found := map[string]*models.ASTNode{}
+		for _, node := range result.Nodes {
+			if node.NodeType == models.NodeTypeMethod {
+				found[node.MethodName] = node
+			}
+		}
+		return found
+	}
+
+	Context("when extracting from a declarative Jenkinsfile", func() {
+		var result *types.ASTResult
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "Jenkinsfile")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err = extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).NotTo(BeNil())
+		})
+
+		It("should extract stages as method nodes of the pipeline", func() {
+			stages := methods(result)
+			Expect(stages).To(HaveKey("Build"))
+			Expect(stages).To(HaveKey("Test"))
+			Expect(stages).To(HaveKey("Test/Unit"))
+			Expect(stages).To(HaveKey("Test/Integration"))
+			Expect(stages).To(HaveKey("Deploy"))
+
+			Expect(stages["Build"].TypeName).To(Equal("pipeline"))
+			Expect(stages["Build"].StartLine).To(Equal(23))
+			Expect(stages["Build"].EndLine).To(Equal(28))
+			Expect(stages["Test"].EndLine).To(Equal(46))
+			Expect(stages["Deploy"].CyclomaticComplexity).To(Equal(4))
+		})
+
+		It("should extract helper methods and the pipeline block", func() {
+			Expect(methods(result)).To(HaveKey("notify"))
+
+			var pipeline *models.ASTNode
+			for _, node := range result.Nodes {
+				if node.NodeType == models.NodeTypeType && node.TypeName == "pipeline" {
+					pipeline = node
+				}
+			}
+			Expect(pipeline).NotTo(BeNil())
+			Expect(pipeline.StartLine).To(Equal(15))
+			Expect(pipeline.EndLine).To(Equal(67))
+		})
+
+		It("should record shared library imports and calls", func() {
+			var imports, calls []string
+			for _, lib := range result.Libraries {
+				switch lib.RelationshipType {
+				case string(models.RelationshipImport):
+					imports = append(imports, lib.Text)
+				case string(models.RelationshipCall):
+					calls = append(calls, lib.Text)
+				}
+			}
+
+			Expect(imports).To(ConsistOf(ContainSubstring("(pkg=pipeline-utils;class=;method=;framework=jenkins-shared-library)")))
+			Expect(calls).To(ConsistOf(
+				ContainSubstring("(pkg=pipeline-utils;class=;method=buildImage;"),
+				ContainSubstring("(pkg=pipeline-utils;class=;method=runIntegrationTests;"),
+				ContainSubstring("(pkg=pipeline-utils;class=deployer;method=rollout;"),
+			))
+		})
+	})
+
+	Context("when extracting from a scripted pipeline", func() {
+		It("should extract stages inside node blocks and every loaded library", func() {
+			content := []byte(`@Library(['common@main', 'deploy']) _
+library identifier: 'extra@1.0', retriever: modernSCM([$class: 'GitSCMSource'])
+
+node('linux') {
+    stage('Checkout') { checkout scm }
+    stage('Build') {
+        def result = compute()
+        result.publish()
+        common.build()
+    }
+}
+`)
+			result, err := extractor.ExtractFile(astCache, "ci/Jenkinsfile.release", content)
+			Expect(err).NotTo(HaveOccurred())
+
+			stages := methods(result)
+			Expect(stages).To(HaveKey("Checkout"))
+			Expect(stages["Checkout"].StartLine).To(Equal(5))
+			Expect(stages["Checkout"].EndLine).To(Equal(5))
+			Expect(stages["Build"].TypeName).To(Equal("node"))
+			Expect(stages["Build"].EndLine).To(Equal(10))
+
+			var texts []string
+			for _, lib := range result.Libraries {
+				texts = append(texts, lib.Text)
+			}
+			Expect(texts).To(ConsistOf(
+				ContainSubstring("(pkg=common;"),
+				ContainSubstring("(pkg=deploy;"),
+				ContainSubstring("(pkg=extra;"),
+				ContainSubstring("(pkg=shared-library;class=common;method=build;"),
+			))
+		})
+	})
+
+	It("should be registered for Jenkinsfiles and Groovy files", func() {
+		for _, path := range []string{"Jenkinsfile", "ci/Jenkinsfile.release", "vars/deploy.groovy"} {
+			_, language, found := analysis.GetExtractorByFile(path)
+			Expect(found).To(BeTrue(), path)
+			Expect(language).To(Equal("groovy"))
+		}
+	})
+})
@@ -7,10 +7,12 @@ import com.github.javaparser.ast.ImportDeclaration;
 import com.github.javaparser.ast.Modifier;
 import com.github.javaparser.ast.Node;
 import com.github.javaparser.ast.body.*;
+import com.github.javaparser.ast.expr.AnnotationExpr;
 import com.github.javaparser.ast.expr.MethodCallExpr;
 import com.github.javaparser.ast.stmt.BlockStmt;
 import com.github.javaparser.ast.type.Type;
 import com.github.javaparser.ast.visitor.VoidVisitorAdapter;
+import com.github.javaparser.javadoc.JavadocBlockTag;
 import com.google.gson.Gson;
 import com.google.gson.GsonBuilder;
 import com.google.gson.annotations.SerializedName;
@@ -172,6 +174,9 @@ public class JavaASTExtractor {
                 }
             }
 
+            markDeprecated(node, n);
+
+            markAnnotations(node, n);
             nodes.add(node);
             super.visit(n, arg);
         }
@@ -199,6 +204,9 @@ public class JavaASTExtractor {
             node.returnCount = 0;
             node.cyclomaticComplexity = 0;
 
+            markDeprecated(node, n);
+
+            markAnnotations(node, n);
             nodes.add(node);
             super.visit(n, arg);
         }
@@ -240,6 +248,9 @@ public class JavaASTExtractor {
             // Calculate cyclomatic complexity
             node.cyclomaticComplexity = calculateCyclomaticComplexity(n);
 
+            markDeprecated(node, n);
+
+            markAnnotations(node, n);
             nodes.add(node);
 
             // Extract method calls
@@ -279,6 +290,9 @@ public class JavaASTExtractor {
             // Calculate cyclomatic complexity
             node.cyclomaticComplexity = calculateCyclomaticComplexity(n);
 
+            markDeprecated(node, n);
+
+            markAnnotations(node, n);
             nodes.add(node);
 
             // Extract method calls
@@ -310,6 +324,9 @@ public class JavaASTExtractor {
                 node.returnCount = 0;
                 node.cyclomaticComplexity = 0;
 
+                markDeprecated(node, n);
+
+                markAnnotations(node, n);
                 nodes.add(node);
             }
             super.visit(n, arg);
@@ -334,6 +351,43 @@ public class JavaASTExtractor {
     }
 
 
+    /**
+     * Records the names of the annotations of a declaration, as written without their arguments,
+     * as the annotations metadata of a node for annotation rules, e.g. Autowired,Qualifier
+     */
+    private void markAnnotations(GoASTNode node, BodyDeclaration<?> declaration) {
+        List<String> names = new ArrayList<String>();
+        for (AnnotationExpr annotation : declaration.getAnnotations()) {
+            names.add(annotation.getNameAsString());
+        }
+        if (!names.isEmpty()) {
+            node.metadata.put("annotations", String.join(",", names));
+        }
+    }
+
+    /**
+     * Records @Deprecated declarations and @deprecated Javadoc tags as the deprecated metadata
+     * of a node, with the text of the Javadoc tag as the deprecation notice
+     */
+    private void markDeprecated(GoASTNode node, BodyDeclaration<?> declaration) {
+        boolean deprecated = declaration.isAnnotationPresent("Deprecated");
+        String notice = "true";
+        if (declaration.getComment().isPresent() && declaration.getComment().get().isJavadocComment()) {
+            for (JavadocBlockTag tag : declaration.getComment().get().asJavadocComment().parse().getBlockTags()) {
+                if (tag.getType() == JavadocBlockTag.Type.DEPRECATED) {
+                    deprecated = true;
+                    String text = tag.getContent().toText().trim().replaceAll("\\s+", " ");
+                    if (!text.isEmpty()) {
+                        notice = text;
+                    }
+                }
+            }
+        }
+        if (deprecated) {
+            node.metadata.put("deprecated", notice);
+        }
+    }
+
     private int calculateCyclomaticComplexity(CallableDeclaration<?> method) {
         final int[] complexity = {1}; // Base complexity is 1
 
@@ -430,10 +484,14 @@ public class JavaASTExtractor {
         @SerializedName("is_private")
         public boolean isPrivate;
 
+        // Language specific metadata, e.g. the deprecation notice
+        public Map<String, String> metadata;
+
         // Constructor for easy creation
         public GoASTNode() {
             this.parameters = new ArrayList<Parameter>();
             this.returnValues = new ArrayList<ReturnValue>();
+            this.metadata = new HashMap<String, String>();
             this.language = "java";
         }
     }
@@ -1,16 +1,17 @@
 package java
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/limits"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/commons/logger"
 )
@@ -175,7 +176,10 @@ func (e *JavaASTExtractor) runJavaASTExtraction(filePath string) (*JavaASTResult
 	logger.Tracef("[java] analyzing %s", filePath)
 
 	// Execute the JAR with java
-	cmd := exec.Command("java", "-jar", tempJar, filePath)
+	cmd, err := limits.CommandFor(context.Background(), "java", "-jar", tempJar, filePath)
+	if err != nil {
+		return nil, err
+	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("Java AST extraction failed: %w - output: %s", err, string(output))
@@ -237,4 +237,50 @@ var _ = Describe("JavaASTExtractor", func() {
 		})
 	})
 
+	Describe("Deprecated symbols", func() {
+		It("should record @Deprecated annotations and @deprecated Javadoc tags", func() {
+			testFile := filepath.Join("testdata", "com", "example", "Legacy.java")
+			javaContent, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, javaContent)
+			Expect(err).ToNot(HaveOccurred())
+
+			notices := make(map[string]string)
+			for _, node := range result.Nodes {
+				if notice, ok := node.Metatdata[models.MetadataDeprecated]; ok {
+					notices[node.String()] = notice
+				}
+			}
+			Expect(notices).To(Equal(map[string]string{
+				"com.example.Legacy":            "Use {@link Service} instead.",
+				"com.example.Legacy.timeout":    "true",
+				"com.example.Legacy.connect":    "the connection is opened lazily",
+				"com.example.Legacy.disconnect": "true",
+			}))
+		})
+	})
+
+	Describe("Annotations", func() {
+		It("should record the names of annotations without their arguments", func() {
+			testFile := filepath.Join("testdata", "com", "example", "Injected.java")
+			javaContent, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, javaContent)
+			Expect(err).ToNot(HaveOccurred())
+
+			annotations := make(map[string][]string)
+			for _, node := range result.Nodes {
+				if _, ok := node.Metatdata[models.MetadataAnnotations]; ok {
+					annotations[node.String()] = models.Annotations(node)
+				}
+			}
+			Expect(annotations).To(HaveKeyWithValue("com.example.Injected", []string{"Component"}))
+			Expect(annotations).To(HaveKeyWithValue("com.example.Injected.service", []string{"Autowired", "Qualifier"}))
+			Expect(annotations).To(HaveKeyWithValue("com.example.Injected.toString", []string{"Override"}))
+			Expect(annotations).ToNot(HaveKey("com.example.Injected.fallback"))
+		})
+	})
+
 })
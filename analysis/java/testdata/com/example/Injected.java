@@ -0,0 +1,25 @@
+package com.example;
+
+import org.springframework.beans.factory.annotation.Autowired;
+import org.springframework.beans.factory.annotation.Qualifier;
+import org.springframework.stereotype.Component;
+
+@Component
+public class Injected {
+
+    @Autowired
+    @Qualifier("primary")
+    private Service service;
+
+    private final Service fallback;
+
+    @Autowired
+    public Injected(Service fallback) {
+        this.fallback = fallback;
+    }
+
+    @Override
+    public String toString() {
+        return "Injected";
+    }
+}
@@ -0,0 +1,30 @@
+package com.example;
+
+/**
+ * Client of the legacy API.
+ *
+ * @deprecated Use {@link Service} instead.
+ */
+@Deprecated
+public class Legacy {
+
+    @Deprecated
+    private int timeout;
+
+    private String endpoint;
+
+    /**
+     * Connects to the API.
+     *
+     * @deprecated the connection is opened lazily
+     */
+    public void connect() {
+    }
+
+    @Deprecated
+    public void disconnect() {
+    }
+
+    public void close() {
+    }
+}
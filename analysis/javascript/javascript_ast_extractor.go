@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -14,6 +13,7 @@ import (
 	"github.com/flanksource/arch-unit/analysis"
 	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/limits"
 	"github.com/flanksource/arch-unit/models"
 	flanksourceContext "github.com/flanksource/commons/context"
 )
@@ -108,7 +108,8 @@ func (e *JavaScriptASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath
 	}
 
 	// Convert JavaScript nodes to AST nodes
-	nodeMap := make(map[string]string) // fullName -> node key
+	nodeMap := make(map[string]string)            // fullName -> node key
+	functions := make(map[string]*models.ASTNode) // Class.method or function name -> node
 	for _, node := range jsResult.Nodes {
 		astNode := &models.ASTNode{
 			FilePath:             filePath,
@@ -152,10 +153,25 @@ func (e *JavaScriptASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath
 		nodeMap[fullName] = nodeKey
 
 		result.Nodes = append(result.Nodes, astNode)
+		if node.Type == "function" || node.Type == "method" {
+			if node.Parent != "" {
+				functions[node.Parent+"."+node.Name] = astNode
+			} else {
+				functions[node.Name] = astNode
+			}
+		}
 	}
 
 	// Convert relationships
 	for _, rel := range jsResult.Relationships {
+		if rel.Type == "http" {
+			addHTTPCall(functions[rel.FromEntity], rel, result)
+			continue
+		}
+		if rel.Type == "env" {
+			addEnvVar(functions[rel.FromEntity], rel, result)
+			continue
+		}
 		fromKey, fromExists := nodeMap[rel.FromEntity]
 		if !fromExists {
 			continue
@@ -194,6 +210,40 @@ func (e *JavaScriptASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath
 	return result, nil
 }
 
+// addHTTPCall records an axios or fetch call of a function to a known URL as a statement and
+// links the function to the URL
+func addHTTPCall(function *models.ASTNode, rel JavaScriptRelationship, result *types.ASTResult) {
+	if function == nil {
+		return
+	}
+	function.Statements = append(function.Statements, models.ASTStatement{
+		StartLine: rel.Line,
+		EndLine:   rel.Line,
+		Text:      rel.Text,
+		Type:      models.ASTStatementTypeHttpCall,
+	})
+	result.Relationships = append(result.Relationships, &models.ASTRelationship{
+		FromAST:          function,
+		LineNo:           rel.Line,
+		RelationshipType: models.RelationshipTypeHTTPCall,
+		Text:             rel.Text,
+	})
+}
+
+// addEnvVar links a function to an environment variable it reads from process.env
+func addEnvVar(function *models.ASTNode, rel JavaScriptRelationship, result *types.ASTResult) {
+	if function == nil {
+		return
+	}
+	result.Relationships = append(result.Relationships, &models.ASTRelationship{
+		FromAST:          function,
+		LineNo:           rel.Line,
+		RelationshipType: models.RelationshipTypeEnvVar,
+		Text:             rel.Text,
+		Metadata:         map[string]string{"source": "process.env"},
+	})
+}
+
 // extractPackageName extracts package name from file path or package.json
 func (e *JavaScriptASTExtractor) extractPackageName(filePath string) string {
 	dir := filepath.Dir(filePath)
@@ -249,7 +299,10 @@ func (e *JavaScriptASTExtractor) runJavaScriptASTExtraction(filePath string) (*J
 	defer func() { _ = os.Remove(scriptPath) }()
 
 	// Execute the script with Node.js
-	cmd := exec.Command("node", scriptPath, filePath)
+	cmd, err := limits.CommandFor(ctx, "node", scriptPath, filePath)
+	if err != nil {
+		return nil, err
+	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("JavaScript AST extraction failed: %w - output: %s", err, string(output))
@@ -2,6 +2,12 @@ const fs = require('fs');
 const acorn = require('acorn');
 const walk = require('acorn-walk');
 
+// Methods of axios and axios instances that send a request, e.g. axios.get(url) or api.post(url)
+const HTTP_VERBS = {
+  get: 'GET', head: 'HEAD', post: 'POST', put: 'PUT', patch: 'PATCH', delete: 'DELETE', options: 'OPTIONS', request: ''
+};
+const HTTP_CLIENT = /axios|client|api|http/i;
+
 class JavaScriptASTExtractor {
   constructor(filename) {
     this.filename = filename;
@@ -12,9 +18,19 @@ class JavaScriptASTExtractor {
     this.currentClass = null;
     this.currentFunction = null;
     this.scopeStack = [];
+    this.constants = {}; // top level string constants, for HTTP call URLs and environment variables
   }
 
   extract(ast) {
+    ast.body.forEach(stmt => {
+      if (stmt.type === 'VariableDeclaration' && stmt.kind === 'const') {
+        stmt.declarations.forEach(decl => {
+          if (decl.id.type === 'Identifier' && decl.init && decl.init.type === 'Literal' && typeof decl.init.value === 'string') {
+            this.constants[decl.id.name] = decl.init.value;
+          }
+        });
+      }
+    });
     this.walkAST(ast);
     return {
       module: this.module,
@@ -239,6 +255,36 @@ class JavaScriptASTExtractor {
               text: 'calls ' + calledName
             });
           }
+
+          const httpCall = this.getHTTPCall(callNode);
+          if (httpCall) {
+            this.relationships.push({
+              from_entity: this.currentFunction,
+              to_entity: '',
+              type: 'http',
+              line: callNode.loc ? callNode.loc.start.line : 0,
+              text: httpCall
+            });
+          }
+        },
+        // process.env.NAME and process.env['NAME']
+        MemberExpression: (memberNode) => {
+          if (!this.isProcessEnv(memberNode.object)) {
+            return;
+          }
+          const name = memberNode.computed ? this.getConstant(memberNode.property) : memberNode.property.name;
+          this.addEnvVar(name, memberNode);
+        },
+        // const { NAME, OTHER: alias } = process.env
+        VariableDeclarator: (declNode) => {
+          if (!declNode.init || !this.isProcessEnv(declNode.init) || declNode.id.type !== 'ObjectPattern') {
+            return;
+          }
+          declNode.id.properties.forEach(prop => {
+            if (prop.type === 'Property') {
+              this.addEnvVar(prop.computed ? this.getConstant(prop.key) : prop.key.name || prop.key.value, prop);
+            }
+          });
         }
       });
     }
@@ -301,6 +347,130 @@ class JavaScriptASTExtractor {
     return parts.join('.');
   }
 
+  // getHTTPCall returns the method and URL of an axios or fetch call to a known URL, e.g.
+  // "GET {}/users/{}" for axios.get(`${baseURL}/users/${id}`)
+  getHTTPCall(node) {
+    const callee = node.callee;
+    let urlArg = node.arguments[0];
+    let options = null;
+    let method = '';
+
+    if (callee.type === 'MemberExpression' && callee.property.type === 'Identifier') {
+      const verb = callee.property.name.toLowerCase();
+      if (!(verb in HTTP_VERBS) || !HTTP_CLIENT.test(this.getNodeName(callee.object))) {
+        return null;
+      }
+      method = HTTP_VERBS[verb];
+      if (verb === 'request') {
+        // axios.request({ method, url })
+        options = urlArg;
+        urlArg = this.getOption(options, 'url');
+      }
+    } else if (callee.type === 'Identifier' && (callee.name === 'fetch' || callee.name === 'axios')) {
+      options = node.arguments[1];
+      method = 'GET';
+      if (callee.name === 'axios' && urlArg && urlArg.type === 'ObjectExpression') {
+        // axios({ method, url })
+        options = urlArg;
+        urlArg = this.getOption(options, 'url');
+      }
+    } else {
+      return null;
+    }
+
+    const methodOption = this.getOption(options, 'method');
+    if (methodOption && methodOption.type === 'Literal' && typeof methodOption.value === 'string') {
+      method = methodOption.value.toUpperCase();
+    } else if (methodOption) {
+      method = '';
+    }
+
+    const url = urlArg ? this.getURL(urlArg) : null;
+    if (url === null) {
+      return null;
+    }
+    return method ? method + ' ' + url : url;
+  }
+
+  // isProcessEnv returns whether an expression is process.env
+  isProcessEnv(node) {
+    return node.type === 'MemberExpression' && !node.computed &&
+      node.object.type === 'Identifier' && node.object.name === 'process' &&
+      node.property.type === 'Identifier' && node.property.name === 'env';
+  }
+
+  // addEnvVar records an environment variable used by the current function
+  addEnvVar(name, node) {
+    if (typeof name !== 'string' || name === '') {
+      return;
+    }
+    this.relationships.push({
+      from_entity: this.currentFunction,
+      to_entity: '',
+      type: 'env',
+      line: node.loc ? node.loc.start.line : 0,
+      text: name
+    });
+  }
+
+  // getConstant returns the value of a string literal or a top level string constant
+  getConstant(node) {
+    if (node.type === 'Literal') {
+      return typeof node.value === 'string' ? node.value : null;
+    }
+    if (node.type === 'Identifier' && node.name in this.constants) {
+      return this.constants[node.name];
+    }
+    return null;
+  }
+
+  // getOption returns the value of a property of an object literal
+  getOption(node, name) {
+    if (!node || node.type !== 'ObjectExpression') {
+      return null;
+    }
+    const prop = node.properties.find(p => p.type === 'Property' && !p.computed &&
+      ((p.key.type === 'Identifier' && p.key.name === name) || p.key.value === name));
+    return prop ? prop.value : null;
+  }
+
+  // getURL returns the URL an expression evaluates to with runtime parts as {}, null when no part
+  // of it is constant
+  getURL(node) {
+    switch (node.type) {
+      case 'Literal':
+        return typeof node.value === 'string' ? node.value : null;
+      case 'Identifier':
+        return node.name in this.constants ? this.constants[node.name] : null;
+      case 'TemplateLiteral': {
+        const parts = [];
+        let known = false;
+        node.quasis.forEach((quasi, i) => {
+          parts.push(quasi.value.cooked);
+          known = known || quasi.value.cooked !== '';
+          if (i < node.expressions.length) {
+            const value = this.getURL(node.expressions[i]);
+            known = known || value !== null;
+            parts.push(value !== null ? value : '{}');
+          }
+        });
+        return known ? parts.join('') : null;
+      }
+      case 'BinaryExpression': {
+        if (node.operator !== '+') {
+          return null;
+        }
+        const left = this.getURL(node.left);
+        const right = this.getURL(node.right);
+        if (left === null && right === null) {
+          return null;
+        }
+        return (left !== null ? left : '{}') + (right !== null ? right : '{}');
+      }
+    }
+    return null;
+  }
+
   getNodeName(node) {
     if (node.type === 'Identifier') {
       return node.name;
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
 )
 
 var _ = Describe("JavaScript AST Extractor", func() {
@@ -60,6 +61,62 @@ var _ = Describe("JavaScript AST Extractor", func() {
 				Expect(result.Nodes).NotTo(BeEmpty(), "Should extract JavaScript nodes")
 			})
 		})
+
+		Context("when extracting HTTP calls", func() {
+			It("should link functions to the URLs of their axios and fetch calls", func() {
+				testFile := filepath.Join("testdata", "billing_client.js")
+				content, err := os.ReadFile(testFile)
+				Expect(err).NotTo(HaveOccurred())
+
+				result, err := extractor.ExtractFile(astCache, testFile, content)
+				if err != nil {
+					if strings.Contains(err.Error(), "acorn") {
+						Skip("JavaScript extraction failed (likely missing acorn): " + err.Error())
+					}
+					Fail("Unexpected error: " + err.Error())
+				}
+
+				urls := make(map[string][]string)
+				for _, rel := range result.Relationships {
+					if rel.RelationshipType == models.RelationshipTypeHTTPCall {
+						urls[rel.FromAST.MethodName] = append(urls[rel.FromAST.MethodName], rel.Text)
+					}
+				}
+				Expect(urls).To(Equal(map[string][]string{
+					"invoice": {"GET /invoices/{}"},
+					"pay":     {"POST http://billing.svc:8080/invoices/{}/payments"},
+					"health":  {"HEAD http://billing.svc:8080/healthz"},
+				}))
+			})
+		})
+
+		Context("when extracting environment variables", func() {
+			It("should link functions to the process.env variables they read", func() {
+				testFile := filepath.Join("testdata", "settings.js")
+				content, err := os.ReadFile(testFile)
+				Expect(err).NotTo(HaveOccurred())
+
+				result, err := extractor.ExtractFile(astCache, testFile, content)
+				if err != nil {
+					if strings.Contains(err.Error(), "acorn") {
+						Skip("JavaScript extraction failed (likely missing acorn): " + err.Error())
+					}
+					Fail("Unexpected error: " + err.Error())
+				}
+
+				envVars := make(map[string][]string)
+				for _, rel := range result.Relationships {
+					if rel.RelationshipType == models.RelationshipTypeEnvVar {
+						envVars[rel.FromAST.MethodName] = append(envVars[rel.FromAST.MethodName], rel.Text)
+					}
+				}
+				// The name read by lookup is only known at runtime
+				Expect(envVars).To(Equal(map[string][]string{
+					"constructor": {"DATABASE_URL", "APP_PORT"},
+					"debug":       {"DEBUG", "LOG_LEVEL"},
+				}))
+			})
+		})
 	})
 
 	Describe("extractPackageName", func() {
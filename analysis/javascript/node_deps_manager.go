@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/flanksource/arch-unit/internal/capabilities"
 	flanksourceContext "github.com/flanksource/commons/context"
 )
 
@@ -47,6 +48,11 @@ func (m *NodeDependenciesManager) EnsureDependencies(ctx flanksourceContext.Cont
 
 // installDependencies installs required Node.js dependencies
 func (m *NodeDependenciesManager) installDependencies(ctx flanksourceContext.Context) error {
+	// The parsers run on node, and are installed with npm or yarn
+	if err := capabilities.Require("node"); err != nil {
+		return err
+	}
+
 	// Create .arch-unit directory if it doesn't exist
 	archUnitDir := filepath.Dir(m.baseDir)
 
@@ -93,6 +99,10 @@ func (m *NodeDependenciesManager) installDependencies(ctx flanksourceContext.Con
 		return nil
 	}
 
+	if err := capabilities.Require("npm"); err != nil {
+		return err
+	}
+
 	cmd := exec.Command("npm", "install", "--no-save", "--no-audit", "--no-fund")
 	cmd.Dir = archUnitDir
 
@@ -0,0 +1,24 @@
+const BILLING_URL = 'http://billing.svc:8080';
+
+class BillingClient {
+  constructor(api) {
+    this.api = api;
+  }
+
+  invoice(id) {
+    return this.api.get(`/invoices/${id}`);
+  }
+
+  pay(id, amount) {
+    return axios({ method: 'post', url: BILLING_URL + '/invoices/' + id + '/payments', data: amount });
+  }
+}
+
+async function health(cache) {
+  cache.get('timeout');
+  return fetch(`${BILLING_URL}/healthz`, { method: 'HEAD' });
+}
+
+function fetchAll(url) {
+  return fetch(url);
+}
@@ -0,0 +1,17 @@
+const PORT_VAR = 'APP_PORT';
+
+class Settings {
+  constructor() {
+    this.databaseURL = process.env.DATABASE_URL;
+    this.port = process.env[PORT_VAR] || 8080;
+  }
+
+  debug() {
+    const { DEBUG, LOG_LEVEL: level } = process.env;
+    return DEBUG === '1' || level === 'debug';
+  }
+}
+
+function lookup(name) {
+  return process.env[name];
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -15,6 +14,7 @@ import (
 	"github.com/flanksource/arch-unit/analysis"
 	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/limits"
 	"github.com/flanksource/arch-unit/models"
 	flanksourceContext "github.com/flanksource/commons/context"
 )
@@ -130,6 +130,7 @@ func (e *TypeScriptASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath
 		switch node.Type {
 		case "class", "interface", "enum", "type":
 			astNode.TypeName = node.Name
+			astNode.Metatdata = map[string]string{"kind": node.Type}
 		case "method", "constructor":
 			if node.Parent != "" {
 				astNode.TypeName = node.Parent
@@ -249,7 +250,10 @@ func (e *TypeScriptASTExtractor) runTypeScriptASTExtraction(filePath string) (*T
 	defer os.Remove(scriptPath)
 
 	// Execute the script with Node.js
-	cmd := exec.Command("node", scriptPath, filePath)
+	cmd, err := limits.CommandFor(ctx, "node", scriptPath, filePath)
+	if err != nil {
+		return nil, err
+	}
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("TypeScript AST extraction failed: %w - output: %s", err, string(output))
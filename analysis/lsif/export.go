@@ -0,0 +1,252 @@
+package lsif
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Export writes the nodes of the files under root and the relationships between them as an LSIF
+// index: every node is a definition with a hover and a moniker naming it, and the relationships
+// pointing to a node are its references
+func Export(w io.Writer, astCache *cache.ASTCache, root string) error {
+	root = filepath.Clean(root)
+	var all []*models.ASTNode
+	if err := astCache.GetReadQuery().Order("file_path, start_line").Find(&all).Error; err != nil {
+		return fmt.Errorf("failed to query AST nodes: %w", err)
+	}
+	var relationships []*models.ASTRelationship
+	if err := astCache.GetReadQuery().Where("to_ast_id IS NOT NULL").Order("from_ast_id, line_no").Find(&relationships).Error; err != nil {
+		return fmt.Errorf("failed to query AST relationships: %w", err)
+	}
+
+	var nodes []*models.ASTNode
+	byID := make(map[int64]*models.ASTNode)
+	for _, node := range all {
+		if node.NodeType == models.NodeTypePackage || node.NodeType == models.NodeTypeDependency ||
+			!strings.HasPrefix(node.FilePath, root+string(filepath.Separator)) {
+			continue
+		}
+		nodes = append(nodes, node)
+		byID[node.ID] = node
+	}
+
+	e := &emitter{encoder: json.NewEncoder(w), sources: make(map[string][]string)}
+	e.vertex(&Element{
+		Label:            "metaData",
+		Version:          Version,
+		ProjectRoot:      fileURI(root),
+		PositionEncoding: "utf-16",
+		ToolInfo:         &ToolInfo{Name: "arch-unit", Version: cache.CLIVersion},
+	})
+
+	var files []string
+	documents := make(map[string]int)
+	ranges := make(map[string][]int)
+	resultSets := make(map[int64]int)
+	definitions := make(map[int64]int)
+	for _, node := range nodes {
+		document, ok := documents[node.FilePath]
+		if !ok {
+			document = e.vertex(&Element{Label: "document", URI: fileURI(node.FilePath), LanguageID: languageID(node)})
+			documents[node.FilePath] = document
+			files = append(files, node.FilePath)
+		}
+
+		name := symbolName(node)
+		start, end := e.locate(node.FilePath, node.StartLine, name)
+		endLine := node.EndLine
+		if endLine < node.StartLine {
+			endLine = node.StartLine
+		}
+		definition := e.vertex(&Element{Label: "range", Start: &start, End: &end, Tag: &RangeTag{
+			Type:      "definition",
+			Text:      name,
+			Kind:      symbolKind(node),
+			FullRange: &Range{Start: Position{Line: start.Line}, End: Position{Line: endLine - 1}},
+		}})
+		ranges[node.FilePath] = append(ranges[node.FilePath], definition)
+
+		resultSet := e.vertex(&Element{Label: "resultSet"})
+		e.edge("next", definition, resultSet)
+		moniker := e.vertex(&Element{Label: "moniker", Scheme: MonikerScheme, Identifier: node.String(), Kind: "export"})
+		e.edge("moniker", resultSet, moniker)
+		hover := e.vertex(&Element{Label: "hoverResult", Result: &HoverResult{Contents: MarkupContent{Kind: "markdown", Value: hoverText(node)}}})
+		e.edge("textDocument/hover", resultSet, hover)
+		result := e.vertex(&Element{Label: "definitionResult"})
+		e.edge("textDocument/definition", resultSet, result)
+		e.items(result, []int{definition}, document, "")
+
+		resultSets[node.ID] = resultSet
+		definitions[node.ID] = definition
+	}
+
+	// References of each node by the document they are in
+	references := make(map[int64]map[int][]int)
+	for _, rel := range relationships {
+		from, ok := byID[rel.FromASTID]
+		if !ok {
+			continue
+		}
+		to, ok := byID[*rel.ToASTID]
+		if !ok {
+			continue
+		}
+		line := rel.LineNo
+		if line <= 0 {
+			line = from.StartLine
+		}
+		name := symbolName(to)
+		start, end := e.locate(from.FilePath, line, name)
+		reference := e.vertex(&Element{Label: "range", Start: &start, End: &end, Tag: &RangeTag{Type: "reference", Text: name, Kind: symbolKind(to)}})
+		e.edge("next", reference, resultSets[to.ID])
+		ranges[from.FilePath] = append(ranges[from.FilePath], reference)
+		if references[to.ID] == nil {
+			references[to.ID] = make(map[int][]int)
+		}
+		document := documents[from.FilePath]
+		references[to.ID][document] = append(references[to.ID][document], reference)
+	}
+	for _, node := range nodes {
+		byDocument, ok := references[node.ID]
+		if !ok {
+			continue
+		}
+		result := e.vertex(&Element{Label: "referenceResult"})
+		e.edge("textDocument/references", resultSets[node.ID], result)
+		e.items(result, []int{definitions[node.ID]}, documents[node.FilePath], "definitions")
+		var referencing []int
+		for document := range byDocument {
+			referencing = append(referencing, document)
+		}
+		sort.Ints(referencing)
+		for _, document := range referencing {
+			e.items(result, byDocument[document], document, "references")
+		}
+	}
+
+	for _, file := range files {
+		e.emit(&Element{Type: "edge", Label: "contains", OutV: documents[file], InVs: ranges[file]})
+	}
+	return e.err
+}
+
+// emitter writes the elements of an index with increasing ids, one JSON object per line
+type emitter struct {
+	encoder *json.Encoder
+	id      int
+	err     error
+	sources map[string][]string // Lines of the source files, read on first use
+}
+
+func (e *emitter) emit(element *Element) int {
+	e.id++
+	element.ID = e.id
+	if e.err == nil {
+		e.err = e.encoder.Encode(element)
+	}
+	return element.ID
+}
+
+func (e *emitter) vertex(element *Element) int {
+	element.Type = "vertex"
+	return e.emit(element)
+}
+
+func (e *emitter) edge(label string, outV, inV int) {
+	e.emit(&Element{Type: "edge", Label: label, OutV: outV, InV: inV})
+}
+
+// items adds ranges of a document to a definition or reference result
+func (e *emitter) items(result int, ranges []int, document int, property string) {
+	e.emit(&Element{Type: "edge", Label: "item", OutV: result, InVs: ranges, Document: document, Property: property})
+}
+
+// locate returns the range of a name on a line of a file, or of the line without its indentation
+// when the name is not found, in UTF-16 code units. Lines are one based.
+func (e *emitter) locate(file string, line int, name string) (Position, Position) {
+	if line < 1 {
+		line = 1
+	}
+	lines, ok := e.sources[file]
+	if !ok {
+		if data, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		e.sources[file] = lines
+	}
+	if line > len(lines) {
+		return Position{Line: line - 1}, Position{Line: line - 1}
+	}
+
+	text := strings.TrimRight(lines[line-1], "\r")
+	if index := indexWord(text, name); index >= 0 {
+		start := utf16Len(text[:index])
+		return Position{Line: line - 1, Character: start}, Position{Line: line - 1, Character: start + utf16Len(name)}
+	}
+	indent := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+	return Position{Line: line - 1, Character: utf16Len(text[:indent])}, Position{Line: line - 1, Character: utf16Len(text)}
+}
+
+// indexWord returns the index of the first occurrence of name in text that is not part of a
+// longer identifier, or -1
+func indexWord(text, name string) int {
+	if name == "" {
+		return -1
+	}
+	for offset := 0; offset < len(text); {
+		index := strings.Index(text[offset:], name)
+		if index < 0 {
+			return -1
+		}
+		start, end := offset+index, offset+index+len(name)
+		if (start == 0 || !isIdentifier(text[start-1])) && (end == len(text) || !isIdentifier(text[end])) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+func isIdentifier(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// hoverText returns the full name of a node as a code block, with its type and summary
+func hoverText(node *models.ASTNode) string {
+	text := node.String()
+	if node.FieldType != nil && *node.FieldType != "" {
+		text += " " + *node.FieldType
+	}
+	hover := fmt.Sprintf("```%s\n%s\n```", languageID(node), text)
+	if node.Summary != nil && *node.Summary != "" {
+		hover += "\n\n" + *node.Summary
+	}
+	return hover
+}
+
+// languageID returns the language of a node, or the extension of its file
+func languageID(node *models.ASTNode) string {
+	if node.Language != nil && *node.Language != "" {
+		return *node.Language
+	}
+	return strings.TrimPrefix(filepath.Ext(node.FilePath), ".")
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
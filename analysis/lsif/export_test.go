@@ -0,0 +1,142 @@
+package lsif_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/lsif"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Export", func() {
+	var (
+		astCache *cache.ASTCache
+		root     string
+	)
+
+	BeforeEach(func() {
+		cache.ResetGormDB()
+		var err error
+		astCache, err = cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).ToNot(HaveOccurred())
+
+		root = GinkgoT().TempDir()
+		file := filepath.Join(root, "user.go")
+		Expect(os.WriteFile(file, []byte("package service\n\nfunc Save() {}\n\nfunc Create() {\n\tSave()\n}\n"), 0644)).To(Succeed())
+
+		save, err := astCache.StoreASTNode(&models.ASTNode{
+			FilePath:    file,
+			PackageName: "service",
+			MethodName:  "Save",
+			NodeType:    models.NodeTypeMethod,
+			StartLine:   3,
+			EndLine:     3,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		create, err := astCache.StoreASTNode(&models.ASTNode{
+			FilePath:    file,
+			PackageName: "service",
+			MethodName:  "Create",
+			NodeType:    models.NodeTypeMethod,
+			StartLine:   5,
+			EndLine:     7,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(astCache.StoreASTRelationship(create, &save, 6, models.RelationshipCall, "Save()")).To(Succeed())
+
+		_, err = astCache.StoreASTNode(&models.ASTNode{
+			FilePath:    "/elsewhere/other.go",
+			PackageName: "other",
+			MethodName:  "Other",
+			NodeType:    models.NodeTypeMethod,
+			StartLine:   1,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	export := func() []lsif.Element {
+		var buf bytes.Buffer
+		Expect(lsif.Export(&buf, astCache, root)).To(Succeed())
+		var elements []lsif.Element
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var element lsif.Element
+			Expect(json.Unmarshal(scanner.Bytes(), &element)).To(Succeed())
+			elements = append(elements, element)
+		}
+		return elements
+	}
+
+	withLabel := func(elements []lsif.Element, label string) []lsif.Element {
+		var matching []lsif.Element
+		for _, element := range elements {
+			if element.Label == label {
+				matching = append(matching, element)
+			}
+		}
+		return matching
+	}
+
+	It("should write the documents under the root with their definitions", func() {
+		elements := export()
+		Expect(elements[0].Label).To(Equal("metaData"))
+		Expect(elements[0].Version).To(Equal(lsif.Version))
+
+		documents := withLabel(elements, "document")
+		Expect(documents).To(HaveLen(1))
+		Expect(documents[0].URI).To(Equal("file://" + filepath.ToSlash(filepath.Join(root, "user.go"))))
+		Expect(documents[0].LanguageID).To(Equal("go"))
+
+		monikers := withLabel(elements, "moniker")
+		Expect(monikers).To(HaveLen(2))
+		Expect(monikers[0].Scheme).To(Equal(lsif.MonikerScheme))
+		Expect(monikers[0].Identifier).To(Equal("service.Save"))
+	})
+
+	It("should locate definitions and references in the source", func() {
+		var definitions, references []lsif.Element
+		for _, element := range withLabel(export(), "range") {
+			if element.Tag.Type == "definition" {
+				definitions = append(definitions, element)
+			} else {
+				references = append(references, element)
+			}
+		}
+
+		Expect(definitions).To(HaveLen(2))
+		Expect(*definitions[0].Start).To(Equal(lsif.Position{Line: 2, Character: 5}))
+		Expect(*definitions[0].End).To(Equal(lsif.Position{Line: 2, Character: 9}))
+		Expect(definitions[0].Tag.Kind).To(Equal(lsif.SymbolKindFunction))
+		Expect(definitions[1].Tag.FullRange.End.Line).To(Equal(6))
+
+		Expect(references).To(HaveLen(1))
+		Expect(references[0].Tag.Text).To(Equal("Save"))
+		Expect(*references[0].Start).To(Equal(lsif.Position{Line: 5, Character: 1}))
+	})
+
+	It("should link references to the result set of their definition", func() {
+		elements := export()
+		Expect(withLabel(elements, "referenceResult")).To(HaveLen(1))
+
+		var items []lsif.Element
+		for _, element := range withLabel(elements, "item") {
+			if element.Property != "" {
+				items = append(items, element)
+			}
+		}
+		Expect(items).To(HaveLen(2))
+		Expect(items[0].Property).To(Equal("definitions"))
+		Expect(items[1].Property).To(Equal("references"))
+
+		contains := withLabel(elements, "contains")
+		Expect(contains).To(HaveLen(1))
+		Expect(contains[0].InVs).To(HaveLen(3))
+	})
+})
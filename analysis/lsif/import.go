@@ -0,0 +1,482 @@
+package lsif
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/types"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Extractor imports an LSIF index written by a compiler-grade indexer such as lsif-node or
+// lsif-clang, for languages arch-unit does not parse: the definitions of the index become nodes
+// and the references between them call or reference relationships, so rules apply to them.
+//
+// It is registered under the lsif language but not for the .lsif extension, so indexes in the
+// analyzed directories, such as those written by ast export, are only imported on request.
+type Extractor struct{}
+
+// NewExtractor creates an LSIF index importer
+func NewExtractor() *Extractor {
+	return &Extractor{}
+}
+
+// element is a vertex or edge of an index being imported, ids may be numbers or strings and
+// hover contents any of the shapes of the specification
+type element struct {
+	ID          elementID       `json:"id"`
+	Type        string          `json:"type"`
+	Label       string          `json:"label"`
+	ProjectRoot string          `json:"projectRoot"`
+	URI         string          `json:"uri"`
+	LanguageID  string          `json:"languageId"`
+	Start       *Position       `json:"start"`
+	End         *Position       `json:"end"`
+	Tag         *RangeTag       `json:"tag"`
+	Result      json.RawMessage `json:"result"`
+	Scheme      string          `json:"scheme"`
+	Identifier  string          `json:"identifier"`
+	OutV        elementID       `json:"outV"`
+	InV         elementID       `json:"inV"`
+	InVs        []elementID     `json:"inVs"`
+	Property    string          `json:"property"`
+}
+
+type elementID string
+
+func (id *elementID) UnmarshalJSON(data []byte) error {
+	*id = elementID(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// index is an LSIF index with its edges resolved
+type index struct {
+	root      string
+	documents map[elementID]*element // Documents by id
+	ranges    map[elementID]*element // Ranges by id
+	contains  map[elementID]elementID
+	next      map[elementID]elementID // Ranges and result sets to their result set
+	monikers  map[elementID]*element  // Result sets and ranges to their moniker
+	hovers    map[elementID]string    // Result sets and ranges to their hover text
+
+	definitionResults map[elementID]elementID // Result sets and ranges to their definition result
+	items             map[elementID][]*element
+	order             []elementID // Ids of documents and ranges in the order of the index
+}
+
+func init() {
+	analysis.DefaultExtractorRegistry.Register(Format, NewExtractor())
+}
+
+// ExtractFile converts an LSIF index into nodes and relationships, nodes have the paths of the
+// documents of the index rather than the path of the index
+func (e *Extractor) ExtractFile(cache cache.ReadOnlyCache, path string, content []byte) (*types.ASTResult, error) {
+	idx, err := parseIndex(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse LSIF index %s: %w", path, err)
+	}
+	if idx.root == "" {
+		idx.root = filepath.Dir(path)
+	}
+
+	result := types.NewASTResult(path, Format)
+	sources := make(map[string][]string)
+	definitions := make(map[elementID]*models.ASTNode) // Definition ranges to their nodes
+	byDocument := make(map[elementID][]*models.ASTNode)
+	for _, id := range idx.order {
+		r, ok := idx.ranges[id]
+		if !ok || !idx.isDefinition(id) {
+			continue
+		}
+		document := idx.documents[idx.contains[id]]
+		if document == nil {
+			continue
+		}
+		node := idx.node(r, document, sources)
+		if node == nil {
+			continue
+		}
+		definitions[id] = node
+		byDocument[document.ID] = append(byDocument[document.ID], node)
+		result.Nodes = append(result.Nodes, node)
+	}
+	for _, nodes := range byDocument {
+		nestMembers(nodes)
+	}
+
+	// Definitions of result sets, to resolve references
+	targets := make(map[elementID]*models.ASTNode)
+	for id, node := range definitions {
+		targets[idx.resultSet(id)] = node
+	}
+	for _, id := range idx.order {
+		r, ok := idx.ranges[id]
+		if !ok || definitions[id] != nil {
+			continue
+		}
+		to := targets[idx.resultSet(id)]
+		if to == nil {
+			continue
+		}
+		from := enclosing(byDocument[idx.contains[id]], r.Start.Line+1)
+		if from == nil || from == to {
+			continue
+		}
+		relType := models.RelationshipTypeReference
+		if strings.HasPrefix(string(to.NodeType), string(models.NodeTypeMethod)) {
+			relType = models.RelationshipTypeCall
+		}
+		result.Relationships = append(result.Relationships, &models.ASTRelationship{
+			FromAST:          from,
+			ToAST:            to,
+			LineNo:           r.Start.Line + 1,
+			RelationshipType: relType,
+			Text:             to.String(),
+		})
+	}
+	return result, nil
+}
+
+// Import extracts an LSIF index and replaces the nodes of its documents in the cache
+func Import(astCache *cache.ASTCache, path string) (*types.ASTResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result, err := NewExtractor().ExtractFile(astCache, path, content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nodes are stored under the paths of the documents while the cache only cleans up the nodes
+	// and relationships of the stored path, so the documents of a previous import are removed first
+	deleted := make(map[string]bool)
+	for _, node := range result.Nodes {
+		if !deleted[node.FilePath] {
+			if err := astCache.DeleteASTForFile(node.FilePath); err != nil {
+				return nil, err
+			}
+			deleted[node.FilePath] = true
+		}
+	}
+	if err := astCache.StoreFileResults(path, result); err != nil {
+		return nil, fmt.Errorf("failed to store LSIF index %s: %w", path, err)
+	}
+	return result, nil
+}
+
+func parseIndex(content []byte) (*index, error) {
+	idx := &index{
+		documents:         make(map[elementID]*element),
+		ranges:            make(map[elementID]*element),
+		contains:          make(map[elementID]elementID),
+		next:              make(map[elementID]elementID),
+		monikers:          make(map[elementID]*element),
+		hovers:            make(map[elementID]string),
+		definitionResults: make(map[elementID]elementID),
+		items:             make(map[elementID][]*element),
+	}
+
+	monikers := make(map[elementID]*element)
+	hovers := make(map[elementID]string)
+	var edges []*element
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		e := &element{}
+		if err := json.Unmarshal(text, e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch {
+		case e.Type == "edge":
+			edges = append(edges, e)
+		case e.Label == "metaData":
+			idx.root = uriPath(e.ProjectRoot)
+		case e.Label == "document":
+			idx.documents[e.ID] = e
+			idx.order = append(idx.order, e.ID)
+		case e.Label == "range":
+			if e.Start == nil || e.End == nil {
+				return nil, fmt.Errorf("line %d: range %s has no start or end", line, e.ID)
+			}
+			idx.ranges[e.ID] = e
+			idx.order = append(idx.order, e.ID)
+		case e.Label == "moniker":
+			monikers[e.ID] = e
+		case e.Label == "hoverResult":
+			hovers[e.ID] = hoverContents(e.Result)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, e := range edges {
+		switch e.Label {
+		case "contains":
+			if _, ok := idx.documents[e.OutV]; ok {
+				for _, in := range e.InVs {
+					idx.contains[in] = e.OutV
+				}
+			}
+		case "next":
+			idx.next[e.OutV] = e.InV
+		case "moniker":
+			if moniker, ok := monikers[e.InV]; ok {
+				idx.monikers[e.OutV] = moniker
+			}
+		case "textDocument/hover":
+			idx.hovers[e.OutV] = hovers[e.InV]
+		case "textDocument/definition":
+			idx.definitionResults[e.OutV] = e.InV
+		case "item":
+			idx.items[e.OutV] = append(idx.items[e.OutV], e)
+		}
+	}
+	return idx, nil
+}
+
+// resultSet returns the last result set of the chain of next edges of a range
+func (idx *index) resultSet(id elementID) elementID {
+	for seen := 0; seen < 100; seen++ {
+		next, ok := idx.next[id]
+		if !ok {
+			break
+		}
+		id = next
+	}
+	return id
+}
+
+// lookup returns the value of a range or of the first result set of its chain that has one
+func lookup[T any](idx *index, values map[elementID]T, id elementID) (T, bool) {
+	for seen := 0; seen < 100; seen++ {
+		if value, ok := values[id]; ok {
+			return value, true
+		}
+		next, ok := idx.next[id]
+		if !ok {
+			break
+		}
+		id = next
+	}
+	var zero T
+	return zero, false
+}
+
+// isDefinition returns true if a range is tagged as a definition, or is an item of the
+// definition result of its result set
+func (idx *index) isDefinition(id elementID) bool {
+	if tag := idx.ranges[id].Tag; tag != nil {
+		return tag.Type == "definition"
+	}
+	result, ok := lookup(idx, idx.definitionResults, id)
+	if !ok {
+		return false
+	}
+	for _, item := range idx.items[result] {
+		for _, in := range item.InVs {
+			if in == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// node returns the node defined by a range, or nil for modules and namespaces
+func (idx *index) node(r, document *element, sources map[string][]string) *models.ASTNode {
+	path := uriPath(document.URI)
+	name := ""
+	kind := 0
+	endLine := r.End.Line
+	if r.Tag != nil {
+		name, kind = r.Tag.Text, r.Tag.Kind
+		if r.Tag.FullRange != nil {
+			endLine = r.Tag.FullRange.End.Line
+		}
+	}
+	if name == "" {
+		name = sourceText(sources, path, r)
+	}
+	if name == "" {
+		if moniker, ok := lookup(idx, idx.monikers, r.ID); ok {
+			name = moniker.Identifier[strings.LastIndexAny(moniker.Identifier, ".:/#")+1:]
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	node := &models.ASTNode{
+		FilePath:    path,
+		PackageName: idx.packageName(path),
+		StartLine:   r.Start.Line + 1,
+		EndLine:     endLine + 1,
+		Metatdata:   map[string]string{},
+	}
+	switch kind {
+	case SymbolKindModule, SymbolKindNamespace, SymbolKindPackage:
+		return nil
+	case SymbolKindClass, SymbolKindStruct, SymbolKindEnum, SymbolKindInterface:
+		node.NodeType = models.NodeTypeType
+		node.TypeName = name
+		if kind == SymbolKindInterface {
+			node.Metatdata["kind"] = models.NamingKindInterface
+		}
+	case SymbolKindField, SymbolKindProperty:
+		node.NodeType = models.NodeTypeField
+		node.FieldName = name
+	case SymbolKindVariable, SymbolKindConstant:
+		node.NodeType = models.NodeTypeVariable
+		node.FieldName = name
+	default:
+		// Functions, methods, and definitions of indexers that do not tag ranges
+		node.NodeType = models.NodeTypeMethod
+		node.MethodName = name
+	}
+	if document.LanguageID != "" {
+		language := document.LanguageID
+		node.Language = &language
+	}
+	if moniker, ok := lookup(idx, idx.monikers, r.ID); ok {
+		node.Metatdata["moniker"] = moniker.Scheme + ":" + moniker.Identifier
+	}
+	if hover, ok := lookup(idx, idx.hovers, r.ID); ok && hover != "" {
+		node.Summary = &hover
+	}
+	return node
+}
+
+// packageName returns the directory of a document relative to the project root with dots, or
+// the name of the root for documents at the root
+func (idx *index) packageName(path string) string {
+	dir := filepath.Dir(path)
+	rel, err := filepath.Rel(idx.root, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return filepath.Base(dir)
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", ".")
+}
+
+// nestMembers sets the type of the methods and fields defined within the full range of a type
+func nestMembers(nodes []*models.ASTNode) {
+	for _, node := range nodes {
+		if node.NodeType == models.NodeTypeType {
+			continue
+		}
+		var owner *models.ASTNode
+		for _, t := range nodes {
+			if t.NodeType == models.NodeTypeType && t.StartLine <= node.StartLine && node.EndLine <= t.EndLine &&
+				(owner == nil || t.EndLine-t.StartLine < owner.EndLine-owner.StartLine) {
+				owner = t
+			}
+		}
+		if owner != nil {
+			node.TypeName = owner.TypeName
+		}
+	}
+}
+
+// enclosing returns the innermost method, or else type, defined around a line
+func enclosing(nodes []*models.ASTNode, line int) *models.ASTNode {
+	candidates := make([]*models.ASTNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node.StartLine <= line && line <= node.EndLine &&
+			(node.NodeType == models.NodeTypeMethod || node.NodeType == models.NodeTypeType) {
+			candidates = append(candidates, node)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if (candidates[i].NodeType == models.NodeTypeMethod) != (candidates[j].NodeType == models.NodeTypeMethod) {
+			return candidates[i].NodeType == models.NodeTypeMethod
+		}
+		return candidates[i].EndLine-candidates[i].StartLine < candidates[j].EndLine-candidates[j].StartLine
+	})
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[0]
+}
+
+// sourceText returns the text of a single line range from the source of a document
+func sourceText(sources map[string][]string, path string, r *element) string {
+	if r.Start.Line != r.End.Line {
+		return ""
+	}
+	lines, ok := sources[path]
+	if !ok {
+		if data, err := os.ReadFile(path); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		sources[path] = lines
+	}
+	if r.Start.Line >= len(lines) {
+		return ""
+	}
+	text := utf16.Encode([]rune(lines[r.Start.Line]))
+	if r.Start.Character > r.End.Character || r.End.Character > len(text) {
+		return ""
+	}
+	return string(utf16.Decode(text[r.Start.Character:r.End.Character]))
+}
+
+// hoverContents returns the text of a hover result, whose contents are markup content, a marked
+// string, or a list of marked strings
+func hoverContents(result json.RawMessage) string {
+	var hover struct {
+		Contents json.RawMessage `json:"contents"`
+	}
+	if len(result) == 0 || json.Unmarshal(result, &hover) != nil {
+		return ""
+	}
+	var parts []json.RawMessage
+	if json.Unmarshal(hover.Contents, &parts) != nil {
+		parts = []json.RawMessage{hover.Contents}
+	}
+	var texts []string
+	for _, part := range parts {
+		var text string
+		if json.Unmarshal(part, &text) == nil {
+			texts = append(texts, text)
+			continue
+		}
+		var marked struct {
+			Language string `json:"language"`
+			Value    string `json:"value"`
+		}
+		if json.Unmarshal(part, &marked) == nil && marked.Value != "" {
+			if marked.Language != "" {
+				texts = append(texts, fmt.Sprintf("```%s\n%s\n```", marked.Language, marked.Value))
+			} else {
+				texts = append(texts, marked.Value)
+			}
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// uriPath returns the path of a file URI
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
@@ -0,0 +1,98 @@
+package lsif_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis/lsif"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Import", func() {
+	It("should convert tagged definitions and references into nodes and relationships", func() {
+		index := strings.Join([]string{
+			`{"id":"1","type":"vertex","label":"metaData","version":"0.5.0","projectRoot":"file:///proj"}`,
+			`{"id":"2","type":"vertex","label":"document","uri":"file:///proj/src/shapes.ts","languageId":"typescript"}`,
+			`{"id":"3","type":"vertex","label":"range","start":{"line":0,"character":6},"end":{"line":0,"character":11},"tag":{"type":"definition","text":"Shape","kind":5,"fullRange":{"start":{"line":0,"character":0},"end":{"line":4,"character":1}}}}`,
+			`{"id":"4","type":"vertex","label":"range","start":{"line":1,"character":2},"end":{"line":1,"character":6},"tag":{"type":"definition","text":"area","kind":6,"fullRange":{"start":{"line":1,"character":2},"end":{"line":3,"character":3}}}}`,
+			`{"id":"5","type":"vertex","label":"range","start":{"line":6,"character":9},"end":{"line":6,"character":13},"tag":{"type":"definition","text":"main","kind":12,"fullRange":{"start":{"line":6,"character":0},"end":{"line":8,"character":1}}}}`,
+			`{"id":"6","type":"vertex","label":"range","start":{"line":7,"character":12},"end":{"line":7,"character":17},"tag":{"type":"reference","text":"Shape","kind":5}}`,
+			`{"id":"7","type":"vertex","label":"resultSet"}`,
+			`{"id":"8","type":"vertex","label":"resultSet"}`,
+			`{"id":"9","type":"vertex","label":"hoverResult","result":{"contents":[{"language":"typescript","value":"area(): number"},"Area of the shape"]}}`,
+			`{"id":"10","type":"vertex","label":"moniker","scheme":"tsc","identifier":"shapes:Shape.area","kind":"export"}`,
+			`{"id":"11","type":"edge","label":"next","outV":"3","inV":"7"}`,
+			`{"id":"12","type":"edge","label":"next","outV":"6","inV":"7"}`,
+			`{"id":"13","type":"edge","label":"next","outV":"4","inV":"8"}`,
+			`{"id":"14","type":"edge","label":"textDocument/hover","outV":"8","inV":"9"}`,
+			`{"id":"15","type":"edge","label":"moniker","outV":"8","inV":"10"}`,
+			`{"id":"16","type":"edge","label":"contains","outV":"2","inVs":["3","4","5","6"]}`,
+		}, "\n")
+
+		result, err := lsif.NewExtractor().ExtractFile(nil, "/proj/dump.lsif", []byte(index))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Nodes).To(HaveLen(3))
+
+		shape, area, main := result.Nodes[0], result.Nodes[1], result.Nodes[2]
+		Expect(shape.FilePath).To(Equal("/proj/src/shapes.ts"))
+		Expect(shape.NodeType).To(Equal(models.NodeTypeType))
+		Expect(shape.PackageName).To(Equal("src"))
+		Expect(*shape.Language).To(Equal("typescript"))
+		Expect(shape.StartLine).To(Equal(1))
+		Expect(shape.EndLine).To(Equal(5))
+
+		Expect(area.String()).To(Equal("src.Shape.area"))
+		Expect(area.NodeType).To(Equal(models.NodeTypeMethod))
+		Expect(*area.Summary).To(Equal("```typescript\narea(): number\n```\n\nArea of the shape"))
+		Expect(area.Metatdata).To(HaveKeyWithValue("moniker", "tsc:shapes:Shape.area"))
+
+		Expect(main.String()).To(Equal("src.main"))
+		Expect(result.Relationships).To(HaveLen(1))
+		Expect(result.Relationships[0].FromAST).To(Equal(main))
+		Expect(result.Relationships[0].ToAST).To(Equal(shape))
+		Expect(result.Relationships[0].LineNo).To(Equal(8))
+		Expect(result.Relationships[0].RelationshipType).To(Equal(models.RelationshipTypeReference))
+	})
+
+	It("should import the indexes written by Export", func() {
+		cache.ResetGormDB()
+		astCache, err := cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).ToNot(HaveOccurred())
+
+		root := GinkgoT().TempDir()
+		file := filepath.Join(root, "user.go")
+		Expect(os.WriteFile(file, []byte("package service\n\nfunc Save() {}\n\nfunc Create() {\n\tSave()\n}\n"), 0644)).To(Succeed())
+		save, err := astCache.StoreASTNode(&models.ASTNode{FilePath: file, PackageName: "service", MethodName: "Save", NodeType: models.NodeTypeMethod, StartLine: 3, EndLine: 3})
+		Expect(err).ToNot(HaveOccurred())
+		create, err := astCache.StoreASTNode(&models.ASTNode{FilePath: file, PackageName: "service", MethodName: "Create", NodeType: models.NodeTypeMethod, StartLine: 5, EndLine: 7})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(astCache.StoreASTRelationship(create, &save, 6, models.RelationshipCall, "Save()")).To(Succeed())
+
+		var buf bytes.Buffer
+		Expect(lsif.Export(&buf, astCache, root)).To(Succeed())
+		dump := filepath.Join(GinkgoT().TempDir(), "dump.lsif")
+		Expect(os.WriteFile(dump, buf.Bytes(), 0644)).To(Succeed())
+
+		// Importing twice replaces the nodes and relationships of the first import
+		for i := 0; i < 2; i++ {
+			result, err := lsif.Import(astCache, dump)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Nodes).To(HaveLen(2))
+		}
+
+		nodes, err := astCache.GetASTNodesByFile(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodes).To(HaveLen(2))
+		var relationships []*models.ASTRelationship
+		Expect(astCache.GetReadQuery().Find(&relationships).Error).To(Succeed())
+		Expect(relationships).To(HaveLen(1))
+		Expect(relationships[0].LineNo).To(Equal(6))
+		Expect(relationships[0].RelationshipType).To(Equal(models.RelationshipTypeCall))
+	})
+})
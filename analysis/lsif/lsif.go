@@ -0,0 +1,145 @@
+// Package lsif exports the AST cache as an LSIF index, the Language Server Index Format of
+// editors and code intelligence platforms such as Sourcegraph: a stream of JSON vertices and
+// edges describing the definitions, hovers and references of the documents of a project.
+// See https://microsoft.github.io/language-server-protocol/specifications/lsif/0.6.0/specification/
+package lsif
+
+import (
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Version is the version of the LSIF specification of exported indexes
+const Version = "0.6.0"
+
+// Format is the name of the LSIF export format
+const Format = "lsif"
+
+// MonikerScheme is the scheme of the monikers naming the nodes of exported indexes
+const MonikerScheme = "arch-unit"
+
+// LSP symbol kinds of the definitions of nodes
+const (
+	SymbolKindModule    = 2
+	SymbolKindNamespace = 3
+	SymbolKindPackage   = 4
+	SymbolKindClass     = 5
+	SymbolKindMethod    = 6
+	SymbolKindProperty  = 7
+	SymbolKindField     = 8
+	SymbolKindEnum      = 10
+	SymbolKindInterface = 11
+	SymbolKindFunction  = 12
+	SymbolKindVariable  = 13
+	SymbolKindConstant  = 14
+	SymbolKindStruct    = 23
+)
+
+// Element is a vertex or edge of an LSIF index, each label uses a subset of the fields
+type Element struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"` // vertex or edge
+	Label string `json:"label"`
+
+	// metaData and project vertices
+	Version          string    `json:"version,omitempty"`
+	ProjectRoot      string    `json:"projectRoot,omitempty"`
+	PositionEncoding string    `json:"positionEncoding,omitempty"`
+	ToolInfo         *ToolInfo `json:"toolInfo,omitempty"`
+
+	// document vertices
+	URI        string `json:"uri,omitempty"`
+	LanguageID string `json:"languageId,omitempty"`
+
+	// range vertices
+	Start *Position `json:"start,omitempty"`
+	End   *Position `json:"end,omitempty"`
+	Tag   *RangeTag `json:"tag,omitempty"`
+
+	// hoverResult vertices
+	Result *HoverResult `json:"result,omitempty"`
+
+	// moniker vertices, Kind is also the language of project vertices
+	Scheme     string `json:"scheme,omitempty"`
+	Identifier string `json:"identifier,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+
+	// edges, from OutV to InV or to each of InVs
+	OutV     int    `json:"outV,omitempty"`
+	InV      int    `json:"inV,omitempty"`
+	InVs     []int  `json:"inVs,omitempty"`
+	Document int    `json:"document,omitempty"`
+	Property string `json:"property,omitempty"` // definitions or references of item edges
+}
+
+// ToolInfo names the tool that wrote an index
+type ToolInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Position is a zero based line and character of a document
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// RangeTag describes the symbol a range defines or references
+type RangeTag struct {
+	Type      string `json:"type"` // definition, declaration or reference
+	Text      string `json:"text"`
+	Kind      int    `json:"kind,omitempty"`
+	FullRange *Range `json:"fullRange,omitempty"`
+}
+
+// Range is the start and end of a symbol, e.g. of the body of a function
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// HoverResult is the hover text of a symbol
+type HoverResult struct {
+	Contents MarkupContent `json:"contents"`
+}
+
+// MarkupContent is markdown or plaintext
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// symbolKind returns the LSP symbol kind of a node, sub-types such as method_http_get are
+// kinds of their base type
+func symbolKind(node *models.ASTNode) int {
+	nodeType := string(node.NodeType)
+	switch {
+	case models.IsAbstract(node):
+		return SymbolKindInterface
+	case node.NodeType == models.NodeTypePackage:
+		return SymbolKindPackage
+	case node.NodeType == models.NodeTypeVariable:
+		return SymbolKindVariable
+	case strings.HasPrefix(nodeType, string(models.NodeTypeField)):
+		return SymbolKindField
+	case strings.HasPrefix(nodeType, string(models.NodeTypeMethod)) && node.TypeName == "":
+		return SymbolKindFunction
+	case strings.HasPrefix(nodeType, string(models.NodeTypeMethod)):
+		return SymbolKindMethod
+	}
+	return SymbolKindClass
+}
+
+// symbolName returns the name a node is defined with in its source
+func symbolName(node *models.ASTNode) string {
+	switch {
+	case node.FieldName != "":
+		return node.FieldName
+	case node.MethodName != "":
+		return node.MethodName
+	case node.TypeName != "":
+		return node.TypeName
+	}
+	return node.PackageName
+}
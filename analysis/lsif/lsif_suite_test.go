@@ -0,0 +1,13 @@
+package lsif_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLSIF(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LSIF Suite")
+}
@@ -0,0 +1,161 @@
+package httpcall
+
+import (
+	"strings"
+)
+
+// Unknown replaces the parts of a URL that are only known at runtime, e.g. /users/{} for
+// "/users/" + id
+const Unknown = "{}"
+
+// Call is an outbound HTTP request made by application code
+type Call struct {
+	Method string // GET, POST, ..., empty when not statically known
+	URL    string // absolute URL or path as written, with runtime parts replaced by Unknown
+}
+
+// methods are the HTTP methods recognized in method constants
+var methods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// verbs maps the lower-cased names of client functions to the method they send, e.g. http.Get,
+// requests.post, axios.delete or resty's R().Put
+var verbs = map[string]string{
+	"get": "GET", "head": "HEAD", "post": "POST", "postform": "POST", "put": "PUT", "patch": "PATCH",
+	"delete": "DELETE", "options": "OPTIONS",
+}
+
+// Verb returns the HTTP method sent by a client function or method named name
+func Verb(name string) (string, bool) {
+	method, ok := verbs[strings.ToLower(name)]
+	return method, ok
+}
+
+// Method returns the HTTP method named by a constant, e.g. "get", "GET" or http.MethodGet
+func Method(name string) (string, bool) {
+	method := strings.ToUpper(strings.TrimPrefix(name, "Method"))
+	return method, methods[method]
+}
+
+// Parse returns the call recorded by String
+func Parse(text string) Call {
+	if method, url, ok := strings.Cut(text, " "); ok && methods[method] {
+		return Call{Method: method, URL: url}
+	}
+	return Call{URL: text}
+}
+
+// String returns the method and URL of the call, e.g. "GET http://billing/invoices/{}"
+func (c Call) String() string {
+	if c.Method == "" {
+		return c.URL
+	}
+	return c.Method + " " + c.URL
+}
+
+// Route returns the method and path of the call, e.g. "GET /invoices/{}"
+func (c Call) Route() string {
+	if c.Method == "" {
+		return c.Path()
+	}
+	return c.Method + " " + c.Path()
+}
+
+// Host returns the host name of an absolute URL without its port, empty when the URL is a path or
+// the host is only known at runtime
+func (c Call) Host() string {
+	_, rest, ok := strings.Cut(c.URL, "://")
+	if !ok {
+		return ""
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	if at := strings.LastIndexByte(host, '@'); at >= 0 {
+		host = host[at+1:]
+	}
+	host, _, _ = strings.Cut(host, ":")
+	if strings.Contains(host, Unknown) {
+		return ""
+	}
+	return strings.ToLower(host)
+}
+
+// Path returns the path of the URL without the query string. A base URL that is only known at
+// runtime, as in baseURL + "/users", is dropped.
+func (c Call) Path() string {
+	url := c.URL
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	if _, rest, ok := strings.Cut(url, "://"); ok {
+		url = rest
+	} else if !strings.HasPrefix(url, Unknown) {
+		// A path, relative paths are resolved against the client's base URL
+		return "/" + strings.TrimPrefix(url, "/")
+	}
+	if slash := strings.IndexByte(url, '/'); slash >= 0 {
+		return url[slash:]
+	}
+	return "/"
+}
+
+// Template returns the URL of a printf format, with each verb replaced by Unknown, e.g.
+// /users/{}/orders for "/users/%d/orders"
+func Template(format string) string {
+	var url strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			url.WriteByte(format[i])
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			url.WriteByte('%')
+			i++
+			continue
+		}
+		// Skip flags, width and precision up to the verb
+		for i+1 < len(format) && strings.IndexByte("+-# 0123456789.*[]", format[i+1]) >= 0 {
+			i++
+		}
+		i++
+		url.WriteString(Unknown)
+	}
+	return url.String()
+}
+
+// Match reports whether a path requested by a call is served by an OpenAPI route such as
+// /users/{id}, returning the number of literal route segments matched so the most specific of
+// several routes can be chosen. The path may start with a base path the route does not include,
+// such as /api/v1.
+func Match(path, route string) (int, bool) {
+	pathSegments := segments(path)
+	routeSegments := segments(route)
+	if len(pathSegments) < len(routeSegments) || (len(routeSegments) == 0 && len(pathSegments) > 0) {
+		return 0, false
+	}
+
+	pathSegments = pathSegments[len(pathSegments)-len(routeSegments):]
+	literals := 0
+	for i, segment := range routeSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			continue
+		}
+		if segment != pathSegments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	// A route made of parameters alone only matches paths without a base path
+	if literals == 0 && len(segments(path)) > len(routeSegments) {
+		return 0, false
+	}
+	return literals, true
+}
+
+func segments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
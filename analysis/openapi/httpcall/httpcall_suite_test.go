@@ -0,0 +1,13 @@
+package httpcall
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHTTPCall(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTP Call Suite")
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/flanksource/arch-unit/analysis"
 	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/offline"
 	"github.com/flanksource/arch-unit/models"
 	"gopkg.in/yaml.v3"
 )
@@ -48,6 +50,10 @@ func (e *OpenAPIExtractor) ExtractFile(cache cache.ReadOnlyCache, filepath strin
 
 // ExtractFromURL extracts AST from an OpenAPI specification URL
 func (e *OpenAPIExtractor) ExtractFromURL(url string) (*types.ASTResult, error) {
+	if err := offline.Check("fetching OpenAPI spec from " + url); err != nil {
+		return nil, err
+	}
+
 	// Create virtual path for this URL
 	virtualPath := e.virtualPathMgr.CreateVirtualPath(analysis.AnalysisSource{
 		Type: "openapi_url",
@@ -308,6 +314,12 @@ func (e *OpenAPIExtractor) convertOperationToASTNode(path, method string, operat
 		}
 	}
 
+	// The route HTTP calls found in code are matched against
+	metadata := map[string]string{"method": strings.ToUpper(method), "path": path}
+	if errors := errorResponses(operation); errors != "" {
+		metadata["errors"] = errors
+	}
+
 	return &models.ASTNode{
 		FilePath:       filePath,
 		PackageName:    namespace,
@@ -318,7 +330,29 @@ func (e *OpenAPIExtractor) convertOperationToASTNode(path, method string, operat
 		ParameterCount: len(parameters),
 		LastModified:   time.Now(),
 		Summary:        models.StringPtr(fmt.Sprintf("%s endpoint with %d parameters", method, len(parameters))),
+		Metatdata:      metadata,
+	}
+}
+
+// errorResponses returns the 4xx, 5xx and default responses of an operation with the schema they
+// reference, e.g. 404=Error,default=Error, with an empty schema for inline or missing content
+func errorResponses(operation *Operation) string {
+	var errors []string
+	for status, response := range operation.Responses {
+		if status != "default" && !strings.HasPrefix(status, "4") && !strings.HasPrefix(status, "5") {
+			continue
+		}
+		schema := ""
+		for _, mediaType := range response.Content {
+			if mediaType.Schema != nil && mediaType.Schema.Ref != "" {
+				schema = mediaType.Schema.Ref[strings.LastIndex(mediaType.Schema.Ref, "/")+1:]
+				break
+			}
+		}
+		errors = append(errors, status+"="+schema)
 	}
+	sort.Strings(errors)
+	return strings.Join(errors, ",")
 }
 
 // getParameterType returns the type of an OpenAPI parameter
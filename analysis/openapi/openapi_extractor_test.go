@@ -193,6 +193,7 @@ var _ = Describe("OpenAPI Extractor", func() {
 			Expect(showPet.Parameters).To(HaveLen(1))
 			Expect(showPet.Parameters[0].Name).To(Equal("petId"))
 			Expect(showPet.Parameters[0].Type).To(Equal("string"))
+			Expect(showPet.Metatdata).To(Equal(map[string]string{"method": "GET", "path": "/pets/{petId}"}))
 
 			// Should have Pet schema fields
 			Expect(fieldNodes).To(HaveLen(3))
@@ -267,6 +268,34 @@ components:
 			}
 			Expect(hasHealthSchema).To(BeTrue())
 		})
+
+		It("should record the schemas of error responses", func() {
+			openAPIYAML := `openapi: 3.0.0
+info:
+  title: Orders API
+  version: 1.0.0
+paths:
+  /orders/{id}:
+    get:
+      operationId: getOrder
+      responses:
+        '200':
+          description: The order
+        '404':
+          description: Not found
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+        default:
+          description: Unexpected error
+`
+
+			result, err := extractor.ExtractFile(astCache, "api/orders.yaml", []byte(openAPIYAML))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Nodes).To(HaveLen(1))
+			Expect(result.Nodes[0].Metatdata).To(HaveKeyWithValue("errors", "404=Error,default="))
+		})
 	})
 
 	Context("when handling invalid inputs", func() {
@@ -242,6 +242,136 @@ func (cpb *CustomPromptBuilder) Build() string {
 	return prompt.String()
 }
 
+// SummaryPrompt asks for a summary of a node of at most a number of words
+const SummaryPrompt = `Summarize what this %s does in at most %d words.
+
+Name: %s
+File: %s
+
+%s
+
+Describe its purpose rather than restating its name or signature. Respond with the summary only,
+on a single line, without quotes or markdown.`
+
+// summarySourceLines limits the source sent for large nodes
+const summarySourceLines = 200
+
+// BuildSummaryPrompt builds a prompt to summarize a node from its source in at most limit words
+func BuildSummaryPrompt(node *models.ASTNode, source []string, limit int) string {
+	if len(source) > summarySourceLines {
+		source = append(source[:summarySourceLines:summarySourceLines], "...")
+	}
+	code := "Source:\n```\n" + strings.Join(source, "\n") + "\n```"
+	if len(source) == 0 {
+		code = "The source is not available."
+	}
+	kind := string(node.NodeType)
+	if i := strings.IndexByte(kind, '_'); i > 0 {
+		kind = kind[:i]
+	}
+	return fmt.Sprintf(SummaryPrompt, kind, limit, node.String(), node.FilePath, code)
+}
+
+// AskRulePrompt asks for an architecture rule implementing a request in natural language
+const AskRulePrompt = `You write architecture rules for arch-unit. Propose one rule implementing this request:
+
+%s
+
+Use one of these two forms.
+
+1. An AQL rule, for dependencies between the packages, types and methods of the codebase:
+   RULE "Name" {
+     FORBID(from -> to)      # direct calls, embedding, SQL queries, HTTP calls or env vars
+     FORBID(from ->> to)     # direct or indirect dependencies
+     REQUIRE(from -> to)     # every node matching from must depend on to
+     ALLOW(from -> to)       # exception to a FORBID of the same rule
+     CYCLE(pattern)          # dependency cycles between matching packages
+     LIMIT(pattern.metric > value)  # metrics: cyclomatic, lines, params, returns
+   }
+   Patterns are package:type:method with * wildcards, e.g. controllers:*, *:*Service:*,
+   *Repository*, sql/* for SQL tables and env:* for environment variables.
+
+2. Import rules, for third-party packages, which AQL rules cannot see: a doublestar glob of the
+   files they apply to and rules such as "!gorm.io/gorm" (deny), "gorm.io/gorm" (allow),
+   "+internal/" (override a parent rule) or "fmt:!Println" (deny one function).
+
+Packages of the codebase:
+%s
+
+Directories:
+%s
+
+Third-party packages used:
+%s
+
+Respond with a single JSON object only, either
+{"aql": "RULE \"...\" {\n  FORBID(...)\n}", "explanation": "..."}
+or
+{"path": "**/controllers/**", "imports": ["!gorm.io/gorm"], "explanation": "..."}`
+
+// maxAskListItems limits the packages, directories and libraries listed in ask prompts
+const maxAskListItems = 150
+
+// BuildAskRulePrompt builds a prompt proposing a rule for a request, listing the packages,
+// directories and third-party packages of the codebase so the rule uses their actual names
+func BuildAskRulePrompt(request string, packages, directories, libraries []string) string {
+	list := func(items []string) string {
+		if len(items) == 0 {
+			return "(none)"
+		}
+		if len(items) > maxAskListItems {
+			items = append(items[:maxAskListItems:maxAskListItems], "...")
+		}
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf(AskRulePrompt, request, list(packages), list(directories), list(libraries))
+}
+
+// ExplainViolationPrompt asks for a short remediation of an architecture or lint violation
+const ExplainViolationPrompt = `This code violates a rule of the codebase. Suggest how to fix it in at most %d words.
+
+Violation: %s
+Rule: %s
+Reported by: %s
+File: %s
+
+%s
+
+Name the concrete change, e.g. which dependency to inject or move, or which layer to call
+instead, rather than restating the rule. Respond with the suggestion only, on a single line,
+without quotes or markdown.`
+
+// BuildExplainViolationPrompt builds a prompt suggesting a fix of a violation from the source of
+// the node it was reported in, in at most limit words
+func BuildExplainViolationPrompt(v models.Violation, source []string, limit int) string {
+	if len(source) > summarySourceLines {
+		source = append(source[:summarySourceLines:summarySourceLines], "...")
+	}
+	code := "Source:\n```\n" + strings.Join(source, "\n") + "\n```"
+	if len(source) == 0 {
+		code = "The source is not available."
+	}
+	message := ""
+	if v.Message != nil {
+		message = *v.Message
+	}
+	if v.Code != nil && *v.Code != "" {
+		message = strings.TrimSpace(message + " at: " + strings.TrimSpace(*v.Code))
+	}
+	rule := "(none)"
+	if v.Rule != nil {
+		rule = v.Rule.String()
+		if v.Rule.OriginalLine != "" {
+			rule = v.Rule.OriginalLine
+		}
+	}
+	tool := v.Source
+	if tool == "" {
+		tool = "arch-unit"
+	}
+	return fmt.Sprintf(ExplainViolationPrompt, limit, message, rule, tool, v.File, code)
+}
+
 // PromptVariants contains different versions of prompts for A/B testing or different contexts
 var PromptVariants = map[string][]string{
 	"comment-quality-short": {
@@ -0,0 +1,249 @@
+package python
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// notebookCell is a cell of a Jupyter notebook
+type notebookCell struct {
+	Type string
+	// Lines are the source lines of the cell, including their line terminator
+	Lines []string
+	// LineNos are the lines of the notebook file each source line is stored on
+	LineNos []int
+}
+
+// notebookScript is the Python script made of the code cells of a notebook
+type notebookScript struct {
+	Source string
+	// lineNos maps each line of Source to its line in the notebook file
+	lineNos []int
+}
+
+// isNotebook returns true if filePath is a Jupyter notebook
+func isNotebook(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filePath), ".ipynb")
+}
+
+// parseNotebookScript concatenates the code cells of a notebook into a Python script, blanking
+// IPython magics and shell escapes so the line mapping is preserved
+func parseNotebookScript(content []byte) (*notebookScript, error) {
+	cells, err := parseNotebook(content)
+	if err != nil {
+		return nil, err
+	}
+
+	script := &notebookScript{}
+	var source strings.Builder
+	for _, cell := range cells {
+		if cell.Type != "code" {
+			continue
+		}
+		// Cell magics such as %%bash make the whole cell non-Python
+		cellMagic := len(cell.Lines) > 0 && strings.HasPrefix(strings.TrimSpace(cell.Lines[0]), "%%")
+		for i, line := range cell.Lines {
+			line = strings.TrimRight(line, "\r\n")
+			trimmed := strings.TrimSpace(line)
+			if cellMagic || strings.HasPrefix(trimmed, "%") || strings.HasPrefix(trimmed, "!") {
+				line = ""
+			}
+			source.WriteString(line)
+			source.WriteString("\n")
+			script.lineNos = append(script.lineNos, cell.LineNos[i])
+		}
+	}
+	script.Source = source.String()
+	return script, nil
+}
+
+// notebookLine maps a line of the script to its line in the notebook file
+func (s *notebookScript) notebookLine(line int) int {
+	if line < 1 || line > len(s.lineNos) {
+		return line
+	}
+	return s.lineNos[line-1]
+}
+
+// parseNotebook reads the cells of a notebook, keeping the line each source line is stored on
+// so that positions in the concatenated code can be reported against the notebook file
+func parseNotebook(content []byte) ([]notebookCell, error) {
+	p := &notebookParser{decoder: json.NewDecoder(bytes.NewReader(content)), content: content, line: 1}
+
+	if err := p.expectDelim('{'); err != nil {
+		return nil, err
+	}
+	var cells []notebookCell
+	for p.decoder.More() {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if key != "cells" {
+			if err := p.skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := p.expectDelim('['); err != nil {
+			return nil, err
+		}
+		for p.decoder.More() {
+			cell, err := p.cell()
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, cell)
+		}
+		if err := p.expectDelim(']'); err != nil {
+			return nil, err
+		}
+	}
+	return cells, nil
+}
+
+// notebookParser walks the JSON tokens of a notebook, tracking the line of the last token read
+type notebookParser struct {
+	decoder *json.Decoder
+	content []byte
+	offset  int
+	line    int
+}
+
+// token reads the next token, returning the line it ends on
+func (p *notebookParser) token() (json.Token, int, error) {
+	token, err := p.decoder.Token()
+	if err == io.EOF {
+		return nil, 0, fmt.Errorf("invalid notebook: unexpected end of JSON")
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid notebook: %w", err)
+	}
+
+	end := int(p.decoder.InputOffset())
+	p.line += bytes.Count(p.content[p.offset:end], []byte("\n"))
+	p.offset = end
+	return token, p.line, nil
+}
+
+func (p *notebookParser) expectDelim(delim json.Delim) error {
+	token, _, err := p.token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("invalid notebook: expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
+func (p *notebookParser) key() (string, error) {
+	token, _, err := p.token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid notebook: expected an object key, got %v", token)
+	}
+	return key, nil
+}
+
+// skip skips the next value
+func (p *notebookParser) skip() error {
+	depth := 0
+	for {
+		token, _, err := p.token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// cell reads a cell object
+func (p *notebookParser) cell() (notebookCell, error) {
+	var cell notebookCell
+	if err := p.expectDelim('{'); err != nil {
+		return cell, err
+	}
+	for p.decoder.More() {
+		key, err := p.key()
+		if err != nil {
+			return cell, err
+		}
+		switch key {
+		case "cell_type":
+			token, _, err := p.token()
+			if err != nil {
+				return cell, err
+			}
+			cell.Type, _ = token.(string)
+		case "source":
+			if err := p.source(&cell); err != nil {
+				return cell, err
+			}
+		default:
+			if err := p.skip(); err != nil {
+				return cell, err
+			}
+		}
+	}
+	return cell, p.expectDelim('}')
+}
+
+// source reads the source of a cell, either a single string or a list of strings that are
+// usually one line each
+func (p *notebookParser) source(cell *notebookCell) error {
+	token, line, err := p.token()
+	if err != nil {
+		return err
+	}
+	if text, ok := token.(string); ok {
+		cell.addSource(text, line)
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("invalid notebook: unexpected cell source %v", token)
+	}
+
+	for p.decoder.More() {
+		token, line, err := p.token()
+		if err != nil {
+			return err
+		}
+		if text, ok := token.(string); ok {
+			cell.addSource(text, line)
+		}
+	}
+	return p.expectDelim(']')
+}
+
+// addSource appends source text stored on line of the notebook file
+func (c *notebookCell) addSource(text string, line int) {
+	continued := len(c.Lines) > 0 && !strings.HasSuffix(c.Lines[len(c.Lines)-1], "\n")
+	for _, part := range strings.SplitAfter(text, "\n") {
+		if part == "" {
+			continue
+		}
+		if continued {
+			c.Lines[len(c.Lines)-1] += part
+			continued = false
+			continue
+		}
+		c.Lines = append(c.Lines, part)
+		c.LineNos = append(c.LineNos, line)
+	}
+}
@@ -5,7 +5,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/flanksource/arch-unit/analysis"
@@ -23,28 +28,39 @@ func init() {
 	analysis.RegisterExtractor("python", pythonExtractor)
 }
 
+var pythonFiles = []string{"requirements.txt", "requirements*.txt", "Pipfile", "Pipfile.lock",
+	"pyproject.toml", "setup.py", "setup.cfg", "poetry.lock"}
+
 // PythonDependencyScanner scans Python dependencies from various file formats
 type PythonDependencyScanner struct {
 	*analysis.BaseDependencyScanner
+	resolver *analysis.ResolutionService
 }
 
 // NewPythonDependencyScanner creates a new Python dependency scanner
 func NewPythonDependencyScanner() *PythonDependencyScanner {
 	scanner := &PythonDependencyScanner{
-		BaseDependencyScanner: analysis.NewBaseDependencyScanner("python",
-			[]string{"requirements.txt", "requirements*.txt", "Pipfile", "Pipfile.lock",
-				"pyproject.toml", "setup.py", "setup.cfg", "poetry.lock"}),
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("python", pythonFiles),
 	}
 
 	return scanner
 }
 
+// NewPythonDependencyScannerWithResolver creates a new Python dependency scanner resolving the
+// Git URLs of direct dependencies from their PyPI metadata
+func NewPythonDependencyScannerWithResolver(resolver *analysis.ResolutionService) *PythonDependencyScanner {
+	return &PythonDependencyScanner{
+		BaseDependencyScanner: analysis.NewBaseDependencyScanner("python", pythonFiles),
+		resolver:              resolver,
+	}
+}
+
 // ScanFile scans a Python dependency file and extracts dependencies
 func (s *PythonDependencyScanner) ScanFile(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
 	filename := strings.ToLower(filepath)
 
 	switch {
-	case strings.Contains(filename, "requirements"):
+	case strings.HasPrefix(path.Base(filename), "requirements"):
 		return s.scanRequirementsTxt(ctx, filepath, content)
 	case strings.HasSuffix(filename, "pipfile"):
 		return s.scanPipfile(ctx, filepath, content)
@@ -63,279 +79,389 @@ func (s *PythonDependencyScanner) ScanFile(ctx *models.ScanContext, filepath str
 	}
 }
 
-// scanRequirementsTxt scans requirements.txt format files
-func (s *PythonDependencyScanner) scanRequirementsTxt(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
-	ctx.Debugf("Scanning Python requirements from %s", filepath)
+// requirement is a dependency declared in a manifest
+type requirement struct {
+	name    string
+	version string
+	// git and ref are set for dependencies installed from a Git repository
+	git  string
+	ref  string
+	line int
+}
 
-	var dependencies []*models.Dependency
-	scanner := bufio.NewScanner(bytes.NewReader(content))
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+
+// parseRequirement parses a PEP 508 specifier, e.g. "requests[socks]>=2.28; python_version>'3.8'"
+// or "pkg @ git+https://github.com/user/pkg.git@v1", or the Git URL of a requirements file, e.g.
+// "git+https://github.com/user/pkg.git@v1#egg=pkg"
+func parseRequirement(spec string) (requirement, bool) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "git+") {
+		gitURL, ref, egg := pipGitURL(spec)
+		if egg == "" {
+			egg = path.Base(gitURL)
+		}
+		return requirement{name: egg, git: gitURL, ref: ref}, gitURL != ""
+	}
 
-	// Regex patterns for parsing requirements
-	packagePattern := regexp.MustCompile(`^([a-zA-Z0-9_\-\.]+)\s*([=<>!~]+.*)?`)
+	// Environment markers follow a semicolon
+	spec, _, _ = strings.Cut(spec, ";")
+	m := requirementPattern.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return requirement{}, false
+	}
+	req := requirement{name: m[1]}
+	if url, ok := strings.CutPrefix(m[2], "@"); ok {
+		req.git, req.ref, _ = pipGitURL(strings.TrimSpace(url))
+		return req, true
+	}
+	req.version = pythonVersion(m[2])
+	return req, true
+}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+// pipGitURL returns the https URL, ref and egg name of a pip VCS URL such as
+// git+ssh://git@github.com/user/pkg.git@v1#egg=pkg. It returns empty strings when url is not a
+// Git repository.
+func pipGitURL(url string) (string, string, string) {
+	url, fragment, _ := strings.Cut(url, "#")
+	var egg string
+	for _, option := range strings.Split(fragment, "&") {
+		if name, ok := strings.CutPrefix(option, "egg="); ok {
+			egg = name
+		}
+	}
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	url, ok := strings.CutPrefix(url, "git+")
+	if !ok {
+		return "", "", egg
+	}
+	// The ref follows the last @ of the path, an @ before the path separates the user
+	scheme, rest, found := strings.Cut(url, "://")
+	if !found {
+		gitURL, _ := analysis.NpmGitURL("git+" + url)
+		return gitURL, "", egg
+	}
+	var ref string
+	if at := strings.LastIndex(rest, "@"); at > strings.Index(rest, "/") {
+		rest, ref = rest[:at], rest[at+1:]
+	}
+	gitURL, _ := analysis.NpmGitURL("git+" + scheme + "://" + rest)
+	return gitURL, ref, egg
+}
+
+// pythonVersion simplifies a version specification, e.g. "==1.2.3" -> "1.2.3"
+func pythonVersion(spec string) string {
+	spec = strings.Trim(strings.TrimSpace(spec), "()")
+	for _, operator := range []string{"===", "==", ">=", "~=", "^", "~"} {
+		if version, ok := strings.CutPrefix(spec, operator); ok {
+			return strings.TrimSpace(version)
 		}
+	}
+	if spec == "*" {
+		return ""
+	}
+	return spec
+}
+
+// normalizeName returns the PEP 503 normalized form of a package name, which lockfiles use to
+// refer to their packages
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
+
+// dependency returns the dependency a requirement declares, resolving the Git URL of registry
+// packages
+func (s *PythonDependencyScanner) dependency(ctx *models.ScanContext, file string, req requirement) *models.Dependency {
+	dependency := &models.Dependency{
+		Name:    req.name,
+		Version: req.version,
+		Type:    models.DependencyTypePip,
+		Source:  pythonSource(file, req.line),
+	}
+	if req.git != "" {
+		dependency.Git = req.git
+		dependency.Version = req.ref
+	} else {
+		s.resolveGitURL(ctx, dependency)
+	}
+	return dependency
+}
 
-		// Skip -r (include) and -e (editable) directives for now
-		if strings.HasPrefix(line, "-r ") || strings.HasPrefix(line, "-e ") {
+// dependencies returns the dependencies of requirements matching the scan filters
+func (s *PythonDependencyScanner) dependencies(ctx *models.ScanContext, file string, reqs []requirement) []*models.Dependency {
+	var dependencies []*models.Dependency
+	for _, req := range reqs {
+		dependency := s.dependency(ctx, file, req)
+		if !ctx.Matches(dependency) {
 			continue
 		}
+		dependencies = append(dependencies, dependency)
+	}
 
-		// Parse package and version
-		matches := packagePattern.FindStringSubmatch(line)
-		if len(matches) >= 2 {
-			dep := &models.Dependency{
-				Name: matches[1],
-				Type: models.DependencyTypePip,
-			}
+	sortPythonDependencies(dependencies)
+	ctx.Debugf("Found %d Python dependencies in %s", len(dependencies), file)
+	return dependencies
+}
 
-			// Parse version if present
-			if len(matches) >= 3 && matches[2] != "" {
-				version := strings.TrimSpace(matches[2])
-				// Simplify version specification (e.g., "==1.2.3" -> "1.2.3")
-				version = strings.TrimPrefix(version, "==")
-				version = strings.TrimPrefix(version, ">=")
-				version = strings.TrimPrefix(version, "~=")
-				dep.Version = version
-			}
+// scanRequirementsTxt scans requirements.txt format files
+func (s *PythonDependencyScanner) scanRequirementsTxt(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning Python requirements from %s", filepath)
 
-			if !ctx.Matches(dep) {
+	var reqs []requirement
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		// Comments and per-requirement options such as --hash follow a space
+		text, _, _ = strings.Cut(text, " #")
+		text, _, _ = strings.Cut(text, " --")
+
+		// Editable installs are only followed when they are Git repositories
+		if editable, ok := strings.CutPrefix(text, "-e "); ok {
+			text = strings.TrimSpace(editable)
+			if !strings.HasPrefix(text, "git+") {
 				continue
 			}
+		}
+		// Skip empty lines, comments and options such as -r (include)
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "-") {
+			continue
+		}
 
-			dependencies = append(dependencies, dep)
-			ctx.Debugf("Found Python dependency: %s@%s", dep.Name, dep.Version)
+		if req, ok := parseRequirement(text); ok {
+			req.line = line
+			reqs = append(reqs, req)
 		}
 	}
 
-	ctx.Debugf("Found %d Python dependencies", len(dependencies))
-	return dependencies, nil
+	return s.dependencies(ctx, filepath, reqs), nil
 }
 
-// scanPipfile scans Pipfile format
-func (s *PythonDependencyScanner) scanPipfile(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
-	ctx.Debugf("Scanning Pipfile from %s", filepath)
+// pipfile is the part of a Pipfile listing dependencies
+type pipfile struct {
+	Packages    map[string]interface{} `toml:"packages"`
+	DevPackages map[string]interface{} `toml:"dev-packages"`
+}
 
-	var pipfile struct {
-		Packages    map[string]interface{} `toml:"packages"`
-		DevPackages map[string]interface{} `toml:"dev-packages"`
+// scanPipfile scans Pipfile format, which is superseded by the resolved versions of a
+// Pipfile.lock next to it
+func (s *PythonDependencyScanner) scanPipfile(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	if _, err := os.Stat(filepath.Join(filepath.Dir(file), "Pipfile.lock")); err == nil {
+		ctx.Debugf("Skipping %s, dependencies are read from Pipfile.lock", file)
+		return nil, nil
 	}
+	ctx.Debugf("Scanning Pipfile from %s", file)
 
-	if err := toml.Unmarshal(content, &pipfile); err != nil {
+	var manifest pipfile
+	if err := toml.Unmarshal(content, &manifest); err != nil {
 		return nil, fmt.Errorf("failed to parse Pipfile: %w", err)
 	}
 
-	var dependencies []*models.Dependency
-
-	// Process regular packages
-	for name, spec := range pipfile.Packages {
-		dep := &models.Dependency{
-			Name: name,
-			Type: models.DependencyTypePip,
-			Git:  fmt.Sprintf("https://pypi.org/project/%s/", name),
+	var reqs []requirement
+	for _, section := range []map[string]interface{}{manifest.Packages, manifest.DevPackages} {
+		for name, spec := range section {
+			req := tableRequirement(name, spec)
+			req.line = lineOfKey(content, name)
+			reqs = append(reqs, req)
 		}
-
-		// Extract version from spec
-		switch v := spec.(type) {
-		case string:
-			dep.Version = strings.TrimPrefix(v, "==")
-		case map[string]interface{}:
-			if version, ok := v["version"].(string); ok {
-				dep.Version = strings.TrimPrefix(version, "==")
-			}
-		}
-
-		dependencies = append(dependencies, dep)
 	}
 
-	// Process dev packages (marked as dev dependencies)
-	for name, spec := range pipfile.DevPackages {
-		dep := &models.Dependency{
-			Name: name,
-			Type: models.DependencyTypePip,
-			Git:  fmt.Sprintf("https://pypi.org/project/%s/", name),
-		}
+	return s.dependencies(ctx, file, reqs), nil
+}
 
-		// Extract version from spec
-		switch v := spec.(type) {
-		case string:
-			dep.Version = strings.TrimPrefix(v, "==")
-		case map[string]interface{}:
-			if version, ok := v["version"].(string); ok {
-				dep.Version = strings.TrimPrefix(version, "==")
+// tableRequirement returns the requirement of a Pipfile or Poetry dependency, whose spec is
+// either a version or a table such as {git = "https://github.com/user/pkg.git", tag = "v1"}
+func tableRequirement(name string, spec interface{}) requirement {
+	req := requirement{name: name}
+	switch v := spec.(type) {
+	case string:
+		req.version = pythonVersion(v)
+	case map[string]interface{}:
+		if version, ok := v["version"].(string); ok {
+			req.version = pythonVersion(version)
+		}
+		if git, ok := v["git"].(string); ok {
+			if !strings.HasPrefix(git, "git+") {
+				git = "git+" + git
+			}
+			req.git, _, _ = pipGitURL(git)
+			for _, key := range []string{"ref", "rev", "tag", "branch"} {
+				if ref, ok := v[key].(string); ok {
+					req.ref = ref
+					break
+				}
 			}
 		}
-
-		dependencies = append(dependencies, dep)
 	}
+	return req
+}
 
-	ctx.Debugf("Found %d Python dependencies in Pipfile", len(dependencies))
-	return dependencies, nil
+// pipfileLockEntry is a package of Pipfile.lock
+type pipfileLockEntry struct {
+	Version string `json:"version"`
+	Git     string `json:"git"`
+	Ref     string `json:"ref"`
 }
 
-// scanPipfileLock scans Pipfile.lock format
-func (s *PythonDependencyScanner) scanPipfileLock(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
-	ctx.Debugf("Scanning Pipfile.lock from %s", filepath)
+// scanPipfileLock scans Pipfile.lock format. The lockfile does not record which package needs
+// which, so the packages listed in the Pipfile next to it are direct and all others transitive.
+func (s *PythonDependencyScanner) scanPipfileLock(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning Pipfile.lock from %s", file)
 
 	var lockfile struct {
-		Default map[string]struct {
-			Version string   `json:"version"`
-			Hashes  []string `json:"hashes"`
-		} `json:"default"`
-		Develop map[string]struct {
-			Version string   `json:"version"`
-			Hashes  []string `json:"hashes"`
-		} `json:"develop"`
+		Default map[string]pipfileLockEntry `json:"default"`
+		Develop map[string]pipfileLockEntry `json:"develop"`
 	}
-
 	if err := json.Unmarshal(content, &lockfile); err != nil {
 		return nil, fmt.Errorf("failed to parse Pipfile.lock: %w", err)
 	}
 
-	var dependencies []*models.Dependency
-
-	// Process default packages
-	for name, info := range lockfile.Default {
-		dep := &models.Dependency{
-			Name:    name,
-			Version: strings.TrimPrefix(info.Version, "=="),
-			Type:    models.DependencyTypePip,
-			Git:     fmt.Sprintf("https://pypi.org/project/%s/", name),
+	var direct map[string]bool
+	if manifestContent, err := os.ReadFile(filepath.Join(filepath.Dir(file), "Pipfile")); err == nil {
+		var manifest pipfile
+		if err := toml.Unmarshal(manifestContent, &manifest); err == nil {
+			direct = make(map[string]bool)
+			for _, section := range []map[string]interface{}{manifest.Packages, manifest.DevPackages} {
+				for name := range section {
+					direct[normalizeName(name)] = true
+				}
+			}
 		}
-		dependencies = append(dependencies, dep)
 	}
 
-	// Process develop packages
-	for name, info := range lockfile.Develop {
-		dep := &models.Dependency{
-			Name:    name,
-			Version: strings.TrimPrefix(info.Version, "=="),
-			Type:    models.DependencyTypePip,
-			Git:     fmt.Sprintf("https://pypi.org/project/%s/", name),
+	var dependencies []*models.Dependency
+	for _, section := range []map[string]pipfileLockEntry{lockfile.Default, lockfile.Develop} {
+		for name, entry := range section {
+			depth := 0
+			if direct != nil && !direct[normalizeName(name)] {
+				depth = 1
+			}
+			req := requirement{name: name, version: pythonVersion(entry.Version), line: lineOf(content, strconv.Quote(name)+":")}
+			if entry.Git != "" {
+				req.git, _, _ = pipGitURL("git+" + strings.TrimPrefix(entry.Git, "git+"))
+				req.ref = entry.Ref
+			}
+			dependencies = s.appendLocked(ctx, file, dependencies, req, depth)
 		}
-		dependencies = append(dependencies, dep)
 	}
 
-	ctx.Debugf("Found %d Python dependencies in Pipfile.lock", len(dependencies))
+	sortPythonDependencies(dependencies)
+	ctx.Debugf("Found %d Python dependencies in %s", len(dependencies), file)
 	return dependencies, nil
 }
 
-// scanPyprojectToml scans pyproject.toml format
-func (s *PythonDependencyScanner) scanPyprojectToml(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
-	ctx.Debugf("Scanning pyproject.toml from %s", filepath)
-
-	var pyproject struct {
-		Tool struct {
-			Poetry struct {
-				Dependencies    map[string]interface{} `toml:"dependencies"`
-				DevDependencies map[string]interface{} `toml:"dev-dependencies"`
-			} `toml:"poetry"`
-		} `toml:"tool"`
-		Project struct {
-			Dependencies         []string            `toml:"dependencies"`
-			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
-		} `toml:"project"`
+// appendLocked appends the dependency of a locked package at depth, only the Git URLs of direct
+// dependencies are resolved as resolving every installed package would query PyPI for each
+func (s *PythonDependencyScanner) appendLocked(ctx *models.ScanContext, file string, dependencies []*models.Dependency, req requirement, depth int) []*models.Dependency {
+	var dependency *models.Dependency
+	if depth == 0 {
+		dependency = s.dependency(ctx, file, req)
+	} else {
+		dependency = &models.Dependency{
+			Name:     req.name,
+			Version:  req.version,
+			Type:     models.DependencyTypePip,
+			Source:   pythonSource(file, req.line),
+			Git:      req.git,
+			Depth:    depth,
+			Indirect: true,
+		}
+		if req.git != "" {
+			dependency.Version = req.ref
+		}
 	}
 
-	if err := toml.Unmarshal(content, &pyproject); err != nil {
-		return nil, fmt.Errorf("failed to parse pyproject.toml: %w", err)
+	if !ctx.Matches(dependency) {
+		return dependencies
 	}
+	return append(dependencies, dependency)
+}
 
-	var dependencies []*models.Dependency
-
-	// Process Poetry dependencies
-	for name, spec := range pyproject.Tool.Poetry.Dependencies {
-		if name == "python" {
-			continue // Skip Python version specification
-		}
+// pyproject is the part of pyproject.toml listing dependencies
+type pyproject struct {
+	Tool struct {
+		Poetry struct {
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+}
 
-		dep := &models.Dependency{
-			Name: name,
-			Type: models.DependencyTypePip,
-			Git:  fmt.Sprintf("https://pypi.org/project/%s/", name),
-		}
+// requirements returns the dependencies declared for Poetry and by PEP 621, with their line in
+// content
+func (p pyproject) requirements(content []byte) []requirement {
+	var reqs []requirement
 
-		// Extract version from spec
-		switch v := spec.(type) {
-		case string:
-			dep.Version = strings.TrimPrefix(v, "^")
-			dep.Version = strings.TrimPrefix(dep.Version, "~")
-		case map[string]interface{}:
-			if version, ok := v["version"].(string); ok {
-				dep.Version = strings.TrimPrefix(version, "^")
-				dep.Version = strings.TrimPrefix(dep.Version, "~")
+	sections := []map[string]interface{}{p.Tool.Poetry.Dependencies, p.Tool.Poetry.DevDependencies}
+	for _, group := range p.Tool.Poetry.Group {
+		sections = append(sections, group.Dependencies)
+	}
+	for _, section := range sections {
+		for name, spec := range section {
+			if name == "python" {
+				continue // Skip Python version specification
 			}
+			req := tableRequirement(name, spec)
+			req.line = lineOfKey(content, name)
+			reqs = append(reqs, req)
 		}
-
-		dependencies = append(dependencies, dep)
 	}
 
-	// Process Poetry dev dependencies
-	for name, spec := range pyproject.Tool.Poetry.DevDependencies {
-		dep := &models.Dependency{
-			Name: name,
-			Type: models.DependencyTypePip,
-			Git:  fmt.Sprintf("https://pypi.org/project/%s/", name),
-		}
-
-		// Extract version from spec
-		switch v := spec.(type) {
-		case string:
-			dep.Version = strings.TrimPrefix(v, "^")
-			dep.Version = strings.TrimPrefix(dep.Version, "~")
-		case map[string]interface{}:
-			if version, ok := v["version"].(string); ok {
-				dep.Version = strings.TrimPrefix(version, "^")
-				dep.Version = strings.TrimPrefix(dep.Version, "~")
+	specs := p.Project.Dependencies
+	for _, optional := range p.Project.OptionalDependencies {
+		specs = append(specs, optional...)
+	}
+	for _, spec := range specs {
+		if req, ok := parseRequirement(spec); ok {
+			req.line = lineOf(content, strconv.Quote(spec))
+			if req.line == 0 {
+				req.line = lineOf(content, "'"+spec+"'")
 			}
+			reqs = append(reqs, req)
 		}
-
-		dependencies = append(dependencies, dep)
 	}
+	return reqs
+}
 
-	// Process standard project dependencies (PEP 621)
-	for _, depStr := range pyproject.Project.Dependencies {
-		// Parse dependency string (e.g., "requests>=2.28.0")
-		parts := regexp.MustCompile(`([a-zA-Z0-9_\-\.]+)\s*([=<>!~]+.*)?`).FindStringSubmatch(depStr)
-		if len(parts) >= 2 {
-			dep := &models.Dependency{
-				Name: parts[1],
-				Type: models.DependencyTypePip,
-				Git:  fmt.Sprintf("https://pypi.org/project/%s/", parts[1]),
-			}
-
-			if len(parts) >= 3 && parts[2] != "" {
-				version := strings.TrimSpace(parts[2])
-				version = strings.TrimPrefix(version, ">=")
-				version = strings.TrimPrefix(version, "==")
-				dep.Version = version
-			}
+// scanPyprojectToml scans pyproject.toml format, which is superseded by the resolved versions
+// of a poetry.lock next to it
+func (s *PythonDependencyScanner) scanPyprojectToml(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	if _, err := os.Stat(filepath.Join(filepath.Dir(file), "poetry.lock")); err == nil {
+		ctx.Debugf("Skipping %s, dependencies are read from poetry.lock", file)
+		return nil, nil
+	}
+	ctx.Debugf("Scanning pyproject.toml from %s", file)
 
-			dependencies = append(dependencies, dep)
-		}
+	var project pyproject
+	if err := toml.Unmarshal(content, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse pyproject.toml: %w", err)
 	}
 
-	ctx.Debugf("Found %d Python dependencies in pyproject.toml", len(dependencies))
-	return dependencies, nil
+	return s.dependencies(ctx, file, project.requirements(content)), nil
 }
 
-// scanPoetryLock scans poetry.lock format
-func (s *PythonDependencyScanner) scanPoetryLock(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
-	ctx.Debugf("Scanning poetry.lock from %s", filepath)
+// scanPoetryLock scans poetry.lock format. Packages are reported at the depth they are first
+// reached from the dependencies of the pyproject.toml next to the lockfile, or from the packages
+// no other package depends on when there is none.
+func (s *PythonDependencyScanner) scanPoetryLock(ctx *models.ScanContext, file string, content []byte) ([]*models.Dependency, error) {
+	ctx.Debugf("Scanning poetry.lock from %s", file)
 
 	var lockfile struct {
 		Package []struct {
-			Name    string `toml:"name"`
-			Version string `toml:"version"`
-			Source  struct {
-				Type      string `toml:"type"`
-				URL       string `toml:"url"`
-				Reference string `toml:"reference"`
+			Name         string                 `toml:"name"`
+			Version      string                 `toml:"version"`
+			Dependencies map[string]interface{} `toml:"dependencies"`
+			Source       struct {
+				Type              string `toml:"type"`
+				URL               string `toml:"url"`
+				Reference         string `toml:"reference"`
+				ResolvedReference string `toml:"resolved_reference"`
 			} `toml:"source"`
 		} `toml:"package"`
 	}
@@ -344,26 +470,78 @@ func (s *PythonDependencyScanner) scanPoetryLock(ctx *models.ScanContext, filepa
 		return nil, fmt.Errorf("failed to parse poetry.lock: %w", err)
 	}
 
-	var dependencies []*models.Dependency
+	packages := make(map[string]int, len(lockfile.Package))
+	referenced := make(map[string]bool)
+	for i, pkg := range lockfile.Package {
+		packages[normalizeName(pkg.Name)] = i
+		for name := range pkg.Dependencies {
+			if normalizeName(name) != normalizeName(pkg.Name) {
+				referenced[normalizeName(name)] = true
+			}
+		}
+	}
 
-	for _, pkg := range lockfile.Package {
-		dep := &models.Dependency{
-			Name:    pkg.Name,
-			Version: pkg.Version,
-			Type:    models.DependencyTypePip,
+	var roots []string
+	if manifest, err := os.ReadFile(filepath.Join(filepath.Dir(file), "pyproject.toml")); err == nil {
+		var project pyproject
+		if err := toml.Unmarshal(manifest, &project); err == nil {
+			for _, req := range project.requirements(manifest) {
+				roots = append(roots, normalizeName(req.name))
+			}
 		}
+	}
+	if len(roots) == 0 {
+		for _, pkg := range lockfile.Package {
+			if !referenced[normalizeName(pkg.Name)] {
+				roots = append(roots, normalizeName(pkg.Name))
+			}
+		}
+	}
 
-		// Set Git URL based on source
-		if pkg.Source.Type == "git" {
-			dep.Git = pkg.Source.URL
-		} else {
-			dep.Git = fmt.Sprintf("https://pypi.org/project/%s/", pkg.Name)
+	depths := make(map[string]int)
+	var queue []string
+	for _, name := range roots {
+		if _, ok := packages[name]; ok {
+			if _, seen := depths[name]; !seen {
+				depths[name] = 0
+				queue = append(queue, name)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for child := range lockfile.Package[packages[name]].Dependencies {
+			child = normalizeName(child)
+			if _, ok := packages[child]; ok {
+				if _, seen := depths[child]; !seen {
+					depths[child] = depths[name] + 1
+					queue = append(queue, child)
+				}
+			}
 		}
+	}
 
-		dependencies = append(dependencies, dep)
+	var dependencies []*models.Dependency
+	for _, pkg := range lockfile.Package {
+		depth, ok := depths[normalizeName(pkg.Name)]
+		if !ok {
+			// Packages that cannot be reached are reported as transitive
+			depth = 1
+		}
+		req := requirement{name: pkg.Name, version: pkg.Version, line: lineOf(content, "name = "+strconv.Quote(pkg.Name))}
+		if pkg.Source.Type == "git" {
+			req.git, _, _ = pipGitURL("git+" + strings.TrimPrefix(pkg.Source.URL, "git+"))
+			req.ref = pkg.Source.ResolvedReference
+			if req.ref == "" {
+				req.ref = pkg.Source.Reference
+			}
+		}
+		dependencies = s.appendLocked(ctx, file, dependencies, req, depth)
 	}
 
-	ctx.Debugf("Found %d Python dependencies in poetry.lock", len(dependencies))
+	sortPythonDependencies(dependencies)
+	ctx.Debugf("Found %d Python dependencies in %s", len(dependencies), file)
 	return dependencies, nil
 }
 
@@ -373,59 +551,40 @@ func (s *PythonDependencyScanner) scanSetupPy(ctx *models.ScanContext, filepath
 
 	// This is a simplified parser - setup.py is Python code and can be complex
 	// Look for install_requires and extras_require patterns
-	var dependencies []*models.Dependency
+	var reqs []requirement
 
 	// Regex to find install_requires or similar lists
 	installRequiresPattern := regexp.MustCompile(`install_requires\s*=\s*\[([\s\S]*?)\]`)
-	matches := installRequiresPattern.FindSubmatch(content)
+	matches := installRequiresPattern.FindSubmatchIndex(content)
 
-	if len(matches) > 1 {
-		requiresList := string(matches[1])
+	if len(matches) > 3 {
+		requiresList := content[matches[2]:matches[3]]
 		// Extract quoted strings from the list
 		stringPattern := regexp.MustCompile(`["']([^"']+)["']`)
-		deps := stringPattern.FindAllStringSubmatch(requiresList, -1)
-
-		for _, match := range deps {
-			if len(match) > 1 {
-				depStr := match[1]
-				// Parse dependency string
-				parts := regexp.MustCompile(`([a-zA-Z0-9_\-\.]+)\s*([=<>!~]+.*)?`).FindStringSubmatch(depStr)
-				if len(parts) >= 2 {
-					dep := &models.Dependency{
-						Name: parts[1],
-						Type: models.DependencyTypePip,
-						Git:  fmt.Sprintf("https://pypi.org/project/%s/", parts[1]),
-					}
-
-					if len(parts) >= 3 && parts[2] != "" {
-						version := strings.TrimSpace(parts[2])
-						version = strings.TrimPrefix(version, ">=")
-						version = strings.TrimPrefix(version, "==")
-						dep.Version = version
-					}
-
-					dependencies = append(dependencies, dep)
-				}
+		for _, match := range stringPattern.FindAllSubmatchIndex(requiresList, -1) {
+			if req, ok := parseRequirement(string(requiresList[match[2]:match[3]])); ok {
+				req.line = bytes.Count(content[:matches[2]+match[0]], []byte("\n")) + 1
+				reqs = append(reqs, req)
 			}
 		}
 	}
 
-	ctx.Debugf("Found %d Python dependencies in setup.py", len(dependencies))
-	return dependencies, nil
+	return s.dependencies(ctx, filepath, reqs), nil
 }
 
 // scanSetupCfg scans setup.cfg files
 func (s *PythonDependencyScanner) scanSetupCfg(ctx *models.ScanContext, filepath string, content []byte) ([]*models.Dependency, error) {
 	ctx.Debugf("Scanning setup.cfg from %s", filepath)
 
-	var dependencies []*models.Dependency
+	var reqs []requirement
 	scanner := bufio.NewScanner(bytes.NewReader(content))
 
 	inOptionsSection := false
 	inInstallRequires := false
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
 
 		// Check for [options] section
 		if strings.HasPrefix(line, "[") {
@@ -442,46 +601,75 @@ func (s *PythonDependencyScanner) scanSetupCfg(ctx *models.ScanContext, filepath
 		if strings.HasPrefix(line, "install_requires") {
 			inInstallRequires = true
 			// Check if dependencies are on the same line
-			if strings.Contains(line, "=") {
-				parts := strings.SplitN(line, "=", 2)
-				if len(parts) == 2 {
-					line = strings.TrimSpace(parts[1])
-				} else {
-					continue
-				}
-			} else {
+			_, value, ok := strings.Cut(line, "=")
+			if !ok {
 				continue
 			}
+			line = strings.TrimSpace(value)
+		} else if inInstallRequires && line != "" && !strings.HasPrefix(raw, " ") && !strings.HasPrefix(raw, "\t") {
+			// An unindented line starts a new config option
+			inInstallRequires = false
+			continue
 		}
 
 		if inInstallRequires && line != "" && !strings.HasPrefix(line, "#") {
-			// Check if this line starts a new config option
-			if strings.Contains(line, "=") && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
-				inInstallRequires = false
-				continue
+			if req, ok := parseRequirement(line); ok {
+				req.line = lineNumber
+				reqs = append(reqs, req)
 			}
+		}
+	}
 
-			// Parse dependency
-			parts := regexp.MustCompile(`([a-zA-Z0-9_\-\.]+)\s*([=<>!~]+.*)?`).FindStringSubmatch(line)
-			if len(parts) >= 2 {
-				dep := &models.Dependency{
-					Name: parts[1],
-					Type: models.DependencyTypePip,
-					Git:  fmt.Sprintf("https://pypi.org/project/%s/", parts[1]),
-				}
+	return s.dependencies(ctx, filepath, reqs), nil
+}
 
-				if len(parts) >= 3 && parts[2] != "" {
-					version := strings.TrimSpace(parts[2])
-					version = strings.TrimPrefix(version, ">=")
-					version = strings.TrimPrefix(version, "==")
-					dep.Version = version
-				}
+// resolveGitURL resolves the Git URL of a PyPI dependency
+func (s *PythonDependencyScanner) resolveGitURL(ctx *models.ScanContext, dependency *models.Dependency) {
+	if s.resolver == nil {
+		return
+	}
+	if gitURL, err := s.resolver.ResolveGitURL(ctx, dependency.Name, "pip"); err == nil && gitURL != "" {
+		dependency.Git = gitURL
+	}
+}
 
-				dependencies = append(dependencies, dep)
+// lineOfKey returns the line of a TOML key, which may be quoted
+func lineOfKey(content []byte, key string) int {
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		for _, text := range []string{key, strconv.Quote(key), "'" + key + "'"} {
+			if rest, ok := strings.CutPrefix(line, text); ok && strings.HasPrefix(strings.TrimSpace(rest), "=") {
+				return i + 1
 			}
 		}
 	}
+	return 0
+}
 
-	ctx.Debugf("Found %d Python dependencies in setup.cfg", len(dependencies))
-	return dependencies, nil
+// lineOf returns the first line containing text, 0 when there is none
+func lineOf(content []byte, text string) int {
+	i := bytes.Index(content, []byte(text))
+	if i < 0 {
+		return 0
+	}
+	return bytes.Count(content[:i], []byte("\n")) + 1
+}
+
+func pythonSource(file string, line int) string {
+	if line == 0 {
+		return path.Base(file)
+	}
+	return fmt.Sprintf("%s:%d", path.Base(file), line)
+}
+
+func sortPythonDependencies(dependencies []*models.Dependency) {
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].Depth != dependencies[j].Depth {
+			return dependencies[i].Depth < dependencies[j].Depth
+		}
+		if dependencies[i].Name != dependencies[j].Name {
+			return dependencies[i].Name < dependencies[j].Name
+		}
+		return dependencies[i].Version < dependencies[j].Version
+	})
 }
@@ -1,20 +1,27 @@
 package python
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/flanksource/arch-unit/analysis/sql/rawsql"
 	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/capabilities"
+	"github.com/flanksource/arch-unit/internal/limits"
 	"github.com/flanksource/arch-unit/models"
 )
 
+// noticeLiteral matches the first string literal of the arguments of a decorator
+var noticeLiteral = regexp.MustCompile(`['"]([^'"]*)['"]`)
+
 // PythonASTExtractor extracts AST information from Python source files
 type PythonASTExtractor struct {
 	filePath    string
@@ -79,12 +86,19 @@ func (e *PythonASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath str
 	result.PackageName = e.packageName
 
 	// Run Python AST extraction
-	pythonResult, err := e.runPythonASTExtraction(filePath)
+	var pythonResult *PythonASTResult
+	var err error
+	if isNotebook(filePath) {
+		pythonResult, err = e.extractNotebook(content)
+	} else {
+		pythonResult, err = e.runPythonASTExtraction(filePath)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract Python AST: %w", err)
 	}
 
 	// Convert Python AST results to generic AST nodes
+	functions := make(map[string]*models.ASTNode) // function or Class.method -> node
 	for _, node := range pythonResult.Nodes {
 		astNode := &models.ASTNode{
 			FilePath:             filePath,
@@ -123,12 +137,43 @@ func (e *PythonASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath str
 			}
 			astNode.FieldName = node.Name
 		}
+		if notice, ok := deprecation(node.Decorators); ok {
+			if astNode.Metatdata == nil {
+				astNode.Metatdata = make(map[string]string)
+			}
+			astNode.Metatdata[models.MetadataDeprecated] = notice
+		}
+		if names := decoratorNames(node.Decorators); names != "" {
+			if astNode.Metatdata == nil {
+				astNode.Metatdata = make(map[string]string)
+			}
+			astNode.Metatdata[models.MetadataAnnotations] = names
+		}
 
 		result.AddNode(astNode)
+		if node.Type == "function" || node.Type == "method" {
+			if node.Parent != "" {
+				functions[node.Parent+"."+node.Name] = astNode
+			} else {
+				functions[node.Name] = astNode
+			}
+		}
 	}
 
 	// Convert relationships
 	for _, rel := range pythonResult.Relationships {
+		if rel.Type == "sql" {
+			e.addSQLQuery(functions[rel.FromEntity], rel, result)
+			continue
+		}
+		if rel.Type == "http" {
+			e.addHTTPCall(functions[rel.FromEntity], rel, result)
+			continue
+		}
+		if rel.Type == "env" {
+			e.addEnvVar(functions[rel.FromEntity], rel, result)
+			continue
+		}
 		astRel := &models.ASTRelationship{
 			FromASTID:        0,   // Will be filled by analyzer
 			ToASTID:          nil, // Will be resolved by analyzer if possible
@@ -154,6 +199,129 @@ func (e *PythonASTExtractor) ExtractFile(cache cache.ReadOnlyCache, filePath str
 	return result, nil
 }
 
+// extractNotebook extracts the code cells of a Jupyter notebook as a single script, reporting
+// lines against the notebook file
+func (e *PythonASTExtractor) extractNotebook(content []byte) (*PythonASTResult, error) {
+	script, err := parseNotebookScript(content)
+	if err != nil {
+		return nil, err
+	}
+
+	scriptFile, err := os.CreateTemp("", "notebook_*.py")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(scriptFile.Name())
+	defer func() { _ = scriptFile.Close() }()
+
+	if _, err := scriptFile.WriteString(script.Source); err != nil {
+		return nil, fmt.Errorf("failed to write notebook script: %w", err)
+	}
+
+	result, err := e.runPythonASTExtraction(scriptFile.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result.Nodes {
+		result.Nodes[i].StartLine = script.notebookLine(result.Nodes[i].StartLine)
+		result.Nodes[i].EndLine = script.notebookLine(result.Nodes[i].EndLine)
+	}
+	for i := range result.Imports {
+		result.Imports[i].Line = script.notebookLine(result.Imports[i].Line)
+	}
+	for i := range result.Relationships {
+		result.Relationships[i].Line = script.notebookLine(result.Relationships[i].Line)
+	}
+	return result, nil
+}
+
+// addSQLQuery records a raw SQL query of a function as a statement and links the function to the
+// tables it reads or writes
+func (e *PythonASTExtractor) addSQLQuery(function *models.ASTNode, rel PythonRelationship, result *types.ASTResult) {
+	query, ok := rawsql.Parse(rel.Text)
+	if !ok || function == nil {
+		return
+	}
+	function.Statements = append(function.Statements, models.ASTStatement{
+		StartLine: rel.Line,
+		EndLine:   rel.Line,
+		Text:      rawsql.Normalize(rel.Text),
+		Type:      models.ASTStatementTypeSQLQuery,
+	})
+	for _, table := range query.Tables {
+		result.AddRelationship(&models.ASTRelationship{
+			FromAST:          function,
+			LineNo:           rel.Line,
+			RelationshipType: models.RelationshipTypeSQLQuery,
+			Text:             table,
+		})
+	}
+}
+
+// addHTTPCall records an HTTP call of a function to a known URL as a statement and links the
+// function to the URL
+func (e *PythonASTExtractor) addHTTPCall(function *models.ASTNode, rel PythonRelationship, result *types.ASTResult) {
+	if function == nil {
+		return
+	}
+	function.Statements = append(function.Statements, models.ASTStatement{
+		StartLine: rel.Line,
+		EndLine:   rel.Line,
+		Text:      rel.Text,
+		Type:      models.ASTStatementTypeHttpCall,
+	})
+	result.AddRelationship(&models.ASTRelationship{
+		FromAST:          function,
+		LineNo:           rel.Line,
+		RelationshipType: models.RelationshipTypeHTTPCall,
+		Text:             rel.Text,
+	})
+}
+
+// addEnvVar links a function to an environment variable it reads or sets through os
+func (e *PythonASTExtractor) addEnvVar(function *models.ASTNode, rel PythonRelationship, result *types.ASTResult) {
+	if function == nil {
+		return
+	}
+	result.AddRelationship(&models.ASTRelationship{
+		FromAST:          function,
+		LineNo:           rel.Line,
+		RelationshipType: models.RelationshipTypeEnvVar,
+		Text:             rel.Text,
+		Metadata:         map[string]string{"source": "os"},
+	})
+}
+
+// deprecation returns the notice of a @deprecated decorator, as of warnings.deprecated (PEP 702),
+// typing_extensions.deprecated or the deprecation and Deprecated packages
+func deprecation(decorators []string) (string, bool) {
+	for _, decorator := range decorators {
+		name, args, _ := strings.Cut(decorator, "(")
+		if name != "deprecated" && !strings.HasSuffix(name, ".deprecated") {
+			continue
+		}
+		if match := noticeLiteral.FindStringSubmatch(args); match != nil {
+			return match[1], true
+		}
+		return "true", true
+	}
+	return "", false
+}
+
+// decoratorNames returns the comma separated names of decorators without their arguments, e.g.
+// app.route,login_required for @app.route("/users") and @login_required
+func decoratorNames(decorators []string) string {
+	names := make([]string, 0, len(decorators))
+	for _, decorator := range decorators {
+		name, _, _ := strings.Cut(decorator, "(")
+		if name = strings.TrimSpace(strings.TrimPrefix(name, "@")); name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
 // extractPackageName extracts package name from file path
 func (e *PythonASTExtractor) extractPackageName(filePath string) string {
 	dir := filepath.Dir(filePath)
@@ -194,16 +362,14 @@ func (e *PythonASTExtractor) runPythonASTExtraction(filePath string) (*PythonAST
 		return nil, fmt.Errorf("failed to write script: %w", err)
 	}
 
-	// Execute the script
-	cmd := exec.Command("python3", tmpFile.Name(), filePath)
-	output, err := cmd.CombinedOutput()
+	// Execute the script with python3, or python when only that is installed
+	interpreter, err := capabilities.Lookup("python3")
 	if err != nil {
-		// Try with python if python3 fails
-		cmd = exec.Command("python", tmpFile.Name(), filePath)
-		output, err = cmd.CombinedOutput()
-		if err != nil {
-			return nil, fmt.Errorf("python AST extraction failed: %w - output: %s", err, string(output))
-		}
+		return nil, err
+	}
+	output, err := runPythonScript(interpreter, tmpFile.Name(), filePath)
+	if err != nil {
+		return nil, fmt.Errorf("python AST extraction failed: %w - output: %s", err, string(output))
 	}
 
 	// Parse JSON output
@@ -215,6 +381,15 @@ func (e *PythonASTExtractor) runPythonASTExtraction(filePath string) (*PythonAST
 	return &result, nil
 }
 
+// runPythonScript runs a script with the given interpreter under its configured resource limits
+func runPythonScript(interpreter string, args ...string) ([]byte, error) {
+	cmd, err := limits.CommandFor(context.Background(), interpreter, args...)
+	if err != nil {
+		return nil, err
+	}
+	return cmd.CombinedOutput()
+}
+
 // mapPythonNodeType maps Python node types to generic AST node types
 func (e *PythonASTExtractor) mapPythonNodeType(pythonType string) string {
 	switch pythonType {
@@ -9,6 +9,20 @@ import json
 import sys
 from typing import List, Dict, Any, Optional
 
+# Calls taking a raw SQL statement: DB-API cursors, SQLAlchemy, Django and pandas
+SQL_METHODS = {"execute", "executemany", "exec_driver_sql", "raw", "read_sql", "read_sql_query"}
+SQL_FUNCTIONS = {"text"}
+
+# HTTP client modules and the functions that send a request, e.g. requests.get(url) or
+# self.session.post(url), where request takes the method first
+HTTP_MODULES = {"requests", "httpx"}
+HTTP_VERBS = {"get", "head", "post", "put", "patch", "delete", "options", "request"}
+
+# Functions of os reading or setting the environment variable named by their first argument, and
+# the methods of os.environ doing the same
+ENV_FUNCTIONS = {"getenv", "putenv", "unsetenv"}
+ENV_METHODS = {"get", "setdefault", "pop"}
+
 
 class PythonASTNode:
     """Represents a node in the Python AST"""
@@ -110,11 +124,16 @@ class PythonASTExtractor(ast.NodeVisitor):
         self.relationships = []
         self.current_class = None
         self.source_lines = source_code.splitlines()
+        self.constants = {}  # module level string constants, for HTTP call URLs and environment variable names
 
     def extract(self) -> Dict[str, Any]:
         """Extract AST information and return as dictionary"""
         try:
             tree = ast.parse(self.source_code, filename=self.file_path)
+            for stmt in tree.body:
+                if isinstance(stmt, ast.Assign) and len(stmt.targets) == 1 and isinstance(stmt.targets[0], ast.Name) \
+                        and isinstance(stmt.value, ast.Constant) and isinstance(stmt.value.value, str):
+                    self.constants[stmt.targets[0].id] = stmt.value.value
             self.visit(tree)
 
             return {
@@ -247,10 +266,128 @@ class PythonASTExtractor(ast.NodeVisitor):
         func_node.decorators = [self._get_name_from_node(dec) for dec in node.decorator_list]
 
         self.nodes.append(func_node)
+        self._extract_sql_queries(node, f"{parent}.{node.name}" if parent else node.name)
+        self._extract_http_calls(node, f"{parent}.{node.name}" if parent else node.name)
+        self._extract_env_vars(node, f"{parent}.{node.name}" if parent else node.name)
 
         # Don't visit function body to avoid nested function detection
         # self.generic_visit(node)
 
+    def _extract_sql_queries(self, node, entity: str):
+        """Record string literals passed to SQL calls, e.g. cursor.execute("SELECT ...") or text("...")"""
+        for child in ast.walk(node):
+            if not isinstance(child, ast.Call) or not child.args:
+                continue
+            func = child.func
+            if isinstance(func, ast.Attribute):
+                is_sql = func.attr in SQL_METHODS
+            else:
+                is_sql = isinstance(func, ast.Name) and func.id in SQL_FUNCTIONS
+            query = child.args[0]
+            if is_sql and isinstance(query, ast.Constant) and isinstance(query.value, str):
+                self.relationships.append({
+                    "from_entity": entity,
+                    "to_entity": "",
+                    "type": "sql",
+                    "line": child.lineno,
+                    "text": query.value
+                })
+
+    def _extract_http_calls(self, node, entity: str):
+        """Record HTTP calls to known URLs, e.g. requests.get(f"{BASE}/users/{id}") or self.client.post("/orders")"""
+        for child in ast.walk(node):
+            if not isinstance(child, ast.Call) or not isinstance(child.func, ast.Attribute):
+                continue
+            func = child.func
+            verb = func.attr.lower()
+            if verb not in HTTP_VERBS:
+                continue
+            if isinstance(func.value, ast.Name):
+                receiver = func.value.id
+            elif isinstance(func.value, ast.Attribute):
+                receiver = func.value.attr
+            else:
+                continue
+            if receiver not in HTTP_MODULES and "session" not in receiver.lower() and "client" not in receiver.lower():
+                continue
+
+            method = verb.upper()
+            args = child.args
+            if verb == "request":
+                if not args:
+                    continue
+                method = args[0].value.upper() if isinstance(args[0], ast.Constant) and isinstance(args[0].value, str) else ""
+                args = args[1:]
+            url = self._url_string(args[0]) if args else None
+            if url is None:
+                continue
+            self.relationships.append({
+                "from_entity": entity,
+                "to_entity": "",
+                "type": "http",
+                "line": child.lineno,
+                "text": f"{method} {url}" if method else url
+            })
+
+    def _extract_env_vars(self, node, entity: str):
+        """Record environment variables read or set by name, e.g. os.getenv("HOME") or os.environ["HOME"]"""
+        for child in ast.walk(node):
+            name = None
+            if isinstance(child, ast.Subscript) and self._is_environ(child.value):
+                name = self._constant_string(child.slice)
+            elif isinstance(child, ast.Call) and child.args:
+                func = child.func
+                if isinstance(func, ast.Attribute) and func.attr in ENV_METHODS and self._is_environ(func.value):
+                    name = self._constant_string(child.args[0])
+                elif isinstance(func, ast.Attribute) and func.attr in ENV_FUNCTIONS \
+                        and isinstance(func.value, ast.Name) and func.value.id == "os":
+                    name = self._constant_string(child.args[0])
+                elif isinstance(func, ast.Name) and func.id in ENV_FUNCTIONS:
+                    name = self._constant_string(child.args[0])
+            if name:
+                self.relationships.append({
+                    "from_entity": entity,
+                    "to_entity": "",
+                    "type": "env",
+                    "line": child.lineno,
+                    "text": name
+                })
+
+    def _is_environ(self, node) -> bool:
+        """Return whether an expression is os.environ, or environ imported from os"""
+        if isinstance(node, ast.Attribute):
+            return node.attr == "environ" and isinstance(node.value, ast.Name) and node.value.id == "os"
+        return isinstance(node, ast.Name) and node.id == "environ"
+
+    def _constant_string(self, node) -> Optional[str]:
+        """Return the value of a string literal or a module level string constant"""
+        if isinstance(node, ast.Constant):
+            return node.value if isinstance(node.value, str) else None
+        if isinstance(node, ast.Name):
+            return self.constants.get(node.id)
+        return None
+
+    def _url_string(self, node) -> Optional[str]:
+        """Return the URL an expression evaluates to with runtime parts as {}, None if no part is constant"""
+        if isinstance(node, ast.Constant):
+            return node.value if isinstance(node.value, str) else None
+        if isinstance(node, ast.Name):
+            return self.constants.get(node.id)
+        if isinstance(node, ast.FormattedValue):
+            return self._url_string(node.value)
+        if isinstance(node, ast.JoinedStr):
+            parts = [self._url_string(v) for v in node.values]
+            if all(part is None for part in parts):
+                return None
+            return "".join(part if part is not None else "{}" for part in parts)
+        if isinstance(node, ast.BinOp) and isinstance(node.op, ast.Add):
+            left = self._url_string(node.left)
+            right = self._url_string(node.right)
+            if left is None and right is None:
+                return None
+            return (left if left is not None else "{}") + (right if right is not None else "{}")
+        return None
+
     def visit_AsyncFunctionDef(self, node):
         """Visit async function definition"""
         # Treat async functions the same as regular functions
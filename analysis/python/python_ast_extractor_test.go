@@ -9,6 +9,7 @@ import (
 
 	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
 )
 
 var _ = Describe("Python AST Extractor", func() {
@@ -80,4 +81,193 @@ var _ = Describe("Python AST Extractor", func() {
 			Expect(foundMain).To(BeTrue(), "Should find main function")
 		})
 	})
+
+	Context("when extracting raw SQL queries", func() {
+		It("should record queries as statements and relationships to their tables", func() {
+			testFile := filepath.Join("testdata", "repository.py")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			tables := make(map[string][]string)
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeSQLQuery {
+					name := rel.FromAST.MethodName
+					tables[name] = append(tables[name], rel.Text)
+				}
+			}
+			Expect(tables).To(Equal(map[string][]string{
+				"find":         {"users"},
+				"archive":      {"archived_users", "users"},
+				"count_orders": {"orders"},
+			}))
+
+			for _, node := range result.Nodes {
+				if node.MethodName == "find" {
+					Expect(node.Statements).To(HaveLen(1))
+					Expect(node.Statements[0].Type).To(Equal(models.ASTStatementTypeSQLQuery))
+					Expect(node.Statements[0].Text).To(Equal("SELECT id, name FROM users WHERE id = :id"))
+				}
+			}
+		})
+	})
+
+	Context("when extracting HTTP calls", func() {
+		It("should record calls to known URLs as statements and relationships", func() {
+			testFile := filepath.Join("testdata", "billing_client.py")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			urls := make(map[string][]string)
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeHTTPCall {
+					name := rel.FromAST.MethodName
+					urls[name] = append(urls[name], rel.Text)
+				}
+			}
+			Expect(urls).To(Equal(map[string][]string{
+				"invoice": {"GET http://billing.svc:8080/invoices/{}"},
+				"pay":     {"POST http://billing.svc:8080/invoices/{}/payments"},
+				"health":  {"HEAD http://billing.svc/healthz"},
+			}))
+
+			for _, node := range result.Nodes {
+				if node.MethodName == "health" {
+					Expect(node.Statements).To(HaveLen(1))
+					Expect(node.Statements[0].Type).To(Equal(models.ASTStatementTypeHttpCall))
+				}
+			}
+		})
+	})
+
+	Context("when extracting environment variables", func() {
+		It("should link functions to the environment variables they read or set", func() {
+			testFile := filepath.Join("testdata", "settings.py")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			envVars := make(map[string][]string)
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeEnvVar {
+					name := rel.FromAST.MethodName
+					envVars[name] = append(envVars[name], rel.Text)
+				}
+			}
+			Expect(envVars).To(Equal(map[string][]string{
+				"__init__":  {"DATABASE_URL", "APP_PORT", "DEBUG"},
+				"configure": {"TZ"},
+				// The name passed to os.environ.get is only known at runtime
+				"log_level": {"LOG_LEVEL"},
+			}))
+		})
+	})
+
+	Context("when extracting deprecated symbols", func() {
+		It("should record the notice of @deprecated decorators", func() {
+			testFile := filepath.Join("testdata", "legacy.py")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			notices := make(map[string]string)
+			for _, node := range result.Nodes {
+				if notice, ok := node.Metatdata[models.MetadataDeprecated]; ok {
+					notices[node.TypeName+"."+node.MethodName] = notice
+				}
+			}
+			Expect(notices).To(Equal(map[string]string{
+				".fetch":            "Use fetch_v2 instead",
+				".load":             "true",
+				"Client.disconnect": "Use close",
+			}))
+		})
+	})
+
+	Context("when extracting decorators", func() {
+		It("should record the names of decorators without their arguments", func() {
+			testFile := filepath.Join("testdata", "legacy.py")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+
+			decorators := make(map[string][]string)
+			for _, node := range result.Nodes {
+				if _, ok := node.Metatdata[models.MetadataAnnotations]; ok {
+					decorators[node.TypeName+"."+node.MethodName] = models.Annotations(node)
+				}
+			}
+			Expect(decorators).To(Equal(map[string][]string{
+				".fetch":            {"warnings.deprecated"},
+				".load":             {"deprecated"},
+				".save":             {"staticmethod"},
+				"Client.disconnect": {"deprecated"},
+			}))
+		})
+	})
+
+	Context("when extracting from a Jupyter notebook", func() {
+		var result *types.ASTResult
+
+		BeforeEach(func() {
+			testFile := filepath.Join("testdata", "analysis.ipynb")
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err = extractor.ExtractFile(astCache, testFile, content)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should extract the code cells with lines of the notebook file", func() {
+			lines := make(map[string][2]int)
+			for _, node := range result.Nodes {
+				if node.NodeType == models.NodeTypeMethod || node.NodeType == models.NodeTypeType {
+					lines[node.TypeName+":"+node.MethodName] = [2]int{node.StartLine, node.EndLine}
+				}
+			}
+			// Markdown cells, outputs and %%bash cells are not code
+			Expect(lines).To(Equal(map[string][2]int{
+				":load_orders":       {38, 42},
+				"Summary:":           {61, 61},
+				"Summary:per_region": {61, 61},
+			}))
+
+			for _, node := range result.Nodes {
+				if node.MethodName == "load_orders" {
+					Expect(node.CyclomaticComplexity).To(Equal(2))
+				}
+			}
+		})
+
+		It("should report imports and environment variables on their notebook lines", func() {
+			imports := make(map[int]string)
+			for _, lib := range result.Libraries {
+				imports[lib.LineNo] = lib.Text
+			}
+			Expect(imports).To(HaveLen(3))
+			Expect(imports[19]).To(HavePrefix("import os "))
+			Expect(imports[20]).To(HavePrefix("import pandas "))
+			Expect(imports[21]).To(HavePrefix("import billing.client.fetch_orders "))
+
+			var envVars []string
+			for _, rel := range result.Relationships {
+				if rel.RelationshipType == models.RelationshipTypeEnvVar {
+					Expect(rel.LineNo).To(Equal(39))
+					envVars = append(envVars, rel.Text)
+				}
+			}
+			Expect(envVars).To(Equal([]string{"ORDERS_URL"}))
+		})
+	})
 })
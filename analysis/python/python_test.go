@@ -0,0 +1,77 @@
+package python
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("PythonDependencyScanner", func() {
+	scan := func(file string) []string {
+		path := filepath.Join("testdata", "deps", file)
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		deps, err := NewPythonDependencyScanner().ScanFile(models.NewScanContext(nil, "."), path, content)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, dep := range deps {
+			Expect(dep.Type).To(Equal(models.DependencyTypePip))
+			Expect(dep.Indirect).To(Equal(dep.Depth > 0))
+			lines = append(lines, fmt.Sprintf("%d %s@%s %s %s", dep.Depth, dep.Name, dep.Version, dep.Git, dep.Source))
+		}
+		return lines
+	}
+
+	It("should scan requirements files with extras, markers and Git URLs", func() {
+		Expect(scan("requirements/requirements-dev.txt")).To(Equal([]string{
+			"0 Django@4.2.7  requirements-dev.txt:5",
+			"0 attrs@v23.1.0 https://github.com/python-attrs/attrs requirements-dev.txt:9",
+			"0 black@23.11.0 https://github.com/psf/black requirements-dev.txt:7",
+			"0 click@8.1.7 https://github.com/pallets/click requirements-dev.txt:8",
+			"0 numpy@  requirements-dev.txt:10",
+			"0 pytest@7.4  requirements-dev.txt:6",
+			"0 requests@2.28.0  requirements-dev.txt:4",
+		}))
+	})
+
+	It("should scan PEP 621 pyproject.toml", func() {
+		Expect(scan("pep621/pyproject.toml")).To(Equal([]string{
+			"0 httpx@0.25  pyproject.toml:5",
+			"0 pytest@7  pyproject.toml:11",
+			"0 rich@13.7.0  pyproject.toml:6",
+			"0 typer@0.9.0 https://github.com/tiangolo/typer pyproject.toml:7",
+		}))
+	})
+
+	It("should skip manifests next to a lockfile", func() {
+		Expect(scan("poetry/pyproject.toml")).To(BeEmpty())
+		Expect(scan("pipenv/Pipfile")).To(BeEmpty())
+	})
+
+	It("should scan poetry.lock using the roots of pyproject.toml", func() {
+		Expect(scan("poetry/poetry.lock")).To(Equal([]string{
+			"0 pytest@7.4.3  poetry.lock:16",
+			"0 requests@2.31.0  poetry.lock:26",
+			"0 tomli@c8a4ab5b34b3e5c4d4f7e3a1a7d1c9a3b2f1e0d9 https://github.com/hukkin/tomli poetry.lock:37",
+			"1 certifi@2023.11.17  poetry.lock:2",
+			"1 iniconfig@2.0.0  poetry.lock:9",
+			"1 urllib3@2.1.0  poetry.lock:51",
+		}))
+	})
+
+	It("should scan Pipfile.lock, treating packages missing from the Pipfile as transitive", func() {
+		Expect(scan("pipenv/Pipfile.lock")).To(Equal([]string{
+			"0 flask@3.0.0  Pipfile.lock:17",
+			"0 my-lib@4e2b7c1d9a8f6e5d4c3b2a1f0e9d8c7b6a5f4e3d https://github.com/example/my-lib Pipfile.lock:24",
+			"0 pytest@7.4.3  Pipfile.lock:37",
+			"1 werkzeug@3.0.1  Pipfile.lock:28",
+		}))
+	})
+})
@@ -0,0 +1,19 @@
+import requests
+
+BILLING_URL = "http://billing.svc:8080"
+
+
+class BillingClient:
+    def __init__(self, session):
+        self.session = session
+
+    def invoice(self, invoice_id):
+        return self.session.get(f"{BILLING_URL}/invoices/{invoice_id}").json()
+
+    def pay(self, invoice_id, amount):
+        return self.session.request("POST", BILLING_URL + "/invoices/" + invoice_id + "/payments", json=amount)
+
+
+def health(config):
+    config.get("timeout")
+    return requests.head("http://billing.svc/healthz").ok
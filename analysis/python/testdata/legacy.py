@@ -0,0 +1,26 @@
+import warnings
+from typing_extensions import deprecated
+
+
+@warnings.deprecated("Use fetch_v2 instead")
+def fetch(url):
+    return url
+
+
+@deprecated
+def load():
+    return None
+
+
+@staticmethod
+def save():
+    return None
+
+
+class Client:
+    @deprecated("Use close")
+    def disconnect(self):
+        pass
+
+    def close(self):
+        pass
@@ -0,0 +1,20 @@
+from sqlalchemy import text
+
+
+class UserRepository:
+    def __init__(self, session, cursor):
+        self.session = session
+        self.cursor = cursor
+
+    def find(self, user_id):
+        return self.session.execute(text("SELECT id, name FROM users WHERE id = :id"), {"id": user_id})
+
+    def archive(self, user_id):
+        self.cursor.execute(
+            "INSERT INTO archived_users SELECT * FROM users WHERE id = %s", (user_id,)
+        )
+
+
+def count_orders(cursor):
+    cursor.execute("not sql")
+    return cursor.execute("SELECT count(*) FROM orders").fetchone()
@@ -0,0 +1,19 @@
+import os
+from os import environ, getenv
+
+PORT_VAR = "APP_PORT"
+
+
+class Settings:
+    def __init__(self):
+        self.database_url = os.environ["DATABASE_URL"]
+        self.port = int(os.getenv(PORT_VAR, "8080"))
+        self.debug = environ.get("DEBUG") == "1"
+
+    def configure(self, config):
+        config.get("timeout")
+        os.environ.setdefault("TZ", "UTC")
+
+
+def log_level(name):
+    return getenv("LOG_LEVEL") or os.environ.get(name)
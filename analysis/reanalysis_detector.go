@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/flanksource/arch-unit/internal/offline"
 )
 
 // ReanalysisDetector determines when sources need to be reanalyzed
@@ -106,6 +108,16 @@ func (r *ReanalysisDetector) checkFileChange(filePath string) (bool, error) {
 
 // checkURLChange checks if content at a URL has changed
 func (r *ReanalysisDetector) checkURLChange(url string) (bool, error) {
+	// Offline, a previously analyzed URL is treated as unchanged so its cached results are reused
+	if offline.Enabled() {
+		r.mutex.RLock()
+		_, exists := r.hashCache[url]
+		r.mutex.RUnlock()
+		if exists {
+			return false, nil
+		}
+	}
+
 	// Calculate current content hash from URL
 	currentHash, err := r.calculateURLContentHash(url)
 	if err != nil {
@@ -233,6 +245,10 @@ func (r *ReanalysisDetector) calculateFileHash(filePath string) (string, error)
 
 // calculateURLContentHash calculates hash of content from a URL
 func (r *ReanalysisDetector) calculateURLContentHash(url string) (string, error) {
+	if err := offline.Check("checking " + url + " for changes"); err != nil {
+		return "", err
+	}
+
 	// Make HTTP request
 	resp, err := r.httpClient.Get(url)
 	if err != nil {
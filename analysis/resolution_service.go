@@ -2,14 +2,17 @@ package analysis
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/offline"
 	"github.com/flanksource/arch-unit/models"
 	"golang.org/x/time/rate"
 )
@@ -23,10 +26,10 @@ type ResolutionService struct {
 }
 
 var (
-	resolutionServiceInstance *ResolutionService
-	resolutionServiceOnce     sync.Once
-	resolutionServiceMutex    sync.RWMutex
-	resolutionServiceTTL      time.Duration = 24 * time.Hour
+	// resolutionServiceInstances are the resolution services of each project root
+	resolutionServiceInstances = map[string]*ResolutionService{}
+	resolutionServiceMutex     sync.RWMutex
+	resolutionServiceTTL       time.Duration = 24 * time.Hour
 )
 
 // NewResolutionService creates a new resolution service with default 24-hour cache TTL
@@ -34,10 +37,16 @@ func NewResolutionService() *ResolutionService {
 	return NewResolutionServiceWithTTL(24 * time.Hour)
 }
 
-// NewResolutionServiceWithTTL creates a new resolution service with configurable cache TTL
+// NewResolutionServiceWithTTL creates a new resolution service with configurable cache TTL, caching
+// in the AST cache of the project in the working directory
 func NewResolutionServiceWithTTL(cacheTTL time.Duration) *ResolutionService {
+	return NewResolutionServiceWithCache(cache.MustGetProjectASTCache("."), cacheTTL)
+}
+
+// NewResolutionServiceWithCache creates a new resolution service caching in astCache
+func NewResolutionServiceWithCache(astCache *cache.ASTCache, cacheTTL time.Duration) *ResolutionService {
 	return &ResolutionService{
-		cache: cache.MustGetASTCache(),
+		cache: astCache,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -46,13 +55,21 @@ func NewResolutionServiceWithTTL(cacheTTL time.Duration) *ResolutionService {
 	}
 }
 
-// GetResolutionService returns the global resolution service singleton
-func GetResolutionService() (*ResolutionService, error) {
-	var err error
-	resolutionServiceOnce.Do(func() {
-		resolutionServiceInstance = NewResolutionServiceWithTTL(resolutionServiceTTL)
-	})
-	return resolutionServiceInstance, err
+// GetResolutionService returns the resolution service of the project at root, caching in its AST
+// cache
+func GetResolutionService(root string) (*ResolutionService, error) {
+	resolutionServiceMutex.Lock()
+	defer resolutionServiceMutex.Unlock()
+	if resolver, ok := resolutionServiceInstances[root]; ok {
+		return resolver, nil
+	}
+	astCache, err := cache.GetProjectASTCache(root)
+	if err != nil {
+		return nil, err
+	}
+	resolver := NewResolutionServiceWithCache(astCache, resolutionServiceTTL)
+	resolutionServiceInstances[root] = resolver
+	return resolver, nil
 }
 
 // SetResolutionServiceTTL configures the resolution service TTL (must be called before first use)
@@ -66,8 +83,7 @@ func SetResolutionServiceTTL(ttl time.Duration) {
 func ResetResolutionService() {
 	resolutionServiceMutex.Lock()
 	defer resolutionServiceMutex.Unlock()
-	resolutionServiceInstance = nil
-	resolutionServiceOnce = sync.Once{}
+	resolutionServiceInstances = map[string]*ResolutionService{}
 }
 
 // ResolveGitURL attempts to resolve a Git URL for the given package
@@ -80,6 +96,16 @@ func (r *ResolutionService) ResolveGitURL(ctx *models.ScanContext, packageName,
 		}
 	}
 
+	// In offline mode only cached aliases can be used, regardless of their age
+	if offline.Enabled() {
+		if r.cache != nil {
+			if cached, err := r.getCachedAlias(packageName, packageType); err == nil && cached != nil {
+				return cached.GitURL, nil
+			}
+		}
+		return "", offline.Check(fmt.Sprintf("resolving Git URL for %s/%s", packageType, packageName))
+	}
+
 	// Try to resolve Git URL using heuristics
 	gitURL, err := r.extractGitURL(ctx, packageName, packageType)
 	if err != nil {
@@ -122,6 +148,10 @@ func (r *ResolutionService) extractGitURL(ctx *models.ScanContext, packageName,
 		return r.extractDockerGitURL(ctx, packageName)
 	case "helm":
 		return r.extractHelmGitURL(ctx, packageName)
+	case "terraform":
+		return r.extractTerraformGitURL(ctx, packageName)
+	case "bazel":
+		return r.extractBazelGitURL(ctx, packageName)
 	default:
 		return "", nil // Unknown package type
 	}
@@ -151,6 +181,11 @@ func (r *ResolutionService) extractGoGitURL(ctx *models.ScanContext, packageName
 		return r.extractGopkgGitURL(ctx, packageName)
 	}
 
+	// GitHub Enterprise and self-managed GitLab hosts configured in registries
+	if parts := strings.Split(packageName, "/"); len(parts) >= 3 && GetCredentials().GitHost(parts[0]) != "" {
+		return "https://" + strings.Join(parts[:3], "/"), nil
+	}
+
 	return "", nil // Cannot determine Git URL for this package
 }
 
@@ -182,18 +217,262 @@ func (r *ResolutionService) extractGopkgGitURL(ctx *models.ScanContext, packageN
 	return "", nil
 }
 
-// extractNpmGitURL extracts Git URLs for NPM packages (placeholder)
+// NpmRegistry is the registry queried for the repository of npm packages
+var NpmRegistry = "https://registry.npmjs.org"
+
+// extractNpmGitURL extracts Git URLs for NPM packages from the repository field of their
+// registry metadata, queried from the registry configured for their scope
 func (r *ResolutionService) extractNpmGitURL(ctx *models.ScanContext, packageName string) (string, error) {
-	// This would typically involve calling the NPM registry API
-	// For now, return empty as we focus on Go packages first
-	return "", nil
+	if err := r.rateLimiter.Wait(context.Background()); err != nil {
+		return "", err
+	}
+
+	// Scoped packages keep the @ but escape the slash, e.g. @babel%2Fcore
+	registry := GetCredentials().NpmRegistry(packageName)
+	resp, err := r.get(registry + "/" + strings.Replace(packageName, "/", "%2F", 1))
+	if err != nil {
+		return "", nil // Network error = unknown
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	var metadata struct {
+		Repository json.RawMessage `json:"repository"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", fmt.Errorf("invalid npm metadata for %s: %w", packageName, err)
+	}
+
+	// The repository is either a URL or an object with a url
+	var repository struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(metadata.Repository, &repository.URL); err != nil {
+		_ = json.Unmarshal(metadata.Repository, &repository)
+	}
+	gitURL, _ := NpmGitURL(repository.URL)
+	if gitURL == "" && strings.HasPrefix(repository.URL, "https://") {
+		// Repository fields may link the project page, which is not a tarball as in versions
+		gitURL = strings.TrimSuffix(repository.URL, "/")
+	}
+	return gitURL, nil
 }
 
-// extractPythonGitURL extracts Git URLs for Python packages (placeholder)
+var npmShorthand = regexp.MustCompile(`^(?:(github|gitlab|bitbucket):)?([\w.-]+)/([\w.-]+?)(?:\.git)?(?:#(.*))?$`)
+
+// NpmGitURL returns the https URL and ref of a Git repository in the forms npm accepts for
+// repository fields and dependency versions, e.g. git+ssh://git@github.com/user/repo.git#v1,
+// github:user/repo or user/repo. It returns empty strings when spec is not a Git repository.
+func NpmGitURL(spec string) (string, string) {
+	if m := npmShorthand.FindStringSubmatch(spec); m != nil && !strings.HasPrefix(spec, ".") {
+		host := map[string]string{"": "github.com", "github": "github.com", "gitlab": "gitlab.com", "bitbucket": "bitbucket.org"}[m[1]]
+		return fmt.Sprintf("https://%s/%s/%s", host, m[2], m[3]), m[4]
+	}
+
+	url, ref, _ := strings.Cut(spec, "#")
+	url, ok := strings.CutPrefix(url, "git+")
+	if !ok && !strings.HasPrefix(url, "git://") && !strings.HasPrefix(url, "ssh://") && !strings.HasPrefix(url, "git@") &&
+		!(strings.HasPrefix(url, "https://") && strings.HasSuffix(url, ".git")) {
+		return "", ""
+	}
+
+	if _, rest, found := strings.Cut(url, "://"); found {
+		// ssh://git@host:port/path
+		if at := strings.Index(rest, "@"); at >= 0 && at < strings.Index(rest, "/") {
+			rest = rest[at+1:]
+		}
+		host, path, _ := strings.Cut(rest, "/")
+		host, _, _ = strings.Cut(host, ":")
+		url = host + "/" + path
+	} else {
+		// git@host:path
+		_, rest, _ := strings.Cut(url, "@")
+		url = strings.Replace(rest, ":", "/", 1)
+	}
+	return "https://" + strings.TrimSuffix(url, ".git"), ref
+}
+
+// PyPIRegistry is the index queried for the project URLs of Python packages
+var PyPIRegistry = "https://pypi.org/pypi"
+
+// extractPythonGitURL extracts Git URLs for Python packages from the project URLs of their
+// PyPI metadata
 func (r *ResolutionService) extractPythonGitURL(ctx *models.ScanContext, packageName string) (string, error) {
-	// This would typically involve calling PyPI API
-	// For now, return empty as we focus on Go packages first
-	return "", nil
+	if err := r.rateLimiter.Wait(context.Background()); err != nil {
+		return "", err
+	}
+
+	resp, err := r.get(PyPIRegistry + "/" + packageName + "/json")
+	if err != nil {
+		return "", nil // Network error = unknown
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	var metadata struct {
+		Info struct {
+			HomePage    string            `json:"home_page"`
+			ProjectURLs map[string]string `json:"project_urls"`
+		} `json:"info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", fmt.Errorf("invalid PyPI metadata for %s: %w", packageName, err)
+	}
+	return PyPIGitURL(metadata.Info.ProjectURLs, metadata.Info.HomePage), nil
+}
+
+// pypiSourceLabels are the project URL labels most likely to link the repository, in order
+var pypiSourceLabels = []string{"source", "sourcecode", "repository", "code", "github", "gitlab", "homepage", "home"}
+
+// PyPIGitURL returns the repository linked by the project URLs or home page of a PyPI package,
+// preferring URLs labelled as the source code. It returns an empty string when none of them is
+// a Git repository.
+func PyPIGitURL(projectURLs map[string]string, homePage string) string {
+	labels := make([]string, 0, len(projectURLs))
+	for label := range projectURLs {
+		labels = append(labels, label)
+	}
+	rank := func(label string) int {
+		normalized := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' {
+				return r
+			}
+			return -1
+		}, strings.ToLower(label))
+		for i, known := range pypiSourceLabels {
+			if normalized == known {
+				return i
+			}
+		}
+		return len(pypiSourceLabels)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if rank(labels[i]) != rank(labels[j]) {
+			return rank(labels[i]) < rank(labels[j])
+		}
+		return labels[i] < labels[j]
+	})
+
+	urls := make([]string, 0, len(labels)+1)
+	for _, label := range labels {
+		urls = append(urls, projectURLs[label])
+	}
+	for _, url := range append(urls, homePage) {
+		if gitURL := repositoryURL(url); gitURL != "" {
+			return gitURL
+		}
+	}
+	return ""
+}
+
+// repositoryURL returns the https URL of the repository a link points into, e.g.
+// https://github.com/user/repo for https://github.com/user/repo/tree/main/docs
+func repositoryURL(link string) string {
+	_, rest, ok := strings.Cut(strings.TrimSpace(link), "://")
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	host := strings.TrimPrefix(strings.ToLower(parts[0]), "www.")
+	switch host {
+	case "github.com", "gitlab.com", "bitbucket.org", "codeberg.org":
+	default:
+		if GetCredentials().GitHost(host) == "" {
+			// Other hosts are only known to serve a repository when linked as one
+			gitURL, _ := NpmGitURL(link)
+			return gitURL
+		}
+	}
+	if len(parts) < 3 || parts[1] == "" || parts[2] == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/%s/%s", host, parts[1], strings.TrimSuffix(parts[2], ".git"))
+}
+
+// TerraformRegistry is the registry queried for the source repositories of providers and modules
+var TerraformRegistry = "https://registry.terraform.io"
+
+// extractTerraformGitURL extracts Git URLs for Terraform providers, namespace/type, and registry
+// modules, namespace/name/provider. Modules published on the registry live in GitHub repositories
+// named terraform-<provider>-<name>, which is used when the registry cannot be reached.
+func (r *ResolutionService) extractTerraformGitURL(ctx *models.ScanContext, packageName string) (string, error) {
+	parts := strings.Split(packageName, "/")
+	var path, fallback string
+	switch len(parts) {
+	case 2:
+		path = "/v1/providers/" + packageName
+		fallback = fmt.Sprintf("https://github.com/%s/terraform-provider-%s", parts[0], parts[1])
+	case 3:
+		path = "/v1/modules/" + packageName
+		fallback = fmt.Sprintf("https://github.com/%s/terraform-%s-%s", parts[0], parts[2], parts[1])
+	default:
+		return "", nil // Private registries are not resolved
+	}
+
+	if err := r.rateLimiter.Wait(context.Background()); err != nil {
+		return "", err
+	}
+
+	resp, err := r.get(TerraformRegistry + path)
+	if err != nil {
+		return fallback, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fallback, nil
+	}
+
+	var metadata struct {
+		Source string `json:"source"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", fmt.Errorf("invalid Terraform registry metadata for %s: %w", packageName, err)
+	}
+	if gitURL := repositoryURL(metadata.Source); gitURL != "" {
+		return gitURL, nil
+	}
+	return fallback, nil
+}
+
+// BazelRegistry is the registry queried for the source repositories of Bazel modules
+var BazelRegistry = "https://bcr.bazel.build"
+
+// extractBazelGitURL extracts Git URLs for Bazel modules from the repository, e.g.
+// github:bazelbuild/rules_go, or the homepage of their registry metadata
+func (r *ResolutionService) extractBazelGitURL(ctx *models.ScanContext, packageName string) (string, error) {
+	if err := r.rateLimiter.Wait(context.Background()); err != nil {
+		return "", err
+	}
+
+	resp, err := r.get(BazelRegistry + "/modules/" + packageName + "/metadata.json")
+	if err != nil {
+		return "", nil // Network error = unknown
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	var metadata struct {
+		Homepage   string   `json:"homepage"`
+		Repository []string `json:"repository"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", fmt.Errorf("invalid Bazel registry metadata for %s: %w", packageName, err)
+	}
+	for _, repository := range metadata.Repository {
+		if repo, ok := strings.CutPrefix(repository, "github:"); ok {
+			return "https://github.com/" + repo, nil
+		}
+		if gitURL := repositoryURL(repository); gitURL != "" {
+			return gitURL, nil
+		}
+	}
+	return repositoryURL(metadata.Homepage), nil
 }
 
 // extractDockerGitURL extracts Git URLs for Docker images
@@ -320,6 +599,10 @@ func (r *ResolutionService) ValidateGitURL(ctx *models.ScanContext, gitURL strin
 
 // ValidateGitURL checks if a Git URL is accessible and returns the final URL after redirects
 func (r *ResolutionService) validateGitURL(gitURL string) (bool, string, error) {
+	if err := offline.Check("Git URL validation"); err != nil {
+		return false, gitURL, err
+	}
+
 	// Rate limit validation requests
 	ctx := context.Background()
 	if err := r.rateLimiter.Wait(ctx); err != nil {
@@ -330,6 +613,11 @@ func (r *ResolutionService) validateGitURL(gitURL string) (bool, string, error)
 	validationURL := r.normalizeGitURL(gitURL)
 	finalURL := validationURL
 
+	// Private repositories of GitHub and GitLab hosts are validated through their API
+	if apiReq, field := GetCredentials().repositoryAPI(validationURL); apiReq != nil {
+		return r.validateRepositoryAPI(gitURL, apiReq, field)
+	}
+
 	// Create a custom HTTP client that tracks redirects
 	client := &http.Client{
 		Timeout: r.httpClient.Timeout,
@@ -348,6 +636,7 @@ func (r *ResolutionService) validateGitURL(gitURL string) (bool, string, error)
 	if err != nil {
 		return false, gitURL, err
 	}
+	GetCredentials().Authorize(req)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -379,6 +668,43 @@ func (r *ResolutionService) validateGitURL(gitURL string) (bool, string, error)
 	return false, gitURL, nil
 }
 
+// validateRepositoryAPI checks that the API of a Git host describes the repository of a Git URL,
+// returning the web URL of the repository from field of the response, which differs from gitURL
+// for renamed or transferred repositories
+func (r *ResolutionService) validateRepositoryAPI(gitURL string, req *http.Request, field string) (bool, string, error) {
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return false, gitURL, nil // Network error = invalid
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return false, gitURL, nil
+	}
+
+	var repository map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+		return false, gitURL, fmt.Errorf("invalid repository metadata from %s: %w", req.URL.Host, err)
+	}
+	webURL, _ := repository[field].(string)
+	if webURL == "" || strings.EqualFold(webURL, r.normalizeGitURL(gitURL)) {
+		return true, gitURL, nil
+	}
+	if strings.HasSuffix(gitURL, ".git") {
+		webURL += ".git"
+	}
+	return true, webURL, nil
+}
+
+// get sends a GET request, authorized with the credentials of the registry it is sent to
+func (r *ResolutionService) get(target string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	GetCredentials().Authorize(req)
+	return r.httpClient.Do(req)
+}
+
 // normalizeGitURL converts Git URLs to HTTP URLs suitable for validation
 func (r *ResolutionService) normalizeGitURL(gitURL string) string {
 	// Remove .git suffix for HTTP validation
@@ -52,7 +52,7 @@ var _ = Describe("ResolutionService ResolveGitURL Caching", func() {
 		// 2. Ensuring proper test setup for the global cache
 		// 3. Mocking the cache dependency
 
-		astCache := cache.MustGetASTCache()
+		astCache := cache.MustGetProjectASTCache(".")
 		resolver := NewResolutionService()
 
 		packageName := "github.com/flanksource/commons"
@@ -96,11 +96,25 @@ var _ = Describe("ResolutionService NormalizeGitURL", func() {
 	)
 })
 
+var _ = Describe("PyPIGitURL", func() {
+	DescribeTable("picking the repository of a PyPI package",
+		func(projectURLs map[string]string, homePage, expected string) {
+			Expect(PyPIGitURL(projectURLs, homePage)).To(Equal(expected))
+		},
+		Entry("preferring the source over the home page",
+			map[string]string{"Homepage": "https://github.com/psf/requests-docs", "Source": "https://github.com/psf/requests"}, "", "https://github.com/psf/requests"),
+		Entry("trimming paths into the repository",
+			map[string]string{"Documentation": "https://flask.palletsprojects.com", "Issue Tracker": "https://github.com/pallets/flask/issues/"}, "", "https://github.com/pallets/flask"),
+		Entry("falling back to the home page", map[string]string{}, "https://www.github.com/numpy/numpy.git", "https://github.com/numpy/numpy"),
+		Entry("without a repository", map[string]string{"Homepage": "https://numpy.org"}, "https://numpy.org", ""),
+	)
+})
+
 var _ = Describe("ResolutionService CachingBehavior", func() {
 	var astCache *cache.ASTCache
 
 	BeforeEach(func() {
-		astCache = cache.MustGetASTCache()
+		astCache = cache.MustGetProjectASTCache(".")
 	})
 
 	Context("Normal Caching Flow", func() {
@@ -165,3 +179,23 @@ var _ = Describe("ResolutionService CachingBehavior", func() {
 	})
 
 })
+
+var _ = Describe("ResolutionService of each project", func() {
+	It("should cache the resolutions of each project root in the AST cache of the project", func() {
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+		DeferCleanup(ResetResolutionService)
+		DeferCleanup(cache.ResetASTCache)
+		api, web := GinkgoT().TempDir(), GinkgoT().TempDir()
+
+		apiResolver, err := GetResolutionService(api)
+		Expect(err).NotTo(HaveOccurred())
+		webResolver, err := GetResolutionService(web)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(webResolver).NotTo(BeIdenticalTo(apiResolver))
+		Expect(apiResolver.cache).To(BeIdenticalTo(cache.MustGetProjectASTCache(api)))
+		Expect(webResolver.cache).To(BeIdenticalTo(cache.MustGetProjectASTCache(web)))
+		Expect(webResolver.cache).NotTo(BeIdenticalTo(apiResolver.cache))
+		Expect(GetResolutionService(api)).To(BeIdenticalTo(apiResolver))
+	})
+})
@@ -39,9 +39,10 @@ func (r *RuleViolationAnalyzer) AnalyzeViolations(astResult *types.ASTResult, ru
 		}
 	}
 
-	// Also check library relationships (external package calls like fmt.Println)
+	// Also check library relationships (external package calls like fmt.Println, or references
+	// like unsafe.Pointer)
 	for _, libRel := range astResult.Libraries {
-		if libRel.RelationshipType == models.RelationshipCall {
+		if libRel.RelationshipType == models.RelationshipCall || libRel.RelationshipType == models.RelationshipReference {
 			if violation := r.checkLibraryCallRelationship(astResult, libRel, rules); violation != nil {
 				violations = append(violations, *violation)
 			}
@@ -133,6 +134,7 @@ func (r *RuleViolationAnalyzer) checkCallRelationship(astResult *types.ASTResult
 			Rule:     rule,
 			Message:  models.StringPtr(violationMsg),
 			Source:   "arch-unit",
+			Severity: rule.Severity,
 		}
 
 		// Set CalledID if we have the called node
@@ -191,18 +193,23 @@ func (r *RuleViolationAnalyzer) checkLibraryCallRelationship(astResult *types.AS
 	// Check if the call is allowed by the rules
 	allowed, rule := rules.IsAllowedForFile(pkgName, methodName, astResult.FilePath)
 	if !allowed {
-		violationMsg := fmt.Sprintf("Call to %s.%s violates architecture rule", pkgName, methodName)
+		usage := "Call to"
+		if libRel.RelationshipType == models.RelationshipReference {
+			usage = "Use of"
+		}
+		violationMsg := fmt.Sprintf("%s %s.%s violates architecture rule", usage, pkgName, methodName)
 		if rule.FilePattern != "" {
-			violationMsg = fmt.Sprintf("Call to %s.%s violates file-specific rule [%s]", pkgName, methodName, rule.FilePattern)
+			violationMsg = fmt.Sprintf("%s %s.%s violates file-specific rule [%s]", usage, pkgName, methodName, rule.FilePattern)
 		}
 
 		violation := &models.Violation{
-			File:    astResult.FilePath,
-			Line:    libRel.LineNo,
-			Column:  0,
-			Rule:    rule,
-			Message: models.StringPtr(violationMsg),
-			Source:  "arch-unit",
+			File:     astResult.FilePath,
+			Line:     libRel.LineNo,
+			Column:   0,
+			Rule:     rule,
+			Message:  models.StringPtr(violationMsg),
+			Source:   "arch-unit",
+			Severity: rule.Severity,
 		}
 
 		return violation
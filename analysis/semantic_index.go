@@ -0,0 +1,157 @@
+package analysis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/llm"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// embeddingBatchSize is the number of texts embedded per request
+const embeddingBatchSize = 64
+
+// SemanticIndex searches nodes by meaning with the embeddings of their names, signatures and
+// summaries, stored in the AST cache
+type SemanticIndex struct {
+	cache    *cache.ASTCache
+	embedder llm.Embedder
+}
+
+// NewSemanticIndex creates an index of the nodes of astCache embedded by embedder
+func NewSemanticIndex(astCache *cache.ASTCache, embedder llm.Embedder) *SemanticIndex {
+	return &SemanticIndex{cache: astCache, embedder: embedder}
+}
+
+// EmbeddingText returns the text embedded for a node: its kind, name, signature and summary
+func EmbeddingText(node *models.ASTNode) string {
+	var text strings.Builder
+	text.WriteString(string(node.NodeType))
+	text.WriteString(" ")
+	text.WriteString(node.String())
+	if len(node.Parameters) > 0 || len(node.ReturnValues) > 0 {
+		var params, returns []string
+		for _, param := range node.Parameters {
+			params = append(params, strings.TrimSpace(param.Name+" "+param.Type))
+		}
+		for _, ret := range node.ReturnValues {
+			returns = append(returns, strings.TrimSpace(ret.Name+" "+ret.Type))
+		}
+		fmt.Fprintf(&text, "(%s) %s", strings.Join(params, ", "), strings.Join(returns, ", "))
+	}
+	if node.FieldType != nil && *node.FieldType != "" {
+		text.WriteString(" ")
+		text.WriteString(*node.FieldType)
+	}
+	if node.Summary != nil && *node.Summary != "" {
+		text.WriteString("\n")
+		text.WriteString(*node.Summary)
+	}
+	return text.String()
+}
+
+func textHash(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(hash[:8])
+}
+
+// Update embeds the nodes that are new or changed since they were last embedded, and returns
+// how many were embedded
+func (i *SemanticIndex) Update(ctx context.Context, nodes []*models.ASTNode) (int, error) {
+	model := i.embedder.Name()
+	if pruned, err := i.cache.PruneEmbeddings(model); err != nil {
+		return 0, err
+	} else if pruned > 0 {
+		logger.Debugf("Pruned %d embeddings of removed nodes or other models", pruned)
+	}
+	existing, err := i.cache.Embeddings(model)
+	if err != nil {
+		return 0, err
+	}
+
+	var stale []*models.ASTNode
+	var texts []string
+	for _, node := range nodes {
+		text := EmbeddingText(node)
+		if embedding, ok := existing[node.ID]; ok && embedding.TextHash == textHash(text) {
+			continue
+		}
+		stale = append(stale, node)
+		texts = append(texts, text)
+	}
+
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+		vectors, err := i.embedder.Embed(ctx, texts[start:end])
+		if err != nil {
+			return start, fmt.Errorf("failed to embed nodes with %s: %w", model, err)
+		}
+		embeddings := make([]models.NodeEmbedding, 0, end-start)
+		for j, vector := range vectors {
+			embeddings = append(embeddings, models.NodeEmbedding{
+				NodeID:   stale[start+j].ID,
+				Model:    model,
+				TextHash: textHash(texts[start+j]),
+				Vector:   vector,
+			})
+		}
+		if err := i.cache.StoreEmbeddings(embeddings); err != nil {
+			return start, err
+		}
+	}
+	return len(texts), nil
+}
+
+// Search returns the nodes most similar to the query, best first. Only nodes embedded by Update
+// are searched.
+func (i *SemanticIndex) Search(ctx context.Context, query string, nodes []*models.ASTNode, limit int) ([]*models.SearchResult, error) {
+	vectors, err := i.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed the query with %s: %w", i.embedder.Name(), err)
+	}
+	embeddings, err := i.cache.Embeddings(i.embedder.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	queryVector := models.Vector(vectors[0])
+	var results []*models.SearchResult
+	for _, node := range nodes {
+		embedding, ok := embeddings[node.ID]
+		if !ok {
+			continue
+		}
+		score := queryVector.Cosine(embedding.Vector)
+		if score <= 0 {
+			continue
+		}
+		result := &models.SearchResult{
+			Score:    score,
+			Name:     node.String(),
+			Kind:     node.NodeType,
+			Location: fmt.Sprintf("%s:%d", node.FilePath, node.StartLine),
+			Node:     node,
+		}
+		if node.Summary != nil {
+			result.Summary = *node.Summary
+		}
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		return results[a].Score > results[b].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	for _, result := range results {
+		result.Score = math.Round(result.Score*100) / 100
+	}
+	return results, nil
+}
@@ -0,0 +1,66 @@
+package analysis_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/llm"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("SemanticIndex", func() {
+	var astCache *cache.ASTCache
+	var index *analysis.SemanticIndex
+	var nodes []*models.ASTNode
+
+	BeforeEach(func() {
+		var err error
+		astCache, err = cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(astCache.Close)
+
+		embedder, err := llm.NewEmbedder(llm.Config{Provider: llm.ProviderLocal})
+		Expect(err).ToNot(HaveOccurred())
+		index = analysis.NewSemanticIndex(astCache, embedder)
+
+		summary := "Sends the welcome email to new users"
+		nodes = []*models.ASTNode{
+			{FilePath: "/src/auth/hash.go", PackageName: "auth", MethodName: "HashPassword", NodeType: models.NodeTypeMethod, StartLine: 12},
+			{FilePath: "/src/mail/welcome.go", PackageName: "mail", MethodName: "Welcome", NodeType: models.NodeTypeMethod, StartLine: 3, Summary: &summary},
+			{FilePath: "/src/auth/user.go", PackageName: "auth", TypeName: "User", NodeType: models.NodeTypeType, StartLine: 5},
+		}
+		for _, node := range nodes {
+			_, err := astCache.StoreASTNode(node)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("should rank nodes by similarity to the query", func() {
+		Expect(index.Update(context.Background(), nodes)).To(Equal(3))
+
+		results, err := index.Search(context.Background(), "where do we hash passwords", nodes, 10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).ToNot(BeEmpty())
+		Expect(results[0].Name).To(ContainSubstring("HashPassword"))
+		Expect(results[0].Location).To(Equal("/src/auth/hash.go:12"))
+
+		// Summaries are embedded with the names
+		results, err = index.Search(context.Background(), "welcome emails", nodes, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].Summary).To(Equal("Sends the welcome email to new users"))
+	})
+
+	It("should only embed new or changed nodes", func() {
+		Expect(index.Update(context.Background(), nodes)).To(Equal(3))
+		Expect(index.Update(context.Background(), nodes)).To(Equal(0))
+
+		summary := "Hashes passwords with bcrypt"
+		nodes[0].Summary = &summary
+		Expect(index.Update(context.Background(), nodes)).To(Equal(1))
+	})
+})
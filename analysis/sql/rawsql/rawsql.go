@@ -0,0 +1,286 @@
+package rawsql
+
+import (
+	"strings"
+)
+
+// Query is a raw SQL statement embedded in application code
+type Query struct {
+	Operation string   // SELECT, INSERT, UPDATE, DELETE, ...
+	Tables    []string // tables read or written, schema qualified when the query qualifies them
+	// SelectsAll is true when a select list includes every column, e.g. SELECT * or SELECT u.*
+	SelectsAll bool
+}
+
+// operations are the keywords a SQL statement starts with
+var operations = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "REPLACE": true, "UPSERT": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "WITH": true,
+}
+
+// tableKeywords are followed by the name of a table
+var tableKeywords = map[string]bool{
+	"FROM": true, "JOIN": true, "INTO": true, "UPDATE": true, "TABLE": true, "TRUNCATE": true, "USING": true,
+}
+
+// keywords can not be table names or aliases
+var keywords = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "BETWEEN": true, "BY": true, "CASE": true, "CONFLICT": true, "CROSS": true,
+	"DEFAULT": true, "DELETE": true, "DO": true, "ELSE": true, "END": true, "EXCEPT": true, "EXISTS": true, "FETCH": true,
+	"FOR": true, "FROM": true, "FULL": true, "GROUP": true, "HAVING": true, "IF": true, "IN": true, "INNER": true,
+	"INSERT": true, "INTERSECT": true, "INTO": true, "IS": true, "JOIN": true, "LATERAL": true, "LEFT": true,
+	"LIKE": true, "LIMIT": true, "LOCKED": true, "NATURAL": true, "NOT": true, "NOTHING": true, "NOWAIT": true,
+	"NULL": true, "OF": true, "OFFSET": true, "ON": true, "ONLY": true, "OR": true, "ORDER": true, "OUTER": true,
+	"RETURNING": true, "RIGHT": true, "SELECT": true, "SET": true, "SHARE": true, "SKIP": true, "TABLE": true,
+	"THEN": true, "UNION": true, "UPDATE": true, "USING": true, "VALUE": true, "VALUES": true, "WHEN": true,
+	"WHERE": true, "WINDOW": true, "WITH": true,
+}
+
+// modifiers may appear between a table keyword and the table name
+var modifiers = map[string]bool{"ONLY": true, "LATERAL": true, "IF": true, "NOT": true, "EXISTS": true}
+
+type token struct {
+	text string
+	word bool // identifier or keyword, otherwise punctuation or a literal
+}
+
+func (t token) keyword() string {
+	if !t.word {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+// Parse returns the operation and tables of a SQL statement, or false when text is not SQL.
+// Tables defined by the statement's WITH clause and set returning functions are not listed.
+func Parse(text string) (*Query, bool) {
+	tokens := tokenize(text)
+	if len(tokens) < 2 || !operations[tokens[0].keyword()] {
+		return nil, false
+	}
+
+	query := &Query{}
+	ctes := make(map[string]bool)
+	seen := make(map[string]bool)
+	// calls records for each open parenthesis whether it holds function arguments, as in
+	// EXTRACT(YEAR FROM created_at), rather than a subquery
+	var calls []bool
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch tok.text {
+		case "(":
+			calls = append(calls, i > 0 && tokens[i-1].word && !keywords[tokens[i-1].keyword()])
+			continue
+		case ")":
+			if len(calls) > 0 {
+				calls = calls[:len(calls)-1]
+			}
+			continue
+		}
+
+		kw := tok.keyword()
+		if kw == "" {
+			continue
+		}
+		// A common table expression: name AS (...)
+		if i+2 < len(tokens) && tokens[i+1].keyword() == "AS" && tokens[i+2].text == "(" {
+			ctes[strings.ToLower(tok.text)] = true
+			continue
+		}
+		if query.Operation == "" && len(calls) == 0 && operations[kw] && kw != "WITH" {
+			query.Operation = kw
+		}
+		if !tableKeywords[kw] || (len(calls) > 0 && calls[len(calls)-1]) {
+			continue
+		}
+
+		j := i + 1
+		for j < len(tokens) && modifiers[tokens[j].keyword()] {
+			j++
+		}
+		for j < len(tokens) && tokens[j].word && !keywords[tokens[j].keyword()] {
+			name := tokens[j].text
+			j++
+			// Set returning functions such as generate_series(1, 10)
+			if kw != "INTO" && kw != "TABLE" && j < len(tokens) && tokens[j].text == "(" {
+				break
+			}
+			if key := strings.ToLower(name); !ctes[key] && !seen[key] {
+				seen[key] = true
+				query.Tables = append(query.Tables, name)
+			}
+
+			// Skip the alias, then continue with the next table of a FROM list
+			if j < len(tokens) && tokens[j].keyword() == "AS" {
+				j += 2
+			} else if j < len(tokens) && tokens[j].word && !keywords[tokens[j].keyword()] {
+				j++
+			}
+			if kw != "FROM" || j >= len(tokens) || tokens[j].text != "," {
+				break
+			}
+			j++
+		}
+		i = j - 1
+	}
+
+	if query.Operation == "" {
+		return nil, false
+	}
+	query.SelectsAll = selectsAll(tokens)
+	return query, true
+}
+
+// Normalize collapses the whitespace of a SQL statement onto a single line
+func Normalize(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// tokenize splits SQL into identifiers, keywords and punctuation, skipping comments. String
+// literals become a single non-word token and quoted identifiers are unquoted.
+func tokenize(text string) []token {
+	var tokens []token
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(text[i:], "--"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'':
+			i = skipString(text, i)
+			tokens = append(tokens, token{text: "''"})
+		case c == '"' || c == '`' || c == '[' || isIdentChar(c):
+			name, next := readName(text, i)
+			tokens = append(tokens, token{text: name, word: true})
+			i = next
+		default:
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+// skipString returns the offset after the string literal starting at i, where a doubled quote is
+// an escaped one
+func skipString(text string, i int) int {
+	for i++; i < len(text); i++ {
+		if text[i] != '\'' {
+			continue
+		}
+		if i+1 < len(text) && text[i+1] == '\'' {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(text)
+}
+
+// readName reads a possibly quoted and schema qualified name, e.g. "public"."users"
+func readName(text string, i int) (string, int) {
+	var name strings.Builder
+	for {
+		if closer := quoteCloser(text[i]); closer != 0 {
+			end := strings.IndexByte(text[i+1:], closer)
+			if end < 0 {
+				name.WriteString(text[i+1:])
+				return name.String(), len(text)
+			}
+			name.WriteString(text[i+1 : i+1+end])
+			i += end + 2
+		} else {
+			start := i
+			for i < len(text) && isIdentChar(text[i]) {
+				i++
+			}
+			name.WriteString(text[start:i])
+		}
+
+		if i+1 < len(text) && text[i] == '.' && (isIdentChar(text[i+1]) || quoteCloser(text[i+1]) != 0) {
+			name.WriteByte('.')
+			i++
+			continue
+		}
+		return name.String(), i
+	}
+}
+
+func quoteCloser(c byte) byte {
+	switch c {
+	case '"', '`':
+		return c
+	case '[':
+		return ']'
+	}
+	return 0
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// selectsAll returns true if a select list includes a * outside of function arguments, such as
+// SELECT * or SELECT id, u.*, but not SELECT count(*) or SELECT price * quantity
+func selectsAll(tokens []token) bool {
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i].text != "*" {
+			continue
+		}
+		switch prev := tokens[i-1]; {
+		case prev.text == "," || prev.text == ".":
+		case prev.keyword() == "SELECT" || prev.keyword() == "DISTINCT" || prev.keyword() == "ALL":
+		default:
+			continue
+		}
+		if inSelectList(tokens, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// inSelectList returns true if the token at i follows a SELECT at the same depth, without a
+// clause or function call opened in between
+func inSelectList(tokens []token, i int) bool {
+	depth := 0
+	for j := i - 1; j >= 0; j-- {
+		switch tokens[j].text {
+		case ")":
+			depth++
+			continue
+		case "(":
+			if depth == 0 {
+				// Arguments of a function, e.g. count(DISTINCT *), rather than a subquery
+				if j > 0 && tokens[j-1].word && !keywords[tokens[j-1].keyword()] {
+					return false
+				}
+				continue
+			}
+			depth--
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		switch kw := tokens[j].keyword(); {
+		case kw == "SELECT":
+			return true
+		case tableKeywords[kw] || kw == "WHERE" || kw == "SET" || kw == "VALUES" || kw == "RETURNING":
+			return false
+		}
+	}
+	return false
+}
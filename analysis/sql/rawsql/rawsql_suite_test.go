@@ -0,0 +1,13 @@
+package rawsql
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRawSQL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Raw SQL Suite")
+}
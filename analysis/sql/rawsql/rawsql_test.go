@@ -0,0 +1,57 @@
+package rawsql
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	DescribeTable("extracts the operation and tables",
+		func(sql, operation string, tables []string) {
+			query, ok := Parse(sql)
+			Expect(ok).To(BeTrue())
+			Expect(query.Operation).To(Equal(operation))
+			Expect(query.Tables).To(Equal(tables))
+		},
+		Entry("select", "SELECT id, name FROM users WHERE id = ?", "SELECT", []string{"users"}),
+		Entry("joins with aliases and quoted names",
+			`select * from public.users u join "orders" o on o.user_id = u.id left join items i on i.order_id = o.id`,
+			"SELECT", []string{"public.users", "orders", "items"}),
+		Entry("insert with a string literal", "INSERT INTO audit_log (id, message) VALUES ($1, 'it''s from x')", "INSERT", []string{"audit_log"}),
+		Entry("update with a subquery", "UPDATE accounts SET balance = balance - 1 WHERE id IN (SELECT account_id FROM transfers)",
+			"UPDATE", []string{"accounts", "transfers"}),
+		Entry("delete using", "DELETE FROM sessions USING users WHERE sessions.user_id = users.id", "DELETE", []string{"sessions", "users"}),
+		Entry("common table expressions and functions",
+			"WITH recent AS (SELECT * FROM orders) SELECT EXTRACT(YEAR FROM created_at) FROM recent, customers c",
+			"SELECT", []string{"orders", "customers"}),
+		Entry("upsert", "INSERT INTO t (a) VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 2", "INSERT", []string{"t"}),
+		Entry("row locks", "SELECT * FROM jobs FOR UPDATE SKIP LOCKED", "SELECT", []string{"jobs"}),
+		Entry("DDL with bracketed names", "CREATE TABLE IF NOT EXISTS [dbo].[people] (id int)", "CREATE", []string{"dbo.people"}),
+		Entry("set returning functions", "-- count\nSELECT * FROM generate_series(1, 10)", "SELECT", []string(nil)),
+	)
+
+	It("rejects text that is not SQL", func() {
+		for _, text := range []string{"name, age", "hello world", "SELECT", ""} {
+			_, ok := Parse(text)
+			Expect(ok).To(BeFalse(), text)
+		}
+	})
+
+	DescribeTable("detects select lists of every column",
+		func(sql string, selectsAll bool) {
+			query, ok := Parse(sql)
+			Expect(ok).To(BeTrue())
+			Expect(query.SelectsAll).To(Equal(selectsAll))
+		},
+		Entry("star", "SELECT * FROM users", true),
+		Entry("qualified star", "SELECT o.id, u.* FROM orders o JOIN users u ON u.id = o.user_id", true),
+		Entry("star of a subquery", "SELECT id FROM (SELECT DISTINCT * FROM users) u", true),
+		Entry("columns", "SELECT id, name FROM users", false),
+		Entry("count", "SELECT count(*) FROM users", false),
+		Entry("multiplication", "SELECT price * quantity FROM items", false),
+	)
+
+	It("normalizes whitespace", func() {
+		Expect(Normalize("SELECT *\n\t  FROM users\n")).To(Equal("SELECT * FROM users"))
+	})
+})
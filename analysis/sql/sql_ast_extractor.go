@@ -272,8 +272,19 @@ func (e *SQLASTExtractor) extractIndexes(ctx context.Context, result *types.ASTR
 			StartLine:    -1,
 			LastModified: time.Now(),
 			Summary:      models.StringPtr("Database index"),
+			Metatdata:    map[string]string{"kind": models.SchemaKindIndex},
 		}
 
+		// The columns of the index are its parameters, in index order
+		columns, err := loader.IndexColumns(ctx, tableName, index.IndexName)
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			indexNode.Parameters = append(indexNode.Parameters, models.Parameter{Name: column.ColumnName})
+		}
+		indexNode.ParameterCount = len(indexNode.Parameters)
+
 		// Set parent relationship
 		indexNode.Parent = parentTable
 
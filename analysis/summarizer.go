@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/llm"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// Summarizer writes bounded summaries of AST nodes with an LLM, reusing the summaries cached for
+// files that did not change since they were summarized
+type Summarizer struct {
+	cache    *cache.ASTCache
+	provider llm.Provider
+}
+
+// SummarizeResult counts the nodes a Summarizer visited
+type SummarizeResult struct {
+	Generated int `json:"generated"`
+	Cached    int `json:"cached"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+}
+
+// NewSummarizer creates a summarizer storing summaries in astCache
+func NewSummarizer(astCache *cache.ASTCache, provider llm.Provider) *Summarizer {
+	return &Summarizer{cache: astCache, provider: provider}
+}
+
+// Summarize summarizes the fields, methods and types among nodes. Nodes that already have a
+// summary, e.g. from LSIF hover text or SQL comments, are skipped unless force is set, which
+// also ignores the summaries cached by file hash.
+func (s *Summarizer) Summarize(ctx context.Context, nodes []*models.ASTNode, force bool) (*SummarizeResult, error) {
+	hashes, err := s.cache.FileHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SummarizeResult{}
+	// Once the token budget is spent only cached summaries are used
+	budgetSpent := false
+	for _, node := range nodes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		limit := models.SummaryWordLimit(node.NodeType)
+		if limit == 0 || (!force && node.Summary != nil && *node.Summary != "") {
+			result.Skipped++
+			continue
+		}
+
+		fileHash := hashes[node.FilePath]
+		if !force && fileHash != "" {
+			summary, ok, err := s.cache.CachedSummary(fileHash, node.String())
+			if err != nil {
+				return result, err
+			}
+			if ok {
+				// Without a file hash only the node is updated, the cached summary keeps its model
+				if err := s.cache.StoreSummary(node, "", summary, ""); err != nil {
+					return result, err
+				}
+				node.Summary = &summary
+				result.Cached++
+				continue
+			}
+		}
+
+		if budgetSpent {
+			result.Skipped++
+			continue
+		}
+		summary, err := s.summarize(ctx, node, limit)
+		if errors.Is(err, llm.ErrTokenBudget) {
+			logger.Warnf("Stopped summarizing: %v", err)
+			budgetSpent = true
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			logger.Warnf("Failed to summarize %s: %v", node.String(), err)
+			result.Failed++
+			continue
+		}
+		if err := s.cache.StoreSummary(node, fileHash, summary, s.provider.Name()); err != nil {
+			return result, err
+		}
+		node.Summary = &summary
+		result.Generated++
+	}
+	return result, nil
+}
+
+func (s *Summarizer) summarize(ctx context.Context, node *models.ASTNode, limit int) (string, error) {
+	source, err := node.GetFullSourceCode()
+	if err != nil {
+		logger.Debugf("Summarizing %s without its source: %v", node.String(), err)
+	}
+	completion, err := s.provider.Complete(ctx, BuildSummaryPrompt(node, source, limit))
+	if err != nil {
+		return "", err
+	}
+	summary := models.LimitWords(completion, limit)
+	if summary == "" {
+		return "", fmt.Errorf("%s returned an empty summary", s.provider.Name())
+	}
+	return summary, nil
+}
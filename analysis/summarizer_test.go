@@ -0,0 +1,95 @@
+package analysis_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// fakeProvider answers every prompt with the same completion
+type fakeProvider struct {
+	completion string
+	prompts    []string
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake/model"
+}
+
+func (p *fakeProvider) Complete(_ context.Context, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	return p.completion, nil
+}
+
+var _ = Describe("Summarizer", func() {
+	var astCache *cache.ASTCache
+	var provider *fakeProvider
+	var file string
+
+	node := func(nodeType models.NodeType, name string, line int) *models.ASTNode {
+		n := &models.ASTNode{FilePath: file, PackageName: "auth", TypeName: "Service", NodeType: nodeType, StartLine: line, EndLine: line + 2}
+		if nodeType == models.NodeTypeMethod {
+			n.MethodName = name
+		}
+		_, err := astCache.StoreASTNode(n)
+		Expect(err).ToNot(HaveOccurred())
+		return n
+	}
+
+	BeforeEach(func() {
+		var err error
+		astCache, err = cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(astCache.Close)
+
+		file = filepath.Join(GinkgoT().TempDir(), "service.go")
+		Expect(os.WriteFile(file, []byte("package auth\n\ntype Service struct{}\n\nfunc (s *Service) Login() {\n\ts.check()\n}\n"), 0644)).To(Succeed())
+		Expect(astCache.UpdateFileMetadata(file)).To(Succeed())
+		provider = &fakeProvider{completion: "\"Authenticates a user with their password and starts a new session, refreshing the token when it expired long ago.\""}
+	})
+
+	It("should bound summaries by node type", func() {
+		method := node(models.NodeTypeMethod, "Login", 5)
+		pkg := node(models.NodeTypePackage, "", 1)
+
+		result, err := analysis.NewSummarizer(astCache, provider).Summarize(context.Background(), []*models.ASTNode{method, pkg}, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Generated).To(Equal(1))
+		Expect(result.Skipped).To(Equal(1))
+
+		Expect(provider.prompts[0]).To(ContainSubstring("at most 20 words"))
+		Expect(provider.prompts[0]).To(ContainSubstring("s.check()"))
+		Expect(strings.Fields(*method.Summary)).To(HaveLen(20))
+
+		stored, err := astCache.GetASTNode(method.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored.Summary).To(Equal(method.Summary))
+	})
+
+	It("should reuse summaries cached by file hash", func() {
+		method := node(models.NodeTypeMethod, "Login", 5)
+		summarizer := analysis.NewSummarizer(astCache, provider)
+		_, err := summarizer.Summarize(context.Background(), []*models.ASTNode{method}, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		// A re-analysis recreates the node without its summary
+		method.Summary = nil
+		result, err := summarizer.Summarize(context.Background(), []*models.ASTNode{method}, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Cached).To(Equal(1))
+		Expect(provider.prompts).To(HaveLen(1))
+
+		result, err = summarizer.Summarize(context.Background(), []*models.ASTNode{method}, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Generated).To(Equal(1))
+		Expect(provider.prompts).To(HaveLen(2))
+	})
+})
@@ -76,7 +76,7 @@ func (a *Analyzer) AnalyzeFiles() error {
 			switch {
 			case strings.HasSuffix(path, ".go"):
 				lang = "go"
-			case strings.HasSuffix(path, ".py"):
+			case strings.HasSuffix(path, ".py") || strings.HasSuffix(path, ".ipynb"):
 				lang = "python"
 			case strings.HasSuffix(path, ".js") || strings.HasSuffix(path, ".jsx") ||
 				strings.HasSuffix(path, ".mjs") || strings.HasSuffix(path, ".cjs"):
@@ -216,7 +216,7 @@ func (a *Analyzer) AnalyzeFilesWithFilter(includePatterns, excludePatterns []str
 			switch {
 			case strings.HasSuffix(path, ".go"):
 				lang = "go"
-			case strings.HasSuffix(path, ".py"):
+			case strings.HasSuffix(path, ".py") || strings.HasSuffix(path, ".ipynb"):
 				lang = "python"
 			case strings.HasSuffix(path, ".js") || strings.HasSuffix(path, ".jsx") ||
 				strings.HasSuffix(path, ".mjs") || strings.HasSuffix(path, ".cjs"):
@@ -433,7 +433,7 @@ func (a *Analyzer) GetCacheStats() (*CacheStats, error) {
 		}
 
 		// Count only supported file types
-		if strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".py") ||
+		if strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".py") || strings.HasSuffix(path, ".ipynb") ||
 			strings.HasSuffix(path, ".js") || strings.HasSuffix(path, ".ts") ||
 			strings.HasSuffix(path, ".md") {
 			totalFiles++
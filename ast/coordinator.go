@@ -8,9 +8,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/flanksource/arch-unit/analysis"
+	goAnalysis "github.com/flanksource/arch-unit/analysis/go"
 	"github.com/flanksource/arch-unit/analysis/types"
 	"github.com/flanksource/arch-unit/internal/cache"
 	"github.com/flanksource/arch-unit/languages"
+	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky"
 	"github.com/flanksource/clicky/task"
 	flanksourceContext "github.com/flanksource/commons/context"
@@ -19,11 +22,13 @@ import (
 // Coordinator manages AST analysis with caching and parallelization
 type Coordinator struct {
 	cache      *cache.ASTCache
+	analyzer   *analysis.GenericAnalyzer // stores into cache rather than the cache of the working directory
 	registry   *languages.Registry
 	noCache    bool
 	cacheTTL   time.Duration
 	maxWorkers int
 	workDir    string
+	profile    models.ExtractionProfile
 }
 
 // CoordinatorOptions configures the coordinator
@@ -31,7 +36,8 @@ type CoordinatorOptions struct {
 	NoCache    bool
 	CacheTTL   time.Duration
 	MaxWorkers int
-	Languages  []string // Filter to specific languages
+	Languages  []string                 // Filter to specific languages
+	Profile    models.ExtractionProfile // Node kinds to extract, empty for all
 }
 
 // NewCoordinator creates a new AST analysis coordinator
@@ -43,11 +49,13 @@ func NewCoordinator(cache *cache.ASTCache, workDir string, opts CoordinatorOptio
 
 	return &Coordinator{
 		cache:      cache,
+		analyzer:   analysis.NewGenericAnalyzer(cache),
 		registry:   languages.GetRegistry(),
 		noCache:    opts.NoCache,
 		cacheTTL:   opts.CacheTTL,
 		maxWorkers: maxWorkers,
 		workDir:    workDir,
+		profile:    opts.Profile,
 	}
 }
 
@@ -195,6 +203,11 @@ func (c *Coordinator) AnalyzeDirectory(parentTask *clicky.Task, dir string) ([]F
 		}
 	}
 
+	// Interface implementations span files and packages, so they are linked once all Go files are stored
+	if _, analyzedGo := langGroups["go"]; analyzedGo {
+		c.linkGoImplementations(parentTask, dir)
+	}
+
 	// Display filtered task summary by language (only if we have language groups from analyzed files)
 	if len(langGroups) > 0 {
 		// Add a small delay to ensure all tasks have completed their status updates
@@ -213,6 +226,24 @@ func (c *Coordinator) AnalyzeDirectory(parentTask *clicky.Task, dir string) ([]F
 	return allResults, nil
 }
 
+// linkGoImplementations records implements relationships between the Go types below dir.
+// Type-checking needs a buildable module, so failures only skip the relationships.
+func (c *Coordinator) linkGoImplementations(parentTask *clicky.Task, dir string) {
+	parentTask.SetName("Linking Go interface implementations")
+	impls, err := goAnalysis.FindImplementations(dir)
+	if err != nil {
+		parentTask.Warnf("Skipping Go interface implementations: %v", err)
+		return
+	}
+
+	stored, err := goAnalysis.StoreImplementations(c.cache, impls)
+	if err != nil {
+		parentTask.Warnf("Failed to store Go interface implementations: %v", err)
+		return
+	}
+	parentTask.Infof("Linked %d Go interface implementations", stored)
+}
+
 // analyzeFileWithPath analyzes a single file with the specified path
 func (c *Coordinator) analyzeFileWithPath(ctx flanksourceContext.Context, task *task.Task, filePath string) (FileResult, error) {
 	result := FileResult{Path: filePath}
@@ -246,27 +277,24 @@ func (c *Coordinator) analyzeFileWithPath(ctx flanksourceContext.Context, task *
 		return result, nil
 	}
 
-	analysisResult, err := lang.Analyzer.AnalyzeFile(task, result.Path, content)
+	// The analyzers of the registry delegate to the generic analyzer of the global cache, which
+	// would mix the files of other roots, e.g. the revisions compared by diff, into it
+	astResult, err := c.analyzer.AnalyzeFile(task, result.Path, content)
 	if err != nil {
 		_, _ = task.FailedWithError(err)
 		return result, nil
 	}
 
-	// Type assert the result
-	astResult, ok := analysisResult.(*types.ASTResult)
-	if !ok {
-		task.Errorf("Invalid analysis result type")
-		task.Failed()
-		result.Error = fmt.Errorf("invalid analysis result type")
-		return result, result.Error
-	}
-
 	// Check for nil result
 	if astResult == nil {
 		task.Warnf("No AST data extracted from %s", result.Path)
 		return result, nil
 	}
 
+	if !c.profile.IsFull() {
+		filterResultByProfile(astResult, c.profile)
+	}
+
 	// Store in cache
 	if !c.noCache {
 		// Don't overwrite the task name - keep showing the filename
@@ -296,6 +324,12 @@ func (c *Coordinator) shouldAnalyze(file string) bool {
 	}
 
 	if !needsAnalysis {
+		// Backfill files whose cached extraction profile is narrower than the requested one
+		cachedProfile, found, err := c.cache.GetExtractionProfile(file)
+		if err != nil || !found || !cachedProfile.Covers(c.profile) {
+			return true
+		}
+
 		// Check TTL if configured
 		if c.cacheTTL > 0 {
 			// Get file info to check last modified time
@@ -407,5 +441,56 @@ func (c *Coordinator) getCachedAnalysis(file string) (*types.ASTResult, error) {
 func (c *Coordinator) storeResults(file string, result *types.ASTResult) error {
 	// Use a single transaction for the entire operation to ensure atomicity
 	// This prevents concurrent operations from interfering with each other
-	return c.cache.StoreFileResults(file, result)
+	return c.cache.StoreFileResultsWithProfile(file, result, c.profile)
+}
+
+// filterResultByProfile drops nodes whose kind is not part of the extraction profile,
+// along with any relationships originating from them
+func filterResultByProfile(result *types.ASTResult, profile models.ExtractionProfile) {
+	dropped := make(map[int64]bool)
+	droppedNodes := make(map[*models.ASTNode]bool)
+	nodes := result.Nodes[:0]
+	for _, node := range result.Nodes {
+		if profile.Includes(node.NodeType) {
+			nodes = append(nodes, node)
+		} else {
+			dropped[node.ID] = true
+			droppedNodes[node] = true
+		}
+	}
+	result.Nodes = nodes
+	result.NodeCount = len(nodes)
+
+	if len(dropped) == 0 {
+		return
+	}
+
+	relationships := result.Relationships[:0]
+	for _, rel := range result.Relationships {
+		if rel.FromAST != nil {
+			if droppedNodes[rel.FromAST] {
+				continue
+			}
+		} else if dropped[rel.FromASTID] {
+			continue
+		}
+		if rel.ToAST != nil && droppedNodes[rel.ToAST] {
+			rel.ToAST = nil
+		}
+		if rel.ToASTID != nil && dropped[*rel.ToASTID] {
+			rel.ToASTID = nil
+		}
+		relationships = append(relationships, rel)
+	}
+	result.Relationships = relationships
+	result.RelationshipCount = len(relationships)
+
+	libraries := result.Libraries[:0]
+	for _, lib := range result.Libraries {
+		if !dropped[lib.ASTID] {
+			libraries = append(libraries, lib)
+		}
+	}
+	result.Libraries = libraries
+	result.LibraryCount = len(libraries)
 }
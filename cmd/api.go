@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/archdiff"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var apiOutput string
+
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Extract the public API surface into a manifest",
+	Long: `Analyze the working directory and list its public API surface: the exported types,
+functions, methods, fields and variables with their signatures. Symbols of Go internal packages
+and test files, and members of unexported types, are not part of the API.
+
+--output writes the manifest as JSON, to be committed and compared with 'arch-unit api diff'.
+
+Examples:
+  # List the public API
+  arch-unit api
+
+  # Write the manifest of a release
+  arch-unit api --output api.json`,
+	Args: cobra.NoArgs,
+	RunE: runAPI,
+}
+
+var apiDiffCmd = &cobra.Command{
+	Use:   "diff <base> [head]",
+	Short: "Report the breaking changes of the public API between two versions",
+	Long: `Compare the public API surface of two versions and print the symbols removed, changed and
+added as markdown. Removed symbols and changed signatures are breaking, and fail the command so
+that it can gate releases of libraries.
+
+Each version is an API manifest written by 'arch-unit api --output', a directory holding an AST
+cache (ast.db), or a git ref checked out into a temporary worktree and analyzed. Without head the
+working tree is compared. Signatures are compared without the names of parameters and results,
+so renaming them is not a breaking change.
+
+Examples:
+  # Check the working tree against the last release
+  arch-unit api diff v1.2.0
+
+  # Check against a committed manifest
+  arch-unit api diff api.json
+
+  # Compare two tags
+  arch-unit api diff v1.2.0 v1.3.0`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE:         runAPIDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(apiCmd)
+	apiCmd.AddCommand(apiDiffCmd)
+	apiCmd.Flags().StringVarP(&apiOutput, "output", "o", "", "Write the manifest to this file, e.g. "+archdiff.DefaultAPIManifest)
+}
+
+func runAPI(cmd *cobra.Command, args []string) error {
+	dir, err := apiDir()
+	if err != nil {
+		return err
+	}
+	manifest, err := apiManifest(dir, "")
+	if err != nil {
+		return err
+	}
+
+	if apiOutput != "" {
+		if err := manifest.Save(apiOutput); err != nil {
+			return err
+		}
+		logger.Infof("Wrote %d public symbols to %s", len(manifest.Symbols), apiOutput)
+		return nil
+	}
+
+	format := clicky.Flags.FormatOptions.ResolveFormat()
+	if format == "json" || format == "yaml" {
+		fmt.Println(clicky.MustFormat(manifest))
+		return nil
+	}
+	if len(manifest.Symbols) == 0 {
+		logger.Infof("No public symbols found in %s", dir)
+		return nil
+	}
+	fmt.Println(clicky.MustFormat(manifest.Symbols))
+	return nil
+}
+
+func runAPIDiff(cmd *cobra.Command, args []string) error {
+	dir, err := apiDir()
+	if err != nil {
+		return err
+	}
+
+	base, err := apiManifest(dir, args[0])
+	if err != nil {
+		return err
+	}
+	head, headLabel := "", "working tree"
+	if len(args) > 1 {
+		head, headLabel = args[1], args[1]
+	}
+	headManifest, err := apiManifest(dir, head)
+	if err != nil {
+		return err
+	}
+
+	changes := archdiff.CompareAPI(base, headManifest)
+	fmt.Print(archdiff.APIMarkdown(args[0], headLabel, changes))
+
+	breaking := 0
+	for _, c := range changes {
+		if c.Breaking() {
+			breaking++
+		}
+	}
+	if breaking > 0 {
+		return fmt.Errorf("%d breaking API changes since %s", breaking, args[0])
+	}
+	return nil
+}
+
+func apiDir() (string, error) {
+	dir, err := GetWorkingDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(dir)
+}
+
+// apiManifest reads the API manifest of a version: a manifest file, or the symbols of the working
+// tree when rev is empty, of a cache snapshot or of a git ref
+func apiManifest(dir, rev string) (*archdiff.APIManifest, error) {
+	if strings.HasSuffix(rev, ".json") {
+		if _, err := os.Stat(rev); err == nil {
+			return archdiff.LoadAPIManifest(rev)
+		}
+	}
+	// Violations are not part of the API, no rules are evaluated
+	snapshot, err := diffSnapshot(dir, rev, nil)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.API(), nil
+}
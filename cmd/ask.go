@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	askProvider string
+	askModel    string
+	askYes      bool
+	askShow     int
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask <request>",
+	Short: "Propose an architecture rule from a request in plain language",
+	Long: `Ask an LLM for an architecture rule implementing a request in plain language, such as
+"controllers must not touch gorm". The proposal is an AQL rule, or import rules for third-party
+packages, using the package and directory names of the AST cache. The nodes it would report now
+are shown, and the rule is appended to arch-unit.yaml after confirmation.
+
+Without a terminal to confirm, the rule is only appended with --yes. The provider is chosen as
+for 'arch-unit summarize'.
+
+Examples:
+  arch-unit ask "controllers must not touch gorm"
+  arch-unit ask "services may not call each other's repositories" --provider ollama
+  arch-unit ask "no function longer than 80 lines in the api package" --yes`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAsk,
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+	askCmd.Flags().StringVar(&askProvider, "provider", "", "LLM provider: anthropic, openai, bedrock or ollama")
+	askCmd.Flags().StringVar(&askModel, "model", "", "Model of the provider, defaults to $ARCH_UNIT_LLM_MODEL or a small model of the provider")
+	askCmd.Flags().BoolVarP(&askYes, "yes", "y", false, "Append the rule to arch-unit.yaml without asking")
+	askCmd.Flags().IntVar(&askShow, "show", 20, "Number of current violations shown, 0 for all")
+}
+
+func runAsk(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	request := strings.Join(args, " ")
+
+	ai, err := loadAIConfig(workingDir)
+	if err != nil {
+		return err
+	}
+	provider, err := newLLMProvider(ai, askProvider, askModel)
+	if err != nil {
+		return err
+	}
+
+	astCache := cache.MustGetASTCache()
+	packages, directories, libraries, err := codebaseNames(astCache, workingDir)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	proposal, err := analysis.ProposeRule(ctx, provider, request, packages, directories, libraries)
+	if err != nil {
+		return err
+	}
+	snippet, err := config.RuleSnippet(proposal)
+	if err != nil {
+		return err
+	}
+	violations, err := query.NewAQLEngine(astCache).Preview(proposal, workingDir)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate the proposed rule: %w", err)
+	}
+
+	format := clicky.Flags.FormatOptions.ResolveFormat()
+	if format == "json" || format == "yaml" {
+		fmt.Println(clicky.MustFormat(struct {
+			Proposal   *models.RuleProposal `json:"proposal"`
+			Violations []*models.Violation  `json:"violations"`
+		}{proposal, violations}))
+	} else {
+		printAskProposal(format, proposal, snippet, violations, workingDir)
+	}
+
+	if !askYes && !confirm("Append this rule to arch-unit.yaml?") {
+		return nil
+	}
+	path, err := config.NewParser(workingDir).AppendRule(proposal)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Appended the rule to %s", path)
+	return nil
+}
+
+func printAskProposal(format string, proposal *models.RuleProposal, snippet string, violations []*models.Violation, workingDir string) {
+	bold := color.New(color.Bold).Sprint
+	markdown := format == "markdown" || format == "md"
+	if markdown {
+		fmt.Printf("## Proposed rule\n\n%s\n\n```yaml\n%s```\n\n", proposal.Explanation, snippet)
+	} else {
+		fmt.Println(bold("Proposed rule"))
+		if proposal.Explanation != "" {
+			fmt.Println(proposal.Explanation)
+		}
+		fmt.Println()
+		fmt.Print(snippet)
+		fmt.Println()
+	}
+
+	title := fmt.Sprintf("%d current violations", len(violations))
+	if markdown {
+		fmt.Printf("## %s\n\n", title)
+	} else {
+		fmt.Println(bold(title))
+	}
+	for i, v := range violations {
+		if askShow > 0 && i == askShow {
+			fmt.Printf("... and %d more\n", len(violations)-askShow)
+			break
+		}
+		file := v.File
+		if rel, err := filepath.Rel(workingDir, v.File); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+		message := ""
+		if v.Message != nil {
+			message = *v.Message
+		}
+		fmt.Printf("  %s:%d %s\n", file, v.Line, message)
+	}
+	fmt.Println()
+}
+
+// confirm asks a yes/no question on the terminal, false when stdin is not a terminal
+func confirm(question string) bool {
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		logger.Infof("Run again with --yes to append the rule to arch-unit.yaml")
+		return false
+	}
+	fmt.Printf("%s (y/n) [n]: ", question)
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes"
+}
+
+// codebaseNames returns the packages, directories relative to workingDir and third-party packages
+// of the code analyzed under workingDir, so proposed rules use their actual names
+func codebaseNames(astCache *cache.ASTCache, workingDir string) ([]string, []string, []string, error) {
+	prefix := workingDir + "/%"
+	packages, err := distinctStrings(astCache, "SELECT DISTINCT package_name FROM ast_nodes WHERE file_path LIKE ? AND package_name != ''", prefix)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	files, err := distinctStrings(astCache, "SELECT DISTINCT file_path FROM ast_nodes WHERE file_path LIKE ?", prefix)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	libraries, err := distinctStrings(astCache, `SELECT DISTINCT l.package FROM library_nodes l
+		JOIN library_relationships r ON r.library_id = l.id
+		JOIN ast_nodes n ON n.id = r.ast_id
+		WHERE n.file_path LIKE ?`, prefix)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	seen := make(map[string]bool)
+	var directories []string
+	for _, file := range files {
+		rel, err := filepath.Rel(workingDir, filepath.Dir(file))
+		if err != nil || rel == "." || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		directories = append(directories, filepath.ToSlash(rel))
+	}
+	sort.Strings(directories)
+	return packages, directories, libraries, nil
+}
+
+func distinctStrings(astCache *cache.ASTCache, sql string, args ...interface{}) ([]string, error) {
+	rows, err := astCache.QueryRaw(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values, rows.Err()
+}
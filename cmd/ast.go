@@ -23,15 +23,16 @@ var (
 	astAll            bool
 
 	// New display configuration flags
-	astShowDirs      bool
-	astShowFiles     bool
-	astShowPackages  bool
-	astShowTypes     bool
-	astShowMethods   bool
-	astShowParams    bool
-	astShowImports   bool
-	astShowLineNo    bool
-	astShowFileStats bool
+	astShowDirs       bool
+	astShowFiles      bool
+	astShowPackages   bool
+	astShowTypes      bool
+	astShowMethods    bool
+	astShowParams     bool
+	astShowImports    bool
+	astShowStatements bool
+	astShowLineNo     bool
+	astShowFileStats  bool
 )
 
 var astCmd = &cobra.Command{
@@ -226,6 +227,7 @@ func init() {
 	astCmd.PersistentFlags().BoolVar(&astShowMethods, "methods", true, "Show methods in tree")
 	astCmd.PersistentFlags().BoolVar(&astShowParams, "params", false, "Show method parameters in tree")
 	astCmd.PersistentFlags().BoolVar(&astShowImports, "imports", false, "Show import statements in tree")
+	astCmd.PersistentFlags().BoolVar(&astShowStatements, "statements", false, "Show the statements of function bodies in tree")
 	astCmd.PersistentFlags().BoolVar(&astShowLineNo, "line-no", true, "Show line numbers in tree")
 	astCmd.PersistentFlags().BoolVar(&astShowFileStats, "file-stats", false, "Show file-level statistics")
 
@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/flanksource/arch-unit/ast"
+	"github.com/flanksource/arch-unit/config"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/limits"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/pkg/plugins"
 	"github.com/flanksource/clicky"
 	flanksourceContext "github.com/flanksource/commons/context"
 	"github.com/spf13/cobra"
@@ -18,6 +23,8 @@ var (
 	astCacheTTL   string
 	astMaxWorkers int
 	astLanguages  []string
+	astNodeTypes  []string
+	astSkipTypes  []string
 )
 
 var astAnalyzeCmd = &cobra.Command{
@@ -39,7 +46,10 @@ Examples:
   arch-unit ast analyze --no-cache
 
   # Analyze only specific languages
-  arch-unit ast analyze --languages go,python`,
+  arch-unit ast analyze --languages go,python
+
+  # Fast run that skips variables and fields, a later full run backfills them
+  arch-unit ast analyze --skip-node-types variable,field`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runASTAnalyze,
 }
@@ -50,6 +60,8 @@ func init() {
 	astAnalyzeCmd.Flags().StringVar(&astCacheTTL, "cache-ttl", "4h", "Cache time-to-live (e.g., 1h, 30m, 24h)")
 	astAnalyzeCmd.Flags().StringSliceVar(&astLanguages, "languages", nil, "Filter to specific languages (e.g., go,python,javascript)")
 	astAnalyzeCmd.Flags().IntVar(&astMaxWorkers, "max-workers", 0, "Maximum number of parallel workers (0 = auto)")
+	astAnalyzeCmd.Flags().StringSliceVar(&astNodeTypes, "node-types", nil, "Only extract these node kinds (package,type,method,field,variable)")
+	astAnalyzeCmd.Flags().StringSliceVar(&astSkipTypes, "skip-node-types", nil, "Skip extraction of these node kinds")
 }
 
 func runASTAnalyze(cmd *cobra.Command, args []string) error {
@@ -82,6 +94,23 @@ func runASTAnalyze(cmd *cobra.Command, args []string) error {
 		cacheTTL = duration
 	}
 
+	// arch-unit.yaml is optional, without it everything is extracted and no resource limits apply
+	archConfig, err := config.NewParser(absPath).LoadConfig()
+	if err != nil && !errors.Is(err, config.ErrConfigNotFound) {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	limits.SetConfig(archConfig)
+	if archConfig != nil {
+		if err := plugins.LoadAll(absPath, archConfig.Plugins); err != nil {
+			return fmt.Errorf("failed to load plugins: %w", err)
+		}
+	}
+
+	profile, err := resolveExtractionProfile(archConfig)
+	if err != nil {
+		return err
+	}
+
 	// Create root task that wraps all AST analysis logic
 	clicky.StartTask("AST Analysis", func(ctx flanksourceContext.Context, t *clicky.Task) (interface{}, error) {
 		// Initialize AST cache
@@ -93,6 +122,7 @@ func runASTAnalyze(cmd *cobra.Command, args []string) error {
 			CacheTTL:   cacheTTL,
 			Languages:  astLanguages,
 			MaxWorkers: astMaxWorkers,
+			Profile:    profile,
 		}
 
 		// Create coordinator
@@ -115,6 +145,9 @@ func runASTAnalyze(cmd *cobra.Command, args []string) error {
 		} else {
 			t.Infof("Languages: all supported")
 		}
+		if !profile.IsFull() {
+			t.Infof("Node kinds: %s", profile.String())
+		}
 
 		// Run analysis
 		startTime := time.Now()
@@ -156,3 +189,20 @@ func runASTAnalyze(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// resolveExtractionProfile returns the extraction profile from flags, falling back to the
+// extraction section of arch-unit.yaml when no flags are given
+func resolveExtractionProfile(archConfig *models.Config) (models.ExtractionProfile, error) {
+	if len(astNodeTypes) > 0 || len(astSkipTypes) > 0 {
+		profile, err := models.NewExtractionProfile(astNodeTypes, astSkipTypes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node kind selection: %w", err)
+		}
+		return profile, nil
+	}
+
+	if archConfig == nil {
+		return nil, nil // No config, extract everything
+	}
+	return archConfig.Extraction.Profile()
+}
@@ -99,19 +99,10 @@ func runASTAnalyzeSQL(cmd *cobra.Command, args []string) error {
 				ConnectionString: sqlConnectionString,
 			})
 
-			// Store nodes in cache
-			nodeMap := make(map[string]int64)
-			for _, node := range result.Nodes {
-				if node == nil {
-					continue
-				}
-
-				nodeID, err := astCache.StoreASTNode(node)
-				if err != nil {
-					t.Warnf("Failed to store AST node: %v", err)
-					continue
-				}
-				nodeMap[node.Key()] = nodeID
+			// Store nodes with their foreign key relationships, which schema rules check
+			if err := astCache.StoreFileResults(virtualPath, result); err != nil {
+				t.Errorf("Failed to store schema: %v", err)
+				return nil, err
 			}
 
 			// Update file metadata for virtual path
@@ -119,7 +110,7 @@ func runASTAnalyzeSQL(cmd *cobra.Command, args []string) error {
 				t.Warnf("Failed to update cache metadata: %v", err)
 			}
 
-			t.Infof("Stored %d nodes in cache", len(nodeMap))
+			t.Infof("Stored %d nodes in cache", len(result.Nodes))
 		}
 
 		// Output results if requested
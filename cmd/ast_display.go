@@ -48,6 +48,7 @@ func GetDisplayConfigFromFlags() models.DisplayConfig {
 		ShowFields:     astShowFields,
 		ShowParams:     astShowParams,
 		ShowImports:    astShowImports,
+		ShowStatements: astShowStatements,
 		ShowLineNo:     astShowLineNo,
 		ShowFileStats:  astShowFileStats,
 		ShowComplexity: astShowComplexity,
@@ -89,7 +90,6 @@ func DisplayNodes(astCache *cache.ASTCache, nodes []*models.ASTNode, pattern str
 	return OutputNodes(astCache, nodes, pattern, workingDir, opts)
 }
 
-
 // OutputNodesTemplate outputs nodes using a template
 func OutputNodesTemplate(nodes []*models.ASTNode, workingDir string, templateStr string) error {
 	tmpl, err := template.New("ast").Parse(templateStr)
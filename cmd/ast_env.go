@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var astEnvCmd = &cobra.Command{
+	Use:   "env [name]",
+	Short: "List the environment variables used by the codebase",
+	Long: `List the environment variables read or set by the analyzed code, with the
+packages and locations using each of them.
+
+Variables are extracted by 'ast analyze' from:
+  Go:         os.Getenv, os.LookupEnv, os.Setenv, viper.Get* and viper.BindEnv
+  Python:     os.getenv, os.environ[...] and os.environ.get
+  JavaScript: process.env.NAME, process.env['NAME'] and destructuring of process.env
+
+Only variables named by a constant are listed. viper keys are listed as written,
+e.g. log.level.
+
+AQL rules match variables as types of the env package, e.g. to keep environment
+access in the config package:
+  FORBID(service:* -> env:*)
+
+Examples:
+  # List all environment variables
+  arch-unit ast env
+
+  # List the variables starting with DB_
+  arch-unit ast env "DB_*"
+
+  # JSON output for programmatic use
+  arch-unit ast env --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runASTEnv,
+}
+
+// EnvVarUsage is an environment variable and the code using it
+type EnvVarUsage struct {
+	Name      string   `json:"name" pretty:"label=Variable,style=text-lime-600"`
+	Uses      int      `json:"uses" pretty:"label=Uses"`
+	Packages  []string `json:"packages" pretty:"label=Packages"`
+	Locations []string `json:"locations" pretty:"label=Locations,style=text-gray-500"`
+}
+
+func init() {
+	astCmd.AddCommand(astEnvCmd)
+}
+
+func runASTEnv(cmd *cobra.Command, args []string) error {
+	astCache := cache.MustGetASTCache()
+
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	namePattern := "*"
+	if len(args) > 0 {
+		namePattern = args[0]
+	}
+
+	usages, err := queryEnvVarUsages(astCache, workingDir, namePattern)
+	if err != nil {
+		return err
+	}
+
+	if len(usages) == 0 {
+		logger.Infof("No environment variables found in cache for %s", workingDir)
+		logger.Infof("%s", i18n.T("hint.analyze_first"))
+		return nil
+	}
+
+	if astFormat == "json" {
+		return OutputJSON(usages)
+	}
+	fmt.Println(clicky.MustFormat(usages))
+	return nil
+}
+
+// queryEnvVarUsages returns the environment variables used by the code in workingDir whose name
+// matches a pattern with * wildcards, ordered by name
+func queryEnvVarUsages(astCache *cache.ASTCache, workingDir, namePattern string) ([]EnvVarUsage, error) {
+	query := `SELECT r.text, r.line_no, n.file_path, n.package_name
+		FROM ast_relationships r JOIN ast_nodes n ON n.id = r.from_ast_id
+		WHERE r.relationship_type = ? AND n.file_path LIKE ? AND r.text LIKE ?
+		ORDER BY r.text, n.file_path, r.line_no`
+	rows, err := astCache.QueryRaw(query, models.RelationshipEnvVar, workingDir+"/%", strings.ReplaceAll(namePattern, "*", "%"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query environment variables: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var usages []EnvVarUsage
+	packages := make(map[string]bool)
+	for rows.Next() {
+		var name, filePath, packageName string
+		var line int
+		if err := rows.Scan(&name, &line, &filePath, &packageName); err != nil {
+			return nil, fmt.Errorf("failed to scan environment variable: %w", err)
+		}
+
+		if len(usages) == 0 || usages[len(usages)-1].Name != name {
+			usages = append(usages, EnvVarUsage{Name: name})
+			packages = make(map[string]bool)
+		}
+		usage := &usages[len(usages)-1]
+		usage.Uses++
+		usage.Locations = append(usage.Locations, fmt.Sprintf("%s:%d", MakeRelativePath(filePath, workingDir), line))
+		if packageName != "" && !packages[packageName] {
+			packages[packageName] = true
+			usage.Packages = append(usage.Packages, packageName)
+			sort.Strings(usage.Packages)
+		}
+	}
+
+	return usages, rows.Err()
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/flanksource/arch-unit/analysis/lsif"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/export"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportDir    string
+)
+
+var astExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the AST cache to CSV or Parquet files, or an LSIF index",
+	Long: `Export the nodes, relationships and library dependencies of the AST cache to flat
+files for analysis in DuckDB, pandas or spreadsheets, one file per table:
+
+  ast_nodes              functions, types, fields and the other nodes with their metrics
+  ast_relationships      calls, inheritance and the other relationships between nodes
+  library_nodes          packages, classes and methods of external libraries
+  library_relationships  imports and calls from nodes to external libraries
+
+Columns are named after the columns of the cache, e.g. file_path or cyclomatic_complexity.
+Lists and maps such as parameters and metadata are exported as JSON.
+
+With --format lsif the nodes of the current directory and the relationships between them are
+written to dump.lsif as an LSIF index, for editors and code intelligence tools such as
+Sourcegraph: every node is a definition with a hover and an arch-unit moniker, and calls,
+references and the other relationships to it are its references.
+
+Examples:
+  # Export the cache to Parquet files in ./arch-unit-export
+  arch-unit ast export
+
+  # Export to CSV files in another directory
+  arch-unit ast export --format csv --dir /tmp/ast
+
+  # Export an LSIF index and upload it to Sourcegraph
+  arch-unit ast export --format lsif
+  src code-intel upload -file=arch-unit-export/dump.lsif
+
+  # Query the most complex methods with DuckDB
+  duckdb -c "SELECT package_name, method_name, cyclomatic_complexity
+    FROM 'arch-unit-export/ast_nodes.parquet' ORDER BY cyclomatic_complexity DESC LIMIT 10"`,
+	Args: cobra.NoArgs,
+	RunE: runASTExport,
+}
+
+func init() {
+	astCmd.AddCommand(astExportCmd)
+	astExportCmd.Flags().StringVar(&exportFormat, "format", export.FormatParquet, "Format of the files: csv, parquet or lsif")
+	astExportCmd.Flags().StringVar(&exportDir, "dir", "arch-unit-export", "Directory the files are written to")
+}
+
+func runASTExport(cmd *cobra.Command, args []string) error {
+	if exportFormat == lsif.Format {
+		return exportLSIF()
+	}
+	paths, err := export.Export(cache.MustGetASTCache().GetReadQuery(), exportDir, exportFormat)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		logger.Infof("Exported %s", path)
+	}
+	return nil
+}
+
+// exportLSIF writes the LSIF index of the working directory to dump.lsif in the export directory
+func exportLSIF() error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", exportDir, err)
+	}
+
+	path := filepath.Join(exportDir, "dump.lsif")
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+	if err := lsif.Export(file, cache.MustGetASTCache(), workingDir); err != nil {
+		return err
+	}
+	logger.Infof("Exported %s", path)
+	return file.Close()
+}
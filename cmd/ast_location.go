@@ -7,6 +7,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/commons/logger"
 	"github.com/spf13/cobra"
@@ -228,7 +229,7 @@ func showASTLocationOverview(astCache *cache.ASTCache, workingDir string) error
 
 	if total == 0 {
 		logger.Infof("No AST data found in cache for %s", workingDir)
-		logger.Infof("Run 'arch-unit ast analyze' first to build the cache.")
+		logger.Infof("%s", i18n.T("hint.analyze_first"))
 		return nil
 	}
 
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/flanksource/arch-unit/models"
+	"github.com/spf13/cobra"
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the baseline of known violations",
+}
+
+var baselineWriteCmd = &cobra.Command{
+	Use:   "write [path] [files...]",
+	Short: "Snapshot the current violations into a baseline",
+	Long: `Run the same analysis as arch-unit check and write every violation it finds to a
+baseline, so that arch-unit check --baseline only fails on new violations.
+
+Violations are fingerprinted by their linter, rule, file, caller and called symbols and
+their message without numbers, not by their line, so that edits moving a known violation
+do not make it new. A symbol violating the same
+rule more often than recorded in the baseline is reported.
+
+Examples:
+  # Freeze the current violations into .arch-unit-baseline.json
+  arch-unit baseline write
+
+  # Only fail on violations that are not in the baseline
+  arch-unit check --baseline .arch-unit-baseline.json
+
+  # Write the baseline of the golangci-lint violations to another file
+  arch-unit baseline write --linters golangci-lint --file lint-baseline.json`,
+	Args:         cobra.ArbitraryArgs,
+	SilenceUsage: true,
+	RunE:         runBaselineWrite,
+}
+
+var baselineWriteFile string
+
+func init() {
+	rootCmd.AddCommand(baselineCmd)
+	baselineCmd.AddCommand(baselineWriteCmd)
+	baselineWriteCmd.Flags().StringVar(&baselineWriteFile, "file", models.DefaultBaselineFile, "Path of the baseline to write")
+	baselineWriteCmd.Flags().StringVar(&lintersFlag, "linters", "*", "Linters whose violations are written ('*' for all configured, or comma-separated list)")
+	baselineWriteCmd.Flags().BoolVar(&noCacheFlag, "no-cache", false, "Disable caching and force re-analysis of all files")
+}
+
+func runBaselineWrite(cmd *cobra.Command, args []string) error {
+	writingBaseline = true
+	baselineFile = baselineWriteFile
+	return runCheck(cmd, args)
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the AST cache",
+	Long: `Manage the AST cache of the current project, which grows with every file analyzed. Each project
+has a cache in ~/.cache/arch-unit/projects, unless --cache-dir or the cache section of arch-unit.yaml
+selects a directory, or the PostgreSQL database of $ARCH_UNIT_CACHE_DSN.
+
+Examples:
+  # Size of the cache, nodes per language and the files analyzed longest ago
+  arch-unit cache stats
+
+  # Forget the files not analyzed in the last 30 days
+  arch-unit cache prune --older-than 30d
+
+  # Forget the files of a project, or everything
+  arch-unit cache clear ./services/api
+  arch-unit cache clear
+
+  # Location of the cache, e.g. to back it up
+  arch-unit cache path
+
+  # Share the cache of a repository between CI jobs
+  arch-unit cache push --remote s3://ci-cache/arch-unit
+  arch-unit cache pull --remote s3://ci-cache/arch-unit`,
+}
+
+var cacheOldest int
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the size of the AST cache, its nodes per language and oldest files",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheStats,
+}
+
+var cacheOlderThan string
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the cached AST of files not analyzed recently",
+	Long: `Delete the nodes and relationships of the files last analyzed before --older-than, e.g.
+30d, 2w or 12h. Pruned files are analyzed again the next time they are needed.`,
+	Args: cobra.NoArgs,
+	RunE: runCachePrune,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [path]",
+	Short: "Delete the cached AST of the files under a path, or of every file",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runCacheClear,
+}
+
+var cachePathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the path of the AST cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dsn := cache.DSN(); dsn != "" {
+			fmt.Println(cache.RedactDSN(dsn))
+			return nil
+		}
+		cacheDir, err := astCacheDir()
+		if err != nil {
+			return err
+		}
+		fmt.Println(filepath.Join(cacheDir, "ast.db"))
+		return nil
+	},
+}
+
+var cacheMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the AST cache to the schema of this arch-unit version",
+	Long: `Migrate the AST cache of the current project to the schema of this arch-unit version.
+
+Caches written by older versions are upgraded in place. Caches written by newer versions, or that
+cannot be upgraded, are moved aside to ast.db.v<version>.bak and recreated empty, so the next
+analysis rebuilds them. A shared PostgreSQL cache is only upgraded, as other machines may still
+use it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cacheDir, err := astCacheDir()
+		if err != nil {
+			return err
+		}
+
+		backup, err := cache.MigrateCache(cacheDir)
+		if err != nil {
+			return fmt.Errorf("failed to migrate cache: %w", err)
+		}
+		if backup != "" {
+			logger.Infof("Moved the incompatible cache to %s and created an empty cache (schema v%d)", backup, cache.SchemaVersion)
+		} else {
+			logger.Infof("Cache is at schema v%d", cache.SchemaVersion)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheMigrateCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePathCmd)
+
+	cacheStatsCmd.Flags().IntVar(&cacheOldest, "oldest", 10, "Number of the files analyzed longest ago shown")
+	cachePruneCmd.Flags().StringVar(&cacheOlderThan, "older-than", "30d", "Prune files last analyzed before this duration ago (e.g., '30d', '2w', '12h')")
+}
+
+// configureCache sets the PostgreSQL database of the cache section of the arch-unit.yaml found
+// from the working directory, which ARCH_UNIT_CACHE_DSN overrides, and the directory of the
+// SQLite cache: --cache-dir, $ARCH_UNIT_CACHE_DIR, the dir of the cache section, or a directory
+// of the project analyzed
+func configureCache() error {
+	dir, err := GetWorkingDir()
+	if err != nil {
+		return err
+	}
+	cacheConfig, err := config.NewParser(dir).LoadCacheConfig()
+	if err != nil {
+		return err
+	}
+	cache.SetDSN(cacheConfig.ResolveDSN())
+
+	cacheDir := cacheDirFlag
+	if cacheDir == "" {
+		cacheDir = os.Getenv(cache.CacheDirEnvVar)
+	}
+	if cacheDir == "" && cacheConfig != nil {
+		cacheDir = cacheConfig.Dir
+	}
+	if cacheDir != "" && !filepath.IsAbs(cacheDir) {
+		cacheDir = filepath.Join(dir, cacheDir)
+	}
+	cache.SetCacheDir(cacheDir)
+	cache.SetProjectRoot(config.ProjectRoot(dir))
+	return nil
+}
+
+// astCacheDir returns the directory of the AST cache
+func astCacheDir() (string, error) {
+	return cache.CacheDir()
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	cacheDir, err := astCacheDir()
+	if err != nil {
+		return err
+	}
+	stats, err := cache.MustGetASTCache().Stats(cacheOldest)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if dsn := cache.DSN(); dsn != "" {
+		_, _ = fmt.Fprintf(w, "Cache:\t%s (PostgreSQL, schema v%d)\n", cache.RedactDSN(dsn), cache.SchemaVersion)
+	} else {
+		// SQLite keeps recent writes in the write-ahead log until a checkpoint
+		var size int64
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			if info, err := os.Stat(filepath.Join(cacheDir, "ast.db"+suffix)); err == nil {
+				size += info.Size()
+			}
+		}
+		_, _ = fmt.Fprintf(w, "Cache:\t%s (%s, schema v%d)\n", filepath.Join(cacheDir, "ast.db"), formatBytes(size), cache.SchemaVersion)
+	}
+	_, _ = fmt.Fprintf(w, "Files:\t%d\n", stats.Files)
+	_, _ = fmt.Fprintf(w, "Nodes:\t%d\n", stats.Nodes)
+	_, _ = fmt.Fprintf(w, "Relationships:\t%d\n", stats.Relationships)
+	_, _ = fmt.Fprintf(w, "Libraries:\t%d\n", stats.Libraries)
+	_ = w.Flush()
+
+	if len(stats.Languages) > 0 {
+		fmt.Println("\nLanguages:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		for _, language := range stats.Languages {
+			_, _ = fmt.Fprintf(w, "  %s\t%d files\t%d nodes\t\n", language.Language, language.Files, language.Nodes)
+		}
+		_ = w.Flush()
+	}
+	if len(stats.Oldest) > 0 {
+		fmt.Println("\nOldest files:")
+		for _, file := range stats.Oldest {
+			fmt.Printf("  %s  %s\n", file.LastAnalyzed.Format("2006-01-02 15:04"), file.FilePath)
+		}
+	}
+	return nil
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	age, err := parseDuration(cacheOlderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than duration %q: %w", cacheOlderThan, err)
+	}
+	pruned, err := cache.MustGetASTCache().Prune(time.Now().Add(-age))
+	if err != nil {
+		return fmt.Errorf("failed to prune cache: %w", err)
+	}
+	logger.Infof("Pruned %d files not analyzed in the last %s", pruned, cacheOlderThan)
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	astCache := cache.MustGetASTCache()
+	if len(args) == 0 {
+		if err := astCache.ClearAllData(); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		logger.Infof("Cleared the AST cache")
+		return nil
+	}
+
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", args[0], err)
+	}
+	cleared, err := astCache.ClearPath(path)
+	if err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	logger.Infof("Cleared %d files under %s", cleared, path)
+	return nil
+}
+
+// formatBytes formats a size in bytes with a binary unit, e.g. 12.5 MB
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/git"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/objectstore"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheRemote     string
+	cacheRepository string
+	cacheCommit     string
+	cachePullDepth  int
+)
+
+var cachePushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload a snapshot of the AST cache of the repository to a remote cache",
+	Long: `Upload a compressed snapshot of the AST cache of the files of the current Git repository,
+keyed by the name of the repository and the commit checked out, so that other CI jobs can pull it
+instead of analyzing every file again.
+
+The remote is --remote or the remote of the cache section of arch-unit.yaml:
+  s3://bucket/prefix   S3, or an S3 compatible store at $AWS_ENDPOINT_URL_S3 or $AWS_ENDPOINT_URL,
+                       with the credentials of the environment, $AWS_PROFILE, IRSA or instance
+                       metadata
+  gs://bucket/prefix   Google Cloud Storage, or its emulator at $STORAGE_EMULATOR_HOST, with
+                       $GOOGLE_OAUTH_ACCESS_TOKEN or the application default credentials
+  https://host/path    an artifact store accepting PUT and GET, with the bearer token of
+                       $` + objectstore.TokenEnvVar + ` or the user and password of the URL
+  /path                a local or mounted directory
+
+Examples:
+  # After analyzing the default branch
+  arch-unit check && arch-unit cache push --remote s3://ci-cache/arch-unit`,
+	Args: cobra.NoArgs,
+	RunE: runCachePush,
+}
+
+var cachePullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Restore the AST cache of the repository from the snapshot of a recent commit",
+	Long: `Download the snapshot of the AST cache pushed for the commit checked out or, failing that, for
+the closest of its --depth first parent ancestors, and restore it in place of the local cache,
+which is moved aside to ast.db.bak. Paths are moved to the directory the repository is checked out
+in, and files changed since the commit of the snapshot are analyzed again when next needed.
+
+A missing snapshot is not an error, the cache is then built by the next analysis.
+
+Examples:
+  # Before analyzing a pull request
+  arch-unit cache pull --remote s3://ci-cache/arch-unit && arch-unit check`,
+	Args: cobra.NoArgs,
+	RunE: runCachePull,
+}
+
+func init() {
+	cacheCmd.AddCommand(cachePushCmd)
+	cacheCmd.AddCommand(cachePullCmd)
+
+	for _, cmd := range []*cobra.Command{cachePushCmd, cachePullCmd} {
+		cmd.Flags().StringVar(&cacheRemote, "remote", "", "Remote cache, e.g. s3://bucket/prefix, gs://bucket/prefix, https://host/path or a directory (default from the cache section of arch-unit.yaml)")
+		cmd.Flags().StringVar(&cacheRepository, "repo", "", "Name of the repository in the remote cache (default from the origin remote, e.g. github.com/org/repo)")
+		cmd.Flags().StringVar(&cacheCommit, "commit", "", "Commit of the snapshot (default HEAD)")
+	}
+	cachePullCmd.Flags().IntVar(&cachePullDepth, "depth", 20, "Number of first parent ancestors of HEAD searched for a snapshot")
+}
+
+// snapshotKey returns the key of the snapshot of a commit in the remote cache
+func snapshotKey(repository, commit string) string {
+	return repository + "/" + commit + ".tar.gz"
+}
+
+// openCacheRemote returns the store of --remote, or of the cache section of arch-unit.yaml
+func openCacheRemote() (objectstore.Store, error) {
+	remote := cacheRemote
+	if remote == "" {
+		dir, err := GetWorkingDir()
+		if err != nil {
+			return nil, err
+		}
+		cacheConfig, err := config.NewParser(dir).LoadCacheConfig()
+		if err != nil {
+			return nil, err
+		}
+		if cacheConfig != nil {
+			remote = cacheConfig.Remote
+		}
+	}
+	if remote == "" {
+		return nil, fmt.Errorf("no remote cache, use --remote or set cache.remote in arch-unit.yaml")
+	}
+	return objectstore.Open(remote)
+}
+
+// snapshotRepository returns the root of the Git repository of the working directory and its
+// name in the remote cache
+func snapshotRepository() (string, string, error) {
+	if cache.DSN() != "" {
+		return "", "", fmt.Errorf("the AST cache is a shared PostgreSQL database, which needs no snapshots")
+	}
+	dir, err := GetWorkingDir()
+	if err != nil {
+		return "", "", err
+	}
+	root, name, err := git.RepositoryOf(dir)
+	if err != nil {
+		return "", "", err
+	}
+	if cacheRepository != "" {
+		name = cacheRepository
+	}
+	return root, name, nil
+}
+
+func runCachePush(cmd *cobra.Command, args []string) error {
+	root, repository, err := snapshotRepository()
+	if err != nil {
+		return err
+	}
+	store, err := openCacheRemote()
+	if err != nil {
+		return err
+	}
+	commit := cacheCommit
+	if commit == "" {
+		head, dirty, err := git.Head(root)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			logger.Warnf("The working tree has uncommitted changes, files analyzed with them are analyzed again by the jobs pulling %s", head)
+		}
+		commit = head
+	}
+
+	file, err := os.CreateTemp("", "arch-unit-snapshot-*.tar.gz")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+	info, err := cache.MustGetASTCache().WriteSnapshot(file, cache.SnapshotInfo{Repository: repository, Commit: commit, Root: root})
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	key := snapshotKey(repository, commit)
+	if err := store.Put(ctx, key, file, size); err != nil {
+		return fmt.Errorf("failed to push snapshot: %w", err)
+	}
+	logger.Infof("Pushed the cache of %d files of %s (%s) to %s/%s", info.Files, repository, formatBytes(size), store, key)
+	return nil
+}
+
+func runCachePull(cmd *cobra.Command, args []string) error {
+	root, repository, err := snapshotRepository()
+	if err != nil {
+		return err
+	}
+	store, err := openCacheRemote()
+	if err != nil {
+		return err
+	}
+	commits := []string{cacheCommit}
+	if cacheCommit == "" {
+		if commits, err = git.Ancestors(root, cachePullDepth); err != nil {
+			return err
+		}
+	}
+	cacheDir, err := astCacheDir()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for behind, commit := range commits {
+		object, found, err := store.Get(ctx, snapshotKey(repository, commit))
+		if err != nil {
+			return fmt.Errorf("failed to pull snapshot: %w", err)
+		}
+		if !found {
+			continue
+		}
+		info, err := cache.RestoreSnapshot(object, cacheDir, root)
+		_ = object.Close()
+		if err != nil {
+			return fmt.Errorf("failed to restore the snapshot of %s: %w", commit, err)
+		}
+		logger.Infof("Restored the cache of %d files of %s at %s, %d commits behind", info.Files, repository, commit, behind)
+		return nil
+	}
+	logger.Infof("No snapshot of %s in %s for the last %d commits, the cache is built by the next analysis", repository, store, len(commits))
+	return nil
+}
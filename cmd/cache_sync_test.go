@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("cache push and pull", func() {
+	var dir, path, head string
+	var objects map[string]string
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		if _, err := exec.LookPath("git"); err != nil {
+			Skip("git is not installed")
+		}
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+		DeferCleanup(cache.SetCacheDir, "")
+		DeferCleanup(cache.ResetASTCache)
+		cache.SetCacheDir(GinkgoT().TempDir())
+
+		previousDir, previousRemote, previousRepository := workingDir, cacheRemote, cacheRepository
+		DeferCleanup(func() { workingDir, cacheRemote, cacheRepository = previousDir, previousRemote, previousRepository })
+		dir, _ = filepath.EvalSymlinks(GinkgoT().TempDir())
+		workingDir, cacheRepository = dir, "github.com/flanksource/example"
+
+		path = filepath.Join(dir, "order.go")
+		Expect(os.WriteFile(path, []byte("package shop\n\nfunc Place() {}\n"), 0644)).To(Succeed())
+		for _, args := range [][]string{{"init", "--quiet"}, {"add", "-A"}, {"commit", "--quiet", "-m", "base"}} {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+			cmd.Dir = dir
+			output, err := cmd.CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(output))
+		}
+		output, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+		Expect(err).NotTo(HaveOccurred())
+		head = strings.TrimSpace(string(output))
+
+		astCache, err := cache.GetASTCache()
+		Expect(err).NotTo(HaveOccurred())
+		language := "go"
+		Expect(astCache.StoreFileResults(path, &struct{ Nodes []*models.ASTNode }{Nodes: []*models.ASTNode{{
+			ID: 1, FilePath: path, PackageName: "shop", MethodName: "Place",
+			NodeType: models.NodeTypeMethod, Language: &language, StartLine: 3,
+		}}})).To(Succeed())
+
+		// Objects are kept by the path of their bucket and key when the request is authorized
+		var mu sync.Mutex
+		objects = map[string]string{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization := r.Header.Get("Authorization")
+			if authorization != "Bearer ya29.token" && !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch r.Method {
+			case http.MethodPut:
+				data, _ := io.ReadAll(r.Body)
+				objects[r.URL.Path] = string(data)
+			case http.MethodGet:
+				data, ok := objects[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(data))
+			}
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	pushAndPull := func(remote string) {
+		cacheRemote = remote
+		Expect(runCachePush(cachePushCmd, nil)).To(Succeed())
+		Expect(objects).To(HaveKey("/ci-cache/arch-unit/github.com/flanksource/example/" + head + ".tar.gz"))
+
+		// Another job starts with an empty cache
+		cache.ResetASTCache()
+		cache.SetCacheDir(GinkgoT().TempDir())
+		Expect(runCachePull(cachePullCmd, nil)).To(Succeed())
+
+		astCache, err := cache.GetASTCache()
+		Expect(err).NotTo(HaveOccurred())
+		nodes, err := astCache.GetASTNodesByFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes).To(HaveLen(1))
+		Expect(nodes[0].MethodName).To(Equal("Place"))
+	}
+
+	It("should push and pull snapshots through S3", func() {
+		awsDir := GinkgoT().TempDir()
+		GinkgoT().Setenv("AWS_CONFIG_FILE", filepath.Join(awsDir, "config"))
+		GinkgoT().Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(awsDir, "credentials"))
+		GinkgoT().Setenv("AWS_EC2_METADATA_DISABLED", "true")
+		GinkgoT().Setenv("AWS_PROFILE", "")
+		GinkgoT().Setenv("AWS_SESSION_TOKEN", "")
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "AKID")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		GinkgoT().Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+
+		pushAndPull("s3://ci-cache/arch-unit")
+	})
+
+	It("should push and pull snapshots through Google Cloud Storage", func() {
+		GinkgoT().Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "ya29.token")
+		GinkgoT().Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+		pushAndPull("gs://ci-cache/arch-unit")
+	})
+})
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/output"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	callgraphFormat   string
+	callgraphCallers  bool
+	callgraphDepth    int
+	callgraphPackages []string
+)
+
+var callgraphCmd = &cobra.Command{
+	Use:   "callgraph <pattern>",
+	Short: "Show the methods called by, or calling, the methods matching a pattern",
+	Long: `Walk the calls recorded by 'ast analyze' from the methods matching an AST pattern and
+print them as a tree, or export them as a Mermaid, PlantUML or Graphviz DOT diagram.
+
+With --callers the tree shows the methods calling the matched methods instead, up the call
+chain. Each method is expanded once per tree, later calls of it, e.g. recursive calls, are
+marked with ↻. --package only walks into the methods of packages matching a glob.
+
+Examples:
+  # The calls made by Save, three levels deep
+  arch-unit callgraph "service:*:Save" --max-depth 3
+
+  # Who calls the database layer
+  arch-unit callgraph "db:*:*" --callers --max-depth 2
+
+  # Calls between the service and store packages, rendered by Graphviz
+  arch-unit callgraph "api:*:Handle*" --package "service*" --package "store*" --format dot | dot -Tsvg -o calls.svg`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCallgraph,
+}
+
+func init() {
+	rootCmd.AddCommand(callgraphCmd)
+	callgraphCmd.Flags().StringVar(&callgraphFormat, "format", "tree", "Output format: tree, json, mermaid, plantuml or dot")
+	callgraphCmd.Flags().BoolVar(&callgraphCallers, "callers", false, "Walk the methods calling the matched methods instead of those they call")
+	callgraphCmd.Flags().IntVar(&callgraphDepth, "max-depth", 5, "Levels of calls walked, 0 for every level")
+	callgraphCmd.Flags().StringSliceVar(&callgraphPackages, "package", nil, "Globs of the packages walked into, e.g. \"service*\"")
+}
+
+func runCallgraph(cmd *cobra.Command, args []string) error {
+	options := models.CallGraphOptions{
+		Pattern:  args[0],
+		Callers:  callgraphCallers,
+		MaxDepth: callgraphDepth,
+		Packages: callgraphPackages,
+	}
+	trees, err := query.NewAQLEngine(cache.MustGetASTCache()).CallGraph(options)
+	if err != nil {
+		return fmt.Errorf("failed to build the call graph: %w", err)
+	}
+	if len(trees) == 0 {
+		logger.Infof("No methods found matching %s", args[0])
+		logger.Infof("%s", i18n.T("hint.analyze_first"))
+		return nil
+	}
+
+	switch callgraphFormat {
+	case "tree":
+		for _, tree := range trees {
+			output, err := clicky.Format(tree, clicky.FormatOptions{Format: "tree"})
+			if err != nil {
+				return fmt.Errorf("failed to format the call tree: %w", err)
+			}
+			fmt.Print(output)
+		}
+		return nil
+	case "json":
+		data, err := json.MarshalIndent(trees, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	diagram, err := output.RenderGraph(models.CallGraphOf(trees, callgraphCallers), callgraphFormat)
+	if err != nil {
+		return err
+	}
+	fmt.Print(diagram)
+	return nil
+}
@@ -1,27 +1,44 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/git"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/capabilities"
+	"github.com/flanksource/arch-unit/internal/github"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/internal/limits"
+	"github.com/flanksource/arch-unit/internal/signing"
 	"github.com/flanksource/arch-unit/linters"
-	_ "github.com/flanksource/arch-unit/linters/aql"
+	"github.com/flanksource/arch-unit/linters/aql"
 	_ "github.com/flanksource/arch-unit/linters/archunit"
 
 	// "github.com/flanksource/arch-unit/linters/comment" // Temporarily disabled
+	_ "github.com/flanksource/arch-unit/linters/contextcheck"
+	_ "github.com/flanksource/arch-unit/linters/duplication"
 	_ "github.com/flanksource/arch-unit/linters/eslint"
 	_ "github.com/flanksource/arch-unit/linters/golangci"
+	_ "github.com/flanksource/arch-unit/linters/images"
 	_ "github.com/flanksource/arch-unit/linters/markdownlint"
+	_ "github.com/flanksource/arch-unit/linters/nilcheck"
+	_ "github.com/flanksource/arch-unit/linters/osv"
 	_ "github.com/flanksource/arch-unit/linters/pyright"
 	_ "github.com/flanksource/arch-unit/linters/ruff"
+	_ "github.com/flanksource/arch-unit/linters/txcheck"
 	_ "github.com/flanksource/arch-unit/linters/vale"
 	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/output"
+	"github.com/flanksource/arch-unit/pkg/plugins"
+	"github.com/flanksource/arch-unit/query"
 	"github.com/flanksource/clicky"
 	"github.com/flanksource/commons/logger"
 	"github.com/spf13/cobra"
@@ -29,14 +46,30 @@ import (
 
 var (
 	failOnViolation bool
+	failOn          string
 	includePattern  string
 	excludePattern  string
 	lintersFlag     string
 	fixFlag         bool
 	noCacheFlag     bool
+	manifestFile    string
+	baselineFile    string
+	writingBaseline bool
+	githubComment   bool
+	watchFlag       bool
+	watchDebounce   string
+	changedRef      string
 	taskMgrOptions  = clicky.DefaultTaskManagerOptions()
 )
 
+// Flags of `check --explain`, named apart from those of the explain command
+var (
+	checkExplain         bool
+	checkExplainLimit    int
+	checkExplainProvider string
+	checkExplainModel    string
+)
+
 var checkCmd = &cobra.Command{
 	Use:          "check [path] [files...]",
 	Short:        "Check architecture violations in the codebase",
@@ -155,13 +188,34 @@ EXAMPLES:
   Output Formats:
     arch-unit check --json                # JSON output
     arch-unit check --csv                 # CSV output
-    arch-unit check --html -o report.html # HTML report
+    arch-unit check --format ndjson       # Stream violations as JSON lines while they are found
+    arch-unit check -o report.html        # HTML report
+    arch-unit check -o report.sarif -o report.html --format pretty  # Several outputs from one run
+    arch-unit check --format codeclimate > gl-code-quality-report.json  # GitLab Code Quality report
+    arch-unit check --format github --github-comment  # GitHub Actions annotations and pull request comment
+    arch-unit check --format rdjsonl | reviewdog -f=rdjsonl  # Reviewdog diagnostics
+
+  Severity:
+    arch-unit check --fail-on=error       # Report warnings without failing the build
 
   Auto-fixing:
     arch-unit check --fix                 # Auto-fix violations where possible
 
   Performance:
-    arch-unit check --no-cache             # Bypass cache and force re-analysis`,
+    arch-unit check --no-cache             # Bypass cache and force re-analysis
+
+  Pull Requests:
+    arch-unit check --changed              # Check uncommitted changes
+    arch-unit check --changed=origin/main  # Check the changes of the branch
+
+  Watch Mode:
+    arch-unit check --watch                # Re-check changed files until interrupted
+    arch-unit check --watch --debounce 1s  # Wait for changes to settle for 1s
+
+  Compliance:
+    arch-unit check --manifest manifest.json  # Record versions and file hashes
+    arch-unit verify manifest.json            # Confirm a tree matches the manifest
+    arch-unit check --manifest manifest.json --sign-key ci.key  # Also sign the manifest`,
 	Args: cobra.ArbitraryArgs,
 	RunE: runCheck,
 }
@@ -170,11 +224,24 @@ func init() {
 	rootCmd.AddCommand(checkCmd)
 
 	checkCmd.Flags().BoolVar(&failOnViolation, "fail-on-violation", true, "Exit with code 1 if violations are found")
+	checkCmd.Flags().StringVar(&failOn, "fail-on", "info", "Least severity of the violations that exit with code 1: error, warning or info")
 	checkCmd.Flags().StringVar(&includePattern, "include", "", "Include files matching pattern (e.g., '*.go')")
 	checkCmd.Flags().StringVar(&excludePattern, "exclude", "", "Exclude files matching pattern (e.g., '*_test.go')")
 	checkCmd.Flags().StringVar(&lintersFlag, "linters", "*", "Linters to run ('*' for all configured, 'none' to skip, or comma-separated list e.g., 'golangci-lint,ruff,arch-unit')")
 	checkCmd.Flags().BoolVar(&fixFlag, "fix", false, "Automatically fix violations where possible")
 	checkCmd.Flags().BoolVar(&noCacheFlag, "no-cache", false, "Disable caching and force re-analysis of all files")
+	checkCmd.Flags().StringVar(&manifestFile, "manifest", "", "Write a reproducible analysis manifest (tool/linter versions, config and file hashes) to this path")
+	checkCmd.Flags().StringVar(&baselineFile, "baseline", "", "Only report violations that are not part of this baseline, written by 'arch-unit baseline write'")
+	checkCmd.Flags().BoolVar(&githubComment, "github-comment", false, "Post or update a summary of the violations on the pull request of the GitHub Actions workflow")
+	checkCmd.Flags().BoolVar(&watchFlag, "watch", false, "Re-check changed files on every change until interrupted")
+	checkCmd.Flags().StringVar(&watchDebounce, "debounce", "", "Time to wait for changes to settle in watch mode (default: debounce of arch-unit.yaml, or 300ms)")
+	checkCmd.Flags().StringVar(&changedRef, "changed", "", "Only check the files changed since their merge base with this git revision, and the files depending on them")
+	checkCmd.Flags().Lookup("changed").NoOptDefVal = "HEAD"
+	checkCmd.Flags().BoolVar(&checkExplain, "explain", false, "Suggest how to fix each violation with an LLM, shown in pretty, HTML and SARIF output")
+	checkCmd.Flags().IntVar(&checkExplainLimit, "explain-limit", 20, "Maximum number of suggestions generated per check, 0 for no limit; cached suggestions are always shown")
+	checkCmd.Flags().StringVar(&checkExplainProvider, "explain-provider", "", "LLM provider of --explain: anthropic, openai, bedrock or ollama")
+	checkCmd.Flags().StringVar(&checkExplainModel, "explain-model", "", "Model of --explain, defaults to $ARCH_UNIT_LLM_MODEL or a small model of the provider")
+	checkCmd.Flags().StringVar(&signKey, "sign-key", "", "Sign the manifest and output file with this private key (default $"+signing.KeyEnvVar+")")
 
 	// Bind TaskManager flags
 	clicky.BindTaskManagerPFlags(checkCmd.Flags(), taskMgrOptions)
@@ -203,6 +270,48 @@ func isWithinWorkingDirectory(filePath, workingDir string) bool {
 	return !strings.HasPrefix(rel, "..")
 }
 
+// violationFilter returns a filter keeping the violations of the requested files, or of every
+// file within the working directory when no files were requested
+func violationFilter(specificFiles []string, workingDir string) func(models.Violation) bool {
+	if len(specificFiles) == 0 {
+		return func(v models.Violation) bool {
+			return isWithinWorkingDirectory(v.File, workingDir)
+		}
+	}
+
+	// Create a set of requested files in both absolute and relative forms
+	cwd, cwdErr := GetWorkingDir()
+	requestedFiles := make(map[string]bool)
+	for _, f := range specificFiles {
+		requestedFiles[f] = true
+		// Also add relative path from working directory
+		if cwdErr == nil {
+			if rel, err := filepath.Rel(cwd, f); err == nil && !strings.HasPrefix(rel, "../") {
+				requestedFiles[rel] = true
+			}
+		}
+	}
+
+	return func(v models.Violation) bool {
+		// Direct match
+		if requestedFiles[v.File] {
+			return true
+		}
+		if cwdErr != nil {
+			return false
+		}
+
+		// If violation file is relative, try making it absolute
+		if !filepath.IsAbs(v.File) {
+			return requestedFiles[filepath.Join(cwd, v.File)]
+		}
+
+		// If violation file is absolute, try making it relative
+		rel, err := filepath.Rel(cwd, v.File)
+		return err == nil && !strings.HasPrefix(rel, "../") && requestedFiles[rel]
+	}
+}
+
 // parseLintersList parses the linters flag and returns which linters to run
 func parseLintersList(lintersFlag string, archConfig *models.Config) (map[string]bool, bool) {
 	// Handle special cases
@@ -232,6 +341,10 @@ func parseLintersList(lintersFlag string, archConfig *models.Config) (map[string
 }
 
 func runCheck(cmd *cobra.Command, args []string) error {
+	if watchFlag && !watching {
+		return runCheckWatch(cmd, args)
+	}
+
 	// Determine working directory - this is where analysis will be performed
 	var workingDir string
 	var specificFiles []string
@@ -299,19 +412,102 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Only the changed files and the files depending on them are checked, and only the violations
+	// on changed lines or of calls into changed files are reported
+	var diff *git.Diff
+	if changedRef != "" {
+		if len(specificFiles) > 0 {
+			return fmt.Errorf("--changed cannot be combined with specific files")
+		}
+		changed, files, err := changedFiles(workingDir, changedRef)
+		if err != nil {
+			return err
+		}
+		diff, specificFiles = changed, files
+		if len(specificFiles) == 0 {
+			logger.Infof("No files changed since %s", changedRef)
+			return nil
+		}
+	}
+
 	// Determine output format for progress display
 	currentFormat := getOutputFormat()
 
+	failSeverity, err := models.ParseSeverity(failOn)
+	if err != nil {
+		return fmt.Errorf("invalid --fail-on: %w", err)
+	}
+
+	// Resolve output file formats up front so a typo fails before the analysis runs
+	outputFormats, err := resolveOutputFormats(outputFiles)
+	if err != nil {
+		return err
+	}
+
+	// Exceptions of the configuration suppress violations until they expire
+	var exceptions []models.Exception
+	var configDir string
+	excepted := 0
+	now := time.Now()
+
+	// Violations of the baseline are known, only new violations are reported
+	var baseline *models.BaselineMatcher
+	baselined := 0
+	baselineRoot, err := filepath.Abs(workingDir)
+	if err != nil {
+		return fmt.Errorf("invalid working directory %s: %w", workingDir, err)
+	}
+	if baselineFile != "" && !writingBaseline {
+		b, err := models.LoadBaseline(baselineFile)
+		if err != nil {
+			return err
+		}
+		baseline = b.Matcher(baselineRoot)
+	}
+
+	// NDJSON streams each violation to stdout as soon as its linter reports it, instead of
+	// collecting every violation until the end of the run
+	var ndjson *output.NDJSONWriter
+	var streamViolation func(models.Violation)
+	var streamedViolations []models.Violation
+	if currentFormat == "ndjson" && !writingBaseline {
+		ndjson = output.NewNDJSONWriter(os.Stdout)
+		matches := diffFilter(violationFilter(specificFiles, workingDir), diff)
+		streamViolation = func(v models.Violation) {
+			if !matches(v) {
+				return
+			}
+			if models.IsExcepted(exceptions, v, configDir, now) {
+				excepted++
+				return
+			}
+			if baseline != nil && baseline.Matches(v) {
+				baselined++
+				return
+			}
+			if err := ndjson.Write(v); err != nil {
+				logger.Warnf("Failed to stream violation: %v", err)
+			}
+			// Output files are only written at the end of the run, so keep violations for them
+			if len(outputFiles) > 0 {
+				streamedViolations = append(streamedViolations, v)
+			}
+		}
+	}
+
 	var archResult *models.AnalysisResult
 	var linterResults []models.LinterResult
 	var consolidatedResult *models.ConsolidatedResult
 	var requestedLinters map[string]bool
-	var configDir string
 
 	// Load configuration - search from current directory up to git root
 	configParser := config.NewParser(workingDir)
 	archConfig, err := configParser.LoadConfig()
 	foundConfigDir := workingDir
+	if err != nil && !errors.Is(err, config.ErrConfigNotFound) {
+		// A broken config or rule pack, e.g. a checksum mismatch, must not fall back to defaults
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
 	if err != nil {
 		// Use smart defaults based on detected languages in working directory
 		logger.Infof("No arch-unit.yaml found, detecting languages and using smart defaults...")
@@ -337,6 +533,16 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	}
 
 	if archConfig != nil {
+		exceptions = archConfig.Exceptions
+
+		// Extractor helpers spawned during analysis look up their limits from the loaded config
+		limits.SetConfig(archConfig)
+
+		// Plugins register their extractors, linters and rule evaluators before linters are selected
+		if err := plugins.LoadAll(configDir, archConfig.Plugins); err != nil {
+			return fmt.Errorf("failed to load plugins: %w", err)
+		}
+
 		// Initialize linters registry using working directory for analysis
 		// But some linters like ArchUnit might need the config directory for rules
 		// TODO: Fix linter interface mismatch - linters have wrong Run method signature
@@ -358,11 +564,13 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		if runLinters {
 			// Filter config to only run requested linters
 			filteredConfig := &models.Config{
-				Version:   archConfig.Version,
-				Debounce:  archConfig.Debounce,
-				Rules:     archConfig.Rules,
-				Linters:   make(map[string]models.LinterConfig),
-				Languages: archConfig.Languages,
+				Version:     archConfig.Version,
+				Debounce:    archConfig.Debounce,
+				Rules:       archConfig.Rules,
+				Linters:     make(map[string]models.LinterConfig),
+				Languages:   archConfig.Languages,
+				Limits:      archConfig.Limits,
+				Duplication: archConfig.Duplication,
 			}
 
 			// Add arch-unit as a linter if requested
@@ -372,14 +580,28 @@ func runCheck(cmd *cobra.Command, args []string) error {
 				}
 			}
 
-			// Add AQL as a linter if requested and AQL rules are configured
-			if (lintersFlag == "*" || requestedLinters["aql"]) && len(archConfig.AQLRules) > 0 {
+			// Add AQL as a linter if requested and AQL rules, layers, naming conventions, annotation rules, CEL rules,
+			// policies, schema, API, god-object, dead code or deprecated rules or rule evaluators of plugins are configured
+			if (lintersFlag == "*" || requestedLinters["aql"]) && (len(archConfig.AQLRules) > 0 || len(archConfig.Layers) > 0 || len(archConfig.Naming) > 0 || len(archConfig.Annotations) > 0 ||
+				len(archConfig.CELRules) > 0 || len(archConfig.Policies) > 0 || archConfig.Schema.IsEnabled() || archConfig.API.IsEnabled() ||
+				archConfig.GodObjects != nil || archConfig.DeadCode != nil || archConfig.Deprecated != nil || len(aql.RuleEvaluators()) > 0) {
 				filteredConfig.Linters["aql"] = models.LinterConfig{
 					Enabled: true,
 				}
 
-				// Store AQL rules in the filtered config for the linter to access
+				// Store AQL rules, layers, naming conventions, annotation rules, CEL rules, policies, schema, API, god-object, dead code and deprecated rules in the filtered config for the linter to access
 				filteredConfig.AQLRules = archConfig.AQLRules
+				filteredConfig.Layers = archConfig.Layers
+				filteredConfig.Naming = archConfig.Naming
+				filteredConfig.Annotations = archConfig.Annotations
+				filteredConfig.CELRules = archConfig.CELRules
+				filteredConfig.Policies = archConfig.Policies
+				filteredConfig.Schema = archConfig.Schema
+				filteredConfig.API = archConfig.API
+				filteredConfig.GodObjects = archConfig.GodObjects
+				filteredConfig.DeadCode = archConfig.DeadCode
+				filteredConfig.Deprecated = archConfig.Deprecated
+				filteredConfig.Coverage = archConfig.Coverage
 			}
 
 			// Copy only requested linters
@@ -396,18 +618,23 @@ func runCheck(cmd *cobra.Command, args []string) error {
 				}
 			}
 
-			var linterRunner *linters.Runner
-			if noCacheFlag {
-				linterRunner, err = linters.NewRunnerWithOptions(filteredConfig, workingDir, linters.RunnerOptions{NoCache: true})
-			} else {
-				linterRunner, err = linters.NewRunner(filteredConfig, workingDir)
-			}
+			linterRunner, err := linters.NewRunnerWithOptions(filteredConfig, workingDir, linters.RunnerOptions{
+				NoCache:    noCacheFlag,
+				NoDebounce: watching,
+				Stream:     streamViolation,
+			})
 			if err != nil {
 				return fmt.Errorf("failed to create linter runner: %w", err)
 			} else {
 				defer func() { _ = linterRunner.Close() }()
 
-				results, err := linterRunner.RunEnabledLintersOnFiles(specificFiles, fixFlag)
+				// In watch mode only the changed files are linted, violations of the other files
+				// are reported from the cache
+				lintFiles := specificFiles
+				if watchChanged != nil {
+					lintFiles = watchChanged
+				}
+				results, err := linterRunner.RunEnabledLintersOnFiles(lintFiles, fixFlag)
 				if err != nil {
 					return fmt.Errorf("failed to run linters: %w", err)
 				} else {
@@ -422,6 +649,7 @@ func runCheck(cmd *cobra.Command, args []string) error {
 							Error:      result.Error,
 							FileCount:  result.FileCount,
 							RuleCount:  result.RuleCount,
+							Skipped:    result.Skipped,
 						})
 					}
 				}
@@ -440,7 +668,15 @@ func runCheck(cmd *cobra.Command, args []string) error {
 
 	// Create consolidated result by fetching all violations from the database
 	// Skip cache access if --no-cache flag is set
-	if noCacheFlag {
+	if ndjson != nil {
+		if watchChanged != nil {
+			if err := streamCachedViolations(streamViolation, requestedLinters, watchChanged, workingDir); err != nil {
+				logger.Warnf("Failed to stream the violations of unchanged files: %v", err)
+			}
+		}
+		// Violations were streamed as they were found, linter results only carry their status
+		consolidatedResult = models.NewConsolidatedResult(&models.AnalysisResult{Violations: streamedViolations}, linterResults)
+	} else if noCacheFlag {
 		// Use in-memory results only when cache is disabled
 		if len(linterResults) > 0 {
 			consolidatedResult = models.NewConsolidatedResult(archResult, linterResults)
@@ -479,64 +715,27 @@ func runCheck(cmd *cobra.Command, args []string) error {
 				return err
 			}
 
-			logger.Infof("Fetched %d total violations from database, files=%d", len(allViolations), len(specificFiles))
-			// Use violations from database, but filter based on working directory
-			var violations []models.Violation
-			if len(specificFiles) > 0 {
-				// Filter violations to only requested files
-				// Create a set of requested files in both absolute and relative forms
-				requestedFiles := make(map[string]bool)
-
-				for _, f := range specificFiles {
-					requestedFiles[f] = true
-					// Also add relative path from working directory
-					if cwd, err := GetWorkingDir(); err == nil {
-						if rel, err := filepath.Rel(cwd, f); err == nil && !strings.HasPrefix(rel, "../") {
-							requestedFiles[rel] = true
+			// A re-check of watch mode only reports the changed files
+			if watchChanged != nil {
+				var rechecked []models.Violation
+				for _, result := range linterResults {
+					for _, v := range result.Violations {
+						if v.Source == "" {
+							v.Source = result.Linter
 						}
+						rechecked = append(rechecked, v)
 					}
 				}
+				allViolations = mergeWatchedViolations(rechecked, allViolations, watchChanged, workingDir)
+			}
 
-				for _, v := range allViolations {
-					// Check if violation file matches any requested file
-					matched := false
-
-					// Direct match
-					if requestedFiles[v.File] {
-						matched = true
-					}
-
-					// If violation file is relative, try making it absolute
-					if !matched && !filepath.IsAbs(v.File) {
-						if cwd, err := GetWorkingDir(); err == nil {
-							absPath := filepath.Join(cwd, v.File)
-							if requestedFiles[absPath] {
-								matched = true
-							}
-						}
-					}
-
-					// If violation file is absolute, try making it relative
-					if !matched && filepath.IsAbs(v.File) {
-						if cwd, err := GetWorkingDir(); err == nil {
-							if rel, err := filepath.Rel(cwd, v.File); err == nil && !strings.HasPrefix(rel, "../") {
-								if requestedFiles[rel] {
-									matched = true
-								}
-							}
-						}
-					}
-
-					if matched {
-						violations = append(violations, v)
-					}
-				}
-			} else {
-				// Filter violations to only those within the working directory
-				for _, v := range allViolations {
-					if isWithinWorkingDirectory(v.File, workingDir) {
-						violations = append(violations, v)
-					}
+			logger.Infof("Fetched %d total violations from database, files=%d", len(allViolations), len(specificFiles))
+			// Use violations from database, but filter based on working directory
+			matches := diffFilter(violationFilter(specificFiles, workingDir), diff)
+			var violations []models.Violation
+			for _, v := range allViolations {
+				if matches(v) {
+					violations = append(violations, v)
 				}
 			}
 
@@ -557,23 +756,129 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if writingBaseline {
+		var violations []models.Violation
+		for _, v := range consolidatedResult.Violations {
+			if !models.IsExcepted(exceptions, v, configDir, now) {
+				violations = append(violations, v)
+			}
+		}
+		b := models.NewBaseline(violations, baselineRoot)
+		if err := b.Save(baselineFile); err != nil {
+			return err
+		}
+		logger.Infof("Wrote baseline of %d violations to %s", b.Total(), baselineFile)
+		return nil
+	}
+
+	if len(exceptions) > 0 {
+		configFile := filepath.Join(configDir, config.ConfigFileName)
+		if ndjson == nil {
+			consolidatedResult.ApplyExceptions(exceptions, configDir, configFile, now)
+		} else {
+			// Streamed violations were already filtered, only expired exceptions are left to report
+			for i := range exceptions {
+				if exceptions[i].IsExpired(now) {
+					v := exceptions[i].ExpiredViolation(configFile)
+					if err := ndjson.Write(v); err != nil {
+						logger.Warnf("Failed to stream violation: %v", err)
+					}
+					consolidatedResult.Violations = append(consolidatedResult.Violations, v)
+				}
+			}
+			consolidatedResult.GenerateSummary()
+			consolidatedResult.Summary.Excepted = excepted
+		}
+		if consolidatedResult.Summary.Excepted > 0 {
+			logger.Infof("Suppressed %d violations with exceptions", consolidatedResult.Summary.Excepted)
+		}
+	}
+
+	if baseline != nil {
+		if ndjson == nil {
+			consolidatedResult.ApplyBaseline(baseline)
+		} else {
+			consolidatedResult.Summary.Baselined = baselined
+		}
+		logger.Infof("Ignoring %d violations of the baseline %s", consolidatedResult.Summary.Baselined, baselineFile)
+	}
+
+	// Features skipped because a tool they need is missing are reported with the results
+	consolidatedResult.Summary.Capabilities = capabilities.Warnings()
+
+	if checkExplain {
+		var ai *models.AIConfig
+		if archConfig != nil {
+			ai = archConfig.AI
+		}
+		explainViolations(consolidatedResult.Violations, ai)
+	}
+
+	// Full runs are recorded by commit for 'arch-unit history'
+	if diff == nil && len(specificFiles) == 0 && !noCacheFlag {
+		recordRunHistory(workingDir, consolidatedResult)
+	}
+
+	if err := writeOutputFiles(consolidatedResult, outputFormats, workingDir); err != nil {
+		return err
+	}
+
+	if githubComment {
+		// A comment that cannot be posted, e.g. from a fork with a read-only token, does not fail the check
+		if err := postGitHubComment(consolidatedResult, baseline); err != nil {
+			logger.Warnf("Failed to post pull request comment: %v", err)
+		}
+	}
+
+	if manifestFile != "" {
+		linterNames := make([]string, 0, len(linterResults))
+		for _, result := range linterResults {
+			linterNames = append(linterNames, result.Linter)
+		}
+		if err := writeAnalysisManifest(manifestFile, workingDir, linterNames); err != nil {
+			return err
+		}
+		logger.Infof("Wrote analysis manifest to %s", manifestFile)
+	}
+
+	if resolveSigningKey() != "" {
+		var artifacts []string
+		for _, path := range append([]string{manifestFile}, outputFiles...) {
+			if _, err := os.Stat(path); path != "" && err == nil {
+				artifacts = append(artifacts, path)
+			}
+		}
+		if err := signArtifacts(workingDir, artifacts...); err != nil {
+			return err
+		}
+	}
+
+	// Violations never end a watch, they are shown until fixed
+	exitOnViolation := failOnViolation && !watching
+
 	// Display results based on output format
-	if currentFormat == "pretty" && !compact {
+	if ndjson != nil {
+		// Violations were already written to stdout
+		if exitOnViolation && (exitCode != 0 || ndjson.CountAtLeast(failSeverity) > 0 || len(consolidatedResult.GetFailedLinters()) > 0) {
+			os.Exit(1)
+		}
+	} else if currentFormat == "pretty" && !compact {
 		// Display combined violation tree for pretty format
 		displayCombinedViolations(consolidatedResult)
+		displayCapabilityWarnings(consolidatedResult)
 
 		// Exit with appropriate code
-		if failOnViolation && (exitCode != 0 || consolidatedResult.HasFailures()) {
+		if exitOnViolation && (exitCode != 0 || consolidatedResult.HasFailuresAt(failSeverity)) {
 			os.Exit(1)
 		}
 	} else {
 		// Output results in requested format (JSON, CSV, etc.)
-		if err := outputConsolidatedResults(consolidatedResult); err != nil {
+		if err := outputConsolidatedResults(consolidatedResult, workingDir); err != nil {
 			return fmt.Errorf("failed to output consolidated results: %w", err)
 		}
 
 		// Exit with error if violations found and flag is set
-		if failOnViolation && consolidatedResult.HasFailures() {
+		if exitOnViolation && consolidatedResult.HasFailuresAt(failSeverity) {
 			os.Exit(1)
 		}
 	}
@@ -595,7 +900,7 @@ func displayCombinedViolations(result *models.ConsolidatedResult) {
 	if err != nil {
 		logger.Errorf("Failed to format violations tree: %v", err)
 		// Fallback to simple display
-		fmt.Printf("\n📋 Combined Violations (%d total)\n", len(result.Violations))
+		fmt.Printf("\n📋 %s\n", i18n.T("summary.combined", len(result.Violations)))
 		for _, v := range result.Violations {
 			fmt.Printf("- %s\n", v.String())
 		}
@@ -605,14 +910,12 @@ func displayCombinedViolations(result *models.ConsolidatedResult) {
 	fmt.Printf("\n%s\n", output)
 
 	// Print summary
-	fmt.Printf("\n%s Found %d total violation(s)\n",
-		color.RedString("✗"),
-		result.Summary.TotalViolations)
+	fmt.Printf("\n%s %s\n", color.RedString("✗"), i18n.T("summary.total", result.Summary.TotalViolations))
 	if result.Summary.ArchViolations > 0 {
-		fmt.Printf("  - %d architecture violation(s)\n", result.Summary.ArchViolations)
+		fmt.Printf("  - %s\n", i18n.T("summary.arch", result.Summary.ArchViolations))
 	}
 	if result.Summary.LinterViolations > 0 {
-		fmt.Printf("  - %d linter violation(s)\n", result.Summary.LinterViolations)
+		fmt.Printf("  - %s\n", i18n.T("summary.linter", result.Summary.LinterViolations))
 	}
 
 	// Count and display fixable violations
@@ -629,45 +932,195 @@ func displayCombinedViolations(result *models.ConsolidatedResult) {
 	}
 
 	if fixableCount > 0 || unsafeFixableCount > 0 {
-		fmt.Printf("\n%s Fix Summary:\n", color.GreenString("🔧"))
+		fmt.Printf("\n%s %s\n", color.GreenString("🔧"), i18n.T("summary.fix_title"))
 		if fixableCount > 0 {
-			fmt.Printf("  - %d violation(s) can be safely auto-fixed with %s\n",
-				fixableCount, color.CyanString("arch-unit check --fix"))
+			fmt.Printf("  - %s\n", i18n.T("summary.fixable", fixableCount, color.CyanString("arch-unit check --fix")))
 		}
 		if unsafeFixableCount > 0 {
-			fmt.Printf("  - %d violation(s) can be auto-fixed but may be unsafe\n",
-				unsafeFixableCount)
+			fmt.Printf("  - %s\n", i18n.T("summary.unsafe_fixable", unsafeFixableCount))
 		}
 	}
 }
 
+// displayCapabilityWarnings lists the features that were skipped because a tool is missing
+func displayCapabilityWarnings(result *models.ConsolidatedResult) {
+	if result == nil || len(result.Summary.Capabilities) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s %s\n", color.YellowString("⚠"), i18n.T("summary.capabilities_title"))
+	for _, warning := range result.Summary.Capabilities {
+		fmt.Printf("  - %s\n", i18n.T("summary.capability", warning.Feature, warning.Tool))
+	}
+	fmt.Printf("  %s\n", i18n.T("hint.doctor"))
+}
+
 // outputConsolidatedResults outputs consolidated results in the requested format
-func outputConsolidatedResults(result *models.ConsolidatedResult) error {
+func outputConsolidatedResults(result *models.ConsolidatedResult, workingDir string) error {
+	if format := getOutputFormat(); output.IsReportFormat(format) {
+		manager := output.NewOutputManager(format)
+		if format == "prometheus" {
+			manager.SetArchitecture(loadArchitecture(workingDir))
+		}
+		return manager.Output(&models.AnalysisResult{
+			Violations: result.Violations,
+			FileCount:  result.Summary.FilesAnalyzed,
+			RuleCount:  result.Summary.RulesApplied,
+		})
+	}
+
 	// For now, just print a simple summary
-	fmt.Printf("Total violations: %d\n", result.Summary.TotalViolations)
+	fmt.Println(i18n.T("summary.total_violations", result.Summary.TotalViolations))
+	displayCapabilityWarnings(result)
+	return nil
+}
+
+// postGitHubComment posts or updates the summary of the violations on the pull request of the
+// GitHub Actions workflow, with the violations fixed since the baseline when one is used
+func postGitHubComment(result *models.ConsolidatedResult, baseline *models.BaselineMatcher) error {
+	pr, err := github.PullRequestFromEnv()
+	if err != nil {
+		return err
+	}
+
+	fixed := 0
+	if baseline != nil {
+		fixed = baseline.Fixed()
+	}
+	body := output.GitHubComment(&models.AnalysisResult{Violations: result.Violations}, baseline != nil, fixed)
+	if err := github.NewClient().UpsertComment(pr, output.GitHubCommentMarker, body); err != nil {
+		return err
+	}
+	logger.Infof("Updated the arch-unit comment of %s#%d", pr.Repository, pr.Number)
 	return nil
 }
 
 func getOutputFormat() string {
-	// First check if a format flag was provided via clicky
+	// Output files carry their own format, so only the format flag controls stdout
 	format := clicky.Flags.FormatOptions.ResolveFormat()
-	if format != "" && format != "pretty" {
+	if format != "" {
 		return format
 	}
 
-	// Fall back to detecting format from output file extension if no format flag
-	if outputFile != "" {
-		if strings.HasSuffix(outputFile, ".json") {
-			return "json"
-		} else if strings.HasSuffix(outputFile, ".csv") {
-			return "csv"
-		} else if strings.HasSuffix(outputFile, ".html") {
-			return "html"
-		} else if strings.HasSuffix(outputFile, ".md") {
-			return "markdown"
+	// Default to pretty format (tree display)
+	return "pretty"
+}
+
+// resolveOutputFormats returns the format of each output file, in the same order
+func resolveOutputFormats(paths []string) ([]string, error) {
+	formats := make([]string, len(paths))
+	for i, path := range paths {
+		format, err := output.FormatForFile(path)
+		if err != nil {
+			return nil, err
 		}
+		formats[i] = format
 	}
+	return formats, nil
+}
 
-	// Default to pretty format (tree display)
-	return "pretty"
+// writeOutputFiles writes the results of a single analysis to every requested output file, HTML
+// reports and Prometheus metrics include the architecture of the packages under workingDir, HTML
+// and markdown reports the saved reports of arch-unit.yaml
+func writeOutputFiles(result *models.ConsolidatedResult, formats []string, workingDir string) error {
+	if result == nil || len(outputFiles) == 0 {
+		return nil
+	}
+
+	var architecture *models.ArchitectureReport
+	for _, format := range formats {
+		if format == "html" || format == "prometheus" {
+			architecture = loadArchitecture(workingDir)
+			break
+		}
+	}
+	var queryReports []*models.QueryReport
+	for _, format := range formats {
+		if format == "html" || format == "markdown" {
+			queryReports = loadQueryReports(workingDir)
+			break
+		}
+	}
+	for _, format := range formats {
+		if format == "html" {
+			attachSummaries(result.Violations)
+			break
+		}
+	}
+
+	analysisResult := &models.AnalysisResult{
+		Violations: result.Violations,
+		FileCount:  result.Summary.FilesAnalyzed,
+		RuleCount:  result.Summary.RulesApplied,
+	}
+	for i, path := range outputFiles {
+		manager := output.NewOutputManager(formats[i])
+		manager.SetOutputFile(path)
+		manager.SetCompact(compact)
+		manager.SetArchitecture(architecture)
+		manager.SetQueryReports(queryReports)
+		if err := manager.Output(analysisResult); err != nil {
+			return fmt.Errorf("failed to write %s output to %s: %w", formats[i], path, err)
+		}
+		logger.Infof("Wrote %s results to %s", formats[i], path)
+	}
+	return nil
+}
+
+// loadArchitecture summarises the packages under workingDir from the AST cache, reports without
+// it only include violations
+func loadArchitecture(workingDir string) *models.ArchitectureReport {
+	absWorkingDir, err := filepath.Abs(workingDir)
+	if err != nil {
+		logger.Warnf("Failed to resolve %s: %v", workingDir, err)
+		return nil
+	}
+	astCache, err := cache.GetASTCache()
+	if err != nil {
+		logger.Warnf("Failed to open the AST cache for the architecture report: %v", err)
+		return nil
+	}
+	architecture, err := query.NewAQLEngine(astCache).Architecture(absWorkingDir + "/")
+	if err != nil {
+		logger.Warnf("Failed to summarise the architecture: %v", err)
+		return nil
+	}
+	return architecture
+}
+
+// loadQueryReports runs the saved reports of arch-unit.yaml on the files under workingDir, reports
+// that fail to run are left out
+func loadQueryReports(workingDir string) []*models.QueryReport {
+	archConfig, err := config.NewParser(workingDir).LoadConfig()
+	if err != nil || len(archConfig.Reports) == 0 {
+		return nil
+	}
+	absWorkingDir, err := filepath.Abs(workingDir)
+	if err != nil {
+		logger.Warnf("Failed to resolve %s: %v", workingDir, err)
+		return nil
+	}
+	astCache, err := cache.GetASTCache()
+	if err != nil {
+		logger.Warnf("Failed to open the AST cache for the saved reports: %v", err)
+		return nil
+	}
+
+	names := make([]string, 0, len(archConfig.Reports))
+	for name := range archConfig.Reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	engine := query.NewAQLEngine(astCache)
+	var reports []*models.QueryReport
+	for _, name := range names {
+		report, err := engine.RunReport(archConfig, name, absWorkingDir+"/")
+		if err != nil {
+			logger.Warnf("Failed to run the report %s: %v", name, err)
+			continue
+		}
+		reports = append(reports, report)
+	}
+	return reports
 }
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/flanksource/arch-unit/git"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// changedFiles returns the changes since ref, and the changed files within the working directory
+// with the files depending on them according to the AST cache
+func changedFiles(workingDir, ref string) (*git.Diff, []string, error) {
+	absWorkingDir, err := filepath.Abs(workingDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	diff, err := git.ChangedSince(absWorkingDir, ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find the files changed since %s: %w", ref, err)
+	}
+
+	var changed, deleted []string
+	for _, file := range diff.Paths() {
+		if isWithinWorkingDirectory(file, absWorkingDir) {
+			changed = append(changed, file)
+		}
+	}
+	for _, file := range diff.Deleted {
+		if isWithinWorkingDirectory(file, absWorkingDir) {
+			deleted = append(deleted, file)
+		}
+	}
+	if len(changed) == 0 && len(deleted) == 0 {
+		return diff, nil, nil
+	}
+
+	// Files calling into deleted files are checked again, though the deleted files are not
+	dependents, err := reverseDependencies(slices.Concat(changed, deleted))
+	if err != nil {
+		// Without the cache only the changed files are checked
+		logger.Warnf("Failed to find the files depending on the changed files: %v", err)
+	}
+	files := append(changed, dependents...)
+	logger.Infof("Checking %d files changed since %s and %d files depending on them or on %d deleted files", len(changed), ref, len(dependents), len(deleted))
+	return diff, files, nil
+}
+
+// reverseDependencies returns the existing files with nodes calling or referencing the nodes of
+// the given files, other than these files
+func reverseDependencies(files []string) ([]string, error) {
+	astCache, err := cache.GetASTCache()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := astCache.DependentFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	var dependents []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			dependents = append(dependents, path)
+		}
+	}
+	return dependents, nil
+}
+
+// diffFilter narrows a violation filter to the violations attributable to a diff: those on
+// changed lines, and those of calls or references into changed files, e.g. a dependent calling a
+// function moved into a forbidden package. Violations without a line belong to their whole file.
+func diffFilter(matches func(models.Violation) bool, diff *git.Diff) func(models.Violation) bool {
+	if diff == nil {
+		return matches
+	}
+	cwd, _ := GetWorkingDir()
+	return func(v models.Violation) bool {
+		if !matches(v) {
+			return false
+		}
+		file := v.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(cwd, file)
+		}
+		if diff.Contains(file, v.Line) {
+			return true
+		}
+		return v.Called != nil && v.Called.FilePath != "" && diff.Contains(v.Called.FilePath, 0)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("check reports", func() {
+	It("should include the saved reports of arch-unit.yaml in the HTML and markdown outputs", func() {
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+		DeferCleanup(cache.SetCacheDir, "")
+		DeferCleanup(cache.ResetASTCache)
+		cache.SetCacheDir(GinkgoT().TempDir())
+
+		dir, err := filepath.EvalSymlinks(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "arch-unit.yaml"), []byte(`
+version: "1.0"
+queries:
+  services:
+    description: Service types
+    pattern: "*Service"
+reports:
+  review:
+    title: Architecture review
+    queries: [services]
+`), 0644)).To(Succeed())
+
+		astCache, err := cache.GetASTCache()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = astCache.StoreASTNode(&models.ASTNode{
+			FilePath: filepath.Join(dir, "service", "user.go"), PackageName: "service", TypeName: "UserService",
+			NodeType: models.NodeTypeType, StartLine: 7,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = astCache.StoreASTNode(&models.ASTNode{
+			FilePath: "/elsewhere/order.go", PackageName: "other", TypeName: "OrderService",
+			NodeType: models.NodeTypeType, StartLine: 3,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		html, markdown := filepath.Join(dir, "report.html"), filepath.Join(dir, "report.md")
+		previous := outputFiles
+		DeferCleanup(func() { outputFiles = previous })
+		outputFiles = []string{html, markdown}
+		formats, err := resolveOutputFormats(outputFiles)
+		Expect(err).NotTo(HaveOccurred())
+
+		result := models.NewConsolidatedResult(&models.AnalysisResult{}, nil)
+		Expect(writeOutputFiles(result, formats, dir)).To(Succeed())
+
+		for _, path := range []string{html, markdown} {
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("Architecture review"), path)
+			Expect(string(data)).To(ContainSubstring("services (1)"), path)
+			Expect(string(data)).To(ContainSubstring("service.UserService"), path)
+			Expect(string(data)).NotTo(ContainSubstring("OrderService"), path)
+		}
+	})
+})
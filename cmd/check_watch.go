@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// defaultWatchDebounce is the time to wait for changes to settle when neither --debounce nor the
+// debounce of arch-unit.yaml are set
+const defaultWatchDebounce = 300 * time.Millisecond
+
+var (
+	// watching is true while runCheckWatch re-runs runCheck
+	watching bool
+	// watchChanged holds the files changed since the previous check of watch mode, nil when every
+	// file is checked
+	watchChanged []string
+)
+
+// watchIgnoredDirs are directories whose changes never trigger a check
+var watchIgnoredDirs = map[string]bool{
+	".git":         true,
+	".arch-unit":   true,
+	"node_modules": true,
+	"vendor":       true,
+	"__pycache__":  true,
+	".venv":        true,
+}
+
+// runCheckWatch checks the working directory, then re-checks the files changed since the previous
+// check every time changes settle, until interrupted. Violations of the other files are reported
+// from the violation cache, so the tree shown stays complete, and never end the watch.
+func runCheckWatch(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("--watch checks a directory, not specific files")
+	}
+	if len(args) == 1 {
+		if info, err := os.Stat(args[0]); err != nil || !info.IsDir() {
+			return fmt.Errorf("--watch checks a directory, not specific files")
+		}
+		if workingDir, err = filepath.Abs(args[0]); err != nil {
+			return err
+		}
+	}
+	if noCacheFlag {
+		return fmt.Errorf("--watch reports unchanged files from the cache and cannot be used with --no-cache")
+	}
+
+	debounce, err := resolveWatchDebounce(workingDir)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer func() { _ = watcher.Close() }()
+	if err := watchTree(watcher, workingDir); err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	watching = true
+	defer func() { watching, watchChanged = false, nil }()
+	if err := runWatchedCheck(cmd, args, workingDir, nil, nil); err != nil {
+		return err
+	}
+
+	changed := make(map[string]bool)
+	removed := make(map[string]bool)
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Warnf("File watcher error: %v", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if isWatchIgnored(workingDir, event.Name) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// New directories are watched too, files created in them before the watch
+					// was added are picked up by the next change
+					if err := watchTree(watcher, event.Name); err != nil {
+						logger.Warnf("Failed to watch %s: %v", event.Name, err)
+					}
+					continue
+				}
+			}
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				removed[event.Name] = true
+				delete(changed, event.Name)
+			} else if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
+				changed[event.Name] = true
+				delete(removed, event.Name)
+			} else {
+				continue
+			}
+			timer.Reset(debounce)
+		case <-timer.C:
+			if err := runWatchedCheck(cmd, args, workingDir, sortedKeys(changed), sortedKeys(removed)); err != nil {
+				logger.Errorf("Check failed: %v", err)
+			}
+			changed = make(map[string]bool)
+			removed = make(map[string]bool)
+		}
+	}
+}
+
+// runWatchedCheck clears the screen and checks the changed files, or every file when none
+// changed. The violations of removed files are dropped from the cache.
+func runWatchedCheck(cmd *cobra.Command, args []string, workingDir string, changed, removed []string) error {
+	if len(removed) > 0 {
+		violationCache, err := cache.NewViolationCache()
+		if err != nil {
+			return fmt.Errorf("failed to open violation cache: %w", err)
+		}
+		err = violationCache.ClearFileCache(removed)
+		_ = violationCache.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if getOutputFormat() == "pretty" && isTerminal(os.Stdout) {
+		fmt.Print("\033[H\033[2J")
+	}
+	if removed == nil {
+		logger.Infof("Watching %s for changes, press Ctrl+C to stop", workingDir)
+	} else {
+		names := make([]string, 0, len(changed)+len(removed))
+		for _, file := range append(append([]string{}, changed...), removed...) {
+			if rel, err := filepath.Rel(workingDir, file); err == nil {
+				file = rel
+			}
+			names = append(names, file)
+		}
+		logger.Infof("%s changed, re-checking", strings.Join(names, ", "))
+	}
+
+	// Removals alone re-check every file, as the removed files may have been required by others
+	watchChanged = nil
+	if len(changed) > 0 {
+		watchChanged = changed
+	}
+	return runCheck(cmd, args)
+}
+
+// resolveWatchDebounce returns the debounce of --debounce, else of arch-unit.yaml
+func resolveWatchDebounce(workingDir string) (time.Duration, error) {
+	if watchDebounce != "" {
+		debounce, err := time.ParseDuration(watchDebounce)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --debounce: %w", err)
+		}
+		return debounce, nil
+	}
+	archConfig, err := config.NewParser(workingDir).LoadConfig()
+	if err != nil || archConfig.Debounce == "" {
+		return defaultWatchDebounce, nil
+	}
+	return archConfig.GetDebounceDuration()
+}
+
+// watchTree watches a directory and its subdirectories, except ignored and hidden ones
+func watchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != root && (watchIgnoredDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// isWatchIgnored returns true for files in ignored or hidden directories, and for hidden and
+// temporary files such as editor swap files
+func isWatchIgnored(workingDir, path string) bool {
+	rel, err := filepath.Rel(workingDir, path)
+	if err != nil {
+		return true
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, part := range parts[:len(parts)-1] {
+		if watchIgnoredDirs[part] || strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	name := parts[len(parts)-1]
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || strings.HasSuffix(name, ".swp")
+}
+
+// mergeWatchedViolations returns the violations of a re-check of the changed files with the cached
+// violations of the other files, which a re-check does not report. Cached violations of the files
+// re-checked are left out, as they may have been fixed since.
+func mergeWatchedViolations(rechecked, cached []models.Violation, changed []string, workingDir string) []models.Violation {
+	checked := make(map[string]bool, len(changed))
+	for _, file := range changed {
+		checked[watchedPath(workingDir, file)] = true
+	}
+	for _, v := range rechecked {
+		checked[watchedPath(workingDir, v.File)] = true
+	}
+
+	merged := append([]models.Violation{}, rechecked...)
+	for _, v := range cached {
+		if !checked[watchedPath(workingDir, v.File)] {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+// streamCachedViolations streams the cached violations of the files a re-check of the changed files
+// left unchanged, so that the stream of every check of watch mode is complete
+func streamCachedViolations(stream func(models.Violation), requestedLinters map[string]bool, changed []string, workingDir string) error {
+	violationCache, err := cache.NewViolationCache()
+	if err != nil {
+		return fmt.Errorf("failed to open violation cache: %w", err)
+	}
+	defer func() { _ = violationCache.Close() }()
+
+	var cached []models.Violation
+	if lintersFlag == "*" {
+		cached, err = violationCache.GetAllViolations()
+	} else {
+		cached, err = violationCache.GetViolationsBySources(sortedKeys(requestedLinters))
+	}
+	if err != nil {
+		return err
+	}
+	for _, v := range mergeWatchedViolations(nil, cached, changed, workingDir) {
+		stream(v)
+	}
+	return nil
+}
+
+// watchedPath returns the absolute path of a file of the working directory
+func watchedPath(workingDir, file string) string {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(workingDir, file)
+	}
+	return filepath.Clean(file)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
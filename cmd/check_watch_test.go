@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Watch mode", func() {
+	DescribeTable("ignoring changes",
+		func(path string, ignored bool) {
+			Expect(isWatchIgnored("/repo", path)).To(Equal(ignored))
+		},
+		Entry("source file", "/repo/service/user.go", false),
+		Entry("file of the root", "/repo/main.go", false),
+		Entry("git directory", "/repo/.git/index", true),
+		Entry("vendored file", "/repo/vendor/github.com/pkg/errors/errors.go", true),
+		Entry("node module", "/repo/web/node_modules/react/index.js", true),
+		Entry("hidden directory", "/repo/.idea/workspace.xml", true),
+		Entry("hidden file", "/repo/service/.user.go.tmp", true),
+		Entry("backup file", "/repo/service/user.go~", true),
+		Entry("swap file", "/repo/service/user.go.swp", true),
+	)
+
+	Describe("resolving the debounce", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			DeferCleanup(func() { watchDebounce = "" })
+		})
+
+		It("should prefer --debounce", func() {
+			Expect(os.WriteFile(filepath.Join(dir, config.ConfigFileName), []byte("version: \"1.0\"\ndebounce: \"2s\"\n"), 0644)).To(Succeed())
+			watchDebounce = "750ms"
+			Expect(resolveWatchDebounce(dir)).To(Equal(750 * time.Millisecond))
+		})
+
+		It("should use the debounce of arch-unit.yaml", func() {
+			Expect(os.WriteFile(filepath.Join(dir, config.ConfigFileName), []byte("version: \"1.0\"\ndebounce: \"2s\"\n"), 0644)).To(Succeed())
+			Expect(resolveWatchDebounce(dir)).To(Equal(2 * time.Second))
+		})
+
+		It("should default without a debounce", func() {
+			Expect(resolveWatchDebounce(dir)).To(Equal(defaultWatchDebounce))
+		})
+
+		It("should refuse an invalid --debounce", func() {
+			watchDebounce = "soon"
+			_, err := resolveWatchDebounce(dir)
+			Expect(err).To(MatchError(ContainSubstring("invalid --debounce")))
+		})
+	})
+
+	Describe("merging re-checks", func() {
+		violation := func(file string, line int, source string) models.Violation {
+			return models.Violation{File: file, Line: line, Source: source}
+		}
+
+		It("should keep the cached violations of unchanged files", func() {
+			cached := []models.Violation{
+				violation("/repo/service/user.go", 10, "golangci-lint"),
+				violation("/repo/service/order.go", 20, "golangci-lint"),
+				violation("/repo/api/handler.go", 30, "aql"),
+			}
+			rechecked := []models.Violation{violation("/repo/service/user.go", 12, "golangci-lint")}
+
+			Expect(mergeWatchedViolations(rechecked, cached, []string{"/repo/service/user.go"}, "/repo")).To(ConsistOf(
+				violation("/repo/service/user.go", 12, "golangci-lint"),
+				violation("/repo/service/order.go", 20, "golangci-lint"),
+				violation("/repo/api/handler.go", 30, "aql"),
+			))
+		})
+
+		It("should drop the cached violations of changed files fixed since", func() {
+			cached := []models.Violation{
+				violation("service/user.go", 10, "golangci-lint"),
+				violation("service/order.go", 20, "golangci-lint"),
+			}
+
+			Expect(mergeWatchedViolations(nil, cached, []string{"/repo/service/user.go"}, "/repo")).To(ConsistOf(
+				violation("service/order.go", 20, "golangci-lint"),
+			))
+		})
+
+		It("should replace the cached violations of other files reported by a re-check", func() {
+			cached := []models.Violation{violation("/repo/api/handler.go", 30, "aql")}
+			rechecked := []models.Violation{violation("/repo/api/handler.go", 31, "aql")}
+
+			Expect(mergeWatchedViolations(rechecked, cached, []string{"/repo/service/user.go"}, "/repo")).To(ConsistOf(
+				violation("/repo/api/handler.go", 31, "aql"),
+			))
+		})
+	})
+})
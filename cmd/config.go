@@ -460,7 +460,7 @@ cache:
   # Enable/disable violation caching for faster subsequent runs
   enabled: true
   
-  # Cache directory (default: ~/.cache/arch-unit)
+  # Directory of the AST cache (default: a directory per project under ~/.cache/arch-unit/projects)
   # dir: "/custom/cache/path"
 
 # Output formatting options
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky"
+	"github.com/spf13/cobra"
+)
+
+var configValidateStrict bool
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check arch-unit.yaml and .ARCHUNIT files for mistakes that silently do nothing",
+	Long: `Check the arch-unit.yaml of the working directory and the .ARCHUNIT files of its repository:
+
+  - keys and values against the JSON Schema printed by 'arch-unit config schema', reporting
+    unknown keys with the key they were probably meant to be
+  - the errors 'arch-unit check' would fail on, e.g. invalid severities or durations
+  - rule patterns that are not valid globs, or that match no files so their rules never apply
+  - .ARCHUNIT lines that cannot be parsed, and file patterns that match no files
+
+Exits with an error when errors are found, and also on warnings with --strict.
+
+Examples:
+  # Validate the configuration of the working directory
+  arch-unit config validate
+
+  # Fail CI on warnings too
+  arch-unit config validate --strict`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runConfigValidate,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of arch-unit.yaml",
+	Long: `Print the JSON Schema of arch-unit.yaml, for editors to complete and check the configuration,
+e.g. with the YAML language server:
+
+  arch-unit config schema > arch-unit.schema.json
+
+and on the first line of arch-unit.yaml:
+
+  # yaml-language-server: $schema=./arch-unit.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := json.MarshalIndent(config.Schema(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configValidateCmd.Flags().BoolVar(&configValidateStrict, "strict", false, "Exit with an error on warnings too")
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	issues, err := config.NewParser(workingDir).Validate()
+	if errors.Is(err, config.ErrConfigNotFound) {
+		return fmt.Errorf("no %s or %s files found, run 'arch-unit init' to create one", config.ConfigFileName, config.ArchUnitFileName)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("✓ Configuration is valid")
+		return nil
+	}
+
+	errorCount := 0
+	for i := range issues {
+		if rel, err := filepath.Rel(workingDir, issues[i].File); err == nil && !strings.HasPrefix(rel, "..") {
+			issues[i].File = rel
+		}
+		if issues[i].Severity == models.SeverityError {
+			errorCount++
+		}
+	}
+	fmt.Println(clicky.MustFormat(issues))
+
+	warnings := len(issues) - errorCount
+	if errorCount > 0 || (configValidateStrict && warnings > 0) {
+		return fmt.Errorf("configuration has %d errors and %d warnings", errorCount, warnings)
+	}
+	return nil
+}
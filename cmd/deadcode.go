@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var deadcodeAll bool
+
+var deadcodeCmd = &cobra.Command{
+	Use:   "deadcode [package]",
+	Short: "Show unexported symbols that are never referenced",
+	Long: `Show the unexported functions, methods, types and variables analyzed by 'ast analyze'
+that nothing references.
+
+A symbol is referenced by the relationships pointing to it, or when its name is used
+by the calls, statements, signatures or field types of another symbol of its package.
+main, init, exported symbols and the symbols of test files are never reported.
+
+The dead_code section of arch-unit.yaml reports the same symbols as violations of the
+aql linter:
+  dead_code:
+    ignore: ["**/generated/**"]
+    severity: warning
+
+Examples:
+  # Show the dead code of the working directory
+  arch-unit deadcode
+
+  # Show the dead code of the packages starting with internal as JSON
+  arch-unit deadcode "internal*" --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDeadcode,
+}
+
+func init() {
+	rootCmd.AddCommand(deadcodeCmd)
+	deadcodeCmd.Flags().BoolVar(&deadcodeAll, "all", false, "Include symbols outside the working directory")
+}
+
+func runDeadcode(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	pathPrefix := workingDir + "/"
+	if deadcodeAll {
+		pathPrefix = ""
+	}
+
+	dead, err := query.NewAQLEngine(cache.MustGetASTCache()).DeadCode(pathPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to find dead code: %w", err)
+	}
+
+	if len(args) > 0 {
+		pattern := &models.AQLPattern{Package: args[0]}
+		var matching []*models.DeadSymbol
+		for _, symbol := range dead {
+			if pattern.Matches(&models.ASTNode{PackageName: symbol.Package}) {
+				matching = append(matching, symbol)
+			}
+		}
+		dead = matching
+	}
+
+	if len(dead) == 0 {
+		logger.Infof("No dead code found in cache for %s", workingDir)
+		return nil
+	}
+
+	fmt.Println(clicky.MustFormat(dead))
+	return nil
+}
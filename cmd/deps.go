@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/flanksource/arch-unit/analysis"
 	"github.com/flanksource/arch-unit/analysis/dependencies"
 	goAnalysis "github.com/flanksource/arch-unit/analysis/go"
+	pythonAnalysis "github.com/flanksource/arch-unit/analysis/python"
+	"github.com/flanksource/arch-unit/config"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky"
 	"github.com/flanksource/clicky/task"
@@ -20,6 +24,7 @@ var (
 	depsNoCache       bool
 	depsGitCacheDir   string
 	depsShowConflicts bool
+	depsHelmSubcharts bool
 )
 
 var depsCmd = &cobra.Command{
@@ -34,12 +39,24 @@ Path can be a local directory or git URL:
 
 Supported dependency files:
   - Go: go.mod, go.sum
-  - JavaScript/TypeScript: package.json, package-lock.json, yarn.lock
-  - Python: requirements.txt, Pipfile, pyproject.toml, poetry.lock
+  - JavaScript/TypeScript: package.json, package-lock.json, yarn.lock, pnpm-lock.yaml
+  - Python: requirements*.txt, Pipfile, Pipfile.lock, pyproject.toml, poetry.lock, setup.py, setup.cfg
   - Helm: Chart.yaml
-  - Docker: Dockerfile
+  - Kustomize: kustomization.yaml
+  - Terraform: *.tf, .terraform.lock.hcl
+  - .NET: *.csproj, *.fsproj, *.vbproj, packages.config, packages.lock.json
+  - Bazel: MODULE.bazel, WORKSPACE, WORKSPACE.bazel
+  - Docker: Dockerfile, docker-compose*.yml, compose*.yml
 
-Use --depth > 0 to enable git repository traversal and version conflict detection.`,
+Use --depth > 0 to enable git repository traversal and version conflict detection.
+
+The subcharts of Helm charts vendored in charts/ are scanned for transitive chart dependencies
+and images. Use --helm-subcharts to download the subcharts missing from charts/ from their
+repository.
+
+Private registries and Git hosts are authenticated with the registries of arch-unit.yaml, the
+GH_TOKEN, GITHUB_TOKEN, GH_ENTERPRISE_TOKEN (for GH_HOST), GITLAB_TOKEN and NPM_TOKEN environment
+variables, and the machines of ~/.netrc, in that order.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runDeps,
 }
@@ -79,6 +96,7 @@ func init() {
 	depsCmd.PersistentFlags().BoolVar(&depsNoCache, "no-cache", false, "Bypass cache for Git URL resolution")
 	depsCmd.PersistentFlags().StringVar(&depsGitCacheDir, "git-cache-dir", ".cache/arch-unit/repositories", "Directory for git repository cache")
 	depsCmd.PersistentFlags().BoolVar(&depsShowConflicts, "show-conflicts", false, "Show version conflicts in output")
+	depsCmd.PersistentFlags().BoolVar(&depsHelmSubcharts, "helm-subcharts", false, "Download Helm subcharts missing from charts/ to scan their dependencies and images")
 }
 
 func runDeps(cmd *cobra.Command, args []string) error {
@@ -139,8 +157,15 @@ func performDependencyScan(ctx clicky.Context, t *clicky.Task, path string) (*mo
 		analysis.SetResolutionServiceTTL(0) // TTL of 0 means no caching
 	}
 
+	// Authenticate to private registries with the credentials of arch-unit.yaml, the environment and ~/.netrc
+	analysis.SetCredentials(analysis.NewCredentials(loadRegistries(path)))
+
 	// Create resolution service EARLY to avoid lazy initialization deadlock in parallel tasks
-	resolver, err := analysis.GetResolutionService()
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := analysis.GetResolutionService(config.ProjectRoot(absPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get resolution service: %w", err)
 	}
@@ -151,7 +176,7 @@ func performDependencyScan(ctx clicky.Context, t *clicky.Task, path string) (*mo
 	// Copy all existing scanners from the default registry
 	defaultRegistry := analysis.GetDefaultRegistry()
 	for _, lang := range defaultRegistry.List() {
-		if lang != "go" && lang != "helm" && lang != "docker" { // Skip go, helm, and docker, we'll add our enhanced versions
+		if lang != "go" && lang != "helm" && lang != "docker" && lang != "compose" && lang != "kustomize" && lang != "terraform" && lang != "bazel" && lang != "npm" && lang != "python" { // Skip the scanners added below with the resolver
 			if existingScanner, ok := defaultRegistry.Get(lang); ok {
 				registry.Register(existingScanner)
 			}
@@ -163,13 +188,41 @@ func performDependencyScan(ctx clicky.Context, t *clicky.Task, path string) (*mo
 	registry.Register(goScanner)
 
 	// Add enhanced Helm scanner with resolver
-	helmScanner := dependencies.NewHelmDependencyScannerWithResolver(resolver)
+	helmScanner := dependencies.NewHelmDependencyScannerWithResolver(resolver).WithDownload(depsHelmSubcharts)
 	registry.Register(helmScanner)
 
 	// Add enhanced Docker scanner with resolver
 	dockerScanner := dependencies.NewDockerDependencyScannerWithResolver(resolver)
 	registry.Register(dockerScanner)
 
+	// Add enhanced Docker Compose scanner with resolver
+	composeScanner := dependencies.NewComposeDependencyScannerWithResolver(resolver)
+	registry.Register(composeScanner)
+
+	// Add enhanced Kustomize scanner with resolver
+	kustomizeScanner := dependencies.NewKustomizeDependencyScannerWithResolver(resolver)
+	registry.Register(kustomizeScanner)
+
+	// Add enhanced Terraform scanner with resolver
+	terraformScanner := dependencies.NewTerraformDependencyScannerWithResolver(resolver)
+	registry.Register(terraformScanner)
+
+	// Add enhanced Bazel scanner with resolver
+	bazelScanner := dependencies.NewBazelDependencyScannerWithResolver(resolver)
+	registry.Register(bazelScanner)
+
+	// Add enhanced npm scanner with resolver
+	npmScanner := dependencies.NewNpmDependencyScannerWithResolver(resolver)
+	registry.Register(npmScanner)
+
+	// Add enhanced Python scanner with resolver
+	pythonScanner := pythonAnalysis.NewPythonDependencyScannerWithResolver(resolver)
+	registry.Register(pythonScanner)
+
+	// Add NuGet scanner
+	nugetScanner := dependencies.NewNugetDependencyScanner()
+	registry.Register(nugetScanner)
+
 	// Create scanner with custom registry
 	scanner := dependencies.NewScannerWithRegistry(registry)
 
@@ -219,6 +272,24 @@ func performDependencyScan(ctx clicky.Context, t *clicky.Task, path string) (*mo
 	return result, nil
 }
 
+// loadRegistries returns the registries configured in the arch-unit.yaml of a local path, or of
+// the working directory when scanning a Git URL
+func loadRegistries(path string) []models.RegistryConfig {
+	dir, err := filepath.Abs(path)
+	if info, statErr := os.Stat(path); statErr != nil || !info.IsDir() {
+		dir, err = GetWorkingDir()
+	}
+	if err != nil {
+		return nil
+	}
+	archConfig, err := config.NewParser(dir).LoadConfig()
+	if err != nil {
+		logger.Debugf("No registry credentials loaded: %v", err)
+		return nil
+	}
+	return archConfig.Registries
+}
+
 func runDepsTree(cmd *cobra.Command, args []string) error {
 	// Tree and list commands use the same implementation
 	return runDepsScan(cmd, args)
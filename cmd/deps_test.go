@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/clicky/task"
+)
+
+var _ = Describe("deps", func() {
+	It("should discover the NuGet packages of .NET project files", func() {
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+		DeferCleanup(analysis.ResetResolutionService)
+		DeferCleanup(cache.ResetASTCache)
+
+		dir := filepath.Join("testdata", "nuget")
+		result, err := task.StartTask("Dependency Scan", func(ctx clicky.Context, t *clicky.Task) (*models.ScanResult, error) {
+			return performDependencyScan(ctx, t, dir)
+		}).GetResult()
+		Expect(err).NotTo(HaveOccurred())
+
+		var deps []string
+		for _, dep := range result.Dependencies {
+			deps = append(deps, fmt.Sprintf("%s %s@%s %s", dep.Type, dep.Name, dep.Version, dep.Source))
+		}
+		Expect(deps).To(ConsistOf(
+			"nuget Serilog@3.1.1 Shop.Api.csproj:8",
+			"nuget Swashbuckle.AspNetCore@6.5.0 Shop.Api.csproj:9",
+		))
+	})
+})
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/flanksource/arch-unit/ast"
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/git"
+	"github.com/flanksource/arch-unit/internal/archdiff"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/limits"
+	"github.com/flanksource/arch-unit/linters/aql"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky"
+	commonsContext "github.com/flanksource/commons/context"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var diffComplexityThreshold int
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <base> [head]",
+	Short: "Compare the architecture between two git revisions or cache snapshots",
+	Long: `Compare the architecture of two revisions and print the differences as markdown,
+suitable for pull request summaries:
+
+  - dependencies between packages added or removed
+  - violations of the AQL rules of arch-unit.yaml that are new, and the number fixed
+  - functions whose cyclomatic complexity grew above --complexity-threshold
+  - public types, functions and fields added, removed or whose signature changed
+
+Each revision is a git ref, checked out into a temporary worktree and analyzed into its own
+cache, or a directory holding an AST cache (ast.db) analyzed earlier. Without head the working
+tree is compared. The rules of the working directory are used for both revisions, so only
+changes of the code make violations new.
+
+Examples:
+  # Compare the working tree with main
+  arch-unit diff main
+
+  # Compare two tags
+  arch-unit diff v1.0.0 v1.1.0
+
+  # Compare two cache snapshots
+  arch-unit diff ./before ./after
+
+  # Summarise a pull request
+  arch-unit diff origin/main HEAD > summary.md`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().IntVar(&diffComplexityThreshold, "complexity-threshold", archdiff.DefaultComplexityThreshold, "Cyclomatic complexity above which more complex functions are reported")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	dir, err := GetWorkingDir()
+	if err != nil {
+		return err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	archConfig, err := config.NewParser(absDir).LoadConfig()
+	if err != nil && !errors.Is(err, config.ErrConfigNotFound) {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	limits.SetConfig(archConfig)
+
+	base, err := diffSnapshot(absDir, args[0], archConfig)
+	if err != nil {
+		return err
+	}
+	head := ""
+	if len(args) > 1 {
+		head = args[1]
+	}
+	headSnapshot, err := diffSnapshot(absDir, head, archConfig)
+	if err != nil {
+		return err
+	}
+
+	report := archdiff.Compare(base, headSnapshot, diffComplexityThreshold)
+	fmt.Print(report.Markdown(headSnapshot.Root))
+	return nil
+}
+
+// diffSnapshot captures the architecture of a revision: the working tree when rev is empty, the
+// cache of a directory holding an ast.db, or else a git ref
+func diffSnapshot(dir, rev string, archConfig *models.Config) (*archdiff.Snapshot, error) {
+	switch {
+	case rev == "":
+		astCache, err := cache.GetASTCache()
+		if err != nil {
+			return nil, err
+		}
+		return analyzeSnapshot(astCache, "working tree", dir, dir, archConfig)
+
+	case isCacheSnapshot(rev):
+		cacheDir := rev
+		if filepath.Base(rev) == "ast.db" {
+			cacheDir = filepath.Dir(rev)
+		}
+		astCache, err := cache.NewASTCacheWithPath(cacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the cache snapshot %s: %w", rev, err)
+		}
+		defer func() { _ = astCache.Close() }()
+		// Snapshots hold the paths they were analyzed at, those of the working directory
+		violations, err := snapshotViolations(astCache, dir, archConfig)
+		if err != nil {
+			return nil, err
+		}
+		return archdiff.Capture(astCache, rev, dir, violations)
+	}
+
+	tmp, err := os.MkdirTemp("", "arch-unit-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	worktree := filepath.Join(tmp, "src")
+	checkout, err := git.Checkout(dir, rev, worktree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", rev, err)
+	}
+	defer func() {
+		if err := git.RemoveCheckout(dir, worktree); err != nil {
+			logger.Warnf("Failed to remove the worktree of %s: %v", rev, err)
+		}
+	}()
+
+	astCache, err := cache.NewASTCacheWithPath(filepath.Join(tmp, "cache"))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = astCache.Close() }()
+	return analyzeSnapshot(astCache, rev, checkout, dir, archConfig)
+}
+
+// analyzeSnapshot analyzes root into astCache and captures its architecture, evaluating the rules
+// of the working directory
+func analyzeSnapshot(astCache *cache.ASTCache, label, root, dir string, archConfig *models.Config) (*archdiff.Snapshot, error) {
+	task := clicky.StartTask(fmt.Sprintf("Analyzing %s", label), func(ctx commonsContext.Context, t *clicky.Task) (bool, error) {
+		coordinator := ast.NewCoordinator(astCache, root, ast.CoordinatorOptions{})
+		if _, err := coordinator.AnalyzeDirectory(t, root); err != nil {
+			return false, fmt.Errorf("failed to analyze %s: %w", label, err)
+		}
+		return true, nil
+	})
+	if _, err := task.GetResult(); err != nil {
+		return nil, err
+	}
+
+	violations, err := snapshotViolations(astCache, dir, archConfig)
+	if err != nil {
+		return nil, err
+	}
+	return archdiff.Capture(astCache, label, root, violations)
+}
+
+// snapshotViolations evaluates the AQL rules of arch-unit.yaml against astCache
+func snapshotViolations(astCache *cache.ASTCache, dir string, archConfig *models.Config) ([]models.Violation, error) {
+	if archConfig == nil {
+		return nil, nil
+	}
+	linter := aql.NewAQLWithConfig(dir, archConfig)
+	linter.SetCache(astCache)
+	task := clicky.StartTask("Evaluating rules", func(ctx commonsContext.Context, t *clicky.Task) ([]models.Violation, error) {
+		return linter.Run(ctx, t)
+	})
+	violations, err := task.GetResult()
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate rules: %w", err)
+	}
+	return violations, nil
+}
+
+// isCacheSnapshot returns true for an ast.db file or a directory holding one
+func isCacheSnapshot(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return filepath.Base(path) == "ast.db"
+	}
+	_, err = os.Stat(filepath.Join(path, "ast.db"))
+	return err == nil
+}
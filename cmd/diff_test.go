@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("diff", func() {
+	var dir string
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(output))
+	}
+
+	writeFile := func(name, content string) {
+		Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		if _, err := exec.LookPath("git"); err != nil {
+			Skip("git is not installed")
+		}
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+		DeferCleanup(cache.SetCacheDir, "")
+		DeferCleanup(cache.ResetASTCache)
+		cache.SetCacheDir(GinkgoT().TempDir())
+
+		dir, _ = filepath.EvalSymlinks(GinkgoT().TempDir())
+		writeFile("go.mod", "module example.com/shop\n\ngo 1.21\n")
+		writeFile("order.go", "package shop\n\nfunc Place() {}\n")
+		git("init", "--quiet")
+		git("add", "-A")
+		git("commit", "--quiet", "-m", "base")
+		writeFile("order.go", "package shop\n\nfunc Place() {}\n\nfunc Cancel() {}\n")
+	})
+
+	It("should analyze a revision without changing the cache of the working tree", func() {
+		head, err := diffSnapshot(dir, "", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(head.Symbols).NotTo(BeEmpty())
+
+		workingCache, err := cache.GetASTCache()
+		Expect(err).NotTo(HaveOccurred())
+		cached := func() []models.ASTNode {
+			var nodes []models.ASTNode
+			Expect(workingCache.GetReadQuery().Order("id").Find(&nodes).Error).To(Succeed())
+			return nodes
+		}
+		before := cached()
+		Expect(before).NotTo(BeEmpty())
+
+		base, err := diffSnapshot(dir, "HEAD", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(base.Symbols).NotTo(BeEmpty())
+
+		after := cached()
+		Expect(after).To(Equal(before))
+		for _, node := range after {
+			Expect(node.FilePath).To(HavePrefix(dir + string(filepath.Separator)))
+		}
+	})
+})
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/capabilities"
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/languages"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [path]",
+	Short: "Check the environment for the tools arch-unit needs",
+	Long: `Check that the tools arch-unit runs for some of its features are installed, with their
+versions, that arch-unit.yaml is valid and that the AST cache is writable and uses the schema of
+this version, and report what is missing for full functionality with the steps fixing it.
+
+Missing tools never stop an analysis: the features that need them are skipped and listed in the
+summary of 'arch-unit check'. The doctor marks a missing tool as needed when the project at path
+(default the working directory) uses it, i.e. has files of a language whose analysis needs it or
+enables a linter it provides, and exits with an error when a needed tool is missing.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	dir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	archConfig, configErr := config.NewParser(dir).LoadConfig()
+	needed := neededTools(dir, archConfig)
+	var missing, remediation []string
+	fmt.Println("Tools:")
+	for _, tool := range capabilities.Tools {
+		binary, ok := capabilities.Find(tool.Name)
+		if ok {
+			path, _ := exec.LookPath(binary)
+			if version := capabilities.Version(tool.Name); version != "" {
+				path = version + " " + color.HiBlackString(path)
+			}
+			fmt.Printf("  %s %-14s %s\n", color.GreenString("✓"), tool.Name, path)
+			continue
+		}
+
+		reason, isNeeded := needed[tool.Name]
+		mark := color.YellowString("-")
+		if isNeeded {
+			mark = color.RedString("✗")
+			missing = append(missing, tool.Name)
+			remediation = append(remediation, fmt.Sprintf("Install %s for %s: %s", tool.Name, reason, tool.Install))
+		}
+		fmt.Printf("  %s %-14s not installed, %s is unavailable\n", mark, tool.Name, tool.Feature)
+		if isNeeded {
+			fmt.Printf("    %-14s needed for %s\n", "", reason)
+		}
+		if tool.Install != "" {
+			fmt.Printf("    %-14s install: %s\n", "", tool.Install)
+		}
+	}
+
+	fmt.Println("\nEnvironment:")
+	switch {
+	case errors.Is(configErr, config.ErrConfigNotFound):
+		fmt.Printf("  %s %-14s no %s found, the smart defaults are used\n", color.YellowString("-"), "config", config.ConfigFileName)
+		remediation = append(remediation, "Run 'arch-unit init' to create an "+config.ConfigFileName+" for this project")
+	case configErr != nil:
+		fmt.Printf("  %s %-14s %v\n", color.RedString("✗"), "config", configErr)
+		missing = append(missing, "config")
+		remediation = append(remediation, "Fix "+config.ConfigFileName+", or run 'arch-unit init --force' to regenerate it")
+	default:
+		fmt.Printf("  %s %-14s %s is valid\n", color.GreenString("✓"), "config", config.ConfigFileName)
+	}
+
+	if err := cache.TestWriteAccess(); err != nil {
+		// The errors of the cache end with the steps fixing them
+		lines := strings.Split(err.Error(), "\n")
+		var mismatch *cache.SchemaMismatchError
+		if errors.As(err, &mismatch) {
+			fmt.Printf("  %s %-14s schema v%d, this arch-unit needs v%d: %s\n", color.RedString("✗"), "cache", mismatch.CacheVersion, cache.SchemaVersion, lines[0])
+		} else {
+			fmt.Printf("  %s %-14s not writable: %s\n", color.RedString("✗"), "cache", lines[0])
+		}
+		missing = append(missing, "cache")
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(strings.TrimPrefix(line, "Try:")); line != "" {
+				remediation = append(remediation, line)
+			}
+		}
+	} else {
+		fmt.Printf("  %s %-14s writable, schema v%d\n", color.GreenString("✓"), "cache", cache.SchemaVersion)
+	}
+	if offline.Enabled() {
+		fmt.Printf("  %s %-14s enabled, Git URL resolution and cloning use existing caches only\n", color.YellowString("-"), "offline")
+	}
+
+	if len(remediation) > 0 {
+		fmt.Println("\nTo fix:")
+		for i, step := range remediation {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing for full functionality: %s", strings.Join(missing, ", "))
+	}
+	fmt.Printf("\n%s Everything this project needs is available\n", color.GreenString("✓"))
+	return nil
+}
+
+// neededTools returns the tools the project in dir uses, with the reason they are needed. The
+// smart defaults are used when archConfig is nil.
+func neededTools(dir string, archConfig *models.Config) map[string]string {
+	needed := make(map[string]string)
+
+	detected, err := languages.DetectLanguagesInDirectory(dir)
+	if err == nil {
+		for _, tool := range capabilities.Tools {
+			for _, language := range tool.Languages {
+				for _, found := range detected {
+					if found == language {
+						needed[tool.Name] = language + " files"
+					}
+				}
+			}
+		}
+	}
+
+	if archConfig == nil {
+		archConfig, _ = config.CreateSmartDefaultConfig(dir)
+	}
+	if archConfig != nil {
+		for _, linter := range archConfig.GetEnabledLinters() {
+			if tool := capabilities.Get(linter); tool.Linter {
+				needed[tool.Name] = "the enabled " + linter + " linter"
+			}
+		}
+		if len(archConfig.Policies) > 0 {
+			needed["opa"] = "the policies of arch-unit.yaml"
+		}
+	}
+	return needed
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/flanksource/arch-unit/scaffold"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	examplesDir    string
+	examplesModule string
+	examplesForce  bool
+)
+
+var examplesCmd = &cobra.Command{
+	Use:   "examples",
+	Short: "Generate example projects with intentional violations",
+	Long: `Generate small example projects that contain intentional violations, to learn the rule
+syntax by running arch-unit against them and editing their rules.`,
+}
+
+var examplesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the example projects",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, example := range scaffold.List() {
+			fmt.Printf("%-12s %s\n", example.Name, example.Description)
+		}
+		return nil
+	},
+}
+
+var examplesGenerateCmd = &cobra.Command{
+	Use:   "generate <pattern>",
+	Short: "Generate the example projects matching a pattern",
+	Long: `Generate the example projects whose name matches a glob pattern, each into its own
+directory below --dir.
+
+Examples:
+  # Generate the layered Go service into ./examples/go-layered
+  arch-unit examples generate go-layered
+
+  # Generate every example into /tmp/arch-unit-examples
+  arch-unit examples generate '*' --dir /tmp/arch-unit-examples
+
+  # Then try the rules
+  cd examples/go-layered && arch-unit check`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExamplesGenerate,
+}
+
+func init() {
+	rootCmd.AddCommand(examplesCmd)
+	examplesCmd.AddCommand(examplesListCmd)
+	examplesCmd.AddCommand(examplesGenerateCmd)
+
+	examplesGenerateCmd.Flags().StringVarP(&examplesDir, "dir", "d", "examples", "Directory to generate the examples into")
+	examplesGenerateCmd.Flags().StringVar(&examplesModule, "module", "", "Go module path of generated Go projects (default example.com/<example>)")
+	examplesGenerateCmd.Flags().BoolVarP(&examplesForce, "force", "f", false, "Overwrite existing files")
+}
+
+func runExamplesGenerate(cmd *cobra.Command, args []string) error {
+	matched, err := scaffold.Match(args[0])
+	if err != nil {
+		return err
+	}
+	if len(matched) == 0 {
+		return fmt.Errorf("no example matches %q, run 'arch-unit examples list' to see the examples", args[0])
+	}
+
+	for _, example := range matched {
+		dir := filepath.Join(examplesDir, example.Name)
+		files, err := scaffold.Generate(example.Name, dir, scaffold.Data{Module: examplesModule}, examplesForce)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Generated %s in %s (%d files)", example.Name, dir, len(files))
+	}
+	return nil
+}
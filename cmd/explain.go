@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/spf13/cobra"
+)
+
+var explainLimit int
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <rule>",
+	Short: "Explain how an AQL rule is evaluated and what it matches",
+	Long: `Explain an enabled AQL rule from arch-unit.yaml, to debug why it does or does not fire.
+
+For the named rule this prints:
+  - the rule as parsed, which shows how statements and patterns were understood
+  - for every pattern of every statement, the query run against the AST cache,
+    how many nodes it currently matches and a few of them
+  - how many violations the rule reports and a few of them
+
+Rules are evaluated against the AST cache, use 'ast analyze' or 'check' first to build it.`,
+	Example: `  # Explain a rule
+  arch-unit explain no-db-in-controllers
+
+  # Show up to 20 matched nodes and violations as JSON
+  arch-unit explain no-db-in-controllers --limit 20 --format json`,
+	Args:         cobra.ExactArgs(1),
+	RunE:         runExplain,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().IntVar(&explainLimit, "limit", 5, "Maximum number of example nodes and violations to show")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	archConfig, err := config.NewParser(workingDir).LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	rules, err := loadEnabledAQLRules(workingDir, archConfig)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.Name, args[0]) {
+			names = append(names, rule.Name)
+			continue
+		}
+
+		explanation, err := query.NewAQLEngine(cache.MustGetASTCache()).Explain(rule, explainLimit)
+		if err != nil {
+			return fmt.Errorf("failed to explain rule '%s': %w", rule.Name, err)
+		}
+		explanation.Source = MakeRelativePath(rule.SourceFile, workingDir)
+		for i := range explanation.Violations {
+			explanation.Violations[i].File = MakeRelativePath(explanation.Violations[i].File, workingDir)
+		}
+		fmt.Println(clicky.MustFormat(explanation))
+		return nil
+	}
+
+	return fmt.Errorf("rule '%s' not found, enabled rules: %v", args[0], names)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/output"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diagramFormat  string
+	diagramLevel   string
+	diagramDepth   int
+	diagramAll     bool
+	diagramEdges   []string
+	diagramExclude []string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [pattern]",
+	Short: "Render the dependencies between packages or types as a diagram",
+	Long: `Render the dependencies between the packages or types analyzed by 'ast analyze' as a
+Mermaid or PlantUML diagram, to paste into markdown documents and architecture decision records,
+or as a Graphviz DOT graph to visualize subsystems with other tools.
+
+Packages and types depend on each other through calls, embedding and the other dependencies
+between their nodes. Types are named package.Type. With a pattern, the diagram starts from the
+matching packages or types and follows their dependencies up to --depth levels.
+
+--edges draws the relationships of the given types apart, labelled with their type, including
+those to external libraries such as imports: import, call, implements, inheritance, reference,
+sql_query, http_call, env_var, ...
+
+Examples:
+  # Diagram of every package of the working directory
+  arch-unit graph
+
+  # The service packages and the packages they depend on, in PlantUML
+  arch-unit graph "service*" --format plantuml
+
+  # The types of package api and two levels of their dependencies
+  arch-unit graph "api.*" --level type --depth 2 > docs/api.mmd
+
+  # Imports and interface implementations between types, without tests, rendered by Graphviz
+  arch-unit graph --level type --edges import,implements --exclude "*test*" --format dot | dot -Tsvg -o types.svg`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGraph,
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringVar(&diagramFormat, "format", "mermaid", "Diagram format: mermaid, plantuml or dot")
+	graphCmd.Flags().StringVar(&diagramLevel, "level", models.GraphLevelPackage, "Nodes of the diagram: package or type")
+	graphCmd.Flags().IntVar(&diagramDepth, "depth", 1, "Levels of dependencies followed from the packages or types matching the pattern")
+	graphCmd.Flags().BoolVar(&diagramAll, "all", false, "Include packages outside the working directory")
+	graphCmd.Flags().StringSliceVar(&diagramEdges, "edges", nil, "Relationship types drawn as labelled edges, e.g. import,call,implements")
+	graphCmd.Flags().StringSliceVar(&diagramExclude, "exclude", nil, "Globs of packages or types to leave out, e.g. \"*test*\"")
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	options := models.GraphOptions{
+		Level:      diagramLevel,
+		Depth:      diagramDepth,
+		PathPrefix: workingDir + "/",
+		Edges:      diagramEdges,
+		Exclude:    diagramExclude,
+	}
+	if diagramAll {
+		options.PathPrefix = ""
+	}
+	if len(args) > 0 {
+		options.Pattern = args[0]
+	}
+
+	graph, err := query.NewAQLEngine(cache.MustGetASTCache()).DependencyGraph(options)
+	if err != nil {
+		return fmt.Errorf("failed to build the dependency graph: %w", err)
+	}
+	if len(graph.Nodes) == 0 {
+		logger.Infof("No packages found in cache for %s", workingDir)
+		logger.Infof("%s", i18n.T("hint.analyze_first"))
+		return nil
+	}
+
+	diagram, err := output.RenderGraph(graph, diagramFormat)
+	if err != nil {
+		return err
+	}
+	fmt.Print(diagram)
+	return nil
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/flanksource/arch-unit/git"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/output"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyLimit            int
+	historyBase             string
+	historyFailOnRegression bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the trends of violations, complexity and dependencies between check runs",
+	Long: `Show the runs 'arch-unit check' recorded in the working directory and the metrics that got
+worse since the previous run: the violations in total and of every rule, the average and maximum
+cyclomatic complexity and the dependencies between packages.
+
+Every full check run is recorded by the commit checked out, runs of specific files or of --changed
+are not. --base compares the last run with the last run at another commit without uncommitted
+changes, e.g. the main branch, and --fail-on-regression exits non-zero when anything got worse,
+enabling "don't get worse" gates in CI.
+
+Examples:
+  # The last 20 runs and the regressions since the previous one
+  arch-unit history
+
+  # Fail when the last run is worse than the last run of main
+  arch-unit check && arch-unit history --base main --fail-on-regression
+
+  # Every run as JSON
+  arch-unit history --limit 0 --format json`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "Number of runs listed, 0 for every run")
+	historyCmd.Flags().StringVar(&historyBase, "base", "", "Compare the last run with the last run at this commit, branch or tag instead of the previous run")
+	historyCmd.Flags().BoolVar(&historyFailOnRegression, "fail-on-regression", false, "Exit with an error when a metric got worse")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	history, err := cache.NewRunHistory()
+	if err != nil {
+		return fmt.Errorf("failed to open run history: %w", err)
+	}
+	defer func() { _ = history.Close() }()
+
+	runs, err := history.List(workingDir, historyLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+	if len(runs) == 0 {
+		logger.Infof("No runs recorded for %s, run 'arch-unit check' first", workingDir)
+		return nil
+	}
+	head := runs[len(runs)-1]
+
+	var base *models.RunSummary
+	if historyBase != "" {
+		commit, err := git.ResolveCommit(workingDir, historyBase)
+		if err != nil {
+			return err
+		}
+		if base, err = history.ForCommit(workingDir, commit); err != nil {
+			return fmt.Errorf("failed to find the run of %s: %w", historyBase, err)
+		}
+		if base == nil {
+			return fmt.Errorf("no run recorded at %s (%s), run 'arch-unit check' on it first", historyBase, shortCommit(commit))
+		}
+	} else if len(runs) > 1 {
+		base = runs[len(runs)-2]
+	}
+
+	var regressions []models.Regression
+	if base != nil {
+		regressions = head.Regressions(base)
+	}
+
+	format := clicky.Flags.FormatOptions.ResolveFormat()
+	if format == "json" || format == "yaml" {
+		fmt.Println(clicky.MustFormat(struct {
+			Runs        []*models.RunSummary `json:"runs"`
+			Base        *models.RunSummary   `json:"base,omitempty"`
+			Regressions []models.Regression  `json:"regressions"`
+		}{runs, base, regressions}))
+	} else {
+		printHistorySection(format, "Runs", runs)
+		if base != nil {
+			fmt.Println()
+			title := fmt.Sprintf("Regressions since %s", shortCommit(base.Commit))
+			if len(regressions) == 0 {
+				fmt.Printf("No regressions since %s\n", shortCommit(base.Commit))
+			} else {
+				printHistorySection(format, title, regressions)
+			}
+		}
+	}
+
+	if historyFailOnRegression && len(regressions) > 0 {
+		return fmt.Errorf("%d metrics got worse since %s", len(regressions), shortCommit(base.Commit))
+	}
+	return nil
+}
+
+func printHistorySection(format, title string, rows interface{}) {
+	if format == "markdown" || format == "md" {
+		fmt.Printf("## %s\n\n", title)
+	} else {
+		fmt.Println(color.New(color.Bold).Sprint(title))
+	}
+	fmt.Println(clicky.MustFormat(rows))
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// recordRunHistory records the violations of a full check run and the size, complexity and
+// package dependencies of the code under workingDir by the commit checked out. Runs outside a
+// git repository are not recorded, and failures never fail the check.
+func recordRunHistory(workingDir string, result *models.ConsolidatedResult) {
+	workingDir, err := filepath.Abs(workingDir)
+	if err != nil {
+		logger.Debugf("Not recording run history: %v", err)
+		return
+	}
+	commit, dirty, err := git.Head(workingDir)
+	if err != nil {
+		logger.Debugf("Not recording run history: %v", err)
+		return
+	}
+
+	summary := &models.RunSummary{
+		Commit:     commit,
+		Dirty:      dirty,
+		RecordedAt: time.Now(),
+		Violations: len(result.Violations),
+		Rules:      make(map[string]int),
+	}
+	for _, v := range result.Violations {
+		summary.Rules[output.RuleName(v)]++
+	}
+
+	engine := query.NewAQLEngine(cache.MustGetASTCache())
+	pathPrefix := workingDir + "/"
+	if stats, err := engine.CodebaseStats(pathPrefix, 0); err != nil {
+		logger.Debugf("Failed to summarise code for run history: %v", err)
+	} else {
+		total := 0.0
+		for _, language := range stats.Languages {
+			summary.Files += language.Files
+			summary.Functions += language.Functions
+			total += language.AvgComplexity * float64(language.Functions)
+			if language.MaxComplexity > summary.MaxComplexity {
+				summary.MaxComplexity = language.MaxComplexity
+			}
+		}
+		if summary.Functions > 0 {
+			summary.AvgComplexity = math.Round(100*total/float64(summary.Functions)) / 100
+		}
+	}
+	if graph, err := engine.DependencyGraph(models.GraphOptions{Level: models.GraphLevelPackage, PathPrefix: pathPrefix}); err != nil {
+		logger.Debugf("Failed to count dependencies for run history: %v", err)
+	} else {
+		summary.Dependencies = len(graph.Edges)
+	}
+
+	history, err := cache.NewRunHistory()
+	if err != nil {
+		logger.Debugf("Failed to open run history: %v", err)
+		return
+	}
+	defer func() { _ = history.Close() }()
+	if err := history.Record(workingDir, summary); err != nil {
+		logger.Debugf("Failed to record run history: %v", err)
+	}
+}
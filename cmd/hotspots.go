@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/flanksource/arch-unit/git"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hotspotsSince     string
+	hotspotsLimit     int
+	hotspotsThreshold int
+	hotspotsAll       bool
+)
+
+var hotspotsCmd = &cobra.Command{
+	Use:   "hotspots",
+	Short: "Rank files by churn times complexity to find refactoring candidates",
+	Long: `Rank the files analyzed by 'ast analyze' by the number of commits changing them times the
+cyclomatic complexity of their functions. Complex files that change often are the most expensive
+to maintain and the best candidates for refactoring.
+
+--threshold only lists the files scoring above it. The hotspots section of arch-unit.yaml reports
+the same files as advisory violations of the aql linter, at info severity unless configured:
+  hotspots:
+    since: 6 months ago
+    threshold: 200
+    ignore: ["**/generated/**"]
+
+Examples:
+  # The 20 hottest files of the last 6 months
+  arch-unit hotspots
+
+  # Every file scoring above 500 over the last year, as JSON
+  arch-unit hotspots --since "1 year ago" --threshold 500 --limit 0 --format json
+
+  # An HTML report
+  arch-unit hotspots --format html > hotspots.html`,
+	Args: cobra.NoArgs,
+	RunE: runHotspots,
+}
+
+func init() {
+	rootCmd.AddCommand(hotspotsCmd)
+	hotspotsCmd.Flags().StringVar(&hotspotsSince, "since", models.DefaultHotspotSince, "Count the commits since a date git understands, e.g. \"2024-01-01\" or \"3 months ago\"")
+	hotspotsCmd.Flags().IntVar(&hotspotsLimit, "limit", 20, "Number of files listed, 0 for every file")
+	hotspotsCmd.Flags().IntVar(&hotspotsThreshold, "threshold", 0, "Only list the files scoring above this churn times complexity")
+	hotspotsCmd.Flags().BoolVar(&hotspotsAll, "all", false, "Include files outside the working directory")
+}
+
+func runHotspots(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	churn, err := git.Churn(workingDir, hotspotsSince)
+	if err != nil {
+		return fmt.Errorf("failed to count commits: %w", err)
+	}
+
+	pathPrefix := workingDir + "/"
+	if hotspotsAll {
+		pathPrefix = ""
+	}
+	hotspots, err := query.NewAQLEngine(cache.MustGetASTCache()).Hotspots(churn, pathPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to rank hotspots: %w", err)
+	}
+
+	var ranked []*models.Hotspot
+	for _, hotspot := range hotspots {
+		if hotspot.Score > hotspotsThreshold {
+			ranked = append(ranked, hotspot)
+		}
+	}
+	if hotspotsLimit > 0 && len(ranked) > hotspotsLimit {
+		ranked = ranked[:hotspotsLimit]
+	}
+
+	if len(ranked) == 0 {
+		logger.Infof("No hotspots found in %s since %s", workingDir, hotspotsSince)
+		if len(hotspots) == 0 {
+			logger.Infof("%s", i18n.T("hint.analyze_first"))
+		}
+		return nil
+	}
+
+	fmt.Println(clicky.MustFormat(ranked))
+	return nil
+}
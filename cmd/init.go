@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/flanksource/arch-unit/config"
 	"github.com/flanksource/arch-unit/languages"
@@ -18,6 +20,7 @@ var (
 	styleGuide   string
 	strictness   string
 	setupLinters bool
+	preCommit    bool
 )
 
 var initCmd = &cobra.Command{
@@ -34,7 +37,13 @@ Examples:
   arch-unit init ./src
 
   # Force overwrite existing file
-  arch-unit init --force`,
+  arch-unit init --force
+
+  # Non-interactive setup installing a git pre-commit hook
+  arch-unit init --interactive=false --pre-commit
+
+The generated configuration enables the linters with existing configuration files, and layer
+rules for the conventional directories found, e.g. handlers, services and repositories.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runInit,
 }
@@ -47,6 +56,7 @@ func init() {
 	initCmd.Flags().StringVar(&styleGuide, "style", "", "Style guide to use (google-go, google-python, google-java, airbnb-javascript, pep8, rust-official)")
 	initCmd.Flags().StringVar(&strictness, "strictness", "moderate", "Strictness level (strict, moderate, lenient)")
 	initCmd.Flags().BoolVar(&setupLinters, "setup-linters", false, "Create linter configuration files if missing")
+	initCmd.Flags().BoolVar(&preCommit, "pre-commit", false, "Install a git pre-commit hook checking the changed files")
 }
 
 
@@ -119,8 +129,24 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to interpolate variables: %w", err)
 	}
 
+	// Layer rules of the conventional directories of the project, e.g. handlers and services
+	if len(generatedConfig.Layers) == 0 {
+		generatedConfig.Layers = config.DetectLayers(targetDir)
+	}
+
 	// Write the configuration file
-	return writeConfigFile(configPath, generatedConfig)
+	if err := writeConfigFile(configPath, generatedConfig); err != nil {
+		return err
+	}
+
+	if preCommit {
+		hook, err := installPreCommitHook(targetDir)
+		if err != nil {
+			return fmt.Errorf("failed to install the pre-commit hook: %w", err)
+		}
+		fmt.Printf("✓ Installed %s\n", hook)
+	}
+	return nil
 }
 
 func generateNonInteractiveConfig(targetDir string, styleGuideName string, strictnessLevel string) *models.Config {
@@ -188,7 +214,7 @@ func generateNonInteractiveConfig(targetDir string, styleGuideName string, stric
 
 func writeConfigFile(configPath string, config *models.Config) error {
 	// Marshal to YAML
-	yamlData, err := yaml.Marshal(config)
+	yamlData, err := commentedYAML(config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal configuration: %w", err)
 	}
@@ -259,3 +285,62 @@ func writeConfigFile(configPath string, config *models.Config) error {
 
 	return nil
 }
+
+// sectionComments explain the sections of a generated arch-unit.yaml
+var sectionComments = map[string]string{
+	"debounce":      "Minimum time between two runs of the same linter on unchanged files",
+	"variables":     "Thresholds interpolated into rules as ${name}, adjust them to the codebase",
+	"builtin_rules": "Built-in architecture rules, see 'arch-unit rules' for the available ones",
+	"rules":         "Import restrictions and quality limits by file pattern:\n  \"!pkg\" forbids an import, \"+pkg\" allows it again for more specific patterns",
+	"linters":       "Linters run by 'arch-unit check', enabled for those already configured in the project",
+	"languages":     "Languages detected in the project and the files analyzed for each",
+	"layers":        "Layered architecture of the detected directories: each layer may only depend on\nthe layers it allows, packages outside every layer are unrestricted",
+}
+
+// commentedYAML marshals a configuration with a comment above each of its sections
+func commentedYAML(config *models.Config) ([]byte, error) {
+	var document yaml.Node
+	if err := document.Encode(config); err != nil {
+		return nil, err
+	}
+	if document.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(document.Content); i += 2 {
+			key := document.Content[i]
+			if comment, ok := sectionComments[key.Value]; ok {
+				key.HeadComment = comment
+			}
+		}
+	}
+	return yaml.Marshal(&document)
+}
+
+// preCommitHook checks the files changed since the last commit before every commit
+const preCommitHook = `#!/bin/sh
+# Installed by arch-unit init
+exec arch-unit check --changed --fail-on-violation
+`
+
+// installPreCommitHook writes a git pre-commit hook running arch-unit check for the repository
+// containing dir, keeping an existing hook unless --force is given
+func installPreCommitHook(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not in a git repository", dir)
+	}
+	hooksDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return "", err
+	}
+
+	hook := filepath.Join(hooksDir, "pre-commit")
+	if _, err := os.Stat(hook); err == nil && !force {
+		return "", fmt.Errorf("%s already exists, use --force to overwrite it", hook)
+	}
+	if err := os.WriteFile(hook, []byte(preCommitHook), 0755); err != nil {
+		return "", err
+	}
+	return hook, nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/internal/llm"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// loadAIConfig returns the ai section of the arch-unit.yaml found from dir, nil without one
+func loadAIConfig(dir string) (*models.AIConfig, error) {
+	archConfig, err := config.NewParser(dir).LoadConfig()
+	if errors.Is(err, config.ErrConfigNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return archConfig.AI, nil
+}
+
+// newLLMProvider returns the provider of the flags of a command, falling back to the environment
+// and then to the ai section of arch-unit.yaml
+func newLLMProvider(ai *models.AIConfig, provider, model string) (llm.Provider, error) {
+	config := llm.Config{Provider: provider, Model: model}
+	if ai != nil {
+		config = config.WithDefaults(llm.Config{
+			Provider:          ai.Provider,
+			Model:             ai.Model,
+			BaseURL:           ai.BaseURL,
+			APIKey:            apiKeyOf(ai.APIKeyEnv),
+			Region:            ai.Region,
+			RequestsPerMinute: ai.RequestsPerMinute,
+			TokenBudget:       ai.TokenBudget,
+			MaxTokens:         ai.MaxTokens,
+		})
+	}
+	return llm.New(config)
+}
+
+// newLLMEmbedder returns the embedder of the flags of a command, falling back to the environment
+// and then to ai.embeddings of arch-unit.yaml, limited like the provider of the ai section
+func newLLMEmbedder(ai *models.AIConfig, provider, model string) (llm.Embedder, error) {
+	config := llm.Config{Provider: provider, Model: model}
+	if ai != nil {
+		defaults := llm.Config{RequestsPerMinute: ai.RequestsPerMinute, TokenBudget: ai.TokenBudget}
+		if ai.Embeddings != nil {
+			defaults.Provider = ai.Embeddings.Provider
+			defaults.Model = ai.Embeddings.Model
+			defaults.BaseURL = ai.Embeddings.BaseURL
+			defaults.APIKey = apiKeyOf(ai.Embeddings.APIKeyEnv)
+		}
+		config = config.WithEmbeddingDefaults(defaults)
+	}
+	return llm.NewEmbedder(config)
+}
+
+func apiKeyOf(env string) string {
+	if env == "" {
+		return ""
+	}
+	return os.Getenv(env)
+}
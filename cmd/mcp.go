@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpAll bool
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve the AST cache to coding agents over the Model Context Protocol",
+	Long: `Start a Model Context Protocol server on stdin and stdout, so coding agents can query the
+architecture of the working directory and run rules while generating code.
+
+Tools:
+  query         nodes matching an AST pattern or an AQL metric condition
+  callers       callers and other nodes referencing the nodes matching a pattern
+  callees       nodes called or referenced by the nodes matching a pattern
+  violations    violations found by the last check, of a file or of every file
+  check         violations of AQL rules
+  architecture  packages with their size, complexity and dependencies
+
+The server reads the AST cache, use 'ast analyze' first to build it and 'check' to
+record violations.
+
+Examples:
+  # Register the server with an agent, in its MCP configuration
+  {"mcpServers": {"arch-unit": {"command": "arch-unit", "args": ["mcp"]}}}
+
+  # Serve another project
+  arch-unit mcp --cwd /path/to/project`,
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.Flags().BoolVar(&mcpAll, "all", false, "Include nodes outside the working directory")
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	root := workingDir + "/"
+	if mcpAll {
+		root = ""
+	}
+
+	server := mcp.NewServer(cache.MustGetASTCache(), root)
+	defer func() { _ = server.Close() }()
+	return server.Serve(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	metricsSort string
+	metricsAll  bool
+)
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics [package]",
+	Short: "Show the coupling metrics of packages",
+	Long: `Show the coupling metrics of every package analyzed by 'ast analyze':
+
+  Ca            afferent coupling, the number of packages depending on the package
+  Ce            efferent coupling, the number of packages the package depends on
+  Instability   Ce / (Ca + Ce), from 0 for stable to 1 for unstable packages
+  Abstractness  the ratio of interfaces among the types of the package
+  Distance      |Abstractness + Instability - 1|, the distance from the main sequence
+
+Packages are coupled through calls, embedding and the other dependencies between
+the nodes of the working directory. Stable packages that many others depend on
+should be abstract, packages with a large distance are either rigid or unused.
+
+AQL rules limit the metrics of packages, e.g. to keep core packages stable:
+  LIMIT(core*.instability > 0.3)
+  LIMIT(package.distance > 0.7)
+
+Examples:
+  # Show the metrics of all packages
+  arch-unit metrics
+
+  # Show the least stable packages first
+  arch-unit metrics --sort instability
+
+  # Show the packages starting with core as JSON
+  arch-unit metrics "core*" --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMetrics,
+}
+
+func init() {
+	rootCmd.AddCommand(metricsCmd)
+	metricsCmd.Flags().StringVar(&metricsSort, "sort", "", "Sort packages by a metric in descending order: afferent, efferent, instability, abstractness or distance")
+	metricsCmd.Flags().BoolVar(&metricsAll, "all", false, "Include packages outside the working directory")
+}
+
+func runMetrics(cmd *cobra.Command, args []string) error {
+	if metricsSort != "" && !models.IsPackageMetric(metricsSort) {
+		return fmt.Errorf("invalid --sort '%s', expected afferent, efferent, instability, abstractness or distance", metricsSort)
+	}
+
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	pathPrefix := workingDir + "/"
+	if metricsAll {
+		pathPrefix = ""
+	}
+
+	coupling, err := query.NewAQLEngine(cache.MustGetASTCache()).PackageCoupling(pathPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to compute package coupling: %w", err)
+	}
+
+	if len(args) > 0 {
+		pattern := &models.AQLPattern{Package: args[0]}
+		var matching []*models.PackageCoupling
+		for _, c := range coupling {
+			if pattern.Matches(&models.ASTNode{PackageName: c.Package}) {
+				matching = append(matching, c)
+			}
+		}
+		coupling = matching
+	}
+
+	if len(coupling) == 0 {
+		logger.Infof("No packages found in cache for %s", workingDir)
+		logger.Infof("%s", i18n.T("hint.analyze_first"))
+		return nil
+	}
+
+	if metricsSort != "" {
+		sort.SliceStable(coupling, func(i, j int) bool {
+			return coupling[i].Metric(metricsSort) > coupling[j].Metric(metricsSort)
+		})
+	}
+
+	fmt.Println(clicky.MustFormat(coupling))
+	return nil
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/repl"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryReport      string
+	queryAll         bool
+	queryInteractive bool
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query [name]",
+	Short: "Run named queries and saved reports defined in arch-unit.yaml",
+	Long: `Run named AQL or pattern queries defined in the 'queries' section of arch-unit.yaml.
+
+Queries are executed against the AST cache, use 'ast analyze' first to build it.
+Without arguments the configured queries and reports are listed.
+
+With --interactive a prompt evaluates AST patterns, AQL conditions and read-only SQL queries
+against the cache tables, switching between pretty, tree and json output with .format.
+
+CONFIGURATION:
+  queries:
+    large_services:
+      description: Services that have grown too large
+      aql: "lines(*Service*) > 300"
+    orphan_services:
+      description: Service types outside the service layer
+      pattern: "@**/*.go:*Service"
+
+  reports:
+    weekly:
+      title: Weekly architecture review
+      queries: [large_services, orphan_services]
+
+EXAMPLES:
+  # List configured queries and reports
+  arch-unit query
+
+  # Run a single named query
+  arch-unit query orphan_services
+
+  # Run all queries in a saved report
+  arch-unit query --report weekly
+
+  # Explore the cache interactively
+  arch-unit query -i`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runQuery,
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.Flags().StringVar(&queryReport, "report", "", "Run all queries in the named report")
+	queryCmd.Flags().BoolVar(&queryAll, "all", false, "Include nodes outside the working directory")
+	queryCmd.Flags().BoolVarP(&queryInteractive, "interactive", "i", false, "Prompt for AST patterns, AQL conditions and read-only SQL queries")
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	if queryInteractive {
+		return runQueryREPL(workingDir)
+	}
+
+	archConfig, err := config.NewParser(workingDir).LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if queryReport == "" && len(args) == 0 {
+		return listNamedQueries(archConfig)
+	}
+
+	pathPrefix := workingDir + "/"
+	if queryAll {
+		pathPrefix = ""
+	}
+
+	engine := query.NewAQLEngine(cache.MustGetASTCache())
+
+	if queryReport != "" {
+		report, err := engine.RunReport(archConfig, queryReport, pathPrefix)
+		if err != nil {
+			return err
+		}
+		makeQueryResultsRelative(report.Results, workingDir)
+		fmt.Println(clicky.MustFormat(report))
+		return nil
+	}
+
+	name := args[0]
+	q, ok := archConfig.GetQuery(name)
+	if !ok {
+		return fmt.Errorf("query '%s' not found, available queries: %v", name, archConfig.GetQueryNames())
+	}
+
+	result, err := engine.RunNamedQuery(name, q, pathPrefix)
+	if err != nil {
+		return err
+	}
+	makeQueryResultsRelative([]models.NamedQueryResult{*result}, workingDir)
+	fmt.Println(clicky.MustFormat(result))
+	return nil
+}
+
+// runQueryREPL prompts for queries against the AST cache until .quit or the end of the input
+func runQueryREPL(workingDir string) error {
+	pathPrefix := workingDir + "/"
+	if queryAll {
+		pathPrefix = ""
+	}
+	astCache, err := cache.GetASTCache()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Querying the AST cache, enter .help for help and .quit to exit")
+	return repl.NewSession(astCache, os.Stdout, workingDir, pathPrefix).Run(os.Stdin)
+}
+
+// listNamedQueries prints the queries and reports defined in the configuration
+func listNamedQueries(archConfig *models.Config) error {
+	if len(archConfig.Queries) == 0 {
+		fmt.Println("No named queries defined, add a 'queries' section to arch-unit.yaml")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "Query\tExpression\tDescription\n")
+	for _, name := range archConfig.GetQueryNames() {
+		q := archConfig.Queries[name]
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", name, q.Expression(), q.Description)
+	}
+	_ = w.Flush()
+
+	if len(archConfig.Reports) > 0 {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "Report\tQueries\n")
+		for name, report := range archConfig.Reports {
+			_, _ = fmt.Fprintf(w, "%s\t%v\n", name, report.Queries)
+		}
+		_ = w.Flush()
+	}
+
+	return nil
+}
+
+// makeQueryResultsRelative rewrites match file paths relative to the working directory
+func makeQueryResultsRelative(results []models.NamedQueryResult, workingDir string) {
+	for i := range results {
+		for j := range results[i].Matches {
+			results[i].Matches[j].File = MakeRelativePath(results[i].Matches[j].File, workingDir)
+		}
+	}
+}
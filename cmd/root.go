@@ -1,18 +1,23 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/internal/offline"
 	"github.com/flanksource/clicky"
 	"github.com/flanksource/commons/logger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	// Import language packages to trigger init() registration
+	_ "github.com/flanksource/arch-unit/analysis/dart"
 	_ "github.com/flanksource/arch-unit/analysis/go"
+	_ "github.com/flanksource/arch-unit/analysis/groovy"
 	_ "github.com/flanksource/arch-unit/analysis/java"
 	_ "github.com/flanksource/arch-unit/analysis/javascript"
 	_ "github.com/flanksource/arch-unit/analysis/markdown"
@@ -20,11 +25,14 @@ import (
 )
 
 var (
-	cfgFile     string
-	outputFile  string
-	compact     bool
-	workingDir  string
-	showVersion bool
+	cfgFile      string
+	outputFiles  []string
+	compact      bool
+	workingDir   string
+	cacheDirFlag string
+	showVersion  bool
+	offlineMode  bool
+	locale       string
 )
 
 // VersionInfo represents version information with pretty formatting
@@ -47,16 +55,49 @@ It supports both Go and Python codebases and uses AST parsing to identify violat
 		// Apply clicky flags first
 		clicky.Flags.UseFlags()
 
+		if offlineMode {
+			offline.SetEnabled(true)
+		}
+		if offline.Enabled() {
+			logger.Debugf("Offline mode enabled, network access is disabled")
+		}
+
+		if err := i18n.SetLocale(locale); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+
+		// The cache section of arch-unit.yaml may point the AST cache to a shared database
+		if err := configureCache(); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+
 		// Run migrations before any command execution
 		if err := runMigrations(); err != nil {
 			logger.Errorf("Failed to run migrations: %v", err)
 			os.Exit(1)
 		}
 
+		if getVersionInfo != nil {
+			cache.CLIVersion, _, _, _ = getVersionInfo()
+		}
+
+		// cache migrate repairs caches that cannot be opened, doctor reports them, and cache pull
+		// replaces them, which it cannot do once they are open
+		if cmd == cacheMigrateCmd || cmd == doctorCmd || cmd == cachePullCmd {
+			return
+		}
+
 		// Test database write access
 		if err := cache.TestWriteAccess(); err != nil {
+			var mismatch *cache.SchemaMismatchError
+			if errors.As(err, &mismatch) {
+				logger.Errorf("%v", mismatch)
+				os.Exit(1)
+			}
 			logger.Errorf("Database write access test failed: %v", err)
-			logger.Errorf("Please check file permissions on ~/.cache/arch-unit/ directory and available disk space")
+			logger.Errorf("%s", i18n.T("hint.db_permissions"))
 			os.Exit(1)
 		}
 	},
@@ -136,11 +177,14 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.arch-unit.yaml)")
 	rootCmd.PersistentFlags().StringVar(&workingDir, "cwd", "", "Working directory for analysis (default: current directory)")
+	rootCmd.PersistentFlags().StringVar(&cacheDirFlag, "cache-dir", "", "Directory of the AST cache (also set via "+cache.CacheDirEnvVar+", default: a directory per project under ~/.cache/arch-unit/projects)")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Disable all network access and use only cached data (also set via "+offline.EnvVar+")")
+	rootCmd.PersistentFlags().StringVar(&locale, "locale", "", "Language of reports and messages, e.g. de or es, or the path of a YAML message catalog (also set via "+i18n.EnvVar+", default from LANG)")
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "V", false, "Show version information")
 
 	clicky.BindAllFlags(rootCmd.PersistentFlags())
-	// Output file flag
-	rootCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "Output file (optional, uses stdout if not specified)")
+	// Output file flag, repeatable to write several formats from a single run
+	rootCmd.PersistentFlags().StringArrayVarP(&outputFiles, "output", "o", nil, "Output file, format is inferred from the extension (.json, .csv, .html, .md, .sarif, .ndjson, .rdjson, .rdjsonl, .prom, .codeclimate.json); repeat for multiple outputs")
 	rootCmd.PersistentFlags().BoolVarP(&compact, "compact", "c", false, "Compact output showing summary only")
 }
 
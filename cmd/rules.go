@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Inspect AQL rules",
+}
+
+var rulesSlowestCmd = &cobra.Command{
+	Use:   "slowest",
+	Short: "List the AQL rules that take longest to evaluate",
+	Long: `List AQL rules ordered by their average evaluation time, as recorded by previous
+runs of arch-unit check, to find candidates for optimization.
+
+Rules that exceed the rule_budget in arch-unit.yaml (default 5s) are also reported
+as warnings when they run.
+
+Examples:
+  # Show the 10 slowest rules
+  arch-unit rules slowest
+
+  # Show the slowest individual FORBID/REQUIRE/LIMIT clauses
+  arch-unit rules slowest --clauses --limit 20`,
+	Args: cobra.NoArgs,
+	RunE: runRulesSlowest,
+}
+
+var (
+	rulesSlowestLimit   int
+	rulesSlowestClauses bool
+)
+
+func init() {
+	rootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesSlowestCmd)
+	rulesSlowestCmd.Flags().IntVar(&rulesSlowestLimit, "limit", 10, "Maximum number of rules to list")
+	rulesSlowestCmd.Flags().BoolVar(&rulesSlowestClauses, "clauses", false, "List individual clauses instead of whole rules")
+}
+
+func runRulesSlowest(cmd *cobra.Command, args []string) error {
+	workDir, err := GetWorkingDir()
+	if err != nil {
+		return err
+	}
+
+	ruleStats, err := cache.NewRuleStats()
+	if err != nil {
+		return fmt.Errorf("failed to initialize rule statistics: %w", err)
+	}
+	defer func() { _ = ruleStats.Close() }()
+
+	stats, err := ruleStats.GetSlowest(workDir, rulesSlowestLimit, rulesSlowestClauses)
+	if err != nil {
+		return fmt.Errorf("failed to get rule timings: %w", err)
+	}
+
+	if len(stats) == 0 {
+		logger.Infof("No AQL rule timings found for %s, run arch-unit check first", workDir)
+		return nil
+	}
+
+	fmt.Println(clicky.MustFormat(stats))
+	return nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchProvider string
+	searchModel    string
+	searchLimit    int
+	searchAll      bool
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search fields, methods and types by meaning",
+	Long: `Search the fields, methods and types analyzed by 'ast analyze' by meaning rather than by name.
+Their names, signatures and the summaries of 'arch-unit summarize' are embedded into vectors kept
+in the AST cache, and the nodes closest to the query are listed with their file and line. Nodes
+are embedded on the first search and again when they change.
+
+The embeddings come from $ARCH_UNIT_EMBEDDING_PROVIDER, or openai ($OPENAI_API_KEY), ollama
+($OLLAMA_HOST) or, without either, a local embedder that matches the words of the query with the
+words of identifiers and summaries. Changing the provider or model rebuilds the index.
+
+Examples:
+  # Find where passwords are hashed
+  arch-unit search "where do we hash passwords"
+
+  # With a local embedding model, as JSON
+  arch-unit search "retry failed http requests" --provider ollama --model nomic-embed-text --format json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().StringVar(&searchProvider, "provider", "", "Embedding provider: openai, ollama or local")
+	searchCmd.Flags().StringVar(&searchModel, "model", "", "Embedding model, defaults to $ARCH_UNIT_EMBEDDING_MODEL or the default model of the provider")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 10, "Number of nodes listed, 0 for every match")
+	searchCmd.Flags().BoolVar(&searchAll, "all", false, "Include nodes outside the working directory")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	ai, err := loadAIConfig(workingDir)
+	if err != nil {
+		return err
+	}
+	embedder, err := newLLMEmbedder(ai, searchProvider, searchModel)
+	if err != nil {
+		return err
+	}
+
+	astCache := cache.MustGetASTCache()
+	pathPrefix := workingDir + "/"
+	if searchAll {
+		pathPrefix = ""
+	}
+	all, err := astCache.QueryASTNodes("SELECT * FROM ast_nodes WHERE file_path LIKE ? ORDER BY file_path, start_line", pathPrefix+"%")
+	if err != nil {
+		return err
+	}
+	var nodes []*models.ASTNode
+	for _, node := range all {
+		if models.SummaryWordLimit(node.NodeType) > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		logger.Infof("No fields, methods or types to search in %s", workingDir)
+		logger.Infof("%s", i18n.T("hint.analyze_first"))
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	index := analysis.NewSemanticIndex(astCache, embedder)
+	embedded, err := index.Update(ctx, nodes)
+	if err != nil {
+		return err
+	}
+	if embedded > 0 {
+		logger.Infof("Embedded %d nodes with %s", embedded, embedder.Name())
+	}
+
+	results, err := index.Search(ctx, strings.Join(args, " "), nodes, searchLimit)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		logger.Infof("No nodes match '%s'", strings.Join(args, " "))
+		return nil
+	}
+	for _, result := range results {
+		if rel, err := filepath.Rel(workingDir, result.Node.FilePath); err == nil && !strings.HasPrefix(rel, "..") {
+			result.Location = fmt.Sprintf("%s:%d", rel, result.Node.StartLine)
+		}
+	}
+	fmt.Println(clicky.MustFormat(results))
+	return nil
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/flanksource/arch-unit/internal/signing"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	signKey       string
+	signPublicKey string
+)
+
+var signCmd = &cobra.Command{
+	Use:   "sign <file>...",
+	Short: "Sign exported reports and bundles for provenance",
+	Long: `Create detached signatures for exported artifacts such as analysis manifests
+and reports, so downstream consumers can verify that they came from an arch-unit
+run over a specific commit.
+
+Each file gets a <file>.sig JSON signature recording its sha256, the commit of
+the analyzed source tree and the arch-unit version. The private key can also be
+provided via the ` + signing.KeyEnvVar + ` environment variable.
+
+Examples:
+  # Create a key pair (ci.key and ci.pub)
+  arch-unit sign keygen ci
+
+  # Sign a manifest and report
+  arch-unit sign manifest.json results.json --key ci.key
+
+  # Verify them
+  arch-unit sign verify manifest.json results.json --public-key ci.pub`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if resolveSigningKey() == "" {
+			return fmt.Errorf("no signing key configured, use --key or %s", signing.KeyEnvVar)
+		}
+		dir, err := GetWorkingDir()
+		if err != nil {
+			return err
+		}
+		return signArtifacts(dir, args...)
+	},
+}
+
+var signKeygenCmd = &cobra.Command{
+	Use:   "keygen <name>",
+	Short: "Generate a signing key pair as <name>.key and <name>.pub",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		privatePEM, publicPEM, err := signing.GenerateKey()
+		if err != nil {
+			return err
+		}
+		privatePath, publicPath := args[0]+".key", args[0]+".pub"
+		if _, err := os.Stat(privatePath); err == nil {
+			return fmt.Errorf("%s already exists", privatePath)
+		}
+		if err := os.WriteFile(privatePath, privatePEM, 0600); err != nil {
+			return fmt.Errorf("failed to write private key: %w", err)
+		}
+		if err := os.WriteFile(publicPath, publicPEM, 0644); err != nil {
+			return fmt.Errorf("failed to write public key: %w", err)
+		}
+		fmt.Printf("Wrote private key to %s and public key to %s\n", privatePath, publicPath)
+		return nil
+	},
+}
+
+var signVerifyCmd = &cobra.Command{
+	Use:          "verify <file>...",
+	Short:        "Verify the signatures of exported artifacts",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if signPublicKey == "" {
+			return fmt.Errorf("--public-key is required")
+		}
+		for _, path := range args {
+			if err := verifyArtifactSignature(path, signPublicKey); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(signCmd)
+	signCmd.AddCommand(signKeygenCmd)
+	signCmd.AddCommand(signVerifyCmd)
+
+	signCmd.Flags().StringVar(&signKey, "key", "", "Private key used to sign artifacts (default $"+signing.KeyEnvVar+")")
+	signVerifyCmd.Flags().StringVar(&signPublicKey, "public-key", "", "Public key used to verify signatures")
+}
+
+// resolveSigningKey returns the configured private key path, preferring the flag over the environment
+func resolveSigningKey() string {
+	if signKey != "" {
+		return signKey
+	}
+	return os.Getenv(signing.KeyEnvVar)
+}
+
+// signArtifacts signs each artifact with the configured key, recording the commit of sourceDir
+func signArtifacts(sourceDir string, paths ...string) error {
+	key, err := signing.LoadPrivateKey(resolveSigningKey())
+	if err != nil {
+		return err
+	}
+
+	provenance := signing.Provenance{
+		Tool:        "arch-unit",
+		ToolVersion: "dev",
+		Commit:      signing.SourceCommit(sourceDir),
+	}
+	if getVersionInfo != nil {
+		provenance.ToolVersion, _, _, _ = getVersionInfo()
+	}
+	if provenance.Commit == "" {
+		logger.Warnf("%s is not in a git repository, signatures will not record a commit", sourceDir)
+	}
+
+	for _, path := range paths {
+		sigPath, err := signing.SignFile(path, key, provenance)
+		if err != nil {
+			return fmt.Errorf("failed to sign %s: %w", path, err)
+		}
+		logger.Infof("Signed %s -> %s", path, sigPath)
+	}
+	return nil
+}
+
+// verifyArtifactSignature verifies the detached signature of path against publicKeyPath
+func verifyArtifactSignature(path, publicKeyPath string) error {
+	key, err := signing.LoadPublicKey(publicKeyPath)
+	if err != nil {
+		return err
+	}
+	sig, err := signing.VerifyFile(path, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	commit := sig.Provenance.Commit
+	if commit == "" {
+		commit = "unknown commit"
+	}
+	fmt.Printf("%s %s signed by key %s (%s %s, %s)\n", color.GreenString("✓"), path, sig.KeyID,
+		sig.Provenance.Tool, sig.Provenance.ToolVersion, commit)
+	return nil
+}
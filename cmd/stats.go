@@ -8,6 +8,10 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
 	"github.com/spf13/cobra"
 )
 
@@ -30,17 +34,95 @@ Examples:
   arch-unit stats ./src
 
   # Show detailed stats with recommendations
-  arch-unit stats --verbose`,
+  arch-unit stats --verbose
+
+  # Summarise the code analyzed by 'ast analyze'
+  arch-unit stats code`,
 	RunE: runStats,
 }
 
+var statsCodeCmd = &cobra.Command{
+	Use:   "code [directory]",
+	Short: "Summarise the languages, complexity, largest types and most depended-on packages",
+	Long: `Summarise the code analyzed by 'ast analyze' under a directory, the working directory by
+default: the lines, comment density, functions and average and percentile cyclomatic complexity
+of every language, the types with the most methods and fields, and the packages most depended on.
+
+Examples:
+  # Summarise the working directory
+  arch-unit stats code
+
+  # As markdown, e.g. for a wiki page
+  arch-unit stats code --format markdown > STATS.md
+
+  # As JSON, listing the 50 largest types and most depended-on packages
+  arch-unit stats code --limit 50 --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStatsCode,
+}
+
 var (
 	statsVerbose bool
+	statsLimit   int
 )
 
 func init() {
 	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsCodeCmd)
 	statsCmd.Flags().BoolVar(&statsVerbose, "verbose", false, "Show detailed statistics")
+	statsCodeCmd.Flags().IntVar(&statsLimit, "limit", 10, "Number of the largest types and most depended-on packages listed, 0 for all")
+}
+
+func runStatsCode(cmd *cobra.Command, args []string) error {
+	dir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if len(args) > 0 {
+		if dir, err = filepath.Abs(args[0]); err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", args[0], err)
+		}
+	}
+
+	stats, err := query.NewAQLEngine(cache.MustGetASTCache()).CodebaseStats(dir+"/", statsLimit)
+	if err != nil {
+		return fmt.Errorf("failed to compute codebase statistics: %w", err)
+	}
+	if len(stats.Languages) == 0 {
+		logger.Infof("No code found in cache for %s", dir)
+		logger.Infof("%s", i18n.T("hint.analyze_first"))
+		return nil
+	}
+
+	format := clicky.Flags.FormatOptions.ResolveFormat()
+	if format == "json" || format == "yaml" {
+		fmt.Println(clicky.MustFormat(stats))
+		return nil
+	}
+	sections := []struct {
+		title string
+		rows  interface{}
+		empty bool
+	}{
+		{"Languages", stats.Languages, false},
+		{"Largest types", stats.LargestTypes, len(stats.LargestTypes) == 0},
+		{"Most depended-on packages", stats.MostDependedOn, len(stats.MostDependedOn) == 0},
+	}
+	for i, section := range sections {
+		if section.empty {
+			continue
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		if format == "markdown" || format == "md" {
+			fmt.Printf("## %s\n\n", section.title)
+		} else {
+			fmt.Println(color.New(color.Bold).Sprint(section.title))
+		}
+		fmt.Println(clicky.MustFormat(section.rows))
+	}
+	return nil
 }
 
 func runStats(cmd *cobra.Command, args []string) error {
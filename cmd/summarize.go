@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	summarizeProvider string
+	summarizeModel    string
+	summarizeForce    bool
+	summarizeLimit    int
+)
+
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize [pattern]",
+	Short: "Generate AI summaries of fields, methods and types",
+	Long: `Generate summaries of the fields, methods and types analyzed by 'ast analyze' with an LLM.
+Summaries are at most 5 words for fields, 20 for methods and 50 for types. They are cached by the
+hash of their file, so re-analyzing unchanged files does not summarize them again, and are shown
+by 'ast' and as tooltips in HTML reports.
+
+Nodes that already have a summary, e.g. from LSIF hover text or SQL comments, are kept unless
+--force is set.
+
+The provider defaults to $ARCH_UNIT_LLM_PROVIDER, the ai section of arch-unit.yaml, or the first
+of anthropic ($ANTHROPIC_API_KEY), openai ($OPENAI_API_KEY) and ollama that is available.
+OPENAI_BASE_URL points the openai provider at compatible servers, OLLAMA_HOST at a remote Ollama.
+bedrock uses AWS_REGION and AWS_BEARER_TOKEN_BEDROCK or the AWS access keys.
+
+Examples:
+  # Summarize every node under the working directory
+  arch-unit summarize
+
+  # Summarize the methods of services with a local model
+  arch-unit summarize "*:*Service:*" --provider ollama --model qwen2.5-coder
+
+  # Regenerate the summaries of a package
+  arch-unit summarize "auth:*" --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSummarize,
+}
+
+func init() {
+	rootCmd.AddCommand(summarizeCmd)
+	summarizeCmd.Flags().StringVar(&summarizeProvider, "provider", "", "LLM provider: anthropic, openai, bedrock or ollama")
+	summarizeCmd.Flags().StringVar(&summarizeModel, "model", "", "Model of the provider, defaults to $ARCH_UNIT_LLM_MODEL or a small model of the provider")
+	summarizeCmd.Flags().BoolVar(&summarizeForce, "force", false, "Regenerate existing and cached summaries")
+	summarizeCmd.Flags().IntVar(&summarizeLimit, "limit", 0, "Maximum number of nodes summarized, 0 for every node")
+}
+
+func runSummarize(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	var pattern *models.AQLPattern
+	if len(args) > 0 {
+		if pattern, err = models.ParsePattern(args[0]); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	ai, err := loadAIConfig(workingDir)
+	if err != nil {
+		return err
+	}
+	provider, err := newLLMProvider(ai, summarizeProvider, summarizeModel)
+	if err != nil {
+		return err
+	}
+
+	astCache := cache.MustGetASTCache()
+	all, err := astCache.QueryASTNodes("SELECT * FROM ast_nodes WHERE file_path LIKE ? ORDER BY file_path, start_line", workingDir+"/%")
+	if err != nil {
+		return err
+	}
+	var nodes []*models.ASTNode
+	for _, node := range all {
+		if models.SummaryWordLimit(node.NodeType) == 0 || (pattern != nil && !pattern.Matches(node)) {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		logger.Infof("No fields, methods or types to summarize in %s", workingDir)
+		logger.Infof("%s", i18n.T("hint.analyze_first"))
+		return nil
+	}
+	if summarizeLimit > 0 && len(nodes) > summarizeLimit {
+		nodes = nodes[:summarizeLimit]
+	}
+
+	// Summaries are stored as they are generated, an interrupt keeps the ones written so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	logger.Infof("Summarizing %d nodes with %s", len(nodes), provider.Name())
+	result, err := analysis.NewSummarizer(astCache, provider).Summarize(ctx, nodes, summarizeForce)
+	if err != nil {
+		return fmt.Errorf("failed to summarize: %w", err)
+	}
+	logger.Infof("Generated %d summaries, %d from the cache, %d skipped and %d failed",
+		result.Generated, result.Cached, result.Skipped, result.Failed)
+	if result.Failed > 0 && result.Generated == 0 && result.Cached == 0 {
+		return fmt.Errorf("failed to summarize any of %d nodes with %s", result.Failed, provider.Name())
+	}
+	return nil
+}
+
+// attachSummaries copies the summaries of the nodes violations are reported in and call, so HTML
+// reports can show them as tooltips
+func attachSummaries(violations []models.Violation) {
+	astCache, err := cache.GetASTCache()
+	if err != nil {
+		logger.Debugf("Reporting violations without summaries: %v", err)
+		return
+	}
+	nodes, err := astCache.SummarizedNodes()
+	if err != nil || len(nodes) == 0 {
+		return
+	}
+
+	byFile := make(map[string][]*models.ASTNode)
+	byName := make(map[string]*models.ASTNode)
+	for _, node := range nodes {
+		byFile[node.FilePath] = append(byFile[node.FilePath], node)
+		if node.MethodName != "" {
+			byName[node.PackageName+"."+node.MethodName] = node
+		}
+	}
+
+	for i := range violations {
+		v := &violations[i]
+		if v.Caller != nil && v.Caller.Summary == nil {
+			// The innermost summarized node around the violation
+			var enclosing *models.ASTNode
+			for _, node := range byFile[v.File] {
+				if node.StartLine <= v.Line && v.Line <= node.EndLine &&
+					(enclosing == nil || node.StartLine >= enclosing.StartLine) {
+					enclosing = node
+				}
+			}
+			if enclosing != nil {
+				caller := *v.Caller
+				caller.Summary = enclosing.Summary
+				v.Caller = &caller
+			}
+		}
+		if v.Called != nil && v.Called.Summary == nil {
+			if node, ok := byName[v.Called.PackageName+"."+v.Called.MethodName]; ok {
+				called := *v.Called
+				called.Summary = node.Summary
+				v.Called = &called
+			}
+		}
+	}
+}
+
+// explainViolations sets the remediation suggestions of violations for `check --explain`. The
+// check does not fail when the LLM is unavailable, violations are then reported without them.
+func explainViolations(violations []models.Violation, ai *models.AIConfig) {
+	if len(violations) == 0 {
+		return
+	}
+	provider, err := newLLMProvider(ai, checkExplainProvider, checkExplainModel)
+	if err != nil {
+		logger.Warnf("Reporting violations without suggestions: %v", err)
+		return
+	}
+	astCache, err := cache.GetASTCache()
+	if err != nil {
+		logger.Warnf("Reporting violations without suggestions: %v", err)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := analysis.NewExplainer(astCache, provider).Explain(ctx, violations, checkExplainLimit)
+	if err != nil {
+		logger.Warnf("Failed to explain violations: %v", err)
+	}
+	if result == nil {
+		return
+	}
+	logger.Infof("Explained %d violations with %s, %d from the cache", result.Generated+result.Cached, provider.Name(), result.Cached)
+	if result.Skipped > 0 {
+		logger.Infof("Skipped %d violations beyond --explain-limit %d", result.Skipped, checkExplainLimit)
+	}
+}
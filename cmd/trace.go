@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/fixtures"
+	_ "github.com/flanksource/arch-unit/fixtures/types" // Register fixture types
+	"github.com/flanksource/arch-unit/linters/aql"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky"
+	"github.com/flanksource/commons/logger"
+	"github.com/spf13/cobra"
+)
+
+var traceStrict bool
+
+var traceCmd = &cobra.Command{
+	Use:   "trace [fixture-files...]",
+	Short: "Show which fixtures exercise each architecture rule",
+	Long: `Build a traceability matrix linking every enabled AQL rule in arch-unit.yaml to the
+fixture tests that exercise it.
+
+Fixtures declare the rule they exercise and the expected outcome with the Rule and
+Expect columns of a fixture table, or the rule and expect keys of a command block
+frontmatter. Expect is either "pass" (the code complies with the rule) or
+"violation" (the rule must report it). Only fixtures that run successfully count.
+
+  | Test Name | CLI Args | Rule | Expect | Exit Code |
+  |-----------|----------|------|--------|-----------|
+  | Layered service | check | no-db-in-controllers | pass | 0 |
+  | Controller queries db | check | no-db-in-controllers | violation | 1 |
+
+With --strict the command fails unless every enabled rule has at least one passing
+and one violating example, which makes it suitable as a CI gate.
+
+Fixture files default to tests/fixtures/**/*.md and examples/**/*.md.`,
+	Example: `  # Show the matrix for the default fixture locations
+  arch-unit trace
+
+  # Fail the build when a rule lacks a passing or a violating example
+  arch-unit trace --strict
+
+  # Restrict to specific fixture files
+  arch-unit trace tests/fixtures/rules/*.md --format json`,
+	RunE:         runTrace,
+	SilenceUsage: true,
+}
+
+func runTrace(cmd *cobra.Command, args []string) error {
+	workingDir, err := GetWorkingDir()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	archConfig, err := config.NewParser(workingDir).LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	rules, err := loadEnabledAQLRules(workingDir, archConfig)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		logger.Warnf("No enabled AQL rules found in configuration")
+	}
+
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{
+			filepath.Join(workingDir, "tests", "fixtures", "**", "*.md"),
+			filepath.Join(workingDir, "examples", "**", "*.md"),
+		}
+	}
+
+	executablePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	runner, err := fixtures.NewRunner(fixtures.RunnerOptions{
+		Paths:          paths,
+		NoColor:        clicky.Flags.FormatOptions.NoColor,
+		WorkDir:        workingDir,
+		MaxWorkers:     clicky.Flags.MaxConcurrent,
+		Logger:         logger.StandardLogger(),
+		ExecutablePath: executablePath,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create fixture runner: %w", err)
+	}
+
+	// Without fixtures every rule is reported as uncovered rather than failing outright
+	if _, err := runner.Execute(); err != nil {
+		logger.Warnf("%v", err)
+	}
+	clicky.WaitForGlobalCompletionSilent()
+
+	matrix := fixtures.BuildTraceabilityMatrix(rules, runner.Tree())
+
+	output, err := clicky.Format(matrix.Rules, clicky.FormatOptions{
+		Format:  clicky.Flags.ResolveFormat(),
+		NoColor: clicky.Flags.FormatOptions.NoColor,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to format traceability matrix: %w", err)
+	}
+	fmt.Println(output)
+
+	for _, orphan := range matrix.Orphans {
+		logger.Warnf("Fixture %s references a rule that is not enabled", orphan.Fixture)
+	}
+
+	uncovered := matrix.Uncovered()
+	if len(uncovered) == 0 {
+		return nil
+	}
+
+	var missing []string
+	for _, rule := range uncovered {
+		missing = append(missing, fmt.Sprintf("%s (missing %s)", rule.Rule, rule.Missing()))
+	}
+	if traceStrict {
+		return fmt.Errorf("%d rules are not covered by fixtures: %s", len(uncovered), strings.Join(missing, ", "))
+	}
+	logger.Warnf("%d rules are not covered by fixtures: %s", len(uncovered), strings.Join(missing, ", "))
+	return nil
+}
+
+// loadEnabledAQLRules parses the enabled AQL rule sets of the configuration
+func loadEnabledAQLRules(workingDir string, archConfig *models.Config) ([]*models.AQLRule, error) {
+	var rules []*models.AQLRule
+	for _, ruleConfig := range archConfig.AQLRules {
+		if !ruleConfig.Enabled {
+			continue
+		}
+
+		ruleText, sourceFile, err := aql.ReadRuleConfig(workingDir, ruleConfig)
+		if err != nil {
+			return nil, err
+		}
+		if ruleText == "" {
+			continue
+		}
+
+		ruleSet, err := aql.ParseRules(ruleText)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse AQL rules from %s: %w", sourceFile, err)
+		}
+
+		for _, rule := range ruleSet.Rules {
+			if rule.SourceFile == "" {
+				rule.SourceFile = sourceFile
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func init() {
+	traceCmd.Flags().BoolVar(&traceStrict, "strict", false, "Fail unless every enabled rule has a passing and a violating example")
+	rootCmd.AddCommand(traceCmd)
+}
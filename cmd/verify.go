@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/flanksource/arch-unit/internal/manifest"
+	"github.com/flanksource/arch-unit/internal/signing"
+	"github.com/flanksource/clicky"
+	"github.com/spf13/cobra"
+)
+
+var verifyResults string
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <manifest.json> [path]",
+	Short: "Verify that an analysis manifest matches a source tree",
+	Long: `Verify that a results bundle corresponds to a given source tree.
+
+The manifest is written by 'arch-unit check --manifest' and records the arch-unit
+version, linter versions and the checksums of every configuration and source file
+that was analyzed. Verification fails if any file was added, removed or modified.
+
+Examples:
+  # Produce results together with a manifest
+  arch-unit check --json -o results.json --manifest manifest.json
+
+  # Verify the current directory against the manifest
+  arch-unit verify manifest.json
+
+  # Also verify the results file checksum
+  arch-unit verify manifest.json ./src --results results.json
+
+  # Require a valid signature on the manifest (see 'arch-unit sign')
+  arch-unit verify manifest.json --public-key ci.pub`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE:         runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringVar(&verifyResults, "results", "", "Results file whose checksum should match the manifest")
+	verifyCmd.Flags().StringVar(&signPublicKey, "public-key", "", "Require the manifest to be signed by this public key")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if signPublicKey != "" {
+		if err := verifyArtifactSignature(args[0], signPublicKey); err != nil {
+			return err
+		}
+	}
+
+	m, err := manifest.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	root := "."
+	if len(args) > 1 {
+		root = args[1]
+	} else if wd, err := GetWorkingDir(); err == nil {
+		root = wd
+	}
+
+	manifestPath, _ := filepath.Abs(args[0])
+	skip := []string{manifestPath, signing.SignatureFile(manifestPath)}
+	if verifyResults != "" {
+		skip = append(skip, signing.SignatureFile(verifyResults))
+	}
+	result, err := m.Verify(root, verifyResults, skip...)
+	if err != nil {
+		return err
+	}
+
+	if !result.OK() {
+		fmt.Println(clicky.MustFormat(result))
+		return fmt.Errorf("source tree does not match manifest created by %s %s at %s",
+			m.Tool.Name, m.Tool.Version, m.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+
+	fmt.Printf("%s %d files match manifest (%s %s, %d linters)\n",
+		color.GreenString("✓"), result.FilesChecked, m.Tool.Name, m.Tool.Version, len(m.Linters))
+	return nil
+}
+
+// writeAnalysisManifest generates a manifest for an analysis of workingDir
+func writeAnalysisManifest(path, workingDir string, linterNames []string) error {
+	tool := manifest.ToolInfo{Name: "arch-unit", Version: "dev"}
+	if getVersionInfo != nil {
+		tool.Version, tool.Commit, _, _ = getVersionInfo()
+	}
+
+	// Only checksum the first results file, and only if it has already been written
+	var resultsFile string
+	if len(outputFiles) > 0 {
+		if _, err := os.Stat(outputFiles[0]); err == nil {
+			resultsFile = outputFiles[0]
+		}
+	}
+
+	skip := []string{path, signing.SignatureFile(path)}
+	for _, file := range outputFiles {
+		skip = append(skip, file, signing.SignatureFile(file))
+	}
+
+	m, err := manifest.Generate(workingDir, manifest.Options{
+		Tool:        tool,
+		Linters:     linterNames,
+		ResultsFile: resultsFile,
+		Skip:        skip,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate manifest: %w", err)
+	}
+	return m.Write(path)
+}
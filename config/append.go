@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+	"gopkg.in/yaml.v3"
+)
+
+// AppendRule adds a proposed rule to the arch-unit.yaml found from the root directory, creating
+// one in the root directory when there is none, and returns its path. The file is edited as
+// text, so its comments and layout are kept.
+func (p *Parser) AppendRule(proposal *models.RuleProposal) (string, error) {
+	rootDir, err := filepath.Abs(p.rootDir)
+	if err != nil {
+		return "", err
+	}
+	configPath, err := p.findConfigFile(rootDir, ConfigFileName)
+	var content string
+	switch {
+	case errors.Is(err, ErrConfigNotFound):
+		configPath = filepath.Join(rootDir, ConfigFileName)
+		content = "version: \"1.0\"\n"
+	case err != nil:
+		return "", err
+	default:
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read configuration file: %w", err)
+		}
+		content = string(data)
+	}
+
+	for _, rule := range proposal.Imports {
+		if err := p.validateImportRule(rule); err != nil {
+			return "", fmt.Errorf("invalid import rule '%s': %w", rule, err)
+		}
+	}
+	updated, err := appendRule(content, proposal)
+	if err != nil {
+		return "", err
+	}
+	var config models.Config
+	if err := yaml.Unmarshal([]byte(updated), &config); err != nil {
+		return "", fmt.Errorf("adding the rule would break %s: %w", configPath, err)
+	}
+	if err := os.WriteFile(configPath, []byte(updated), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+	return configPath, nil
+}
+
+// appendRule returns content with the proposal added at the end of its aql_rules or rules
+// section, adding the section when it is missing
+func appendRule(content string, proposal *models.RuleProposal) (string, error) {
+	key, entry, err := ruleEntry(proposal)
+	if err != nil {
+		return "", err
+	}
+	if !proposal.IsAQL() {
+		var config models.Config
+		if err := yaml.Unmarshal([]byte(content), &config); err == nil {
+			if _, exists := config.Rules[proposal.Path]; exists {
+				return "", fmt.Errorf("rules of %s already exist, add the imports to them by hand:\n%s", proposal.Path, entry)
+			}
+		}
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, key+":") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return content + "\n" + key + ":\n" + indentLines(entry, "  "), nil
+	}
+
+	// An empty section may be written in flow style
+	value := strings.TrimSpace(strings.TrimPrefix(lines[start], key+":"))
+	if i := strings.Index(value, "#"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	switch value {
+	case "", "[]", "{}", "~", "null":
+		lines[start] = key + ":"
+	default:
+		return "", fmt.Errorf("%s is written in flow style, add the rule by hand:\n%s", key, entry)
+	}
+
+	// The section ends at the next top-level key, comments just above it belong to that key
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		if line != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") &&
+			!strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "-") {
+			end = i
+			break
+		}
+	}
+	for end > start+1 && (strings.TrimSpace(lines[end-1]) == "" || strings.HasPrefix(lines[end-1], "#")) {
+		end--
+	}
+
+	// Entries are indented like the first entry of the section
+	indent := "  "
+	for _, line := range lines[start+1 : end] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			indent = line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+
+	inserted := strings.Split(strings.TrimSuffix(indentLines(entry, indent), "\n"), "\n")
+	result := append(append(append([]string{}, lines[:end]...), inserted...), lines[end:]...)
+	return strings.Join(result, "\n") + "\n", nil
+}
+
+// RuleSnippet returns the proposal as the YAML added to arch-unit.yaml
+func RuleSnippet(proposal *models.RuleProposal) (string, error) {
+	key, entry, err := ruleEntry(proposal)
+	if err != nil {
+		return "", err
+	}
+	return key + ":\n" + indentLines(entry, "  "), nil
+}
+
+// ruleEntry returns the section of a proposal and its entry as YAML
+func ruleEntry(proposal *models.RuleProposal) (string, string, error) {
+	key := "aql_rules"
+	var value interface{} = []models.AQLRuleConfig{{Inline: proposal.AQL + "\n", Enabled: true}}
+	if !proposal.IsAQL() {
+		key = "rules"
+		value = map[string]models.RuleConfig{proposal.Path: {Imports: proposal.Imports}}
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", "", err
+	}
+	return key, buf.String(), nil
+}
+
+func indentLines(text, indent string) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
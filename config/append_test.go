@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("AppendRule", func() {
+	aql := &models.RuleProposal{AQL: "RULE \"Controllers do not query\" {\n  FORBID(controllers:* -> sql/*)\n}"}
+	imports := &models.RuleProposal{Path: "**/controllers/**", Imports: []string{"!gorm.io/gorm"}}
+
+	load := func(content string) models.Config {
+		var config models.Config
+		Expect(yaml.Unmarshal([]byte(content), &config)).To(Succeed())
+		return config
+	}
+
+	It("should append AQL rules to the end of aql_rules, keeping comments", func() {
+		content := `version: "1.0"
+# Architecture rules
+aql_rules:
+- inline: |
+    RULE "No cycles" {
+      CYCLE(*)
+    }
+  enabled: true
+
+# Linters
+linters:
+  golangci-lint:
+    enabled: true
+`
+		updated, err := appendRule(content, aql)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated).To(ContainSubstring("# Architecture rules"))
+		Expect(updated).To(ContainSubstring("\n\n# Linters\nlinters:"))
+
+		config := load(updated)
+		Expect(config.AQLRules).To(HaveLen(2))
+		Expect(config.AQLRules[1].Inline).To(ContainSubstring("FORBID(controllers:* -> sql/*)"))
+		Expect(config.AQLRules[1].Enabled).To(BeTrue())
+		Expect(config.Linters).To(HaveKey("golangci-lint"))
+	})
+
+	It("should add missing and empty sections", func() {
+		updated, err := appendRule("version: \"1.0\"\nrules: {}\n", imports)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(load(updated).Rules["**/controllers/**"].Imports).To(Equal([]string{"!gorm.io/gorm"}))
+
+		updated, err = appendRule(updated, aql)
+		Expect(err).ToNot(HaveOccurred())
+		config := load(updated)
+		Expect(config.AQLRules).To(HaveLen(1))
+		Expect(config.Rules).To(HaveLen(1))
+	})
+
+	It("should not overwrite the rules of a path", func() {
+		_, err := appendRule("rules:\n  \"**/controllers/**\":\n    imports: [\"!fmt\"]\n", imports)
+		Expect(err).To(MatchError(ContainSubstring("already exist")))
+	})
+
+	It("should create arch-unit.yaml when there is none", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(dir, ".git"), 0755)).To(Succeed())
+
+		path, err := NewParser(dir).AppendRule(aql)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(path).To(Equal(filepath.Join(dir, ConfigFileName)))
+
+		config, err := NewParser(dir).LoadConfig()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.AQLRules).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// rulePackTTL is how long a fetched rule pack is used before it is fetched again
+const rulePackTTL = 24 * time.Hour
+
+// resolveExtends merges the rule packs a configuration extends into it in order, the
+// configuration itself taking precedence over its rule packs
+func resolveExtends(config *models.Config, configDir string) error {
+	if len(config.Extends) == 0 {
+		return nil
+	}
+
+	merged := &models.Config{}
+	for _, extends := range config.Extends {
+		pack, err := loadRulePack(extends, configDir)
+		if err != nil {
+			return fmt.Errorf("rule pack %s: %w", extends.Source, err)
+		}
+		if len(pack.Extends) > 0 {
+			return fmt.Errorf("rule pack %s cannot extend other rule packs", extends.Source)
+		}
+		if len(pack.Plugins) > 0 {
+			return fmt.Errorf("rule pack %s cannot load plugins", extends.Source)
+		}
+		// Expired exceptions are reported at the configuration, the lines of a pack do not point into it
+		for i := range pack.Exceptions {
+			pack.Exceptions[i].Line = 0
+		}
+		mergeConfig(merged, pack)
+	}
+	mergeConfig(merged, config)
+	merged.Extends = config.Extends
+	*config = *merged
+	return nil
+}
+
+// loadRulePack reads a local rule pack or fetches a remote one, verifying its checksum
+func loadRulePack(extends models.ExtendsConfig, configDir string) (*models.Config, error) {
+	source, err := extends.ParseSource()
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	if source.IsLocal() {
+		dir := source.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(configDir, dir)
+		}
+		content, err = readRulePack(dir)
+	} else {
+		content, err = fetchRulePack(extends.Source, source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := RulePackChecksum(content)
+	if extends.Checksum != "" && extends.Checksum != checksum {
+		return nil, fmt.Errorf("checksum mismatch, expected %s, got %s", extends.Checksum, checksum)
+	} else if extends.Checksum == "" && !source.IsLocal() {
+		logger.Infof("Rule pack %s is not pinned, pin it with checksum: %s", extends.Source, checksum)
+	}
+
+	var pack models.Config
+	if err := yaml.Unmarshal(content, &pack); err != nil {
+		return nil, fmt.Errorf("failed to parse rule pack: %w", err)
+	}
+	return &pack, nil
+}
+
+// RulePackChecksum returns the sha256 checksum of the content of a rule pack, as pinned by the
+// checksum of extends
+func RulePackChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// readRulePack returns the content of the arch-unit.yaml of a rule pack directory, with the AQL
+// rule files and policies it references inlined so the rule pack is self-contained
+func readRulePack(dir string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule pack: %w", err)
+	}
+
+	var pack models.Config
+	if err := yaml.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("failed to parse rule pack: %w", err)
+	}
+	for i := range pack.AQLRules {
+		rule := &pack.AQLRules[i]
+		if rule.File == "" {
+			continue
+		}
+		file := rule.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read AQL rule file %s: %w", rule.File, err)
+		}
+		rule.File = ""
+		rule.Inline = string(content)
+	}
+	for i := range pack.Policies {
+		policy := &pack.Policies[i]
+		if policy.File == "" {
+			continue
+		}
+		file := policy.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy %s, rule packs only support single file policies: %w", policy.File, err)
+		}
+		if policy.Name == "" {
+			policy.Name = policy.File
+		}
+		policy.File = ""
+		policy.Inline = string(content)
+	}
+
+	return yaml.Marshal(&pack)
+}
+
+// fetchRulePack returns the content of a remote rule pack, fetched again once it is older than
+// rulePackTTL. Cached rule packs are used in offline mode and when fetching fails.
+func fetchRulePack(name string, source models.RulePackSource) ([]byte, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(name))
+	cached := filepath.Join(homeDir, ".cache", "arch-unit", "rules", hex.EncodeToString(sum[:8])+".yaml")
+
+	info, statErr := os.Stat(cached)
+	if statErr == nil && (offline.Enabled() || time.Since(info.ModTime()) < rulePackTTL) {
+		return os.ReadFile(cached)
+	}
+	if err := offline.Check("fetching rule pack " + name); err != nil {
+		return nil, err
+	}
+
+	content, err := cloneRulePack(source)
+	if err != nil {
+		if statErr == nil {
+			logger.Warnf("Using the cached rule pack %s: %v", name, err)
+			return os.ReadFile(cached)
+		}
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create rule pack cache: %w", err)
+	}
+	if err := os.WriteFile(cached, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to cache rule pack: %w", err)
+	}
+	return content, nil
+}
+
+// cloneRulePack clones the repository of a rule pack at its ref and reads the rule pack
+func cloneRulePack(source models.RulePackSource) ([]byte, error) {
+	gitCache, err := cache.NewGitCache()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := gitCache.CloneOrUpdate(source.Repository, source.Ref)
+	if err != nil {
+		return nil, err
+	}
+	return readRulePack(filepath.Join(dir, source.Path))
+}
+
+// mergeConfig merges src into dst. Rules and lists are appended, imports of the same path
+// pattern combined, and keyed settings and scalars of src override those of dst.
+func mergeConfig(dst, src *models.Config) {
+	if src.Version != "" {
+		dst.Version = src.Version
+	}
+	if src.GeneratedFrom != "" {
+		dst.GeneratedFrom = src.GeneratedFrom
+	}
+	if src.Debounce != "" {
+		dst.Debounce = src.Debounce
+	}
+	if src.RuleBudget != "" {
+		dst.RuleBudget = src.RuleBudget
+	}
+
+	for pattern, rule := range src.Rules {
+		if dst.Rules == nil {
+			dst.Rules = make(map[string]models.RuleConfig)
+		}
+		if existing, ok := dst.Rules[pattern]; ok {
+			rule.Imports = append(append([]string{}, existing.Imports...), rule.Imports...)
+		}
+		dst.Rules[pattern] = rule
+	}
+	dst.Variables = mergeMap(dst.Variables, src.Variables)
+	dst.BuiltinRules = mergeMap(dst.BuiltinRules, src.BuiltinRules)
+	dst.Linters = mergeMap(dst.Linters, src.Linters)
+	dst.Languages = mergeMap(dst.Languages, src.Languages)
+	dst.Queries = mergeMap(dst.Queries, src.Queries)
+	dst.Reports = mergeMap(dst.Reports, src.Reports)
+	dst.Templates = mergeMap(dst.Templates, src.Templates)
+
+	dst.GlobalExcludes = append(dst.GlobalExcludes, src.GlobalExcludes...)
+	dst.AQLRules = append(dst.AQLRules, src.AQLRules...)
+	dst.Registries = append(dst.Registries, src.Registries...)
+	dst.Layers = append(dst.Layers, src.Layers...)
+	dst.Naming = append(dst.Naming, src.Naming...)
+	dst.Annotations = append(dst.Annotations, src.Annotations...)
+	dst.CELRules = append(dst.CELRules, src.CELRules...)
+	dst.Policies = append(dst.Policies, src.Policies...)
+	dst.Plugins = append(dst.Plugins, src.Plugins...)
+	dst.Exceptions = append(dst.Exceptions, src.Exceptions...)
+	dst.Coverage = append(dst.Coverage, src.Coverage...)
+
+	if src.Extraction != nil {
+		dst.Extraction = src.Extraction
+	}
+	if src.Limits != nil {
+		dst.Limits = src.Limits
+	}
+	if src.Schema != nil {
+		dst.Schema = src.Schema
+	}
+	if src.API != nil {
+		dst.API = src.API
+	}
+	if src.GodObjects != nil {
+		dst.GodObjects = src.GodObjects
+	}
+	if src.DeadCode != nil {
+		dst.DeadCode = src.DeadCode
+	}
+	if src.Deprecated != nil {
+		dst.Deprecated = src.Deprecated
+	}
+	if src.Hotspots != nil {
+		dst.Hotspots = src.Hotspots
+	}
+	if src.Duplication != nil {
+		dst.Duplication = src.Duplication
+	}
+	if src.Vulnerabilities != nil {
+		dst.Vulnerabilities = src.Vulnerabilities
+	}
+	if src.Images != nil {
+		dst.Images = src.Images
+	}
+	if src.AI != nil {
+		dst.AI = src.AI
+	}
+}
+
+func mergeMap[V any](dst, src map[string]V) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]V, len(src))
+	}
+	for key, value := range src {
+		dst[key] = value
+	}
+	return dst
+}
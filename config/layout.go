@@ -0,0 +1,94 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// layoutLayer is a conventional layer of an application, recognized by the names of its
+// directories, and the layers it may depend on
+type layoutLayer struct {
+	name        string
+	directories []string
+	allow       []string
+}
+
+// layoutLayers are the layers detected by DetectLayers, from the outermost in
+var layoutLayers = []layoutLayer{
+	{name: "handler", directories: []string{"handler", "handlers", "controller", "controllers", "api", "routes", "web", "views"}, allow: []string{"service", "model"}},
+	{name: "service", directories: []string{"service", "services", "usecase", "usecases", "application"}, allow: []string{"repository", "model"}},
+	{name: "repository", directories: []string{"repository", "repositories", "repo", "store", "storage", "dao", "persistence", "db"}, allow: []string{"model"}},
+	{name: "model", directories: []string{"model", "models", "domain", "entity", "entities"}},
+}
+
+// layoutDepth is the depth of the directories searched for layers, deep enough for Java sources
+// such as src/main/java/com/acme/service
+const layoutDepth = 6
+
+// layoutSkipDirs are directories never holding the packages of an application
+var layoutSkipDirs = map[string]bool{
+	".git": true, "vendor": true, "node_modules": true, "testdata": true, "dist": true, "build": true, "target": true,
+}
+
+// DetectLayers returns the layers of the conventional directories found in the first levels of
+// rootDir, e.g. handlers, services and repositories, each allowed to depend on the layers below it.
+// Layers are only returned when at least two are found, one directory name is not a layout.
+func DetectLayers(rootDir string) models.Layers {
+	found := make(map[string]map[string]bool)
+	_ = filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == rootDir {
+			return nil
+		}
+		name := d.Name()
+		if layoutSkipDirs[name] || strings.HasPrefix(name, ".") {
+			return filepath.SkipDir
+		}
+		for _, layer := range layoutLayers {
+			for _, dir := range layer.directories {
+				if name == dir {
+					if found[layer.name] == nil {
+						found[layer.name] = make(map[string]bool)
+					}
+					found[layer.name][name] = true
+				}
+			}
+		}
+		if rel, err := filepath.Rel(rootDir, path); err == nil && strings.Count(rel, string(os.PathSeparator)) >= layoutDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if len(found) < 2 {
+		return nil
+	}
+
+	var layers models.Layers
+	for _, layer := range layoutLayers {
+		dirs, ok := found[layer.name]
+		if !ok {
+			continue
+		}
+		config := models.LayerConfig{Name: layer.name}
+		names := make([]string, 0, len(dirs))
+		for dir := range dirs {
+			names = append(names, dir)
+		}
+		sort.Strings(names)
+		// Dotted packages of Java and Python name the layer last, e.g. com.acme.service
+		for _, name := range names {
+			config.Packages = append(config.Packages, name, "*."+name)
+		}
+		for _, allowed := range layer.allow {
+			if _, ok := found[allowed]; ok {
+				config.Allow = append(config.Allow, allowed)
+			}
+		}
+		layers = append(layers, config)
+	}
+	return layers
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("DetectLayers", func() {
+	mkdirs := func(root string, dirs ...string) {
+		for _, dir := range dirs {
+			Expect(os.MkdirAll(filepath.Join(root, dir), 0755)).To(Succeed())
+		}
+	}
+
+	It("should allow each detected layer to depend on the layers below it", func() {
+		root := GinkgoT().TempDir()
+		mkdirs(root, "internal/handlers", "internal/service", "internal/store", "pkg/models", "node_modules/api", "vendor/service")
+
+		Expect(DetectLayers(root)).To(Equal(models.Layers{
+			{Name: "handler", Packages: []string{"handlers", "*.handlers"}, Allow: []string{"service", "model"}},
+			{Name: "service", Packages: []string{"service", "*.service"}, Allow: []string{"repository", "model"}},
+			{Name: "repository", Packages: []string{"store", "*.store"}, Allow: []string{"model"}},
+			{Name: "model", Packages: []string{"models", "*.models"}},
+		}))
+	})
+
+	It("should leave out layers missing from the project", func() {
+		root := GinkgoT().TempDir()
+		mkdirs(root, "src/main/java/com/acme/controller", "src/main/java/com/acme/repository")
+
+		Expect(DetectLayers(root)).To(Equal(models.Layers{
+			{Name: "handler", Packages: []string{"controller", "*.controller"}},
+			{Name: "repository", Packages: []string{"repository", "*.repository"}},
+		}))
+	})
+
+	It("should not detect a layout from a single layer", func() {
+		root := GinkgoT().TempDir()
+		mkdirs(root, "api", "cmd", "docs")
+
+		Expect(DetectLayers(root)).To(BeEmpty())
+	})
+})
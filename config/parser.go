@@ -1,10 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/commons/logger"
 	"gopkg.in/yaml.v3"
@@ -12,6 +14,10 @@ import (
 
 const ConfigFileName = "arch-unit.yaml"
 
+// ErrConfigNotFound is returned by LoadConfig when no arch-unit.yaml exists between the root
+// directory and the git root, the only error that callers may answer with a default config
+var ErrConfigNotFound = errors.New("configuration file not found")
+
 type Parser struct {
 	rootDir string
 }
@@ -39,6 +45,11 @@ func findGitRoot(startDir string) string {
 	}
 }
 
+// ProjectRoot returns the root of the git repository containing dir, or dir outside of one
+func ProjectRoot(dir string) string {
+	return findGitRoot(dir)
+}
+
 // findConfigFile searches for a config file by walking up the directory tree
 func (p *Parser) findConfigFile(startDir, fileName string) (string, error) {
 	gitRoot := findGitRoot(startDir)
@@ -64,7 +75,7 @@ func (p *Parser) findConfigFile(startDir, fileName string) (string, error) {
 		dir = parent
 	}
 	
-	return "", fmt.Errorf("configuration file %s not found in directory tree from %s to %s", fileName, startDir, gitRoot)
+	return "", fmt.Errorf("%w: %s in directory tree from %s to %s", ErrConfigNotFound, fileName, startDir, gitRoot)
 }
 
 // LoadConfig loads the arch-unit.yaml configuration file
@@ -85,6 +96,11 @@ func (p *Parser) LoadConfig() (*models.Config, error) {
 		return nil, fmt.Errorf("failed to parse YAML configuration: %w", err)
 	}
 
+	// Merge the shared rule packs the configuration extends
+	if err := resolveExtends(&config, filepath.Dir(configPath)); err != nil {
+		return nil, fmt.Errorf("failed to extend configuration: %w", err)
+	}
+
 	// Validate configuration
 	if err := p.validateConfig(&config); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -93,6 +109,33 @@ func (p *Parser) LoadConfig() (*models.Config, error) {
 	return &config, nil
 }
 
+// LoadCacheConfig returns the cache section of the arch-unit.yaml found from the root directory,
+// nil without one. It is read before the cache is opened, so rule packs are not extended.
+func (p *Parser) LoadCacheConfig() (*models.CacheConfig, error) {
+	configPath, err := p.findConfigFile(p.rootDir, ConfigFileName)
+	if errors.Is(err, ErrConfigNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	var config struct {
+		Cache *models.CacheConfig `yaml:"cache"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML configuration: %w", err)
+	}
+	if err := config.Cache.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cache config: %w", err)
+	}
+	return config.Cache, nil
+}
+
 // validateConfig performs basic validation on the configuration
 func (p *Parser) validateConfig(config *models.Config) error {
 	if config.Version == "" {
@@ -120,6 +163,183 @@ func (p *Parser) validateConfig(config *models.Config) error {
 		}
 	}
 
+	// Validate extraction profile
+	if _, err := config.Extraction.Profile(); err != nil {
+		return fmt.Errorf("invalid extraction config: %w", err)
+	}
+
+	// Validate resource limits
+	if config.Limits != nil {
+		if err := config.Limits.Validate(); err != nil {
+			return fmt.Errorf("invalid limits: %w", err)
+		}
+		for tool, limits := range config.Limits.Tools {
+			if err := limits.Validate(); err != nil {
+				return fmt.Errorf("invalid limits for tool '%s': %w", tool, err)
+			}
+		}
+	}
+
+	// Validate vulnerability scanning
+	if err := config.Vulnerabilities.Validate(); err != nil {
+		return fmt.Errorf("invalid vulnerabilities config: %w", err)
+	}
+
+	// Validate the image policy
+	if err := config.Images.Validate(); err != nil {
+		return fmt.Errorf("invalid images config: %w", err)
+	}
+
+	// Validate the LLM provider
+	if err := config.AI.Validate(); err != nil {
+		return fmt.Errorf("invalid ai config: %w", err)
+	}
+
+	// Validate the database of the AST cache
+	if err := config.Cache.Validate(); err != nil {
+		return fmt.Errorf("invalid cache config: %w", err)
+	}
+
+	// Validate registry credentials
+	for _, registry := range config.Registries {
+		if err := registry.Validate(); err != nil {
+			return fmt.Errorf("invalid registries config: %w", err)
+		}
+	}
+
+	// Validate the severity of AQL rules
+	for i, rule := range config.AQLRules {
+		if rule.Severity == "" {
+			continue
+		}
+		if _, err := models.ParseSeverity(string(rule.Severity)); err != nil {
+			return fmt.Errorf("invalid aql rule #%d: %w", i+1, err)
+		}
+	}
+
+	// Instantiate rule templates
+	if err := config.ExpandTemplates(); err != nil {
+		return fmt.Errorf("invalid templates config: %w", err)
+	}
+
+	// Validate the layered architecture
+	if err := config.Layers.Validate(); err != nil {
+		return fmt.Errorf("invalid layers config: %w", err)
+	}
+
+	// Validate naming conventions
+	for i := range config.Naming {
+		if err := config.Naming[i].Validate(); err != nil {
+			return fmt.Errorf("invalid naming rule '%s': %w", config.Naming[i].RuleName(), err)
+		}
+	}
+
+	// Validate annotation rules
+	for i := range config.Annotations {
+		if err := config.Annotations[i].Validate(); err != nil {
+			return fmt.Errorf("invalid annotation rule '%s': %w", config.Annotations[i].RuleName(), err)
+		}
+	}
+
+	// Validate CEL rules
+	for i := range config.CELRules {
+		if err := config.CELRules[i].Validate(); err != nil {
+			return fmt.Errorf("invalid cel rule #%d: %w", i+1, err)
+		}
+	}
+
+	// Validate OPA/Rego policies
+	for i := range config.Policies {
+		if err := config.Policies[i].Validate(); err != nil {
+			return fmt.Errorf("invalid policy '%s': %w", config.Policies[i].PolicyName(), err)
+		}
+	}
+
+	// Validate SQL schema rules
+	if config.Schema != nil {
+		if err := config.Schema.Validate(); err != nil {
+			return fmt.Errorf("invalid schema: %w", err)
+		}
+	}
+
+	// Validate REST API rules
+	if config.API != nil {
+		if err := config.API.Validate(); err != nil {
+			return fmt.Errorf("invalid api: %w", err)
+		}
+	}
+
+	// Validate god-object rules
+	if config.GodObjects != nil {
+		if err := config.GodObjects.Validate(); err != nil {
+			return fmt.Errorf("invalid god_objects: %w", err)
+		}
+	}
+
+	// Validate the dead code rule
+	if config.DeadCode != nil {
+		if err := config.DeadCode.Validate(); err != nil {
+			return fmt.Errorf("invalid dead_code: %w", err)
+		}
+	}
+
+	// Validate the hotspot rule
+	if config.Hotspots != nil {
+		if err := config.Hotspots.Validate(); err != nil {
+			return fmt.Errorf("invalid hotspots: %w", err)
+		}
+	}
+
+	// Validate duplicate code detection
+	if config.Duplication != nil {
+		if err := config.Duplication.Validate(); err != nil {
+			return fmt.Errorf("invalid duplication: %w", err)
+		}
+	}
+
+	// Validate the deprecated rule
+	if config.Deprecated != nil {
+		if err := config.Deprecated.Validate(); err != nil {
+			return fmt.Errorf("invalid deprecated: %w", err)
+		}
+	}
+
+	// Validate coverage report patterns
+	for _, pattern := range config.Coverage {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid coverage pattern '%s'", pattern)
+		}
+	}
+
+	// Validate exceptions
+	for i := range config.Exceptions {
+		if err := config.Exceptions[i].Validate(); err != nil {
+			return fmt.Errorf("invalid exception #%d: %w", i+1, err)
+		}
+	}
+
+	// Validate named queries
+	for name, query := range config.Queries {
+		if query.AQL == "" && query.Pattern == "" {
+			return fmt.Errorf("query '%s' must define either 'aql' or 'pattern'", name)
+		}
+		if query.AQL != "" && query.Pattern != "" {
+			return fmt.Errorf("query '%s' cannot define both 'aql' and 'pattern'", name)
+		}
+	}
+
+	// Validate that reports only reference defined queries
+	for name, report := range config.Reports {
+		if len(report.Queries) == 0 {
+			return fmt.Errorf("report '%s' must reference at least one query", name)
+		}
+		for _, queryName := range report.Queries {
+			if _, ok := config.Queries[queryName]; !ok {
+				return fmt.Errorf("report '%s' references unknown query '%s'", name, queryName)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -132,6 +352,13 @@ func (p *Parser) validateRuleConfig(pattern string, config *models.RuleConfig) e
 		}
 	}
 
+	// Validate the severity of import violations
+	if config.Severity != "" {
+		if _, err := models.ParseSeverity(string(config.Severity)); err != nil {
+			return err
+		}
+	}
+
 	// Validate import rules format
 	for i, importRule := range config.Imports {
 		if err := p.validateImportRule(importRule); err != nil {
@@ -178,6 +405,13 @@ func (p *Parser) validateLinterConfig(name string, config *models.LinterConfig)
 		}
 	}
 
+	// Validate resource limits
+	if config.Limits != nil {
+		if err := config.Limits.Validate(); err != nil {
+			return fmt.Errorf("invalid limits: %w", err)
+		}
+	}
+
 	// Validate output format
 	if config.OutputFormat != "" {
 		validFormats := []string{"json", "text", "xml", "junit"}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -62,6 +64,427 @@ linters:
 			Expect(exists).To(BeTrue())
 			Expect(golangciLint.Enabled).To(BeTrue())
 		})
+
+		It("should load named queries and reports", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+queries:
+  large_services:
+    description: Services that have grown too large
+    aql: "lines(*Service*) > 300"
+  orphan_services:
+    pattern: "*Service"
+reports:
+  weekly:
+    title: Weekly review
+    queries: [large_services, orphan_services]
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.GetQueryNames()).To(Equal([]string{"large_services", "orphan_services"}))
+
+			q, ok := config.GetQuery("orphan_services")
+			Expect(ok).To(BeTrue())
+			Expect(q.Expression()).To(Equal("*Service"))
+			Expect(config.Reports["weekly"].Queries).To(HaveLen(2))
+		})
+
+		It("should reject reports that reference unknown queries", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+queries:
+  large_services:
+    aql: "lines(*Service*) > 300"
+reports:
+  weekly:
+    queries: [large_services, missing]
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			_, err := NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unknown query 'missing'"))
+		})
+
+		It("should load resource limits with per-tool and per-linter overrides", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+limits:
+  timeout: 5m
+  memory: 2G
+  tools:
+    node:
+      memory: 1G
+linters:
+  golangci-lint:
+    enabled: true
+    limits:
+      timeout: 15m
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.GetResourceLimits("node", nil)).To(Equal(models.ResourceLimits{Timeout: "5m", Memory: "1G"}))
+
+			linterConfig := config.GetLinterConfig("golangci-lint", tempDir)
+			Expect(config.GetResourceLimits("golangci-lint", linterConfig.Limits)).To(Equal(models.ResourceLimits{Timeout: "15m", Memory: "2G"}))
+		})
+
+		It("should reject invalid resource limits", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+limits:
+  tools:
+    ruff:
+      memory: lots
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			_, err := NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid limits for tool 'ruff'"))
+		})
+
+		It("should load per-ecosystem vulnerability settings", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+vulnerabilities:
+  min_severity: medium
+  ignore: [CVE-2021-23337]
+  ecosystems:
+    maven:
+      enabled: false
+    npm:
+      min_severity: high
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Vulnerabilities.IsEcosystemEnabled("maven")).To(BeFalse())
+			Expect(config.Vulnerabilities.IsEcosystemEnabled("go")).To(BeTrue())
+			Expect(config.Vulnerabilities.GetMinSeverity("npm")).To(Equal("high"))
+			Expect(config.Vulnerabilities.GetMinSeverity("pip")).To(Equal("medium"))
+			Expect(config.Vulnerabilities.IsIgnored("go", "GHSA-35jh-r3h4-6jhm", "cve-2021-23337")).To(BeTrue())
+
+			configContent = strings.Replace(configContent, "min_severity: high", "min_severity: severe", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ecosystem npm: invalid min_severity 'severe'"))
+		})
+
+		It("should load the image policy", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+images:
+  forbid_latest: true
+  require_digest: true
+  registries: [docker.io, ghcr.io/flanksource]
+  ignore: ["localhost:5000/**"]
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Images.IsEnabled()).To(BeTrue())
+			Expect(config.Images.Registries).To(Equal([]string{"docker.io", "ghcr.io/flanksource"}))
+			Expect(config.Images.IsIgnored("localhost:5000/app")).To(BeTrue())
+
+			configContent = strings.Replace(configContent, "[docker.io,", `["docker.io@latest",`, 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid images config: invalid registry 'docker.io@latest'"))
+		})
+
+		It("should load the ai provider", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+ai:
+  provider: ollama
+  model: qwen2.5-coder
+  base_url: http://localhost:11434
+  requests_per_minute: 30
+  token_budget: 200000
+  embeddings:
+    provider: ollama
+    model: nomic-embed-text
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.AI.Provider).To(Equal("ollama"))
+			Expect(config.AI.TokenBudget).To(Equal(200000))
+			Expect(config.AI.Embeddings.Model).To(Equal("nomic-embed-text"))
+
+			configContent = strings.Replace(configContent, "provider: ollama\n    model: nomic", "provider: anthropic\n    model: nomic", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid ai config: embeddings: invalid provider 'anthropic'"))
+		})
+
+		It("should load the database of the cache without extending rule packs", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+extends:
+  - https://example.invalid/rules.yaml
+cache:
+  dsn_env: ARCH_UNIT_TEST_DSN
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			GinkgoT().Setenv("ARCH_UNIT_TEST_DSN", "postgres://arch-unit@db/arch_unit")
+
+			cacheConfig, err := NewParser(tempDir).LoadCacheConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cacheConfig.ResolveDSN()).To(Equal("postgres://arch-unit@db/arch_unit"))
+
+			configContent = "version: \"1.0\"\ncache:\n  dsn: /tmp/ast.db\n"
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadCacheConfig()
+			Expect(err).To(MatchError(ContainSubstring("invalid cache config: invalid dsn")))
+
+			configContent = "version: \"1.0\"\ncache:\n  remote: ftp://host/arch-unit\n"
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadCacheConfig()
+			Expect(err).To(MatchError(ContainSubstring("invalid cache config: invalid remote ftp://host/arch-unit")))
+
+			cacheConfig, err = NewParser(GinkgoT().TempDir()).LoadCacheConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cacheConfig).To(BeNil())
+		})
+
+		It("should load the registry credentials", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+registries:
+  - url: https://npm.pkg.github.com
+    type: npm
+    scopes: ["@flanksource"]
+    token: $NPM_TOKEN
+  - url: https://github.example.com
+    type: github
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Registries).To(HaveLen(2))
+			Expect(config.Registries[0].Scopes).To(Equal([]string{"@flanksource"}))
+			Expect(config.Registries[0].Token).To(Equal("$NPM_TOKEN"))
+
+			configContent = strings.Replace(configContent, "type: npm", "type: helm", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid registries config: scopes of registry https://npm.pkg.github.com require type npm"))
+		})
+
+		It("should load the layers", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+layers:
+  - name: handler
+    packages: ["handler", "*api"]
+    allow: [service]
+  - name: service
+    packages: ["service"]
+    allow: [repo]
+  - name: repo
+    packages: ["repo*"]
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Layers).To(HaveLen(3))
+			Expect(config.Layers[0].Packages).To(Equal([]string{"handler", "*api"}))
+			Expect(config.Layers[1].Allow).To(Equal([]string{"repo"}))
+
+			configContent = strings.Replace(configContent, "allow: [repo]", "allow: [repository]", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid layers config: layer 'service' allows unknown layer 'repository'"))
+		})
+
+		It("should load the naming conventions", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+naming:
+  - name: Interfaces describe behaviour
+    kind: interface
+    pattern: er$
+  - kind: table
+    pattern: ^[a-z][a-z0-9_]*$
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Naming).To(HaveLen(2))
+			Expect(config.Naming[0].Kind).To(Equal("interface"))
+			Expect(config.Naming[1].Pattern).To(Equal("^[a-z][a-z0-9_]*$"))
+
+			configContent = strings.Replace(configContent, "kind: table", "kind: tables", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid naming rule 'tables naming': invalid kind 'tables'"))
+		})
+
+		It("should merge the rule packs it extends", func() {
+			tempDir := GinkgoT().TempDir()
+			packDir := filepath.Join(tempDir, "packs", "go-standard")
+			Expect(os.MkdirAll(packDir, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(packDir, ConfigFileName), []byte(`
+version: "1.0"
+rules:
+  "**":
+    imports:
+      - "!internal/"
+aql_rules:
+  - file: layers.aql
+    enabled: true
+policies:
+  - file: secrets.rego
+linters:
+  golangci-lint:
+    enabled: true
+`), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(packDir, "layers.aql"), []byte(`RULE "Services" { FORBID(service -> controller) }`), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(packDir, "secrets.rego"), []byte("package archunit\n"), 0644)).To(Succeed())
+
+			configContent := `
+version: "1.0"
+extends:
+  - ./packs/go-standard
+rules:
+  "**":
+    imports:
+      - "!unsafe"
+linters:
+  golangci-lint:
+    enabled: false
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Rules["**"].Imports).To(Equal([]string{"!internal/", "!unsafe"}))
+			Expect(config.AQLRules).To(HaveLen(1))
+			Expect(config.AQLRules[0].File).To(BeEmpty())
+			Expect(config.AQLRules[0].Inline).To(Equal(`RULE "Services" { FORBID(service -> controller) }`))
+			Expect(config.Policies).To(Equal([]models.PolicyConfig{{Name: "secrets.rego", Inline: "package archunit\n"}}))
+			Expect(config.Linters["golangci-lint"].Enabled).To(BeFalse())
+
+			configContent = strings.Replace(configContent, "  - ./packs/go-standard", "  - source: ./packs/go-standard\n    checksum: sha256:0000", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("rule pack ./packs/go-standard: checksum mismatch, expected sha256:0000, got sha256:"))
+			// Only a missing arch-unit.yaml lets callers fall back to a default config
+			Expect(errors.Is(err, ErrConfigNotFound)).To(BeFalse())
+		})
+
+		It("should report a missing configuration file as ErrConfigNotFound", func() {
+			tempDir := GinkgoT().TempDir()
+			Expect(os.Mkdir(filepath.Join(tempDir, ".git"), 0755)).To(Succeed())
+
+			_, err := NewParser(tempDir).LoadConfig()
+			Expect(err).To(MatchError(ErrConfigNotFound))
+		})
+
+		It("should instantiate rule templates", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+templates:
+  no-calls:
+    params: [from, to]
+    aql: 'RULE "${from} must not call ${to}" { FORBID(${from} -> ${to}) }'
+aql_rules:
+  - template: no-calls
+    with: {from: service, to: controller}
+    enabled: true
+  - template: no-calls
+    with: {from: repository, to: service}
+    enabled: true
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.AQLRules[0].Inline).To(Equal(`RULE "service must not call controller" { FORBID(service -> controller) }`))
+			Expect(config.AQLRules[1].Inline).To(Equal(`RULE "repository must not call service" { FORBID(repository -> service) }`))
+
+			configContent = strings.Replace(configContent, "{from: repository, to: service}", "{from: repository}", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid templates config: aql rule #2 of template 'no-calls': missing parameter 'to'"))
+		})
+
+		It("should load the severity of import and AQL rules", func() {
+			tempDir := GinkgoT().TempDir()
+
+			configContent := `
+version: "1.0"
+rules:
+  "**":
+    severity: warning
+    imports:
+      - "!internal/"
+aql_rules:
+  - inline: 'RULE "Services" { FORBID(service -> controller) }'
+    enabled: true
+    severity: info
+`
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+
+			config, err := NewParser(tempDir).LoadConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.AQLRules[0].Severity).To(Equal(models.SeverityInfo))
+
+			rules, err := config.GetRulesForFile(filepath.Join(tempDir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules.Rules).To(HaveLen(1))
+			Expect(rules.Rules[0].Severity).To(Equal(models.SeverityWarning))
+
+			configContent = strings.Replace(configContent, "severity: warning", "severity: fatal", 1)
+			Expect(os.WriteFile(filepath.Join(tempDir, ConfigFileName), []byte(configContent), 0644)).To(Succeed())
+			_, err = NewParser(tempDir).LoadConfig()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid severity 'fatal', expected error, warning or info"))
+		})
 	})
 
 	Describe("getting rules for files", func() {
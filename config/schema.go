@@ -0,0 +1,273 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaID identifies the JSON Schema of arch-unit.yaml printed by 'arch-unit config schema'
+const SchemaID = "https://github.com/flanksource/arch-unit/schemas/arch-unit.schema.json"
+
+// JSONSchema is the subset of JSON Schema describing arch-unit.yaml
+type JSONSchema struct {
+	Schema     string                 `json:"$schema,omitempty"`
+	ID         string                 `json:"$id,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	// false for mappings without other keys, or the schema of the values of any key
+	AdditionalProperties interface{}   `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema   `json:"items,omitempty"`
+	AnyOf                []*JSONSchema `json:"anyOf,omitempty"`
+}
+
+// scalarShorthands are the configuration types that can also be written as a string
+var scalarShorthands = map[reflect.Type]bool{
+	reflect.TypeOf(models.ExtendsConfig{}): true,
+}
+
+// Schema returns the JSON Schema of arch-unit.yaml, derived from the yaml tags of models.Config
+func Schema() *JSONSchema {
+	schema := schemaOf(reflect.TypeOf(models.Config{}), map[reflect.Type]bool{})
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.ID = SchemaID
+	schema.Title = "arch-unit configuration"
+	return schema
+}
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		// Recursive types accept anything below their first level
+		if visiting[t] {
+			return &JSONSchema{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: false}
+		addFields(schema, t, visiting)
+		if scalarShorthands[t] {
+			return &JSONSchema{AnyOf: []*JSONSchema{{Type: "string"}, schema}}
+		}
+		return schema
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), visiting)}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: schemaOf(t.Elem(), visiting)}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	}
+	return &JSONSchema{}
+}
+
+// addFields adds the fields of a struct as properties, those of inlined structs included
+func addFields(schema *JSONSchema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(options, "inline") {
+			inlined := field.Type
+			for inlined.Kind() == reflect.Ptr {
+				inlined = inlined.Elem()
+			}
+			if inlined.Kind() == reflect.Map {
+				schema.AdditionalProperties = schemaOf(inlined.Elem(), visiting)
+			} else {
+				addFields(schema, inlined, visiting)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		schema.Properties[name] = schemaOf(field.Type, visiting)
+	}
+}
+
+// ValidationIssue is a problem of a configuration file found by Validate
+type ValidationIssue struct {
+	File     string          `json:"file" pretty:"label=File,style=text-blue-600"`
+	Line     int             `json:"line,omitempty" pretty:"label=Line"`
+	Severity models.Severity `json:"severity" pretty:"label=Severity"`
+	Message  string          `json:"message" pretty:"label=Message"`
+}
+
+// ValidateSchema checks YAML against the schema, reporting unknown keys with the key they were
+// probably meant to be, and values of the wrong type
+func ValidateSchema(file string, data []byte, schema *JSONSchema) []ValidationIssue {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []ValidationIssue{{File: file, Severity: models.SeverityError, Message: err.Error()}}
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+	v := &schemaValidator{file: file}
+	v.validate(root.Content[0], schema, "")
+	return v.issues
+}
+
+type schemaValidator struct {
+	file   string
+	issues []ValidationIssue
+}
+
+func (v *schemaValidator) report(node *yaml.Node, format string, args ...interface{}) {
+	v.issues = append(v.issues, ValidationIssue{File: v.file, Line: node.Line, Severity: models.SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *schemaValidator) validate(node *yaml.Node, schema *JSONSchema, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+
+	if len(schema.AnyOf) > 0 {
+		// The alternative with the fewest issues is reported
+		var best []ValidationIssue
+		for i, alternative := range schema.AnyOf {
+			branch := &schemaValidator{file: v.file}
+			branch.validate(node, alternative, path)
+			if len(branch.issues) == 0 {
+				return
+			}
+			if i == 0 || len(branch.issues) < len(best) {
+				best = branch.issues
+			}
+		}
+		v.issues = append(v.issues, best...)
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			v.report(node, "%s must be a mapping", describePath(path))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				v.validate(value, schema, path)
+				continue
+			}
+			child := joinPath(path, key.Value)
+			if property, ok := schema.Properties[key.Value]; ok {
+				v.validate(value, property, child)
+				continue
+			}
+			switch additional := schema.AdditionalProperties.(type) {
+			case *JSONSchema:
+				v.validate(value, additional, child)
+			default:
+				if suggestion := closestKey(key.Value, schema.Properties); suggestion != "" {
+					v.report(key, "unknown key '%s' in %s, did you mean '%s'?", key.Value, describePath(path), suggestion)
+				} else {
+					v.report(key, "unknown key '%s' in %s", key.Value, describePath(path))
+				}
+			}
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.report(node, "%s must be a list", describePath(path))
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(item, schema.Items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			v.report(node, "%s must be a string", describePath(path))
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			v.report(node, "%s must be an integer, not '%s'", describePath(path), node.Value)
+		}
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			v.report(node, "%s must be a number, not '%s'", describePath(path), node.Value)
+		}
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			v.report(node, "%s must be true or false, not '%s'", describePath(path), node.Value)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "the configuration"
+	}
+	return "'" + path + "'"
+}
+
+// closestKey returns the property a mistyped key was probably meant to be, empty when none is
+// close enough
+func closestKey(key string, properties map[string]*JSONSchema) string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", len(key)/3+1
+	for _, name := range names {
+		if distance := editDistance(strings.ToLower(key), name); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
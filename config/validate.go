@@ -0,0 +1,201 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/flanksource/arch-unit/models"
+	"gopkg.in/yaml.v3"
+)
+
+// skippedDirs are not searched for the files rule patterns match
+var skippedDirs = map[string]bool{"vendor": true, "node_modules": true}
+
+// Validate checks the arch-unit.yaml that applies to the root directory and the .ARCHUNIT files
+// of its repository, reporting what would otherwise silently do nothing: keys and values that do
+// not match the JSON Schema, the errors LoadConfig fails on, rule patterns that are invalid or
+// match no file, and .ARCHUNIT lines that cannot be parsed or whose file pattern matches no file.
+// Returns ErrConfigNotFound when there is neither.
+func (p *Parser) Validate() ([]ValidationIssue, error) {
+	rootDir, err := filepath.Abs(p.rootDir)
+	if err != nil {
+		return nil, err
+	}
+	gitRoot := findGitRoot(rootDir)
+	files, archUnitFiles, err := listFiles(gitRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	configPath, err := p.findConfigFile(rootDir, ConfigFileName)
+	if err != nil && len(archUnitFiles) == 0 {
+		return nil, err
+	}
+
+	var issues []ValidationIssue
+	if configPath != "" {
+		configIssues, err := p.validateConfigFile(configPath, files)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, configIssues...)
+	}
+	archUnitParser := NewArchUnitParser(gitRoot)
+	for _, path := range archUnitFiles {
+		archUnitIssues, err := archUnitParser.validateFile(path, files)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, archUnitIssues...)
+	}
+	return issues, nil
+}
+
+func (p *Parser) validateConfigFile(configPath string, files []string) ([]ValidationIssue, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	issues := ValidateSchema(configPath, data, Schema())
+
+	config, err := NewParser(filepath.Dir(configPath)).LoadConfig()
+	if err != nil {
+		// Syntax errors and values of the wrong type are reported with their line by ValidateSchema
+		if !strings.Contains(err.Error(), "failed to parse YAML") {
+			issues = append(issues, ValidationIssue{File: configPath, Severity: models.SeverityError, Message: strings.TrimPrefix(err.Error(), "invalid configuration: ")})
+		}
+		return issues, nil
+	}
+
+	ruleLines := keyLines(data, "rules")
+	configDir := filepath.Dir(configPath)
+	patterns := make([]string, 0, len(config.Rules))
+	for pattern := range config.Rules {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		issue := ValidationIssue{File: configPath, Line: ruleLines[pattern], Severity: models.SeverityWarning}
+		rule := config.Rules[pattern]
+		switch {
+		case !doublestar.ValidatePattern(pattern):
+			issue.Severity = models.SeverityError
+			issue.Message = fmt.Sprintf("rules pattern '%s' is not a valid glob", pattern)
+		case !matchesAnyFile(files, configDir, func(abs, rel string) bool { return config.PatternMatches(pattern, abs, rel) }):
+			issue.Message = fmt.Sprintf("rules pattern '%s' matches no files, its rules never apply", pattern)
+		case len(rule.Imports) == 0 && len(rule.Linters) == 0 && rule.Quality == nil && rule.Debounce == "":
+			issue.Message = fmt.Sprintf("rules pattern '%s' defines no imports, linters or quality checks", pattern)
+		default:
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// validateFile reports the lines of a .ARCHUNIT file that cannot be parsed, and the file patterns
+// that match no file under its directory
+func (p *ArchUnitParser) validateFile(path string, files []string) ([]ValidationIssue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var issues []ValidationIssue
+	scope := filepath.Dir(path)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, err := p.parseArchUnitLine(line, path, lineNum, scope)
+		switch {
+		case err != nil:
+			issues = append(issues, ValidationIssue{File: path, Line: lineNum, Severity: models.SeverityError, Message: err.Error()})
+		case rule.FilePattern == "":
+		case !validGlob(rule.FilePattern):
+			issues = append(issues, ValidationIssue{File: path, Line: lineNum, Severity: models.SeverityError,
+				Message: fmt.Sprintf("file pattern '%s' is not a valid glob", rule.FilePattern)})
+		case !matchesAnyFile(files, scope, func(abs, rel string) bool { return rule.AppliesToFile(rel) }):
+			issues = append(issues, ValidationIssue{File: path, Line: lineNum, Severity: models.SeverityWarning,
+				Message: fmt.Sprintf("file pattern '%s' matches no files under %s, the rule never applies", rule.FilePattern, scope)})
+		}
+	}
+	return issues, scanner.Err()
+}
+
+// listFiles returns the files under root and the .ARCHUNIT files among them, without hidden,
+// vendored and node_modules directories
+func listFiles(root string) (files, archUnitFiles []string, err error) {
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == ArchUnitFileName {
+			archUnitFiles = append(archUnitFiles, path)
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, archUnitFiles, err
+}
+
+// matchesAnyFile returns true if match accepts a file under dir, by its absolute path and its
+// path relative to dir
+func matchesAnyFile(files []string, dir string, match func(abs, rel string) bool) bool {
+	prefix := dir + string(filepath.Separator)
+	for _, file := range files {
+		if !strings.HasPrefix(file, prefix) {
+			continue
+		}
+		if match(file, filepath.ToSlash(strings.TrimPrefix(file, prefix))) {
+			return true
+		}
+	}
+	return false
+}
+
+func validGlob(pattern string) bool {
+	_, err := filepath.Match(pattern, "")
+	return err == nil
+}
+
+// keyLines returns the lines of the keys of a top-level mapping of a YAML document
+func keyLines(data []byte, section string) map[string]int {
+	lines := make(map[string]int)
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return lines
+	}
+	document := root.Content[0]
+	for i := 0; i+1 < len(document.Content); i += 2 {
+		if document.Content[i].Value != section || document.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		mapping := document.Content[i+1]
+		for j := 0; j+1 < len(mapping.Content); j += 2 {
+			lines[mapping.Content[j].Value] = mapping.Content[j].Line
+		}
+	}
+	return lines
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Config Validation", func() {
+	var dir string
+
+	write := func(path, content string) {
+		path = filepath.Join(dir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	messages := func(issues []ValidationIssue) []string {
+		var result []string
+		for _, issue := range issues {
+			result = append(result, issue.Message)
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		write("service/user.go", "package service\n")
+		write("api/handler.go", "package api\n")
+	})
+
+	It("should publish the schema of the configuration", func() {
+		schema := Schema()
+		Expect(schema.ID).To(Equal(SchemaID))
+		Expect(schema.AdditionalProperties).To(Equal(false))
+		Expect(schema.Properties).To(HaveKey("rules"))
+		Expect(schema.Properties["rules"].AdditionalProperties.(*JSONSchema).Properties["imports"].Type).To(Equal("array"))
+		Expect(schema.Properties["extends"].Items.AnyOf).To(HaveLen(2))
+
+		_, err := json.Marshal(schema)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should report unknown keys and values of the wrong type", func() {
+		issues := ValidateSchema("arch-unit.yaml", []byte(`
+version: "1.0"
+rule:
+  "**":
+    imports: ["!internal/"]
+rules:
+  "**":
+    import: ["!internal/"]
+linters:
+  golangci-lint:
+    enabled: yes please
+extends:
+  - ./packs/base
+  - source: ./packs/extra
+    checksum: sha256:abc
+`), Schema())
+
+		Expect(issues).To(Equal([]ValidationIssue{
+			{File: "arch-unit.yaml", Line: 3, Severity: models.SeverityError, Message: "unknown key 'rule' in the configuration, did you mean 'rules'?"},
+			{File: "arch-unit.yaml", Line: 8, Severity: models.SeverityError, Message: "unknown key 'import' in 'rules.**', did you mean 'imports'?"},
+			{File: "arch-unit.yaml", Line: 11, Severity: models.SeverityError, Message: "'linters.golangci-lint.enabled' must be true or false, not 'yes please'"},
+		}))
+	})
+
+	It("should report rule patterns that match no files", func() {
+		write(ConfigFileName, `
+rules:
+  "service/**":
+    imports: ["!api/"]
+  "servcie/**":
+    imports: ["!api/"]
+  "api/**": {}
+`)
+
+		issues, err := NewParser(dir).Validate()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issues).To(HaveLen(2))
+		Expect(issues[0].Line).To(Equal(7))
+		Expect(issues[0].Message).To(Equal("rules pattern 'api/**' defines no imports, linters or quality checks"))
+		Expect(issues[1].Line).To(Equal(5))
+		Expect(issues[1].Severity).To(Equal(models.SeverityWarning))
+		Expect(issues[1].Message).To(Equal("rules pattern 'servcie/**' matches no files, its rules never apply"))
+	})
+
+	It("should report invalid .ARCHUNIT lines and file patterns that match no files", func() {
+		write("service/.ARCHUNIT", "# rules of the service\n!internal/\n[*_test.go] +testing\n[*.py] !os\n[handler.go\n")
+
+		issues, err := NewParser(dir).Validate()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages(issues)).To(Equal([]string{
+			"file pattern '*_test.go' matches no files under " + filepath.Join(dir, "service") + ", the rule never applies",
+			"file pattern '*.py' matches no files under " + filepath.Join(dir, "service") + ", the rule never applies",
+			"invalid file-specific rule format (missing closing bracket): [handler.go",
+		}))
+		Expect(issues[2].Line).To(Equal(5))
+	})
+
+	It("should report that there is nothing to validate", func() {
+		_, err := NewParser(dir).Validate()
+		Expect(err).To(MatchError(ErrConfigNotFound))
+	})
+})
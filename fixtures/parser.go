@@ -31,6 +31,8 @@ type FixtureTest struct {
 	Env          map[string]string      // Environment variables from front-matter
 	Metadata     map[string]interface{} // Additional metadata from front-matter
 	TemplateVars map[string]string      // Template variables (.file, .filename, .dir)
+	Rule         string                 // Name of the architecture rule this test exercises
+	Expect       string                 // Whether the test is a passing or violating example of Rule
 }
 
 func (fixture FixtureTest) String() string {
@@ -198,6 +200,10 @@ func parseTableRow(headers, values []string) *FixtureNode {
 			fixture.Expected.Output = value
 		case "cel validation", "cel", "validation":
 			fixture.CEL = value
+		case "rule":
+			fixture.Rule = value
+		case "expect", "expectation":
+			fixture.Expect = value
 		default:
 			// Store unknown headers as properties
 			fixture.Expected.Properties[header] = value
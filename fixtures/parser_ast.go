@@ -322,6 +322,8 @@ func buildFixtureFromCommand(cmd *commandBlockBuilder, frontMatter *FrontMatter,
 			ExitCode *int              `yaml:"exitCode"`
 			Env      map[string]string `yaml:"env"`
 			Timeout  string            `yaml:"timeout"`
+			Rule     string            `yaml:"rule"`
+			Expect   string            `yaml:"expect"`
 		}
 		
 		if err := yaml.Unmarshal([]byte(cmd.frontmatter), &cmdFrontMatter); err == nil {
@@ -334,6 +336,8 @@ func buildFixtureFromCommand(cmd *commandBlockBuilder, frontMatter *FrontMatter,
 			if cmdFrontMatter.Env != nil {
 				fixture.Env = cmdFrontMatter.Env
 			}
+			fixture.Rule = cmdFrontMatter.Rule
+			fixture.Expect = cmdFrontMatter.Expect
 		}
 	}
 	
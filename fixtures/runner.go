@@ -57,24 +57,9 @@ func NewRunner(opts RunnerOptions) (*Runner, error) {
 
 // Run executes the fixture tests
 func (r *Runner) Run() error {
-	// Parse fixture files
-	if err := r.parseFixtureFiles(); err != nil {
-		return fmt.Errorf("failed to parse fixture files: %w", err)
-	}
-
-	// Apply filter if specified
-	if r.options.Filter != "" {
-		r.filterTests()
-	}
-
-	if len(r.fixtures) == 0 {
-		return fmt.Errorf("no fixtures found")
-	}
-
-	// Execute fixtures using TaskManager
-	results, err := r.executeFixtures()
+	results, err := r.Execute()
 	if err != nil {
-		return fmt.Errorf("failed to execute fixtures: %w", err)
+		return err
 	}
 
 	// Display results using clicky.Format() with tree structure
@@ -107,6 +92,36 @@ func (r *Runner) Run() error {
 	return nil
 }
 
+// Execute parses and runs the fixture tests without printing them, leaving the results on the
+// test nodes of Tree
+func (r *Runner) Execute() (*FixtureGroup, error) {
+	// Parse fixture files
+	if err := r.parseFixtureFiles(); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture files: %w", err)
+	}
+
+	// Apply filter if specified
+	if r.options.Filter != "" {
+		r.filterTests()
+	}
+
+	if len(r.fixtures) == 0 {
+		return nil, fmt.Errorf("no fixtures found")
+	}
+
+	// Execute fixtures using TaskManager
+	results, err := r.executeFixtures()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute fixtures: %w", err)
+	}
+	return results, nil
+}
+
+// Tree returns the hierarchical fixture tree
+func (r *Runner) Tree() *FixtureNode {
+	return r.tree
+}
+
 // parseFixtureFiles parses all fixture files from the provided paths and builds tree structure
 func (r *Runner) parseFixtureFiles() error {
 	var allFixtures []FixtureTest
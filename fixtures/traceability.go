@@ -0,0 +1,143 @@
+package fixtures
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Expectations of a fixture that exercises an architecture rule
+const (
+	ExpectPass      = "pass"
+	ExpectViolation = "violation"
+)
+
+// normalizeExpect maps the spellings accepted in fixture tables to ExpectPass or ExpectViolation
+func normalizeExpect(expect string) string {
+	switch strings.ToLower(strings.TrimSpace(expect)) {
+	case "pass", "passes", "passing", "ok", "allowed":
+		return ExpectPass
+	case "violation", "violates", "violating", "fail", "denied":
+		return ExpectViolation
+	default:
+		return ""
+	}
+}
+
+// RuleExample is a fixture test that exercises an architecture rule
+type RuleExample struct {
+	Fixture string `json:"fixture" pretty:"label=Fixture"` // fixture file > section > test name
+	Expect  string `json:"expect" pretty:"label=Expect"`
+	Passed  bool   `json:"passed" pretty:"label=Passed"`
+}
+
+// RuleTrace links an architecture rule to the fixtures that exercise it
+type RuleTrace struct {
+	Rule      string        `json:"rule" pretty:"label=Rule,style=text-blue-600"`
+	Source    string        `json:"source,omitempty" pretty:"label=Source,style=text-gray-500"`
+	Passing   int           `json:"passing" pretty:"label=Passing"`
+	Violating int           `json:"violating" pretty:"label=Violating"`
+	Examples  []RuleExample `json:"examples,omitempty" pretty:"hide"`
+}
+
+// Covered returns true when the rule has at least one passing and one violating example
+// whose fixtures succeeded
+func (t RuleTrace) Covered() bool {
+	return t.Passing > 0 && t.Violating > 0
+}
+
+// Missing describes what a rule lacks to be covered
+func (t RuleTrace) Missing() string {
+	var missing []string
+	if t.Passing == 0 {
+		missing = append(missing, "passing example")
+	}
+	if t.Violating == 0 {
+		missing = append(missing, "violating example")
+	}
+	return strings.Join(missing, " and ")
+}
+
+// TraceabilityMatrix links every enabled architecture rule to the fixtures that exercise it
+type TraceabilityMatrix struct {
+	Rules []RuleTrace `json:"rules"`
+	// Fixtures that name a rule which is not enabled, usually a typo or a removed rule
+	Orphans []RuleExample `json:"orphans,omitempty"`
+}
+
+// Uncovered returns the rules without a passing or without a violating example
+func (m TraceabilityMatrix) Uncovered() []RuleTrace {
+	var uncovered []RuleTrace
+	for _, rule := range m.Rules {
+		if !rule.Covered() {
+			uncovered = append(uncovered, rule)
+		}
+	}
+	return uncovered
+}
+
+// BuildTraceabilityMatrix matches the fixture tests below tree that declare a rule against
+// the given rules. Fixtures count towards coverage only once they have run successfully.
+func BuildTraceabilityMatrix(rules []*models.AQLRule, tree *FixtureNode) TraceabilityMatrix {
+	matrix := TraceabilityMatrix{Rules: make([]RuleTrace, 0, len(rules))}
+
+	index := make(map[string]int, len(rules))
+	for _, rule := range rules {
+		if _, exists := index[rule.Name]; exists {
+			continue
+		}
+		trace := RuleTrace{Rule: rule.Name}
+		if rule.SourceFile != "" {
+			trace.Source = filepath.Base(rule.SourceFile)
+			if rule.LineNumber > 0 {
+				trace.Source = fmt.Sprintf("%s:%d", trace.Source, rule.LineNumber)
+			}
+		}
+		index[rule.Name] = len(matrix.Rules)
+		matrix.Rules = append(matrix.Rules, trace)
+	}
+
+	if tree != nil {
+		tree.Walk(func(node *FixtureNode) {
+			if node.Test.Rule == "" {
+				return
+			}
+
+			fixture := node.getSectionOnlyPath()
+			if file := node.getFixturePath(); file != "" {
+				fixture = file + " > " + fixture
+			}
+			example := RuleExample{
+				Fixture: fixture,
+				Expect:  normalizeExpect(node.Test.Expect),
+				Passed:  node.Results != nil && node.Results.IsOK(),
+			}
+
+			i, known := index[node.Test.Rule]
+			if !known {
+				matrix.Orphans = append(matrix.Orphans, example)
+				return
+			}
+
+			trace := &matrix.Rules[i]
+			trace.Examples = append(trace.Examples, example)
+			if !example.Passed {
+				return
+			}
+			switch example.Expect {
+			case ExpectPass:
+				trace.Passing++
+			case ExpectViolation:
+				trace.Violating++
+			}
+		})
+	}
+
+	sort.SliceStable(matrix.Rules, func(i, j int) bool {
+		return matrix.Rules[i].Rule < matrix.Rules[j].Rule
+	})
+	return matrix
+}
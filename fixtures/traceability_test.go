@@ -0,0 +1,87 @@
+package fixtures
+
+import (
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/clicky/task"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Traceability matrix", func() {
+	var tree *FixtureNode
+
+	addTest := func(parent *FixtureNode, name, rule, expect string, status task.Status) {
+		parent.AddChild(&FixtureNode{
+			Name:    name,
+			Type:    TestNode,
+			Test:    &FixtureTest{Name: name, Rule: rule, Expect: expect},
+			Results: &FixtureResult{Name: name, Status: status},
+		})
+	}
+
+	BeforeEach(func() {
+		tree = &FixtureNode{Name: "Fixtures", Type: SectionNode}
+		file := &FixtureNode{Name: "rules.md", Type: FileNode}
+		tree.AddChild(file)
+		section := &FixtureNode{Name: "Layering", Type: SectionNode}
+		file.AddChild(section)
+
+		addTest(section, "service uses repository", "no-db-in-controllers", "pass", task.StatusPASS)
+		addTest(section, "controller queries db", "no-db-in-controllers", "violation", task.StatusPASS)
+		addTest(section, "utils stay pure", "pure-utils", "pass", task.StatusPASS)
+		addTest(section, "utils import http", "pure-utils", "violates", task.StatusFailed)
+		addTest(section, "removed rule", "legacy-rule", "pass", task.StatusPASS)
+		addTest(section, "unrelated", "", "", task.StatusPASS)
+	})
+
+	It("should count successful passing and violating examples per rule", func() {
+		matrix := BuildTraceabilityMatrix([]*models.AQLRule{
+			{Name: "pure-utils", SourceFile: "/repo/rules/utils.aql", LineNumber: 3},
+			{Name: "no-db-in-controllers"},
+			{Name: "no-cycles"},
+		}, tree)
+
+		Expect(matrix.Rules).To(HaveLen(3))
+		Expect(matrix.Rules[0].Rule).To(Equal("no-cycles"))
+		Expect(matrix.Rules[1].Rule).To(Equal("no-db-in-controllers"))
+		Expect(matrix.Rules[1].Passing).To(Equal(1))
+		Expect(matrix.Rules[1].Violating).To(Equal(1))
+		Expect(matrix.Rules[1].Covered()).To(BeTrue())
+		Expect(matrix.Rules[1].Examples[1]).To(Equal(RuleExample{
+			Fixture: "rules.md > Layering > controller queries db",
+			Expect:  ExpectViolation,
+			Passed:  true,
+		}))
+
+		Expect(matrix.Rules[2].Source).To(Equal("utils.aql:3"))
+		Expect(matrix.Rules[2].Passing).To(Equal(1))
+		Expect(matrix.Rules[2].Violating).To(Equal(0))
+		Expect(matrix.Rules[2].Examples).To(HaveLen(2))
+	})
+
+	It("should report uncovered rules and orphaned fixtures", func() {
+		matrix := BuildTraceabilityMatrix([]*models.AQLRule{
+			{Name: "pure-utils"},
+			{Name: "no-db-in-controllers"},
+			{Name: "no-cycles"},
+		}, tree)
+
+		uncovered := matrix.Uncovered()
+		Expect(uncovered).To(HaveLen(2))
+		Expect(uncovered[0].Rule).To(Equal("no-cycles"))
+		Expect(uncovered[0].Missing()).To(Equal("passing example and violating example"))
+		Expect(uncovered[1].Rule).To(Equal("pure-utils"))
+		Expect(uncovered[1].Missing()).To(Equal("violating example"))
+
+		Expect(matrix.Orphans).To(ConsistOf(RuleExample{
+			Fixture: "rules.md > Layering > removed rule",
+			Expect:  ExpectPass,
+			Passed:  true,
+		}))
+	})
+
+	It("should treat every rule as uncovered without fixtures", func() {
+		matrix := BuildTraceabilityMatrix([]*models.AQLRule{{Name: "no-cycles"}}, nil)
+		Expect(matrix.Uncovered()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,30 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/capabilities"
+)
+
+// Checkout checks out ref of the repository containing dir into a detached worktree at dest and
+// returns the directory of the worktree corresponding to dir
+func Checkout(dir, ref, dest string) (string, error) {
+	if err := capabilities.Require("git"); err != nil {
+		return "", err
+	}
+	prefix, err := gitOutput(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	if _, err := gitOutput(dir, "worktree", "add", "--detach", "--quiet", dest, ref); err != nil {
+		return "", err
+	}
+	return filepath.Join(dest, filepath.FromSlash(strings.TrimSpace(prefix))), nil
+}
+
+// RemoveCheckout removes a worktree created by Checkout
+func RemoveCheckout(dir, dest string) error {
+	_, err := gitOutput(dir, "worktree", "remove", "--force", dest)
+	return err
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/capabilities"
+)
+
+// Churn returns the number of commits changing each file of the repository containing dir since
+// a date git understands, e.g. "6 months ago", by absolute path. Every commit counts when since
+// is empty. Merge commits are left out, their changes are counted by the commits merged.
+func Churn(dir, since string) (map[string]int, error) {
+	if err := capabilities.Require("git"); err != nil {
+		return nil, err
+	}
+	root, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+
+	args := []string{"log", "--format=", "--name-only", "--no-renames", "--no-merges"}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	output, err := gitOutput(root, append(args, "--")...)
+	if err != nil {
+		return nil, err
+	}
+	return parseChurn(output, root), nil
+}
+
+// parseChurn counts the files listed by git log --name-only with an empty format
+func parseChurn(output, root string) map[string]int {
+	churn := make(map[string]int)
+	for _, file := range strings.Split(output, "\n") {
+		if file = strings.TrimSpace(file); file != "" {
+			churn[filepath.Join(root, filepath.FromSlash(file))]++
+		}
+	}
+	return churn
+}
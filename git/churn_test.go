@@ -0,0 +1,18 @@
+package git
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Churn", func() {
+	It("should count the commits listing each file", func() {
+		output := "\nservice/user.go\nservice/order.go\n\nservice/user.go\n\nREADME.md\nservice/user.go\n"
+
+		Expect(parseChurn(output, "/repo")).To(Equal(map[string]int{
+			"/repo/service/user.go":  3,
+			"/repo/service/order.go": 1,
+			"/repo/README.md":        1,
+		}))
+	})
+})
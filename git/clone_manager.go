@@ -13,6 +13,8 @@ import (
 
 	"log/slog"
 
+	"github.com/flanksource/arch-unit/internal/capabilities"
+	"github.com/flanksource/arch-unit/internal/offline"
 	"github.com/flanksource/arch-unit/shutdown"
 	"github.com/flanksource/commons/logger"
 )
@@ -138,6 +140,9 @@ func (cm *DefaultCloneManager) CreateClone(ctx context.Context, repoPath, versio
 
 	// Log start of operation
 	log.Debugf("Creating clone: repo=%s, version=%s, depth=%d", repoName, version, depth)
+	if err := capabilities.Require("git"); err != nil {
+		return err
+	}
 	// Ensure the repository exists and is up to date
 	if err := cm.ensureRepoFetched(repoPath); err != nil {
 		return fmt.Errorf("failed to ensure repository is fetched: %w", err)
@@ -370,6 +375,11 @@ func (cm *DefaultCloneManager) ensureRepoFetched(repoPath string) error {
 		// It's a bare repository, which is fine
 	}
 
+	// Offline, clone from the refs that have already been fetched
+	if offline.Enabled() {
+		return nil
+	}
+
 	// Fetch latest changes
 	cmd := exec.Command("git", "fetch", "--all", "--tags")
 	cmd.Dir = repoPath
@@ -0,0 +1,186 @@
+package git
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/capabilities"
+)
+
+// LineRange is an inclusive range of one based lines
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Diff holds the files changed since a revision and their changed lines, by absolute path. New
+// and untracked files have no ranges, every line of them is changed. Deleted files are held apart,
+// they have no lines to check but the files depending on them do.
+type Diff struct {
+	Base    string
+	Files   map[string][]LineRange
+	Deleted []string
+}
+
+// hunkHeader matches the new side of a hunk header, e.g. @@ -10,2 +12,3 @@
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// ChangedSince returns the changes of the working tree of the repository containing dir since
+// its merge base with ref, including uncommitted and untracked files
+func ChangedSince(dir, ref string) (*Diff, error) {
+	if err := capabilities.Require("git"); err != nil {
+		return nil, err
+	}
+	root, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+
+	// Changes of a branch are those since it forked from ref, not the changes made to ref since
+	base := ref
+	if mergeBase, err := gitOutput(dir, "merge-base", ref, "HEAD"); err == nil {
+		base = strings.TrimSpace(mergeBase)
+	}
+
+	output, err := gitOutput(root, "diff", "-U0", "--no-color", "--no-ext-diff", "--no-renames", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	diff := parseDiff(output, root)
+	diff.Base = base
+
+	untracked, err := gitOutput(root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range strings.Split(untracked, "\n") {
+		if file != "" {
+			diff.Files[filepath.Join(root, filepath.FromSlash(file))] = nil
+		}
+	}
+	return diff, nil
+}
+
+// Head returns the commit checked out in the repository containing dir, and whether its tracked
+// files have uncommitted changes
+func Head(dir string) (string, bool, error) {
+	if err := capabilities.Require("git"); err != nil {
+		return "", false, err
+	}
+	commit, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", false, err
+	}
+	status, err := gitOutput(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(commit), strings.TrimSpace(status) != "", nil
+}
+
+// ResolveCommit returns the commit a ref of the repository containing dir points to
+func ResolveCommit(dir, ref string) (string, error) {
+	if err := capabilities.Require("git"); err != nil {
+		return "", err
+	}
+	commit, err := gitOutput(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s", ref)
+	}
+	return strings.TrimSpace(commit), nil
+}
+
+// parseDiff reads the changed lines of the new side of a diff without context lines
+func parseDiff(output, root string) *Diff {
+	diff := &Diff{Files: make(map[string][]LineRange)}
+	var file, removed string
+	// Removed and added lines may start with --- and +++ too, headers only follow diff --git
+	header := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			header, file, removed = true, "", ""
+		case header && strings.HasPrefix(line, "--- "):
+			removed = strings.TrimPrefix(line, "--- ")
+		case header && strings.HasPrefix(line, "+++ "):
+			header, file = false, ""
+			if name := strings.TrimPrefix(line, "+++ "); name == "/dev/null" {
+				if removed != "/dev/null" {
+					diff.Deleted = append(diff.Deleted, filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(removed, "a/"))))
+				}
+			} else {
+				file = filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/")))
+				if _, ok := diff.Files[file]; !ok {
+					diff.Files[file] = []LineRange{}
+				}
+			}
+		case strings.HasPrefix(line, "@@") && file != "":
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(match[1])
+			count := 1
+			if match[2] != "" {
+				count, _ = strconv.Atoi(match[2])
+			}
+			if count == 0 {
+				// Lines were only removed, after the given line
+				diff.Files[file] = append(diff.Files[file], LineRange{Start: start, End: start + 1})
+			} else {
+				diff.Files[file] = append(diff.Files[file], LineRange{Start: start, End: start + count - 1})
+			}
+		}
+	}
+	return diff
+}
+
+// Paths returns the changed files, sorted
+func (d *Diff) Paths() []string {
+	paths := make([]string, 0, len(d.Files))
+	for path := range d.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Contains returns true if a line of a file changed, or any line of it when line is 0
+func (d *Diff) Contains(file string, line int) bool {
+	ranges, ok := d.Files[file]
+	if !ok {
+		return false
+	}
+	if ranges == nil || line <= 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s failed: %w", strings.Join(args, " "), err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	output := `diff --git a/service/user.go b/service/user.go
+index 1111111..2222222 100644
+--- a/service/user.go
++++ b/service/user.go
+@@ -10,0 +11,3 @@ func Create() {
++	store.Save()
++	store.Flush()
++	return nil
+@@ -40 +43 @@ func Delete() {
+-	old()
++	store.Delete()
+@@ -60,2 +62,0 @@ func List() {
+-	a()
+-	b()
+diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package main
++
+diff --git a/legacy.go b/legacy.go
+deleted file mode 100644
+--- a/legacy.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package main
+--- removed comment
+`
+
+	It("should parse the changed lines of the new side", func() {
+		diff := parseDiff(output, "/repo")
+		Expect(diff.Paths()).To(Equal([]string{"/repo/new.go", "/repo/service/user.go"}))
+		Expect(diff.Files["/repo/service/user.go"]).To(Equal([]LineRange{{11, 13}, {43, 43}, {62, 63}}))
+		Expect(diff.Files["/repo/new.go"]).To(Equal([]LineRange{{1, 2}}))
+	})
+
+	It("should hold the deleted files apart from the changed files", func() {
+		diff := parseDiff(output, "/repo")
+		Expect(diff.Deleted).To(Equal([]string{"/repo/legacy.go"}))
+		Expect(diff.Contains("/repo/legacy.go", 0)).To(BeFalse())
+	})
+
+	It("should match changed lines and files", func() {
+		diff := parseDiff(output, "/repo")
+		diff.Files["/repo/untracked.go"] = nil
+
+		Expect(diff.Contains("/repo/service/user.go", 12)).To(BeTrue())
+		Expect(diff.Contains("/repo/service/user.go", 20)).To(BeFalse())
+		Expect(diff.Contains("/repo/service/user.go", 0)).To(BeTrue())
+		Expect(diff.Contains("/repo/untracked.go", 100)).To(BeTrue())
+		Expect(diff.Contains("/repo/other.go", 0)).To(BeFalse())
+	})
+})
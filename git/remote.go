@@ -0,0 +1,66 @@
+package git
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/capabilities"
+)
+
+// RepositoryOf returns the top level directory of the repository containing dir and its name,
+// which identifies the repository independently of where it is cloned, e.g.
+// github.com/flanksource/arch-unit. Repositories without an origin remote are named after their
+// directory.
+func RepositoryOf(dir string) (string, string, error) {
+	if err := capabilities.Require("git"); err != nil {
+		return "", "", err
+	}
+	root, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", "", err
+	}
+	root = strings.TrimSpace(root)
+	if origin, err := gitOutput(root, "remote", "get-url", "origin"); err == nil {
+		if name := RepositoryName(strings.TrimSpace(origin)); name != "" {
+			return root, name, nil
+		}
+	}
+	return root, filepath.Base(root), nil
+}
+
+// Ancestors returns up to depth commits following the first parents of HEAD, starting with HEAD
+func Ancestors(dir string, depth int) ([]string, error) {
+	if err := capabilities.Require("git"); err != nil {
+		return nil, err
+	}
+	output, err := gitOutput(dir, "rev-list", "--first-parent", "--max-count="+strconv.Itoa(depth), "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// RepositoryName returns the host and path of a remote URL without credentials and the .git
+// suffix, so that SSH and HTTPS clones of a repository share a name, e.g.
+// git@github.com:flanksource/arch-unit.git becomes github.com/flanksource/arch-unit
+func RepositoryName(remote string) string {
+	name := remote
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	} else if colon := strings.Index(name, ":"); colon > 0 && !strings.Contains(name[:colon], "/") {
+		// scp-like syntax, user@host:path
+		name = name[:colon] + "/" + name[colon+1:]
+	}
+	if at := strings.LastIndex(name, "@"); at >= 0 && at < strings.Index(name+"/", "/") {
+		name = name[at+1:]
+	}
+	if slash := strings.Index(name, "/"); slash >= 0 {
+		// Ports of the host are not part of the repository
+		if colon := strings.Index(name[:slash], ":"); colon >= 0 {
+			name = name[:colon] + name[slash:]
+		}
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+	return strings.Trim(name, "/")
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/flanksource/arch-unit/internal/offline"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -63,6 +64,11 @@ func (r *DefaultGitRepository) Fetch(ctx context.Context) error {
 		return nil
 	}
 
+	// Offline, work with whatever has already been fetched
+	if offline.Enabled() {
+		return nil
+	}
+
 	err := r.gitRepo.Fetch(&git.FetchOptions{
 		RemoteName: "origin",
 		RefSpecs:   []config.RefSpec{"refs/*:refs/*"},
@@ -426,6 +432,10 @@ func (r *DefaultGitRepository) ensureCloned() error {
 		}
 	}
 
+	if err := offline.Check(fmt.Sprintf("cloning %s (no cached clone at %s)", r.gitURL, r.repoPath)); err != nil {
+		return err
+	}
+
 	// Ensure parent directory exists
 	if err := os.MkdirAll(filepath.Dir(r.repoPath), 0755); err != nil {
 		return fmt.Errorf("failed to create repository directory: %w", err)
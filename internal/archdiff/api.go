@@ -0,0 +1,179 @@
+package archdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultAPIManifest is the manifest written by arch-unit api when no path is given
+const DefaultAPIManifest = "api.json"
+
+// APIManifest is the public API surface of a module: its exported types, functions, methods,
+// fields and variables, committed so that releases can be checked for breaking changes
+type APIManifest struct {
+	Version int         `json:"version"`
+	Symbols []APISymbol `json:"symbols"`
+}
+
+// APISymbol is an exported symbol of an API manifest
+type APISymbol struct {
+	Package   string `json:"package" pretty:"label=Package"` // Directory relative to the root of the module
+	Name      string `json:"name" pretty:"label=Symbol,style=text-blue-600"`
+	Kind      string `json:"kind" pretty:"label=Kind"`
+	Signature string `json:"signature,omitempty" pretty:"label=Signature"`
+	File      string `json:"file" pretty:"label=File"`
+}
+
+func (s APISymbol) key() string {
+	return s.Package + ":" + s.Name
+}
+
+// API returns the public symbols of the snapshot. Symbols of internal packages and test files,
+// and members of types that are not public, are not part of the API.
+func (s *Snapshot) API() *APIManifest {
+	manifest := &APIManifest{Version: 1, Symbols: []APISymbol{}}
+	for _, key := range sortedKeys(s.Symbols) {
+		symbol := s.Symbols[key]
+		dir := strings.SplitN(key, ":", 2)[0]
+		if !symbol.Public || strings.HasSuffix(symbol.File, "_test.go") || isInternal(dir) {
+			continue
+		}
+		if i := strings.LastIndex(symbol.Name, "."); i > 0 {
+			if parent, ok := s.Symbols[dir+":"+symbol.Name[:i]]; ok && !parent.Public {
+				continue
+			}
+		}
+		manifest.Symbols = append(manifest.Symbols, APISymbol{
+			Package:   dir,
+			Name:      symbol.Name,
+			Kind:      string(symbol.Kind),
+			Signature: symbol.Types,
+			File:      symbol.File,
+		})
+	}
+	return manifest
+}
+
+// isInternal returns true for the directories of Go internal packages, which other modules cannot
+// import
+func isInternal(dir string) bool {
+	for _, segment := range strings.Split(path.Clean(dir), "/") {
+		if segment == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadAPIManifest reads a manifest written by Save
+func LoadAPIManifest(path string) (*APIManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API manifest: %w", err)
+	}
+	var manifest APIManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse API manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Save writes the manifest as indented JSON
+func (m *APIManifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write API manifest: %w", err)
+	}
+	return nil
+}
+
+// CompareAPI returns the symbols of head added to, removed from or changed since base, sorted by
+// symbol. Symbols moved between the files of a package are unchanged.
+func CompareAPI(base, head *APIManifest) []APIChange {
+	before := make(map[string]APISymbol, len(base.Symbols))
+	for _, symbol := range base.Symbols {
+		before[symbol.key()] = symbol
+	}
+	after := make(map[string]APISymbol, len(head.Symbols))
+	for _, symbol := range head.Symbols {
+		after[symbol.key()] = symbol
+	}
+
+	var changes []APIChange
+	for key, b := range before {
+		a, ok := after[key]
+		switch {
+		case !ok:
+			changes = append(changes, APIChange{Symbol: b.Name, Change: Removed, Before: b.describe()})
+		case a.Kind != b.Kind || a.Signature != b.Signature:
+			changes = append(changes, APIChange{Symbol: b.Name, Change: Changed, Before: b.describe(), After: a.describe()})
+		}
+	}
+	for key, a := range after {
+		if _, ok := before[key]; !ok {
+			changes = append(changes, APIChange{Symbol: a.Name, Change: Added, After: a.describe()})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Symbol != changes[j].Symbol {
+			return changes[i].Symbol < changes[j].Symbol
+		}
+		return changes[i].Change < changes[j].Change
+	})
+	return changes
+}
+
+// describe renders the signature of a symbol, or its kind when it has none
+func (s APISymbol) describe() string {
+	if s.Signature == "" || s.Signature == s.Kind {
+		return s.Kind
+	}
+	return s.Signature
+}
+
+// Breaking returns true for changes that can break callers: removed symbols and changed
+// signatures
+func (c APIChange) Breaking() bool {
+	return c.Change == Removed || c.Change == Changed
+}
+
+// APIMarkdown renders the changes of the public API between two revisions, breaking changes first
+func APIMarkdown(base, head string, changes []APIChange) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## API diff `%s`..`%s`\n\n", base, head))
+	if len(changes) == 0 {
+		sb.WriteString("No API changes.\n")
+		return sb.String()
+	}
+
+	var breaking, compatible []APIChange
+	for _, c := range changes {
+		if c.Breaking() {
+			breaking = append(breaking, c)
+		} else {
+			compatible = append(compatible, c)
+		}
+	}
+	for _, section := range []struct {
+		title   string
+		changes []APIChange
+	}{{"Breaking Changes", breaking}, {"Additions", compatible}} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("### %s (%d)\n\n| | Symbol | Before | After |\n|-|--------|--------|-------|\n", section.title, len(section.changes)))
+		for _, c := range section.changes {
+			mark := map[string]string{Added: "+", Removed: "-", Changed: "~"}[c.Change]
+			sb.WriteString(fmt.Sprintf("| %s | `%s` | %s | %s |\n", mark, escape(c.Symbol), code(c.Before), code(c.After)))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
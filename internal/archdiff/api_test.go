@@ -0,0 +1,76 @@
+package archdiff_test
+
+import (
+	"path/filepath"
+
+	"github.com/flanksource/arch-unit/internal/archdiff"
+	"github.com/flanksource/arch-unit/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("API", func() {
+	It("extracts the public symbols", func() {
+		snapshot := &archdiff.Snapshot{Symbols: map[string]*archdiff.Symbol{
+			"client:client.Client":        {Name: "client.Client", File: "client/client.go", Public: true, Kind: models.NodeTypeType, Types: "type"},
+			"client:client.Client.Get":    {Name: "client.Client.Get", File: "client/client.go", Public: true, Kind: models.NodeTypeMethod, Types: "(string) error", Method: true},
+			"client:client.Client.retry":  {Name: "client.Client.retry", File: "client/client.go", Kind: models.NodeTypeMethod, Types: "()", Method: true},
+			"client:client.pool":          {Name: "client.pool", File: "client/pool.go", Kind: models.NodeTypeType, Types: "type"},
+			"client:client.pool.Acquire":  {Name: "client.pool.Acquire", File: "client/pool.go", Public: true, Kind: models.NodeTypeMethod, Types: "()", Method: true},
+			"client:client.TestGet":       {Name: "client.TestGet", File: "client/client_test.go", Public: true, Kind: models.NodeTypeMethod, Types: "(*T)", Method: true},
+			"internal/wire:wire.Encode":   {Name: "wire.Encode", File: "internal/wire/wire.go", Public: true, Kind: models.NodeTypeMethod, Types: "(any) []byte", Method: true},
+			"client/internal:x.Exported":  {Name: "x.Exported", File: "client/internal/x.go", Public: true, Kind: models.NodeTypeMethod, Types: "()", Method: true},
+			"client/internalx:y.Exported": {Name: "y.Exported", File: "client/internalx/y.go", Public: true, Kind: models.NodeTypeMethod, Types: "()", Method: true},
+		}}
+
+		Expect(snapshot.API().Symbols).To(Equal([]archdiff.APISymbol{
+			{Package: "client/internalx", Name: "y.Exported", Kind: "method", Signature: "()", File: "client/internalx/y.go"},
+			{Package: "client", Name: "client.Client", Kind: "type", Signature: "type", File: "client/client.go"},
+			{Package: "client", Name: "client.Client.Get", Kind: "method", Signature: "(string) error", File: "client/client.go"},
+		}))
+	})
+
+	It("reports added, removed and changed symbols", func() {
+		base := &archdiff.APIManifest{Version: 1, Symbols: []archdiff.APISymbol{
+			{Package: "client", Name: "client.Client", Kind: "type", Signature: "type", File: "client/client.go"},
+			{Package: "client", Name: "client.Client.Get", Kind: "method", Signature: "(string) error", File: "client/client.go"},
+			{Package: "client", Name: "client.Client.Delete", Kind: "method", Signature: "(string) error", File: "client/client.go"},
+			{Package: "client", Name: "client.Timeout", Kind: "variable", Signature: "time.Duration", File: "client/client.go"},
+		}}
+		head := &archdiff.APIManifest{Version: 1, Symbols: []archdiff.APISymbol{
+			// Moved to another file of the package
+			{Package: "client", Name: "client.Client", Kind: "type", Signature: "type", File: "client/types.go"},
+			{Package: "client", Name: "client.Client.Get", Kind: "method", Signature: "(context.Context, string) error", File: "client/client.go"},
+			{Package: "client", Name: "client.Client.List", Kind: "method", Signature: "() []string", File: "client/client.go"},
+			{Package: "client", Name: "client.Timeout", Kind: "variable", Signature: "time.Duration", File: "client/client.go"},
+		}}
+
+		changes := archdiff.CompareAPI(base, head)
+		Expect(changes).To(Equal([]archdiff.APIChange{
+			{Symbol: "client.Client.Delete", Change: archdiff.Removed, Before: "(string) error"},
+			{Symbol: "client.Client.Get", Change: archdiff.Changed, Before: "(string) error", After: "(context.Context, string) error"},
+			{Symbol: "client.Client.List", Change: archdiff.Added, After: "() []string"},
+		}))
+		Expect(changes[0].Breaking()).To(BeTrue())
+		Expect(changes[1].Breaking()).To(BeTrue())
+		Expect(changes[2].Breaking()).To(BeFalse())
+
+		markdown := archdiff.APIMarkdown("v1.0.0", "HEAD", changes)
+		Expect(markdown).To(ContainSubstring("### Breaking Changes (2)"))
+		Expect(markdown).To(ContainSubstring("| ~ | `client.Client.Get` | `(string) error` | `(context.Context, string) error` |"))
+		Expect(markdown).To(ContainSubstring("### Additions (1)"))
+		Expect(archdiff.APIMarkdown("v1.0.0", "HEAD", nil)).To(ContainSubstring("No API changes."))
+	})
+
+	It("round-trips manifests", func() {
+		path := filepath.Join(GinkgoT().TempDir(), archdiff.DefaultAPIManifest)
+		manifest := &archdiff.APIManifest{Version: 1, Symbols: []archdiff.APISymbol{
+			{Package: "client", Name: "client.Client", Kind: "type", Signature: "type", File: "client/client.go"},
+		}}
+		Expect(manifest.Save(path)).To(Succeed())
+
+		loaded, err := archdiff.LoadAPIManifest(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(manifest))
+	})
+})
@@ -0,0 +1,143 @@
+package archdiff
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/query"
+)
+
+// DefaultComplexityThreshold is the cyclomatic complexity above which a more complex function is
+// reported as a regression
+const DefaultComplexityThreshold = 10
+
+// Snapshot is the architecture of one revision: the dependencies between its packages, its
+// symbols and its violations
+type Snapshot struct {
+	Label        string
+	Root         string
+	Dependencies []*models.DependencyEdge
+	Symbols      map[string]*Symbol
+	Violations   []models.Violation
+}
+
+// Symbol is a type, method, field or variable of a snapshot
+type Symbol struct {
+	Name       string
+	File       string // Relative to the root of the snapshot
+	Line       int
+	Public     bool
+	Signature  string
+	Complexity int
+	Method     bool
+	Kind       models.NodeType
+	Types      string // Signature without the names of parameters and results, which callers do not depend on
+}
+
+// Capture reads the snapshot of the files under root from the AST cache, with the violations
+// reported for them
+func Capture(astCache *cache.ASTCache, label, root string, violations []models.Violation) (*Snapshot, error) {
+	prefix := strings.TrimSuffix(root, string(filepath.Separator)) + string(filepath.Separator)
+	report, err := query.NewAQLEngine(astCache).Architecture(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarise the architecture of %s: %w", label, err)
+	}
+
+	var nodes []*models.ASTNode
+	if err := astCache.GetReadQuery().Where("file_path LIKE ?", prefix+"%").Find(&nodes).Error; err != nil {
+		return nil, fmt.Errorf("failed to read the symbols of %s: %w", label, err)
+	}
+
+	snapshot := &Snapshot{
+		Label:        label,
+		Root:         root,
+		Dependencies: report.Dependencies,
+		Symbols:      make(map[string]*Symbol, len(nodes)),
+		Violations:   violations,
+	}
+	for _, node := range nodes {
+		kind := baseKind(node.NodeType)
+		if kind == models.NodeTypePackage || kind == models.NodeTypeDependency {
+			continue
+		}
+		file, err := filepath.Rel(root, node.FilePath)
+		if err != nil {
+			file = node.FilePath
+		}
+		// Symbols are keyed by directory so that moving them between the files of a package is
+		// not an API change, while packages of the same name in different directories stay apart
+		key := filepath.ToSlash(filepath.Dir(file)) + ":" + node.String()
+		snapshot.Symbols[key] = &Symbol{
+			Name:       node.String(),
+			File:       filepath.ToSlash(file),
+			Line:       node.StartLine,
+			Public:     !node.IsPrivate,
+			Signature:  signature(node),
+			Complexity: node.CyclomaticComplexity,
+			Method:     kind == models.NodeTypeMethod,
+			Kind:       kind,
+			Types:      typesSignature(node),
+		}
+	}
+	return snapshot, nil
+}
+
+// baseKind maps sub-types such as method_http_get to their kind
+func baseKind(nodeType models.NodeType) models.NodeType {
+	for _, kind := range []models.NodeType{models.NodeTypeType, models.NodeTypeMethod, models.NodeTypeField} {
+		if strings.HasPrefix(string(nodeType), string(kind)+"_") {
+			return kind
+		}
+	}
+	return nodeType
+}
+
+// signature renders the parameters and results of a method, or the type of a field
+func signature(node *models.ASTNode) string {
+	switch baseKind(node.NodeType) {
+	case models.NodeTypeMethod:
+		return methodSignature(node, true)
+	case models.NodeTypeField, models.NodeTypeVariable:
+		if node.FieldType != nil {
+			return *node.FieldType
+		}
+	}
+	return string(node.NodeType)
+}
+
+// typesSignature renders a signature without the names of the parameters and results of methods
+func typesSignature(node *models.ASTNode) string {
+	if baseKind(node.NodeType) == models.NodeTypeMethod {
+		return methodSignature(node, false)
+	}
+	return signature(node)
+}
+
+func methodSignature(node *models.ASTNode, names bool) string {
+	variable := func(name, typ string) string {
+		if !names {
+			return typ
+		}
+		return strings.TrimSpace(name + " " + typ)
+	}
+	params := make([]string, 0, len(node.Parameters))
+	for _, p := range node.Parameters {
+		params = append(params, variable(p.Name, p.Type))
+	}
+	results := make([]string, 0, len(node.ReturnValues))
+	for _, r := range node.ReturnValues {
+		results = append(results, variable(r.Name, r.Type))
+	}
+	sig := "(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		sig += " " + results[0]
+	default:
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
@@ -0,0 +1,13 @@
+package archdiff_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestArchDiff(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Architecture Diff Suite")
+}
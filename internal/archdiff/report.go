@@ -0,0 +1,205 @@
+package archdiff
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Change kinds of public symbols
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Report holds the differences between the architecture of two revisions
+type Report struct {
+	Base                  string
+	Head                  string
+	AddedDependencies     []*models.DependencyEdge
+	RemovedDependencies   []*models.DependencyEdge
+	NewViolations         []models.Violation
+	FixedViolations       int
+	ComplexityRegressions []ComplexityChange
+	APIChanges            []APIChange
+}
+
+// ComplexityChange is a function whose complexity grew above the threshold, Before is 0 for new
+// functions
+type ComplexityChange struct {
+	Symbol string
+	File   string
+	Line   int
+	Before int
+	After  int
+}
+
+// APIChange is a public symbol added, removed, or whose signature changed
+type APIChange struct {
+	Symbol string
+	Change string
+	Before string
+	After  string
+}
+
+// Compare reports the dependencies, violations, complexity and public API of head that differ
+// from base. Violations are matched by their baseline fingerprint, so violations moved by edits
+// are not new.
+func Compare(base, head *Snapshot, complexityThreshold int) *Report {
+	report := &Report{Base: base.Label, Head: head.Label}
+
+	report.AddedDependencies = subtractEdges(head.Dependencies, base.Dependencies)
+	report.RemovedDependencies = subtractEdges(base.Dependencies, head.Dependencies)
+
+	matcher := models.NewBaseline(base.Violations, base.Root).Matcher(head.Root)
+	for _, v := range head.Violations {
+		if !matcher.Matches(v) {
+			report.NewViolations = append(report.NewViolations, v)
+		}
+	}
+	report.FixedViolations = matcher.Fixed()
+
+	for _, key := range sortedKeys(head.Symbols) {
+		after := head.Symbols[key]
+		before, existed := base.Symbols[key]
+		if after.Method && after.Complexity > complexityThreshold && (!existed || after.Complexity > before.Complexity) {
+			change := ComplexityChange{Symbol: after.Name, File: after.File, Line: after.Line, After: after.Complexity}
+			if existed {
+				change.Before = before.Complexity
+			}
+			report.ComplexityRegressions = append(report.ComplexityRegressions, change)
+		}
+
+		switch {
+		case !after.Public:
+		case !existed || !before.Public:
+			report.APIChanges = append(report.APIChanges, APIChange{Symbol: after.Name, Change: Added, After: after.Signature})
+		case before.Signature != after.Signature:
+			report.APIChanges = append(report.APIChanges, APIChange{Symbol: after.Name, Change: Changed, Before: before.Signature, After: after.Signature})
+		}
+	}
+	for _, key := range sortedKeys(base.Symbols) {
+		before := base.Symbols[key]
+		if after, ok := head.Symbols[key]; before.Public && (!ok || !after.Public) {
+			report.APIChanges = append(report.APIChanges, APIChange{Symbol: before.Name, Change: Removed, Before: before.Signature})
+		}
+	}
+	sort.SliceStable(report.APIChanges, func(i, j int) bool { return report.APIChanges[i].Symbol < report.APIChanges[j].Symbol })
+	sort.SliceStable(report.ComplexityRegressions, func(i, j int) bool {
+		a, b := report.ComplexityRegressions[i], report.ComplexityRegressions[j]
+		return a.After-a.Before > b.After-b.Before
+	})
+	return report
+}
+
+// Empty returns true if the revisions do not differ
+func (r *Report) Empty() bool {
+	return len(r.AddedDependencies) == 0 && len(r.RemovedDependencies) == 0 && len(r.NewViolations) == 0 &&
+		r.FixedViolations == 0 && len(r.ComplexityRegressions) == 0 && len(r.APIChanges) == 0
+}
+
+// Markdown renders the report for a pull request summary, files relative to root
+func (r *Report) Markdown(root string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## Architecture diff `%s`..`%s`\n\n", r.Base, r.Head))
+	if r.Empty() {
+		sb.WriteString("No architecture changes.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Change | Count |\n|--------|------:|\n")
+	sb.WriteString(fmt.Sprintf("| New dependencies | %d |\n", len(r.AddedDependencies)))
+	sb.WriteString(fmt.Sprintf("| Removed dependencies | %d |\n", len(r.RemovedDependencies)))
+	sb.WriteString(fmt.Sprintf("| New violations | %d |\n", len(r.NewViolations)))
+	sb.WriteString(fmt.Sprintf("| Fixed violations | %d |\n", r.FixedViolations))
+	sb.WriteString(fmt.Sprintf("| Complexity regressions | %d |\n", len(r.ComplexityRegressions)))
+	sb.WriteString(fmt.Sprintf("| Public API changes | %d |\n", len(r.APIChanges)))
+
+	if len(r.AddedDependencies) > 0 || len(r.RemovedDependencies) > 0 {
+		sb.WriteString("\n### Dependencies\n\n| | From | To | References |\n|-|------|----|-----------:|\n")
+		for _, edge := range r.AddedDependencies {
+			sb.WriteString(fmt.Sprintf("| + | `%s` | `%s` | %d |\n", escape(edge.From), escape(edge.To), edge.Count))
+		}
+		for _, edge := range r.RemovedDependencies {
+			sb.WriteString(fmt.Sprintf("| - | `%s` | `%s` | %d |\n", escape(edge.From), escape(edge.To), edge.Count))
+		}
+	}
+
+	if len(r.NewViolations) > 0 {
+		sb.WriteString("\n### New Violations\n\n| File | Line | Rule | Message |\n|------|-----:|------|---------|\n")
+		for _, v := range r.NewViolations {
+			file := v.File
+			if rel, err := filepath.Rel(root, file); err == nil && filepath.IsAbs(file) && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+			rule := v.Source
+			if v.Rule != nil && v.Rule.String() != "" {
+				rule = v.Rule.String()
+			}
+			message := ""
+			if v.Message != nil {
+				message = *v.Message
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %d | `%s` | %s |\n", escape(filepath.ToSlash(file)), v.Line, escape(rule), escape(message)))
+		}
+	}
+
+	if len(r.ComplexityRegressions) > 0 {
+		sb.WriteString("\n### Complexity Regressions\n\n| Function | File | Before | After |\n|----------|------|-------:|------:|\n")
+		for _, c := range r.ComplexityRegressions {
+			before := "new"
+			if c.Before > 0 {
+				before = fmt.Sprintf("%d", c.Before)
+			}
+			sb.WriteString(fmt.Sprintf("| `%s` | %s:%d | %s | %d |\n", escape(c.Symbol), escape(c.File), c.Line, before, c.After))
+		}
+	}
+
+	if len(r.APIChanges) > 0 {
+		sb.WriteString("\n### Public API\n\n| | Symbol | Before | After |\n|-|--------|--------|-------|\n")
+		for _, c := range r.APIChanges {
+			mark := map[string]string{Added: "+", Removed: "-", Changed: "~"}[c.Change]
+			sb.WriteString(fmt.Sprintf("| %s | `%s` | %s | %s |\n", mark, escape(c.Symbol), code(c.Before), code(c.After)))
+		}
+	}
+	return sb.String()
+}
+
+// subtractEdges returns the edges of a that are not in b, by their packages
+func subtractEdges(a, b []*models.DependencyEdge) []*models.DependencyEdge {
+	existing := make(map[[2]string]bool, len(b))
+	for _, edge := range b {
+		existing[[2]string{edge.From, edge.To}] = true
+	}
+	var edges []*models.DependencyEdge
+	for _, edge := range a {
+		if !existing[[2]string{edge.From, edge.To}] {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func sortedKeys(symbols map[string]*Symbol) []string {
+	keys := make([]string, 0, len(symbols))
+	for key := range symbols {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+}
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + escape(s) + "`"
+}
@@ -0,0 +1,92 @@
+package archdiff_test
+
+import (
+	"github.com/flanksource/arch-unit/internal/archdiff"
+	"github.com/flanksource/arch-unit/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compare", func() {
+	violation := func(root, file string, line int, message string) models.Violation {
+		return models.Violation{File: root + "/" + file, Line: line, Source: "aql", Message: models.StringPtr(message)}
+	}
+
+	base := &archdiff.Snapshot{
+		Label: "main",
+		Root:  "/tmp/base",
+		Dependencies: []*models.DependencyEdge{
+			{From: "api", To: "service", Count: 3},
+			{From: "service", To: "legacy", Count: 1},
+		},
+		Symbols: map[string]*archdiff.Symbol{
+			"service:service.Save":   {Name: "service.Save", File: "service/save.go", Public: true, Signature: "(id string) error", Complexity: 8, Method: true},
+			"service:service.Load":   {Name: "service.Load", File: "service/load.go", Public: true, Signature: "(id string) error", Complexity: 12, Method: true},
+			"service:service.Delete": {Name: "service.Delete", File: "service/delete.go", Public: true, Signature: "(id string) error", Method: true},
+			"service:service.helper": {Name: "service.helper", File: "service/save.go", Signature: "()", Method: true},
+		},
+		Violations: []models.Violation{
+			violation("/tmp/base", "api/handler.go", 10, "api must not call legacy"),
+			violation("/tmp/base", "service/save.go", 20, "service must not call legacy"),
+		},
+	}
+	head := &archdiff.Snapshot{
+		Label: "working tree",
+		Root:  "/src",
+		Dependencies: []*models.DependencyEdge{
+			{From: "api", To: "service", Count: 4},
+			{From: "api", To: "db", Count: 2},
+		},
+		Symbols: map[string]*archdiff.Symbol{
+			"service:service.Save":   {Name: "service.Save", File: "service/save.go", Line: 5, Public: true, Signature: "(ctx Context, id string) error", Complexity: 14, Method: true},
+			"service:service.Load":   {Name: "service.Load", File: "service/load.go", Public: true, Signature: "(id string) error", Complexity: 12, Method: true},
+			"service:service.List":   {Name: "service.List", File: "service/list.go", Public: true, Signature: "() []string", Complexity: 11, Method: true},
+			"service:service.helper": {Name: "service.helper", File: "service/save.go", Signature: "(id string)", Method: true},
+		},
+		Violations: []models.Violation{
+			// Moved by an unrelated edit
+			violation("/src", "api/handler.go", 14, "api must not call legacy"),
+			violation("/src", "api/db.go", 3, "api must not call db"),
+		},
+	}
+
+	It("reports the differences of head", func() {
+		report := archdiff.Compare(base, head, archdiff.DefaultComplexityThreshold)
+
+		Expect(report.AddedDependencies).To(ConsistOf(&models.DependencyEdge{From: "api", To: "db", Count: 2}))
+		Expect(report.RemovedDependencies).To(ConsistOf(&models.DependencyEdge{From: "service", To: "legacy", Count: 1}))
+
+		Expect(report.NewViolations).To(HaveLen(1))
+		Expect(report.NewViolations[0].File).To(Equal("/src/api/db.go"))
+		Expect(report.FixedViolations).To(Equal(1))
+
+		// Load kept its complexity, helper is not public
+		Expect(report.ComplexityRegressions).To(Equal([]archdiff.ComplexityChange{
+			{Symbol: "service.List", File: "service/list.go", After: 11},
+			{Symbol: "service.Save", File: "service/save.go", Line: 5, Before: 8, After: 14},
+		}))
+		Expect(report.APIChanges).To(Equal([]archdiff.APIChange{
+			{Symbol: "service.Delete", Change: archdiff.Removed, Before: "(id string) error"},
+			{Symbol: "service.List", Change: archdiff.Added, After: "() []string"},
+			{Symbol: "service.Save", Change: archdiff.Changed, Before: "(id string) error", After: "(ctx Context, id string) error"},
+		}))
+	})
+
+	It("renders markdown", func() {
+		markdown := archdiff.Compare(base, head, archdiff.DefaultComplexityThreshold).Markdown(head.Root)
+
+		Expect(markdown).To(HavePrefix("## Architecture diff `main`..`working tree`"))
+		Expect(markdown).To(ContainSubstring("| New violations | 1 |"))
+		Expect(markdown).To(ContainSubstring("| + | `api` | `db` | 2 |"))
+		Expect(markdown).To(ContainSubstring("| api/db.go | 3 | `aql` | api must not call db |"))
+		Expect(markdown).To(ContainSubstring("| `service.Save` | service/save.go:5 | 8 | 14 |"))
+		Expect(markdown).To(ContainSubstring("| ~ | `service.Save` | `(id string) error` | `(ctx Context, id string) error` |"))
+	})
+
+	It("reports identical revisions as unchanged", func() {
+		report := archdiff.Compare(base, base, archdiff.DefaultComplexityThreshold)
+
+		Expect(report.Empty()).To(BeTrue())
+		Expect(report.Markdown(base.Root)).To(ContainSubstring("No architecture changes."))
+	})
+})
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -22,28 +21,68 @@ import (
 // ASTCache manages cached AST data and relationships using GORM
 type ASTCache struct {
 	db DBInterface
+
+	stmtsMu sync.Mutex
+	stmts   map[string]*sql.Stmt // statements prepared by QueryPrepared, keyed by query
 }
 
 var (
-	astCacheInstance *ASTCache
-	astCacheMutex    sync.Mutex
-	astCacheOnce     sync.Once
+	// astCacheInstances are the AST caches opened, by cacheKey
+	astCacheInstances = map[string]*ASTCache{}
+	astCacheMutex     sync.Mutex
 )
 
 func NewASTCache() (*ASTCache, error) {
 	return GetASTCache()
 }
 
-// GetASTCache returns the singleton AST cache instance
+// GetASTCache returns the AST cache in CacheDir, opened once per cache directory
 func GetASTCache() (*ASTCache, error) {
-	var err error
-	astCacheOnce.Do(func() {
-		astCacheInstance, err = newASTCache()
+	key, err := cacheKey()
+	if err != nil {
+		return nil, err
+	}
+	return openASTCache(key, newASTCache)
+}
+
+// GetProjectASTCache returns the AST cache of the project at root, opened once per cache
+// directory, so that projects analyzed in one process do not share a cache: the shared PostgreSQL
+// database when configured, else the SQLite file of ProjectCacheDirOf(root)
+func GetProjectASTCache(root string) (*ASTCache, error) {
+	if DSN() != "" {
+		return GetASTCache()
+	}
+	dir, err := ProjectCacheDirOf(root)
+	if err != nil {
+		return nil, err
+	}
+	return openASTCache(dir, func() (*ASTCache, error) {
+		return newASTCacheWithPath(dir)
 	})
+}
+
+// MustGetProjectASTCache returns the AST cache of the project at root or panics
+func MustGetProjectASTCache(root string) *ASTCache {
+	astCache, err := GetProjectASTCache(root)
+	if err != nil {
+		panic(err)
+	}
+	return astCache
+}
+
+// openASTCache returns the AST cache opened for key, opening it when there is none
+func openASTCache(key string, open func() (*ASTCache, error)) (*ASTCache, error) {
+	astCacheMutex.Lock()
+	defer astCacheMutex.Unlock()
+	if astCache, ok := astCacheInstances[key]; ok {
+		return astCache, nil
+	}
+	astCache, err := open()
 	if err != nil {
 		return nil, err
 	}
-	return astCacheInstance, nil
+	astCacheInstances[key] = astCache
+	return astCache, nil
 }
 
 func MustGetASTCache() *ASTCache {
@@ -54,16 +93,15 @@ func MustGetASTCache() *ASTCache {
 	return astCache
 }
 
-// ResetASTCache resets the singleton instance (mainly for testing)
+// ResetASTCache closes the AST caches of every project (mainly for testing)
 func ResetASTCache() {
 	astCacheMutex.Lock()
 	defer astCacheMutex.Unlock()
 
-	if astCacheInstance != nil {
-		_ = astCacheInstance.Close()
-		astCacheInstance = nil
+	for key, astCache := range astCacheInstances {
+		_ = astCache.Close()
+		delete(astCacheInstances, key)
 	}
-	astCacheOnce = sync.Once{}
 }
 
 // ClearAllData removes all data from the AST cache tables
@@ -94,12 +132,19 @@ func (c *ASTCache) ClearAllData() error {
 
 // NewASTCache creates a new AST cache
 func newASTCache() (*ASTCache, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	// A shared PostgreSQL database replaces the SQLite file of the cache directory
+	if dsn := DSN(); dsn != "" {
+		db, err := newPostgresGormDB(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return &ASTCache{db: db}, nil
 	}
 
-	cacheDir := filepath.Join(homeDir, ".cache", "arch-unit")
+	cacheDir, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
 	return newASTCacheWithPath(cacheDir)
 }
 
@@ -144,6 +189,13 @@ func (c *ASTCache) ensureBasicStructure() error {
 
 // Close closes the database connection
 func (c *ASTCache) Close() error {
+	c.stmtsMu.Lock()
+	for _, stmt := range c.stmts {
+		_ = stmt.Close()
+	}
+	c.stmts = nil
+	c.stmtsMu.Unlock()
+
 	sqlDB, err := c.db.DB()
 	if err != nil {
 		return err
@@ -177,6 +229,7 @@ func (c *ASTCache) ClearCache() error {
 func (c *ASTCache) QueryRow(query string, args ...interface{}) *sql.Row {
 	// Get the underlying sql.DB from GORM to maintain compatibility
 	sqlDB, _ := c.db.DB()
+	query, args = c.bind(query, args)
 	return sqlDB.QueryRow(query, args...)
 }
 
@@ -241,6 +294,20 @@ func (c *ASTCache) NeedsReanalysis(filePath string) (bool, error) {
 	return currentHash != metadata.FileHash, nil
 }
 
+// GetExtractionProfile returns the extraction profile recorded for a file.
+// The boolean result is false when the file has not been analyzed yet.
+func (c *ASTCache) GetExtractionProfile(filePath string) (models.ExtractionProfile, bool, error) {
+	var metadata models.FileMetadata
+	err := c.db.Where("file_path = ?", filePath).First(&metadata).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	return models.ParseExtractionProfile(metadata.ExtractionProfile), true, nil
+}
+
 // UpdateFileMetadata updates or inserts file metadata
 func (c *ASTCache) UpdateFileMetadata(filePath string) error {
 	// Check if this is a virtual path (SQL connection, OpenAPI URL, etc.)
@@ -269,9 +336,10 @@ func (c *ASTCache) UpdateFileMetadata(filePath string) error {
 	}
 
 	// Use Clauses to handle upsert (INSERT ... ON CONFLICT DO UPDATE)
+	// A full analysis resets any partial extraction profile
 	if err := c.db.GetWriteDB().Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "file_path"}},
-		DoUpdates: clause.AssignmentColumns([]string{"file_hash", "file_size", "last_modified", "last_analyzed", "analysis_version"}),
+		DoUpdates: clause.AssignmentColumns([]string{"file_hash", "file_size", "last_modified", "last_analyzed", "analysis_version", "extraction_profile"}),
 	}).Create(metadata).Error; err != nil {
 		return fmt.Errorf("failed to update file metadata: %w", err)
 	}
@@ -410,6 +478,28 @@ func (c *ASTCache) GetASTRelationships(astID int64, relType string) ([]*models.A
 	return relationships, nil
 }
 
+// GetASTRelationshipsOfTypes retrieves the relationships of any of the given types for an AST
+// node in a single query, ordered by line
+func (c *ASTCache) GetASTRelationshipsOfTypes(astID int64, relTypes ...string) ([]*models.ASTRelationship, error) {
+	var relationships []*models.ASTRelationship
+
+	if err := c.db.Where("from_ast_id = ? AND relationship_type IN ?", astID, relTypes).Order("line_no").Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to get AST relationships: %w", err)
+	}
+
+	return relationships, nil
+}
+
+// DeleteASTRelationships removes the relationships of a type originating from a node
+func (c *ASTCache) DeleteASTRelationships(fromID int64, relType string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("from_ast_id = ? AND relationship_type = ?", fromID, relType).Delete(&models.ASTRelationship{}).Error; err != nil {
+			return fmt.Errorf("failed to delete AST relationships: %w", err)
+		}
+		return nil
+	})
+}
+
 // StoreLibraryNode stores a library node and returns its ID
 func (c *ASTCache) StoreLibraryNode(pkg, class, method, field, nodeType, language, framework string) (int64, error) {
 	// Check for nil cache or database connection
@@ -534,9 +624,48 @@ func (c *ASTCache) DeleteASTForFile(filePath string) error {
 func (c *ASTCache) QueryRaw(query string, args ...interface{}) (*sql.Rows, error) {
 	// Get the underlying sql.DB from GORM to maintain compatibility
 	sqlDB, _ := c.db.DB()
+	query, args = c.bind(query, args)
 	return sqlDB.Query(query, args...)
 }
 
+// bind converts the ? placeholders of a raw query and its arguments for the database of the cache,
+// letting the GORM dialector of PostgreSQL number them
+func (c *ASTCache) bind(query string, args []interface{}) (string, []interface{}) {
+	db := c.db.GetReadDB()
+	if !isPostgres(db) {
+		return query, args
+	}
+	stmt := db.Session(&gorm.Session{DryRun: true}).Raw(query, args...).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+// QueryPrepared executes a raw SQL query like QueryRaw, preparing it once and reusing the
+// statement on later calls. The query must be parameterized, as each distinct query text keeps
+// a statement open until the cache is closed.
+func (c *ASTCache) QueryPrepared(query string, args ...interface{}) (*sql.Rows, error) {
+	bound, args := c.bind(query, args)
+	c.stmtsMu.Lock()
+	stmt, ok := c.stmts[query]
+	if !ok {
+		sqlDB, err := c.db.DB()
+		if err != nil {
+			c.stmtsMu.Unlock()
+			return nil, err
+		}
+		if stmt, err = sqlDB.Prepare(bound); err != nil {
+			c.stmtsMu.Unlock()
+			return nil, fmt.Errorf("failed to prepare query: %w", err)
+		}
+		if c.stmts == nil {
+			c.stmts = make(map[string]*sql.Stmt)
+		}
+		c.stmts[query] = stmt
+	}
+	c.stmtsMu.Unlock()
+
+	return stmt.Query(args...)
+}
+
 // GetDB returns the underlying GORM database instance
 // Deprecated: Use GetReadQuery() for read operations or GetWriteQuery() for write operations
 func (c *ASTCache) GetDB() *gorm.DB {
@@ -662,6 +791,12 @@ func (c *ASTCache) CountExternalCalls(nodeID int64) (int, error) {
 // StoreFileResults stores all analysis results for a file using an update-first approach
 // This preserves node IDs across re-analysis cycles and only cleans up orphaned nodes at the end
 func (c *ASTCache) StoreFileResults(file string, result interface{}) error {
+	return c.StoreFileResultsWithProfile(file, result, nil)
+}
+
+// StoreFileResultsWithProfile stores analysis results for a file and records the extraction
+// profile used, so later runs with a wider profile know the file needs to be backfilled
+func (c *ASTCache) StoreFileResultsWithProfile(file string, result interface{}, profile models.ExtractionProfile) error {
 	// Import cycle prevention - accept interface{} and type assert
 	type astResult struct {
 		Nodes         []*models.ASTNode
@@ -698,6 +833,8 @@ func (c *ASTCache) StoreFileResults(file string, result interface{}) error {
 		// Phase 1: Setup tracking for existing nodes
 		validNodeIDs := make(map[int64]bool)
 		nodeIDMap := make(map[int64]int64) // Map analysis IDs to database IDs
+		// Map nodes to database IDs for relationships that reference them by FromAST/ToAST
+		nodePtrMap := make(map[*models.ASTNode]int64)
 
 		// Phase 2: Update-first processing of nodes
 		for _, newNode := range r.Nodes {
@@ -719,6 +856,7 @@ func (c *ASTCache) StoreFileResults(file string, result interface{}) error {
 				// Track that this node is still valid
 				validNodeIDs[existing.ID] = true
 				nodeIDMap[analysisID] = existing.ID
+				nodePtrMap[newNode] = existing.ID
 			} else {
 				// Create new node
 				if err := tx.Create(newNode).Error; err != nil {
@@ -728,6 +866,7 @@ func (c *ASTCache) StoreFileResults(file string, result interface{}) error {
 				// Track the new node
 				validNodeIDs[newNode.ID] = true
 				nodeIDMap[analysisID] = newNode.ID
+				nodePtrMap[newNode] = newNode.ID
 			}
 		}
 
@@ -753,8 +892,15 @@ func (c *ASTCache) StoreFileResults(file string, result interface{}) error {
 		// Phase 4: Store new relationships with proper ID mapping
 		for _, rel := range r.Relationships {
 			fromID := nodeIDMap[rel.FromASTID]
+			if rel.FromAST != nil {
+				fromID = nodePtrMap[rel.FromAST]
+			}
 			var toID *int64
-			if rel.ToASTID != nil && *rel.ToASTID > 0 {
+			if rel.ToAST != nil {
+				if dbID := nodePtrMap[rel.ToAST]; dbID > 0 {
+					toID = &dbID
+				}
+			} else if rel.ToASTID != nil && *rel.ToASTID > 0 {
 				dbID := nodeIDMap[*rel.ToASTID]
 				if dbID > 0 {
 					toID = &dbID
@@ -831,18 +977,19 @@ func (c *ASTCache) StoreFileResults(file string, result interface{}) error {
 		}
 
 		fileMetadata := &models.FileMetadata{
-			FilePath:        file,
-			FileHash:        fileHash,
-			FileSize:        fileInfo.Size(),
-			LastModified:    fileInfo.ModTime(),
-			LastAnalyzed:    time.Now(),
-			AnalysisVersion: "1.0",
+			FilePath:          file,
+			FileHash:          fileHash,
+			FileSize:          fileInfo.Size(),
+			LastModified:      fileInfo.ModTime(),
+			LastAnalyzed:      time.Now(),
+			AnalysisVersion:   "1.0",
+			ExtractionProfile: profile.String(),
 		}
 
 		// Use proper upsert with conflict resolution
 		if err := tx.Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "file_path"}},
-			DoUpdates: clause.AssignmentColumns([]string{"file_hash", "file_size", "last_modified", "last_analyzed", "analysis_version"}),
+			DoUpdates: clause.AssignmentColumns([]string{"file_hash", "file_size", "last_modified", "last_analyzed", "analysis_version", "extraction_profile"}),
 		}).Create(fileMetadata).Error; err != nil {
 			return fmt.Errorf("failed to update file metadata: %w", err)
 		}
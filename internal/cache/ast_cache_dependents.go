@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// queryBatchSize is the number of values bound to an IN list per query, well below the variable
+// limit of SQLite, 999 on builds older than 3.32
+const queryBatchSize = 500
+
+// DependentFiles returns the files with nodes calling or referencing the nodes of the given files,
+// other than these files, sorted. The files may no longer exist, e.g. deleted files whose callers
+// need to be checked again.
+func (c *ASTCache) DependentFiles(files []string) ([]string, error) {
+	db := c.db.GetReadDB()
+	var ids []int64
+	for start := 0; start < len(files); start += queryBatchSize {
+		var batch []int64
+		if err := db.Model(&models.ASTNode{}).Where("file_path IN ?", files[start:min(start+queryBatchSize, len(files))]).
+			Pluck("id", &batch).Error; err != nil {
+			return nil, fmt.Errorf("failed to find the nodes of the changed files: %w", err)
+		}
+		ids = append(ids, batch...)
+	}
+
+	fromIDs := make(map[int64]bool)
+	for start := 0; start < len(ids); start += queryBatchSize {
+		var batch []int64
+		if err := db.Model(&models.ASTRelationship{}).Where("to_ast_id IN ?", ids[start:min(start+queryBatchSize, len(ids))]).
+			Distinct().Pluck("from_ast_id", &batch).Error; err != nil {
+			return nil, fmt.Errorf("failed to find the references to the changed files: %w", err)
+		}
+		for _, id := range batch {
+			fromIDs[id] = true
+		}
+	}
+	callers := make([]int64, 0, len(fromIDs))
+	for id := range fromIDs {
+		callers = append(callers, id)
+	}
+
+	excluded := make(map[string]bool, len(files))
+	for _, file := range files {
+		excluded[file] = true
+	}
+	dependents := make(map[string]bool)
+	for start := 0; start < len(callers); start += queryBatchSize {
+		var paths []string
+		if err := db.Model(&models.ASTNode{}).Where("id IN ?", callers[start:min(start+queryBatchSize, len(callers))]).
+			Distinct().Pluck("file_path", &paths).Error; err != nil {
+			return nil, fmt.Errorf("failed to find the files depending on the changed files: %w", err)
+		}
+		for _, path := range paths {
+			if !excluded[path] {
+				dependents[path] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(dependents))
+	for path := range dependents {
+		result = append(result, path)
+	}
+	sort.Strings(result)
+	return result, nil
+}
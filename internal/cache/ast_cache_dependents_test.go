@@ -0,0 +1,61 @@
+package cache_test
+
+import (
+	"fmt"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Dependent files", func() {
+	var astCache *cache.ASTCache
+
+	node := func(path, method string) int64 {
+		id, err := astCache.StoreASTNode(&models.ASTNode{
+			FilePath: path, PackageName: "main", MethodName: method, NodeType: models.NodeTypeMethod,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return id
+	}
+
+	BeforeEach(func() {
+		var err error
+		astCache, err = cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(astCache.Close)
+	})
+
+	It("should find the callers of the nodes of changed and deleted files", func() {
+		changed := node("/repo/store.go", "Save")
+		deleted := node("/repo/legacy.go", "Flush")
+		handler := node("/repo/handler.go", "Create")
+		worker := node("/repo/worker.go", "Run")
+		node("/repo/unrelated.go", "Main")
+
+		Expect(astCache.StoreASTRelationship(handler, &changed, 10, models.RelationshipCall, "store.Save()")).To(Succeed())
+		Expect(astCache.StoreASTRelationship(worker, &deleted, 20, models.RelationshipCall, "legacy.Flush()")).To(Succeed())
+		Expect(astCache.StoreASTRelationship(changed, &deleted, 30, models.RelationshipCall, "legacy.Flush()")).To(Succeed())
+
+		Expect(astCache.DependentFiles([]string{"/repo/store.go", "/repo/legacy.go"})).
+			To(Equal([]string{"/repo/handler.go", "/repo/worker.go"}))
+	})
+
+	It("should batch the nodes of large changes", func() {
+		var called []int64
+		for i := 0; i < 1200; i++ {
+			called = append(called, node("/repo/generated.go", fmt.Sprintf("Method%d", i)))
+		}
+		for i, id := range called {
+			caller := node(filepath.Join("/repo", "callers", fmt.Sprintf("caller%d.go", i%3)), fmt.Sprintf("Call%d", i))
+			Expect(astCache.StoreASTRelationship(caller, &id, i+1, models.RelationshipCall, "")).To(Succeed())
+		}
+
+		Expect(astCache.DependentFiles([]string{"/repo/generated.go"})).To(Equal([]string{
+			"/repo/callers/caller0.go", "/repo/callers/caller1.go", "/repo/callers/caller2.go",
+		}))
+	})
+})
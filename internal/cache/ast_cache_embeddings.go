@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/flanksource/arch-unit/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Embeddings returns the embeddings computed by a model by node ID
+func (c *ASTCache) Embeddings(model string) (map[int64]models.NodeEmbedding, error) {
+	var embeddings []models.NodeEmbedding
+	if err := c.db.GetReadDB().Where("model = ?", model).Find(&embeddings).Error; err != nil {
+		return nil, fmt.Errorf("failed to read embeddings: %w", err)
+	}
+	byNode := make(map[int64]models.NodeEmbedding, len(embeddings))
+	for _, embedding := range embeddings {
+		byNode[embedding.NodeID] = embedding
+	}
+	return byNode, nil
+}
+
+// StoreEmbeddings inserts or replaces the embeddings of nodes
+func (c *ASTCache) StoreEmbeddings(embeddings []models.NodeEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(embeddings, 100).Error; err != nil {
+			return fmt.Errorf("failed to store embeddings: %w", err)
+		}
+		return nil
+	})
+}
+
+// PruneEmbeddings deletes the embeddings of other models and of nodes that no longer exist, the
+// index holds the embeddings of one model at a time
+func (c *ASTCache) PruneEmbeddings(model string) (int64, error) {
+	result := c.db.GetWriteDB().
+		Where("model != ? OR node_id NOT IN (SELECT id FROM ast_nodes)", model).
+		Delete(&models.NodeEmbedding{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune embeddings: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
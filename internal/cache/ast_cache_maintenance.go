@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/models"
+	"gorm.io/gorm"
+)
+
+// LanguageCount is the number of nodes of a language in the AST cache
+type LanguageCount struct {
+	Language string `json:"language"`
+	Files    int64  `json:"files"`
+	Nodes    int64  `json:"nodes"`
+}
+
+// ASTCacheStats summarises the contents of the AST cache
+type ASTCacheStats struct {
+	Files         int64                 `json:"files"`
+	Nodes         int64                 `json:"nodes"`
+	Relationships int64                 `json:"relationships"`
+	Libraries     int64                 `json:"libraries"`
+	Languages     []LanguageCount       `json:"languages"`
+	Oldest        []models.FileMetadata `json:"oldest"`
+}
+
+// Stats counts the files, nodes and relationships of the cache, the nodes per language, and
+// returns the oldest files analyzed, at most limit of them
+func (c *ASTCache) Stats(limit int) (*ASTCacheStats, error) {
+	db := c.db.GetReadDB()
+	stats := &ASTCacheStats{}
+	counts := []struct {
+		model interface{}
+		count *int64
+	}{
+		{&models.FileMetadata{}, &stats.Files},
+		{&models.ASTNode{}, &stats.Nodes},
+		{&models.ASTRelationship{}, &stats.Relationships},
+		{&models.LibraryNode{}, &stats.Libraries},
+	}
+	for _, count := range counts {
+		if err := db.Model(count.model).Count(count.count).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %T: %w", count.model, err)
+		}
+	}
+
+	if err := db.Model(&models.ASTNode{}).
+		Select("COALESCE(language, 'unknown') AS language, COUNT(DISTINCT file_path) AS files, COUNT(*) AS nodes").
+		Group("COALESCE(language, 'unknown')").Order("nodes DESC").
+		Scan(&stats.Languages).Error; err != nil {
+		return nil, fmt.Errorf("failed to count nodes per language: %w", err)
+	}
+
+	if err := db.Order("last_analyzed").Limit(limit).Find(&stats.Oldest).Error; err != nil {
+		return nil, fmt.Errorf("failed to get the oldest files: %w", err)
+	}
+	return stats, nil
+}
+
+// Prune deletes the nodes, relationships and metadata of the files last analyzed before cutoff,
+// returning the number of files deleted. Pruned files are analyzed again when next needed.
+func (c *ASTCache) Prune(cutoff time.Time) (int, error) {
+	var files []string
+	if err := c.db.GetReadDB().Model(&models.FileMetadata{}).
+		Where("last_analyzed < ?", cutoff).Pluck("file_path", &files).Error; err != nil {
+		return 0, fmt.Errorf("failed to find files analyzed before %s: %w", cutoff.Format(time.RFC3339), err)
+	}
+	return len(files), c.deleteFiles(files)
+}
+
+// ClearPath deletes the nodes, relationships and metadata of the file at path, or of the files
+// under it when it is a directory, returning the number of files deleted
+func (c *ASTCache) ClearPath(path string) (int, error) {
+	path = filepath.Clean(path)
+	under := strings.TrimSuffix(path, string(filepath.Separator)) + string(filepath.Separator)
+
+	var files []string
+	if err := c.db.GetReadDB().Raw(
+		// substr rather than LIKE, whose wildcards match the underscores of paths, and available in
+		// SQLite and PostgreSQL
+		"SELECT file_path FROM file_metadata WHERE file_path = ? OR substr(file_path, 1, length(?)) = ? "+
+			"UNION SELECT DISTINCT file_path FROM ast_nodes WHERE file_path = ? OR substr(file_path, 1, length(?)) = ?",
+		path, under, under, path, under, under).Scan(&files).Error; err != nil {
+		return 0, fmt.Errorf("failed to find the files of %s: %w", path, err)
+	}
+	return len(files), c.deleteFiles(files)
+}
+
+// deleteFiles deletes the AST data and metadata of files in a single transaction
+func (c *ASTCache) deleteFiles(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		for _, file := range files {
+			if err := c.deleteFileDataInTx(tx, file); err != nil {
+				return err
+			}
+			if err := tx.Where("file_path = ?", file).Delete(&models.FileMetadata{}).Error; err != nil {
+				return fmt.Errorf("failed to delete metadata of %s: %w", file, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,89 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("AST Cache Maintenance", func() {
+	var (
+		tempDir  string
+		astCache *cache.ASTCache
+	)
+
+	store := func(path, language string, methods ...string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte("package main\n"), 0644)).To(Succeed())
+		result := &struct {
+			Nodes         []*models.ASTNode
+			Relationships []*models.ASTRelationship
+			Libraries     []*models.LibraryRelationship
+		}{}
+		for i, method := range methods {
+			result.Nodes = append(result.Nodes, &models.ASTNode{
+				ID: int64(i + 1), FilePath: path, PackageName: "main", MethodName: method,
+				NodeType: models.NodeTypeMethod, Language: &language, StartLine: i + 1,
+			})
+		}
+		Expect(astCache.StoreFileResults(path, result)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		tempDir = GinkgoT().TempDir()
+		var err error
+		astCache, err = cache.NewASTCacheWithPath(filepath.Join(tempDir, "cache"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(astCache.Close)
+
+		store(filepath.Join(tempDir, "src", "main.go"), "go", "main", "run")
+		store(filepath.Join(tempDir, "src_old", "app.py"), "python", "handler")
+	})
+
+	It("should count the nodes of every language", func() {
+		stats, err := astCache.Stats(10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Files).To(Equal(int64(2)))
+		Expect(stats.Nodes).To(Equal(int64(3)))
+		Expect(stats.Languages).To(Equal([]cache.LanguageCount{
+			{Language: "go", Files: 1, Nodes: 2},
+			{Language: "python", Files: 1, Nodes: 1},
+		}))
+		Expect(stats.Oldest).To(HaveLen(2))
+		Expect(stats.Oldest[0].FilePath).To(HaveSuffix("main.go"))
+	})
+
+	It("should prune the files analyzed before a cutoff", func() {
+		Expect(astCache.GetWriteQuery().Model(&models.FileMetadata{}).
+			Where("file_path = ?", filepath.Join(tempDir, "src_old", "app.py")).
+			Update("last_analyzed", time.Now().Add(-60*24*time.Hour)).Error).To(Succeed())
+
+		pruned, err := astCache.Prune(time.Now().Add(-30 * 24 * time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(Equal(1))
+
+		stats, err := astCache.Stats(10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Files).To(Equal(int64(1)))
+		Expect(stats.Languages).To(ConsistOf(cache.LanguageCount{Language: "go", Files: 1, Nodes: 2}))
+	})
+
+	It("should only clear the files under a directory", func() {
+		cleared, err := astCache.ClearPath(filepath.Join(tempDir, "src") + "/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cleared).To(Equal(1))
+
+		nodes, err := astCache.GetASTNodesByFile(filepath.Join(tempDir, "src_old", "app.py"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes).To(HaveLen(1))
+		nodes, err = astCache.GetASTNodesByFile(filepath.Join(tempDir, "src", "main.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes).To(BeEmpty())
+	})
+})
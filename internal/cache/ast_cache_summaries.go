@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flanksource/arch-unit/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FileHashes returns the hashes of the analyzed files by path
+func (c *ASTCache) FileHashes() (map[string]string, error) {
+	var files []models.FileMetadata
+	if err := c.db.GetReadDB().Select("file_path", "file_hash").Find(&files).Error; err != nil {
+		return nil, fmt.Errorf("failed to read file hashes: %w", err)
+	}
+	hashes := make(map[string]string, len(files))
+	for _, file := range files {
+		hashes[file.FilePath] = file.FileHash
+	}
+	return hashes, nil
+}
+
+// CachedSummary returns the summary cached for a node of a file with the given hash
+func (c *ASTCache) CachedSummary(fileHash, nodeKey string) (string, bool, error) {
+	var summary models.NodeSummary
+	err := c.db.GetReadDB().Where("file_hash = ? AND node_key = ?", fileHash, nodeKey).First(&summary).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read cached summary of %s: %w", nodeKey, err)
+	}
+	return summary.Summary, true, nil
+}
+
+// StoreSummary sets the summary of a node, and caches it by the hash of its file when it has one
+func (c *ASTCache) StoreSummary(node *models.ASTNode, fileHash, summary, model string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ASTNode{}).Where("id = ?", node.ID).Update("summary", summary).Error; err != nil {
+			return fmt.Errorf("failed to store summary of %s: %w", node.String(), err)
+		}
+		if fileHash == "" {
+			return nil
+		}
+		cached := &models.NodeSummary{FileHash: fileHash, NodeKey: node.String(), Summary: summary, Model: model, CreatedAt: time.Now()}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(cached).Error; err != nil {
+			return fmt.Errorf("failed to cache summary of %s: %w", node.String(), err)
+		}
+		return nil
+	})
+}
+
+// SummarizedNodes returns the nodes that have a summary, without their relationships
+func (c *ASTCache) SummarizedNodes() ([]*models.ASTNode, error) {
+	var nodes []*models.ASTNode
+	err := c.db.GetReadDB().
+		Select("id", "file_path", "package_name", "type_name", "method_name", "field_name", "node_type", "start_line", "end_line", "summary").
+		Where("summary IS NOT NULL AND summary != ''").
+		Find(&nodes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summarized nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+// CachedSuggestion returns the remediation suggestion cached for a prompt hash
+func (c *ASTCache) CachedSuggestion(promptHash string) (string, bool, error) {
+	var suggestion models.ViolationSuggestion
+	err := c.db.GetReadDB().Where("prompt_hash = ?", promptHash).First(&suggestion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read cached suggestion: %w", err)
+	}
+	return suggestion.Suggestion, true, nil
+}
+
+// StoreSuggestion caches a remediation suggestion by the hash of its prompt
+func (c *ASTCache) StoreSuggestion(promptHash, suggestion, model string) error {
+	cached := &models.ViolationSuggestion{PromptHash: promptHash, Suggestion: suggestion, Model: model, CreatedAt: time.Now()}
+	if err := c.db.GetWriteDB().Clauses(clause.OnConflict{UpdateAll: true}).Create(cached).Error; err != nil {
+		return fmt.Errorf("failed to cache suggestion: %w", err)
+	}
+	return nil
+}
@@ -9,6 +9,9 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/flanksource/arch-unit/internal/capabilities"
+	"github.com/flanksource/arch-unit/internal/offline"
 )
 
 type GitCache struct {
@@ -82,6 +85,9 @@ func (gc *GitCache) IsCached(repoURL string) bool {
 }
 
 func (gc *GitCache) CloneOrUpdate(repoURL string, ref string) (string, error) {
+	if err := capabilities.Require("git"); err != nil {
+		return "", err
+	}
 	cacheDir := gc.getCacheDir(repoURL)
 
 	if err := os.MkdirAll(gc.baseDir, 0755); err != nil {
@@ -90,10 +96,13 @@ func (gc *GitCache) CloneOrUpdate(repoURL string, ref string) (string, error) {
 
 	gitDir := filepath.Join(cacheDir, ".git")
 	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+		if err := offline.Check("cloning " + repoURL); err != nil {
+			return "", err
+		}
 		if err := gc.clone(repoURL, cacheDir); err != nil {
 			return "", fmt.Errorf("failed to clone repository: %w", err)
 		}
-	} else {
+	} else if !offline.Enabled() {
 		if err := gc.update(cacheDir); err != nil {
 			_ = os.RemoveAll(cacheDir)
 			if err := gc.clone(repoURL, cacheDir); err != nil {
@@ -149,6 +158,9 @@ func (gc *GitCache) checkout(repoDir, ref string) error {
 	cmd.Dir = repoDir
 
 	if _, err := cmd.CombinedOutput(); err != nil {
+		if err := offline.Check("fetching ref " + ref); err != nil {
+			return err
+		}
 		cmd = exec.Command("git", "fetch", "origin", ref)
 		cmd.Dir = repoDir
 		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
@@ -90,6 +90,18 @@ func (d *DualPoolGormDB) DB() (*sql.DB, error) {
 	return d.readDB.DB()
 }
 
+// Close closes both pools
+func (d *DualPoolGormDB) Close() error {
+	for _, db := range []*gorm.DB{d.readDB, d.writeDB} {
+		if sqlDB, err := db.DB(); err == nil && sqlDB != nil {
+			if err := sqlDB.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // getRawDB returns the read database (internal use only)
 func (d *DualPoolGormDB) getRawDB() *gorm.DB {
 	return d.readDB
@@ -365,7 +377,8 @@ type DBInterface interface {
 }
 
 var (
-	dualPoolGormInstance  *DualPoolGormDB
+	// dualPoolGormInstances are the databases of the AST caches opened, by cacheKey
+	dualPoolGormInstances = map[string]*DualPoolGormDB{}
 	protectedGormInstance *ProtectedGormDB
 	gormInstance          *gorm.DB
 	gormOnce              sync.Once
@@ -373,22 +386,28 @@ var (
 	useDualPool           = true // Feature flag to switch between implementations
 )
 
-// GetDualPoolGormDB returns the singleton dual-pool GORM database instance
+// GetDualPoolGormDB returns the dual-pool GORM database of the AST cache in CacheDir, opened once
+// per cache directory
 func GetDualPoolGormDB() (*DualPoolGormDB, error) {
-	var err error
-	gormOnce.Do(func() {
-		homeDir, homeErr := os.UserHomeDir()
-		if homeErr != nil {
-			err = fmt.Errorf("failed to get home directory: %w", homeErr)
-			return
-		}
-		cacheDir := filepath.Join(homeDir, ".cache", "arch-unit")
-		dualPoolGormInstance, err = newDualPoolGormDBWithPath(cacheDir)
-	})
+	key, err := cacheKey()
+	if err != nil {
+		return nil, err
+	}
+	gormMutex.Lock()
+	defer gormMutex.Unlock()
+	if db, ok := dualPoolGormInstances[key]; ok {
+		return db, nil
+	}
+	cacheDir, err := CacheDir()
 	if err != nil {
 		return nil, err
 	}
-	return dualPoolGormInstance, nil
+	db, err := openDualPoolGormDB(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	dualPoolGormInstances[key] = db
+	return db, nil
 }
 
 // MustGetDualPoolGormDB returns dual-pool GORM instance or panics
@@ -453,19 +472,19 @@ func MustGetGormDB() DBInterface {
 	return db
 }
 
-// ResetGormDB resets the singleton instance (mainly for testing)
+// ResetGormDB closes the databases of the AST caches of every project (mainly for testing)
 func ResetGormDB() {
 	gormMutex.Lock()
 	defer gormMutex.Unlock()
 
-	if dualPoolGormInstance != nil {
-		if readSqlDB, err := dualPoolGormInstance.readDB.DB(); err == nil && readSqlDB != nil {
+	for key, db := range dualPoolGormInstances {
+		if readSqlDB, err := db.readDB.DB(); err == nil && readSqlDB != nil {
 			_ = readSqlDB.Close()
 		}
-		if writeSqlDB, err := dualPoolGormInstance.writeDB.DB(); err == nil && writeSqlDB != nil {
+		if writeSqlDB, err := db.writeDB.DB(); err == nil && writeSqlDB != nil {
 			_ = writeSqlDB.Close()
 		}
-		dualPoolGormInstance = nil
+		delete(dualPoolGormInstances, key)
 	}
 
 	if gormInstance != nil {
@@ -481,12 +500,10 @@ func ResetGormDB() {
 
 // newGormDB creates a new GORM database instance
 func newGormDB() (*gorm.DB, error) {
-	homeDir, err := os.UserHomeDir()
+	cacheDir, err := CacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
-
-	cacheDir := filepath.Join(homeDir, ".cache", "arch-unit")
 	return newGormDBWithPath(cacheDir)
 }
 
@@ -499,6 +516,15 @@ func NewGormDBWithPath(cacheDir string) (*ProtectedGormDB, error) {
 	return NewProtectedGormDB(rawDB), nil
 }
 
+// openDualPoolGormDB opens the PostgreSQL database of DSN when one is set, and the SQLite cache in
+// cacheDir otherwise
+func openDualPoolGormDB(cacheDir string) (*DualPoolGormDB, error) {
+	if dsn := DSN(); dsn != "" {
+		return newPostgresGormDB(dsn)
+	}
+	return newDualPoolGormDBWithPath(cacheDir)
+}
+
 // newDualPoolGormDBWithPath creates dual GORM database pools in the specified directory
 func newDualPoolGormDBWithPath(cacheDir string) (*DualPoolGormDB, error) {
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
@@ -506,6 +532,12 @@ func newDualPoolGormDBWithPath(cacheDir string) (*DualPoolGormDB, error) {
 	}
 
 	dbPath := filepath.Join(cacheDir, "ast.db")
+	// The write pool opens the database with mode=rw, which does not create it
+	file, err := os.OpenFile(dbPath, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dbPath, err)
+	}
+	_ = file.Close()
 
 	// Create read-only connection string with file: prefix and SQLite parameters
 	readConnStr := fmt.Sprintf("file:%s?mode=ro&_journal_mode=wal&_busy_timeout=5000&_foreign_keys=on&_synchronous=normal&_cache_size=10000&_temp_store=memory", dbPath)
@@ -518,6 +550,11 @@ func newDualPoolGormDBWithPath(cacheDir string) (*DualPoolGormDB, error) {
 		Logger: logger.Default.LogMode(logger.Silent), // Reduce log noise
 	}
 
+	// The read pool caches prepared statements as the same lookups are repeated for every node
+	// when rules are evaluated. gorm.Open stores state in its config, so copy it before opening.
+	readConfig := *config
+	readConfig.PrepareStmt = true
+
 	// Create write database first (needed for migrations)
 	writeDB, err := gorm.Open(sqlite.Open(writeConnStr), config)
 	if err != nil {
@@ -532,13 +569,15 @@ func newDualPoolGormDBWithPath(cacheDir string) (*DualPoolGormDB, error) {
 	writeSqlDB.SetMaxIdleConns(1)  // Single connection for writes
 	writeSqlDB.SetMaxOpenConns(1)  // SQLite single writer constraint
 
-	// Auto-migrate all models using write database
-	if err := autoMigrateModels(writeDB); err != nil {
-		return nil, fmt.Errorf("failed to auto-migrate models: %w", err)
+	// Auto-migrate all models using write database, unless the cache was written by an
+	// incompatible arch-unit
+	if err := migrateSchema(writeDB, dbPath); err != nil {
+		_ = writeSqlDB.Close()
+		return nil, err
 	}
 
 	// Create read-only database
-	readDB, err := gorm.Open(sqlite.Open(readConnStr), config)
+	readDB, err := gorm.Open(sqlite.Open(readConnStr), &readConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open read database with GORM: %w", err)
 	}
@@ -610,9 +649,10 @@ func newGormDBWithPath(cacheDir string) (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(5)   // Reduced from 10 - fewer idle connections
 	sqlDB.SetMaxOpenConns(20)  // Reduced from 100 - SQLite works better with fewer connections
 
-	// Auto-migrate all models
-	if err := autoMigrateModels(db); err != nil {
-		return nil, fmt.Errorf("failed to auto-migrate models: %w", err)
+	// Auto-migrate all models, unless the cache was written by an incompatible arch-unit
+	if err := migrateSchema(db, dbPath); err != nil {
+		_ = sqlDB.Close()
+		return nil, err
 	}
 
 	return db, nil
@@ -630,6 +670,9 @@ func autoMigrateModels(db *gorm.DB) error {
 		&models.DependencyAlias{},
 		&models.FileScan{},
 		&models.Violation{},
+		&models.NodeSummary{},
+		&models.NodeEmbedding{},
+		&models.ViolationSuggestion{},
 	}
 
 	for _, model := range modelsToMigrate {
@@ -715,6 +758,9 @@ func TestWriteAccess() error {
 	// This updates file_metadata rows with id = -1 (which should match 0 rows)
 	// but tests that we have write permissions on the database
 	if err := db.Exec("UPDATE file_metadata SET last_analyzed = last_analyzed WHERE id = ?", -1); err != nil {
+		if isPostgres(db.GetWriteDB()) {
+			return fmt.Errorf("cannot write to the PostgreSQL cache %s: %w", RedactDSN(DSN()), err)
+		}
 		return formatWriteAccessError(err)
 	}
 
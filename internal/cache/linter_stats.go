@@ -27,8 +27,8 @@ type ExecutionStats struct {
 	SuccessRate    float64
 }
 
-// NewLinterStats creates a new linter statistics tracker
-func NewLinterStats() (*LinterStats, error) {
+// openStatsDB opens the statistics database shared by linter and rule statistics
+func openStatsDB() (*DB, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
@@ -44,6 +44,15 @@ func NewLinterStats() (*LinterStats, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open stats database: %w", err)
 	}
+	return db, nil
+}
+
+// NewLinterStats creates a new linter statistics tracker
+func NewLinterStats() (*LinterStats, error) {
+	db, err := openStatsDB()
+	if err != nil {
+		return nil, err
+	}
 
 	ls := &LinterStats{db: db}
 	if err := ls.initSchema(); err != nil {
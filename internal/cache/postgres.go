@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DSNEnvVar selects a PostgreSQL database for the AST cache, shared by the machines analyzing the
+// same code, instead of the SQLite file of each machine. It takes precedence over SetDSN.
+const DSNEnvVar = "ARCH_UNIT_CACHE_DSN"
+
+// migrationLockKey serializes the schema migrations of arch-unit processes sharing a database
+const migrationLockKey = 0x61726368756e6974 // "archunit"
+
+var (
+	configuredDSN string
+	dsnMutex      sync.RWMutex
+)
+
+// SetDSN sets the PostgreSQL database of the AST cache, usually from the cache section of
+// arch-unit.yaml. It applies to caches opened afterwards.
+func SetDSN(dsn string) {
+	dsnMutex.Lock()
+	defer dsnMutex.Unlock()
+	configuredDSN = dsn
+}
+
+// DSN returns the PostgreSQL database of the AST cache, empty for the SQLite file of the cache
+// directory
+func DSN() string {
+	if dsn := os.Getenv(DSNEnvVar); dsn != "" {
+		return dsn
+	}
+	dsnMutex.RLock()
+	defer dsnMutex.RUnlock()
+	return configuredDSN
+}
+
+var passwordPattern = regexp.MustCompile(`(password\s*=\s*)('[^']*'|\S+)`)
+
+// RedactDSN returns the DSN with its password masked, for messages and logs
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+	return passwordPattern.ReplaceAllString(dsn, "${1}xxxxx")
+}
+
+// newPostgresGormDB opens the AST cache in a PostgreSQL database and migrates its schema. The
+// server handles concurrent readers and writers, so both pools share the same connections.
+func newPostgresGormDB(dsn string) (*DualPoolGormDB, error) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent), // Reduce log noise
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the PostgreSQL cache %s: %w", RedactDSN(dsn), err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	sqlDB.SetMaxIdleConns(5)
+	sqlDB.SetMaxOpenConns(20)
+
+	// Processes starting together, e.g. the jobs of a CI pipeline, migrate one at a time
+	err = db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to lock the cache for migration: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+		return migrateSchema(conn, RedactDSN(dsn))
+	})
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	return NewDualPoolGormDB(db, db), nil
+}
+
+// isPostgres returns true when db is a PostgreSQL database
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// CacheDirEnvVar overrides the directory of the AST cache, like --cache-dir
+const CacheDirEnvVar = "ARCH_UNIT_CACHE_DIR"
+
+var (
+	cacheDirMu  sync.RWMutex
+	cacheDir    string
+	projectRoot string
+)
+
+// BaseDir returns ~/.cache/arch-unit, which holds the caches shared by every project and the
+// AST caches of each project
+func BaseDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".cache", "arch-unit"), nil
+}
+
+// SetCacheDir sets the directory of the AST cache of every project, e.g. that of --cache-dir. An
+// empty dir restores the ProjectCacheDir of each project.
+func SetCacheDir(dir string) {
+	cacheDirMu.Lock()
+	defer cacheDirMu.Unlock()
+	cacheDir = dir
+}
+
+// SetProjectRoot sets the project whose AST cache GetASTCache and GetGormDB open, the project
+// analyzed by the command run
+func SetProjectRoot(root string) {
+	cacheDirMu.Lock()
+	defer cacheDirMu.Unlock()
+	projectRoot = root
+}
+
+// CacheDir returns the directory of the AST cache opened by GetASTCache: that set by SetCacheDir,
+// else the ProjectCacheDir of the project set by SetProjectRoot, else BaseDir
+func CacheDir() (string, error) {
+	cacheDirMu.RLock()
+	dir, root := cacheDir, projectRoot
+	cacheDirMu.RUnlock()
+	if dir != "" {
+		return dir, nil
+	}
+	if root != "" {
+		return ProjectCacheDir(root)
+	}
+	return BaseDir()
+}
+
+// ProjectCacheDirOf returns the directory of the AST cache of the project at root: that set by
+// SetCacheDir, which every project shares, else its ProjectCacheDir
+func ProjectCacheDirOf(root string) (string, error) {
+	cacheDirMu.RLock()
+	dir := cacheDir
+	cacheDirMu.RUnlock()
+	if dir != "" {
+		return dir, nil
+	}
+	return ProjectCacheDir(root)
+}
+
+// unsafeName matches the characters of directory names left out of project cache directories
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ProjectCacheDir returns the directory of the AST cache of the project at root, named after the
+// project and a hash of its absolute path, e.g. ~/.cache/arch-unit/projects/arch-unit-1f2e3d4c5b6a,
+// so that projects do not share, and grow, a single cache. Cached paths are absolute, so clones
+// of a repository in other directories have caches of their own.
+func ProjectCacheDir(root string) (string, error) {
+	base, err := BaseDir()
+	if err != nil {
+		return "", err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	sum := sha256.Sum256([]byte(root))
+	name := unsafeName.ReplaceAllString(filepath.Base(root), "_")
+	return filepath.Join(base, "projects", name+"-"+hex.EncodeToString(sum[:6])), nil
+}
+
+// cacheKey identifies the database of the AST cache opened by the singletons, the DSN of a shared
+// PostgreSQL cache or the directory of the SQLite cache
+func cacheKey() (string, error) {
+	if dsn := DSN(); dsn != "" {
+		return dsn, nil
+	}
+	return CacheDir()
+}
@@ -0,0 +1,102 @@
+package cache_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Project caches", func() {
+	It("should give each project root a cache directory of its own", func() {
+		base, err := cache.BaseDir()
+		Expect(err).NotTo(HaveOccurred())
+
+		api, err := cache.ProjectCacheDir("/src/services/api")
+		Expect(err).NotTo(HaveOccurred())
+		web, err := cache.ProjectCacheDir("/src/services/web")
+		Expect(err).NotTo(HaveOccurred())
+		clone, err := cache.ProjectCacheDir("/tmp/api")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Dir(api)).To(Equal(filepath.Join(base, "projects")))
+		Expect(filepath.Base(api)).To(MatchRegexp(`^api-[0-9a-f]{12}$`))
+		Expect(api).NotTo(Equal(web))
+		Expect(api).NotTo(Equal(clone))
+		Expect(cache.ProjectCacheDir("/src/services/api/")).To(Equal(api))
+	})
+
+	It("should open the cache of the directory set", func() {
+		DeferCleanup(cache.SetCacheDir, "")
+		DeferCleanup(cache.ResetASTCache)
+		first, second := GinkgoT().TempDir(), GinkgoT().TempDir()
+
+		cache.SetCacheDir(first)
+		Expect(cache.CacheDir()).To(Equal(first))
+		firstCache, err := cache.GetASTCache()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(first, "ast.db")).To(BeAnExistingFile())
+
+		cache.SetCacheDir(second)
+		secondCache, err := cache.GetASTCache()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secondCache).NotTo(BeIdenticalTo(firstCache))
+		Expect(filepath.Join(second, "ast.db")).To(BeAnExistingFile())
+
+		cache.SetCacheDir(first)
+		Expect(cache.GetASTCache()).To(BeIdenticalTo(firstCache))
+	})
+
+	It("should keep the caches of two project roots in one process apart", func() {
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+		DeferCleanup(cache.ResetASTCache)
+		api, web := GinkgoT().TempDir(), GinkgoT().TempDir()
+
+		apiCache, err := cache.GetProjectASTCache(api)
+		Expect(err).NotTo(HaveOccurred())
+		webCache, err := cache.GetProjectASTCache(web)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(webCache).NotTo(BeIdenticalTo(apiCache))
+		Expect(cache.GetProjectASTCache(api)).To(BeIdenticalTo(apiCache))
+
+		apiDir, err := cache.ProjectCacheDir(api)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(apiDir, "ast.db")).To(BeAnExistingFile())
+
+		file := filepath.Join(api, "main.go")
+		_, err = apiCache.StoreASTNode(&models.ASTNode{
+			FilePath: file, PackageName: "main", MethodName: "main", NodeType: models.NodeTypeMethod,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apiCache.GetASTNodesByFile(file)).To(HaveLen(1))
+		Expect(webCache.GetASTNodesByFile(file)).To(BeEmpty())
+	})
+
+	It("should share the cache directory set between project roots", func() {
+		DeferCleanup(cache.SetCacheDir, "")
+		DeferCleanup(cache.ResetASTCache)
+		cache.SetCacheDir(GinkgoT().TempDir())
+
+		apiCache, err := cache.GetProjectASTCache(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache.GetProjectASTCache(GinkgoT().TempDir())).To(BeIdenticalTo(apiCache))
+		Expect(cache.GetASTCache()).To(BeIdenticalTo(apiCache))
+	})
+
+	It("should open the cache of the project root set", func() {
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+		DeferCleanup(cache.SetProjectRoot, "")
+		DeferCleanup(cache.ResetASTCache)
+		root := GinkgoT().TempDir()
+
+		projectDir, err := cache.ProjectCacheDir(root)
+		Expect(err).NotTo(HaveOccurred())
+
+		cache.SetProjectRoot(root)
+		Expect(cache.CacheDir()).To(Equal(projectDir))
+		Expect(cache.GetASTCache()).To(BeIdenticalTo(cache.MustGetProjectASTCache(root)))
+	})
+})
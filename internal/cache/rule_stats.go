@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// RuleStats tracks how long AQL rules and their clauses take to evaluate
+type RuleStats struct {
+	db *DB
+}
+
+// RuleEvaluation is the time taken to evaluate a rule, or a single clause of a rule
+type RuleEvaluation struct {
+	Rule       string
+	Clause     string // empty for the rule as a whole
+	Duration   time.Duration
+	Violations int
+}
+
+// RuleTimingStats summarizes the recorded evaluations of a rule or clause
+type RuleTimingStats struct {
+	Rule        string        `json:"rule" pretty:"label=Rule,style=text-blue-500"`
+	Clause      string        `json:"clause,omitempty" pretty:"label=Clause,omitempty"`
+	Runs        int64         `json:"runs" pretty:"label=Runs"`
+	AvgDuration time.Duration `json:"avg_duration" pretty:"label=Average,style=text-orange-600"`
+	MaxDuration time.Duration `json:"max_duration" pretty:"label=Max,style=text-red-600"`
+	Violations  int64         `json:"max_violations" pretty:"label=Max Violations"`
+}
+
+// NewRuleStats creates a new rule statistics tracker
+func NewRuleStats() (*RuleStats, error) {
+	db, err := openStatsDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &RuleStats{db: db}
+	if err := rs.initSchema(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// initSchema creates the necessary tables
+func (rs *RuleStats) initSchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS rule_evaluations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		rule_name TEXT NOT NULL,
+		clause TEXT NOT NULL,
+		work_dir TEXT NOT NULL,
+		evaluated_at DATETIME NOT NULL,
+		duration_us INTEGER NOT NULL,
+		violation_count INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_rule_workdir ON rule_evaluations(work_dir, rule_name, clause);
+	`
+
+	if _, err := rs.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return nil
+}
+
+// RecordEvaluations records the evaluations of a single run of the rules in workDir
+func (rs *RuleStats) RecordEvaluations(workDir string, evaluations []RuleEvaluation) error {
+	tx, err := rs.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, evaluation := range evaluations {
+		_, err = tx.Exec(`
+			INSERT INTO rule_evaluations
+			(rule_name, clause, work_dir, evaluated_at, duration_us, violation_count)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			evaluation.Rule, evaluation.Clause, workDir, now, evaluation.Duration.Microseconds(), evaluation.Violations)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSlowest returns the rules in workDir ordered by their average evaluation time, or their
+// individual clauses when clauses is set
+func (rs *RuleStats) GetSlowest(workDir string, limit int, clauses bool) ([]RuleTimingStats, error) {
+	clauseFilter := "clause = ''"
+	if clauses {
+		clauseFilter = "clause != ''"
+	}
+
+	rows, err := rs.db.Query(`
+		SELECT
+			rule_name,
+			clause,
+			COUNT(*) as runs,
+			AVG(duration_us) as avg_duration,
+			MAX(duration_us) as max_duration,
+			MAX(violation_count) as violations
+		FROM rule_evaluations
+		WHERE work_dir = ? AND `+clauseFilter+`
+		GROUP BY rule_name, clause
+		ORDER BY avg_duration DESC
+		LIMIT ?`,
+		workDir, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var stats []RuleTimingStats
+	for rows.Next() {
+		var s RuleTimingStats
+		var avgUs float64
+		var maxUs int64
+		if err := rows.Scan(&s.Rule, &s.Clause, &s.Runs, &avgUs, &maxUs, &s.Violations); err != nil {
+			return nil, err
+		}
+		s.AvgDuration = time.Duration(int64(avgUs)) * time.Microsecond
+		s.MaxDuration = time.Duration(maxUs) * time.Microsecond
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// Close closes the database connection
+func (rs *RuleStats) Close() error {
+	if rs.db != nil {
+		return rs.db.Close()
+	}
+	return nil
+}
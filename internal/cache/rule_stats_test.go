@@ -0,0 +1,68 @@
+package cache_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+)
+
+var _ = Describe("RuleStats", func() {
+	var ruleStats *cache.RuleStats
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+
+		var err error
+		ruleStats, err = cache.NewRuleStats()
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(ruleStats.Close)
+	})
+
+	recordRuns := func() {
+		for _, fast := range []time.Duration{10 * time.Millisecond, 30 * time.Millisecond} {
+			Expect(ruleStats.RecordEvaluations("/repo", []cache.RuleEvaluation{
+				{Rule: "layers", Clause: "FORBID(controllers:* -> repositories:*)", Duration: 2 * time.Second, Violations: 3},
+				{Rule: "layers", Duration: 2 * time.Second, Violations: 3},
+				{Rule: "complexity", Clause: "LIMIT(*.cyclomatic > 10)", Duration: fast},
+				{Rule: "complexity", Duration: fast},
+			})).To(Succeed())
+		}
+	}
+
+	It("should list rules by average evaluation time", func() {
+		recordRuns()
+
+		stats, err := ruleStats.GetSlowest("/repo", 10, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats).To(HaveLen(2))
+
+		Expect(stats[0].Rule).To(Equal("layers"))
+		Expect(stats[0].Clause).To(BeEmpty())
+		Expect(stats[0].Runs).To(Equal(int64(2)))
+		Expect(stats[0].Violations).To(Equal(int64(3)))
+
+		Expect(stats[1].Rule).To(Equal("complexity"))
+		Expect(stats[1].AvgDuration).To(Equal(20 * time.Millisecond))
+		Expect(stats[1].MaxDuration).To(Equal(30 * time.Millisecond))
+	})
+
+	It("should list clauses and honour the limit", func() {
+		recordRuns()
+
+		stats, err := ruleStats.GetSlowest("/repo", 1, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats).To(HaveLen(1))
+		Expect(stats[0].Clause).To(Equal("FORBID(controllers:* -> repositories:*)"))
+	})
+
+	It("should only list timings of the given directory", func() {
+		recordRuns()
+
+		stats, err := ruleStats.GetSlowest("/other", 10, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats).To(BeEmpty())
+	})
+})
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// RunHistory records a summary of every check run by commit, for "arch-unit history"
+type RunHistory struct {
+	db *DB
+}
+
+// NewRunHistory opens the run history in the statistics database
+func NewRunHistory() (*RunHistory, error) {
+	db, err := openStatsDB()
+	if err != nil {
+		return nil, err
+	}
+
+	rh := &RunHistory{db: db}
+	if err := rh.initSchema(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return rh, nil
+}
+
+// initSchema creates the necessary tables
+func (rh *RunHistory) initSchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS run_summaries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		work_dir TEXT NOT NULL,
+		commit_sha TEXT NOT NULL,
+		dirty BOOLEAN NOT NULL,
+		recorded_at DATETIME NOT NULL,
+		violation_count INTEGER NOT NULL,
+		rules_json TEXT NOT NULL,
+		file_count INTEGER NOT NULL,
+		function_count INTEGER NOT NULL,
+		avg_complexity REAL NOT NULL,
+		max_complexity INTEGER NOT NULL,
+		dependency_count INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_run_summaries_workdir ON run_summaries(work_dir, commit_sha);
+	`
+
+	if _, err := rh.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return nil
+}
+
+// Record stores the summary of a run in workDir, setting its ID
+func (rh *RunHistory) Record(workDir string, summary *models.RunSummary) error {
+	rules, err := json.Marshal(summary.Rules)
+	if err != nil {
+		return err
+	}
+	result, err := rh.db.Exec(`
+		INSERT INTO run_summaries
+		(work_dir, commit_sha, dirty, recorded_at, violation_count, rules_json, file_count, function_count, avg_complexity, max_complexity, dependency_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		workDir, summary.Commit, summary.Dirty, summary.RecordedAt, summary.Violations, string(rules),
+		summary.Files, summary.Functions, summary.AvgComplexity, summary.MaxComplexity, summary.Dependencies)
+	if err != nil {
+		return err
+	}
+	summary.ID, err = result.LastInsertId()
+	return err
+}
+
+// List returns the last limit runs in workDir, oldest first, every run when limit is 0
+func (rh *RunHistory) List(workDir string, limit int) ([]*models.RunSummary, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := rh.db.Query(`
+		SELECT * FROM (
+			SELECT id, commit_sha, dirty, recorded_at, violation_count, rules_json, file_count, function_count, avg_complexity, max_complexity, dependency_count
+			FROM run_summaries
+			WHERE work_dir = ?
+			ORDER BY id DESC
+			LIMIT ?
+		) ORDER BY id`,
+		workDir, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return scanRunSummaries(rows)
+}
+
+// ForCommit returns the last run in workDir at a commit without uncommitted changes, nil when
+// there is none
+func (rh *RunHistory) ForCommit(workDir, commit string) (*models.RunSummary, error) {
+	rows, err := rh.db.Query(`
+		SELECT id, commit_sha, dirty, recorded_at, violation_count, rules_json, file_count, function_count, avg_complexity, max_complexity, dependency_count
+		FROM run_summaries
+		WHERE work_dir = ? AND commit_sha = ? AND NOT dirty
+		ORDER BY id DESC
+		LIMIT 1`,
+		workDir, commit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	summaries, err := scanRunSummaries(rows)
+	if err != nil || len(summaries) == 0 {
+		return nil, err
+	}
+	return summaries[0], nil
+}
+
+func scanRunSummaries(rows *sql.Rows) ([]*models.RunSummary, error) {
+	var summaries []*models.RunSummary
+	for rows.Next() {
+		var s models.RunSummary
+		var rules string
+		if err := rows.Scan(&s.ID, &s.Commit, &s.Dirty, &s.RecordedAt, &s.Violations, &rules,
+			&s.Files, &s.Functions, &s.AvgComplexity, &s.MaxComplexity, &s.Dependencies); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(rules), &s.Rules); err != nil {
+			return nil, fmt.Errorf("invalid rules of run %d: %w", s.ID, err)
+		}
+		summaries = append(summaries, &s)
+	}
+	return summaries, rows.Err()
+}
+
+// Close closes the database connection
+func (rh *RunHistory) Close() error {
+	if rh.db != nil {
+		return rh.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package cache_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("RunHistory", func() {
+	var history *cache.RunHistory
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+
+		var err error
+		history, err = cache.NewRunHistory()
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(history.Close)
+	})
+
+	record := func(commit string, dirty bool, violations int) *models.RunSummary {
+		summary := &models.RunSummary{
+			Commit: commit, Dirty: dirty, RecordedAt: time.Now().UTC().Truncate(time.Second),
+			Violations: violations, Rules: map[string]int{"layers": violations},
+			Files: 3, Functions: 12, AvgComplexity: 2.5, MaxComplexity: 7, Dependencies: 4,
+		}
+		Expect(history.Record("/repo", summary)).To(Succeed())
+		return summary
+	}
+
+	It("should list the last runs oldest first", func() {
+		record("aaa", false, 3)
+		second := record("bbb", false, 2)
+		third := record("bbb", true, 4)
+		record("ccc", false, 1)
+
+		runs, err := history.List("/repo", 3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(HaveLen(3))
+		Expect(runs[0].ID).To(Equal(second.ID))
+		Expect(runs[0].RecordedAt).To(BeTemporally("==", second.RecordedAt))
+		Expect(runs[0].Rules).To(Equal(map[string]int{"layers": 2}))
+		Expect(runs[0].AvgComplexity).To(Equal(2.5))
+		Expect(runs[1].ID).To(Equal(third.ID))
+		Expect(runs[1].Dirty).To(BeTrue())
+		Expect(runs[2].Commit).To(Equal("ccc"))
+
+		runs, err = history.List("/other", 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(BeEmpty())
+	})
+
+	It("should find the last clean run of a commit", func() {
+		record("aaa", false, 3)
+		clean := record("aaa", false, 2)
+		record("aaa", true, 5)
+
+		run, err := history.ForCommit("/repo", "aaa")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(run.ID).To(Equal(clean.ID))
+		Expect(run.Violations).To(Equal(2))
+
+		run, err = history.ForCommit("/repo", "zzz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(run).To(BeNil())
+	})
+})
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flanksource/arch-unit/models"
+	"gorm.io/gorm"
+)
+
+// SchemaVersion is the version of the AST cache schema. Bump it whenever the cached models change
+// in a way AutoMigrate cannot apply, or that older binaries cannot read.
+const SchemaVersion = 1
+
+// CLIVersion is the arch-unit version recorded in the cache when it is migrated
+var CLIVersion = "dev"
+
+// CacheInfo records the schema version of the AST cache and the arch-unit version that last
+// migrated it, so that binaries of different versions sharing a cache can detect each other
+type CacheInfo struct {
+	ID            int       `json:"-" gorm:"primaryKey"`
+	SchemaVersion int       `json:"schema_version" gorm:"column:schema_version;not null"`
+	WrittenBy     string    `json:"written_by" gorm:"column:written_by"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for CacheInfo
+func (CacheInfo) TableName() string {
+	return "cache_info"
+}
+
+// SchemaMismatchError is returned when the AST cache was written by an incompatible arch-unit
+type SchemaMismatchError struct {
+	Path string
+	// CacheVersion is the schema version of the cache, 0 for caches predating schema versions
+	CacheVersion int
+	WrittenBy    string
+	// Err is the migration error when an older cache could not be upgraded
+	Err error
+}
+
+func (e *SchemaMismatchError) Error() string {
+	writtenBy := "an arch-unit release without schema versions"
+	if e.WrittenBy != "" {
+		writtenBy = "arch-unit " + e.WrittenBy
+	}
+	if e.CacheVersion > SchemaVersion {
+		return fmt.Sprintf("the cache %s uses schema v%d written by %s, but this arch-unit (%s) supports up to v%d\n"+
+			"Upgrade arch-unit, or run 'arch-unit cache migrate' to rebuild the cache for this version",
+			e.Path, e.CacheVersion, writtenBy, CLIVersion, SchemaVersion)
+	}
+	return fmt.Sprintf("the cache %s uses schema v%d written by %s and could not be upgraded to v%d: %v\n"+
+		"Run 'arch-unit cache migrate' to rebuild the cache for this version",
+		e.Path, e.CacheVersion, writtenBy, SchemaVersion, e.Err)
+}
+
+func (e *SchemaMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// migrateSchema upgrades the cache at path to SchemaVersion, refusing caches written by newer
+// binaries instead of failing later with errors about missing columns
+func migrateSchema(db *gorm.DB, path string) error {
+	info, err := readCacheInfo(db)
+	if err != nil {
+		return fmt.Errorf("failed to read cache schema version: %w", err)
+	}
+
+	mismatch := &SchemaMismatchError{Path: path}
+	if info != nil {
+		mismatch.CacheVersion = info.SchemaVersion
+		mismatch.WrittenBy = info.WrittenBy
+	}
+	if mismatch.CacheVersion > SchemaVersion {
+		return mismatch
+	}
+
+	existing := info != nil || db.Migrator().HasTable(&models.FileMetadata{})
+	if err := autoMigrateModels(db); err != nil {
+		// A new cache failing to migrate is not a version problem
+		if !existing {
+			return fmt.Errorf("failed to auto-migrate models: %w", err)
+		}
+		mismatch.Err = err
+		return mismatch
+	}
+
+	if info != nil && info.SchemaVersion == SchemaVersion && info.WrittenBy == CLIVersion {
+		return nil
+	}
+	if err := db.AutoMigrate(&CacheInfo{}); err != nil {
+		return fmt.Errorf("failed to migrate cache info: %w", err)
+	}
+	return db.Save(&CacheInfo{ID: 1, SchemaVersion: SchemaVersion, WrittenBy: CLIVersion, UpdatedAt: time.Now()}).Error
+}
+
+// readCacheInfo returns the version information of a cache, nil for caches predating it
+func readCacheInfo(db *gorm.DB) (*CacheInfo, error) {
+	if !db.Migrator().HasTable(&CacheInfo{}) {
+		return nil, nil
+	}
+	var info CacheInfo
+	if err := db.First(&info).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &info, nil
+}
+
+// MigrateCache brings the AST cache in cacheDir to SchemaVersion. Older caches are upgraded in
+// place; caches written by newer binaries, or that cannot be upgraded, are moved aside and
+// recreated empty. It returns the path of the moved cache, empty when it was upgraded in place.
+// A shared PostgreSQL cache is only upgraded, as other machines may still be using it.
+func MigrateCache(cacheDir string) (string, error) {
+	if dsn := DSN(); dsn != "" {
+		db, err := newPostgresGormDB(dsn)
+		if err != nil {
+			return "", fmt.Errorf("%w\nThe shared cache is not recreated, drop its tables or use another database", err)
+		}
+		return "", db.Close()
+	}
+
+	db, err := newDualPoolGormDBWithPath(cacheDir)
+	if err == nil {
+		return "", db.Close()
+	}
+	var mismatch *SchemaMismatchError
+	if !errors.As(err, &mismatch) {
+		return "", err
+	}
+
+	dbPath := filepath.Join(cacheDir, "ast.db")
+	backup := fmt.Sprintf("%s.v%d.bak", dbPath, mismatch.CacheVersion)
+	// The write-ahead log belongs to the database and is moved with it
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Rename(dbPath+suffix, backup+suffix); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to move %s aside: %w", dbPath+suffix, err)
+		}
+	}
+
+	db, err = newDualPoolGormDBWithPath(cacheDir)
+	if err != nil {
+		return backup, fmt.Errorf("failed to recreate the cache: %w", err)
+	}
+	return backup, db.Close()
+}
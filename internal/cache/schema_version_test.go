@@ -0,0 +1,98 @@
+package cache_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+)
+
+var _ = Describe("Cache schema version", func() {
+	var dir string
+
+	open := func() (*cache.ProtectedGormDB, error) {
+		db, err := cache.NewGormDBWithPath(dir)
+		if err == nil {
+			DeferCleanup(func() {
+				sqlDB, err := db.DB()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sqlDB.Close()).To(Succeed())
+			})
+		}
+		return db, err
+	}
+
+	readInfo := func(db *cache.ProtectedGormDB) cache.CacheInfo {
+		var info cache.CacheInfo
+		Expect(db.GetWriteDB().First(&info).Error).ToNot(HaveOccurred())
+		return info
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("should record the schema version and arch-unit version of a new cache", func() {
+		db, err := open()
+		Expect(err).ToNot(HaveOccurred())
+
+		info := readInfo(db)
+		Expect(info.SchemaVersion).To(Equal(cache.SchemaVersion))
+		Expect(info.WrittenBy).To(Equal(cache.CLIVersion))
+	})
+
+	Context("with a cache written by a newer arch-unit", func() {
+		BeforeEach(func() {
+			db, err := cache.NewGormDBWithPath(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.GetWriteDB().Save(&cache.CacheInfo{ID: 1, SchemaVersion: cache.SchemaVersion + 1, WrittenBy: "v99.0.0"}).Error).To(Succeed())
+			sqlDB, err := db.DB()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sqlDB.Close()).To(Succeed())
+		})
+
+		It("should refuse to open it with an upgrade message", func() {
+			_, err := open()
+			var mismatch *cache.SchemaMismatchError
+			Expect(errors.As(err, &mismatch)).To(BeTrue())
+			Expect(mismatch.CacheVersion).To(Equal(cache.SchemaVersion + 1))
+			Expect(err.Error()).To(ContainSubstring("written by arch-unit v99.0.0"))
+			Expect(err.Error()).To(ContainSubstring("Upgrade arch-unit, or run 'arch-unit cache migrate'"))
+		})
+
+		It("should move it aside and recreate it when migrated", func() {
+			backup, err := cache.MigrateCache(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backup).To(Equal(filepath.Join(dir, fmt.Sprintf("ast.db.v%d.bak", cache.SchemaVersion+1))))
+			Expect(backup).To(BeAnExistingFile())
+
+			db, err := open()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readInfo(db).SchemaVersion).To(Equal(cache.SchemaVersion))
+		})
+	})
+
+	It("should upgrade an older cache in place when migrated", func() {
+		db, err := cache.NewGormDBWithPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(db.GetWriteDB().Exec("DROP TABLE cache_info").Error).To(Succeed())
+		sqlDB, err := db.DB()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sqlDB.Close()).To(Succeed())
+
+		backup, err := cache.MigrateCache(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backup).To(BeEmpty())
+
+		_, err = os.Stat(filepath.Join(dir, "ast.db"))
+		Expect(err).ToNot(HaveOccurred())
+		db, err = open()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readInfo(db).SchemaVersion).To(Equal(cache.SchemaVersion))
+	})
+})
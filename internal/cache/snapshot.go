@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/models"
+	"gorm.io/gorm"
+)
+
+// Entries of a snapshot archive
+const (
+	snapshotInfoEntry = "snapshot.json"
+	snapshotDBEntry   = "ast.db"
+)
+
+// SnapshotInfo describes the AST cache of a commit stored in a snapshot
+type SnapshotInfo struct {
+	Repository string `json:"repository"`
+	Commit     string `json:"commit"`
+	// Root is the directory the repository was analyzed in, paths under it are moved to the
+	// directory a snapshot is restored for
+	Root          string    `json:"root"`
+	SchemaVersion int       `json:"schema_version"`
+	WrittenBy     string    `json:"written_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	Files         int64     `json:"files"`
+}
+
+// WriteSnapshot writes a gzipped tar of a copy of the SQLite cache holding only the files under
+// info.Root, so that CI jobs can start from the cache of another job instead of an empty one
+func (c *ASTCache) WriteSnapshot(w io.Writer, info SnapshotInfo) (*SnapshotInfo, error) {
+	if isPostgres(c.db.GetWriteDB()) {
+		return nil, fmt.Errorf("snapshots are only supported for the SQLite cache, a shared PostgreSQL cache needs no snapshots")
+	}
+	root := filepath.Clean(info.Root)
+
+	dir, err := os.MkdirTemp("", "arch-unit-snapshot-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// VACUUM INTO copies a consistent state of the cache while other processes write to it
+	path := filepath.Join(dir, snapshotDBEntry)
+	if err := c.db.Exec("VACUUM INTO ?", path); err != nil {
+		return nil, fmt.Errorf("failed to copy the cache: %w", err)
+	}
+	db, err := newDualPoolGormDBWithPath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the copy of the cache: %w", err)
+	}
+	files, err := (&ASTCache{db: db}).keepOnly(root)
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info.Root = root
+	info.SchemaVersion = SchemaVersion
+	info.WrittenBy = CLIVersion
+	info.CreatedAt = time.Now().UTC()
+	info.Files = files
+	if err := writeSnapshotArchive(w, &info, path); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// keepOnly deletes the files outside root and the summaries and embeddings left without nodes,
+// then compacts the database, returning the number of files kept
+func (c *ASTCache) keepOnly(root string) (int64, error) {
+	under := strings.TrimSuffix(root, string(filepath.Separator)) + string(filepath.Separator)
+	outside := "file_path != ? AND substr(file_path, 1, length(?)) != ?"
+
+	var files []string
+	if err := c.db.GetReadDB().Raw(
+		"SELECT file_path FROM file_metadata WHERE "+outside+
+			" UNION SELECT DISTINCT file_path FROM ast_nodes WHERE "+outside,
+		root, under, under, root, under, under).Scan(&files).Error; err != nil {
+		return 0, fmt.Errorf("failed to find the files outside %s: %w", root, err)
+	}
+	if err := c.deleteFiles(files); err != nil {
+		return 0, err
+	}
+
+	if err := c.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.Violation{}, &models.FileScan{}} {
+			if err := tx.Where(outside, root, under, under).Delete(model).Error; err != nil {
+				return fmt.Errorf("failed to delete %T outside %s: %w", model, root, err)
+			}
+		}
+		if err := tx.Where("node_id NOT IN (SELECT id FROM ast_nodes)").Delete(&models.NodeEmbedding{}).Error; err != nil {
+			return fmt.Errorf("failed to delete embeddings: %w", err)
+		}
+		if err := tx.Where("file_hash NOT IN (SELECT file_hash FROM file_metadata)").Delete(&models.NodeSummary{}).Error; err != nil {
+			return fmt.Errorf("failed to delete summaries: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	// Deleted rows leave free pages behind, which would be uploaded with every snapshot
+	if err := c.db.Exec("VACUUM"); err != nil {
+		return 0, fmt.Errorf("failed to compact the snapshot: %w", err)
+	}
+	var count int64
+	if err := c.db.GetReadDB().Model(&models.FileMetadata{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count the files of the snapshot: %w", err)
+	}
+	return count, nil
+}
+
+// writeSnapshotArchive writes the info and the database at path to a gzipped tar
+func writeSnapshotArchive(w io.Writer, info *SnapshotInfo, path string) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot info: %w", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open the snapshot: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open the snapshot: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	if err := archive.WriteHeader(&tar.Header{Name: snapshotInfoEntry, Mode: 0644, Size: int64(len(data)), ModTime: info.CreatedAt}); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if _, err := archive.Write(data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := archive.WriteHeader(&tar.Header{Name: snapshotDBEntry, Mode: 0644, Size: stat.Size(), ModTime: info.CreatedAt}); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if _, err := io.Copy(archive, file); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return gz.Close()
+}
+
+// RestoreSnapshot replaces the SQLite cache in cacheDir with a snapshot written by WriteSnapshot,
+// moving the paths of the files it holds from the directory it was written in to root. The
+// replaced cache is moved aside to ast.db.bak. Snapshots written by older arch-unit versions are
+// upgraded; those of newer versions are refused. The cache must not be open while restoring.
+func RestoreSnapshot(r io.Reader, cacheDir, root string) (*SnapshotInfo, error) {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// A directory next to the cache, so that the restored database is renamed into place
+	dir, err := os.MkdirTemp(cacheDir, "snapshot-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	info, err := readSnapshotArchive(r, filepath.Join(dir, snapshotDBEntry))
+	if err != nil {
+		return nil, err
+	}
+	if info.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("the snapshot of %s uses schema v%d written by arch-unit %s, but this arch-unit (%s) supports up to v%d",
+			info.Commit, info.SchemaVersion, info.WrittenBy, CLIVersion, SchemaVersion)
+	}
+
+	db, err := newDualPoolGormDBWithPath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the snapshot: %w", err)
+	}
+	root = filepath.Clean(root)
+	if info.Root != "" && info.Root != root {
+		err = relocate(db, info.Root, root)
+	}
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dbPath := filepath.Join(cacheDir, "ast.db")
+	// The write-ahead log belongs to the database and is moved with it
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Rename(dbPath+suffix, dbPath+".bak"+suffix); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to move %s aside: %w", dbPath+suffix, err)
+		}
+	}
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Rename(filepath.Join(dir, snapshotDBEntry+suffix), dbPath+suffix); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to restore %s: %w", dbPath+suffix, err)
+		}
+	}
+	return info, nil
+}
+
+// readSnapshotArchive reads the info of a snapshot and writes its database to path
+func readSnapshotArchive(r io.Reader, path string) (*SnapshotInfo, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	var info *SnapshotInfo
+	restored := false
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		switch header.Name {
+		case snapshotInfoEntry:
+			info = &SnapshotInfo{}
+			if err := json.NewDecoder(archive).Decode(info); err != nil {
+				return nil, fmt.Errorf("failed to decode snapshot info: %w", err)
+			}
+		case snapshotDBEntry:
+			file, err := os.Create(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore snapshot: %w", err)
+			}
+			_, err = io.Copy(file, archive)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to restore snapshot: %w", err)
+			}
+			restored = true
+		}
+	}
+	if info == nil || !restored {
+		return nil, fmt.Errorf("not an arch-unit cache snapshot, %s or %s is missing", snapshotInfoEntry, snapshotDBEntry)
+	}
+	return info, nil
+}
+
+// relocate moves the paths of the files under from to the same paths under to
+func relocate(db *DualPoolGormDB, from, to string) error {
+	from = strings.TrimSuffix(from, string(filepath.Separator)) + string(filepath.Separator)
+	to = strings.TrimSuffix(to, string(filepath.Separator)) + string(filepath.Separator)
+
+	// Violations reference the paths of file scans, which are renamed one table at a time
+	if err := db.Exec("PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("failed to relocate the snapshot: %w", err)
+	}
+	defer func() { _ = db.Exec("PRAGMA foreign_keys = ON") }()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"file_metadata", "ast_nodes", "file_scans", "violations"} {
+			if err := tx.Exec("UPDATE "+table+" SET file_path = ? || substr(file_path, length(?) + 1) "+
+				"WHERE substr(file_path, 1, length(?)) = ?", to, from, from, from).Error; err != nil {
+				return fmt.Errorf("failed to relocate %s from %s to %s: %w", table, from, to, err)
+			}
+		}
+		return nil
+	})
+}
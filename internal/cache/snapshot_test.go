@@ -0,0 +1,65 @@
+package cache_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("AST Cache Snapshots", func() {
+	It("should restore the files of a repository under another directory", func() {
+		tempDir := GinkgoT().TempDir()
+		astCache, err := cache.NewASTCacheWithPath(filepath.Join(tempDir, "cache"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(astCache.Close)
+
+		for _, path := range []string{filepath.Join(tempDir, "repo", "main.go"), filepath.Join(tempDir, "other", "main.go")} {
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(os.WriteFile(path, []byte("package main\n"), 0644)).To(Succeed())
+			language := "go"
+			result := &struct{ Nodes []*models.ASTNode }{Nodes: []*models.ASTNode{{
+				ID: 1, FilePath: path, PackageName: "main", MethodName: "main",
+				NodeType: models.NodeTypeMethod, Language: &language, StartLine: 1,
+			}}}
+			Expect(astCache.StoreFileResults(path, result)).To(Succeed())
+		}
+
+		var snapshot bytes.Buffer
+		info, err := astCache.WriteSnapshot(&snapshot, cache.SnapshotInfo{
+			Repository: "github.com/flanksource/example", Commit: "abc123", Root: filepath.Join(tempDir, "repo"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Files).To(Equal(int64(1)))
+		Expect(info.SchemaVersion).To(Equal(cache.SchemaVersion))
+
+		restoreDir := filepath.Join(tempDir, "restored")
+		Expect(os.MkdirAll(restoreDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(restoreDir, "ast.db"), []byte("previous"), 0644)).To(Succeed())
+		restored, err := cache.RestoreSnapshot(&snapshot, restoreDir, filepath.Join(tempDir, "ci", "repo"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restored.Commit).To(Equal("abc123"))
+		Expect(filepath.Join(restoreDir, "ast.db.bak")).To(BeAnExistingFile())
+
+		restoredCache, err := cache.NewASTCacheWithPath(restoreDir)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(restoredCache.Close)
+		var files []string
+		Expect(restoredCache.GetReadQuery().Model(&models.FileMetadata{}).Pluck("file_path", &files).Error).To(Succeed())
+		Expect(files).To(Equal([]string{filepath.Join(tempDir, "ci", "repo", "main.go")}))
+
+		nodes, err := restoredCache.GetASTNodesByFile(filepath.Join(tempDir, "ci", "repo", "main.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes).To(HaveLen(1))
+	})
+
+	It("should refuse archives that are not snapshots", func() {
+		_, err := cache.RestoreSnapshot(bytes.NewBufferString("not gzip"), GinkgoT().TempDir(), "/repo")
+		Expect(err).To(MatchError(ContainSubstring("failed to read snapshot")))
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -236,6 +237,66 @@ func (c *ViolationCache) StoreViolations(filePath string, violations []models.Vi
 	})
 }
 
+// ReplaceViolations replaces the violations a source reported in files with the violations of its
+// latest run, so that files fixed since the previous run are cleared too, leaving the violations
+// of other sources. Without files the run covered every file under dir, whose violations of the
+// source are all replaced.
+func (c *ViolationCache) ReplaceViolations(source, dir string, files []string, violations []models.Violation) error {
+	return c.db.GormDB().Transaction(func(tx *gorm.DB) error {
+		if len(files) == 0 {
+			under := strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+			if err := tx.Where("source = ? AND substr(file_path, 1, length(?)) = ?", source, under, under).Delete(&models.Violation{}).Error; err != nil {
+				return err
+			}
+		}
+		for _, file := range files {
+			if err := tx.Where("source = ? AND file_path = ?", source, file).Delete(&models.Violation{}).Error; err != nil {
+				return err
+			}
+		}
+		cleared := make(map[string]bool, len(files))
+		for _, file := range files {
+			cleared[file] = true
+		}
+		scanned := make(map[string]bool)
+
+		for i := range violations {
+			v := &violations[i]
+			// Violations may be reported in files other than those checked
+			if len(files) > 0 && !cleared[v.File] {
+				if err := tx.Where("source = ? AND file_path = ?", source, v.File).Delete(&models.Violation{}).Error; err != nil {
+					return err
+				}
+				cleared[v.File] = true
+			}
+			if v.Source == "" {
+				v.Source = source
+			}
+			// Violations reference the scan of their file, an existing scan is kept as is
+			if !scanned[v.File] {
+				if err := tx.Where(FileScan{FilePath: v.File}).FirstOrCreate(&FileScan{FilePath: v.File, LastScanTime: time.Now().Unix()}).Error; err != nil {
+					return err
+				}
+				scanned[v.File] = true
+			}
+			if v.Caller != nil {
+				if err := tx.Save(v.Caller).Error; err != nil {
+					return err
+				}
+			}
+			if v.Called != nil {
+				if err := tx.Save(v.Called).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Create(v).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // GetAllCachedFiles returns all files that have cached violations
 func (c *ViolationCache) GetAllCachedFiles() ([]string, error) {
 	gormDB := c.db.GormDB()
@@ -0,0 +1,72 @@
+package cache_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Violation cache", func() {
+	var violationCache *cache.ViolationCache
+
+	violation := func(file string, line int, source string) models.Violation {
+		return models.Violation{File: file, Line: line, Source: source, Message: models.StringPtr("unused result")}
+	}
+
+	lines := func() map[string][]int {
+		violations, err := violationCache.GetAllViolations()
+		Expect(err).NotTo(HaveOccurred())
+		lines := make(map[string][]int)
+		for _, v := range violations {
+			lines[v.Source+" "+v.File] = append(lines[v.Source+" "+v.File], v.Line)
+		}
+		return lines
+	}
+
+	BeforeEach(func() {
+		GinkgoT().Setenv("HOME", GinkgoT().TempDir())
+		var err error
+		violationCache, err = cache.NewViolationCache()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(violationCache.Close)
+
+		Expect(violationCache.ReplaceViolations("golangci-lint", "/repo", nil, []models.Violation{
+			violation("/repo/user.go", 10, "golangci-lint"),
+			violation("/repo/order.go", 20, "golangci-lint"),
+		})).To(Succeed())
+		Expect(violationCache.ReplaceViolations("eslint", "/repo", nil, []models.Violation{
+			violation("/repo/user.go", 5, "eslint"),
+		})).To(Succeed())
+	})
+
+	It("should clear the checked files left without violations", func() {
+		Expect(violationCache.ReplaceViolations("golangci-lint", "/repo", []string{"/repo/user.go"}, nil)).To(Succeed())
+		Expect(lines()).To(Equal(map[string][]int{
+			"golangci-lint /repo/order.go": {20},
+			"eslint /repo/user.go":         {5},
+		}))
+	})
+
+	It("should replace the violations of the checked files only", func() {
+		Expect(violationCache.ReplaceViolations("golangci-lint", "/repo", []string{"/repo/user.go"}, []models.Violation{
+			violation("/repo/user.go", 12, "golangci-lint"),
+		})).To(Succeed())
+		Expect(lines()).To(Equal(map[string][]int{
+			"golangci-lint /repo/user.go":  {12},
+			"golangci-lint /repo/order.go": {20},
+			"eslint /repo/user.go":         {5},
+		}))
+	})
+
+	It("should replace every violation of a source under the directory of a full run", func() {
+		Expect(violationCache.ReplaceViolations("golangci-lint", "/repo", nil, []models.Violation{
+			violation("/repo/order.go", 21, "golangci-lint"),
+		})).To(Succeed())
+		Expect(lines()).To(Equal(map[string][]int{
+			"golangci-lint /repo/order.go": {21},
+			"eslint /repo/user.go":         {5},
+		}))
+	})
+})
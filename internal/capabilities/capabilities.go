@@ -0,0 +1,201 @@
+package capabilities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// Tool is an external program that some features of arch-unit run
+type Tool struct {
+	Name string
+	// Binaries are the executables providing the tool, the first one found on PATH is used
+	Binaries []string
+	// Feature describes what is unavailable when the tool is missing
+	Feature string
+	// Languages whose analysis needs the tool
+	Languages []string
+	// Linter is set for tools run as the linter of the same name
+	Linter  bool
+	Install string
+	// VersionArgs print the version of the tool, --version when empty
+	VersionArgs []string
+}
+
+// Tools are the external programs arch-unit knows how to degrade without
+var Tools = []Tool{
+	{Name: "git", Binaries: []string{"git"}, Feature: "dependency traversal (deps --depth) and Git repositories", Install: "https://git-scm.com/downloads"},
+	{Name: "go", Binaries: []string{"go"}, Feature: "transitive Go dependencies (go mod graph)", Install: "https://go.dev/doc/install", VersionArgs: []string{"version"}},
+	{Name: "python3", Binaries: []string{"python3", "python"}, Feature: "Python analysis", Languages: []string{"python"}, Install: "https://www.python.org/downloads/"},
+	{Name: "node", Binaries: []string{"node"}, Feature: "JavaScript and TypeScript analysis", Languages: []string{"javascript", "typescript"}, Install: "https://nodejs.org/en/download"},
+	{Name: "npm", Binaries: []string{"npm", "yarn"}, Feature: "installing the JavaScript and TypeScript parsers", Languages: []string{"javascript", "typescript"}, Install: "https://nodejs.org/en/download"},
+	{Name: "java", Binaries: []string{"java"}, Feature: "Java analysis", Languages: []string{"java"}, Install: "https://adoptium.net", VersionArgs: []string{"-version"}},
+	{Name: "golangci-lint", Binaries: []string{"golangci-lint"}, Feature: "the golangci-lint linter", Linter: true, Install: "https://golangci-lint.run/welcome/install/"},
+	{Name: "ruff", Binaries: []string{"ruff"}, Feature: "the ruff linter", Linter: true, Install: "pip install ruff"},
+	{Name: "pyright", Binaries: []string{"pyright"}, Feature: "the pyright linter", Linter: true, Install: "npm install -g pyright"},
+	{Name: "eslint", Binaries: []string{"eslint"}, Feature: "the eslint linter", Linter: true, Install: "npm install -g eslint"},
+	{Name: "markdownlint", Binaries: []string{"markdownlint"}, Feature: "the markdownlint linter", Linter: true, Install: "npm install -g markdownlint-cli"},
+	{Name: "vale", Binaries: []string{"vale"}, Feature: "the vale linter", Linter: true, Install: "https://vale.sh/docs/install"},
+	{Name: "opa", Binaries: []string{"opa"}, Feature: "OPA/Rego policies", Install: "https://www.openpolicyagent.org/docs/latest/#1-download-opa", VersionArgs: []string{"version"}},
+}
+
+// MissingToolError is returned when a feature needs a tool that is not installed
+type MissingToolError struct {
+	Tool    string
+	Feature string
+	Install string
+}
+
+func (e *MissingToolError) Error() string {
+	return fmt.Sprintf("%s is not installed, %s is unavailable", e.Tool, e.Feature)
+}
+
+func (e *MissingToolError) Unwrap() error {
+	return exec.ErrNotFound
+}
+
+// IsMissing reports whether err was caused by a missing tool
+func IsMissing(err error) bool {
+	var missing *MissingToolError
+	return errors.As(err, &missing)
+}
+
+// versionTimeout bounds the version command of a tool, some start a runtime before printing it
+const versionTimeout = 10 * time.Second
+
+var (
+	// lookPath is replaced in tests
+	lookPath = exec.LookPath
+
+	// runVersion is replaced in tests
+	runVersion = defaultRunVersion
+
+	mu       sync.Mutex
+	found    = make(map[string]string)
+	warnings = make(map[string]models.CapabilityWarning)
+)
+
+// Get returns the tool providing a binary, tools that are not known are provided by the binary
+// of the same name
+func Get(name string) Tool {
+	for _, tool := range Tools {
+		if tool.Name == name {
+			return tool
+		}
+		for _, binary := range tool.Binaries {
+			if binary == name {
+				return tool
+			}
+		}
+	}
+	return Tool{Name: name, Binaries: []string{name}, Feature: "running " + name}
+}
+
+// Find returns the binary providing a tool, without recording a warning when it is missing
+func Find(name string) (string, bool) {
+	tool := Get(name)
+	// Callers asking for an alternative binary by name need that binary
+	binaries := tool.Binaries
+	if name != tool.Name {
+		binaries = []string{name}
+	}
+	for _, binary := range binaries {
+		if _, err := lookPath(binary); err == nil {
+			return binary, true
+		}
+	}
+	return "", false
+}
+
+func defaultRunVersion(binary string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	// java -version prints to stderr
+	out, err := exec.CommandContext(ctx, binary, args...).CombinedOutput()
+	return string(out), err
+}
+
+// Version returns the first line printed by the version command of a tool, empty when the tool
+// is not installed or its version command fails
+func Version(name string) string {
+	binary, ok := Find(name)
+	if !ok {
+		return ""
+	}
+	args := Get(name).VersionArgs
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+	out, err := runVersion(binary, args...)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Lookup returns the binary providing a tool. When none is installed it records a capability
+// warning, reported once per tool in the summary, and returns a MissingToolError.
+func Lookup(name string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if binary, ok := found[name]; ok {
+		return binary, nil
+	}
+	tool := Get(name)
+	// A missing alternative binary does not mean the tool is missing
+	tool.Name = name
+	if warning, ok := warnings[name]; ok {
+		return "", &MissingToolError{Tool: warning.Tool, Feature: warning.Feature, Install: warning.Install}
+	}
+
+	if binary, ok := Find(name); ok {
+		found[name] = binary
+		return binary, nil
+	}
+
+	missing := &MissingToolError{Tool: tool.Name, Feature: tool.Feature, Install: tool.Install}
+	logger.Warnf("%v (run 'arch-unit doctor' for details)", missing)
+	warnings[name] = models.CapabilityWarning{Tool: tool.Name, Feature: tool.Feature, Install: tool.Install}
+	return "", missing
+}
+
+// Require returns a MissingToolError when a tool is not installed
+func Require(name string) error {
+	_, err := Lookup(name)
+	return err
+}
+
+// Warnings returns a warning for every missing tool a feature needed, sorted by tool
+func Warnings() []models.CapabilityWarning {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list := make([]models.CapabilityWarning, 0, len(warnings))
+	for _, warning := range warnings {
+		list = append(list, warning)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Tool < list[j].Tool })
+	return list
+}
+
+// Reset forgets the tools that were looked up and the warnings recorded
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	found = make(map[string]string)
+	warnings = make(map[string]models.CapabilityWarning)
+}
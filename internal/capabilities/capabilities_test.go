@@ -0,0 +1,109 @@
+package capabilities
+
+import (
+	"os/exec"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Capabilities", func() {
+	var installed map[string]bool
+	var lookups int
+
+	BeforeEach(func() {
+		installed = map[string]bool{}
+		lookups = 0
+		lookPath = func(binary string) (string, error) {
+			lookups++
+			if installed[binary] {
+				return "/usr/bin/" + binary, nil
+			}
+			return "", exec.ErrNotFound
+		}
+		Reset()
+		DeferCleanup(func() {
+			lookPath = exec.LookPath
+			Reset()
+		})
+	})
+
+	It("should return the installed binary of a tool", func() {
+		installed["python3"] = true
+
+		binary, err := Lookup("python3")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(binary).To(Equal("python3"))
+		Expect(Warnings()).To(BeEmpty())
+	})
+
+	It("should fall back to an alternative binary", func() {
+		installed["python"] = true
+
+		binary, err := Lookup("python3")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(binary).To(Equal("python"))
+	})
+
+	It("should record a single warning for a missing tool", func() {
+		for range 3 {
+			err := Require("golangci-lint")
+			Expect(IsMissing(err)).To(BeTrue())
+			Expect(err).To(MatchError(exec.ErrNotFound))
+			Expect(err.Error()).To(Equal("golangci-lint is not installed, the golangci-lint linter is unavailable"))
+		}
+		Expect(lookups).To(Equal(1))
+
+		Expect(Warnings()).To(Equal([]models.CapabilityWarning{{
+			Tool:    "golangci-lint",
+			Feature: "the golangci-lint linter",
+			Install: "https://golangci-lint.run/welcome/install/",
+		}}))
+	})
+
+	It("should describe tools it does not know", func() {
+		Expect(Require("custom-lint")).To(MatchError(ContainSubstring("running custom-lint is unavailable")))
+		Expect(Warnings()).To(ConsistOf(models.CapabilityWarning{Tool: "custom-lint", Feature: "running custom-lint"}))
+	})
+
+	It("should not treat other errors as missing tools", func() {
+		Expect(IsMissing(exec.ErrNotFound)).To(BeFalse())
+		Expect(IsMissing(nil)).To(BeFalse())
+	})
+
+	Describe("Version", func() {
+		var commands [][]string
+
+		BeforeEach(func() {
+			commands = nil
+			runVersion = func(binary string, args ...string) (string, error) {
+				commands = append(commands, append([]string{binary}, args...))
+				return "\nopenjdk version \"21.0.2\" 2024-01-16\nOpenJDK Runtime Environment\n", nil
+			}
+			DeferCleanup(func() {
+				runVersion = defaultRunVersion
+			})
+		})
+
+		It("should return the first line printed by the version command", func() {
+			installed["java"] = true
+
+			Expect(Version("java")).To(Equal(`openjdk version "21.0.2" 2024-01-16`))
+			Expect(commands).To(Equal([][]string{{"java", "-version"}}))
+		})
+
+		It("should default to --version", func() {
+			installed["ruff"] = true
+
+			Version("ruff")
+			Expect(commands).To(Equal([][]string{{"ruff", "--version"}}))
+		})
+
+		It("should not run missing tools", func() {
+			Expect(Version("ruff")).To(BeEmpty())
+			Expect(commands).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,204 @@
+// Package export writes the tables of the AST cache to flat files for analysis with other tools,
+// such as DuckDB or pandas
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/flanksource/arch-unit/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Formats of exported tables
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Tables are the models of the exported tables: the nodes and relationships between them, and
+// the library nodes and relationships of their dependencies
+var Tables = []interface{}{
+	&models.ASTNode{},
+	&models.ASTRelationship{},
+	&models.LibraryNode{},
+	&models.LibraryRelationship{},
+}
+
+// columnKind is how the values of a column are written
+type columnKind int
+
+const (
+	kindBool columnKind = iota
+	kindInt
+	kindFloat
+	kindString
+	kindTime
+	kindJSON // Slices, maps and structs serialized to JSON strings
+)
+
+// column is a column of an exported table, named after the column of the GORM model. Optional
+// columns hold nulls for nil pointers, slices and maps.
+type column struct {
+	name     string
+	kind     columnKind
+	optional bool
+	field    *schema.Field
+}
+
+// table holds the rows of a table, values are bool, int64, float64, string, time.Time or nil
+type table struct {
+	name    string
+	columns []column
+	rows    [][]interface{}
+}
+
+// Export writes every table of the cache to a file of the format named after the table in dir,
+// e.g. ast_nodes.parquet, and returns the paths of the files
+func Export(db *gorm.DB, dir, format string) ([]string, error) {
+	if format != FormatCSV && format != FormatParquet {
+		return nil, fmt.Errorf("unsupported export format '%s', expected csv or parquet", format)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var paths []string
+	for _, model := range Tables {
+		t, err := loadTable(db, model)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, t.name+"."+format)
+		if err := writeTable(path, format, t); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// writeTable writes a table to a new file
+func writeTable(path, format string, t *table) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if format == FormatCSV {
+		err = writeCSV(file, t)
+	} else {
+		err = writeParquet(file, t)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// loadTable reads every row of the table of a model, with a column for each field GORM stores
+func loadTable(db *gorm.DB, model interface{}) (*table, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+	}
+
+	t := &table{name: stmt.Schema.Table}
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		t.columns = append(t.columns, newColumn(field))
+	}
+
+	rows := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+	if err := db.Model(model).Order(stmt.Schema.PrioritizedPrimaryField.DBName).Find(rows.Interface()).Error; err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", t.name, err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < rows.Elem().Len(); i++ {
+		row := rows.Elem().Index(i)
+		values := make([]interface{}, len(t.columns))
+		for j, c := range t.columns {
+			value, err := c.value(ctx, row)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export %s.%s: %w", t.name, c.name, err)
+			}
+			values[j] = value
+		}
+		t.rows = append(t.rows, values)
+	}
+	return t, nil
+}
+
+// newColumn returns the column of a field by the kind of its Go type
+func newColumn(field *schema.Field) column {
+	c := column{name: field.DBName, field: field, kind: kindJSON}
+	typ := field.FieldType
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		c.optional = true
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		c.kind = kindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		c.kind = kindInt
+	case reflect.Float32, reflect.Float64:
+		c.kind = kindFloat
+	case reflect.String:
+		c.kind = kindString
+	case reflect.Struct:
+		if typ == reflect.TypeOf(time.Time{}) {
+			c.kind = kindTime
+		}
+	}
+	return c
+}
+
+// value returns the value of the column in a row, nil for null
+func (c column) value(ctx context.Context, row reflect.Value) (interface{}, error) {
+	// ValueOf wraps the values of serialized fields, the JSON of their Go value is exported
+	v := c.field.ReflectValueOf(ctx, row)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch c.kind {
+	case kindBool:
+		return v.Bool(), nil
+	case kindInt:
+		if v.CanUint() {
+			return int64(v.Uint()), nil
+		}
+		return v.Int(), nil
+	case kindFloat:
+		return v.Float(), nil
+	case kindString:
+		return v.String(), nil
+	case kindTime:
+		return v.Interface().(time.Time), nil
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
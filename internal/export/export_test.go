@@ -0,0 +1,109 @@
+package export_test
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/export"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Export", func() {
+	var (
+		astCache *cache.ASTCache
+		dir      string
+	)
+
+	BeforeEach(func() {
+		cache.ResetGormDB()
+		var err error
+		astCache, err = cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).ToNot(HaveOccurred())
+		dir = GinkgoT().TempDir()
+
+		coverage := 75.5
+		from, err := astCache.StoreASTNode(&models.ASTNode{
+			FilePath:             "/repo/service/user.go",
+			PackageName:          "service",
+			TypeName:             "UserService",
+			MethodName:           "Create",
+			NodeType:             models.NodeTypeMethod,
+			StartLine:            10,
+			CyclomaticComplexity: 4,
+			Coverage:             &coverage,
+			Metatdata:            map[string]string{"receiver": "*UserService"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		to, err := astCache.StoreASTNode(&models.ASTNode{
+			FilePath:    "/repo/store/user.go",
+			PackageName: "store",
+			MethodName:  "Save",
+			NodeType:    models.NodeTypeMethod,
+			StartLine:   5,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(astCache.StoreASTRelationship(from, &to, 12, models.RelationshipCall, "store.Save()")).To(Succeed())
+	})
+
+	It("should write a CSV file per table with the column names of the models", func() {
+		paths, err := export.Export(astCache.GetReadQuery(), dir, export.FormatCSV)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(Equal([]string{
+			filepath.Join(dir, "ast_nodes.csv"),
+			filepath.Join(dir, "ast_relationships.csv"),
+			filepath.Join(dir, "library_nodes.csv"),
+			filepath.Join(dir, "library_relationships.csv"),
+		}))
+
+		file, err := os.Open(paths[0])
+		Expect(err).ToNot(HaveOccurred())
+		defer func() { _ = file.Close() }()
+		records, err := csv.NewReader(file).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records).To(HaveLen(3))
+
+		rows := make([]map[string]string, 0, 2)
+		for _, record := range records[1:] {
+			row := make(map[string]string)
+			for i, name := range records[0] {
+				row[name] = record[i]
+			}
+			rows = append(rows, row)
+		}
+		Expect(rows[0]).To(HaveKeyWithValue("file_path", "/repo/service/user.go"))
+		Expect(rows[0]).To(HaveKeyWithValue("cyclomatic_complexity", "4"))
+		Expect(rows[0]).To(HaveKeyWithValue("coverage", "75.5"))
+		Expect(rows[0]).To(HaveKeyWithValue("metatdata", `{"receiver":"*UserService"}`))
+		Expect(rows[1]).To(HaveKeyWithValue("coverage", ""))
+		Expect(rows[1]).To(HaveKeyWithValue("is_private", "false"))
+
+		relationships, err := os.ReadFile(paths[1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(relationships)).To(ContainSubstring("id,from_ast_id,to_ast_id,line_no,relationship_type,comments,text,metadata"))
+	})
+
+	It("should write Parquet files", func() {
+		paths, err := export.Export(astCache.GetReadQuery(), dir, export.FormatParquet)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(HaveLen(4))
+
+		data, err := os.ReadFile(paths[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data[:4])).To(Equal("PAR1"))
+		Expect(string(data[len(data)-4:])).To(Equal("PAR1"))
+		footer := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+		Expect(footer).To(BeNumerically("<", len(data)-12))
+		Expect(string(data[len(data)-8-footer:])).To(ContainSubstring("cyclomatic_complexity"))
+	})
+
+	It("should reject unknown formats", func() {
+		_, err := export.Export(astCache.GetReadQuery(), dir, "xlsx")
+		Expect(err).To(MatchError(ContainSubstring("unsupported export format 'xlsx'")))
+	})
+})
@@ -0,0 +1,299 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Parquet files are written without a dependency on a Parquet library: a single row group with
+// an uncompressed PLAIN encoded data page per column, described by a footer in the Thrift compact
+// protocol. See https://parquet.apache.org/docs/file-format/
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet physical types, repetitions, converted types and encodings
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// parquetType returns the physical and converted type of a column, -1 without converted type
+func parquetType(kind columnKind) (int32, int32) {
+	switch kind {
+	case kindBool:
+		return parquetBoolean, -1
+	case kindInt:
+		return parquetInt64, -1
+	case kindFloat:
+		return parquetDouble, -1
+	case kindTime:
+		return parquetInt64, parquetTimestampMillis
+	}
+	return parquetByteArray, parquetUTF8
+}
+
+// writeParquet writes a table as a Parquet file
+func writeParquet(w io.Writer, t *table) error {
+	var out bytes.Buffer
+	out.Write(parquetMagic)
+
+	type chunk struct {
+		offset int64
+		size   int64
+	}
+	chunks := make([]chunk, len(t.columns))
+	for i := range t.columns {
+		if len(t.rows) == 0 {
+			break
+		}
+		page := encodeParquetPage(t, i)
+
+		header := &thriftEncoder{}
+		header.begin()
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.structField(5)
+		header.i32(1, int32(len(t.rows)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.end()
+		header.end()
+
+		chunks[i] = chunk{offset: int64(out.Len()), size: int64(header.buf.Len() + len(page))}
+		out.Write(header.buf.Bytes())
+		out.Write(page)
+	}
+
+	meta := &thriftEncoder{}
+	meta.begin()
+	meta.i32(1, 1)
+	meta.list(2, thriftStruct, len(t.columns)+1)
+	meta.begin()
+	meta.str(4, "schema")
+	meta.i32(5, int32(len(t.columns)))
+	meta.end()
+	for _, c := range t.columns {
+		physical, converted := parquetType(c.kind)
+		repetition := int32(parquetRequired)
+		if c.optional {
+			repetition = parquetOptional
+		}
+		meta.begin()
+		meta.i32(1, physical)
+		meta.i32(3, repetition)
+		meta.str(4, c.name)
+		if converted >= 0 {
+			meta.i32(6, converted)
+		}
+		meta.end()
+	}
+	meta.i64(3, int64(len(t.rows)))
+	if len(t.rows) == 0 {
+		meta.list(4, thriftStruct, 0)
+	} else {
+		var total int64
+		meta.list(4, thriftStruct, 1)
+		meta.begin()
+		meta.list(1, thriftStruct, len(t.columns))
+		for i, c := range t.columns {
+			physical, _ := parquetType(c.kind)
+			meta.begin()
+			meta.i64(2, chunks[i].offset)
+			meta.structField(3)
+			meta.i32(1, physical)
+			meta.list(2, thriftI32, 2)
+			meta.listI32(parquetPlain)
+			meta.listI32(parquetRLE)
+			meta.list(3, thriftBinary, 1)
+			meta.listStr(c.name)
+			meta.i32(4, 0) // UNCOMPRESSED
+			meta.i64(5, int64(len(t.rows)))
+			meta.i64(6, chunks[i].size)
+			meta.i64(7, chunks[i].size)
+			meta.i64(9, chunks[i].offset)
+			meta.end()
+			meta.end()
+			total += chunks[i].size
+		}
+		meta.i64(2, total)
+		meta.i64(3, int64(len(t.rows)))
+		meta.end()
+	}
+	meta.str(6, "arch-unit")
+	meta.end()
+
+	out.Write(meta.buf.Bytes())
+	_ = binary.Write(&out, binary.LittleEndian, uint32(meta.buf.Len()))
+	out.Write(parquetMagic)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// encodeParquetPage encodes the values of a column: the definition levels of optional columns,
+// 0 for nulls, followed by the PLAIN encoded values that are not null
+func encodeParquetPage(t *table, index int) []byte {
+	var page bytes.Buffer
+	c := t.columns[index]
+	if c.optional {
+		levels := make([]bool, len(t.rows))
+		for i, row := range t.rows {
+			levels[i] = row[index] != nil
+		}
+		encoded := encodeDefinitionLevels(levels)
+		_ = binary.Write(&page, binary.LittleEndian, uint32(len(encoded)))
+		page.Write(encoded)
+	}
+
+	var bits []bool
+	for _, row := range t.rows {
+		switch v := row[index].(type) {
+		case nil:
+		case bool:
+			bits = append(bits, v)
+		case int64:
+			_ = binary.Write(&page, binary.LittleEndian, v)
+		case float64:
+			_ = binary.Write(&page, binary.LittleEndian, math.Float64bits(v))
+		case time.Time:
+			_ = binary.Write(&page, binary.LittleEndian, v.UnixMilli())
+		case string:
+			_ = binary.Write(&page, binary.LittleEndian, uint32(len(v)))
+			page.WriteString(v)
+		}
+	}
+	// Booleans are bit-packed, least significant bit first
+	if c.kind == kindBool {
+		packed := make([]byte, (len(bits)+7)/8)
+		for i, bit := range bits {
+			if bit {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		page.Write(packed)
+	}
+	return page.Bytes()
+}
+
+// encodeDefinitionLevels encodes levels of a bit width of 1 as runs of the RLE/bit-packing
+// hybrid encoding
+func encodeDefinitionLevels(levels []bool) []byte {
+	var buf bytes.Buffer
+	for start := 0; start < len(levels); {
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		writeUvarint(&buf, uint64(end-start)<<1)
+		if levels[start] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		start = end
+	}
+	return buf.Bytes()
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftEncoder writes structs of the Thrift compact protocol, fields are written in the order
+// of their ids
+type thriftEncoder struct {
+	buf  bytes.Buffer
+	last []int16 // Id of the last field of each struct being written
+}
+
+// begin starts a struct, at the top level or as an element of a list
+func (e *thriftEncoder) begin() {
+	e.last = append(e.last, 0)
+}
+
+// end writes the stop field of the current struct
+func (e *thriftEncoder) end() {
+	e.buf.WriteByte(0)
+	e.last = e.last[:len(e.last)-1]
+}
+
+func (e *thriftEncoder) field(id int16, typ byte) {
+	last := &e.last[len(e.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		e.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		e.buf.WriteByte(typ)
+		writeUvarint(&e.buf, zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (e *thriftEncoder) i32(id int16, v int32) {
+	e.field(id, thriftI32)
+	writeUvarint(&e.buf, zigzag(int64(v)))
+}
+
+func (e *thriftEncoder) i64(id int16, v int64) {
+	e.field(id, thriftI64)
+	writeUvarint(&e.buf, zigzag(v))
+}
+
+func (e *thriftEncoder) str(id int16, s string) {
+	e.field(id, thriftBinary)
+	e.listStr(s)
+}
+
+// structField starts a struct field, ended with end
+func (e *thriftEncoder) structField(id int16) {
+	e.field(id, thriftStruct)
+	e.begin()
+}
+
+// list starts a list field of size elements of a type
+func (e *thriftEncoder) list(id int16, elem byte, size int) {
+	e.field(id, thriftList)
+	if size < 15 {
+		e.buf.WriteByte(byte(size)<<4 | elem)
+	} else {
+		e.buf.WriteByte(0xf0 | elem)
+		writeUvarint(&e.buf, uint64(size))
+	}
+}
+
+func (e *thriftEncoder) listI32(v int32) {
+	writeUvarint(&e.buf, zigzag(int64(v)))
+}
+
+func (e *thriftEncoder) listStr(s string) {
+	writeUvarint(&e.buf, uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
@@ -41,4 +41,31 @@ func FindSourceFiles(rootDir string) ([]string, []string, error) {
 	})
 
 	return goFiles, pythonFiles, err
-}
\ No newline at end of file
+}
+// FindDartFiles walks a directory tree and finds Dart source files, skipping tests,
+// generated code and build output
+func FindDartFiles(rootDir string) ([]string, error) {
+	var dartFiles []string
+
+	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if info.IsDir() {
+			if info.Name() == "build" || (strings.HasPrefix(info.Name(), ".") && path != rootDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := info.Name()
+		if strings.HasSuffix(name, ".dart") && !strings.HasSuffix(name, "_test.dart") &&
+			!strings.HasSuffix(name, ".g.dart") && !strings.HasSuffix(name, ".freezed.dart") {
+			dartFiles = append(dartFiles, path)
+		}
+		return nil
+	})
+
+	return dartFiles, err
+}
@@ -0,0 +1,149 @@
+// Package github posts the results of a check to the pull request of a GitHub Actions workflow.
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/internal/offline"
+)
+
+// DefaultAPIURL is the GitHub API used unless GITHUB_API_URL is set, as on GitHub Enterprise
+const DefaultAPIURL = "https://api.github.com"
+
+// PullRequest identifies a pull request of a repository
+type PullRequest struct {
+	Repository string // owner/name
+	Number     int
+}
+
+// PullRequestFromEnv returns the pull request of the GitHub Actions workflow run, read from
+// GITHUB_REPOSITORY and the event payload at GITHUB_EVENT_PATH
+func PullRequestFromEnv() (*PullRequest, error) {
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	eventPath := os.Getenv("GITHUB_EVENT_PATH")
+	if repository == "" || eventPath == "" {
+		return nil, fmt.Errorf("GITHUB_REPOSITORY and GITHUB_EVENT_PATH are not set, not running in GitHub Actions")
+	}
+
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event payload: %w", err)
+	}
+	var event struct {
+		Number      int `json:"number"`
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse event payload %s: %w", eventPath, err)
+	}
+
+	number := event.PullRequest.Number
+	if number == 0 {
+		number = event.Number
+	}
+	if number == 0 {
+		return nil, fmt.Errorf("the %s event is not a pull request event", os.Getenv("GITHUB_EVENT_NAME"))
+	}
+	return &PullRequest{Repository: repository, Number: number}, nil
+}
+
+// Client calls the issue comment API of GitHub, authorized with GH_TOKEN or GITHUB_TOKEN
+type Client struct {
+	apiURL string
+	http   *http.Client
+}
+
+// NewClient returns a client of the API at GITHUB_API_URL, or the public GitHub API
+func NewClient() *Client {
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{apiURL: strings.TrimSuffix(apiURL, "/"), http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type comment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// UpsertComment updates the comment of a pull request containing marker with body, or creates one
+// when there is none, so that every run of a workflow keeps a single comment up to date
+func (c *Client) UpsertComment(pr *PullRequest, marker, body string) error {
+	if err := offline.Check("posting pull request comments"); err != nil {
+		return err
+	}
+
+	for page := 1; ; page++ {
+		var comments []comment
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100&page=%d", pr.Repository, pr.Number, page)
+		if err := c.do(http.MethodGet, path, nil, &comments); err != nil {
+			return err
+		}
+		for _, existing := range comments {
+			if strings.Contains(existing.Body, marker) {
+				return c.do(http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", pr.Repository, existing.ID), comment{Body: body}, nil)
+			}
+		}
+		if len(comments) < 100 {
+			break
+		}
+	}
+	return c.do(http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", pr.Repository, pr.Number), comment{Body: body}, nil)
+}
+
+// do sends a request with a JSON body and decodes the JSON response into result, if not nil
+func (c *Client) do(method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	analysis.GetCredentials().Authorize(req)
+	// The workflow token also authorizes GitHub Enterprise, whose API host has no token of its own
+	if req.Header.Get("Authorization") == "" {
+		for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+			if token := os.Getenv(name); token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+				break
+			}
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
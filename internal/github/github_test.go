@@ -0,0 +1,81 @@
+package github_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/github"
+)
+
+var _ = Describe("GitHub", func() {
+	It("should read the pull request of the workflow event", func() {
+		event := filepath.Join(GinkgoT().TempDir(), "event.json")
+		Expect(os.WriteFile(event, []byte(`{"action":"synchronize","pull_request":{"number":42}}`), 0644)).To(Succeed())
+		GinkgoT().Setenv("GITHUB_REPOSITORY", "flanksource/arch-unit")
+		GinkgoT().Setenv("GITHUB_EVENT_PATH", event)
+
+		pr, err := github.PullRequestFromEnv()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pr).To(Equal(&github.PullRequest{Repository: "flanksource/arch-unit", Number: 42}))
+	})
+
+	It("should reject events that are not pull requests", func() {
+		event := filepath.Join(GinkgoT().TempDir(), "event.json")
+		Expect(os.WriteFile(event, []byte(`{"ref":"refs/heads/main"}`), 0644)).To(Succeed())
+		GinkgoT().Setenv("GITHUB_REPOSITORY", "flanksource/arch-unit")
+		GinkgoT().Setenv("GITHUB_EVENT_PATH", event)
+		GinkgoT().Setenv("GITHUB_EVENT_NAME", "push")
+
+		_, err := github.PullRequestFromEnv()
+		Expect(err).To(MatchError(ContainSubstring("push event is not a pull request event")))
+	})
+
+	Context("UpsertComment", func() {
+		var requests []string
+
+		serve := func(comments string) {
+			requests = nil
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				if r.Method == http.MethodGet {
+					_, _ = w.Write([]byte(comments))
+					return
+				}
+				var body map[string]string
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				Expect(body["body"]).To(Equal("<!-- arch-unit -->\nupdated"))
+				w.WriteHeader(http.StatusCreated)
+			}))
+			DeferCleanup(server.Close)
+			GinkgoT().Setenv("GITHUB_API_URL", server.URL)
+			GinkgoT().Setenv("GITHUB_TOKEN", "token")
+		}
+
+		pr := &github.PullRequest{Repository: "flanksource/arch-unit", Number: 42}
+
+		It("should update the existing comment", func() {
+			serve(`[{"id":1,"body":"LGTM"},{"id":7,"body":"<!-- arch-unit -->\nold"}]`)
+			Expect(github.NewClient().UpsertComment(pr, "<!-- arch-unit -->", "<!-- arch-unit -->\nupdated")).To(Succeed())
+			Expect(requests).To(Equal([]string{
+				"GET /repos/flanksource/arch-unit/issues/42/comments",
+				"PATCH /repos/flanksource/arch-unit/issues/comments/7",
+			}))
+		})
+
+		It("should create a comment when there is none", func() {
+			serve(`[{"id":1,"body":"LGTM"}]`)
+			Expect(github.NewClient().UpsertComment(pr, "<!-- arch-unit -->", "<!-- arch-unit -->\nupdated")).To(Succeed())
+			Expect(requests).To(Equal([]string{
+				"GET /repos/flanksource/arch-unit/issues/42/comments",
+				"POST /repos/flanksource/arch-unit/issues/42/comments",
+			}))
+		})
+	})
+})
@@ -0,0 +1,170 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar selects the locale of reports and messages, taking precedence over LC_ALL, LC_MESSAGES and LANG
+const EnvVar = "ARCH_UNIT_LOCALE"
+
+// DefaultLocale is used for messages missing from the selected catalog
+const DefaultLocale = "en"
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// Catalog maps message ids to fmt format strings
+type Catalog map[string]string
+
+var (
+	fallback = mustLoadEmbedded(DefaultLocale)
+	current  atomic.Pointer[localized]
+)
+
+type localized struct {
+	locale   string
+	messages Catalog
+}
+
+// SetLocale selects the catalog used by T. locale is a locale name such as "de" or "de_DE.UTF-8",
+// or the path of a YAML catalog; when empty it is read from ARCH_UNIT_LOCALE, then detected from
+// LC_ALL, LC_MESSAGES and LANG, falling back to English. Messages missing from the catalog are
+// shown in English.
+func SetLocale(locale string) error {
+	if locale == "" {
+		if env := os.Getenv(EnvVar); env != "" {
+			return SetLocale(env)
+		}
+		current.Store(&localized{locale: DefaultLocale, messages: fallback})
+		if detected := detectLocale(); detected != "" {
+			if messages, err := loadEmbedded(detected); err == nil {
+				current.Store(&localized{locale: detected, messages: messages})
+			}
+		}
+		return nil
+	}
+
+	if ext := strings.ToLower(filepath.Ext(locale)); ext == ".yaml" || ext == ".yml" {
+		messages, err := loadFile(locale)
+		if err != nil {
+			return err
+		}
+		current.Store(&localized{locale: strings.TrimSuffix(filepath.Base(locale), filepath.Ext(locale)), messages: messages})
+		return nil
+	}
+
+	name := normalize(locale)
+	messages, err := loadEmbedded(name)
+	if err != nil {
+		return fmt.Errorf("unsupported locale %q, available locales are %s or the path of a YAML catalog",
+			locale, strings.Join(Locales(), ", "))
+	}
+	current.Store(&localized{locale: name, messages: messages})
+	return nil
+}
+
+// Locale returns the selected locale
+func Locale() string {
+	if l := current.Load(); l != nil {
+		return l.locale
+	}
+	return DefaultLocale
+}
+
+// Locales returns the locales with a built-in catalog
+func Locales() []string {
+	entries, _ := locales.ReadDir("locales")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// T returns the message with the given id in the selected locale, formatted with args
+func T(id string, args ...interface{}) string {
+	message, ok := "", false
+	if l := current.Load(); l != nil {
+		message, ok = l.messages[id]
+	}
+	if !ok {
+		if message, ok = fallback[id]; !ok {
+			message = id
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// detectLocale returns the locale of the POSIX environment, unsupported locales fall back to English
+func detectLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			return normalize(value)
+		}
+	}
+	return ""
+}
+
+// normalize turns POSIX locale names such as de_DE.UTF-8 into the language of a catalog
+func normalize(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "c" || locale == "posix" {
+		return DefaultLocale
+	}
+	return locale
+}
+
+func loadEmbedded(locale string) (Catalog, error) {
+	data, err := locales.ReadFile("locales/" + locale + ".yaml")
+	if err != nil {
+		return nil, err
+	}
+	return parse(data)
+}
+
+func mustLoadEmbedded(locale string) Catalog {
+	messages, err := loadEmbedded(locale)
+	if err != nil {
+		panic(fmt.Sprintf("invalid %s message catalog: %v", locale, err))
+	}
+	return messages
+}
+
+func loadFile(file string) (Catalog, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message catalog: %w", err)
+	}
+	messages, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message catalog %s: %w", file, err)
+	}
+	return messages, nil
+}
+
+func parse(data []byte) (Catalog, error) {
+	var messages Catalog
+	if err := yaml.Unmarshal(data, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Message catalog", func() {
+	BeforeEach(func() {
+		for _, env := range []string{EnvVar, "LC_ALL", "LC_MESSAGES", "LANG"} {
+			GinkgoT().Setenv(env, "")
+		}
+		DeferCleanup(SetLocale, DefaultLocale)
+	})
+
+	It("should define every message in every built-in catalog", func() {
+		Expect(Locales()).To(ContainElements("de", "en", "es"))
+		for _, locale := range Locales() {
+			messages, err := loadEmbedded(locale)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys(messages)).To(ConsistOf(keys(fallback)), "catalog %s", locale)
+		}
+	})
+
+	It("should select a locale by POSIX name", func() {
+		Expect(SetLocale("de_DE.UTF-8")).To(Succeed())
+		Expect(Locale()).To(Equal("de"))
+		Expect(T("report.title")).To(Equal("Bericht über Architekturverstöße"))
+		Expect(T("summary.total_violations", 3)).To(Equal("Verstöße insgesamt: 3"))
+	})
+
+	It("should reject unknown locales", func() {
+		Expect(SetLocale("xx")).To(MatchError(ContainSubstring(`unsupported locale "xx"`)))
+	})
+
+	It("should detect the locale from the environment", func() {
+		GinkgoT().Setenv("LANG", "es_ES.UTF-8")
+		Expect(SetLocale("")).To(Succeed())
+		Expect(Locale()).To(Equal("es"))
+
+		GinkgoT().Setenv(EnvVar, "de")
+		Expect(SetLocale("")).To(Succeed())
+		Expect(Locale()).To(Equal("de"))
+	})
+
+	It("should fall back to English for unsupported environment locales", func() {
+		GinkgoT().Setenv("LANG", "fr_FR.UTF-8")
+		Expect(SetLocale("")).To(Succeed())
+		Expect(Locale()).To(Equal(DefaultLocale))
+		Expect(T("report.summary")).To(Equal("Summary"))
+	})
+
+	It("should load a catalog file and fall back to English for missing messages", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "fr.yaml")
+		Expect(os.WriteFile(path, []byte("report.title: Rapport des violations d'architecture\n"), 0o644)).To(Succeed())
+
+		Expect(SetLocale(path)).To(Succeed())
+		Expect(Locale()).To(Equal("fr"))
+		Expect(T("report.title")).To(Equal("Rapport des violations d'architecture"))
+		Expect(T("report.summary")).To(Equal("Summary"))
+		Expect(T("unknown.id")).To(Equal("unknown.id"))
+	})
+})
+
+func keys(catalog Catalog) []string {
+	var ids []string
+	for id := range catalog {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,141 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/flanksource/arch-unit/internal/capabilities"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+var (
+	defaults      *models.Config
+	defaultsMutex sync.RWMutex
+)
+
+// SetConfig registers the configuration used to look up limits for extractor helpers
+// that are spawned without access to the loaded arch-unit.yaml
+func SetConfig(config *models.Config) {
+	defaultsMutex.Lock()
+	defer defaultsMutex.Unlock()
+	defaults = config
+}
+
+// For returns the effective limits for a tool from the registered configuration
+func For(tool string) models.ResourceLimits {
+	defaultsMutex.RLock()
+	defer defaultsMutex.RUnlock()
+	return defaults.GetResourceLimits(tool, nil)
+}
+
+// Cmd is an exec.Cmd that runs under resource limits
+type Cmd struct {
+	*exec.Cmd
+	ctx    context.Context
+	cancel context.CancelFunc
+	tool   string
+	limits models.ResourceLimits
+}
+
+// Command prepares name to run under the given limits. Wall-clock timeouts are enforced via
+// the context, CPU and memory limits via ulimit where the POSIX shell of the platform supports them.
+// It returns a capabilities.MissingToolError when name is not installed.
+func Command(ctx context.Context, limits models.ResourceLimits, name string, args ...string) (*Cmd, error) {
+	if err := capabilities.Require(name); err != nil {
+		return nil, err
+	}
+	if err := limits.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid resource limits for %s: %w", name, err)
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if timeout, _ := limits.TimeoutDuration(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	cmd := &Cmd{ctx: ctx, cancel: cancel, tool: name, limits: limits}
+
+	ulimits, unsupported := ulimitArgs(limits, runtime.GOOS)
+	if len(unsupported) > 0 {
+		logger.Debugf("%s limits are not supported on %s and do not apply to %s", strings.Join(unsupported, " and "), runtime.GOOS, name)
+	}
+	if len(ulimits) == 0 {
+		cmd.Cmd = exec.CommandContext(ctx, name, args...)
+		return cmd, nil
+	}
+
+	// exec replaces the shell so the limits (and any timeout kill) apply to the tool itself
+	script := strings.Join(ulimits, " && ") + ` && exec "$0" "$@"`
+	cmd.Cmd = exec.CommandContext(ctx, "/bin/sh", append([]string{"-c", script, name}, args...)...)
+	return cmd, nil
+}
+
+// CommandFor prepares an extractor helper to run under the limits registered for it via SetConfig
+func CommandFor(ctx context.Context, name string, args ...string) (*Cmd, error) {
+	return Command(ctx, For(name), name, args...)
+}
+
+// ulimitArgs returns the ulimit invocations applying the limits on goos, and the limits goos
+// cannot apply: Windows has no POSIX shell, and the /bin/sh of macOS fails on ulimit -v, which
+// macOS does not enforce anyway
+func ulimitArgs(limits models.ResourceLimits, goos string) ([]string, []string) {
+	var ulimits, unsupported []string
+	if seconds, _ := limits.CPUSeconds(); seconds > 0 {
+		if goos == "windows" {
+			unsupported = append(unsupported, "cpu_time")
+		} else {
+			ulimits = append(ulimits, fmt.Sprintf("ulimit -t %d", seconds))
+		}
+	}
+	if bytes, _ := limits.MemoryBytes(); bytes > 0 {
+		if goos == "windows" || goos == "darwin" {
+			unsupported = append(unsupported, "memory")
+		} else {
+			// ulimit -v takes kilobytes
+			ulimits = append(ulimits, fmt.Sprintf("ulimit -v %d", (bytes+1023)/1024))
+		}
+	}
+	return ulimits, unsupported
+}
+
+// Run starts the command and waits for it to complete
+func (c *Cmd) Run() error {
+	defer c.cancel()
+	return c.explain(c.Cmd.Run())
+}
+
+// Output runs the command and returns its standard output
+func (c *Cmd) Output() ([]byte, error) {
+	defer c.cancel()
+	out, err := c.Cmd.Output()
+	return out, c.explain(err)
+}
+
+// CombinedOutput runs the command and returns its combined standard output and error
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	defer c.cancel()
+	out, err := c.Cmd.CombinedOutput()
+	return out, c.explain(err)
+}
+
+// explain annotates errors caused by a limit being exceeded, leaving other errors untouched
+func (c *Cmd) explain(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(c.ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s exceeded its timeout of %s: %w", c.tool, c.limits.Timeout, err)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && !exitErr.Exited() && (c.limits.CPUTime != "" || c.limits.Memory != "") {
+		return fmt.Errorf("%s was terminated, possibly for exceeding its resource limits (cpu_time=%s, memory=%s): %w",
+			c.tool, c.limits.CPUTime, c.limits.Memory, err)
+	}
+	return err
+}
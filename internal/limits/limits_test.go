@@ -0,0 +1,93 @@
+package limits
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Limits", func() {
+	Describe("ulimit arguments", func() {
+		limits := models.ResourceLimits{CPUTime: "1500ms", Memory: "1M"}
+
+		It("should apply CPU time in seconds and memory in kilobytes", func() {
+			ulimits, unsupported := ulimitArgs(limits, "linux")
+			Expect(ulimits).To(Equal([]string{"ulimit -t 2", "ulimit -v 1024"}))
+			Expect(unsupported).To(BeEmpty())
+		})
+
+		It("should skip the memory limit on macOS", func() {
+			ulimits, unsupported := ulimitArgs(limits, "darwin")
+			Expect(ulimits).To(Equal([]string{"ulimit -t 2"}))
+			Expect(unsupported).To(Equal([]string{"memory"}))
+		})
+
+		It("should skip every limit on Windows", func() {
+			ulimits, unsupported := ulimitArgs(limits, "windows")
+			Expect(ulimits).To(BeEmpty())
+			Expect(unsupported).To(Equal([]string{"cpu_time", "memory"}))
+		})
+
+		It("should not limit anything without limits", func() {
+			ulimits, unsupported := ulimitArgs(models.ResourceLimits{Timeout: "1m"}, "linux")
+			Expect(ulimits).To(BeEmpty())
+			Expect(unsupported).To(BeEmpty())
+		})
+	})
+
+	Describe("commands", func() {
+		BeforeEach(func() {
+			if runtime.GOOS == "windows" {
+				Skip("requires a POSIX shell")
+			}
+		})
+
+		It("should wrap the tool in a shell applying the limits", func() {
+			cmd, err := Command(context.Background(), models.ResourceLimits{CPUTime: "10s"}, "echo", "limited")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.Args).To(Equal([]string{"/bin/sh", "-c", `ulimit -t 10 && exec "$0" "$@"`, "echo", "limited"}))
+
+			out, err := cmd.Output()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal("limited\n"))
+		})
+
+		It("should run the tool directly without CPU or memory limits", func() {
+			cmd, err := Command(context.Background(), models.ResourceLimits{Timeout: "10s"}, "echo", "unlimited")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.Args).To(Equal([]string{"echo", "unlimited"}))
+		})
+
+		It("should refuse invalid limits", func() {
+			_, err := Command(context.Background(), models.ResourceLimits{Memory: "lots"}, "echo")
+			Expect(err).To(MatchError(ContainSubstring("invalid resource limits for echo")))
+		})
+
+		It("should kill the tool when it exceeds its timeout", func() {
+			cmd, err := Command(context.Background(), models.ResourceLimits{Timeout: "200ms", CPUTime: "10s"}, "sleep", "10")
+			Expect(err).NotTo(HaveOccurred())
+
+			start := time.Now()
+			err = cmd.Run()
+			Expect(err).To(MatchError(ContainSubstring("sleep exceeded its timeout of 200ms")))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		})
+
+		It("should explain tools terminated under CPU or memory limits", func() {
+			cmd, err := Command(context.Background(), models.ResourceLimits{CPUTime: "10s"}, "sh", "-c", "kill -KILL $$")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.Run()).To(MatchError(ContainSubstring("sh was terminated, possibly for exceeding its resource limits (cpu_time=10s, memory=)")))
+		})
+
+		It("should leave the errors of tools exiting on their own untouched", func() {
+			cmd, err := Command(context.Background(), models.ResourceLimits{CPUTime: "10s"}, "sh", "-c", "exit 3")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.Run()).To(MatchError("exit status 3"))
+		})
+	})
+})
@@ -0,0 +1,204 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ProviderLocal embeds text without a model, by hashing its words. It works offline and needs no
+// setup, but only matches words shared by the query and the code.
+const ProviderLocal = "local"
+
+// Environment variables selecting the embedding provider and model, separate from the completion
+// provider as Anthropic has no embeddings API
+const (
+	EnvEmbeddingProvider = "ARCH_UNIT_EMBEDDING_PROVIDER"
+	EnvEmbeddingModel    = "ARCH_UNIT_EMBEDDING_MODEL"
+)
+
+var defaultEmbeddingModels = map[string]string{
+	ProviderOpenAI: "text-embedding-3-small",
+	ProviderOllama: "nomic-embed-text",
+	ProviderLocal:  "hashed-words",
+}
+
+// Embedder converts texts to vectors whose cosine similarity measures how related they are
+type Embedder interface {
+	// Name returns the provider and model, e.g. openai/text-embedding-3-small
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder returns the embedder of config. Without a provider, OpenAI is used when
+// OPENAI_API_KEY is set, then Ollama when OLLAMA_HOST is set, and otherwise the local embedder.
+func NewEmbedder(config Config) (Embedder, error) {
+	if config.Provider == "" {
+		config.Provider = os.Getenv(EnvEmbeddingProvider)
+	}
+	if config.Provider == "" {
+		switch {
+		case os.Getenv("OPENAI_API_KEY") != "":
+			config.Provider = ProviderOpenAI
+		case os.Getenv("OLLAMA_HOST") != "":
+			config.Provider = ProviderOllama
+		default:
+			config.Provider = ProviderLocal
+		}
+	}
+	config.Provider = strings.ToLower(config.Provider)
+	if config.Model == "" {
+		config.Model = os.Getenv(EnvEmbeddingModel)
+	}
+	if config.Model == "" {
+		config.Model = defaultEmbeddingModels[config.Provider]
+	}
+
+	switch config.Provider {
+	case ProviderLocal:
+		return localEmbedder{}, nil
+	case ProviderAnthropic:
+		return nil, fmt.Errorf("%s has no embeddings API, use %s, %s or %s", ProviderAnthropic, ProviderOpenAI, ProviderOllama, ProviderLocal)
+	case ProviderOpenAI, ProviderOllama:
+		client, err := newClient(config)
+		if err != nil {
+			return nil, err
+		}
+		var embedder Embedder = &ollama{client: client, model: config.Model}
+		if config.Provider == ProviderOpenAI {
+			embedder = &openAI{client: client, model: config.Model}
+		}
+		if limiter := newLimiter(config); limiter != nil {
+			return &limitedEmbedder{Embedder: embedder, limiter: limiter}, nil
+		}
+		return embedder, nil
+	}
+	return nil, fmt.Errorf("unknown embedding provider '%s', expected %s, %s or %s", config.Provider, ProviderOpenAI, ProviderOllama, ProviderLocal)
+}
+
+func (p *openAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	request := map[string]interface{}{"model": p.model, "input": texts}
+	headers := map[string]string{}
+	if p.client.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.client.apiKey
+	}
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := p.client.post(ctx, "/embeddings", headers, request, &response); err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, len(texts))
+	for _, data := range response.Data {
+		if data.Index >= 0 && data.Index < len(vectors) {
+			vectors[data.Index] = data.Embedding
+		}
+	}
+	return vectors, checkVectors(p.Name(), vectors)
+}
+
+func (p *ollama) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	request := map[string]interface{}{"model": p.model, "input": texts}
+	var response struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := p.client.post(ctx, "/api/embed", nil, request, &response); err != nil {
+		return nil, fmt.Errorf("%w (is Ollama running with the model %s pulled?)", err, p.model)
+	}
+	if len(response.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("%s returned %d embeddings for %d texts", p.Name(), len(response.Embeddings), len(texts))
+	}
+	return response.Embeddings, checkVectors(p.Name(), response.Embeddings)
+}
+
+func checkVectors(name string, vectors [][]float32) error {
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return fmt.Errorf("%s returned no embedding for text %d", name, i)
+		}
+	}
+	return nil
+}
+
+// localDimensions is the size of the vectors of the local embedder
+const localDimensions = 512
+
+// localEmbedder hashes the words of a text into a vector, splitting identifiers such as
+// HashPassword or hash_password into their words
+type localEmbedder struct{}
+
+func (localEmbedder) Name() string {
+	return ProviderLocal + "/" + defaultEmbeddingModels[ProviderLocal]
+}
+
+func (localEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, localDimensions)
+		for _, word := range words(text) {
+			hash := fnv.New32a()
+			_, _ = hash.Write([]byte(word))
+			vector[hash.Sum32()%localDimensions]++
+		}
+		var norm float64
+		for _, value := range vector {
+			norm += float64(value * value)
+		}
+		if norm > 0 {
+			for j := range vector {
+				vector[j] /= float32(math.Sqrt(norm))
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// stopWords are left out of local embeddings as they match everything
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "do": true, "does": true, "for": true, "from": true,
+	"how": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true, "the": true,
+	"to": true, "we": true, "what": true, "where": true, "which": true, "who": true, "with": true,
+}
+
+// words returns the lower case words of text without stop words, with identifiers split at case
+// changes, digits and punctuation, and plurals reduced to their singular
+func words(text string) []string {
+	var result []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			w := strings.ToLower(string(word))
+			if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+				w = strings.TrimSuffix(w, "s")
+			}
+			if !stopWords[w] {
+				result = append(result, w)
+			}
+			word = word[:0]
+		}
+	}
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r):
+			flush()
+		case unicode.IsUpper(r) && len(word) > 0 && (unicode.IsLower(word[len(word)-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			// hashPassword and HTTPServer split before Password and Server
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return result
+}
@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrTokenBudget is returned by providers whose token budget is spent
+var ErrTokenBudget = errors.New("token budget exhausted")
+
+// EstimateTokens approximates the tokens of text at 4 characters per token, as providers count
+// tokens differently and not all of them report usage
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// limiter enforces the request rate and token budget of a provider
+type limiter struct {
+	rate   *rate.Limiter
+	budget int
+	used   int
+	mutex  sync.Mutex
+}
+
+// newLimiter returns the limiter of config, nil when it sets no limit
+func newLimiter(config Config) *limiter {
+	if config.RequestsPerMinute <= 0 && config.TokenBudget <= 0 {
+		return nil
+	}
+	l := &limiter{budget: config.TokenBudget}
+	if config.RequestsPerMinute > 0 {
+		l.rate = rate.NewLimiter(rate.Every(time.Minute/time.Duration(config.RequestsPerMinute)), 1)
+	}
+	return l
+}
+
+// acquire reserves the tokens of a request within the budget and waits for the rate limit
+func (l *limiter) acquire(ctx context.Context, tokens int) error {
+	l.mutex.Lock()
+	if l.budget > 0 && l.used+tokens > l.budget {
+		used := l.used
+		l.mutex.Unlock()
+		return fmt.Errorf("%w: %d of %d tokens used", ErrTokenBudget, used, l.budget)
+	}
+	l.used += tokens
+	l.mutex.Unlock()
+
+	if l.rate == nil {
+		return nil
+	}
+	return l.rate.Wait(ctx)
+}
+
+// spend counts the tokens of a response against the budget
+func (l *limiter) spend(tokens int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.used += tokens
+}
+
+// limitedProvider is a provider limited by the requests_per_minute and token_budget of its config
+type limitedProvider struct {
+	Provider
+	limiter *limiter
+}
+
+func (p *limitedProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	if err := p.limiter.acquire(ctx, EstimateTokens(prompt)); err != nil {
+		return "", err
+	}
+	completion, err := p.Provider.Complete(ctx, prompt)
+	p.limiter.spend(EstimateTokens(completion))
+	return completion, err
+}
+
+// limitedEmbedder is an embedder limited like limitedProvider
+type limitedEmbedder struct {
+	Embedder
+	limiter *limiter
+}
+
+func (e *limitedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	tokens := 0
+	for _, text := range texts {
+		tokens += EstimateTokens(text)
+	}
+	if err := e.limiter.acquire(ctx, tokens); err != nil {
+		return nil, err
+	}
+	return e.Embedder.Embed(ctx, texts)
+}
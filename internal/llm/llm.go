@@ -0,0 +1,293 @@
+// Package llm completes prompts with the large language models of OpenAI compatible APIs,
+// Anthropic, Amazon Bedrock and Ollama, for the AI features of arch-unit.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/internal/sigv4"
+)
+
+// Providers
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+	ProviderBedrock   = "bedrock"
+)
+
+// Environment variables configuring the provider, the API keys and base URLs of each provider are
+// read from their usual variables: OPENAI_API_KEY, OPENAI_BASE_URL, ANTHROPIC_API_KEY,
+// ANTHROPIC_BASE_URL and OLLAMA_HOST, and those of Bedrock from AWS_REGION,
+// AWS_BEARER_TOKEN_BEDROCK, or AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+const (
+	EnvProvider = "ARCH_UNIT_LLM_PROVIDER"
+	EnvModel    = "ARCH_UNIT_LLM_MODEL"
+)
+
+// defaultModels are small, inexpensive models of each provider
+var defaultModels = map[string]string{
+	ProviderOpenAI:    "gpt-4o-mini",
+	ProviderAnthropic: "claude-3-5-haiku-latest",
+	ProviderOllama:    "llama3.2",
+	ProviderBedrock:   "amazon.nova-micro-v1:0",
+}
+
+// defaultMaxTokens bounds completions when no maximum is configured
+const defaultMaxTokens = 1024
+
+// Provider completes prompts with a model
+type Provider interface {
+	// Name returns the provider and model, e.g. openai/gpt-4o-mini
+	Name() string
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// Config selects the provider and model, empty fields are read from the environment
+type Config struct {
+	Provider string
+	Model    string
+	BaseURL  string
+	APIKey   string
+	Region   string // AWS region of Bedrock
+	// Limits of the provider, 0 for no limit
+	RequestsPerMinute int
+	TokenBudget       int // Estimated tokens of all prompts and completions, see EstimateTokens
+	MaxTokens         int // Tokens of each completion, 1024 when 0
+}
+
+// WithDefaults returns config with its empty fields set from defaults, such as the ai section of
+// arch-unit.yaml. The provider and model of the environment take precedence over those of
+// defaults, whose model, endpoint and credentials only apply to the provider they configure.
+func (c Config) WithDefaults(defaults Config) Config {
+	return c.withDefaults(defaults, EnvProvider, EnvModel)
+}
+
+// WithEmbeddingDefaults is WithDefaults for the config of an embedder
+func (c Config) WithEmbeddingDefaults(defaults Config) Config {
+	return c.withDefaults(defaults, EnvEmbeddingProvider, EnvEmbeddingModel)
+}
+
+func (c Config) withDefaults(defaults Config, envProvider, envModel string) Config {
+	if c.Provider == "" {
+		c.Provider = firstNonEmpty(os.Getenv(envProvider), defaults.Provider)
+	}
+	if defaults.Provider == "" || strings.EqualFold(c.Provider, defaults.Provider) {
+		if c.Model == "" {
+			c.Model = firstNonEmpty(os.Getenv(envModel), defaults.Model)
+		}
+		c.BaseURL = firstNonEmpty(c.BaseURL, defaults.BaseURL)
+		c.APIKey = firstNonEmpty(c.APIKey, defaults.APIKey)
+		c.Region = firstNonEmpty(c.Region, defaults.Region)
+	}
+	if c.RequestsPerMinute == 0 {
+		c.RequestsPerMinute = defaults.RequestsPerMinute
+	}
+	if c.TokenBudget == 0 {
+		c.TokenBudget = defaults.TokenBudget
+	}
+	if c.MaxTokens == 0 {
+		c.MaxTokens = defaults.MaxTokens
+	}
+	return c
+}
+
+// New returns the provider of config. Without a provider, Anthropic is used when ANTHROPIC_API_KEY
+// is set, then OpenAI when OPENAI_API_KEY is set, and otherwise a local Ollama. Only models served
+// on the local machine, by Ollama or an OpenAI compatible server, work in offline mode.
+func New(config Config) (Provider, error) {
+	if config.Provider == "" {
+		config.Provider = os.Getenv(EnvProvider)
+	}
+	if config.Provider == "" {
+		switch {
+		case os.Getenv("ANTHROPIC_API_KEY") != "":
+			config.Provider = ProviderAnthropic
+		case os.Getenv("OPENAI_API_KEY") != "":
+			config.Provider = ProviderOpenAI
+		default:
+			config.Provider = ProviderOllama
+		}
+	}
+	config.Provider = strings.ToLower(config.Provider)
+	if config.Model == "" {
+		config.Model = os.Getenv(EnvModel)
+	}
+	if config.Model == "" {
+		config.Model = defaultModels[config.Provider]
+	}
+
+	if config.MaxTokens == 0 {
+		config.MaxTokens = defaultMaxTokens
+	}
+
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+	var provider Provider
+	switch config.Provider {
+	case ProviderOpenAI:
+		provider = &openAI{client: client, model: config.Model, maxTokens: config.MaxTokens}
+	case ProviderAnthropic:
+		provider = &anthropic{client: client, model: config.Model, maxTokens: config.MaxTokens}
+	case ProviderOllama:
+		provider = &ollama{client: client, model: config.Model, maxTokens: config.MaxTokens}
+	case ProviderBedrock:
+		provider = &bedrock{client: client, model: config.Model, maxTokens: config.MaxTokens}
+	default:
+		return nil, fmt.Errorf("unknown LLM provider '%s', expected %s, %s, %s or %s", config.Provider,
+			ProviderOpenAI, ProviderAnthropic, ProviderBedrock, ProviderOllama)
+	}
+	if limiter := newLimiter(config); limiter != nil {
+		return &limitedProvider{Provider: provider, limiter: limiter}, nil
+	}
+	return provider, nil
+}
+
+// newClient returns a client of the API of config.Provider, with the base URL and API key of the
+// config or the environment
+func newClient(config Config) (*client, error) {
+	client := &client{http: &http.Client{Timeout: 2 * time.Minute}}
+	switch config.Provider {
+	case ProviderOpenAI:
+		client.baseURL = firstNonEmpty(config.BaseURL, os.Getenv("OPENAI_BASE_URL"), "https://api.openai.com/v1")
+		client.apiKey = firstNonEmpty(config.APIKey, os.Getenv("OPENAI_API_KEY"))
+		if client.apiKey == "" && !isLocal(client.baseURL) {
+			return nil, fmt.Errorf("OPENAI_API_KEY is not set")
+		}
+	case ProviderAnthropic:
+		client.baseURL = firstNonEmpty(config.BaseURL, os.Getenv("ANTHROPIC_BASE_URL"), "https://api.anthropic.com")
+		client.apiKey = firstNonEmpty(config.APIKey, os.Getenv("ANTHROPIC_API_KEY"))
+		if client.apiKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY is not set")
+		}
+	case ProviderOllama:
+		client.baseURL = firstNonEmpty(config.BaseURL, os.Getenv("OLLAMA_HOST"), "http://localhost:11434")
+		if !strings.Contains(client.baseURL, "://") {
+			client.baseURL = "http://" + client.baseURL
+		}
+	case ProviderBedrock:
+		region := firstNonEmpty(config.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+		// Bedrock API keys are bearer tokens, other credentials sign each request
+		client.apiKey = firstNonEmpty(config.APIKey, os.Getenv("AWS_BEARER_TOKEN_BEDROCK"))
+		if client.apiKey == "" {
+			signer, err := sigv4.NewSigner(context.Background(), config.Region)
+			if err != nil {
+				return nil, fmt.Errorf("AWS_BEARER_TOKEN_BEDROCK is not set and %w", err)
+			}
+			region = signer.Region
+			client.sign = func(req *http.Request, body []byte) error {
+				return signer.Sign(req, sigv4.HashPayload(body), "bedrock", time.Now())
+			}
+		}
+		client.baseURL = firstNonEmpty(config.BaseURL, "https://bedrock-runtime."+region+".amazonaws.com")
+	default:
+		return client, nil
+	}
+	return client, checkOffline(client.baseURL)
+}
+
+// checkOffline fails in offline mode unless the API runs on the local machine
+func checkOffline(baseURL string) error {
+	if isLocal(baseURL) {
+		return nil
+	}
+	return offline.Check("AI features with a remote model")
+}
+
+func isLocal(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// client posts JSON to the API of a provider
+type client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+	sign    func(req *http.Request, body []byte) error // Signs requests of providers without API keys
+}
+
+// maxRetries is the number of times a request is retried when the provider is rate limiting
+const maxRetries = 3
+
+// post sends body as JSON to the path of the API and decodes the JSON response into result.
+// Requests refused by rate limits of the provider are retried after the delay it asks for.
+func (c *client) post(ctx context.Context, path string, headers map[string]string, body, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.baseURL, "/")+path, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		if c.sign != nil {
+			if err := c.sign(req, data); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return fmt.Errorf("POST %s: %w", path, err)
+		}
+		if (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) && attempt < maxRetries {
+			delay := retryDelay(resp.Header.Get("Retry-After"), attempt)
+			_ = resp.Body.Close()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			continue
+		}
+		if resp.StatusCode >= 300 {
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			_ = resp.Body.Close()
+			return fmt.Errorf("POST %s: %s: %s", path, resp.Status, strings.TrimSpace(string(message)))
+		}
+		err = json.NewDecoder(resp.Body).Decode(result)
+		_ = resp.Body.Close()
+		return err
+	}
+}
+
+// retryDelay returns the delay of a Retry-After header in seconds, or backs off exponentially
+// from one second
+func retryDelay(retryAfter string, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Second << attempt
+}
@@ -0,0 +1,218 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/llm"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Providers", func() {
+	var requests []map[string]interface{}
+	var headers []http.Header
+
+	serve := func(path string, response interface{}) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal(path))
+			var body map[string]interface{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			requests = append(requests, body)
+			headers = append(headers, r.Header)
+			Expect(json.NewEncoder(w).Encode(response)).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+		return server.URL
+	}
+
+	BeforeEach(func() {
+		requests, headers = nil, nil
+		for _, name := range []string{llm.EnvProvider, llm.EnvModel, "ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL", "OPENAI_API_KEY", "OPENAI_BASE_URL", "OLLAMA_HOST",
+			"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_BEARER_TOKEN_BEDROCK", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+			"AWS_PROFILE", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"} {
+			GinkgoT().Setenv(name, "")
+		}
+		// The AWS credentials of the machine are never used
+		GinkgoT().Setenv("AWS_CONFIG_FILE", filepath.Join(GinkgoT().TempDir(), "config"))
+		GinkgoT().Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(GinkgoT().TempDir(), "credentials"))
+		GinkgoT().Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	})
+
+	It("should complete prompts with OpenAI compatible APIs", func() {
+		url := serve("/chat/completions", map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": " Hashes passwords. "}}},
+		})
+		provider, err := llm.New(llm.Config{Provider: llm.ProviderOpenAI, BaseURL: url, APIKey: "key"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provider.Name()).To(Equal("openai/gpt-4o-mini"))
+
+		Expect(provider.Complete(context.Background(), "Summarize")).To(Equal("Hashes passwords."))
+		Expect(requests[0]["model"]).To(Equal("gpt-4o-mini"))
+		Expect(headers[0].Get("Authorization")).To(Equal("Bearer key"))
+	})
+
+	It("should complete prompts with Anthropic", func() {
+		GinkgoT().Setenv("ANTHROPIC_API_KEY", "key")
+		GinkgoT().Setenv("ANTHROPIC_BASE_URL", serve("/v1/messages", map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": "Hashes passwords."}},
+		}))
+		// Anthropic is the default provider when its API key is set
+		provider, err := llm.New(llm.Config{Model: "claude-test"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provider.Name()).To(Equal("anthropic/claude-test"))
+
+		Expect(provider.Complete(context.Background(), "Summarize")).To(Equal("Hashes passwords."))
+		Expect(headers[0].Get("x-api-key")).To(Equal("key"))
+		Expect(headers[0].Get("anthropic-version")).ToNot(BeEmpty())
+	})
+
+	It("should complete prompts with Ollama", func() {
+		GinkgoT().Setenv("OLLAMA_HOST", serve("/api/generate", map[string]interface{}{"response": "Hashes passwords."}))
+		provider, err := llm.New(llm.Config{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provider.Name()).To(Equal("ollama/llama3.2"))
+
+		Expect(provider.Complete(context.Background(), "Summarize")).To(Equal("Hashes passwords."))
+		Expect(requests[0]["stream"]).To(BeFalse())
+	})
+
+	It("should complete prompts with Bedrock", func() {
+		url := serve("/model/amazon.nova-micro-v1:0/converse", map[string]interface{}{
+			"output": map[string]interface{}{"message": map[string]interface{}{
+				"role": "assistant", "content": []map[string]string{{"text": "Hashes passwords."}},
+			}},
+		})
+		GinkgoT().Setenv("AWS_BEARER_TOKEN_BEDROCK", "token")
+		provider, err := llm.New(llm.Config{Provider: llm.ProviderBedrock, BaseURL: url, MaxTokens: 200})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provider.Name()).To(Equal("bedrock/amazon.nova-micro-v1:0"))
+
+		Expect(provider.Complete(context.Background(), "Summarize")).To(Equal("Hashes passwords."))
+		Expect(headers[0].Get("Authorization")).To(Equal("Bearer token"))
+		Expect(requests[0]["inferenceConfig"]).To(HaveKeyWithValue("maxTokens", BeNumerically("==", 200)))
+
+		// Without an API key requests are signed with the AWS credentials
+		GinkgoT().Setenv("AWS_BEARER_TOKEN_BEDROCK", "")
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "AKID")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		GinkgoT().Setenv("AWS_SESSION_TOKEN", "session")
+		provider, err = llm.New(llm.Config{Provider: llm.ProviderBedrock, BaseURL: url, Region: "eu-west-1"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provider.Complete(context.Background(), "Summarize")).To(Equal("Hashes passwords."))
+		Expect(headers[1].Get("Authorization")).To(HavePrefix("AWS4-HMAC-SHA256 Credential=AKID/"))
+		Expect(headers[1].Get("Authorization")).To(ContainSubstring("/eu-west-1/bedrock/aws4_request"))
+		Expect(headers[1].Get("X-Amz-Security-Token")).To(Equal("session"))
+	})
+
+	It("should retry requests refused by rate limits", func() {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{"response": "Hashes passwords."})).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+
+		provider, err := llm.New(llm.Config{Provider: llm.ProviderOllama, BaseURL: server.URL})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provider.Complete(context.Background(), "Summarize")).To(Equal("Hashes passwords."))
+		Expect(attempts).To(Equal(2))
+	})
+
+	It("should stop once the token budget is spent", func() {
+		url := serve("/api/generate", map[string]interface{}{"response": "Hashes passwords."})
+		provider, err := llm.New(llm.Config{Provider: llm.ProviderOllama, BaseURL: url, TokenBudget: 15, RequestsPerMinute: 600})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(provider.Name()).To(Equal("ollama/llama3.2"))
+
+		_, err = provider.Complete(context.Background(), "Summarize the login method")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = provider.Complete(context.Background(), "Summarize the logout method")
+		Expect(errors.Is(err, llm.ErrTokenBudget)).To(BeTrue())
+		Expect(requests).To(HaveLen(1))
+	})
+
+	It("should prefer flags and the environment to the defaults of arch-unit.yaml", func() {
+		defaults := llm.Config{Provider: llm.ProviderOllama, Model: "qwen2.5-coder", BaseURL: "http://gpu:11434", TokenBudget: 1000}
+
+		config := llm.Config{}.WithDefaults(defaults)
+		Expect(config).To(Equal(defaults))
+
+		// The model and endpoint of another provider do not apply
+		GinkgoT().Setenv(llm.EnvProvider, llm.ProviderOpenAI)
+		config = llm.Config{}.WithDefaults(defaults)
+		Expect(config.Provider).To(Equal(llm.ProviderOpenAI))
+		Expect(config.Model).To(BeEmpty())
+		Expect(config.BaseURL).To(BeEmpty())
+		Expect(config.TokenBudget).To(Equal(1000))
+
+		config = llm.Config{Provider: llm.ProviderOllama, Model: "llama3.2"}.WithDefaults(defaults)
+		Expect(config.Model).To(Equal("llama3.2"))
+		Expect(config.BaseURL).To(Equal("http://gpu:11434"))
+	})
+
+	It("should reject unknown providers and missing API keys", func() {
+		_, err := llm.New(llm.Config{Provider: "unknown"})
+		Expect(err).To(MatchError(ContainSubstring("unknown LLM provider")))
+
+		_, err = llm.New(llm.Config{Provider: llm.ProviderAnthropic})
+		Expect(err).To(MatchError(ContainSubstring("ANTHROPIC_API_KEY")))
+
+		_, err = llm.New(llm.Config{Provider: llm.ProviderBedrock})
+		Expect(err).To(MatchError(ContainSubstring("no AWS credentials found")))
+	})
+})
+
+var _ = Describe("Embedders", func() {
+	BeforeEach(func() {
+		for _, name := range []string{llm.EnvEmbeddingProvider, llm.EnvEmbeddingModel, "OPENAI_API_KEY", "OPENAI_BASE_URL", "OLLAMA_HOST"} {
+			GinkgoT().Setenv(name, "")
+		}
+	})
+
+	It("should embed texts with OpenAI compatible APIs in order", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/embeddings"))
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"index": 1, "embedding": []float32{0, 1}},
+					{"index": 0, "embedding": []float32{1, 0}},
+				},
+			})).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+
+		embedder, err := llm.NewEmbedder(llm.Config{Provider: llm.ProviderOpenAI, BaseURL: server.URL, APIKey: "key"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(embedder.Name()).To(Equal("openai/text-embedding-3-small"))
+		Expect(embedder.Embed(context.Background(), []string{"first", "second"})).To(Equal([][]float32{{1, 0}, {0, 1}}))
+	})
+
+	It("should match identifiers to words locally", func() {
+		embedder, err := llm.NewEmbedder(llm.Config{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(embedder.Name()).To(HavePrefix(llm.ProviderLocal))
+
+		vectors, err := embedder.Embed(context.Background(), []string{"where do we hash passwords", "method auth.HashPassword", "method mail.SendEmail"})
+		Expect(err).ToNot(HaveOccurred())
+		query := models.Vector(vectors[0])
+		Expect(query.Cosine(vectors[1])).To(BeNumerically(">", 0.5))
+		Expect(query.Cosine(vectors[2])).To(BeNumerically("<", 0.1))
+	})
+
+	It("should reject Anthropic, which has no embeddings API", func() {
+		_, err := llm.NewEmbedder(llm.Config{Provider: llm.ProviderAnthropic})
+		Expect(err).To(MatchError(ContainSubstring("no embeddings API")))
+	})
+})
@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/sigv4"
+)
+
+// temperature keeps completions consistent between runs
+const temperature = 0.1
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAI completes prompts with the chat completions API of OpenAI and compatible servers, such as
+// vLLM, LM Studio or llama.cpp
+type openAI struct {
+	client    *client
+	model     string
+	maxTokens int
+}
+
+func (p *openAI) Name() string {
+	return ProviderOpenAI + "/" + p.model
+}
+
+func (p *openAI) Complete(ctx context.Context, prompt string) (string, error) {
+	request := map[string]interface{}{
+		"model":       p.model,
+		"messages":    []message{{Role: "user", Content: prompt}},
+		"temperature": temperature,
+		"max_tokens":  p.maxTokens,
+	}
+	headers := map[string]string{}
+	if p.client.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.client.apiKey
+	}
+	var response struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+	}
+	if err := p.client.post(ctx, "/chat/completions", headers, request, &response); err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("%s returned no completion", p.Name())
+	}
+	return strings.TrimSpace(response.Choices[0].Message.Content), nil
+}
+
+// anthropic completes prompts with the messages API of Anthropic
+type anthropic struct {
+	client    *client
+	model     string
+	maxTokens int
+}
+
+// anthropicVersion is the version of the messages API
+const anthropicVersion = "2023-06-01"
+
+func (p *anthropic) Name() string {
+	return ProviderAnthropic + "/" + p.model
+}
+
+func (p *anthropic) Complete(ctx context.Context, prompt string) (string, error) {
+	request := map[string]interface{}{
+		"model":       p.model,
+		"max_tokens":  p.maxTokens,
+		"messages":    []message{{Role: "user", Content: prompt}},
+		"temperature": temperature,
+	}
+	headers := map[string]string{"x-api-key": p.client.apiKey, "anthropic-version": anthropicVersion}
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := p.client.post(ctx, "/v1/messages", headers, request, &response); err != nil {
+		return "", err
+	}
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("%s returned no completion", p.Name())
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+// ollama completes prompts with the generate API of a local Ollama
+type ollama struct {
+	client    *client
+	model     string
+	maxTokens int
+}
+
+func (p *ollama) Name() string {
+	return ProviderOllama + "/" + p.model
+}
+
+func (p *ollama) Complete(ctx context.Context, prompt string) (string, error) {
+	request := map[string]interface{}{
+		"model":   p.model,
+		"prompt":  prompt,
+		"stream":  false,
+		"options": map[string]interface{}{"temperature": temperature, "num_predict": p.maxTokens},
+	}
+	var response struct {
+		Response string `json:"response"`
+	}
+	if err := p.client.post(ctx, "/api/generate", nil, request, &response); err != nil {
+		return "", fmt.Errorf("%w (is Ollama running with the model %s pulled?)", err, p.model)
+	}
+	return strings.TrimSpace(response.Response), nil
+}
+
+// bedrock completes prompts with the Converse API of Amazon Bedrock, which serves the models of
+// Amazon, Anthropic, Meta and others with AWS credentials
+type bedrock struct {
+	client    *client
+	model     string
+	maxTokens int
+}
+
+func (p *bedrock) Name() string {
+	return ProviderBedrock + "/" + p.model
+}
+
+func (p *bedrock) Complete(ctx context.Context, prompt string) (string, error) {
+	type content struct {
+		Text string `json:"text,omitempty"`
+	}
+	type bedrockMessage struct {
+		Role    string    `json:"role"`
+		Content []content `json:"content"`
+	}
+	request := map[string]interface{}{
+		"messages":        []bedrockMessage{{Role: "user", Content: []content{{Text: prompt}}}},
+		"inferenceConfig": map[string]interface{}{"maxTokens": p.maxTokens, "temperature": temperature},
+	}
+	headers := map[string]string{}
+	if p.client.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.client.apiKey
+	}
+	var response struct {
+		Output struct {
+			Message bedrockMessage `json:"message"`
+		} `json:"output"`
+	}
+	if err := p.client.post(ctx, "/model/"+sigv4.Escape(p.model)+"/converse", headers, request, &response); err != nil {
+		return "", err
+	}
+	var text strings.Builder
+	for _, block := range response.Output.Message.Content {
+		text.WriteString(block.Text)
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("%s returned no completion", p.Name())
+	}
+	return strings.TrimSpace(text.String()), nil
+}
@@ -0,0 +1,254 @@
+package manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// FormatVersion is the version of the manifest file format
+const FormatVersion = "1"
+
+// Manifest records everything that went into an analysis run, so a results bundle
+// can later be matched against the exact source tree and tooling that produced it
+type Manifest struct {
+	FormatVersion string            `json:"format_version"`
+	Tool          ToolInfo          `json:"tool"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Root          string            `json:"root"`
+	Configs       map[string]string `json:"configs"`           // Relative path -> sha256
+	Files         map[string]string `json:"files"`             // Relative path -> sha256
+	Linters       map[string]string `json:"linters,omitempty"` // Linter name -> version
+	Results       *ResultsDigest    `json:"results,omitempty"`
+}
+
+// ToolInfo identifies the arch-unit build that produced a manifest
+type ToolInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+}
+
+// ResultsDigest is the checksum of the results file written alongside the manifest
+type ResultsDigest struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// Options configures manifest generation
+type Options struct {
+	Tool        ToolInfo
+	Linters     []string // Names of the linters that ran
+	ResultsFile string   // Optional results file to checksum
+	Skip        []string // Files to leave out of the manifest, e.g. the manifest itself
+	Excludes    []string // Additional exclusion patterns
+}
+
+// configFileNames are files whose contents change how an analysis behaves
+var configFileNames = map[string]bool{
+	config.ConfigFileName: true,
+	".ARCHUNIT":           true,
+}
+
+func init() {
+	for _, patterns := range config.LinterConfigPatterns {
+		for _, name := range patterns {
+			configFileNames[name] = true
+		}
+	}
+}
+
+// Generate walks root and builds a manifest of configuration and source file hashes
+func Generate(root string, opts Options) (*Manifest, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve root: %w", err)
+	}
+
+	m := &Manifest{
+		FormatVersion: FormatVersion,
+		Tool:          opts.Tool,
+		CreatedAt:     time.Now().UTC(),
+		Root:          absRoot,
+		Linters:       make(map[string]string),
+	}
+
+	m.Configs, m.Files, err = hashTree(absRoot, opts.Excludes, append(opts.Skip, opts.ResultsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range opts.Linters {
+		m.Linters[name] = linterVersion(name, opts.Tool.Version)
+	}
+
+	if opts.ResultsFile != "" {
+		sum, err := hashFile(opts.ResultsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash results file: %w", err)
+		}
+		m.Results = &ResultsDigest{File: filepath.Base(opts.ResultsFile), SHA256: sum}
+	}
+
+	return m, nil
+}
+
+// Load reads a manifest from disk
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported manifest format version %q, expected %q", m.FormatVersion, FormatVersion)
+	}
+	return &m, nil
+}
+
+// Write saves the manifest as indented JSON
+func (m *Manifest) Write(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// hashTree returns the hashes of config files and source files below root
+func hashTree(root string, extraExcludes []string, skipFiles []string) (map[string]string, map[string]string, error) {
+	configs := make(map[string]string)
+	files := make(map[string]string)
+	excludes := append(models.GetBuiltinExcludePatterns(), extraExcludes...)
+
+	skip := make(map[string]bool)
+	for _, file := range skipFiles {
+		if file == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(file); err == nil {
+			skip[abs] = true
+		}
+	}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if isExcluded(rel+"/", excludes) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !info.Mode().IsRegular() || skip[path] || isExcluded(rel, excludes) {
+			return nil
+		}
+
+		sum, err := hashFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", rel, err)
+		}
+
+		if configFileNames[filepath.Base(rel)] {
+			configs[rel] = sum
+		} else {
+			files[rel] = sum
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	return configs, files, nil
+}
+
+func isExcluded(rel string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := doublestar.Match(pattern, rel); matched {
+			return true
+		}
+		if matched, _ := doublestar.Match(pattern, filepath.Base(rel)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// linterVersion returns the version reported by an external linter binary.
+// Built-in linters share the arch-unit version.
+func linterVersion(name, toolVersion string) string {
+	switch name {
+	case "arch-unit", "aql", "comment-analysis":
+		return toolVersion
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, "--version").Output()
+	if err != nil {
+		logger.Debugf("Failed to get version of %s: %v", name, err)
+		return "unknown"
+	}
+
+	version := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	if version == "" {
+		return "unknown"
+	}
+	return version
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,62 @@
+package manifest_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/manifest"
+)
+
+var _ = Describe("Manifest", func() {
+	var root string
+
+	BeforeEach(func() {
+		root = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(root, "arch-unit.yaml"), []byte("version: \"1.0\"\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(root, "node_modules", "dep"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "node_modules", "dep", "index.js"), []byte("x"), 0644)).To(Succeed())
+	})
+
+	It("separates config files from source files and skips excluded directories", func() {
+		m, err := manifest.Generate(root, manifest.Options{Tool: manifest.ToolInfo{Name: "arch-unit", Version: "1.0.0"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Configs).To(HaveKey("arch-unit.yaml"))
+		Expect(m.Files).To(HaveKey("main.go"))
+		Expect(m.Files).NotTo(HaveKey("node_modules/dep/index.js"))
+	})
+
+	It("round-trips through disk and verifies an unchanged tree", func() {
+		m, err := manifest.Generate(root, manifest.Options{Tool: manifest.ToolInfo{Name: "arch-unit", Version: "1.0.0"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		path := filepath.Join(GinkgoT().TempDir(), "manifest.json")
+		Expect(m.Write(path)).To(Succeed())
+
+		loaded, err := manifest.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := loaded.Verify(root, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.OK()).To(BeTrue())
+	})
+
+	It("reports modified, added and missing files", func() {
+		m, err := manifest.Generate(root, manifest.Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "extra.go"), []byte("package main\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(root, "arch-unit.yaml"), []byte("version: \"2.0\"\n"), 0644)).To(Succeed())
+
+		result, err := m.Verify(root, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.OK()).To(BeFalse())
+		Expect(result.FilesModified).To(ConsistOf("main.go"))
+		Expect(result.FilesAdded).To(ConsistOf("extra.go"))
+		Expect(result.ConfigsChanged).To(ConsistOf("arch-unit.yaml"))
+	})
+})
@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// VerifyResult describes the differences between a manifest and a source tree
+type VerifyResult struct {
+	ConfigsChanged []string `json:"configs_changed,omitempty" pretty:"label=Changed Configs,omitempty"`
+	FilesModified  []string `json:"files_modified,omitempty" pretty:"label=Modified Files,omitempty"`
+	FilesMissing   []string `json:"files_missing,omitempty" pretty:"label=Missing Files,omitempty"`
+	FilesAdded     []string `json:"files_added,omitempty" pretty:"label=Added Files,omitempty"`
+	ResultsError   string   `json:"results_error,omitempty" pretty:"label=Results,omitempty"`
+	FilesChecked   int      `json:"files_checked" pretty:"label=Files Checked"`
+}
+
+// OK returns true if the tree matches the manifest exactly
+func (r *VerifyResult) OK() bool {
+	return len(r.ConfigsChanged) == 0 && len(r.FilesModified) == 0 &&
+		len(r.FilesMissing) == 0 && len(r.FilesAdded) == 0 && r.ResultsError == ""
+}
+
+// Verify compares the manifest against the source tree at root.
+// If resultsFile is set its checksum is compared against the recorded results digest,
+// files listed in skip (such as the manifest itself) are ignored.
+func (m *Manifest) Verify(root string, resultsFile string, skip ...string) (*VerifyResult, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve root: %w", err)
+	}
+
+	configs, files, err := hashTree(absRoot, nil, append(skip, resultsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{FilesChecked: len(files) + len(configs)}
+
+	for _, path := range sortedKeys(m.Configs) {
+		if configs[path] != m.Configs[path] {
+			result.ConfigsChanged = append(result.ConfigsChanged, path)
+		}
+	}
+	for _, path := range sortedKeys(configs) {
+		if _, ok := m.Configs[path]; !ok {
+			result.ConfigsChanged = append(result.ConfigsChanged, path)
+		}
+	}
+
+	for _, path := range sortedKeys(m.Files) {
+		sum, ok := files[path]
+		if !ok {
+			result.FilesMissing = append(result.FilesMissing, path)
+		} else if sum != m.Files[path] {
+			result.FilesModified = append(result.FilesModified, path)
+		}
+	}
+	for _, path := range sortedKeys(files) {
+		if _, ok := m.Files[path]; !ok {
+			result.FilesAdded = append(result.FilesAdded, path)
+		}
+	}
+
+	if resultsFile != "" {
+		if m.Results == nil {
+			result.ResultsError = "manifest does not record a results checksum"
+		} else if sum, err := hashFile(resultsFile); err != nil {
+			result.ResultsError = fmt.Sprintf("failed to hash results file: %v", err)
+		} else if sum != m.Results.SHA256 {
+			result.ResultsError = fmt.Sprintf("results checksum mismatch: expected %s, got %s", m.Results.SHA256, sum)
+		}
+	}
+
+	return result, nil
+}
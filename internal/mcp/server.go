@@ -0,0 +1,185 @@
+// Package mcp serves the AST cache, AQL rules and cached violations over the Model Context
+// Protocol, so coding agents can look up callers, violations and run rules while writing code.
+// Messages are JSON-RPC 2.0 requests, one per line on stdin, answered on stdout.
+// See https://modelcontextprotocol.io/specification/2025-06-18
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/query"
+)
+
+// ProtocolVersion is the latest version of the protocol the server implements, clients asking
+// for another version are answered with it
+const ProtocolVersion = "2025-06-18"
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"` // Absent for notifications
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server answers the requests of an MCP client about the nodes of the files under a root
+type Server struct {
+	astCache *cache.ASTCache
+	engine   *query.AQLEngine
+	root     string
+	tools    map[string]*tool
+
+	mu         sync.Mutex
+	violations *cache.ViolationCache // Opened on first use, most sessions never read violations
+}
+
+// NewServer creates a server for the nodes of the files under root, or of every file when
+// root is empty
+func NewServer(astCache *cache.ASTCache, root string) *Server {
+	s := &Server{
+		astCache: astCache,
+		engine:   query.NewAQLEngine(astCache),
+		root:     root,
+	}
+	s.tools = s.registerTools()
+	return s
+}
+
+// Serve answers the requests read from r until it is closed
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(w)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if resp := s.handle(scanner.Bytes()); resp != nil {
+			if err := encoder.Encode(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// Close closes the violation cache if it was opened
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.violations == nil {
+		return nil
+	}
+	return s.violations.Close()
+}
+
+// handle returns the response to a message, or nil for notifications
+func (s *Server) handle(message []byte) *response {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}}
+	}
+	if req.ID == nil {
+		// Notifications such as notifications/initialized need no answer
+		return nil
+	}
+
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.Error = &rpcError{Code: codeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}
+		return resp
+	}
+
+	switch req.Method {
+	case "initialize":
+		resp.Result = map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "arch-unit", "version": cache.CLIVersion},
+			"instructions":    "Query the architecture of the analyzed code: find nodes, their callers and callees, cached violations, and run AQL rules.",
+		}
+	case "ping":
+		resp.Result = map[string]interface{}{}
+	case "tools/list":
+		resp.Result = map[string]interface{}{"tools": s.toolList()}
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			resp.Error = &rpcError{Code: codeInvalidParams, Message: err.Error()}
+			return resp
+		}
+		t, ok := s.tools[params.Name]
+		if !ok {
+			resp.Error = &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool '%s'", params.Name)}
+			return resp
+		}
+		resp.Result = s.call(t, params.Arguments)
+	default:
+		resp.Error = &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method '%s' not found", req.Method)}
+	}
+	return resp
+}
+
+// call runs a tool, errors of tools are reported in their result so agents can correct their
+// arguments
+func (s *Server) call(t *tool, arguments json.RawMessage) map[string]interface{} {
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	result, err := t.run(arguments)
+	if err == nil {
+		var text []byte
+		text, err = json.MarshalIndent(result, "", "  ")
+		if err == nil {
+			return toolResult(string(text), false)
+		}
+	}
+	return toolResult(err.Error(), true)
+}
+
+func toolResult(text string, isError bool) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+func (s *Server) violationCache() (*cache.ViolationCache, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.violations == nil {
+		violations, err := cache.NewViolationCache()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open violation cache: %w", err)
+		}
+		s.violations = violations
+	}
+	return s.violations, nil
+}
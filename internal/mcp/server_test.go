@@ -0,0 +1,149 @@
+package mcp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/mcp"
+	"github.com/flanksource/arch-unit/models"
+)
+
+type response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type toolResult struct {
+	Content []struct {
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+var _ = Describe("Server", func() {
+	var server *mcp.Server
+
+	BeforeEach(func() {
+		cache.ResetGormDB()
+		astCache, err := cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).ToNot(HaveOccurred())
+
+		create, err := astCache.StoreASTNode(&models.ASTNode{
+			FilePath:    "/repo/service/user.go",
+			PackageName: "service",
+			TypeName:    "UserService",
+			MethodName:  "Create",
+			NodeType:    models.NodeTypeMethod,
+			StartLine:   10,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		save, err := astCache.StoreASTNode(&models.ASTNode{
+			FilePath:    "/repo/store/user.go",
+			PackageName: "store",
+			TypeName:    "UserStore",
+			MethodName:  "Save",
+			NodeType:    models.NodeTypeMethod,
+			StartLine:   5,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(astCache.StoreASTRelationship(create, &save, 12, models.RelationshipCall, "store.Save()")).To(Succeed())
+
+		server = mcp.NewServer(astCache, "/repo/")
+	})
+
+	// serve sends requests to the server and returns its responses
+	serve := func(requests ...string) []response {
+		var out bytes.Buffer
+		Expect(server.Serve(strings.NewReader(strings.Join(requests, "\n")), &out)).To(Succeed())
+		var responses []response
+		scanner := bufio.NewScanner(&out)
+		for scanner.Scan() {
+			var resp response
+			Expect(json.Unmarshal(scanner.Bytes(), &resp)).To(Succeed())
+			responses = append(responses, resp)
+		}
+		return responses
+	}
+
+	call := func(name string, arguments interface{}) toolResult {
+		params, err := json.Marshal(map[string]interface{}{"name": name, "arguments": arguments})
+		Expect(err).ToNot(HaveOccurred())
+		responses := serve(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":` + string(params) + `}`)
+		Expect(responses).To(HaveLen(1))
+		Expect(responses[0].Error).To(BeNil())
+		var result toolResult
+		Expect(json.Unmarshal(responses[0].Result, &result)).To(Succeed())
+		Expect(result.Content).To(HaveLen(1))
+		return result
+	}
+
+	It("should answer the handshake and list its tools", func() {
+		responses := serve(
+			`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{}}}`,
+			`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+			`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+			`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		)
+		Expect(responses).To(HaveLen(3))
+		Expect(string(responses[0].Result)).To(ContainSubstring(`"protocolVersion":"` + mcp.ProtocolVersion + `"`))
+
+		var list struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+		}
+		Expect(json.Unmarshal(responses[1].Result, &list)).To(Succeed())
+		var names []string
+		for _, t := range list.Tools {
+			names = append(names, t.Name)
+		}
+		Expect(names).To(Equal([]string{"architecture", "callees", "callers", "check", "query", "violations"}))
+
+		Expect(responses[2].ID).To(Equal(3))
+		Expect(responses[2].Error.Code).To(Equal(-32601))
+	})
+
+	It("should find nodes and their callers", func() {
+		result := call("query", map[string]string{"pattern": "UserStore:Save"})
+		Expect(result.IsError).To(BeFalse())
+		Expect(result.Content[0].Text).To(ContainSubstring(`"name": "store.UserStore.Save"`))
+
+		result = call("callers", map[string]string{"pattern": "UserStore:Save"})
+		var references []mcp.Reference
+		Expect(json.Unmarshal([]byte(result.Content[0].Text), &references)).To(Succeed())
+		Expect(references).To(Equal([]mcp.Reference{{
+			From: "service.UserService.Create",
+			To:   "store.UserStore.Save",
+			Type: "call",
+			File: "/repo/service/user.go",
+			Line: 12,
+		}}))
+
+		result = call("callees", map[string]string{"pattern": "UserStore:Save"})
+		Expect(result.Content[0].Text).To(Equal("[]"))
+	})
+
+	It("should run rules", func() {
+		result := call("check", map[string]string{"rules": `RULE "layers" { FORBID(*Service* -> *Store*) }`})
+		Expect(result.IsError).To(BeFalse())
+		var violations []map[string]interface{}
+		Expect(json.Unmarshal([]byte(result.Content[0].Text), &violations)).To(Succeed())
+		Expect(violations).To(HaveLen(1))
+	})
+
+	It("should report invalid arguments as tool errors", func() {
+		result := call("query", map[string]string{})
+		Expect(result.IsError).To(BeTrue())
+		Expect(result.Content[0].Text).To(ContainSubstring("exactly one of"))
+	})
+})
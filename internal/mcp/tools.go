@@ -0,0 +1,310 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/parser"
+)
+
+// defaultLimit is the number of results returned by tools when no limit is given, to keep the
+// context of agents small
+const defaultLimit = 100
+
+// tool is a function agents can call, with the JSON schema of its arguments
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	run         func(arguments json.RawMessage) (interface{}, error)
+}
+
+// Reference is a relationship between two nodes returned by the callers and callees tools
+type Reference struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+func schema(required []string, properties map[string]interface{}) map[string]interface{} {
+	s := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func property(kind, description string) map[string]interface{} {
+	return map[string]interface{}{"type": kind, "description": description}
+}
+
+func (s *Server) registerTools() map[string]*tool {
+	patternProperty := property("string", "AST pattern, e.g. UserService:Create, *Service* or @internal/**/*.go:*")
+	limitProperty := property("integer", fmt.Sprintf("Maximum number of results, %d by default", defaultLimit))
+	tools := []*tool{
+		{
+			Name:        "query",
+			Description: "Find the nodes matching an AST pattern, or the nodes whose metrics match an AQL condition such as lines(*Service*) > 300 or cyclomatic(*) > 10",
+			InputSchema: schema(nil, map[string]interface{}{
+				"pattern": patternProperty,
+				"aql":     property("string", "AQL metric condition"),
+				"limit":   limitProperty,
+			}),
+			run: s.query,
+		},
+		{
+			Name:        "callers",
+			Description: "List the callers of the nodes matching an AST pattern, and the other nodes referencing them",
+			InputSchema: schema([]string{"pattern"}, map[string]interface{}{"pattern": patternProperty, "limit": limitProperty}),
+			run: func(arguments json.RawMessage) (interface{}, error) {
+				return s.references(arguments, true)
+			},
+		},
+		{
+			Name:        "callees",
+			Description: "List the nodes called or referenced by the nodes matching an AST pattern",
+			InputSchema: schema([]string{"pattern"}, map[string]interface{}{"pattern": patternProperty, "limit": limitProperty}),
+			run: func(arguments json.RawMessage) (interface{}, error) {
+				return s.references(arguments, false)
+			},
+		},
+		{
+			Name:        "violations",
+			Description: "List the violations found by the last arch-unit check, of a file or of every file",
+			InputSchema: schema(nil, map[string]interface{}{
+				"file":   property("string", "Path of a file, relative to the project root or absolute"),
+				"source": property("string", "Linter that reported the violations, e.g. arch-unit, aql or golangci-lint"),
+				"limit":  limitProperty,
+			}),
+			run: s.listViolations,
+		},
+		{
+			Name:        "check",
+			Description: "Run AQL rules against the AST cache and return their violations, e.g. RULE \"layers\" { FORBID(*Controller* -> *Repository*) }",
+			InputSchema: schema([]string{"rules"}, map[string]interface{}{
+				"rules": property("string", "AQL rules, in the AQL or YAML syntax"),
+				"limit": limitProperty,
+			}),
+			run: s.check,
+		},
+		{
+			Name:        "architecture",
+			Description: "Summarize the packages of the project with their size and complexity, and the dependencies between them",
+			InputSchema: schema(nil, map[string]interface{}{}),
+			run: func(json.RawMessage) (interface{}, error) {
+				return s.engine.Architecture(s.root)
+			},
+		},
+	}
+
+	byName := make(map[string]*tool, len(tools))
+	for _, t := range tools {
+		byName[t.Name] = t
+	}
+	return byName
+}
+
+// toolList returns the tools sorted by name
+func (s *Server) toolList() []*tool {
+	list := make([]*tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+type arguments struct {
+	Pattern string `json:"pattern"`
+	AQL     string `json:"aql"`
+	File    string `json:"file"`
+	Source  string `json:"source"`
+	Rules   string `json:"rules"`
+	Limit   int    `json:"limit"`
+}
+
+func parseArguments(raw json.RawMessage) (*arguments, error) {
+	args := &arguments{}
+	if err := json.Unmarshal(raw, args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultLimit
+	}
+	return args, nil
+}
+
+func (s *Server) inScope(file string) bool {
+	return s.root == "" || strings.HasPrefix(file, s.root)
+}
+
+func (s *Server) query(raw json.RawMessage) (interface{}, error) {
+	args, err := parseArguments(raw)
+	if err != nil {
+		return nil, err
+	}
+	if (args.AQL == "") == (args.Pattern == "") {
+		return nil, fmt.Errorf("exactly one of 'pattern' or 'aql' is required")
+	}
+
+	result, err := s.engine.RunNamedQuery("query", &models.NamedQuery{AQL: args.AQL, Pattern: args.Pattern}, s.root)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Matches) > args.Limit {
+		result.Matches = result.Matches[:args.Limit]
+	}
+	return result, nil
+}
+
+// references returns the relationships to the nodes matching a pattern when incoming is true,
+// or from them
+func (s *Server) references(raw json.RawMessage, incoming bool) (interface{}, error) {
+	args, err := parseArguments(raw)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := models.ParsePattern(args.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", args.Pattern, err)
+	}
+	nodes, err := s.engine.FindNodes(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, node := range nodes {
+		if s.inScope(node.FilePath) && pattern.Matches(node) {
+			ids = append(ids, node.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return []Reference{}, nil
+	}
+
+	column := "from_ast_id"
+	if incoming {
+		column = "to_ast_id"
+	}
+	var relationships []*models.ASTRelationship
+	if err := s.astCache.GetReadQuery().Where(column+" IN ?", ids).Order("from_ast_id, line_no").Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	if len(relationships) > args.Limit {
+		relationships = relationships[:args.Limit]
+	}
+
+	var endpoints []int64
+	for _, rel := range relationships {
+		endpoints = append(endpoints, rel.FromASTID)
+		if rel.ToASTID != nil {
+			endpoints = append(endpoints, *rel.ToASTID)
+		}
+	}
+	var endpointNodes []*models.ASTNode
+	if err := s.astCache.GetReadQuery().Where("id IN ?", endpoints).Find(&endpointNodes).Error; err != nil {
+		return nil, fmt.Errorf("failed to query AST nodes: %w", err)
+	}
+	byID := make(map[int64]*models.ASTNode, len(endpointNodes))
+	for _, node := range endpointNodes {
+		byID[node.ID] = node
+	}
+
+	references := make([]Reference, 0, len(relationships))
+	for _, rel := range relationships {
+		from, ok := byID[rel.FromASTID]
+		if !ok {
+			continue
+		}
+		to := rel.Text
+		if rel.ToASTID != nil && byID[*rel.ToASTID] != nil {
+			to = byID[*rel.ToASTID].String()
+		}
+		references = append(references, Reference{
+			From: from.String(),
+			To:   to,
+			Type: string(rel.RelationshipType),
+			File: from.FilePath,
+			Line: rel.LineNo,
+		})
+	}
+	return references, nil
+}
+
+func (s *Server) listViolations(raw json.RawMessage) (interface{}, error) {
+	args, err := parseArguments(raw)
+	if err != nil {
+		return nil, err
+	}
+	violationCache, err := s.violationCache()
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []models.Violation
+	if args.File != "" {
+		file := args.File
+		if !filepath.IsAbs(file) && s.root != "" {
+			file = filepath.Join(s.root, file)
+		}
+		violations, err = violationCache.GetCachedViolations(file)
+	} else {
+		violations, err = violationCache.GetAllViolations()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get violations: %w", err)
+	}
+
+	matching := make([]models.Violation, 0, len(violations))
+	for _, v := range violations {
+		if s.inScope(v.File) && (args.Source == "" || v.Source == args.Source) {
+			matching = append(matching, v)
+			if len(matching) == args.Limit {
+				break
+			}
+		}
+	}
+	return matching, nil
+}
+
+func (s *Server) check(raw json.RawMessage) (interface{}, error) {
+	args, err := parseArguments(raw)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Rules) == "" {
+		return nil, fmt.Errorf("'rules' is required")
+	}
+
+	var ruleSet *models.AQLRuleSet
+	if parser.IsLegacyAQLFormat(args.Rules) {
+		ruleSet, err = parser.ParseAQL(args.Rules)
+	} else {
+		ruleSet, err = parser.LoadAQLFromYAML(args.Rules)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	violations, err := s.engine.ExecuteRuleSet(ruleSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute rules: %w", err)
+	}
+	matching := make([]*models.Violation, 0, len(violations))
+	for _, v := range violations {
+		if s.inScope(v.File) {
+			matching = append(matching, v)
+			if len(matching) == args.Limit {
+				break
+			}
+		}
+	}
+	return matching, nil
+}
@@ -0,0 +1,275 @@
+// Package objectstore stores files in S3 and Google Cloud Storage buckets, HTTP artifact stores
+// and local directories, e.g. to share snapshots of the AST cache between CI jobs.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/internal/sigv4"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// TokenEnvVar is the bearer token of HTTP artifact stores
+const TokenEnvVar = "ARCH_UNIT_CACHE_TOKEN"
+
+// Store reads and writes objects by key, a slash separated path relative to the location of
+// the store
+type Store interface {
+	// Put writes size bytes of r to key, replacing the object stored there
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get returns the object stored at key, and false when there is none
+	Get(ctx context.Context, key string) (io.ReadCloser, bool, error)
+	// String returns the location of the store without credentials
+	String() string
+}
+
+// Open returns the store of a location:
+//
+//	s3://bucket/prefix   S3, or an S3 compatible store at $AWS_ENDPOINT_URL_S3 or $AWS_ENDPOINT_URL
+//	gs://bucket/prefix   Google Cloud Storage, or its emulator at $STORAGE_EMULATOR_HOST
+//	https://host/path    an artifact store accepting PUT and GET, e.g. Artifactory or Nexus
+//	file:///path, /path  a local or mounted directory
+func Open(location string) (Store, error) {
+	scheme, rest, found := strings.Cut(location, "://")
+	if !found {
+		return &dirStore{dir: location}, nil
+	}
+	if scheme == "file" {
+		return &dirStore{dir: rest}, nil
+	}
+	if err := offline.Check("the remote cache " + location); err != nil {
+		return nil, err
+	}
+
+	switch scheme {
+	case "s3":
+		return openS3(rest)
+	case "gs":
+		return openGCS(rest)
+	case "http", "https":
+		base, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote cache %s: %w", location, err)
+		}
+		store := &httpStore{location: base.Redacted(), base: base}
+		if token := os.Getenv(TokenEnvVar); token != "" {
+			store.authorize = func(req *http.Request) error {
+				req.Header.Set("Authorization", "Bearer "+token)
+				return nil
+			}
+		}
+		return store, nil
+	}
+	return nil, fmt.Errorf("unsupported remote cache %s, expected s3://, gs://, http(s)://, file:// or a directory", location)
+}
+
+// openS3 returns the store of bucket/prefix, addressed by the virtual host of the bucket on AWS
+// and by path on S3 compatible stores such as MinIO
+func openS3(location string) (Store, error) {
+	signer, err := sigv4.NewSigner(context.Background(), "")
+	if err != nil {
+		return nil, err
+	}
+	bucket, prefix, _ := strings.Cut(location, "/")
+
+	rawBase := "https://" + bucket + ".s3." + signer.Region + ".amazonaws.com"
+	for _, name := range []string{"AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"} {
+		if endpoint := os.Getenv(name); endpoint != "" {
+			rawBase = strings.TrimSuffix(endpoint, "/") + "/" + bucket
+			break
+		}
+	}
+	base, err := url.Parse(rawBase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %s: %w", rawBase, err)
+	}
+	return &httpStore{
+		location: "s3://" + location,
+		base:     base.JoinPath(prefix),
+		authorize: func(req *http.Request) error {
+			// Snapshots are streamed from disk, rather than read into memory to hash them
+			return signer.Sign(req, sigv4.UnsignedPayload, "s3", time.Now())
+		},
+	}, nil
+}
+
+// openGCS returns the store of bucket/prefix in Google Cloud Storage, or in the emulator of
+// $STORAGE_EMULATOR_HOST, which needs no credentials
+func openGCS(location string) (Store, error) {
+	bucket, prefix, _ := strings.Cut(location, "/")
+	endpoint := "https://storage.googleapis.com"
+	emulator := os.Getenv("STORAGE_EMULATOR_HOST")
+	if emulator != "" {
+		endpoint = emulator
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	}
+	base, err := url.Parse(strings.TrimSuffix(endpoint, "/") + "/" + bucket)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote cache gs://%s: %w", location, err)
+	}
+	store := &httpStore{location: "gs://" + location, base: base.JoinPath(prefix)}
+
+	tokens, err := gcsTokenSource()
+	if err != nil {
+		if emulator != "" {
+			return store, nil
+		}
+		return nil, err
+	}
+	store.authorize = func(req *http.Request) error {
+		token, err := tokens.Token()
+		if err != nil {
+			return fmt.Errorf("failed to get a Google Cloud access token: %w", err)
+		}
+		token.SetAuthHeader(req)
+		return nil
+	}
+	return store, nil
+}
+
+// gcsScope is the OAuth scope of reading and writing objects in Google Cloud Storage
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// gcsTokenSource returns the access token of $GOOGLE_OAUTH_ACCESS_TOKEN, or the tokens of the
+// application default credentials: the service account key or workload identity federation
+// config of $GOOGLE_APPLICATION_CREDENTIALS, the credentials of gcloud auth application-default
+// login, or the service account of the GCE and GKE metadata server
+func gcsTokenSource() (oauth2.TokenSource, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	}
+	credentials, err := google.FindDefaultCredentials(context.Background(), gcsScope)
+	if err != nil {
+		return nil, fmt.Errorf("no Google Cloud credentials found, set GOOGLE_APPLICATION_CREDENTIALS or run gcloud auth application-default login: %w", err)
+	}
+	return credentials.TokenSource, nil
+}
+
+// httpStore stores objects with PUT and GET requests under a base URL
+type httpStore struct {
+	location string
+	base     *url.URL
+	// authorize adds the credentials of the store to a request
+	authorize func(req *http.Request) error
+}
+
+func (s *httpStore) String() string {
+	return s.location
+}
+
+func (s *httpStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.base.JoinPath(key).String(), r)
+	if err != nil {
+		return err
+	}
+	// S3 refuses uploads of unknown length
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/gzip")
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+func (s *httpStore) Get(ctx context.Context, key string) (io.ReadCloser, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.base.JoinPath(key).String(), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		var status *statusError
+		if errors.As(err, &status) && status.status == http.StatusNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return resp.Body, true, nil
+}
+
+// statusError is the unexpected status of a response
+type statusError struct {
+	method, key string
+	status      int
+	body        string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d: %s", e.method, e.key, e.status, e.body)
+}
+
+// do sends a request, returning the response of successful requests only
+func (s *httpStore) do(req *http.Request) (*http.Response, error) {
+	if s.authorize != nil {
+		if err := s.authorize(req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", req.Method, s.location, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		key := strings.TrimPrefix(req.URL.Path, s.base.Path)
+		return nil, &statusError{method: req.Method, key: s.location + key, status: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+	return resp, nil
+}
+
+// dirStore stores objects as files under a directory
+type dirStore struct {
+	dir string
+}
+
+func (s *dirStore) String() string {
+	return s.dir
+}
+
+func (s *dirStore) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	// Readers of the directory never see a partially written object
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *dirStore) Get(_ context.Context, key string) (io.ReadCloser, bool, error) {
+	file, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return file, true, nil
+}
@@ -0,0 +1,164 @@
+package objectstore_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/objectstore"
+	"github.com/flanksource/arch-unit/internal/offline"
+)
+
+var _ = Describe("Object stores", func() {
+	ctx := context.Background()
+
+	roundTrip := func(store objectstore.Store) {
+		_, found, err := store.Get(ctx, "github.com/flanksource/example/abc123.db.gz")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		Expect(store.Put(ctx, "github.com/flanksource/example/abc123.db.gz", strings.NewReader("snapshot"), 8)).To(Succeed())
+		object, found, err := store.Get(ctx, "github.com/flanksource/example/abc123.db.gz")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		defer func() { _ = object.Close() }()
+		data, err := io.ReadAll(object)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("snapshot"))
+	}
+
+	It("should store objects in a directory", func() {
+		store, err := objectstore.Open("file://" + GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		roundTrip(store)
+	})
+
+	// serve stores the objects PUT by authorized requests, keyed by path
+	serve := func(authorized func(r *http.Request) bool) (*httptest.Server, map[string]string) {
+		var mu sync.Mutex
+		objects := map[string]string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !authorized(r) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch r.Method {
+			case http.MethodPut:
+				data, _ := io.ReadAll(r.Body)
+				objects[r.URL.Path] = string(data)
+				w.WriteHeader(http.StatusCreated)
+			case http.MethodGet:
+				data, ok := objects[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(data))
+			}
+		}))
+		DeferCleanup(server.Close)
+		return server, objects
+	}
+
+	It("should store objects in an artifact store with a bearer token", func() {
+		server, objects := serve(func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer secret" })
+		GinkgoT().Setenv(objectstore.TokenEnvVar, "secret")
+
+		store, err := objectstore.Open(server.URL + "/artifacts/arch-unit")
+		Expect(err).NotTo(HaveOccurred())
+		roundTrip(store)
+		Expect(objects).To(HaveKey("/artifacts/arch-unit/github.com/flanksource/example/abc123.db.gz"))
+	})
+
+	It("should store objects in S3 with the credentials of the AWS profile", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "config"), []byte("[profile ci]\nregion = eu-west-1\n"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "credentials"), []byte("[ci]\naws_access_key_id = AKIDPROFILE\naws_secret_access_key = secret\n"), 0600)).To(Succeed())
+		GinkgoT().Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+		GinkgoT().Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+		GinkgoT().Setenv("AWS_PROFILE", "ci")
+		GinkgoT().Setenv("AWS_EC2_METADATA_DISABLED", "true")
+		for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ENDPOINT_URL"} {
+			GinkgoT().Setenv(name, "")
+		}
+
+		server, objects := serve(func(r *http.Request) bool {
+			return strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDPROFILE/") &&
+				strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request") &&
+				r.Header.Get("X-Amz-Content-Sha256") == "UNSIGNED-PAYLOAD"
+		})
+		GinkgoT().Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+
+		store, err := objectstore.Open("s3://ci-cache/arch-unit")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.String()).To(Equal("s3://ci-cache/arch-unit"))
+		roundTrip(store)
+		Expect(objects).To(HaveKey("/ci-cache/arch-unit/github.com/flanksource/example/abc123.db.gz"))
+	})
+
+	It("should store objects in GCS with the token of a service account key", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.ParseForm()).To(Succeed())
+			Expect(r.PostForm.Get("grant_type")).To(Equal("urn:ietf:params:oauth:grant-type:jwt-bearer"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "ya29.service-account", "token_type": "Bearer", "expires_in": 3600}`))
+		}))
+		DeferCleanup(tokens.Close)
+
+		account, err := json.Marshal(map[string]string{
+			"type":           "service_account",
+			"project_id":     "ci",
+			"private_key_id": "1",
+			"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+			"client_email":   "arch-unit@ci.iam.gserviceaccount.com",
+			"token_uri":      tokens.URL,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		path := filepath.Join(GinkgoT().TempDir(), "key.json")
+		Expect(os.WriteFile(path, account, 0600)).To(Succeed())
+		GinkgoT().Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+		GinkgoT().Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+
+		server, objects := serve(func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer ya29.service-account" })
+		GinkgoT().Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+		store, err := objectstore.Open("gs://ci-cache/arch-unit")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.String()).To(Equal("gs://ci-cache/arch-unit"))
+		roundTrip(store)
+		Expect(objects).To(HaveKey("/ci-cache/arch-unit/github.com/flanksource/example/abc123.db.gz"))
+	})
+
+	It("should not open network stores in offline mode", func() {
+		offline.SetEnabled(true)
+		DeferCleanup(offline.SetEnabled, false)
+
+		_, err := objectstore.Open("s3://bucket/arch-unit")
+		Expect(err).To(MatchError(offline.ErrNetworkRequired))
+	})
+
+	It("should refuse unknown schemes", func() {
+		_, err := objectstore.Open("ftp://host/arch-unit")
+		Expect(err).To(MatchError(ContainSubstring("unsupported remote cache")))
+	})
+})
@@ -0,0 +1,39 @@
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+// EnvVar enables offline mode when set to a true value (e.g. "true" or "1")
+const EnvVar = "ARCH_UNIT_OFFLINE"
+
+var enabled atomic.Bool
+
+// ErrNetworkRequired is returned when a feature needs network access while offline mode is enabled
+var ErrNetworkRequired = errors.New("network access is disabled in offline mode")
+
+// SetEnabled turns offline mode on or off for the current process
+func SetEnabled(value bool) {
+	enabled.Store(value)
+}
+
+// Enabled reports whether network access is disabled, either via SetEnabled or the ARCH_UNIT_OFFLINE env var
+func Enabled() bool {
+	if enabled.Load() {
+		return true
+	}
+	value, err := strconv.ParseBool(os.Getenv(EnvVar))
+	return err == nil && value
+}
+
+// Check returns an error describing the feature when offline mode is enabled, nil otherwise
+func Check(feature string) error {
+	if !Enabled() {
+		return nil
+	}
+	return fmt.Errorf("%s requires network access (run without --offline): %w", feature, ErrNetworkRequired)
+}
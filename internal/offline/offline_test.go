@@ -0,0 +1,37 @@
+package offline_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/offline"
+)
+
+var _ = Describe("Offline mode", func() {
+	BeforeEach(func() {
+		GinkgoT().Setenv(offline.EnvVar, "")
+		offline.SetEnabled(false)
+		DeferCleanup(offline.SetEnabled, false)
+	})
+
+	It("should allow network features when disabled", func() {
+		Expect(offline.Enabled()).To(BeFalse())
+		Expect(offline.Check("registry lookup")).To(Succeed())
+	})
+
+	It("should fail fast with a descriptive error when enabled", func() {
+		offline.SetEnabled(true)
+
+		err := offline.Check("registry lookup")
+		Expect(err).To(MatchError(offline.ErrNetworkRequired))
+		Expect(err.Error()).To(ContainSubstring("registry lookup requires network access"))
+	})
+
+	It("should be enabled by the environment variable", func() {
+		GinkgoT().Setenv(offline.EnvVar, "true")
+		Expect(offline.Enabled()).To(BeTrue())
+
+		GinkgoT().Setenv(offline.EnvVar, "not-a-bool")
+		Expect(offline.Enabled()).To(BeFalse())
+	})
+})
@@ -0,0 +1,278 @@
+package repl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/query"
+	"github.com/flanksource/clicky"
+)
+
+// Formats of the results of a session
+const (
+	FormatPretty = "pretty"
+	FormatTree   = "tree"
+	FormatJSON   = "json"
+)
+
+// Prompt is printed before every input
+const Prompt = "aql> "
+
+// sqlStatement matches the statements of read-only SQL queries
+var sqlStatement = regexp.MustCompile(`(?i)^\s*(select|with|pragma|explain)\b`)
+
+// comparison matches the operator of a metric condition such as lines(*Service*) > 300
+var comparison = regexp.MustCompile(`[<>!=]=?`)
+
+const help = `Enter an AST pattern, an AQL condition or a read-only SQL query:
+
+  *Service*                      nodes matching a pattern
+  lines(*Service*) > 300         nodes violating a metric condition
+  SELECT * FROM ast_nodes LIMIT 5
+
+Commands:
+  .format pretty|tree|json       switch the output format
+  .tables                        list the tables of the cache
+  .schema <table>                show the definition of a table
+  .help                          show this help
+  .quit                          exit
+`
+
+// Session evaluates the AST patterns, AQL conditions and read-only SQL queries of an interactive
+// prompt against the AST cache. Patterns and conditions only match nodes of files under the path
+// prefix, every node when it is empty.
+type Session struct {
+	cache      *cache.ASTCache
+	engine     *query.AQLEngine
+	out        io.Writer
+	pathPrefix string
+	workingDir string
+	format     string
+}
+
+// NewSession creates a session writing its results to out, as pretty tables at first
+func NewSession(astCache *cache.ASTCache, out io.Writer, workingDir, pathPrefix string) *Session {
+	return &Session{
+		cache:      astCache,
+		engine:     query.NewAQLEngine(astCache),
+		out:        out,
+		pathPrefix: pathPrefix,
+		workingDir: workingDir,
+		format:     FormatPretty,
+	}
+}
+
+// Run prompts for inputs until .quit or the end of in, errors of an input are printed and the
+// session continues
+func (s *Session) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		_, _ = fmt.Fprint(s.out, Prompt)
+		if !scanner.Scan() {
+			_, _ = fmt.Fprintln(s.out)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == ".quit" || line == ".exit" {
+			return nil
+		}
+		if err := s.Eval(line); err != nil {
+			_, _ = fmt.Fprintf(s.out, "Error: %v\n", err)
+		}
+	}
+}
+
+// Eval evaluates a single input
+func (s *Session) Eval(line string) error {
+	line = strings.TrimSuffix(strings.TrimSpace(line), ";")
+	switch {
+	case line == "":
+		return nil
+	case strings.HasPrefix(line, "."):
+		return s.command(line)
+	case sqlStatement.MatchString(line):
+		return s.sql(line)
+	case comparison.MatchString(line):
+		return s.condition(line)
+	}
+	return s.pattern(line)
+}
+
+// command runs a dot command
+func (s *Session) command(line string) error {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ".help":
+		_, _ = fmt.Fprint(s.out, help)
+		return nil
+	case ".format":
+		if len(fields) != 2 || (fields[1] != FormatPretty && fields[1] != FormatTree && fields[1] != FormatJSON) {
+			return fmt.Errorf("expected .format pretty, tree or json")
+		}
+		s.format = fields[1]
+		return nil
+	case ".tables":
+		if s.postgres() {
+			return s.sql("SELECT table_name AS name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name")
+		}
+		return s.sql("SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+	case ".schema":
+		if len(fields) != 2 {
+			return fmt.Errorf("expected .schema <table>")
+		}
+		// PostgreSQL keeps no CREATE statement, the columns are listed instead
+		if s.postgres() {
+			return s.sql("SELECT column_name, data_type, is_nullable, column_default FROM information_schema.columns "+
+				"WHERE table_schema = current_schema() AND table_name = ? ORDER BY ordinal_position", fields[1])
+		}
+		var definition string
+		if err := s.cache.GetReadQuery().Raw("SELECT sql FROM sqlite_master WHERE name = ?", fields[1]).Scan(&definition).Error; err != nil {
+			return err
+		}
+		if definition == "" {
+			return fmt.Errorf("table '%s' not found", fields[1])
+		}
+		_, _ = fmt.Fprintln(s.out, definition)
+		return nil
+	}
+	return fmt.Errorf("unknown command %s, enter .help for the commands", fields[0])
+}
+
+// postgres returns true when the cache is a PostgreSQL database, whose catalog differs from SQLite
+func (s *Session) postgres() bool {
+	return s.cache.GetReadQuery().Dialector.Name() == "postgres"
+}
+
+// sql runs a query on the read-only connection of the cache, or in a read-only transaction of a
+// PostgreSQL cache, so statements writing to the cache fail
+func (s *Session) sql(statement string, args ...interface{}) error {
+	db := s.cache.GetReadQuery()
+	if s.postgres() {
+		db = db.Begin()
+		if db.Error != nil {
+			return db.Error
+		}
+		defer db.Rollback()
+		if err := db.Exec("SET TRANSACTION READ ONLY").Error; err != nil {
+			return err
+		}
+	}
+	rows, err := db.Raw(statement, args...).Rows()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	var records []map[string]interface{}
+	var table [][]string
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		record := make(map[string]interface{}, len(columns))
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			record[column] = values[i]
+			if values[i] != nil {
+				cells[i] = fmt.Sprint(values[i])
+			}
+		}
+		records = append(records, record)
+		table = append(table, cells)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if s.format == FormatJSON {
+		return s.json(records)
+	}
+	w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, strings.Join(columns, "\t"))
+	for _, cells := range table {
+		_, _ = fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	_ = w.Flush()
+	_, _ = fmt.Fprintf(s.out, "(%d rows)\n", len(table))
+	return nil
+}
+
+// condition reports the nodes violating a metric condition
+func (s *Session) condition(expression string) error {
+	result, err := s.engine.RunNamedQuery("query", &models.NamedQuery{AQL: expression}, s.pathPrefix)
+	if err != nil {
+		return err
+	}
+	if s.format == FormatJSON {
+		return s.json(result)
+	}
+	output, err := clicky.Format(result)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(s.out, output)
+	return nil
+}
+
+// pattern lists the nodes matching an AST pattern
+func (s *Session) pattern(expression string) error {
+	pattern, err := models.ParsePattern(expression)
+	if err != nil {
+		return fmt.Errorf("invalid pattern '%s': %w", expression, err)
+	}
+	found, err := s.engine.FindNodes(pattern)
+	if err != nil {
+		return err
+	}
+	nodes := make([]*models.ASTNode, 0, len(found))
+	for _, node := range found {
+		if s.pathPrefix == "" || strings.HasPrefix(node.FilePath, s.pathPrefix) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	var output string
+	switch s.format {
+	case FormatJSON:
+		return s.json(nodes)
+	case FormatTree:
+		output, err = clicky.Format(models.BuildHierarchicalASTTree(nodes, models.DefaultDisplayConfig(), s.workingDir), clicky.FormatOptions{Format: "tree"})
+	default:
+		output, err = clicky.Format(nodes, clicky.FormatOptions{Format: "table"})
+	}
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(s.out, output)
+	_, _ = fmt.Fprintf(s.out, "(%d nodes)\n", len(nodes))
+	return nil
+}
+
+func (s *Session) json(value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(s.out, string(data))
+	return nil
+}
@@ -0,0 +1,76 @@
+package repl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/repl"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Session", func() {
+	var (
+		out     *bytes.Buffer
+		session *repl.Session
+	)
+
+	BeforeEach(func() {
+		cache.ResetGormDB()
+		astCache, err := cache.NewASTCacheWithPath(GinkgoT().TempDir())
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, node := range []*models.ASTNode{
+			{FilePath: "/repo/service/user.go", PackageName: "service", TypeName: "UserService", NodeType: models.NodeTypeType, StartLine: 5, LineCount: 400},
+			{FilePath: "/repo/service/user.go", PackageName: "service", TypeName: "UserService", MethodName: "Create", NodeType: models.NodeTypeMethod, StartLine: 10},
+			{FilePath: "/other/service/order.go", PackageName: "service", TypeName: "OrderService", NodeType: models.NodeTypeType, StartLine: 3},
+		} {
+			_, err := astCache.StoreASTNode(node)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		out = &bytes.Buffer{}
+		session = repl.NewSession(astCache, out, "/repo", "/repo/")
+	})
+
+	It("runs read-only SQL queries", func() {
+		Expect(session.Eval(".format json")).To(Succeed())
+		Expect(session.Eval("SELECT type_name, start_line FROM ast_nodes WHERE method_name = 'Create';")).To(Succeed())
+
+		var rows []map[string]interface{}
+		Expect(json.Unmarshal(out.Bytes(), &rows)).To(Succeed())
+		Expect(rows).To(Equal([]map[string]interface{}{{"type_name": "UserService", "start_line": float64(10)}}))
+	})
+
+	It("rejects statements writing to the cache", func() {
+		Expect(session.Eval("DELETE FROM ast_nodes")).ToNot(Succeed())
+		Expect(session.Eval("PRAGMA user_version = 5")).ToNot(Succeed())
+	})
+
+	It("lists the nodes of the working directory matching a pattern", func() {
+		Expect(session.Eval(".format json")).To(Succeed())
+		Expect(session.Eval("*Service")).To(Succeed())
+
+		var nodes []models.ASTNode
+		Expect(json.Unmarshal(out.Bytes(), &nodes)).To(Succeed())
+		Expect(nodes).To(HaveLen(1))
+		Expect(nodes[0].TypeName).To(Equal("UserService"))
+	})
+
+	It("prints tables of SQL results", func() {
+		Expect(session.Eval(".tables")).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("ast_nodes"))
+		Expect(out.String()).To(MatchRegexp(`\(\d+ rows\)`))
+	})
+
+	It("reports errors and keeps prompting until .quit", func() {
+		Expect(session.Run(strings.NewReader(".format xml\n.schema missing\n.quit\n.help\n"))).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("Error: expected .format pretty, tree or json"))
+		Expect(out.String()).To(ContainSubstring("Error: table 'missing' not found"))
+		Expect(out.String()).ToNot(ContainSubstring("Commands:"))
+	})
+})
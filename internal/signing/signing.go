@@ -0,0 +1,276 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// FormatVersion is the version of the signature file format
+const FormatVersion = "1"
+
+// Algorithm is the only signature algorithm currently supported
+const Algorithm = "ed25519"
+
+// KeyEnvVar points at the private key used to sign exported artifacts
+const KeyEnvVar = "ARCH_UNIT_SIGNING_KEY"
+
+// Extension is appended to an artifact's path to form the path of its signature
+const Extension = ".sig"
+
+// ErrInvalidSignature is returned when an artifact does not match its signature
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Provenance describes the run that produced a signed artifact
+type Provenance struct {
+	Tool        string `json:"tool"`
+	ToolVersion string `json:"tool_version"`
+	Commit      string `json:"commit,omitempty"` // Commit of the analyzed source tree
+}
+
+// Signature is a detached signature over an artifact and the provenance of the run that produced it
+type Signature struct {
+	FormatVersion string     `json:"format_version"`
+	Algorithm     string     `json:"algorithm"`
+	Artifact      string     `json:"artifact"` // Base name of the signed file
+	SHA256        string     `json:"sha256"`
+	Provenance    Provenance `json:"provenance"`
+	SignedAt      time.Time  `json:"signed_at"`
+	KeyID         string     `json:"key_id"`
+	Signature     string     `json:"signature,omitempty"` // Base64 signature over all other fields
+}
+
+// SignatureFile returns the path of the signature for an artifact
+func SignatureFile(artifact string) string {
+	return artifact + Extension
+}
+
+// GenerateKey creates a new key pair, returning PEM encoded private and public keys
+func GenerateKey() (privatePEM, publicPEM []byte, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), nil
+}
+
+// LoadPrivateKey reads a PEM encoded PKCS#8 ed25519 private key
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an %s key", path, Algorithm)
+	}
+	return private, nil
+}
+
+// LoadPublicKey reads a PEM encoded PKIX ed25519 public key
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an %s key", path, Algorithm)
+	}
+	return public, nil
+}
+
+func readPEM(path, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s does not contain a PEM encoded %s", path, blockType)
+	}
+	return block, nil
+}
+
+// KeyID returns a short identifier for a public key
+func KeyID(public ed25519.PublicKey) string {
+	sum := sha256.Sum256(public)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Sign creates a detached signature for the artifact at path
+func Sign(path string, key ed25519.PrivateKey, provenance Provenance) (*Signature, error) {
+	digest, err := hashFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := &Signature{
+		FormatVersion: FormatVersion,
+		Algorithm:     Algorithm,
+		Artifact:      filepath.Base(path),
+		SHA256:        digest,
+		Provenance:    provenance,
+		SignedAt:      time.Now().UTC().Truncate(time.Second),
+		KeyID:         KeyID(key.Public().(ed25519.PublicKey)),
+	}
+
+	payload, err := sig.payload()
+	if err != nil {
+		return nil, err
+	}
+	sig.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return sig, nil
+}
+
+// SignFile signs the artifact at path and writes the signature next to it
+func SignFile(path string, key ed25519.PrivateKey, provenance Provenance) (string, error) {
+	sig, err := Sign(path, key, provenance)
+	if err != nil {
+		return "", err
+	}
+	sigPath := SignatureFile(path)
+	if err := sig.Write(sigPath); err != nil {
+		return "", err
+	}
+	return sigPath, nil
+}
+
+// Verify checks that the artifact at path matches the signature and was signed by key
+func (s *Signature) Verify(path string, key ed25519.PublicKey) error {
+	if s.FormatVersion != FormatVersion {
+		return fmt.Errorf("unsupported signature format version %q (expected %q)", s.FormatVersion, FormatVersion)
+	}
+	if s.Algorithm != Algorithm {
+		return fmt.Errorf("unsupported signature algorithm %q", s.Algorithm)
+	}
+	if keyID := KeyID(key); s.KeyID != keyID {
+		return fmt.Errorf("%w: signed with key %s, not %s", ErrInvalidSignature, s.KeyID, keyID)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature: %v", ErrInvalidSignature, err)
+	}
+	payload, err := s.payload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, payload, raw) {
+		return fmt.Errorf("%w: signature does not match its contents", ErrInvalidSignature)
+	}
+
+	digest, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	if digest != s.SHA256 {
+		return fmt.Errorf("%w: %s has been modified since it was signed", ErrInvalidSignature, path)
+	}
+	return nil
+}
+
+// VerifyFile loads the signature stored next to the artifact and verifies it
+func VerifyFile(path string, key ed25519.PublicKey) (*Signature, error) {
+	sig, err := Load(SignatureFile(path))
+	if err != nil {
+		return nil, err
+	}
+	if err := sig.Verify(path, key); err != nil {
+		return sig, err
+	}
+	return sig, nil
+}
+
+// payload returns the signed bytes: the signature document without the signature itself
+func (s *Signature) payload() ([]byte, error) {
+	unsigned := *s
+	unsigned.Signature = ""
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signature payload: %w", err)
+	}
+	return data, nil
+}
+
+// Load reads a signature file
+func Load(path string) (*Signature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	var sig Signature
+	if err := json.Unmarshal(data, &sig); err != nil {
+		return nil, fmt.Errorf("failed to parse signature %s: %w", path, err)
+	}
+	return &sig, nil
+}
+
+// Write saves the signature as indented JSON
+func (s *Signature) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode signature: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
+
+// SourceCommit returns the HEAD commit of the git repository containing dir, or "" outside a repository
+func SourceCommit(dir string) string {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return ""
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+	return head.Hash().String()
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,80 @@
+package signing_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/signing"
+)
+
+var _ = Describe("Signing", func() {
+	var (
+		dir      string
+		artifact string
+	)
+
+	writeKeys := func(name string) (string, string) {
+		privatePEM, publicPEM, err := signing.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		privatePath := filepath.Join(dir, name+".key")
+		publicPath := filepath.Join(dir, name+".pub")
+		Expect(os.WriteFile(privatePath, privatePEM, 0600)).To(Succeed())
+		Expect(os.WriteFile(publicPath, publicPEM, 0644)).To(Succeed())
+		return privatePath, publicPath
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		artifact = filepath.Join(dir, "results.json")
+		Expect(os.WriteFile(artifact, []byte(`{"violations":[]}`), 0644)).To(Succeed())
+	})
+
+	It("should verify an artifact signed with the matching key", func() {
+		privatePath, publicPath := writeKeys("ci")
+		private, err := signing.LoadPrivateKey(privatePath)
+		Expect(err).NotTo(HaveOccurred())
+		public, err := signing.LoadPublicKey(publicPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		sigPath, err := signing.SignFile(artifact, private, signing.Provenance{Tool: "arch-unit", ToolVersion: "1.0.0", Commit: "abc123"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sigPath).To(Equal(artifact + signing.Extension))
+
+		sig, err := signing.VerifyFile(artifact, public)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sig.Artifact).To(Equal("results.json"))
+		Expect(sig.Provenance.Commit).To(Equal("abc123"))
+	})
+
+	It("should reject a modified artifact", func() {
+		privatePath, publicPath := writeKeys("ci")
+		private, _ := signing.LoadPrivateKey(privatePath)
+		public, _ := signing.LoadPublicKey(publicPath)
+
+		_, err := signing.SignFile(artifact, private, signing.Provenance{Tool: "arch-unit"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(artifact, []byte(`{"violations":null}`), 0644)).To(Succeed())
+
+		_, err = signing.VerifyFile(artifact, public)
+		Expect(err).To(MatchError(signing.ErrInvalidSignature))
+	})
+
+	It("should reject tampered provenance and foreign keys", func() {
+		privatePath, publicPath := writeKeys("ci")
+		_, otherPublicPath := writeKeys("other")
+		private, _ := signing.LoadPrivateKey(privatePath)
+		public, _ := signing.LoadPublicKey(publicPath)
+		otherPublic, _ := signing.LoadPublicKey(otherPublicPath)
+
+		sig, err := signing.Sign(artifact, private, signing.Provenance{Tool: "arch-unit", Commit: "abc123"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sig.Verify(artifact, otherPublic)).To(MatchError(signing.ErrInvalidSignature))
+
+		sig.Provenance.Commit = "def456"
+		Expect(sig.Verify(artifact, public)).To(MatchError(signing.ErrInvalidSignature))
+	})
+})
@@ -0,0 +1,93 @@
+// Package sigv4 signs requests to AWS APIs, such as Bedrock and S3, with AWS Signature Version 4.
+package sigv4
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// UnsignedPayload is the payload hash of S3 requests streaming a body that is not signed
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// defaultRegion is the region of requests when neither the caller nor the AWS config set one
+const defaultRegion = "us-east-1"
+
+// Signer signs requests with the credentials of the default chain of the AWS SDK: the environment,
+// the shared config and credentials files of AWS_PROFILE, SSO, web identity tokens such as those
+// of IRSA, and the container and EC2 instance metadata endpoints
+type Signer struct {
+	// Region of the requests, from AWS_REGION or the profile unless set by the caller
+	Region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+}
+
+// NewSigner loads the default AWS config and returns a signer for region, or for the region of
+// the config when empty. It fails when no credentials can be found, rather than on the first
+// request.
+func NewSigner(ctx context.Context, region string) (*Signer, error) {
+	var options []func(*config.LoadOptions) error
+	if region != "" {
+		options = append(options, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the AWS config: %w", err)
+	}
+	if cfg.Credentials == nil {
+		return nil, fmt.Errorf("no AWS credentials found")
+	}
+	// Credentials are cached and refreshed before they expire
+	if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+		return nil, fmt.Errorf("no AWS credentials found: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	return &Signer{Region: cfg.Region, credentials: cfg.Credentials, signer: v4.NewSigner()}, nil
+}
+
+// Sign signs a request to service, covering its host, its headers and the payload hash, either
+// HashPayload of the body or UnsignedPayload
+func (s *Signer) Sign(req *http.Request, payloadHash, service string, now time.Time) error {
+	credentials, err := s.credentials.Retrieve(req.Context())
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	// S3 refuses requests without the hash of their payload, and expects their paths to be
+	// escaped once where other services escape them twice
+	s3 := service == "s3"
+	if s3 {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	return s.signer.SignHTTP(req.Context(), credentials, req, payloadHash, service, s.Region, now,
+		func(options *v4.SignerOptions) { options.DisableURIPathEscaping = s3 })
+}
+
+// Escape percent-encodes everything but the unreserved characters of RFC 3986, as AWS expects
+func Escape(value string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || b == '-' || b == '_' || b == '.' || b == '~' {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+// HashPayload returns the hex encoded SHA-256 of a payload
+func HashPayload(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,98 @@
+package sigv4_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/sigv4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sign", func() {
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	var dir string
+
+	BeforeEach(func() {
+		// Only the credentials of each spec are found, never those of the machine
+		dir = GinkgoT().TempDir()
+		GinkgoT().Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+		GinkgoT().Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+		GinkgoT().Setenv("AWS_EC2_METADATA_DISABLED", "true")
+		for _, name := range []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_SESSION_TOKEN",
+			"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"} {
+			GinkgoT().Setenv(name, "")
+		}
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	})
+
+	It("should sign requests like the AWS Signature Version 4 test suite", func() {
+		signer, err := sigv4.NewSigner(context.Background(), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signer.Region).To(Equal("us-east-1"))
+
+		// get-vanilla of the AWS SigV4 test suite
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signer.Sign(req, sigv4.HashPayload(nil), "service", now)).To(Succeed())
+
+		Expect(req.Header.Get("X-Amz-Date")).To(Equal("20150830T123600Z"))
+		Expect(req.Header.Get("Authorization")).To(Equal("AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+			"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"))
+	})
+
+	It("should sign the payload hash of S3 requests", func() {
+		signer, err := sigv4.NewSigner(context.Background(), "eu-west-1")
+		Expect(err).ToNot(HaveOccurred())
+		req, err := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/arch-unit/repo/abc.db.gz", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signer.Sign(req, sigv4.UnsignedPayload, "s3", now)).To(Succeed())
+
+		Expect(req.Header.Get("X-Amz-Content-Sha256")).To(Equal(sigv4.UnsignedPayload))
+		Expect(req.Header.Get("Authorization")).To(ContainSubstring("/eu-west-1/s3/aws4_request"))
+		Expect(req.Header.Get("Authorization")).To(ContainSubstring("SignedHeaders=host;x-amz-content-sha256;x-amz-date,"))
+	})
+
+	It("should sign the session token of temporary credentials", func() {
+		GinkgoT().Setenv("AWS_SESSION_TOKEN", "token")
+		signer, err := sigv4.NewSigner(context.Background(), "")
+		Expect(err).ToNot(HaveOccurred())
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signer.Sign(req, sigv4.HashPayload(nil), "service", now)).To(Succeed())
+
+		Expect(req.Header.Get("X-Amz-Security-Token")).To(Equal("token"))
+		Expect(req.Header.Get("Authorization")).To(ContainSubstring("x-amz-security-token"))
+	})
+
+	It("should sign with the credentials and region of the profile of AWS_PROFILE", func() {
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "")
+		GinkgoT().Setenv("AWS_PROFILE", "ci")
+		Expect(os.WriteFile(filepath.Join(dir, "config"), []byte("[profile ci]\nregion = ap-southeast-2\n"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "credentials"), []byte("[ci]\naws_access_key_id = AKIDPROFILE\naws_secret_access_key = secret\n"), 0600)).To(Succeed())
+
+		signer, err := sigv4.NewSigner(context.Background(), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signer.Region).To(Equal("ap-southeast-2"))
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(signer.Sign(req, sigv4.HashPayload(nil), "service", now)).To(Succeed())
+		Expect(req.Header.Get("Authorization")).To(HavePrefix("AWS4-HMAC-SHA256 Credential=AKIDPROFILE/20150830/ap-southeast-2/service/aws4_request"))
+	})
+
+	It("should fail without credentials", func() {
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "")
+		_, err := sigv4.NewSigner(context.Background(), "")
+		Expect(err).To(MatchError(ContainSubstring("no AWS credentials found")))
+	})
+
+	It("should escape model IDs like AWS", func() {
+		Expect(sigv4.Escape("anthropic.claude-3-5-haiku-20241022-v1:0")).To(Equal("anthropic.claude-3-5-haiku-20241022-v1%3A0"))
+	})
+})
@@ -1,6 +1,9 @@
 package languages
 
 import (
+	"path/filepath"
+	"strings"
+
 	"github.com/flanksource/arch-unit/models"
 )
 
@@ -77,6 +80,8 @@ func DetectLanguage(filePath string) string {
 		return "go"
 	case filePath[len(filePath)-3:] == ".py" || (len(filePath) >= 4 && filePath[len(filePath)-4:] == ".pyi"):
 		return "python"
+	case len(filePath) >= 6 && filePath[len(filePath)-6:] == ".ipynb":
+		return "python"
 	case len(filePath) >= 5 && filePath[len(filePath)-5:] == ".java":
 		return "java"
 	case len(filePath) >= 4 && filePath[len(filePath)-4:] == ".tsx":
@@ -99,6 +104,12 @@ func DetectLanguage(filePath string) string {
 		return "markdown"
 	case len(filePath) >= 9 && filePath[len(filePath)-9:] == ".markdown":
 		return "markdown"
+	case strings.HasSuffix(filePath, ".dart"):
+		return "dart"
+	case strings.HasSuffix(filePath, ".groovy") || strings.HasSuffix(filePath, ".gvy"):
+		return "groovy"
+	case strings.HasPrefix(filepath.Base(filePath), "Jenkinsfile"):
+		return "groovy"
 	default:
 		return "unknown"
 	}
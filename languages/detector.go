@@ -134,7 +134,7 @@ func GetDefaultIncludesForLanguage(language string) []string {
 	case "go":
 		return []string{"**/*.go"}
 	case "python":
-		return []string{"**/*.py", "**/*.pyi"}
+		return []string{"**/*.py", "**/*.pyi", "**/*.ipynb"}
 	case "java":
 		return []string{"**/*.java"}
 	case "javascript":
@@ -147,6 +147,10 @@ func GetDefaultIncludesForLanguage(language string) []string {
 		return []string{"**/*.rb"}
 	case "markdown":
 		return []string{"**/*.md", "**/*.mdx", "**/*.markdown"}
+	case "dart":
+		return []string{"**/*.dart"}
+	case "groovy":
+		return []string{"**/*.groovy", "**/*.gvy", "**/Jenkinsfile", "**/Jenkinsfile.*"}
 	default:
 		return []string{}
 	}
@@ -95,7 +95,7 @@ func (h *PythonHandler) IsTestFile(filename string) bool {
 
 // GetExtensions returns file extensions
 func (h *PythonHandler) GetExtensions() []string {
-	return []string{".py", ".pyi"}
+	return []string{".py", ".pyi", ".ipynb"}
 }
 
 // GetDefaultLinters returns default linters
@@ -49,7 +49,7 @@ func init() {
 	// Register Python language
 	DefaultRegistry.Register(&LanguageConfig{
 		Name:       "python",
-		Extensions: []string{".py", ".pyw", ".pyi"},
+		Extensions: []string{".py", ".pyw", ".pyi", ".ipynb"},
 		DefaultLinters: []string{
 			"ruff",
 			"pyright",
@@ -88,6 +88,21 @@ func init() {
 		Analyzer: nil, // Will be set when analyzer is created
 	})
 
+	// Register Dart language
+	DefaultRegistry.Register(&LanguageConfig{
+		Name:       "dart",
+		Extensions: []string{".dart"},
+		Analyzer:   nil, // Will be set when analyzer is created
+	})
+
+	// Register Groovy language, including Jenkins pipelines
+	DefaultRegistry.Register(&LanguageConfig{
+		Name:       "groovy",
+		Extensions: []string{".groovy", ".gvy", ".jenkinsfile"},
+		FileNames:  []string{"Jenkinsfile"},
+		Analyzer:   nil, // Will be set when analyzer is created
+	})
+
 	// Register YAML language
 	DefaultRegistry.Register(&LanguageConfig{
 		Name:       "yaml",
@@ -62,6 +62,7 @@ type StyleGuideOption struct {
 type LanguageConfig struct {
 	Name           string
 	Extensions     []string
+	FileNames      []string // Extensionless file names, e.g. "Jenkinsfile" (also matches "Jenkinsfile.release")
 	DefaultLinters []string
 	Analyzer       ASTAnalyzer
 }
@@ -71,6 +72,7 @@ type Registry struct {
 	mu           sync.RWMutex
 	languages    map[string]*LanguageConfig
 	extensionMap map[string]*LanguageConfig
+	fileNameMap  map[string]*LanguageConfig
 	handlers     map[string]LanguageHandler
 }
 
@@ -79,6 +81,7 @@ func NewRegistry() *Registry {
 	return &Registry{
 		languages:    make(map[string]*LanguageConfig),
 		extensionMap: make(map[string]*LanguageConfig),
+		fileNameMap:  make(map[string]*LanguageConfig),
 		handlers:     make(map[string]LanguageHandler),
 	}
 }
@@ -94,6 +97,11 @@ func (r *Registry) Register(lang *LanguageConfig) {
 	for _, ext := range lang.Extensions {
 		r.extensionMap[ext] = lang
 	}
+
+	// Map well-known file names to language
+	for _, name := range lang.FileNames {
+		r.fileNameMap[name] = lang
+	}
 }
 
 // RegisterHandler adds a language handler to the registry
@@ -125,8 +133,19 @@ func (r *Registry) RegisterHandler(handler LanguageHandler) error {
 	return nil
 }
 
-// DetectLanguage determines the language of a file based on its extension
+// DetectLanguage determines the language of a file based on its name or extension
 func (r *Registry) DetectLanguage(filePath string) *LanguageConfig {
+	base := filepath.Base(filePath)
+	if lang, ok := r.fileNameMap[base]; ok {
+		return lang
+	}
+	// Variants such as Jenkinsfile.release
+	if i := strings.Index(base, "."); i > 0 {
+		if lang, ok := r.fileNameMap[base[:i]]; ok {
+			return lang
+		}
+	}
+
 	ext := strings.ToLower(filepath.Ext(filePath))
 	return r.extensionMap[ext]
 }
@@ -195,7 +214,7 @@ func (r *Registry) GetFilePatternForLanguage(language string) string {
 	case "go":
 		return "**/*.go"
 	case "python":
-		return "**/*.py"
+		return "**/*.{py,ipynb}"
 	case "javascript":
 		return "**/*.{js,jsx}"
 	case "typescript":
@@ -206,6 +225,10 @@ func (r *Registry) GetFilePatternForLanguage(language string) string {
 		return "**/*.rs"
 	case "markdown":
 		return "**/*.{md,mdx}"
+	case "dart":
+		return "**/*.dart"
+	case "groovy":
+		return "**/{*.groovy,Jenkinsfile,Jenkinsfile.*}"
 	default:
 		return "**/*"
 	}
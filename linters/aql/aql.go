@@ -4,9 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/coverage"
+	"github.com/flanksource/arch-unit/config"
+	"github.com/flanksource/arch-unit/git"
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/capabilities"
 	"github.com/flanksource/arch-unit/linters"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/arch-unit/parser"
@@ -39,6 +44,11 @@ func (a *AQL) SetOptions(opts linters.RunOptions) {
 	a.RunOptions = opts
 }
 
+// SetCache evaluates the rules against astCache instead of the shared AST cache
+func (a *AQL) SetCache(astCache *cache.ASTCache) {
+	a.astCache = astCache
+}
+
 // NewAQLWithConfig creates a new AQL linter with configuration
 func NewAQLWithConfig(workingDir string, config *models.Config) *AQL {
 	return &AQL{
@@ -95,10 +105,15 @@ func (a *AQL) ValidateConfig(config *models.LinterConfig) error {
 
 // Run executes AQL linting using the modern interface
 func (a *AQL) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Violation, error) {
-	// Initialize AST cache if not already done
+	// Initialize AST cache if not already done, with that of the project checked
 	if a.astCache == nil {
-		a.astCache = cache.MustGetASTCache()
-
+		root, err := filepath.Abs(a.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+		if a.astCache, err = cache.GetProjectASTCache(config.ProjectRoot(root)); err != nil {
+			return nil, fmt.Errorf("failed to open the AST cache: %w", err)
+		}
 	}
 
 	// Initialize components
@@ -137,22 +152,45 @@ func (a *AQL) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Viola
 		}
 	}
 
-	// Get AQL rules from config
-	var aqlRuleConfigs []models.AQLRuleConfig
-	if a.config != nil && len(a.config.AQLRules) > 0 {
+	// Get AQL rules, layers, naming conventions, CEL rules and policies from config
+	var config *models.Config
+	if hasArchitectureRules(a.config) {
 		// Use AQL rules from main configuration
-		aqlRuleConfigs = a.config.AQLRules
-	} else if a.ArchConfig != nil && len(a.ArchConfig.AQLRules) > 0 {
+		config = a.config
+	} else if hasArchitectureRules(a.ArchConfig) {
 		// Use AQL rules from arch config in run options
-		aqlRuleConfigs = a.ArchConfig.AQLRules
+		config = a.ArchConfig
 	}
 
-	if len(aqlRuleConfigs) == 0 {
-		return []models.Violation{}, nil
+	if config == nil {
+		if len(RuleEvaluators()) == 0 {
+			return []models.Violation{}, nil
+		}
+		config = &models.Config{}
+	}
+	aqlRuleConfigs := config.AQLRules
+
+	// Coverage reports provide the coverage metric of AQL rules
+	if len(config.Coverage) > 0 {
+		profile, err := coverage.Load(a.WorkDir, config.Coverage...)
+		if err != nil {
+			return nil, err
+		}
+		annotated, err := coverage.Store(a.astCache, profile)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debugf("Attached coverage of %d files to %d nodes", len(profile.Files), annotated)
+	}
+
+	budget, err := config.GetRuleBudget()
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule_budget %q: %w", config.RuleBudget, err)
 	}
 
 	// Parse and execute AQL rules
 	var allViolations []models.Violation
+	var timings []cache.RuleEvaluation
 
 	for _, ruleConfig := range aqlRuleConfigs {
 		// Skip disabled rules
@@ -160,43 +198,17 @@ func (a *AQL) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Viola
 			continue
 		}
 
-		var ruleText string
-		var sourceFile string
-
-		if ruleConfig.File != "" {
-			// Load from file
-			sourceFile = ruleConfig.File
-			if !filepath.IsAbs(sourceFile) {
-				sourceFile = filepath.Join(a.WorkDir, sourceFile)
-			}
-
-			content, err := os.ReadFile(sourceFile)
-			if err != nil {
-				logger.Warnf("Failed to read AQL rule file %s: %v", sourceFile, err)
-				continue
-			}
-			ruleText = string(content)
-		} else if ruleConfig.Inline != "" {
-			// Use inline rule
-			ruleText = ruleConfig.Inline
-			sourceFile = "inline"
+		ruleText, sourceFile, err := ReadRuleConfig(a.WorkDir, ruleConfig)
+		if err != nil {
+			logger.Warnf("%v", err)
+			continue
 		}
 
 		if ruleText == "" {
 			continue
 		}
 
-		// Parse AQL rules - support both YAML and legacy formats
-		var ruleSet *models.AQLRuleSet
-		var err error
-		if parser.IsLegacyAQLFormat(ruleText) {
-			// Legacy AQL format
-			ruleSet, err = parser.ParseAQL(ruleText)
-		} else {
-			// YAML format
-			ruleSet, err = parser.LoadAQLFromYAML(ruleText)
-		}
-
+		ruleSet, err := ParseRules(ruleText)
 		if err != nil {
 			violation := models.Violation{
 				File:    sourceFile,
@@ -204,13 +216,21 @@ func (a *AQL) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Viola
 				Message: models.StringPtr(fmt.Sprintf("AQL parsing error: %v", err)),
 				Source:  "aql",
 			}
-			allViolations = append(allViolations, violation)
+			allViolations = a.emit(allViolations, violation)
 			continue
 		}
 
+		// Rules without a severity take the one of their config
+		for _, rule := range ruleSet.Rules {
+			if rule.Severity == "" {
+				rule.Severity = ruleConfig.Severity
+			}
+		}
+
 		// Execute AQL rules
 		engine := query.NewAQLEngine(a.astCache)
 		violations, err := engine.ExecuteRuleSet(ruleSet)
+		timings = append(timings, engine.Timings()...)
 		if err != nil {
 			violation := models.Violation{
 				File:    sourceFile,
@@ -218,7 +238,7 @@ func (a *AQL) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Viola
 				Message: models.StringPtr(fmt.Sprintf("AQL execution error: %v", err)),
 				Source:  "aql",
 			}
-			allViolations = append(allViolations, violation)
+			allViolations = a.emit(allViolations, violation)
 			continue
 		}
 
@@ -227,13 +247,277 @@ func (a *AQL) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Viola
 			if v.Source == "" {
 				v.Source = "aql"
 			}
-			allViolations = append(allViolations, *v)
+			allViolations = a.emit(allViolations, *v)
 		}
 	}
 
+	if len(config.Layers) > 0 {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteLayers(config.Layers)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check layers: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if len(config.Naming) > 0 {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteNaming(config.Naming)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check naming conventions: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if len(config.Annotations) > 0 {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteAnnotations(config.Annotations)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check annotation rules: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if len(config.CELRules) > 0 {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteCEL(config.CELRules)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check CEL rules: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if config.Schema.IsEnabled() {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteSchema(config.Schema)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check schema rules: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if config.API.IsEnabled() {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteAPI(config.API)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check API rules: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if config.GodObjects != nil {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteGodObjects(config.GodObjects)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check god objects: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if config.DeadCode != nil {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteDeadCode(config.DeadCode)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check dead code: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if config.Deprecated != nil {
+		engine := query.NewAQLEngine(a.astCache)
+		violations, err := engine.ExecuteDeprecated(config.Deprecated)
+		timings = append(timings, engine.Timings()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check deprecated symbols: %w", err)
+		}
+		for _, v := range violations {
+			allViolations = a.emit(allViolations, *v)
+		}
+	}
+
+	if config.Hotspots != nil {
+		// Hotspots are advisory, without git history there is nothing to report
+		churn, err := git.Churn(a.WorkDir, config.Hotspots.GetSince())
+		if err != nil && !capabilities.IsMissing(err) {
+			logger.Warnf("Skipping hotspots: %v", err)
+		}
+		if err == nil {
+			engine := query.NewAQLEngine(a.astCache)
+			violations, err := engine.ExecuteHotspots(config.Hotspots, churn)
+			timings = append(timings, engine.Timings()...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check hotspots: %w", err)
+			}
+			for _, v := range violations {
+				allViolations = a.emit(allViolations, *v)
+			}
+		}
+	}
+
+	if evaluators := RuleEvaluators(); len(config.Policies) > 0 || len(evaluators) > 0 {
+		engine := query.NewAQLEngine(a.astCache)
+		input, err := engine.PolicyInput()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build policy input: %w", err)
+		}
+		for _, policy := range config.Policies {
+			violations, err := a.EvaluatePolicy(ctx, policy, input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate policy %s: %w", policy.PolicyName(), err)
+			}
+			for _, v := range violations {
+				allViolations = a.emit(allViolations, v)
+			}
+		}
+
+		// Rule evaluators registered by plugins
+		for _, evaluator := range evaluators {
+			start := time.Now()
+			violations, err := evaluator.Evaluate(input)
+			if err != nil {
+				return nil, fmt.Errorf("rule evaluator %s failed: %w", evaluator.Name(), err)
+			}
+			timings = append(timings, cache.RuleEvaluation{
+				Rule:       evaluator.Name(),
+				Duration:   time.Since(start),
+				Violations: len(violations),
+			})
+			for _, v := range violations {
+				if v.Source == "" {
+					v.Source = "aql"
+				}
+				if v.Severity == "" {
+					v.Severity = models.SeverityError
+				}
+				allViolations = a.emit(allViolations, v)
+			}
+		}
+	}
+
+	warnSlowRules(timings, budget)
+	if err := recordRuleTimings(a.WorkDir, timings); err != nil {
+		logger.Debugf("failed to record AQL rule timings: %v", err)
+	}
+
 	return allViolations, nil
 }
 
+// hasArchitectureRules returns true if a config defines AQL rules, layers, naming conventions,
+// annotation rules, CEL rules, policies, schema, API, god-object, dead code, deprecated or hotspot
+// rules
+func hasArchitectureRules(config *models.Config) bool {
+	return config != nil && (len(config.AQLRules) > 0 || len(config.Layers) > 0 || len(config.Naming) > 0 || len(config.Annotations) > 0 ||
+		len(config.CELRules) > 0 || len(config.Policies) > 0 || config.Schema.IsEnabled() || config.API.IsEnabled() ||
+		config.GodObjects != nil || config.DeadCode != nil || config.Deprecated != nil || config.Hotspots != nil)
+}
+
+// emit appends a violation, streaming it first when streaming is enabled so rule violations are
+// reported as each rule set is evaluated
+func (a *AQL) emit(violations []models.Violation, violation models.Violation) []models.Violation {
+	if a.Stream != nil {
+		a.Stream(violation)
+	}
+	return append(violations, violation)
+}
+
+// warnSlowRules warns about every rule that took longer than budget to evaluate, naming its
+// slowest clause
+func warnSlowRules(timings []cache.RuleEvaluation, budget time.Duration) {
+	var slowest *cache.RuleEvaluation
+	for i := range timings {
+		timing := &timings[i]
+		if timing.Clause != "" {
+			if slowest == nil || timing.Duration > slowest.Duration {
+				slowest = timing
+			}
+			continue
+		}
+
+		if budget > 0 && timing.Duration > budget {
+			if slowest != nil {
+				logger.Warnf("AQL rule %q took %s, exceeding the %s budget (slowest clause %s took %s)",
+					timing.Rule, timing.Duration.Round(time.Millisecond), budget, slowest.Clause, slowest.Duration.Round(time.Millisecond))
+			} else {
+				logger.Warnf("AQL rule %q took %s, exceeding the %s budget", timing.Rule, timing.Duration.Round(time.Millisecond), budget)
+			}
+		}
+		slowest = nil
+	}
+}
+
+// recordRuleTimings stores rule timings for "arch-unit rules slowest"
+func recordRuleTimings(workDir string, timings []cache.RuleEvaluation) error {
+	if len(timings) == 0 {
+		return nil
+	}
+
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return err
+	}
+
+	ruleStats, err := cache.NewRuleStats()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ruleStats.Close() }()
+
+	return ruleStats.RecordEvaluations(absWorkDir, timings)
+}
+
+// ReadRuleConfig returns the AQL text of a rule config and the file it was read from,
+// "inline" for rules embedded in arch-unit.yaml
+func ReadRuleConfig(workDir string, ruleConfig models.AQLRuleConfig) (string, string, error) {
+	if ruleConfig.File != "" {
+		sourceFile := ruleConfig.File
+		if !filepath.IsAbs(sourceFile) {
+			sourceFile = filepath.Join(workDir, sourceFile)
+		}
+
+		content, err := os.ReadFile(sourceFile)
+		if err != nil {
+			return "", sourceFile, fmt.Errorf("failed to read AQL rule file %s: %w", sourceFile, err)
+		}
+		return string(content), sourceFile, nil
+	}
+	if ruleConfig.Inline != "" {
+		return ruleConfig.Inline, "inline", nil
+	}
+	return "", "", nil
+}
+
+// ParseRules parses AQL rules in either the YAML or the legacy format
+func ParseRules(ruleText string) (*models.AQLRuleSet, error) {
+	if parser.IsLegacyAQLFormat(ruleText) {
+		return parser.ParseAQL(ruleText)
+	}
+	return parser.LoadAQLFromYAML(ruleText)
+}
+
 // Close cleans up resources
 func (a *AQL) Close() error {
 	if a.astCache != nil {
@@ -0,0 +1,35 @@
+package aql
+
+import (
+	"sync"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// RuleEvaluator evaluates custom rules against the AST graph, registered by plugins and run by
+// the aql linter with the same input as OPA/Rego policies
+type RuleEvaluator interface {
+	// Name is reported with the violations of the evaluator
+	Name() string
+	// Evaluate returns the violations of the rules of the evaluator
+	Evaluate(input *models.PolicyInput) ([]models.Violation, error)
+}
+
+var (
+	evaluatorsMu sync.RWMutex
+	evaluators   []RuleEvaluator
+)
+
+// RegisterRuleEvaluator adds a rule evaluator run by the aql linter
+func RegisterRuleEvaluator(evaluator RuleEvaluator) {
+	evaluatorsMu.Lock()
+	defer evaluatorsMu.Unlock()
+	evaluators = append(evaluators, evaluator)
+}
+
+// RuleEvaluators returns the registered rule evaluators
+func RuleEvaluators() []RuleEvaluator {
+	evaluatorsMu.RLock()
+	defer evaluatorsMu.RUnlock()
+	return append([]RuleEvaluator{}, evaluators...)
+}
@@ -0,0 +1,86 @@
+package aql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// EvaluatePolicy evaluates an OPA/Rego policy with the opa CLI, reporting each violation its
+// query returns at the file and line it names, or at the policy otherwise
+func (a *AQL) EvaluatePolicy(ctx context.Context, policy models.PolicyConfig, input *models.PolicyInput) ([]models.Violation, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode policy input: %w", err)
+	}
+
+	path := policy.File
+	if path != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(a.WorkDir, path)
+	}
+	if policy.Inline != "" {
+		file, err := os.CreateTemp("", "arch-unit-policy-*.rego")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create policy file: %w", err)
+		}
+		defer func() { _ = os.Remove(file.Name()) }()
+		if _, err := file.WriteString(policy.Inline); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to write policy file: %w", err)
+		}
+		if err := file.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write policy file: %w", err)
+		}
+		path = file.Name()
+	}
+
+	cmd, err := a.Command(ctx, "opa", "eval", "--format", "json", "--stdin-input", "--data", path, policy.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("opa eval failed: %w: %s", err, bytes.TrimSpace(append(output, stderr.Bytes()...)))
+	}
+
+	results, err := models.ParsePolicyResult(output)
+	if err != nil {
+		return nil, err
+	}
+
+	source := policy.File
+	if source == "" {
+		source = "inline"
+	}
+	violations := make([]models.Violation, 0, len(results))
+	for _, result := range results {
+		violation := models.Violation{
+			File:    result.File,
+			Line:    result.Line,
+			Message: models.StringPtr(fmt.Sprintf("Policy '%s': %s", policy.PolicyName(), result.Message)),
+			Source:  "aql",
+		}
+		if severity, err := models.ParseSeverity(string(result.Severity)); err == nil {
+			violation.Severity = severity
+		}
+		if violation.File == "" {
+			violation.File, violation.Line = source, 1
+		}
+		if violation.Severity == "" {
+			violation.Severity = policy.Severity
+		}
+		if violation.Severity == "" {
+			violation.Severity = models.SeverityError
+		}
+		violations = append(violations, violation)
+	}
+	return violations, nil
+}
@@ -2,6 +2,7 @@ package archunit
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -32,9 +33,15 @@ func (a *ArchUnit) Name() string {
 	return "arch-unit"
 }
 
+// CachesViolations returns true, as the violations of every file analyzed are cached with its
+// hash, to skip the file until it changes
+func (a *ArchUnit) CachesViolations() bool {
+	return true
+}
+
 // DefaultIncludes returns default file patterns this linter should process
 func (a *ArchUnit) DefaultIncludes() []string {
-	return []string{"**/*.go", "**/*.py"}
+	return []string{"**/*.go", "**/*.py", "**/*.dart"}
 }
 
 // DefaultExcludes returns patterns this linter should ignore by default
@@ -45,6 +52,9 @@ func (a *ArchUnit) DefaultExcludes() []string {
 		".git/**",
 		"**/*_test.go",
 		"**/test_*.py",
+		"**/*_test.dart",
+		"**/*.g.dart",
+		"**/*.freezed.dart",
 	}
 }
 
@@ -92,8 +102,8 @@ func (a *ArchUnit) Start(ctx commonsCtx.Context, task *clicky.Task) ([]models.Vi
 
 // Run executes the arch-unit analysis and returns violations
 func (a *ArchUnit) Run(ctx context.Context, opts linters.RunOptions) ([]models.Violation, error) {
-	// If specific files are provided, filter for Go and Python files
-	var goFiles, pythonFiles []string
+	// If specific files are provided, filter for Go, Python and Dart files
+	var goFiles, pythonFiles, dartFiles []string
 
 	if len(opts.Files) > 0 {
 		for _, file := range opts.Files {
@@ -102,6 +112,8 @@ func (a *ArchUnit) Run(ctx context.Context, opts linters.RunOptions) ([]models.V
 				goFiles = append(goFiles, file)
 			} else if ext == ".py" {
 				pythonFiles = append(pythonFiles, file)
+			} else if ext == ".dart" {
+				dartFiles = append(dartFiles, file)
 			}
 		}
 	} else {
@@ -111,6 +123,10 @@ func (a *ArchUnit) Run(ctx context.Context, opts linters.RunOptions) ([]models.V
 		if err != nil {
 			return nil, fmt.Errorf("failed to find source files: %w", err)
 		}
+		dartFiles, err = files.FindDartFiles(opts.WorkDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find Dart files: %w", err)
+		}
 	}
 
 	// Load configuration - start from the directory containing the files being analyzed
@@ -122,6 +138,9 @@ func (a *ArchUnit) Run(ctx context.Context, opts linters.RunOptions) ([]models.V
 	
 	configParser := config.NewParser(searchDir)
 	archConfig, err := configParser.LoadConfig()
+	if err != nil && !errors.Is(err, config.ErrConfigNotFound) {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
 	if err != nil {
 		// Use smart defaults if no config found
 		archConfig, err = config.CreateSmartDefaultConfig(searchDir)
@@ -160,6 +179,33 @@ func (a *ArchUnit) Run(ctx context.Context, opts linters.RunOptions) ([]models.V
 		totalRules += goResult.RuleCount
 	}
 
+	// Analyze Dart imports, e.g. for presentation -> domain -> data layering
+	if len(dartFiles) > 0 {
+		dartResult, err := analyzeFilesWithCache(dartFiles, archConfig, violationCache, checkDartImports)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze Dart files: %w", err)
+		}
+		allViolations = append(allViolations, dartResult.Violations...)
+		totalFiles += dartResult.FileCount
+		totalRules += dartResult.RuleCount
+	}
+
+	// Check the dependencies declared in go.mod, package.json, Dockerfiles, etc. against rules
+	// such as !dep:github.com/pkg/errors
+	registry := newDependencyRegistry()
+	dependencyFiles, err := findDependencyFiles(registry, opts.WorkDir, opts.Files)
+	if err != nil {
+		return nil, err
+	}
+	if len(dependencyFiles) > 0 {
+		depResult, err := analyzeFilesWithCache(dependencyFiles, archConfig, violationCache, dependencyChecker(registry))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check dependencies: %w", err)
+		}
+		allViolations = append(allViolations, depResult.Violations...)
+		totalFiles += depResult.FileCount
+	}
+
 	// TODO: Implement Python analysis using new architecture
 	// Analyze Python files
 	if len(pythonFiles) > 0 {
@@ -186,7 +232,14 @@ func (a *ArchUnit) Run(ctx context.Context, opts linters.RunOptions) ([]models.V
 
 // analyzeGoFilesWithCache analyzes Go files with caching support
 func analyzeGoFilesWithCache(rootDir string, files []string, config *models.Config, violationCache *cache.ViolationCache) (*models.AnalysisResult, error) {
-	checker := NewViolationChecker()
+	return analyzeFilesWithCache(files, config, violationCache, NewViolationChecker().CheckViolations)
+}
+
+// checkFunc checks a single file against the rules that apply to it
+type checkFunc func(filePath string, rules *models.RuleSet) ([]models.Violation, error)
+
+// analyzeFilesWithCache checks files that changed since they were last cached
+func analyzeFilesWithCache(files []string, config *models.Config, violationCache *cache.ViolationCache, check checkFunc) (*models.AnalysisResult, error) {
 	result := &models.AnalysisResult{
 		FileCount: len(files),
 	}
@@ -230,7 +283,7 @@ func analyzeGoFilesWithCache(rootDir string, files []string, config *models.Conf
 			result.RuleCount += len(rules.Rules)
 		}
 
-		violations, err := checker.CheckViolations(file, rules)
+		violations, err := check(file, rules)
 		if err != nil {
 			return nil, fmt.Errorf("failed to analyze %s: %w", file, err)
 		}
@@ -0,0 +1,58 @@
+package archunit
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/flanksource/arch-unit/analysis/dart"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// checkDartImports checks the imports of a Dart file against architecture rules.
+//
+// Imports are matched by package path, e.g. `!shop/data` in lib/presentation/.ARCHUNIT
+// forbids widgets from importing the data layer directly.
+func checkDartImports(filePath string, rules *models.RuleSet) ([]models.Violation, error) {
+	if rules == nil {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Dart file: %w", err)
+	}
+
+	var violations []models.Violation
+	for _, imp := range dart.ParseImports(filePath, content) {
+		allowed, rule := rules.IsAllowedForFile(imp.Package, "", filePath)
+		if allowed {
+			continue
+		}
+
+		violationMsg := fmt.Sprintf("Import of %s violates architecture rule", imp.Package)
+		if rule.FilePattern != "" {
+			violationMsg = fmt.Sprintf("Import of %s violates file-specific rule [%s]", imp.Package, rule.FilePattern)
+		}
+
+		violations = append(violations, models.Violation{
+			File: filePath,
+			Line: imp.Line,
+			Caller: &models.ASTNode{
+				FilePath:    filePath,
+				PackageName: dart.PackageOf(filePath),
+				StartLine:   imp.Line,
+				NodeType:    models.NodeTypePackage,
+			},
+			Called: &models.ASTNode{
+				PackageName: imp.Package,
+				TypeName:    imp.File,
+				NodeType:    models.NodeTypePackage,
+			},
+			Rule:     rule,
+			Message:  models.StringPtr(violationMsg),
+			Code:     models.StringPtr(imp.Text),
+			Severity: rule.Severity,
+		})
+	}
+	return violations, nil
+}
@@ -0,0 +1,39 @@
+package archunit
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Dart import checker", func() {
+	presentation := filepath.Join("..", "..", "analysis", "dart", "testdata", "shop", "lib", "presentation", "cart_page.dart")
+
+	It("should report imports that skip a layer", func() {
+		rules := &models.RuleSet{Rules: []models.Rule{
+			{Type: models.RuleTypeDeny, Pattern: "shop/data"},
+		}}
+
+		violations, err := checkDartImports(presentation, rules)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Line).To(Equal(4))
+		Expect(violations[0].Called.PackageName).To(Equal("shop/data"))
+		Expect(violations[0].Caller.PackageName).To(Equal("shop/presentation"))
+		Expect(*violations[0].Message).To(ContainSubstring("Import of shop/data"))
+	})
+
+	It("should allow imports of permitted layers", func() {
+		rules := &models.RuleSet{Rules: []models.Rule{
+			{Type: models.RuleTypeDeny, Pattern: "shop/data"},
+			{Type: models.RuleTypeOverride, Pattern: "shop/data", FilePattern: "cart_page.dart"},
+		}}
+
+		violations, err := checkDartImports(presentation, rules)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+})
@@ -0,0 +1,141 @@
+package archunit
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	goAnalysis "github.com/flanksource/arch-unit/analysis/go"
+	"github.com/flanksource/arch-unit/analysis/java"
+	pythonAnalysis "github.com/flanksource/arch-unit/analysis/python"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// newDependencyRegistry returns the scanners of the files declaring dependencies
+func newDependencyRegistry() *analysis.DependencyRegistry {
+	registry := analysis.NewDependencyRegistry()
+	registry.Register(goAnalysis.NewGoDependencyScanner())
+	registry.Register(dependencies.NewNpmDependencyScanner())
+	registry.Register(pythonAnalysis.NewPythonDependencyScanner())
+	registry.Register(java.NewJavaDependencyScanner())
+	registry.Register(dependencies.NewNugetDependencyScanner())
+	registry.Register(dependencies.NewDockerDependencyScanner())
+	registry.Register(dependencies.NewHelmDependencyScanner())
+	registry.Register(dependencies.NewComposeDependencyScanner())
+	registry.Register(dependencies.NewKustomizeDependencyScanner())
+	registry.Register(dependencies.NewTerraformDependencyScanner())
+	registry.Register(dependencies.NewBazelDependencyScanner())
+	return registry
+}
+
+// findDependencyFiles returns the files declaring dependencies, of files when given or under workDir
+func findDependencyFiles(registry *analysis.DependencyRegistry, workDir string, files []string) ([]string, error) {
+	var found []string
+	if len(files) > 0 {
+		for _, file := range files {
+			if _, ok := registry.GetScannerForFile(file); ok {
+				found = append(found, file)
+			}
+		}
+		return found, nil
+	}
+
+	err := filepath.WalkDir(workDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			switch entry.Name() {
+			case ".git", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, ok := registry.GetScannerForFile(path); ok {
+			found = append(found, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find dependency files: %w", err)
+	}
+	return found, nil
+}
+
+// dependencyChecker returns a check of the dependencies declared by a file against rules such as
+// !dep:github.com/pkg/errors or !docker:*:latest, reported at the line declaring them
+func dependencyChecker(registry *analysis.DependencyRegistry) checkFunc {
+	return func(filePath string, rules *models.RuleSet) ([]models.Violation, error) {
+		if rules == nil || !rules.HasDependencyRules() {
+			return nil, nil
+		}
+		scanner, ok := registry.GetScannerForFile(filePath)
+		if !ok {
+			return nil, nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		deps, err := scanner.ScanFile(models.NewScanContext(nil, filepath.Dir(filePath)), filePath, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dependencies of %s: %w", filePath, err)
+		}
+
+		var violations []models.Violation
+		for _, dep := range deps {
+			// Only dependencies declared at a line of the file itself can be reported
+			line := sourceLine(dep.Source)
+			if line == 0 || dep.Depth > 0 {
+				continue
+			}
+			allowed, rule := rules.IsDependencyAllowed(dep, filePath)
+			if allowed {
+				continue
+			}
+
+			name := dep.Name
+			if dep.Version != "" {
+				name += "@" + dep.Version
+			}
+			violationMsg := fmt.Sprintf("Dependency on %s violates architecture rule %s", name, rule.String())
+			if rule.FilePattern != "" {
+				violationMsg = fmt.Sprintf("Dependency on %s violates file-specific rule [%s]", name, rule.FilePattern)
+			}
+
+			violations = append(violations, models.Violation{
+				File: filePath,
+				Line: line,
+				Called: &models.ASTNode{
+					PackageName: dep.Name,
+					FilePath:    filePath,
+					StartLine:   line,
+					NodeType:    models.NodeTypePackage,
+				},
+				Rule:     rule,
+				Message:  models.StringPtr(violationMsg),
+				Severity: rule.Severity,
+			})
+		}
+		return violations, nil
+	}
+}
+
+// sourceLine returns the line of a dependency source such as go.mod:23, or 0 without one
+func sourceLine(source string) int {
+	i := strings.LastIndex(source, ":")
+	if i < 0 {
+		return 0
+	}
+	line, err := strconv.Atoi(source[i+1:])
+	if err != nil {
+		return 0
+	}
+	return line
+}
@@ -0,0 +1,44 @@
+package archunit
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Dependency checker", func() {
+	var dockerfile string
+
+	BeforeEach(func() {
+		dockerfile = filepath.Join(GinkgoT().TempDir(), "Dockerfile")
+		Expect(os.WriteFile(dockerfile, []byte("FROM golang:1.25 AS build\nRUN go build\n\nFROM alpine:latest\n"), 0644)).To(Succeed())
+	})
+
+	It("should report denied dependencies at the line declaring them", func() {
+		rules := &models.RuleSet{Rules: []models.Rule{
+			{Type: models.RuleTypeDeny, Package: "docker", Method: "*:latest", OriginalLine: "!docker:*:latest"},
+		}}
+
+		violations, err := dependencyChecker(newDependencyRegistry())(dockerfile, rules)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].File).To(Equal(dockerfile))
+		Expect(violations[0].Line).To(Equal(4))
+		Expect(violations[0].Called.PackageName).To(Equal("alpine"))
+		Expect(*violations[0].Message).To(ContainSubstring("Dependency on alpine@latest"))
+	})
+
+	It("should skip files without dependency rules", func() {
+		rules := &models.RuleSet{Rules: []models.Rule{
+			{Type: models.RuleTypeDeny, Pattern: "internal"},
+		}}
+
+		violations, err := dependencyChecker(newDependencyRegistry())(dockerfile, rules)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+})
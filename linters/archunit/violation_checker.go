@@ -140,14 +140,15 @@ func (v *ViolationChecker) checkCallExpr(call *ast.CallExpr, rules *models.RuleS
 		}
 
 		return &models.Violation{
-			File:    v.filePath,
-			Line:    pos.Line,
-			Column:  pos.Column,
-			Caller:  callerNode,
-			Called:  calledNode,
-			Rule:    rule,
-			Message: models.StringPtr(violationMsg),
-			Code:    models.StringPtr(sourceCode),
+			File:     v.filePath,
+			Line:     pos.Line,
+			Column:   pos.Column,
+			Caller:   callerNode,
+			Called:   calledNode,
+			Rule:     rule,
+			Message:  models.StringPtr(violationMsg),
+			Code:     models.StringPtr(sourceCode),
+			Severity: rule.Severity,
 		}
 	}
 
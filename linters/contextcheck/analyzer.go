@@ -0,0 +1,309 @@
+package contextcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/linters/gocheck"
+)
+
+// Checks reported by the analyzer
+const (
+	// CheckDroppedContext flags a call made without the context.Context the caller received
+	CheckDroppedContext = "dropped-context"
+	// CheckBackgroundContext flags context.Background() or context.TODO() outside main packages and tests
+	CheckBackgroundContext = "background-context"
+)
+
+// AllChecks lists every check in the order they are documented
+var AllChecks = []string{CheckDroppedContext, CheckBackgroundContext}
+
+// Finding is a call site that does not propagate a context
+type Finding = gocheck.Finding
+
+// pkgInfo records the functions and methods of a package that accept a context
+type pkgInfo struct {
+	accepts map[string]bool // function or Type.Method -> accepts a context
+}
+
+func collectPackage(files []*ast.File) *pkgInfo {
+	pkg := &pkgInfo{accepts: map[string]bool{}}
+	for _, file := range files {
+		alias := contextImport(file)
+		if alias == "" {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && len(contextParams(fn.Type, alias)) > 0 {
+				pkg.accepts[funcName(fn)] = true
+			}
+		}
+	}
+	return pkg
+}
+
+// Analyze reports the call sites of a package that do not propagate a context. All files must
+// belong to the same package so that calls to functions declared in other files resolve.
+// Calls into other packages are only checked when they pass a fresh context.
+func Analyze(fset *token.FileSet, files []*ast.File) []Finding {
+	a := &analyzer{fset: fset, pkg: collectPackage(files)}
+	for _, file := range files {
+		alias := contextImport(file)
+		if alias == "" {
+			continue
+		}
+		// main and tests are where root contexts are meant to be created
+		exempt := file.Name.Name == "main" || strings.HasSuffix(fset.Position(file.Pos()).Filename, "_test.go")
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			f := &function{
+				analyzer: a,
+				alias:    alias,
+				exempt:   exempt || (fn.Recv == nil && fn.Name.Name == "init"),
+				name:     funcName(fn),
+				contexts: map[string]bool{},
+			}
+			if fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 {
+				f.recv = fn.Recv.List[0].Names[0].Name
+				f.recvType = typeName(fn.Recv.List[0].Type)
+			}
+			f.walk(fn.Type, fn.Body)
+		}
+	}
+
+	sort.SliceStable(a.findings, func(i, j int) bool {
+		if a.findings[i].Pos.Filename != a.findings[j].Pos.Filename {
+			return a.findings[i].Pos.Filename < a.findings[j].Pos.Filename
+		}
+		return a.findings[i].Pos.Offset < a.findings[j].Pos.Offset
+	})
+	return a.findings
+}
+
+type analyzer struct {
+	fset     *token.FileSet
+	pkg      *pkgInfo
+	findings []Finding
+}
+
+// function walks the body of a single function declaration, including its closures
+type function struct {
+	*analyzer
+	alias    string // local name of the context package
+	exempt   bool   // may create root contexts
+	name     string
+	recv     string // receiver variable of a method
+	recvType string
+	contexts map[string]bool // variables holding the received context or one derived from it
+}
+
+func (f *function) report(check string, node ast.Node, format string, args ...interface{}) {
+	f.findings = append(f.findings, Finding{
+		Check:    check,
+		Pos:      f.fset.Position(node.Pos()),
+		Function: f.name,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (f *function) walk(ftype *ast.FuncType, body *ast.BlockStmt) {
+	f.receive(ftype)
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			// Closures see the contexts of the enclosing function and may receive their own
+			f.receive(n.Type)
+		case *ast.AssignStmt:
+			f.derive(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			f.derive(lhs, n.Values)
+		case *ast.CallExpr:
+			f.call(n)
+		}
+		return true
+	})
+}
+
+// receive records the named context parameters of a function, blank ones cannot be passed on
+func (f *function) receive(ftype *ast.FuncType) {
+	for _, name := range contextParams(ftype, f.alias) {
+		if name != "_" {
+			f.contexts[name] = true
+		}
+	}
+}
+
+// derive marks the variables assigned from an expression using a received context, such as
+// ctx, cancel := context.WithTimeout(ctx, time.Second), as contexts themselves
+func (f *function) derive(lhs, rhs []ast.Expr) {
+	if len(f.contexts) == 0 {
+		return
+	}
+	for _, value := range rhs {
+		if !f.usesContext(value) {
+			continue
+		}
+		for _, target := range lhs {
+			if ident, ok := target.(*ast.Ident); ok && ident.Name != "_" {
+				f.contexts[ident.Name] = true
+			}
+		}
+		return
+	}
+}
+
+func (f *function) call(call *ast.CallExpr) {
+	if root := f.rootContext(call); root != "" {
+		switch {
+		case len(f.contexts) > 0:
+			f.report(CheckDroppedContext, call, "%s.%s() replaces the context %s received, pass it on instead", f.alias, root, f.name)
+		case !f.exempt:
+			f.report(CheckBackgroundContext, call, "%s.%s() creates a root context outside main, accept a context.Context from the caller", f.alias, root)
+		}
+		return
+	}
+
+	if len(f.contexts) == 0 {
+		return
+	}
+	callee := f.contextCallee(call)
+	if callee == "" {
+		return
+	}
+	for _, arg := range call.Args {
+		// Arguments that create a root context are reported on their own
+		if f.usesContext(arg) || f.createsRootContext(arg) {
+			return
+		}
+	}
+	f.report(CheckDroppedContext, call, "%s accepts a context but is called without the context %s received", callee, f.name)
+}
+
+// rootContext returns Background or TODO when call creates a root context
+func (f *function) rootContext(call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != f.alias {
+		return ""
+	}
+	if sel.Sel.Name == "Background" || sel.Sel.Name == "TODO" {
+		return sel.Sel.Name
+	}
+	return ""
+}
+
+func (f *function) createsRootContext(expr ast.Expr) bool {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	return ok && f.rootContext(call) != ""
+}
+
+// contextCallee returns the name of the called function when it is declared in the package and
+// accepts a context. Without type information methods are only resolved on the method's receiver.
+func (f *function) contextCallee(call *ast.CallExpr) string {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		if f.pkg.accepts[fun.Name] {
+			return fun.Name
+		}
+	case *ast.SelectorExpr:
+		if recv, ok := fun.X.(*ast.Ident); ok && f.recv != "" && recv.Name == f.recv && f.pkg.accepts[f.recvType+"."+fun.Sel.Name] {
+			return recv.Name + "." + fun.Sel.Name
+		}
+	}
+	return ""
+}
+
+// usesContext returns true when expr references a received or derived context
+func (f *function) usesContext(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		if ident, ok := node.(*ast.Ident); ok && f.contexts[ident.Name] {
+			found = true
+		}
+		return true
+	})
+	return found
+}
+
+// contextImport returns the local name of the context package in file, or "" when it is not imported
+func contextImport(file *ast.File) string {
+	for _, imp := range file.Imports {
+		if path, err := strconv.Unquote(imp.Path.Value); err != nil || path != "context" {
+			continue
+		}
+		if imp.Name == nil {
+			return "context"
+		}
+		// Blank and dot imports cannot be referenced as a package
+		if imp.Name.Name != "_" && imp.Name.Name != "." {
+			return imp.Name.Name
+		}
+	}
+	return ""
+}
+
+// contextParams returns the names of the context.Context parameters of a function
+func contextParams(ftype *ast.FuncType, alias string) []string {
+	var names []string
+	if ftype.Params == nil {
+		return nil
+	}
+	for _, field := range ftype.Params.List {
+		sel, ok := field.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Context" {
+			continue
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != alias {
+			continue
+		}
+		if len(field.Names) == 0 {
+			// Unnamed parameters still make the function accept a context
+			names = append(names, "_")
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// funcName returns the name of a function, Type.Method for methods
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		return typeName(fn.Recv.List[0].Type) + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// typeName returns the type name of a receiver, e.g. Store for (s *Store[T])
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	case *ast.IndexListExpr:
+		return typeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
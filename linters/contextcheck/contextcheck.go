@@ -0,0 +1,80 @@
+package contextcheck
+
+import (
+	"context"
+
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/linters/gocheck"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// ContextCheck reports Go call sites that do not propagate a context.Context
+type ContextCheck struct {
+	linters.RunOptions
+	fileCount int
+}
+
+// NewContextCheck creates a new context propagation linter
+func NewContextCheck(workDir string) *ContextCheck {
+	return &ContextCheck{RunOptions: linters.RunOptions{WorkDir: workDir}}
+}
+
+// Name returns the linter name
+func (c *ContextCheck) Name() string {
+	return "contextcheck"
+}
+
+// DefaultIncludes returns default file patterns this linter should process
+func (c *ContextCheck) DefaultIncludes() []string {
+	return []string{"**/*.go"}
+}
+
+// DefaultExcludes returns patterns this linter should ignore by default
+func (c *ContextCheck) DefaultExcludes() []string {
+	return []string{"vendor/**", ".git/**", "**/*_test.go"}
+}
+
+// SupportsJSON returns true if linter supports JSON output
+func (c *ContextCheck) SupportsJSON() bool {
+	return true
+}
+
+// JSONArgs returns additional args needed for JSON output
+func (c *ContextCheck) JSONArgs() []string {
+	return []string{}
+}
+
+// SupportsFix returns true if linter supports auto-fixing violations
+func (c *ContextCheck) SupportsFix() bool {
+	return false
+}
+
+// FixArgs returns additional args needed for fix mode
+func (c *ContextCheck) FixArgs() []string {
+	return []string{}
+}
+
+// ValidateConfig validates linter-specific configuration
+func (c *ContextCheck) ValidateConfig(config *models.LinterConfig) error {
+	return nil
+}
+
+// GetFileCount returns the number of files analyzed by the last run
+func (c *ContextCheck) GetFileCount() int {
+	return c.fileCount
+}
+
+// GetRuleCount returns the number of checks the linter applies
+func (c *ContextCheck) GetRuleCount() int {
+	return len(AllChecks)
+}
+
+// Run analyzes the Go files, one package directory at a time, and returns violations for the
+// checks enabled by the quality.context_checks configuration of each file
+func (c *ContextCheck) Run(ctx context.Context, opts linters.RunOptions) ([]models.Violation, error) {
+	violations, fileCount, err := gocheck.Run(c.Name(), opts, Analyze, func(quality *models.QualityConfig) *models.CheckSelection {
+		return quality.ContextChecks
+	})
+	c.fileCount = fileCount
+	return violations, err
+}
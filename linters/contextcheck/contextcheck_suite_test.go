@@ -0,0 +1,13 @@
+package contextcheck
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestContextCheck(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ContextCheck Linter Suite")
+}
@@ -0,0 +1,77 @@
+package contextcheck
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/linters/gocheck"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Context propagation analyzer", func() {
+	service := filepath.Join("testdata", "service")
+
+	lines := func(findings []Finding, check string) []int {
+		var result []int
+		for _, finding := range findings {
+			if finding.Check == check {
+				result = append(result, finding.Pos.Line)
+			}
+		}
+		return result
+	}
+
+	It("should report calls that drop the received context", func() {
+		findings, err := gocheck.AnalyzeDir(service, Analyze)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Dropped passes fresh contexts and Forgotten none; Handle, Derived, Closure and Ignored are safe
+		Expect(lines(findings, CheckDroppedContext)).To(Equal([]int{40, 44, 50}))
+		Expect(findings[0].Function).To(Equal("Service.Dropped"))
+		Expect(findings[0].Message).To(Equal("context.Background() replaces the context Service.Dropped received, pass it on instead"))
+		Expect(findings[2].Message).To(Equal("save accepts a context but is called without the context Forgotten received"))
+	})
+
+	It("should report root contexts created outside main", func() {
+		findings, err := gocheck.AnalyzeDir(service, Analyze)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lines(findings, CheckBackgroundContext)).To(Equal([]int{63}))
+
+		findings, err = gocheck.AnalyzeDir(filepath.Join("testdata", "cmd"), Analyze)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(BeEmpty())
+	})
+
+	It("should apply the context_checks configuration of each path", func() {
+		workDir, err := filepath.Abs(service)
+		Expect(err).NotTo(HaveOccurred())
+
+		archConfig := &models.Config{Rules: map[string]models.RuleConfig{
+			"*.go": {Quality: &models.QualityConfig{
+				ContextChecks: &models.CheckSelection{Checks: []string{CheckBackgroundContext}},
+			}},
+		}}
+
+		violations, err := NewContextCheck(workDir).Run(context.Background(), linters.RunOptions{
+			WorkDir:    workDir,
+			ArchConfig: archConfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Source).To(Equal("contextcheck"))
+		Expect(violations[0].Line).To(Equal(63))
+		Expect(violations[0].Rule.Method).To(Equal(CheckBackgroundContext))
+
+		archConfig.Rules["*.go"].Quality.ContextChecks.Disabled = true
+		violations, err = NewContextCheck(workDir).Run(context.Background(), linters.RunOptions{
+			WorkDir:    workDir,
+			ArchConfig: archConfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+})
@@ -0,0 +1,10 @@
+package contextcheck
+
+import (
+	"github.com/flanksource/arch-unit/linters"
+)
+
+func init() {
+	// Register the context propagation linter with the default registry
+	linters.DefaultRegistry.Register(NewContextCheck("."))
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+func main() {
+	ctx := context.Background()
+	fmt.Println(ctx)
+}
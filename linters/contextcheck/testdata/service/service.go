@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type Service struct {
+	name string
+}
+
+func (s *Service) load(ctx context.Context, id string) (string, error) {
+	return s.name + id, ctx.Err()
+}
+
+func save(ctx context.Context, value string) error {
+	return ctx.Err()
+}
+
+// Handle passes ctx everywhere
+func (s *Service) Handle(ctx context.Context, id string) error {
+	value, err := s.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	return save(ctx, value)
+}
+
+// Derived passes a context derived from ctx
+func (s *Service) Derived(ctx context.Context, id string) error {
+	timeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err := s.load(timeout, id)
+	return err
+}
+
+// Dropped calls a function accepting a context with a fresh one
+func (s *Service) Dropped(ctx context.Context, id string) error {
+	value, err := s.load(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	return save(context.TODO(), value)
+}
+
+// Forgotten calls a function accepting a context without any
+func Forgotten(ctx context.Context, s *Service) {
+	go func() {
+		fmt.Println(save(nil, s.name))
+	}()
+}
+
+// Closure receives its own context
+func Closure(run func(func(context.Context) error) error) error {
+	return run(func(ctx context.Context) error {
+		return save(ctx, "closure")
+	})
+}
+
+// Detached creates a root context without receiving one
+func Detached(value string) error {
+	return save(context.Background(), value)
+}
+
+// Ignored discards its context, which cannot be passed on
+func Ignored(_ context.Context, value string) string {
+	return fmt.Sprint(value)
+}
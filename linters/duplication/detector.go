@@ -0,0 +1,274 @@
+package duplication
+
+import (
+	"hash/fnv"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// hashBase is the base of the rolling hash of token windows
+const hashBase = 1000003
+
+// hashComments are the extensions of the languages whose line comments start with #
+var hashComments = map[string]bool{
+	".py": true, ".rb": true, ".sh": true, ".bash": true, ".pl": true, ".r": true,
+	".yaml": true, ".yml": true, ".toml": true, ".tf": true,
+}
+
+// Token is a word, literal or punctuation of a source file
+type Token struct {
+	Text string
+	Line int
+}
+
+// SourceFile is a tokenized source file
+type SourceFile struct {
+	Path   string
+	Tokens []Token
+}
+
+// Location is the line range of a source file spanned by a clone
+type Location struct {
+	File      string
+	StartLine int
+	EndLine   int
+}
+
+// Clone is a sequence of tokens found at several locations
+type Clone struct {
+	Tokens    int
+	Locations []Location
+}
+
+// Tokenize splits source code into words, numbers, string literals and punctuation, skipping
+// whitespace and the comments of the language of its extension
+func Tokenize(content string, ext string) []Token {
+	lineComment := "//"
+	if hashComments[strings.ToLower(ext)] {
+		lineComment = "#"
+	}
+
+	var tokens []Token
+	line := 1
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(content[i:], lineComment):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end
+		case lineComment == "//" && strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			line += strings.Count(content[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'' || c == '`':
+			end := stringEnd(content, i)
+			tokens = append(tokens, Token{Text: content[i:end], Line: line})
+			line += strings.Count(content[i:end], "\n")
+			i = end
+		case isWordChar(c):
+			start := i
+			for i < len(content) && isWordChar(content[i]) {
+				i++
+			}
+			tokens = append(tokens, Token{Text: content[start:i], Line: line})
+		default:
+			tokens = append(tokens, Token{Text: string(c), Line: line})
+			i++
+		}
+	}
+	return tokens
+}
+
+// stringEnd returns the offset after the string literal starting at i, where backslashes escape
+// the next character outside of backtick strings
+func stringEnd(content string, i int) int {
+	quote := content[i]
+	for j := i + 1; j < len(content); j++ {
+		switch content[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case '\n':
+			if quote != '`' {
+				return j
+			}
+		case quote:
+			return j + 1
+		}
+	}
+	return len(content)
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// position is the index of a token of a file
+type position struct {
+	file  int
+	index int
+}
+
+// FindClones returns the sequences of at least minTokens tokens found at several locations of
+// files, longest first. Each location is reported once, as part of the longest clone starting
+// before it.
+func FindClones(files []SourceFile, minTokens int) []Clone {
+	if minTokens <= 0 {
+		return nil
+	}
+
+	hashes := make([][]uint64, len(files))
+	index := make(map[uint64][]position)
+	for f, file := range files {
+		hashes[f] = windowHashes(file.Tokens, minTokens)
+		for i, h := range hashes[f] {
+			index[h] = append(index[h], position{file: f, index: i})
+		}
+	}
+
+	covered := make([][]bool, len(files))
+	for f, file := range files {
+		covered[f] = make([]bool, len(file.Tokens))
+	}
+
+	var clones []Clone
+	for f := range files {
+		for i, h := range hashes[f] {
+			if covered[f][i] {
+				continue
+			}
+
+			members := []position{{file: f, index: i}}
+			for _, other := range index[h] {
+				if overlaps(members, other, minTokens) || !equalTokens(files, members[0], other, minTokens) {
+					continue
+				}
+				members = append(members, other)
+			}
+			if len(members) < 2 {
+				continue
+			}
+
+			length := extend(files, members, minTokens)
+			clone := Clone{Tokens: length}
+			for _, member := range members {
+				tokens := files[member.file].Tokens
+				for k := member.index; k < member.index+length; k++ {
+					covered[member.file][k] = true
+				}
+				clone.Locations = append(clone.Locations, Location{
+					File:      files[member.file].Path,
+					StartLine: tokens[member.index].Line,
+					EndLine:   tokens[member.index+length-1].Line,
+				})
+			}
+			sort.Slice(clone.Locations, func(a, b int) bool {
+				if clone.Locations[a].File != clone.Locations[b].File {
+					return clone.Locations[a].File < clone.Locations[b].File
+				}
+				return clone.Locations[a].StartLine < clone.Locations[b].StartLine
+			})
+			clones = append(clones, clone)
+		}
+	}
+
+	sort.SliceStable(clones, func(a, b int) bool {
+		return clones[a].Tokens > clones[b].Tokens
+	})
+	return clones
+}
+
+// windowHashes returns the rolling hash of every window of n tokens
+func windowHashes(tokens []Token, n int) []uint64 {
+	if len(tokens) < n {
+		return nil
+	}
+
+	// power is hashBase^(n-1), the weight of the token leaving the window
+	power := uint64(1)
+	for k := 1; k < n; k++ {
+		power *= hashBase
+	}
+
+	hashes := make([]uint64, 0, len(tokens)-n+1)
+	var h uint64
+	for k, token := range tokens {
+		if k >= n {
+			h -= tokenHash(tokens[k-n].Text) * power
+		}
+		h = h*hashBase + tokenHash(token.Text)
+		if k >= n-1 {
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes
+}
+
+func tokenHash(text string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(text))
+	return h.Sum64()
+}
+
+// overlaps returns true if a window of n tokens at p overlaps the window of a member of the same
+// file, or is one of them
+func overlaps(members []position, p position, n int) bool {
+	for _, member := range members {
+		if member.file == p.file && member.index-n < p.index && p.index < member.index+n {
+			return true
+		}
+	}
+	return false
+}
+
+// equalTokens returns true if the n tokens at a and b are the same, ruling out hash collisions
+func equalTokens(files []SourceFile, a, b position, n int) bool {
+	ta, tb := files[a.file].Tokens, files[b.file].Tokens
+	for k := 0; k < n; k++ {
+		if ta[a.index+k].Text != tb[b.index+k].Text {
+			return false
+		}
+	}
+	return true
+}
+
+// extend returns the number of tokens, from n on, for which every member matches the first
+// without running into the next member of the same file
+func extend(files []SourceFile, members []position, n int) int {
+	first := files[members[0].file].Tokens
+	for length := n; ; length++ {
+		for _, member := range members {
+			tokens := files[member.file].Tokens
+			if member.index+length >= len(tokens) || tokens[member.index+length].Text != first[members[0].index+length].Text {
+				return length
+			}
+			for _, other := range members {
+				if other != member && other.file == member.file && other.index > member.index && member.index+length >= other.index {
+					return length
+				}
+			}
+		}
+	}
+}
+
+// relativeTo returns path relative to dir, or path when it is outside of dir
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,61 @@
+package duplication
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Duplicate code detector", func() {
+	body := `func sum(items []int) int {
+	total := 0
+	for _, item := range items {
+		total += item // add
+	}
+	return total
+}
+`
+
+	It("should skip whitespace and comments", func() {
+		tokens := Tokenize("x := \"a // b\" /* c\n d */ + y # z\n", ".go")
+
+		var texts []string
+		for _, token := range tokens {
+			texts = append(texts, token.Text)
+		}
+		Expect(texts).To(Equal([]string{"x", ":", "=", `"a // b"`, "+", "y", "#", "z"}))
+		Expect(tokens[4].Line).To(Equal(2))
+
+		Expect(Tokenize("x = 1 # comment\n", ".py")).To(HaveLen(3))
+	})
+
+	It("should report every location of a clone with its lines", func() {
+		clones := FindClones([]SourceFile{
+			{Path: "a.go", Tokens: Tokenize("package a\n\n"+body, ".go")},
+			{Path: "b.go", Tokens: Tokenize("package b\n\nvar x = 1\n\n"+body+"\nfunc other() {}\n", ".go")},
+		}, 20)
+
+		Expect(clones).To(HaveLen(1))
+		Expect(clones[0].Tokens).To(Equal(31))
+		Expect(clones[0].Locations).To(Equal([]Location{
+			{File: "a.go", StartLine: 3, EndLine: 9},
+			{File: "b.go", StartLine: 5, EndLine: 11},
+		}))
+	})
+
+	It("should find clones within a file without overlapping them", func() {
+		clones := FindClones([]SourceFile{{Path: "c.go", Tokens: Tokenize(body+body, ".go")}}, 20)
+
+		Expect(clones).To(HaveLen(1))
+		Expect(clones[0].Locations).To(Equal([]Location{
+			{File: "c.go", StartLine: 1, EndLine: 7},
+			{File: "c.go", StartLine: 8, EndLine: 14},
+		}))
+	})
+
+	It("should ignore sequences shorter than the minimum", func() {
+		Expect(FindClones([]SourceFile{
+			{Path: "a.go", Tokens: Tokenize(body, ".go")},
+			{Path: "b.go", Tokens: Tokenize(body, ".go")},
+		}, 100)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,189 @@
+package duplication
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/files"
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// RuleDuplicateCode is the rule of the violations of the duplication linter
+const RuleDuplicateCode = "duplicate-code"
+
+// Duplication reports copy-pasted code across the files indexed by ast analyze, at every
+// location of each clone
+type Duplication struct {
+	linters.RunOptions
+	fileCount int
+}
+
+// NewDuplication creates a new duplicate code linter
+func NewDuplication(workDir string) *Duplication {
+	return &Duplication{RunOptions: linters.RunOptions{WorkDir: workDir}}
+}
+
+// Name returns the linter name
+func (d *Duplication) Name() string {
+	return "duplication"
+}
+
+// DefaultIncludes returns default file patterns this linter should process
+func (d *Duplication) DefaultIncludes() []string {
+	return []string{"**/*"}
+}
+
+// DefaultExcludes returns patterns this linter should ignore by default
+func (d *Duplication) DefaultExcludes() []string {
+	return []string{"vendor/**", "node_modules/**", ".git/**"}
+}
+
+// SupportsJSON returns true if linter supports JSON output
+func (d *Duplication) SupportsJSON() bool {
+	return true
+}
+
+// JSONArgs returns additional args needed for JSON output
+func (d *Duplication) JSONArgs() []string {
+	return []string{}
+}
+
+// SupportsFix returns true if linter supports auto-fixing violations
+func (d *Duplication) SupportsFix() bool {
+	return false
+}
+
+// FixArgs returns additional args needed for fix mode
+func (d *Duplication) FixArgs() []string {
+	return []string{}
+}
+
+// ValidateConfig validates linter-specific configuration
+func (d *Duplication) ValidateConfig(config *models.LinterConfig) error {
+	return nil
+}
+
+// GetFileCount returns the number of files analyzed by the last run
+func (d *Duplication) GetFileCount() int {
+	return d.fileCount
+}
+
+// GetRuleCount returns the number of checks the linter applies
+func (d *Duplication) GetRuleCount() int {
+	return 1
+}
+
+// Run compares the tokens of the indexed files of the work directory and reports every clone of
+// at least duplication.min_tokens tokens at each of its locations. When files are given, only the
+// locations in them are reported, while clones are still searched across every file.
+func (d *Duplication) Run(ctx context.Context, opts linters.RunOptions) ([]models.Violation, error) {
+	var config *models.DuplicationConfig
+	if opts.ArchConfig != nil {
+		config = opts.ArchConfig.Duplication
+	}
+
+	paths, err := indexedFiles(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []SourceFile
+	for _, path := range paths {
+		if models.IsTestFile(path) || config.IsIgnored(relativeTo(opts.WorkDir, path)) {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			// Virtual paths such as sql:// and files deleted since they were indexed
+			continue
+		}
+		sources = append(sources, SourceFile{Path: path, Tokens: Tokenize(string(content), filepath.Ext(path))})
+	}
+	d.fileCount = len(sources)
+
+	requested := make(map[string]bool, len(opts.Files))
+	for _, file := range opts.Files {
+		if abs, err := filepath.Abs(file); err == nil {
+			requested[abs] = true
+		}
+	}
+
+	var violations []models.Violation
+	for _, clone := range FindClones(sources, config.GetMinTokens()) {
+		for i, location := range clone.Locations {
+			if len(requested) > 0 && !requested[location.File] {
+				continue
+			}
+
+			var others []string
+			for j, other := range clone.Locations {
+				if j != i {
+					others = append(others, fmt.Sprintf("%s:%d-%d", relativeTo(opts.WorkDir, other.File), other.StartLine, other.EndLine))
+				}
+			}
+			message := fmt.Sprintf("Duplicate code of %d tokens (lines %d-%d) also found at %s",
+				clone.Tokens, location.StartLine, location.EndLine, strings.Join(others, ", "))
+			violations = append(violations, models.NewViolationBuilder().
+				WithFile(location.File).
+				WithLocation(location.StartLine, 0).
+				WithCaller(filepath.Dir(location.File), "").
+				WithCalled(d.Name(), RuleDuplicateCode).
+				WithMessage(message).
+				WithSource(d.Name()).
+				WithSeverity(config.GetSeverity()).
+				WithRuleFromLinter(d.Name(), RuleDuplicateCode).
+				Build())
+		}
+	}
+
+	sort.SliceStable(violations, func(a, b int) bool {
+		if violations[a].File != violations[b].File {
+			return violations[a].File < violations[b].File
+		}
+		return violations[a].Line < violations[b].Line
+	})
+	logger.Debugf("Found %d duplicate code locations in %d files", len(violations), len(sources))
+	return violations, nil
+}
+
+// indexedFiles returns the files under workDir indexed by ast analyze, or its Go, Python and
+// Dart source files when none are indexed
+func indexedFiles(workDir string) ([]string, error) {
+	root, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", workDir, err)
+	}
+
+	var paths []string
+	if astCache, err := cache.GetASTCache(); err == nil {
+		if err := astCache.GetReadQuery().Model(&models.FileMetadata{}).
+			Where("file_path LIKE ?", root+string(filepath.Separator)+"%").
+			Order("file_path").
+			Pluck("file_path", &paths).Error; err != nil {
+			return nil, fmt.Errorf("failed to query indexed files: %w", err)
+		}
+	}
+	if len(paths) > 0 {
+		return paths, nil
+	}
+
+	logger.Debugf("No files of %s are indexed, checking its source files", root)
+	goFiles, pythonFiles, err := files.FindSourceFiles(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find source files: %w", err)
+	}
+	dartFiles, err := files.FindDartFiles(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Dart files: %w", err)
+	}
+	paths = append(append(goFiles, pythonFiles...), dartFiles...)
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,13 @@
+package duplication
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDuplication(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Duplication Linter Suite")
+}
@@ -0,0 +1,10 @@
+package duplication
+
+import (
+	"github.com/flanksource/arch-unit/linters"
+)
+
+func init() {
+	// Register the duplicate code linter with the default registry
+	linters.DefaultRegistry.Register(NewDuplication("."))
+}
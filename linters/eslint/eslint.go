@@ -145,7 +145,10 @@ func (e *ESLint) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Vi
 	}
 
 	// Execute command
-	cmd := exec.CommandContext(ctx, "eslint", args...)
+	cmd, err := e.Command(ctx, "eslint", args...)
+	if err != nil {
+		return nil, err
+	}
 	cmd.Dir = e.WorkDir
 
 	logger.Infof("Executing: eslint %s", strings.Join(args, " "))
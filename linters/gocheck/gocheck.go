@@ -0,0 +1,156 @@
+// Package gocheck runs the analyzers of the nilcheck, contextcheck and txcheck linters over the
+// Go packages of a run.
+//
+// The analyzers parse the package sources with go/parser, as the Go AST extractor does, rather
+// than reading the statements it stores: stored statements keep the text and classification of a
+// statement but not its expressions, while the analyzers follow the identifiers assigned, compared
+// and dereferenced by each expression, and the variables captured by closures.
+package gocheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/files"
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// Finding is a problem reported by an analyzer at a position of a Go file
+type Finding struct {
+	Check    string
+	Pos      token.Position
+	Function string // enclosing function, Type.Method for methods, when the analyzer records it
+	Message  string
+}
+
+// Analyzer analyzes the parsed files of a single package
+type Analyzer func(fset *token.FileSet, files []*ast.File) []Finding
+
+// Enabled returns the checks selection of a quality configuration, e.g. its nil_checks
+type Enabled func(quality *models.QualityConfig) *models.CheckSelection
+
+// Run analyzes the non-test Go files of a run, one package directory at a time, and returns
+// the violations of a linter for the findings in the requested files whose check is enabled by
+// the most specific quality configuration of the file, with the number of files analyzed
+func Run(linter string, opts linters.RunOptions, analyze Analyzer, enabled Enabled) ([]models.Violation, int, error) {
+	var goFiles []string
+	if len(opts.Files) > 0 {
+		for _, file := range opts.Files {
+			if filepath.Ext(file) == ".go" && !strings.HasSuffix(file, "_test.go") {
+				goFiles = append(goFiles, file)
+			}
+		}
+	} else {
+		var err error
+		goFiles, _, err = files.FindSourceFiles(opts.WorkDir)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to find source files: %w", err)
+		}
+	}
+
+	requested := make(map[string]bool, len(goFiles))
+	dirs := make(map[string]bool)
+	for _, file := range goFiles {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			abs = file
+		}
+		requested[abs] = true
+		dirs[filepath.Dir(abs)] = true
+	}
+
+	var sortedDirs []string
+	for dir := range dirs {
+		sortedDirs = append(sortedDirs, dir)
+	}
+	sort.Strings(sortedDirs)
+
+	var violations []models.Violation
+	for _, dir := range sortedDirs {
+		findings, err := AnalyzeDir(dir, analyze)
+		if err != nil {
+			logger.Warnf("Failed to analyze %s with %s: %v", dir, linter, err)
+			continue
+		}
+
+		for _, finding := range findings {
+			if !requested[finding.Pos.Filename] || !isEnabled(opts, finding, enabled) {
+				continue
+			}
+			function := finding.Function
+			if function == "" {
+				function = "unknown"
+			}
+			violations = append(violations, models.NewViolationBuilder().
+				WithFile(finding.Pos.Filename).
+				WithLocation(finding.Pos.Line, finding.Pos.Column).
+				WithCaller(filepath.Dir(finding.Pos.Filename), function).
+				WithCalled(linter, finding.Check).
+				WithMessage(finding.Message).
+				WithSource(linter).
+				WithRuleFromLinter(linter, finding.Check).
+				Build())
+		}
+	}
+
+	logger.Debugf("%s found %d violations in %d files", linter, len(violations), len(goFiles))
+	return violations, len(goFiles), nil
+}
+
+// isEnabled applies the most specific quality configuration for the finding's file
+func isEnabled(opts linters.RunOptions, finding Finding, enabled Enabled) bool {
+	if opts.ArchConfig == nil {
+		return true
+	}
+	relPath, err := filepath.Rel(opts.WorkDir, finding.Pos.Filename)
+	if err != nil {
+		relPath = finding.Pos.Filename
+	}
+	quality := opts.ArchConfig.GetQualityConfig(relPath)
+	if quality == nil {
+		return true
+	}
+	return enabled(quality).IsCheckEnabled(finding.Check)
+}
+
+// AnalyzeDir parses the non-test Go files of a directory and analyzes each package in it
+func AnalyzeDir(dir string, analyze Analyzer) ([]Finding, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	packages := make(map[string][]*ast.File)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			logger.Debugf("Skipping %s: %v", name, err)
+			continue
+		}
+		pkg := file.Name.Name
+		if _, ok := packages[pkg]; !ok {
+			names = append(names, pkg)
+		}
+		packages[pkg] = append(packages[pkg], file)
+	}
+
+	var findings []Finding
+	for _, pkg := range names {
+		findings = append(findings, analyze(fset, packages[pkg])...)
+	}
+	return findings, nil
+}
@@ -137,7 +137,10 @@ func (g *GolangciLint) Run(ctx commonsContext.Context, task *clicky.Task) ([]mod
 	}
 
 	// Execute command
-	cmd := exec.CommandContext(ctx, "golangci-lint", args...)
+	cmd, err := g.Command(ctx, "golangci-lint", args...)
+	if err != nil {
+		return nil, err
+	}
 	cmd.Dir = g.WorkDir
 
 	logger.Infof("Executing: golangci-lint %s", strings.Join(args, " "))
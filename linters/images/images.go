@@ -0,0 +1,299 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// DefaultRegistry is the registry of images referenced without one, e.g. nginx:1.27
+const DefaultRegistry = "docker.io"
+
+// Images reports the Docker images violating the tag, digest and registry policy configured by
+// images, at the line of the Dockerfile, compose file, kustomization or Helm values referencing them
+type Images struct {
+	linters.RunOptions
+	fileCount int
+}
+
+// NewImages creates a new image policy linter
+func NewImages(workDir string) *Images {
+	return &Images{RunOptions: linters.RunOptions{WorkDir: workDir}}
+}
+
+// Name returns the linter name
+func (i *Images) Name() string {
+	return "images"
+}
+
+// DefaultIncludes returns default file patterns this linter should process
+func (i *Images) DefaultIncludes() []string {
+	return newRegistry().GetAllSupportedFiles()
+}
+
+// DefaultExcludes returns patterns this linter should ignore by default
+func (i *Images) DefaultExcludes() []string {
+	return []string{"vendor/**", "node_modules/**", ".git/**"}
+}
+
+// SupportsJSON returns true if linter supports JSON output
+func (i *Images) SupportsJSON() bool {
+	return true
+}
+
+// JSONArgs returns additional args needed for JSON output
+func (i *Images) JSONArgs() []string {
+	return []string{}
+}
+
+// SupportsFix returns true if linter supports auto-fixing violations
+func (i *Images) SupportsFix() bool {
+	return false
+}
+
+// FixArgs returns additional args needed for fix mode
+func (i *Images) FixArgs() []string {
+	return []string{}
+}
+
+// ValidateConfig validates linter-specific configuration
+func (i *Images) ValidateConfig(config *models.LinterConfig) error {
+	return nil
+}
+
+// GetFileCount returns the number of files referencing images checked by the last run
+func (i *Images) GetFileCount() int {
+	return i.fileCount
+}
+
+// GetRuleCount returns the number of image rules, forbid-latest, require-digest and
+// allowed-registries
+func (i *Images) GetRuleCount() int {
+	return 3
+}
+
+// newRegistry returns the scanners of the files referencing Docker images
+func newRegistry() *analysis.DependencyRegistry {
+	registry := analysis.NewDependencyRegistry()
+	registry.Register(dependencies.NewDockerDependencyScanner())
+	registry.Register(dependencies.NewHelmDependencyScanner())
+	registry.Register(dependencies.NewComposeDependencyScanner())
+	registry.Register(dependencies.NewKustomizeDependencyScanner())
+	return registry
+}
+
+// Run scans the images referenced under the working directory and checks them against the policy
+func (i *Images) Run(ctx context.Context, opts linters.RunOptions) ([]models.Violation, error) {
+	var policy *models.ImagePolicyConfig
+	if opts.ArchConfig != nil {
+		policy = opts.ArchConfig.Images
+	}
+	if !policy.IsEnabled() {
+		logger.Debugf("No image policy configured, skipping image checks")
+		return nil, nil
+	}
+
+	registry := newRegistry()
+	if len(opts.Files) > 0 && !touchesImages(registry, opts.Files) {
+		logger.Debugf("No file referencing images changed, skipping image checks")
+		return nil, nil
+	}
+
+	deps, fileCount, err := scanImages(registry, opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	i.fileCount = fileCount
+
+	var violations []models.Violation
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		// Images of subcharts are only checked where their values file is part of the tree, as scanned on its own
+		if dep.Type != models.DependencyTypeDocker || dep.Source == "" || dep.Depth > 0 {
+			continue
+		}
+		// Images of unresolved build args or templates are not known until build time
+		if strings.ContainsAny(dep.Name+dep.Version, "${}") {
+			continue
+		}
+		key := dep.Name + "@" + dep.Version + ":" + dep.Source
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		file, line := sourceLocation(opts.WorkDir, dep.Source)
+		ref := ParseReference(dep.Name, dep.Version)
+		if policy.IsIgnored(dep.Name, ref.Repository) {
+			continue
+		}
+		for _, check := range i.check(policy, ref) {
+			violations = append(violations, models.NewViolationBuilder().
+				WithFile(file).
+				WithLocation(line, 0).
+				WithCaller(dep.Name, dep.Version).
+				WithCalled(i.Name(), check.rule).
+				WithMessage(check.message).
+				WithSource(i.Name()).
+				WithRuleFromLinter(i.Name(), check.rule).
+				Build())
+		}
+	}
+
+	sort.SliceStable(violations, func(a, b int) bool {
+		if violations[a].File != violations[b].File {
+			return violations[a].File < violations[b].File
+		}
+		return violations[a].Line < violations[b].Line
+	})
+	logger.Debugf("Found %d image policy violations", len(violations))
+	return violations, nil
+}
+
+// scanImages scans the files under workDir referencing images, returning their dependencies and
+// the number of files scanned
+func scanImages(registry *analysis.DependencyRegistry, workDir string) ([]*models.Dependency, int, error) {
+	ctx := models.NewScanContext(nil, workDir)
+	var deps []*models.Dependency
+	count := 0
+	err := filepath.WalkDir(workDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			switch entry.Name() {
+			case ".git", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		scanner, ok := registry.GetScannerForFile(path)
+		if !ok {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		found, err := scanner.ScanFile(ctx, path, content)
+		if err != nil {
+			// A file merely named like a values or compose file is not worth failing the run
+			logger.Warnf("Failed to scan images of %s: %v", path, err)
+			return nil
+		}
+		count++
+		deps = append(deps, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan images: %w", err)
+	}
+	return deps, count, nil
+}
+
+// result is a rule an image breaks
+type result struct {
+	rule    string
+	message string
+}
+
+// check returns the rules of the policy an image breaks
+func (i *Images) check(policy *models.ImagePolicyConfig, ref Reference) []result {
+	var results []result
+	if policy.ForbidLatest && ref.Tag == "latest" {
+		results = append(results, result{"forbid-latest", fmt.Sprintf("image %s uses the latest tag, pin it to a version", ref.Name)})
+	}
+	// An image of a kustomization keeping the tag of its manifests has neither tag nor digest
+	if policy.RequireDigest && ref.Digest == "" && ref.Tag != "" {
+		results = append(results, result{"require-digest", fmt.Sprintf("image %s:%s is not pinned by digest", ref.Name, ref.Tag)})
+	}
+	if len(policy.Registries) > 0 && !ref.IsAllowed(policy.Registries) {
+		results = append(results, result{"allowed-registries", fmt.Sprintf("image %s is pulled from %s, which is not an allowed registry: %s",
+			ref.Name, ref.Registry, strings.Join(policy.Registries, ", "))})
+	}
+	return results
+}
+
+// Reference is an image reference of a Docker dependency
+type Reference struct {
+	Name       string // As referenced, without tag or digest, e.g. nginx
+	Registry   string // e.g. docker.io
+	Repository string // Including the registry, e.g. docker.io/library/nginx
+	Tag        string // e.g. 1.27, "latest" when the image has neither tag nor digest
+	Digest     string // e.g. sha256:...
+}
+
+// ParseReference parses the name and version of a Docker dependency, where the version is either
+// a tag or a digest prefixed with @
+func ParseReference(name, version string) Reference {
+	ref := Reference{Name: name}
+	if digest, ok := strings.CutPrefix(version, "@"); ok {
+		ref.Digest = digest
+		// A reference with both a tag and a digest keeps its tag in the name, e.g. nginx:1.27@sha256:...
+		if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+			ref.Name, ref.Tag = name[:i], name[i+1:]
+		}
+	} else {
+		ref.Tag = version
+	}
+
+	ref.Registry = DefaultRegistry
+	path := ref.Name
+	if first, rest, ok := strings.Cut(ref.Name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, path = first, rest
+	}
+	if ref.Registry == DefaultRegistry && !strings.Contains(path, "/") {
+		path = "library/" + path // Official images
+	}
+	ref.Repository = ref.Registry + "/" + path
+	return ref
+}
+
+// IsAllowed reports whether the repository of an image is one of the allowed registries or
+// repository prefixes
+func (r Reference) IsAllowed(allowed []string) bool {
+	for _, prefix := range allowed {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if r.Repository == prefix || strings.HasPrefix(r.Repository, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceLocation splits the source of a dependency, file:line, into the file under workDir and
+// the line, 0 when unknown
+func sourceLocation(workDir, source string) (string, int) {
+	file := source
+	line := 0
+	if i := strings.LastIndex(source, ":"); i > 0 {
+		if n, err := strconv.Atoi(source[i+1:]); err == nil {
+			file, line = source[:i], n
+		}
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(workDir, file)
+	}
+	return file, line
+}
+
+// touchesImages reports whether any of the files may reference images
+func touchesImages(registry *analysis.DependencyRegistry, files []string) bool {
+	for _, file := range files {
+		if _, ok := registry.GetScannerForFile(file); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Images linter", func() {
+	var workDir string
+
+	BeforeEach(func() {
+		var err error
+		workDir, err = filepath.Abs(filepath.Join("testdata", "project"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	run := func(policy *models.ImagePolicyConfig, files ...string) []string {
+		violations, err := NewImages(workDir).Run(context.Background(), linters.RunOptions{
+			WorkDir:    workDir,
+			Files:      files,
+			ArchConfig: &models.Config{Images: policy},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, v := range violations {
+			Expect(v.Source).To(Equal("images"))
+			file, err := filepath.Rel(workDir, v.File)
+			Expect(err).NotTo(HaveOccurred())
+			lines = append(lines, fmt.Sprintf("%s:%d %s: %s", file, v.Line, v.Rule.Method, *v.Message))
+		}
+		return lines
+	}
+
+	It("should report images breaking the policy at the line referencing them", func() {
+		Expect(run(&models.ImagePolicyConfig{
+			ForbidLatest:  true,
+			RequireDigest: true,
+			Registries:    []string{"docker.io", "ghcr.io/flanksource"},
+		})).To(Equal([]string{
+			"Dockerfile:2 require-digest: image golang:1.25 is not pinned by digest",
+			"Dockerfile:7 allowed-registries: image gcr.io/distroless/static is pulled from gcr.io, which is not an allowed registry: docker.io, ghcr.io/flanksource",
+			"charts/app/values.yaml:2 require-digest: image quay.io/prometheus/node-exporter:v1.8.0 is not pinned by digest",
+			"charts/app/values.yaml:2 allowed-registries: image quay.io/prometheus/node-exporter is pulled from quay.io, which is not an allowed registry: docker.io, ghcr.io/flanksource",
+			"charts/app/values.yaml:5 forbid-latest: image busybox uses the latest tag, pin it to a version",
+			"charts/app/values.yaml:5 require-digest: image busybox:latest is not pinned by digest",
+			"docker-compose.yml:3 forbid-latest: image redis uses the latest tag, pin it to a version",
+			"docker-compose.yml:3 require-digest: image redis:latest is not pinned by digest",
+		}))
+	})
+
+	It("should skip ignored images", func() {
+		Expect(run(&models.ImagePolicyConfig{
+			ForbidLatest: true,
+			Ignore:       []string{"docker.io/library/redis", "busybox"},
+		})).To(BeEmpty())
+	})
+
+	It("should not scan without a policy or when no file referencing images changed", func() {
+		Expect(run(nil)).To(BeEmpty())
+		Expect(run(&models.ImagePolicyConfig{ForbidLatest: true}, filepath.Join(workDir, "main.go"))).To(BeEmpty())
+	})
+
+	DescribeTable("parsing image references",
+		func(name, version string, expected Reference) {
+			Expect(ParseReference(name, version)).To(Equal(expected))
+		},
+		Entry("official image", "nginx", "1.27",
+			Reference{Name: "nginx", Registry: "docker.io", Repository: "docker.io/library/nginx", Tag: "1.27"}),
+		Entry("Docker Hub user image", "bitnami/redis", "latest",
+			Reference{Name: "bitnami/redis", Registry: "docker.io", Repository: "docker.io/bitnami/redis", Tag: "latest"}),
+		Entry("registry with a port", "localhost:5000/app", "@sha256:abc",
+			Reference{Name: "localhost:5000/app", Registry: "localhost:5000", Repository: "localhost:5000/app", Digest: "sha256:abc"}),
+		Entry("tag and digest", "ghcr.io/flanksource/postgres:16", "@sha256:abc",
+			Reference{Name: "ghcr.io/flanksource/postgres", Registry: "ghcr.io", Repository: "ghcr.io/flanksource/postgres", Tag: "16", Digest: "sha256:abc"}),
+	)
+
+	DescribeTable("allowing registries",
+		func(name string, allowed []string, expected bool) {
+			Expect(ParseReference(name, "1.0").IsAllowed(allowed)).To(Equal(expected))
+		},
+		Entry("default registry", "nginx", []string{"docker.io"}, true),
+		Entry("repository prefix", "ghcr.io/flanksource/app", []string{"ghcr.io/flanksource/"}, true),
+		Entry("other organization", "ghcr.io/other/app", []string{"ghcr.io/flanksource"}, false),
+		Entry("prefix of a path segment", "ghcr.io/flanksource-labs/app", []string{"ghcr.io/flanksource"}, false),
+	)
+})
@@ -0,0 +1,10 @@
+package images
+
+import (
+	"github.com/flanksource/arch-unit/linters"
+)
+
+func init() {
+	// Register the image policy linter with the default registry
+	linters.DefaultRegistry.Register(NewImages("."))
+}
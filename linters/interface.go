@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/flanksource/arch-unit/internal/limits"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky/api"
 )
@@ -50,6 +51,12 @@ type MetadataProvider interface {
 	GetRuleCount() int
 }
 
+// ViolationCacher is implemented by linters storing their violations in the violation cache
+// themselves, e.g. to skip the files unchanged since their violations were cached
+type ViolationCacher interface {
+	CachesViolations() bool
+}
+
 // LinterWithLanguageSupport extends Linter to provide language-aware file filtering
 type LinterWithLanguageSupport interface {
 	Linter
@@ -76,6 +83,19 @@ type RunOptions struct {
 	Fix        bool // Enable auto-fixing mode
 	NoCache    bool // Disable caching
 	ExtraArgs  []string
+
+	// Stream receives violations as soon as they are found, linters that call it must also
+	// return every streamed violation from Run. Nil when streaming is disabled
+	Stream func(models.Violation)
+}
+
+// Command prepares a linter executable to run under the resource limits configured for it
+func (o RunOptions) Command(ctx context.Context, name string, args ...string) (*limits.Cmd, error) {
+	var linterLimits *models.ResourceLimits
+	if o.Config != nil {
+		linterLimits = o.Config.Limits
+	}
+	return limits.Command(ctx, o.ArchConfig.GetResourceLimits(name, linterLimits), name, args...)
 }
 
 // Registry manages available linters
@@ -136,16 +156,18 @@ type LinterResult struct {
 	DebounceUsed time.Duration      `json:"debounce_used,omitempty"`
 	FileCount    int                `json:"file_count,omitempty"`
 	RuleCount    int                `json:"rule_count,omitempty"`
+	Streamed     int                `json:"streamed,omitempty"` // Violations streamed instead of kept in Violations
+	Skipped      bool               `json:"skipped,omitempty"`  // The linter's tool is not installed
 }
 
 // GetViolationCount returns the number of violations found
 func (lr *LinterResult) GetViolationCount() int {
-	return len(lr.Violations)
+	return len(lr.Violations) + lr.Streamed
 }
 
 // HasViolations returns true if violations were found
 func (lr *LinterResult) HasViolations() bool {
-	return len(lr.Violations) > 0
+	return lr.GetViolationCount() > 0
 }
 
 // IsSuccessWithViolations returns true if the linter ran successfully but found violations
@@ -176,9 +198,9 @@ func (lr *LinterResult) Pretty() api.Text {
 		text += fmt.Sprintf(" (cached, %v)", lr.DebounceUsed)
 	} else {
 		if lr.FileCount > 0 {
-			text += fmt.Sprintf(" (%d violations, %d files, %v)", len(lr.Violations), lr.FileCount, lr.Duration)
+			text += fmt.Sprintf(" (%d violations, %d files, %v)", lr.GetViolationCount(), lr.FileCount, lr.Duration)
 		} else {
-			text += fmt.Sprintf(" (%d violations, %v)", len(lr.Violations), lr.Duration)
+			text += fmt.Sprintf(" (%d violations, %v)", lr.GetViolationCount(), lr.Duration)
 		}
 	}
 
@@ -145,7 +145,10 @@ func (m *Markdownlint) Run(ctx commonsContext.Context, task *clicky.Task) ([]mod
 
 	// Execute command (markdownlint-cli2 is the modern version)
 	cmdName := "markdownlint"
-	cmd := exec.CommandContext(ctx, cmdName, args...)
+	cmd, err := m.Command(ctx, cmdName, args...)
+	if err != nil {
+		return nil, err
+	}
 	cmd.Dir = m.WorkDir
 
 	logger.Infof("Executing: %s %s", cmdName, strings.Join(args, " "))
@@ -0,0 +1,819 @@
+package nilcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/linters/gocheck"
+)
+
+// Checks reported by the analyzer
+const (
+	// CheckErrValue flags a value used although the call returning it may have failed
+	CheckErrValue = "err-value"
+	// CheckMapLookup flags a pointer read from a map and used without a nil check
+	CheckMapLookup = "map-lookup"
+)
+
+// AllChecks lists every check in the order they are documented
+var AllChecks = []string{CheckErrValue, CheckMapLookup}
+
+// Finding is a probable nil dereference
+type Finding = gocheck.Finding
+
+// suspect is a variable that may be nil until one of its guards has been checked
+type suspect struct {
+	check  string
+	origin string   // call or map lookup the value came from
+	guards []string // err, ok or the variable itself
+	// shared by the copies of a suspect so that it is reported once across branches
+	reported *bool
+}
+
+// state tracks the suspects of a straight-line sequence of statements
+type state struct {
+	suspects map[string]suspect
+	checked  map[string]bool   // expressions already compared against nil, e.g. m[key]
+	lookups  map[string]string // ok of v, ok := m[key] -> m[key]
+}
+
+func newState() state {
+	return state{suspects: map[string]suspect{}, checked: map[string]bool{}, lookups: map[string]string{}}
+}
+
+func (s state) copy() state {
+	c := newState()
+	for k, v := range s.suspects {
+		c.suspects[k] = v
+	}
+	for k, v := range s.checked {
+		c.checked[k] = v
+	}
+	for k, v := range s.lookups {
+		c.lookups[k] = v
+	}
+	return c
+}
+
+// clear drops the suspects protected by any of keys, which are known to have been checked
+func (s state) clear(keys []string) {
+	for _, key := range keys {
+		s.checked[key] = true
+		if lookup, ok := s.lookups[key]; ok {
+			s.checked[lookup] = true
+		}
+	}
+	for name, sus := range s.suspects {
+		for _, guard := range sus.guards {
+			if s.checked[guard] {
+				delete(s.suspects, name)
+				break
+			}
+		}
+	}
+}
+
+// merge replaces s with the union of the suspects and the intersection of the checked
+// expressions of the paths joining after a branch
+func (s state) merge(paths []state) {
+	for name := range s.suspects {
+		delete(s.suspects, name)
+	}
+	for key := range s.checked {
+		delete(s.checked, key)
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	for _, path := range paths {
+		for name, sus := range path.suspects {
+			s.suspects[name] = sus
+		}
+		for ok, lookup := range path.lookups {
+			s.lookups[ok] = lookup
+		}
+	}
+	for key := range paths[0].checked {
+		all := true
+		for _, path := range paths[1:] {
+			all = all && path.checked[key]
+		}
+		if all {
+			s.checked[key] = true
+		}
+	}
+}
+
+// forget drops suspects that are reassigned or whose guard is reassigned
+func (s state) forget(name string) {
+	delete(s.suspects, name)
+	for other, sus := range s.suspects {
+		for _, guard := range sus.guards {
+			if guard == name {
+				delete(s.suspects, other)
+				break
+			}
+		}
+	}
+	delete(s.checked, name)
+	delete(s.lookups, name)
+}
+
+// Analyze reports probable nil dereferences in the functions of a package. All files must
+// belong to the same package so that package-level types, variables and functions resolve.
+func Analyze(fset *token.FileSet, files []*ast.File) []Finding {
+	a := &analyzer{fset: fset, pkg: collectPackage(files)}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			env := map[string]ast.Expr{}
+			if fn.Recv != nil {
+				addFields(env, fn.Recv)
+			}
+			a.function(fn.Type, fn.Body, env)
+		}
+	}
+
+	sort.SliceStable(a.findings, func(i, j int) bool {
+		if a.findings[i].Pos.Filename != a.findings[j].Pos.Filename {
+			return a.findings[i].Pos.Filename < a.findings[j].Pos.Filename
+		}
+		return a.findings[i].Pos.Offset < a.findings[j].Pos.Offset
+	})
+	return a.findings
+}
+
+// pkgInfo holds the package-level declarations the analyzer resolves types against
+type pkgInfo struct {
+	types map[string]ast.Expr      // type name -> type expression
+	vars  map[string]ast.Expr      // package variable -> type expression
+	funcs map[string]*ast.FuncType // function or Type.Method -> signature
+}
+
+func collectPackage(files []*ast.File) *pkgInfo {
+	pkg := &pkgInfo{
+		types: map[string]ast.Expr{},
+		vars:  map[string]ast.Expr{},
+		funcs: map[string]*ast.FuncType{},
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				key := d.Name.Name
+				if d.Recv != nil && len(d.Recv.List) > 0 {
+					key = typeName(d.Recv.List[0].Type) + "." + key
+				}
+				pkg.funcs[key] = d.Type
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						pkg.types[s.Name.Name] = s.Type
+					case *ast.ValueSpec:
+						for i, name := range s.Names {
+							if s.Type != nil {
+								pkg.vars[name.Name] = s.Type
+							} else if i < len(s.Values) {
+								if t := literalType(s.Values[i]); t != nil {
+									pkg.vars[name.Name] = t
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return pkg
+}
+
+type analyzer struct {
+	fset     *token.FileSet
+	pkg      *pkgInfo
+	findings []Finding
+}
+
+func (a *analyzer) report(check string, node ast.Node, format string, args ...interface{}) {
+	a.findings = append(a.findings, Finding{
+		Check:   check,
+		Pos:     a.fset.Position(node.Pos()),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// function analyzes a function body; env holds the variables captured from enclosing scopes
+func (a *analyzer) function(ftype *ast.FuncType, body *ast.BlockStmt, env map[string]ast.Expr) {
+	fn := &function{analyzer: a, env: env}
+	addFields(fn.env, ftype.Params)
+	addFields(fn.env, ftype.Results)
+	fn.block(body.List, newState())
+
+	// Closures start without suspects but see the variables declared around them
+	for _, lit := range fn.closures {
+		a.function(lit.fn.Type, lit.fn.Body, lit.env)
+	}
+}
+
+type closure struct {
+	fn  *ast.FuncLit
+	env map[string]ast.Expr
+}
+
+// function walks the statements of a single function body
+type function struct {
+	*analyzer
+	env      map[string]ast.Expr // local variable -> type expression, when known
+	closures []closure
+}
+
+func (f *function) block(stmts []ast.Stmt, st state) {
+	for _, stmt := range stmts {
+		f.stmt(stmt, st)
+	}
+}
+
+func (f *function) stmt(stmt ast.Stmt, st state) {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		f.assign(s, st)
+	case *ast.DeclStmt:
+		f.scan(s, st)
+		if gen, ok := s.Decl.(*ast.GenDecl); ok {
+			for _, spec := range gen.Specs {
+				if vs, ok := spec.(*ast.ValueSpec); ok {
+					for i, name := range vs.Names {
+						st.forget(name.Name)
+						f.define(name.Name, vs.Type, vs.Values, i)
+					}
+				}
+			}
+		}
+	case *ast.IfStmt:
+		f.ifStmt(s, st)
+	case *ast.BlockStmt:
+		f.block(s.List, st)
+	case *ast.LabeledStmt:
+		f.stmt(s.Stmt, st)
+	case *ast.ForStmt:
+		inner := st.copy()
+		if s.Init != nil {
+			f.stmt(s.Init, inner)
+		}
+		if s.Cond != nil {
+			f.scan(s.Cond, inner)
+		}
+		f.block(s.Body.List, inner)
+	case *ast.RangeStmt:
+		f.scan(s.X, st)
+		inner := st.copy()
+		for _, expr := range []ast.Expr{s.Key, s.Value} {
+			if ident, ok := expr.(*ast.Ident); ok {
+				inner.forget(ident.Name)
+				delete(f.env, ident.Name)
+			}
+		}
+		f.block(s.Body.List, inner)
+	case *ast.SwitchStmt:
+		inner := st.copy()
+		if s.Init != nil {
+			f.stmt(s.Init, inner)
+		}
+		if s.Tag == nil {
+			st.clear(f.conditions(s.Body, inner))
+			break
+		}
+		f.scan(s.Tag, inner)
+		f.clauses(s.Body, inner)
+	case *ast.TypeSwitchStmt:
+		inner := st.copy()
+		if s.Init != nil {
+			f.stmt(s.Init, inner)
+		}
+		f.scan(s.Assign, inner)
+		f.clauses(s.Body, inner)
+	case *ast.SelectStmt:
+		f.clauses(s.Body, st)
+	default:
+		f.scan(stmt, st)
+	}
+}
+
+func (f *function) clauses(body *ast.BlockStmt, st state) {
+	for _, clause := range body.List {
+		switch c := clause.(type) {
+		case *ast.CaseClause:
+			for _, expr := range c.List {
+				f.scan(expr, st)
+			}
+			f.block(c.Body, st.copy())
+		case *ast.CommClause:
+			inner := st.copy()
+			if c.Comm != nil {
+				f.stmt(c.Comm, inner)
+			}
+			f.block(c.Body, inner)
+		}
+	}
+}
+
+// conditions walks the clauses of a switch without tag like an if-else chain, returning the
+// keys that are safe after the switch because every clause checking them terminates
+func (f *function) conditions(body *ast.BlockStmt, st state) []string {
+	var previous, safe []string
+	exhaustive := true
+	for _, clause := range body.List {
+		c, ok := clause.(*ast.CaseClause)
+		if !ok {
+			continue
+		}
+		inner := st.copy()
+		inner.clear(previous)
+		for _, expr := range c.List {
+			f.scan(expr, inner)
+		}
+
+		var whenFalse []string
+		if len(c.List) == 1 {
+			inner.clear(conditionKeys(c.List[0], true))
+			whenFalse = conditionKeys(c.List[0], false)
+		}
+		f.block(c.Body, inner)
+
+		terminated := len(c.Body) > 0 && terminates(c.Body[len(c.Body)-1])
+		exhaustive = exhaustive && terminated
+		if exhaustive {
+			safe = append(safe, whenFalse...)
+		}
+		previous = append(previous, whenFalse...)
+	}
+	return safe
+}
+
+func (f *function) ifStmt(s *ast.IfStmt, st state) {
+	if s.Init != nil {
+		f.stmt(s.Init, st)
+	}
+	f.scan(s.Cond, st)
+
+	whenTrue := conditionKeys(s.Cond, true)
+	whenFalse := conditionKeys(s.Cond, false)
+
+	// Uses inside an err != nil or v == nil branch are reported, uses inside the
+	// opposite branch are safe
+	body := st.copy()
+	body.clear(whenTrue)
+	f.block(s.Body.List, body)
+
+	other := st.copy()
+	other.clear(whenFalse)
+	if s.Else != nil {
+		f.stmt(s.Else, other)
+	}
+
+	// Continue with whatever may flow out of either branch
+	var paths []state
+	if !terminates(s.Body) {
+		paths = append(paths, body)
+	}
+	if s.Else == nil || !terminates(s.Else) {
+		paths = append(paths, other)
+	}
+	st.merge(paths)
+}
+
+func (f *function) assign(s *ast.AssignStmt, st state) {
+	for _, rhs := range s.Rhs {
+		f.scan(rhs, st)
+	}
+	for _, lhs := range s.Lhs {
+		if _, ok := lhs.(*ast.Ident); ok {
+			continue
+		}
+		f.scan(lhs, st)
+		if index, ok := lhs.(*ast.IndexExpr); ok {
+			// m[key] = &T{} makes later uses of m[key] safe
+			st.checked[types.ExprString(index)] = true
+		}
+	}
+
+	names := make([]string, len(s.Lhs))
+	for i, lhs := range s.Lhs {
+		if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+			names[i] = ident.Name
+			st.forget(ident.Name)
+			if s.Tok == token.DEFINE || s.Tok == token.ASSIGN {
+				f.define(ident.Name, nil, s.Rhs, i)
+			}
+		}
+	}
+
+	if len(s.Rhs) != 1 {
+		return
+	}
+	switch rhs := ast.Unparen(s.Rhs[0]).(type) {
+	case *ast.CallExpr:
+		if len(names) < 2 {
+			return
+		}
+		errName := names[len(names)-1]
+		// v, _ := f() can only be recognized when f is declared in the package
+		discarded := errName == "" && isIdent(f.resultType(rhs, len(names)-1), "error")
+		if !isErrName(errName) && !discarded {
+			return
+		}
+		origin := types.ExprString(rhs.Fun)
+		for i, name := range names[:len(names)-1] {
+			if name == "" || !f.mayReturnNil(rhs, i) {
+				continue
+			}
+			guards := []string{name}
+			if !discarded {
+				guards = []string{errName, name}
+			}
+			st.suspects[name] = suspect{check: CheckErrValue, origin: origin, guards: guards, reported: new(bool)}
+		}
+	case *ast.IndexExpr:
+		if !f.isPointerMap(f.typeOf(rhs.X)) {
+			return
+		}
+		if len(names) == 2 && names[1] != "" {
+			st.lookups[names[1]] = types.ExprString(rhs)
+		}
+		if names[0] == "" {
+			return
+		}
+		guards := []string{names[0]}
+		if len(names) == 2 && names[1] != "" {
+			guards = append(guards, names[1])
+		}
+		st.suspects[names[0]] = suspect{check: CheckMapLookup, origin: types.ExprString(rhs), guards: guards, reported: new(bool)}
+	}
+}
+
+// define records the type of a variable assigned values[i], or typ when declared explicitly
+func (f *function) define(name string, typ ast.Expr, values []ast.Expr, i int) {
+	if typ == nil && len(values) == 1 {
+		// v, ok := m[key] and v, err := f() take their type from the first value
+		if call, ok := ast.Unparen(values[0]).(*ast.CallExpr); ok {
+			typ = f.resultType(call, i)
+		} else if i == 0 {
+			typ = f.typeOf(values[0])
+		}
+	} else if typ == nil && i < len(values) {
+		typ = f.typeOf(values[i])
+	}
+	if typ != nil {
+		f.env[name] = typ
+	} else {
+		delete(f.env, name)
+	}
+}
+
+// scan reports dereferences of suspects within node, queuing closures for separate analysis
+func (f *function) scan(node ast.Node, st state) {
+	if node == nil {
+		return
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			env := make(map[string]ast.Expr, len(f.env))
+			for k, v := range f.env {
+				env[k] = v
+			}
+			f.closures = append(f.closures, closure{fn: e, env: env})
+			return false
+		case *ast.BinaryExpr:
+			if e.Op != token.LAND && e.Op != token.LOR {
+				return true
+			}
+			// The right operand only runs once the left one has been checked
+			f.scan(e.X, st)
+			right := st.copy()
+			right.clear(conditionKeys(e.X, e.Op == token.LAND))
+			f.scan(e.Y, right)
+			return false
+		case *ast.SelectorExpr:
+			f.deref(e, e.X, st)
+		case *ast.StarExpr:
+			f.deref(e, e.X, st)
+		}
+		return true
+	})
+}
+
+func (f *function) deref(node ast.Node, x ast.Expr, st state) {
+	switch target := ast.Unparen(x).(type) {
+	case *ast.Ident:
+		sus, ok := st.suspects[target.Name]
+		if !ok || *sus.reported {
+			return
+		}
+		*sus.reported = true
+		switch {
+		case sus.check == CheckErrValue && len(sus.guards) == 1:
+			f.report(CheckErrValue, node, "%s may be nil when %s returns an error, which is discarded",
+				target.Name, sus.origin)
+		case sus.check == CheckErrValue:
+			f.report(CheckErrValue, node, "%s may be nil when %s returns an error, check %s before using it",
+				target.Name, sus.origin, sus.guards[0])
+		default:
+			f.report(CheckMapLookup, node, "%s read from %s may be nil, check it before using it",
+				target.Name, sus.origin)
+		}
+	case *ast.IndexExpr:
+		key := types.ExprString(target)
+		if st.checked[key] || !f.isPointerMap(f.typeOf(target.X)) {
+			return
+		}
+		st.checked[key] = true
+		f.report(CheckMapLookup, node, "%s may be nil when the key is missing, check it before using it", key)
+	}
+}
+
+// mayReturnNil returns false only when the i-th result of call is known to be a non-nilable type
+func (f *function) mayReturnNil(call *ast.CallExpr, i int) bool {
+	typ := f.resultType(call, i)
+	if typ == nil {
+		return true
+	}
+	return f.isNilable(typ, 0)
+}
+
+// resultType resolves the type of the i-th result of a call to a function or method of the package
+func (f *function) resultType(call *ast.CallExpr, i int) ast.Expr {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		switch fun.Name {
+		case "new":
+			if len(call.Args) == 1 && i == 0 {
+				return &ast.StarExpr{X: call.Args[0]}
+			}
+		case "make":
+			if len(call.Args) > 0 && i == 0 {
+				return call.Args[0]
+			}
+		}
+		if _, local := f.env[fun.Name]; local {
+			return nil
+		}
+		return result(f.pkg.funcs[fun.Name], i)
+	case *ast.SelectorExpr:
+		recv := f.typeOf(fun.X)
+		if recv == nil {
+			return nil
+		}
+		return result(f.pkg.funcs[typeName(recv)+"."+fun.Sel.Name], i)
+	}
+	return nil
+}
+
+// typeOf resolves the type expression of expr from declarations visible to the analyzer
+func (f *function) typeOf(expr ast.Expr) ast.Expr {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if typ, ok := f.env[e.Name]; ok {
+			return typ
+		}
+		return f.pkg.vars[e.Name]
+	case *ast.SelectorExpr:
+		st, ok := f.underlying(f.typeOf(e.X)).(*ast.StructType)
+		if !ok {
+			return nil
+		}
+		for _, field := range st.Fields.List {
+			for _, name := range field.Names {
+				if name.Name == e.Sel.Name {
+					return field.Type
+				}
+			}
+		}
+	case *ast.StarExpr:
+		if ptr, ok := f.typeOf(e.X).(*ast.StarExpr); ok {
+			return ptr.X
+		}
+	case *ast.IndexExpr:
+		switch container := f.underlying(f.typeOf(e.X)).(type) {
+		case *ast.MapType:
+			return container.Value
+		case *ast.ArrayType:
+			return container.Elt
+		}
+	case *ast.CallExpr:
+		return f.resultType(e, 0)
+	default:
+		return literalType(e)
+	}
+	return nil
+}
+
+// underlying resolves named types of the package, looking through pointers
+func (f *function) underlying(typ ast.Expr) ast.Expr {
+	for depth := 0; typ != nil && depth < 10; depth++ {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.ParenExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.Ident:
+			named, ok := f.pkg.types[t.Name]
+			if !ok {
+				return t
+			}
+			typ = named
+		default:
+			return typ
+		}
+	}
+	return typ
+}
+
+func (f *function) isPointerMap(typ ast.Expr) bool {
+	if typ == nil {
+		return false
+	}
+	if _, ok := typ.(*ast.StarExpr); ok {
+		// A pointer to a map must be dereferenced before indexing
+		return false
+	}
+	mt, ok := f.underlying(typ).(*ast.MapType)
+	if !ok {
+		return false
+	}
+	_, ok = ast.Unparen(mt.Value).(*ast.StarExpr)
+	return ok
+}
+
+func (f *function) isNilable(typ ast.Expr, depth int) bool {
+	switch t := ast.Unparen(typ).(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return true
+	case *ast.ArrayType:
+		return t.Len == nil
+	case *ast.IndexExpr:
+		return f.isNilable(t.X, depth+1)
+	case *ast.IndexListExpr:
+		return f.isNilable(t.X, depth+1)
+	case *ast.Ident:
+		if named, ok := f.pkg.types[t.Name]; ok && depth < 10 {
+			return f.isNilable(named, depth+1)
+		}
+		// error, any and type parameters may be nil, predeclared basic types may not
+		return types.Universe.Lookup(t.Name) == nil || t.Name == "error" || t.Name == "any"
+	case *ast.StructType:
+		return false
+	}
+	// Types of other packages may be interfaces
+	return true
+}
+
+func result(sig *ast.FuncType, i int) ast.Expr {
+	if sig == nil || sig.Results == nil {
+		return nil
+	}
+	n := 0
+	for _, field := range sig.Results.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		if i < n+count {
+			return field.Type
+		}
+		n += count
+	}
+	return nil
+}
+
+// literalType returns the type of composite literals such as T{} and &T{}
+func literalType(expr ast.Expr) ast.Expr {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CompositeLit:
+		return e.Type
+	case *ast.UnaryExpr:
+		if lit, ok := ast.Unparen(e.X).(*ast.CompositeLit); ok && e.Op == token.AND && lit.Type != nil {
+			return &ast.StarExpr{X: lit.Type}
+		}
+	}
+	return nil
+}
+
+// conditionKeys returns the expressions known to be safe to use when cond evaluates to
+// outcome: err once err == nil, ok once ok is true and anything else once v != nil
+func conditionKeys(cond ast.Expr, outcome bool) []string {
+	switch c := ast.Unparen(cond).(type) {
+	case *ast.BinaryExpr:
+		switch {
+		case c.Op == token.LAND && outcome, c.Op == token.LOR && !outcome:
+			return append(conditionKeys(c.X, outcome), conditionKeys(c.Y, outcome)...)
+		case c.Op == token.EQL, c.Op == token.NEQ:
+			operand := c.X
+			if isNil(c.X) {
+				operand = c.Y
+			} else if !isNil(c.Y) {
+				return nil
+			}
+			key := types.ExprString(ast.Unparen(operand))
+			isErr := isErrName(key)
+			// err != nil and v == nil describe a failure
+			failure := (c.Op == token.NEQ) == isErr
+			if failure != outcome {
+				return []string{key}
+			}
+		}
+	case *ast.UnaryExpr:
+		if c.Op == token.NOT {
+			return conditionKeys(c.X, !outcome)
+		}
+	case *ast.Ident:
+		if outcome {
+			return []string{c.Name}
+		}
+	}
+	return nil
+}
+
+// terminates returns true when control never falls through the end of stmt
+func terminates(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		return len(s.List) > 0 && terminates(s.List[len(s.List)-1])
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return s.Tok != token.FALLTHROUGH
+	case *ast.IfStmt:
+		return s.Else != nil && terminates(s.Body) && terminates(s.Else)
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			return fun.Name == "panic"
+		case *ast.SelectorExpr:
+			name := fun.Sel.Name
+			return name == "Exit" || name == "FailNow" || name == "Goexit" ||
+				strings.HasPrefix(name, "Fatal") || strings.HasPrefix(name, "Panic")
+		}
+	}
+	return false
+}
+
+func addFields(env map[string]ast.Expr, fields *ast.FieldList) {
+	if fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			env[name.Name] = field.Type
+		}
+	}
+}
+
+// typeName returns the name of a possibly pointer or generic named type
+func typeName(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.ParenExpr:
+		return typeName(t.X)
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	case *ast.IndexListExpr:
+		return typeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+func isErrName(name string) bool {
+	return name == "err" || strings.HasSuffix(name, "Err")
+}
+
+func isNil(expr ast.Expr) bool {
+	return isIdent(expr, "nil")
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	if expr == nil {
+		return false
+	}
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && ident.Name == name
+}
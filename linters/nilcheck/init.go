@@ -0,0 +1,10 @@
+package nilcheck
+
+import (
+	"github.com/flanksource/arch-unit/linters"
+)
+
+func init() {
+	// Register the nil dereference linter with the default registry
+	linters.DefaultRegistry.Register(NewNilCheck("."))
+}
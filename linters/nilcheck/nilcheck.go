@@ -0,0 +1,80 @@
+package nilcheck
+
+import (
+	"context"
+
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/linters/gocheck"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// NilCheck reports probable nil dereferences in Go code
+type NilCheck struct {
+	linters.RunOptions
+	fileCount int
+}
+
+// NewNilCheck creates a new nil dereference linter
+func NewNilCheck(workDir string) *NilCheck {
+	return &NilCheck{RunOptions: linters.RunOptions{WorkDir: workDir}}
+}
+
+// Name returns the linter name
+func (n *NilCheck) Name() string {
+	return "nilcheck"
+}
+
+// DefaultIncludes returns default file patterns this linter should process
+func (n *NilCheck) DefaultIncludes() []string {
+	return []string{"**/*.go"}
+}
+
+// DefaultExcludes returns patterns this linter should ignore by default
+func (n *NilCheck) DefaultExcludes() []string {
+	return []string{"vendor/**", ".git/**", "**/*_test.go"}
+}
+
+// SupportsJSON returns true if linter supports JSON output
+func (n *NilCheck) SupportsJSON() bool {
+	return true
+}
+
+// JSONArgs returns additional args needed for JSON output
+func (n *NilCheck) JSONArgs() []string {
+	return []string{}
+}
+
+// SupportsFix returns true if linter supports auto-fixing violations
+func (n *NilCheck) SupportsFix() bool {
+	return false
+}
+
+// FixArgs returns additional args needed for fix mode
+func (n *NilCheck) FixArgs() []string {
+	return []string{}
+}
+
+// ValidateConfig validates linter-specific configuration
+func (n *NilCheck) ValidateConfig(config *models.LinterConfig) error {
+	return nil
+}
+
+// GetFileCount returns the number of files analyzed by the last run
+func (n *NilCheck) GetFileCount() int {
+	return n.fileCount
+}
+
+// GetRuleCount returns the number of checks the linter applies
+func (n *NilCheck) GetRuleCount() int {
+	return len(AllChecks)
+}
+
+// Run analyzes the Go files, one package directory at a time, and returns violations for the
+// checks enabled by the quality.nil_checks configuration of each file
+func (n *NilCheck) Run(ctx context.Context, opts linters.RunOptions) ([]models.Violation, error) {
+	violations, fileCount, err := gocheck.Run(n.Name(), opts, Analyze, func(quality *models.QualityConfig) *models.CheckSelection {
+		return quality.NilChecks
+	})
+	n.fileCount = fileCount
+	return violations, err
+}
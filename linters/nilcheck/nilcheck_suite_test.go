@@ -0,0 +1,13 @@
+package nilcheck
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestNilCheck(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "NilCheck Linter Suite")
+}
@@ -0,0 +1,74 @@
+package nilcheck
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/linters/gocheck"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Nil dereference analyzer", func() {
+	testdata := filepath.Join("testdata", "store")
+
+	lines := func(findings []Finding, check string) []int {
+		var result []int
+		for _, finding := range findings {
+			if finding.Check == check {
+				result = append(result, finding.Pos.Line)
+			}
+		}
+		return result
+	}
+
+	It("should report values used before their error is checked", func() {
+		findings, err := gocheck.AnalyzeDir(testdata, Analyze)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Unchecked, LoggedOnly, UsedInErrBranch and Closure; Checked, SuccessBranch,
+		// ValueResult, Method, Switch, ShortCircuit and Retry are safe
+		Expect(lines(findings, CheckErrValue)).To(Equal([]int{37, 58, 64, 138}))
+		Expect(findings[0].Message).To(Equal("f may be nil when os.Open returns an error, check err before using it"))
+		Expect(findings[len(findings)-1].Message).To(Equal("item may be nil when load returns an error, which is discarded"))
+	})
+
+	It("should report pointers read from maps without a nil check", func() {
+		findings, err := gocheck.AnalyzeDir(testdata, Analyze)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Lookup and the registry fallback of Direct; LookupChecked, CommaOk, Size and Ensure are safe
+		Expect(lines(findings, CheckMapLookup)).To(Equal([]int{101, 127}))
+	})
+
+	It("should apply the nil_checks configuration of each path", func() {
+		workDir, err := filepath.Abs(testdata)
+		Expect(err).NotTo(HaveOccurred())
+
+		archConfig := &models.Config{Rules: map[string]models.RuleConfig{
+			"*.go": {Quality: &models.QualityConfig{
+				NilChecks: &models.CheckSelection{Checks: []string{CheckMapLookup}},
+			}},
+		}}
+
+		violations, err := NewNilCheck(workDir).Run(context.Background(), linters.RunOptions{
+			WorkDir:    workDir,
+			ArchConfig: archConfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(2))
+		Expect(violations[0].Source).To(Equal("nilcheck"))
+		Expect(violations[0].Rule.Method).To(Equal(CheckMapLookup))
+
+		archConfig.Rules["*.go"].Quality.NilChecks.Disabled = true
+		violations, err = NewNilCheck(workDir).Run(context.Background(), linters.RunOptions{
+			WorkDir:    workDir,
+			ArchConfig: archConfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+})
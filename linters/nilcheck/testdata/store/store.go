@@ -0,0 +1,163 @@
+package store
+
+import (
+	"log"
+	"os"
+)
+
+type Item struct {
+	Name string
+}
+
+type Config struct {
+	Path string
+}
+
+type Store struct {
+	items map[string]*Item
+	sizes map[string]int
+}
+
+var registry = map[string]*Item{}
+
+func load(name string) (*Item, error) {
+	return nil, nil
+}
+
+func loadConfig() (Config, error) {
+	return Config{}, nil
+}
+
+func (s *Store) Get(name string) (*Item, error) {
+	return s.items[name], nil
+}
+
+func Unchecked() string {
+	f, err := os.Open("items.json")
+	defer f.Close()
+	if err != nil {
+		return ""
+	}
+	return f.Name()
+}
+
+func Checked() string {
+	f, err := os.Open("items.json")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	return f.Name()
+}
+
+func LoggedOnly() string {
+	item, err := load("a")
+	if err != nil {
+		log.Printf("failed to load: %v", err)
+	}
+	return item.Name
+}
+
+func UsedInErrBranch() error {
+	item, err := load("a")
+	if err != nil {
+		log.Printf("failed to load %s", item.Name)
+		return err
+	}
+	return nil
+}
+
+func SuccessBranch() string {
+	if item, err := load("a"); err == nil {
+		return item.Name
+	}
+	return ""
+}
+
+func ValueResult() string {
+	cfg, _ := loadConfig()
+	return cfg.Path
+}
+
+func Method(s *Store) string {
+	item, err := s.Get("a")
+	if err != nil {
+		return ""
+	}
+	return item.Name
+}
+
+func Switch() string {
+	item, err := load("a")
+	switch {
+	case err != nil:
+		return ""
+	}
+	return item.Name
+}
+
+func (s *Store) Lookup(name string) string {
+	item := s.items[name]
+	return item.Name
+}
+
+func (s *Store) LookupChecked(name string) string {
+	item := s.items[name]
+	if item == nil {
+		return ""
+	}
+	return item.Name
+}
+
+func (s *Store) CommaOk(name string) string {
+	if item, ok := s.items[name]; ok {
+		return item.Name
+	}
+	item, ok := s.items[name]
+	if !ok {
+		return ""
+	}
+	return item.Name
+}
+
+func (s *Store) Direct(name string) string {
+	if s.items[name] != nil {
+		return s.items[name].Name
+	}
+	return registry[name].Name
+}
+
+func (s *Store) Size(name string) int {
+	size := s.sizes[name]
+	return size + 1
+}
+
+func Closure() func() string {
+	return func() string {
+		item, _ := load("a")
+		return item.Name
+	}
+}
+
+func ShortCircuit(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.IsDir()
+}
+
+func Retry(name string) string {
+	item, err := load(name)
+	if err != nil {
+		item, err = load("default")
+		if err != nil {
+			return ""
+		}
+	}
+	return item.Name
+}
+
+func (s *Store) Ensure(name string) string {
+	if _, ok := s.items[name]; !ok {
+		s.items[name] = &Item{Name: name}
+	}
+	return s.items[name].Name
+}
@@ -0,0 +1,421 @@
+package osv
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flanksource/commons/logger"
+)
+
+// Package is a dependency looked up in the vulnerability database, with an OSV ecosystem name
+type Package struct {
+	Ecosystem string
+	Name      string
+	Version   string
+}
+
+// Vulnerability is the part of an OSV record the linter reports, see https://ossf.github.io/osv-schema/
+type Vulnerability struct {
+	ID               string                 `json:"id"`
+	Summary          string                 `json:"summary,omitempty"`
+	Aliases          []string               `json:"aliases,omitempty"`
+	Severity         []SeverityScore        `json:"severity,omitempty"`
+	Affected         []Affected             `json:"affected,omitempty"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+// SeverityScore is a severity of an OSV record, e.g. a CVSS_V3 vector
+type SeverityScore struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Affected lists the affected versions of one package
+type Affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges            []Range                `json:"ranges,omitempty"`
+	Versions          []string               `json:"versions,omitempty"`
+	EcosystemSpecific map[string]interface{} `json:"ecosystem_specific,omitempty"`
+}
+
+// Range is a range of affected versions, described by the events of its version history
+type Range struct {
+	Type   string  `json:"type"` // SEMVER, ECOSYSTEM or GIT
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// Database looks up the vulnerabilities affecting packages
+type Database interface {
+	// Query returns the vulnerabilities of every package, in the order of packages
+	Query(ctx context.Context, packages []Package) ([][]Vulnerability, error)
+}
+
+// APIURL is the OSV.dev API queried when no local database is configured
+var APIURL = "https://api.osv.dev/v1"
+
+// batchSize is the maximum number of queries of a querybatch request
+const batchSize = 1000
+
+// API queries the OSV.dev API, batching packages and fetching the details of each vulnerability once
+type API struct {
+	URL    string
+	client *http.Client
+}
+
+// NewAPI creates a client of the OSV.dev API
+func NewAPI() *API {
+	return &API{URL: APIURL, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type apiQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version   string `json:"version"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+type apiBatchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+		NextPageToken string `json:"next_page_token"`
+	} `json:"results"`
+}
+
+// Query returns the vulnerabilities of every package
+func (a *API) Query(ctx context.Context, packages []Package) ([][]Vulnerability, error) {
+	results := make([][]Vulnerability, len(packages))
+	details := make(map[string]*Vulnerability)
+	for start := 0; start < len(packages); start += batchSize {
+		end := min(start+batchSize, len(packages))
+		ids, err := a.queryBatch(ctx, packages[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for i, packageIDs := range ids {
+			seen := make(map[string]bool)
+			for _, id := range packageIDs {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				vulnerability, ok := details[id]
+				if !ok {
+					if vulnerability, err = a.vulnerability(ctx, id); err != nil {
+						return nil, err
+					}
+					details[id] = vulnerability
+				}
+				results[start+i] = append(results[start+i], *vulnerability)
+			}
+		}
+	}
+	return results, nil
+}
+
+// queryBatch returns the IDs of the vulnerabilities of a batch of packages, following the pages of
+// packages with many vulnerabilities
+func (a *API) queryBatch(ctx context.Context, packages []Package) ([][]string, error) {
+	queries := make([]apiQuery, len(packages))
+	for i, pkg := range packages {
+		queries[i].Package.Name = pkg.Name
+		queries[i].Package.Ecosystem = pkg.Ecosystem
+		queries[i].Version = pkg.Version
+	}
+
+	ids := make([][]string, len(packages))
+	pending := make([]int, len(packages))
+	for i := range pending {
+		pending[i] = i
+	}
+	for len(pending) > 0 {
+		batch := make([]apiQuery, len(pending))
+		for i, index := range pending {
+			batch[i] = queries[index]
+		}
+
+		var response apiBatchResponse
+		if err := a.post(ctx, "/querybatch", map[string]interface{}{"queries": batch}, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Results) != len(batch) {
+			return nil, fmt.Errorf("OSV returned %d results for %d queries", len(response.Results), len(batch))
+		}
+
+		var next []int
+		for i, result := range response.Results {
+			index := pending[i]
+			for _, vuln := range result.Vulns {
+				ids[index] = append(ids[index], vuln.ID)
+			}
+			if result.NextPageToken != "" {
+				queries[index].PageToken = result.NextPageToken
+				next = append(next, index)
+			}
+		}
+		pending = next
+	}
+	return ids, nil
+}
+
+// vulnerability fetches the full record of a vulnerability
+func (a *API) vulnerability(ctx context.Context, id string) (*Vulnerability, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL+"/vulns/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var vulnerability Vulnerability
+	if err := a.do(req, &vulnerability); err != nil {
+		return nil, fmt.Errorf("failed to get vulnerability %s: %w", id, err)
+	}
+	return &vulnerability, nil
+}
+
+func (a *API) post(ctx context.Context, path string, body, result interface{}) error {
+	content, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL+path, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := a.do(req, result); err != nil {
+		return fmt.Errorf("failed to query OSV: %w", err)
+	}
+	return nil
+}
+
+func (a *API) do(req *http.Request, result interface{}) error {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s %s", req.Method, req.URL, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// LocalDatabase matches packages against OSV exports on disk, for scanning without network access.
+// Each ecosystem is read from <dir>/<ecosystem>/all.zip, as published at
+// https://osv-vulnerabilities.storage.googleapis.com/<ecosystem>/all.zip, or from the JSON records
+// extracted into <dir>/<ecosystem>.
+type LocalDatabase struct {
+	Dir        string
+	ecosystems map[string]map[string][]Vulnerability // Vulnerabilities by package name
+}
+
+// NewLocalDatabase creates a database reading the OSV exports in dir
+func NewLocalDatabase(dir string) *LocalDatabase {
+	return &LocalDatabase{Dir: dir, ecosystems: make(map[string]map[string][]Vulnerability)}
+}
+
+// Query returns the vulnerabilities of every package
+func (d *LocalDatabase) Query(ctx context.Context, packages []Package) ([][]Vulnerability, error) {
+	results := make([][]Vulnerability, len(packages))
+	for i, pkg := range packages {
+		vulnerabilities, err := d.load(pkg.Ecosystem)
+		if err != nil {
+			return nil, err
+		}
+		for _, vulnerability := range vulnerabilities[packageKey(pkg.Ecosystem, pkg.Name)] {
+			if Affects(vulnerability, pkg) {
+				results[i] = append(results[i], vulnerability)
+			}
+		}
+	}
+	return results, nil
+}
+
+// load reads the export of an ecosystem once, an ecosystem without an export has no vulnerabilities
+func (d *LocalDatabase) load(ecosystem string) (map[string][]Vulnerability, error) {
+	if vulnerabilities, ok := d.ecosystems[ecosystem]; ok {
+		return vulnerabilities, nil
+	}
+	vulnerabilities := make(map[string][]Vulnerability)
+	d.ecosystems[ecosystem] = vulnerabilities
+
+	add := func(name string, content []byte) error {
+		var vulnerability Vulnerability
+		if err := json.Unmarshal(content, &vulnerability); err != nil {
+			return fmt.Errorf("invalid OSV record %s: %w", name, err)
+		}
+		seen := make(map[string]bool)
+		for _, affected := range vulnerability.Affected {
+			key := packageKey(ecosystem, affected.Package.Name)
+			if affected.Package.Ecosystem == ecosystem && !seen[key] {
+				seen[key] = true
+				vulnerabilities[key] = append(vulnerabilities[key], vulnerability)
+			}
+		}
+		return nil
+	}
+
+	dir := filepath.Join(d.Dir, ecosystem)
+	path := filepath.Join(dir, "all.zip")
+	archive, err := zip.OpenReader(path)
+	if err == nil {
+		defer func() { _ = archive.Close() }()
+		for _, file := range archive.File {
+			if filepath.Ext(file.Name) != ".json" {
+				continue
+			}
+			reader, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s from %s: %w", file.Name, path, err)
+			}
+			content, err := io.ReadAll(reader)
+			_ = reader.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s from %s: %w", file.Name, path, err)
+			}
+			if err := add(file.Name, content); err != nil {
+				return nil, err
+			}
+		}
+		return vulnerabilities, nil
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) == 0 {
+		logger.Warnf("No OSV export for %s in %s, its packages are not checked", ecosystem, d.Dir)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := add(file, content); err != nil {
+			return nil, err
+		}
+	}
+	return vulnerabilities, nil
+}
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// packageKey normalizes a package name the way its ecosystem compares names
+func packageKey(ecosystem, name string) string {
+	switch ecosystem {
+	case "PyPI":
+		return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	case "NuGet":
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// affectedPackages returns the entries of a vulnerability for a package
+func affectedPackages(vulnerability Vulnerability, pkg Package) []Affected {
+	var entries []Affected
+	for _, affected := range vulnerability.Affected {
+		if affected.Package.Ecosystem == pkg.Ecosystem && packageKey(pkg.Ecosystem, affected.Package.Name) == packageKey(pkg.Ecosystem, pkg.Name) {
+			entries = append(entries, affected)
+		}
+	}
+	return entries
+}
+
+// Affects reports whether the version of a package is affected by a vulnerability, from its
+// explicit versions or its SEMVER and ECOSYSTEM ranges
+func Affects(vulnerability Vulnerability, pkg Package) bool {
+	for _, affected := range affectedPackages(vulnerability, pkg) {
+		for _, version := range affected.Versions {
+			if version == pkg.Version {
+				return true
+			}
+		}
+		for _, r := range affected.Ranges {
+			if r.Type != "GIT" && inRange(r, pkg.Version) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inRange evaluates the events of a range in version order, a version is affected from an
+// introduced event until a fixed or after a last_affected event
+func inRange(r Range, version string) bool {
+	events := append([]Event{}, r.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return compareVersions(r.Type, eventVersion(events[i]), eventVersion(events[j])) < 0
+	})
+
+	affected := false
+	for _, event := range events {
+		switch {
+		case event.Introduced != "":
+			if event.Introduced == "0" || compareVersions(r.Type, version, event.Introduced) >= 0 {
+				affected = true
+			}
+		case event.Fixed != "":
+			if compareVersions(r.Type, version, event.Fixed) >= 0 {
+				affected = false
+			}
+		case event.LastAffected != "":
+			if compareVersions(r.Type, version, event.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func eventVersion(event Event) string {
+	switch {
+	case event.Introduced != "":
+		return event.Introduced
+	case event.Fixed != "":
+		return event.Fixed
+	}
+	return event.LastAffected
+}
+
+// FixedVersions returns the versions fixing a vulnerability for a package
+func FixedVersions(vulnerability Vulnerability, pkg Package) []string {
+	var fixed []string
+	seen := make(map[string]bool)
+	for _, affected := range affectedPackages(vulnerability, pkg) {
+		for _, r := range affected.Ranges {
+			if r.Type == "GIT" {
+				continue
+			}
+			for _, event := range r.Events {
+				if event.Fixed != "" && !seen[event.Fixed] {
+					seen[event.Fixed] = true
+					fixed = append(fixed, event.Fixed)
+				}
+			}
+		}
+	}
+	sort.Slice(fixed, func(i, j int) bool { return compareVersions("", fixed[i], fixed[j]) < 0 })
+	return fixed
+}
@@ -0,0 +1,10 @@
+package osv
+
+import (
+	"github.com/flanksource/arch-unit/linters"
+)
+
+func init() {
+	// Register the vulnerability linter with the default registry
+	linters.DefaultRegistry.Register(NewOSV("."))
+}
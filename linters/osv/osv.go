@@ -0,0 +1,289 @@
+package osv
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/dependencies"
+	goAnalysis "github.com/flanksource/arch-unit/analysis/go"
+	"github.com/flanksource/arch-unit/analysis/java"
+	pythonAnalysis "github.com/flanksource/arch-unit/analysis/python"
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/commons/logger"
+)
+
+// Ecosystems maps the dependency types checked for vulnerabilities to their OSV ecosystem
+var Ecosystems = map[models.DependencyType]string{
+	models.DependencyTypeGo:    "Go",
+	models.DependencyTypeNpm:   "npm",
+	models.DependencyTypePip:   "PyPI",
+	models.DependencyTypeMaven: "Maven",
+	models.DependencyTypeNuget: "NuGet",
+}
+
+// OSV reports the scanned dependencies affected by known vulnerabilities of the OSV.dev database
+type OSV struct {
+	linters.RunOptions
+	// Database overrides the database configured by vulnerabilities.database, for tests
+	Database  Database
+	fileCount int
+}
+
+// NewOSV creates a new vulnerability linter
+func NewOSV(workDir string) *OSV {
+	return &OSV{RunOptions: linters.RunOptions{WorkDir: workDir}}
+}
+
+// Name returns the linter name
+func (o *OSV) Name() string {
+	return "osv"
+}
+
+// DefaultIncludes returns default file patterns this linter should process
+func (o *OSV) DefaultIncludes() []string {
+	return newRegistry().GetAllSupportedFiles()
+}
+
+// DefaultExcludes returns patterns this linter should ignore by default
+func (o *OSV) DefaultExcludes() []string {
+	return []string{"vendor/**", "node_modules/**", ".git/**"}
+}
+
+// SupportsJSON returns true if linter supports JSON output
+func (o *OSV) SupportsJSON() bool {
+	return true
+}
+
+// JSONArgs returns additional args needed for JSON output
+func (o *OSV) JSONArgs() []string {
+	return []string{}
+}
+
+// SupportsFix returns true if linter supports auto-fixing violations
+func (o *OSV) SupportsFix() bool {
+	return false
+}
+
+// FixArgs returns additional args needed for fix mode
+func (o *OSV) FixArgs() []string {
+	return []string{}
+}
+
+// ValidateConfig validates linter-specific configuration
+func (o *OSV) ValidateConfig(config *models.LinterConfig) error {
+	return nil
+}
+
+// GetFileCount returns the number of dependency files checked by the last run
+func (o *OSV) GetFileCount() int {
+	return o.fileCount
+}
+
+// GetRuleCount returns the number of ecosystems checked
+func (o *OSV) GetRuleCount() int {
+	return len(Ecosystems)
+}
+
+// newRegistry returns the scanners of the dependency types with an OSV ecosystem
+func newRegistry() *analysis.DependencyRegistry {
+	registry := analysis.NewDependencyRegistry()
+	registry.Register(goAnalysis.NewGoDependencyScanner())
+	registry.Register(dependencies.NewNpmDependencyScanner())
+	registry.Register(pythonAnalysis.NewPythonDependencyScanner())
+	registry.Register(java.NewJavaDependencyScanner())
+	registry.Register(dependencies.NewNugetDependencyScanner())
+	return registry
+}
+
+// Run scans the dependencies of the working directory and returns a violation for every
+// vulnerability affecting them, at the line of the file declaring the dependency
+func (o *OSV) Run(ctx context.Context, opts linters.RunOptions) ([]models.Violation, error) {
+	registry := newRegistry()
+	if len(opts.Files) > 0 && !touchesDependencies(registry, opts.Files) {
+		logger.Debugf("No dependency file changed, skipping vulnerability scanning")
+		return nil, nil
+	}
+
+	var config *models.VulnerabilityConfig
+	if opts.ArchConfig != nil {
+		config = opts.ArchConfig.Vulnerabilities
+	}
+
+	database := o.Database
+	if database == nil {
+		if config != nil && config.Database != "" {
+			dir := config.Database
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(opts.WorkDir, dir)
+			}
+			database = NewLocalDatabase(dir)
+		} else if err := offline.Check("OSV vulnerability scanning (configure vulnerabilities.database)"); err != nil {
+			return nil, err
+		} else {
+			database = NewAPI()
+		}
+	}
+
+	scanner := dependencies.NewScannerWithRegistry(registry)
+	defer func() { _ = scanner.Close() }()
+	deps, err := scanner.ScanDirectory(nil, opts.WorkDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan dependencies: %w", err)
+	}
+
+	var packages []Package
+	var scanned []*models.Dependency
+	files := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		ecosystem, ok := Ecosystems[dep.Type]
+		if !ok || !config.IsEcosystemEnabled(string(dep.Type)) || !isExactVersion(dep.Version) {
+			continue
+		}
+		pkg := Package{Ecosystem: ecosystem, Name: dep.Name, Version: dep.Version}
+		if dep.Type == models.DependencyTypeGo {
+			pkg.Version = strings.TrimPrefix(pkg.Version, "v")
+		}
+		key := pkg.Ecosystem + ":" + pkg.Name + "@" + pkg.Version + ":" + dep.Source
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		packages = append(packages, pkg)
+		scanned = append(scanned, dep)
+		file, _ := sourceLocation(opts.WorkDir, dep)
+		files[file] = true
+	}
+	o.fileCount = len(files)
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	results, err := database.Query(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []models.Violation
+	for i, vulnerabilities := range results {
+		dep := scanned[i]
+		for _, vulnerability := range vulnerabilities {
+			ids := append([]string{vulnerability.ID}, vulnerability.Aliases...)
+			if config.IsIgnored(string(dep.Type), ids...) {
+				continue
+			}
+			severity := Severity(vulnerability)
+			if minSeverity := config.GetMinSeverity(string(dep.Type)); minSeverity != "" &&
+				models.VulnerabilitySeverityRank(orMedium(severity)) < models.VulnerabilitySeverityRank(minSeverity) {
+				continue
+			}
+			violations = append(violations, o.violation(opts.WorkDir, dep, packages[i], vulnerability, severity))
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		if violations[i].Line != violations[j].Line {
+			return violations[i].Line < violations[j].Line
+		}
+		return *violations[i].Message < *violations[j].Message
+	})
+	logger.Debugf("Found %d vulnerabilities in %d dependencies", len(violations), len(packages))
+	return violations, nil
+}
+
+// violation builds the violation of a vulnerability, naming its CVEs and the versions fixing it
+func (o *OSV) violation(workDir string, dep *models.Dependency, pkg Package, vulnerability Vulnerability, severity string) models.Violation {
+	message := fmt.Sprintf("%s@%s is affected by %s", dep.Name, dep.Version, vulnerability.ID)
+	if cves := CVEs(vulnerability); len(cves) > 0 {
+		message += " (" + strings.Join(cves, ", ") + ")"
+	}
+	if severity != "" {
+		message += ", severity " + severity
+	}
+	if vulnerability.Summary != "" {
+		message += ": " + vulnerability.Summary
+	}
+	if fixed := FixedVersions(vulnerability, pkg); len(fixed) > 0 {
+		message += ", fixed in " + strings.Join(fixed, ", ")
+	} else {
+		message += ", no fix available"
+	}
+
+	file, line := sourceLocation(workDir, dep)
+	return models.NewViolationBuilder().
+		WithFile(file).
+		WithLocation(line, 0).
+		WithCaller(dep.Name, dep.Version).
+		WithCalled(o.Name(), vulnerability.ID).
+		WithMessage(message).
+		WithSource(o.Name()).
+		WithSeverity(violationSeverity(severity)).
+		WithRuleFromLinter(o.Name(), vulnerability.ID).
+		Build()
+}
+
+// CVEs returns the CVE IDs of a vulnerability, its own ID or its aliases
+func CVEs(vulnerability Vulnerability) []string {
+	var cves []string
+	for _, id := range append([]string{vulnerability.ID}, vulnerability.Aliases...) {
+		if strings.HasPrefix(id, "CVE-") {
+			cves = append(cves, id)
+		}
+	}
+	return cves
+}
+
+// orMedium ranks vulnerabilities of unknown severity as medium ones
+func orMedium(severity string) string {
+	if severity == "" {
+		return "medium"
+	}
+	return severity
+}
+
+// isExactVersion reports whether a version is a resolved version rather than a range such as
+// ^1.2.0 or >=2.0, or a Maven property
+func isExactVersion(version string) bool {
+	return version != "" && !strings.ContainsAny(version, "^~<>=*, [()]$|")
+}
+
+// sourceLocation splits the source of a dependency, file:line, into the file under workDir and
+// the line, 0 when unknown
+func sourceLocation(workDir string, dep *models.Dependency) (string, int) {
+	source := dep.Source
+	if source == "go mod graph" {
+		source = "go.mod" // Modules only found in the build list are required through go.mod
+	}
+
+	file := source
+	line := 0
+	if i := strings.LastIndex(source, ":"); i > 0 {
+		if n, err := strconv.Atoi(source[i+1:]); err == nil {
+			file, line = source[:i], n
+		}
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(workDir, file)
+	}
+	return file, line
+}
+
+// touchesDependencies reports whether any of the files is a dependency file
+func touchesDependencies(registry *analysis.DependencyRegistry, files []string) bool {
+	for _, file := range files {
+		if _, ok := registry.GetScannerForFile(file); ok {
+			return true
+		}
+	}
+	return false
+}
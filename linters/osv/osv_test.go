@@ -0,0 +1,175 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/offline"
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("OSV linter", func() {
+	var workDir, database string
+
+	BeforeEach(func() {
+		var err error
+		workDir, err = filepath.Abs(filepath.Join("testdata", "project"))
+		Expect(err).NotTo(HaveOccurred())
+		database, err = filepath.Abs(filepath.Join("testdata", "database"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	run := func(linter *OSV, config *models.VulnerabilityConfig) []string {
+		violations, err := linter.Run(context.Background(), linters.RunOptions{
+			WorkDir:    workDir,
+			ArchConfig: &models.Config{Vulnerabilities: config},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, v := range violations {
+			Expect(v.Source).To(Equal("osv"))
+			lines = append(lines, fmt.Sprintf("%s:%d %s %s", filepath.Base(v.File), v.Line, v.Severity, *v.Message))
+		}
+		return lines
+	}
+
+	lodash := "package-lock.json:15 error lodash@4.17.20 is affected by GHSA-35jh-r3h4-6jhm (CVE-2021-23337), severity high: Command Injection in lodash, fixed in 4.17.21"
+	jinja := "requirements.txt:1 warning jinja2@2.11.2 is affected by PYSEC-2021-66 (CVE-2020-28493), severity medium: ReDoS in the urlize filter of Jinja2, fixed in 2.11.3"
+
+	It("should report vulnerable dependencies from a local database", func() {
+		Expect(run(NewOSV(workDir), &models.VulnerabilityConfig{Database: database})).To(Equal([]string{lodash, jinja}))
+	})
+
+	It("should apply the ecosystem, severity and ignore settings", func() {
+		disabled := false
+		Expect(run(NewOSV(workDir), &models.VulnerabilityConfig{
+			Database:   database,
+			Ecosystems: map[string]models.VulnerabilityEcosystemConfig{"pip": {Enabled: &disabled}},
+		})).To(Equal([]string{lodash}))
+
+		Expect(run(NewOSV(workDir), &models.VulnerabilityConfig{Database: database, MinSeverity: "high"})).To(Equal([]string{lodash}))
+
+		Expect(run(NewOSV(workDir), &models.VulnerabilityConfig{
+			Database:   database,
+			Ecosystems: map[string]models.VulnerabilityEcosystemConfig{"npm": {Ignore: []string{"CVE-2021-23337"}}},
+		})).To(Equal([]string{jinja}))
+	})
+
+	It("should batch queries to the OSV API and fetch each vulnerability once", func() {
+		var mu sync.Mutex
+		fetched := make(map[string]int)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.URL.Path == "/querybatch" {
+				var request struct{ Queries []apiQuery }
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				var results []map[string]interface{}
+				for _, query := range request.Queries {
+					result := map[string]interface{}{}
+					switch {
+					case query.Package.Name == "lodash" && query.PageToken == "":
+						// The vulnerability is repeated on the next page
+						result["vulns"] = []map[string]string{{"id": "GHSA-35jh-r3h4-6jhm"}}
+						result["next_page_token"] = "page-2"
+					case query.Package.Name == "lodash":
+						result["vulns"] = []map[string]string{{"id": "GHSA-35jh-r3h4-6jhm"}}
+					case query.Package.Name == "jinja2":
+						result["vulns"] = []map[string]string{{"id": "PYSEC-2021-66"}}
+					}
+					results = append(results, result)
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+				return
+			}
+
+			id := strings.TrimPrefix(r.URL.Path, "/vulns/")
+			fetched[id]++
+			files, _ := filepath.Glob(filepath.Join(database, "*", id+".json"))
+			if len(files) == 0 {
+				http.NotFound(w, r)
+				return
+			}
+			http.ServeFile(w, r, files[0])
+		}))
+		defer server.Close()
+
+		api := NewAPI()
+		api.URL = server.URL
+		linter := NewOSV(workDir)
+		linter.Database = api
+
+		Expect(run(linter, nil)).To(Equal([]string{lodash, jinja}))
+		Expect(fetched).To(Equal(map[string]int{"GHSA-35jh-r3h4-6jhm": 1, "PYSEC-2021-66": 1}))
+	})
+
+	It("should require a local database in offline mode", func() {
+		offline.SetEnabled(true)
+		DeferCleanup(offline.SetEnabled, false)
+
+		_, err := NewOSV(workDir).Run(context.Background(), linters.RunOptions{WorkDir: workDir})
+		Expect(err).To(MatchError(offline.ErrNetworkRequired))
+	})
+
+	It("should skip runs on files that are not dependency files", func() {
+		violations, err := NewOSV(workDir).Run(context.Background(), linters.RunOptions{
+			WorkDir: workDir,
+			Files:   []string{filepath.Join(workDir, "main.go")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Severity", func() {
+	DescribeTable("CVSS v3 base scores",
+		func(vector string, score float64) {
+			base, ok := cvss3BaseScore(vector)
+			Expect(ok).To(BeTrue())
+			Expect(base).To(Equal(score))
+		},
+		Entry("critical", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8),
+		Entry("scope changed", "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:L/I:L/A:N", 6.4),
+		Entry("high privileges", "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H", 7.2),
+		Entry("no impact", "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0.0),
+	)
+
+	It("should prefer the severity of the advisory database", func() {
+		vulnerability := Vulnerability{
+			Severity:         []SeverityScore{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}},
+			DatabaseSpecific: map[string]interface{}{"severity": "MODERATE"},
+		}
+		Expect(Severity(vulnerability)).To(Equal("medium"))
+
+		vulnerability.DatabaseSpecific = nil
+		Expect(Severity(vulnerability)).To(Equal("critical"))
+		Expect(Severity(Vulnerability{})).To(BeEmpty())
+	})
+
+	DescribeTable("version ordering",
+		func(rangeType, a, b string, expected int) {
+			Expect(compareVersions(rangeType, a, b)).To(Equal(expected))
+		},
+		Entry("semver", "SEMVER", "1.10.0", "1.9.0", 1),
+		Entry("semver pre-release", "SEMVER", "1.0.0-beta.1", "1.0.0", -1),
+		Entry("numeric parts", "ECOSYSTEM", "2.11.2", "2.11.10", -1),
+		Entry("pre-release label", "ECOSYSTEM", "1.0rc1", "1.0", -1),
+		Entry("trailing number", "ECOSYSTEM", "1.0", "1.0.1", -1),
+		Entry("equal", "ECOSYSTEM", "4.17.21", "4.17.21", 0),
+	)
+})
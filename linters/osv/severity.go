@@ -0,0 +1,224 @@
+package osv
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/flanksource/arch-unit/models"
+	"golang.org/x/mod/semver"
+)
+
+// Severity returns the severity of a vulnerability, low, medium, high or critical, or "" when the
+// record gives none. The severity assigned by the advisory database is preferred over the one
+// computed from a CVSS v3 vector.
+func Severity(vulnerability Vulnerability) string {
+	if severity := severityLabel(vulnerability.DatabaseSpecific["severity"]); severity != "" {
+		return severity
+	}
+	for _, affected := range vulnerability.Affected {
+		if severity := severityLabel(affected.EcosystemSpecific["severity"]); severity != "" {
+			return severity
+		}
+	}
+	for _, score := range vulnerability.Severity {
+		if !strings.HasPrefix(score.Type, "CVSS_V3") {
+			continue
+		}
+		if base, ok := cvss3BaseScore(score.Score); ok {
+			return scoreSeverity(base)
+		}
+	}
+	return ""
+}
+
+// severityLabel normalizes the severity labels of advisory databases, e.g. GitHub's MODERATE
+func severityLabel(value interface{}) string {
+	label, _ := value.(string)
+	switch strings.ToLower(label) {
+	case "low", "negligible":
+		return "low"
+	case "moderate", "medium":
+		return "medium"
+	case "high", "important":
+		return "high"
+	case "critical":
+		return "critical"
+	}
+	return ""
+}
+
+// scoreSeverity returns the qualitative severity of a CVSS score
+func scoreSeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	}
+	return "low"
+}
+
+// violationSeverity maps the severity of a vulnerability to the severity of its violation, a
+// vulnerability of unknown severity is reported as a warning
+func violationSeverity(severity string) models.Severity {
+	switch severity {
+	case "critical", "high":
+		return models.SeverityError
+	case "low":
+		return models.SeverityInfo
+	}
+	return models.SeverityWarning
+}
+
+// cvss3Weights are the weights of the base metrics of CVSS v3, see
+// https://www.first.org/cvss/v3.1/specification-document#7-4-Metric-Values
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore computes the base score of a CVSS v3 vector such as
+// CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H
+func cvss3BaseScore(vector string) (float64, bool) {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		if key, value, ok := strings.Cut(part, ":"); ok {
+			metrics[key] = value
+		}
+	}
+	if !strings.HasPrefix(metrics["CVSS"], "3") {
+		return 0, false
+	}
+
+	weights := make(map[string]float64)
+	for metric, values := range cvss3Weights {
+		weight, ok := values[metrics[metric]]
+		if !ok {
+			return 0, false
+		}
+		weights[metric] = weight
+	}
+	changed := metrics["S"] == "C"
+	if !changed && metrics["S"] != "U" {
+		return 0, false
+	}
+	// Privileges weigh more when the scope changes
+	if changed && metrics["PR"] == "L" {
+		weights["PR"] = 0.68
+	} else if changed && metrics["PR"] == "H" {
+		weights["PR"] = 0.5
+	}
+
+	iss := 1 - (1-weights["C"])*(1-weights["I"])*(1-weights["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * weights["AV"] * weights["AC"] * weights["PR"] * weights["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp rounds up to one decimal as defined by CVSS v3.1
+func roundUp(value float64) float64 {
+	scaled := int(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
+
+// compareVersions compares versions, as semantic versions for SEMVER ranges and otherwise by their
+// numeric and alphabetic parts, where a version with a trailing pre-release part such as 1.0rc1
+// sorts before the release
+func compareVersions(rangeType, a, b string) int {
+	if rangeType == "SEMVER" {
+		va, vb := "v"+strings.TrimPrefix(a, "v"), "v"+strings.TrimPrefix(b, "v")
+		if semver.IsValid(va) && semver.IsValid(vb) {
+			return semver.Compare(va, vb)
+		}
+	}
+
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		switch {
+		case i >= len(pa):
+			return releaseOrder(pb[i])
+		case i >= len(pb):
+			return -releaseOrder(pa[i])
+		}
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return compareInts(na, nb)
+			}
+		case errA == nil:
+			return 1 // A number sorts after a pre-release label
+		case errB == nil:
+			return -1
+		default:
+			if c := strings.Compare(strings.ToLower(pa[i]), strings.ToLower(pb[i])); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// releaseOrder compares a version that has no more parts with one continuing with part, which is
+// newer when part is a number and older when it is a pre-release label
+func releaseOrder(part string) int {
+	if _, err := strconv.Atoi(part); err == nil {
+		return -1
+	}
+	return 1
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	return 1
+}
+
+// versionParts splits a version into runs of digits and of letters, dropping separators
+func versionParts(version string) []string {
+	var parts []string
+	var current strings.Builder
+	digits := false
+	for _, r := range strings.TrimPrefix(version, "v") {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		if current.Len() > 0 && unicode.IsDigit(r) != digits {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		digits = unicode.IsDigit(r)
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
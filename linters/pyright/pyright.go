@@ -143,7 +143,10 @@ func (p *Pyright) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.V
 	}
 
 	// Execute command
-	cmd := exec.CommandContext(ctx, "pyright", args...)
+	cmd, err := p.Command(ctx, "pyright", args...)
+	if err != nil {
+		return nil, err
+	}
 	cmd.Dir = p.WorkDir
 
 	logger.Infof("Executing: pyright %s", strings.Join(args, " "))
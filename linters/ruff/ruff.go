@@ -144,7 +144,10 @@ func (r *Ruff) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Viol
 	}
 
 	// Execute command
-	cmd := exec.CommandContext(ctx, "ruff", args...)
+	cmd, err := r.Command(ctx, "ruff", args...)
+	if err != nil {
+		return nil, err
+	}
 	cmd.Dir = r.WorkDir
 
 	logger.Infof("Executing: ruff %s", strings.Join(args, " "))
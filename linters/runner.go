@@ -3,9 +3,11 @@ package linters
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/internal/capabilities"
 	"github.com/flanksource/arch-unit/models"
 	"github.com/flanksource/clicky"
 	"github.com/flanksource/clicky/task"
@@ -22,11 +24,21 @@ type Runner struct {
 	config         *models.Config
 	workDir        string
 	noCache        bool
+	noDebounce     bool
+	stream         func(models.Violation)
 }
 
 // RunnerOptions configures the runner behavior
 type RunnerOptions struct {
 	NoCache bool // Disable caching
+
+	// NoDebounce runs linters even when they ran within their debounce, e.g. on the changes seen
+	// in watch mode
+	NoDebounce bool
+
+	// Stream receives every violation as soon as its linter reports it, and the violations are
+	// then dropped from the returned results to keep memory flat on large runs
+	Stream func(models.Violation)
 }
 
 // NewRunner creates a new linter runner with intelligent debouncing
@@ -59,6 +71,8 @@ func NewRunnerWithOptions(config *models.Config, workDir string, opts RunnerOpti
 		config:         config,
 		workDir:        workDir,
 		noCache:        opts.NoCache,
+		noDebounce:     opts.NoDebounce,
+		stream:         opts.Stream,
 	}, nil
 }
 
@@ -131,14 +145,18 @@ func (r *Runner) RunWithIntelligentDebounce(ctx context.Context, linterName stri
 	config := r.config.GetLinterConfig(linterName, r.workDir)
 
 	// Check intelligent debounce (only if cache is enabled)
-	if r.linterStats != nil {
+	if r.linterStats != nil && !r.noDebounce {
 		shouldSkip, actualDebounce, err := r.linterStats.ShouldSkipLinter(linterName, r.workDir, config.Debounce)
 		if err != nil {
 			logger.Warnf("Failed to check debounce for %s: %v", linterName, err)
 		} else if shouldSkip {
 			logger.Debugf("Skipping %s due to intelligent debounce (%v)", linterName, actualDebounce)
 			// Load cached violations and return
-			return r.loadCachedResult(linterName, actualDebounce)
+			result, err := r.loadCachedResult(linterName, actualDebounce)
+			if err == nil && result != nil {
+				r.streamViolations(result, false)
+			}
+			return result, err
 		}
 	}
 
@@ -152,6 +170,16 @@ func (r *Runner) RunWithIntelligentDebounce(ctx context.Context, linterName stri
 		NoCache:    r.noCache,
 	}
 
+	// Linters that stream their own violations report them as they are found, the rest are
+	// streamed once they return
+	streamed := false
+	if r.stream != nil {
+		opts.Stream = func(v models.Violation) {
+			streamed = true
+			r.stream(v)
+		}
+	}
+
 	if mixin, ok := linter.(OptionsMixin); ok {
 		mixin.SetOptions(opts)
 	}
@@ -161,6 +189,14 @@ func (r *Runner) RunWithIntelligentDebounce(ctx context.Context, linterName stri
 	})
 	violations, err := task.GetResult()
 
+	// A linter whose tool is not installed is skipped rather than failed, the missing tool is
+	// reported in the summary
+	if capabilities.IsMissing(err) {
+		task.SetName(fmt.Sprintf("%s (skipped, not installed)", linterName))
+		task.Warning()
+		return &LinterResult{Linter: linterName, Skipped: true, Duration: task.Duration(), Error: err.Error()}, nil
+	}
+
 	// Record execution stats
 	if r.linterStats != nil {
 		if statsErr := r.linterStats.RecordExecution(linterName, r.workDir, task.Duration(), len(violations), task.Error() == nil); statsErr != nil {
@@ -168,9 +204,10 @@ func (r *Runner) RunWithIntelligentDebounce(ctx context.Context, linterName stri
 		}
 	}
 
-	// Cache violations if successful
-	if task.Error() == nil && len(violations) > 0 && r.violationCache != nil {
-		r.cacheViolations(linterName, violations)
+	// Cache the violations of successful runs, replacing those of the files checked even when
+	// none are left
+	if cacher, ok := linter.(ViolationCacher); task.Error() == nil && r.violationCache != nil && !(ok && cacher.CachesViolations()) {
+		r.cacheViolations(linterName, files, violations)
 	}
 
 	r.updateTaskStatus(task.Task, linterName, task.IsOk(), len(violations), task.Error())
@@ -189,9 +226,26 @@ func (r *Runner) RunWithIntelligentDebounce(ctx context.Context, linterName stri
 		result.RuleCount = metadata.GetRuleCount()
 	}
 
+	r.streamViolations(result, streamed)
 	return result, nil
 }
 
+// streamViolations sends the violations of a result to the stream, unless the linter already
+// streamed them, and drops them from the result
+func (r *Runner) streamViolations(result *LinterResult, streamed bool) {
+	if r.stream == nil {
+		return
+	}
+
+	if !streamed {
+		for _, v := range result.Violations {
+			r.stream(v)
+		}
+	}
+	result.Streamed += len(result.Violations)
+	result.Violations = nil
+}
+
 // loadCachedResult loads cached violations for debounced linters
 func (r *Runner) loadCachedResult(linterName string, debounce time.Duration) (*LinterResult, error) {
 
@@ -227,23 +281,16 @@ func (r *Runner) loadCachedResult(linterName string, debounce time.Duration) (*L
 	return task.GetResult()
 }
 
-// cacheViolations stores violations in the cache
-func (r *Runner) cacheViolations(linterName string, violations []models.Violation) {
-	if r.violationCache == nil {
+// cacheViolations replaces the cached violations of a linter in the files it checked, or in every
+// file of the working directory when it checked them all
+func (r *Runner) cacheViolations(linterName string, files []string, violations []models.Violation) {
+	workDir, err := filepath.Abs(r.workDir)
+	if err != nil {
+		logger.Debugf("Failed to cache violations of %s: %v", linterName, err)
 		return
 	}
-
-	// Group violations by file
-	fileViolations := make(map[string][]models.Violation)
-	for _, v := range violations {
-		fileViolations[v.File] = append(fileViolations[v.File], v)
-	}
-
-	// Store each file's violations
-	for file, vList := range fileViolations {
-		if err := r.violationCache.StoreViolations(file, vList); err != nil {
-			logger.Debugf("Failed to cache linter violations for %s: %v", file, err)
-		}
+	if err := r.violationCache.ReplaceViolations(linterName, workDir, files, violations); err != nil {
+		logger.Debugf("Failed to cache violations of %s: %v", linterName, err)
 	}
 }
 
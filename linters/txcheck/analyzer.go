@@ -0,0 +1,369 @@
+package txcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	goAnalysis "github.com/flanksource/arch-unit/analysis/go"
+	"github.com/flanksource/arch-unit/linters/gocheck"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Checks reported by the analyzer
+const (
+	// CheckTransactionLayer flags transactions opened outside the repository layer
+	CheckTransactionLayer = "transaction-layer"
+	// CheckHTTPInTransaction flags HTTP calls made while a transaction is open
+	CheckHTTPInTransaction = "http-in-transaction"
+)
+
+// AllChecks lists every check in the order they are documented
+var AllChecks = []string{CheckTransactionLayer, CheckHTTPInTransaction}
+
+// beginMethods are the database/sql, sqlx and GORM methods that open a transaction
+var beginMethods = map[string]bool{
+	"Begin": true, "BeginTx": true, "Beginx": true, "BeginTxx": true, "MustBegin": true, "MustBeginTx": true, "Transaction": true,
+}
+
+// Finding is a transaction opened in the wrong layer or an HTTP call made inside a transaction
+type Finding = gocheck.Finding
+
+// repositoryNames are the package and directory names of the data access layer, when no layer
+// of arch-unit.yaml is marked with transactions
+var repositoryNames = map[string]bool{
+	"repository": true, "repositories": true, "repo": true, "repos": true, "store": true, "stores": true,
+	"storage": true, "dao": true, "dal": true, "db": true, "database": true, "persistence": true,
+}
+
+// IsRepositoryLayer returns true when a package belongs to the layer of arch-unit.yaml marked with
+// transactions or, without such a layer, when the package or its directory is named like a data
+// access layer, e.g. repository, store, dao or db. Names must match as a whole, a reporting or
+// keystore package is not a data access layer.
+func IsRepositoryLayer(pkg, dir string, layers models.Layers) bool {
+	if layers.HasTransactions() {
+		layer := layers.Find(&models.ASTNode{PackageName: pkg})
+		return layer != nil && layer.Transactions
+	}
+	return repositoryNames[strings.ToLower(pkg)] || repositoryNames[strings.ToLower(dir)]
+}
+
+// function is a function declaration of the analyzed package
+type function struct {
+	decl       *ast.FuncDecl
+	imports    map[string]string
+	name       string
+	recv       string // receiver variable of a method
+	recvType   string
+	repository bool // declared in the repository layer
+}
+
+// transaction is the part of a function during which a transaction is open
+type transaction struct {
+	line       int // line the transaction is opened on
+	start, end token.Pos
+}
+
+type analyzer struct {
+	fset      *token.FileSet
+	functions map[string]*function
+	http      map[string]bool // functions making HTTP calls, directly or through other functions
+	findings  []Finding
+}
+
+// Analyze reports transactions opened outside the repository layer and HTTP calls made while a
+// transaction is open. Calls are classified like the statements of the Go AST extractor, and HTTP
+// calls made by other functions of the package are followed through the package's call graph. All
+// files must belong to the same package.
+func Analyze(fset *token.FileSet, files []*ast.File) []Finding {
+	return analyze(fset, files, nil)
+}
+
+// AnalyzeLayers returns the analysis of Analyze with the repository layer taken from the layers
+// of arch-unit.yaml
+func AnalyzeLayers(layers models.Layers) gocheck.Analyzer {
+	return func(fset *token.FileSet, files []*ast.File) []Finding {
+		return analyze(fset, files, layers)
+	}
+}
+
+func analyze(fset *token.FileSet, files []*ast.File, layers models.Layers) []Finding {
+	a := &analyzer{fset: fset, functions: map[string]*function{}, http: map[string]bool{}}
+
+	var functions []*function
+	for _, file := range files {
+		imports := goAnalysis.Imports(file)
+		dir := filepath.Base(filepath.Dir(fset.Position(file.Pos()).Filename))
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			f := &function{
+				decl:       fn,
+				imports:    imports,
+				name:       funcName(fn),
+				repository: IsRepositoryLayer(file.Name.Name, dir, layers),
+			}
+			if fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 {
+				f.recv = fn.Recv.List[0].Names[0].Name
+				f.recvType = typeName(fn.Recv.List[0].Type)
+			}
+			a.functions[f.name] = f
+			functions = append(functions, f)
+		}
+	}
+
+	a.buildCallGraph(functions)
+	for _, f := range functions {
+		a.check(f)
+	}
+
+	sort.SliceStable(a.findings, func(i, j int) bool {
+		if a.findings[i].Pos.Filename != a.findings[j].Pos.Filename {
+			return a.findings[i].Pos.Filename < a.findings[j].Pos.Filename
+		}
+		return a.findings[i].Pos.Offset < a.findings[j].Pos.Offset
+	})
+	return a.findings
+}
+
+// buildCallGraph marks the functions that make HTTP calls themselves or call a function of the
+// package that does
+func (a *analyzer) buildCallGraph(functions []*function) {
+	callees := make(map[string][]string)
+	for _, f := range functions {
+		ast.Inspect(f.decl.Body, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if goAnalysis.ClassifyCall(call, f.imports) == models.ASTStatementTypeHttpCall {
+				a.http[f.name] = true
+			} else if callee := a.callee(f, call); callee != "" {
+				callees[f.name] = append(callees[f.name], callee)
+			}
+			return true
+		})
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for caller, called := range callees {
+			if a.http[caller] {
+				continue
+			}
+			for _, callee := range called {
+				if a.http[callee] {
+					a.http[caller] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+}
+
+func (a *analyzer) check(f *function) {
+	transactions := a.transactions(f)
+	if len(transactions) == 0 {
+		return
+	}
+
+	ast.Inspect(f.decl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		tx := openAt(transactions, call.Pos())
+		if tx == nil {
+			return true
+		}
+		if goAnalysis.ClassifyCall(call, f.imports) == models.ASTStatementTypeHttpCall {
+			a.report(f, CheckHTTPInTransaction, call, "%s makes an HTTP call while the transaction opened on line %d is open", types.ExprString(call.Fun), tx.line)
+		} else if callee := a.callee(f, call); callee != "" && a.http[callee] {
+			a.report(f, CheckHTTPInTransaction, call, "%s makes HTTP calls while the transaction opened on line %d is open", types.ExprString(call.Fun), tx.line)
+		}
+		return true
+	})
+}
+
+// transactions returns the transactions opened by a function, reporting those opened outside the
+// repository layer. A transaction run by a closure, as with GORM's db.Transaction(func(tx *gorm.DB) error),
+// is open for the body of the closure. One opened by Begin is open from the statement calling Begin to
+// the next statement of the same block calling Commit, or the end of the block.
+func (a *analyzer) transactions(f *function) []transaction {
+	var transactions []transaction
+	begins := make(map[*ast.CallExpr]bool)
+
+	ast.Inspect(f.decl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || !isBegin(call, f.imports) {
+			return true
+		}
+		begins[call] = true
+		if !f.repository {
+			a.report(f, CheckTransactionLayer, call, "%s opens a transaction outside the repository layer", types.ExprString(call.Fun))
+		}
+		for _, arg := range call.Args {
+			if closure, ok := ast.Unparen(arg).(*ast.FuncLit); ok {
+				transactions = append(transactions, a.newTransaction(call, closure.Body.Lbrace, closure.Body.Rbrace))
+			}
+		}
+		return true
+	})
+
+	ast.Inspect(f.decl.Body, func(node ast.Node) bool {
+		var list []ast.Stmt
+		var end token.Pos
+		switch n := node.(type) {
+		case *ast.BlockStmt:
+			list, end = n.List, n.Rbrace
+		case *ast.CaseClause:
+			list, end = n.Body, n.End()
+		case *ast.CommClause:
+			list, end = n.Body, n.End()
+		default:
+			return true
+		}
+
+		for i, stmt := range list {
+			call := statementCall(stmt)
+			if call == nil || !begins[call] || hasClosure(call) {
+				continue
+			}
+			tx := a.newTransaction(call, stmt.End(), end)
+			for _, next := range list[i+1:] {
+				if commits(next) {
+					tx.end = next.Pos()
+					break
+				}
+			}
+			transactions = append(transactions, tx)
+		}
+		return true
+	})
+	return transactions
+}
+
+func (a *analyzer) newTransaction(begin *ast.CallExpr, start, end token.Pos) transaction {
+	return transaction{line: a.fset.Position(begin.Pos()).Line, start: start, end: end}
+}
+
+func (a *analyzer) report(f *function, check string, node ast.Node, format string, args ...interface{}) {
+	a.findings = append(a.findings, Finding{
+		Check:    check,
+		Pos:      a.fset.Position(node.Pos()),
+		Function: f.name,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// callee returns the name of the called function when it is declared in the package. Without type
+// information methods are only resolved on the method's receiver.
+func (a *analyzer) callee(f *function, call *ast.CallExpr) string {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		if _, ok := a.functions[fun.Name]; ok {
+			return fun.Name
+		}
+	case *ast.SelectorExpr:
+		if recv, ok := fun.X.(*ast.Ident); ok && f.recv != "" && recv.Name == f.recv {
+			if _, ok := a.functions[f.recvType+"."+fun.Sel.Name]; ok {
+				return f.recvType + "." + fun.Sel.Name
+			}
+		}
+	}
+	return ""
+}
+
+// openAt returns the transaction open at pos, if any
+func openAt(transactions []transaction, pos token.Pos) *transaction {
+	for i := range transactions {
+		if pos > transactions[i].start && pos < transactions[i].end {
+			return &transactions[i]
+		}
+	}
+	return nil
+}
+
+// isBegin returns true when call opens a transaction on a database handle
+func isBegin(call *ast.CallExpr, imports map[string]string) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	return ok && beginMethods[sel.Sel.Name] && goAnalysis.ClassifyCall(call, imports) == models.ASTStatementTypeSQLQuery
+}
+
+func hasClosure(call *ast.CallExpr) bool {
+	for _, arg := range call.Args {
+		if _, ok := ast.Unparen(arg).(*ast.FuncLit); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// statementCall returns the call made by an expression statement or assigned by a statement,
+// e.g. tx for tx, err := db.Begin()
+func statementCall(stmt ast.Stmt) *ast.CallExpr {
+	var expr ast.Expr
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		expr = s.X
+	case *ast.AssignStmt:
+		if len(s.Rhs) == 1 {
+			expr = s.Rhs[0]
+		}
+	case *ast.DeclStmt:
+		if gen, ok := s.Decl.(*ast.GenDecl); ok && len(gen.Specs) == 1 {
+			if spec, ok := gen.Specs[0].(*ast.ValueSpec); ok && len(spec.Values) == 1 {
+				expr = spec.Values[0]
+			}
+		}
+	}
+	call, _ := ast.Unparen(expr).(*ast.CallExpr)
+	return call
+}
+
+// commits returns true when stmt commits a transaction, deferred calls and closures aside
+func commits(stmt ast.Stmt) bool {
+	found := false
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.DeferStmt, *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if sel, ok := ast.Unparen(n.Fun).(*ast.SelectorExpr); ok && sel.Sel.Name == "Commit" {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// funcName returns the name of a function, Type.Method for methods
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		return typeName(fn.Recv.List[0].Type) + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// typeName returns the type name of a receiver, e.g. Store for (s *Store[T])
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	case *ast.IndexListExpr:
+		return typeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
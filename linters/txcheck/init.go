@@ -0,0 +1,10 @@
+package txcheck
+
+import (
+	"github.com/flanksource/arch-unit/linters"
+)
+
+func init() {
+	// Register the transaction boundary linter with the default registry
+	linters.DefaultRegistry.Register(NewTxCheck("."))
+}
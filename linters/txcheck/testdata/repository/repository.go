@@ -0,0 +1,21 @@
+package repository
+
+import "database/sql"
+
+type Repository struct {
+	db *sql.DB
+}
+
+// Save opens a transaction in the repository layer
+func (r *Repository) Save(name string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("INSERT INTO items (name) VALUES (?)", name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
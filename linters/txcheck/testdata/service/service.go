@@ -0,0 +1,61 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+type Service struct {
+	db     *sql.DB
+	gormDB *gorm.DB
+	client *http.Client
+}
+
+func (s *Service) notify(url string) error {
+	_, err := s.client.Post(url, "application/json", nil)
+	return err
+}
+
+// Transfer opens a transaction in the service layer and calls out while it is open
+func (s *Service) Transfer(url string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE accounts SET balance = balance - 1"); err != nil {
+		return err
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if err := s.notify(url); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	// The transaction is committed
+	return s.notify(url)
+}
+
+// Archive runs a GORM transaction that calls out before it returns
+func (s *Service) Archive(url string) error {
+	return s.gormDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM items").Error; err != nil {
+			return err
+		}
+		return s.notify(url)
+	})
+}
+
+// Fetch makes HTTP calls without a transaction
+func (s *Service) Fetch(url string) error {
+	return s.notify(url)
+}
@@ -0,0 +1,86 @@
+package txcheck
+
+import (
+	"context"
+
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/linters/gocheck"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// TxCheck reports Go database transactions opened outside the repository layer or held open
+// across HTTP calls
+type TxCheck struct {
+	linters.RunOptions
+	fileCount int
+}
+
+// NewTxCheck creates a new transaction boundary linter
+func NewTxCheck(workDir string) *TxCheck {
+	return &TxCheck{RunOptions: linters.RunOptions{WorkDir: workDir}}
+}
+
+// Name returns the linter name
+func (c *TxCheck) Name() string {
+	return "txcheck"
+}
+
+// DefaultIncludes returns default file patterns this linter should process
+func (c *TxCheck) DefaultIncludes() []string {
+	return []string{"**/*.go"}
+}
+
+// DefaultExcludes returns patterns this linter should ignore by default
+func (c *TxCheck) DefaultExcludes() []string {
+	return []string{"vendor/**", ".git/**", "**/*_test.go"}
+}
+
+// SupportsJSON returns true if linter supports JSON output
+func (c *TxCheck) SupportsJSON() bool {
+	return true
+}
+
+// JSONArgs returns additional args needed for JSON output
+func (c *TxCheck) JSONArgs() []string {
+	return []string{}
+}
+
+// SupportsFix returns true if linter supports auto-fixing violations
+func (c *TxCheck) SupportsFix() bool {
+	return false
+}
+
+// FixArgs returns additional args needed for fix mode
+func (c *TxCheck) FixArgs() []string {
+	return []string{}
+}
+
+// ValidateConfig validates linter-specific configuration
+func (c *TxCheck) ValidateConfig(config *models.LinterConfig) error {
+	return nil
+}
+
+// GetFileCount returns the number of files analyzed by the last run
+func (c *TxCheck) GetFileCount() int {
+	return c.fileCount
+}
+
+// GetRuleCount returns the number of checks the linter applies
+func (c *TxCheck) GetRuleCount() int {
+	return len(AllChecks)
+}
+
+// Run analyzes the Go files, one package directory at a time, and returns violations for the
+// checks enabled by the quality.transaction_checks configuration of each file. The layer of
+// arch-unit.yaml marked with transactions is the repository layer, when there is one.
+func (c *TxCheck) Run(ctx context.Context, opts linters.RunOptions) ([]models.Violation, error) {
+	var layers models.Layers
+	if opts.ArchConfig != nil {
+		layers = opts.ArchConfig.Layers
+	}
+	violations, fileCount, err := gocheck.Run(c.Name(), opts, AnalyzeLayers(layers), func(quality *models.QualityConfig) *models.CheckSelection {
+		return quality.TransactionChecks
+	})
+	c.fileCount = fileCount
+	return violations, err
+}
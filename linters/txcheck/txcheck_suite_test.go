@@ -0,0 +1,13 @@
+package txcheck
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTxCheck(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TxCheck Linter Suite")
+}
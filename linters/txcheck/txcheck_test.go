@@ -0,0 +1,108 @@
+package txcheck
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/linters/gocheck"
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Transaction boundary analyzer", func() {
+	service := filepath.Join("testdata", "service")
+
+	lines := func(findings []Finding, check string) []int {
+		var result []int
+		for _, finding := range findings {
+			if finding.Check == check {
+				result = append(result, finding.Pos.Line)
+			}
+		}
+		return result
+	}
+
+	It("should report transactions opened outside the repository layer", func() {
+		findings, err := gocheck.AnalyzeDir(service, Analyze)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lines(findings, CheckTransactionLayer)).To(Equal([]int{23, 50}))
+		Expect(findings[0].Function).To(Equal("Service.Transfer"))
+		Expect(findings[0].Message).To(Equal("s.db.Begin opens a transaction outside the repository layer"))
+
+		findings, err = gocheck.AnalyzeDir(filepath.Join("testdata", "repository"), Analyze)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(BeEmpty())
+	})
+
+	It("should report HTTP calls made while a transaction is open", func() {
+		findings, err := gocheck.AnalyzeDir(service, Analyze)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Calls after the commit in Transfer and in Fetch are made without a transaction
+		Expect(lines(findings, CheckHTTPInTransaction)).To(Equal([]int{32, 37, 54}))
+		Expect(findings[1].Message).To(Equal("http.Get makes an HTTP call while the transaction opened on line 23 is open"))
+		Expect(findings[2].Message).To(Equal("s.notify makes HTTP calls while the transaction opened on line 23 is open"))
+		Expect(findings[4].Function).To(Equal("Service.Archive"))
+	})
+
+	It("should recognise repository layer packages by their whole name", func() {
+		Expect(IsRepositoryLayer("repository", "repository", nil)).To(BeTrue())
+		Expect(IsRepositoryLayer("users", "store", nil)).To(BeTrue())
+		Expect(IsRepositoryLayer("db", "internal", nil)).To(BeTrue())
+		Expect(IsRepositoryLayer("service", "service", nil)).To(BeFalse())
+		Expect(IsRepositoryLayer("api", "debug", nil)).To(BeFalse())
+		for _, name := range []string{"report", "reporting", "repository_test_helpers", "restore", "keystore", "datastore_client"} {
+			Expect(IsRepositoryLayer(name, name, nil)).To(BeFalse(), name)
+		}
+	})
+
+	It("should take the repository layer from the layers marked with transactions", func() {
+		layers := models.Layers{
+			{Name: "service", Packages: []string{"service*"}},
+			{Name: "persistence", Packages: []string{"*dal", "keystore"}, Transactions: true},
+		}
+		Expect(IsRepositoryLayer("keystore", "keystore", layers)).To(BeTrue())
+		Expect(IsRepositoryLayer("userdal", "users", layers)).To(BeTrue())
+		// Conventional names no longer apply once a layer is marked
+		Expect(IsRepositoryLayer("repository", "repository", layers)).To(BeFalse())
+		Expect(IsRepositoryLayer("service", "service", layers)).To(BeFalse())
+
+		findings, err := gocheck.AnalyzeDir(service, AnalyzeLayers(models.Layers{
+			{Name: "service", Packages: []string{"service"}, Transactions: true},
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lines(findings, CheckTransactionLayer)).To(BeEmpty())
+	})
+
+	It("should apply the transaction_checks configuration of each path", func() {
+		workDir, err := filepath.Abs(service)
+		Expect(err).NotTo(HaveOccurred())
+
+		archConfig := &models.Config{Rules: map[string]models.RuleConfig{
+			"*.go": {Quality: &models.QualityConfig{
+				TransactionChecks: &models.CheckSelection{Checks: []string{CheckTransactionLayer}},
+			}},
+		}}
+
+		violations, err := NewTxCheck(workDir).Run(context.Background(), linters.RunOptions{
+			WorkDir:    workDir,
+			ArchConfig: archConfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(HaveLen(2))
+		Expect(violations[0].Source).To(Equal("txcheck"))
+		Expect(violations[0].Line).To(Equal(23))
+		Expect(violations[0].Rule.Method).To(Equal(CheckTransactionLayer))
+
+		archConfig.Rules["*.go"].Quality.TransactionChecks.Disabled = true
+		violations, err = NewTxCheck(workDir).Run(context.Background(), linters.RunOptions{
+			WorkDir:    workDir,
+			ArchConfig: archConfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(violations).To(BeEmpty())
+	})
+})
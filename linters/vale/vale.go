@@ -181,7 +181,10 @@ func (v *Vale) Run(ctx commonsContext.Context, task *clicky.Task) ([]models.Viol
 	}
 
 	// Execute command
-	cmd := exec.CommandContext(ctx, "vale", args...)
+	cmd, err := v.Command(ctx, "vale", args...)
+	if err != nil {
+		return nil, err
+	}
 	cmd.Dir = v.WorkDir
 
 	logger.Infof("Executing: vale %s", strings.Join(args, " "))
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AIProviders are the providers of the ai section, AIEmbeddingProviders those of ai.embeddings
+var (
+	AIProviders          = []string{"openai", "anthropic", "bedrock", "ollama"}
+	AIEmbeddingProviders = []string{"openai", "ollama", "local"}
+)
+
+// AIConfig configures the LLM shared by the AI features: summarize, search, ask and
+// check --explain. Command line flags and ARCH_UNIT_LLM_* variables take precedence.
+type AIConfig struct {
+	Provider string `yaml:"provider,omitempty"` // openai, anthropic, bedrock or ollama
+	Model    string `yaml:"model,omitempty"`
+	// BaseURL of an OpenAI compatible server, such as vLLM, LM Studio or llama.cpp, or of Ollama
+	BaseURL   string `yaml:"base_url,omitempty"`
+	APIKeyEnv string `yaml:"api_key_env,omitempty"` // Variable holding the API key, keys are not stored in the file
+	Region    string `yaml:"region,omitempty"`      // AWS region of bedrock
+	// Limits of each run, 0 for no limit
+	RequestsPerMinute int              `yaml:"requests_per_minute,omitempty"`
+	TokenBudget       int              `yaml:"token_budget,omitempty"` // Estimated tokens of all prompts and completions
+	MaxTokens         int              `yaml:"max_tokens,omitempty"`   // Tokens of each completion
+	Embeddings        *EmbeddingConfig `yaml:"embeddings,omitempty"`   // Embedding model of search
+}
+
+// EmbeddingConfig selects the embedding model, separate from the completion model as not every
+// provider has one
+type EmbeddingConfig struct {
+	Provider  string `yaml:"provider,omitempty"` // openai, ollama or local
+	Model     string `yaml:"model,omitempty"`
+	BaseURL   string `yaml:"base_url,omitempty"`
+	APIKeyEnv string `yaml:"api_key_env,omitempty"`
+}
+
+// Validate checks the providers and limits
+func (c *AIConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if err := validateAIProvider(c.Provider, AIProviders); err != nil {
+		return err
+	}
+	for name, value := range map[string]int{"requests_per_minute": c.RequestsPerMinute, "token_budget": c.TokenBudget, "max_tokens": c.MaxTokens} {
+		if value < 0 {
+			return fmt.Errorf("invalid %s %d, expected a positive number or 0 for no limit", name, value)
+		}
+	}
+	if c.Embeddings != nil {
+		if err := validateAIProvider(c.Embeddings.Provider, AIEmbeddingProviders); err != nil {
+			return fmt.Errorf("embeddings: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateAIProvider(provider string, providers []string) error {
+	if provider == "" {
+		return nil
+	}
+	for _, name := range providers {
+		if strings.EqualFold(provider, name) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid provider '%s', expected one of %s", provider, strings.Join(providers, ", "))
+}
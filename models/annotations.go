@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Annotation metadata recorded by the extractors
+const (
+	// MetadataAnnotations holds the comma separated annotations of Java nodes and decorators of
+	// Python nodes, as named in the source without their arguments, e.g. Autowired,Qualifier
+	MetadataAnnotations = "annotations"
+	// MetadataTags holds the struct tag of Go fields without its backquotes, e.g. json:"name,omitempty"
+	MetadataTags = "tags"
+)
+
+// structTagKey matches the keys of the key:"value" pairs of a Go struct tag
+var structTagKey = regexp.MustCompile(`([^\s:"]+):"(?:[^"\\]|\\.)*"`)
+
+// Annotations returns the annotations of a node, the keys of the struct tag of Go fields
+func Annotations(node *ASTNode) []string {
+	var annotations []string
+	if value := node.Metatdata[MetadataAnnotations]; value != "" {
+		annotations = strings.Split(value, ",")
+	}
+	for _, match := range structTagKey.FindAllStringSubmatch(node.Metatdata[MetadataTags], -1) {
+		annotations = append(annotations, match[1])
+	}
+	return annotations
+}
+
+// AnnotationRule forbids or requires annotations on the nodes of a kind, e.g. no @Autowired field
+// injection, or json tags on the exported fields of API structs. Annotations are Java annotations,
+// Python decorators and the keys of Go struct tags.
+type AnnotationRule struct {
+	Name     string   `yaml:"name,omitempty"`     // Reported with violations, defaults to "<kind> annotations"
+	Kind     string   `yaml:"kind,omitempty"`     // type, method or field, every kind when empty
+	Path     string   `yaml:"path,omitempty"`     // Doublestar glob of the files the rule applies to, e.g. api/**
+	Symbol   string   `yaml:"symbol,omitempty"`   // Glob of the full names of the nodes, e.g. api.*
+	Exported bool     `yaml:"exported,omitempty"` // Only applies to exported or public nodes
+	Forbid   []string `yaml:"forbid,omitempty"`   // Globs of annotations the nodes must not have, e.g. Autowired
+	Require  []string `yaml:"require,omitempty"`  // Globs of annotations the nodes must all have, e.g. json
+	Reason   string   `yaml:"reason,omitempty"`   // Explanation appended to violations
+	Severity Severity `yaml:"severity,omitempty"` // Severity of violations, error when empty
+}
+
+// annotationNodeTypes maps the node kinds of annotation rules to node types
+var annotationNodeTypes = map[string]NodeType{
+	NodeTypeType:   NodeTypeType,
+	NodeTypeMethod: NodeTypeMethod,
+	NodeTypeField:  NodeTypeField,
+}
+
+// Validate checks the kind, patterns and severity of the rule
+func (r *AnnotationRule) Validate() error {
+	if _, ok := annotationNodeTypes[r.Kind]; r.Kind != "" && !ok {
+		return fmt.Errorf("invalid kind '%s', expected one of type, method or field", r.Kind)
+	}
+	if len(r.Forbid) == 0 && len(r.Require) == 0 {
+		return fmt.Errorf("at least one of 'forbid' or 'require' is required")
+	}
+	for _, pattern := range append(append([]string{r.Symbol}, r.Forbid...), r.Require...) {
+		if pattern != "" && !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid pattern '%s'", pattern)
+		}
+	}
+	if r.Severity != "" {
+		if _, err := ParseSeverity(string(r.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RuleName returns the name of the rule, or one derived from its kind
+func (r *AnnotationRule) RuleName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	if r.Kind == "" {
+		return "annotations"
+	}
+	return r.Kind + " annotations"
+}
+
+// AppliesTo returns true if the rule checks the annotations of a node
+func (r *AnnotationRule) AppliesTo(node *ASTNode) bool {
+	if _, ok := annotationNodeTypes[node.NodeType]; !ok {
+		return false
+	}
+	if r.Kind != "" && node.NodeType != annotationNodeTypes[r.Kind] {
+		return false
+	}
+	if r.Exported && node.IsPrivate {
+		return false
+	}
+	if r.Path != "" && !matchesFilePath(node.FilePath, r.Path) {
+		return false
+	}
+	if r.Symbol != "" {
+		if match, _ := doublestar.Match(r.Symbol, node.String()); !match {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns the forbidden annotations of a node and the required annotations it lacks
+func (r *AnnotationRule) Check(node *ASTNode) (forbidden, missing []string) {
+	annotations := Annotations(node)
+	for _, annotation := range annotations {
+		for _, pattern := range r.Forbid {
+			if matchesAnnotation(pattern, annotation) {
+				forbidden = append(forbidden, annotation)
+				break
+			}
+		}
+	}
+	for _, pattern := range r.Require {
+		found := false
+		for _, annotation := range annotations {
+			if matchesAnnotation(pattern, annotation) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, pattern)
+		}
+	}
+	return forbidden, missing
+}
+
+// GetSeverity returns the severity of violations of the rule
+func (r *AnnotationRule) GetSeverity() Severity {
+	if r.Severity == "" {
+		return SeverityError
+	}
+	return r.Severity
+}
+
+// matchesAnnotation matches an annotation as named in the source, or its name without qualifier,
+// e.g. Autowired matches org.springframework.beans.factory.annotation.Autowired
+func matchesAnnotation(pattern, annotation string) bool {
+	if match, _ := doublestar.Match(pattern, annotation); match {
+		return true
+	}
+	if dot := strings.LastIndex(annotation, "."); dot >= 0 {
+		match, _ := doublestar.Match(pattern, annotation[dot+1:])
+		return match
+	}
+	return false
+}
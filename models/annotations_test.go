@@ -0,0 +1,53 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("AnnotationRule", func() {
+	field := &models.ASTNode{
+		FilePath:    "/repo/service/OrderService.java",
+		PackageName: "com.example.service",
+		TypeName:    "OrderService",
+		FieldName:   "repository",
+		NodeType:    models.NodeTypeField,
+		Metatdata: map[string]string{
+			models.MetadataAnnotations: "org.springframework.beans.factory.annotation.Autowired,Qualifier",
+		},
+	}
+	tagged := &models.ASTNode{
+		PackageName: "api",
+		TypeName:    "User",
+		FieldName:   "Name",
+		NodeType:    models.NodeTypeField,
+		Metatdata:   map[string]string{models.MetadataTags: `json:"name,omitempty" db:"name" validate:"required,min=1"`},
+	}
+
+	It("should read annotations and the keys of struct tags", func() {
+		Expect(models.Annotations(field)).To(Equal([]string{"org.springframework.beans.factory.annotation.Autowired", "Qualifier"}))
+		Expect(models.Annotations(tagged)).To(Equal([]string{"json", "db", "validate"}))
+	})
+
+	It("should match annotations by their name without qualifier", func() {
+		rule := models.AnnotationRule{Forbid: []string{"Autowired"}, Require: []string{"Inject"}}
+		forbidden, missing := rule.Check(field)
+		Expect(forbidden).To(Equal([]string{"org.springframework.beans.factory.annotation.Autowired"}))
+		Expect(missing).To(Equal([]string{"Inject"}))
+	})
+
+	It("should only apply to the nodes it selects", func() {
+		Expect((&models.AnnotationRule{Kind: "field", Symbol: "api.*"}).AppliesTo(tagged)).To(BeTrue())
+		Expect((&models.AnnotationRule{Kind: "field", Symbol: "api.*"}).AppliesTo(field)).To(BeFalse())
+		Expect((&models.AnnotationRule{Kind: "method"}).AppliesTo(tagged)).To(BeFalse())
+		Expect((&models.AnnotationRule{Exported: true}).AppliesTo(&models.ASTNode{NodeType: models.NodeTypeField, IsPrivate: true})).To(BeFalse())
+	})
+
+	It("should require forbidden or required annotations", func() {
+		Expect((&models.AnnotationRule{Kind: "field"}).Validate()).To(MatchError(ContainSubstring("at least one of")))
+		Expect((&models.AnnotationRule{Kind: "class", Forbid: []string{"Autowired"}}).Validate()).To(MatchError(ContainSubstring("invalid kind")))
+		Expect((&models.AnnotationRule{Kind: "field", Forbid: []string{"Autowired"}}).Validate()).To(Succeed())
+	})
+})
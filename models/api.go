@@ -0,0 +1,225 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// pathNamingPresets are the named conventions of path segments
+var pathNamingPresets = map[string]string{
+	"kebab-case": "^[a-z0-9]+(-[a-z0-9]+)*$",
+	"snake_case": "^[a-z0-9]+(_[a-z0-9]+)*$",
+	"camelCase":  "^[a-z][a-zA-Z0-9]*$",
+}
+
+// irregularPlurals are plural nouns not ending with s
+var irregularPlurals = map[string]bool{
+	"people": true, "children": true, "men": true, "women": true, "data": true, "media": true,
+	"criteria": true, "feet": true, "teeth": true, "mice": true, "geese": true,
+}
+
+// APIConfig enables the conventions of the REST APIs of OpenAPI specs analyzed with arch-unit ast
+// analyze openapi, checked against their method_http_* endpoint nodes
+type APIConfig struct {
+	PathNaming      string              `yaml:"path_naming,omitempty"`      // kebab-case, snake_case, camelCase or a regular expression literal path segments must match
+	PluralResources bool                `yaml:"plural_resources,omitempty"` // Segments followed by a path parameter are plural nouns, e.g. /users/{id}
+	ForbiddenVerbs  []string            `yaml:"forbidden_verbs,omitempty"`  // Words path segments may not contain, e.g. get or create
+	ErrorSchema     string              `yaml:"error_schema,omitempty"`     // Schema every 4xx, 5xx and default response references
+	HandlerPrefixes map[string][]string `yaml:"handler_prefixes,omitempty"` // Prefixes of the operation IDs of each HTTP method, e.g. GET: [get, list]
+	Severity        Severity            `yaml:"severity,omitempty"`         // Severity of violations, error when empty
+
+	regexp *regexp.Regexp
+}
+
+// APIIssue is a convention an endpoint does not follow
+type APIIssue struct {
+	Rule    string
+	Message string
+}
+
+// Validate checks the path naming, handler prefixes and severity of the API rules
+func (a *APIConfig) Validate() error {
+	if _, err := a.compile(); err != nil {
+		return fmt.Errorf("invalid path naming '%s': %w", a.PathNaming, err)
+	}
+	for method := range a.HandlerPrefixes {
+		switch strings.ToUpper(method) {
+		case "GET", "POST", "PUT", "PATCH", "DELETE":
+		default:
+			return fmt.Errorf("invalid handler prefix method '%s', expected one of GET, POST, PUT, PATCH or DELETE", method)
+		}
+	}
+	if a.Severity != "" {
+		if _, err := ParseSeverity(string(a.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsEnabled returns true if any API rule is configured
+func (a *APIConfig) IsEnabled() bool {
+	return a != nil && (a.PathNaming != "" || a.PluralResources || len(a.ForbiddenVerbs) > 0 ||
+		a.ErrorSchema != "" || len(a.HandlerPrefixes) > 0)
+}
+
+// GetSeverity returns the severity of API violations
+func (a *APIConfig) GetSeverity() Severity {
+	if a.Severity == "" {
+		return SeverityError
+	}
+	return a.Severity
+}
+
+// Check returns the conventions not followed by the endpoint of an HTTP method and path, whose
+// operation ID is empty when the spec does not define one and whose error responses map status
+// codes to the schema they reference
+func (a *APIConfig) Check(method, path, operationID string, errorResponses map[string]string) ([]APIIssue, error) {
+	var issues []APIIssue
+	re, err := a.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "" || isPathParameter(segment) {
+			continue
+		}
+		if re != nil && !re.MatchString(segment) {
+			issues = append(issues, APIIssue{Rule: "api path naming", Message: fmt.Sprintf("path segment %s does not match %s", segment, a.PathNaming)})
+		}
+		if a.PluralResources && i+1 < len(segments) && isPathParameter(segments[i+1]) {
+			words := pathWords(segment)
+			if noun := words[len(words)-1]; !isPlural(noun) {
+				issues = append(issues, APIIssue{Rule: "api plural resources", Message: fmt.Sprintf("resource %s is not a plural noun", segment)})
+			}
+		}
+		for _, word := range pathWords(segment) {
+			if containsFold(a.ForbiddenVerbs, word) {
+				issues = append(issues, APIIssue{Rule: "api forbidden verbs", Message: fmt.Sprintf("path segment %s contains the verb %s", segment, word)})
+				break
+			}
+		}
+	}
+
+	if a.ErrorSchema != "" {
+		if len(errorResponses) == 0 {
+			issues = append(issues, APIIssue{Rule: "api error schema", Message: fmt.Sprintf("no error response uses schema %s", a.ErrorSchema)})
+		}
+		statuses := make([]string, 0, len(errorResponses))
+		for status := range errorResponses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			if errorResponses[status] != a.ErrorSchema {
+				issues = append(issues, APIIssue{Rule: "api error schema", Message: fmt.Sprintf("%s response does not use schema %s", status, a.ErrorSchema)})
+			}
+		}
+	}
+
+	if prefixes := a.handlerPrefixes(method); operationID != "" && len(prefixes) > 0 {
+		matches := false
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(strings.ToLower(operationID), strings.ToLower(prefix)) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			issues = append(issues, APIIssue{Rule: "api handler naming", Message: fmt.Sprintf("handler %s of %s does not start with %s", operationID, strings.ToUpper(method), strings.Join(prefixes, ", "))})
+		}
+	}
+	return issues, nil
+}
+
+// handlerPrefixes returns the operation ID prefixes of an HTTP method, whatever the case of the
+// configured method
+func (a *APIConfig) handlerPrefixes(method string) []string {
+	for m, prefixes := range a.HandlerPrefixes {
+		if strings.EqualFold(m, method) {
+			return prefixes
+		}
+	}
+	return nil
+}
+
+// compile returns the compiled path naming convention, nil without one
+func (a *APIConfig) compile() (*regexp.Regexp, error) {
+	if a.regexp != nil || a.PathNaming == "" {
+		return a.regexp, nil
+	}
+	pattern := a.PathNaming
+	if preset, ok := pathNamingPresets[pattern]; ok {
+		pattern = preset
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	a.regexp = re
+	return re, nil
+}
+
+// ParseErrorResponses parses the errors metadata of endpoint nodes, e.g. 404=Error,default=Error
+func ParseErrorResponses(errors string) map[string]string {
+	responses := make(map[string]string)
+	for _, response := range strings.Split(errors, ",") {
+		if status, schema, ok := strings.Cut(response, "="); ok {
+			responses[status] = schema
+		}
+	}
+	return responses
+}
+
+// isPathParameter returns true for path parameters such as {id}
+func isPathParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// pathWords splits a path segment into its lower case words, e.g. getUserOrders and
+// get-user-orders into get, user and orders
+func pathWords(segment string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, strings.ToLower(word.String()))
+			word.Reset()
+		}
+	}
+	for _, r := range segment {
+		switch {
+		case r == '-' || r == '_' || r == '.':
+			flush()
+		case unicode.IsUpper(r):
+			flush()
+			word.WriteRune(r)
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	if len(words) == 0 {
+		return []string{segment}
+	}
+	return words
+}
+
+// isPlural returns true for nouns ending with s and irregular plurals
+func isPlural(noun string) bool {
+	return irregularPlurals[noun] || (strings.HasSuffix(noun, "s") && !strings.HasSuffix(noun, "ss"))
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
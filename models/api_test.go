@@ -0,0 +1,52 @@
+package models
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APIConfig", func() {
+	messages := func(api *APIConfig, method, path, operationID string, errors map[string]string) []string {
+		issues, err := api.Check(method, path, operationID, errors)
+		Expect(err).NotTo(HaveOccurred())
+		var messages []string
+		for _, issue := range issues {
+			messages = append(messages, issue.Rule+": "+issue.Message)
+		}
+		return messages
+	}
+
+	It("should reject invalid path naming and handler methods", func() {
+		Expect((&APIConfig{PathNaming: "kebab-case"}).Validate()).To(Succeed())
+		Expect((&APIConfig{PathNaming: "[a-z"}).Validate()).To(MatchError(ContainSubstring("invalid path naming")))
+		Expect((&APIConfig{HandlerPrefixes: map[string][]string{"FETCH": {"get"}}}).Validate()).To(MatchError(ContainSubstring("invalid handler prefix method")))
+	})
+
+	It("should check path segments but not path parameters", func() {
+		api := &APIConfig{PathNaming: "kebab-case", PluralResources: true, ForbiddenVerbs: []string{"get", "create"}}
+
+		Expect(messages(api, "GET", "/order-items/{orderItemId}", "", nil)).To(BeEmpty())
+		Expect(messages(api, "GET", "/user/{id}/getOrders", "", nil)).To(Equal([]string{
+			"api plural resources: resource user is not a plural noun",
+			"api path naming: path segment getOrders does not match kebab-case",
+			"api forbidden verbs: path segment getOrders contains the verb get",
+		}))
+		Expect(messages(api, "GET", "/people/{id}", "", nil)).To(BeEmpty())
+	})
+
+	It("should require error responses to use the error schema", func() {
+		api := &APIConfig{ErrorSchema: "Error"}
+
+		Expect(messages(api, "GET", "/users", "", map[string]string{"404": "Error", "default": "Error"})).To(BeEmpty())
+		Expect(messages(api, "GET", "/users", "", nil)).To(Equal([]string{"api error schema: no error response uses schema Error"}))
+		Expect(messages(api, "GET", "/users", "", ParseErrorResponses("404=Error,500="))).To(Equal([]string{"api error schema: 500 response does not use schema Error"}))
+	})
+
+	It("should require handlers to be named after their method", func() {
+		api := &APIConfig{HandlerPrefixes: map[string][]string{"get": {"get", "list"}, "DELETE": {"delete"}}}
+
+		Expect(messages(api, "GET", "/users", "listUsers", nil)).To(BeEmpty())
+		Expect(messages(api, "GET", "/users", "", nil)).To(BeEmpty())
+		Expect(messages(api, "DELETE", "/users/{id}", "removeUser", nil)).To(Equal([]string{"api handler naming: handler removeUser of DELETE does not start with delete"}))
+	})
+})
@@ -2,7 +2,9 @@ package models
 
 import (
 	"fmt"
+	"math"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -14,6 +16,7 @@ import (
 // AQLRule represents a complete AQL rule
 type AQLRule struct {
 	Name       string          `json:"name" yaml:"name"`
+	Severity   Severity        `json:"severity,omitempty" yaml:"severity,omitempty"` // Severity of the violations, error when empty
 	Statements []*AQLStatement `json:"statements" yaml:"statements"`
 	SourceFile string          `json:"source_file,omitempty" yaml:"source_file,omitempty"`
 	LineNumber int             `json:"line_number,omitempty" yaml:"line_number,omitempty"`
@@ -26,6 +29,10 @@ type AQLStatement struct {
 	Pattern     *AQLPattern      `json:"pattern,omitempty" yaml:"pattern,omitempty"`           // For single pattern statements
 	FromPattern *AQLPattern      `json:"from_pattern,omitempty" yaml:"from_pattern,omitempty"` // For relationship statements
 	ToPattern   *AQLPattern      `json:"to_pattern,omitempty" yaml:"to_pattern,omitempty"`     // For relationship statements
+	Level       AQLCycleLevel    `json:"level,omitempty" yaml:"level,omitempty"`               // For CYCLE statements
+	Transitive  bool             `json:"transitive,omitempty" yaml:"transitive,omitempty"`     // For FORBID(A ->> B), matching indirect paths
+	MaxHops     int              `json:"max_hops,omitempty" yaml:"max_hops,omitempty"`         // For transitive statements, DefaultMaxHops when 0
+	Expression  *AQLExpression   `json:"expression,omitempty" yaml:"expression,omitempty"`     // For LIMIT and FORBID statements composing conditions or patterns
 }
 
 // AQLStatementType represents the type of AQL statement
@@ -36,14 +43,301 @@ const (
 	AQLStatementForbid  AQLStatementType = "FORBID"
 	AQLStatementRequire AQLStatementType = "REQUIRE"
 	AQLStatementAllow   AQLStatementType = "ALLOW"
+	AQLStatementCycle   AQLStatementType = "CYCLE"
+)
+
+// DefaultMaxHops is the length of the longest path followed by transitive statements without
+// a number of hops
+const DefaultMaxHops = 5
+
+// AQLCycleLevel is the granularity at which a CYCLE statement detects cycles
+type AQLCycleLevel string
+
+const (
+	AQLCycleLevelPackage AQLCycleLevel = "package"
+	AQLCycleLevelType    AQLCycleLevel = "type"
 )
 
 // AQLCondition represents a conditional expression in AQL
 type AQLCondition struct {
-	Pattern  *AQLPattern     `json:"pattern" yaml:"pattern"`
-	Property string          `json:"property,omitempty" yaml:"property,omitempty"` // Backward compatibility
-	Operator AQLOperatorType `json:"operator" yaml:"operator"`
-	Value    interface{}     `json:"value" yaml:"value"` // Can hold raw values for backward compatibility
+	Pattern   *AQLPattern     `json:"pattern" yaml:"pattern"`
+	Property  string          `json:"property,omitempty" yaml:"property,omitempty"`   // Backward compatibility
+	Aggregate *AQLAggregate   `json:"aggregate,omitempty" yaml:"aggregate,omitempty"` // Rolls the matching nodes up per package or type
+	Operator  AQLOperatorType `json:"operator" yaml:"operator"`
+	Value     interface{}     `json:"value" yaml:"value"` // Can hold raw values for backward compatibility
+}
+
+// AQLAggregateFunction is the function rolling up the nodes of an aggregate condition
+type AQLAggregateFunction string
+
+const (
+	AQLAggregateCount AQLAggregateFunction = "COUNT"
+	AQLAggregateSum   AQLAggregateFunction = "SUM"
+	AQLAggregateAvg   AQLAggregateFunction = "AVG"
+	AQLAggregateMin   AQLAggregateFunction = "MIN"
+	AQLAggregateMax   AQLAggregateFunction = "MAX"
+)
+
+// ParseAggregateFunction returns the aggregate function of a name, case insensitive
+func ParseAggregateFunction(name string) (AQLAggregateFunction, bool) {
+	function := AQLAggregateFunction(strings.ToUpper(name))
+	switch function {
+	case AQLAggregateCount, AQLAggregateSum, AQLAggregateAvg, AQLAggregateMin, AQLAggregateMax:
+		return function, true
+	}
+	return "", false
+}
+
+// Aggregate selectors restricting the nodes rolled up to a kind, e.g. COUNT(api.methods)
+var aggregateSelectors = map[string]NodeType{
+	"methods": NodeTypeMethod,
+	"types":   NodeTypeType,
+	"fields":  NodeTypeField,
+}
+
+// AQLAggregate rolls the nodes matching the pattern of a condition up per package or type, e.g.
+// COUNT(api.methods) > 200 or AVG(service.*.cyclomatic, type) > 7
+type AQLAggregate struct {
+	Function AQLAggregateFunction `json:"function" yaml:"function"`
+	Selector string               `json:"selector,omitempty" yaml:"selector,omitempty"` // methods, types or fields, the nodes rolled up
+	Level    AQLAggregateLevel    `json:"level,omitempty" yaml:"level,omitempty"`       // package or type, the scope nodes are rolled up to
+}
+
+// AQLAggregateLevel is the scope the nodes of an aggregate condition are rolled up to
+type AQLAggregateLevel string
+
+const (
+	AQLAggregateLevelPackage AQLAggregateLevel = "package"
+	AQLAggregateLevelType    AQLAggregateLevel = "type"
+)
+
+// CutAggregateSelector splits the selector of the nodes rolled up from a pattern, e.g. api and
+// methods for api.methods
+func CutAggregateSelector(pattern string) (string, string) {
+	if _, ok := aggregateSelectors[pattern]; ok {
+		return "*", pattern
+	}
+	if dot := strings.LastIndex(pattern, "."); dot >= 0 {
+		if _, ok := aggregateSelectors[pattern[dot+1:]]; ok {
+			return pattern[:dot], pattern[dot+1:]
+		}
+	}
+	return pattern, ""
+}
+
+// Validate checks the function, selector and level of the aggregate, and that functions other than
+// COUNT aggregate a metric
+func (a *AQLAggregate) Validate(metric string) error {
+	if _, ok := ParseAggregateFunction(string(a.Function)); !ok {
+		return fmt.Errorf("invalid aggregate function '%s', expected COUNT, SUM, AVG, MIN or MAX", a.Function)
+	}
+	if _, ok := aggregateSelectors[a.Selector]; a.Selector != "" && !ok {
+		return fmt.Errorf("invalid aggregate selector '%s', expected methods, types or fields", a.Selector)
+	}
+	switch a.Level {
+	case "", AQLAggregateLevelPackage, AQLAggregateLevelType:
+	default:
+		return fmt.Errorf("invalid aggregate level '%s', expected package or type", a.Level)
+	}
+	if metric == "" && a.Function != AQLAggregateCount {
+		return fmt.Errorf("%s requires a metric, e.g. %s(*.cyclomatic)", a.Function, a.Function)
+	}
+	if metric != "" && a.Function == AQLAggregateCount {
+		return fmt.Errorf("COUNT counts nodes and does not take a metric")
+	}
+	if IsPackageMetric(metric) {
+		return fmt.Errorf("%s is computed per package and cannot be aggregated", metric)
+	}
+	return nil
+}
+
+// Includes returns true if a node is rolled up by the aggregate. Metrics are measured on
+// functions and methods, so metric aggregates without a selector roll up methods.
+func (a *AQLAggregate) Includes(node *ASTNode, metric string) bool {
+	nodeType, ok := aggregateSelectors[a.Selector]
+	if !ok {
+		if metric == "" {
+			return true
+		}
+		nodeType = NodeTypeMethod
+	}
+	if metric == MetricCoverage && node.Coverage == nil {
+		return false
+	}
+	return strings.HasPrefix(node.NodeType, nodeType)
+}
+
+// ScopeOf returns the package or type a node is rolled up to, false when the node is not part of
+// a type rolled up per type
+func (a *AQLAggregate) ScopeOf(node *ASTNode, pattern *AQLPattern) (string, bool) {
+	if a.Scope(pattern) == AQLAggregateLevelType {
+		if node.TypeName == "" {
+			return "", false
+		}
+		return node.PackageName + "." + node.TypeName, true
+	}
+	return node.PackageName, true
+}
+
+// Scope returns the level of the aggregate, otherwise type when the pattern names a type, e.g.
+// COUNT(*.*Service.methods), and package when it does not
+func (a *AQLAggregate) Scope(pattern *AQLPattern) AQLAggregateLevel {
+	if a.Level != "" {
+		return a.Level
+	}
+	if pattern != nil && pattern.Type != "" && pattern.Type != "*" {
+		return AQLAggregateLevelType
+	}
+	return AQLAggregateLevelPackage
+}
+
+// ScopeNode returns the package or type node a node is rolled up to, reported as the called node
+// of the violations of the aggregate
+func (a *AQLAggregate) ScopeNode(node *ASTNode, pattern *AQLPattern) *ASTNode {
+	scope := &ASTNode{FilePath: node.FilePath, PackageName: node.PackageName, StartLine: node.StartLine, NodeType: NodeTypePackage}
+	if a.Scope(pattern) == AQLAggregateLevelType {
+		scope.TypeName = node.TypeName
+		scope.NodeType = NodeTypeType
+	}
+	return scope
+}
+
+// Compute returns the aggregate of the metric values of the nodes of a scope
+func (a *AQLAggregate) Compute(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum, lowest, highest := 0.0, values[0], values[0]
+	for _, value := range values {
+		sum += value
+		lowest = min(lowest, value)
+		highest = max(highest, value)
+	}
+	switch a.Function {
+	case AQLAggregateCount:
+		return float64(len(values))
+	case AQLAggregateSum:
+		return sum
+	case AQLAggregateAvg:
+		return round2(sum / float64(len(values)))
+	case AQLAggregateMin:
+		return lowest
+	}
+	return highest
+}
+
+// AQLLogicalOperator combines the operands of an expression
+type AQLLogicalOperator string
+
+const (
+	AQLLogicalAnd AQLLogicalOperator = "AND"
+	AQLLogicalOr  AQLLogicalOperator = "OR"
+	AQLLogicalNot AQLLogicalOperator = "NOT"
+)
+
+// AQLExpression composes the conditions of a LIMIT statement or the patterns of a FORBID
+// statement with AND, OR and NOT, e.g. LIMIT(*.cyclomatic > 10 AND NOT *Test*.lines > 0). Leaves
+// hold a condition or a pattern and no operator.
+type AQLExpression struct {
+	Operator  AQLLogicalOperator `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Operands  []*AQLExpression   `json:"operands,omitempty" yaml:"operands,omitempty"`
+	Condition *AQLCondition      `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Pattern   *AQLPattern        `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+}
+
+// Evaluate returns true if a node satisfies the expression, a condition holding for the node or
+// a pattern matching it
+func (x *AQLExpression) Evaluate(node *ASTNode) (bool, error) {
+	switch x.Operator {
+	case AQLLogicalNot:
+		if len(x.Operands) != 1 {
+			return false, fmt.Errorf("NOT takes a single operand")
+		}
+		result, err := x.Operands[0].Evaluate(node)
+		return !result, err
+	case AQLLogicalAnd, AQLLogicalOr:
+		for _, operand := range x.Operands {
+			result, err := operand.Evaluate(node)
+			if err != nil {
+				return false, err
+			}
+			// AND stops at the first false operand, OR at the first true one
+			if result == (x.Operator == AQLLogicalOr) {
+				return result, nil
+			}
+		}
+		return x.Operator == AQLLogicalAnd, nil
+	case "":
+		if x.Condition != nil {
+			return x.Condition.Evaluate(node)
+		}
+		if x.Pattern != nil {
+			return x.Pattern.Matches(node), nil
+		}
+		return false, fmt.Errorf("expression requires a condition or a pattern")
+	default:
+		return false, fmt.Errorf("unknown logical operator: %s", x.Operator)
+	}
+}
+
+// Validate checks the operands of the expression. Conditions on aggregates and package metrics
+// apply to packages and types rather than to nodes, so they cannot be composed.
+func (x *AQLExpression) Validate() error {
+	switch x.Operator {
+	case AQLLogicalNot:
+		if len(x.Operands) != 1 {
+			return fmt.Errorf("NOT takes a single operand")
+		}
+	case AQLLogicalAnd, AQLLogicalOr:
+		if len(x.Operands) < 2 {
+			return fmt.Errorf("%s takes at least two operands", x.Operator)
+		}
+	case "":
+		if x.Condition == nil && x.Pattern == nil {
+			return fmt.Errorf("expression requires a condition or a pattern")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown logical operator: %s", x.Operator)
+	}
+	for _, operand := range x.Operands {
+		if c := operand.Condition; c != nil && (c.Aggregate != nil || IsPackageMetric(c.Pattern.Metric) || IsPackageMetric(c.Property)) {
+			return fmt.Errorf("conditions on aggregates and package metrics cannot be combined with AND, OR or NOT: %s", c)
+		}
+		if err := operand.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the expression, parenthesizing the operands that combine others
+func (x *AQLExpression) String() string {
+	switch x.Operator {
+	case "":
+		if x.Condition != nil {
+			return x.Condition.String()
+		}
+		return x.Pattern.String()
+	case AQLLogicalNot:
+		if len(x.Operands) == 1 {
+			return "NOT " + x.Operands[0].operandString()
+		}
+	}
+	parts := make([]string, 0, len(x.Operands))
+	for _, operand := range x.Operands {
+		parts = append(parts, operand.operandString())
+	}
+	return strings.Join(parts, " "+string(x.Operator)+" ")
+}
+
+// operandString returns the expression as the operand of another, parenthesized unless it is a
+// leaf or a negation
+func (x *AQLExpression) operandString() string {
+	if x.Operator == "" || x.Operator == AQLLogicalNot {
+		return x.String()
+	}
+	return "(" + x.String() + ")"
 }
 
 // AQLOperatorType represents comparison operators
@@ -106,6 +400,9 @@ func (r *AQLRule) String() string {
 	for _, stmt := range r.Statements {
 		parts = append(parts, stmt.String())
 	}
+	if r.Severity != "" {
+		return fmt.Sprintf("RULE %q SEVERITY %s {\n  %s\n}", r.Name, r.Severity, strings.Join(parts, ",\n  "))
+	}
 	return fmt.Sprintf("RULE %q {\n  %s\n}", r.Name, strings.Join(parts, ",\n  "))
 }
 
@@ -113,11 +410,21 @@ func (r *AQLRule) String() string {
 func (s *AQLStatement) String() string {
 	switch s.Type {
 	case AQLStatementLimit:
+		if s.Expression != nil {
+			return fmt.Sprintf("LIMIT(%s)", s.Expression.String())
+		}
 		if s.Condition != nil {
 			return fmt.Sprintf("LIMIT(%s)", s.Condition.String())
 		}
 	case AQLStatementForbid:
-		if s.FromPattern != nil && s.ToPattern != nil {
+		if s.Expression != nil {
+			return fmt.Sprintf("FORBID(%s)", s.Expression.String())
+		}
+		if s.FromPattern != nil && s.ToPattern != nil && s.Transitive && s.MaxHops > 0 {
+			return fmt.Sprintf("FORBID(%s ->> %s, %d)", s.FromPattern.String(), s.ToPattern.String(), s.MaxHops)
+		} else if s.FromPattern != nil && s.ToPattern != nil && s.Transitive {
+			return fmt.Sprintf("FORBID(%s ->> %s)", s.FromPattern.String(), s.ToPattern.String())
+		} else if s.FromPattern != nil && s.ToPattern != nil {
 			return fmt.Sprintf("FORBID(%s -> %s)", s.FromPattern.String(), s.ToPattern.String())
 		} else if s.Pattern != nil {
 			return fmt.Sprintf("FORBID(%s)", s.Pattern.String())
@@ -134,14 +441,63 @@ func (s *AQLStatement) String() string {
 		} else if s.Pattern != nil {
 			return fmt.Sprintf("ALLOW(%s)", s.Pattern.String())
 		}
+	case AQLStatementCycle:
+		if s.Pattern != nil && s.Level != "" {
+			return fmt.Sprintf("CYCLE(%s, %s)", s.Pattern.String(), s.Level)
+		} else if s.Pattern != nil {
+			return fmt.Sprintf("CYCLE(%s)", s.Pattern.String())
+		}
 	}
 	return string(s.Type)
 }
 
+// CycleLevel returns the granularity of a CYCLE statement, its level or otherwise type when the
+// pattern names a type, e.g. *.*Service, and package when it does not
+func (s *AQLStatement) CycleLevel() AQLCycleLevel {
+	if s.Level != "" {
+		return s.Level
+	}
+	if s.Pattern != nil && s.Pattern.Type != "" && s.Pattern.Type != "*" {
+		return AQLCycleLevelType
+	}
+	return AQLCycleLevelPackage
+}
+
+// Hops returns the length of the longest path followed by a transitive statement
+func (s *AQLStatement) Hops() int {
+	if s.MaxHops > 0 {
+		return s.MaxHops
+	}
+	return DefaultMaxHops
+}
+
 // String returns string representation of AQL condition
 func (c *AQLCondition) String() string {
 	valueStr := fmt.Sprintf("%v", c.Value)
-	return fmt.Sprintf("%s %s %s", c.Pattern.String(), string(c.Operator), valueStr)
+	if c.Aggregate != nil {
+		return fmt.Sprintf("%s %s %s", c.Aggregate.String(c.Pattern, c.Property), string(c.Operator), valueStr)
+	}
+	pattern := c.Pattern.String()
+	if c.Pattern.Metric == "" && c.Property != "" {
+		// Parsed conditions keep their metric as the property
+		pattern += "." + c.Property
+	}
+	return fmt.Sprintf("%s %s %s", pattern, string(c.Operator), valueStr)
+}
+
+// String returns the aggregate of a pattern and metric, e.g. AVG(service.*.cyclomatic, type)
+func (a *AQLAggregate) String(pattern *AQLPattern, metric string) string {
+	expr := pattern.String()
+	if a.Selector != "" {
+		expr += "." + a.Selector
+	}
+	if metric != "" && pattern.Metric == "" {
+		expr += "." + metric
+	}
+	if a.Level != "" {
+		expr += ", " + string(a.Level)
+	}
+	return fmt.Sprintf("%s(%s)", a.Function, expr)
 }
 
 // String returns string representation of AQL pattern
@@ -247,6 +603,9 @@ func (v *AQLValue) String() string {
 	}
 }
 
+// knownLanguages are the languages a pattern may start with, e.g. sql.users or sql/*
+var knownLanguages = []string{"sql", "go", "python", "javascript", "typescript", "openapi", "java", "rust", "custom"}
+
 // ParsePattern parses a pattern string into an AQLPattern
 func ParsePattern(pattern string) (*AQLPattern, error) {
 	p := &AQLPattern{
@@ -289,12 +648,18 @@ func ParsePattern(pattern string) (*AQLPattern, error) {
 	if dotIndex := strings.LastIndex(pattern, "."); dotIndex != -1 {
 		possibleMetric := pattern[dotIndex+1:]
 		if possibleMetric == "cyclomatic" || possibleMetric == "parameters" || possibleMetric == "params" ||
-			possibleMetric == "returns" || possibleMetric == "lines" {
+			possibleMetric == "returns" || possibleMetric == "lines" || possibleMetric == MetricCoverage || IsPackageMetric(possibleMetric) {
 			p.Metric = possibleMetric
 			pattern = pattern[:dotIndex]
 		}
 	}
 
+	// Language prefixed patterns, e.g. "sql/*" for every SQL node or "sql/public.users"
+	if lang, rest, ok := strings.Cut(pattern, "/"); ok && slices.Contains(knownLanguages, strings.ToLower(lang)) {
+		p.Language = strings.ToLower(lang)
+		pattern = rest
+	}
+
 	// Handle different pattern formats:
 	// - "*" -> wildcard for all
 	// - "pkg.*" -> package + wildcard type
@@ -342,7 +707,6 @@ func ParsePattern(pattern string) (*AQLPattern, error) {
 	}
 
 	// Smart language detection: check if first part is a known language
-	knownLanguages := []string{"sql", "go", "python", "javascript", "typescript", "openapi", "java", "rust", "custom"}
 	if len(parts) > 0 {
 		firstPart := strings.ToLower(parts[0])
 		for _, lang := range knownLanguages {
@@ -478,6 +842,10 @@ func matchesFilePath(filePath, pattern string) bool {
 	return false
 }
 
+// MetricCoverage is the percentage of the statements of a node covered by tests, attached to nodes
+// from the coverage reports of the configuration
+const MetricCoverage = "coverage"
+
 // GetMetricValue extracts the metric value from an AST node
 func (p *AQLPattern) GetMetricValue(node *ASTNode) (int, error) {
 	switch p.Metric {
@@ -497,6 +865,11 @@ func (p *AQLPattern) GetMetricValue(node *ASTNode) (int, error) {
 		return len(node.ReturnValues), nil
 	case "lines":
 		return node.LineCount, nil
+	case MetricCoverage:
+		if node.Coverage == nil {
+			return 0, fmt.Errorf("%s has no coverage", node.GetFullName())
+		}
+		return int(math.Round(*node.Coverage)), nil
 	default:
 		return 0, fmt.Errorf("unknown metric: %s", p.Metric)
 	}
@@ -564,7 +937,7 @@ func inferLanguageFromPath(filePath string) string {
 	switch {
 	case strings.HasSuffix(filePath, ".go"):
 		return "go"
-	case strings.HasSuffix(filePath, ".py"):
+	case strings.HasSuffix(filePath, ".py") || strings.HasSuffix(filePath, ".ipynb"):
 		return "python"
 	case strings.HasSuffix(filePath, ".js") || strings.HasSuffix(filePath, ".jsx") ||
 		 strings.HasSuffix(filePath, ".mjs") || strings.HasSuffix(filePath, ".cjs"):
@@ -598,6 +971,14 @@ func (c *AQLCondition) Evaluate(node *ASTNode) (bool, error) {
 		return false, fmt.Errorf("condition requires a metric")
 	}
 
+	// Nodes of files without a coverage report are not checked, and percentages are not truncated
+	if metric == MetricCoverage {
+		if node.Coverage == nil {
+			return false, nil
+		}
+		return c.Compare(*node.Coverage)
+	}
+
 	// Create a temporary pattern with the metric for GetMetricValue
 	tempPattern := &AQLPattern{
 		Package:    c.Pattern.Package,
@@ -645,6 +1026,39 @@ func (c *AQLCondition) Evaluate(node *ASTNode) (bool, error) {
 	}
 }
 
+// Compare compares a metric value with the value of the condition without truncating either, as
+// the coupling metrics of packages are ratios
+func (c *AQLCondition) Compare(value float64) (bool, error) {
+	var compareValue float64
+	switch v := c.Value.(type) {
+	case int:
+		compareValue = float64(v)
+	case float64:
+		compareValue = v
+	case *AQLValue:
+		compareValue = float64(v.IntValue)
+	default:
+		return false, fmt.Errorf("value must be numeric for comparison")
+	}
+
+	switch c.Operator {
+	case AQLOperatorGT:
+		return value > compareValue, nil
+	case AQLOperatorLT:
+		return value < compareValue, nil
+	case AQLOperatorGTE:
+		return value >= compareValue, nil
+	case AQLOperatorLTE:
+		return value <= compareValue, nil
+	case AQLOperatorEQ:
+		return value == compareValue, nil
+	case AQLOperatorNE:
+		return value != compareValue, nil
+	default:
+		return false, fmt.Errorf("unknown operator: %s", c.Operator)
+	}
+}
+
 // AQLRuleSet represents a collection of AQL rules
 type AQLRuleSet struct {
 	Rules      []*AQLRule `json:"rules" yaml:"rules"`
@@ -0,0 +1,28 @@
+package models
+
+// ArchitectureReport is the structure of a codebase drawn by the HTML report: its packages with
+// their size and complexity, and the dependencies between them
+type ArchitectureReport struct {
+	Packages     []*PackageSummary `json:"packages"`
+	Dependencies []*DependencyEdge `json:"dependencies"`
+}
+
+// PackageSummary holds the size and complexity of a package
+type PackageSummary struct {
+	Package       string  `json:"package"`
+	Files         int     `json:"files"`
+	Types         int     `json:"types"`
+	Methods       int     `json:"methods"`
+	Lines         int     `json:"lines"`
+	AvgComplexity float64 `json:"avg_complexity"`
+	MaxComplexity int     `json:"max_complexity"`
+}
+
+// DependencyEdge is a dependency of a package or type on another, Count is the number of
+// relationships between their nodes and Type their relationship type when graphs draw them apart
+type DependencyEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+	Type  string `json:"type,omitempty"`
+}
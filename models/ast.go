@@ -234,9 +234,9 @@ type ASTNode struct {
 	ParentID             *int64        `json:"parent_id,omitempty" gorm:"column:parent_id;index" pretty:"hide"`         // Nullable for root nodes, For a field, parent is the struct/class, for a struct/class parent is package,
 	DependencyID         *int64        `json:"dependency_id,omitempty" gorm:"column:dependency_id;index" pretty:"hide"` // Id of the dependency that contains this node
 	FilePath             string        `json:"file_path,omitempty" gorm:"column:file_path;not null;index" pretty:"label=File,style=text-blue-500"`
-	PackageName          string        `json:"package_name,omitempty" gorm:"column:package_name;index" pretty:"label=Package"`
-	TypeName             string        `json:"type_name,omitempty" gorm:"column:type_name;index" pretty:"label=Type,style=text-green-600"`
-	MethodName           string        `json:"method_name,omitempty" gorm:"column:method_name;index" pretty:"label=Method,style=text-purple-600"`
+	PackageName          string        `json:"package_name,omitempty" gorm:"column:package_name;index;index:idx_ast_nodes_name,priority:1" pretty:"label=Package"`
+	TypeName             string        `json:"type_name,omitempty" gorm:"column:type_name;index;index:idx_ast_nodes_name,priority:2" pretty:"label=Type,style=text-green-600"`
+	MethodName           string        `json:"method_name,omitempty" gorm:"column:method_name;index;index:idx_ast_nodes_name,priority:3" pretty:"label=Method,style=text-purple-600"`
 	FieldName            string        `json:"field_name,omitempty" gorm:"column:field_name" pretty:"label=Field,style=text-orange-600"`
 	NodeType             NodeType      `json:"node_type,omitempty" gorm:"column:node_type;not null;index" pretty:"label=Type,style=text-gray-600"` // "package", "type", "method", "field", "variable"
 	Language             *string       `json:"language,omitempty" gorm:"column:language;index" pretty:"label=Language"`                            // "go", "python", "sql", "openapi", etc. (optional)
@@ -246,9 +246,10 @@ type ASTNode struct {
 	ParameterCount       int           `json:"parameter_count,omitempty" gorm:"column:parameter_count;default:0" pretty:"label=Params"`
 	ReturnCount          int           `json:"return_count,omitempty" gorm:"column:return_count;default:0" pretty:"label=Returns"`
 	LineCount            int           `json:"line_count,omitempty" gorm:"column:line_count;default:0" pretty:"label=Lines"`
-	Imports              []string      `json:"imports,omitempty" gorm:"-" pretty:"hide"`                     // List of import paths - not stored in DB
-	Parameters           []Parameter   `json:"parameters,omitempty" gorm:"serializer:json" pretty:"hide"`    // Detailed parameter information
-	ReturnValues         []ReturnValue `json:"return_values,omitempty" gorm:"serializer:json" pretty:"hide"` // Return value information
+	Imports              []string      `json:"imports,omitempty" gorm:"-" pretty:"hide"`                       // List of import paths - not stored in DB
+	Parameters           []Parameter   `json:"parameters,omitempty" gorm:"serializer:json" pretty:"hide"`      // Detailed parameter information
+	TypeParameters       []Parameter   `json:"type_parameters,omitempty" gorm:"serializer:json" pretty:"hide"` // Generic type parameters, with the constraint as the type
+	ReturnValues         []ReturnValue `json:"return_values,omitempty" gorm:"serializer:json" pretty:"hide"`   // Return value information
 	LastModified         time.Time     `json:"last_modified,omitempty" gorm:"column:last_modified;index" pretty:"hide"`
 	FileHash             string        `json:"file_hash,omitempty" gorm:"column:file_hash" pretty:"hide"`
 	// Summary is an AI generated/enhanced summary of the node,
@@ -258,10 +259,11 @@ type ASTNode struct {
 	DefaultValue *string           `json:"default_value,omitempty" gorm:"column:default_value" pretty:"label=Default"`               // Default value for fields
 	IsPrivate    bool              `json:"is_private,omitempty" gorm:"column:is_private;default:false;index" pretty:"label=Private"` // Unified visibility across languages
 	Metatdata    map[string]string `json:"metadata,omitempty" gorm:"serializer:json"`                                                // Additional metadata specific to language or analysis
+	Coverage     *float64          `json:"coverage,omitempty" gorm:"column:coverage" pretty:"label=Coverage,omitempty"`              // Percentage of statements covered by tests, nil without a coverage report
 
 	// Hydrated relationships for easy printing
 	Relationships []*ASTRelationship `json:"-" gorm:"-"`
-	Statements    []ASTStatement     `json:"statements,omitempty" gorm:"-"`
+	Statements    []ASTStatement     `json:"statements,omitempty" gorm:"serializer:json"` // Statement tree of function bodies
 }
 
 type FieldType string
@@ -532,10 +534,10 @@ type ASTRelationship struct {
 	ID               int64             `json:"id" gorm:"primaryKey;autoIncrement"`
 	FromAST          *ASTNode          `json:"-"`
 	ToAST            *ASTNode          `json:"-"`
-	FromASTID        int64             `json:"from_ast_id" gorm:"column:from_ast_id;not null;index"`
-	ToASTID          *int64            `json:"to_ast_id,omitempty" gorm:"column:to_ast_id;index"` // Nullable for external calls
-	LineNo           int               `json:"line_no,omitempty" gorm:"column:line_no;index"`
-	RelationshipType RelationshipType  `json:"relationship_type" gorm:"column:relationship_type;not null;index"`
+	FromASTID        int64             `json:"from_ast_id" gorm:"column:from_ast_id;not null;index;index:idx_ast_relationships_from_type,priority:1"`
+	ToASTID          *int64            `json:"to_ast_id,omitempty" gorm:"column:to_ast_id;index;index:idx_ast_relationships_to_type,priority:1"` // Nullable for external calls
+	LineNo           int               `json:"line_no,omitempty" gorm:"column:line_no;index;index:idx_ast_relationships_from_type,priority:3"`
+	RelationshipType RelationshipType  `json:"relationship_type" gorm:"column:relationship_type;not null;index;index:idx_ast_relationships_from_type,priority:2;index:idx_ast_relationships_to_type,priority:2"`
 	Comments         string            `json:"comments,omitempty" gorm:"column:comments"` // Additional comments or context found in the code
 	Text             string            `json:"text" gorm:"column:text"`                   // Text of the relationship, could be the line(s) with the function call, the line in a go.mod or Chart.yaml=
 	Metadata         map[string]string `json:"metadata,omitempty" gorm:"serializer:json"`
@@ -556,6 +558,10 @@ const (
 	RelationshipTypeImplements  RelationshipType = "implements"  // Interface implementation
 	RelationshipTypeIncludes    RelationshipType = "includes"    // e.g. For a chart including a subchart
 	RelationshipTypeForeignKey  RelationshipType = "foreign_key" // Database foreign key constraint
+	RelationshipTypeFileOp      RelationshipType = "file_op"     // File read or embedded, e.g. Go //go:embed patterns
+	RelationshipTypeSQLQuery    RelationshipType = "sql_query"   // Raw SQL query reading or writing a table
+	RelationshipTypeHTTPCall    RelationshipType = "http_call"   // Outbound HTTP request to a statically known URL
+	RelationshipTypeEnvVar      RelationshipType = "env_var"     // Environment variable read or set, e.g. os.Getenv
 )
 
 func (r RelationshipType) Pretty() api.Text {
@@ -572,6 +578,14 @@ func (r RelationshipType) Pretty() api.Text {
 		return clicky.Text("").Add(icons.ArrowRight).Append(" includes", "text-pink-600")
 	case RelationshipTypeForeignKey:
 		return clicky.Text("").Add(icons.ArrowRight).Append(" foreign key", "text-red-600")
+	case RelationshipTypeFileOp:
+		return clicky.Text("").Add(icons.Folder).Append(" file", "text-cyan-600")
+	case RelationshipTypeSQLQuery:
+		return clicky.Text("").Add(icons.DB).Append(" query", "text-orange-600")
+	case RelationshipTypeHTTPCall:
+		return clicky.Text("").Add(icons.Http).Append(" http", "text-teal-600")
+	case RelationshipTypeEnvVar:
+		return clicky.Text("").Add(icons.Variable).Append(" env", "text-lime-600")
 	default:
 		return clicky.Text("").Add(icons.ArrowRight).Append(" reference", "text-yellow-600")
 	}
@@ -694,6 +708,9 @@ const (
 	RelationshipImport      = "import"
 	RelationshipExtends     = "extends"
 	RelationshipForeignKey  = "foreign_key"
+	RelationshipSQLQuery    = "sql_query"
+	RelationshipHTTPCall    = "http_call"
+	RelationshipEnvVar      = "env_var"
 )
 
 // nodeTypeIconCache caches NodeType -> api.Text mappings to avoid repeated lookups
@@ -898,6 +915,7 @@ func (n *ASTNode) ShortClone() *ASTNode {
 		Language:    n.Language,
 		StartLine:   n.StartLine,
 		FieldType:   n.FieldType,
+		Summary:     n.Summary,
 		// Explicitly omit: Parent, Statements, Relationships, and other circular references
 	}
 }
@@ -1049,6 +1067,11 @@ func (n *ASTNode) PrettyWithConfig(config DisplayConfig, parentContext string) a
 		content = content.Append(parentContext, "text-gray-600 text-xs")
 	}
 
+	if n.Summary != nil && *n.Summary != "" {
+		content = content.Append(" — ", "text-gray-400 text-xs")
+		content = content.Append(*n.Summary, "text-gray-500 text-xs italic")
+	}
+
 	return content
 }
 
@@ -1056,6 +1079,10 @@ func (n *ASTNode) AsMap() map[string]interface{} {
 	if n == nil {
 		return nil
 	}
+	language := ""
+	if n.Language != nil {
+		language = *n.Language
+	}
 	return map[string]interface{}{
 		"call_count":            0, // This would need to be calculated from relationships
 		"cyclomatic_complexity": n.CyclomaticComplexity,
@@ -1069,14 +1096,17 @@ func (n *ASTNode) AsMap() map[string]interface{} {
 		"method_name":           n.MethodName,
 		"node_type":             string(n.NodeType),
 		"package_name":          n.PackageName,
-		"parameter_count":       len(n.Parameters),
+		"parameter_count":       max(n.ParameterCount, len(n.Parameters)),
 		"parameters":            n.Parameters,
-		"return_count":          len(n.ReturnValues),
+		"return_count":          max(n.ReturnCount, len(n.ReturnValues)),
 		"return_values":         n.ReturnValues,
 		"start_line":            n.StartLine,
 		"type_name":             n.TypeName,
 		"field_type":            n.FieldType,
 		"default_value":         n.DefaultValue,
+		"is_private":            n.IsPrivate,
+		"language":              language,
+		"name":                  n.String(),
 	}
 }
 
@@ -1256,7 +1286,7 @@ func (e *EnhancedASTNodeTreeNode) GetChildren() []api.TreeNode {
 					ParentContext: e.getContextForChild(astChild),
 				})
 			}
-		} else {
+		} else if _, isStatement := child.(ASTStatement); !isStatement || e.Config.ShowStatements {
 			enhanced = append(enhanced, child)
 		}
 	}
@@ -1283,11 +1313,12 @@ type DisplayConfig struct {
 	ShowPackages bool // Show/hide package nodes (default: true)
 
 	// Content control
-	ShowTypes   bool // Show/hide type definitions (default: true)
-	ShowMethods bool // Show/hide methods (default: true)
-	ShowFields  bool // Show/hide struct fields (default: false)
-	ShowParams  bool // Show/hide method parameters (default: false)
-	ShowImports bool // Show/hide import statements (default: false)
+	ShowTypes      bool // Show/hide type definitions (default: true)
+	ShowMethods    bool // Show/hide methods (default: true)
+	ShowFields     bool // Show/hide struct fields (default: false)
+	ShowParams     bool // Show/hide method parameters (default: false)
+	ShowImports    bool // Show/hide import statements (default: false)
+	ShowStatements bool // Show/hide the statements of function bodies (default: false)
 
 	// Display details
 	ShowLineNo     bool // Show/hide line numbers (default: true)
@@ -1306,6 +1337,7 @@ func DefaultDisplayConfig() DisplayConfig {
 		ShowFields:     false,
 		ShowParams:     false,
 		ShowImports:    false,
+		ShowStatements: false,
 		ShowLineNo:     true,
 		ShowFileStats:  false,
 		ShowComplexity: false,
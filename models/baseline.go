@@ -0,0 +1,204 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultBaselineFile is the baseline written by arch-unit baseline write when no path is given
+const DefaultBaselineFile = ".arch-unit-baseline.json"
+
+// Baseline is a snapshot of known violations, check --baseline only fails on violations that are
+// not part of it. Violations are fingerprinted without their line so that unrelated edits moving
+// them do not make them new.
+type Baseline struct {
+	Version    int                 `json:"version"`
+	Violations []BaselineViolation `json:"violations"`
+}
+
+// BaselineViolation is a fingerprinted violation of a baseline, counted as the same rule can be
+// violated several times by the same symbol
+type BaselineViolation struct {
+	Fingerprint string `json:"fingerprint"`
+	Source      string `json:"source,omitempty"`
+	File        string `json:"file,omitempty"`
+	Rule        string `json:"rule,omitempty"`
+	Symbol      string `json:"symbol,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Count       int    `json:"count"`
+}
+
+// NewBaseline fingerprints violations with their files relative to root
+func NewBaseline(violations []Violation, root string) *Baseline {
+	byFingerprint := make(map[string]*BaselineViolation)
+	for _, v := range violations {
+		entry := newBaselineViolation(v, root)
+		if existing, ok := byFingerprint[entry.Fingerprint]; ok {
+			existing.Count++
+			continue
+		}
+		byFingerprint[entry.Fingerprint] = &entry
+	}
+
+	baseline := &Baseline{Version: 1, Violations: make([]BaselineViolation, 0, len(byFingerprint))}
+	for _, entry := range byFingerprint {
+		baseline.Violations = append(baseline.Violations, *entry)
+	}
+	// Sorted so regenerating a baseline gives a reviewable diff
+	sort.Slice(baseline.Violations, func(i, j int) bool {
+		a, b := baseline.Violations[i], baseline.Violations[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	return baseline
+}
+
+// LoadBaseline reads a baseline written by Save
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// Save writes the baseline as indented JSON
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Total returns the number of violations in the baseline
+func (b *Baseline) Total() int {
+	total := 0
+	for _, v := range b.Violations {
+		total += v.Count
+	}
+	return total
+}
+
+// BaselineMatcher matches violations against a baseline, each baselined violation matching once
+type BaselineMatcher struct {
+	root      string
+	remaining map[string]int
+}
+
+// Matcher returns a matcher of violations with their files relative to root
+func (b *Baseline) Matcher(root string) *BaselineMatcher {
+	remaining := make(map[string]int, len(b.Violations))
+	for _, v := range b.Violations {
+		remaining[v.Fingerprint] += v.Count
+	}
+	return &BaselineMatcher{root: root, remaining: remaining}
+}
+
+// Matches returns true if a violation is part of the baseline, consuming it so that a symbol
+// violating a rule more often than in the baseline is reported
+func (m *BaselineMatcher) Matches(v Violation) bool {
+	fingerprint := newBaselineViolation(v, m.root).Fingerprint
+	if m.remaining[fingerprint] == 0 {
+		return false
+	}
+	m.remaining[fingerprint]--
+	return true
+}
+
+// Fixed returns the number of violations of the baseline that were not matched, those fixed since
+// it was written
+func (m *BaselineMatcher) Fixed() int {
+	fixed := 0
+	for _, count := range m.remaining {
+		fixed += count
+	}
+	return fixed
+}
+
+// ApplyBaseline removes the violations of the baseline from the result and regenerates its summary
+func (cr *ConsolidatedResult) ApplyBaseline(matcher *BaselineMatcher) {
+	violations := make([]Violation, 0, len(cr.Violations))
+	for _, v := range cr.Violations {
+		if !matcher.Matches(v) {
+			violations = append(violations, v)
+		}
+	}
+	baselined := len(cr.Violations) - len(violations)
+	cr.Violations = violations
+	excepted := cr.Summary.Excepted
+	cr.GenerateSummary()
+	cr.Summary.Baselined = baselined
+	cr.Summary.Excepted = excepted
+}
+
+// positions matches the standalone numbers of messages and positions such as file.go:12:5 or #3,
+// as line numbers, counts and lengths change with edits unrelated to the violation. Digits of
+// identifiers such as md5, v2 or int64 are kept.
+var positions = regexp.MustCompile(`:\d+(:\d+)?\b|#\d+\b|\b\d+\b`)
+
+// normalizeMessage strips the numbers and positions of a violation message
+func normalizeMessage(message string) string {
+	return strings.Join(strings.Fields(positions.ReplaceAllString(message, "")), " ")
+}
+
+// Fingerprint identifies a violation across edits that move it, with its file relative to root
+func Fingerprint(v Violation, root string) string {
+	return newBaselineViolation(v, root).Fingerprint
+}
+
+// newBaselineViolation fingerprints a violation by its source, rule, file, caller and called
+// symbols, and its message without numbers
+func newBaselineViolation(v Violation, root string) BaselineViolation {
+	file := v.File
+	if filepath.IsAbs(file) && root != "" {
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	file = filepath.ToSlash(file)
+
+	rule := ""
+	if v.Rule != nil {
+		rule = v.Rule.String()
+	}
+	message := ""
+	if v.Message != nil {
+		message = normalizeMessage(*v.Message)
+	}
+
+	var symbols []string
+	for _, node := range []*ASTNode{v.Caller, v.Called} {
+		if node != nil {
+			symbols = append(symbols, node.String())
+		}
+	}
+	symbol := strings.Join(symbols, " -> ")
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{v.Source, rule, file, symbol, message}, "\x00")))
+	return BaselineViolation{
+		Fingerprint: hex.EncodeToString(sum[:8]),
+		Source:      v.Source,
+		File:        file,
+		Rule:        rule,
+		Symbol:      symbol,
+		Message:     message,
+		Count:       1,
+	}
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Baseline", func() {
+	violation := func(file string, line int, message string) Violation {
+		return Violation{
+			File:    file,
+			Line:    line,
+			Source:  "golangci-lint",
+			Message: &message,
+			Caller:  &ASTNode{PackageName: "service", TypeName: "UserService", MethodName: "Create"},
+		}
+	}
+
+	It("should count violations with the same fingerprint", func() {
+		baseline := NewBaseline([]Violation{
+			violation("/repo/service.go", 10, "unused result"),
+			violation("/repo/service.go", 20, "unused result"),
+			violation("/repo/service.go", 30, "shadowed err"),
+		}, "/repo")
+
+		Expect(baseline.Violations).To(HaveLen(2))
+		Expect(baseline.Total()).To(Equal(3))
+		Expect(baseline.Violations[0].File).To(Equal("service.go"))
+	})
+
+	It("should ignore the line of baselined violations", func() {
+		baseline := NewBaseline([]Violation{violation("/repo/service.go", 10, "unused result")}, "/repo")
+
+		matcher := baseline.Matcher("/repo")
+		Expect(matcher.Matches(violation("/repo/service.go", 42, "unused result"))).To(BeTrue())
+	})
+
+	It("should keep the fingerprint of a violation whose line shifts", func() {
+		shifted := violation("/repo/service.go", 42, "service.go:42:7: function Create is 61 lines long")
+		shifted.Called = &ASTNode{PackageName: "store", TypeName: "DB", MethodName: "Exec"}
+		original := shifted
+		original.Line = 10
+		original.Message = StringPtr("service.go:10:7: function Create is 58 lines long")
+
+		Expect(newBaselineViolation(shifted, "/repo").Fingerprint).
+			To(Equal(newBaselineViolation(original, "/repo").Fingerprint))
+		Expect(newBaselineViolation(shifted, "/repo").Message).
+			To(Equal("service.go: function Create is lines long"))
+	})
+
+	It("should keep the digits of identifiers in fingerprinted messages", func() {
+		hashed := violation("/repo/service.go", 12, "crypto/md5 is deprecated, use v2 of int64 helpers at line 12")
+		other := violation("/repo/service.go", 12, "crypto/md4 is deprecated, use v3 of int32 helpers at line 40")
+
+		Expect(newBaselineViolation(hashed, "/repo").Message).
+			To(Equal("crypto/md5 is deprecated, use v2 of int64 helpers at line"))
+		Expect(newBaselineViolation(hashed, "/repo").Fingerprint).
+			ToNot(Equal(newBaselineViolation(other, "/repo").Fingerprint))
+	})
+
+	It("should distinguish violations of different rules", func() {
+		unused := violation("/repo/service.go", 10, "unused result")
+		unused.Rule = &Rule{Type: RuleTypeDeny, Package: "golangci-lint", Method: "unparam"}
+		errcheck := unused
+		errcheck.Rule = &Rule{Type: RuleTypeDeny, Package: "golangci-lint", Method: "errcheck"}
+
+		Expect(newBaselineViolation(unused, "/repo").Fingerprint).
+			ToNot(Equal(newBaselineViolation(errcheck, "/repo").Fingerprint))
+	})
+
+	It("should report violations beyond the baselined count", func() {
+		baseline := NewBaseline([]Violation{violation("/repo/service.go", 10, "unused result")}, "/repo")
+
+		result := &ConsolidatedResult{Violations: []Violation{
+			violation("/repo/service.go", 10, "unused result"),
+			violation("/repo/service.go", 20, "unused result"),
+			violation("/repo/handler.go", 5, "unused result"),
+		}}
+		result.ApplyBaseline(baseline.Matcher("/repo"))
+
+		Expect(result.Violations).To(HaveLen(2))
+		Expect(result.Summary.Baselined).To(Equal(1))
+		Expect(result.Summary.TotalViolations).To(Equal(2))
+	})
+
+	It("should round trip through a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), DefaultBaselineFile)
+		baseline := NewBaseline([]Violation{violation("/repo/service.go", 10, "unused result")}, "/repo")
+		Expect(baseline.Save(path)).To(Succeed())
+
+		loaded, err := LoadBaseline(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(baseline))
+	})
+})
@@ -13,6 +13,8 @@ type FileMetadata struct {
 	LastModified    time.Time `json:"last_modified" gorm:"column:last_modified;not null;index"`
 	LastAnalyzed    time.Time `json:"last_analyzed" gorm:"column:last_analyzed"`
 	AnalysisVersion string    `json:"analysis_version" gorm:"column:analysis_version"`
+	// Node kinds extracted for this file, empty when all kinds were extracted
+	ExtractionProfile string `json:"extraction_profile,omitempty" gorm:"column:extraction_profile"`
 }
 
 // TableName specifies the table name for FileMetadata
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CacheConfig selects a PostgreSQL database shared by the machines analyzing the same code, instead
+// of the SQLite AST cache of each machine. ARCH_UNIT_CACHE_DSN takes precedence.
+type CacheConfig struct {
+	// DSN of the database, e.g. postgres://arch-unit@db:5432/arch_unit or host=db dbname=arch_unit
+	DSN    string `yaml:"dsn,omitempty"`
+	DSNEnv string `yaml:"dsn_env,omitempty"` // Variable holding the DSN, so passwords are not stored in the file
+	// Remote stores the snapshots of cache push and pull, e.g. s3://bucket/arch-unit
+	Remote string `yaml:"remote,omitempty"`
+	// Dir of the SQLite AST cache, a directory per project under ~/.cache/arch-unit/projects by default
+	Dir string `yaml:"dir,omitempty"`
+}
+
+// Validate checks that a single PostgreSQL DSN and a supported remote are given
+func (c *CacheConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.DSN != "" && c.DSNEnv != "" {
+		return fmt.Errorf("dsn and dsn_env are mutually exclusive")
+	}
+	if c.DSN != "" && !IsPostgresDSN(c.DSN) {
+		return fmt.Errorf("invalid dsn, expected a postgres:// URL or key=value pairs such as host=db dbname=arch_unit")
+	}
+	if scheme, _, found := strings.Cut(c.Remote, "://"); found {
+		switch scheme {
+		case "s3", "gs", "http", "https", "file":
+		default:
+			return fmt.Errorf("invalid remote %s, expected s3://, gs://, http(s)://, file:// or a directory", c.Remote)
+		}
+	}
+	return nil
+}
+
+// ResolveDSN returns the DSN, read from DSNEnv when it is set
+func (c *CacheConfig) ResolveDSN() string {
+	if c == nil {
+		return ""
+	}
+	if c.DSNEnv != "" {
+		return os.Getenv(c.DSNEnv)
+	}
+	return c.DSN
+}
+
+// IsPostgresDSN returns true for postgres:// and postgresql:// URLs and key=value connection strings
+func IsPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") ||
+		strings.Contains(dsn, "=") && !strings.Contains(dsn, "://")
+}
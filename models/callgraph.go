@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/flanksource/clicky/api"
+)
+
+// GraphLevelMethod is the level of call graphs, whose nodes are methods named package.Type.method
+const GraphLevelMethod = "method"
+
+// CallGraphOptions selects the calls walked by a call graph
+type CallGraphOptions struct {
+	Pattern  string   // AQL pattern of the methods to start from, e.g. service.Save*
+	Callers  bool     // Walk the methods calling them instead of those they call
+	MaxDepth int      // Levels of calls walked, every level when 0
+	Packages []string // Globs of the packages walked into, every package when empty
+}
+
+// Validate checks the depth and package globs of the options
+func (o *CallGraphOptions) Validate() error {
+	if o.Pattern == "" {
+		return fmt.Errorf("a pattern is required")
+	}
+	if o.MaxDepth < 0 {
+		return fmt.Errorf("invalid max depth %d", o.MaxDepth)
+	}
+	for _, pattern := range o.Packages {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid package pattern '%s'", pattern)
+		}
+	}
+	return nil
+}
+
+// IncludesPackage returns true if the calls of a package are walked
+func (o *CallGraphOptions) IncludesPackage(pkg string) bool {
+	if len(o.Packages) == 0 {
+		return true
+	}
+	for _, pattern := range o.Packages {
+		if matched, _ := doublestar.Match(pattern, pkg); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// CallTree is a method with the methods it calls, or that call it when walking callers. Line is
+// the line of the call of the parent, and Repeated marks methods whose calls are already shown
+// elsewhere in the tree, e.g. recursive calls.
+type CallTree struct {
+	Node     *ASTNode    `json:"node"`
+	Line     int         `json:"line,omitempty"`
+	Repeated bool        `json:"repeated,omitempty"`
+	Calls    []*CallTree `json:"calls,omitempty"`
+}
+
+func (t *CallTree) Pretty() api.Text {
+	content := getNodeTypeIconStyle(t.Node.NodeType).Append(" ", "").Add(t.Node.FullName())
+	if t.Line > 0 {
+		content = content.Append(fmt.Sprintf(" L%d", t.Line), "text-gray-500 text-xs")
+	}
+	if t.Repeated {
+		content = content.Append(" ↻", "text-gray-400")
+	}
+	return content
+}
+
+func (t *CallTree) GetChildren() []api.TreeNode {
+	children := make([]api.TreeNode, len(t.Calls))
+	for i, call := range t.Calls {
+		children[i] = call
+	}
+	return children
+}
+
+// CallGraphOf flattens call trees into a graph of the calls between their methods, edges going
+// from the caller to the called method whichever way the trees were walked
+func CallGraphOf(trees []*CallTree, callers bool) *DependencyGraph {
+	nodes := make(map[string]bool)
+	edges := make(map[[2]string]int)
+	var walk func(tree *CallTree)
+	walk = func(tree *CallTree) {
+		nodes[tree.Node.String()] = true
+		for _, call := range tree.Calls {
+			edge := [2]string{tree.Node.String(), call.Node.String()}
+			if callers {
+				edge = [2]string{edge[1], edge[0]}
+			}
+			edges[edge]++
+			if !call.Repeated {
+				walk(call)
+			} else {
+				nodes[call.Node.String()] = true
+			}
+		}
+	}
+	for _, tree := range trees {
+		walk(tree)
+	}
+
+	graph := &DependencyGraph{Level: GraphLevelMethod, Nodes: []string{}, Edges: []*DependencyEdge{}}
+	for node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Strings(graph.Nodes)
+	for edge, count := range edges {
+		graph.Edges = append(graph.Edges, &DependencyEdge{From: edge[0], To: edge[1], Count: count})
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return graph
+}
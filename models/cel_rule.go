@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+// CELRule reports every node for which a CEL expression over the fields of the node is true,
+// e.g. node.parameter_count > 5 && !node.is_private
+type CELRule struct {
+	Name       string   `yaml:"name"`
+	Expression string   `yaml:"expression"`         // CEL expression over node, the fields of ASTNode.AsMap
+	Path       string   `yaml:"path,omitempty"`     // Doublestar glob of the files the rule applies to, e.g. pkg/**
+	Reason     string   `yaml:"reason,omitempty"`   // Explanation appended to violations
+	Severity   Severity `yaml:"severity,omitempty"` // Severity of violations, error when empty
+
+	program cel.Program
+}
+
+// Validate checks the name, expression and severity of the rule
+func (r *CELRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Expression == "" {
+		return fmt.Errorf("expression is required")
+	}
+	if _, err := r.compile(); err != nil {
+		return err
+	}
+	if r.Severity != "" {
+		if _, err := ParseSeverity(string(r.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the rule applies to a node and its expression is true for it
+func (r *CELRule) Matches(node *ASTNode) (bool, error) {
+	if r.Path != "" && !matchesFilePath(node.FilePath, r.Path) {
+		return false, nil
+	}
+	program, err := r.compile()
+	if err != nil {
+		return false, fmt.Errorf("CEL rule %s: %w", r.Name, err)
+	}
+	out, _, err := program.Eval(map[string]interface{}{"node": node.AsMap()})
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate CEL rule %s on %s: %w", r.Name, node.String(), err)
+	}
+	matches, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL rule %s returned %T, expected a bool", r.Name, out.Value())
+	}
+	return matches, nil
+}
+
+// compile returns the compiled expression of the rule, compiling it on first use
+func (r *CELRule) compile() (cel.Program, error) {
+	if r.program != nil {
+		return r.program, nil
+	}
+	env, err := cel.NewEnv(
+		cel.Variable("node", cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	ast, issues := env.Compile(r.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression '%s': %w", r.Expression, issues.Err())
+	}
+	if output := ast.OutputType(); !output.IsExactType(cel.BoolType) && !output.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression '%s' returns %s, expected a bool", r.Expression, output)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL program: %w", err)
+	}
+	r.program = program
+	return program, nil
+}
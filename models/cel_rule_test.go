@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("CELRule", func() {
+	process := &models.ASTNode{
+		FilePath:       "/repo/orders/service.go",
+		PackageName:    "orders",
+		TypeName:       "OrderService",
+		MethodName:     "Process",
+		NodeType:       models.NodeTypeMethod,
+		ParameterCount: 6,
+	}
+	validate := &models.ASTNode{
+		FilePath:       "/repo/orders/service.go",
+		PackageName:    "orders",
+		TypeName:       "OrderService",
+		MethodName:     "validate",
+		NodeType:       models.NodeTypeMethod,
+		ParameterCount: 7,
+		IsPrivate:      true,
+	}
+
+	DescribeTable("matching nodes",
+		func(rule models.CELRule, node *models.ASTNode, expected bool) {
+			Expect(rule.Validate()).To(Succeed())
+			Expect(rule.Matches(node)).To(Equal(expected))
+		},
+		Entry("public method with many parameters",
+			models.CELRule{Name: "params", Expression: "node.parameter_count > 5 && node.is_private == false"}, process, true),
+		Entry("private method with many parameters",
+			models.CELRule{Name: "params", Expression: "node.parameter_count > 5 && node.is_private == false"}, validate, false),
+		Entry("string functions",
+			models.CELRule{Name: "names", Expression: `node.name.endsWith("Process")`}, process, true),
+		Entry("outside path",
+			models.CELRule{Name: "params", Expression: "node.parameter_count > 5", Path: "**/tests/**"}, process, false),
+	)
+
+	DescribeTable("validation",
+		func(rule models.CELRule, expectedError string) {
+			Expect(rule.Validate()).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("missing name", models.CELRule{Expression: "true"}, "name is required"),
+		Entry("missing expression", models.CELRule{Name: "x"}, "expression is required"),
+		Entry("invalid expression", models.CELRule{Name: "x", Expression: "node.parameter_count >"}, "invalid expression"),
+		Entry("non bool expression", models.CELRule{Name: "x", Expression: "1 + 2"}, "expected a bool"),
+		Entry("invalid severity", models.CELRule{Name: "x", Expression: "true", Severity: "fatal"}, "invalid severity 'fatal'"),
+	)
+})
@@ -0,0 +1,41 @@
+package models
+
+// LanguageStats summarises the files of a language
+type LanguageStats struct {
+	Language     string `json:"language" pretty:"label=Language,style=text-blue-600"`
+	Files        int    `json:"files" pretty:"label=Files"`
+	Lines        int    `json:"lines" pretty:"label=Lines"`
+	CommentLines int    `json:"comment_lines" pretty:"label=Comments"`
+	// CommentDensity is the percentage of the non-blank lines that are comments
+	CommentDensity float64 `json:"comment_density" pretty:"label=Comment %"`
+	Functions      int     `json:"functions" pretty:"label=Functions"`
+	AvgComplexity  float64 `json:"avg_complexity" pretty:"label=Avg Complexity"`
+	P50Complexity  int     `json:"p50_complexity" pretty:"label=P50"`
+	P90Complexity  int     `json:"p90_complexity" pretty:"label=P90"`
+	MaxComplexity  int     `json:"max_complexity" pretty:"label=Max"`
+}
+
+// TypeSize is the number of members of a type
+type TypeSize struct {
+	Type    string `json:"type" pretty:"label=Type,style=text-green-600"`
+	Methods int    `json:"methods" pretty:"label=Methods"`
+	Fields  int    `json:"fields" pretty:"label=Fields"`
+	Lines   int    `json:"lines" pretty:"label=Lines"`
+	File    string `json:"file" pretty:"label=File"`
+	Line    int    `json:"line" pretty:"label=Line"`
+}
+
+// PackageDependents is the number of packages depending on a package
+type PackageDependents struct {
+	Package    string `json:"package" pretty:"label=Package,style=text-blue-600"`
+	Dependents int    `json:"dependents" pretty:"label=Dependents"`
+	Types      int    `json:"types" pretty:"label=Types"`
+}
+
+// CodebaseStats summarises the languages, largest types and most depended-on packages of a
+// codebase
+type CodebaseStats struct {
+	Languages      []LanguageStats     `json:"languages"`
+	LargestTypes   []TypeSize          `json:"largest_types"`
+	MostDependedOn []PackageDependents `json:"most_depended_on"`
+}
@@ -12,21 +12,53 @@ import (
 
 // Config represents the arch-unit.yaml configuration structure
 type Config struct {
-	Version        string                       `yaml:"version"`
-	GeneratedFrom  string                       `yaml:"generated_from,omitempty"` // Style guide or template used
-	Debounce       string                       `yaml:"debounce,omitempty"`
-	Variables      map[string]interface{}       `yaml:"variables,omitempty"`     // Variable definitions for interpolation
-	BuiltinRules   map[string]BuiltinRuleConfig `yaml:"builtin_rules,omitempty"` // Built-in rule configurations
-	Rules          map[string]RuleConfig        `yaml:"rules"`
-	Linters        map[string]LinterConfig      `yaml:"linters,omitempty"`
-	GlobalExcludes []string                     `yaml:"global_excludes,omitempty"`
-	Languages      map[string]LanguageConfig    `yaml:"languages,omitempty"`
-	AQLRules       []AQLRuleConfig              `yaml:"aql_rules,omitempty"` // AQL architecture rules
+	Version         string                       `yaml:"version"`
+	Extends         []ExtendsConfig              `yaml:"extends,omitempty"`        // Rule packs merged into the configuration
+	GeneratedFrom   string                       `yaml:"generated_from,omitempty"` // Style guide or template used
+	Debounce        string                       `yaml:"debounce,omitempty"`
+	Variables       map[string]interface{}       `yaml:"variables,omitempty"`     // Variable definitions for interpolation
+	BuiltinRules    map[string]BuiltinRuleConfig `yaml:"builtin_rules,omitempty"` // Built-in rule configurations
+	Rules           map[string]RuleConfig        `yaml:"rules"`
+	Linters         map[string]LinterConfig      `yaml:"linters,omitempty"`
+	GlobalExcludes  []string                     `yaml:"global_excludes,omitempty"`
+	Languages       map[string]LanguageConfig    `yaml:"languages,omitempty"`
+	AQLRules        []AQLRuleConfig              `yaml:"aql_rules,omitempty"`       // AQL architecture rules
+	Queries         map[string]NamedQuery        `yaml:"queries,omitempty"`         // Named AQL/pattern queries
+	Reports         map[string]ReportConfig      `yaml:"reports,omitempty"`         // Saved reports composed of named queries
+	Extraction      *ExtractionConfig            `yaml:"extraction,omitempty"`      // Node kinds to extract during AST analysis
+	Limits          *LimitsConfig                `yaml:"limits,omitempty"`          // Resource limits for spawned linters and extractors
+	RuleBudget      string                       `yaml:"rule_budget,omitempty"`     // Evaluation time after which an AQL rule is reported as slow
+	Vulnerabilities *VulnerabilityConfig         `yaml:"vulnerabilities,omitempty"` // Vulnerability scanning of dependencies by the osv linter
+	Images          *ImagePolicyConfig           `yaml:"images,omitempty"`          // Tag, digest and registry policy of Docker images, checked by the images linter
+	Registries      []RegistryConfig             `yaml:"registries,omitempty"`      // Credentials of private package registries and Git hosts
+	Layers          Layers                       `yaml:"layers,omitempty"`          // Layered architecture checked by the aql linter
+	Naming          []NamingRule                 `yaml:"naming,omitempty"`          // Naming conventions checked by the aql linter
+	Annotations     []AnnotationRule             `yaml:"annotations,omitempty"`     // Annotations, decorators and struct tags forbidden or required by the aql linter
+	Templates       map[string]RuleTemplate      `yaml:"templates,omitempty"`       // Parameterized AQL rules instantiated by aql_rules
+	CELRules        []CELRule                    `yaml:"cel_rules,omitempty"`       // CEL expressions over AST nodes checked by the aql linter
+	Policies        []PolicyConfig               `yaml:"policies,omitempty"`        // OPA/Rego policies evaluated against the AST graph by the aql linter
+	Schema          *SchemaConfig                `yaml:"schema,omitempty"`          // Conventions of SQL schemas and raw SQL queries checked by the aql linter
+	API             *APIConfig                   `yaml:"api,omitempty"`             // REST conventions of OpenAPI endpoints checked by the aql linter
+	GodObjects      *GodObjectConfig             `yaml:"god_objects,omitempty"`     // Size of types and packages and fan-out of functions checked by the aql linter
+	DeadCode        *DeadCodeConfig              `yaml:"dead_code,omitempty"`       // Unexported symbols that are never referenced, reported by the aql linter
+	Deprecated      *DeprecatedConfig            `yaml:"deprecated,omitempty"`      // Usages of deprecated symbols reported by the aql linter
+	Hotspots        *HotspotConfig               `yaml:"hotspots,omitempty"`        // Files whose churn times complexity is reported by the aql linter
+	Duplication     *DuplicationConfig           `yaml:"duplication,omitempty"`     // Minimum size of the clones reported by the duplication linter
+	Plugins         []string                     `yaml:"plugins,omitempty"`         // Go plugins registering extractors, linters and rule evaluators
+	Exceptions      []Exception                  `yaml:"exceptions,omitempty"`      // Accepted violations, reported again once they expire
+	Coverage        []string                     `yaml:"coverage,omitempty"`        // Go cover profiles, coverage.py XML and lcov reports providing the coverage metric of AQL
+	AI              *AIConfig                    `yaml:"ai,omitempty"`              // LLM provider and limits of the AI features
+	Cache           *CacheConfig                 `yaml:"cache,omitempty"`           // PostgreSQL database of the AST cache shared by several machines
 }
 
+// DefaultRuleBudget is the evaluation time after which an AQL rule is reported as slow when
+// no rule_budget is configured
+const DefaultRuleBudget = 5 * time.Second
+
 // RuleConfig represents configuration for a specific path pattern
 type RuleConfig struct {
 	Imports  []string                `yaml:"imports,omitempty"`
+	Severity Severity                `yaml:"severity,omitempty"` // Severity of import violations, error when empty
 	Debounce string                  `yaml:"debounce,omitempty"`
 	Linters  map[string]LinterConfig `yaml:"linters,omitempty"`
 	Quality  *QualityConfig          `yaml:"quality,omitempty"`
@@ -40,6 +72,9 @@ type QualityConfig struct {
 	MaxParameterNameLen int                     `yaml:"max_parameter_name_length,omitempty"`
 	DisallowedNames     []DisallowedNamePattern `yaml:"disallowed_names,omitempty"`
 	CommentAnalysis     CommentAnalysisConfig   `yaml:"comment_analysis,omitempty"`
+	NilChecks           *CheckSelection         `yaml:"nil_checks,omitempty"`         // e.g. err-value, map-lookup
+	ContextChecks       *CheckSelection         `yaml:"context_checks,omitempty"`     // e.g. dropped-context, background-context
+	TransactionChecks   *CheckSelection         `yaml:"transaction_checks,omitempty"` // e.g. transaction-layer, http-in-transaction
 }
 
 // DisallowedNamePattern represents a pattern for disallowed names
@@ -57,6 +92,31 @@ type CommentAnalysisConfig struct {
 	CheckVerbosity      bool    `yaml:"check_verbosity"`
 }
 
+// CheckSelection selects the checks a linter such as nilcheck or contextcheck applies to matching paths
+type CheckSelection struct {
+	Disabled bool     `yaml:"disabled,omitempty"`
+	Checks   []string `yaml:"checks,omitempty"` // empty enables all checks
+}
+
+// IsCheckEnabled returns true if the named check applies, all checks apply without configuration
+func (cs *CheckSelection) IsCheckEnabled(check string) bool {
+	if cs == nil {
+		return true
+	}
+	if cs.Disabled {
+		return false
+	}
+	if len(cs.Checks) == 0 {
+		return true
+	}
+	for _, c := range cs.Checks {
+		if c == check {
+			return true
+		}
+	}
+	return false
+}
+
 // LanguageConfig represents configuration for a specific language
 type LanguageConfig struct {
 	Includes []string `yaml:"includes,omitempty"`
@@ -65,17 +125,48 @@ type LanguageConfig struct {
 
 // LinterConfig represents configuration for a specific linter
 type LinterConfig struct {
-	Enabled      bool     `yaml:"enabled"`
-	Debounce     string   `yaml:"debounce,omitempty"`
-	Args         []string `yaml:"args,omitempty"`
-	OutputFormat string   `yaml:"output_format,omitempty"`
+	Enabled      bool            `yaml:"enabled"`
+	Debounce     string          `yaml:"debounce,omitempty"`
+	Args         []string        `yaml:"args,omitempty"`
+	OutputFormat string          `yaml:"output_format,omitempty"`
+	Limits       *ResourceLimits `yaml:"limits,omitempty"`
 }
 
 // AQLRuleConfig represents configuration for AQL rules
 type AQLRuleConfig struct {
-	File    string `yaml:"file,omitempty"`   // Path to AQL rule file
-	Inline  string `yaml:"inline,omitempty"` // Inline AQL rule text
-	Enabled bool   `yaml:"enabled"`          // Whether this rule is enabled
+	File     string            `yaml:"file,omitempty"`     // Path to AQL rule file
+	Inline   string            `yaml:"inline,omitempty"`   // Inline AQL rule text
+	Template string            `yaml:"template,omitempty"` // Name of a template to instantiate
+	With     map[string]string `yaml:"with,omitempty"`     // Arguments of the template
+	Enabled  bool              `yaml:"enabled"`            // Whether this rule is enabled
+	Severity Severity          `yaml:"severity,omitempty"` // Severity of the rules that do not declare one
+}
+
+// ExtractionConfig selects which node kinds are extracted during AST analysis
+type ExtractionConfig struct {
+	NodeTypes     []string `yaml:"node_types,omitempty"`      // Only extract these kinds
+	SkipNodeTypes []string `yaml:"skip_node_types,omitempty"` // Extract everything except these kinds
+}
+
+// Profile returns the extraction profile described by the configuration
+func (e *ExtractionConfig) Profile() (ExtractionProfile, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return NewExtractionProfile(e.NodeTypes, e.SkipNodeTypes)
+}
+
+// NamedQuery represents a reusable query that can be run by name
+type NamedQuery struct {
+	Description string `yaml:"description,omitempty"`
+	AQL         string `yaml:"aql,omitempty"`     // Metric condition, e.g. "lines(*Service*) > 200"
+	Pattern     string `yaml:"pattern,omitempty"` // AST pattern, e.g. "@internal/**/*.go:*Service*"
+}
+
+// ReportConfig represents a saved report made up of named queries
+type ReportConfig struct {
+	Title   string   `yaml:"title,omitempty"`
+	Queries []string `yaml:"queries"`
 }
 
 // BuiltinRuleConfig represents configuration for a built-in rule
@@ -92,6 +183,14 @@ func (c *Config) GetDebounceDuration() (time.Duration, error) {
 	return time.ParseDuration(c.Debounce)
 }
 
+// GetRuleBudget returns the parsed rule budget, DefaultRuleBudget when unset
+func (c *Config) GetRuleBudget() (time.Duration, error) {
+	if c.RuleBudget == "" {
+		return DefaultRuleBudget, nil
+	}
+	return time.ParseDuration(c.RuleBudget)
+}
+
 // GetDebounceDuration returns the parsed debounce duration for a rule config
 func (r *RuleConfig) GetDebounceDuration() (time.Duration, error) {
 	if r.Debounce == "" {
@@ -108,6 +207,25 @@ func (l *LinterConfig) GetDebounceDuration() (time.Duration, error) {
 	return time.ParseDuration(l.Debounce)
 }
 
+// GetQuery returns the named query with the given name
+func (c *Config) GetQuery(name string) (*NamedQuery, bool) {
+	q, ok := c.Queries[name]
+	if !ok {
+		return nil, false
+	}
+	return &q, true
+}
+
+// GetQueryNames returns the names of all configured queries in sorted order
+func (c *Config) GetQueryNames() []string {
+	names := make([]string, 0, len(c.Queries))
+	for name := range c.Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetRulesForFile returns the applicable rules for a given file path
 func (c *Config) GetRulesForFile(filePath string) (*RuleSet, error) {
 	var rules []Rule
@@ -127,7 +245,7 @@ func (c *Config) GetRulesForFile(filePath string) (*RuleSet, error) {
 	var matches []patternMatch
 
 	for pattern, ruleConfig := range c.Rules {
-		if c.patternMatches(pattern, absPath, filePath) {
+		if c.PatternMatches(pattern, absPath, filePath) {
 			// Calculate specificity: more specific patterns should be processed last
 			specificity := 0
 			if pattern == "**" {
@@ -166,6 +284,7 @@ func (c *Config) GetRulesForFile(filePath string) (*RuleSet, error) {
 			if err != nil {
 				return nil, fmt.Errorf("invalid import rule '%s' in pattern '%s': %w", importRule, match.pattern, err)
 			}
+			rule.Severity = match.ruleConfig.Severity
 			rules = append(rules, *rule)
 			logger.Debugf("Added rule from pattern '%s': %s", match.pattern, importRule)
 		}
@@ -177,8 +296,8 @@ func (c *Config) GetRulesForFile(filePath string) (*RuleSet, error) {
 	}, nil
 }
 
-// patternMatches checks if a file path matches a given pattern
-func (c *Config) patternMatches(pattern, absPath, relPath string) bool {
+// PatternMatches checks if a file path matches the pattern of a rule
+func (c *Config) PatternMatches(pattern, absPath, relPath string) bool {
 	// Handle special "**" pattern (matches everything)
 	if pattern == "**" {
 		return true
@@ -305,7 +424,7 @@ func (c *Config) GetLinterConfig(linterName, filePath string) *LinterConfig {
 
 	// Override with path-specific config
 	for pattern, ruleConfig := range c.Rules {
-		if c.patternMatches(pattern, filePath, filePath) {
+		if c.PatternMatches(pattern, filePath, filePath) {
 			if linterConfig, exists := ruleConfig.Linters[linterName]; exists {
 				// Merge configurations (path-specific takes precedence)
 				if linterConfig.Enabled {
@@ -320,6 +439,10 @@ func (c *Config) GetLinterConfig(linterName, filePath string) *LinterConfig {
 				if linterConfig.OutputFormat != "" {
 					config.OutputFormat = linterConfig.OutputFormat
 				}
+				if linterConfig.Limits != nil {
+					merged := config.GetLimits().Merge(linterConfig.Limits)
+					config.Limits = &merged
+				}
 			}
 		}
 	}
@@ -344,7 +467,7 @@ func (c *Config) GetQualityConfig(filePath string) *QualityConfig {
 
 	// Find the most specific pattern match
 	for pattern, ruleConfig := range c.Rules {
-		if c.patternMatches(pattern, filePath, filePath) && ruleConfig.Quality != nil {
+		if c.PatternMatches(pattern, filePath, filePath) && ruleConfig.Quality != nil {
 			if config == nil {
 				// First match - create a copy
 				configCopy := *ruleConfig.Quality
@@ -370,6 +493,15 @@ func (c *Config) GetQualityConfig(filePath string) *QualityConfig {
 				if ruleConfig.Quality.CommentAnalysis.Enabled {
 					config.CommentAnalysis = ruleConfig.Quality.CommentAnalysis
 				}
+				if ruleConfig.Quality.NilChecks != nil {
+					config.NilChecks = ruleConfig.Quality.NilChecks
+				}
+				if ruleConfig.Quality.ContextChecks != nil {
+					config.ContextChecks = ruleConfig.Quality.ContextChecks
+				}
+				if ruleConfig.Quality.TransactionChecks != nil {
+					config.TransactionChecks = ruleConfig.Quality.TransactionChecks
+				}
 			}
 		}
 	}
@@ -283,6 +283,25 @@ var _ = Describe("Config.IsQualityEnabled", func() {
 	})
 })
 
+var _ = Describe("CheckSelection.IsCheckEnabled", func() {
+	It("should enable every check without configuration", func() {
+		var config *models.CheckSelection
+		Expect(config.IsCheckEnabled("err-value")).To(BeTrue())
+		Expect((&models.CheckSelection{}).IsCheckEnabled("map-lookup")).To(BeTrue())
+	})
+
+	It("should only enable the listed checks", func() {
+		config := &models.CheckSelection{Checks: []string{"map-lookup"}}
+		Expect(config.IsCheckEnabled("map-lookup")).To(BeTrue())
+		Expect(config.IsCheckEnabled("err-value")).To(BeFalse())
+	})
+
+	It("should disable every check", func() {
+		config := &models.CheckSelection{Disabled: true, Checks: []string{"map-lookup"}}
+		Expect(config.IsCheckEnabled("map-lookup")).To(BeFalse())
+	})
+})
+
 var _ = Describe("Performance tests", func() {
 	It("should check name disallowed efficiently", func() {
 		config := &models.QualityConfig{
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -23,6 +24,26 @@ type ConsolidatedSummary struct {
 	ArchViolations    int           `json:"arch_violations"`
 	LinterViolations  int           `json:"linter_violations"`
 	Duration          time.Duration `json:"duration"`
+	// Baselined is the number of violations hidden because they are part of the baseline
+	Baselined int `json:"baselined,omitempty"`
+	// Excepted is the number of violations suppressed by exceptions that did not expire
+	Excepted int `json:"excepted,omitempty"`
+	// Capabilities lists the features that were skipped because a tool they need is missing
+	Capabilities []CapabilityWarning `json:"capability_warnings,omitempty"`
+}
+
+// CapabilityWarning records a feature that was skipped because a tool it needs is not installed
+type CapabilityWarning struct {
+	Tool    string `json:"tool"`
+	Feature string `json:"feature"`
+	Install string `json:"install,omitempty"`
+}
+
+func (w CapabilityWarning) String() string {
+	if w.Install == "" {
+		return fmt.Sprintf("%s skipped: %s is not installed", w.Feature, w.Tool)
+	}
+	return fmt.Sprintf("%s skipped: %s is not installed (%s)", w.Feature, w.Tool, w.Install)
 }
 
 // LinterResult represents the result of running a linter (imported to avoid circular dependency)
@@ -35,6 +56,8 @@ type LinterResult struct {
 	Error      string        `json:"error,omitempty"`
 	FileCount  int           `json:"file_count,omitempty"`
 	RuleCount  int           `json:"rule_count,omitempty"`
+	// Skipped is set when the linter could not run because its tool is not installed
+	Skipped bool `json:"skipped,omitempty"`
 }
 
 // NewConsolidatedResult creates a new consolidated result from arch-unit and linter results
@@ -160,7 +183,7 @@ func (cr *ConsolidatedResult) GetFailedLinters() []string {
 	var failed []string
 
 	for _, linterResult := range cr.Linters {
-		if !linterResult.Success {
+		if !linterResult.Success && !linterResult.Skipped {
 			failed = append(failed, linterResult.Linter)
 		}
 	}
@@ -177,3 +200,14 @@ func (cr *ConsolidatedResult) HasViolations() bool {
 func (cr *ConsolidatedResult) HasFailures() bool {
 	return cr.HasViolations() || len(cr.GetFailedLinters()) > 0
 }
+
+// HasFailuresAt returns true if there are violations at least as serious as failOn or linter
+// failures
+func (cr *ConsolidatedResult) HasFailuresAt(failOn Severity) bool {
+	for _, v := range cr.Violations {
+		if v.Severity.IsAtLeast(failOn) {
+			return true
+		}
+	}
+	return len(cr.GetFailedLinters()) > 0
+}
@@ -0,0 +1,78 @@
+package models
+
+import "math"
+
+// Package coupling metrics of Robert C. Martin, conditions on them apply to packages rather than
+// to nodes, e.g. LIMIT(package.instability > 0.8)
+const (
+	MetricAfferent     = "afferent"     // Ca, the number of packages depending on the package
+	MetricEfferent     = "efferent"     // Ce, the number of packages the package depends on
+	MetricInstability  = "instability"  // I = Ce / (Ca + Ce)
+	MetricAbstractness = "abstractness" // A, the ratio of abstract types such as interfaces
+	MetricDistance     = "distance"     // D = |A + I - 1|, the distance from the main sequence
+)
+
+// IsPackageMetric returns true for the coupling metrics computed per package
+func IsPackageMetric(metric string) bool {
+	switch metric {
+	case MetricAfferent, MetricEfferent, MetricInstability, MetricAbstractness, MetricDistance:
+		return true
+	}
+	return false
+}
+
+// PackageCoupling holds the coupling metrics of a package. Stable packages that many others
+// depend on, with an instability near 0, should be abstract, packages far from the main sequence
+// are either rigid or useless.
+type PackageCoupling struct {
+	Package       string  `json:"package" pretty:"label=Package,style=text-blue-600"`
+	Afferent      int     `json:"afferent" pretty:"label=Ca"`
+	Efferent      int     `json:"efferent" pretty:"label=Ce"`
+	Instability   float64 `json:"instability" pretty:"label=Instability"`
+	Types         int     `json:"types" pretty:"label=Types"`
+	AbstractTypes int     `json:"abstract_types" pretty:"label=Abstract"`
+	Abstractness  float64 `json:"abstractness" pretty:"label=Abstractness"`
+	Distance      float64 `json:"distance" pretty:"label=Distance"`
+	File          string  `json:"file,omitempty" pretty:"hide"` // First file of the package, where violations are reported
+	Line          int     `json:"line,omitempty" pretty:"hide"`
+}
+
+// Compute derives the instability, abstractness and distance of the package from its counts,
+// rounded to two decimals
+func (c *PackageCoupling) Compute() {
+	if c.Afferent+c.Efferent > 0 {
+		c.Instability = float64(c.Efferent) / float64(c.Afferent+c.Efferent)
+	}
+	if c.Types > 0 {
+		c.Abstractness = float64(c.AbstractTypes) / float64(c.Types)
+	}
+	c.Distance = round2(math.Abs(c.Abstractness + c.Instability - 1))
+	c.Instability = round2(c.Instability)
+	c.Abstractness = round2(c.Abstractness)
+}
+
+// Metric returns the value of a package metric
+func (c *PackageCoupling) Metric(metric string) float64 {
+	switch metric {
+	case MetricAfferent:
+		return float64(c.Afferent)
+	case MetricEfferent:
+		return float64(c.Efferent)
+	case MetricInstability:
+		return c.Instability
+	case MetricAbstractness:
+		return c.Abstractness
+	case MetricDistance:
+		return c.Distance
+	}
+	return 0
+}
+
+// IsAbstract returns true for interfaces, the abstract types counted by abstractness
+func IsAbstract(node *ASTNode) bool {
+	return node.NodeType == NodeTypeType && node.Metatdata["kind"] == NamingKindInterface
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
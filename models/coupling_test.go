@@ -0,0 +1,34 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("PackageCoupling", func() {
+	It("computes instability, abstractness and distance from the counts", func() {
+		coupling := &models.PackageCoupling{Afferent: 1, Efferent: 2, Types: 4, AbstractTypes: 1}
+		coupling.Compute()
+		Expect(coupling.Instability).To(Equal(0.67))
+		Expect(coupling.Abstractness).To(Equal(0.25))
+		Expect(coupling.Distance).To(Equal(0.08))
+		Expect(coupling.Metric(models.MetricEfferent)).To(Equal(2.0))
+	})
+
+	It("treats isolated packages without types as stable and concrete", func() {
+		coupling := &models.PackageCoupling{}
+		coupling.Compute()
+		Expect(coupling.Instability).To(BeZero())
+		Expect(coupling.Abstractness).To(BeZero())
+		Expect(coupling.Distance).To(Equal(1.0))
+	})
+
+	It("recognizes package metrics in patterns", func() {
+		pattern, err := models.ParsePattern("core*.instability")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pattern.Metric).To(Equal(models.MetricInstability))
+		Expect(models.IsPackageMetric("cyclomatic")).To(BeFalse())
+	})
+})
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DeadSymbol is an unexported function, method, type or variable that nothing references
+type DeadSymbol struct {
+	Name    string `json:"name" pretty:"label=Symbol,style=text-red-600"`
+	Kind    string `json:"kind" pretty:"label=Kind"`
+	Package string `json:"package" pretty:"label=Package,style=text-blue-600"`
+	File    string `json:"file" pretty:"label=File"`
+	Line    int    `json:"line" pretty:"label=Line"`
+}
+
+// DeadCodeConfig enables the dead code rule of the aql linter
+type DeadCodeConfig struct {
+	Ignore   []string `yaml:"ignore,omitempty"`   // Doublestar globs of the files whose symbols are not reported, e.g. **/generated/**
+	Severity Severity `yaml:"severity,omitempty"` // Severity of violations, warning when empty
+}
+
+// Validate checks the severity of the dead code rule
+func (d *DeadCodeConfig) Validate() error {
+	if d.Severity != "" {
+		if _, err := ParseSeverity(string(d.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSeverity returns the severity of dead code violations
+func (d *DeadCodeConfig) GetSeverity() Severity {
+	if d.Severity == "" {
+		return SeverityWarning
+	}
+	return d.Severity
+}
+
+// IsIgnored returns true if the symbols of a file are not reported
+func (d *DeadCodeConfig) IsIgnored(filePath string) bool {
+	for _, pattern := range d.Ignore {
+		if matchesFilePath(filePath, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeadCodeCandidate returns the name and kind of a node that is dead when nothing references it:
+// unexported functions, methods, types and variables, except main, init and the symbols of tests
+func DeadCodeCandidate(node *ASTNode) (string, string, bool) {
+	if !node.IsPrivate || IsTestFile(node.FilePath) {
+		return "", "", false
+	}
+
+	var name, kind string
+	switch {
+	case node.NodeType == NodeTypeMethod && node.TypeName != "":
+		name, kind = node.MethodName, "method"
+	case node.NodeType == NodeTypeMethod:
+		name, kind = node.MethodName, "function"
+	case node.NodeType == NodeTypeType && node.MethodName == "" && node.FieldName == "":
+		name, kind = node.TypeName, "type"
+	case node.NodeType == NodeTypeVariable && node.TypeName == "" && node.MethodName == "":
+		name, kind = node.FieldName, "variable"
+	default:
+		return "", "", false
+	}
+
+	switch name {
+	case "", "_", "main", "init":
+		return "", "", false
+	}
+	return name, kind, true
+}
+
+// IsTestFile returns true for the test files of Go, Python, JavaScript/TypeScript and Dart
+func IsTestFile(filePath string) bool {
+	base := filepath.Base(filePath)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	return strings.HasSuffix(name, "_test") || strings.HasPrefix(name, "test_") ||
+		strings.HasSuffix(name, ".test") || strings.HasSuffix(name, ".spec")
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// MetadataDeprecated is the metadata of nodes documented as deprecated, holding the deprecation
+// notice, or "true" when the symbol is deprecated without a notice
+const MetadataDeprecated = "deprecated"
+
+// DeprecatedConfig enables the deprecated symbol usage rule of the aql linter
+type DeprecatedConfig struct {
+	Allow    []DeprecatedAllowance `yaml:"allow,omitempty"`    // Usages that are still allowed while callers migrate
+	Severity Severity              `yaml:"severity,omitempty"` // Severity of violations, warning when empty
+}
+
+// DeprecatedAllowance allows the usages of deprecated symbols matching a symbol pattern from
+// files matching one of the file patterns. An empty symbol matches every symbol and no files
+// match every file.
+type DeprecatedAllowance struct {
+	Symbol string   `yaml:"symbol,omitempty"` // Glob of the full name of the deprecated symbol, e.g. client.OldClient*
+	Files  []string `yaml:"files,omitempty"`  // Doublestar globs of the files still allowed to use the symbol
+}
+
+// Validate checks the severity and the symbol patterns of the deprecated rule
+func (d *DeprecatedConfig) Validate() error {
+	if d.Severity != "" {
+		if _, err := ParseSeverity(string(d.Severity)); err != nil {
+			return err
+		}
+	}
+	for _, allow := range d.Allow {
+		if allow.Symbol != "" && !doublestar.ValidatePattern(allow.Symbol) {
+			return fmt.Errorf("invalid symbol pattern '%s'", allow.Symbol)
+		}
+	}
+	return nil
+}
+
+// GetSeverity returns the severity of deprecated symbol usages
+func (d *DeprecatedConfig) GetSeverity() Severity {
+	if d.Severity == "" {
+		return SeverityWarning
+	}
+	return d.Severity
+}
+
+// IsAllowed returns true if a file may still use a deprecated symbol
+func (d *DeprecatedConfig) IsAllowed(symbol, filePath string) bool {
+	for _, allow := range d.Allow {
+		if allow.Symbol != "" {
+			if match, _ := doublestar.Match(allow.Symbol, symbol); !match {
+				continue
+			}
+		}
+		if len(allow.Files) == 0 {
+			return true
+		}
+		for _, pattern := range allow.Files {
+			if matchesFilePath(filePath, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Deprecation returns the deprecation notice of a node, and whether the node is deprecated
+func Deprecation(node *ASTNode) (string, bool) {
+	notice, ok := node.Metatdata[MetadataDeprecated]
+	if !ok {
+		return "", false
+	}
+	if notice == "true" {
+		notice = ""
+	}
+	return notice, true
+}
@@ -20,10 +20,25 @@ const (
 	DependencyTypeHelm      DependencyType = "helm"      // Helm chart dependencies
 	DependencyTypeGit       DependencyType = "git"       // Git repository dependencies
 	DependencyTypeKustomize DependencyType = "kustomize" // Kustomize dependencies
+	DependencyTypeTerraform DependencyType = "terraform" // Terraform providers and modules
+	DependencyTypeNuget     DependencyType = "nuget"     // NuGet packages of .NET projects
+	DependencyTypeBazel     DependencyType = "bazel"     // Bazel modules and external repositories
 	DependencyTypeStdlib    DependencyType = "stdlib"    // Standard library dependencies builtin to the language, version refers to he Go version etc..
 
 )
 
+// IsDependencyType returns true if name is one of the dependency types, e.g. go or docker
+func IsDependencyType(name string) bool {
+	switch DependencyType(name) {
+	case DependencyTypeInternal, DependencyTypeMaven, DependencyTypeNpm, DependencyTypePip,
+		DependencyTypeGo, DependencyTypeDocker, DependencyTypeHelm, DependencyTypeGit,
+		DependencyTypeKustomize, DependencyTypeTerraform, DependencyTypeNuget, DependencyTypeBazel,
+		DependencyTypeStdlib:
+		return true
+	}
+	return false
+}
+
 type Dependency struct {
 	ID           int64          `json:"id" pretty:"hide"`
 	Name         string         `json:"name" pretty:"label=Name,style=text-blue-500,sort=2"`  // Name of the dependency, e.g. "express", "requests", "flask", "gin", "kubernetes"
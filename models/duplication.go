@@ -0,0 +1,55 @@
+package models
+
+import "fmt"
+
+// DefaultMinTokens is the number of tokens a clone spans at least when min_tokens is unset
+const DefaultMinTokens = 100
+
+// DuplicationConfig configures the copy-paste detection of the duplication linter
+type DuplicationConfig struct {
+	MinTokens int      `yaml:"min_tokens,omitempty"` // Tokens a clone spans at least, 100 when unset
+	Ignore    []string `yaml:"ignore,omitempty"`     // Doublestar globs of the files not checked, e.g. **/generated/**
+	Severity  Severity `yaml:"severity,omitempty"`   // Severity of violations, warning when empty
+}
+
+// Validate checks the minimum number of tokens and severity of clones
+func (d *DuplicationConfig) Validate() error {
+	if d.MinTokens < 0 {
+		return fmt.Errorf("invalid min_tokens %d, expected a positive number", d.MinTokens)
+	}
+	if d.Severity != "" {
+		if _, err := ParseSeverity(string(d.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMinTokens returns the number of tokens a clone spans at least
+func (d *DuplicationConfig) GetMinTokens() int {
+	if d == nil || d.MinTokens == 0 {
+		return DefaultMinTokens
+	}
+	return d.MinTokens
+}
+
+// GetSeverity returns the severity of duplication violations
+func (d *DuplicationConfig) GetSeverity() Severity {
+	if d == nil || d.Severity == "" {
+		return SeverityWarning
+	}
+	return d.Severity
+}
+
+// IsIgnored returns true if a file is not checked for duplicates
+func (d *DuplicationConfig) IsIgnored(filePath string) bool {
+	if d == nil {
+		return false
+	}
+	for _, pattern := range d.Ignore {
+		if matchesFilePath(filePath, pattern) {
+			return true
+		}
+	}
+	return false
+}
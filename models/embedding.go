@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// NodeEmbedding is the embedding vector of a node for semantic search, with the hash of the text
+// it was computed from so that only changed nodes are embedded again
+type NodeEmbedding struct {
+	NodeID   int64  `json:"node_id" gorm:"column:node_id;primaryKey;autoIncrement:false"`
+	Model    string `json:"model" gorm:"column:model;not null;index"` // Provider and model of the embedding
+	TextHash string `json:"text_hash" gorm:"column:text_hash;not null"`
+	Vector   Vector `json:"-" gorm:"column:vector;not null"`
+}
+
+// TableName specifies the table name for NodeEmbedding
+func (NodeEmbedding) TableName() string {
+	return "node_embeddings"
+}
+
+// Vector is an embedding, stored as little endian float32 values
+type Vector []float32
+
+// GormDataType stores vectors as blobs
+func (Vector) GormDataType() string {
+	return "bytes"
+}
+
+// Value implements driver.Valuer
+func (v Vector) Value() (driver.Value, error) {
+	data := make([]byte, 4*len(v))
+	for i, value := range v {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data, nil
+}
+
+// Scan implements sql.Scanner
+func (v *Vector) Scan(value interface{}) error {
+	data, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into a vector", value)
+	}
+	if len(data)%4 != 0 {
+		return fmt.Errorf("invalid vector of %d bytes", len(data))
+	}
+	vector := make(Vector, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	*v = vector
+	return nil
+}
+
+// Cosine returns the cosine similarity of two vectors, from -1 for opposite to 1 for identical
+// directions, and 0 when their sizes differ
+func (v Vector) Cosine(other Vector) float64 {
+	if len(v) != len(other) || len(v) == 0 {
+		return 0
+	}
+	var dot, normV, normOther float64
+	for i := range v {
+		dot += float64(v[i]) * float64(other[i])
+		normV += float64(v[i]) * float64(v[i])
+		normOther += float64(other[i]) * float64(other[i])
+	}
+	if normV == 0 || normOther == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normV*normOther)
+}
+
+// SearchResult is a node matching a semantic search
+type SearchResult struct {
+	Score    float64  `json:"score" pretty:"label=Score"` // Cosine similarity with the query, rounded to 2 decimals
+	Name     string   `json:"name" pretty:"label=Node,style=text-blue-600"`
+	Kind     NodeType `json:"kind" pretty:"label=Kind"`
+	Location string   `json:"location" pretty:"label=Location"`
+	Summary  string   `json:"summary,omitempty" pretty:"label=Summary,style=text-gray-700"`
+	Node     *ASTNode `json:"-" pretty:"hide"`
+}
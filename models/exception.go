@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExceptionDateFormat is the format of the expiry date of exceptions
+const ExceptionDateFormat = "2006-01-02"
+
+// Exception suppresses the violations of a rule in some files. An exception with an expiry date
+// stops suppressing them once it expires and is itself reported, so that exceptions cannot turn
+// into permanent debt.
+type Exception struct {
+	Path    string `yaml:"path,omitempty"`    // Doublestar glob of the files, relative to the configuration, e.g. legacy/**
+	Rule    string `yaml:"rule,omitempty"`    // Text of the rule or message of the violations, e.g. No service to controller calls
+	Linter  string `yaml:"linter,omitempty"`  // Linter that reported the violations, e.g. golangci-lint
+	Reason  string `yaml:"reason,omitempty"`  // Why the violations are accepted
+	Expires string `yaml:"expires,omitempty"` // Last day the exception applies, e.g. 2025-06-30
+
+	Line int `yaml:"-"` // Line of the exception in the configuration that declares it, 0 when unknown
+}
+
+// UnmarshalYAML records the line of the exception so that it is reported there once it expires
+func (e *Exception) UnmarshalYAML(node *yaml.Node) error {
+	type plain Exception
+	if err := node.Decode((*plain)(e)); err != nil {
+		return err
+	}
+	e.Line = node.Line
+	return nil
+}
+
+// Validate checks that the exception selects violations and the format of its expiry date
+func (e *Exception) Validate() error {
+	if e.Path == "" && e.Rule == "" && e.Linter == "" {
+		return fmt.Errorf("exception must define at least one of 'path', 'rule' or 'linter'")
+	}
+	if e.Expires != "" {
+		if _, err := time.Parse(ExceptionDateFormat, e.Expires); err != nil {
+			return fmt.Errorf("invalid expires '%s', expected YYYY-MM-DD", e.Expires)
+		}
+	}
+	return nil
+}
+
+// IsExpired returns true once the day after the expiry date of the exception started
+func (e *Exception) IsExpired(now time.Time) bool {
+	if e.Expires == "" {
+		return false
+	}
+	expires, err := time.ParseInLocation(ExceptionDateFormat, e.Expires, now.Location())
+	if err != nil {
+		return false
+	}
+	return !now.Before(expires.AddDate(0, 0, 1))
+}
+
+// Matches returns true if a violation, with its file relative to root, is selected by the exception
+func (e *Exception) Matches(v Violation, root string) bool {
+	if e.Linter != "" && e.Linter != v.Source {
+		return false
+	}
+	if e.Path != "" {
+		file := v.File
+		if filepath.IsAbs(file) && root != "" {
+			if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+		if !matchesFilePath(filepath.ToSlash(file), e.Path) {
+			return false
+		}
+	}
+	if e.Rule != "" {
+		rule := ""
+		if v.Rule != nil {
+			rule = v.Rule.String()
+		}
+		message := ""
+		if v.Message != nil {
+			message = *v.Message
+		}
+		if !strings.Contains(rule, e.Rule) && !strings.Contains(message, e.Rule) {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the violations selected by the exception
+func (e *Exception) String() string {
+	parts := []string{"violations"}
+	if e.Rule != "" {
+		parts = append(parts, fmt.Sprintf("of '%s'", e.Rule))
+	}
+	if e.Linter != "" {
+		parts = append(parts, "from "+e.Linter)
+	}
+	if e.Path != "" {
+		parts = append(parts, "in "+e.Path)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ExpiredViolation returns the violation reporting that the exception expired, at its line of the
+// configuration that declares it
+func (e *Exception) ExpiredViolation(configFile string) Violation {
+	message := fmt.Sprintf("Exception for %s expired on %s", e, e.Expires)
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+	line := e.Line
+	if line == 0 {
+		line = 1
+	}
+	return Violation{
+		File:     configFile,
+		Line:     line,
+		Message:  &message,
+		Source:   "arch-unit",
+		Severity: SeverityError,
+	}
+}
+
+// ApplyExceptions removes the violations suppressed by exceptions that did not expire, reports the
+// expired exceptions at configFile and regenerates the summary of the result
+func (cr *ConsolidatedResult) ApplyExceptions(exceptions []Exception, root, configFile string, now time.Time) {
+	violations := make([]Violation, 0, len(cr.Violations))
+	for _, v := range cr.Violations {
+		if !IsExcepted(exceptions, v, root, now) {
+			violations = append(violations, v)
+		}
+	}
+	excepted := len(cr.Violations) - len(violations)
+	for i := range exceptions {
+		if exceptions[i].IsExpired(now) {
+			violations = append(violations, exceptions[i].ExpiredViolation(configFile))
+		}
+	}
+	cr.Violations = violations
+	baselined := cr.Summary.Baselined
+	cr.GenerateSummary()
+	cr.Summary.Excepted = excepted
+	cr.Summary.Baselined = baselined
+}
+
+// IsExcepted returns true if a violation is suppressed by an exception that did not expire
+func IsExcepted(exceptions []Exception, v Violation, root string, now time.Time) bool {
+	for i := range exceptions {
+		if !exceptions[i].IsExpired(now) && exceptions[i].Matches(v, root) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Exception", func() {
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	violation := func(file, message string) Violation {
+		return Violation{File: file, Source: "aql", Message: &message}
+	}
+
+	It("should require a path, rule or linter", func() {
+		Expect((&Exception{Reason: "legacy"}).Validate()).To(MatchError(ContainSubstring("at least one of")))
+		Expect((&Exception{Path: "legacy/**"}).Validate()).To(Succeed())
+	})
+
+	It("should reject malformed expiry dates", func() {
+		Expect((&Exception{Path: "legacy/**", Expires: "30/06/2025"}).Validate()).To(MatchError(ContainSubstring("expected YYYY-MM-DD")))
+	})
+
+	It("should apply until the end of its expiry date", func() {
+		Expect((&Exception{Expires: "2025-07-01"}).IsExpired(now)).To(BeFalse())
+		Expect((&Exception{Expires: "2025-06-30"}).IsExpired(now)).To(BeTrue())
+		Expect((&Exception{}).IsExpired(now)).To(BeFalse())
+	})
+
+	It("should match violations by file, rule and linter", func() {
+		exception := &Exception{Path: "legacy/**", Rule: "No service to controller calls", Linter: "aql"}
+
+		Expect(exception.Matches(violation("/repo/legacy/user.go", "Rule 'No service to controller calls': Forbidden call"), "/repo")).To(BeTrue())
+		Expect(exception.Matches(violation("/repo/api/user.go", "Rule 'No service to controller calls': Forbidden call"), "/repo")).To(BeFalse())
+		Expect(exception.Matches(violation("/repo/legacy/user.go", "Rule 'Layers': Forbidden call"), "/repo")).To(BeFalse())
+	})
+
+	It("should report violations of expired exceptions and the expired exceptions", func() {
+		result := &ConsolidatedResult{Violations: []Violation{
+			violation("/repo/legacy/user.go", "unused result"),
+			violation("/repo/old/user.go", "unused result"),
+			violation("/repo/api/user.go", "unused result"),
+		}}
+		result.ApplyExceptions([]Exception{
+			{Path: "legacy/**"},
+			{Path: "old/**", Reason: "migrated in Q2", Expires: "2025-06-30"},
+		}, "/repo", "/repo/arch-unit.yaml", now)
+
+		Expect(result.Summary.Excepted).To(Equal(1))
+		Expect(result.Violations).To(HaveLen(3))
+		expired := result.Violations[2]
+		Expect(expired.File).To(Equal("/repo/arch-unit.yaml"))
+		Expect(*expired.Message).To(Equal("Exception for violations in old/** expired on 2025-06-30: migrated in Q2"))
+	})
+
+	It("should report expired exceptions at their line of the configuration", func() {
+		var config Config
+		Expect(yaml.Unmarshal([]byte(`version: "1.0"
+exceptions:
+  - path: legacy/**
+  - path: old/**
+    reason: migrated in Q2
+    expires: "2025-06-30"
+`), &config)).To(Succeed())
+
+		Expect(config.Exceptions).To(HaveLen(2))
+		Expect(config.Exceptions[1].Reason).To(Equal("migrated in Q2"))
+		Expect(config.Exceptions[1].ExpiredViolation("/repo/arch-unit.yaml").Line).To(Equal(4))
+		Expect((&Exception{Path: "old/**"}).ExpiredViolation("/repo/arch-unit.yaml").Line).To(Equal(1))
+	})
+})
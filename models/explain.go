@@ -0,0 +1,25 @@
+package models
+
+// RuleExplanation describes how an AQL rule is evaluated against the AST cache
+type RuleExplanation struct {
+	Rule           string                 `json:"rule" pretty:"label=Rule,style=text-purple-600"`
+	Source         string                 `json:"source,omitempty" pretty:"label=Source,omitempty"`
+	Parsed         string                 `json:"parsed" pretty:"label=Parsed"`
+	Statements     []StatementExplanation `json:"statements" pretty:"label=Statements"`
+	ViolationCount int                    `json:"violation_count" pretty:"label=Violations"`
+	Violations     []QueryMatch           `json:"violations,omitempty" pretty:"label=Example Violations,omitempty"`
+}
+
+// StatementExplanation describes the patterns of a single statement of a rule
+type StatementExplanation struct {
+	Statement string               `json:"statement" pretty:"label=Statement,style=text-blue-600"`
+	Patterns  []PatternExplanation `json:"patterns,omitempty" pretty:"label=Patterns,omitempty"`
+}
+
+// PatternExplanation describes the query run for a pattern and the nodes it currently matches
+type PatternExplanation struct {
+	Pattern  string   `json:"pattern" pretty:"label=Pattern"`
+	Query    string   `json:"query" pretty:"label=Query,style=text-gray-500"`
+	Matches  int      `json:"matches" pretty:"label=Matches"`
+	Examples []string `json:"examples,omitempty" pretty:"label=Examples,omitempty"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExtendsConfig is a rule pack whose arch-unit.yaml is merged into the configuration, either a
+// directory relative to the configuration or a directory of a Git repository, e.g.
+// github.com/org/arch-rules//go-standard@v1
+type ExtendsConfig struct {
+	Source   string `yaml:"source"`
+	Checksum string `yaml:"checksum,omitempty"` // sha256:<hex> of the rule pack, fetched packs must match it
+}
+
+// UnmarshalYAML accepts either a source or a mapping with a source and a checksum
+func (e *ExtendsConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		e.Source = node.Value
+		return nil
+	}
+	type plain ExtendsConfig
+	return node.Decode((*plain)(e))
+}
+
+// RulePackSource is the location of a rule pack
+type RulePackSource struct {
+	Repository string // Git URL of the repository, empty for local rule packs
+	Path       string // Directory of the rule pack in the repository, or the local directory
+	Ref        string // Branch, tag or commit, HEAD when empty
+}
+
+// IsLocal returns true for rule packs in a local directory
+func (s RulePackSource) IsLocal() bool {
+	return s.Repository == ""
+}
+
+// ParseSource parses the source of a rule pack. Paths starting with . or / are local
+// directories, other sources are repositories with an optional directory after // and ref
+// after @, fetched over HTTPS unless they include a scheme.
+func (e *ExtendsConfig) ParseSource() (RulePackSource, error) {
+	source := strings.TrimSpace(e.Source)
+	if source == "" {
+		return RulePackSource{}, fmt.Errorf("rule pack source is required")
+	}
+	if strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") {
+		return RulePackSource{Path: source}, nil
+	}
+
+	var parsed RulePackSource
+	if at := strings.LastIndex(source, "@"); at > strings.LastIndex(source, "/") {
+		source, parsed.Ref = source[:at], source[at+1:]
+	}
+
+	scheme := ""
+	if i := strings.Index(source, "://"); i >= 0 {
+		scheme, source = source[:i+3], source[i+3:]
+	}
+	repository, path, _ := strings.Cut(source, "//")
+	if strings.Count(strings.Trim(repository, "/"), "/") < 2 {
+		return RulePackSource{}, fmt.Errorf("invalid rule pack source '%s', expected host/org/repo//path@ref", e.Source)
+	}
+	if scheme == "" {
+		scheme = "https://"
+	}
+	parsed.Repository = scheme + strings.Trim(repository, "/")
+	parsed.Path = strings.Trim(path, "/")
+	return parsed, nil
+}
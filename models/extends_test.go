@@ -0,0 +1,46 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("ExtendsConfig", func() {
+	DescribeTable("parsing rule pack sources",
+		func(source string, expected models.RulePackSource) {
+			extends := models.ExtendsConfig{Source: source}
+			Expect(extends.ParseSource()).To(Equal(expected))
+		},
+		Entry("repository directory at a ref", "github.com/org/arch-rules//go-standard@v1",
+			models.RulePackSource{Repository: "https://github.com/org/arch-rules", Path: "go-standard", Ref: "v1"}),
+		Entry("repository root", "github.com/org/arch-rules",
+			models.RulePackSource{Repository: "https://github.com/org/arch-rules"}),
+		Entry("repository with a scheme", "ssh://git@gitlab.com/org/rules//packs/go@main",
+			models.RulePackSource{Repository: "ssh://git@gitlab.com/org/rules", Path: "packs/go", Ref: "main"}),
+		Entry("local directory", "./rules/go-standard",
+			models.RulePackSource{Path: "./rules/go-standard"}),
+	)
+
+	It("rejects sources without a repository", func() {
+		extends := models.ExtendsConfig{Source: "github.com/org"}
+		_, err := extends.ParseSource()
+		Expect(err).To(MatchError(ContainSubstring("invalid rule pack source 'github.com/org'")))
+	})
+
+	It("accepts sources with or without a checksum", func() {
+		var config models.Config
+		Expect(yaml.Unmarshal([]byte(`
+extends:
+  - github.com/org/arch-rules//go-standard@v1
+  - source: github.com/org/arch-rules//security@v2
+    checksum: sha256:abc
+`), &config)).To(Succeed())
+		Expect(config.Extends).To(Equal([]models.ExtendsConfig{
+			{Source: "github.com/org/arch-rules//go-standard@v1"},
+			{Source: "github.com/org/arch-rules//security@v2", Checksum: "sha256:abc"},
+		}))
+	})
+})
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExtractableNodeKinds are the node kinds that can be selected for extraction.
+// Sub-types such as "method_http_get" or "type_table" follow their base kind.
+var ExtractableNodeKinds = []NodeType{
+	NodeTypePackage,
+	NodeTypeType,
+	NodeTypeMethod,
+	NodeTypeField,
+	NodeTypeVariable,
+}
+
+// ExtractionProfile is the set of node kinds extracted during analysis.
+// An empty profile means all node kinds were extracted.
+type ExtractionProfile []NodeType
+
+// NewExtractionProfile builds a profile from an include list and a skip list.
+// An empty include list starts from all kinds before the skip list is applied.
+func NewExtractionProfile(include, skip []string) (ExtractionProfile, error) {
+	if len(include) == 0 && len(skip) == 0 {
+		return nil, nil
+	}
+
+	selected := make(map[NodeType]bool)
+	if len(include) == 0 {
+		for _, kind := range ExtractableNodeKinds {
+			selected[kind] = true
+		}
+	}
+
+	for _, kind := range include {
+		if err := validateNodeKind(kind); err != nil {
+			return nil, err
+		}
+		selected[strings.TrimSpace(kind)] = true
+	}
+
+	for _, kind := range skip {
+		if err := validateNodeKind(kind); err != nil {
+			return nil, err
+		}
+		delete(selected, strings.TrimSpace(kind))
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("extraction profile excludes all node kinds")
+	}
+
+	profile := make(ExtractionProfile, 0, len(selected))
+	for kind := range selected {
+		profile = append(profile, kind)
+	}
+	sort.Strings(profile)
+
+	if len(profile) == len(ExtractableNodeKinds) {
+		return nil, nil // Equivalent to a full extraction
+	}
+	return profile, nil
+}
+
+// ParseExtractionProfile parses a profile previously produced by String
+func ParseExtractionProfile(s string) ExtractionProfile {
+	if s == "" {
+		return nil
+	}
+	profile := ExtractionProfile(strings.Split(s, ","))
+	sort.Strings(profile)
+	return profile
+}
+
+// IsFull returns true if the profile extracts all node kinds
+func (p ExtractionProfile) IsFull() bool {
+	return len(p) == 0
+}
+
+// Includes returns true if nodes of the given type are extracted by this profile
+func (p ExtractionProfile) Includes(nodeType NodeType) bool {
+	if p.IsFull() {
+		return true
+	}
+	base := BaseNodeKind(nodeType)
+	for _, kind := range p {
+		if kind == base {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers returns true if everything extracted by other is also extracted by this profile
+func (p ExtractionProfile) Covers(other ExtractionProfile) bool {
+	if p.IsFull() {
+		return true
+	}
+	if other.IsFull() {
+		return false
+	}
+	for _, kind := range other {
+		if !p.Includes(kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the canonical comma separated form stored in the cache
+func (p ExtractionProfile) String() string {
+	return strings.Join(p, ",")
+}
+
+// BaseNodeKind returns the base kind of a node type, e.g. "method" for "method_http_get"
+func BaseNodeKind(nodeType NodeType) NodeType {
+	if i := strings.Index(nodeType, "_"); i > 0 {
+		return nodeType[:i]
+	}
+	return nodeType
+}
+
+func validateNodeKind(kind string) error {
+	kind = strings.TrimSpace(kind)
+	for _, valid := range ExtractableNodeKinds {
+		if kind == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown node kind '%s', must be one of: %v", kind, ExtractableNodeKinds)
+}
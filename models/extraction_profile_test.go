@@ -0,0 +1,40 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("ExtractionProfile", func() {
+	It("treats an empty selection as a full extraction", func() {
+		profile, err := models.NewExtractionProfile(nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profile.IsFull()).To(BeTrue())
+		Expect(profile.Includes(models.NodeTypeVariable)).To(BeTrue())
+	})
+
+	It("applies skip lists to all kinds", func() {
+		profile, err := models.NewExtractionProfile(nil, []string{"variable", "field"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profile.String()).To(Equal("method,package,type"))
+		Expect(profile.Includes(models.NodeTypeMethodHTTPGet)).To(BeTrue())
+		Expect(profile.Includes(models.NodeTypeFieldColumn)).To(BeFalse())
+	})
+
+	It("rejects unknown node kinds", func() {
+		_, err := models.NewExtractionProfile([]string{"statement"}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("checking whether a cached profile covers a requested one",
+		func(cached, requested string, expected bool) {
+			Expect(models.ParseExtractionProfile(cached).Covers(models.ParseExtractionProfile(requested))).To(Equal(expected))
+		},
+		Entry("full covers partial", "", "method,type", true),
+		Entry("partial does not cover full", "method,type", "", false),
+		Entry("superset covers subset", "method,package,type", "method,type", true),
+		Entry("subset does not cover superset", "method", "method,type", false),
+	)
+})
@@ -0,0 +1,75 @@
+package models
+
+// Default thresholds of the god-class and god-package rules
+const (
+	DefaultMaxMethods      = 20
+	DefaultMaxFields       = 15
+	DefaultMaxPackageFiles = 50
+	DefaultMaxPackageTypes = 40
+	DefaultMaxFanOut       = 20
+)
+
+// GodObjectConfig bounds the size of types and packages and the fan-out of functions. Unset
+// thresholds take their default, negative ones disable their check.
+type GodObjectConfig struct {
+	MaxMethods      int      `yaml:"max_methods,omitempty"`       // Methods per type
+	MaxFields       int      `yaml:"max_fields,omitempty"`        // Fields per type
+	MaxPackageFiles int      `yaml:"max_package_files,omitempty"` // Files per package
+	MaxPackageTypes int      `yaml:"max_package_types,omitempty"` // Types per package
+	MaxFanOut       int      `yaml:"max_fan_out,omitempty"`       // Distinct functions called by a function
+	Severity        Severity `yaml:"severity,omitempty"`          // Severity of violations, error when empty
+}
+
+// Validate checks the severity of the god-object rules
+func (g *GodObjectConfig) Validate() error {
+	if g.Severity != "" {
+		if _, err := ParseSeverity(string(g.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSeverity returns the severity of god-object violations
+func (g *GodObjectConfig) GetSeverity() Severity {
+	if g.Severity == "" {
+		return SeverityError
+	}
+	return g.Severity
+}
+
+// MethodLimit returns the maximum number of methods per type, 0 when unchecked
+func (g *GodObjectConfig) MethodLimit() int {
+	return threshold(g.MaxMethods, DefaultMaxMethods)
+}
+
+// FieldLimit returns the maximum number of fields per type, 0 when unchecked
+func (g *GodObjectConfig) FieldLimit() int {
+	return threshold(g.MaxFields, DefaultMaxFields)
+}
+
+// PackageFileLimit returns the maximum number of files per package, 0 when unchecked
+func (g *GodObjectConfig) PackageFileLimit() int {
+	return threshold(g.MaxPackageFiles, DefaultMaxPackageFiles)
+}
+
+// PackageTypeLimit returns the maximum number of types per package, 0 when unchecked
+func (g *GodObjectConfig) PackageTypeLimit() int {
+	return threshold(g.MaxPackageTypes, DefaultMaxPackageTypes)
+}
+
+// FanOutLimit returns the maximum fan-out of functions, 0 when unchecked
+func (g *GodObjectConfig) FanOutLimit() int {
+	return threshold(g.MaxFanOut, DefaultMaxFanOut)
+}
+
+// threshold returns value, def when unset or 0 when disabled
+func threshold(value, def int) int {
+	switch {
+	case value < 0:
+		return 0
+	case value == 0:
+		return def
+	}
+	return value
+}
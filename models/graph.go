@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Levels of dependency graphs, the nodes of a type graph are named package.Type
+const (
+	GraphLevelPackage = "package"
+	GraphLevelType    = "type"
+)
+
+// graphEdgeTypes are the relationship types a graph can draw
+var graphEdgeTypes = map[string]bool{
+	RelationshipCall:                 true,
+	RelationshipReference:            true,
+	RelationshipInheritance:          true,
+	RelationshipImplements:           true,
+	RelationshipImport:               true,
+	RelationshipExtends:              true,
+	RelationshipForeignKey:           true,
+	RelationshipSQLQuery:             true,
+	RelationshipHTTPCall:             true,
+	RelationshipEnvVar:               true,
+	string(RelationshipTypeIncludes): true,
+	string(RelationshipTypeFileOp):   true,
+}
+
+// GraphOptions select the nodes and relationships of a dependency graph
+type GraphOptions struct {
+	Level      string // package or type
+	Pattern    string // Glob of the packages or types to start from, e.g. service* or api.User*, every one when empty
+	Depth      int    // Number of dependencies followed from the nodes matching Pattern
+	PathPrefix string // Only nodes of files under the prefix, every file when empty
+	// Relationship types drawn as edges of their own, including those to external libraries
+	// such as imports, e.g. import, call and implements. Without types, edges are the calls,
+	// embedding and other dependencies between the nodes under PathPrefix.
+	Edges   []string
+	Exclude []string // Globs of the packages or types to leave out, e.g. *test* or fmt
+}
+
+// Validate checks the level and pattern of the options
+func (o *GraphOptions) Validate() error {
+	if o.Level != GraphLevelPackage && o.Level != GraphLevelType {
+		return fmt.Errorf("invalid level '%s', expected package or type", o.Level)
+	}
+	if o.Pattern != "" && !doublestar.ValidatePattern(o.Pattern) {
+		return fmt.Errorf("invalid pattern '%s'", o.Pattern)
+	}
+	for _, pattern := range o.Exclude {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid exclude pattern '%s'", pattern)
+		}
+	}
+	for _, edge := range o.Edges {
+		if !graphEdgeTypes[edge] {
+			return fmt.Errorf("invalid edge type '%s', expected one of %s", edge, strings.Join(GraphEdgeTypes(), ", "))
+		}
+	}
+	if o.Depth < 0 {
+		return fmt.Errorf("invalid depth %d", o.Depth)
+	}
+	return nil
+}
+
+// GraphEdgeTypes returns the relationship types a graph can draw, ordered by name
+func GraphEdgeTypes() []string {
+	types := make([]string, 0, len(graphEdgeTypes))
+	for edge := range graphEdgeTypes {
+		types = append(types, edge)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// DependencyGraph is a diagram of the dependencies between packages or types, ordered by name.
+// External holds the nodes that are libraries rather than packages or types of the codebase.
+type DependencyGraph struct {
+	Level    string            `json:"level"`
+	Nodes    []string          `json:"nodes"`
+	External []string          `json:"external,omitempty"`
+	Edges    []*DependencyEdge `json:"edges"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// RunSummary summarises the violations and code of a check run, recorded by commit so that the
+// trends of a codebase can be reported
+type RunSummary struct {
+	ID            int64          `json:"id" pretty:"hide"`
+	Commit        string         `json:"commit" pretty:"label=Commit,style=text-blue-600"`
+	Dirty         bool           `json:"dirty,omitempty" pretty:"label=Uncommitted"` // The working tree had uncommitted changes
+	RecordedAt    time.Time      `json:"recorded_at" pretty:"label=Recorded"`
+	Violations    int            `json:"violations" pretty:"label=Violations,style=text-red-600"`
+	Rules         map[string]int `json:"rules,omitempty" pretty:"hide"` // Violations by rule
+	Files         int            `json:"files" pretty:"label=Files"`
+	Functions     int            `json:"functions" pretty:"label=Functions"`
+	AvgComplexity float64        `json:"avg_complexity" pretty:"label=Avg Complexity"`
+	MaxComplexity int            `json:"max_complexity" pretty:"label=Max Complexity"`
+	Dependencies  int            `json:"dependencies" pretty:"label=Dependencies"` // Dependencies between packages
+}
+
+// Regression is a metric that got worse between two runs
+type Regression struct {
+	Metric string  `json:"metric" pretty:"label=Metric,style=text-blue-600"`
+	Base   float64 `json:"base" pretty:"label=Before"`
+	Head   float64 `json:"head" pretty:"label=After,style=text-red-600"`
+}
+
+// Regressions returns the metrics that are worse in the run than in base: the violations in
+// total and of every rule, the average and maximum complexity and the dependencies between
+// packages. More files and functions are growth, not regressions.
+func (s *RunSummary) Regressions(base *RunSummary) []Regression {
+	var regressions []Regression
+	check := func(metric string, before, after float64) {
+		if after > before {
+			regressions = append(regressions, Regression{Metric: metric, Base: before, Head: after})
+		}
+	}
+
+	check("violations", float64(base.Violations), float64(s.Violations))
+	rules := make([]string, 0, len(s.Rules))
+	for rule := range s.Rules {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		check("violations of "+rule, float64(base.Rules[rule]), float64(s.Rules[rule]))
+	}
+	check("average complexity", base.AvgComplexity, s.AvgComplexity)
+	check("max complexity", float64(base.MaxComplexity), float64(s.MaxComplexity))
+	check("dependencies", float64(base.Dependencies), float64(s.Dependencies))
+	return regressions
+}
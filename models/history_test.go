@@ -0,0 +1,35 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("RunSummary", func() {
+	base := &models.RunSummary{
+		Violations: 5, Rules: map[string]int{"layers": 3, "dead code": 2},
+		Files: 10, Functions: 40, AvgComplexity: 3.5, MaxComplexity: 12, Dependencies: 8,
+	}
+
+	It("reports the metrics that got worse", func() {
+		head := &models.RunSummary{
+			Violations: 6, Rules: map[string]int{"layers": 2, "dead code": 2, "naming": 2},
+			Files: 12, Functions: 50, AvgComplexity: 3.75, MaxComplexity: 12, Dependencies: 8,
+		}
+		Expect(head.Regressions(base)).To(Equal([]models.Regression{
+			{Metric: "violations", Base: 5, Head: 6},
+			{Metric: "violations of naming", Base: 0, Head: 2},
+			{Metric: "average complexity", Base: 3.5, Head: 3.75},
+		}))
+	})
+
+	It("does not treat growth or improvements as regressions", func() {
+		head := &models.RunSummary{
+			Violations: 1, Rules: map[string]int{"layers": 1},
+			Files: 20, Functions: 80, AvgComplexity: 3, MaxComplexity: 10, Dependencies: 6,
+		}
+		Expect(head.Regressions(base)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,76 @@
+package models
+
+import "fmt"
+
+// Defaults of the hotspot rule
+const (
+	DefaultHotspotSince     = "6 months ago"
+	DefaultHotspotThreshold = 200
+)
+
+// Hotspot is a file ranked as a refactoring candidate by its churn, the commits changing it, times
+// its complexity, the cyclomatic complexity of its functions
+type Hotspot struct {
+	File       string `json:"file" pretty:"label=File,style=text-blue-600"`
+	Commits    int    `json:"commits" pretty:"label=Commits"`
+	Complexity int    `json:"complexity" pretty:"label=Complexity"`
+	Functions  int    `json:"functions" pretty:"label=Functions"`
+	Lines      int    `json:"lines" pretty:"label=Lines"`
+	Score      int    `json:"score" pretty:"label=Score,style=text-red-600"`
+}
+
+// HotspotConfig enables the hotspot rule of the aql linter, reporting the files whose churn times
+// complexity exceeds a threshold
+type HotspotConfig struct {
+	Since     string   `yaml:"since,omitempty"`     // Commits counted since a date git understands, 6 months ago when empty
+	Threshold int      `yaml:"threshold,omitempty"` // Score above which files are reported, 200 when unset
+	Ignore    []string `yaml:"ignore,omitempty"`    // Doublestar globs of the files not reported, e.g. **/generated/**
+	Severity  Severity `yaml:"severity,omitempty"`  // Severity of violations, info when empty so hotspots are advisory
+}
+
+// Validate checks the threshold and severity of the hotspot rule
+func (h *HotspotConfig) Validate() error {
+	if h.Threshold < 0 {
+		return fmt.Errorf("invalid threshold %d", h.Threshold)
+	}
+	if h.Severity != "" {
+		if _, err := ParseSeverity(string(h.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSince returns the date from which commits are counted
+func (h *HotspotConfig) GetSince() string {
+	if h.Since == "" {
+		return DefaultHotspotSince
+	}
+	return h.Since
+}
+
+// GetThreshold returns the score above which files are reported
+func (h *HotspotConfig) GetThreshold() int {
+	if h.Threshold == 0 {
+		return DefaultHotspotThreshold
+	}
+	return h.Threshold
+}
+
+// GetSeverity returns the severity of hotspot violations
+func (h *HotspotConfig) GetSeverity() Severity {
+	if h.Severity == "" {
+		return SeverityInfo
+	}
+	return h.Severity
+}
+
+// IsIgnored returns true if a file is not reported
+func (h *HotspotConfig) IsIgnored(filePath string) bool {
+	for _, pattern := range h.Ignore {
+		if matchesFilePath(filePath, pattern) {
+			return true
+		}
+	}
+	return false
+}
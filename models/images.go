@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ImagePolicyConfig configures the images linter, which checks the Docker images referenced by
+// Dockerfiles, compose files, kustomizations and Helm values
+type ImagePolicyConfig struct {
+	ForbidLatest  bool     `yaml:"forbid_latest,omitempty"`  // Report images using the latest tag, or no tag at all
+	RequireDigest bool     `yaml:"require_digest,omitempty"` // Report images not pinned by a digest, e.g. nginx@sha256:...
+	Registries    []string `yaml:"registries,omitempty"`     // Allowed registries or repository prefixes, e.g. docker.io, ghcr.io/flanksource
+	Ignore        []string `yaml:"ignore,omitempty"`         // Image patterns exempt from the policy, e.g. localhost/**
+}
+
+// Validate checks the configured registries and ignore patterns
+func (c *ImagePolicyConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for _, registry := range c.Registries {
+		if registry == "" || strings.ContainsAny(registry, "@ \t") {
+			return fmt.Errorf("invalid registry '%s', expected a registry or repository prefix such as ghcr.io/flanksource", registry)
+		}
+	}
+	for _, pattern := range c.Ignore {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid ignore pattern '%s'", pattern)
+		}
+	}
+	return nil
+}
+
+// IsEnabled returns whether any rule of the policy is configured
+func (c *ImagePolicyConfig) IsEnabled() bool {
+	return c != nil && (c.ForbidLatest || c.RequireDigest || len(c.Registries) > 0)
+}
+
+// IsIgnored returns whether an image, named as referenced or by its full repository, is exempt
+// from the policy
+func (c *ImagePolicyConfig) IsIgnored(names ...string) bool {
+	if c == nil {
+		return false
+	}
+	for _, pattern := range c.Ignore {
+		for _, name := range names {
+			if matched, _ := doublestar.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package models
+
+import "fmt"
+
+// LayerConfig defines an architectural layer by the packages it contains and the layers its
+// packages may depend on, e.g. a handler layer allowed to depend on the service layer
+type LayerConfig struct {
+	Name     string   `yaml:"name"`
+	Packages []string `yaml:"packages"`        // Package patterns, e.g. handler, *service*, repo*
+	Allow    []string `yaml:"allow,omitempty"` // Names of the layers this layer may depend on
+	// Transactions marks the data access layer, whose packages may open database transactions
+	Transactions bool `yaml:"transactions,omitempty"`
+}
+
+// Layers is the layered architecture of arch-unit.yaml. A package belongs to the first layer
+// with a matching pattern, dependencies within a layer and to packages outside of all layers
+// are always allowed.
+type Layers []LayerConfig
+
+// Validate checks that layers are named uniquely, define packages and only allow defined layers
+func (l Layers) Validate() error {
+	names := make(map[string]bool, len(l))
+	for _, layer := range l {
+		if layer.Name == "" {
+			return fmt.Errorf("layer name is required")
+		}
+		if names[layer.Name] {
+			return fmt.Errorf("duplicate layer '%s'", layer.Name)
+		}
+		names[layer.Name] = true
+		if len(layer.Packages) == 0 {
+			return fmt.Errorf("layer '%s' must define at least one package pattern", layer.Name)
+		}
+	}
+	for _, layer := range l {
+		for _, allowed := range layer.Allow {
+			if !names[allowed] {
+				return fmt.Errorf("layer '%s' allows unknown layer '%s'", layer.Name, allowed)
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns the layer of a node, the first layer with a pattern matching its package, or nil
+func (l Layers) Find(node *ASTNode) *LayerConfig {
+	for i, layer := range l {
+		for _, pattern := range layer.Packages {
+			if matchesWildcard(node.PackageName, pattern) {
+				return &l[i]
+			}
+		}
+	}
+	return nil
+}
+
+// HasTransactions returns true if a layer is marked as the data access layer
+func (l Layers) HasTransactions() bool {
+	for _, layer := range l {
+		if layer.Transactions {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows returns true if a layer may depend on another
+func (layer *LayerConfig) Allows(to *LayerConfig) bool {
+	if layer.Name == to.Name {
+		return true
+	}
+	for _, allowed := range layer.Allow {
+		if allowed == to.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Layers", func() {
+	layers := models.Layers{
+		{Name: "handler", Packages: []string{"handler", "*api"}, Allow: []string{"service"}},
+		{Name: "service", Packages: []string{"service*"}, Allow: []string{"repo"}},
+		{Name: "repo", Packages: []string{"repo*", "service_store"}},
+	}
+
+	It("assigns packages to the first layer with a matching pattern", func() {
+		Expect(layers.Find(&models.ASTNode{PackageName: "publicapi"}).Name).To(Equal("handler"))
+		Expect(layers.Find(&models.ASTNode{PackageName: "service_store"}).Name).To(Equal("service"))
+		Expect(layers.Find(&models.ASTNode{PackageName: "repository"}).Name).To(Equal("repo"))
+		Expect(layers.Find(&models.ASTNode{PackageName: "util"})).To(BeNil())
+	})
+
+	It("allows dependencies within a layer and to allowed layers", func() {
+		handler, service, repo := &layers[0], &layers[1], &layers[2]
+		Expect(handler.Allows(handler)).To(BeTrue())
+		Expect(handler.Allows(service)).To(BeTrue())
+		Expect(handler.Allows(repo)).To(BeFalse())
+		Expect(service.Allows(repo)).To(BeTrue())
+		Expect(repo.Allows(service)).To(BeFalse())
+	})
+
+	DescribeTable("validation",
+		func(layers models.Layers, expectedError string) {
+			err := layers.Validate()
+			if expectedError == "" {
+				Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("valid", layers, ""),
+		Entry("no layers", models.Layers(nil), ""),
+		Entry("missing name", models.Layers{{Packages: []string{"a"}}}, "layer name is required"),
+		Entry("duplicate name", models.Layers{{Name: "a", Packages: []string{"a"}}, {Name: "a", Packages: []string{"b"}}}, "duplicate layer 'a'"),
+		Entry("missing packages", models.Layers{{Name: "a"}}, "layer 'a' must define at least one package pattern"),
+		Entry("unknown allowed layer", models.Layers{{Name: "a", Packages: []string{"a"}, Allow: []string{"b"}}}, "layer 'a' allows unknown layer 'b'"),
+	)
+})
@@ -0,0 +1,33 @@
+package models
+
+// QueryMatch represents a single node matched by a named query
+type QueryMatch struct {
+	File    string `json:"file" pretty:"label=File,style=text-blue-500"`
+	Line    int    `json:"line" pretty:"label=Line"`
+	Name    string `json:"name" pretty:"label=Name"`
+	Message string `json:"message,omitempty" pretty:"label=Message,omitempty"`
+}
+
+// NamedQueryResult contains the results of running a named query
+type NamedQueryResult struct {
+	Name        string       `json:"name" pretty:"label=Query,style=text-purple-600"`
+	Description string       `json:"description,omitempty" pretty:"label=Description,omitempty"`
+	Query       string       `json:"query" pretty:"label=Expression"`
+	Count       int          `json:"count" pretty:"label=Count"`
+	Matches     []QueryMatch `json:"matches,omitempty" pretty:"label=Matches,omitempty"`
+}
+
+// QueryReport is a saved report composed of the results of several named queries
+type QueryReport struct {
+	Name    string             `json:"name" pretty:"label=Report,style=text-blue-600 font-bold"`
+	Title   string             `json:"title,omitempty" pretty:"label=Title,omitempty"`
+	Results []NamedQueryResult `json:"results" pretty:"label=Results"`
+}
+
+// Expression returns the AQL or pattern expression of the query
+func (q *NamedQuery) Expression() string {
+	if q.AQL != "" {
+		return q.AQL
+	}
+	return q.Pattern
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Naming kinds besides node types, matched against the kind metadata of type nodes, or against
+// the names of the files and packages of nodes
+const (
+	NamingKindFile      = "file"
+	NamingKindInterface = "interface"
+	NamingKindStruct    = "struct"
+	NamingKindClass     = "class"
+	NamingKindEnum      = "enum"
+)
+
+// namingNodeTypes maps the node kinds of naming rules to node types
+var namingNodeTypes = map[string]NodeType{
+	NodeTypePackage:  NodeTypePackage,
+	NodeTypeType:     NodeTypeType,
+	NodeTypeMethod:   NodeTypeMethod,
+	NodeTypeField:    NodeTypeField,
+	NodeTypeVariable: NodeTypeVariable,
+	"table":          NodeTypeTypeTable,
+	"view":           NodeTypeTypeView,
+	"column":         NodeTypeFieldColumn,
+	"procedure":      NodeTypeMethodStoredProc,
+	"function":       NodeTypeMethodFunction,
+}
+
+// NamingRule enforces a naming convention on the nodes of a kind, e.g. interfaces ending with er
+// or snake_case SQL tables
+type NamingRule struct {
+	Name    string `yaml:"name,omitempty"`   // Reported with violations, defaults to "<kind> naming"
+	Kind    string `yaml:"kind"`             // file, package, type, interface, struct, class, enum, method, field, variable, table, view, column, procedure or function
+	Pattern string `yaml:"pattern"`          // Regular expression names must match, e.g. ^[a-z][a-z0-9_]*$
+	Path    string `yaml:"path,omitempty"`   // Doublestar glob of the files the rule applies to, e.g. tests/**
+	Reason  string `yaml:"reason,omitempty"` // Explanation appended to violations
+
+	regexp *regexp.Regexp
+}
+
+// Validate checks the kind and pattern of the rule
+func (r *NamingRule) Validate() error {
+	switch r.Kind {
+	case NamingKindFile, NamingKindInterface, NamingKindStruct, NamingKindClass, NamingKindEnum:
+	default:
+		if _, ok := namingNodeTypes[r.Kind]; !ok {
+			return fmt.Errorf("invalid kind '%s', expected one of file, package, type, interface, struct, class, enum, method, field, variable, table, view, column, procedure or function", r.Kind)
+		}
+	}
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if _, err := r.compile(); err != nil {
+		return fmt.Errorf("invalid pattern '%s': %w", r.Pattern, err)
+	}
+	return nil
+}
+
+// RuleName returns the name of the rule, or one derived from its kind
+func (r *NamingRule) RuleName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Kind + " naming"
+}
+
+// NameOf returns the name of a node checked by the rule, false when the rule does not apply to the
+// node. Files and packages are named by every node they contain, callers check each name once.
+func (r *NamingRule) NameOf(node *ASTNode) (string, bool) {
+	if r.Path != "" && !matchesFilePath(node.FilePath, r.Path) {
+		return "", false
+	}
+
+	switch r.Kind {
+	case NamingKindFile:
+		return filepath.Base(node.FilePath), node.FilePath != ""
+	case NodeTypePackage:
+		return node.PackageName, node.PackageName != ""
+	case NamingKindInterface, NamingKindStruct, NamingKindClass, NamingKindEnum:
+		return node.TypeName, node.NodeType == NodeTypeType && node.Metatdata["kind"] == r.Kind
+	}
+
+	nodeType := namingNodeTypes[r.Kind]
+	if node.NodeType != nodeType {
+		return "", false
+	}
+	switch {
+	case strings.HasPrefix(nodeType, NodeTypeType):
+		return node.TypeName, node.TypeName != ""
+	case strings.HasPrefix(nodeType, NodeTypeMethod):
+		return node.MethodName, node.MethodName != ""
+	default:
+		return node.FieldName, node.FieldName != ""
+	}
+}
+
+// Matches returns true if a name follows the convention of the rule
+func (r *NamingRule) Matches(name string) (bool, error) {
+	re, err := r.compile()
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern '%s' of naming rule %s: %w", r.Pattern, r.RuleName(), err)
+	}
+	return re.MatchString(name), nil
+}
+
+// compile returns the compiled pattern of the rule, compiling it on first use
+func (r *NamingRule) compile() (*regexp.Regexp, error) {
+	if r.regexp != nil {
+		return r.regexp, nil
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	r.regexp = re
+	return re, nil
+}
@@ -0,0 +1,55 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("NamingRule", func() {
+	store := &models.ASTNode{
+		FilePath:    "/repo/store/store.go",
+		PackageName: "store",
+		TypeName:    "Store",
+		NodeType:    models.NodeTypeType,
+		Metatdata:   map[string]string{"kind": "interface"},
+	}
+	users := &models.ASTNode{FilePath: "/repo/schema.sql", PackageName: "public", TypeName: "UserAccounts", NodeType: models.NodeTypeTypeTable}
+	save := &models.ASTNode{FilePath: "/repo/store/store.go", PackageName: "store", TypeName: "Store", MethodName: "Save", NodeType: models.NodeTypeMethod}
+
+	DescribeTable("naming nodes by kind",
+		func(rule models.NamingRule, node *models.ASTNode, expectedName string, applies bool) {
+			name, ok := rule.NameOf(node)
+			Expect(ok).To(Equal(applies))
+			Expect(name).To(Equal(expectedName))
+		},
+		Entry("interface", models.NamingRule{Kind: "interface"}, store, "Store", true),
+		Entry("struct", models.NamingRule{Kind: "struct"}, store, "Store", false),
+		Entry("type", models.NamingRule{Kind: "type"}, store, "Store", true),
+		Entry("table", models.NamingRule{Kind: "table"}, users, "UserAccounts", true),
+		Entry("method", models.NamingRule{Kind: "method"}, save, "Save", true),
+		Entry("method of a type", models.NamingRule{Kind: "method"}, store, "", false),
+		Entry("file", models.NamingRule{Kind: "file"}, save, "store.go", true),
+		Entry("package", models.NamingRule{Kind: "package"}, users, "public", true),
+		Entry("file in path", models.NamingRule{Kind: "file", Path: "**/store/**"}, save, "store.go", true),
+		Entry("file outside path", models.NamingRule{Kind: "file", Path: "**/tests/**"}, save, "", false),
+	)
+
+	It("matches names against the pattern", func() {
+		rule := models.NamingRule{Kind: "interface", Pattern: "er$"}
+		Expect(rule.Validate()).To(Succeed())
+		Expect(rule.Matches("Reader")).To(BeTrue())
+		Expect(rule.Matches("Store")).To(BeFalse())
+		Expect(rule.RuleName()).To(Equal("interface naming"))
+	})
+
+	DescribeTable("validation",
+		func(rule models.NamingRule, expectedError string) {
+			Expect(rule.Validate()).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("unknown kind", models.NamingRule{Kind: "module", Pattern: "x"}, "invalid kind 'module'"),
+		Entry("missing pattern", models.NamingRule{Kind: "table"}, "pattern is required"),
+		Entry("invalid pattern", models.NamingRule{Kind: "table", Pattern: "("}, "invalid pattern '('"),
+	)
+})
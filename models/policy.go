@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultPolicyQuery is the Rego query evaluated for policies that do not configure one
+const DefaultPolicyQuery = "data.archunit.deny"
+
+// PolicyConfig is an OPA/Rego policy evaluated by the opa CLI against the AST graph. The
+// query of the policy returns a violation per offending node, either a message or an object
+// with a msg and optionally the file, line and severity of the violation.
+type PolicyConfig struct {
+	Name     string   `yaml:"name,omitempty"`     // Reported with violations, defaults to the file of the policy
+	File     string   `yaml:"file,omitempty"`     // Path to a .rego file or a directory of them
+	Inline   string   `yaml:"inline,omitempty"`   // Inline Rego policy
+	Query    string   `yaml:"query,omitempty"`    // Rego query returning the violations, data.archunit.deny when empty
+	Severity Severity `yaml:"severity,omitempty"` // Severity of the violations that do not declare one, error when empty
+}
+
+// Validate checks that the policy has either a file or inline Rego, and its severity
+func (p *PolicyConfig) Validate() error {
+	if p.File == "" && p.Inline == "" {
+		return fmt.Errorf("policy must define either 'file' or 'inline'")
+	}
+	if p.File != "" && p.Inline != "" {
+		return fmt.Errorf("policy cannot define both 'file' and 'inline'")
+	}
+	if p.Severity != "" {
+		if _, err := ParseSeverity(string(p.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PolicyName returns the name of the policy, or its file
+func (p *PolicyConfig) PolicyName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	if p.File != "" {
+		return p.File
+	}
+	return "inline policy"
+}
+
+// GetQuery returns the Rego query of the policy
+func (p *PolicyConfig) GetQuery() string {
+	if p.Query != "" {
+		return p.Query
+	}
+	return DefaultPolicyQuery
+}
+
+// PolicyInput is the input document of policies: every node with the fields of
+// ASTNode.AsMap, the relationships between nodes, and the calls and imports of library nodes
+type PolicyInput struct {
+	Nodes         []map[string]interface{} `json:"nodes"`
+	Relationships []*ASTRelationship       `json:"relationships"`
+	Dependencies  []*LibraryRelationship   `json:"dependencies"`
+}
+
+// PolicyViolation is a violation returned by a policy
+type PolicyViolation struct {
+	Message  string   `json:"msg"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+}
+
+// UnmarshalJSON accepts either a message or an object, with the message as msg or message
+func (v *PolicyViolation) UnmarshalJSON(data []byte) error {
+	var message string
+	if err := json.Unmarshal(data, &message); err == nil {
+		v.Message = message
+		return nil
+	}
+
+	type plain PolicyViolation
+	var object struct {
+		plain
+		AltMessage string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("expected a message or an object with a msg, got %s", data)
+	}
+	*v = PolicyViolation(object.plain)
+	if v.Message == "" {
+		v.Message = object.AltMessage
+	}
+	return nil
+}
+
+// ParsePolicyResult returns the violations of the output of opa eval --format json, an undefined
+// query returns no violations
+func ParsePolicyResult(output []byte) ([]PolicyViolation, error) {
+	var result struct {
+		Result []struct {
+			Expressions []struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse opa output: %w", err)
+	}
+
+	var violations []PolicyViolation
+	for _, r := range result.Result {
+		for _, expression := range r.Expressions {
+			var values []PolicyViolation
+			if err := json.Unmarshal(expression.Value, &values); err != nil {
+				return nil, fmt.Errorf("query must return a set of violations: %w", err)
+			}
+			violations = append(violations, values...)
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("PolicyConfig", func() {
+	DescribeTable("parsing opa eval output",
+		func(output string, expected []models.PolicyViolation) {
+			violations, err := models.ParsePolicyResult([]byte(output))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(Equal(expected))
+		},
+		Entry("messages",
+			`{"result":[{"expressions":[{"value":["service calls controller"],"text":"data.archunit.deny"}]}]}`,
+			[]models.PolicyViolation{{Message: "service calls controller"}}),
+		Entry("objects",
+			`{"result":[{"expressions":[{"value":[{"msg":"hardcoded secret","file":"/repo/config.go","line":12,"severity":"warning"},{"message":"no tests"}]}]}]}`,
+			[]models.PolicyViolation{
+				{Message: "hardcoded secret", File: "/repo/config.go", Line: 12, Severity: models.SeverityWarning},
+				{Message: "no tests"},
+			}),
+		Entry("undefined query", `{}`, nil),
+	)
+
+	It("rejects queries that do not return a set", func() {
+		_, err := models.ParsePolicyResult([]byte(`{"result":[{"expressions":[{"value":true}]}]}`))
+		Expect(err).To(MatchError(ContainSubstring("query must return a set of violations")))
+	})
+
+	DescribeTable("validation",
+		func(policy models.PolicyConfig, expectedError string) {
+			Expect(policy.Validate()).To(MatchError(ContainSubstring(expectedError)))
+		},
+		Entry("missing rego", models.PolicyConfig{Name: "x"}, "either 'file' or 'inline'"),
+		Entry("file and inline", models.PolicyConfig{File: "a.rego", Inline: "package archunit"}, "cannot define both"),
+		Entry("invalid severity", models.PolicyConfig{File: "a.rego", Severity: "fatal"}, "invalid severity 'fatal'"),
+	)
+
+	It("defaults the query and name", func() {
+		policy := models.PolicyConfig{File: "policies/secrets.rego"}
+		Expect(policy.Validate()).To(Succeed())
+		Expect(policy.GetQuery()).To(Equal("data.archunit.deny"))
+		Expect(policy.PolicyName()).To(Equal("policies/secrets.rego"))
+	})
+})
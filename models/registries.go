@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Registry types, the kind of service a RegistryConfig authenticates against
+const (
+	RegistryTypeNpm    = "npm"
+	RegistryTypeHelm   = "helm"
+	RegistryTypeGitHub = "github" // github.com or a GitHub Enterprise host
+	RegistryTypeGitLab = "gitlab" // gitlab.com or a self-managed GitLab host
+)
+
+// RegistryConfig configures the credentials of a private package registry or Git host, used to
+// resolve the Git repositories of dependencies and to download Helm subcharts. Credentials may
+// reference environment variables, e.g. token: $NPM_TOKEN, to keep secrets out of arch-unit.yaml.
+type RegistryConfig struct {
+	URL      string   `yaml:"url"`                // e.g. https://npm.pkg.github.com, https://github.example.com
+	Type     string   `yaml:"type,omitempty"`     // npm, helm, github or gitlab
+	Scopes   []string `yaml:"scopes,omitempty"`   // npm scopes served by the registry, e.g. @flanksource
+	Token    string   `yaml:"token,omitempty"`    // Sent as a bearer token
+	Username string   `yaml:"username,omitempty"` // Sent with password as basic auth
+	Password string   `yaml:"password,omitempty"`
+}
+
+// Validate checks the URL, type and scopes of the registry
+func (r RegistryConfig) Validate() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid registry url '%s', expected an http(s) URL such as https://npm.example.com", r.URL)
+	}
+	switch r.Type {
+	case "", RegistryTypeNpm, RegistryTypeHelm, RegistryTypeGitHub, RegistryTypeGitLab:
+	default:
+		return fmt.Errorf("invalid type '%s' of registry %s, expected one of npm, helm, github or gitlab", r.Type, r.URL)
+	}
+	if len(r.Scopes) > 0 && r.Type != RegistryTypeNpm {
+		return fmt.Errorf("scopes of registry %s require type npm", r.URL)
+	}
+	for _, scope := range r.Scopes {
+		if !strings.HasPrefix(scope, "@") || len(scope) == 1 || strings.Contains(scope, "/") {
+			return fmt.Errorf("invalid npm scope '%s' of registry %s, expected a scope such as @flanksource", scope, r.URL)
+		}
+	}
+	if r.Token != "" && (r.Username != "" || r.Password != "") {
+		return fmt.Errorf("registry %s cannot define both a token and a username or password", r.URL)
+	}
+	return nil
+}
+
+// Credentials returns the token, username and password of the registry with environment variables
+// expanded
+func (r RegistryConfig) Credentials() (token, username, password string) {
+	return os.ExpandEnv(r.Token), os.ExpandEnv(r.Username), os.ExpandEnv(r.Password)
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResourceLimits bounds the resources available to a spawned linter or extractor helper
+type ResourceLimits struct {
+	Timeout string `yaml:"timeout,omitempty"`  // Wall-clock limit, e.g. "5m"
+	CPUTime string `yaml:"cpu_time,omitempty"` // CPU time limit, e.g. "2m"
+	Memory  string `yaml:"memory,omitempty"`   // Virtual memory limit, e.g. "2G"
+}
+
+// LimitsConfig holds default resource limits with per-tool overrides keyed by executable name
+type LimitsConfig struct {
+	ResourceLimits `yaml:",inline"`
+	Tools          map[string]ResourceLimits `yaml:"tools,omitempty"`
+}
+
+// IsZero returns true when no limit is set
+func (r ResourceLimits) IsZero() bool {
+	return r.Timeout == "" && r.CPUTime == "" && r.Memory == ""
+}
+
+// Merge returns a copy of the limits with any values set in override taking precedence
+func (r ResourceLimits) Merge(override *ResourceLimits) ResourceLimits {
+	if override == nil {
+		return r
+	}
+	if override.Timeout != "" {
+		r.Timeout = override.Timeout
+	}
+	if override.CPUTime != "" {
+		r.CPUTime = override.CPUTime
+	}
+	if override.Memory != "" {
+		r.Memory = override.Memory
+	}
+	return r
+}
+
+// Validate checks that all limits can be parsed
+func (r ResourceLimits) Validate() error {
+	if _, err := r.TimeoutDuration(); err != nil {
+		return fmt.Errorf("invalid timeout '%s': %w", r.Timeout, err)
+	}
+	if _, err := r.CPUSeconds(); err != nil {
+		return fmt.Errorf("invalid cpu_time '%s': %w", r.CPUTime, err)
+	}
+	if _, err := r.MemoryBytes(); err != nil {
+		return fmt.Errorf("invalid memory '%s': %w", r.Memory, err)
+	}
+	return nil
+}
+
+// TimeoutDuration returns the wall-clock limit, or 0 when unset
+func (r ResourceLimits) TimeoutDuration() (time.Duration, error) {
+	if r.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(r.Timeout)
+}
+
+// CPUSeconds returns the CPU time limit rounded up to whole seconds, or 0 when unset
+func (r ResourceLimits) CPUSeconds() (int, error) {
+	if r.CPUTime == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(r.CPUTime)
+	if err != nil {
+		return 0, err
+	}
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 0, fmt.Errorf("must be at least 1s")
+	}
+	return seconds, nil
+}
+
+// MemoryBytes returns the memory limit in bytes, or 0 when unset
+func (r ResourceLimits) MemoryBytes() (int64, error) {
+	if r.Memory == "" {
+		return 0, nil
+	}
+	return ParseByteSize(r.Memory)
+}
+
+// ParseByteSize parses sizes such as "512M", "2G" or "1.5Gi" (binary units) into bytes
+func ParseByteSize(s string) (int64, error) {
+	value := strings.TrimSpace(strings.ToUpper(s))
+	value = strings.TrimSuffix(value, "B")
+	value = strings.TrimSuffix(value, "I")
+
+	multiplier := int64(1)
+	if value != "" {
+		switch value[len(value)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			value = value[:len(value)-1]
+		}
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected a positive size such as 512M or 2G")
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// GetLimits returns the linter-level limits, or no limits when unset
+func (l *LinterConfig) GetLimits() ResourceLimits {
+	if l.Limits == nil {
+		return ResourceLimits{}
+	}
+	return *l.Limits
+}
+
+// GetResourceLimits returns the effective limits for a tool, applying the global defaults,
+// then the per-tool override and finally the linter-level limits
+func (c *Config) GetResourceLimits(tool string, linterLimits *ResourceLimits) ResourceLimits {
+	var limits ResourceLimits
+	if c != nil && c.Limits != nil {
+		limits = c.Limits.ResourceLimits
+		if override, ok := c.Limits.Tools[tool]; ok {
+			limits = limits.Merge(&override)
+		}
+	}
+	return limits.Merge(linterLimits)
+}
@@ -0,0 +1,56 @@
+package models_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("ResourceLimits", func() {
+	It("layers global, per-tool and linter limits", func() {
+		config := &models.Config{
+			Limits: &models.LimitsConfig{
+				ResourceLimits: models.ResourceLimits{Timeout: "5m", Memory: "1G"},
+				Tools: map[string]models.ResourceLimits{
+					"golangci-lint": {Memory: "4G"},
+				},
+			},
+		}
+
+		limits := config.GetResourceLimits("golangci-lint", &models.ResourceLimits{Timeout: "10m"})
+		Expect(limits).To(Equal(models.ResourceLimits{Timeout: "10m", Memory: "4G"}))
+
+		Expect(config.GetResourceLimits("ruff", nil)).To(Equal(models.ResourceLimits{Timeout: "5m", Memory: "1G"}))
+	})
+
+	It("returns no limits without configuration", func() {
+		var config *models.Config
+		Expect(config.GetResourceLimits("node", nil).IsZero()).To(BeTrue())
+	})
+
+	It("parses durations and sizes", func() {
+		limits := models.ResourceLimits{Timeout: "90s", CPUTime: "1500ms", Memory: "512Mi"}
+		Expect(limits.Validate()).To(Succeed())
+
+		timeout, _ := limits.TimeoutDuration()
+		Expect(timeout).To(Equal(90 * time.Second))
+		seconds, _ := limits.CPUSeconds()
+		Expect(seconds).To(Equal(2))
+		bytes, _ := limits.MemoryBytes()
+		Expect(bytes).To(Equal(int64(512 << 20)))
+	})
+
+	DescribeTable("rejecting invalid limits",
+		func(limits models.ResourceLimits, message string) {
+			err := limits.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(message))
+		},
+		Entry("bad timeout", models.ResourceLimits{Timeout: "soon"}, "invalid timeout"),
+		Entry("bad memory", models.ResourceLimits{Memory: "lots"}, "invalid memory"),
+		Entry("zero cpu time", models.ResourceLimits{CPUTime: "0s"}, "invalid cpu_time"),
+	)
+})
@@ -31,6 +31,7 @@ type Rule struct {
 	Scope        string   `json:"scope,omitempty"` // Directory where this rule applies
 	OriginalLine string   `json:"original_line,omitempty"`
 	FilePattern  string   `json:"file_pattern,omitempty"` // File-specific pattern (e.g., "*_test.go", "cmd/*/main.go")
+	Severity     Severity `json:"severity,omitempty"`     // Severity of violations, errors when empty
 
 	// Quality rule parameters
 	MaxFileLines        int      `yaml:"max_file_lines,omitempty" json:"max_file_lines,omitempty"`
@@ -172,6 +173,48 @@ func matchesPattern(text, pattern string) bool {
 	return text == pattern || strings.HasPrefix(text, pattern+"/") || strings.HasPrefix(text, pattern+".")
 }
 
+// DependencyRulePrefix selects scanned dependencies of any type, e.g. !dep:github.com/pkg/errors
+const DependencyRulePrefix = "dep"
+
+// Dependency returns the type, name and version patterns of a rule on scanned dependencies, such
+// as !dep:github.com/pkg/errors or !docker:*:latest, ok is false for rules on imports and calls
+func (r Rule) Dependency() (depType DependencyType, name, version string, ok bool) {
+	if r.Package != DependencyRulePrefix && !IsDependencyType(r.Package) {
+		return "", "", "", false
+	}
+	if r.Package != DependencyRulePrefix {
+		depType = DependencyType(r.Package)
+	}
+	name = r.Method
+	// The version follows the last colon, unless it is the port of a registry, e.g. localhost:5000/app
+	if i := strings.LastIndex(name, ":"); i > 0 && !strings.Contains(name[i+1:], "/") {
+		name, version = name[:i], name[i+1:]
+	}
+	return depType, name, version, true
+}
+
+// IsDependencyRule returns true if the rule applies to scanned dependencies instead of imports
+func (r Rule) IsDependencyRule() bool {
+	_, _, _, ok := r.Dependency()
+	return ok
+}
+
+// MatchesDependency returns true if a dependency rule matches the type, name and version of a dependency
+func (r Rule) MatchesDependency(dep *Dependency) bool {
+	depType, name, version, ok := r.Dependency()
+	if !ok {
+		return false
+	}
+	if depType != "" && depType != dep.Type {
+		return false
+	}
+	if !matchesPattern(dep.Name, name) {
+		return false
+	}
+	return version == "" || matchesPattern(dep.Version, version) ||
+		(dep.ResolvedFrom != "" && matchesPattern(dep.ResolvedFrom, version))
+}
+
 type RuleSet struct {
 	Rules []Rule
 	Path  string
@@ -183,6 +226,9 @@ func (rs *RuleSet) IsAllowed(pkg, method string) (bool, *Rule) {
 
 	for i := range rs.Rules {
 		rule := &rs.Rules[i]
+		if rule.IsDependencyRule() {
+			continue
+		}
 		if rule.Matches(pkg, method) {
 			lastMatchingRule = rule
 			switch rule.Type {
@@ -208,7 +254,7 @@ func (rs *RuleSet) IsAllowedForFile(pkg, method, filePath string) (bool, *Rule)
 	for i := range rs.Rules {
 		rule := &rs.Rules[i]
 		// Check if rule applies to this specific file
-		if !rule.AppliesToFile(filePath) {
+		if !rule.AppliesToFile(filePath) || rule.IsDependencyRule() {
 			continue
 		}
 
@@ -230,6 +276,43 @@ func (rs *RuleSet) IsAllowedForFile(pkg, method, filePath string) (bool, *Rule)
 	return true, nil
 }
 
+// IsDependencyAllowed checks a dependency declared in filePath against the dependency rules of
+// the set, the last matching rule deciding like for imports
+func (rs *RuleSet) IsDependencyAllowed(dep *Dependency, filePath string) (bool, *Rule) {
+	var lastMatchingRule *Rule
+	allowed := true
+
+	for i := range rs.Rules {
+		rule := &rs.Rules[i]
+		if !rule.AppliesToFile(filePath) || !rule.MatchesDependency(dep) {
+			continue
+		}
+		lastMatchingRule = rule
+		switch rule.Type {
+		case RuleTypeDeny:
+			allowed = false
+		case RuleTypeAllow, RuleTypeOverride:
+			allowed = true
+		}
+	}
+
+	if !allowed && lastMatchingRule != nil {
+		return false, lastMatchingRule
+	}
+
+	return true, nil
+}
+
+// HasDependencyRules returns true if any rule of the set applies to scanned dependencies
+func (rs *RuleSet) HasDependencyRules() bool {
+	for _, rule := range rs.Rules {
+		if rule.IsDependencyRule() {
+			return true
+		}
+	}
+	return false
+}
+
 // QualityRule represents a quality-specific rule with validation methods
 type QualityRule struct {
 	Rule
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RuleProposal is a rule proposed by `arch-unit ask` from a natural language request: either an
+// AQL rule, or import rules for the files matching a path pattern, which also cover third-party
+// packages that AQL relationships do not record
+type RuleProposal struct {
+	AQL         string   `json:"aql,omitempty"`         // AQL rule, appended to aql_rules
+	Path        string   `json:"path,omitempty"`        // Files the import rules apply to, e.g. **/controllers/**
+	Imports     []string `json:"imports,omitempty"`     // Import rules of rules.<path>, e.g. !gorm.io/gorm
+	Explanation string   `json:"explanation,omitempty"` // Why the rule implements the request
+}
+
+// ParseRuleProposal reads the JSON object of a proposal from an LLM completion, ignoring any
+// markdown fences or text around it
+func ParseRuleProposal(completion string) (*RuleProposal, error) {
+	start := strings.Index(completion, "{")
+	end := strings.LastIndex(completion, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in the response")
+	}
+	var proposal RuleProposal
+	if err := json.Unmarshal([]byte(completion[start:end+1]), &proposal); err != nil {
+		return nil, fmt.Errorf("invalid JSON in the response: %w", err)
+	}
+	proposal.AQL = strings.TrimSpace(proposal.AQL)
+	proposal.Path = strings.TrimSpace(proposal.Path)
+
+	switch {
+	case proposal.AQL != "" && len(proposal.Imports) > 0:
+		return nil, fmt.Errorf("the response has both an AQL rule and import rules, expected one of them")
+	case proposal.AQL == "" && len(proposal.Imports) == 0:
+		return nil, fmt.Errorf("the response has neither an AQL rule nor import rules")
+	case len(proposal.Imports) > 0 && proposal.Path == "":
+		return nil, fmt.Errorf("the import rules have no path")
+	}
+	for _, rule := range proposal.Imports {
+		if strings.TrimSpace(rule) == "" {
+			return nil, fmt.Errorf("the response has an empty import rule")
+		}
+	}
+	return &proposal, nil
+}
+
+// IsAQL returns true when the proposal is an AQL rule rather than import rules
+func (p *RuleProposal) IsAQL() bool {
+	return p.AQL != ""
+}
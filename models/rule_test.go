@@ -62,3 +62,48 @@ var _ = Describe("RuleSet.IsAllowed", func() {
 			"fmt", "Println", false, true),
 	)
 })
+
+var _ = Describe("Dependency rules", func() {
+	It("should parse the type, name and version of dependency rules", func() {
+		depType, name, version, ok := models.Rule{Package: "docker", Method: "*:latest"}.Dependency()
+		Expect(ok).To(BeTrue())
+		Expect(depType).To(Equal(models.DependencyTypeDocker))
+		Expect(name).To(Equal("*"))
+		Expect(version).To(Equal("latest"))
+
+		depType, name, version, ok = models.Rule{Package: "dep", Method: "localhost:5000/app"}.Dependency()
+		Expect(ok).To(BeTrue())
+		Expect(depType).To(BeEmpty())
+		Expect(name).To(Equal("localhost:5000/app"))
+		Expect(version).To(BeEmpty())
+
+		_, _, _, ok = models.Rule{Package: "fmt", Method: "Println"}.Dependency()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should match dependencies by type, name and version", func() {
+		errors := &models.Dependency{Name: "github.com/pkg/errors", Version: "v0.9.1", Type: models.DependencyTypeGo}
+		nginx := &models.Dependency{Name: "nginx", Version: "latest", Type: models.DependencyTypeDocker}
+
+		Expect(models.Rule{Package: "dep", Method: "github.com/pkg/errors"}.MatchesDependency(errors)).To(BeTrue())
+		Expect(models.Rule{Package: "npm", Method: "github.com/pkg/errors"}.MatchesDependency(errors)).To(BeFalse())
+		Expect(models.Rule{Package: "docker", Method: "*:latest"}.MatchesDependency(nginx)).To(BeTrue())
+		Expect(models.Rule{Package: "docker", Method: "*:1.*"}.MatchesDependency(nginx)).To(BeFalse())
+	})
+
+	It("should let the last matching rule decide and leave imports alone", func() {
+		rules := models.RuleSet{Rules: []models.Rule{
+			{Type: models.RuleTypeDeny, Package: "dep", Method: "github.com/pkg/*"},
+			{Type: models.RuleTypeOverride, Package: "dep", Method: "github.com/pkg/sftp"},
+		}}
+
+		allowed, rule := rules.IsDependencyAllowed(&models.Dependency{Name: "github.com/pkg/errors", Type: models.DependencyTypeGo}, "go.mod")
+		Expect(allowed).To(BeFalse())
+		Expect(rule.Method).To(Equal("github.com/pkg/*"))
+		allowed, _ = rules.IsDependencyAllowed(&models.Dependency{Name: "github.com/pkg/sftp", Type: models.DependencyTypeGo}, "go.mod")
+		Expect(allowed).To(BeTrue())
+
+		allowed, _ = rules.IsAllowed("dep", "github.com/pkg/errors")
+		Expect(allowed).To(BeTrue())
+	})
+})
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SchemaKindIndex is the kind metadata of the method nodes of database indexes, whose parameters
+// are the columns of the index
+const SchemaKindIndex = "index"
+
+// SchemaConfig enables the built-in conventions of the SQL schemas extracted by arch-unit ast
+// analyze sql, reported at their sql:// virtual paths, and of the raw SQL queries extracted from code
+type SchemaConfig struct {
+	TableNaming      string   `yaml:"table_naming,omitempty"`       // Regular expression table names must match, e.g. ^[a-z][a-z0-9_]*$
+	ColumnNaming     string   `yaml:"column_naming,omitempty"`      // Regular expression column names must match
+	IndexForeignKeys bool     `yaml:"index_foreign_keys,omitempty"` // Every foreign key column leads an index of its table
+	ForbidSelectStar bool     `yaml:"forbid_select_star,omitempty"` // Raw SQL queries list the columns they select
+	Severity         Severity `yaml:"severity,omitempty"`           // Severity of violations, error when empty
+}
+
+// Validate checks the naming patterns and severity of the schema rules
+func (s *SchemaConfig) Validate() error {
+	for _, pattern := range []string{s.TableNaming, s.ColumnNaming} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid naming pattern '%s': %w", pattern, err)
+		}
+	}
+	if s.Severity != "" {
+		if _, err := ParseSeverity(string(s.Severity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsEnabled returns true if any schema rule is configured
+func (s *SchemaConfig) IsEnabled() bool {
+	return s != nil && (s.TableNaming != "" || s.ColumnNaming != "" || s.IndexForeignKeys || s.ForbidSelectStar)
+}
+
+// NamingRules returns the table and column naming conventions as naming rules
+func (s *SchemaConfig) NamingRules() []NamingRule {
+	var rules []NamingRule
+	if s.TableNaming != "" {
+		rules = append(rules, NamingRule{Name: "schema table naming", Kind: "table", Pattern: s.TableNaming})
+	}
+	if s.ColumnNaming != "" {
+		rules = append(rules, NamingRule{Name: "schema column naming", Kind: "column", Pattern: s.ColumnNaming})
+	}
+	return rules
+}
+
+// GetSeverity returns the severity of schema violations
+func (s *SchemaConfig) GetSeverity() Severity {
+	if s.Severity == "" {
+		return SeverityError
+	}
+	return s.Severity
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// SuggestionWordLimit is the maximum number of words of a remediation suggestion
+const SuggestionWordLimit = 40
+
+// ViolationSuggestion is an AI remediation suggestion for a violation, cached by the hash of the
+// model and the prompt it answers, which includes the rule and the source around the violation,
+// so unchanged violations are not explained again on every check
+type ViolationSuggestion struct {
+	PromptHash string    `json:"prompt_hash" gorm:"column:prompt_hash;primaryKey"`
+	Suggestion string    `json:"suggestion" gorm:"column:suggestion;not null"`
+	Model      string    `json:"model" gorm:"column:model"` // Provider and model that wrote the suggestion
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for ViolationSuggestion
+func (ViolationSuggestion) TableName() string {
+	return "violation_suggestions"
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// NodeSummary is the AI summary of a node, cached by the hash of its file so that unchanged files
+// are not summarized again when they are re-analyzed
+type NodeSummary struct {
+	FileHash  string    `json:"file_hash" gorm:"column:file_hash;primaryKey"`
+	NodeKey   string    `json:"node_key" gorm:"column:node_key;primaryKey"` // ASTNode.String() of the node
+	Summary   string    `json:"summary" gorm:"column:summary;not null"`
+	Model     string    `json:"model" gorm:"column:model"` // Provider and model that wrote the summary
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for NodeSummary
+func (NodeSummary) TableName() string {
+	return "node_summaries"
+}
+
+// SummaryWordLimit returns the maximum number of words of the summary of a node: 5 for fields and
+// variables, 20 for methods and 50 for types, 0 for nodes that are not summarized
+func SummaryWordLimit(nodeType NodeType) int {
+	switch {
+	case nodeType == NodeTypeField || nodeType == NodeTypeVariable || strings.HasPrefix(string(nodeType), "field_"):
+		return 5
+	case nodeType == NodeTypeMethod || strings.HasPrefix(string(nodeType), "method_"):
+		return 20
+	case nodeType == NodeTypeType || strings.HasPrefix(string(nodeType), "type_"):
+		return 50
+	}
+	return 0
+}
+
+// LimitWords returns the first line of text without surrounding quotes, cut to at most limit words
+func LimitWords(text string, limit int) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	text = strings.Trim(text, "\"'` ")
+	words := strings.Fields(text)
+	if len(words) > limit {
+		words = words[:limit]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var templateParamRegex = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// RuleTemplate is AQL text with ${param} placeholders, defined once in arch-unit.yaml and
+// instantiated by AQL rule configs with different arguments, e.g. a template forbidding
+// ${from} -> ${to} used for several pairs of packages
+type RuleTemplate struct {
+	Description string   `yaml:"description,omitempty"`
+	Params      []string `yaml:"params,omitempty"`
+	AQL         string   `yaml:"aql"`
+}
+
+// Validate checks that a template has AQL text and declares every parameter it uses
+func (t *RuleTemplate) Validate() error {
+	if strings.TrimSpace(t.AQL) == "" {
+		return fmt.Errorf("aql is required")
+	}
+	for _, match := range templateParamRegex.FindAllStringSubmatch(t.AQL, -1) {
+		if !slices.Contains(t.Params, match[1]) {
+			return fmt.Errorf("undeclared parameter '%s'", match[1])
+		}
+	}
+	return nil
+}
+
+// Instantiate returns the AQL text of a template with its parameters replaced by args, each
+// parameter must be given exactly once
+func (t *RuleTemplate) Instantiate(args map[string]string) (string, error) {
+	for _, param := range t.Params {
+		if _, ok := args[param]; !ok {
+			return "", fmt.Errorf("missing parameter '%s'", param)
+		}
+	}
+	for name := range args {
+		if !slices.Contains(t.Params, name) {
+			return "", fmt.Errorf("unknown parameter '%s'", name)
+		}
+	}
+	return templateParamRegex.ReplaceAllStringFunc(t.AQL, func(match string) string {
+		return args[templateParamRegex.FindStringSubmatch(match)[1]]
+	}), nil
+}
+
+// ExpandTemplates replaces the template of every AQL rule config with the inline AQL text of
+// its instantiation
+func (c *Config) ExpandTemplates() error {
+	for name, template := range c.Templates {
+		if err := template.Validate(); err != nil {
+			return fmt.Errorf("template '%s': %w", name, err)
+		}
+	}
+	for i := range c.AQLRules {
+		rule := &c.AQLRules[i]
+		if rule.Template == "" {
+			continue
+		}
+		if rule.File != "" || rule.Inline != "" {
+			return fmt.Errorf("aql rule #%d cannot define a template together with a file or inline rule", i+1)
+		}
+		template, ok := c.Templates[rule.Template]
+		if !ok {
+			return fmt.Errorf("aql rule #%d uses unknown template '%s'", i+1, rule.Template)
+		}
+		text, err := template.Instantiate(rule.With)
+		if err != nil {
+			return fmt.Errorf("aql rule #%d of template '%s': %w", i+1, rule.Template, err)
+		}
+		rule.Inline = text
+	}
+	return nil
+}
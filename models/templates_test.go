@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("RuleTemplate", func() {
+	template := models.RuleTemplate{
+		Params: []string{"target", "owner"},
+		AQL:    `RULE "${target} only in ${owner}" { FORBID(controller -> ${target}) }`,
+	}
+
+	It("replaces every parameter", func() {
+		text, err := template.Instantiate(map[string]string{"target": "sql", "owner": "repository"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(Equal(`RULE "sql only in repository" { FORBID(controller -> sql) }`))
+	})
+
+	It("requires every parameter and no others", func() {
+		_, err := template.Instantiate(map[string]string{"target": "sql"})
+		Expect(err).To(MatchError("missing parameter 'owner'"))
+
+		_, err = template.Instantiate(map[string]string{"target": "sql", "owner": "repository", "layer": "data"})
+		Expect(err).To(MatchError("unknown parameter 'layer'"))
+	})
+
+	It("rejects undeclared parameters", func() {
+		invalid := models.RuleTemplate{Params: []string{"target"}, AQL: `RULE "x" { FORBID(${from} -> ${target}) }`}
+		Expect(invalid.Validate()).To(MatchError("undeclared parameter 'from'"))
+	})
+
+	It("expands the templates of AQL rule configs", func() {
+		config := &models.Config{
+			Templates: map[string]models.RuleTemplate{"confined": template},
+			AQLRules: []models.AQLRuleConfig{
+				{Template: "confined", With: map[string]string{"target": "sql", "owner": "repository"}, Enabled: true},
+				{Inline: `RULE "y" { FORBID(a -> b) }`, Enabled: true},
+			},
+		}
+		Expect(config.ExpandTemplates()).To(Succeed())
+		Expect(config.AQLRules[0].Inline).To(ContainSubstring(`RULE "sql only in repository"`))
+
+		config.AQLRules = append(config.AQLRules, models.AQLRuleConfig{Template: "missing"})
+		Expect(config.ExpandTemplates()).To(MatchError("aql rule #3 uses unknown template 'missing'"))
+	})
+})
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -30,9 +31,46 @@ type Violation struct {
 	Message *string `json:"message,omitempty" gorm:"column:message"`
 	// Source tool that reported the violation (e.g., arch-unit, golangci-lint)
 	Source           string    `json:"source,omitempty" gorm:"column:source;not null;index"`
+	Severity         Severity  `json:"severity,omitempty" gorm:"column:severity;default:''"`
 	Fixable          bool      `json:"fixable,omitempty" gorm:"column:fixable;default:false"`
 	FixApplicability string    `json:"fix_applicability,omitempty" gorm:"column:fix_applicability;default:''"`
 	CreatedAt        time.Time `json:"created_at,omitempty" gorm:"column:stored_at;index"`
+	// Remediation suggested by an LLM with `check --explain`, not stored with the violation
+	Suggestion *string `json:"suggestion,omitempty" gorm:"-"`
+}
+
+// Severity is how serious a violation is, empty when the source tool does not tell
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// ParseSeverity returns the severity named error, warning or info
+func ParseSeverity(name string) (Severity, error) {
+	switch severity := Severity(strings.ToLower(name)); severity {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return severity, nil
+	}
+	return "", fmt.Errorf("invalid severity '%s', expected error, warning or info", name)
+}
+
+// IsAtLeast returns true if a severity is as serious as threshold. Violations without a
+// severity, or with one of another tool, count as errors.
+func (s Severity) IsAtLeast(threshold Severity) bool {
+	return s.rank() >= threshold.rank()
+}
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	}
+	return 3
 }
 
 // TableName specifies the table name for Violation
@@ -65,7 +103,11 @@ func (v Violation) Pretty() api.Text {
 		t = t.Append(", ⇥ ", "text-gray-400").Append(strings.TrimSpace(*v.Code), "text-blue-500")
 	}
 
-	return t.Append(" (").Add(v.Rule.Pretty()).Append(")")
+	t = t.Append(" (").Add(v.Rule.Pretty()).Append(")")
+	if v.Suggestion != nil && *v.Suggestion != "" {
+		t = t.Append(" 💡 ", "text-yellow-500").Append(*v.Suggestion, "text-gray-600 italic")
+	}
+	return t
 }
 
 // ViolationNode represents an individual violation as a tree node
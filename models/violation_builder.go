@@ -47,6 +47,12 @@ func (vb *ViolationBuilder) WithSource(source string) *ViolationBuilder {
 	return vb
 }
 
+// WithSeverity sets how serious the violation is
+func (vb *ViolationBuilder) WithSeverity(severity Severity) *ViolationBuilder {
+	vb.violation.Severity = severity
+	return vb
+}
+
 // WithCaller creates or finds a caller AST node and associates it with the violation
 func (vb *ViolationBuilder) WithCaller(pkg, method string) *ViolationBuilder {
 	caller := vb.findOrCreateASTNode(pkg, method, NodeTypeMethod)
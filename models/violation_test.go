@@ -225,4 +225,30 @@ var _ = Describe("Violation", func() {
 			Expect(result).To(Equal(expected))
 		})
 	})
+
+	Describe("Severity", func() {
+		It("should parse the severities", func() {
+			severity, err := ParseSeverity("Warning")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(severity).To(Equal(SeverityWarning))
+
+			_, err = ParseSeverity("fatal")
+			Expect(err).To(MatchError("invalid severity 'fatal', expected error, warning or info"))
+		})
+
+		It("should compare severities, counting unknown severities as errors", func() {
+			Expect(SeverityWarning.IsAtLeast(SeverityError)).To(BeFalse())
+			Expect(SeverityWarning.IsAtLeast(SeverityWarning)).To(BeTrue())
+			Expect(SeverityInfo.IsAtLeast(SeverityWarning)).To(BeFalse())
+			Expect(Severity("").IsAtLeast(SeverityError)).To(BeTrue())
+			Expect(Severity("high").IsAtLeast(SeverityError)).To(BeTrue())
+		})
+
+		It("should only fail on violations at least as serious as the threshold", func() {
+			result := &ConsolidatedResult{Violations: []Violation{{Severity: SeverityWarning}, {Severity: SeverityInfo}}}
+			Expect(result.HasFailuresAt(SeverityError)).To(BeFalse())
+			Expect(result.HasFailuresAt(SeverityWarning)).To(BeTrue())
+			Expect(result.HasFailures()).To(BeTrue())
+		})
+	})
 })
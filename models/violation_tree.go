@@ -334,7 +334,7 @@ func (vft *ViolationFileTreeNode) Pretty() api.Text {
 	switch {
 	case strings.HasSuffix(vft.file.path, ".go"):
 		icon = "🐹"
-	case strings.HasSuffix(vft.file.path, ".py"):
+	case strings.HasSuffix(vft.file.path, ".py"), strings.HasSuffix(vft.file.path, ".ipynb"):
 		icon = "🐍"
 	case strings.HasSuffix(vft.file.path, ".js"), strings.HasSuffix(vft.file.path, ".jsx"):
 		icon = "📜"
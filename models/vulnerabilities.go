@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VulnerabilitySeverities are the severities of vulnerabilities, from the least to the most severe
+var VulnerabilitySeverities = []string{"low", "medium", "high", "critical"}
+
+// VulnerabilityConfig configures the osv linter, which checks the scanned dependencies against the
+// OSV.dev vulnerability database
+type VulnerabilityConfig struct {
+	// Database is a directory of OSV exports, <ecosystem>/all.zip, used instead of the OSV.dev API
+	Database    string                                  `yaml:"database,omitempty"`
+	MinSeverity string                                  `yaml:"min_severity,omitempty"` // low, medium, high or critical
+	Ignore      []string                                `yaml:"ignore,omitempty"`       // Vulnerability IDs or aliases, e.g. CVE-2021-23337
+	Ecosystems  map[string]VulnerabilityEcosystemConfig `yaml:"ecosystems,omitempty"`   // Keyed by dependency type: go, npm, pip, maven, nuget
+}
+
+// VulnerabilityEcosystemConfig overrides the vulnerability scanning of one ecosystem
+type VulnerabilityEcosystemConfig struct {
+	Enabled     *bool    `yaml:"enabled,omitempty"`
+	MinSeverity string   `yaml:"min_severity,omitempty"`
+	Ignore      []string `yaml:"ignore,omitempty"`
+}
+
+// Validate checks the configured severities
+func (c *VulnerabilityConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if err := validateVulnerabilitySeverity(c.MinSeverity); err != nil {
+		return err
+	}
+	for ecosystem, config := range c.Ecosystems {
+		if err := validateVulnerabilitySeverity(config.MinSeverity); err != nil {
+			return fmt.Errorf("ecosystem %s: %w", ecosystem, err)
+		}
+	}
+	return nil
+}
+
+func validateVulnerabilitySeverity(severity string) error {
+	if severity == "" || VulnerabilitySeverityRank(severity) > 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid min_severity '%s', expected one of %s", severity, strings.Join(VulnerabilitySeverities, ", "))
+}
+
+// IsEcosystemEnabled returns whether dependencies of a type are checked, every ecosystem is
+// checked unless disabled
+func (c *VulnerabilityConfig) IsEcosystemEnabled(ecosystem string) bool {
+	if c == nil {
+		return true
+	}
+	config, ok := c.Ecosystems[ecosystem]
+	return !ok || config.Enabled == nil || *config.Enabled
+}
+
+// GetMinSeverity returns the least severity reported for an ecosystem, "" to report everything
+func (c *VulnerabilityConfig) GetMinSeverity(ecosystem string) string {
+	if c == nil {
+		return ""
+	}
+	if config, ok := c.Ecosystems[ecosystem]; ok && config.MinSeverity != "" {
+		return config.MinSeverity
+	}
+	return c.MinSeverity
+}
+
+// IsIgnored returns whether any of the IDs of a vulnerability is ignored for an ecosystem
+func (c *VulnerabilityConfig) IsIgnored(ecosystem string, ids ...string) bool {
+	if c == nil {
+		return false
+	}
+	ignored := append([]string{}, c.Ignore...)
+	ignored = append(ignored, c.Ecosystems[ecosystem].Ignore...)
+	for _, id := range ids {
+		for _, ignore := range ignored {
+			if strings.EqualFold(id, ignore) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VulnerabilitySeverityRank orders severities from 1 for low to 4 for critical, 0 when unknown
+func VulnerabilitySeverityRank(severity string) int {
+	for i, s := range VulnerabilitySeverities {
+		if strings.EqualFold(s, severity) {
+			return i + 1
+		}
+	}
+	return 0
+}
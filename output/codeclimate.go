@@ -0,0 +1,116 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// codeClimateIssue is an issue of the Code Climate format, as read by GitLab Code Quality
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeClimateLocation `json:"location"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+}
+
+// codeClimateSeverity maps the severity of a violation to a Code Climate severity, violations
+// without a severity count as errors
+func codeClimateSeverity(severity models.Severity) string {
+	switch severity {
+	case models.SeverityInfo:
+		return "info"
+	case models.SeverityWarning:
+		return "minor"
+	}
+	return "major"
+}
+
+// codeClimateCategory returns the Code Climate category of the violations of a linter
+func codeClimateCategory(source string) string {
+	switch source {
+	case "osv", "images":
+		return "Security"
+	case "duplication":
+		return "Duplication"
+	case "nilcheck", "contextcheck", "txcheck", "pyright":
+		return "Bug Risk"
+	}
+	return "Style"
+}
+
+// buildCodeClimate converts violations into Code Climate issues. Issues are fingerprinted like
+// baselines, without their line, so that GitLab tracks them across commits moving them; repeated
+// violations of the same rule by the same symbol are told apart by their occurrence.
+func buildCodeClimate(result *models.AnalysisResult) []codeClimateIssue {
+	root, _ := os.Getwd()
+	issues := []codeClimateIssue{}
+	occurrences := make(map[string]int)
+	for _, v := range result.Violations {
+		checkName := sarifRuleID(v)
+		description := checkName
+		if v.Message != nil && *v.Message != "" {
+			description = *v.Message
+		}
+
+		fingerprint := models.Fingerprint(v, root)
+		if n := occurrences[fingerprint]; n > 0 {
+			sum := sha256.Sum256([]byte(fingerprint + "\x00" + strconv.Itoa(n)))
+			occurrences[fingerprint]++
+			fingerprint = hex.EncodeToString(sum[:8])
+		} else {
+			occurrences[fingerprint] = 1
+		}
+
+		line := v.Line
+		if line < 1 {
+			line = 1
+		}
+		issues = append(issues, codeClimateIssue{
+			Type:        "issue",
+			CheckName:   checkName,
+			Description: description,
+			Categories:  []string{codeClimateCategory(v.Source)},
+			Severity:    codeClimateSeverity(v.Severity),
+			Fingerprint: fingerprint,
+			Location: codeClimateLocation{
+				Path:  filepath.ToSlash(getRelativePath(v.File)),
+				Lines: codeClimateLines{Begin: line},
+			},
+		})
+	}
+	return issues
+}
+
+func (o *OutputManager) outputCodeClimate(result *models.AnalysisResult) error {
+	writer := os.Stdout
+	if o.output != "" {
+		file, err := os.Create(o.output)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		writer = file
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildCodeClimate(result))
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// RenderGraph renders a dependency graph as a mermaid, plantuml or Graphviz dot diagram
+func RenderGraph(graph *models.DependencyGraph, format string) (string, error) {
+	switch format {
+	case "mermaid":
+		return renderMermaid(graph), nil
+	case "plantuml":
+		return renderPlantUML(graph), nil
+	case "dot":
+		return renderDOT(graph), nil
+	}
+	return "", fmt.Errorf("unsupported graph format '%s', expected mermaid, plantuml or dot", format)
+}
+
+// diagramIDs returns identifiers of the nodes safe to use in diagrams, which do not allow the
+// dots and slashes of package names
+func diagramIDs(graph *models.DependencyGraph) map[string]string {
+	ids := make(map[string]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+	}
+	return ids
+}
+
+// renderMermaid renders a left to right mermaid flowchart, e.g. for markdown documents rendered
+// by GitHub and GitLab
+func renderMermaid(graph *models.DependencyGraph) string {
+	ids := diagramIDs(graph)
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(&sb, "    %s[\"%s\"]\n", ids[node], strings.ReplaceAll(node, `"`, "#quot;"))
+	}
+	for _, edge := range graph.Edges {
+		if edge.Type != "" {
+			fmt.Fprintf(&sb, "    %s -->|%s| %s\n", ids[edge.From], edge.Type, ids[edge.To])
+		} else {
+			fmt.Fprintf(&sb, "    %s --> %s\n", ids[edge.From], ids[edge.To])
+		}
+	}
+	return sb.String()
+}
+
+// renderPlantUML renders packages as plantuml packages and types as classes
+func renderPlantUML(graph *models.DependencyGraph) string {
+	ids := diagramIDs(graph)
+	var sb strings.Builder
+	sb.WriteString("@startuml\nleft to right direction\n")
+	for _, node := range graph.Nodes {
+		name := strings.ReplaceAll(node, `"`, "'")
+		if graph.Level == models.GraphLevelPackage {
+			fmt.Fprintf(&sb, "package \"%s\" as %s {\n}\n", name, ids[node])
+		} else {
+			fmt.Fprintf(&sb, "class \"%s\" as %s\n", name, ids[node])
+		}
+	}
+	for _, edge := range graph.Edges {
+		if edge.Type != "" {
+			fmt.Fprintf(&sb, "%s --> %s : %s\n", ids[edge.From], ids[edge.To], edge.Type)
+		} else {
+			fmt.Fprintf(&sb, "%s --> %s\n", ids[edge.From], ids[edge.To])
+		}
+	}
+	sb.WriteString("@enduml\n")
+	return sb.String()
+}
+
+// dotEdgeStyles are the Graphviz attributes of the edges of a relationship type, following UML:
+// dashed realization of interfaces, hollow arrows for inheritance and dotted imports
+var dotEdgeStyles = map[string]string{
+	models.RelationshipImplements:  ", style=dashed, arrowhead=empty",
+	models.RelationshipInheritance: ", arrowhead=empty",
+	models.RelationshipExtends:     ", arrowhead=empty",
+	models.RelationshipImport:      ", style=dotted",
+}
+
+// renderDOT renders a Graphviz digraph, external libraries are dashed and edges are labelled
+// with their relationship type and weighted by their number of relationships
+func renderDOT(graph *models.DependencyGraph) string {
+	ids := diagramIDs(graph)
+	external := make(map[string]bool, len(graph.External))
+	for _, node := range graph.External {
+		external[node] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph dependencies {\n    rankdir=LR;\n    node [shape=box];\n")
+	for _, node := range graph.Nodes {
+		style := ""
+		if external[node] {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&sb, "    %s [label=%s%s];\n", ids[node], dotQuote(node), style)
+	}
+	for _, edge := range graph.Edges {
+		attributes := fmt.Sprintf("weight=%d", edge.Count)
+		if edge.Type != "" {
+			attributes += ", label=" + dotQuote(edge.Type) + dotEdgeStyles[edge.Type]
+		}
+		fmt.Fprintf(&sb, "    %s -> %s [%s];\n", ids[edge.From], ids[edge.To], attributes)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotQuote returns a quoted Graphviz string
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
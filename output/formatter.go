@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/flanksource/arch-unit/internal/i18n"
 	"github.com/flanksource/arch-unit/models"
 )
 
@@ -28,10 +29,59 @@ func getCallerMethod(v models.Violation) string {
 	return "unknown"
 }
 
+// fileFormats maps output file extensions to the format written to them
+var fileFormats = map[string]string{
+	".json":    "json",
+	".csv":     "csv",
+	".html":    "html",
+	".htm":     "html",
+	".md":      "markdown",
+	".sarif":   "sarif",
+	".ndjson":  "ndjson",
+	".jsonl":   "ndjson",
+	".rdjson":  "rdjson",
+	".rdjsonl": "rdjsonl",
+	".prom":    "prometheus",
+}
+
+// reportFormats are the formats consumed by CI systems and monitoring rather than people, written
+// to stdout as is when selected with --format
+var reportFormats = map[string]bool{
+	"sarif":       true,
+	"codeclimate": true,
+	"github":      true,
+	"rdjson":      true,
+	"rdjsonl":     true,
+	"prometheus":  true,
+}
+
+// IsReportFormat returns true if a format is consumed by CI systems rather than people
+func IsReportFormat(format string) bool {
+	return reportFormats[format]
+}
+
+// FormatForFile returns the output format for a file based on its extension
+func FormatForFile(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if strings.HasSuffix(strings.ToLower(path), ".sarif.json") {
+		ext = ".sarif"
+	}
+	// GitLab Code Quality reports are JSON files, told apart by their name
+	if name := strings.ToLower(filepath.Base(path)); name == "gl-code-quality-report.json" || strings.HasSuffix(name, ".codeclimate.json") {
+		return "codeclimate", nil
+	}
+	if format, ok := fileFormats[ext]; ok {
+		return format, nil
+	}
+	return "", fmt.Errorf("cannot determine output format for %s: unsupported extension %q", path, ext)
+}
+
 type OutputManager struct {
-	format  string
-	output  string
-	compact bool
+	format       string
+	output       string
+	compact      bool
+	architecture *models.ArchitectureReport
+	queryReports []*models.QueryReport
 }
 
 func NewOutputManager(format string) *OutputManager {
@@ -52,6 +102,8 @@ func (o *OutputManager) Output(result *models.AnalysisResult) error {
 	switch o.format {
 	case "json":
 		return o.outputJSON(result)
+	case "ndjson":
+		return o.outputNDJSON(result)
 	case "csv":
 		return o.outputCSV(result)
 	case "html":
@@ -60,6 +112,18 @@ func (o *OutputManager) Output(result *models.AnalysisResult) error {
 		return o.outputExcel(result)
 	case "markdown":
 		return o.outputMarkdown(result)
+	case "sarif":
+		return o.outputSARIF(result)
+	case "codeclimate":
+		return o.outputCodeClimate(result)
+	case "github":
+		return o.outputGitHub(result)
+	case "rdjson":
+		return o.outputRDJSON(result, false)
+	case "rdjsonl":
+		return o.outputRDJSON(result, true)
+	case "prometheus":
+		return o.outputPrometheus(result)
 	default:
 		return o.outputTable(result)
 	}
@@ -98,7 +162,7 @@ func (o *OutputManager) outputCompact(result *models.AnalysisResult) {
 	}
 	sort.Strings(files)
 
-	fmt.Println("\n📋 Architecture Violations (Compact)")
+	fmt.Println("\n📋 " + i18n.T("tree.compact_title"))
 	fmt.Println(strings.Repeat("─", 80))
 
 	for _, file := range files {
@@ -155,7 +219,7 @@ func (o *OutputManager) outputTree(result *models.AnalysisResult) {
 	}
 	sort.Strings(files)
 
-	fmt.Println("\n📋 Architecture Violations")
+	fmt.Println("\n📋 " + i18n.T("tree.title"))
 	fmt.Println(strings.Repeat("─", 80))
 
 	for i, file := range files {
@@ -166,9 +230,9 @@ func (o *OutputManager) outputTree(result *models.AnalysisResult) {
 		isLast := i == len(files)-1
 
 		if isLast {
-			fmt.Printf("└── %s (%d violations)\n", fileStyle.Render(relPath), len(violations))
+			fmt.Printf("└── %s (%s)\n", fileStyle.Render(relPath), i18n.T("tree.file_violations", len(violations)))
 		} else {
-			fmt.Printf("├── %s (%d violations)\n", fileStyle.Render(relPath), len(violations))
+			fmt.Printf("├── %s (%s)\n", fileStyle.Render(relPath), i18n.T("tree.file_violations", len(violations)))
 		}
 
 		// Group violations by rule type
@@ -267,7 +331,17 @@ func getRelativePath(path string) string {
 }
 
 func (o *OutputManager) outputJSON(result *models.AnalysisResult) error {
-	encoder := json.NewEncoder(os.Stdout)
+	writer := os.Stdout
+	if o.output != "" {
+		file, err := os.Create(o.output)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		writer = file
+	}
+
+	encoder := json.NewEncoder(writer)
 	encoder.SetIndent("", "  ")
 
 	output := map[string]interface{}{
@@ -323,97 +397,6 @@ func (o *OutputManager) outputCSV(result *models.AnalysisResult) error {
 	return nil
 }
 
-func (o *OutputManager) outputHTML(result *models.AnalysisResult) error {
-	if o.output == "" {
-		return fmt.Errorf("output file required for HTML format")
-	}
-
-	file, err := os.Create(o.output)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = file.Close() }()
-
-	html := `<!DOCTYPE html>
-<html>
-<head>
-	<title>Architecture Violations Report</title>
-	<style>
-		body { font-family: Arial, sans-serif; margin: 20px; }
-		h1 { color: #333; }
-		.summary { background: #f0f0f0; padding: 10px; border-radius: 5px; margin-bottom: 20px; }
-		table { border-collapse: collapse; width: 100%; }
-		th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
-		th { background-color: #f2f2f2; }
-		tr:nth-child(even) { background-color: #f9f9f9; }
-		.violation { color: #d9534f; }
-		.no-violations { color: #5cb85c; font-size: 1.2em; padding: 20px; }
-	</style>
-</head>
-<body>
-	<h1>Architecture Violations Report</h1>
-	<div class="summary">
-		<p><strong>Files Analyzed:</strong> %d</p>
-		<p><strong>Rules Applied:</strong> %d</p>
-		<p><strong>Violations Found:</strong> <span class="violation">%d</span></p>
-	</div>
-`
-
-	_, _ = fmt.Fprintf(file, html, result.FileCount, result.RuleCount, len(result.Violations))
-
-	if len(result.Violations) == 0 {
-		fmt.Fprintln(file, `<div class="no-violations">✓ No architecture violations found!</div>`)
-	} else {
-		fmt.Fprintln(file, `<table>
-		<thead>
-			<tr>
-				<th>File</th>
-				<th>Line</th>
-				<th>Caller</th>
-				<th>Violation</th>
-				<th>Rule</th>
-				<th>Rule Source</th>
-			</tr>
-		</thead>
-		<tbody>`)
-
-		for _, v := range result.Violations {
-			call := "unknown"
-			if v.Called != nil {
-				if v.Called.PackageName != "" && v.Called.MethodName != "" {
-					call = fmt.Sprintf("%s.%s", v.Called.PackageName, v.Called.MethodName)
-				} else if v.Called.PackageName != "" {
-					call = v.Called.PackageName
-				} else if v.Called.MethodName != "" {
-					call = v.Called.MethodName
-				}
-			}
-
-			ruleStr := ""
-			ruleFile := ""
-			if v.Rule != nil {
-				ruleStr = v.Rule.String()
-				ruleFile = fmt.Sprintf("%s:%d", v.Rule.SourceFile, v.Rule.LineNumber)
-			}
-
-			fmt.Fprintf(file, `<tr>
-				<td>%s:%d:%d</td>
-				<td>%d</td>
-				<td>%s</td>
-				<td class="violation">%s</td>
-				<td>%s</td>
-				<td>%s</td>
-			</tr>`, v.File, v.Line, v.Column, v.Line, getCallerMethod(v), call, ruleStr, ruleFile)
-		}
-
-		fmt.Fprintln(file, `</tbody></table>`)
-	}
-
-	fmt.Fprintln(file, `</body></html>`)
-
-	return nil
-}
-
 func (o *OutputManager) outputMarkdown(result *models.AnalysisResult) error {
 	writer := os.Stdout
 	if o.output != "" {
@@ -425,22 +408,26 @@ func (o *OutputManager) outputMarkdown(result *models.AnalysisResult) error {
 		writer = file
 	}
 
-	fmt.Fprintln(writer, "# Architecture Violations Report")
+	fmt.Fprintln(writer, "# "+i18n.T("report.title"))
 	fmt.Fprintln(writer)
-	fmt.Fprintln(writer, "## Summary")
-	_, _ = fmt.Fprintf(writer, "- **Files Analyzed:** %d\n", result.FileCount)
-	_, _ = fmt.Fprintf(writer, "- **Rules Applied:** %d\n", result.RuleCount)
-	_, _ = fmt.Fprintf(writer, "- **Violations Found:** %d\n", len(result.Violations))
+	fmt.Fprintln(writer, "## "+i18n.T("report.summary"))
+	_, _ = fmt.Fprintf(writer, "- **%s:** %d\n", i18n.T("report.files_analyzed"), result.FileCount)
+	_, _ = fmt.Fprintf(writer, "- **%s:** %d\n", i18n.T("report.rules_applied"), result.RuleCount)
+	_, _ = fmt.Fprintf(writer, "- **%s:** %d\n", i18n.T("report.violations_found"), len(result.Violations))
 	fmt.Fprintln(writer)
 
+	writeMarkdownQueryReports(writer, o.queryReports)
+
 	if len(result.Violations) == 0 {
-		fmt.Fprintln(writer, "✓ **No architecture violations found!**")
+		_, _ = fmt.Fprintf(writer, "✓ **%s**\n", i18n.T("report.no_violations"))
 		return nil
 	}
 
-	fmt.Fprintln(writer, "## Violations")
+	fmt.Fprintln(writer, "## "+i18n.T("report.violations"))
 	fmt.Fprintln(writer)
-	fmt.Fprintln(writer, "| File | Line | Caller | Violation | Rule | Source |")
+	_, _ = fmt.Fprintf(writer, "| %s | %s | %s | %s | %s | %s |\n",
+		i18n.T("report.column.file"), i18n.T("report.column.line"), i18n.T("report.column.caller"),
+		i18n.T("report.column.violation"), i18n.T("report.column.rule"), i18n.T("report.column.rule_source"))
 	fmt.Fprintln(writer, "|------|------|--------|-----------|------|--------|")
 
 	for _, v := range result.Violations {
@@ -0,0 +1,370 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+var _ = Describe("Output", func() {
+	Context("FormatForFile", func() {
+		DescribeTable("infers the format from the extension",
+			func(path, expected string) {
+				format, err := FormatForFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(format).To(Equal(expected))
+			},
+			Entry("json", "report.json", "json"),
+			Entry("csv", "out/report.CSV", "csv"),
+			Entry("html", "report.html", "html"),
+			Entry("markdown", "report.md", "markdown"),
+			Entry("sarif", "report.sarif", "sarif"),
+			Entry("sarif json", "report.sarif.json", "sarif"),
+			Entry("ndjson", "report.ndjson", "ndjson"),
+			Entry("json lines", "report.jsonl", "ndjson"),
+			Entry("gitlab code quality", "gl-code-quality-report.json", "codeclimate"),
+			Entry("code climate", "report.codeclimate.json", "codeclimate"),
+			Entry("reviewdog", "report.rdjson", "rdjson"),
+			Entry("reviewdog lines", "report.rdjsonl", "rdjsonl"),
+			Entry("prometheus", "arch-unit.prom", "prometheus"),
+		)
+
+		It("rejects unknown extensions", func() {
+			_, err := FormatForFile("report.txt")
+			Expect(err).To(MatchError(ContainSubstring(`unsupported extension ".txt"`)))
+		})
+	})
+
+	Context("when writing several outputs from one result", func() {
+		var (
+			dir    string
+			result *models.AnalysisResult
+		)
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			result = &models.AnalysisResult{
+				FileCount: 2,
+				RuleCount: 1,
+				Violations: []models.Violation{{
+					File:    filepath.Join(dir, "service", "handler.go"),
+					Line:    12,
+					Column:  4,
+					Source:  "arch-unit",
+					Message: models.StringPtr("Call to internal/db violates architecture rule"),
+					Rule:    &models.Rule{Type: models.RuleTypeDeny, Pattern: "internal/db", OriginalLine: "!internal/db"},
+				}},
+			}
+		})
+
+		It("writes each file in its own format", func() {
+			for _, name := range []string{"report.sarif", "report.json", "report.md"} {
+				path := filepath.Join(dir, name)
+				format, err := FormatForFile(path)
+				Expect(err).NotTo(HaveOccurred())
+
+				manager := NewOutputManager(format)
+				manager.SetOutputFile(path)
+				Expect(manager.Output(result)).To(Succeed())
+				Expect(path).To(BeAnExistingFile())
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "report.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("# Architecture Violations Report"))
+
+			data, err = os.ReadFile(filepath.Join(dir, "report.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"violations"`))
+		})
+
+		It("writes SARIF results with rules and locations", func() {
+			path := filepath.Join(dir, "report.sarif")
+			manager := NewOutputManager("sarif")
+			manager.SetOutputFile(path)
+			Expect(manager.Output(result)).To(Succeed())
+
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			var log sarifLog
+			Expect(json.Unmarshal(data, &log)).To(Succeed())
+			Expect(log.Version).To(Equal("2.1.0"))
+			Expect(log.Runs).To(HaveLen(1))
+
+			run := log.Runs[0]
+			Expect(run.Tool.Driver.Name).To(Equal("arch-unit"))
+			Expect(run.Tool.Driver.Rules).To(ConsistOf(sarifRule{
+				ID:               "internal/db",
+				ShortDescription: sarifMessage{Text: "!internal/db"},
+			}))
+			Expect(run.Results).To(HaveLen(1))
+			Expect(run.Results[0].RuleID).To(Equal("internal/db"))
+			Expect(run.Results[0].Message.Text).To(Equal("Call to internal/db violates architecture rule"))
+			Expect(run.Results[0].Locations[0].PhysicalLocation.Region).To(Equal(&sarifRegion{StartLine: 12, StartColumn: 4}))
+		})
+
+		It("writes the suggestions of --explain with SARIF results", func() {
+			result.Violations[0].Suggestion = models.StringPtr("Call the repository of the service instead")
+			sarif := buildSARIF(result)
+			Expect(sarif.Runs[0].Results[0].Message.Text).To(HaveSuffix("\n\nSuggestion: Call the repository of the service instead"))
+			Expect(sarif.Runs[0].Results[0].Properties.Suggestion).To(Equal("Call the repository of the service instead"))
+		})
+
+		It("writes an HTML report with the architecture and filters on the violations", func() {
+			result.Violations[0].Caller = &models.ASTNode{PackageName: "service", MethodName: "Handle"}
+			path := filepath.Join(dir, "report.html")
+			manager := NewOutputManager("html")
+			manager.SetOutputFile(path)
+			manager.SetArchitecture(&models.ArchitectureReport{
+				Packages: []*models.PackageSummary{
+					{Package: "db", Files: 1, Methods: 2, AvgComplexity: 2},
+					{Package: "service", Files: 1, Methods: 4, AvgComplexity: 12.5, MaxComplexity: 20},
+				},
+				Dependencies: []*models.DependencyEdge{{From: "service", To: "db", Count: 3}},
+			})
+			Expect(manager.Output(result)).To(Succeed())
+
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			html := string(data)
+			Expect(html).To(ContainSubstring(`<svg id="graph"`))
+			Expect(html).To(ContainSubstring(`data-from="service" data-to="db"`))
+			Expect(html).To(ContainSubstring(`<div data-select-package="service" style="background: hsl(20, 70%, 80%)"`))
+			Expect(html).To(ContainSubstring(`<tr data-select-rule="internal/db">`))
+			Expect(html).To(ContainSubstring(`<tr data-severity="error" data-source="arch-unit" data-rule="internal/db" data-package="service">`))
+			Expect(html).To(ContainSubstring(`id="filter-severity"`))
+		})
+
+		It("writes the results of saved reports with HTML and markdown reports", func() {
+			reports := []*models.QueryReport{{
+				Name:  "weekly",
+				Title: "Weekly review",
+				Results: []models.NamedQueryResult{
+					{
+						Name:        "large_services",
+						Description: "Services that have grown too large",
+						Query:       "lines(*Service*) > 300",
+						Count:       1,
+						Matches: []models.QueryMatch{{
+							File: filepath.Join(dir, "service", "user.go"), Line: 8, Name: "service.UserService",
+							Message: "lines 412 > 300",
+						}},
+					},
+					{Name: "orphan_services", Query: "*Service"},
+				},
+			}}
+
+			for _, name := range []string{"report.html", "report.md"} {
+				path := filepath.Join(dir, name)
+				format, err := FormatForFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				manager := NewOutputManager(format)
+				manager.SetOutputFile(path)
+				manager.SetQueryReports(reports)
+				Expect(manager.Output(result)).To(Succeed())
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "report.html"))
+			Expect(err).NotTo(HaveOccurred())
+			html := string(data)
+			Expect(html).To(ContainSubstring("<h2>Weekly review</h2>"))
+			Expect(html).To(ContainSubstring("<h3>large_services (1)</h3>"))
+			Expect(html).To(ContainSubstring(`<table data-query="large_services">`))
+			Expect(html).To(ContainSubstring("<td>service.UserService</td>"))
+			Expect(html).To(ContainSubstring("<td>lines 412 &gt; 300</td>"))
+			Expect(html).To(ContainSubstring("<h3>orphan_services (0)</h3>\n\t<p><code>*Service</code></p>\n\t<p>No matches</p>"))
+
+			data, err = os.ReadFile(filepath.Join(dir, "report.md"))
+			Expect(err).NotTo(HaveOccurred())
+			markdown := string(data)
+			Expect(markdown).To(ContainSubstring("## Weekly review\n\n### large_services (1)\n\nServices that have grown too large\n\n`lines(*Service*) > 300`"))
+			Expect(markdown).To(MatchRegexp(`\| service\.UserService \| \S*service/user\.go \| 8 \| lines 412 > 300 \|`))
+			Expect(markdown).To(ContainSubstring("### orphan_services (0)\n\n`*Service`\n\nNo matches"))
+		})
+	})
+
+	Context("Code Climate", func() {
+		It("fingerprints issues without their line and tells repeated issues apart", func() {
+			violation := models.Violation{
+				File:     "service/handler.go",
+				Line:     12,
+				Source:   "aql",
+				Severity: models.SeverityWarning,
+				Message:  models.StringPtr("Rule 'Complexity': handler.Serve violated limit"),
+			}
+			moved := violation
+			moved.Line = 40
+
+			issues := buildCodeClimate(&models.AnalysisResult{Violations: []models.Violation{violation, moved}})
+			Expect(issues).To(HaveLen(2))
+			Expect(issues[0].Type).To(Equal("issue"))
+			Expect(issues[0].CheckName).To(Equal("aql"))
+			Expect(issues[0].Description).To(Equal("Rule 'Complexity': handler.Serve violated limit"))
+			Expect(issues[0].Severity).To(Equal("minor"))
+			Expect(issues[0].Location).To(Equal(codeClimateLocation{Path: "service/handler.go", Lines: codeClimateLines{Begin: 12}}))
+			Expect(issues[0].Fingerprint).To(Equal(models.Fingerprint(violation, "")))
+			Expect(issues[1].Fingerprint).ToNot(Equal(issues[0].Fingerprint))
+
+			single := buildCodeClimate(&models.AnalysisResult{Violations: []models.Violation{moved}})
+			Expect(single[0].Fingerprint).To(Equal(issues[0].Fingerprint))
+		})
+	})
+
+	Context("GitHub", func() {
+		It("writes a workflow command for every violation", func() {
+			var buf bytes.Buffer
+			Expect(writeGitHubAnnotations(&buf, &models.AnalysisResult{Violations: []models.Violation{
+				{File: "service/handler.go", Line: 12, Column: 4, Source: "aql", Message: models.StringPtr("Rule 'Layers': 100% wrong\nsecond line")},
+				{File: "api/user.go", Line: 3, Source: "golangci-lint", Severity: models.SeverityWarning, Message: models.StringPtr("unused")},
+			}})).To(Succeed())
+			Expect(buf.String()).To(Equal("::error file=service/handler.go,line=12,col=4,title=aql::Rule 'Layers': 100%25 wrong%0Asecond line\n" +
+				"::warning file=api/user.go,line=3,title=golangci-lint::unused\n"))
+		})
+
+		It("summarizes new and fixed violations for a pull request comment", func() {
+			comment := GitHubComment(&models.AnalysisResult{Violations: []models.Violation{
+				{Source: "aql", Severity: models.SeverityWarning},
+				{Source: "aql"},
+				{Source: "osv"},
+			}}, true, 2)
+			Expect(comment).To(HavePrefix(GitHubCommentMarker))
+			Expect(comment).To(ContainSubstring("**3 new** and **2 fixed** violations"))
+			Expect(comment).To(ContainSubstring("| error | 2 |"))
+			Expect(comment).To(ContainSubstring("| `aql` | 2 |"))
+
+			Expect(GitHubComment(&models.AnalysisResult{}, false, 0)).To(ContainSubstring("No violations found."))
+		})
+	})
+
+	Context("Reviewdog", func() {
+		It("writes a diagnostic per violation with its rule and position", func() {
+			diagnostics := buildRDJSONDiagnostics(&models.AnalysisResult{Violations: []models.Violation{
+				{File: "service/handler.go", Line: 12, Column: 4, Source: "aql", Severity: models.SeverityWarning, Message: models.StringPtr("Rule 'Layers': service calls controller")},
+				{File: "go.mod", Source: "osv"},
+			}})
+			Expect(diagnostics).To(Equal([]rdjsonDiagnostic{
+				{
+					Message:  "Rule 'Layers': service calls controller",
+					Location: rdjsonLocation{Path: "service/handler.go", Range: &rdjsonRange{Start: rdjsonPosition{Line: 12, Column: 4}}},
+					Severity: "WARNING",
+					Source:   &rdjsonSource{Name: "aql"},
+					Code:     &rdjsonCode{Value: "aql"},
+				},
+				{
+					Message:  "osv",
+					Location: rdjsonLocation{Path: "go.mod"},
+					Severity: "ERROR",
+					Source:   &rdjsonSource{Name: "osv"},
+					Code:     &rdjsonCode{Value: "osv"},
+				},
+			}))
+		})
+	})
+
+	Context("Prometheus", func() {
+		It("writes violation counts and package gauges with labels", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "arch-unit.prom")
+			manager := NewOutputManager("prometheus")
+			manager.SetOutputFile(path)
+			manager.SetArchitecture(&models.ArchitectureReport{
+				Packages: []*models.PackageSummary{
+					{Package: "db", Methods: 2, AvgComplexity: 2.5, MaxComplexity: 4},
+					{Package: "service", Methods: 4, AvgComplexity: 3, MaxComplexity: 7},
+				},
+				Dependencies: []*models.DependencyEdge{{From: "service", To: "db", Count: 3}},
+			})
+			Expect(manager.Output(&models.AnalysisResult{
+				FileCount: 2,
+				Violations: []models.Violation{
+					{Source: "aql", Message: models.StringPtr("Rule 'no \"db\"': service calls db")},
+					{Source: "aql", Message: models.StringPtr("Rule 'no \"db\"': service calls db again")},
+					{Source: "golangci-lint", Severity: models.SeverityWarning, Rule: &models.Rule{Pattern: "errcheck"}},
+				},
+			})).To(Succeed())
+
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			metrics := string(data)
+			Expect(metrics).To(ContainSubstring("# TYPE arch_unit_violations gauge\n" +
+				`arch_unit_violations{source="aql",rule="no \"db\"",severity="error"} 2` + "\n" +
+				`arch_unit_violations{source="golangci-lint",rule="errcheck",severity="warning"} 1` + "\n"))
+			Expect(metrics).To(ContainSubstring("arch_unit_files_analyzed 2\n"))
+			Expect(metrics).To(ContainSubstring(`arch_unit_package_complexity_avg{package="db"} 2.5` + "\n"))
+			Expect(metrics).To(ContainSubstring(`arch_unit_package_dependencies{package="service"} 1` + "\n"))
+			Expect(metrics).To(ContainSubstring(`arch_unit_package_dependents{package="service"} 0` + "\n"))
+			Expect(metrics).To(ContainSubstring(`arch_unit_dependency_relationships{from="service",to="db"} 3` + "\n"))
+		})
+	})
+
+	Context("Diagrams", func() {
+		graph := &models.DependencyGraph{
+			Level: models.GraphLevelPackage,
+			Nodes: []string{"api/v1", "store"},
+			Edges: []*models.DependencyEdge{{From: "api/v1", To: "store", Count: 2}},
+		}
+
+		It("renders a mermaid flowchart", func() {
+			Expect(RenderGraph(graph, "mermaid")).To(Equal("flowchart LR\n    n0[\"api/v1\"]\n    n1[\"store\"]\n    n0 --> n1\n"))
+		})
+
+		It("renders plantuml packages", func() {
+			Expect(RenderGraph(graph, "plantuml")).To(Equal("@startuml\nleft to right direction\n" +
+				"package \"api/v1\" as n0 {\n}\npackage \"store\" as n1 {\n}\nn0 --> n1\n@enduml\n"))
+		})
+
+		It("renders a dot digraph with external libraries and relationship types", func() {
+			typed := &models.DependencyGraph{
+				Level:    models.GraphLevelType,
+				Nodes:    []string{"fmt", "store.DB", "store.Repository"},
+				External: []string{"fmt"},
+				Edges: []*models.DependencyEdge{
+					{From: "store.DB", To: "fmt", Count: 1, Type: models.RelationshipImport},
+					{From: "store.DB", To: "store.Repository", Count: 3, Type: models.RelationshipImplements},
+				},
+			}
+			Expect(RenderGraph(typed, "dot")).To(Equal(`digraph dependencies {
+    rankdir=LR;
+    node [shape=box];
+    n0 [label="fmt", style=dashed];
+    n1 [label="store.DB"];
+    n2 [label="store.Repository"];
+    n1 -> n0 [weight=1, label="import", style=dotted];
+    n1 -> n2 [weight=3, label="implements", style=dashed, arrowhead=empty];
+}
+`))
+		})
+
+		It("rejects unknown formats", func() {
+			_, err := RenderGraph(graph, "svg")
+			Expect(err).To(MatchError(ContainSubstring("unsupported graph format 'svg'")))
+		})
+	})
+
+	Context("NDJSONWriter", func() {
+		It("writes each violation on its own line as soon as it is written", func() {
+			var buf bytes.Buffer
+			writer := NewNDJSONWriter(&buf)
+
+			Expect(writer.Write(models.Violation{File: "a.go", Line: 1, Source: "aql"})).To(Succeed())
+			Expect(buf.String()).To(HaveSuffix("\n"))
+			Expect(writer.Write(models.Violation{File: "b.go", Line: 2, Source: "golangci-lint"})).To(Succeed())
+			Expect(writer.Count()).To(Equal(2))
+
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			Expect(lines).To(HaveLen(2))
+
+			var violation models.Violation
+			Expect(json.Unmarshal(lines[1], &violation)).To(Succeed())
+			Expect(violation.File).To(Equal("b.go"))
+			Expect(violation.Line).To(Equal(2))
+			Expect(violation.Source).To(Equal("golangci-lint"))
+		})
+	})
+})
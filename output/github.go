@@ -0,0 +1,134 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// GitHubCommentMarker identifies the pull request comment of arch-unit, so later runs update it
+const GitHubCommentMarker = "<!-- arch-unit -->"
+
+// githubLevel maps the severity of a violation to a workflow command, violations without a
+// severity count as errors
+func githubLevel(severity models.Severity) string {
+	switch severity {
+	case models.SeverityInfo:
+		return "notice"
+	case models.SeverityWarning:
+		return "warning"
+	}
+	return "error"
+}
+
+// githubEscapeData escapes the message of a workflow command
+func githubEscapeData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// githubEscapeProperty escapes a property of a workflow command, which also ends at : and ,
+func githubEscapeProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
+
+// writeGitHubAnnotations writes a ::error, ::warning or ::notice workflow command for every
+// violation, which GitHub Actions shows as annotations on the lines of the pull request
+func writeGitHubAnnotations(w io.Writer, result *models.AnalysisResult) error {
+	for _, v := range result.Violations {
+		title := sarifRuleID(v)
+		message := title
+		if v.Message != nil && *v.Message != "" {
+			message = *v.Message
+		}
+
+		var properties []string
+		if v.File != "" {
+			properties = append(properties, "file="+githubEscapeProperty(filepath.ToSlash(getRelativePath(v.File))))
+			if v.Line > 0 {
+				properties = append(properties, fmt.Sprintf("line=%d", v.Line))
+			}
+			if v.Column > 0 {
+				properties = append(properties, fmt.Sprintf("col=%d", v.Column))
+			}
+		}
+		properties = append(properties, "title="+githubEscapeProperty(title))
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", githubLevel(v.Severity), strings.Join(properties, ","), githubEscapeData(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *OutputManager) outputGitHub(result *models.AnalysisResult) error {
+	writer := os.Stdout
+	if o.output != "" {
+		file, err := os.Create(o.output)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		writer = file
+	}
+	return writeGitHubAnnotations(writer, result)
+}
+
+// GitHubComment summarizes violations for a pull request comment, counted by severity and rule.
+// With a baseline the violations are those it does not contain, and fixed those of the
+// baseline that are no longer reported.
+func GitHubComment(result *models.AnalysisResult, baseline bool, fixed int) string {
+	var sb strings.Builder
+	sb.WriteString(GitHubCommentMarker + "\n")
+	sb.WriteString("## Architecture check\n\n")
+
+	switch {
+	case baseline:
+		sb.WriteString(fmt.Sprintf("**%d new** and **%d fixed** violations compared to the baseline.\n", len(result.Violations), fixed))
+	case len(result.Violations) == 0:
+		sb.WriteString("No violations found.\n")
+	default:
+		sb.WriteString(fmt.Sprintf("**%d** violations found.\n", len(result.Violations)))
+	}
+	if len(result.Violations) == 0 {
+		return sb.String()
+	}
+
+	severities := make(map[string]int)
+	rules := make(map[string]int)
+	for _, v := range result.Violations {
+		severities[githubLevel(v.Severity)]++
+		rules[sarifRuleID(v)]++
+	}
+
+	sb.WriteString("\n| Severity | Violations |\n|----------|-----------:|\n")
+	for _, level := range []string{"error", "warning", "notice"} {
+		if severities[level] > 0 {
+			sb.WriteString(fmt.Sprintf("| %s | %d |\n", level, severities[level]))
+		}
+	}
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if rules[names[i]] != rules[names[j]] {
+			return rules[names[i]] > rules[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	sb.WriteString("\n| Rule | Violations |\n|------|-----------:|\n")
+	for i, name := range names {
+		if i == 10 {
+			sb.WriteString(fmt.Sprintf("| %d more rules | |\n", len(names)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | %d |\n", strings.ReplaceAll(name, "|", "\\|"), rules[name]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,540 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// maxGraphPackages limits the dependency graph to the largest packages, a circle of more is unreadable
+const maxGraphPackages = 100
+
+// aqlRuleName matches the rule named by the message of AQL violations, e.g. Rule 'layers': ...
+var aqlRuleName = regexp.MustCompile(`^Rule '([^']+)'`)
+
+// htmlReport is the data of the HTML report template
+type htmlReport struct {
+	Locale     string
+	FileCount  int
+	RuleCount  int
+	Violations []htmlViolation
+	Rules      []*htmlRule
+	Sources    []string
+	Graph      *htmlGraph
+	Heatmap    []htmlHeatCell
+	Queries    []*models.QueryReport
+}
+
+type htmlViolation struct {
+	Location      string
+	Line          int
+	Caller        string
+	CallerSummary string
+	Call          string
+	CallSummary   string
+	Rule          string
+	RuleSource    string
+	Message       string
+	Suggestion    string
+	Severity      string
+	Source        string
+	Package       string
+}
+
+// htmlRule summarises the violations of a rule
+type htmlRule struct {
+	Name     string
+	Source   string
+	Errors   int
+	Warnings int
+	Infos    int
+	Total    int
+}
+
+type htmlGraph struct {
+	Size  int
+	Nodes []htmlGraphNode
+	Edges []htmlGraphEdge
+}
+
+type htmlGraphNode struct {
+	Package    string
+	X, Y, R    float64
+	Violations int
+}
+
+type htmlGraphEdge struct {
+	From, To       string
+	X1, Y1, X2, Y2 float64
+	Width          float64
+	Count          int
+}
+
+type htmlHeatCell struct {
+	*models.PackageSummary
+	Violations int
+	Color      template.CSS
+}
+
+// SetArchitecture adds the packages and dependencies of the codebase to the HTML report
+func (o *OutputManager) SetArchitecture(report *models.ArchitectureReport) {
+	o.architecture = report
+}
+
+// outputHTML writes a self-contained interactive report: a summary of the violations of each
+// rule, the package dependency graph and complexity heatmap of the architecture when it was set,
+// and the violations with filters on their severity, source, rule and package
+func (o *OutputManager) outputHTML(result *models.AnalysisResult) error {
+	if o.output == "" {
+		return fmt.Errorf("output file required for HTML format")
+	}
+
+	file, err := os.Create(o.output)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	report := buildHTMLReport(result, o.architecture)
+	report.Queries = o.queryReports
+	return htmlTemplate.Execute(file, report)
+}
+
+// buildHTMLReport converts violations and the architecture into the data of the report
+func buildHTMLReport(result *models.AnalysisResult, architecture *models.ArchitectureReport) *htmlReport {
+	report := &htmlReport{Locale: i18n.Locale(), FileCount: result.FileCount, RuleCount: result.RuleCount}
+
+	rules := make(map[string]*htmlRule)
+	sources := make(map[string]bool)
+	packageViolations := make(map[string]int)
+	for _, v := range result.Violations {
+		severity := v.Severity
+		if severity == "" {
+			severity = models.SeverityError
+		}
+		row := htmlViolation{
+			Location: fmt.Sprintf("%s:%d:%d", getRelativePath(v.File), v.Line, v.Column),
+			Line:     v.Line,
+			Caller:   getCallerMethod(v),
+			Call:     getCalledName(v),
+			Rule:     RuleName(v),
+			Severity: string(severity),
+			Source:   v.Source,
+		}
+		if v.Rule != nil {
+			row.RuleSource = fmt.Sprintf("%s:%d", v.Rule.SourceFile, v.Rule.LineNumber)
+		}
+		if v.Message != nil {
+			row.Message = *v.Message
+		}
+		if v.Suggestion != nil {
+			row.Suggestion = *v.Suggestion
+		}
+		if v.Caller != nil {
+			row.Package = v.Caller.PackageName
+			row.CallerSummary = nodeSummary(v.Caller)
+			packageViolations[row.Package]++
+		}
+		if v.Called != nil {
+			row.CallSummary = nodeSummary(v.Called)
+		}
+		report.Violations = append(report.Violations, row)
+
+		rule, ok := rules[row.Rule]
+		if !ok {
+			rule = &htmlRule{Name: row.Rule, Source: row.Source}
+			rules[row.Rule] = rule
+			report.Rules = append(report.Rules, rule)
+		}
+		rule.Total++
+		switch severity {
+		case models.SeverityWarning:
+			rule.Warnings++
+		case models.SeverityInfo:
+			rule.Infos++
+		default:
+			rule.Errors++
+		}
+		if v.Source != "" && !sources[v.Source] {
+			sources[v.Source] = true
+			report.Sources = append(report.Sources, v.Source)
+		}
+	}
+	sort.SliceStable(report.Rules, func(i, j int) bool { return report.Rules[i].Total > report.Rules[j].Total })
+	sort.Strings(report.Sources)
+
+	if architecture != nil && len(architecture.Packages) > 0 {
+		report.Graph = buildHTMLGraph(architecture, packageViolations)
+		for _, pkg := range architecture.Packages {
+			report.Heatmap = append(report.Heatmap, htmlHeatCell{
+				PackageSummary: pkg,
+				Violations:     packageViolations[pkg.Package],
+				Color:          heatColor(pkg.AvgComplexity),
+			})
+		}
+		sort.SliceStable(report.Heatmap, func(i, j int) bool {
+			return report.Heatmap[i].AvgComplexity > report.Heatmap[j].AvgComplexity
+		})
+	}
+	return report
+}
+
+// buildHTMLGraph lays out the packages on a circle sized by their methods, with an arrow to each
+// package they depend on
+func buildHTMLGraph(architecture *models.ArchitectureReport, violations map[string]int) *htmlGraph {
+	packages := append([]*models.PackageSummary(nil), architecture.Packages...)
+	if len(packages) > maxGraphPackages {
+		sort.SliceStable(packages, func(i, j int) bool { return packages[i].Methods > packages[j].Methods })
+		packages = packages[:maxGraphPackages]
+		sort.Slice(packages, func(i, j int) bool { return packages[i].Package < packages[j].Package })
+	}
+
+	radius := math.Max(160, float64(len(packages))*14)
+	// Labels are drawn outside of the circle
+	center := radius + 140
+	graph := &htmlGraph{Size: int(2 * center)}
+	positions := make(map[string]htmlGraphNode, len(packages))
+	for i, pkg := range packages {
+		angle := 2*math.Pi*float64(i)/float64(len(packages)) - math.Pi/2
+		node := htmlGraphNode{
+			Package:    pkg.Package,
+			X:          center + radius*math.Cos(angle),
+			Y:          center + radius*math.Sin(angle),
+			R:          5 + math.Min(15, math.Sqrt(float64(pkg.Methods))),
+			Violations: violations[pkg.Package],
+		}
+		positions[pkg.Package] = node
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for _, dep := range architecture.Dependencies {
+		from, ok := positions[dep.From]
+		if !ok {
+			continue
+		}
+		to, ok := positions[dep.To]
+		if !ok {
+			continue
+		}
+		// End the arrow at the border of the target
+		dx, dy := to.X-from.X, to.Y-from.Y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		graph.Edges = append(graph.Edges, htmlGraphEdge{
+			From:  dep.From,
+			To:    dep.To,
+			X1:    from.X,
+			Y1:    from.Y,
+			X2:    to.X - dx/length*(to.R+3),
+			Y2:    to.Y - dy/length*(to.R+3),
+			Width: 1 + math.Log1p(float64(dep.Count)),
+			Count: dep.Count,
+		})
+	}
+	return graph
+}
+
+// heatColor returns a color from green for simple packages to red for an average complexity of
+// 15 and more
+func heatColor(complexity float64) template.CSS {
+	hue := 120 * (1 - math.Min(complexity, 15)/15)
+	return template.CSS(fmt.Sprintf("hsl(%.0f, 70%%, 80%%)", hue))
+}
+
+// RuleName names the rule a violation broke, AQL violations name it in their message
+func RuleName(v models.Violation) string {
+	if v.Rule == nil && v.Message != nil {
+		if match := aqlRuleName.FindStringSubmatch(*v.Message); match != nil {
+			return match[1]
+		}
+	}
+	return sarifRuleID(v)
+}
+
+// nodeSummary returns the AI summary of a node, shown as a tooltip of its cell
+func nodeSummary(node *models.ASTNode) string {
+	if node.Summary == nil {
+		return ""
+	}
+	return *node.Summary
+}
+
+// getCalledName returns the package and method a violation calls
+func getCalledName(v models.Violation) string {
+	if v.Called == nil {
+		return "unknown"
+	}
+	switch {
+	case v.Called.PackageName != "" && v.Called.MethodName != "":
+		return fmt.Sprintf("%s.%s", v.Called.PackageName, v.Called.MethodName)
+	case v.Called.PackageName != "":
+		return v.Called.PackageName
+	case v.Called.MethodName != "":
+		return v.Called.MethodName
+	}
+	return "unknown"
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"t":   func(id string) string { return i18n.T(id) },
+	"rel": getRelativePath,
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head>
+	<meta charset="utf-8">
+	<title>{{t "report.title"}}</title>
+	<style>
+		body { font-family: Arial, sans-serif; margin: 20px; color: #333; }
+		h1 { color: #333; }
+		h2 { margin-top: 30px; border-bottom: 1px solid #ddd; padding-bottom: 4px; }
+		.summary { background: #f0f0f0; padding: 10px; border-radius: 5px; margin-bottom: 20px; }
+		table { border-collapse: collapse; width: 100%; }
+		th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
+		th { background-color: #f2f2f2; }
+		tr:nth-child(even) { background-color: #f9f9f9; }
+		.violation { color: #d9534f; }
+		.no-violations { color: #5cb85c; font-size: 1.2em; padding: 20px; }
+		.severity-error { color: #d9534f; font-weight: bold; }
+		.severity-warning { color: #f0ad4e; font-weight: bold; }
+		.severity-info { color: #5bc0de; font-weight: bold; }
+		.suggestion { color: #666; font-style: italic; margin-top: 4px; }
+		.filters { display: flex; gap: 10px; margin-bottom: 10px; align-items: center; }
+		.filters input { flex: 1; padding: 6px; }
+		.filters select, .filters button { padding: 6px; }
+		[data-select-rule], [data-select-package] { cursor: pointer; }
+		.selected { outline: 3px solid #337ab7; }
+		#graph .edge { stroke: #bbb; fill: none; }
+		#graph .edge.highlight { stroke: #337ab7; }
+		#graph circle { fill: #9ecae1; stroke: #3182bd; }
+		#graph circle.has-violations { fill: #f4a6a4; stroke: #d9534f; }
+		#graph text { font-size: 11px; }
+		.heatmap { display: flex; flex-wrap: wrap; gap: 4px; }
+		.heatmap div { padding: 6px; border-radius: 3px; min-width: 120px; font-size: 12px; }
+		.heatmap strong { display: block; }
+	</style>
+</head>
+<body>
+	<h1>{{t "report.title"}}</h1>
+	<div class="summary">
+		<p><strong>{{t "report.files_analyzed"}}:</strong> {{.FileCount}}</p>
+		<p><strong>{{t "report.rules_applied"}}:</strong> {{.RuleCount}}</p>
+		<p><strong>{{t "report.violations_found"}}:</strong> <span class="violation">{{len .Violations}}</span></p>
+	</div>
+{{- if .Rules}}
+	<h2>{{t "report.rules"}}</h2>
+	<table id="rules">
+		<thead>
+			<tr>
+				<th>{{t "report.column.rule"}}</th>
+				<th>{{t "report.column.source"}}</th>
+				<th>error</th>
+				<th>warning</th>
+				<th>info</th>
+				<th>{{t "report.violations"}}</th>
+			</tr>
+		</thead>
+		<tbody>
+{{- range .Rules}}
+			<tr data-select-rule="{{.Name}}">
+				<td>{{.Name}}</td>
+				<td>{{.Source}}</td>
+				<td class="severity-error">{{.Errors}}</td>
+				<td class="severity-warning">{{.Warnings}}</td>
+				<td class="severity-info">{{.Infos}}</td>
+				<td>{{.Total}}</td>
+			</tr>
+{{- end}}
+		</tbody>
+	</table>
+{{- end}}
+{{- with .Graph}}
+	<h2>{{t "report.dependencies"}}</h2>
+	<svg id="graph" width="{{.Size}}" height="{{.Size}}" viewBox="0 0 {{.Size}} {{.Size}}" xmlns="http://www.w3.org/2000/svg">
+		<defs>
+			<marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse">
+				<path d="M 0 0 L 10 5 L 0 10 z" fill="#999"></path>
+			</marker>
+		</defs>
+{{- range .Edges}}
+		<line class="edge" data-from="{{.From}}" data-to="{{.To}}" x1="{{printf "%.1f" .X1}}" y1="{{printf "%.1f" .Y1}}" x2="{{printf "%.1f" .X2}}" y2="{{printf "%.1f" .Y2}}" stroke-width="{{printf "%.1f" .Width}}" marker-end="url(#arrow)"><title>{{.From}} → {{.To}} ({{.Count}})</title></line>
+{{- end}}
+{{- range .Nodes}}
+		<g data-select-package="{{.Package}}">
+			<circle cx="{{printf "%.1f" .X}}" cy="{{printf "%.1f" .Y}}" r="{{printf "%.1f" .R}}"{{if .Violations}} class="has-violations"{{end}}><title>{{.Package}} ({{.Violations}})</title></circle>
+			<text x="{{printf "%.1f" .X}}" y="{{printf "%.1f" .Y}}" dx="{{printf "%.1f" .R}}" dy="-4">{{.Package}}</text>
+		</g>
+{{- end}}
+	</svg>
+{{- end}}
+{{- if .Heatmap}}
+	<h2>{{t "report.complexity"}}</h2>
+	<div class="heatmap">
+{{- range .Heatmap}}
+		<div data-select-package="{{.Package}}" style="background: {{.Color}}" title="{{t "report.column.methods"}}: {{.Methods}}, {{t "report.column.lines"}}: {{.Lines}}">
+			<strong>{{.Package}}</strong>
+			{{t "report.column.avg_complexity"}}: {{printf "%.1f" .AvgComplexity}}, {{t "report.column.max_complexity"}}: {{.MaxComplexity}}
+			{{- if .Violations}}, <span class="violation">{{t "report.violations"}}: {{.Violations}}</span>{{end}}
+		</div>
+{{- end}}
+	</div>
+{{- end}}
+{{- range .Queries}}
+	<h2>{{if .Title}}{{.Title}}{{else}}{{.Name}}{{end}}</h2>
+{{- range .Results}}
+	<h3>{{.Name}} ({{.Count}})</h3>
+{{- if .Description}}
+	<p>{{.Description}}</p>
+{{- end}}
+	<p><code>{{.Query}}</code></p>
+{{- if .Matches}}
+	<table data-query="{{.Name}}">
+		<thead>
+			<tr>
+				<th>{{t "report.column.name"}}</th>
+				<th>{{t "report.column.file"}}</th>
+				<th>{{t "report.column.line"}}</th>
+				<th>{{t "report.column.message"}}</th>
+			</tr>
+		</thead>
+		<tbody>
+{{- range .Matches}}
+			<tr>
+				<td>{{.Name}}</td>
+				<td>{{rel .File}}</td>
+				<td>{{.Line}}</td>
+				<td>{{.Message}}</td>
+			</tr>
+{{- end}}
+		</tbody>
+	</table>
+{{- else}}
+	<p>{{t "report.no_matches"}}</p>
+{{- end}}
+{{- end}}
+{{- end}}
+	<h2>{{t "report.violations"}}</h2>
+{{- if not .Violations}}
+	<div class="no-violations">✓ {{t "report.no_violations"}}</div>
+{{- else}}
+	<div class="filters">
+		<input id="filter-text" type="search" placeholder="{{t "report.filter.search"}}">
+		<select id="filter-severity">
+			<option value="">{{t "report.filter.all_severities"}}</option>
+			<option value="error">error</option>
+			<option value="warning">warning</option>
+			<option value="info">info</option>
+		</select>
+		<select id="filter-source">
+			<option value="">{{t "report.filter.all_sources"}}</option>
+{{- range .Sources}}
+			<option value="{{.}}">{{.}}</option>
+{{- end}}
+		</select>
+		<button id="filter-clear" type="button">{{t "report.filter.clear"}}</button>
+		<span><span id="shown">{{len .Violations}}</span> / {{len .Violations}}</span>
+	</div>
+	<table id="violations">
+		<thead>
+			<tr>
+				<th>{{t "report.column.file"}}</th>
+				<th>{{t "report.column.line"}}</th>
+				<th>{{t "report.column.caller"}}</th>
+				<th>{{t "report.column.violation"}}</th>
+				<th>{{t "report.column.rule"}}</th>
+				<th>{{t "report.column.rule_source"}}</th>
+				<th>{{t "report.column.severity"}}</th>
+				<th>{{t "report.column.message"}}</th>
+			</tr>
+		</thead>
+		<tbody>
+{{- range .Violations}}
+			<tr data-severity="{{.Severity}}" data-source="{{.Source}}" data-rule="{{.Rule}}" data-package="{{.Package}}">
+				<td>{{.Location}}</td>
+				<td>{{.Line}}</td>
+				<td{{with .CallerSummary}} title="{{.}}"{{end}}>{{.Caller}}</td>
+				<td class="violation"{{with .CallSummary}} title="{{.}}"{{end}}>{{.Call}}</td>
+				<td>{{.Rule}}</td>
+				<td>{{.RuleSource}}</td>
+				<td class="severity-{{.Severity}}">{{.Severity}}</td>
+				<td>{{.Message}}{{with .Suggestion}}<div class="suggestion">💡 {{.}}</div>{{end}}</td>
+			</tr>
+{{- end}}
+		</tbody>
+	</table>
+{{- end}}
+	<script>
+	(function () {
+		var filters = { text: "", severity: "", source: "", rule: "", pkg: "" };
+		var rows = document.querySelectorAll("#violations tbody tr");
+
+		function apply() {
+			var shown = 0;
+			rows.forEach(function (row) {
+				var d = row.dataset;
+				var visible = (!filters.severity || d.severity === filters.severity) &&
+					(!filters.source || d.source === filters.source) &&
+					(!filters.rule || d.rule === filters.rule) &&
+					(!filters.pkg || d.package === filters.pkg) &&
+					(!filters.text || row.textContent.toLowerCase().indexOf(filters.text) >= 0);
+				row.hidden = !visible;
+				if (visible) {
+					shown++;
+				}
+			});
+			var counter = document.getElementById("shown");
+			if (counter) {
+				counter.textContent = shown;
+			}
+			document.querySelectorAll("[data-select-package]").forEach(function (el) {
+				el.classList.toggle("selected", el.dataset.selectPackage === filters.pkg);
+			});
+			document.querySelectorAll("[data-select-rule]").forEach(function (el) {
+				el.classList.toggle("selected", el.dataset.selectRule === filters.rule);
+			});
+			document.querySelectorAll("#graph .edge").forEach(function (el) {
+				el.classList.toggle("highlight", filters.pkg !== "" && (el.dataset.from === filters.pkg || el.dataset.to === filters.pkg));
+			});
+		}
+
+		function bind(id, event, update) {
+			var el = document.getElementById(id);
+			if (el) {
+				el.addEventListener(event, function () { update(el); apply(); });
+			}
+		}
+		bind("filter-text", "input", function (el) { filters.text = el.value.toLowerCase(); });
+		bind("filter-severity", "change", function (el) { filters.severity = el.value; });
+		bind("filter-source", "change", function (el) { filters.source = el.value; });
+		bind("filter-clear", "click", function () {
+			filters = { text: "", severity: "", source: "", rule: "", pkg: "" };
+			["filter-text", "filter-severity", "filter-source"].forEach(function (id) {
+				document.getElementById(id).value = "";
+			});
+		});
+
+		// Clicking a package or rule drills down to its violations, clicking it again shows all
+		document.querySelectorAll("[data-select-package]").forEach(function (el) {
+			el.addEventListener("click", function () {
+				filters.pkg = filters.pkg === el.dataset.selectPackage ? "" : el.dataset.selectPackage;
+				apply();
+			});
+		});
+		document.querySelectorAll("[data-select-rule]").forEach(function (el) {
+			el.addEventListener("click", function () {
+				filters.rule = filters.rule === el.dataset.selectRule ? "" : el.dataset.selectRule;
+				apply();
+			});
+		});
+	})();
+	</script>
+</body>
+</html>
+`))
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// NDJSONWriter writes violations as newline-delimited JSON, one object per line, as soon as
+// they are written so downstream processors can consume them while the analysis is running
+type NDJSONWriter struct {
+	mu         sync.Mutex
+	encoder    *json.Encoder
+	count      int
+	severities map[models.Severity]int
+}
+
+// NewNDJSONWriter creates a writer streaming violations to w
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{encoder: json.NewEncoder(w), severities: make(map[models.Severity]int)}
+}
+
+// Write writes a single violation, it is safe for concurrent use
+func (n *NDJSONWriter) Write(violation models.Violation) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.encoder.Encode(violation); err != nil {
+		return err
+	}
+	n.count++
+	n.severities[violation.Severity]++
+	return nil
+}
+
+// Count returns the number of violations written
+func (n *NDJSONWriter) Count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.count
+}
+
+// CountAtLeast returns the number of violations written that are at least as serious as threshold
+func (n *NDJSONWriter) CountAtLeast(threshold models.Severity) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	count := 0
+	for severity, c := range n.severities {
+		if severity.IsAtLeast(threshold) {
+			count += c
+		}
+	}
+	return count
+}
+
+func (o *OutputManager) outputNDJSON(result *models.AnalysisResult) error {
+	writer := os.Stdout
+	if o.output != "" {
+		file, err := os.Create(o.output)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		writer = file
+	}
+
+	ndjson := NewNDJSONWriter(writer)
+	for _, v := range result.Violations {
+		if err := ndjson.Write(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// prometheusLabelValue escapes a label value of the Prometheus text exposition format
+var prometheusLabelValue = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// prometheusSample is a sample of a metric family, labels are name and value pairs
+type prometheusSample struct {
+	labels []string
+	value  float64
+}
+
+// writePrometheusFamily writes the help, type and samples of a gauge
+func writePrometheusFamily(w io.Writer, name, help string, samples []prometheusSample) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	for _, sample := range samples {
+		var labels []string
+		for i := 0; i+1 < len(sample.labels); i += 2 {
+			labels = append(labels, fmt.Sprintf(`%s="%s"`, sample.labels[i], prometheusLabelValue.Replace(sample.labels[i+1])))
+		}
+		if len(labels) > 0 {
+			_, _ = fmt.Fprintf(w, "%s{%s} %v\n", name, strings.Join(labels, ","), sample.value)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s %v\n", name, sample.value)
+		}
+	}
+}
+
+// outputPrometheus writes violation counts and, when the architecture was set, the size,
+// complexity and dependencies of packages as gauges of the Prometheus text exposition format,
+// e.g. for the textfile collector of the node exporter or a Pushgateway
+func (o *OutputManager) outputPrometheus(result *models.AnalysisResult) error {
+	writer := os.Stdout
+	if o.output != "" {
+		file, err := os.Create(o.output)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		writer = file
+	}
+
+	writePrometheusFamily(writer, "arch_unit_files_analyzed", "Number of files analyzed.",
+		[]prometheusSample{{value: float64(result.FileCount)}})
+	writePrometheusFamily(writer, "arch_unit_rules_applied", "Number of rules applied.",
+		[]prometheusSample{{value: float64(result.RuleCount)}})
+
+	type violationKey struct{ source, rule, severity string }
+	counts := make(map[violationKey]int)
+	for _, v := range result.Violations {
+		severity := v.Severity
+		if severity == "" {
+			severity = models.SeverityError
+		}
+		counts[violationKey{v.Source, RuleName(v), string(severity)}]++
+	}
+	keys := make([]violationKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.source != b.source {
+			return a.source < b.source
+		}
+		if a.rule != b.rule {
+			return a.rule < b.rule
+		}
+		return a.severity < b.severity
+	})
+	var violations []prometheusSample
+	for _, key := range keys {
+		violations = append(violations, prometheusSample{
+			labels: []string{"source", key.source, "rule", key.rule, "severity", key.severity},
+			value:  float64(counts[key]),
+		})
+	}
+	writePrometheusFamily(writer, "arch_unit_violations", "Number of violations by source, rule and severity.", violations)
+
+	if o.architecture != nil {
+		writePrometheusArchitecture(writer, o.architecture)
+	}
+	return nil
+}
+
+// writePrometheusArchitecture writes the size, complexity and coupling of every package and the
+// number of relationships between packages
+func writePrometheusArchitecture(w io.Writer, architecture *models.ArchitectureReport) {
+	afferent := make(map[string]int)
+	efferent := make(map[string]int)
+	var relationships []prometheusSample
+	for _, dep := range architecture.Dependencies {
+		efferent[dep.From]++
+		afferent[dep.To]++
+		relationships = append(relationships, prometheusSample{
+			labels: []string{"from", dep.From, "to", dep.To},
+			value:  float64(dep.Count),
+		})
+	}
+
+	families := []struct {
+		name, help string
+		value      func(*models.PackageSummary) float64
+	}{
+		{"arch_unit_package_files", "Number of files of a package.", func(p *models.PackageSummary) float64 { return float64(p.Files) }},
+		{"arch_unit_package_lines", "Number of lines of the files of a package.", func(p *models.PackageSummary) float64 { return float64(p.Lines) }},
+		{"arch_unit_package_types", "Number of types of a package.", func(p *models.PackageSummary) float64 { return float64(p.Types) }},
+		{"arch_unit_package_methods", "Number of functions and methods of a package.", func(p *models.PackageSummary) float64 { return float64(p.Methods) }},
+		{"arch_unit_package_complexity_avg", "Average cyclomatic complexity of the methods of a package.", func(p *models.PackageSummary) float64 { return p.AvgComplexity }},
+		{"arch_unit_package_complexity_max", "Highest cyclomatic complexity of the methods of a package.", func(p *models.PackageSummary) float64 { return float64(p.MaxComplexity) }},
+		{"arch_unit_package_dependencies", "Number of packages a package depends on.", func(p *models.PackageSummary) float64 { return float64(efferent[p.Package]) }},
+		{"arch_unit_package_dependents", "Number of packages depending on a package.", func(p *models.PackageSummary) float64 { return float64(afferent[p.Package]) }},
+	}
+	for _, family := range families {
+		samples := make([]prometheusSample, 0, len(architecture.Packages))
+		for _, pkg := range architecture.Packages {
+			samples = append(samples, prometheusSample{labels: []string{"package", pkg.Package}, value: family.value(pkg)})
+		}
+		writePrometheusFamily(w, family.name, family.help, samples)
+	}
+	writePrometheusFamily(w, "arch_unit_dependency_relationships", "Number of relationships from the nodes of a package to those of another.", relationships)
+}
@@ -0,0 +1,48 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/flanksource/arch-unit/internal/i18n"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// SetQueryReports adds the results of the saved reports of arch-unit.yaml to the HTML and markdown
+// reports
+func (o *OutputManager) SetQueryReports(reports []*models.QueryReport) {
+	o.queryReports = reports
+}
+
+// writeMarkdownQueryReports writes a section per saved report with a table of the matches of each
+// of its queries
+func writeMarkdownQueryReports(w io.Writer, reports []*models.QueryReport) {
+	escape := func(s string) string { return strings.ReplaceAll(s, "|", "\\|") }
+	for _, report := range reports {
+		title := report.Title
+		if title == "" {
+			title = report.Name
+		}
+		_, _ = fmt.Fprintf(w, "## %s\n\n", title)
+		for _, result := range report.Results {
+			_, _ = fmt.Fprintf(w, "### %s (%d)\n\n", result.Name, result.Count)
+			if result.Description != "" {
+				_, _ = fmt.Fprintf(w, "%s\n\n", result.Description)
+			}
+			_, _ = fmt.Fprintf(w, "`%s`\n\n", result.Query)
+			if len(result.Matches) == 0 {
+				_, _ = fmt.Fprintf(w, "%s\n\n", i18n.T("report.no_matches"))
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "| %s | %s | %s | %s |\n", i18n.T("report.column.name"),
+				i18n.T("report.column.file"), i18n.T("report.column.line"), i18n.T("report.column.message"))
+			_, _ = fmt.Fprintln(w, "|------|------|------|---------|")
+			for _, match := range result.Matches {
+				_, _ = fmt.Fprintf(w, "| %s | %s | %d | %s |\n",
+					escape(match.Name), getRelativePath(match.File), match.Line, escape(match.Message))
+			}
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+}
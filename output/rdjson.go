@@ -0,0 +1,119 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// rdjsonResult is a Reviewdog Diagnostic Format result of the rdjson format
+type rdjsonResult struct {
+	Source      rdjsonSource       `json:"source"`
+	Diagnostics []rdjsonDiagnostic `json:"diagnostics"`
+}
+
+type rdjsonSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// rdjsonDiagnostic is a single diagnostic, also written on its own line by the rdjsonl format
+type rdjsonDiagnostic struct {
+	Message  string         `json:"message"`
+	Location rdjsonLocation `json:"location"`
+	Severity string         `json:"severity"`
+	Source   *rdjsonSource  `json:"source,omitempty"`
+	Code     *rdjsonCode    `json:"code,omitempty"`
+}
+
+type rdjsonLocation struct {
+	Path  string       `json:"path"`
+	Range *rdjsonRange `json:"range,omitempty"`
+}
+
+type rdjsonRange struct {
+	Start rdjsonPosition `json:"start"`
+}
+
+type rdjsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+type rdjsonCode struct {
+	Value string `json:"value"`
+}
+
+// rdjsonSeverity maps the severity of a violation to a Reviewdog severity, violations without a
+// severity count as errors
+func rdjsonSeverity(severity models.Severity) string {
+	switch severity {
+	case models.SeverityInfo:
+		return "INFO"
+	case models.SeverityWarning:
+		return "WARNING"
+	}
+	return "ERROR"
+}
+
+// buildRDJSONDiagnostics converts violations into Reviewdog diagnostics, each naming the linter
+// that reported it and the rule it broke
+func buildRDJSONDiagnostics(result *models.AnalysisResult) []rdjsonDiagnostic {
+	diagnostics := []rdjsonDiagnostic{}
+	for _, v := range result.Violations {
+		code := sarifRuleID(v)
+		message := code
+		if v.Message != nil && *v.Message != "" {
+			message = *v.Message
+		}
+
+		diagnostic := rdjsonDiagnostic{
+			Message:  message,
+			Location: rdjsonLocation{Path: filepath.ToSlash(getRelativePath(v.File))},
+			Severity: rdjsonSeverity(v.Severity),
+			Code:     &rdjsonCode{Value: code},
+		}
+		if v.Source != "" {
+			diagnostic.Source = &rdjsonSource{Name: v.Source}
+		}
+		if v.Line > 0 {
+			diagnostic.Location.Range = &rdjsonRange{Start: rdjsonPosition{Line: v.Line, Column: v.Column}}
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	return diagnostics
+}
+
+func (o *OutputManager) outputRDJSON(result *models.AnalysisResult, lines bool) error {
+	writer := os.Stdout
+	if o.output != "" {
+		file, err := os.Create(o.output)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		writer = file
+	}
+
+	diagnostics := buildRDJSONDiagnostics(result)
+	encoder := json.NewEncoder(writer)
+	if lines {
+		for _, diagnostic := range diagnostics {
+			if err := encoder.Encode(diagnostic); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rdjsonResult{
+		Source: rdjsonSource{
+			Name: "arch-unit",
+			URL:  "https://github.com/flanksource/arch-unit",
+		},
+		Diagnostics: diagnostics,
+	})
+}
@@ -0,0 +1,168 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID     string           `json:"ruleId"`
+	Level      string           `json:"level"`
+	Message    sarifMessage     `json:"message"`
+	Locations  []sarifLocation  `json:"locations,omitempty"`
+	Properties *sarifProperties `json:"properties,omitempty"`
+}
+
+// sarifProperties is the property bag of a result
+type sarifProperties struct {
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifRuleID identifies the rule a violation broke, falling back to the tool that reported it
+func sarifRuleID(v models.Violation) string {
+	if v.Rule != nil {
+		if id := strings.TrimLeft(v.Rule.String(), "!+"); id != "" {
+			return id
+		}
+	}
+	if v.Source != "" {
+		return v.Source
+	}
+	return "arch-unit"
+}
+
+// buildSARIF converts violations into a SARIF log with a single arch-unit run
+func buildSARIF(result *models.AnalysisResult) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "arch-unit",
+			InformationURI: "https://github.com/flanksource/arch-unit",
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	for _, v := range result.Violations {
+		ruleID := sarifRuleID(v)
+		if !rules[ruleID] {
+			rules[ruleID] = true
+			description := ruleID
+			if v.Rule != nil && v.Rule.OriginalLine != "" {
+				description = v.Rule.OriginalLine
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               ruleID,
+				ShortDescription: sarifMessage{Text: description},
+			})
+		}
+
+		message := ruleID
+		if v.Message != nil && *v.Message != "" {
+			message = *v.Message
+		}
+
+		// Suggestions of --explain are shown with the message by code scanning tools
+		var properties *sarifProperties
+		if v.Suggestion != nil && *v.Suggestion != "" {
+			message += "\n\nSuggestion: " + *v.Suggestion
+			properties = &sarifProperties{Suggestion: *v.Suggestion}
+		}
+
+		sr := sarifResult{
+			RuleID:     ruleID,
+			Level:      "error",
+			Message:    sarifMessage{Text: message},
+			Properties: properties,
+		}
+		if v.File != "" {
+			location := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(getRelativePath(v.File))},
+			}
+			if v.Line > 0 {
+				location.Region = &sarifRegion{StartLine: v.Line, StartColumn: v.Column}
+			}
+			sr.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		run.Results = append(run.Results, sr)
+	}
+
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	return sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}
+}
+
+func (o *OutputManager) outputSARIF(result *models.AnalysisResult) error {
+	writer := os.Stdout
+	if o.output != "" {
+		file, err := os.Create(o.output)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		writer = file
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildSARIF(result))
+}
@@ -24,6 +24,7 @@ const (
 	TokenForbid
 	TokenRequire
 	TokenAllow
+	TokenCycle
 
 	// Operators
 	TokenGT    // >
@@ -33,6 +34,7 @@ const (
 	TokenEQ    // ==
 	TokenNE    // !=
 	TokenArrow // ->
+	TokenPath  // ->>
 
 	// Delimiters
 	TokenLBrace // {
@@ -75,6 +77,7 @@ var tokenTypeNames = map[TokenType]string{
 	TokenForbid:  "FORBID",
 	TokenRequire: "REQUIRE",
 	TokenAllow:   "ALLOW",
+	TokenCycle:   "CYCLE",
 	TokenGT:      ">",
 	TokenLT:      "<",
 	TokenGTE:     ">=",
@@ -82,6 +85,7 @@ var tokenTypeNames = map[TokenType]string{
 	TokenEQ:      "==",
 	TokenNE:      "!=",
 	TokenArrow:   "->",
+	TokenPath:    "->>",
 	TokenLBrace:  "{",
 	TokenRBrace:  "}",
 	TokenLParen:  "(",
@@ -98,6 +102,7 @@ var keywords = map[string]TokenType{
 	"FORBID":  TokenForbid,
 	"REQUIRE": TokenRequire,
 	"ALLOW":   TokenAllow,
+	"CYCLE":   TokenCycle,
 }
 
 // Lexer represents a lexical analyzer
@@ -183,7 +188,8 @@ func (l *Lexer) skipComment() {
 // readIdentifier reads an identifier
 func (l *Lexer) readIdentifier() string {
 	start := l.position - 1 // Account for current character
-	for unicode.IsLetter(l.current) || unicode.IsDigit(l.current) || l.current == '_' || l.current == '*' {
+	// Slashes separate the language of a pattern, e.g. sql/*
+	for unicode.IsLetter(l.current) || unicode.IsDigit(l.current) || l.current == '_' || l.current == '*' || l.current == '/' {
 		l.readChar()
 	}
 	return l.input[start : l.position-1]
@@ -320,6 +326,10 @@ func (l *Lexer) NextToken() Token {
 		if l.peekChar() == '>' {
 			l.readChar()
 			l.readChar()
+			if l.current == '>' {
+				l.readChar()
+				return Token{TokenPath, "->>", startLine, startColumn, l.start}
+			}
 			return Token{TokenArrow, "->", startLine, startColumn, l.start}
 		}
 		l.readChar()
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/flanksource/arch-unit/models"
 )
@@ -106,12 +107,25 @@ func (p *Parser) parseRule() (*models.AQLRule, error) {
 	lineNumber := p.currentToken.Line
 	p.nextToken()
 
+	// Optional severity, e.g. RULE "name" SEVERITY warning { ... }
+	var severity models.Severity
+	if p.currentTokenIs(TokenIdent) && strings.EqualFold(p.currentToken.Value, "severity") {
+		p.nextToken()
+		var err error
+		if severity, err = models.ParseSeverity(p.currentToken.Value); err != nil || !p.currentTokenIs(TokenIdent) {
+			p.addError(fmt.Sprintf("expected severity error, warning or info, got %s", p.currentToken.Value))
+			return nil, fmt.Errorf("expected severity")
+		}
+		p.nextToken()
+	}
+
 	if !p.expectToken(TokenLBrace) {
 		return nil, fmt.Errorf("expected '{' after rule name")
 	}
 
 	rule := &models.AQLRule{
 		Name:       ruleName,
+		Severity:   severity,
 		LineNumber: lineNumber,
 		Statements: []*models.AQLStatement{},
 	}
@@ -151,6 +165,8 @@ func (p *Parser) parseStatement() (*models.AQLStatement, error) {
 		return p.parseRequireStatement()
 	case TokenAllow:
 		return p.parseAllowStatement()
+	case TokenCycle:
+		return p.parseCycleStatement()
 	default:
 		p.addError(fmt.Sprintf("unexpected token: %s", p.currentToken.Value))
 		p.nextToken() // Skip invalid token
@@ -166,7 +182,13 @@ func (p *Parser) parseLimitStatement() (*models.AQLStatement, error) {
 		return nil, fmt.Errorf("expected '(' after LIMIT")
 	}
 
-	condition, err := p.parseCondition()
+	expression, err := p.parseExpression(func() (*models.AQLExpression, error) {
+		condition, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		return &models.AQLExpression{Condition: condition}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -175,10 +197,16 @@ func (p *Parser) parseLimitStatement() (*models.AQLStatement, error) {
 		return nil, fmt.Errorf("expected ')' after condition")
 	}
 
+	if expression.Condition != nil {
+		return &models.AQLStatement{
+			Type:      models.AQLStatementLimit,
+			Pattern:   expression.Condition.Pattern,
+			Condition: expression.Condition,
+		}, nil
+	}
 	return &models.AQLStatement{
-		Type:      models.AQLStatementLimit,
-		Pattern:   condition.Pattern,
-		Condition: condition,
+		Type:       models.AQLStatementLimit,
+		Expression: expression,
 	}, nil
 }
 
@@ -190,6 +218,11 @@ func (p *Parser) parseForbidStatement() (*models.AQLStatement, error) {
 		return nil, fmt.Errorf("expected '(' after FORBID")
 	}
 
+	// Check if it's a path pattern (contains ->>)
+	if p.isPathPattern() {
+		return p.parsePathPattern()
+	}
+
 	// Check if it's a relationship pattern (contains ->)
 	if p.isRelationshipPattern() {
 		fromPattern, toPattern, err := p.parseRelationshipPattern()
@@ -208,8 +241,14 @@ func (p *Parser) parseForbidStatement() (*models.AQLStatement, error) {
 		}, nil
 	}
 
-	// Single pattern
-	pattern, err := p.parsePattern()
+	// Single pattern, or patterns combined with AND, OR and NOT
+	expression, err := p.parseExpression(func() (*models.AQLExpression, error) {
+		pattern, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+		return &models.AQLExpression{Pattern: pattern}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -218,9 +257,15 @@ func (p *Parser) parseForbidStatement() (*models.AQLStatement, error) {
 		return nil, fmt.Errorf("expected ')' after pattern")
 	}
 
+	if expression.Pattern != nil {
+		return &models.AQLStatement{
+			Type:    models.AQLStatementForbid,
+			Pattern: expression.Pattern,
+		}, nil
+	}
 	return &models.AQLStatement{
-		Type:    models.AQLStatementForbid,
-		Pattern: pattern,
+		Type:       models.AQLStatementForbid,
+		Expression: expression,
 	}, nil
 }
 
@@ -308,8 +353,55 @@ func (p *Parser) parseAllowStatement() (*models.AQLStatement, error) {
 	}, nil
 }
 
+// parseCycleStatement parses a CYCLE statement, CYCLE(pattern) or CYCLE(pattern, package|type)
+func (p *Parser) parseCycleStatement() (*models.AQLStatement, error) {
+	p.nextToken() // consume CYCLE
+
+	if !p.expectToken(TokenLParen) {
+		return nil, fmt.Errorf("expected '(' after CYCLE")
+	}
+
+	pattern, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &models.AQLStatement{
+		Type:    models.AQLStatementCycle,
+		Pattern: pattern,
+	}
+
+	if p.currentTokenIs(TokenComma) {
+		p.nextToken()
+		level := models.AQLCycleLevel(p.currentToken.Value)
+		if !p.currentTokenIs(TokenIdent) || (level != models.AQLCycleLevelPackage && level != models.AQLCycleLevelType) {
+			p.addError(fmt.Sprintf("expected cycle level package or type, got %s", p.currentToken.Value))
+			return nil, fmt.Errorf("expected cycle level")
+		}
+		stmt.Level = level
+		p.nextToken()
+	}
+
+	if !p.expectToken(TokenRParen) {
+		return nil, fmt.Errorf("expected ')' after pattern")
+	}
+
+	return stmt, nil
+}
+
 // isRelationshipPattern checks if the current position contains a relationship pattern (->)
 func (p *Parser) isRelationshipPattern() bool {
+	return p.containsToken(TokenArrow)
+}
+
+// isPathPattern checks if the current position contains a path pattern (->>)
+func (p *Parser) isPathPattern() bool {
+	return p.containsToken(TokenPath)
+}
+
+// containsToken checks if a token of a type follows the current position before the closing
+// parenthesis of the statement
+func (p *Parser) containsToken(tokenType TokenType) bool {
 	// Simple lookahead to check for arrow
 	// This is a simplified check - in a full parser we'd need better lookahead
 	lexerCopy := NewLexer(p.lexer.input[p.currentToken.Position:])
@@ -328,7 +420,7 @@ func (p *Parser) isRelationshipPattern() bool {
 			if depth < 0 {
 				break
 			}
-		} else if token.Type == TokenArrow && depth == 0 {
+		} else if token.Type == tokenType && depth == 0 {
 			return true
 		}
 	}
@@ -355,8 +447,130 @@ func (p *Parser) parseRelationshipPattern() (*models.AQLPattern, *models.AQLPatt
 	return fromPattern, toPattern, nil
 }
 
-// parseCondition parses a condition expression
+// parsePathPattern parses the path pattern of a FORBID statement, pattern ->> pattern with an
+// optional maximum number of hops, e.g. Controller* ->> sql/*, 3
+func (p *Parser) parsePathPattern() (*models.AQLStatement, error) {
+	fromPattern, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.expectToken(TokenPath) {
+		return nil, fmt.Errorf("expected '->>' in path pattern")
+	}
+
+	toPattern, err := p.parsePattern()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &models.AQLStatement{
+		Type:        models.AQLStatementForbid,
+		FromPattern: fromPattern,
+		ToPattern:   toPattern,
+		Transitive:  true,
+	}
+
+	if p.currentTokenIs(TokenComma) {
+		p.nextToken()
+		hops, err := strconv.Atoi(p.currentToken.Value)
+		if !p.currentTokenIs(TokenNumber) || err != nil || hops < 1 {
+			p.addError(fmt.Sprintf("expected a positive number of hops, got %s", p.currentToken.Value))
+			return nil, fmt.Errorf("expected number of hops")
+		}
+		stmt.MaxHops = hops
+		p.nextToken()
+	}
+
+	if !p.expectToken(TokenRParen) {
+		return nil, fmt.Errorf("expected ')' after path pattern")
+	}
+
+	return stmt, nil
+}
+
+// parseExpression parses the leaves parsed by leaf combined with AND, OR and NOT and grouped with
+// parentheses, AND binding tighter than OR, e.g. a > 1 AND (b > 2 OR NOT c > 3)
+func (p *Parser) parseExpression(leaf func() (*models.AQLExpression, error)) (*models.AQLExpression, error) {
+	expression, err := p.parseOrExpression(leaf)
+	if err != nil {
+		return nil, err
+	}
+	if err := expression.Validate(); err != nil {
+		p.addError(err.Error())
+		return nil, err
+	}
+	return expression, nil
+}
+
+// parseOrExpression parses AND expressions joined by OR
+func (p *Parser) parseOrExpression(leaf func() (*models.AQLExpression, error)) (*models.AQLExpression, error) {
+	return p.parseLogicalExpression(models.AQLLogicalOr, func() (*models.AQLExpression, error) {
+		return p.parseLogicalExpression(models.AQLLogicalAnd, func() (*models.AQLExpression, error) {
+			return p.parseUnaryExpression(leaf)
+		})
+	})
+}
+
+// parseLogicalExpression parses operands joined by a logical operator
+func (p *Parser) parseLogicalExpression(operator models.AQLLogicalOperator, operand func() (*models.AQLExpression, error)) (*models.AQLExpression, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*models.AQLExpression{first}
+	for p.isLogicalOperator(operator) {
+		p.nextToken()
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &models.AQLExpression{Operator: operator, Operands: operands}, nil
+}
+
+// parseUnaryExpression parses a negation, a parenthesized expression or a leaf
+func (p *Parser) parseUnaryExpression(leaf func() (*models.AQLExpression, error)) (*models.AQLExpression, error) {
+	if p.isLogicalOperator(models.AQLLogicalNot) {
+		p.nextToken()
+		operand, err := p.parseUnaryExpression(leaf)
+		if err != nil {
+			return nil, err
+		}
+		return &models.AQLExpression{Operator: models.AQLLogicalNot, Operands: []*models.AQLExpression{operand}}, nil
+	}
+
+	if p.currentTokenIs(TokenLParen) {
+		p.nextToken()
+		expression, err := p.parseOrExpression(leaf)
+		if err != nil {
+			return nil, err
+		}
+		if !p.expectToken(TokenRParen) {
+			return nil, fmt.Errorf("expected ')' to close group")
+		}
+		return expression, nil
+	}
+
+	return leaf()
+}
+
+// isLogicalOperator checks if the current token is a logical operator. Operators are upper case so
+// that packages named and, or and not remain patterns.
+func (p *Parser) isLogicalOperator(operator models.AQLLogicalOperator) bool {
+	return p.currentTokenIs(TokenIdent) && p.currentToken.Value == string(operator)
+}
+
+// parseCondition parses a condition expression, on the metric of nodes or on an aggregate
 func (p *Parser) parseCondition() (*models.AQLCondition, error) {
+	if _, ok := models.ParseAggregateFunction(p.currentToken.Value); ok && p.currentTokenIs(TokenIdent) && p.peekToken.Type == TokenLParen {
+		return p.parseAggregateCondition()
+	}
+
 	pattern, err := p.parsePattern()
 	if err != nil {
 		return nil, err
@@ -396,11 +610,87 @@ func (p *Parser) parseCondition() (*models.AQLCondition, error) {
 	}, nil
 }
 
+// parseAggregateCondition parses a condition on an aggregate of the nodes of each package or type,
+// FUNCTION(pattern[.selector][.metric][, package|type]) operator value, e.g. COUNT(api.methods) > 200
+func (p *Parser) parseAggregateCondition() (*models.AQLCondition, error) {
+	function, _ := models.ParseAggregateFunction(p.currentToken.Value)
+	p.nextToken() // consume the function
+	p.nextToken() // consume (
+
+	patternText, err := p.readPatternText()
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := models.ParsePattern(patternText)
+	if err != nil {
+		p.addError(fmt.Sprintf("invalid pattern: %v", err))
+		return nil, err
+	}
+	metric := pattern.Metric
+	if metric != "" {
+		patternText = patternText[:len(patternText)-len("."+metric)]
+	}
+	patternText, selector := models.CutAggregateSelector(patternText)
+	if pattern, err = models.ParsePattern(patternText); err != nil {
+		p.addError(fmt.Sprintf("invalid pattern: %v", err))
+		return nil, err
+	}
+
+	aggregate := &models.AQLAggregate{Function: function, Selector: selector}
+	if p.currentTokenIs(TokenComma) {
+		p.nextToken()
+		aggregate.Level = models.AQLAggregateLevel(p.currentToken.Value)
+		p.nextToken()
+	}
+	if err := aggregate.Validate(metric); err != nil {
+		p.addError(err.Error())
+		return nil, err
+	}
+
+	if !p.expectToken(TokenRParen) {
+		return nil, fmt.Errorf("expected ')' after aggregate")
+	}
+
+	operator, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AQLCondition{
+		Pattern:   pattern,
+		Property:  metric,
+		Aggregate: aggregate,
+		Operator:  operator,
+		Value:     value,
+	}, nil
+}
+
 // parsePattern parses a pattern expression
 func (p *Parser) parsePattern() (*models.AQLPattern, error) {
+	patternText, err := p.readPatternText()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := models.ParsePattern(patternText)
+	if err != nil {
+		p.addError(fmt.Sprintf("invalid pattern: %v", err))
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+// readPatternText reads the identifiers of a pattern with their . and : delimiters
+func (p *Parser) readPatternText() (string, error) {
 	if !p.currentTokenIs(TokenIdent) {
 		p.addError("expected pattern identifier")
-		return nil, fmt.Errorf("expected pattern")
+		return "", fmt.Errorf("expected pattern")
 	}
 
 	patternText := p.currentToken.Value
@@ -413,20 +703,14 @@ func (p *Parser) parsePattern() (*models.AQLPattern, error) {
 
 		if !p.currentTokenIs(TokenIdent) {
 			p.addError("expected identifier after " + delimiter)
-			return nil, fmt.Errorf("expected identifier")
+			return "", fmt.Errorf("expected identifier")
 		}
 
 		patternText += delimiter + p.currentToken.Value
 		p.nextToken()
 	}
 
-	pattern, err := models.ParsePattern(patternText)
-	if err != nil {
-		p.addError(fmt.Sprintf("invalid pattern: %v", err))
-		return nil, err
-	}
-
-	return pattern, nil
+	return patternText, nil
 }
 
 // parseOperator parses a comparison operator
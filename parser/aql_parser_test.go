@@ -81,6 +81,177 @@ var _ = Describe("AQL Parser", func() {
 		})
 	})
 
+	Describe("parsing cycle statements", func() {
+		It("should parse a CYCLE statement with and without a level", func() {
+			aql := `RULE "No cycles" {
+				CYCLE(*)
+				CYCLE(*Service*, package)
+			}`
+
+			ruleSet, err := parser.ParseAQL(aql)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ruleSet.Rules[0].Statements).To(HaveLen(2))
+
+			stmt := ruleSet.Rules[0].Statements[0]
+			Expect(stmt.Type).To(Equal(models.AQLStatementCycle))
+			Expect(stmt.Pattern.Package).To(Equal("*"))
+			Expect(stmt.Level).To(BeEmpty())
+			Expect(stmt.CycleLevel()).To(Equal(models.AQLCycleLevelPackage))
+			Expect(stmt.String()).To(Equal("CYCLE(*)"))
+
+			stmt = ruleSet.Rules[0].Statements[1]
+			Expect(stmt.Pattern.Type).To(Equal("*Service*"))
+			Expect(stmt.Level).To(Equal(models.AQLCycleLevelPackage))
+			Expect(stmt.String()).To(Equal("CYCLE(*Service*, package)"))
+		})
+
+		It("should detect cycles among types when the pattern names a type", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No cycles" { CYCLE(*Service*) }`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ruleSet.Rules[0].Statements[0].CycleLevel()).To(Equal(models.AQLCycleLevelType))
+		})
+
+		It("should reject unknown levels", func() {
+			_, err := parser.ParseAQL(`RULE "No cycles" { CYCLE(*, method) }`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("expected cycle level package or type, got method"))
+		})
+	})
+
+	Describe("parsing rule severity", func() {
+		It("should parse the severity of a rule", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Layers" SEVERITY warning { FORBID(service -> controller) }`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ruleSet.Rules[0].Severity).To(Equal(models.SeverityWarning))
+			Expect(ruleSet.Rules[0].String()).To(HavePrefix(`RULE "Layers" SEVERITY warning {`))
+		})
+
+		It("should reject unknown severities", func() {
+			_, err := parser.ParseAQL(`RULE "Layers" SEVERITY fatal { FORBID(service -> controller) }`)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("parsing path statements", func() {
+		It("should parse transitive FORBID statements", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No SQL in controllers" { FORBID(Controller* ->> sql/*) }`)
+			Expect(err).NotTo(HaveOccurred())
+
+			stmt := ruleSet.Rules[0].Statements[0]
+			Expect(stmt.Type).To(Equal(models.AQLStatementForbid))
+			Expect(stmt.Transitive).To(BeTrue())
+			Expect(stmt.Hops()).To(Equal(models.DefaultMaxHops))
+			Expect(stmt.FromPattern.Type).To(Equal("Controller*"))
+			Expect(stmt.ToPattern.Language).To(Equal("sql"))
+			Expect(stmt.ToPattern.Package).To(Equal("*"))
+		})
+
+		It("should parse the number of hops", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No SQL in controllers" { FORBID(controller ->> repository, 2) }`)
+			Expect(err).NotTo(HaveOccurred())
+
+			stmt := ruleSet.Rules[0].Statements[0]
+			Expect(stmt.MaxHops).To(Equal(2))
+			Expect(stmt.String()).To(Equal("FORBID(controller ->> repository, 2)"))
+		})
+
+		It("should reject paths in other statements and invalid hops", func() {
+			_, err := parser.ParseAQL(`RULE "Paths" { REQUIRE(controller ->> repository) }`)
+			Expect(err).To(HaveOccurred())
+
+			_, err = parser.ParseAQL(`RULE "Paths" { FORBID(controller ->> repository, 0) }`)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("parsing package metrics", func() {
+		It("should parse conditions on the coupling metrics of packages", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Stable core" { LIMIT(package.instability > 0.8) }`)
+			Expect(err).NotTo(HaveOccurred())
+
+			condition := ruleSet.Rules[0].Statements[0].Condition
+			Expect(condition.Property).To(Equal(models.MetricInstability))
+			Expect(condition.Pattern.Package).To(Equal("package"))
+			Expect(condition.Value).To(Equal(0.8))
+		})
+	})
+
+	Describe("parsing aggregates", func() {
+		It("should parse counts of the nodes of a kind", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Small packages" { LIMIT(COUNT(api.methods) > 200) }`)
+			Expect(err).NotTo(HaveOccurred())
+
+			condition := ruleSet.Rules[0].Statements[0].Condition
+			Expect(condition.Aggregate).To(Equal(&models.AQLAggregate{Function: models.AQLAggregateCount, Selector: "methods"}))
+			Expect(condition.Pattern.Package).To(Equal("api"))
+			Expect(condition.Property).To(BeEmpty())
+			Expect(condition.String()).To(Equal("COUNT(api.methods) > 200"))
+		})
+
+		It("should parse aggregates of metrics with their level", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Simple types" { LIMIT(avg(service.*.cyclomatic, type) > 7) }`)
+			Expect(err).NotTo(HaveOccurred())
+
+			condition := ruleSet.Rules[0].Statements[0].Condition
+			Expect(condition.Aggregate).To(Equal(&models.AQLAggregate{Function: models.AQLAggregateAvg, Level: models.AQLAggregateLevelType}))
+			Expect(condition.Pattern.Package).To(Equal("service"))
+			Expect(condition.Property).To(Equal("cyclomatic"))
+			Expect(condition.String()).To(Equal("AVG(service.*.cyclomatic, type) > 7"))
+		})
+
+		DescribeTable("rejecting invalid aggregates",
+			func(aql string) {
+				_, err := parser.ParseAQL(aql)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("metric aggregate without metric", `RULE "Test" { LIMIT(AVG(api.methods) > 7) }`),
+			Entry("count of a metric", `RULE "Test" { LIMIT(COUNT(*.cyclomatic) > 7) }`),
+			Entry("aggregate of a package metric", `RULE "Test" { LIMIT(MAX(*.instability) > 0.5) }`),
+			Entry("invalid level", `RULE "Test" { LIMIT(COUNT(*.methods, file) > 7) }`),
+		)
+	})
+
+	Describe("parsing boolean expressions", func() {
+		It("should bind AND tighter than OR and keep single conditions", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Complexity" {
+				LIMIT(*.cyclomatic > 10 OR *.lines > 100 AND NOT *Test*.lines > 0)
+				LIMIT(*.cyclomatic > 10)
+			}`)
+			Expect(err).NotTo(HaveOccurred())
+
+			statements := ruleSet.Rules[0].Statements
+			expression := statements[0].Expression
+			Expect(expression.Operator).To(Equal(models.AQLLogicalOr))
+			Expect(expression.Operands).To(HaveLen(2))
+			Expect(expression.Operands[1].Operator).To(Equal(models.AQLLogicalAnd))
+			Expect(expression.Operands[1].Operands[1].Operator).To(Equal(models.AQLLogicalNot))
+			Expect(statements[0].String()).To(Equal("LIMIT(*.cyclomatic > 10 OR (*.lines > 100 AND NOT *Test*.lines > 0))"))
+
+			Expect(statements[1].Expression).To(BeNil())
+			Expect(statements[1].Condition).NotTo(BeNil())
+		})
+
+		It("should parse parenthesized patterns of FORBID statements", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Layers" { FORBID((controller OR service) AND NOT *Legacy*) }`)
+			Expect(err).NotTo(HaveOccurred())
+
+			statement := ruleSet.Rules[0].Statements[0]
+			Expect(statement.Pattern).To(BeNil())
+			Expect(statement.String()).To(Equal("FORBID((controller OR service) AND NOT *Legacy*)"))
+		})
+
+		DescribeTable("rejecting invalid expressions",
+			func(aql string) {
+				_, err := parser.ParseAQL(aql)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("missing operand", `RULE "Test" { LIMIT(*.cyclomatic > 10 AND) }`),
+			Entry("unclosed group", `RULE "Test" { FORBID((controller OR service) }`),
+			Entry("combined aggregate", `RULE "Test" { LIMIT(COUNT(*.methods) > 10 OR *.lines > 100) }`),
+			Entry("combined package metric", `RULE "Test" { LIMIT(*.instability > 0.5 AND *.lines > 100) }`),
+		)
+	})
+
 	Describe("parsing multiple rules", func() {
 		It("should parse multiple rules in a single file", func() {
 			aql := `
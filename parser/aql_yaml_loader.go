@@ -74,6 +74,14 @@ func validateRule(rule *models.AQLRule, index int) error {
 		return fmt.Errorf("rule must contain at least one statement")
 	}
 
+	if rule.Severity != "" {
+		severity, err := models.ParseSeverity(string(rule.Severity))
+		if err != nil {
+			return err
+		}
+		rule.Severity = severity
+	}
+
 	for j, stmt := range rule.Statements {
 		if err := validateStatement(stmt, j); err != nil {
 			return fmt.Errorf("statement %d: %w", j, err)
@@ -87,12 +95,27 @@ func validateRule(rule *models.AQLRule, index int) error {
 func validateStatement(stmt *models.AQLStatement, index int) error {
 	switch stmt.Type {
 	case models.AQLStatementLimit:
+		if stmt.Expression != nil {
+			return validateExpression(stmt.Expression)
+		}
 		if stmt.Condition == nil {
 			return fmt.Errorf("LIMIT statement requires a condition")
 		}
 		return validateCondition(stmt.Condition)
 
 	case models.AQLStatementForbid, models.AQLStatementRequire, models.AQLStatementAllow:
+		if (stmt.Transitive || stmt.MaxHops != 0) && (stmt.Type != models.AQLStatementForbid || stmt.FromPattern == nil || stmt.ToPattern == nil) {
+			return fmt.Errorf("transitive paths are only supported by FORBID statements with from_pattern and to_pattern")
+		}
+		if stmt.MaxHops < 0 || (stmt.MaxHops > 0 && !stmt.Transitive) {
+			return fmt.Errorf("max_hops must be positive and requires transitive")
+		}
+		if stmt.Expression != nil {
+			if stmt.Type != models.AQLStatementForbid {
+				return fmt.Errorf("expressions are only supported by LIMIT and FORBID statements")
+			}
+			return validateExpression(stmt.Expression)
+		}
 		// These can have either a single pattern or from/to patterns
 		if stmt.Pattern != nil {
 			return validatePattern(stmt.Pattern)
@@ -108,11 +131,41 @@ func validateStatement(stmt *models.AQLStatement, index int) error {
 		}
 		return fmt.Errorf("%s statement requires either a pattern or both from_pattern and to_pattern", stmt.Type)
 
+	case models.AQLStatementCycle:
+		if stmt.Pattern == nil {
+			return fmt.Errorf("CYCLE statement requires a pattern")
+		}
+		switch stmt.Level {
+		case "", models.AQLCycleLevelPackage, models.AQLCycleLevelType:
+		default:
+			return fmt.Errorf("invalid cycle level '%s', expected package or type", stmt.Level)
+		}
+		return validatePattern(stmt.Pattern)
+
 	default:
 		return fmt.Errorf("unknown statement type: %s", stmt.Type)
 	}
 }
 
+// validateExpression validates the operands of an expression and its conditions or patterns
+func validateExpression(expression *models.AQLExpression) error {
+	if err := expression.Validate(); err != nil {
+		return fmt.Errorf("expression: %w", err)
+	}
+	if expression.Condition != nil {
+		return validateCondition(expression.Condition)
+	}
+	if expression.Pattern != nil {
+		return validatePattern(expression.Pattern)
+	}
+	for _, operand := range expression.Operands {
+		if err := validateExpression(operand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // validateCondition performs validation on a condition
 func validateCondition(condition *models.AQLCondition) error {
 	if condition.Pattern == nil {
@@ -123,8 +176,16 @@ func validateCondition(condition *models.AQLCondition) error {
 		return fmt.Errorf("pattern: %w", err)
 	}
 
-	// Check that the pattern has a metric or the condition has a property (backward compatibility)
-	if condition.Pattern.Metric == "" && condition.Property == "" {
+	metric := condition.Pattern.Metric
+	if metric == "" {
+		metric = condition.Property
+	}
+	if condition.Aggregate != nil {
+		if err := condition.Aggregate.Validate(metric); err != nil {
+			return fmt.Errorf("aggregate: %w", err)
+		}
+	} else if metric == "" {
+		// Check that the pattern has a metric or the condition has a property (backward compatibility)
 		return fmt.Errorf("condition requires a metric in the pattern or property field")
 	}
 
@@ -168,7 +229,8 @@ func validatePattern(pattern *models.AQLPattern) error {
 
 	// Validate metric if specified
 	if pattern.Metric != "" {
-		validMetrics := []string{"cyclomatic", "parameters", "params", "returns", "lines"}
+		validMetrics := []string{"cyclomatic", "parameters", "params", "returns", "lines", models.MetricCoverage,
+			models.MetricAfferent, models.MetricEfferent, models.MetricInstability, models.MetricAbstractness, models.MetricDistance}
 		valid := false
 		for _, metric := range validMetrics {
 			if pattern.Metric == metric {
@@ -206,6 +206,26 @@ rules:
             metric: "cyclomatic"
           operator: ">"
 `, "condition requires a value"),
+			Entry("invalid cycle level", `
+rules:
+  - name: "Test Rule"
+    statements:
+      - type: CYCLE
+        pattern:
+          package: "*"
+        level: method
+`, "invalid cycle level 'method'"),
+			Entry("transitive require", `
+rules:
+  - name: "Test Rule"
+    statements:
+      - type: REQUIRE
+        from_pattern:
+          package: "controller"
+        to_pattern:
+          package: "repository"
+        transitive: true
+`, "transitive paths are only supported by FORBID statements"),
 		)
 	})
 
@@ -0,0 +1,234 @@
+// Package archtest asserts architecture rules in Go tests, backed by the same AST cache and
+// extractors as the arch-unit CLI:
+//
+//	func TestArchitecture(t *testing.T) {
+//		archtest.Packages("./...").That().ResideIn("internal/api").ShouldNotDependOn("internal/db").Check(t)
+//	}
+//
+// Packages are selected with go list style patterns relative to the working directory of the
+// test, directories of ResideIn and ShouldNotDependOn are relative to the root of the module.
+package archtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	_ "github.com/flanksource/arch-unit/analysis/go"
+	"github.com/flanksource/arch-unit/ast"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/query"
+)
+
+// TestingT is the subset of testing.TB used to report violations
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// scope is a directory of packages, with its subdirectories when recursive
+type scope struct {
+	dir       string
+	recursive bool
+}
+
+// PackageSet is a selection of packages rules are asserted on
+type PackageSet struct {
+	root   string
+	scopes []scope
+	err    error
+}
+
+// Packages selects the packages matching go list style patterns, e.g. ./... or ./internal/api
+func Packages(patterns ...string) *PackageSet {
+	set := &PackageSet{}
+	cwd, err := os.Getwd()
+	if err != nil {
+		set.err = fmt.Errorf("failed to get working directory: %w", err)
+		return set
+	}
+	set.root = moduleRoot(cwd)
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	for _, pattern := range patterns {
+		dir, recursive := strings.CutSuffix(filepath.ToSlash(pattern), "/...")
+		if pattern == "..." {
+			dir, recursive = ".", true
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cwd, dir)
+		}
+		set.scopes = append(set.scopes, scope{dir: filepath.Clean(dir), recursive: recursive})
+	}
+	return set
+}
+
+// That starts the conditions packages are filtered with
+func (s *PackageSet) That() *PackageSet {
+	return s
+}
+
+// And chains conditions
+func (s *PackageSet) And() *PackageSet {
+	return s
+}
+
+// ResideIn keeps the packages in one of the directories or their subdirectories
+func (s *PackageSet) ResideIn(dirs ...string) *PackageSet {
+	filtered := &PackageSet{root: s.root, err: s.err}
+	for _, dir := range dirs {
+		within := scope{dir: s.abs(dir), recursive: true}
+		for _, sc := range s.scopes {
+			if intersection, ok := sc.intersect(within); ok {
+				filtered.scopes = append(filtered.scopes, intersection)
+			}
+		}
+	}
+	return filtered
+}
+
+// ShouldNotDependOn asserts that no package of the set calls, embeds or otherwise depends on a
+// node in one of the directories or their subdirectories
+func (s *PackageSet) ShouldNotDependOn(dirs ...string) *Rule {
+	rule := &Rule{
+		set: s,
+		aql: &models.AQLRule{Name: fmt.Sprintf("%s should not depend on %s", s, strings.Join(dirs, ", "))},
+	}
+	for _, sc := range s.scopes {
+		for _, dir := range dirs {
+			rule.aql.Statements = append(rule.aql.Statements, &models.AQLStatement{
+				Type:        models.AQLStatementForbid,
+				FromPattern: &models.AQLPattern{FilePath: sc.glob(), Original: sc.glob()},
+				ToPattern:   &models.AQLPattern{FilePath: s.abs(dir) + "/**", Original: dir},
+			})
+		}
+	}
+	return rule
+}
+
+// String returns the directories of the set relative to the module root
+func (s *PackageSet) String() string {
+	var dirs []string
+	for _, sc := range s.scopes {
+		dir, err := filepath.Rel(s.root, sc.dir)
+		if err != nil {
+			dir = sc.dir
+		}
+		if sc.recursive {
+			dir += "/..."
+		}
+		dirs = append(dirs, filepath.ToSlash(dir))
+	}
+	return strings.Join(dirs, ", ")
+}
+
+func (s *PackageSet) abs(dir string) string {
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(s.root, dir)
+}
+
+// intersect returns the packages in both scopes
+func (sc scope) intersect(other scope) (scope, bool) {
+	switch {
+	case sc.contains(other.dir):
+		if sc.recursive || sc.dir == other.dir {
+			return scope{dir: other.dir, recursive: other.recursive && sc.recursive}, true
+		}
+	case other.contains(sc.dir):
+		if other.recursive {
+			return sc, true
+		}
+	}
+	return scope{}, false
+}
+
+// contains returns true if dir is the directory of the scope or one of its subdirectories
+func (sc scope) contains(dir string) bool {
+	return dir == sc.dir || strings.HasPrefix(dir, sc.dir+string(filepath.Separator))
+}
+
+// glob returns the doublestar glob of the Go files of the scope
+func (sc scope) glob() string {
+	if sc.recursive {
+		return filepath.ToSlash(sc.dir) + "/**/*.go"
+	}
+	return filepath.ToSlash(sc.dir) + "/*.go"
+}
+
+// Rule is an architecture rule over a set of packages
+type Rule struct {
+	set *PackageSet
+	aql *models.AQLRule
+}
+
+// Violations analyzes the module, reusing the AST cache, and returns the violations of the rule
+func (r *Rule) Violations() ([]*models.Violation, error) {
+	if r.set.err != nil {
+		return nil, r.set.err
+	}
+	astCache, err := analyze(r.set.root)
+	if err != nil {
+		return nil, err
+	}
+	return query.NewAQLEngine(astCache).ExecuteRule(r.aql)
+}
+
+// Check reports every violation of the rule as a test error
+func (r *Rule) Check(t TestingT) {
+	t.Helper()
+	violations, err := r.Violations()
+	if err != nil {
+		t.Fatalf("failed to check %s: %v", r.aql.Name, err)
+		return
+	}
+	for _, v := range violations {
+		file, err := filepath.Rel(r.set.root, v.File)
+		if err != nil {
+			file = v.File
+		}
+		t.Errorf("%s:%d: %s", file, v.Line, *v.Message)
+	}
+}
+
+var (
+	analyzedMu sync.Mutex
+	analyzed   = make(map[string]bool)
+)
+
+// analyze extracts the AST of the Go files of a module once per test binary, files that did not
+// change since they were cached are not extracted again
+func analyze(root string) (*cache.ASTCache, error) {
+	analyzedMu.Lock()
+	defer analyzedMu.Unlock()
+
+	astCache, err := cache.GetASTCache()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open AST cache: %w", err)
+	}
+	if !analyzed[root] {
+		if err := ast.NewAnalyzer(astCache, root).AnalyzeFilesWithFilter([]string{"**/*.go"}, []string{"**/*_test.go"}); err != nil {
+			return nil, fmt.Errorf("failed to analyze %s: %w", root, err)
+		}
+		analyzed[root] = true
+	}
+	return astCache, nil
+}
+
+// moduleRoot returns the closest directory with a go.mod, or dir itself
+func moduleRoot(dir string) string {
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			return current
+		}
+		if filepath.Dir(current) == current {
+			return dir
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package archtest
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestArchtest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Archtest Suite")
+}
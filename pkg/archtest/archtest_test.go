@@ -0,0 +1,45 @@
+package archtest
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Packages", func() {
+	var root string
+
+	BeforeEach(func() {
+		cwd, err := os.Getwd()
+		Expect(err).ToNot(HaveOccurred())
+		root = moduleRoot(cwd)
+		Expect(filepath.Join(root, "go.mod")).To(BeAnExistingFile())
+	})
+
+	It("selects packages relative to the module root", func() {
+		set := Packages(root+"/...").That().ResideIn("internal/api", "cmd")
+		Expect(set.scopes).To(Equal([]scope{
+			{dir: filepath.Join(root, "internal/api"), recursive: true},
+			{dir: filepath.Join(root, "cmd"), recursive: true},
+		}))
+		Expect(set.String()).To(Equal("internal/api/..., cmd/..."))
+	})
+
+	It("intersects package patterns with directories", func() {
+		set := Packages(root+"/internal/api", root+"/cmd/...").That().ResideIn("internal")
+		Expect(set.String()).To(Equal("internal/api"))
+
+		set = Packages(root + "/internal").That().ResideIn("internal/api")
+		Expect(set.scopes).To(BeEmpty())
+	})
+
+	It("builds a FORBID statement per package and dependency", func() {
+		rule := Packages(root+"/...").That().ResideIn("internal/api").ShouldNotDependOn("internal/db", "internal/cache")
+		Expect(rule.aql.Name).To(Equal("internal/api/... should not depend on internal/db, internal/cache"))
+		Expect(rule.aql.Statements).To(HaveLen(2))
+		Expect(rule.aql.Statements[0].FromPattern.FilePath).To(Equal(filepath.ToSlash(root) + "/internal/api/**/*.go"))
+		Expect(rule.aql.Statements[1].ToPattern.FilePath).To(Equal(filepath.Join(root, "internal/cache") + "/**"))
+	})
+})
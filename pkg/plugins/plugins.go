@@ -0,0 +1,117 @@
+// Package plugins loads Go plugins shipping custom extractors, linters and rule evaluators.
+//
+// A plugin is a main package built with go build -buildmode=plugin against the same version of
+// arch-unit and Go as the arch-unit binary, exporting a Register function:
+//
+//	func Register(registry *plugins.Registry) error {
+//		registry.RegisterLinter(NewMyLinter("."))
+//		return nil
+//	}
+//
+// Plugins are listed under plugins in arch-unit.yaml.
+package plugins
+
+import (
+	"fmt"
+	"path/filepath"
+	goplugin "plugin"
+	"sync"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/analysis/types"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/linters"
+	"github.com/flanksource/arch-unit/linters/aql"
+	"github.com/flanksource/commons/logger"
+)
+
+// Types implemented by plugins, aliased here as some of them live in internal packages
+type (
+	Extractor     = analysis.Extractor
+	ReadOnlyCache = cache.ReadOnlyCache
+	ASTResult     = types.ASTResult
+	Linter        = linters.Linter
+	RunOptions    = linters.RunOptions
+	RuleEvaluator = aql.RuleEvaluator
+)
+
+// RegisterSymbol is the name of the function every plugin exports
+const RegisterSymbol = "Register"
+
+// Registry registers the extensions of a plugin with the registries of arch-unit
+type Registry struct {
+	path string
+}
+
+// RegisterLinter adds a linter, run like the built-in ones when enabled under linters
+func (r *Registry) RegisterLinter(linter Linter) {
+	logger.Debugf("Plugin %s registered the %s linter", r.path, linter.Name())
+	linters.DefaultRegistry.Register(linter)
+}
+
+// RegisterExtractor adds the AST extractor of a language, used for files with one of the
+// extensions, e.g. .kt
+func (r *Registry) RegisterExtractor(language string, extractor Extractor, extensions ...string) {
+	logger.Debugf("Plugin %s registered the %s extractor", r.path, language)
+	analysis.DefaultExtractorRegistry.Register(language, extractor)
+	for _, ext := range extensions {
+		analysis.DefaultExtractorRegistry.RegisterExtension(ext, language)
+	}
+}
+
+// RegisterRuleEvaluator adds a rule evaluator, run by the aql linter
+func (r *Registry) RegisterRuleEvaluator(evaluator RuleEvaluator) {
+	logger.Debugf("Plugin %s registered the %s rule evaluator", r.path, evaluator.Name())
+	aql.RegisterRuleEvaluator(evaluator)
+}
+
+var (
+	mu     sync.Mutex
+	loaded = make(map[string]bool)
+)
+
+// Load opens a plugin and calls its Register function, plugins that are already loaded are
+// skipped
+func Load(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if loaded[path] {
+		return nil
+	}
+
+	p, err := goplugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+	symbol, err := p.Lookup(RegisterSymbol)
+	if err != nil {
+		return fmt.Errorf("plugin %s does not export %s: %w", path, RegisterSymbol, err)
+	}
+	register, ok := symbol.(func(*Registry) error)
+	if !ok {
+		return fmt.Errorf("plugin %s exports %s as %T, expected func(*plugins.Registry) error", path, RegisterSymbol, symbol)
+	}
+	if err := register(&Registry{path: path}); err != nil {
+		return fmt.Errorf("plugin %s failed to register: %w", path, err)
+	}
+	loaded[path] = true
+	return nil
+}
+
+// LoadAll loads the plugins of a configuration, relative to its directory
+func LoadAll(configDir string, paths []string) error {
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(configDir, path)
+		}
+		if err := Load(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
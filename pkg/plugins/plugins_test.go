@@ -0,0 +1,50 @@
+package plugins
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/analysis"
+	"github.com/flanksource/arch-unit/linters/aql"
+	"github.com/flanksource/arch-unit/models"
+)
+
+type kotlinExtractor struct{}
+
+func (kotlinExtractor) ExtractFile(cache ReadOnlyCache, path string, content []byte) (*ASTResult, error) {
+	return nil, nil
+}
+
+type todoEvaluator struct{}
+
+func (todoEvaluator) Name() string { return "todo" }
+
+func (todoEvaluator) Evaluate(input *models.PolicyInput) ([]models.Violation, error) {
+	return nil, nil
+}
+
+var _ = Describe("Plugins", func() {
+	registry := &Registry{path: "test.so"}
+
+	It("registers extractors for their file extensions", func() {
+		registry.RegisterExtractor("kotlin", kotlinExtractor{}, ".kt", ".KTS")
+
+		extractor, language, ok := analysis.DefaultExtractorRegistry.GetExtractorForFile("/repo/Main.kts")
+		Expect(ok).To(BeTrue())
+		Expect(language).To(Equal("kotlin"))
+		Expect(extractor).To(Equal(kotlinExtractor{}))
+	})
+
+	It("registers rule evaluators with the aql linter", func() {
+		registry.RegisterRuleEvaluator(todoEvaluator{})
+		Expect(aql.RuleEvaluators()).To(ContainElement(todoEvaluator{}))
+	})
+
+	It("fails to load missing plugins", func() {
+		err := LoadAll(GinkgoT().TempDir(), []string{"missing.so"})
+		Expect(err).To(MatchError(ContainSubstring("failed to open plugin")))
+		Expect(err.Error()).To(ContainSubstring(filepath.Join("missing.so")))
+	})
+})
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// executeAggregateLimit executes a LIMIT statement on an aggregate, reporting each package or type
+// whose nodes roll up to a violating value at its first node
+func (e *AQLEngine) executeAggregateLimit(rule *models.AQLRule, condition *models.AQLCondition, metric string) ([]*models.Violation, error) {
+	nodes, err := e.findMatchingNodes(condition.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate := condition.Aggregate
+	metricPattern := &models.AQLPattern{Metric: metric}
+	values := make(map[string][]float64)
+	first := make(map[string]*models.ASTNode)
+	var scopes []string
+	for _, node := range nodes {
+		if !aggregate.Includes(node, metric) {
+			continue
+		}
+		scope, ok := aggregate.ScopeOf(node, condition.Pattern)
+		if !ok {
+			continue
+		}
+
+		value := 1.0
+		if metric != "" {
+			v, err := metricPattern.GetMetricValue(node)
+			if err != nil {
+				return nil, fmt.Errorf("failed to evaluate condition: %w", err)
+			}
+			value = float64(v)
+		}
+
+		if _, ok := values[scope]; !ok {
+			scopes = append(scopes, scope)
+		}
+		values[scope] = append(values[scope], value)
+		if f := first[scope]; f == nil || node.FilePath < f.FilePath || (node.FilePath == f.FilePath && node.StartLine < f.StartLine) {
+			first[scope] = node
+		}
+	}
+	sort.Strings(scopes)
+
+	level := aggregate.Scope(condition.Pattern)
+	expr := aggregate.String(condition.Pattern, metric)
+	var violations []*models.Violation
+	for _, scope := range scopes {
+		value := aggregate.Compute(values[scope])
+		violated, err := condition.Compare(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate condition: %w", err)
+		}
+		if !violated {
+			continue
+		}
+
+		node := first[scope]
+		violations = append(violations, &models.Violation{
+			File: node.FilePath,
+			Line: node.StartLine,
+			Caller: &models.ASTNode{
+				FilePath:    node.FilePath,
+				PackageName: node.PackageName,
+				StartLine:   node.StartLine,
+				NodeType:    models.NodeTypePackage,
+			},
+			Called: aggregate.ScopeNode(node, condition.Pattern),
+			Message: models.StringPtr(fmt.Sprintf("Rule '%s': %s %s has %s %v, violating %s %v",
+				rule.Name, level, scope, expr, value, condition.Operator, condition.Value)),
+			Source: "aql",
+		})
+	}
+	return violations, nil
+}
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// ExecuteAnnotations reports every node with an annotation forbidden by an annotation rule, or
+// without an annotation it requires
+func (e *AQLEngine) ExecuteAnnotations(rules []models.AnnotationRule) ([]*models.Violation, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	// Annotations are only recorded in the metadata of nodes
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for i := range rules {
+		rule := &rules[i]
+		start := time.Now()
+		count := 0
+		for _, node := range nodes {
+			if !rule.AppliesTo(node) {
+				continue
+			}
+
+			forbidden, missing := rule.Check(node)
+			var problems []string
+			if len(forbidden) > 0 {
+				problems = append(problems, "has forbidden annotation "+strings.Join(forbidden, ", "))
+			}
+			if len(missing) > 0 {
+				problems = append(problems, "is missing required annotation "+strings.Join(missing, ", "))
+			}
+			if len(problems) == 0 {
+				continue
+			}
+
+			message := fmt.Sprintf("Rule '%s': %s %s %s", rule.RuleName(), node.NodeType, node.String(), strings.Join(problems, " and "))
+			if rule.Reason != "" {
+				message += ": " + rule.Reason
+			}
+			violations = append(violations, &models.Violation{
+				File: node.FilePath,
+				Line: node.StartLine,
+				Caller: &models.ASTNode{
+					FilePath:    node.FilePath,
+					PackageName: node.PackageName,
+					StartLine:   node.StartLine,
+					NodeType:    models.NodeTypePackage,
+				},
+				Called:   node,
+				Message:  models.StringPtr(message),
+				Source:   "aql",
+				Severity: rule.GetSeverity(),
+			})
+			count++
+		}
+
+		e.timings = append(e.timings, cache.RuleEvaluation{
+			Rule:       rule.RuleName(),
+			Duration:   time.Since(start),
+			Violations: count,
+		})
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// ExecuteAPI reports the endpoints of OpenAPI specs that do not follow the REST conventions of
+// the API rules, at the spec defining them
+func (e *AQLEngine) ExecuteAPI(api *models.APIConfig) ([]*models.Violation, error) {
+	if !api.IsEnabled() {
+		return nil, nil
+	}
+
+	const ruleName = "api conventions"
+	start := time.Now()
+
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for _, node := range nodes {
+		method, path := node.Metatdata["method"], node.Metatdata["path"]
+		if path == "" {
+			continue
+		}
+
+		// Operations without an operationId are named after their method and path
+		operationID := node.MethodName
+		if operationID == method+" "+path {
+			operationID = ""
+		}
+		issues, err := api.Check(method, path, operationID, models.ParseErrorResponses(node.Metatdata["errors"]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid path naming '%s': %w", api.PathNaming, err)
+		}
+
+		// The endpoints of OpenAPI specs have no line
+		line := node.StartLine
+		if line < 1 {
+			line = 1
+		}
+		for _, issue := range issues {
+			message := fmt.Sprintf("Rule '%s': %s %s: %s", issue.Rule, method, path, issue.Message)
+			violations = append(violations, &models.Violation{
+				File: node.FilePath,
+				Line: line,
+				Caller: &models.ASTNode{
+					FilePath:    node.FilePath,
+					PackageName: node.PackageName,
+					StartLine:   line,
+					NodeType:    models.NodeTypePackage,
+				},
+				Called: &models.ASTNode{
+					FilePath:    node.FilePath,
+					PackageName: method + " " + path,
+					StartLine:   line,
+					NodeType:    node.NodeType,
+				},
+				Message:  models.StringPtr(message),
+				Source:   "aql",
+				Severity: api.GetSeverity(),
+			})
+		}
+	}
+
+	e.timings = append(e.timings, cache.RuleEvaluation{
+		Rule:       ruleName,
+		Duration:   time.Since(start),
+		Violations: len(violations),
+	})
+	return violations, nil
+}
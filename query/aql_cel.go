@@ -0,0 +1,74 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// ExecuteCEL reports every node for which the expression of a CEL rule is true
+func (e *AQLEngine) ExecuteCEL(rules []models.CELRule) ([]*models.Violation, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for i := range rules {
+		rule := &rules[i]
+		start := time.Now()
+		severity := rule.Severity
+		if severity == "" {
+			severity = models.SeverityError
+		}
+		count := 0
+		for _, node := range nodes {
+			matches, err := rule.Matches(node)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+
+			message := fmt.Sprintf("Rule '%s': %s matches %s", rule.Name, node.String(), rule.Expression)
+			if rule.Reason != "" {
+				message += ": " + rule.Reason
+			}
+			violations = append(violations, &models.Violation{
+				File: node.FilePath,
+				Line: node.StartLine,
+				Caller: &models.ASTNode{
+					FilePath:    node.FilePath,
+					PackageName: node.PackageName,
+					StartLine:   node.StartLine,
+					NodeType:    models.NodeTypePackage,
+				},
+				Called: &models.ASTNode{
+					FilePath:    node.FilePath,
+					PackageName: node.String(),
+					StartLine:   node.StartLine,
+					NodeType:    node.NodeType,
+				},
+				Message:  models.StringPtr(message),
+				Source:   "aql",
+				Severity: severity,
+			})
+			count++
+		}
+
+		e.timings = append(e.timings, cache.RuleEvaluation{
+			Rule:       rule.Name,
+			Duration:   time.Since(start),
+			Violations: count,
+		})
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,243 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// cycleEdge is the first dependency found from one package or type of a CYCLE statement to
+// another, reported as the evidence of the edge
+type cycleEdge struct {
+	from    *models.ASTNode
+	to      *models.ASTNode
+	line    int
+	relType models.RelationshipType
+}
+
+// executeCycleStatement executes a CYCLE statement, reporting every dependency cycle among the
+// packages or types of the nodes matching its pattern. Cycles are found among the strongly
+// connected components of the dependency graph, each package or type of a component is reported
+// with the shortest cycle through it, once per distinct cycle.
+func (e *AQLEngine) executeCycleStatement(rule *models.AQLRule, stmt *models.AQLStatement) ([]*models.Violation, error) {
+	if stmt.Pattern == nil {
+		return nil, fmt.Errorf("CYCLE statement missing pattern")
+	}
+	level := stmt.CycleLevel()
+
+	nodes, err := e.findMatchingNodes(stmt.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make(map[string]map[string]*cycleEdge)
+	for _, fromNode := range nodes {
+		from := cycleUnit(fromNode, level)
+		if from == "" {
+			continue
+		}
+		relationships, err := e.dependencyRelationships(fromNode.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range relationships {
+			toNode := e.relationshipTarget(fromNode, rel)
+			if toNode == nil || !stmt.Pattern.Matches(toNode) {
+				continue
+			}
+			to := cycleUnit(toNode, level)
+			if to == "" || to == from {
+				continue
+			}
+			if edges[from] == nil {
+				edges[from] = make(map[string]*cycleEdge)
+			}
+			if _, ok := edges[from][to]; !ok {
+				edges[from][to] = &cycleEdge{from: fromNode, to: toNode, line: rel.LineNo, relType: rel.RelationshipType}
+			}
+		}
+	}
+
+	graph := make(map[string][]string, len(edges))
+	for from, targets := range edges {
+		for to := range targets {
+			graph[from] = append(graph[from], to)
+		}
+		sort.Strings(graph[from])
+	}
+
+	var violations []*models.Violation
+	reported := make(map[string]bool)
+	for _, component := range stronglyConnectedComponents(graph) {
+		if len(component) < 2 {
+			continue
+		}
+		members := make(map[string]bool, len(component))
+		for _, unit := range component {
+			members[unit] = true
+		}
+		for _, unit := range component {
+			cycle := shortestCycle(graph, members, unit)
+			key := canonicalCycle(cycle)
+			if cycle == nil || reported[key] {
+				continue
+			}
+			reported[key] = true
+			violations = append(violations, cycleViolation(rule, cycle, edges))
+		}
+	}
+
+	return violations, nil
+}
+
+// cycleUnit returns the package, or the package qualified type, a node belongs to, or an empty
+// string for nodes without a type at the type level, e.g. package functions
+func cycleUnit(node *models.ASTNode, level models.AQLCycleLevel) string {
+	if level == models.AQLCycleLevelType {
+		if node.TypeName == "" {
+			return ""
+		}
+		if node.PackageName == "" {
+			return node.TypeName
+		}
+		return node.PackageName + "." + node.TypeName
+	}
+	return node.PackageName
+}
+
+// stronglyConnectedComponents returns the strongly connected components of a graph using Tarjan's
+// algorithm, visiting nodes in sorted order so that components and their members are stable
+func stronglyConnectedComponents(graph map[string][]string) [][]string {
+	var units []string
+	for unit := range graph {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var visit func(unit string)
+	visit = func(unit string) {
+		index[unit] = len(index)
+		lowlink[unit] = index[unit]
+		stack = append(stack, unit)
+		onStack[unit] = true
+
+		for _, next := range graph[unit] {
+			if _, ok := index[next]; !ok {
+				visit(next)
+				lowlink[unit] = min(lowlink[unit], lowlink[next])
+			} else if onStack[next] {
+				lowlink[unit] = min(lowlink[unit], index[next])
+			}
+		}
+
+		if lowlink[unit] == index[unit] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == unit {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, unit := range units {
+		if _, ok := index[unit]; !ok {
+			visit(unit)
+		}
+	}
+	return components
+}
+
+// shortestCycle returns the shortest path from start back to itself through the members of its
+// component, starting and ending with start, or nil when there is none
+func shortestCycle(graph map[string][]string, members map[string]bool, start string) []string {
+	previous := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		unit := queue[0]
+		queue = queue[1:]
+		for _, next := range graph[unit] {
+			if !members[next] {
+				continue
+			}
+			if next == start {
+				cycle := []string{start}
+				for u := unit; u != start; u = previous[u] {
+					cycle = append(cycle, u)
+				}
+				cycle = append(cycle, start)
+				// The path was collected backwards from the end of the cycle
+				for i, j := 1, len(cycle)-2; i < j; i, j = i+1, j-1 {
+					cycle[i], cycle[j] = cycle[j], cycle[i]
+				}
+				return cycle
+			}
+			if _, seen := previous[next]; !seen {
+				previous[next] = unit
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// canonicalCycle returns a key identifying a cycle regardless of the unit it starts at
+func canonicalCycle(cycle []string) string {
+	if len(cycle) < 2 {
+		return ""
+	}
+	units := cycle[:len(cycle)-1]
+	first := 0
+	for i, unit := range units {
+		if unit < units[first] {
+			first = i
+		}
+	}
+	return strings.Join(append(append([]string{}, units[first:]...), units[:first]...), " -> ")
+}
+
+// cycleViolation reports a cycle at the first dependency of the cycle, listing the dependency
+// found for each of its edges
+func cycleViolation(rule *models.AQLRule, cycle []string, edges map[string]map[string]*cycleEdge) *models.Violation {
+	var evidence []string
+	for i := 0; i < len(cycle)-1; i++ {
+		edge := edges[cycle[i]][cycle[i+1]]
+		evidence = append(evidence, fmt.Sprintf("%s %s %s at %s:%d",
+			edge.from.GetFullName(), edge.relType, edge.to.GetFullName(), edge.from.FilePath, edge.line))
+	}
+
+	first := edges[cycle[0]][cycle[1]]
+	return &models.Violation{
+		File: first.from.FilePath,
+		Line: first.line,
+		Caller: &models.ASTNode{
+			FilePath:    first.from.FilePath,
+			PackageName: first.from.PackageName,
+			StartLine:   first.line,
+			NodeType:    models.NodeTypeMethod,
+		},
+		Called: &models.ASTNode{
+			FilePath:    first.to.FilePath,
+			PackageName: first.to.PackageName,
+			StartLine:   first.line,
+			NodeType:    models.NodeTypeMethod,
+		},
+		Message: models.StringPtr(fmt.Sprintf("Rule '%s': Dependency cycle %s (%s)",
+			rule.Name, strings.Join(cycle, " -> "), strings.Join(evidence, ", "))),
+		Source: "aql",
+	}
+}
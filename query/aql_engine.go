@@ -2,21 +2,39 @@ package query
 
 import (
 	"fmt"
+	"path"
 	"strings"
+	"time"
 
+	"github.com/flanksource/arch-unit/analysis/openapi/httpcall"
 	"github.com/flanksource/arch-unit/internal/cache"
 	"github.com/flanksource/arch-unit/models"
 )
 
 // AQLEngine executes AQL queries against the AST database
 type AQLEngine struct {
-	cache *cache.ASTCache
+	cache     *cache.ASTCache
+	timings   []cache.RuleEvaluation
+	endpoints []*models.ASTNode         // OpenAPI operations, loaded on first use
+	nodes     map[int64]*models.ASTNode // relationship targets, looked up once per engine
+	all       []*models.ASTNode         // every node with its metadata, loaded on first use
+	coupling  []*models.PackageCoupling // coupling metrics of every package, computed on first use
+}
+
+// dependencyTypes are the relationships that make a node depend on their target
+var dependencyTypes = []string{
+	models.RelationshipCall,
+	models.RelationshipInheritance,
+	models.RelationshipSQLQuery,
+	models.RelationshipHTTPCall,
+	models.RelationshipEnvVar,
 }
 
 // NewAQLEngine creates a new AQL engine
 func NewAQLEngine(astCache *cache.ASTCache) *AQLEngine {
 	return &AQLEngine{
 		cache: astCache,
+		nodes: make(map[int64]*models.ASTNode),
 	}
 }
 
@@ -47,17 +65,43 @@ func (e *AQLEngine) ExecuteRuleSet(ruleSet *models.AQLRuleSet) ([]*models.Violat
 func (e *AQLEngine) ExecuteRule(rule *models.AQLRule) ([]*models.Violation, error) {
 	var violations []*models.Violation
 
+	ruleStart := time.Now()
 	for _, stmt := range rule.Statements {
+		start := time.Now()
 		stmtViolations, err := e.executeStatement(rule, stmt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute statement in rule %s: %w", rule.Name, err)
 		}
+		e.timings = append(e.timings, cache.RuleEvaluation{
+			Rule:       rule.Name,
+			Clause:     stmt.String(),
+			Duration:   time.Since(start),
+			Violations: len(stmtViolations),
+		})
 		violations = append(violations, stmtViolations...)
 	}
+	severity := rule.Severity
+	if severity == "" {
+		severity = models.SeverityError
+	}
+	for _, v := range violations {
+		v.Severity = severity
+	}
+	e.timings = append(e.timings, cache.RuleEvaluation{
+		Rule:       rule.Name,
+		Duration:   time.Since(ruleStart),
+		Violations: len(violations),
+	})
 
 	return violations, nil
 }
 
+// Timings returns the evaluation time of every rule and clause executed by the engine, each
+// rule's clauses are followed by the rule as a whole
+func (e *AQLEngine) Timings() []cache.RuleEvaluation {
+	return e.timings
+}
+
 // executeStatement executes a single AQL statement
 func (e *AQLEngine) executeStatement(rule *models.AQLRule, stmt *models.AQLStatement) ([]*models.Violation, error) {
 	switch stmt.Type {
@@ -70,6 +114,8 @@ func (e *AQLEngine) executeStatement(rule *models.AQLRule, stmt *models.AQLState
 	case models.AQLStatementAllow:
 		// ALLOW statements don't generate violations directly
 		return nil, nil
+	case models.AQLStatementCycle:
+		return e.executeCycleStatement(rule, stmt)
 	default:
 		return nil, fmt.Errorf("unknown statement type: %s", stmt.Type)
 	}
@@ -77,10 +123,27 @@ func (e *AQLEngine) executeStatement(rule *models.AQLRule, stmt *models.AQLState
 
 // executeLimitStatement executes a LIMIT statement
 func (e *AQLEngine) executeLimitStatement(rule *models.AQLRule, stmt *models.AQLStatement) ([]*models.Violation, error) {
+	if stmt.Expression != nil {
+		return e.executeExpression(rule, stmt.Expression, func(node *models.ASTNode) string {
+			return fmt.Sprintf("Rule '%s': %s violated limit", rule.Name, node.GetFullName())
+		})
+	}
 	if stmt.Condition == nil {
 		return nil, fmt.Errorf("LIMIT statement missing condition")
 	}
 
+	// Coupling metrics apply to packages rather than to nodes
+	metric := stmt.Condition.Pattern.Metric
+	if metric == "" {
+		metric = stmt.Condition.Property
+	}
+	if stmt.Condition.Aggregate != nil {
+		return e.executeAggregateLimit(rule, stmt.Condition, metric)
+	}
+	if models.IsPackageMetric(metric) {
+		return e.executePackageLimit(rule, stmt.Condition, metric)
+	}
+
 	// Get all AST nodes that match the pattern
 	nodes, err := e.findMatchingNodes(stmt.Condition.Pattern)
 	if err != nil {
@@ -125,7 +188,16 @@ func (e *AQLEngine) executeLimitStatement(rule *models.AQLRule, stmt *models.AQL
 
 // executeForbidStatement executes a FORBID statement
 func (e *AQLEngine) executeForbidStatement(rule *models.AQLRule, stmt *models.AQLStatement) ([]*models.Violation, error) {
-	if stmt.FromPattern != nil && stmt.ToPattern != nil {
+	if stmt.Expression != nil {
+		// Patterns combined with AND, OR and NOT: FORBID(A AND NOT B)
+		return e.executeExpression(rule, stmt.Expression, func(node *models.ASTNode) string {
+			return fmt.Sprintf("Rule '%s': Forbidden pattern %s found in %s", rule.Name, stmt.Expression.String(), node.GetFullName())
+		})
+	}
+	if stmt.FromPattern != nil && stmt.ToPattern != nil && stmt.Transitive {
+		// Path pattern: FORBID(A ->> B)
+		return e.executeForbidPath(rule, stmt)
+	} else if stmt.FromPattern != nil && stmt.ToPattern != nil {
 		// Relationship pattern: FORBID(A -> B)
 		return e.executeForbidRelationship(rule, stmt.FromPattern, stmt.ToPattern)
 	} else if stmt.Pattern != nil {
@@ -148,71 +220,154 @@ func (e *AQLEngine) executeForbidRelationship(rule *models.AQLRule, fromPattern,
 
 	for _, fromNode := range fromNodes {
 		// Get relationships from this node
-		relationships, err := e.cache.GetASTRelationships(fromNode.ID, models.RelationshipCall)
+		relationships, err := e.dependencyRelationships(fromNode.ID)
 		if err != nil {
 			return nil, err
 		}
 
 		for _, rel := range relationships {
-			if rel.ToASTID == nil {
-				// External call - check library relationships
-				// libRels, err := e.cache.GetLibraryRelationships(fromNode.ID, models.RelationshipCall)
-				// if err != nil {
-				// 	continue
-				// }
-
-				// for _, libRel := range libRels {
-				// 	if e.matchesLibraryPattern(libRel.LibraryNode, toPattern) {
-				// 		violation := &models.Violation{
-				// 			File:          fromNode.FilePath,
-				// 			Line:          libRel.LineNo,
-				// 			Caller: fromNode.PackageName,
-				// 			Called:  fromNode.GetFullName(),
-				// 			CalledPackage: libRel.LibraryNode.Package,
-				// 			CalledMethod:  libRel.LibraryNode.GetFullName(),
-				// 			Message:       fmt.Sprintf("Rule '%s': Forbidden call from %s to %s", rule.Name, fromNode.GetFullName(), libRel.LibraryNode.GetFullName()),
-				// 			Source:        "aql",
-				// 		}
-				// 		violations = append(violations, violation)
-				// 	}
-				// }
-			} else {
-				// Internal call
-				toNode, err := e.cache.GetASTNode(*rel.ToASTID)
-				if err != nil {
-					continue
-				}
-
-				if toPattern.Matches(toNode) {
-					callerNode := &models.ASTNode{
-						FilePath:    fromNode.FilePath,
-						PackageName: fromNode.PackageName,
-						StartLine:   rel.LineNo,
-						NodeType:    models.NodeTypeMethod,
-					}
-					calledNode := &models.ASTNode{
-						FilePath:    toNode.FilePath,
-						PackageName: toNode.PackageName,
-						StartLine:   rel.LineNo,
-						NodeType:    models.NodeTypeMethod,
-					}
-					violation := &models.Violation{
-						File:    fromNode.FilePath,
-						Line:    rel.LineNo,
-						Caller:  callerNode,
-						Called:  calledNode,
-						Message: models.StringPtr(fmt.Sprintf("Rule '%s': Forbidden call from %s to %s", rule.Name, fromNode.GetFullName(), toNode.GetFullName())),
-						Source:  "aql",
-					}
-					violations = append(violations, violation)
-				}
+			// External calls are only recorded as library relationships
+			toNode := e.relationshipTarget(fromNode, rel)
+			if toNode == nil || !toPattern.Matches(toNode) {
+				continue
+			}
+
+			callerNode := &models.ASTNode{
+				FilePath:    fromNode.FilePath,
+				PackageName: fromNode.PackageName,
+				StartLine:   rel.LineNo,
+				NodeType:    models.NodeTypeMethod,
+			}
+			calledNode := &models.ASTNode{
+				FilePath:    toNode.FilePath,
+				PackageName: toNode.PackageName,
+				StartLine:   rel.LineNo,
+				NodeType:    models.NodeTypeMethod,
+			}
+			violation := &models.Violation{
+				File:    fromNode.FilePath,
+				Line:    rel.LineNo,
+				Caller:  callerNode,
+				Called:  calledNode,
+				Message: models.StringPtr(fmt.Sprintf("Rule '%s': Forbidden %s from %s to %s", rule.Name, rel.RelationshipType, fromNode.GetFullName(), toNode.GetFullName())),
+				Source:  "aql",
 			}
+			violations = append(violations, violation)
 		}
 	}
 
 	return violations, nil
 }
 
+// dependencyRelationships returns the calls, inheritance relationships such as Go struct
+// embedding, SQL queries, HTTP calls and environment variables used by a node, ordered by line.
+// All of them make the node depend on the target.
+func (e *AQLEngine) dependencyRelationships(fromID int64) ([]*models.ASTRelationship, error) {
+	return e.cache.GetASTRelationshipsOfTypes(fromID, dependencyTypes...)
+}
+
+// relationshipTarget returns the node a relationship points to, or nil when it is unknown.
+// Go types embedding a type from another file only record its qualified name, e.g.
+// *github.com/acme/shop/store.Base, which is matched as type Base of package store. Raw SQL
+// queries record the table name, matched as a table of its schema, e.g. *:users, public:users or sql/*.
+// HTTP calls resolve to the OpenAPI operation serving their route, or otherwise to an endpoint
+// of the host they call, e.g. billing.svc:* for http://billing.svc/invoices. Environment variables
+// are matched as types of the env package, e.g. env:DATABASE_URL.
+func (e *AQLEngine) relationshipTarget(fromNode *models.ASTNode, rel *models.ASTRelationship) *models.ASTNode {
+	if rel.ToASTID != nil {
+		if toNode, ok := e.nodes[*rel.ToASTID]; ok {
+			return toNode
+		}
+		toNode, err := e.cache.GetASTNode(*rel.ToASTID)
+		if err != nil {
+			return nil
+		}
+		e.nodes[*rel.ToASTID] = toNode
+		return toNode
+	}
+
+	if rel.RelationshipType == models.RelationshipTypeHTTPCall && rel.Text != "" {
+		call := httpcall.Parse(rel.Text)
+		if endpoint := e.findEndpoint(call); endpoint != nil {
+			return endpoint
+		}
+		return &models.ASTNode{PackageName: call.Host(), MethodName: call.Route(), NodeType: endpointNodeType(call.Method)}
+	}
+
+	if rel.RelationshipType == models.RelationshipTypeEnvVar && rel.Text != "" {
+		return &models.ASTNode{PackageName: "env", TypeName: rel.Text, NodeType: models.NodeTypeVariable}
+	}
+
+	if rel.RelationshipType == models.RelationshipTypeSQLQuery && rel.Text != "" {
+		toNode := &models.ASTNode{TypeName: rel.Text, NodeType: models.NodeTypeTypeTable, Language: models.StringPtr("sql")}
+		if dot := strings.LastIndex(rel.Text, "."); dot >= 0 {
+			toNode.PackageName = rel.Text[:dot]
+			toNode.TypeName = rel.Text[dot+1:]
+		}
+		return toNode
+	}
+
+	if rel.RelationshipType != models.RelationshipTypeInheritance || rel.Text == "" || !strings.HasSuffix(fromNode.FilePath, ".go") {
+		return nil
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(rel.Text, "*"), "[")
+	toNode := &models.ASTNode{TypeName: name, NodeType: models.NodeTypeType}
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		toNode.PackageName = path.Base(name[:dot])
+		toNode.TypeName = name[dot+1:]
+	}
+	return toNode
+}
+
+// findEndpoint returns the OpenAPI operation serving the route of an HTTP call, preferring the
+// route with the most literal segments when several match
+func (e *AQLEngine) findEndpoint(call httpcall.Call) *models.ASTNode {
+	if e.endpoints == nil {
+		e.endpoints = []*models.ASTNode{}
+		nodeTypes := []models.NodeType{
+			models.NodeTypeMethodHTTPGet, models.NodeTypeMethodHTTPPost, models.NodeTypeMethodHTTPPut,
+			models.NodeTypeMethodHTTPDelete, models.NodeTypeMethod,
+		}
+		var nodes []*models.ASTNode
+		if err := e.cache.GetReadQuery().Where("node_type IN ?", nodeTypes).Find(&nodes).Error; err != nil {
+			return nil
+		}
+		for _, node := range nodes {
+			if node.Metatdata["path"] != "" {
+				e.endpoints = append(e.endpoints, node)
+			}
+		}
+	}
+
+	var best *models.ASTNode
+	bestLiterals := -1
+	path := call.Path()
+	for _, endpoint := range e.endpoints {
+		if call.Method != "" && endpoint.Metatdata["method"] != call.Method {
+			continue
+		}
+		if literals, ok := httpcall.Match(path, endpoint.Metatdata["path"]); ok && literals > bestLiterals {
+			best, bestLiterals = endpoint, literals
+		}
+	}
+	return best
+}
+
+// endpointNodeType returns the node type of an HTTP endpoint called with method
+func endpointNodeType(method string) models.NodeType {
+	switch method {
+	case "GET":
+		return models.NodeTypeMethodHTTPGet
+	case "POST":
+		return models.NodeTypeMethodHTTPPost
+	case "PUT":
+		return models.NodeTypeMethodHTTPPut
+	case "DELETE":
+		return models.NodeTypeMethodHTTPDelete
+	}
+	return models.NodeTypeMethod
+}
+
 // executeForbidPattern executes a FORBID pattern statement
 func (e *AQLEngine) executeForbidPattern(rule *models.AQLRule, pattern *models.AQLPattern) ([]*models.Violation, error) {
 	// Find all nodes that match the forbidden pattern
@@ -277,22 +432,15 @@ func (e *AQLEngine) executeRequireRelationship(rule *models.AQLRule, fromPattern
 		hasRequiredRelationship := false
 
 		// Check internal relationships
-		relationships, err := e.cache.GetASTRelationships(fromNode.ID, models.RelationshipCall)
+		relationships, err := e.dependencyRelationships(fromNode.ID)
 		if err != nil {
 			return nil, err
 		}
 
 		for _, rel := range relationships {
-			if rel.ToASTID != nil {
-				toNode, err := e.cache.GetASTNode(*rel.ToASTID)
-				if err != nil {
-					continue
-				}
-
-				if toPattern.Matches(toNode) {
-					hasRequiredRelationship = true
-					break
-				}
+			if toNode := e.relationshipTarget(fromNode, rel); toNode != nil && toPattern.Matches(toNode) {
+				hasRequiredRelationship = true
+				break
 			}
 		}
 
@@ -361,10 +509,59 @@ func (e *AQLEngine) executeRequirePattern(rule *models.AQLRule, pattern *models.
 	return nil, nil
 }
 
+// FindNodes returns all AST nodes that match a pattern
+func (e *AQLEngine) FindNodes(pattern *models.AQLPattern) ([]*models.ASTNode, error) {
+	return e.findMatchingNodes(pattern)
+}
+
 // findMatchingNodes finds AST nodes that match a pattern
 func (e *AQLEngine) findMatchingNodes(pattern *models.AQLPattern) ([]*models.ASTNode, error) {
-	// Build a query based on the pattern
-	query := "SELECT id, file_path, package_name, type_name, method_name, field_name, node_type, start_line, end_line, cyclomatic_complexity, parameter_count, return_count, line_count, last_modified FROM ast_nodes WHERE 1=1"
+	query, args := patternQuery(pattern)
+
+	// Patterns only vary in which columns they filter on, so the statement is reused across rules
+	rows, err := e.cache.QueryPrepared(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query AST nodes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var allNodes []*models.ASTNode
+	for rows.Next() {
+		var node models.ASTNode
+		err := rows.Scan(&node.ID, &node.FilePath, &node.PackageName, &node.TypeName,
+			&node.MethodName, &node.FieldName, &node.NodeType, &node.StartLine,
+			&node.EndLine, &node.CyclomaticComplexity, &node.ParameterCount,
+			&node.ReturnCount, &node.LineCount, &node.Coverage, &node.LastModified)
+		if err != nil {
+			return nil, err
+		}
+		allNodes = append(allNodes, &node)
+	}
+
+	// Filter by file path pattern if specified
+	if pattern.FilePath != "" && pattern.FilePath != "*" {
+		var filteredNodes []*models.ASTNode
+		for _, node := range allNodes {
+			if pattern.Matches(node) {
+				filteredNodes = append(filteredNodes, node)
+			}
+		}
+		return filteredNodes, nil
+	}
+
+	return allNodes, nil
+}
+
+// patternQuery builds the SQL query selecting the AST nodes of a pattern, file path patterns are
+// matched on the nodes it returns
+func patternQuery(pattern *models.AQLPattern) (string, []interface{}) {
+	where, args := patternWhere(pattern)
+	return "SELECT id, file_path, package_name, type_name, method_name, field_name, node_type, start_line, end_line, cyclomatic_complexity, parameter_count, return_count, line_count, coverage, last_modified FROM ast_nodes WHERE " + where, args
+}
+
+// patternWhere builds the SQL condition on the names of the AST nodes of a pattern
+func patternWhere(pattern *models.AQLPattern) (string, []interface{}) {
+	query := "1=1"
 	args := []interface{}{}
 
 	if pattern.Package != "" && pattern.Package != "*" {
@@ -407,35 +604,5 @@ func (e *AQLEngine) findMatchingNodes(pattern *models.AQLPattern) ([]*models.AST
 		}
 	}
 
-	rows, err := e.cache.QueryRaw(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query AST nodes: %w", err)
-	}
-	defer func() { _ = rows.Close() }()
-
-	var allNodes []*models.ASTNode
-	for rows.Next() {
-		var node models.ASTNode
-		err := rows.Scan(&node.ID, &node.FilePath, &node.PackageName, &node.TypeName,
-			&node.MethodName, &node.FieldName, &node.NodeType, &node.StartLine,
-			&node.EndLine, &node.CyclomaticComplexity, &node.ParameterCount,
-			&node.ReturnCount, &node.LineCount, &node.LastModified)
-		if err != nil {
-			return nil, err
-		}
-		allNodes = append(allNodes, &node)
-	}
-
-	// Filter by file path pattern if specified
-	if pattern.FilePath != "" && pattern.FilePath != "*" {
-		var filteredNodes []*models.ASTNode
-		for _, node := range allNodes {
-			if pattern.Matches(node) {
-				filteredNodes = append(filteredNodes, node)
-			}
-		}
-		return filteredNodes, nil
-	}
-
-	return allNodes, nil
+	return query, args
 }
@@ -0,0 +1,129 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// executeExpression reports every node satisfying an expression of a LIMIT or FORBID statement
+// with the message returned for the node. Only the nodes matching the patterns the expression
+// requires are evaluated, or every node when a negation or an OR can be satisfied without them.
+func (e *AQLEngine) executeExpression(rule *models.AQLRule, expression *models.AQLExpression, message func(*models.ASTNode) string) ([]*models.Violation, error) {
+	nodes, err := e.expressionCandidates(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for _, node := range nodes {
+		violated, err := expression.Evaluate(node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate %s: %w", expression, err)
+		}
+		if !violated {
+			continue
+		}
+
+		violations = append(violations, &models.Violation{
+			File: node.FilePath,
+			Line: node.StartLine,
+			Caller: &models.ASTNode{
+				FilePath:    node.FilePath,
+				PackageName: node.PackageName,
+				StartLine:   node.StartLine,
+				NodeType:    models.NodeTypePackage,
+			},
+			Called: &models.ASTNode{
+				FilePath:    node.FilePath,
+				PackageName: node.GetFullName(),
+				StartLine:   node.StartLine,
+				NodeType:    models.NodeTypeMethod,
+			},
+			Message: models.StringPtr(message(node)),
+			Source:  "aql",
+		})
+	}
+	return violations, nil
+}
+
+// expressionCandidates returns the nodes that can satisfy an expression, ordered by file and line:
+// those matching one of its required patterns, or every node when it has none
+func (e *AQLEngine) expressionCandidates(expression *models.AQLExpression) ([]*models.ASTNode, error) {
+	patterns, bounded := requiredPatterns(expression)
+	if !bounded {
+		return e.allNodes()
+	}
+
+	seen := make(map[int64]bool)
+	var candidates []*models.ASTNode
+	for _, pattern := range patterns {
+		where, args := patternWhere(pattern)
+		var nodes []*models.ASTNode
+		if err := e.cache.GetReadQuery().Where(where, args...).Find(&nodes).Error; err != nil {
+			return nil, fmt.Errorf("failed to query AST nodes: %w", err)
+		}
+		for _, node := range nodes {
+			// File path and language patterns are matched on the nodes of the query
+			if !seen[node.ID] && pattern.Matches(node) {
+				seen[node.ID] = true
+				candidates = append(candidates, node)
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].FilePath != candidates[j].FilePath {
+			return candidates[i].FilePath < candidates[j].FilePath
+		}
+		return candidates[i].StartLine < candidates[j].StartLine
+	})
+	return candidates, nil
+}
+
+// requiredPatterns returns patterns one of which every node satisfying an expression matches, and
+// false when there are none: a negation is satisfied by nodes no pattern names, an OR by the nodes
+// of any operand, and an AND by the nodes of the operand requiring the fewest patterns
+func requiredPatterns(expression *models.AQLExpression) ([]*models.AQLPattern, bool) {
+	switch expression.Operator {
+	case "":
+		pattern := expression.Pattern
+		if expression.Condition != nil {
+			pattern = expression.Condition.Pattern
+		}
+		if pattern == nil || isWildcardPattern(pattern) {
+			return nil, false
+		}
+		return []*models.AQLPattern{pattern}, true
+	case models.AQLLogicalAnd:
+		var narrowest []*models.AQLPattern
+		bounded := false
+		for _, operand := range expression.Operands {
+			if patterns, ok := requiredPatterns(operand); ok && (!bounded || len(patterns) < len(narrowest)) {
+				narrowest, bounded = patterns, true
+			}
+		}
+		return narrowest, bounded
+	case models.AQLLogicalOr:
+		var union []*models.AQLPattern
+		for _, operand := range expression.Operands {
+			patterns, ok := requiredPatterns(operand)
+			if !ok {
+				return nil, false
+			}
+			union = append(union, patterns...)
+		}
+		return union, len(union) > 0
+	}
+	return nil, false
+}
+
+// isWildcardPattern returns true if a pattern matches every node
+func isWildcardPattern(pattern *models.AQLPattern) bool {
+	for _, part := range []string{pattern.Package, pattern.Type, pattern.Method, pattern.Field, pattern.FilePath, pattern.Language} {
+		if part != "" && part != "*" {
+			return false
+		}
+	}
+	return true
+}
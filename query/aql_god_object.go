@@ -0,0 +1,193 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// ExecuteGodObjects reports the types with too many methods or fields, the packages with too many
+// files or types and the functions calling too many distinct functions
+func (e *AQLEngine) ExecuteGodObjects(config *models.GodObjectConfig) ([]*models.Violation, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Members are counted by the package and name of their type, as Go methods may be declared in
+	// several files
+	types := make(map[string]*models.ASTNode)
+	methods := make(map[string]int)
+	fields := make(map[string]int)
+	packages := make(map[string]*models.ASTNode)
+	var packageNames []string
+	packageFiles := make(map[string]map[string]bool)
+	packageTypes := make(map[string]int)
+	for _, node := range nodes {
+		key := node.PackageName + "." + node.TypeName
+		switch {
+		case node.NodeType == models.NodeTypeType && node.MethodName == "" && node.FieldName == "":
+			if _, ok := types[key]; !ok {
+				types[key] = node
+				packageTypes[node.PackageName]++
+			}
+		case node.NodeType == models.NodeTypeMethod && node.TypeName != "":
+			methods[key]++
+		case node.NodeType == models.NodeTypeField && node.TypeName != "":
+			fields[key]++
+		}
+
+		if node.PackageName == "" {
+			continue
+		}
+		if _, ok := packages[node.PackageName]; !ok {
+			packages[node.PackageName] = node
+			packageNames = append(packageNames, node.PackageName)
+			packageFiles[node.PackageName] = make(map[string]bool)
+		}
+		packageFiles[node.PackageName][node.FilePath] = true
+	}
+
+	var violations []*models.Violation
+	severity := config.GetSeverity()
+	check := func(ruleName string, limit int, count func(node *models.ASTNode) (string, int), subjects []*models.ASTNode) {
+		if limit == 0 {
+			return
+		}
+		start := time.Now()
+		found := 0
+		for _, node := range subjects {
+			description, value := count(node)
+			if value <= limit {
+				continue
+			}
+			message := fmt.Sprintf("Rule '%s': %s, more than %d", ruleName, description, limit)
+			violations = append(violations, godObjectViolation(node, message, severity))
+			found++
+		}
+		e.timings = append(e.timings, cache.RuleEvaluation{
+			Rule:       ruleName,
+			Duration:   time.Since(start),
+			Violations: found,
+		})
+	}
+
+	// Types and packages are reported in the order of their first node
+	var typeNodes []*models.ASTNode
+	for _, node := range nodes {
+		if types[node.PackageName+"."+node.TypeName] == node {
+			typeNodes = append(typeNodes, node)
+		}
+	}
+	var packageNodes []*models.ASTNode
+	for _, name := range packageNames {
+		packageNodes = append(packageNodes, packages[name])
+	}
+
+	check("max methods per type", config.MethodLimit(), func(node *models.ASTNode) (string, int) {
+		count := methods[node.PackageName+"."+node.TypeName]
+		return fmt.Sprintf("type %s has %d methods", node.String(), count), count
+	}, typeNodes)
+	check("max fields per type", config.FieldLimit(), func(node *models.ASTNode) (string, int) {
+		count := fields[node.PackageName+"."+node.TypeName]
+		return fmt.Sprintf("type %s has %d fields", node.String(), count), count
+	}, typeNodes)
+	check("max files per package", config.PackageFileLimit(), func(node *models.ASTNode) (string, int) {
+		count := len(packageFiles[node.PackageName])
+		return fmt.Sprintf("package %s has %d files", node.PackageName, count), count
+	}, packageNodes)
+	check("max types per package", config.PackageTypeLimit(), func(node *models.ASTNode) (string, int) {
+		count := packageTypes[node.PackageName]
+		return fmt.Sprintf("package %s has %d types", node.PackageName, count), count
+	}, packageNodes)
+
+	if limit := config.FanOutLimit(); limit > 0 {
+		fanOut, err := e.fanOut()
+		if err != nil {
+			return nil, err
+		}
+		var functions []*models.ASTNode
+		for _, node := range nodes {
+			if node.NodeType == models.NodeTypeMethod {
+				functions = append(functions, node)
+			}
+		}
+		check("max fan-out per function", limit, func(node *models.ASTNode) (string, int) {
+			count := fanOut[node.ID]
+			return fmt.Sprintf("%s calls %d distinct functions", node.String(), count), count
+		}, functions)
+	}
+
+	return violations, nil
+}
+
+// fanOut returns the number of distinct functions called by each node, in the repository or in
+// libraries
+func (e *AQLEngine) fanOut() (map[int64]int, error) {
+	var relationships []*models.ASTRelationship
+	if err := e.cache.GetReadQuery().
+		Where("relationship_type = ?", models.RelationshipCall).
+		Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query call relationships: %w", err)
+	}
+	var libraryRelationships []*models.LibraryRelationship
+	if err := e.cache.GetReadQuery().
+		Where("relationship_type = ?", models.RelationshipCall).
+		Find(&libraryRelationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query library call relationships: %w", err)
+	}
+
+	// Calls to nodes outside of the cache are told apart by their text
+	targets := make(map[int64]map[string]bool)
+	add := func(from int64, target string) {
+		if targets[from] == nil {
+			targets[from] = make(map[string]bool)
+		}
+		targets[from][target] = true
+	}
+	for _, rel := range relationships {
+		if rel.ToASTID != nil {
+			add(rel.FromASTID, fmt.Sprintf("ast:%d", *rel.ToASTID))
+		} else {
+			add(rel.FromASTID, "text:"+rel.Text)
+		}
+	}
+	for _, rel := range libraryRelationships {
+		add(rel.ASTID, fmt.Sprintf("library:%d", rel.LibraryID))
+	}
+
+	fanOut := make(map[int64]int, len(targets))
+	for from, called := range targets {
+		fanOut[from] = len(called)
+	}
+	return fanOut, nil
+}
+
+// godObjectViolation returns a violation of a god-object rule reported at node
+func godObjectViolation(node *models.ASTNode, message string, severity models.Severity) *models.Violation {
+	return &models.Violation{
+		File: node.FilePath,
+		Line: node.StartLine,
+		Caller: &models.ASTNode{
+			FilePath:    node.FilePath,
+			PackageName: node.PackageName,
+			StartLine:   node.StartLine,
+			NodeType:    models.NodeTypePackage,
+		},
+		Called: &models.ASTNode{
+			FilePath:    node.FilePath,
+			PackageName: node.GetFullName(),
+			StartLine:   node.StartLine,
+			NodeType:    node.NodeType,
+		},
+		Message:  models.StringPtr(message),
+		Source:   "aql",
+		Severity: severity,
+	}
+}
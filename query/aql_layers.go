@@ -0,0 +1,99 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// LayersRuleName is the rule reported for violations of the layers of arch-unit.yaml
+const LayersRuleName = "layers"
+
+// ExecuteLayers reports every import and dependency from a package of one layer to a package of
+// a layer it is not allowed to depend on
+func (e *AQLEngine) ExecuteLayers(layers models.Layers) ([]*models.Violation, error) {
+	if len(layers) == 0 {
+		return nil, nil
+	}
+
+	start := time.Now()
+	nodes, err := e.findMatchingNodes(&models.AQLPattern{Package: "*"})
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for _, fromNode := range nodes {
+		fromLayer := layers.Find(fromNode)
+		if fromLayer == nil {
+			continue
+		}
+
+		relationships, err := e.dependencyRelationships(fromNode.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range relationships {
+			toNode := e.relationshipTarget(fromNode, rel)
+			if toNode == nil {
+				continue
+			}
+			if violation := layerViolation(layers, fromLayer, fromNode, toNode, string(rel.RelationshipType), rel.LineNo); violation != nil {
+				violations = append(violations, violation)
+			}
+		}
+
+		// Imports of other packages are only recorded as library relationships
+		imports, err := e.cache.GetLibraryRelationships(fromNode.ID, models.RelationshipImport)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range imports {
+			if rel.LibraryNode == nil {
+				continue
+			}
+			toNode := &models.ASTNode{PackageName: rel.LibraryNode.Package, TypeName: rel.LibraryNode.Class, NodeType: models.NodeTypePackage}
+			if violation := layerViolation(layers, fromLayer, fromNode, toNode, rel.RelationshipType, rel.LineNo); violation != nil {
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	e.timings = append(e.timings, cache.RuleEvaluation{
+		Rule:       LayersRuleName,
+		Duration:   time.Since(start),
+		Violations: len(violations),
+	})
+	return violations, nil
+}
+
+// layerViolation returns a violation when the layer of fromNode may not depend on the layer of
+// toNode, or nil when toNode is outside of all layers or the dependency is allowed
+func layerViolation(layers models.Layers, fromLayer *models.LayerConfig, fromNode, toNode *models.ASTNode, relType string, line int) *models.Violation {
+	toLayer := layers.Find(toNode)
+	if toLayer == nil || fromLayer.Allows(toLayer) {
+		return nil
+	}
+
+	return &models.Violation{
+		File: fromNode.FilePath,
+		Line: line,
+		Caller: &models.ASTNode{
+			FilePath:    fromNode.FilePath,
+			PackageName: fromNode.PackageName,
+			StartLine:   line,
+			NodeType:    models.NodeTypeMethod,
+		},
+		Called: &models.ASTNode{
+			FilePath:    toNode.FilePath,
+			PackageName: toNode.PackageName,
+			StartLine:   line,
+			NodeType:    models.NodeTypeMethod,
+		},
+		Message: models.StringPtr(fmt.Sprintf("Rule '%s': Forbidden %s from %s to %s, layer %s may not depend on layer %s",
+			LayersRuleName, relType, fromNode.GetFullName(), toNode.GetFullName(), fromLayer.Name, toLayer.Name)),
+		Source: "aql",
+	}
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// ExecuteNaming reports every file, package and node whose name does not follow the convention of
+// a naming rule, once per file or package
+func (e *AQLEngine) ExecuteNaming(rules []models.NamingRule) ([]*models.Violation, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	// The kind of types is only recorded in their metadata
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for i := range rules {
+		rule := &rules[i]
+		start := time.Now()
+		checked := make(map[string]bool)
+		count := 0
+		for _, node := range nodes {
+			name, ok := rule.NameOf(node)
+			if !ok {
+				continue
+			}
+
+			line := node.StartLine
+			switch rule.Kind {
+			case models.NamingKindFile:
+				if checked[node.FilePath] {
+					continue
+				}
+				checked[node.FilePath] = true
+				line = 1
+			case models.NodeTypePackage:
+				if checked[name] {
+					continue
+				}
+				checked[name] = true
+			}
+
+			matches, err := rule.Matches(name)
+			if err != nil {
+				return nil, err
+			}
+			if matches {
+				continue
+			}
+
+			message := fmt.Sprintf("Rule '%s': %s %s does not match %s", rule.RuleName(), rule.Kind, name, rule.Pattern)
+			if rule.Reason != "" {
+				message += ": " + rule.Reason
+			}
+			violations = append(violations, &models.Violation{
+				File: node.FilePath,
+				Line: line,
+				Caller: &models.ASTNode{
+					FilePath:    node.FilePath,
+					PackageName: node.PackageName,
+					StartLine:   line,
+					NodeType:    models.NodeTypePackage,
+				},
+				Called: &models.ASTNode{
+					FilePath:    node.FilePath,
+					PackageName: node.GetFullName(),
+					StartLine:   line,
+					NodeType:    node.NodeType,
+				},
+				Message: models.StringPtr(message),
+				Source:  "aql",
+			})
+			count++
+		}
+
+		e.timings = append(e.timings, cache.RuleEvaluation{
+			Rule:       rule.RuleName(),
+			Duration:   time.Since(start),
+			Violations: count,
+		})
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,132 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// pathStep is a node reached by a transitive statement with the path leading to it
+type pathStep struct {
+	node  *models.ASTNode
+	path  []*models.ASTRelationship
+	nodes []*models.ASTNode
+}
+
+// executeForbidPath executes a FORBID(A ->> B) statement, reporting every node matching the to
+// pattern that a node matching the from pattern reaches through calls and the other dependency
+// relationships within the hops of the statement, once per pair with the shortest path
+func (e *AQLEngine) executeForbidPath(rule *models.AQLRule, stmt *models.AQLStatement) ([]*models.Violation, error) {
+	fromNodes, err := e.findMatchingNodes(stmt.FromPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make(map[int64][]*models.ASTRelationship)
+	var violations []*models.Violation
+	for _, fromNode := range fromNodes {
+		paths, err := e.callPaths(fromNode, stmt.ToPattern, stmt.Hops(), edges)
+		if err != nil {
+			return nil, err
+		}
+		for _, callPath := range paths {
+			violations = append(violations, pathViolation(rule, callPath))
+		}
+	}
+	return violations, nil
+}
+
+// callPaths searches the dependency graph breadth first from a node, returning the shortest path
+// to every node matching a pattern within a number of hops. edges caches the relationships of
+// the nodes visited by earlier searches.
+func (e *AQLEngine) callPaths(fromNode *models.ASTNode, toPattern *models.AQLPattern, hops int, edges map[int64][]*models.ASTRelationship) ([]*models.CallPath, error) {
+	visited := map[int64]bool{fromNode.ID: true}
+	reported := make(map[string]bool)
+	frontier := []pathStep{{node: fromNode, nodes: []*models.ASTNode{fromNode}}}
+
+	var paths []*models.CallPath
+	for depth := 0; depth < hops && len(frontier) > 0; depth++ {
+		var next []pathStep
+		for _, step := range frontier {
+			relationships, ok := edges[step.node.ID]
+			if !ok {
+				var err error
+				if relationships, err = e.dependencyRelationships(step.node.ID); err != nil {
+					return nil, err
+				}
+				edges[step.node.ID] = relationships
+			}
+
+			for _, rel := range relationships {
+				toNode := e.relationshipTarget(step.node, rel)
+				if toNode == nil {
+					continue
+				}
+				reached := pathStep{
+					node:  toNode,
+					path:  append(slices.Clone(step.path), rel),
+					nodes: append(slices.Clone(step.nodes), toNode),
+				}
+
+				// Targets outside the cache, such as tables and endpoints, have no ID
+				key := toNode.String()
+				if toNode.ID != 0 {
+					key = fmt.Sprint(toNode.ID)
+				}
+				if toPattern.Matches(toNode) && !reported[key] {
+					reported[key] = true
+					paths = append(paths, newCallPath(reached))
+				}
+				if toNode.ID != 0 && !visited[toNode.ID] {
+					visited[toNode.ID] = true
+					next = append(next, reached)
+				}
+			}
+		}
+		frontier = next
+	}
+	return paths, nil
+}
+
+// newCallPath returns the call path of a step, e.g. controller.UserController.Get ->
+// service.UserService.Find -> public.users
+func newCallPath(step pathStep) *models.CallPath {
+	names := make([]string, len(step.nodes))
+	for i, node := range step.nodes {
+		names[i] = node.String()
+	}
+	return &models.CallPath{
+		FromNode:    step.nodes[0],
+		ToNode:      step.node,
+		Path:        step.path,
+		PathLength:  len(step.path),
+		CallPattern: strings.Join(names, " -> "),
+	}
+}
+
+// pathViolation reports a forbidden call path at the first relationship of the path
+func pathViolation(rule *models.AQLRule, callPath *models.CallPath) *models.Violation {
+	fromNode, toNode := callPath.FromNode, callPath.ToNode
+	line := callPath.Path[0].LineNo
+	return &models.Violation{
+		File: fromNode.FilePath,
+		Line: line,
+		Caller: &models.ASTNode{
+			FilePath:    fromNode.FilePath,
+			PackageName: fromNode.PackageName,
+			StartLine:   line,
+			NodeType:    models.NodeTypeMethod,
+		},
+		Called: &models.ASTNode{
+			FilePath:    toNode.FilePath,
+			PackageName: toNode.PackageName,
+			StartLine:   callPath.Path[len(callPath.Path)-1].LineNo,
+			NodeType:    models.NodeTypeMethod,
+		},
+		Message: models.StringPtr(fmt.Sprintf("Rule '%s': Forbidden path from %s to %s in %d hops: %s",
+			rule.Name, fromNode.String(), toNode.String(), callPath.PathLength, callPath.CallPattern)),
+		Source: "aql",
+	}
+}
@@ -0,0 +1,32 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// PolicyInput returns the input document of OPA/Rego policies, the nodes, relationships and
+// library dependencies of the cache
+func (e *AQLEngine) PolicyInput() (*models.PolicyInput, error) {
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &models.PolicyInput{
+		Nodes:         make([]map[string]interface{}, 0, len(nodes)),
+		Relationships: []*models.ASTRelationship{},
+		Dependencies:  []*models.LibraryRelationship{},
+	}
+	for _, node := range nodes {
+		input.Nodes = append(input.Nodes, node.AsMap())
+	}
+	if err := e.cache.GetReadQuery().Order("id").Find(&input.Relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query AST relationships: %w", err)
+	}
+	if err := e.cache.GetReadQuery().Preload("LibraryNode").Order("id").Find(&input.Dependencies).Error; err != nil {
+		return nil, fmt.Errorf("failed to query library relationships: %w", err)
+	}
+	return input, nil
+}
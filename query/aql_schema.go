@@ -0,0 +1,167 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/arch-unit/analysis/sql/rawsql"
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// ExecuteSchema reports the tables and columns of SQL schemas that do not follow the naming
+// conventions, the foreign key columns without an index and the raw SQL queries selecting every
+// column
+func (e *AQLEngine) ExecuteSchema(schema *models.SchemaConfig) ([]*models.Violation, error) {
+	if !schema.IsEnabled() {
+		return nil, nil
+	}
+
+	violations, err := e.ExecuteNaming(schema.NamingRules())
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.IndexForeignKeys {
+		unindexed, err := e.unindexedForeignKeys()
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, unindexed...)
+	}
+
+	if schema.ForbidSelectStar {
+		selectStar, err := e.selectStarQueries()
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, selectStar...)
+	}
+
+	for _, v := range violations {
+		v.Severity = schema.GetSeverity()
+		// The tables and columns of SQL schemas have no line
+		if v.Line < 1 {
+			v.Line = 1
+		}
+	}
+	return violations, nil
+}
+
+// unindexedForeignKeys reports every foreign key column that is not the first column of an index
+// of its table, at the virtual path of its schema
+func (e *AQLEngine) unindexedForeignKeys() ([]*models.Violation, error) {
+	const ruleName = "schema foreign key indexes"
+	start := time.Now()
+
+	var relationships []*models.ASTRelationship
+	if err := e.cache.GetReadQuery().
+		Where("relationship_type = ?", models.RelationshipTypeForeignKey).
+		Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	if len(relationships) == 0 {
+		return nil, nil
+	}
+
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*models.ASTNode, len(nodes))
+	indexed := make(map[string]bool)
+	for _, node := range nodes {
+		byID[node.ID] = node
+		if node.Metatdata["kind"] == models.SchemaKindIndex && len(node.Parameters) > 0 {
+			indexed[tableColumnKey(node, node.Parameters[0].Name)] = true
+		}
+	}
+
+	var violations []*models.Violation
+	for _, rel := range relationships {
+		column, ok := byID[rel.FromASTID]
+		if !ok || indexed[tableColumnKey(column, column.FieldName)] {
+			continue
+		}
+
+		message := fmt.Sprintf("Rule '%s': foreign key %s is not indexed", ruleName, rel.Text)
+		violations = append(violations, schemaViolation(column, column.StartLine, column.TypeName+"."+column.FieldName, message))
+	}
+
+	e.timings = append(e.timings, cache.RuleEvaluation{
+		Rule:       ruleName,
+		Duration:   time.Since(start),
+		Violations: len(violations),
+	})
+	return violations, nil
+}
+
+// selectStarQueries reports every raw SQL query extracted from code that selects every column
+// with *, at the line of the query
+func (e *AQLEngine) selectStarQueries() ([]*models.Violation, error) {
+	const ruleName = "schema select star"
+	start := time.Now()
+
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for _, node := range nodes {
+		for _, statement := range sqlStatements(node.Statements) {
+			query, ok := rawsql.Parse(statement.Text)
+			if !ok || !query.SelectsAll {
+				continue
+			}
+			message := fmt.Sprintf("Rule '%s': %s selects every column: %s", ruleName, node.String(), statement.Text)
+			violations = append(violations, schemaViolation(node, statement.StartLine, node.String(), message))
+		}
+	}
+
+	e.timings = append(e.timings, cache.RuleEvaluation{
+		Rule:       ruleName,
+		Duration:   time.Since(start),
+		Violations: len(violations),
+	})
+	return violations, nil
+}
+
+// sqlStatements returns the SQL query statements of a statement tree, depth first
+func sqlStatements(statements []models.ASTStatement) []models.ASTStatement {
+	var queries []models.ASTStatement
+	for _, statement := range statements {
+		if statement.Type == models.ASTStatementTypeSQLQuery {
+			queries = append(queries, statement)
+		}
+		queries = append(queries, sqlStatements(statement.Children)...)
+	}
+	return queries
+}
+
+// tableColumnKey identifies a column of a table of a schema
+func tableColumnKey(node *models.ASTNode, column string) string {
+	return node.FilePath + "\x00" + node.PackageName + "\x00" + node.TypeName + "\x00" + column
+}
+
+// schemaViolation returns a violation of a schema rule by a node, reported at line
+func schemaViolation(node *models.ASTNode, line int, name, message string) *models.Violation {
+	return &models.Violation{
+		File: node.FilePath,
+		Line: line,
+		Caller: &models.ASTNode{
+			FilePath:    node.FilePath,
+			PackageName: node.PackageName,
+			StartLine:   line,
+			NodeType:    models.NodeTypePackage,
+		},
+		Called: &models.ASTNode{
+			FilePath:    node.FilePath,
+			PackageName: name,
+			StartLine:   line,
+			NodeType:    node.NodeType,
+		},
+		Message: models.StringPtr(message),
+		Source:  "aql",
+	}
+}
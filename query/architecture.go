@@ -0,0 +1,79 @@
+package query
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Architecture summarises the size and complexity of every package with a file under
+// pathPrefix and the dependencies between them, ordered by package; an empty prefix includes
+// every package.
+func (e *AQLEngine) Architecture(pathPrefix string) (*models.ArchitectureReport, error) {
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make(map[string]*models.PackageSummary)
+	packageOf := make(map[int64]string, len(nodes))
+	fileLines := make(map[string]map[string]int)
+	complexity := make(map[string]int)
+	for _, node := range nodes {
+		if node.PackageName == "" || (pathPrefix != "" && !strings.HasPrefix(node.FilePath, pathPrefix)) {
+			continue
+		}
+		packageOf[node.ID] = node.PackageName
+		summary, ok := packages[node.PackageName]
+		if !ok {
+			summary = &models.PackageSummary{Package: node.PackageName}
+			packages[node.PackageName] = summary
+			fileLines[node.PackageName] = make(map[string]int)
+		}
+		// The length of a file is the end of its last node
+		if end, ok := fileLines[node.PackageName][node.FilePath]; !ok || node.EndLine > end {
+			fileLines[node.PackageName][node.FilePath] = node.EndLine
+		}
+
+		switch node.NodeType {
+		case models.NodeTypeType:
+			summary.Types++
+		case models.NodeTypeMethod:
+			summary.Methods++
+			complexity[node.PackageName] += node.CyclomaticComplexity
+			if node.CyclomaticComplexity > summary.MaxComplexity {
+				summary.MaxComplexity = node.CyclomaticComplexity
+			}
+		}
+	}
+
+	report := &models.ArchitectureReport{}
+	for name, summary := range packages {
+		summary.Files = len(fileLines[name])
+		for _, lines := range fileLines[name] {
+			summary.Lines += lines
+		}
+		if summary.Methods > 0 {
+			summary.AvgComplexity = float64(complexity[name]) / float64(summary.Methods)
+		}
+		report.Packages = append(report.Packages, summary)
+	}
+	sort.Slice(report.Packages, func(i, j int) bool { return report.Packages[i].Package < report.Packages[j].Package })
+
+	edges, err := e.groupDependencies(packageOf, dependencyTypes)
+	if err != nil {
+		return nil, err
+	}
+	for edge, count := range edges {
+		report.Dependencies = append(report.Dependencies, &models.DependencyEdge{From: edge.from, To: edge.to, Count: count})
+	}
+	sort.Slice(report.Dependencies, func(i, j int) bool {
+		a, b := report.Dependencies[i], report.Dependencies[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return report, nil
+}
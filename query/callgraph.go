@@ -0,0 +1,97 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// CallGraph walks the calls from the methods matching the pattern of the options, or to them when
+// walking callers, up to the max depth of the options. Every method is expanded once per tree,
+// later calls of it are marked as repeated, so recursion terminates.
+func (e *AQLEngine) CallGraph(options models.CallGraphOptions) ([]*models.CallTree, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	pattern, err := models.ParsePattern(options.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", options.Pattern, err)
+	}
+	nodes, err := e.FindNodes(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var trees []*models.CallTree
+	for _, node := range nodes {
+		if !isMethod(node) {
+			continue
+		}
+		tree, err := e.callTree(node, 0, 0, options, map[int64]bool{node.ID: true})
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree)
+	}
+	return trees, nil
+}
+
+// callTree expands the calls of a node at depth, recording the expanded nodes in expanded
+func (e *AQLEngine) callTree(node *models.ASTNode, line, depth int, options models.CallGraphOptions, expanded map[int64]bool) (*models.CallTree, error) {
+	tree := &models.CallTree{Node: node, Line: line}
+	if options.MaxDepth > 0 && depth >= options.MaxDepth {
+		return tree, nil
+	}
+
+	var relationships []*models.ASTRelationship
+	column := "from_ast_id"
+	if options.Callers {
+		column = "to_ast_id"
+	}
+	if err := e.cache.GetReadQuery().
+		Where(column+" = ? AND relationship_type = ? AND to_ast_id IS NOT NULL", node.ID, models.RelationshipCall).
+		Order("line_no").Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query the calls of %s: %w", node.String(), err)
+	}
+
+	for _, rel := range relationships {
+		id := *rel.ToASTID
+		if options.Callers {
+			id = rel.FromASTID
+		}
+		other, err := e.node(id)
+		if err != nil || !options.IncludesPackage(other.PackageName) {
+			continue
+		}
+		if expanded[id] {
+			tree.Calls = append(tree.Calls, &models.CallTree{Node: other, Line: rel.LineNo, Repeated: true})
+			continue
+		}
+		expanded[id] = true
+		call, err := e.callTree(other, rel.LineNo, depth+1, options, expanded)
+		if err != nil {
+			return nil, err
+		}
+		tree.Calls = append(tree.Calls, call)
+	}
+	return tree, nil
+}
+
+// node returns a node by id, caching it for the lifetime of the engine
+func (e *AQLEngine) node(id int64) (*models.ASTNode, error) {
+	if node, ok := e.nodes[id]; ok {
+		return node, nil
+	}
+	node, err := e.cache.GetASTNode(id)
+	if err != nil {
+		return nil, err
+	}
+	e.nodes[id] = node
+	return node, nil
+}
+
+// isMethod returns true for methods and their sub-types such as stored procedures
+func isMethod(node *models.ASTNode) bool {
+	return node.NodeType == models.NodeTypeMethod || strings.HasPrefix(string(node.NodeType), string(models.NodeTypeMethod)+"_")
+}
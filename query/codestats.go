@@ -0,0 +1,226 @@
+package query
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// commentSyntax is the line comment marker and block comment delimiters of a language
+type commentSyntax struct {
+	line, blockStart, blockEnd string
+}
+
+// commentSyntaxes are the comment markers of source files by extension
+var commentSyntaxes = map[string]commentSyntax{
+	".go":    {"//", "/*", "*/"},
+	".java":  {"//", "/*", "*/"},
+	".kt":    {"//", "/*", "*/"},
+	".js":    {"//", "/*", "*/"},
+	".jsx":   {"//", "/*", "*/"},
+	".ts":    {"//", "/*", "*/"},
+	".tsx":   {"//", "/*", "*/"},
+	".dart":  {"//", "/*", "*/"},
+	".rs":    {"//", "/*", "*/"},
+	".c":     {"//", "/*", "*/"},
+	".cpp":   {"//", "/*", "*/"},
+	".cs":    {"//", "/*", "*/"},
+	".py":    {"#", `"""`, `"""`},
+	".rb":    {"#", "=begin", "=end"},
+	".sh":    {"#", "", ""},
+	".yaml":  {"#", "", ""},
+	".yml":   {"#", "", ""},
+	".sql":   {"--", "/*", "*/"},
+	".proto": {"//", "/*", "*/"},
+}
+
+// languageTally accumulates the statistics of a language
+type languageTally struct {
+	stats        models.LanguageStats
+	lastLines    map[string]int
+	complexities []int
+}
+
+// CodebaseStats summarises the nodes of the files under pathPrefix: the lines, comment density,
+// functions and complexity of every language, the limit types with the most members and the
+// limit packages most depended on. Lines are counted in the files on disk, files that no longer
+// exist count up to the last line of their nodes. An empty prefix includes every file.
+func (e *AQLEngine) CodebaseStats(pathPrefix string, limit int) (*models.CodebaseStats, error) {
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	tallies := make(map[string]*languageTally)
+	types := make(map[string]*models.TypeSize)
+	typeSize := func(node *models.ASTNode) *models.TypeSize {
+		key := node.PackageName + "." + node.TypeName
+		size, ok := types[key]
+		if !ok {
+			size = &models.TypeSize{Type: key}
+			types[key] = size
+		}
+		return size
+	}
+
+	for _, node := range nodes {
+		if pathPrefix != "" && !strings.HasPrefix(node.FilePath, pathPrefix) {
+			continue
+		}
+		language := "unknown"
+		if node.Language != nil && *node.Language != "" {
+			language = *node.Language
+		}
+		tally, ok := tallies[language]
+		if !ok {
+			tally = &languageTally{stats: models.LanguageStats{Language: language}, lastLines: make(map[string]int)}
+			tallies[language] = tally
+		}
+		if last, ok := tally.lastLines[node.FilePath]; !ok || node.EndLine > last {
+			tally.lastLines[node.FilePath] = node.EndLine
+		}
+
+		switch {
+		case isMethod(node):
+			tally.stats.Functions++
+			tally.complexities = append(tally.complexities, node.CyclomaticComplexity)
+			if node.TypeName != "" {
+				typeSize(node).Methods++
+			}
+		case node.NodeType == models.NodeTypeField && node.TypeName != "":
+			typeSize(node).Fields++
+		case node.NodeType == models.NodeTypeType && node.TypeName != "" && node.MethodName == "" && node.FieldName == "":
+			size := typeSize(node)
+			size.File, size.Line = node.FilePath, node.StartLine
+			size.Lines = node.EndLine - node.StartLine + 1
+		}
+	}
+
+	stats := &models.CodebaseStats{}
+	for _, tally := range tallies {
+		language := tally.stats
+		language.Files = len(tally.lastLines)
+		nonBlank := 0
+		for file, last := range tally.lastLines {
+			lines, code, comments, err := countLines(file)
+			if err != nil {
+				language.Lines += last
+				nonBlank += last
+				continue
+			}
+			language.Lines += lines
+			language.CommentLines += comments
+			nonBlank += code + comments
+		}
+		if nonBlank > 0 {
+			language.CommentDensity = round2(100 * float64(language.CommentLines) / float64(nonBlank))
+		}
+		if n := len(tally.complexities); n > 0 {
+			sort.Ints(tally.complexities)
+			total := 0
+			for _, complexity := range tally.complexities {
+				total += complexity
+			}
+			language.AvgComplexity = round2(float64(total) / float64(n))
+			language.P50Complexity = percentile(tally.complexities, 0.5)
+			language.P90Complexity = percentile(tally.complexities, 0.9)
+			language.MaxComplexity = tally.complexities[n-1]
+		}
+		stats.Languages = append(stats.Languages, language)
+	}
+	sort.Slice(stats.Languages, func(i, j int) bool {
+		a, b := stats.Languages[i], stats.Languages[j]
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		return a.Language < b.Language
+	})
+
+	// Only types declared under the prefix are ranked, not those only extended by methods
+	for _, size := range types {
+		if size.File != "" {
+			stats.LargestTypes = append(stats.LargestTypes, *size)
+		}
+	}
+	sort.Slice(stats.LargestTypes, func(i, j int) bool {
+		a, b := stats.LargestTypes[i], stats.LargestTypes[j]
+		if a.Methods+a.Fields != b.Methods+b.Fields {
+			return a.Methods+a.Fields > b.Methods+b.Fields
+		}
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		return a.Type < b.Type
+	})
+	if limit > 0 && len(stats.LargestTypes) > limit {
+		stats.LargestTypes = stats.LargestTypes[:limit]
+	}
+
+	coupling, err := e.PackageCoupling(pathPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range coupling {
+		if c.Afferent > 0 {
+			stats.MostDependedOn = append(stats.MostDependedOn, models.PackageDependents{Package: c.Package, Dependents: c.Afferent, Types: c.Types})
+		}
+	}
+	sort.SliceStable(stats.MostDependedOn, func(i, j int) bool {
+		return stats.MostDependedOn[i].Dependents > stats.MostDependedOn[j].Dependents
+	})
+	if limit > 0 && len(stats.MostDependedOn) > limit {
+		stats.MostDependedOn = stats.MostDependedOn[:limit]
+	}
+	return stats, nil
+}
+
+// countLines returns the lines of a file, its non-blank lines that are code and those that are
+// comments. Lines of code ending with a comment count as code.
+func countLines(path string) (lines, code, comments int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer func() { _ = file.Close() }()
+
+	syntax := commentSyntaxes[strings.ToLower(filepath.Ext(path))]
+	inBlock := false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case inBlock:
+			comments++
+			inBlock = !strings.Contains(line, syntax.blockEnd)
+		case syntax.line != "" && strings.HasPrefix(line, syntax.line):
+			comments++
+		case syntax.blockStart != "" && strings.HasPrefix(line, syntax.blockStart):
+			comments++
+			inBlock = !strings.Contains(line[len(syntax.blockStart):], syntax.blockEnd)
+		default:
+			code++
+		}
+	}
+	return lines, code, comments, scanner.Err()
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []int, p float64) int {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
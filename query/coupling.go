@@ -0,0 +1,162 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// allNodes returns every node of the cache with its metadata, ordered by file and line, loaded
+// once per engine
+func (e *AQLEngine) allNodes() ([]*models.ASTNode, error) {
+	if e.all != nil {
+		return e.all, nil
+	}
+	var nodes []*models.ASTNode
+	if err := e.cache.GetReadQuery().Order("file_path, start_line").Find(&nodes).Error; err != nil {
+		return nil, fmt.Errorf("failed to query AST nodes: %w", err)
+	}
+	e.all = nodes
+	return nodes, nil
+}
+
+// PackageCoupling computes the afferent and efferent coupling, instability, abstractness and
+// distance from the main sequence of every package with a file under pathPrefix, ordered by
+// package. Coupling counts the other packages depending on or depended on by a package through
+// calls, embedding and the other dependency relationships between nodes under pathPrefix; an
+// empty prefix includes every package.
+func (e *AQLEngine) PackageCoupling(pathPrefix string) ([]*models.PackageCoupling, error) {
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make(map[string]*models.PackageCoupling)
+	packageOf := make(map[int64]string, len(nodes))
+	for _, node := range nodes {
+		if node.PackageName == "" || (pathPrefix != "" && !strings.HasPrefix(node.FilePath, pathPrefix)) {
+			continue
+		}
+		packageOf[node.ID] = node.PackageName
+		coupling, ok := packages[node.PackageName]
+		if !ok {
+			coupling = &models.PackageCoupling{Package: node.PackageName, File: node.FilePath, Line: node.StartLine}
+			packages[node.PackageName] = coupling
+		}
+		if node.NodeType == models.NodeTypeType {
+			coupling.Types++
+			if models.IsAbstract(node) {
+				coupling.AbstractTypes++
+			}
+		}
+	}
+
+	edges, err := e.groupDependencies(packageOf, dependencyTypes)
+	if err != nil {
+		return nil, err
+	}
+	for edge := range edges {
+		packages[edge.from].Efferent++
+		packages[edge.to].Afferent++
+	}
+
+	result := make([]*models.PackageCoupling, 0, len(packages))
+	for _, coupling := range packages {
+		coupling.Compute()
+		result = append(result, coupling)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Package < result[j].Package })
+	return result, nil
+}
+
+// groupEdge is a dependency of a package or type on another
+type groupEdge struct{ from, to string }
+
+// groupDependencies counts the relationships of the given types between the nodes of different
+// groups, groupOf maps the IDs of the nodes to include to their package or type
+func (e *AQLEngine) groupDependencies(groupOf map[int64]string, types []string) (map[groupEdge]int, error) {
+	var relationships []*models.ASTRelationship
+	if err := e.cache.GetReadQuery().
+		Where("to_ast_id IS NOT NULL AND relationship_type IN ?", types).
+		Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query AST relationships: %w", err)
+	}
+
+	edges := make(map[groupEdge]int)
+	for _, rel := range relationships {
+		from, ok := groupOf[rel.FromASTID]
+		if !ok {
+			continue
+		}
+		to, ok := groupOf[*rel.ToASTID]
+		if !ok || from == to {
+			continue
+		}
+		edges[groupEdge{from, to}]++
+	}
+	return edges, nil
+}
+
+// executePackageLimit executes a LIMIT statement on a coupling metric, reporting each package
+// matching the pattern that violates the condition at its first file
+func (e *AQLEngine) executePackageLimit(rule *models.AQLRule, condition *models.AQLCondition, metric string) ([]*models.Violation, error) {
+	if e.coupling == nil {
+		coupling, err := e.PackageCoupling("")
+		if err != nil {
+			return nil, err
+		}
+		e.coupling = coupling
+	}
+
+	pattern := &models.AQLPattern{Package: packagePattern(condition.Pattern)}
+	var violations []*models.Violation
+	for _, coupling := range e.coupling {
+		if !pattern.Matches(&models.ASTNode{PackageName: coupling.Package}) {
+			continue
+		}
+		value := coupling.Metric(metric)
+		violated, err := condition.Compare(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate condition: %w", err)
+		}
+		if !violated {
+			continue
+		}
+
+		violations = append(violations, &models.Violation{
+			File: coupling.File,
+			Line: coupling.Line,
+			Caller: &models.ASTNode{
+				FilePath:    coupling.File,
+				PackageName: coupling.Package,
+				StartLine:   coupling.Line,
+				NodeType:    models.NodeTypePackage,
+			},
+			Called: &models.ASTNode{
+				FilePath:    coupling.File,
+				PackageName: coupling.Package,
+				StartLine:   coupling.Line,
+				NodeType:    models.NodeTypePackage,
+			},
+			Message: models.StringPtr(fmt.Sprintf("Rule '%s': package %s has %s %v, violating %s %v (Ca=%d, Ce=%d)",
+				rule.Name, coupling.Package, metric, value, condition.Operator, condition.Value, coupling.Afferent, coupling.Efferent)),
+			Source: "aql",
+		})
+	}
+	return violations, nil
+}
+
+// packagePattern returns the package pattern of a condition on a package metric. package and *
+// match every package, and core* the packages starting with core, which patterns otherwise match
+// as types.
+func packagePattern(pattern *models.AQLPattern) string {
+	switch {
+	case pattern.Package == "package":
+		return "*"
+	case pattern.Package == "*" && pattern.Type != "" && pattern.Method == "":
+		return pattern.Type
+	}
+	return pattern.Package
+}
@@ -0,0 +1,168 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// identifier matches the identifiers of relationship and statement text, and of types
+var identifier = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// DeadCode returns the unexported functions, methods, types and variables with a file under
+// pathPrefix that no other node references, ordered by file and line. A node is referenced by
+// relationships pointing to it, or when its name is used by the relationships, statements,
+// signatures or field types of another node of its package, as most calls record the text of
+// the call rather than their target. An empty prefix includes every node.
+func (e *AQLEngine) DeadCode(pathPrefix string) ([]*models.DeadSymbol, error) {
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	var relationships []*models.ASTRelationship
+	if err := e.cache.GetReadQuery().Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query AST relationships: %w", err)
+	}
+
+	referenced := make(map[int64]bool)
+	texts := make(map[int64][]string)
+	for _, rel := range relationships {
+		if rel.ToASTID != nil && *rel.ToASTID != rel.FromASTID {
+			referenced[*rel.ToASTID] = true
+		}
+		texts[rel.FromASTID] = append(texts[rel.FromASTID], rel.Text)
+	}
+
+	// The number of nodes of each package using a name
+	users := make(map[string]map[string]int)
+	names := make(map[int64]map[string]bool, len(nodes))
+	for _, node := range nodes {
+		used := usedNames(node, texts[node.ID])
+		names[node.ID] = used
+		if users[node.PackageName] == nil {
+			users[node.PackageName] = make(map[string]int)
+		}
+		for name := range used {
+			users[node.PackageName][name]++
+		}
+	}
+
+	var dead []*models.DeadSymbol
+	for _, node := range nodes {
+		if pathPrefix != "" && !strings.HasPrefix(node.FilePath, pathPrefix) {
+			continue
+		}
+		name, kind, ok := models.DeadCodeCandidate(node)
+		if !ok || referenced[node.ID] {
+			continue
+		}
+
+		// Recursive calls do not keep a function alive
+		count := users[node.PackageName][name]
+		if names[node.ID][name] {
+			count--
+		}
+		if count > 0 {
+			continue
+		}
+
+		dead = append(dead, &models.DeadSymbol{
+			Name:    node.GetFullName(),
+			Kind:    kind,
+			Package: node.PackageName,
+			File:    node.FilePath,
+			Line:    node.StartLine,
+		})
+	}
+	return dead, nil
+}
+
+// ExecuteDeadCode reports every unexported symbol that nothing references
+func (e *AQLEngine) ExecuteDeadCode(config *models.DeadCodeConfig) ([]*models.Violation, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	const ruleName = "dead code"
+	start := time.Now()
+	dead, err := e.DeadCode("")
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for _, symbol := range dead {
+		if config.IsIgnored(symbol.File) {
+			continue
+		}
+		violations = append(violations, &models.Violation{
+			File: symbol.File,
+			Line: symbol.Line,
+			Caller: &models.ASTNode{
+				FilePath:    symbol.File,
+				PackageName: symbol.Package,
+				StartLine:   symbol.Line,
+				NodeType:    models.NodeTypePackage,
+			},
+			Called: &models.ASTNode{
+				FilePath:    symbol.File,
+				PackageName: symbol.Name,
+				StartLine:   symbol.Line,
+			},
+			Message:  models.StringPtr(fmt.Sprintf("Rule '%s': %s %s is never referenced", ruleName, symbol.Kind, symbol.Name)),
+			Source:   "aql",
+			Severity: config.GetSeverity(),
+		})
+	}
+
+	e.timings = append(e.timings, cache.RuleEvaluation{
+		Rule:       ruleName,
+		Duration:   time.Since(start),
+		Violations: len(violations),
+	})
+	return violations, nil
+}
+
+// usedNames returns the identifiers used by a node in the text of its relationships and
+// statements, its signature and its field type
+func usedNames(node *models.ASTNode, texts []string) map[string]bool {
+	used := make(map[string]bool)
+	add := func(text string) {
+		for _, name := range identifier.FindAllString(text, -1) {
+			used[name] = true
+		}
+	}
+
+	for _, text := range texts {
+		add(text)
+	}
+	var walk func(statements []models.ASTStatement)
+	walk = func(statements []models.ASTStatement) {
+		for _, statement := range statements {
+			add(statement.Text)
+			walk(statement.Children)
+		}
+	}
+	walk(node.Statements)
+	for _, param := range node.Parameters {
+		add(param.Type)
+	}
+	for _, param := range node.TypeParameters {
+		add(param.Type)
+	}
+	for _, ret := range node.ReturnValues {
+		add(ret.Type)
+	}
+	if node.FieldType != nil {
+		add(*node.FieldType)
+	}
+	if node.DefaultValue != nil {
+		add(*node.DefaultValue)
+	}
+	return used
+}
@@ -0,0 +1,147 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// callee matches the name called by the text of a call relationship, with its qualifier when
+// it is a selector, e.g. client.Do in resp, err := client.Do(req)
+var callee = regexp.MustCompile(`(?:([A-Za-z_][A-Za-z0-9_]*)\s*\.\s*)?([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+// ExecuteDeprecated reports the usages of symbols documented as deprecated, unless the symbol is
+// deprecated itself or the usage is allowed. Usages are relationships pointing to a deprecated
+// node, and calls whose text names a deprecated function of the caller's package or of a package
+// by its name, or a deprecated method of the type of a parameter of the caller. Method calls on
+// values of unknown type are not matched by name, as any type may have a method of that name.
+func (e *AQLEngine) ExecuteDeprecated(config *models.DeprecatedConfig) ([]*models.Violation, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	const ruleName = "deprecated"
+	start := time.Now()
+
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*models.ASTNode, len(nodes))
+	deprecated := make(map[int64]*models.ASTNode)
+	functions := make(map[string]*models.ASTNode) // package.function -> deprecated function
+	methods := make(map[string]*models.ASTNode)   // package.Type.method -> deprecated method
+	for _, node := range nodes {
+		byID[node.ID] = node
+		if _, ok := models.Deprecation(node); !ok {
+			continue
+		}
+		deprecated[node.ID] = node
+		if node.NodeType != models.NodeTypeMethod {
+			continue
+		}
+		if node.TypeName == "" {
+			functions[node.PackageName+"."+node.MethodName] = node
+		} else {
+			methods[node.PackageName+"."+node.TypeName+"."+node.MethodName] = node
+		}
+	}
+
+	if len(deprecated) == 0 {
+		return nil, nil
+	}
+
+	var relationships []*models.ASTRelationship
+	if err := e.cache.GetReadQuery().Order("from_ast_id, line_no").Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query AST relationships: %w", err)
+	}
+
+	var violations []*models.Violation
+	for _, rel := range relationships {
+		caller := byID[rel.FromASTID]
+		if caller == nil {
+			continue
+		}
+		if _, ok := models.Deprecation(caller); ok {
+			continue
+		}
+
+		var target *models.ASTNode
+		if rel.ToASTID != nil {
+			target = deprecated[*rel.ToASTID]
+		} else if rel.RelationshipType == models.RelationshipCall {
+			if match := callee.FindStringSubmatch(rel.Text); match != nil {
+				qualifier, name := match[1], match[2]
+				switch typeName, ok := parameterType(caller, qualifier); {
+				case qualifier == "":
+					target = functions[caller.PackageName+"."+name]
+				case ok:
+					target = methods[typeName+"."+name]
+				default:
+					target = functions[qualifier+"."+name]
+				}
+			}
+		}
+
+		if target != nil && target.ID != caller.ID {
+			symbol := target.String()
+			if config.IsAllowed(symbol, caller.FilePath) {
+				continue
+			}
+
+			message := fmt.Sprintf("Rule '%s': %s uses deprecated %s", ruleName, caller.String(), symbol)
+			if notice, _ := models.Deprecation(target); notice != "" {
+				message += ": " + notice
+			}
+			line := rel.LineNo
+			if line == 0 {
+				line = caller.StartLine
+			}
+			violations = append(violations, &models.Violation{
+				File:     caller.FilePath,
+				Line:     line,
+				Caller:   caller,
+				Called:   target,
+				Message:  models.StringPtr(message),
+				Source:   "aql",
+				Severity: config.GetSeverity(),
+			})
+		}
+	}
+
+	e.timings = append(e.timings, cache.RuleEvaluation{
+		Rule:       ruleName,
+		Duration:   time.Since(start),
+		Violations: len(violations),
+	})
+	return violations, nil
+}
+
+// parameterType returns the package qualified type of a parameter of a function, without
+// pointers, e.g. store.Client for a parameter c *store.Client, or for c *Client of package store
+func parameterType(function *models.ASTNode, name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	for _, param := range function.Parameters {
+		if param.Name != name {
+			continue
+		}
+		typeName := strings.TrimLeft(param.Type, "*&")
+		if dot := strings.LastIndex(typeName, "."); dot >= 0 {
+			// Fully qualified types keep the import path, e.g. github.com/acme/store.Client
+			pkg := typeName[:dot]
+			if slash := strings.LastIndex(pkg, "/"); slash >= 0 {
+				pkg = pkg[slash+1:]
+			}
+			return pkg + typeName[dot:], true
+		}
+		return function.PackageName + "." + typeName, true
+	}
+	return "", false
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Explain evaluates a rule and describes each of its statements, the query run for every pattern
+// and the nodes it matches, followed by up to limit example violations of the rule
+func (e *AQLEngine) Explain(rule *models.AQLRule, limit int) (*models.RuleExplanation, error) {
+	explanation := &models.RuleExplanation{
+		Rule:   rule.Name,
+		Parsed: rule.String(),
+	}
+
+	for _, stmt := range rule.Statements {
+		statement := models.StatementExplanation{Statement: stmt.String()}
+		for _, pattern := range statementPatterns(stmt) {
+			nodes, err := e.findMatchingNodes(pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			query, args := patternQuery(pattern)
+			explained := models.PatternExplanation{
+				Pattern: pattern.String(),
+				Query:   inlineArgs(query, args),
+				Matches: len(nodes),
+			}
+			if pattern.FilePath != "" && pattern.FilePath != "*" {
+				explained.Query += fmt.Sprintf(" -- filtered on file path %s", pattern.FilePath)
+			}
+			for i := 0; i < len(nodes) && i < limit; i++ {
+				explained.Examples = append(explained.Examples, nodes[i].GetFullName())
+			}
+			statement.Patterns = append(statement.Patterns, explained)
+		}
+		explanation.Statements = append(explanation.Statements, statement)
+	}
+
+	violations, err := e.ExecuteRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	explanation.ViolationCount = len(violations)
+	for i := 0; i < len(violations) && i < limit; i++ {
+		match := models.QueryMatch{File: violations[i].File, Line: violations[i].Line}
+		if violations[i].Called != nil {
+			match.Name = violations[i].Called.GetFullName()
+		}
+		if violations[i].Message != nil {
+			match.Message = *violations[i].Message
+		}
+		explanation.Violations = append(explanation.Violations, match)
+	}
+
+	return explanation, nil
+}
+
+// statementPatterns returns the patterns a statement selects nodes with
+func statementPatterns(stmt *models.AQLStatement) []*models.AQLPattern {
+	if stmt.Expression != nil {
+		return expressionPatterns(stmt.Expression)
+	}
+	var patterns []*models.AQLPattern
+	if stmt.Condition != nil && stmt.Condition.Pattern != nil {
+		patterns = append(patterns, stmt.Condition.Pattern)
+	}
+	for _, pattern := range []*models.AQLPattern{stmt.Pattern, stmt.FromPattern, stmt.ToPattern} {
+		if pattern != nil {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// expressionPatterns returns the patterns of the conditions and patterns of an expression
+func expressionPatterns(expr *models.AQLExpression) []*models.AQLPattern {
+	if expr.Condition != nil && expr.Condition.Pattern != nil {
+		return []*models.AQLPattern{expr.Condition.Pattern}
+	}
+	if expr.Pattern != nil {
+		return []*models.AQLPattern{expr.Pattern}
+	}
+	var patterns []*models.AQLPattern
+	for _, operand := range expr.Operands {
+		patterns = append(patterns, expressionPatterns(operand)...)
+	}
+	return patterns
+}
+
+// inlineArgs replaces the placeholders of a query with its quoted arguments
+func inlineArgs(query string, args []interface{}) string {
+	for _, arg := range args {
+		query = strings.Replace(query, "?", fmt.Sprintf("'%v'", arg), 1)
+	}
+	return query
+}
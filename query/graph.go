@@ -0,0 +1,199 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// typedEdge is a dependency of a package or type on another through relationships of a type,
+// empty for the dependencies of every type
+type typedEdge struct {
+	groupEdge
+	kind string
+}
+
+// DependencyGraph returns the packages or types of the files under the path prefix of the
+// options with the dependencies between them. With a pattern, the graph starts from the matching
+// packages or types and follows their dependencies up to the depth of the options.
+func (e *AQLEngine) DependencyGraph(options models.GraphOptions) (*models.DependencyGraph, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	groupOf := make(map[int64]string, len(nodes))
+	groups := make(map[string]bool)
+	for _, node := range nodes {
+		if options.PathPrefix != "" && !strings.HasPrefix(node.FilePath, options.PathPrefix) {
+			continue
+		}
+		group := graphGroup(node, options.Level)
+		if group == "" {
+			continue
+		}
+		groupOf[node.ID] = group
+		groups[group] = true
+	}
+
+	edges := make(map[typedEdge]int)
+	external := make(map[string]bool)
+	if len(options.Edges) == 0 {
+		dependencies, err := e.groupDependencies(groupOf, dependencyTypes)
+		if err != nil {
+			return nil, err
+		}
+		for edge, count := range dependencies {
+			edges[typedEdge{edge, ""}] = count
+		}
+	}
+	for _, kind := range options.Edges {
+		dependencies, err := e.groupDependencies(groupOf, []string{kind})
+		if err != nil {
+			return nil, err
+		}
+		libraries, err := e.libraryDependencies(groupOf, kind, options.Level)
+		if err != nil {
+			return nil, err
+		}
+		for edge, count := range libraries {
+			if !groups[edge.to] {
+				external[edge.to] = true
+			}
+			dependencies[edge] += count
+		}
+		for edge, count := range dependencies {
+			edges[typedEdge{edge, kind}] = count
+		}
+	}
+
+	for group := range external {
+		groups[group] = true
+	}
+	for group := range groups {
+		if excludedGroup(group, options.Exclude) {
+			delete(groups, group)
+		}
+	}
+
+	included := groups
+	if options.Pattern != "" {
+		dependencies := make(map[string][]string)
+		for edge := range edges {
+			if groups[edge.from] && groups[edge.to] {
+				dependencies[edge.from] = append(dependencies[edge.from], edge.to)
+			}
+		}
+		included = reachableGroups(groups, dependencies, options.Pattern, options.Depth)
+	}
+
+	graph := &models.DependencyGraph{Level: options.Level, Nodes: []string{}, Edges: []*models.DependencyEdge{}}
+	for group := range included {
+		graph.Nodes = append(graph.Nodes, group)
+		if external[group] {
+			graph.External = append(graph.External, group)
+		}
+	}
+	sort.Strings(graph.Nodes)
+	sort.Strings(graph.External)
+	for edge, count := range edges {
+		if included[edge.from] && included[edge.to] {
+			graph.Edges = append(graph.Edges, &models.DependencyEdge{From: edge.from, To: edge.to, Count: count, Type: edge.kind})
+		}
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+	return graph, nil
+}
+
+// libraryDependencies counts the relationships of a type from the nodes of groups to external
+// libraries, by library package or by library class for type graphs
+func (e *AQLEngine) libraryDependencies(groupOf map[int64]string, kind, level string) (map[groupEdge]int, error) {
+	var relationships []*models.LibraryRelationship
+	if err := e.cache.GetReadQuery().Preload("LibraryNode").
+		Where("relationship_type = ?", kind).
+		Find(&relationships).Error; err != nil {
+		return nil, fmt.Errorf("failed to query library relationships: %w", err)
+	}
+
+	edges := make(map[groupEdge]int)
+	for _, rel := range relationships {
+		from, ok := groupOf[rel.ASTID]
+		if !ok || rel.LibraryNode == nil || rel.LibraryNode.Package == "" {
+			continue
+		}
+		to := rel.LibraryNode.Package
+		if level == models.GraphLevelType && rel.LibraryNode.Class != "" {
+			to += "." + rel.LibraryNode.Class
+		}
+		if to != from {
+			edges[groupEdge{from, to}]++
+		}
+	}
+	return edges, nil
+}
+
+// graphGroup returns the package of a node, or its type named package.Type, empty for nodes
+// outside of a package or type
+func graphGroup(node *models.ASTNode, level string) string {
+	if node.PackageName == "" {
+		return ""
+	}
+	if level == models.GraphLevelPackage {
+		return node.PackageName
+	}
+	if node.TypeName == "" {
+		return ""
+	}
+	return node.PackageName + "." + node.TypeName
+}
+
+// excludedGroup returns true if a package or type matches one of the exclude patterns
+func excludedGroup(group string, exclude []string) bool {
+	for _, pattern := range exclude {
+		if match, _ := doublestar.Match(pattern, group); match {
+			return true
+		}
+	}
+	return false
+}
+
+// reachableGroups returns the groups matching pattern and the groups they depend on, following
+// up to depth dependencies
+func reachableGroups(groups map[string]bool, dependencies map[string][]string, pattern string, depth int) map[string]bool {
+	reached := make(map[string]bool)
+	var frontier []string
+	for group := range groups {
+		if match, _ := doublestar.Match(pattern, group); match {
+			reached[group] = true
+			frontier = append(frontier, group)
+		}
+	}
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		var next []string
+		for _, group := range frontier {
+			for _, dependency := range dependencies[group] {
+				if !reached[dependency] {
+					reached[dependency] = true
+					next = append(next, dependency)
+				}
+			}
+		}
+		frontier = next
+	}
+	return reached
+}
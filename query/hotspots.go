@@ -0,0 +1,103 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+// Hotspots ranks the files under pathPrefix by churn times complexity: the commits changing a
+// file, from churn by absolute path, times the sum of the cyclomatic complexity of its functions.
+// Files without commits or functions are left out. An empty prefix includes every file.
+func (e *AQLEngine) Hotspots(churn map[string]int, pathPrefix string) ([]*models.Hotspot, error) {
+	nodes, err := e.allNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*models.Hotspot)
+	for _, node := range nodes {
+		commits := churn[node.FilePath]
+		if commits == 0 || (pathPrefix != "" && !strings.HasPrefix(node.FilePath, pathPrefix)) {
+			continue
+		}
+		hotspot, ok := files[node.FilePath]
+		if !ok {
+			hotspot = &models.Hotspot{File: node.FilePath, Commits: commits}
+			files[node.FilePath] = hotspot
+		}
+		if node.EndLine > hotspot.Lines {
+			hotspot.Lines = node.EndLine
+		}
+		if isMethod(node) {
+			hotspot.Functions++
+			hotspot.Complexity += node.CyclomaticComplexity
+		}
+	}
+
+	hotspots := make([]*models.Hotspot, 0, len(files))
+	for _, hotspot := range files {
+		if hotspot.Functions == 0 {
+			continue
+		}
+		hotspot.Score = hotspot.Commits * hotspot.Complexity
+		hotspots = append(hotspots, hotspot)
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Score != hotspots[j].Score {
+			return hotspots[i].Score > hotspots[j].Score
+		}
+		return hotspots[i].File < hotspots[j].File
+	})
+	return hotspots, nil
+}
+
+// ExecuteHotspots reports the files whose churn times complexity exceeds the threshold of config
+func (e *AQLEngine) ExecuteHotspots(config *models.HotspotConfig, churn map[string]int) ([]*models.Violation, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	const ruleName = "hotspots"
+	start := time.Now()
+	hotspots, err := e.Hotspots(churn, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*models.Violation
+	for _, hotspot := range hotspots {
+		if hotspot.Score <= config.GetThreshold() || config.IsIgnored(hotspot.File) {
+			continue
+		}
+		violations = append(violations, &models.Violation{
+			File: hotspot.File,
+			Line: 1,
+			Caller: &models.ASTNode{
+				FilePath:  hotspot.File,
+				StartLine: 1,
+				NodeType:  models.NodeTypePackage,
+			},
+			Called: &models.ASTNode{
+				FilePath:    hotspot.File,
+				PackageName: hotspot.File,
+				StartLine:   1,
+			},
+			Message: models.StringPtr(fmt.Sprintf("Rule '%s': changed by %d commits with a complexity of %d, a score of %d above %d, consider refactoring it",
+				ruleName, hotspot.Commits, hotspot.Complexity, hotspot.Score, config.GetThreshold())),
+			Source:   "aql",
+			Severity: config.GetSeverity(),
+		})
+	}
+
+	e.timings = append(e.timings, cache.RuleEvaluation{
+		Rule:       ruleName,
+		Duration:   time.Since(start),
+		Violations: len(violations),
+	})
+	return violations, nil
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/parser"
+)
+
+// RunNamedQuery executes a named query from arch-unit.yaml and returns the matched nodes.
+// Only matches whose file path is under pathPrefix are returned; an empty prefix matches everything.
+func (e *AQLEngine) RunNamedQuery(name string, q *models.NamedQuery, pathPrefix string) (*models.NamedQueryResult, error) {
+	result := &models.NamedQueryResult{
+		Name:        name,
+		Description: q.Description,
+		Query:       q.Expression(),
+	}
+
+	inScope := func(file string) bool {
+		return pathPrefix == "" || strings.HasPrefix(file, pathPrefix)
+	}
+
+	if q.AQL != "" {
+		ruleSet, err := parser.ParseAQL(fmt.Sprintf(`RULE %q { LIMIT(%s) }`, name, q.AQL))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query '%s': %w", name, err)
+		}
+
+		violations, err := e.ExecuteRuleSet(ruleSet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query '%s': %w", name, err)
+		}
+
+		for _, v := range violations {
+			if !inScope(v.File) {
+				continue
+			}
+			match := models.QueryMatch{File: v.File, Line: v.Line}
+			if v.Called != nil {
+				match.Name = v.Called.PackageName
+			}
+			if v.Message != nil {
+				match.Message = *v.Message
+			}
+			result.Matches = append(result.Matches, match)
+		}
+	} else {
+		pattern, err := models.ParsePattern(q.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern in query '%s': %w", name, err)
+		}
+
+		nodes, err := e.FindNodes(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute query '%s': %w", name, err)
+		}
+
+		for _, node := range nodes {
+			if !inScope(node.FilePath) || !pattern.Matches(node) {
+				continue
+			}
+			result.Matches = append(result.Matches, models.QueryMatch{
+				File: node.FilePath,
+				Line: node.StartLine,
+				Name: node.GetFullName(),
+			})
+		}
+	}
+
+	result.Count = len(result.Matches)
+	return result, nil
+}
+
+// RunReport executes every query referenced by a saved report
+func (e *AQLEngine) RunReport(config *models.Config, name string, pathPrefix string) (*models.QueryReport, error) {
+	reportConfig, ok := config.Reports[name]
+	if !ok {
+		return nil, fmt.Errorf("report '%s' not found in configuration", name)
+	}
+
+	report := &models.QueryReport{
+		Name:  name,
+		Title: reportConfig.Title,
+	}
+
+	for _, queryName := range reportConfig.Queries {
+		q, ok := config.GetQuery(queryName)
+		if !ok {
+			return nil, fmt.Errorf("report '%s' references unknown query '%s'", name, queryName)
+		}
+		result, err := e.RunNamedQuery(queryName, q, pathPrefix)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, *result)
+	}
+
+	return report, nil
+}
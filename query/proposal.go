@@ -0,0 +1,86 @@
+package query
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/parser"
+)
+
+// Preview returns the violations a proposed rule would report for the files under workingDir.
+// Import rules are checked against the calls into third-party packages recorded in the cache.
+func (e *AQLEngine) Preview(proposal *models.RuleProposal, workingDir string) ([]*models.Violation, error) {
+	prefix := strings.TrimSuffix(workingDir, "/") + "/"
+	if !proposal.IsAQL() {
+		return e.previewImports(proposal, workingDir, prefix)
+	}
+
+	ruleSet, err := parser.ParseAQL(proposal.AQL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AQL: %w", err)
+	}
+	violations, err := e.ExecuteRuleSet(ruleSet)
+	if err != nil {
+		return nil, err
+	}
+	var matching []*models.Violation
+	for _, v := range violations {
+		if v.File == "" || strings.HasPrefix(v.File, prefix) {
+			matching = append(matching, v)
+		}
+	}
+	return matching, nil
+}
+
+func (e *AQLEngine) previewImports(proposal *models.RuleProposal, workingDir, prefix string) ([]*models.Violation, error) {
+	config := &models.Config{Rules: map[string]models.RuleConfig{proposal.Path: {Imports: proposal.Imports}}}
+
+	rows, err := e.cache.QueryRaw(`SELECT n.file_path, r.line_no, l.package, COALESCE(l.method, '')
+		FROM library_relationships r
+		JOIN ast_nodes n ON n.id = r.ast_id
+		JOIN library_nodes l ON l.id = r.library_id
+		WHERE n.file_path LIKE ?
+		ORDER BY n.file_path, r.line_no`, prefix+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query library calls: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ruleSets := make(map[string]*models.RuleSet)
+	var violations []*models.Violation
+	for rows.Next() {
+		var file, pkg, method string
+		var line int
+		if err := rows.Scan(&file, &line, &pkg, &method); err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(workingDir, file)
+		if err != nil {
+			rel = file
+		}
+		ruleSet, ok := ruleSets[file]
+		if !ok {
+			if ruleSet, err = config.GetRulesForFile(rel); err != nil {
+				return nil, err
+			}
+			ruleSets[file] = ruleSet
+		}
+		if len(ruleSet.Rules) == 0 {
+			continue
+		}
+		if allowed, rule := ruleSet.IsAllowedForFile(pkg, method, rel); !allowed {
+			violations = append(violations, &models.Violation{
+				File:    file,
+				Line:    line,
+				Caller:  &models.ASTNode{FilePath: file, StartLine: line},
+				Called:  &models.ASTNode{PackageName: pkg, MethodName: method},
+				Rule:    rule,
+				Message: models.StringPtr(fmt.Sprintf("%s uses %s", rel, strings.TrimSuffix(pkg+"."+method, "."))),
+				Source:  "arch-unit",
+			})
+		}
+	}
+	return violations, rows.Err()
+}
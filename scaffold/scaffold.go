@@ -0,0 +1,123 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateSuffix marks files rendered with text/template, the suffix is stripped when generating.
+// Go sources and go.mod files always carry it so they are not compiled or treated as modules.
+const templateSuffix = ".tmpl"
+
+//go:embed all:templates
+var templates embed.FS
+
+// Example is a project that can be generated
+type Example struct {
+	Name        string
+	Description string
+}
+
+// Data is passed to templated files
+type Data struct {
+	// Name of the generated project, defaults to the name of its directory
+	Name string
+	// Module is the Go module path of the generated project
+	Module string
+}
+
+var examples = []Example{
+	{Name: "go-layered", Description: "Layered Go service (api -> service -> repository) with layering, import and complexity violations"},
+	{Name: "react-go", Description: "React frontend calling a Go API, with components bypassing the API client and reading the environment"},
+	{Name: "helm-chart", Description: "Helm chart with unpinned chart dependencies and image tags"},
+}
+
+// List returns the examples that can be generated
+func List() []Example {
+	return append([]Example(nil), examples...)
+}
+
+// Match returns the examples whose name matches the glob pattern, e.g. "go-*" or "*"
+func Match(pattern string) ([]Example, error) {
+	var matched []Example
+	for _, example := range examples {
+		ok, err := path.Match(pattern, example.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid example pattern %q: %w", pattern, err)
+		}
+		if ok {
+			matched = append(matched, example)
+		}
+	}
+	return matched, nil
+}
+
+// Generate writes the files of the named example into dir, returning the paths written. Existing
+// files are only overwritten when force is set.
+func Generate(name, dir string, data Data, force bool) ([]string, error) {
+	root := path.Join("templates", name)
+	if _, err := fs.Stat(templates, root); err != nil {
+		return nil, fmt.Errorf("unknown example %q", name)
+	}
+	if data.Name == "" {
+		data.Name = filepath.Base(dir)
+	}
+	if data.Module == "" {
+		data.Module = "example.com/" + data.Name
+	}
+
+	var written []string
+	err := fs.WalkDir(templates, root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := templates.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(file, root+"/")
+		if strings.HasSuffix(rel, templateSuffix) {
+			rel = strings.TrimSuffix(rel, templateSuffix)
+			if content, err = render(file, content, data); err != nil {
+				return err
+			}
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if _, err := os.Stat(target); err == nil && !force {
+			return fmt.Errorf("%s already exists, use --force to overwrite", target)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		written = append(written, target)
+		return nil
+	})
+	if err != nil {
+		return written, fmt.Errorf("failed to generate example %s: %w", name, err)
+	}
+	return written, nil
+}
+
+func render(file string, content []byte, data Data) ([]byte, error) {
+	tmpl, err := template.New(path.Base(file)).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", file, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", file, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Scaffold", func() {
+	Context("Match", func() {
+		It("should match examples by glob", func() {
+			matched, err := Match("go-*")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matched).To(HaveLen(1))
+			Expect(matched[0].Name).To(Equal("go-layered"))
+
+			matched, err = Match("*")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matched).To(Equal(List()))
+		})
+
+		It("should reject invalid patterns", func() {
+			_, err := Match("[")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Generate", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+		})
+
+		for _, example := range List() {
+			example := example
+			It("should generate valid files for "+example.Name, func() {
+				files, err := Generate(example.Name, filepath.Join(dir, example.Name), Data{Module: "example.com/demo"}, false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).NotTo(BeEmpty())
+
+				for _, file := range files {
+					Expect(file).NotTo(HaveSuffix(templateSuffix))
+					content, err := os.ReadFile(file)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).NotTo(ContainSubstring("{{.Name}}"))
+
+					switch {
+					case strings.HasSuffix(file, ".go"):
+						_, err := parser.ParseFile(token.NewFileSet(), file, content, 0)
+						Expect(err).NotTo(HaveOccurred(), file)
+					case strings.HasSuffix(file, ".json"):
+						Expect(json.Valid(content)).To(BeTrue(), file)
+					case strings.HasSuffix(file, ".yaml") && !strings.Contains(filepath.ToSlash(file), "/templates/"):
+						var value interface{}
+						Expect(yaml.Unmarshal(content, &value)).To(Succeed(), file)
+					}
+				}
+			})
+		}
+
+		It("should substitute the module path", func() {
+			_, err := Generate("go-layered", dir, Data{Module: "example.com/demo"}, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(goMod)).To(HavePrefix("module example.com/demo\n"))
+
+			main, err := os.ReadFile(filepath.Join(dir, "cmd", "server", "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(main)).To(ContainSubstring(`"example.com/demo/internal/api"`))
+		})
+
+		It("should copy Helm templates verbatim", func() {
+			_, err := Generate("helm-chart", dir, Data{}, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment, err := os.ReadFile(filepath.Join(dir, "templates", "deployment.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(deployment)).To(ContainSubstring("{{ .Values.image.repository }}"))
+
+			chart, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(chart)).To(ContainSubstring("name: " + filepath.Base(dir)))
+		})
+
+		It("should not overwrite existing files unless forced", func() {
+			_, err := Generate("helm-chart", dir, Data{}, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = Generate("helm-chart", dir, Data{}, false)
+			Expect(err).To(MatchError(ContainSubstring("already exists")))
+
+			_, err = Generate("helm-chart", dir, Data{}, true)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject unknown examples", func() {
+			_, err := Generate("missing", dir, Data{}, false)
+			Expect(err).To(MatchError(ContainSubstring("unknown example")))
+		})
+	})
+})
@@ -0,0 +1,8 @@
+import React from 'react';
+import { UserList } from './components/UserList';
+
+const h = React.createElement;
+
+export function App() {
+  return h('main', null, h('h1', null, 'Users'), h(UserList));
+}
@@ -0,0 +1,11 @@
+import axios from 'axios';
+
+const BASE_URL = process.env.API_URL || 'http://localhost:8080';
+
+export class ApiClient {
+  listUsers() {
+    return axios.get(`${BASE_URL}/api/users`).then((response) => response.data);
+  }
+}
+
+export const api = new ApiClient();
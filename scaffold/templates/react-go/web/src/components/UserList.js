@@ -0,0 +1,21 @@
+import React from 'react';
+
+const h = React.createElement;
+
+export class UserList extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = { users: [] };
+  }
+
+  // Violations: the component calls fetch and reads process.env instead of using ApiClient
+  componentDidMount() {
+    fetch(`${process.env.API_URL}/api/users`)
+      .then((response) => response.json())
+      .then((users) => this.setState({ users }));
+  }
+
+  render() {
+    return h('ul', null, this.state.users.map((user) => h('li', { key: user.id }, user.name)));
+  }
+}
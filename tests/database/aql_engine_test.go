@@ -1,7 +1,11 @@
 package database_test_suite
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -116,6 +120,128 @@ rules:
 		})
 	})
 
+	Context("Aggregate LIMIT Statements", func() {
+		messages := func(aql string) []string {
+			ruleSet, err := parser.ParseAQL(aql)
+			Expect(err).ToNot(HaveOccurred())
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+
+			var messages []string
+			for _, v := range violations {
+				Expect(v.File).ToNot(BeEmpty())
+				messages = append(messages, *v.Message)
+			}
+			return messages
+		}
+
+		It("should count the methods of each package", func() {
+			Expect(messages(`RULE "Small packages" { LIMIT(COUNT(*.methods) > 1) }`)).To(ConsistOf(
+				"Rule 'Small packages': package controller has COUNT(*.methods) 2, violating > 1",
+			))
+		})
+
+		It("should average the metric of the methods of each package", func() {
+			Expect(messages(`RULE "Simple packages" { LIMIT(AVG(*.cyclomatic) > 4) }`)).To(ConsistOf(
+				"Rule 'Simple packages': package controller has AVG(*.cyclomatic) 13.5, violating > 4",
+				"Rule 'Simple packages': package service has AVG(*.cyclomatic) 5, violating > 4",
+			))
+		})
+
+		It("should roll metrics up per type", func() {
+			Expect(messages(`RULE "Simple types" { LIMIT(MAX(controller.*.cyclomatic, type) > 10) }`)).To(ConsistOf(
+				"Rule 'Simple types': type controller.ComplexController has MAX(controller.*.cyclomatic, type) 25, violating > 10",
+			))
+		})
+
+		It("should report the package or type rolled up as the called node", func() {
+			called := func(aql string) *models.ASTNode {
+				ruleSet, err := parser.ParseAQL(aql)
+				Expect(err).ToNot(HaveOccurred())
+				violations, err := engine.ExecuteRuleSet(ruleSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(violations).To(HaveLen(1))
+				return violations[0].Called
+			}
+
+			pkg := called(`RULE "Small packages" { LIMIT(COUNT(*.methods) > 1) }`)
+			Expect(pkg.NodeType).To(Equal(models.NodeTypePackage))
+			Expect(pkg.ShortName().String()).To(ContainSubstring("controller"))
+
+			typ := called(`RULE "Simple types" { LIMIT(MAX(controller.*.cyclomatic, type) > 10) }`)
+			Expect(typ.NodeType).To(Equal(models.NodeTypeType))
+			Expect(typ.PackageName).To(Equal("controller"))
+			Expect(typ.ShortName().String()).To(ContainSubstring("ComplexController"))
+		})
+	})
+
+	Context("Coverage", func() {
+		It("should compare the coverage of nodes that have one", func() {
+			Expect(astCache.GetWriteQuery().Model(&models.ASTNode{}).Where("type_name = ?", "UserService").Update("coverage", 59.5).Error).ToNot(HaveOccurred())
+			Expect(astCache.GetWriteQuery().Model(&models.ASTNode{}).Where("type_name = ?", "UserRepository").Update("coverage", 80.0).Error).ToNot(HaveOccurred())
+
+			ruleSet, err := parser.ParseAQL(`RULE "Covered" {
+				LIMIT(*.coverage < 60)
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1)) // Nodes without coverage are not checked
+			Expect(*violations[0].Message).To(ContainSubstring("UserService.CreateUser"))
+		})
+	})
+
+	Context("Boolean Expressions", func() {
+		messages := func(aql string) []string {
+			ruleSet, err := parser.ParseAQL(aql)
+			Expect(err).ToNot(HaveOccurred())
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+
+			var messages []string
+			for _, v := range violations {
+				messages = append(messages, *v.Message)
+			}
+			return messages
+		}
+
+		It("should report nodes satisfying every condition of an AND", func() {
+			Expect(messages(`RULE "Complex non controllers" {
+				LIMIT(*.cyclomatic > 3 AND NOT *Controller*.cyclomatic > 0)
+			}`)).To(ConsistOf(
+				"Rule 'Complex non controllers': service.UserService.CreateUser violated limit",
+			))
+		})
+
+		It("should report nodes satisfying any condition of an OR", func() {
+			Expect(messages(`RULE "Outliers" {
+				LIMIT(*.cyclomatic > 20 OR (*.cyclomatic < 2 AND *.params < 5))
+			}`)).To(ConsistOf(
+				"Rule 'Outliers': controller.ComplexController.ProcessOrder violated limit",
+				"Rule 'Outliers': repository.UserRepository.Save violated limit",
+				"Rule 'Outliers': model.User violated limit",
+			))
+		})
+
+		It("should forbid patterns combined with NOT", func() {
+			Expect(messages(`RULE "Only simple controllers" {
+				FORBID(controller AND NOT *Simple*)
+			}`)).To(ConsistOf(
+				"Rule 'Only simple controllers': Forbidden pattern controller AND NOT *Simple* found in controller.ComplexController.ProcessOrder",
+			))
+		})
+
+		It("should only report the nodes of the patterns an expression requires", func() {
+			Expect(messages(`RULE "Simple layers" {
+				FORBID((controller OR service) AND NOT *Complex*)
+			}`)).To(ConsistOf(
+				"Rule 'Simple layers': Forbidden pattern (controller OR service) AND NOT *Complex* found in controller.SimpleController.GetUser",
+				"Rule 'Simple layers': Forbidden pattern (controller OR service) AND NOT *Complex* found in service.UserService.CreateUser",
+			))
+		})
+	})
+
 	Context("FORBID Statements", func() {
 		It("should not find direct controller to repository calls", func() {
 			aql := `RULE "Layer Violation" {
@@ -261,6 +387,800 @@ rules:
 		)
 	})
 
+	Context("HTTP Calls", func() {
+		BeforeEach(func() {
+			endpoint := &models.ASTNode{
+				FilePath:     "/test/billing.yaml",
+				PackageName:  "billing",
+				MethodName:   "getInvoice",
+				NodeType:     models.NodeTypeMethodHTTPGet,
+				StartLine:    -1,
+				LastModified: time.Now(),
+				Metatdata:    map[string]string{"method": "GET", "path": "/invoices/{id}"},
+			}
+			_, err := astCache.StoreASTNode(endpoint)
+			Expect(err).ToNot(HaveOccurred())
+
+			pattern, err := models.ParsePattern("service:UserService:CreateUser")
+			Expect(err).ToNot(HaveOccurred())
+			nodes, err := engine.FindNodes(pattern)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nodes).To(HaveLen(1))
+
+			Expect(astCache.StoreASTRelationship(nodes[0].ID, nil, 12, models.RelationshipHTTPCall, "GET http://billing.svc/api/invoices/{}")).To(Succeed())
+			Expect(astCache.StoreASTRelationship(nodes[0].ID, nil, 14, models.RelationshipHTTPCall, "POST http://payments/charges")).To(Succeed())
+		})
+
+		It("should resolve calls to the OpenAPI operation serving their route", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No direct billing calls" {
+				FORBID(service:* -> billing:*)
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Line).To(Equal(12))
+		})
+
+		It("should match calls without an OpenAPI operation by host", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No direct payment calls" {
+				FORBID(service:* -> payments:*)
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Line).To(Equal(14))
+		})
+	})
+
+	Context("Environment Variables", func() {
+		BeforeEach(func() {
+			for pattern, envVar := range map[string]string{
+				"service:UserService:CreateUser":      "DATABASE_URL",
+				"controller:SimpleController:GetUser": "LOG_LEVEL",
+			} {
+				p, err := models.ParsePattern(pattern)
+				Expect(err).ToNot(HaveOccurred())
+				nodes, err := engine.FindNodes(p)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(nodes).To(HaveLen(1))
+
+				Expect(astCache.StoreASTRelationship(nodes[0].ID, nil, 20, models.RelationshipEnvVar, envVar)).To(Succeed())
+			}
+		})
+
+		It("should forbid environment access outside of config packages", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Config only" {
+				FORBID(service:* -> env:*)
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(*violations[0].Message).To(ContainSubstring("env.DATABASE_URL"))
+		})
+
+		It("should match environment variables by name", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No database access from controllers" {
+				FORBID(controller:* -> env:DATABASE_URL)
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(BeEmpty())
+		})
+	})
+
+	Context("CYCLE Statements", func() {
+		It("should not report acyclic packages", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No cycles" { CYCLE(*) }`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(BeEmpty())
+		})
+
+		Context("with a repository calling back into a controller", func() {
+			BeforeEach(func() {
+				ids := map[string]int64{}
+				for _, pattern := range []string{"repository:UserRepository:Save", "controller:SimpleController:GetUser"} {
+					p, err := models.ParsePattern(pattern)
+					Expect(err).ToNot(HaveOccurred())
+					nodes, err := engine.FindNodes(p)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(nodes).To(HaveLen(1))
+					ids[pattern] = nodes[0].ID
+				}
+				controller := ids["controller:SimpleController:GetUser"]
+				Expect(astCache.StoreASTRelationship(ids["repository:UserRepository:Save"], &controller, 9, models.RelationshipCall, "controller.GetUser()")).To(Succeed())
+			})
+
+			It("should report the cycle among packages with its full path", func() {
+				ruleSet, err := parser.ParseAQL(`RULE "No cycles" { CYCLE(*) }`)
+				Expect(err).ToNot(HaveOccurred())
+
+				violations, err := engine.ExecuteRuleSet(ruleSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(violations).To(HaveLen(1))
+				Expect(*violations[0].Message).To(ContainSubstring("Dependency cycle controller -> service -> repository -> controller"))
+				Expect(*violations[0].Message).To(ContainSubstring("repository.UserRepository.Save call controller.SimpleController.GetUser at /test/UserRepository.go:9"))
+				Expect(violations[0].File).To(Equal("/test/SimpleController.go"))
+				Expect(violations[0].Line).To(Equal(12))
+			})
+
+			It("should report the cycle among types", func() {
+				ruleSet, err := parser.ParseAQL(`RULE "No cycles" { CYCLE(*, type) }`)
+				Expect(err).ToNot(HaveOccurred())
+
+				violations, err := engine.ExecuteRuleSet(ruleSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(violations).To(HaveLen(1))
+				Expect(*violations[0].Message).To(ContainSubstring("Dependency cycle controller.SimpleController -> service.UserService -> repository.UserRepository -> controller.SimpleController"))
+			})
+
+			It("should only follow dependencies among matching packages", func() {
+				ruleSet, err := parser.ParseAQL(`RULE "No cycles" { CYCLE(*Service*) }`)
+				Expect(err).ToNot(HaveOccurred())
+
+				violations, err := engine.ExecuteRuleSet(ruleSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(violations).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("Severity", func() {
+		It("should report violations with the severity of their rule, error by default", func() {
+			ruleSet, err := parser.ParseAQL(`
+				RULE "Layers" SEVERITY warning { FORBID(controller -> service) }
+				RULE "Services" { FORBID(service -> repository) }`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(3))
+			Expect(violations[0].Severity).To(Equal(models.SeverityWarning))
+			Expect(violations[1].Severity).To(Equal(models.SeverityWarning))
+			Expect(violations[2].Severity).To(Equal(models.SeverityError))
+		})
+	})
+
+	Context("Transitive FORBID Statements", func() {
+		BeforeEach(func() {
+			p, err := models.ParsePattern("repository:UserRepository:Save")
+			Expect(err).ToNot(HaveOccurred())
+			nodes, err := engine.FindNodes(p)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nodes).To(HaveLen(1))
+			Expect(astCache.StoreASTRelationship(nodes[0].ID, nil, 20, models.RelationshipSQLQuery, "public.users")).To(Succeed())
+		})
+
+		It("should report indirect paths with their full call path", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No SQL in controllers" { FORBID(controller ->> sql/*) }`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(2))
+
+			var messages []string
+			for _, v := range violations {
+				messages = append(messages, *v.Message)
+				if v.File == "/test/SimpleController.go" {
+					Expect(v.Line).To(Equal(12))
+				}
+			}
+			Expect(messages).To(ContainElement("Rule 'No SQL in controllers': Forbidden path from controller.SimpleController.GetUser to public.users in 3 hops: " +
+				"controller.SimpleController.GetUser -> service.UserService.CreateUser -> repository.UserRepository.Save -> public.users"))
+		})
+
+		It("should not follow paths longer than the number of hops", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "No SQL in controllers" {
+				FORBID(controller ->> sql/*, 2)
+				FORBID(controller ->> repository, 2)
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(2))
+			for _, v := range violations {
+				Expect(*v.Message).To(ContainSubstring("to repository.UserRepository.Save in 2 hops"))
+			}
+		})
+	})
+
+	Context("Layers", func() {
+		layers := models.Layers{
+			{Name: "controller", Packages: []string{"controller"}, Allow: []string{"service"}},
+			{Name: "service", Packages: []string{"service"}},
+			{Name: "repository", Packages: []string{"repo*"}},
+		}
+
+		It("should report dependencies on layers that are not allowed", func() {
+			violations, err := engine.ExecuteLayers(layers)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].File).To(Equal("/test/UserService.go"))
+			Expect(violations[0].Line).To(Equal(18))
+			Expect(*violations[0].Message).To(Equal("Rule 'layers': Forbidden call from service.UserService.CreateUser to repository.UserRepository.Save, layer service may not depend on layer repository"))
+		})
+
+		It("should allow dependencies on allowed layers and packages outside of all layers", func() {
+			layers[1].Allow = []string{"repository"}
+			DeferCleanup(func() { layers[1].Allow = nil })
+
+			violations, err := engine.ExecuteLayers(layers[1:])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(BeEmpty())
+
+			violations, err = engine.ExecuteLayers(layers)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(BeEmpty())
+		})
+	})
+
+	Context("Naming", func() {
+		It("should report nodes not following a naming convention", func() {
+			violations, err := engine.ExecuteNaming([]models.NamingRule{
+				{Name: "Layer suffixes", Kind: "type", Pattern: "(Controller|Service|Repository)$", Reason: "types are named after their layer"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].File).To(Equal("/test/User.go"))
+			Expect(violations[0].Line).To(Equal(1))
+			Expect(*violations[0].Message).To(Equal("Rule 'Layer suffixes': type User does not match (Controller|Service|Repository)$: types are named after their layer"))
+		})
+
+		It("should report each file and package once", func() {
+			violations, err := engine.ExecuteNaming([]models.NamingRule{
+				{Kind: "file", Pattern: "Controller"},
+				{Kind: "package", Pattern: "^(controller|service)$"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			var messages []string
+			for _, v := range violations {
+				messages = append(messages, *v.Message)
+			}
+			Expect(messages).To(ConsistOf(
+				"Rule 'file naming': file User.go does not match Controller",
+				"Rule 'file naming': file UserRepository.go does not match Controller",
+				"Rule 'file naming': file UserService.go does not match Controller",
+				"Rule 'package naming': package model does not match ^(controller|service)$",
+				"Rule 'package naming': package repository does not match ^(controller|service)$",
+			))
+		})
+	})
+
+	Context("Annotations", func() {
+		BeforeEach(func() {
+			store := func(node *models.ASTNode) {
+				_, err := astCache.StoreASTNode(node)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			store(&models.ASTNode{FilePath: "/test/api/user.go", PackageName: "api", TypeName: "UserRequest", FieldName: "Name",
+				NodeType: models.NodeTypeField, StartLine: 4, Metatdata: map[string]string{models.MetadataTags: `json:"name" validate:"required"`}})
+			store(&models.ASTNode{FilePath: "/test/api/user.go", PackageName: "api", TypeName: "UserRequest", FieldName: "Email",
+				NodeType: models.NodeTypeField, StartLine: 5})
+			store(&models.ASTNode{FilePath: "/test/api/user.go", PackageName: "api", TypeName: "UserRequest", FieldName: "token",
+				NodeType: models.NodeTypeField, StartLine: 6, IsPrivate: true})
+			store(&models.ASTNode{FilePath: "/test/service/OrderService.java", PackageName: "com.example.service", TypeName: "OrderService",
+				FieldName: "repository", NodeType: models.NodeTypeField, StartLine: 12, IsPrivate: true,
+				Metatdata: map[string]string{models.MetadataAnnotations: "Autowired"}})
+		})
+
+		It("should report forbidden annotations", func() {
+			violations, err := engine.ExecuteAnnotations([]models.AnnotationRule{
+				{Name: "No field injection", Kind: "field", Forbid: []string{"Autowired"}, Reason: "inject dependencies through constructors"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].File).To(Equal("/test/service/OrderService.java"))
+			Expect(violations[0].Line).To(Equal(12))
+			Expect(violations[0].Severity).To(Equal(models.SeverityError))
+			Expect(*violations[0].Message).To(Equal("Rule 'No field injection': field com.example.service.OrderService.repository has forbidden annotation Autowired: inject dependencies through constructors"))
+		})
+
+		It("should report exported fields without required struct tags", func() {
+			violations, err := engine.ExecuteAnnotations([]models.AnnotationRule{
+				{Kind: "field", Path: "**/api/**", Exported: true, Require: []string{"json"}, Severity: models.SeverityWarning},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Severity).To(Equal(models.SeverityWarning))
+			Expect(*violations[0].Message).To(Equal("Rule 'field annotations': field api.UserRequest.Email is missing required annotation json"))
+		})
+	})
+
+	Context("Schema", func() {
+		BeforeEach(func() {
+			table := &models.ASTNode{FilePath: "sql://db/public", PackageName: "public", TypeName: "Orders", NodeType: models.NodeTypeTypeTable, StartLine: -1}
+			_, err := astCache.StoreASTNode(table)
+			Expect(err).ToNot(HaveOccurred())
+
+			var columnIDs []int64
+			for _, column := range []string{"user_id", "product_id"} {
+				id, err := astCache.StoreASTNode(&models.ASTNode{FilePath: "sql://db/public", PackageName: "public", TypeName: "Orders", FieldName: column, NodeType: models.NodeTypeFieldColumn, StartLine: -1})
+				Expect(err).ToNot(HaveOccurred())
+				columnIDs = append(columnIDs, id)
+			}
+			_, err = astCache.StoreASTNode(&models.ASTNode{
+				FilePath: "sql://db/public", PackageName: "public", TypeName: "Orders", MethodName: "orders_user_id_idx",
+				NodeType: models.NodeTypeMethod, StartLine: -1, Metatdata: map[string]string{"kind": models.SchemaKindIndex},
+				Parameters: []models.Parameter{{Name: "user_id"}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(astCache.StoreASTRelationship(columnIDs[0], nil, 0, string(models.RelationshipTypeForeignKey), "Orders.user_id -> users.id")).To(Succeed())
+			Expect(astCache.StoreASTRelationship(columnIDs[1], nil, 0, string(models.RelationshipTypeForeignKey), "Orders.product_id -> products.id")).To(Succeed())
+
+			_, err = astCache.StoreASTNode(&models.ASTNode{
+				FilePath: "/test/OrderRepository.go", PackageName: "repository", TypeName: "OrderRepository", MethodName: "FindAll",
+				NodeType: models.NodeTypeMethod, StartLine: 10,
+				Statements: []models.ASTStatement{{StartLine: 11, Type: models.ASTStatementTypeLoop, Children: []models.ASTStatement{
+					{StartLine: 12, Type: models.ASTStatementTypeSQLQuery, Text: "SELECT * FROM orders"},
+					{StartLine: 13, Type: models.ASTStatementTypeSQLQuery, Text: "SELECT count(*) FROM orders"},
+				}}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should report tables and columns not following the naming conventions", func() {
+			violations, err := engine.ExecuteSchema(&models.SchemaConfig{TableNaming: "^[a-z_]+$", Severity: models.SeverityWarning})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].File).To(Equal("sql://db/public"))
+			Expect(violations[0].Line).To(Equal(1))
+			Expect(violations[0].Severity).To(Equal(models.SeverityWarning))
+			Expect(*violations[0].Message).To(Equal("Rule 'schema table naming': table Orders does not match ^[a-z_]+$"))
+		})
+
+		It("should report foreign keys without an index", func() {
+			violations, err := engine.ExecuteSchema(&models.SchemaConfig{IndexForeignKeys: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].File).To(Equal("sql://db/public"))
+			Expect(violations[0].Severity).To(Equal(models.SeverityError))
+			Expect(*violations[0].Message).To(Equal("Rule 'schema foreign key indexes': foreign key Orders.product_id -> products.id is not indexed"))
+		})
+
+		It("should report queries selecting every column", func() {
+			violations, err := engine.ExecuteSchema(&models.SchemaConfig{ForbidSelectStar: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].File).To(Equal("/test/OrderRepository.go"))
+			Expect(violations[0].Line).To(Equal(12))
+		})
+	})
+
+	Context("God objects", func() {
+		It("should not report types, packages and functions within the default thresholds", func() {
+			violations, err := engine.ExecuteGodObjects(&models.GodObjectConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(BeEmpty())
+		})
+
+		It("should report functions calling too many distinct functions", func() {
+			violations, err := engine.ExecuteGodObjects(&models.GodObjectConfig{
+				MaxMethods: -1, MaxFields: -1, MaxPackageFiles: -1, MaxPackageTypes: -1, MaxFanOut: 1,
+				Severity: models.SeverityWarning,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].File).To(Equal("/test/SimpleController.go"))
+			Expect(violations[0].Severity).To(Equal(models.SeverityWarning))
+			Expect(*violations[0].Message).To(ContainSubstring("calls 2 distinct functions, more than 1"))
+		})
+
+		It("should report packages with too many files", func() {
+			violations, err := engine.ExecuteGodObjects(&models.GodObjectConfig{
+				MaxMethods: -1, MaxFields: -1, MaxPackageFiles: 1, MaxPackageTypes: -1, MaxFanOut: -1,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+			Expect(*violations[0].Message).To(Equal("Rule 'max files per package': package controller has 2 files, more than 1"))
+		})
+	})
+
+	Context("Dead code", func() {
+		BeforeEach(func() {
+			store := func(node *models.ASTNode) int64 {
+				node.FilePath, node.PackageName, node.IsPrivate = "/test/helpers.go", "helpers", true
+				id, err := astCache.StoreASTNode(node)
+				Expect(err).ToNot(HaveOccurred())
+				return id
+			}
+			caller := store(&models.ASTNode{MethodName: "run", NodeType: models.NodeTypeMethod, StartLine: 3,
+				Statements: []models.ASTStatement{{StartLine: 4, Type: models.ASTStatementTypeAssignment, Text: "opts := defaultOptions"}}})
+			Expect(astCache.StoreASTRelationship(caller, nil, 5, models.RelationshipCall, "format(opts)")).To(Succeed())
+			store(&models.ASTNode{MethodName: "format", NodeType: models.NodeTypeMethod, StartLine: 10})
+			store(&models.ASTNode{FieldName: "defaultOptions", NodeType: models.NodeTypeVariable, StartLine: 1})
+			retry := store(&models.ASTNode{MethodName: "retry", NodeType: models.NodeTypeMethod, StartLine: 20})
+			Expect(astCache.StoreASTRelationship(retry, nil, 22, models.RelationshipCall, "retry(n - 1)")).To(Succeed())
+			store(&models.ASTNode{MethodName: "main", NodeType: models.NodeTypeMethod, StartLine: 30})
+		})
+
+		It("should find unexported symbols that no other symbol references", func() {
+			dead, err := engine.DeadCode("/test/")
+			Expect(err).ToNot(HaveOccurred())
+
+			var names []string
+			for _, symbol := range dead {
+				names = append(names, symbol.Kind+" "+symbol.Name)
+			}
+			Expect(names).To(ConsistOf("function helpers.run", "function helpers.retry"))
+		})
+
+		It("should report dead code outside of ignored files", func() {
+			violations, err := engine.ExecuteDeadCode(&models.DeadCodeConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(2))
+			Expect(violations[0].Severity).To(Equal(models.SeverityWarning))
+			Expect(*violations[0].Message).To(Equal("Rule 'dead code': function helpers.run is never referenced"))
+
+			violations, err = engine.ExecuteDeadCode(&models.DeadCodeConfig{Ignore: []string{"helpers.go"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(BeEmpty())
+		})
+	})
+
+	Context("Hotspots", func() {
+		churn := map[string]int{
+			"/test/ComplexController.go": 4,
+			"/test/UserService.go":       10,
+			"/test/SimpleController.go":  1,
+			"/test/User.go":              5,
+			"/test/deleted.go":           8,
+		}
+
+		It("should rank files by churn times complexity", func() {
+			hotspots, err := engine.Hotspots(churn, "/test/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hotspots).To(HaveLen(3))
+			Expect(*hotspots[0]).To(Equal(models.Hotspot{
+				File: "/test/ComplexController.go", Commits: 4, Complexity: 25, Functions: 1, Lines: 80, Score: 100,
+			}))
+			Expect(hotspots[1].File).To(Equal("/test/UserService.go"))
+			Expect(hotspots[1].Score).To(Equal(50))
+			Expect(hotspots[2].File).To(Equal("/test/SimpleController.go"))
+		})
+
+		It("should report advisory violations above the threshold", func() {
+			violations, err := engine.ExecuteHotspots(&models.HotspotConfig{Threshold: 40}, churn)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(2))
+			Expect(violations[0].Severity).To(Equal(models.SeverityInfo))
+			Expect(*violations[0].Message).To(Equal("Rule 'hotspots': changed by 4 commits with a complexity of 25, a score of 100 above 40, consider refactoring it"))
+
+			violations, err = engine.ExecuteHotspots(&models.HotspotConfig{Threshold: 40, Ignore: []string{"UserService.go"}}, churn)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(1))
+		})
+	})
+
+	Context("Deprecated symbols", func() {
+		BeforeEach(func() {
+			store := func(node *models.ASTNode) int64 {
+				if node.PackageName == "" {
+					node.FilePath, node.PackageName = "/test/store/client.go", "store"
+				}
+				id, err := astCache.StoreASTNode(node)
+				Expect(err).ToNot(HaveOccurred())
+				return id
+			}
+			call := func(from int64, to *int64, line int, text string) {
+				Expect(astCache.StoreASTRelationship(from, to, line, models.RelationshipCall, text)).To(Succeed())
+			}
+			deprecated := func(notice string) map[string]string {
+				return map[string]string{models.MetadataDeprecated: notice}
+			}
+
+			store(&models.ASTNode{MethodName: "Connect", NodeType: models.NodeTypeMethod, StartLine: 1, Metatdata: deprecated("Use Dial")})
+			store(&models.ASTNode{TypeName: "Client", MethodName: "Close", NodeType: models.NodeTypeMethod, StartLine: 5, Metatdata: deprecated("true")})
+			timeout := store(&models.ASTNode{FieldName: "DefaultTimeout", NodeType: models.NodeTypeVariable, StartLine: 9, Metatdata: deprecated("Use Client.Timeout")})
+
+			run := store(&models.ASTNode{MethodName: "run", NodeType: models.NodeTypeMethod, StartLine: 20})
+			call(run, nil, 21, "c := Connect()")
+			call(run, &timeout, 22, "DefaultTimeout")
+			shutdown := store(&models.ASTNode{MethodName: "shutdown", NodeType: models.NodeTypeMethod, StartLine: 30,
+				Parameters: []models.Parameter{{Name: "c", Type: "*Client"}}})
+			call(shutdown, nil, 31, "c.Close()")
+			// Close of another type is not a usage of the deprecated Client.Close
+			cleanup := store(&models.ASTNode{MethodName: "cleanup", NodeType: models.NodeTypeMethod, StartLine: 40,
+				Parameters: []models.Parameter{{Name: "f", Type: "*os.File"}}})
+			call(cleanup, nil, 41, "f.Close()")
+			handler := store(&models.ASTNode{FilePath: "/test/api/handler.go", PackageName: "api", MethodName: "Serve",
+				NodeType: models.NodeTypeMethod, StartLine: 3})
+			call(handler, nil, 4, "store.Connect()")
+		})
+
+		It("should report usages of deprecated symbols with their notice", func() {
+			violations, err := engine.ExecuteDeprecated(&models.DeprecatedConfig{})
+			Expect(err).ToNot(HaveOccurred())
+
+			var messages []string
+			for _, v := range violations {
+				Expect(v.Severity).To(Equal(models.SeverityWarning))
+				messages = append(messages, *v.Message)
+			}
+			Expect(messages).To(ConsistOf(
+				"Rule 'deprecated': store.run uses deprecated store.Connect: Use Dial",
+				"Rule 'deprecated': store.run uses deprecated store.DefaultTimeout: Use Client.Timeout",
+				"Rule 'deprecated': store.shutdown uses deprecated store.Client.Close",
+				"Rule 'deprecated': api.Serve uses deprecated store.Connect: Use Dial",
+			))
+		})
+
+		It("should not report allowed usages", func() {
+			violations, err := engine.ExecuteDeprecated(&models.DeprecatedConfig{Allow: []models.DeprecatedAllowance{
+				{Symbol: "store.Connect", Files: []string{"**/api/**"}},
+				{Symbol: "store.Client.*"},
+			}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(violations).To(HaveLen(2))
+			for _, v := range violations {
+				Expect(v.Caller.String()).To(Equal("store.run"))
+			}
+		})
+	})
+
+	Context("CEL Rules", func() {
+		It("should report nodes matching a CEL expression", func() {
+			violations, err := engine.ExecuteCEL([]models.CELRule{
+				{Name: "Long parameter lists", Expression: "node.parameter_count > 1 && !node.is_private", Reason: "use a request type", Severity: models.SeverityWarning},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			var messages []string
+			for _, v := range violations {
+				Expect(v.Severity).To(Equal(models.SeverityWarning))
+				messages = append(messages, *v.Message)
+			}
+			Expect(messages).To(ConsistOf(
+				"Rule 'Long parameter lists': controller.ComplexController.ProcessOrder matches node.parameter_count > 1 && !node.is_private: use a request type",
+				"Rule 'Long parameter lists': service.UserService.CreateUser matches node.parameter_count > 1 && !node.is_private: use a request type",
+			))
+		})
+	})
+
+	Context("Policies", func() {
+		It("should build the policy input from the graph", func() {
+			input, err := engine.PolicyInput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(input.Nodes).To(HaveLen(5))
+			Expect(input.Nodes[0]).To(HaveKeyWithValue("name", "controller.ComplexController.ProcessOrder"))
+			Expect(input.Relationships).To(HaveLen(3))
+			Expect(input.Dependencies).To(HaveLen(1))
+			Expect(input.Dependencies[0].LibraryNode.Package).To(Equal("fmt"))
+			Expect(input.Dependencies[0].LineNo).To(Equal(11))
+		})
+	})
+
+	Context("Package Coupling", func() {
+		It("should compute the coupling metrics of every package", func() {
+			coupling, err := engine.PackageCoupling("")
+			Expect(err).ToNot(HaveOccurred())
+
+			metrics := make(map[string][]float64)
+			for _, c := range coupling {
+				metrics[c.Package] = []float64{float64(c.Afferent), float64(c.Efferent), c.Instability, c.Abstractness, c.Distance}
+			}
+			Expect(metrics).To(Equal(map[string][]float64{
+				"controller": {0, 1, 1, 0, 0},
+				"service":    {1, 1, 0.5, 0, 0.5},
+				"repository": {1, 0, 0, 0, 1},
+				"model":      {0, 0, 0, 0, 1},
+			}))
+		})
+
+		It("should limit the metrics of packages", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Stable packages" {
+				LIMIT(package.instability > 0.4)
+				LIMIT(repo*.distance >= 1)
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			violations, err := engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+
+			var messages []string
+			for _, v := range violations {
+				messages = append(messages, *v.Message)
+			}
+			Expect(messages).To(Equal([]string{
+				"Rule 'Stable packages': package controller has instability 1, violating > 0.4 (Ca=0, Ce=1)",
+				"Rule 'Stable packages': package service has instability 0.5, violating > 0.4 (Ca=1, Ce=1)",
+				"Rule 'Stable packages': package repository has distance 1, violating >= 1 (Ca=1, Ce=0)",
+			}))
+			Expect(violations[0].File).To(Equal("/test/ComplexController.go"))
+		})
+	})
+
+	Context("Codebase Statistics", func() {
+		It("should summarise the languages, types and packages of the cache", func() {
+			stats, err := engine.CodebaseStats("/test/", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Languages).To(Equal([]models.LanguageStats{{
+				Language: "unknown", Files: 5, Lines: 137, Functions: 4,
+				AvgComplexity: 8.25, P50Complexity: 2, P90Complexity: 25, MaxComplexity: 25,
+			}}))
+			Expect(stats.LargestTypes).To(Equal([]models.TypeSize{{Type: "model.User", Lines: 10, File: "/test/User.go", Line: 1}}))
+			Expect(stats.MostDependedOn).To(Equal([]models.PackageDependents{
+				{Package: "repository", Dependents: 1}, {Package: "service", Dependents: 1},
+			}))
+		})
+
+		It("should count the comments of the files on disk", func() {
+			dir := GinkgoT().TempDir()
+			file := filepath.Join(dir, "store.go")
+			Expect(os.WriteFile(file, []byte("// Package store persists users\npackage store\n\n/*\n  Save writes a user\n*/\nfunc Save() {} // inline\n"), 0644)).To(Succeed())
+			language := "go"
+			_, err := astCache.StoreASTNode(&models.ASTNode{FilePath: file, PackageName: "store", MethodName: "Save",
+				NodeType: models.NodeTypeMethod, Language: &language, StartLine: 7, EndLine: 7, CyclomaticComplexity: 1})
+			Expect(err).ToNot(HaveOccurred())
+
+			stats, err := engine.CodebaseStats(dir+"/", 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Languages).To(Equal([]models.LanguageStats{{
+				Language: "go", Files: 1, Lines: 7, CommentLines: 4, CommentDensity: 66.67, Functions: 1,
+				AvgComplexity: 1, P50Complexity: 1, P90Complexity: 1, MaxComplexity: 1,
+			}}))
+		})
+	})
+
+	Context("Dependency Graph", func() {
+		It("should connect the packages depending on each other", func() {
+			graph, err := engine.DependencyGraph(models.GraphOptions{Level: models.GraphLevelPackage})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(graph.Nodes).To(Equal([]string{"controller", "model", "repository", "service"}))
+			Expect(graph.Edges).To(Equal([]*models.DependencyEdge{
+				{From: "controller", To: "service", Count: 2},
+				{From: "service", To: "repository", Count: 1},
+			}))
+		})
+
+		It("should follow the dependencies of matching types up to the depth", func() {
+			graph, err := engine.DependencyGraph(models.GraphOptions{Level: models.GraphLevelType, Pattern: "controller.Simple*", Depth: 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(graph.Nodes).To(Equal([]string{"controller.SimpleController", "service.UserService"}))
+			Expect(graph.Edges).To(Equal([]*models.DependencyEdge{
+				{From: "controller.SimpleController", To: "service.UserService", Count: 1},
+			}))
+		})
+
+		It("should draw the chosen relationship types with the libraries they reach", func() {
+			graph, err := engine.DependencyGraph(models.GraphOptions{
+				Level:   models.GraphLevelPackage,
+				Edges:   []string{models.RelationshipCall},
+				Exclude: []string{"repo*"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(graph.Nodes).To(Equal([]string{"controller", "fmt", "model", "service"}))
+			Expect(graph.External).To(Equal([]string{"fmt"}))
+			Expect(graph.Edges).To(Equal([]*models.DependencyEdge{
+				{From: "controller", To: "fmt", Count: 1, Type: models.RelationshipCall},
+				{From: "controller", To: "service", Count: 2, Type: models.RelationshipCall},
+			}))
+		})
+
+		It("should reject unknown relationship types", func() {
+			_, err := engine.DependencyGraph(models.GraphOptions{Level: models.GraphLevelPackage, Edges: []string{"uses"}})
+			Expect(err).To(MatchError(ContainSubstring("invalid edge type 'uses'")))
+		})
+	})
+
+	Context("Call Graph", func() {
+		names := func(trees []*models.CallTree) []string {
+			var result []string
+			var walk func(tree *models.CallTree, indent string)
+			walk = func(tree *models.CallTree, indent string) {
+				result = append(result, fmt.Sprintf("%s%s L%d", indent, tree.Node.String(), tree.Line))
+				for _, call := range tree.Calls {
+					walk(call, indent+"  ")
+				}
+			}
+			for _, tree := range trees {
+				walk(tree, "")
+			}
+			return result
+		}
+
+		It("should walk the calls of the matching methods", func() {
+			trees, err := engine.CallGraph(models.CallGraphOptions{Pattern: "controller:SimpleController:GetUser"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names(trees)).To(Equal([]string{
+				"controller.SimpleController.GetUser L0",
+				"  service.UserService.CreateUser L12",
+				"    repository.UserRepository.Save L18",
+			}))
+		})
+
+		It("should walk the callers up to the max depth", func() {
+			trees, err := engine.CallGraph(models.CallGraphOptions{Pattern: "repository:*:Save", Callers: true, MaxDepth: 1})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names(trees)).To(Equal([]string{
+				"repository.UserRepository.Save L0",
+				"  service.UserService.CreateUser L18",
+			}))
+		})
+
+		It("should only walk into the chosen packages", func() {
+			trees, err := engine.CallGraph(models.CallGraphOptions{Pattern: "controller:SimpleController:GetUser", Packages: []string{"serv*"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(names(trees)).To(Equal([]string{
+				"controller.SimpleController.GetUser L0",
+				"  service.UserService.CreateUser L12",
+			}))
+		})
+
+		It("should draw the calls of the callers from caller to called method", func() {
+			trees, err := engine.CallGraph(models.CallGraphOptions{Pattern: "repository:*:Save", Callers: true})
+			Expect(err).ToNot(HaveOccurred())
+			graph := models.CallGraphOf(trees, true)
+			Expect(graph.Edges).To(Equal([]*models.DependencyEdge{
+				{From: "controller.ComplexController.ProcessOrder", To: "service.UserService.CreateUser", Count: 1},
+				{From: "controller.SimpleController.GetUser", To: "service.UserService.CreateUser", Count: 1},
+				{From: "service.UserService.CreateUser", To: "repository.UserRepository.Save", Count: 1},
+			}))
+		})
+	})
+
+	Context("Timings", func() {
+		It("should record the evaluation time of every clause and rule", func() {
+			aql := `RULE "Complexity" {
+				LIMIT(*.cyclomatic > 10)
+				LIMIT(*.params > 2)
+			}`
+
+			ruleSet, err := parser.ParseAQL(aql)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = engine.ExecuteRuleSet(ruleSet)
+			Expect(err).ToNot(HaveOccurred())
+
+			timings := engine.Timings()
+			Expect(timings).To(HaveLen(3))
+			Expect(timings[0].Rule).To(Equal("Complexity"))
+			Expect(timings[0].Clause).To(HavePrefix("LIMIT("))
+			Expect(timings[0].Violations).To(Equal(1))
+			Expect(timings[1].Clause).To(HavePrefix("LIMIT("))
+			Expect(timings[2].Clause).To(BeEmpty())
+			Expect(timings[2].Violations).To(Equal(2))
+			Expect(timings[2].Duration).To(BeNumerically(">=", timings[0].Duration+timings[1].Duration))
+		})
+	})
+
+	Context("Explain", func() {
+		It("should describe the query, matched nodes and violations of a rule", func() {
+			ruleSet, err := parser.ParseAQL(`RULE "Complex Controllers" {
+				LIMIT(*Controller*.cyclomatic > 5)
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			explanation, err := engine.Explain(ruleSet.Rules[0], 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(explanation.Rule).To(Equal("Complex Controllers"))
+			Expect(explanation.Parsed).To(ContainSubstring("LIMIT(*Controller*.cyclomatic > 5)"))
+			Expect(explanation.Statements).To(HaveLen(1))
+
+			patterns := explanation.Statements[0].Patterns
+			Expect(patterns).To(HaveLen(1))
+			Expect(patterns[0].Query).To(HaveSuffix("AND type_name LIKE '%Controller%'"))
+			Expect(patterns[0].Matches).To(BeNumerically(">", 1))
+			Expect(patterns[0].Examples).To(HaveLen(1))
+
+			Expect(explanation.ViolationCount).To(Equal(1))
+			Expect(explanation.Violations).To(HaveLen(1))
+			Expect(explanation.Violations[0].Message).To(ContainSubstring("ProcessOrder"))
+		})
+	})
+
 	Context("Error Handling", func() {
 		It("should handle empty rule set", func() {
 			emptyRuleSet := &models.AQLRuleSet{Rules: []*models.AQLRule{}}
@@ -0,0 +1,59 @@
+package performance_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+	"github.com/flanksource/arch-unit/parser"
+	"github.com/flanksource/arch-unit/query"
+)
+
+// benchmarkCache returns an AST cache seeded with the benchmark codebase
+func benchmarkCache(b *testing.B) *cache.ASTCache {
+	g := NewWithT(b)
+	astCache, err := cache.NewASTCacheWithPath(b.TempDir())
+	g.Expect(err).ToNot(HaveOccurred())
+	b.Cleanup(func() { _ = astCache.Close() })
+
+	seedCodebase(g, astCache)
+	return astCache
+}
+
+// BenchmarkForbidRules measures the evaluation of FORBID rules, run with
+// go test -bench . ./tests/performance
+func BenchmarkForbidRules(b *testing.B) {
+	g := NewWithT(b)
+	astCache := benchmarkCache(b)
+	ruleSet, err := parser.ParseAQL(`RULE "Layers" {
+		FORBID(pkg0:* -> pkg1:*)
+		FORBID(pkg5:* -> pkg7:*)
+	}`)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		violations, err := query.NewAQLEngine(astCache).ExecuteRuleSet(ruleSet)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(violations).To(HaveLen(typesPerPkg * methodsPerType * 2))
+	}
+}
+
+// BenchmarkFindNodes measures the lookup of a node by qualified name, with the prepared
+// statements of the read pool reused across iterations
+func BenchmarkFindNodes(b *testing.B) {
+	g := NewWithT(b)
+	astCache := benchmarkCache(b)
+	pattern, err := models.ParsePattern("pkg3.Type4:Method5")
+	g.Expect(err).ToNot(HaveOccurred())
+	engine := query.NewAQLEngine(astCache)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nodes, err := engine.FindNodes(pattern)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(nodes).To(HaveLen(1))
+	}
+}
@@ -0,0 +1,111 @@
+package performance_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/flanksource/arch-unit/internal/cache"
+	"github.com/flanksource/arch-unit/models"
+)
+
+const (
+	packages       = 20
+	typesPerPkg    = 10
+	methodsPerType = 10
+)
+
+// seedCodebase stores packages*typesPerPkg*methodsPerType methods, each calling the method of
+// the same name in the next two packages
+func seedCodebase(g Gomega, astCache *cache.ASTCache) {
+	var nodes []*models.ASTNode
+	for p := 0; p < packages; p++ {
+		for t := 0; t < typesPerPkg; t++ {
+			for m := 0; m < methodsPerType; m++ {
+				nodes = append(nodes, &models.ASTNode{
+					FilePath:             fmt.Sprintf("/repo/pkg%d/type%d.go", p, t),
+					PackageName:          fmt.Sprintf("pkg%d", p),
+					TypeName:             fmt.Sprintf("Type%d", t),
+					MethodName:           fmt.Sprintf("Method%d", m),
+					NodeType:             models.NodeTypeMethod,
+					StartLine:            m*10 + 1,
+					EndLine:              m*10 + 9,
+					CyclomaticComplexity: m,
+					LastModified:         time.Now(),
+				})
+			}
+		}
+	}
+	g.Expect(astCache.GetWriteQuery().CreateInBatches(nodes, 500).Error).To(Succeed())
+
+	perPackage := typesPerPkg * methodsPerType
+	var relationships []*models.ASTRelationship
+	for i, node := range nodes {
+		for _, next := range []int{1, 2} {
+			target := nodes[(i+next*perPackage)%len(nodes)]
+			relationships = append(relationships, &models.ASTRelationship{
+				FromASTID:        node.ID,
+				ToASTID:          &target.ID,
+				LineNo:           node.StartLine + next,
+				RelationshipType: models.RelationshipTypeCall,
+				Text:             target.TypeName + "." + target.MethodName + "()",
+			})
+		}
+	}
+	g.Expect(astCache.GetWriteQuery().CreateInBatches(relationships, 500).Error).To(Succeed())
+}
+
+// queryPlan returns the details of the SQLite query plan of a query
+func queryPlan(astCache *cache.ASTCache, sql string, args ...interface{}) string {
+	rows, err := astCache.QueryRaw("EXPLAIN QUERY PLAN "+sql, args...)
+	Expect(err).ToNot(HaveOccurred())
+	defer func() { _ = rows.Close() }()
+
+	var details []string
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		Expect(rows.Scan(&id, &parent, &notUsed, &detail)).To(Succeed())
+		details = append(details, detail)
+	}
+	Expect(rows.Err()).ToNot(HaveOccurred())
+	return strings.Join(details, "\n")
+}
+
+// The query plans are checked by the suite, latency is measured by the benchmarks of
+// aql_query_bench_test.go as wall-clock budgets are not reliable on shared CI runners
+var _ = Describe("AQL query plans", Ordered, func() {
+	var astCache *cache.ASTCache
+
+	BeforeAll(func() {
+		dir, err := os.MkdirTemp("", "arch-unit-perf-*")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+
+		astCache, err = cache.NewASTCacheWithPath(dir)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(astCache.Close)
+
+		seedCodebase(Default, astCache)
+	})
+
+	Context("Indexes", func() {
+		It("should look up nodes by qualified name with the composite index", func() {
+			plan := queryPlan(astCache,
+				"SELECT id FROM ast_nodes WHERE 1=1 AND package_name = ? AND type_name = ? AND method_name = ?",
+				"pkg3", "Type4", "Method5")
+			Expect(plan).To(ContainSubstring("idx_ast_nodes_name (package_name=? AND type_name=? AND method_name=?)"))
+		})
+
+		It("should look up the dependencies of a node with the composite index", func() {
+			plan := queryPlan(astCache,
+				"SELECT id FROM ast_relationships WHERE from_ast_id = ? AND relationship_type IN (?,?,?,?) ORDER BY line_no",
+				1, models.RelationshipCall, models.RelationshipInheritance, models.RelationshipSQLQuery, models.RelationshipHTTPCall)
+			Expect(plan).To(ContainSubstring("idx_ast_relationships_from_type (from_ast_id=? AND relationship_type=?)"))
+		})
+	})
+})